@@ -0,0 +1,340 @@
+// Package agent runs tool-calling loops on top of a warp.Client.
+//
+// An Agent sends a conversation to the model along with its tools. When the
+// model responds with tool calls, the agent executes them, appends the
+// results to the conversation, and asks the model again, until the model
+// produces a final answer.
+//
+// Basic usage:
+//
+//	weather := agent.Tool{
+//	    Definition: warp.Function{
+//	        Name:        "get_weather",
+//	        Description: "Get the current weather for a city",
+//	        Parameters: map[string]any{
+//	            "type":       "object",
+//	            "properties": map[string]any{"city": map[string]any{"type": "string"}},
+//	            "required":   []string{"city"},
+//	        },
+//	    },
+//	    Func: func(ctx context.Context, arguments string) (string, error) {
+//	        return `{"temp_c": 21}`, nil
+//	    },
+//	}
+//
+//	a := agent.New(client, "openai/gpt-4o", agent.WithTools(weather))
+//	result, err := a.Run(ctx, []warp.Message{
+//	    {Role: "user", Content: "What's the weather in Paris?"},
+//	})
+package agent
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/blue-context/warp"
+)
+
+// ErrMaxRounds is returned when the model keeps calling tools past the
+// configured round limit.
+var ErrMaxRounds = errors.New("agent: maximum tool rounds exceeded")
+
+// ToolFunc executes a tool call.
+//
+// arguments is the JSON-encoded argument object emitted by the model. The
+// returned string is sent back to the model as the tool result. Returned
+// errors are reported to the model as the tool result rather than aborting
+// the run, so the model can recover.
+type ToolFunc func(ctx context.Context, arguments string) (string, error)
+
+// Tool is a function the model can call.
+type Tool struct {
+	// Definition describes the tool to the model.
+	Definition warp.Function
+
+	// Func executes the tool.
+	Func ToolFunc
+
+	// Tags label the tool for per-request selection from a ToolRegistry.
+	Tags []string
+
+	// Permissions lists the permissions a caller must hold to use the tool
+	// when it is served from a ToolRegistry.
+	Permissions []string
+
+	// Priority orders tools when a ToolRegistry prunes definitions to fit a
+	// token budget; higher priorities are kept first.
+	Priority int
+}
+
+// Agent runs tool-calling loops against a model.
+//
+// Thread Safety: Agent is safe for concurrent use; each Run or RunStream
+// call works on its own copy of the conversation.
+type Agent struct {
+	client    warp.Client
+	model     string
+	tools     []Tool
+	byName    map[string]Tool
+	maxRounds int
+	request   warp.CompletionRequest
+	registry  *ToolRegistry
+
+	validate     bool // Validate arguments against tool schemas
+	retryInvalid bool // Report invalid arguments to the model instead of failing
+	intermediate bool // Stream the output of tool-calling rounds
+}
+
+// Option configures an Agent.
+type Option func(*Agent)
+
+// WithTools adds tools the model can call.
+func WithTools(tools ...Tool) Option {
+	return func(a *Agent) {
+		a.tools = append(a.tools, tools...)
+	}
+}
+
+// WithMaxRounds limits how many tool-calling rounds a run may take
+// (default 10). A run that exceeds the limit returns ErrMaxRounds.
+func WithMaxRounds(n int) Option {
+	return func(a *Agent) {
+		a.maxRounds = n
+	}
+}
+
+// WithArgumentValidation enables or disables validating tool arguments
+// against each tool's parameter schema before calling it (default enabled).
+//
+// Validation prevents tool functions from receiving malformed arguments.
+func WithArgumentValidation(enabled bool) Option {
+	return func(a *Agent) {
+		a.validate = enabled
+	}
+}
+
+// WithValidationRetry controls what happens when tool arguments fail
+// validation (default enabled).
+//
+// When enabled, the tool is not called and the validation error is sent
+// back to the model as the tool result, so it can retry with corrected
+// arguments in the next round. When disabled, the run fails with the
+// *ValidationError.
+func WithValidationRetry(enabled bool) Option {
+	return func(a *Agent) {
+		a.retryInvalid = enabled
+	}
+}
+
+// WithIntermediateOutput controls whether RunStream forwards the model
+// output of rounds that end in tool calls (default disabled).
+//
+// By default each round is held back until it ends, since a round only
+// turns out to call tools once its tool-call deltas arrive; rounds that
+// call tools are dropped and the final answer is forwarded when its round
+// completes. When enabled, every round streams as it arrives, so the final
+// answer streams token by token, preceded by any text the model wrote
+// before calling tools.
+func WithIntermediateOutput(enabled bool) Option {
+	return func(a *Agent) {
+		a.intermediate = enabled
+	}
+}
+
+// WithToolRegistry adds the tools of a ToolRegistry, selected for each
+// model call with FilterFromMetadata applied to the request metadata set by
+// WithRequest. Tools added with WithTools are always offered and take
+// precedence on name conflicts.
+//
+// Example:
+//
+//	a := agent.New(client, model,
+//	    agent.WithToolRegistry(registry),
+//	    agent.WithRequest(warp.CompletionRequest{
+//	        Metadata: map[string]any{agent.MetadataPermissions: user.Permissions},
+//	    }),
+//	)
+func WithToolRegistry(registry *ToolRegistry) Option {
+	return func(a *Agent) {
+		a.registry = registry
+	}
+}
+
+// WithRequest sets request parameters (temperature, max tokens, and so on)
+// used for every model call. Model, Messages, and Tools are set by the
+// agent and ignored here.
+//
+// Example:
+//
+//	agent.WithRequest(warp.CompletionRequest{Temperature: warp.Float64Ptr(0)})
+func WithRequest(req warp.CompletionRequest) Option {
+	return func(a *Agent) {
+		a.request = req
+	}
+}
+
+// New creates an Agent that calls model through client.
+//
+// Example:
+//
+//	a := agent.New(client, "anthropic/claude-3-5-sonnet-20241022",
+//	    agent.WithTools(searchTool, fetchTool),
+//	    agent.WithMaxRounds(5),
+//	)
+func New(client warp.Client, model string, opts ...Option) *Agent {
+	a := &Agent{
+		client:       client,
+		model:        model,
+		maxRounds:    10,
+		validate:     true,
+		retryInvalid: true,
+	}
+
+	for _, opt := range opts {
+		opt(a)
+	}
+
+	a.byName = make(map[string]Tool, len(a.tools))
+	for _, t := range a.tools {
+		a.byName[t.Definition.Name] = t
+	}
+
+	return a
+}
+
+// Result is the outcome of a completed run.
+type Result struct {
+	// Response is the model's final response.
+	Response *warp.CompletionResponse
+
+	// Messages is the full conversation, including tool calls, tool
+	// results, and the final answer.
+	Messages []warp.Message
+
+	// Rounds is the number of model calls made.
+	Rounds int
+}
+
+// Content returns the text of the final answer.
+func (r *Result) Content() string {
+	if r.Response == nil || len(r.Response.Choices) == 0 {
+		return ""
+	}
+	content, _ := r.Response.Choices[0].Message.Content.(string)
+	return content
+}
+
+// Run executes the tool-calling loop until the model produces an answer
+// without tool calls.
+//
+// messages is not modified.
+func (a *Agent) Run(ctx context.Context, messages []warp.Message) (*Result, error) {
+	conversation := append([]warp.Message(nil), messages...)
+
+	for round := 1; round <= a.maxRounds; round++ {
+		resp, err := a.client.Completion(ctx, a.newRequest(conversation))
+		if err != nil {
+			return nil, err
+		}
+		if len(resp.Choices) == 0 {
+			return nil, fmt.Errorf("agent: model returned no choices")
+		}
+
+		msg := resp.Choices[0].Message
+		if msg.Role == "" {
+			msg.Role = "assistant"
+		}
+		conversation = append(conversation, msg)
+
+		if len(msg.ToolCalls) == 0 {
+			return &Result{Response: resp, Messages: conversation, Rounds: round}, nil
+		}
+
+		for _, call := range msg.ToolCalls {
+			result, err := a.execute(ctx, call)
+			if err != nil {
+				return nil, err
+			}
+			conversation = append(conversation, result)
+		}
+	}
+
+	return nil, ErrMaxRounds
+}
+
+// newRequest builds the completion request for one round.
+func (a *Agent) newRequest(conversation []warp.Message) *warp.CompletionRequest {
+	req := a.request
+	req.Model = a.model
+	req.Messages = conversation
+	req.Tools = nil
+	for _, t := range a.tools {
+		req.Tools = append(req.Tools, warp.Tool{Type: "function", Function: t.Definition})
+	}
+	if a.registry != nil {
+		for _, t := range a.registry.Tools(FilterFromMetadata(a.request.Metadata)) {
+			if _, ok := a.byName[t.Definition.Name]; !ok {
+				req.Tools = append(req.Tools, warp.Tool{Type: "function", Function: t.Definition})
+			}
+		}
+	}
+	return &req
+}
+
+// execute runs a tool call and returns the tool result message.
+//
+// Tool errors and panics are reported to the model as the result. Returns an
+// error only for invalid arguments when validation retry is disabled.
+func (a *Agent) execute(ctx context.Context, call warp.ToolCall) (warp.Message, error) {
+	result := warp.Message{Role: "tool", ToolCallID: call.ID, Name: call.Function.Name}
+
+	tool, ok := a.lookup(call.Function.Name)
+	if !ok || tool.Func == nil {
+		result.Content = fmt.Sprintf("error: unknown tool %q", call.Function.Name)
+		return result, nil
+	}
+
+	if a.validate {
+		if err := ValidateArguments(tool.Definition.Parameters, call.Function.Arguments); err != nil {
+			var verr *ValidationError
+			if errors.As(err, &verr) {
+				verr.Tool = call.Function.Name
+			}
+			if !a.retryInvalid {
+				return warp.Message{}, err
+			}
+			result.Content = "error: " + err.Error() + ". Call the tool again with corrected arguments."
+			return result, nil
+		}
+	}
+
+	output, err := callTool(ctx, tool.Func, call.Function.Arguments)
+	if err != nil {
+		result.Content = "error: " + err.Error()
+		return result, nil
+	}
+	result.Content = output
+	return result, nil
+}
+
+// lookup finds a tool by name among the agent's tools and its registry.
+func (a *Agent) lookup(name string) (Tool, bool) {
+	if t, ok := a.byName[name]; ok {
+		return t, true
+	}
+	if a.registry != nil {
+		return a.registry.Lookup(name, FilterFromMetadata(a.request.Metadata))
+	}
+	return Tool{}, false
+}
+
+// callTool runs fn, converting a panic into an error.
+func callTool(ctx context.Context, fn ToolFunc, arguments string) (output string, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("tool panicked: %v", r)
+		}
+	}()
+	return fn(ctx, arguments)
+}
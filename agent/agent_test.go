@@ -0,0 +1,242 @@
+package agent
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/blue-context/warp"
+	"github.com/blue-context/warp/internal/testutil"
+)
+
+// newTestClient returns a client with mock registered as provider "mock".
+func newTestClient(t *testing.T, mock *testutil.MockProvider) warp.Client {
+	t.Helper()
+	client, err := warp.NewClient()
+	if err != nil {
+		t.Fatalf("NewClient() error = %v", err)
+	}
+	t.Cleanup(func() { client.Close() })
+	if err := client.RegisterProvider(mock); err != nil {
+		t.Fatalf("RegisterProvider() error = %v", err)
+	}
+	return client
+}
+
+// weatherTool returns a tool that records its arguments.
+func weatherTool(calls *[]string) Tool {
+	return Tool{
+		Definition: warp.Function{
+			Name:       "get_weather",
+			Parameters: map[string]any{"type": "object"},
+		},
+		Func: func(ctx context.Context, arguments string) (string, error) {
+			*calls = append(*calls, arguments)
+			return `{"temp_c": 21}`, nil
+		},
+	}
+}
+
+// toolCallResponse returns a response requesting a get_weather call.
+func toolCallResponse() *warp.CompletionResponse {
+	return &warp.CompletionResponse{
+		Choices: []warp.Choice{{
+			Message: warp.Message{
+				Role: "assistant",
+				ToolCalls: []warp.ToolCall{{
+					ID:       "call_1",
+					Type:     "function",
+					Function: warp.FunctionCall{Name: "get_weather", Arguments: `{"city":"Paris"}`},
+				}},
+			},
+			FinishReason: "tool_calls",
+		}},
+	}
+}
+
+// TestRun tests the non-streaming tool loop
+func TestRun(t *testing.T) {
+	var requests []*warp.CompletionRequest
+	mock := &testutil.MockProvider{
+		CompletionFunc: func(ctx context.Context, req *warp.CompletionRequest) (*warp.CompletionResponse, error) {
+			requests = append(requests, req)
+			if len(requests) == 1 {
+				return toolCallResponse(), nil
+			}
+			return &warp.CompletionResponse{
+				Choices: []warp.Choice{{Message: warp.Message{Role: "assistant", Content: "It's 21°C."}, FinishReason: "stop"}},
+			}, nil
+		},
+	}
+
+	var calls []string
+	a := New(newTestClient(t, mock), "mock/model", WithTools(weatherTool(&calls)))
+
+	messages := []warp.Message{{Role: "user", Content: "Weather in Paris?"}}
+	result, err := a.Run(context.Background(), messages)
+	if err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+
+	if result.Content() != "It's 21°C." {
+		t.Errorf("Content() = %q", result.Content())
+	}
+	if result.Rounds != 2 {
+		t.Errorf("Rounds = %d, want 2", result.Rounds)
+	}
+	if len(calls) != 1 || calls[0] != `{"city":"Paris"}` {
+		t.Errorf("tool calls = %v", calls)
+	}
+	if len(result.Messages) != 4 {
+		t.Fatalf("len(Messages) = %d, want 4", len(result.Messages))
+	}
+	if tool := result.Messages[2]; tool.Role != "tool" || tool.ToolCallID != "call_1" || tool.Content != `{"temp_c": 21}` {
+		t.Errorf("tool message = %+v", tool)
+	}
+	if len(requests[0].Tools) != 1 || requests[0].Tools[0].Function.Name != "get_weather" {
+		t.Errorf("request tools = %+v", requests[0].Tools)
+	}
+	if len(messages) != 1 {
+		t.Error("Run() modified the input messages")
+	}
+}
+
+// TestRunToolErrors tests that tool failures are reported to the model
+func TestRunToolErrors(t *testing.T) {
+	var second *warp.CompletionRequest
+	mock := &testutil.MockProvider{
+		CompletionFunc: func(ctx context.Context, req *warp.CompletionRequest) (*warp.CompletionResponse, error) {
+			if second == nil && len(req.Messages) == 1 {
+				resp := toolCallResponse()
+				resp.Choices[0].Message.ToolCalls = append(resp.Choices[0].Message.ToolCalls, warp.ToolCall{
+					ID: "call_2", Type: "function", Function: warp.FunctionCall{Name: "missing"},
+				})
+				return resp, nil
+			}
+			second = req
+			return &warp.CompletionResponse{Choices: []warp.Choice{{Message: warp.Message{Content: "ok"}}}}, nil
+		},
+	}
+
+	failing := Tool{
+		Definition: warp.Function{Name: "get_weather"},
+		Func: func(ctx context.Context, arguments string) (string, error) {
+			return "", errors.New("service down")
+		},
+	}
+	a := New(newTestClient(t, mock), "mock/model", WithTools(failing))
+
+	if _, err := a.Run(context.Background(), []warp.Message{{Role: "user", Content: "Hi"}}); err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+
+	if got := second.Messages[2].Content; got != "error: service down" {
+		t.Errorf("tool error result = %q", got)
+	}
+	if got := second.Messages[3].Content; got != `error: unknown tool "missing"` {
+		t.Errorf("unknown tool result = %q", got)
+	}
+}
+
+// TestRunMaxRounds tests the round limit
+func TestRunMaxRounds(t *testing.T) {
+	mock := &testutil.MockProvider{
+		CompletionFunc: func(ctx context.Context, req *warp.CompletionRequest) (*warp.CompletionResponse, error) {
+			return toolCallResponse(), nil
+		},
+	}
+
+	var calls []string
+	a := New(newTestClient(t, mock), "mock/model", WithTools(weatherTool(&calls)), WithMaxRounds(3))
+
+	_, err := a.Run(context.Background(), []warp.Message{{Role: "user", Content: "Hi"}})
+	if !errors.Is(err, ErrMaxRounds) {
+		t.Errorf("Run() error = %v, want ErrMaxRounds", err)
+	}
+	if mock.CompletionCalls != 3 {
+		t.Errorf("CompletionCalls = %d, want 3", mock.CompletionCalls)
+	}
+}
+
+// TestRunArgumentValidation tests that invalid arguments are reported to the model
+func TestRunArgumentValidation(t *testing.T) {
+	var requests []*warp.CompletionRequest
+	mock := &testutil.MockProvider{
+		CompletionFunc: func(ctx context.Context, req *warp.CompletionRequest) (*warp.CompletionResponse, error) {
+			requests = append(requests, req)
+			switch len(requests) {
+			case 1:
+				resp := toolCallResponse()
+				resp.Choices[0].Message.ToolCalls[0].Function.Arguments = `{"town":"Paris"}`
+				return resp, nil
+			case 2:
+				return toolCallResponse(), nil
+			}
+			return &warp.CompletionResponse{Choices: []warp.Choice{{Message: warp.Message{Content: "done"}}}}, nil
+		},
+	}
+
+	var calls []string
+	tool := weatherTool(&calls)
+	tool.Definition.Parameters = map[string]any{
+		"type":       "object",
+		"properties": map[string]any{"city": map[string]any{"type": "string"}},
+		"required":   []string{"city"},
+	}
+
+	a := New(newTestClient(t, mock), "mock/model", WithTools(tool))
+	result, err := a.Run(context.Background(), []warp.Message{{Role: "user", Content: "Weather?"}})
+	if err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+
+	if len(calls) != 1 || calls[0] != `{"city":"Paris"}` {
+		t.Errorf("tool calls = %v, want only the corrected call", calls)
+	}
+	if result.Rounds != 3 {
+		t.Errorf("Rounds = %d, want 3", result.Rounds)
+	}
+	if got, _ := requests[1].Messages[2].Content.(string); !strings.Contains(got, "$.city: is required") {
+		t.Errorf("validation result = %q", got)
+	}
+
+	// Without retry the run fails
+	requests = nil
+	a = New(newTestClient(t, mock), "mock/model", WithTools(tool), WithValidationRetry(false))
+	_, err = a.Run(context.Background(), []warp.Message{{Role: "user", Content: "Weather?"}})
+	var verr *ValidationError
+	if !errors.As(err, &verr) || verr.Tool != "get_weather" {
+		t.Errorf("Run() error = %v, want *ValidationError for get_weather", err)
+	}
+}
+
+// TestRunToolPanic tests that a panicking tool is reported instead of crashing
+func TestRunToolPanic(t *testing.T) {
+	var second *warp.CompletionRequest
+	mock := &testutil.MockProvider{
+		CompletionFunc: func(ctx context.Context, req *warp.CompletionRequest) (*warp.CompletionResponse, error) {
+			if len(req.Messages) == 1 {
+				return toolCallResponse(), nil
+			}
+			second = req
+			return &warp.CompletionResponse{Choices: []warp.Choice{{Message: warp.Message{Content: "ok"}}}}, nil
+		},
+	}
+
+	panicky := Tool{
+		Definition: warp.Function{Name: "get_weather"},
+		Func: func(ctx context.Context, arguments string) (string, error) {
+			var m map[string]string
+			m["x"] = "boom"
+			return "", nil
+		},
+	}
+	a := New(newTestClient(t, mock), "mock/model", WithTools(panicky))
+	if _, err := a.Run(context.Background(), []warp.Message{{Role: "user", Content: "Hi"}}); err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	if got, _ := second.Messages[2].Content.(string); !strings.HasPrefix(got, "error: tool panicked") {
+		t.Errorf("panic result = %q", got)
+	}
+}
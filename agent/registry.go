@@ -0,0 +1,385 @@
+package agent
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/blue-context/warp"
+	"github.com/blue-context/warp/token"
+)
+
+// NamespaceSeparator joins a tool set name and a tool name into the
+// qualified name the model sees, e.g. "github__create_issue".
+//
+// Function names are restricted to letters, digits, underscores, and dashes
+// by most providers, so a dot or slash cannot be used.
+const NamespaceSeparator = "__"
+
+// Request metadata keys read by FilterFromMetadata.
+const (
+	// MetadataToolSets restricts tools to the listed sets.
+	MetadataToolSets = "tool_sets"
+
+	// MetadataToolTags restricts tools to those with at least one listed tag.
+	MetadataToolTags = "tool_tags"
+
+	// MetadataPermissions lists the permissions held by the caller.
+	MetadataPermissions = "permissions"
+
+	// MetadataToolTokenBudget limits the tokens spent on tool definitions.
+	MetadataToolTokenBudget = "tool_token_budget"
+)
+
+// ToolFilter selects tools from a ToolRegistry.
+//
+// Zero values impose no restriction, except Permissions: a tool that
+// declares permissions is only selected when all of them are granted.
+type ToolFilter struct {
+	// Sets restricts tools to the named sets.
+	Sets []string
+
+	// Tags restricts tools to those with at least one of these tags.
+	Tags []string
+
+	// Permissions lists the permissions held by the caller.
+	Permissions []string
+
+	// MaxTokens prunes tool definitions to fit this token budget.
+	MaxTokens int
+}
+
+// FilterFromMetadata builds a ToolFilter from request metadata.
+//
+// List values may be []string, []any of strings, or a comma-separated
+// string. The budget may be any integer type, a float64 (as decoded from
+// JSON), or a numeric string.
+//
+// Example:
+//
+//	filter := agent.FilterFromMetadata(map[string]any{
+//	    agent.MetadataToolTags:    []string{"read"},
+//	    agent.MetadataPermissions: "repo:read,issues:write",
+//	})
+func FilterFromMetadata(metadata map[string]any) ToolFilter {
+	return ToolFilter{
+		Sets:        metadataList(metadata[MetadataToolSets]),
+		Tags:        metadataList(metadata[MetadataToolTags]),
+		Permissions: metadataList(metadata[MetadataPermissions]),
+		MaxTokens:   metadataInt(metadata[MetadataToolTokenBudget]),
+	}
+}
+
+// ToolRegistry holds named tool sets that can be enabled, disabled, and
+// filtered per request.
+//
+// Tools registered in a set are exposed to the model under qualified names
+// (set + NamespaceSeparator + tool name), so sets from different sources
+// cannot collide. Tools registered with an empty set name keep their names.
+//
+// Thread Safety: ToolRegistry is safe for concurrent use. Tools may be
+// registered, enabled, or disabled while agents are running; changes apply
+// from the next model call.
+type ToolRegistry struct {
+	mu       sync.RWMutex
+	sets     map[string]*toolSet
+	order    []string
+	byName   map[string]registeredTool
+	disabled map[string]bool // Disabled set and qualified tool names
+	counter  token.Counter
+	seq      int
+}
+
+// toolSet is a named group of tools.
+type toolSet struct {
+	names []string // Qualified names in registration order
+}
+
+// registeredTool is a tool with its qualified name and owning set.
+type registeredTool struct {
+	tool Tool
+	set  string
+	seq  int // Unique registration sequence number
+}
+
+// RegistryOption configures a ToolRegistry.
+type RegistryOption func(*ToolRegistry)
+
+// WithRegistryCounter sets the token counter used to measure tool
+// definitions when pruning to a budget.
+//
+// The default is token.NewCounter().
+func WithRegistryCounter(counter token.Counter) RegistryOption {
+	return func(r *ToolRegistry) {
+		r.counter = counter
+	}
+}
+
+// NewToolRegistry creates an empty ToolRegistry.
+//
+// Example:
+//
+//	registry := agent.NewToolRegistry()
+//	registry.Register("github", createIssue, listPulls)
+//	registry.Register("fs", readFile)
+//	registry.Disable("fs")
+func NewToolRegistry(opts ...RegistryOption) *ToolRegistry {
+	r := &ToolRegistry{
+		sets:     make(map[string]*toolSet),
+		byName:   make(map[string]registeredTool),
+		disabled: make(map[string]bool),
+		counter:  token.NewCounter(),
+	}
+
+	for _, opt := range opts {
+		opt(r)
+	}
+
+	return r
+}
+
+// Register adds tools to the named set, creating it if needed.
+//
+// Each tool's Definition.Name is qualified with the set name. Returns an
+// error if a tool has no name or function, or if its qualified name is
+// already registered; in that case no tools are added.
+func (r *ToolRegistry) Register(set string, tools ...Tool) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	pending := make(map[string]bool, len(tools))
+	for _, t := range tools {
+		if t.Definition.Name == "" {
+			return fmt.Errorf("tool name cannot be empty")
+		}
+		if t.Func == nil {
+			return fmt.Errorf("tool %q has no function", t.Definition.Name)
+		}
+		name := qualifiedName(set, t.Definition.Name)
+		if _, exists := r.byName[name]; exists || pending[name] {
+			return fmt.Errorf("tool %q already registered", name)
+		}
+		pending[name] = true
+	}
+
+	s, ok := r.sets[set]
+	if !ok {
+		s = &toolSet{}
+		r.sets[set] = s
+		r.order = append(r.order, set)
+	}
+
+	for _, t := range tools {
+		name := qualifiedName(set, t.Definition.Name)
+		t.Definition.Name = name
+		r.seq++
+		r.byName[name] = registeredTool{tool: t, set: set, seq: r.seq}
+		s.names = append(s.names, name)
+	}
+	return nil
+}
+
+// Unregister removes a set and all its tools.
+//
+// Returns an error if the set does not exist.
+func (r *ToolRegistry) Unregister(set string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	s, ok := r.sets[set]
+	if !ok {
+		return fmt.Errorf("tool set %q not found", set)
+	}
+	for _, name := range s.names {
+		delete(r.byName, name)
+		delete(r.disabled, name)
+	}
+	delete(r.sets, set)
+	delete(r.disabled, setKey(set))
+	r.order = slices.DeleteFunc(r.order, func(name string) bool { return name == set })
+	return nil
+}
+
+// Enable re-enables a set or a single tool by qualified name.
+//
+// Returns an error if no set or tool has that name.
+func (r *ToolRegistry) Enable(name string) error {
+	return r.setDisabled(name, false)
+}
+
+// Disable hides a set or a single tool (by qualified name) from all
+// requests until it is enabled again.
+//
+// Returns an error if no set or tool has that name.
+func (r *ToolRegistry) Disable(name string) error {
+	return r.setDisabled(name, true)
+}
+
+// setDisabled updates the disabled state of a set or tool.
+func (r *ToolRegistry) setDisabled(name string, disabled bool) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	key := name
+	if _, ok := r.sets[name]; ok {
+		key = setKey(name)
+	} else if _, ok := r.byName[name]; !ok {
+		return fmt.Errorf("tool or tool set %q not found", name)
+	}
+
+	if disabled {
+		r.disabled[key] = true
+	} else {
+		delete(r.disabled, key)
+	}
+	return nil
+}
+
+// Lookup returns the enabled tool with the given qualified name if filter
+// permits it. The filter's token budget is not applied.
+func (r *ToolRegistry) Lookup(name string, filter ToolFilter) (Tool, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	rt, ok := r.byName[name]
+	if !ok || !r.allowed(rt, filter) {
+		return Tool{}, false
+	}
+	return rt.tool, true
+}
+
+// Tools returns the enabled tools permitted by filter, grouped by set in
+// the order sets were created.
+//
+// When filter.MaxTokens is set, tools are admitted in descending Priority
+// (then registration order) while their definitions fit the budget; a tool
+// that does not fit is skipped so smaller ones can still be included.
+func (r *ToolRegistry) Tools(filter ToolFilter) []Tool {
+	r.mu.RLock()
+	var selected []registeredTool
+	for _, set := range r.order {
+		for _, name := range r.sets[set].names {
+			if rt := r.byName[name]; r.allowed(rt, filter) {
+				selected = append(selected, rt)
+			}
+		}
+	}
+	r.mu.RUnlock()
+
+	if filter.MaxTokens > 0 {
+		selected = r.prune(selected, filter.MaxTokens)
+	}
+
+	tools := make([]Tool, len(selected))
+	for i, rt := range selected {
+		tools[i] = rt.tool
+	}
+	return tools
+}
+
+// allowed reports whether a tool is enabled and passes filter.
+//
+// The caller must hold r.mu.
+func (r *ToolRegistry) allowed(rt registeredTool, filter ToolFilter) bool {
+	if r.disabled[setKey(rt.set)] || r.disabled[rt.tool.Definition.Name] {
+		return false
+	}
+	if len(filter.Sets) > 0 && !slices.Contains(filter.Sets, rt.set) {
+		return false
+	}
+	if len(filter.Tags) > 0 && !slices.ContainsFunc(rt.tool.Tags, func(tag string) bool {
+		return slices.Contains(filter.Tags, tag)
+	}) {
+		return false
+	}
+	for _, perm := range rt.tool.Permissions {
+		if !slices.Contains(filter.Permissions, perm) {
+			return false
+		}
+	}
+	return true
+}
+
+// prune keeps the highest-priority tools whose definitions fit budget,
+// preserving the input order in the result.
+func (r *ToolRegistry) prune(tools []registeredTool, budget int) []registeredTool {
+	ranked := slices.Clone(tools)
+	sort.SliceStable(ranked, func(i, j int) bool {
+		return ranked[i].tool.Priority > ranked[j].tool.Priority
+	})
+
+	keep := make(map[int]bool, len(ranked))
+	used := 0
+	for _, rt := range ranked {
+		cost := r.counter.CountRequest(&warp.CompletionRequest{
+			Tools: []warp.Tool{{Type: "function", Function: rt.tool.Definition}},
+		})
+		if used+cost > budget {
+			continue
+		}
+		used += cost
+		keep[rt.seq] = true
+	}
+
+	return slices.DeleteFunc(tools, func(rt registeredTool) bool { return !keep[rt.seq] })
+}
+
+// qualifiedName returns the name a tool in set is exposed under.
+func qualifiedName(set, name string) string {
+	if set == "" {
+		return name
+	}
+	return set + NamespaceSeparator + name
+}
+
+// setKey returns the disabled-map key for a set, distinct from tool names.
+func setKey(set string) string {
+	return "set:" + set
+}
+
+// metadataList converts a metadata value to a list of strings.
+func metadataList(v any) []string {
+	switch val := v.(type) {
+	case []string:
+		return val
+	case []any:
+		out := make([]string, 0, len(val))
+		for _, item := range val {
+			if s, ok := item.(string); ok {
+				out = append(out, s)
+			}
+		}
+		return out
+	case string:
+		var out []string
+		for _, s := range strings.Split(val, ",") {
+			if s = strings.TrimSpace(s); s != "" {
+				out = append(out, s)
+			}
+		}
+		return out
+	}
+	return nil
+}
+
+// metadataInt converts a metadata value to an int, returning 0 if it is not
+// numeric.
+func metadataInt(v any) int {
+	switch val := v.(type) {
+	case int:
+		return val
+	case int32:
+		return int(val)
+	case int64:
+		return int(val)
+	case float64:
+		return int(val)
+	case string:
+		n, _ := strconv.Atoi(strings.TrimSpace(val))
+		return n
+	}
+	return 0
+}
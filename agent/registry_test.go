@@ -0,0 +1,223 @@
+package agent
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/blue-context/warp"
+	"github.com/blue-context/warp/internal/testutil"
+)
+
+// namedTool returns a no-op tool with the given name and labels.
+func namedTool(name string, tags, permissions []string) Tool {
+	return Tool{
+		Definition:  warp.Function{Name: name, Description: "Tool " + name},
+		Func:        func(ctx context.Context, arguments string) (string, error) { return name, nil },
+		Tags:        tags,
+		Permissions: permissions,
+	}
+}
+
+// toolNames returns the definition names of tools.
+func toolNames(tools []Tool) string {
+	names := make([]string, len(tools))
+	for i, t := range tools {
+		names[i] = t.Definition.Name
+	}
+	return strings.Join(names, ",")
+}
+
+// newTestRegistry returns a registry with "github" and "fs" tool sets.
+func newTestRegistry(t *testing.T) *ToolRegistry {
+	t.Helper()
+	r := NewToolRegistry()
+	if err := r.Register("github",
+		namedTool("list_issues", []string{"read"}, nil),
+		namedTool("create_issue", []string{"write"}, []string{"issues:write"}),
+	); err != nil {
+		t.Fatalf("Register() error = %v", err)
+	}
+	if err := r.Register("fs", namedTool("read_file", []string{"read"}, nil)); err != nil {
+		t.Fatalf("Register() error = %v", err)
+	}
+	return r
+}
+
+// TestToolRegistryFilter tests set, tag, and permission filtering
+func TestToolRegistryFilter(t *testing.T) {
+	r := newTestRegistry(t)
+
+	tests := []struct {
+		name   string
+		filter ToolFilter
+		want   string
+	}{
+		{name: "no permissions", want: "github__list_issues,fs__read_file"},
+		{name: "permission granted", filter: ToolFilter{Permissions: []string{"issues:write"}}, want: "github__list_issues,github__create_issue,fs__read_file"},
+		{name: "set", filter: ToolFilter{Sets: []string{"fs"}}, want: "fs__read_file"},
+		{name: "tag", filter: ToolFilter{Tags: []string{"write"}, Permissions: []string{"issues:write"}}, want: "github__create_issue"},
+		{name: "no match", filter: ToolFilter{Tags: []string{"admin"}}, want: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := toolNames(r.Tools(tt.filter)); got != tt.want {
+				t.Errorf("Tools() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+
+	if _, ok := r.Lookup("github__create_issue", ToolFilter{}); ok {
+		t.Error("Lookup() returned a tool without its permission")
+	}
+	if _, ok := r.Lookup("github__create_issue", ToolFilter{Permissions: []string{"issues:write"}}); !ok {
+		t.Error("Lookup() did not find a permitted tool")
+	}
+}
+
+// TestToolRegistryEnableDisable tests toggling sets and tools
+func TestToolRegistryEnableDisable(t *testing.T) {
+	r := newTestRegistry(t)
+
+	if err := r.Disable("github"); err != nil {
+		t.Fatalf("Disable() error = %v", err)
+	}
+	if got := toolNames(r.Tools(ToolFilter{})); got != "fs__read_file" {
+		t.Errorf("after disabling set, Tools() = %q", got)
+	}
+	if _, ok := r.Lookup("github__list_issues", ToolFilter{}); ok {
+		t.Error("Lookup() returned a tool from a disabled set")
+	}
+
+	if err := r.Enable("github"); err != nil {
+		t.Fatalf("Enable() error = %v", err)
+	}
+	if err := r.Disable("fs__read_file"); err != nil {
+		t.Fatalf("Disable() error = %v", err)
+	}
+	if got := toolNames(r.Tools(ToolFilter{})); got != "github__list_issues" {
+		t.Errorf("after disabling tool, Tools() = %q", got)
+	}
+
+	if err := r.Disable("unknown"); err == nil {
+		t.Error("Disable() of unknown name succeeded")
+	}
+
+	if err := r.Unregister("fs"); err != nil {
+		t.Fatalf("Unregister() error = %v", err)
+	}
+	if err := r.Register("fs", namedTool("read_file", nil, nil)); err != nil {
+		t.Fatalf("re-Register() error = %v", err)
+	}
+	if got := toolNames(r.Tools(ToolFilter{})); got != "github__list_issues,fs__read_file" {
+		t.Errorf("after re-registering, Tools() = %q", got)
+	}
+}
+
+// TestToolRegistryRegisterErrors tests registration validation
+func TestToolRegistryRegisterErrors(t *testing.T) {
+	r := newTestRegistry(t)
+
+	if err := r.Register("fs", namedTool("write_file", nil, nil), namedTool("read_file", nil, nil)); err == nil {
+		t.Error("Register() of duplicate name succeeded")
+	}
+	if _, ok := r.Lookup("fs__write_file", ToolFilter{}); ok {
+		t.Error("failed Register() added tools")
+	}
+	if err := r.Register("fs", Tool{Definition: warp.Function{Name: "no_func"}}); err == nil {
+		t.Error("Register() of tool without function succeeded")
+	}
+	if err := r.Register("", namedTool("plain", nil, nil)); err != nil {
+		t.Fatalf("Register() without set error = %v", err)
+	}
+	if _, ok := r.Lookup("plain", ToolFilter{}); !ok {
+		t.Error("tool without set was namespaced")
+	}
+}
+
+// TestToolRegistryBudget tests pruning tool definitions to a token budget
+func TestToolRegistryBudget(t *testing.T) {
+	r := NewToolRegistry()
+	low := namedTool("low", nil, nil)
+	high := namedTool("high", nil, nil)
+	high.Priority = 10
+	if err := r.Register("", low, high); err != nil {
+		t.Fatalf("Register() error = %v", err)
+	}
+
+	all := r.Tools(ToolFilter{})
+	one := r.counter.CountRequest(&warp.CompletionRequest{Tools: []warp.Tool{{Type: "function", Function: all[0].Definition}}})
+
+	if got := toolNames(r.Tools(ToolFilter{MaxTokens: one})); got != "high" {
+		t.Errorf("Tools() with budget for one = %q, want high", got)
+	}
+	if got := toolNames(r.Tools(ToolFilter{MaxTokens: 10 * one})); got != "low,high" {
+		t.Errorf("Tools() with large budget = %q, want low,high", got)
+	}
+}
+
+// TestFilterFromMetadata tests metadata parsing
+func TestFilterFromMetadata(t *testing.T) {
+	f := FilterFromMetadata(map[string]any{
+		MetadataToolSets:        []any{"github", 1},
+		MetadataToolTags:        "read, write",
+		MetadataPermissions:     []string{"issues:write"},
+		MetadataToolTokenBudget: float64(500),
+	})
+
+	if strings.Join(f.Sets, ",") != "github" {
+		t.Errorf("Sets = %v", f.Sets)
+	}
+	if strings.Join(f.Tags, ",") != "read,write" {
+		t.Errorf("Tags = %v", f.Tags)
+	}
+	if strings.Join(f.Permissions, ",") != "issues:write" {
+		t.Errorf("Permissions = %v", f.Permissions)
+	}
+	if f.MaxTokens != 500 {
+		t.Errorf("MaxTokens = %d", f.MaxTokens)
+	}
+}
+
+// TestRunWithToolRegistry tests that agents offer and enforce registry filters
+func TestRunWithToolRegistry(t *testing.T) {
+	var requests []*warp.CompletionRequest
+	mock := &testutil.MockProvider{
+		CompletionFunc: func(ctx context.Context, req *warp.CompletionRequest) (*warp.CompletionResponse, error) {
+			requests = append(requests, req)
+			if len(requests) == 1 {
+				return &warp.CompletionResponse{Choices: []warp.Choice{{Message: warp.Message{
+					Role: "assistant",
+					ToolCalls: []warp.ToolCall{
+						{ID: "call_1", Type: "function", Function: warp.FunctionCall{Name: "fs__read_file", Arguments: "{}"}},
+						{ID: "call_2", Type: "function", Function: warp.FunctionCall{Name: "github__create_issue", Arguments: "{}"}},
+					},
+				}}}}, nil
+			}
+			return &warp.CompletionResponse{Choices: []warp.Choice{{Message: warp.Message{Content: "done"}}}}, nil
+		},
+	}
+
+	a := New(newTestClient(t, mock), "mock/model",
+		WithToolRegistry(newTestRegistry(t)),
+		WithRequest(warp.CompletionRequest{Metadata: map[string]any{MetadataToolTags: "read"}}),
+	)
+	if _, err := a.Run(context.Background(), []warp.Message{{Role: "user", Content: "Hi"}}); err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+
+	var offered []string
+	for _, tool := range requests[0].Tools {
+		offered = append(offered, tool.Function.Name)
+	}
+	if got := strings.Join(offered, ","); got != "github__list_issues,fs__read_file" {
+		t.Errorf("offered tools = %q", got)
+	}
+	if got := requests[1].Messages[2].Content; got != "read_file" {
+		t.Errorf("permitted tool result = %q", got)
+	}
+	if got := requests[1].Messages[3].Content; got != `error: unknown tool "github__create_issue"` {
+		t.Errorf("filtered tool result = %q", got)
+	}
+}
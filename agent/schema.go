@@ -0,0 +1,47 @@
+package agent
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/blue-context/warp/internal/jsonschema"
+)
+
+// ValidationError reports tool arguments that do not match the tool's
+// parameter schema.
+type ValidationError struct {
+	// Tool is the name of the tool that was called.
+	Tool string
+
+	// Problems lists each mismatch as "path: message".
+	Problems []string
+}
+
+// Error implements the error interface.
+func (e *ValidationError) Error() string {
+	return fmt.Sprintf("invalid arguments for tool %q: %s", e.Tool, strings.Join(e.Problems, "; "))
+}
+
+// ValidateArguments checks JSON-encoded arguments against a JSON Schema.
+//
+// It supports the subset of JSON Schema used for function parameters:
+// type, properties, required, additionalProperties (as a boolean), items,
+// enum, const, minimum, maximum, minLength, maxLength, minItems, and
+// maxItems. Unsupported keywords are ignored. An empty or nil schema
+// accepts any JSON object.
+//
+// Returns a *ValidationError describing every mismatch found.
+//
+// Example:
+//
+//	err := agent.ValidateArguments(tool.Definition.Parameters, call.Function.Arguments)
+func ValidateArguments(schema map[string]any, arguments string) error {
+	if strings.TrimSpace(arguments) == "" {
+		arguments = "{}"
+	}
+
+	if problems := jsonschema.Validate(schema, arguments); len(problems) > 0 {
+		return &ValidationError{Problems: problems}
+	}
+	return nil
+}
@@ -0,0 +1,72 @@
+package agent
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+// TestValidateArguments tests schema validation of tool arguments
+func TestValidateArguments(t *testing.T) {
+	schema := map[string]any{
+		"type": "object",
+		"properties": map[string]any{
+			"city":  map[string]any{"type": "string", "minLength": 2},
+			"unit":  map[string]any{"type": "string", "enum": []string{"celsius", "fahrenheit"}},
+			"days":  map[string]any{"type": "integer", "minimum": 1, "maximum": 7},
+			"tags":  map[string]any{"type": "array", "items": map[string]any{"type": "string"}, "maxItems": 2},
+			"ratio": map[string]any{"type": "number"},
+		},
+		"required":             []string{"city"},
+		"additionalProperties": false,
+	}
+
+	tests := []struct {
+		name      string
+		arguments string
+		wantErr   string
+	}{
+		{name: "valid", arguments: `{"city":"Paris","unit":"celsius","days":3,"tags":["a"],"ratio":0.5}`},
+		{name: "integer accepted as number", arguments: `{"city":"Paris","ratio":1}`},
+		{name: "missing required", arguments: `{"unit":"celsius"}`, wantErr: "$.city: is required"},
+		{name: "wrong type", arguments: `{"city":42}`, wantErr: "$.city: expected string, got integer"},
+		{name: "enum", arguments: `{"city":"Paris","unit":"kelvin"}`, wantErr: "$.unit: must be one of"},
+		{name: "float for integer", arguments: `{"city":"Paris","days":2.5}`, wantErr: "$.days: expected integer, got number"},
+		{name: "maximum", arguments: `{"city":"Paris","days":9}`, wantErr: "$.days: must be <= 7"},
+		{name: "min length", arguments: `{"city":"P"}`, wantErr: "$.city: must be at least 2 characters"},
+		{name: "array items", arguments: `{"city":"Paris","tags":["a",1]}`, wantErr: "$.tags[1]: expected string"},
+		{name: "max items", arguments: `{"city":"Paris","tags":["a","b","c"]}`, wantErr: "$.tags: must have at most 2 items"},
+		{name: "additional property", arguments: `{"city":"Paris","extra":true}`, wantErr: "$.extra: is not an allowed property"},
+		{name: "malformed JSON", arguments: `{"city":`, wantErr: "not valid JSON"},
+		{name: "not an object", arguments: `["Paris"]`, wantErr: "$: expected object, got array"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateArguments(schema, tt.arguments)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("ValidateArguments() error = %v, want nil", err)
+				}
+				return
+			}
+			var verr *ValidationError
+			if !errors.As(err, &verr) {
+				t.Fatalf("ValidateArguments() error = %v, want *ValidationError", err)
+			}
+			if !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("ValidateArguments() error = %q, want containing %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+// TestValidateArgumentsEmptySchema tests that an empty schema accepts any object
+func TestValidateArgumentsEmptySchema(t *testing.T) {
+	if err := ValidateArguments(nil, ""); err != nil {
+		t.Errorf("ValidateArguments(nil, \"\") error = %v", err)
+	}
+	if err := ValidateArguments(nil, `{"any":1}`); err != nil {
+		t.Errorf("ValidateArguments(nil, object) error = %v", err)
+	}
+}
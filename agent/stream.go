@@ -0,0 +1,284 @@
+package agent
+
+import (
+	"context"
+	"io"
+	"slices"
+
+	"github.com/blue-context/warp"
+)
+
+// Synthetic chunk Object values marking tool execution boundaries in a
+// Stream returned by RunStream.
+const (
+	// EventToolCall precedes the execution of a tool call. The call is in
+	// Choices[0].Delta.ToolCalls.
+	EventToolCall = "agent.tool_call"
+
+	// EventToolResult follows the execution of a tool call. The call is in
+	// Choices[0].Delta.ToolCalls; the result message is the last entry of
+	// Stream.Messages().
+	EventToolResult = "agent.tool_result"
+)
+
+// IsToolEvent reports whether chunk is a synthetic tool execution event
+// rather than model output.
+func IsToolEvent(chunk *warp.CompletionChunk) bool {
+	return chunk != nil && (chunk.Object == EventToolCall || chunk.Object == EventToolResult)
+}
+
+// Stream is the combined stream of an agent run.
+//
+// It yields the model's output of the final round, ending with the model's
+// finish reason; io.EOF follows it. Rounds that end in tool calls are
+// handled internally: their tool calls are assembled, executed, and
+// surfaced as EventToolCall and EventToolResult chunks, and their output is
+// dropped. Because a round is only known to be final when it ends, its
+// chunks are held back until then, unless the agent was created with
+// WithIntermediateOutput or has no tools to call; the output of every round
+// then streams as it arrives, with tool-call deltas held back.
+//
+// Tools run synchronously inside Recv, so a slow tool delays the next chunk.
+//
+// Thread Safety: Stream is NOT safe for concurrent use.
+type Stream struct {
+	agent        *Agent
+	ctx          context.Context
+	conversation []warp.Message
+	round        int
+
+	current warp.Stream
+	acc     *warp.StreamAccumulator // Deltas of the current round
+
+	hold      bool                    // Whether to hold back the current round
+	toolRound bool                    // Whether the current round calls tools
+	held      []*warp.CompletionChunk // Held back chunks of the current round
+	pending   []*warp.CompletionChunk // Chunks of the final round to forward
+
+	queue   []warp.ToolCall // Tool calls awaiting execution
+	started bool            // Whether EventToolCall was sent for queue[0]
+
+	err error
+}
+
+// Compile-time interface check
+var _ warp.Stream = (*Stream)(nil)
+
+// RunStream executes the tool-calling loop, streaming the final answer.
+//
+// messages is not modified. The caller must close the returned Stream.
+//
+// Example:
+//
+//	stream, err := a.RunStream(ctx, messages)
+//	if err != nil {
+//	    return err
+//	}
+//	defer stream.Close()
+//
+//	for {
+//	    chunk, err := stream.Recv()
+//	    if err == io.EOF {
+//	        break
+//	    }
+//	    if err != nil {
+//	        return err
+//	    }
+//	    if agent.IsToolEvent(chunk) {
+//	        if chunk.Object == agent.EventToolCall {
+//	            fmt.Printf("\n[calling %s]\n", chunk.Choices[0].Delta.ToolCalls[0].Function.Name)
+//	        }
+//	        continue
+//	    }
+//	    fmt.Print(chunk.Choices[0].Delta.Content)
+//	}
+func (a *Agent) RunStream(ctx context.Context, messages []warp.Message) (*Stream, error) {
+	s := &Stream{
+		agent:        a,
+		ctx:          ctx,
+		conversation: append([]warp.Message(nil), messages...),
+	}
+	// Start the first round eagerly so request errors surface here
+	if err := s.nextRound(); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// Messages returns the conversation so far, including tool calls and
+// results. After io.EOF it includes the final answer.
+func (s *Stream) Messages() []warp.Message {
+	return s.conversation
+}
+
+// Recv returns the next model chunk or tool event.
+func (s *Stream) Recv() (*warp.CompletionChunk, error) {
+	for {
+		if len(s.pending) > 0 {
+			chunk := s.pending[0]
+			s.pending = s.pending[1:]
+			return chunk, nil
+		}
+		if s.err != nil {
+			return nil, s.err
+		}
+
+		if len(s.queue) > 0 {
+			return s.nextToolEvent()
+		}
+
+		if s.current == nil {
+			if err := s.nextRound(); err != nil {
+				s.err = err
+				return nil, err
+			}
+		}
+
+		chunk, err := s.current.Recv()
+		if err == io.EOF {
+			s.finishRound()
+			continue
+		}
+		if err != nil {
+			s.err = err
+			return nil, err
+		}
+
+		out := s.accumulate(chunk)
+		if out == nil {
+			continue
+		}
+		if s.hold {
+			if !s.toolRound {
+				s.held = append(s.held, out)
+			}
+			continue
+		}
+		return out, nil
+	}
+}
+
+// nextRound starts streaming the next model call.
+func (s *Stream) nextRound() error {
+	if s.round >= s.agent.maxRounds {
+		return ErrMaxRounds
+	}
+	s.round++
+
+	req := s.agent.newRequest(s.conversation)
+	stream, err := s.agent.client.CompletionStream(s.ctx, req)
+	if err != nil {
+		return err
+	}
+	s.current = stream
+	s.acc = warp.NewStreamAccumulator()
+	s.hold = !s.agent.intermediate && len(req.Tools) > 0
+	s.toolRound = false
+	s.held = nil
+	return nil
+}
+
+// accumulate records a chunk and returns the part to forward, or nil if
+// nothing remains after holding back tool-call deltas.
+func (s *Stream) accumulate(chunk *warp.CompletionChunk) *warp.CompletionChunk {
+	s.acc.Add(chunk)
+
+	// The conversation continues with the choice with index 0; other
+	// choices of an N > 1 request are forwarded as they are
+	i := slices.IndexFunc(chunk.Choices, func(c warp.ChunkChoice) bool { return c.Index == 0 })
+	if i < 0 {
+		if chunk.Usage != nil || len(chunk.Choices) > 0 {
+			return chunk
+		}
+		return nil
+	}
+
+	choice := chunk.Choices[i]
+	if len(choice.Delta.ToolCalls) == 0 && (choice.FinishReason == nil || *choice.FinishReason != "tool_calls") {
+		return chunk
+	}
+
+	// The round calls tools, so nothing it held back is forwarded
+	s.toolRound = true
+	s.held = nil
+
+	// Strip tool-call parts; forward any remaining text
+	choice.Delta.ToolCalls = nil
+	if choice.FinishReason != nil && *choice.FinishReason == "tool_calls" {
+		choice.FinishReason = nil
+	}
+	if choice.Delta.Content == "" && choice.Delta.ReasoningContent == "" && choice.FinishReason == nil && chunk.Usage == nil && len(chunk.Choices) == 1 {
+		return nil
+	}
+	out := *chunk
+	out.Choices = slices.Clone(chunk.Choices)
+	out.Choices[i] = choice
+	return &out
+}
+
+// finishRound records the assistant turn and queues its tool calls.
+func (s *Stream) finishRound() {
+	s.current.Close()
+	s.current = nil
+
+	msg := warp.Message{Role: "assistant", Content: ""}
+	if resp := s.acc.Response(); len(resp.Choices) > 0 && resp.Choices[0].Index == 0 {
+		msg = resp.Choices[0].Message
+	}
+	s.conversation = append(s.conversation, msg)
+
+	held := s.held
+	s.held = nil
+	if len(msg.ToolCalls) == 0 {
+		s.pending = held
+		s.err = io.EOF
+		return
+	}
+	s.queue = append(s.queue, msg.ToolCalls...)
+}
+
+// nextToolEvent announces or executes the next queued tool call.
+func (s *Stream) nextToolEvent() (*warp.CompletionChunk, error) {
+	call := s.queue[0]
+
+	event := &warp.CompletionChunk{
+		Object: EventToolCall,
+		Model:  s.agent.model,
+		Choices: []warp.ChunkChoice{{
+			Delta: warp.MessageDelta{Role: "assistant", ToolCalls: []warp.ToolCall{call}},
+		}},
+	}
+
+	if !s.started {
+		s.started = true
+		return event, nil
+	}
+
+	result, err := s.agent.execute(s.ctx, call)
+	if err != nil {
+		s.err = err
+		return nil, err
+	}
+	s.conversation = append(s.conversation, result)
+	s.queue = s.queue[1:]
+	s.started = false
+
+	event.Object = EventToolResult
+	event.Choices[0].Delta.Role = "tool"
+	return event, nil
+}
+
+// Close closes the in-flight model stream. It is safe to call Close
+// multiple times.
+func (s *Stream) Close() error {
+	s.pending = nil
+	if s.err == nil {
+		s.err = io.EOF
+	}
+	if s.current == nil {
+		return nil
+	}
+	err := s.current.Close()
+	s.current = nil
+	return err
+}
@@ -0,0 +1,164 @@
+package agent
+
+import (
+	"context"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/blue-context/warp"
+	"github.com/blue-context/warp/internal/testutil"
+)
+
+// textChunk returns a chunk with a content delta.
+func textChunk(content string, finish string) *warp.CompletionChunk {
+	choice := warp.ChunkChoice{Delta: warp.MessageDelta{Content: content}}
+	if finish != "" {
+		choice.FinishReason = &finish
+	}
+	return &warp.CompletionChunk{Object: "chat.completion.chunk", Choices: []warp.ChunkChoice{choice}}
+}
+
+// toolChunk returns a chunk with a tool call delta.
+func toolChunk(id, name, arguments string) *warp.CompletionChunk {
+	return &warp.CompletionChunk{Choices: []warp.ChunkChoice{{
+		Delta: warp.MessageDelta{ToolCalls: []warp.ToolCall{{ID: id, Type: "function", Function: warp.FunctionCall{Name: name, Arguments: arguments}}}},
+	}}}
+}
+
+// TestRunStream tests streaming with a non-streamed tool round
+func TestRunStream(t *testing.T) {
+	tests := []struct {
+		name     string
+		opts     []Option
+		wantText string
+	}{
+		{"final round only", nil, "It's 21°C."},
+		{"intermediate output", []Option{WithIntermediateOutput(true)}, "Checking. It's 21°C."},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			round := 0
+			mock := &testutil.MockProvider{
+				CompletionStreamFunc: func(ctx context.Context, req *warp.CompletionRequest) (warp.Stream, error) {
+					round++
+					if round == 1 {
+						return testutil.NewMockStream(
+							textChunk("Checking. ", ""),
+							toolChunk("call_1", "get_weather", `{"ci`),
+							toolChunk("", "", `ty":"Paris"}`),
+							textChunk("", "tool_calls"),
+						), nil
+					}
+					return testutil.NewMockStream(
+						textChunk("It's ", ""),
+						textChunk("21°C.", "stop"),
+					), nil
+				},
+			}
+
+			var calls []string
+			a := New(newTestClient(t, mock), "mock/model", append([]Option{WithTools(weatherTool(&calls))}, tt.opts...)...)
+
+			stream, err := a.RunStream(context.Background(), []warp.Message{{Role: "user", Content: "Weather in Paris?"}})
+			if err != nil {
+				t.Fatalf("RunStream() error = %v", err)
+			}
+			defer stream.Close()
+
+			var text strings.Builder
+			var events []string
+			var finish string
+			for {
+				chunk, err := stream.Recv()
+				if err == io.EOF {
+					break
+				}
+				if err != nil {
+					t.Fatalf("Recv() error = %v", err)
+				}
+				if IsToolEvent(chunk) {
+					events = append(events, chunk.Object+":"+chunk.Choices[0].Delta.ToolCalls[0].Function.Name)
+					continue
+				}
+				if len(chunk.Choices[0].Delta.ToolCalls) > 0 {
+					t.Error("tool call delta forwarded to caller")
+				}
+				text.WriteString(chunk.Choices[0].Delta.Content)
+				if chunk.Choices[0].FinishReason != nil {
+					finish = *chunk.Choices[0].FinishReason
+				}
+			}
+
+			if text.String() != tt.wantText {
+				t.Errorf("text = %q, want %q", text.String(), tt.wantText)
+			}
+			if finish != "stop" {
+				t.Errorf("finish reason = %q, want stop", finish)
+			}
+			want := []string{EventToolCall + ":get_weather", EventToolResult + ":get_weather"}
+			if strings.Join(events, ",") != strings.Join(want, ",") {
+				t.Errorf("events = %v, want %v", events, want)
+			}
+			if len(calls) != 1 || calls[0] != `{"city":"Paris"}` {
+				t.Errorf("tool arguments = %v, want assembled arguments", calls)
+			}
+
+			messages := stream.Messages()
+			if len(messages) != 4 {
+				t.Fatalf("len(Messages()) = %d, want 4", len(messages))
+			}
+			if messages[1].Content != "Checking. " {
+				t.Errorf("tool round message = %q, want its text kept in the conversation", messages[1].Content)
+			}
+			if messages[3].Content != "It's 21°C." {
+				t.Errorf("final message = %q", messages[3].Content)
+			}
+		})
+	}
+}
+
+// TestRunStreamToolCallWithoutID tests that a tool call streamed without an
+// ID is given one that its result refers to
+func TestRunStreamToolCallWithoutID(t *testing.T) {
+	round := 0
+	mock := &testutil.MockProvider{
+		CompletionStreamFunc: func(ctx context.Context, req *warp.CompletionRequest) (warp.Stream, error) {
+			round++
+			if round == 1 {
+				return testutil.NewMockStream(
+					toolChunk("", "get_weather", `{"city":"Paris"}`),
+					textChunk("", "tool_calls"),
+				), nil
+			}
+			return testutil.NewMockStream(textChunk("Sunny.", "stop")), nil
+		},
+	}
+
+	var calls []string
+	a := New(newTestClient(t, mock), "mock/model", WithTools(weatherTool(&calls)))
+
+	stream, err := a.RunStream(context.Background(), []warp.Message{{Role: "user", Content: "Weather in Paris?"}})
+	if err != nil {
+		t.Fatalf("RunStream() error = %v", err)
+	}
+	defer stream.Close()
+	for {
+		if _, err := stream.Recv(); err == io.EOF {
+			break
+		} else if err != nil {
+			t.Fatalf("Recv() error = %v", err)
+		}
+	}
+
+	messages := stream.Messages()
+	if len(messages) != 4 {
+		t.Fatalf("len(Messages()) = %d, want 4", len(messages))
+	}
+	if len(messages[1].ToolCalls) != 1 || messages[1].ToolCalls[0].ID == "" {
+		t.Fatalf("assistant tool calls = %+v, want one call with an ID", messages[1].ToolCalls)
+	}
+	if messages[2].ToolCallID != messages[1].ToolCalls[0].ID {
+		t.Errorf("tool result ToolCallID = %q, want %q", messages[2].ToolCallID, messages[1].ToolCalls[0].ID)
+	}
+}
@@ -4,6 +4,9 @@ import (
 	"context"
 	"fmt"
 	"io"
+	"time"
+
+	"github.com/blue-context/warp/callback"
 )
 
 // Transcription transcribes audio to text using the specified model.
@@ -11,6 +14,10 @@ import (
 // The audio file is uploaded via multipart/form-data. The File reader will be
 // fully consumed during the request. For files, use os.Open() and defer Close().
 //
+// The audio format is detected from the file content: a missing or wrong
+// Filename extension is corrected, and formats providers do not accept are
+// converted by the AudioTranscoder set with WithAudioTranscoder, if any.
+//
 // Thread Safety: This method is safe for concurrent use.
 //
 // Example:
@@ -41,8 +48,11 @@ func (c *client) Transcription(ctx context.Context, req *TranscriptionRequest) (
 	if req.File == nil {
 		return nil, fmt.Errorf("file is required")
 	}
-	if req.Filename == "" {
-		return nil, fmt.Errorf("filename is required for multipart upload")
+
+	// Detect the audio format and fix a missing or wrong filename extension
+	req, format, err := sniffAudio(req)
+	if err != nil {
+		return nil, err
 	}
 
 	// Add request ID if not present
@@ -50,6 +60,13 @@ func (c *client) Transcription(ctx context.Context, req *TranscriptionRequest) (
 		ctx = WithGeneratedRequestID(ctx)
 	}
 
+	// Fill in the language from the prompt
+	ctx, req = c.detectTranscriptionLanguage(ctx, req)
+
+	// Add start time to context
+	startTime := c.now()
+	ctx = WithStartTime(ctx, startTime)
+
 	// Parse model to extract provider and model name
 	providerName, modelName, err := parseModel(req.Model)
 	if err != nil {
@@ -59,6 +76,7 @@ func (c *client) Transcription(ctx context.Context, req *TranscriptionRequest) (
 	// Add provider and model to context
 	ctx = WithProvider(ctx, providerName)
 	ctx = WithModel(ctx, modelName)
+	ctx = c.withRequestHeaders(ctx)
 
 	// Get provider
 	provider, err := c.getProvider(providerName)
@@ -103,35 +121,66 @@ func (c *client) Transcription(ctx context.Context, req *TranscriptionRequest) (
 		return nil, fmt.Errorf("provider %q does not support transcription", providerName)
 	}
 
+	// Convert formats providers do not accept
+	req, err = c.transcodeAudio(ctx, req, format)
+	if err != nil {
+		return nil, err
+	}
+
+	// Refuse the request once the budget is spent
+	if err := c.checkBudget(providerName, modelName); err != nil {
+		return nil, err
+	}
+
 	// Update model name in request (strip provider prefix)
 	req.Model = modelName
 
 	// Apply default timeout if not specified
 	if req.Timeout > 0 {
 		var cancel context.CancelFunc
-		ctx, cancel = context.WithTimeout(ctx, req.Timeout)
+		ctx, cancel = c.withTimeout(ctx, req.Timeout)
 		defer cancel()
 	} else if c.config.DefaultTimeout > 0 {
 		var cancel context.CancelFunc
-		ctx, cancel = context.WithTimeout(ctx, c.config.DefaultTimeout)
+		ctx, cancel = c.withTimeout(ctx, c.config.DefaultTimeout)
 		defer cancel()
 	}
 
+	// Make the file readable again for retries
+	files := c.newUploads(&req.File)
+
 	// Call provider with retry logic
 	var resp *TranscriptionResponse
-	err = c.withRetry(ctx, func() error {
-		var callErr error
-		resp, callErr = provider.Transcription(ctx, req)
-		return callErr
-	})
+	err = c.withRetryIf(ctx, func() error {
+		if err := files.rewind(); err != nil {
+			return err
+		}
+		return c.callProvider(ctx, providerName, func() error {
+			var callErr error
+			resp, callErr = provider.Transcription(ctx, req)
+			return callErr
+		})
+	}, files.replayable)
 
 	if err != nil {
+		c.reportAudio(ctx, providerName, modelName, req, nil, startTime, 0, err)
 		return nil, err
 	}
 
 	// Set metadata
 	resp.Provider = providerName
 	resp.Model = modelName
+	resp.RequestID = RequestIDFromContext(ctx)
+	resp.ProviderRequestID = ProviderRequestIDFromContext(ctx)
+
+	cost := 0.0
+	if c.config.TrackCost {
+		if calculatedCost, err := c.TranscriptionCost(resp); err == nil {
+			cost = calculatedCost
+		}
+		c.chargeBudget(providerName, modelName, cost)
+	}
+	c.reportAudio(ctx, providerName, modelName, req, resp, startTime, cost, nil)
 
 	return resp, nil
 }
@@ -190,6 +239,11 @@ func (c *client) Speech(ctx context.Context, req *SpeechRequest) (io.ReadCloser,
 	// Add provider and model to context
 	ctx = WithProvider(ctx, providerName)
 	ctx = WithModel(ctx, modelName)
+	ctx = c.withRequestHeaders(ctx)
+
+	// Add start time to context
+	startTime := c.now()
+	ctx = WithStartTime(ctx, startTime)
 
 	// Get provider
 	provider, err := c.getProvider(providerName)
@@ -221,26 +275,95 @@ func (c *client) Speech(ctx context.Context, req *SpeechRequest) (io.ReadCloser,
 		return nil, fmt.Errorf("provider %q does not support text-to-speech", providerName)
 	}
 
+	// Refuse the request once the budget is spent
+	if err := c.checkBudget(providerName, modelName); err != nil {
+		return nil, err
+	}
+
 	// Update model name in request (strip provider prefix)
 	req.Model = modelName
 
 	// Apply default timeout if not specified
 	if req.Timeout > 0 {
 		var cancel context.CancelFunc
-		ctx, cancel = context.WithTimeout(ctx, req.Timeout)
+		ctx, cancel = c.withTimeout(ctx, req.Timeout)
 		defer cancel()
 	} else if c.config.DefaultTimeout > 0 {
 		var cancel context.CancelFunc
-		ctx, cancel = context.WithTimeout(ctx, c.config.DefaultTimeout)
+		ctx, cancel = c.withTimeout(ctx, c.config.DefaultTimeout)
 		defer cancel()
 	}
 
 	// Call provider (NO retry - streaming response!)
 	// Streaming responses cannot be retried as the body is consumed
-	audio, err := provider.Speech(ctx, req)
+	release, err := c.acquireBulkhead(ctx, providerName)
 	if err != nil {
 		return nil, err
 	}
+	var audio io.ReadCloser
+	func() {
+		defer c.recoverPanic(providerName, "provider panicked", &err)
+		audio, err = provider.Speech(ctx, req)
+	}()
+	if err != nil {
+		release()
+		c.reportAudio(ctx, providerName, modelName, req, nil, startTime, 0, err)
+		return nil, err
+	}
+
+	// Speech is billed on the input, so its cost is known before the audio
+	// is read
+	cost := 0.0
+	if c.config.TrackCost {
+		if calculatedCost, err := c.SpeechCost(providerName+"/"+modelName, req.Input); err == nil {
+			cost = calculatedCost
+		}
+		c.chargeBudget(providerName, modelName, cost)
+	}
+	c.reportAudio(ctx, providerName, modelName, req, nil, startTime, cost, nil)
 
+	// Hold the bulkhead slot until the audio is read
+	if c.bulkheads.get(providerName) != nil {
+		return &bulkheadReader{ReadCloser: audio, release: release}, nil
+	}
 	return audio, nil
 }
+
+// reportAudio executes the success or failure callbacks of a transcription
+// or speech request. req is a *TranscriptionRequest or *SpeechRequest, and
+// resp the *TranscriptionResponse, if any; cost is in USD.
+func (c *client) reportAudio(ctx context.Context, providerName, modelName string, req, resp interface{}, startTime time.Time, cost float64, err error) {
+	if c.callbacks == nil {
+		return
+	}
+	endTime := c.now()
+
+	if err != nil {
+		c.callbacks.ExecuteFailure(ctx, &callback.FailureEvent{
+			RequestID: RequestIDFromContext(ctx),
+			Model:     modelName,
+			Provider:  providerName,
+			Request:   req,
+			Error:     err,
+			StartTime: startTime,
+			EndTime:   endTime,
+			Duration:  endTime.Sub(startTime),
+		})
+		return
+	}
+
+	currency, localCost := c.localCost(ctx, cost)
+	c.callbacks.ExecuteSuccess(ctx, &callback.SuccessEvent{
+		RequestID: RequestIDFromContext(ctx),
+		Model:     modelName,
+		Provider:  providerName,
+		Request:   req,
+		Response:  resp,
+		StartTime: startTime,
+		EndTime:   endTime,
+		Duration:  endTime.Sub(startTime),
+		Cost:      cost,
+		Currency:  currency,
+		LocalCost: localCost,
+	})
+}
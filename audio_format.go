@@ -0,0 +1,181 @@
+package warp
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"strings"
+)
+
+// AudioFormat is an audio container format.
+type AudioFormat string
+
+const (
+	// AudioFormatUnknown is returned when the format cannot be detected.
+	AudioFormatUnknown AudioFormat = ""
+
+	// AudioFormatMP3 is MPEG audio layer III.
+	AudioFormatMP3 AudioFormat = "mp3"
+
+	// AudioFormatMP4 is MPEG-4 audio (including M4A).
+	AudioFormatMP4 AudioFormat = "mp4"
+
+	// AudioFormatWAV is RIFF WAVE.
+	AudioFormatWAV AudioFormat = "wav"
+
+	// AudioFormatWebM is WebM (Matroska).
+	AudioFormatWebM AudioFormat = "webm"
+
+	// AudioFormatOGG is Ogg (Vorbis or Opus).
+	AudioFormatOGG AudioFormat = "ogg"
+
+	// AudioFormatFLAC is FLAC.
+	AudioFormatFLAC AudioFormat = "flac"
+)
+
+// audioExtensions lists the file extensions of each format; the first is
+// used when a filename is corrected.
+var audioExtensions = map[AudioFormat][]string{
+	AudioFormatMP3:  {"mp3", "mpga", "mpeg"},
+	AudioFormatMP4:  {"m4a", "mp4"},
+	AudioFormatWAV:  {"wav"},
+	AudioFormatWebM: {"webm"},
+	AudioFormatOGG:  {"ogg", "oga", "opus"},
+	AudioFormatFLAC: {"flac"},
+}
+
+// transcriptionFormats are the formats every transcription provider
+// accepts. Others are converted by the AudioTranscoder, if configured.
+var transcriptionFormats = map[AudioFormat]bool{
+	AudioFormatMP3:  true,
+	AudioFormatMP4:  true,
+	AudioFormatWAV:  true,
+	AudioFormatWebM: true,
+}
+
+// audioSniffLen is how much of a file DetectAudioFormat needs.
+const audioSniffLen = 12
+
+// DetectAudioFormat detects the format of audio from its first bytes,
+// returning AudioFormatUnknown if it is not recognized. At least 12 bytes
+// should be given.
+func DetectAudioFormat(header []byte) AudioFormat {
+	switch {
+	case bytes.HasPrefix(header, []byte("ID3")):
+		return AudioFormatMP3
+	case len(header) >= 12 && bytes.HasPrefix(header, []byte("RIFF")) && bytes.Equal(header[8:12], []byte("WAVE")):
+		return AudioFormatWAV
+	case bytes.HasPrefix(header, []byte("OggS")):
+		return AudioFormatOGG
+	case bytes.HasPrefix(header, []byte("fLaC")):
+		return AudioFormatFLAC
+	case bytes.HasPrefix(header, []byte{0x1A, 0x45, 0xDF, 0xA3}):
+		return AudioFormatWebM
+	case len(header) >= 8 && bytes.Equal(header[4:8], []byte("ftyp")):
+		return AudioFormatMP4
+	case len(header) >= 2 && header[0] == 0xFF && header[1]&0xE0 == 0xE0:
+		// MPEG audio frame sync without an ID3 tag
+		return AudioFormatMP3
+	}
+	return AudioFormatUnknown
+}
+
+// AudioTranscoder converts audio to a format transcription providers
+// accept (MP3, MP4, WAV or WebM), for example by running ffmpeg.
+//
+// Example:
+//
+//	type ffmpegTranscoder struct{}
+//
+//	func (ffmpegTranscoder) Transcode(ctx context.Context, audio io.Reader, from warp.AudioFormat) (io.Reader, warp.AudioFormat, error) {
+//	    cmd := exec.CommandContext(ctx, "ffmpeg", "-i", "pipe:0", "-f", "wav", "pipe:1")
+//	    cmd.Stdin = audio
+//	    out, err := cmd.Output()
+//	    if err != nil {
+//	        return nil, "", err
+//	    }
+//	    return bytes.NewReader(out), warp.AudioFormatWAV, nil
+//	}
+type AudioTranscoder interface {
+	// Transcode converts audio in format from, returning the converted
+	// audio and its format.
+	Transcode(ctx context.Context, audio io.Reader, from AudioFormat) (io.Reader, AudioFormat, error)
+}
+
+// sniffAudio detects the format of a transcription request's file and
+// returns a copy of req whose filename extension matches it. A missing
+// filename is named "audio" with the detected extension. A seekable file
+// is moved back to where it started; other files are replaced by a reader
+// that still yields their whole content.
+//
+// Returns an error if there is no filename and the format is unknown.
+func sniffAudio(req *TranscriptionRequest) (*TranscriptionRequest, AudioFormat, error) {
+	header := make([]byte, audioSniffLen)
+	n, err := io.ReadFull(req.File, header)
+	if err != nil && !errors.Is(err, io.EOF) && !errors.Is(err, io.ErrUnexpectedEOF) {
+		return nil, AudioFormatUnknown, fmt.Errorf("failed to read audio file: %w", err)
+	}
+	header = header[:n]
+
+	r := *req
+	if seeker, ok := req.File.(io.Seeker); !ok || seekBack(seeker, n) != nil {
+		r.File = io.MultiReader(bytes.NewReader(header), req.File)
+	}
+
+	format := DetectAudioFormat(header)
+	if format == AudioFormatUnknown {
+		if r.Filename == "" {
+			return nil, format, fmt.Errorf("filename is required for multipart upload")
+		}
+		return &r, format, nil
+	}
+	r.Filename = audioFilename(r.Filename, format)
+	return &r, format, nil
+}
+
+// seekBack moves seeker back n bytes.
+func seekBack(seeker io.Seeker, n int) error {
+	_, err := seeker.Seek(-int64(n), io.SeekCurrent)
+	return err
+}
+
+// audioFilename returns name with an extension of format, replacing an
+// extension of another format.
+func audioFilename(name string, format AudioFormat) string {
+	ext := path.Ext(name)
+	for _, e := range audioExtensions[format] {
+		if strings.EqualFold(ext, "."+e) {
+			return name
+		}
+	}
+	base := strings.TrimSuffix(name, ext)
+	if base == "" {
+		base = "audio"
+	}
+	return base + "." + audioExtensions[format][0]
+}
+
+// transcodeAudio converts req's file with the configured AudioTranscoder
+// if providers do not accept its format. It returns req unchanged if the
+// format is accepted or unknown, or no transcoder is configured.
+func (c *client) transcodeAudio(ctx context.Context, req *TranscriptionRequest, format AudioFormat) (*TranscriptionRequest, error) {
+	if c.config.AudioTranscoder == nil || format == AudioFormatUnknown || transcriptionFormats[format] {
+		return req, nil
+	}
+
+	audio, to, err := c.config.AudioTranscoder.Transcode(ctx, req.File, format)
+	if err != nil {
+		return nil, fmt.Errorf("failed to transcode %s audio: %w", format, err)
+	}
+	if audioExtensions[to] == nil {
+		return nil, fmt.Errorf("transcoder returned unknown audio format %q", to)
+	}
+
+	r := *req
+	r.File = audio
+	r.Filename = audioFilename(r.Filename, to)
+	return &r, nil
+}
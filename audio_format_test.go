@@ -0,0 +1,159 @@
+package warp
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestDetectAudioFormat(t *testing.T) {
+	tests := []struct {
+		name   string
+		header string
+		want   AudioFormat
+	}{
+		{"mp3 with ID3", "ID3\x04\x00\x00\x00\x00\x00\x00", AudioFormatMP3},
+		{"mp3 frame", "\xFF\xFB\x90\x64\x00", AudioFormatMP3},
+		{"wav", "RIFF\x24\x08\x00\x00WAVEfmt ", AudioFormatWAV},
+		{"riff but not wave", "RIFF\x24\x08\x00\x00AVI LIST", AudioFormatUnknown},
+		{"ogg", "OggS\x00\x02\x00\x00", AudioFormatOGG},
+		{"flac", "fLaC\x00\x00\x00\x22", AudioFormatFLAC},
+		{"webm", "\x1A\x45\xDF\xA3\x9F\x42\x86\x81", AudioFormatWebM},
+		{"m4a", "\x00\x00\x00\x20ftypM4A ", AudioFormatMP4},
+		{"text", "hello world!", AudioFormatUnknown},
+		{"empty", "", AudioFormatUnknown},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := DetectAudioFormat([]byte(tt.header)); got != tt.want {
+				t.Errorf("DetectAudioFormat() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAudioFilename(t *testing.T) {
+	tests := []struct {
+		name   string
+		format AudioFormat
+		want   string
+	}{
+		{"meeting.mp3", AudioFormatMP3, "meeting.mp3"},
+		{"meeting.MPGA", AudioFormatMP3, "meeting.MPGA"},
+		{"meeting.wav", AudioFormatMP3, "meeting.mp3"},
+		{"recording", AudioFormatOGG, "recording.ogg"},
+		{"", AudioFormatWAV, "audio.wav"},
+		{"v1.2/clip.bin", AudioFormatMP4, "v1.2/clip.m4a"},
+	}
+	for _, tt := range tests {
+		if got := audioFilename(tt.name, tt.format); got != tt.want {
+			t.Errorf("audioFilename(%q, %q) = %q, want %q", tt.name, tt.format, got, tt.want)
+		}
+	}
+}
+
+// recordingTranscriptionProvider records transcription requests.
+type recordingTranscriptionProvider struct {
+	mockTranscriptionProvider
+	filename string
+	content  []byte
+}
+
+func (p *recordingTranscriptionProvider) Transcription(ctx context.Context, req *TranscriptionRequest) (*TranscriptionResponse, error) {
+	p.filename = req.Filename
+	p.content, _ = io.ReadAll(req.File)
+	return &TranscriptionResponse{Text: "ok"}, nil
+}
+
+// wavTranscoder converts any audio to a fake WAV file.
+type wavTranscoder struct{ from AudioFormat }
+
+func (t *wavTranscoder) Transcode(ctx context.Context, audio io.Reader, from AudioFormat) (io.Reader, AudioFormat, error) {
+	t.from = from
+	data, err := io.ReadAll(audio)
+	if err != nil {
+		return nil, "", err
+	}
+	return io.MultiReader(strings.NewReader("RIFF\x00\x00\x00\x00WAVE"), bytes.NewReader(data)), AudioFormatWAV, nil
+}
+
+func TestClientTranscriptionAudioFormat(t *testing.T) {
+	ogg := "OggS\x00\x02\x00\x00\x00\x00\x00\x00 vorbis data"
+	tests := []struct {
+		name         string
+		file         string
+		filename     string
+		transcoder   *wavTranscoder
+		wantFilename string
+		wantContent  string
+		wantFrom     AudioFormat
+	}{
+		{
+			name:         "missing filename",
+			file:         "ID3 mp3 data",
+			wantFilename: "audio.mp3",
+			wantContent:  "ID3 mp3 data",
+		},
+		{
+			name:         "wrong extension",
+			file:         ogg,
+			filename:     "voice-note.mp3",
+			wantFilename: "voice-note.ogg",
+			wantContent:  ogg,
+		},
+		{
+			name:         "unknown format keeps filename",
+			file:         "raw",
+			filename:     "clip.pcm",
+			transcoder:   &wavTranscoder{},
+			wantFilename: "clip.pcm",
+			wantContent:  "raw",
+		},
+		{
+			name:         "transcoded",
+			file:         ogg,
+			filename:     "voice-note.ogg",
+			transcoder:   &wavTranscoder{},
+			wantFilename: "voice-note.wav",
+			wantContent:  "RIFF\x00\x00\x00\x00WAVE" + ogg,
+			wantFrom:     AudioFormatOGG,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var opts []ClientOption
+			if tt.transcoder != nil {
+				opts = append(opts, WithAudioTranscoder(tt.transcoder))
+			}
+			client, err := NewClient(opts...)
+			if err != nil {
+				t.Fatalf("NewClient() error = %v", err)
+			}
+			provider := &recordingTranscriptionProvider{
+				mockTranscriptionProvider: mockTranscriptionProvider{name: "openai", supportsTranscription: true},
+			}
+			client.RegisterProvider(provider)
+
+			_, err = client.Transcription(context.Background(), &TranscriptionRequest{
+				Model:    "openai/whisper-1",
+				File:     strings.NewReader(tt.file),
+				Filename: tt.filename,
+			})
+			if err != nil {
+				t.Fatalf("Transcription() error = %v", err)
+			}
+			if provider.filename != tt.wantFilename {
+				t.Errorf("Filename = %q, want %q", provider.filename, tt.wantFilename)
+			}
+			if string(provider.content) != tt.wantContent {
+				t.Errorf("content = %q, want %q", provider.content, tt.wantContent)
+			}
+			if tt.transcoder != nil && tt.transcoder.from != tt.wantFrom {
+				t.Errorf("transcoded from %q, want %q", tt.transcoder.from, tt.wantFrom)
+			}
+		})
+	}
+}
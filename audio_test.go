@@ -9,6 +9,8 @@ import (
 	"testing"
 	"time"
 
+	"github.com/blue-context/warp/callback"
+	"github.com/blue-context/warp/cost"
 	"github.com/blue-context/warp/types"
 )
 
@@ -685,3 +687,136 @@ func TestClientSpeechWithContextCancellation(t *testing.T) {
 func float64Ptr(f float64) *float64 {
 	return &f
 }
+
+// TestTranscriptionResponseAudioDuration tests duration detection
+func TestTranscriptionResponseAudioDuration(t *testing.T) {
+	tests := []struct {
+		name string
+		resp *TranscriptionResponse
+		want time.Duration
+	}{
+		{name: "nil response", resp: nil, want: 0},
+		{name: "reported duration", resp: &TranscriptionResponse{Duration: 12.5}, want: 12500 * time.Millisecond},
+		{
+			name: "segments",
+			resp: &TranscriptionResponse{Segments: []Segment{{End: 3}, {End: 7.25}}},
+			want: 7250 * time.Millisecond,
+		},
+		{
+			name: "words",
+			resp: &TranscriptionResponse{Words: []Word{{Word: "hi", End: 1.5}}},
+			want: 1500 * time.Millisecond,
+		},
+		{name: "text only", resp: &TranscriptionResponse{Text: "hello"}, want: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.resp.AudioDuration(); got != tt.want {
+				t.Errorf("AudioDuration() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+// TestClientAudioCost tests speech and transcription cost calculation
+func TestClientAudioCost(t *testing.T) {
+	client, err := NewClient()
+	if err != nil {
+		t.Fatalf("NewClient() error = %v", err)
+	}
+	defer client.Close()
+
+	if err := client.RegisterModelPricing("test/tts", 0, 0, cost.WithSpeechPrice(15.0)); err != nil {
+		t.Fatalf("RegisterModelPricing() error = %v", err)
+	}
+	if err := client.RegisterModelPricing("test/stt", 0, 0, cost.WithTranscriptionPrice(0.006)); err != nil {
+		t.Fatalf("RegisterModelPricing() error = %v", err)
+	}
+
+	// Multi-byte characters are billed per character, not per byte
+	got, err := client.SpeechCost("test/tts", strings.Repeat("é", 1000))
+	if err != nil {
+		t.Fatalf("SpeechCost() error = %v", err)
+	}
+	if diff := got - 0.015; diff > 1e-9 || diff < -1e-9 {
+		t.Errorf("SpeechCost() = %f, want 0.015", got)
+	}
+
+	got, err = client.TranscriptionCost(&TranscriptionResponse{Provider: "test", Model: "stt", Duration: 120})
+	if err != nil {
+		t.Fatalf("TranscriptionCost() error = %v", err)
+	}
+	if diff := got - 0.012; diff > 1e-9 || diff < -1e-9 {
+		t.Errorf("TranscriptionCost() = %f, want 0.012", got)
+	}
+
+	if _, err := client.TranscriptionCost(&TranscriptionResponse{Provider: "test", Model: "stt", Text: "hi"}); err == nil {
+		t.Error("TranscriptionCost() expected error without duration")
+	}
+}
+
+// TestClientAudioCostCallbacks tests that speech and transcription costs
+// are reported to success callbacks
+func TestClientAudioCostCallbacks(t *testing.T) {
+	var events []*callback.SuccessEvent
+	client, err := NewClient(
+		WithCostTracking(true),
+		WithSuccessCallback(func(ctx context.Context, event *callback.SuccessEvent) {
+			events = append(events, event)
+		}),
+	)
+	if err != nil {
+		t.Fatalf("NewClient() error = %v", err)
+	}
+	defer client.Close()
+
+	client.RegisterProvider(&mockSpeechProvider{name: "tts", audioData: []byte("audio"), supportsSpeech: true})
+	client.RegisterProvider(&mockTranscriptionProvider{
+		name:                  "stt",
+		transcriptionResp:     &TranscriptionResponse{Text: "hi", Duration: 120},
+		supportsTranscription: true,
+	})
+	if err := client.RegisterModelPricing("tts/tts-1", 0, 0, cost.WithSpeechPrice(15.0)); err != nil {
+		t.Fatalf("RegisterModelPricing() error = %v", err)
+	}
+	if err := client.RegisterModelPricing("stt/whisper-1", 0, 0, cost.WithTranscriptionPrice(0.006)); err != nil {
+		t.Fatalf("RegisterModelPricing() error = %v", err)
+	}
+
+	audio, err := client.Speech(context.Background(), &SpeechRequest{
+		Model: "tts/tts-1",
+		Input: strings.Repeat("a", 1000),
+		Voice: "alloy",
+	})
+	if err != nil {
+		t.Fatalf("Speech() error = %v", err)
+	}
+	audio.Close()
+
+	if _, err := client.Transcription(context.Background(), &TranscriptionRequest{
+		Model:    "stt/whisper-1",
+		File:     strings.NewReader("fake audio data"),
+		Filename: "test.mp3",
+	}); err != nil {
+		t.Fatalf("Transcription() error = %v", err)
+	}
+
+	if len(events) != 2 {
+		t.Fatalf("success callbacks = %d, want 2", len(events))
+	}
+	for i, want := range []struct {
+		provider string
+		cost     float64
+	}{{"tts", 0.015}, {"stt", 0.012}} {
+		if events[i].Provider != want.provider {
+			t.Errorf("events[%d].Provider = %q, want %q", i, events[i].Provider, want.provider)
+		}
+		if diff := events[i].Cost - want.cost; diff > 1e-9 || diff < -1e-9 {
+			t.Errorf("events[%d].Cost = %f, want %f", i, events[i].Cost, want.cost)
+		}
+	}
+	if _, ok := events[1].Response.(*TranscriptionResponse); !ok {
+		t.Errorf("events[1].Response = %T, want *TranscriptionResponse", events[1].Response)
+	}
+}
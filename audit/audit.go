@@ -0,0 +1,413 @@
+// Package audit records a tamper-evident log of Warp requests.
+//
+// Each Record holds request metadata (provider, model, tokens, cost,
+// duration, outcome) and is chained to its predecessor by a SHA-256 hash, so
+// editing, removing, or reordering any record breaks the chain. Records can
+// also be signed with an HMAC key. Message content is omitted unless enabled
+// with WithContent or WithContentKeys, in which case it is encrypted with
+// the encrypt package.
+//
+// Records are written to a Sink. FileSink appends JSON lines to a file;
+// sinks for S3, Postgres, or other stores implement the one-method Sink
+// interface (see s3_example.go and postgres_example.go).
+//
+// Basic usage:
+//
+//	sink, err := audit.NewFileSink("audit.jsonl")
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+//	defer sink.Close()
+//
+//	logger := audit.New(sink, audit.WithSigningKey(signingKey))
+//
+//	client, err := warp.NewClient(
+//	    warp.WithSuccessCallback(logger.Success),
+//	    warp.WithFailureCallback(logger.Failure),
+//	)
+//
+// Verify a log later with Verify:
+//
+//	f, _ := os.Open("audit.jsonl")
+//	last, err := audit.Verify(f, audit.WithSigningKey(signingKey))
+package audit
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/blue-context/warp"
+	"github.com/blue-context/warp/callback"
+	"github.com/blue-context/warp/encrypt"
+)
+
+// Record outcomes.
+const (
+	StatusSuccess = "success"
+	StatusFailure = "failure"
+	StatusDenied  = "denied"
+)
+
+// Record is a single audit log entry.
+type Record struct {
+	// Seq is the record's position in the chain, starting at 1.
+	Seq uint64 `json:"seq"`
+
+	// Time is when the record was logged (UTC).
+	Time time.Time `json:"time"`
+
+	// RequestID identifies the request.
+	RequestID string `json:"request_id,omitempty"`
+
+	// Tenant is the tenant a denied request was made for, if any.
+	Tenant string `json:"tenant,omitempty"`
+
+	// Provider and Model identify the endpoint called.
+	Provider string `json:"provider"`
+	Model    string `json:"model"`
+
+	// Status is StatusSuccess, StatusFailure, or StatusDenied.
+	Status string `json:"status"`
+
+	// Error is the failure message for failed or denied requests.
+	Error string `json:"error,omitempty"`
+
+	// Duration is the request duration.
+	Duration time.Duration `json:"duration_ns"`
+
+	// PromptTokens, CompletionTokens, and TotalTokens are the token usage.
+	PromptTokens     int `json:"prompt_tokens,omitempty"`
+	CompletionTokens int `json:"completion_tokens,omitempty"`
+	TotalTokens      int `json:"total_tokens,omitempty"`
+
+	// Cost is the estimated cost in USD.
+	Cost float64 `json:"cost,omitempty"`
+
+	// Content is the encrypted request and response content, present only
+	// when the logger was created with WithContent.
+	Content *EncryptedContent `json:"content,omitempty"`
+
+	// PrevHash is the Hash of the previous record, empty for the first.
+	PrevHash string `json:"prev_hash"`
+
+	// Hash is the hex SHA-256 of the record with Hash and Signature empty.
+	Hash string `json:"hash"`
+
+	// Signature is the hex HMAC-SHA256 of Hash, present when a signing key
+	// is configured.
+	Signature string `json:"signature,omitempty"`
+}
+
+// EncryptedContent is the JSON of the request messages and response,
+// sealed with encrypt.Seal. Decrypt it with DecryptContent or
+// DecryptContentWithKeys.
+type EncryptedContent struct {
+	// KeyID identifies the key the content was sealed with: the key ID
+	// from WithContentKeys, or "content" for WithContent.
+	KeyID string `json:"key_id,omitempty"`
+
+	// Sealed is the output of encrypt.Seal.
+	Sealed []byte `json:"sealed"`
+}
+
+// contentKeyID identifies the key given to WithContent.
+const contentKeyID = "content"
+
+// Content is the plaintext of EncryptedContent.
+type Content struct {
+	Messages []warp.Message           `json:"messages,omitempty"`
+	Response *warp.CompletionResponse `json:"response,omitempty"`
+}
+
+// Sink stores audit records.
+//
+// Write is called with records in chain order, one at a time.
+type Sink interface {
+	Write(ctx context.Context, rec *Record) error
+}
+
+// Logger appends hash-chained records to a Sink.
+//
+// Thread Safety: Logger is safe for concurrent use. Records are
+// serialized so the chain order matches the sink order.
+type Logger struct {
+	mu         sync.Mutex
+	sink       Sink
+	seq        uint64
+	prevHash   string
+	signingKey []byte
+	keys       encrypt.KeyProvider
+	onError    func(error)
+	now        func() time.Time
+	err        error
+}
+
+// Option configures a Logger or Verify.
+type Option func(*Logger)
+
+// New creates a Logger writing to sink.
+//
+// Invalid options (e.g., a content key of the wrong length) are reported
+// by the first Log call.
+func New(sink Sink, opts ...Option) *Logger {
+	l := &Logger{
+		sink: sink,
+		now:  time.Now,
+	}
+	for _, opt := range opts {
+		opt(l)
+	}
+	return l
+}
+
+// WithSigningKey signs each record's hash with HMAC-SHA256 using key.
+//
+// Unlike the hash chain alone, signatures prevent an attacker from
+// rewriting the whole log with a recomputed chain.
+func WithSigningKey(key []byte) Option {
+	return func(l *Logger) {
+		l.signingKey = key
+	}
+}
+
+// WithContent records request messages and responses, encrypted with
+// AES-GCM using key (16, 24, or 32 bytes).
+func WithContent(key []byte) Option {
+	return func(l *Logger) {
+		keys, err := encrypt.NewKeyRing(contentKeyID, key)
+		if err != nil {
+			l.err = fmt.Errorf("invalid content key: %w", err)
+			return
+		}
+		l.keys = keys
+	}
+}
+
+// WithContentKeys records request messages and responses, encrypted with
+// AES-GCM using the current key from keys. Each record notes its key ID, so
+// keys can be rotated without losing access to older records.
+func WithContentKeys(keys encrypt.KeyProvider) Option {
+	return func(l *Logger) {
+		l.keys = keys
+	}
+}
+
+// WithResume continues the chain after last, typically the record returned
+// by Verify for an existing log.
+func WithResume(last *Record) Option {
+	return func(l *Logger) {
+		if last != nil {
+			l.seq = last.Seq
+			l.prevHash = last.Hash
+		}
+	}
+}
+
+// WithErrorHandler sets a function called when Success or Failure cannot
+// write a record. Callbacks cannot return errors, so without a handler
+// these errors are dropped.
+func WithErrorHandler(fn func(error)) Option {
+	return func(l *Logger) {
+		l.onError = fn
+	}
+}
+
+// Log completes rec with its sequence number, time, hashes, and signature
+// and writes it to the sink.
+//
+// If the sink fails, the chain does not advance, so the next record links
+// to the last one written.
+func (l *Logger) Log(ctx context.Context, rec *Record) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.err != nil {
+		return l.err
+	}
+
+	rec.Seq = l.seq + 1
+	if rec.Time.IsZero() {
+		rec.Time = l.now()
+	}
+	rec.Time = rec.Time.UTC()
+	rec.PrevHash = l.prevHash
+
+	hash, err := hashRecord(rec)
+	if err != nil {
+		return err
+	}
+	rec.Hash = hash
+	if l.signingKey != nil {
+		rec.Signature = sign(l.signingKey, hash)
+	}
+
+	if err := l.sink.Write(ctx, rec); err != nil {
+		return fmt.Errorf("failed to write audit record: %w", err)
+	}
+
+	l.seq = rec.Seq
+	l.prevHash = rec.Hash
+	return nil
+}
+
+// Success records a successful request. Register it with
+// warp.WithSuccessCallback.
+func (l *Logger) Success(ctx context.Context, event *callback.SuccessEvent) {
+	rec := &Record{
+		RequestID:   event.RequestID,
+		Provider:    event.Provider,
+		Model:       event.Model,
+		Status:      StatusSuccess,
+		Duration:    event.Duration,
+		TotalTokens: event.Tokens,
+		Cost:        event.Cost,
+	}
+
+	resp, _ := event.Response.(*warp.CompletionResponse)
+	if resp != nil && resp.Usage != nil {
+		rec.PromptTokens = resp.Usage.PromptTokens
+		rec.CompletionTokens = resp.Usage.CompletionTokens
+		rec.TotalTokens = resp.Usage.TotalTokens
+	}
+
+	l.record(ctx, rec, event.Request, resp)
+}
+
+// Failure records a failed request. Register it with
+// warp.WithFailureCallback.
+func (l *Logger) Failure(ctx context.Context, event *callback.FailureEvent) {
+	rec := &Record{
+		RequestID: event.RequestID,
+		Provider:  event.Provider,
+		Model:     event.Model,
+		Status:    StatusFailure,
+		Duration:  event.Duration,
+	}
+	if event.Error != nil {
+		rec.Error = event.Error.Error()
+	}
+
+	l.record(ctx, rec, event.Request, nil)
+}
+
+// Violation records a request denied by policy. Register it with
+// policy.WithViolationHandler.
+func (l *Logger) Violation(ctx context.Context, err *warp.PolicyViolationError) {
+	rec := &Record{
+		RequestID: warp.RequestIDFromContext(ctx),
+		Tenant:    err.Tenant,
+		Provider:  err.Provider,
+		Model:     err.Model,
+		Status:    StatusDenied,
+		Error:     err.Rule + ": " + err.Message,
+	}
+
+	l.record(ctx, rec, nil, nil)
+}
+
+// record attaches encrypted content if enabled and logs rec, reporting
+// errors to the error handler.
+func (l *Logger) record(ctx context.Context, rec *Record, request interface{}, resp *warp.CompletionResponse) {
+	// Callbacks run after the request, so a cancelled request context must
+	// not prevent the record from being written.
+	ctx = context.WithoutCancel(ctx)
+
+	err := func() error {
+		if l.keys == nil || (request == nil && resp == nil) {
+			return nil
+		}
+		content := Content{Response: resp}
+		if req, ok := request.(*warp.CompletionRequest); ok && req != nil {
+			content.Messages = req.Messages
+		}
+		plaintext, err := json.Marshal(content)
+		if err != nil {
+			return fmt.Errorf("failed to encode audit content: %w", err)
+		}
+		sealed, err := encrypt.Seal(ctx, l.keys, plaintext, nil)
+		if err != nil {
+			return fmt.Errorf("failed to encrypt audit content: %w", err)
+		}
+		keyID, err := encrypt.KeyID(sealed)
+		if err != nil {
+			return err
+		}
+		rec.Content = &EncryptedContent{KeyID: keyID, Sealed: sealed}
+		return nil
+	}()
+	if err == nil {
+		err = l.Log(ctx, rec)
+	}
+
+	if err != nil && l.onError != nil {
+		l.onError(err)
+	}
+}
+
+// DecryptContent decrypts a record's content with the key given to
+// WithContent.
+func DecryptContent(rec *Record, key []byte) (*Content, error) {
+	if rec.Content == nil {
+		return nil, fmt.Errorf("record %d has no content", rec.Seq)
+	}
+	id, err := encrypt.KeyID(rec.Content.Sealed)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt record %d: %w", rec.Seq, err)
+	}
+	keys, err := encrypt.NewKeyRing(id, key)
+	if err != nil {
+		return nil, fmt.Errorf("invalid content key: %w", err)
+	}
+	return open(context.Background(), keys, rec)
+}
+
+// DecryptContentWithKeys decrypts a record's content with the key it was
+// written with under WithContentKeys.
+func DecryptContentWithKeys(ctx context.Context, rec *Record, keys encrypt.KeyProvider) (*Content, error) {
+	if rec.Content == nil {
+		return nil, fmt.Errorf("record %d has no content", rec.Seq)
+	}
+	return open(ctx, keys, rec)
+}
+
+// open decrypts and decodes a record's content.
+func open(ctx context.Context, keys encrypt.KeyProvider, rec *Record) (*Content, error) {
+	plaintext, err := encrypt.Open(ctx, keys, rec.Content.Sealed, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt record %d: %w", rec.Seq, err)
+	}
+
+	var content Content
+	if err := json.Unmarshal(plaintext, &content); err != nil {
+		return nil, fmt.Errorf("failed to decode record %d content: %w", rec.Seq, err)
+	}
+	return &content, nil
+}
+
+// hashRecord returns the hex SHA-256 of rec's JSON with Hash and Signature
+// cleared.
+func hashRecord(rec *Record) (string, error) {
+	r := *rec
+	r.Hash = ""
+	r.Signature = ""
+
+	data, err := json.Marshal(&r)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode audit record: %w", err)
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// sign returns the hex HMAC-SHA256 of hash.
+func sign(key []byte, hash string) string {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(hash))
+	return hex.EncodeToString(mac.Sum(nil))
+}
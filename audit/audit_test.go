@@ -0,0 +1,288 @@
+package audit
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/blue-context/warp"
+	"github.com/blue-context/warp/callback"
+	"github.com/blue-context/warp/encrypt"
+	"github.com/blue-context/warp/internal/testutil"
+)
+
+// failingSink fails every write.
+type failingSink struct{}
+
+func (failingSink) Write(ctx context.Context, rec *Record) error {
+	return errors.New("disk full")
+}
+
+func successEvent() *callback.SuccessEvent {
+	return &callback.SuccessEvent{
+		RequestID: "req-1",
+		Provider:  "openai",
+		Model:     "gpt-4o",
+		Request: &warp.CompletionRequest{
+			Model:    "openai/gpt-4o",
+			Messages: []warp.Message{{Role: "user", Content: "secret prompt"}},
+		},
+		Response: &warp.CompletionResponse{
+			Choices: []warp.Choice{{Message: warp.Message{Role: "assistant", Content: "secret answer"}}},
+			Usage:   &warp.Usage{PromptTokens: 10, CompletionTokens: 5, TotalTokens: 15},
+		},
+		Duration: time.Second,
+		Cost:     0.002,
+		Tokens:   15,
+	}
+}
+
+func TestLoggerChain(t *testing.T) {
+	var buf bytes.Buffer
+	key := []byte("signing-key")
+	l := New(NewWriterSink(&buf), WithSigningKey(key))
+
+	ctx := context.Background()
+	l.Success(ctx, successEvent())
+	l.Failure(ctx, &callback.FailureEvent{Provider: "openai", Model: "gpt-4o", Error: errors.New("rate limited")})
+	l.Success(ctx, successEvent())
+
+	log := buf.String()
+	if strings.Contains(log, "secret") {
+		t.Error("log contains message content without WithContent")
+	}
+
+	last, err := Verify(strings.NewReader(log), WithSigningKey(key))
+	if err != nil {
+		t.Fatalf("Verify() error = %v", err)
+	}
+	if last.Seq != 3 || last.PromptTokens != 10 || last.CompletionTokens != 5 || last.Cost != 0.002 {
+		t.Errorf("last record = %+v", last)
+	}
+
+	lines := strings.Split(strings.TrimSpace(log), "\n")
+	if !strings.Contains(lines[1], `"status":"failure"`) || !strings.Contains(lines[1], "rate limited") {
+		t.Errorf("failure record = %s", lines[1])
+	}
+
+	tests := []struct {
+		name    string
+		log     string
+		opts    []Option
+		wantErr string
+	}{
+		{
+			name:    "edited record",
+			log:     strings.Replace(log, `"cost":0.002`, `"cost":0.001`, 1),
+			wantErr: "hash mismatch",
+		},
+		{
+			name:    "removed record",
+			log:     lines[0] + "\n" + lines[2] + "\n",
+			wantErr: "sequence 3 follows 1",
+		},
+		{
+			name:    "wrong signing key",
+			log:     log,
+			opts:    []Option{WithSigningKey([]byte("other"))},
+			wantErr: "invalid signature",
+		},
+		{
+			name:    "not json",
+			log:     "{",
+			wantErr: "invalid record",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Verify(strings.NewReader(tt.log), tt.opts...)
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Verify() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestLoggerContent(t *testing.T) {
+	var buf bytes.Buffer
+	key := bytes.Repeat([]byte{1}, 32)
+	l := New(NewWriterSink(&buf), WithContent(key))
+
+	l.Success(context.Background(), successEvent())
+	if strings.Contains(buf.String(), "secret") {
+		t.Fatal("content stored in plaintext")
+	}
+
+	last, err := Verify(&buf)
+	if err != nil {
+		t.Fatalf("Verify() error = %v", err)
+	}
+
+	content, err := DecryptContent(last, key)
+	if err != nil {
+		t.Fatalf("DecryptContent() error = %v", err)
+	}
+	if content.Messages[0].Content != "secret prompt" || content.Response.Choices[0].Message.Content != "secret answer" {
+		t.Errorf("content = %+v", content)
+	}
+
+	if _, err := DecryptContent(last, bytes.Repeat([]byte{2}, 32)); err == nil {
+		t.Error("DecryptContent() with wrong key succeeded")
+	}
+}
+
+func TestLoggerErrors(t *testing.T) {
+	var handled []error
+	l := New(failingSink{}, WithErrorHandler(func(err error) { handled = append(handled, err) }))
+
+	l.Success(context.Background(), successEvent())
+	if len(handled) != 1 || !strings.Contains(handled[0].Error(), "disk full") {
+		t.Errorf("handled = %v", handled)
+	}
+	if l.seq != 0 || l.prevHash != "" {
+		t.Error("chain advanced after failed write")
+	}
+
+	l = New(NewWriterSink(&bytes.Buffer{}), WithContent([]byte("short")))
+	if err := l.Log(context.Background(), &Record{}); err == nil || !strings.Contains(err.Error(), "invalid content key") {
+		t.Errorf("Log() error = %v, want invalid content key", err)
+	}
+}
+
+func TestFileSinkResume(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.jsonl")
+	ctx := context.Background()
+
+	for run := 0; run < 2; run++ {
+		f, err := os.Open(path)
+		var last *Record
+		if err == nil {
+			last, err = Verify(f)
+			f.Close()
+			if err != nil {
+				t.Fatalf("run %d: Verify() error = %v", run, err)
+			}
+		}
+
+		sink, err := NewFileSink(path)
+		if err != nil {
+			t.Fatalf("NewFileSink() error = %v", err)
+		}
+		l := New(sink, WithResume(last))
+		for i := 0; i < 2; i++ {
+			if err := l.Log(ctx, &Record{Provider: "openai", Model: fmt.Sprint(run, i), Status: StatusSuccess}); err != nil {
+				t.Fatalf("Log() error = %v", err)
+			}
+		}
+		sink.Close()
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	last, err := Verify(f)
+	if err != nil {
+		t.Fatalf("Verify() error = %v", err)
+	}
+	if last.Seq != 4 || last.Model != "1 1" {
+		t.Errorf("last = %+v, want seq 4", last)
+	}
+}
+
+func TestLoggerClientIntegration(t *testing.T) {
+	var buf bytes.Buffer
+	l := New(NewWriterSink(&buf))
+
+	client, err := warp.NewClient(
+		warp.WithSuccessCallback(l.Success),
+		warp.WithFailureCallback(l.Failure),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+
+	calls := 0
+	client.RegisterProvider(&testutil.MockProvider{
+		CompletionFunc: func(ctx context.Context, req *warp.CompletionRequest) (*warp.CompletionResponse, error) {
+			calls++
+			if calls == 2 {
+				return nil, errors.New("boom")
+			}
+			return testutil.CompletionResponseFixture(), nil
+		},
+	})
+
+	req := &warp.CompletionRequest{Model: "mock/m", Messages: []warp.Message{{Role: "user", Content: "hi"}}}
+	client.Completion(context.Background(), req)
+	client.Completion(context.Background(), req)
+
+	last, err := Verify(&buf)
+	if err != nil {
+		t.Fatalf("Verify() error = %v", err)
+	}
+	if last == nil || last.Seq != 2 || last.Status != StatusFailure || last.Provider != "mock" || last.Model != "m" {
+		t.Errorf("last = %+v, want failure record 2", last)
+	}
+}
+
+func TestLoggerViolation(t *testing.T) {
+	var buf bytes.Buffer
+	l := New(NewWriterSink(&buf), WithContent(bytes.Repeat([]byte{1}, 16)))
+
+	ctx := warp.WithRequestID(context.Background(), "req-9")
+	l.Violation(ctx, warp.NewPolicyViolationError("model is banned", "banned_models", "acme", "openai", "gpt-3.5"))
+
+	last, err := Verify(&buf)
+	if err != nil {
+		t.Fatalf("Verify() error = %v", err)
+	}
+	if last.Status != StatusDenied || last.Tenant != "acme" || last.RequestID != "req-9" ||
+		last.Error != "banned_models: model is banned" || last.Content != nil {
+		t.Errorf("record = %+v", last)
+	}
+}
+
+func TestLoggerContentKeys(t *testing.T) {
+	ctx := context.Background()
+	keys, err := encrypt.NewKeyRing("k1", bytes.Repeat([]byte{1}, 32))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var buf bytes.Buffer
+	l := New(NewWriterSink(&buf), WithContentKeys(keys))
+	l.Success(ctx, successEvent())
+	keys.Rotate("k2", bytes.Repeat([]byte{2}, 32))
+	l.Success(ctx, successEvent())
+
+	if strings.Contains(buf.String(), "secret") {
+		t.Fatal("content stored in plaintext")
+	}
+
+	lines := strings.SplitAfter(buf.String(), "\n")
+	for i, wantKey := range []string{"k1", "k2"} {
+		var rec Record
+		if err := json.Unmarshal([]byte(lines[i]), &rec); err != nil {
+			t.Fatal(err)
+		}
+		if rec.Content.KeyID != wantKey {
+			t.Errorf("record %d key = %q, want %q", i+1, rec.Content.KeyID, wantKey)
+		}
+		content, err := DecryptContentWithKeys(ctx, &rec, keys)
+		if err != nil || content.Messages[0].Content != "secret prompt" {
+			t.Errorf("record %d: DecryptContentWithKeys() = %+v, %v", i+1, content, err)
+		}
+	}
+}
@@ -0,0 +1,76 @@
+//go:build example
+// +build example
+
+package audit
+
+// This file is an EXAMPLE showing how to implement the Sink interface
+// with PostgreSQL. It is excluded from normal builds using the "example" build tag.
+//
+// Users should copy this pattern and use their own database driver.
+//
+// Suggested schema. The primary key on seq rejects duplicate or replayed
+// records; revoke UPDATE and DELETE from the application role to make the
+// table append-only:
+//
+//	CREATE TABLE warp_audit (
+//	    seq        BIGINT PRIMARY KEY,
+//	    time       TIMESTAMPTZ NOT NULL,
+//	    request_id TEXT,
+//	    provider   TEXT NOT NULL,
+//	    model      TEXT NOT NULL,
+//	    status     TEXT NOT NULL,
+//	    hash       TEXT NOT NULL,
+//	    record     JSONB NOT NULL
+//	);
+//	REVOKE UPDATE, DELETE ON warp_audit FROM warp_app;
+//
+// Example usage:
+//
+//	import (
+//	    "database/sql"
+//	    _ "github.com/jackc/pgx/v5/stdlib"
+//	    "github.com/blue-context/warp/audit"
+//	)
+//
+//	db, _ := sql.Open("pgx", os.Getenv("DATABASE_URL"))
+//	logger := audit.New(NewPostgresSink(db), audit.WithSigningKey(signingKey))
+
+/*
+Example Postgres sink implementation (requires a database/sql driver such as pgx):
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+)
+
+// PostgresSink implements the Sink interface by inserting rows into warp_audit.
+//
+// Thread Safety: Safe for concurrent use (database/sql handles concurrency).
+type PostgresSink struct {
+	db *sql.DB
+}
+
+// NewPostgresSink creates a sink writing to the warp_audit table.
+func NewPostgresSink(db *sql.DB) *PostgresSink {
+	return &PostgresSink{db: db}
+}
+
+// Write inserts rec. The full record is stored as JSON so the chain can be
+// verified by exporting the record column in seq order and passing it to
+// audit.Verify.
+func (s *PostgresSink) Write(ctx context.Context, rec *audit.Record) error {
+	data, err := json.Marshal(rec)
+	if err != nil {
+		return err
+	}
+
+	_, err = s.db.ExecContext(ctx,
+		`INSERT INTO warp_audit (seq, time, request_id, provider, model, status, hash, record)
+		 VALUES ($1, $2, $3, $4, $5, $6, $7, $8)`,
+		rec.Seq, rec.Time, rec.RequestID, rec.Provider, rec.Model, rec.Status, rec.Hash, data,
+	)
+	return err
+}
+
+*/
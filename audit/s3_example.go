@@ -0,0 +1,73 @@
+//go:build example
+// +build example
+
+package audit
+
+// This file is an EXAMPLE showing how to implement the Sink interface
+// with Amazon S3. It is excluded from normal builds using the "example" build tag.
+//
+// Users should copy this pattern and use their own AWS SDK version.
+//
+// S3 objects cannot be appended to, so this sink writes one object per
+// record under a prefix, keyed by zero-padded sequence number so that
+// listing the prefix returns records in chain order. Enable S3 Object Lock
+// on the bucket to make records immutable.
+//
+// Example usage:
+//
+//	import (
+//	    "github.com/aws/aws-sdk-go-v2/config"
+//	    "github.com/aws/aws-sdk-go-v2/service/s3"
+//	    "github.com/blue-context/warp/audit"
+//	)
+//
+//	cfg, _ := config.LoadDefaultConfig(ctx)
+//	sink := NewS3Sink(s3.NewFromConfig(cfg), "compliance-logs", "warp/audit/")
+//	logger := audit.New(sink, audit.WithSigningKey(signingKey))
+
+/*
+Example S3 sink implementation (requires github.com/aws/aws-sdk-go-v2/service/s3):
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+)
+
+// S3Sink implements the Sink interface by writing each record to an S3 object.
+//
+// Thread Safety: Safe for concurrent use (the S3 client handles concurrency).
+type S3Sink struct {
+	client *s3.Client
+	bucket string
+	prefix string
+}
+
+// NewS3Sink creates a sink writing records to bucket under prefix.
+func NewS3Sink(client *s3.Client, bucket, prefix string) *S3Sink {
+	return &S3Sink{client: client, bucket: bucket, prefix: prefix}
+}
+
+// Write stores rec as "<prefix><seq>.json".
+func (s *S3Sink) Write(ctx context.Context, rec *audit.Record) error {
+	data, err := json.Marshal(rec)
+	if err != nil {
+		return err
+	}
+
+	_, err = s.client.PutObject(ctx, &s3.PutObjectInput{
+		Bucket:      aws.String(s.bucket),
+		Key:         aws.String(fmt.Sprintf("%s%020d.json", s.prefix, rec.Seq)),
+		Body:        bytes.NewReader(data),
+		ContentType: aws.String("application/json"),
+		// Refuse to overwrite an existing record
+		IfNoneMatch: aws.String("*"),
+	})
+	return err
+}
+
+*/
@@ -0,0 +1,146 @@
+package audit
+
+import (
+	"bufio"
+	"context"
+	"crypto/hmac"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+)
+
+// WriterSink writes records as JSON lines to an io.Writer.
+//
+// Thread Safety: WriterSink is safe for concurrent use.
+type WriterSink struct {
+	mu sync.Mutex
+	w  io.Writer
+}
+
+// NewWriterSink creates a sink writing JSON lines to w.
+func NewWriterSink(w io.Writer) *WriterSink {
+	return &WriterSink{w: w}
+}
+
+// Write appends rec as a JSON line.
+func (s *WriterSink) Write(ctx context.Context, rec *Record) error {
+	data, err := json.Marshal(rec)
+	if err != nil {
+		return fmt.Errorf("failed to encode audit record: %w", err)
+	}
+	data = append(data, '\n')
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	_, err = s.w.Write(data)
+	return err
+}
+
+// FileSink appends records as JSON lines to a file, syncing after each
+// write so acknowledged records survive a crash.
+//
+// Thread Safety: FileSink is safe for concurrent use.
+type FileSink struct {
+	mu   sync.Mutex
+	file *os.File
+}
+
+// NewFileSink opens path for appending, creating it with mode 0600 if it
+// does not exist.
+//
+// To continue the chain of an existing file, verify it and pass the last
+// record to WithResume:
+//
+//	f, _ := os.Open(path)
+//	last, err := audit.Verify(f)
+//	f.Close()
+//	if err != nil {
+//	    return err
+//	}
+//	sink, err := audit.NewFileSink(path)
+//	logger := audit.New(sink, audit.WithResume(last))
+func NewFileSink(path string) (*FileSink, error) {
+	file, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o600)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open audit log: %w", err)
+	}
+	return &FileSink{file: file}, nil
+}
+
+// Write appends rec as a JSON line and syncs the file.
+func (s *FileSink) Write(ctx context.Context, rec *Record) error {
+	data, err := json.Marshal(rec)
+	if err != nil {
+		return fmt.Errorf("failed to encode audit record: %w", err)
+	}
+	data = append(data, '\n')
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, err := s.file.Write(data); err != nil {
+		return err
+	}
+	return s.file.Sync()
+}
+
+// Close closes the file.
+func (s *FileSink) Close() error {
+	return s.file.Close()
+}
+
+// Verify reads a JSON lines audit log and checks that every record's hash
+// is correct and links to its predecessor, and that sequence numbers are
+// contiguous. With WithSigningKey, signatures are checked as well.
+//
+// It returns the last record, for WithResume, or nil for an empty log.
+// The error identifies the first record that fails verification.
+func Verify(r io.Reader, opts ...Option) (*Record, error) {
+	l := New(nil, opts...)
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 64*1024*1024)
+
+	var last *Record
+	line := 0
+	for scanner.Scan() {
+		line++
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+
+		var rec Record
+		if err := json.Unmarshal(scanner.Bytes(), &rec); err != nil {
+			return last, fmt.Errorf("line %d: invalid record: %w", line, err)
+		}
+
+		if rec.Seq != l.seq+1 {
+			return last, fmt.Errorf("line %d: sequence %d follows %d", line, rec.Seq, l.seq)
+		}
+		if rec.PrevHash != l.prevHash {
+			return last, fmt.Errorf("line %d: record %d does not link to the previous record", line, rec.Seq)
+		}
+		hash, err := hashRecord(&rec)
+		if err != nil {
+			return last, err
+		}
+		if hash != rec.Hash {
+			return last, fmt.Errorf("line %d: record %d hash mismatch", line, rec.Seq)
+		}
+		if l.signingKey != nil && !hmac.Equal([]byte(rec.Signature), []byte(sign(l.signingKey, rec.Hash))) {
+			return last, fmt.Errorf("line %d: record %d has an invalid signature", line, rec.Seq)
+		}
+
+		l.seq = rec.Seq
+		l.prevHash = rec.Hash
+		last = &rec
+	}
+	if err := scanner.Err(); err != nil {
+		return last, fmt.Errorf("failed to read audit log: %w", err)
+	}
+
+	return last, nil
+}
@@ -0,0 +1,160 @@
+package warp
+
+import (
+	"context"
+	"fmt"
+)
+
+// WarningContinuationFailed is the callback.WarningEvent code raised when a
+// request to continue a truncated reply fails. The reply received so far
+// is returned, still stopped at the token limit.
+const WarningContinuationFailed = "continuation_failed"
+
+// maxContinuations bounds the follow-up requests of one completion, for
+// providers that do not report usage.
+const maxContinuations = 8
+
+// continuePrompt asks the model to resume its cut-off reply.
+const continuePrompt = "Continue exactly where your previous reply stopped. Do not repeat any of it or add commentary."
+
+// continueTruncated continues resp while it stopped at the token limit
+// and the AutoContinueTokens budget allows, stitching the pieces into
+// resp. req is the request resp answers; req.Model has no provider
+// prefix. Only single-choice text replies are continued.
+//
+// Each continuation sends the conversation with the output so far as an
+// assistant message and a user message asking the model to go on. The
+// continuations are sent without the response format, since each one is
+// a fragment of the reply. If a continuation fails, the reply so far is
+// returned with a WarningContinuationFailed warning.
+func (c *client) continueTruncated(ctx context.Context, p Provider, req *CompletionRequest, resp *CompletionResponse) (*CompletionResponse, error) {
+	budget := c.config.AutoContinueTokens
+	if budget <= 0 || !continuable(resp) {
+		return resp, nil
+	}
+
+	count := c.tokenCounter()
+	text := messageText(resp.Choices[0].Message.Content)
+	used := completionTokens(resp, text, count)
+	continuations := 0
+
+	for continuations < maxContinuations && used < budget && continuable(resp) {
+		next := *req
+		next.ResponseFormat = nil
+		next.Messages = make([]Message, 0, len(req.Messages)+2)
+		next.Messages = append(next.Messages, req.Messages...)
+		next.Messages = append(next.Messages,
+			Message{Role: "assistant", Content: text},
+			Message{Role: "user", Content: continuePrompt},
+		)
+		remaining := budget - used
+		if next.MaxTokens == nil || *next.MaxTokens > remaining {
+			next.MaxTokens = IntPtr(remaining)
+		}
+
+		var part *CompletionResponse
+		err := c.withRetry(ctx, func() error {
+			var callErr error
+			part, callErr = c.complete(ctx, p, &next)
+			return callErr
+		})
+		if err != nil {
+			c.warn(ctx, WarningContinuationFailed, fmt.Sprintf("continuing the reply of %s failed after %d continuations: %v", req.Model, continuations, err))
+			break
+		}
+		if len(part.Choices) == 0 {
+			break
+		}
+
+		partText := messageText(part.Choices[0].Message.Content)
+		continuations++
+		used += completionTokens(part, partText, count)
+		text += partText
+		resp.Choices[0].FinishReason = part.Choices[0].FinishReason
+		resp.Choices[0].Message.Content = text
+		resp.Usage = addUsage(resp.Usage, part.Usage)
+		if partText == "" {
+			break
+		}
+	}
+
+	if continuations > 0 {
+		if resp.HiddenParams == nil {
+			resp.HiddenParams = make(map[string]any)
+		}
+		resp.HiddenParams["_continuations"] = continuations
+	}
+	return resp, nil
+}
+
+// continuable reports whether resp is a single text choice cut off by the
+// token limit.
+func continuable(resp *CompletionResponse) bool {
+	if resp == nil || len(resp.Choices) != 1 {
+		return false
+	}
+	choice := resp.Choices[0]
+	if !choice.Reason().IsTruncated() || len(choice.Message.ToolCalls) > 0 {
+		return false
+	}
+	_, ok := choice.Message.Content.(string)
+	return ok
+}
+
+// completionTokens returns the output tokens of resp, counting text if
+// the provider did not report usage.
+func completionTokens(resp *CompletionResponse, text string, count func(string) int) int {
+	if resp.Usage != nil && resp.Usage.CompletionTokens > 0 {
+		return resp.Usage.CompletionTokens
+	}
+	return count(text)
+}
+
+// addUsage returns the sum of two usages, including their token details,
+// which cost calculation prices separately. Either may be nil.
+func addUsage(a, b *Usage) *Usage {
+	if a == nil || b == nil {
+		if a == nil {
+			return b
+		}
+		return a
+	}
+	return &Usage{
+		PromptTokens:      a.PromptTokens + b.PromptTokens,
+		CompletionTokens:  a.CompletionTokens + b.CompletionTokens,
+		TotalTokens:       a.TotalTokens + b.TotalTokens,
+		PromptDetails:     addPromptDetails(a.PromptDetails, b.PromptDetails),
+		CompletionDetails: addCompletionDetails(a.CompletionDetails, b.CompletionDetails),
+	}
+}
+
+// addPromptDetails returns the sum of two prompt token details. Either may
+// be nil.
+func addPromptDetails(a, b *PromptTokensDetails) *PromptTokensDetails {
+	if a == nil || b == nil {
+		if a == nil {
+			return b
+		}
+		return a
+	}
+	return &PromptTokensDetails{
+		CachedTokens:     a.CachedTokens + b.CachedTokens,
+		CacheWriteTokens: a.CacheWriteTokens + b.CacheWriteTokens,
+		AudioTokens:      a.AudioTokens + b.AudioTokens,
+	}
+}
+
+// addCompletionDetails returns the sum of two completion token details.
+// Either may be nil.
+func addCompletionDetails(a, b *CompletionTokensDetails) *CompletionTokensDetails {
+	if a == nil || b == nil {
+		if a == nil {
+			return b
+		}
+		return a
+	}
+	return &CompletionTokensDetails{
+		ReasoningTokens: a.ReasoningTokens + b.ReasoningTokens,
+		AudioTokens:     a.AudioTokens + b.AudioTokens,
+	}
+}
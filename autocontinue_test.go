@@ -0,0 +1,203 @@
+package warp
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/blue-context/warp/callback"
+)
+
+// continuingProvider replies with the next of parts on each request,
+// stopping at the token limit until the last one.
+func continuingProvider(parts []string, requests *[]*CompletionRequest) *mockProvider {
+	return &mockProvider{
+		name: "mock",
+		completionFunc: func(ctx context.Context, req *CompletionRequest) (*CompletionResponse, error) {
+			i := len(*requests)
+			*requests = append(*requests, req)
+			if i >= len(parts) {
+				return nil, errors.New("unexpected request")
+			}
+			finish := "length"
+			if i == len(parts)-1 {
+				finish = "stop"
+			}
+			return &CompletionResponse{
+				Choices: []Choice{{Message: Message{Role: "assistant", Content: parts[i]}, FinishReason: finish}},
+				Usage: &Usage{
+					PromptTokens: 10, CompletionTokens: 5, TotalTokens: 15,
+					PromptDetails:     &PromptTokensDetails{CachedTokens: 4},
+					CompletionDetails: &CompletionTokensDetails{ReasoningTokens: 2},
+				},
+			}, nil
+		},
+	}
+}
+
+func TestAutoContinue(t *testing.T) {
+	client, err := NewClient(WithAutoContinue(100))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var requests []*CompletionRequest
+	client.RegisterProvider(continuingProvider([]string{"Once upon ", "a time ", "the end."}, &requests))
+
+	resp, err := client.Completion(context.Background(), &CompletionRequest{
+		Model:          "mock/model",
+		Messages:       []Message{{Role: "user", Content: "Tell a story"}},
+		MaxTokens:      IntPtr(5),
+		ResponseFormat: &ResponseFormat{Type: "text"},
+	})
+	if err != nil {
+		t.Fatalf("Completion() error = %v", err)
+	}
+
+	if got := resp.Choices[0].Message.Content; got != "Once upon a time the end." {
+		t.Errorf("content = %q, want the stitched reply", got)
+	}
+	if resp.Choices[0].FinishReason != "stop" || resp.Truncated() {
+		t.Errorf("finish reason = %q, want stop", resp.Choices[0].FinishReason)
+	}
+	if resp.Usage.CompletionTokens != 15 || resp.Usage.PromptTokens != 30 || resp.Usage.TotalTokens != 45 {
+		t.Errorf("usage = %+v, want the sum of 3 requests", resp.Usage)
+	}
+	if resp.Usage.GetCachedTokens() != 12 || resp.Usage.GetReasoningTokens() != 6 {
+		t.Errorf("usage details = %+v, %+v, want the sum of 3 requests", resp.Usage.PromptDetails, resp.Usage.CompletionDetails)
+	}
+	if resp.HiddenParams["_continuations"] != 2 {
+		t.Errorf("_continuations = %v, want 2", resp.HiddenParams["_continuations"])
+	}
+
+	if len(requests) != 3 {
+		t.Fatalf("requests = %d, want 3", len(requests))
+	}
+	last := requests[2]
+	if n := len(last.Messages); n != 3 || last.Messages[1].Role != "assistant" || last.Messages[1].Content != "Once upon a time " ||
+		last.Messages[2].Role != "user" {
+		t.Errorf("continuation messages = %+v, want the output so far and a continue prompt", last.Messages)
+	}
+	if last.ResponseFormat != nil || *last.MaxTokens != 5 {
+		t.Errorf("continuation = %+v, want no response format and the original max tokens", last)
+	}
+}
+
+func TestAutoContinue_ContinuationFails(t *testing.T) {
+	var warnings []*callback.WarningEvent
+	client, err := NewClient(WithAutoContinue(100), WithMaxRetries(0), WithWarningCallback(func(ctx context.Context, event *callback.WarningEvent) {
+		warnings = append(warnings, event)
+	}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var requests []*CompletionRequest
+	client.RegisterProvider(&mockProvider{
+		name: "mock",
+		completionFunc: func(ctx context.Context, req *CompletionRequest) (*CompletionResponse, error) {
+			requests = append(requests, req)
+			if len(requests) > 1 {
+				return nil, errors.New("server overloaded")
+			}
+			return &CompletionResponse{
+				Choices: []Choice{{Message: Message{Role: "assistant", Content: "Once upon "}, FinishReason: "length"}},
+				Usage: &Usage{
+					PromptTokens: 10, CompletionTokens: 5, TotalTokens: 15,
+					PromptDetails:     &PromptTokensDetails{CachedTokens: 4},
+					CompletionDetails: &CompletionTokensDetails{ReasoningTokens: 2},
+				},
+			}, nil
+		},
+	})
+
+	resp, err := client.Completion(context.Background(), &CompletionRequest{
+		Model:    "mock/model",
+		Messages: []Message{{Role: "user", Content: "Tell a story"}},
+	})
+	if err != nil {
+		t.Fatalf("Completion() error = %v, want the partial reply", err)
+	}
+	if got := resp.Choices[0].Message.Content; got != "Once upon " || resp.Choices[0].FinishReason != "length" {
+		t.Errorf("choice = %+v, want the first reply, still truncated", resp.Choices[0])
+	}
+	if len(requests) != 2 {
+		t.Errorf("requests = %d, want 2", len(requests))
+	}
+	var failed bool
+	for _, w := range warnings {
+		failed = failed || w.Code == WarningContinuationFailed
+	}
+	if !failed {
+		t.Errorf("warnings = %+v, want continuation_failed", warnings)
+	}
+}
+
+func TestAutoContinue_Budget(t *testing.T) {
+	client, err := NewClient(WithAutoContinue(12))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var requests []*CompletionRequest
+	client.RegisterProvider(continuingProvider([]string{"a", "b", "c", "d"}, &requests))
+
+	resp, err := client.Completion(context.Background(), &CompletionRequest{
+		Model:    "mock/model",
+		Messages: []Message{{Role: "user", Content: "Hi"}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// 5 tokens per reply: continue at 5 and 10 tokens, stop at 15
+	if len(requests) != 3 || resp.Choices[0].Message.Content != "abc" || !resp.Truncated() {
+		t.Errorf("requests = %d, content %q, want 3 requests and a still truncated reply", len(requests), resp.Choices[0].Message.Content)
+	}
+	if got := *requests[2].MaxTokens; got != 2 {
+		t.Errorf("last max tokens = %d, want the remaining budget 2", got)
+	}
+}
+
+func TestAutoContinue_Disabled(t *testing.T) {
+	tests := []struct {
+		name string
+		opts []ClientOption
+		resp *CompletionResponse
+	}{
+		{"disabled", nil, &CompletionResponse{Choices: []Choice{{Message: Message{Content: "a"}, FinishReason: "length"}}}},
+		{"tool calls", []ClientOption{WithAutoContinue(100)}, &CompletionResponse{Choices: []Choice{{
+			Message: Message{ToolCalls: []ToolCall{{ID: "1", Type: "function"}}}, FinishReason: "length",
+		}}}},
+		{"several choices", []ClientOption{WithAutoContinue(100)}, &CompletionResponse{Choices: []Choice{
+			{Message: Message{Content: "a"}, FinishReason: "length"},
+			{Index: 1, Message: Message{Content: "b"}, FinishReason: "length"},
+		}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client, err := NewClient(tt.opts...)
+			if err != nil {
+				t.Fatal(err)
+			}
+			calls := 0
+			client.RegisterProvider(&mockProvider{
+				name: "mock",
+				completionFunc: func(ctx context.Context, req *CompletionRequest) (*CompletionResponse, error) {
+					calls++
+					return tt.resp, nil
+				},
+			})
+			if _, err := client.Completion(context.Background(), &CompletionRequest{
+				Model:    "mock/model",
+				Messages: []Message{{Role: "user", Content: "Hi"}},
+			}); err != nil {
+				t.Fatal(err)
+			}
+			if calls != 1 {
+				t.Errorf("calls = %d, want no continuation", calls)
+			}
+		})
+	}
+
+	if _, err := NewClient(WithAutoContinue(-1)); err == nil {
+		t.Error("NewClient(WithAutoContinue(-1)) error = nil")
+	}
+}
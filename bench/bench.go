@@ -0,0 +1,400 @@
+// Package bench measures the serving performance of a model behind warp.
+//
+// A Runner streams completions at each configured concurrency level and
+// prompt size and records, per scenario, request throughput, time to first
+// token (TTFT), end-to-end latency, and output tokens per second. Reports
+// are plain structs with JSON tags and can be written as JSON or CSV for
+// capacity planning, such as sizing a vLLM deployment.
+//
+// Basic usage:
+//
+//	runner := bench.New(client, "vllm/meta-llama/Llama-3.1-8B-Instruct",
+//	    bench.WithConcurrency(1, 8, 32),
+//	    bench.WithPromptTokens(256, 2048),
+//	    bench.WithRequests(100),
+//	)
+//	report, err := runner.Run(ctx)
+//	if err != nil {
+//	    return err
+//	}
+//	err = report.WriteJSON(os.Stdout)
+package bench
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/blue-context/warp"
+)
+
+// Report holds the results of a benchmark run.
+type Report struct {
+	// Model is the model benchmarked, in "provider/model-name" form.
+	Model string `json:"model"`
+
+	// StartedAt is when the run started.
+	StartedAt time.Time `json:"started_at"`
+
+	// Scenarios holds one result per concurrency level and prompt size.
+	Scenarios []Scenario `json:"scenarios"`
+}
+
+// Scenario is the result of one concurrency level and prompt size.
+type Scenario struct {
+	// Concurrency is the number of requests in flight.
+	Concurrency int `json:"concurrency"`
+
+	// PromptTokens is the approximate prompt size requested.
+	PromptTokens int `json:"prompt_tokens"`
+
+	// Requests is the number of requests sent.
+	Requests int `json:"requests"`
+
+	// Errors is the number of requests that failed.
+	Errors int `json:"errors"`
+
+	// DurationSeconds is the wall time of the scenario.
+	DurationSeconds float64 `json:"duration_seconds"`
+
+	// RequestsPerSecond is the rate of successful requests.
+	RequestsPerSecond float64 `json:"requests_per_second"`
+
+	// OutputTokensPerSecond is the aggregate rate of generated tokens
+	// across all requests.
+	OutputTokensPerSecond float64 `json:"output_tokens_per_second"`
+
+	// TTFTMillis is the time to first token of successful requests.
+	TTFTMillis Stats `json:"ttft_ms"`
+
+	// LatencyMillis is the end-to-end latency of successful requests.
+	LatencyMillis Stats `json:"latency_ms"`
+
+	// TokensPerSecond is the per-request generation rate after the first
+	// token.
+	TokensPerSecond Stats `json:"tokens_per_second"`
+
+	// FirstError is the message of the first error, if any.
+	FirstError string `json:"first_error,omitempty"`
+}
+
+// Stats summarizes a set of measurements.
+type Stats struct {
+	Mean float64 `json:"mean"`
+	Min  float64 `json:"min"`
+	P50  float64 `json:"p50"`
+	P90  float64 `json:"p90"`
+	P99  float64 `json:"p99"`
+	Max  float64 `json:"max"`
+}
+
+// Runner runs benchmarks against one model.
+//
+// Thread Safety: Runner is safe for concurrent use, but concurrent runs
+// distort each other's measurements.
+type Runner struct {
+	client       warp.Client
+	model        string
+	concurrency  []int
+	promptTokens []int
+	requests     int
+	maxTokens    int
+	warmup       int
+}
+
+// Option configures a Runner.
+type Option func(*Runner)
+
+// WithConcurrency sets the concurrency levels to measure (default 1).
+func WithConcurrency(levels ...int) Option {
+	return func(r *Runner) {
+		r.concurrency = levels
+	}
+}
+
+// WithPromptTokens sets the approximate prompt sizes, in tokens, to
+// measure (default 128). Prompts are filler text of about that length.
+func WithPromptTokens(sizes ...int) Option {
+	return func(r *Runner) {
+		r.promptTokens = sizes
+	}
+}
+
+// WithRequests sets the number of requests per scenario (default 20).
+func WithRequests(n int) Option {
+	return func(r *Runner) {
+		r.requests = n
+	}
+}
+
+// WithMaxTokens sets the maximum output tokens per request (default 128).
+func WithMaxTokens(n int) Option {
+	return func(r *Runner) {
+		r.maxTokens = n
+	}
+}
+
+// WithWarmup sets how many requests are sent before each scenario without
+// being measured (default 0), to load the model and fill caches.
+func WithWarmup(n int) Option {
+	return func(r *Runner) {
+		r.warmup = n
+	}
+}
+
+// New creates a Runner for model, in "provider/model-name" form, using
+// client.
+func New(client warp.Client, model string, opts ...Option) *Runner {
+	r := &Runner{
+		client:       client,
+		model:        model,
+		concurrency:  []int{1},
+		promptTokens: []int{128},
+		requests:     20,
+		maxTokens:    128,
+	}
+
+	for _, opt := range opts {
+		opt(r)
+	}
+
+	return r
+}
+
+// Run measures every combination of concurrency level and prompt size, in
+// order. A failing request counts as an error in its scenario; Run only
+// fails on invalid settings or when ctx is done.
+func (r *Runner) Run(ctx context.Context) (*Report, error) {
+	if r.model == "" {
+		return nil, fmt.Errorf("model is required")
+	}
+	if r.requests < 1 || r.maxTokens < 1 {
+		return nil, fmt.Errorf("requests and max tokens must be positive")
+	}
+	for _, n := range r.concurrency {
+		if n < 1 {
+			return nil, fmt.Errorf("concurrency must be positive, got %d", n)
+		}
+	}
+
+	report := &Report{Model: r.model, StartedAt: time.Now()}
+	for _, size := range r.promptTokens {
+		for _, concurrency := range r.concurrency {
+			if err := ctx.Err(); err != nil {
+				return report, err
+			}
+			report.Scenarios = append(report.Scenarios, r.runScenario(ctx, concurrency, size))
+		}
+	}
+	return report, nil
+}
+
+// sample is the measurement of one request.
+type sample struct {
+	ttft    time.Duration
+	latency time.Duration
+	tokens  int
+	err     error
+}
+
+// runScenario sends r.requests requests with the given concurrency.
+func (r *Runner) runScenario(ctx context.Context, concurrency, promptTokens int) Scenario {
+	prompt := fillerPrompt(promptTokens)
+	for i := 0; i < r.warmup; i++ {
+		r.measure(ctx, prompt)
+	}
+
+	samples := make([]sample, r.requests)
+	next := make(chan int)
+	var wg sync.WaitGroup
+	start := time.Now()
+	for w := 0; w < concurrency; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range next {
+				samples[i] = r.measure(ctx, prompt)
+			}
+		}()
+	}
+	for i := range samples {
+		next <- i
+	}
+	close(next)
+	wg.Wait()
+
+	return summarize(samples, concurrency, promptTokens, time.Since(start))
+}
+
+// measure streams one completion.
+func (r *Runner) measure(ctx context.Context, prompt string) sample {
+	start := time.Now()
+	stream, err := r.client.CompletionStream(ctx, &warp.CompletionRequest{
+		Model:     r.model,
+		Messages:  []warp.Message{{Role: "user", Content: prompt}},
+		MaxTokens: warp.IntPtr(r.maxTokens),
+	})
+	if err != nil {
+		return sample{err: err}
+	}
+	defer stream.Close()
+
+	var s sample
+	chunks := 0
+	for {
+		chunk, err := stream.Recv()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return sample{err: err}
+		}
+		if chunk.Usage != nil && chunk.Usage.CompletionTokens > 0 {
+			s.tokens = chunk.Usage.CompletionTokens
+		}
+		for _, choice := range chunk.Choices {
+			if choice.Delta.Content == "" {
+				continue
+			}
+			if chunks == 0 {
+				s.ttft = time.Since(start)
+			}
+			chunks++
+		}
+	}
+	s.latency = time.Since(start)
+	if s.tokens == 0 {
+		// Without usage, count content chunks, which are about one token
+		// each on most servers.
+		s.tokens = chunks
+	}
+	return s
+}
+
+// summarize computes the scenario result from its samples.
+func summarize(samples []sample, concurrency, promptTokens int, elapsed time.Duration) Scenario {
+	sc := Scenario{
+		Concurrency:     concurrency,
+		PromptTokens:    promptTokens,
+		Requests:        len(samples),
+		DurationSeconds: elapsed.Seconds(),
+	}
+
+	var ttft, latency, rate []float64
+	tokens := 0
+	for _, s := range samples {
+		if s.err != nil {
+			sc.Errors++
+			if sc.FirstError == "" {
+				sc.FirstError = s.err.Error()
+			}
+			continue
+		}
+		tokens += s.tokens
+		ttft = append(ttft, millis(s.ttft))
+		latency = append(latency, millis(s.latency))
+		if decode := s.latency - s.ttft; s.tokens > 1 && decode > 0 {
+			rate = append(rate, float64(s.tokens-1)/decode.Seconds())
+		}
+	}
+
+	if seconds := elapsed.Seconds(); seconds > 0 {
+		sc.RequestsPerSecond = float64(len(samples)-sc.Errors) / seconds
+		sc.OutputTokensPerSecond = float64(tokens) / seconds
+	}
+	sc.TTFTMillis = newStats(ttft)
+	sc.LatencyMillis = newStats(latency)
+	sc.TokensPerSecond = newStats(rate)
+	return sc
+}
+
+// newStats summarizes values, or returns zero Stats if there are none.
+func newStats(values []float64) Stats {
+	if len(values) == 0 {
+		return Stats{}
+	}
+	slices.Sort(values)
+	sum := 0.0
+	for _, v := range values {
+		sum += v
+	}
+	return Stats{
+		Mean: sum / float64(len(values)),
+		Min:  values[0],
+		P50:  percentile(values, 50),
+		P90:  percentile(values, 90),
+		P99:  percentile(values, 99),
+		Max:  values[len(values)-1],
+	}
+}
+
+// percentile returns the p-th percentile of sorted values using the
+// nearest-rank method.
+func percentile(sorted []float64, p float64) float64 {
+	rank := int(math.Ceil(p / 100 * float64(len(sorted))))
+	return sorted[max(rank-1, 0)]
+}
+
+// millis returns d in milliseconds.
+func millis(d time.Duration) float64 {
+	return float64(d) / float64(time.Millisecond)
+}
+
+// fillerWords are common words of about one token each.
+var fillerWords = strings.Fields(`the quick brown fox jumps over a lazy dog while
+	people walk by and talk about their day at work or school in the city`)
+
+// fillerPrompt returns a prompt of about tokens tokens.
+func fillerPrompt(tokens int) string {
+	const instruction = "Continue the following text with a short story.\n\n"
+	var b strings.Builder
+	b.WriteString(instruction)
+	for i := 0; i < tokens-10; i++ {
+		if i > 0 {
+			b.WriteByte(' ')
+		}
+		b.WriteString(fillerWords[i%len(fillerWords)])
+	}
+	return b.String()
+}
+
+// WriteJSON writes the report as indented JSON.
+func (r *Report) WriteJSON(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(r)
+}
+
+// WriteCSV writes one row per scenario with a header row. Times are in
+// milliseconds.
+func (r *Report) WriteCSV(w io.Writer) error {
+	cw := csv.NewWriter(w)
+	cw.Write([]string{
+		"model", "concurrency", "prompt_tokens", "requests", "errors", "duration_seconds",
+		"requests_per_second", "output_tokens_per_second",
+		"ttft_p50_ms", "ttft_p90_ms", "ttft_p99_ms",
+		"latency_p50_ms", "latency_p90_ms", "latency_p99_ms",
+		"tokens_per_second_p50",
+	})
+	f := func(v float64) string { return strconv.FormatFloat(v, 'f', 3, 64) }
+	for _, sc := range r.Scenarios {
+		cw.Write([]string{
+			r.Model, strconv.Itoa(sc.Concurrency), strconv.Itoa(sc.PromptTokens),
+			strconv.Itoa(sc.Requests), strconv.Itoa(sc.Errors), f(sc.DurationSeconds),
+			f(sc.RequestsPerSecond), f(sc.OutputTokensPerSecond),
+			f(sc.TTFTMillis.P50), f(sc.TTFTMillis.P90), f(sc.TTFTMillis.P99),
+			f(sc.LatencyMillis.P50), f(sc.LatencyMillis.P90), f(sc.LatencyMillis.P99),
+			f(sc.TokensPerSecond.P50),
+		})
+	}
+	cw.Flush()
+	return cw.Error()
+}
@@ -0,0 +1,116 @@
+package bench
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/blue-context/warp"
+	"github.com/blue-context/warp/internal/testutil"
+)
+
+// streamProvider is a provider whose streams are safe to open from
+// concurrent workers; the third stream fails.
+type streamProvider struct {
+	testutil.MockProvider
+	calls atomic.Int32
+}
+
+func (p *streamProvider) CompletionStream(ctx context.Context, req *warp.CompletionRequest) (warp.Stream, error) {
+	if p.calls.Add(1) == 3 {
+		return nil, errors.New("server overloaded")
+	}
+	time.Sleep(time.Millisecond)
+	return testutil.NewMockStream(
+		&warp.CompletionChunk{Choices: []warp.ChunkChoice{{Delta: warp.MessageDelta{Content: "Once"}}}},
+		&warp.CompletionChunk{Choices: []warp.ChunkChoice{{Delta: warp.MessageDelta{Content: " upon"}}}},
+		&warp.CompletionChunk{Usage: &warp.Usage{CompletionTokens: 5}},
+	), nil
+}
+
+func TestRun(t *testing.T) {
+	mock := &streamProvider{}
+	client, err := warp.NewClient()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+	client.RegisterProvider(mock)
+
+	report, err := New(client, "mock/model",
+		WithConcurrency(1, 2),
+		WithPromptTokens(64),
+		WithRequests(4),
+	).Run(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(report.Scenarios) != 2 {
+		t.Fatalf("got %d scenarios, want 2", len(report.Scenarios))
+	}
+	first := report.Scenarios[0]
+	if first.Concurrency != 1 || first.PromptTokens != 64 || first.Requests != 4 {
+		t.Errorf("scenario = %+v", first)
+	}
+	if first.Errors != 1 || first.FirstError == "" {
+		t.Errorf("Errors = %d, FirstError = %q, want the third request failed", first.Errors, first.FirstError)
+	}
+	if first.TTFTMillis.P50 <= 0 || first.LatencyMillis.Max < first.TTFTMillis.Max {
+		t.Errorf("TTFT = %+v, latency = %+v", first.TTFTMillis, first.LatencyMillis)
+	}
+	if first.RequestsPerSecond <= 0 || first.OutputTokensPerSecond <= 0 {
+		t.Errorf("throughput = %v req/s, %v tokens/s", first.RequestsPerSecond, first.OutputTokensPerSecond)
+	}
+	if report.Scenarios[1].Errors != 0 {
+		t.Errorf("second scenario errors = %d, want 0", report.Scenarios[1].Errors)
+	}
+
+	var buf bytes.Buffer
+	if err := report.WriteJSON(&buf); err != nil {
+		t.Fatal(err)
+	}
+	var decoded Report
+	if err := json.Unmarshal(buf.Bytes(), &decoded); err != nil || len(decoded.Scenarios) != 2 {
+		t.Errorf("WriteJSON() output does not round-trip: %v", err)
+	}
+
+	buf.Reset()
+	if err := report.WriteCSV(&buf); err != nil {
+		t.Fatal(err)
+	}
+	if lines := strings.Split(strings.TrimSpace(buf.String()), "\n"); len(lines) != 3 {
+		t.Errorf("WriteCSV() wrote %d lines, want header and 2 rows", len(lines))
+	}
+}
+
+func TestRunInvalid(t *testing.T) {
+	client, err := warp.NewClient()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+
+	if _, err := New(client, "").Run(context.Background()); err == nil {
+		t.Error("Run() without model error = nil")
+	}
+	if _, err := New(client, "mock/model", WithConcurrency(0)).Run(context.Background()); err == nil {
+		t.Error("Run() with zero concurrency error = nil")
+	}
+}
+
+func TestNewStats(t *testing.T) {
+	stats := newStats([]float64{5, 1, 4, 2, 3})
+	want := Stats{Mean: 3, Min: 1, P50: 3, P90: 5, P99: 5, Max: 5}
+	if stats != want {
+		t.Errorf("newStats() = %+v, want %+v", stats, want)
+	}
+	if got := newStats(nil); got != (Stats{}) {
+		t.Errorf("newStats(nil) = %+v, want zero", got)
+	}
+}
@@ -0,0 +1,179 @@
+package warp
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// WarningBulkheadRejected is the callback.WarningEvent code raised when a
+// request is rejected because its provider's bulkhead is full.
+const WarningBulkheadRejected = "bulkhead_rejected"
+
+// Bulkhead limits the concurrent requests to one provider, so a slow
+// provider holding many requests open cannot starve the others sharing a
+// client. See WithBulkhead.
+type Bulkhead struct {
+	// MaxConcurrent is the maximum number of requests in flight to the
+	// provider. Streams count until they are closed.
+	MaxConcurrent int
+
+	// MaxWait is how long a request waits for a free slot before it is
+	// rejected. Zero rejects requests at once when the bulkhead is full.
+	MaxWait time.Duration
+}
+
+// BulkheadStats reports the state of a provider's bulkhead.
+type BulkheadStats struct {
+	// Limit is the maximum number of concurrent requests.
+	Limit int
+
+	// InFlight is the number of requests holding a slot.
+	InFlight int
+
+	// Rejected is the number of requests rejected since the client was
+	// created.
+	Rejected int64
+}
+
+// bulkhead is the slot pool of one provider.
+type bulkhead struct {
+	slots    chan struct{}
+	maxWait  time.Duration
+	rejected atomic.Int64
+}
+
+// bulkheads holds the bulkheads of a client, created on first use.
+type bulkheads struct {
+	mu         sync.Mutex
+	config     map[string]Bulkhead
+	byProvider map[string]*bulkhead
+}
+
+// newBulkheads returns the bulkheads for config, or nil if none are
+// configured.
+func newBulkheads(config map[string]Bulkhead) *bulkheads {
+	if len(config) == 0 {
+		return nil
+	}
+	return &bulkheads{config: config, byProvider: make(map[string]*bulkhead)}
+}
+
+// get returns the bulkhead of provider, or nil if it has none. Providers
+// without their own setting use the "*" setting, each with its own slots.
+func (b *bulkheads) get(provider string) *bulkhead {
+	if b == nil {
+		return nil
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if bh, ok := b.byProvider[provider]; ok {
+		return bh
+	}
+	config, ok := b.config[provider]
+	if !ok {
+		config, ok = b.config["*"]
+	}
+	var bh *bulkhead
+	if ok {
+		bh = &bulkhead{slots: make(chan struct{}, config.MaxConcurrent), maxWait: config.MaxWait}
+	}
+	b.byProvider[provider] = bh
+	return bh
+}
+
+// acquireBulkhead takes a slot in provider's bulkhead, waiting up to its
+// MaxWait, and returns the function that frees it. It returns a
+// *BulkheadFullError if no slot became free, and a no-op release if the
+// provider has no bulkhead.
+func (c *client) acquireBulkhead(ctx context.Context, provider string) (release func(), err error) {
+	bh := c.bulkheads.get(provider)
+	if bh == nil {
+		return func() {}, nil
+	}
+
+	select {
+	case bh.slots <- struct{}{}:
+		return bh.release, nil
+	default:
+	}
+	if bh.maxWait > 0 {
+		timer := c.clock().NewTimer(bh.maxWait)
+		defer timer.Stop()
+		select {
+		case bh.slots <- struct{}{}:
+			return bh.release, nil
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-timer.C():
+		}
+	}
+
+	bh.rejected.Add(1)
+	c.warn(ctx, WarningBulkheadRejected, fmt.Sprintf("%s bulkhead full (%d concurrent requests)", provider, cap(bh.slots)))
+	return nil, NewBulkheadFullError(provider, cap(bh.slots))
+}
+
+// release frees a slot.
+func (b *bulkhead) release() {
+	<-b.slots
+}
+
+// bulkheadStream frees its bulkhead slot when closed.
+type bulkheadStream struct {
+	Stream
+	release func()
+	once    sync.Once
+}
+
+func (s *bulkheadStream) Close() error {
+	err := s.Stream.Close()
+	s.once.Do(s.release)
+	return err
+}
+
+// bulkheadReader frees its bulkhead slot when closed.
+type bulkheadReader struct {
+	io.ReadCloser
+	release func()
+	once    sync.Once
+}
+
+func (r *bulkheadReader) Close() error {
+	err := r.ReadCloser.Close()
+	r.once.Do(r.release)
+	return err
+}
+
+// BulkheadStats returns the state of each provider bulkhead used so far,
+// keyed by provider name.
+//
+// Example:
+//
+//	for provider, stats := range client.BulkheadStats() {
+//	    log.Printf("%s: %d/%d in flight, %d rejected", provider, stats.InFlight, stats.Limit, stats.Rejected)
+//	}
+func (c *client) BulkheadStats() map[string]BulkheadStats {
+	stats := make(map[string]BulkheadStats)
+	if c.bulkheads == nil {
+		return stats
+	}
+	c.bulkheads.mu.Lock()
+	defer c.bulkheads.mu.Unlock()
+
+	for provider, bh := range c.bulkheads.byProvider {
+		if bh == nil {
+			continue
+		}
+		stats[provider] = BulkheadStats{
+			Limit:    cap(bh.slots),
+			InFlight: len(bh.slots),
+			Rejected: bh.rejected.Load(),
+		}
+	}
+	return stats
+}
@@ -0,0 +1,151 @@
+package warp
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/blue-context/warp/callback"
+)
+
+func TestBulkheadRejects(t *testing.T) {
+	started := make(chan struct{})
+	unblock := make(chan struct{})
+	var calls atomic.Int32
+	var warnings atomic.Int32
+
+	client, err := NewClient(
+		WithBulkhead("slow", 1, 0),
+		WithRetries(2, time.Millisecond, 1),
+		WithWarningCallback(func(ctx context.Context, event *callback.WarningEvent) {
+			if event.Code == WarningBulkheadRejected {
+				warnings.Add(1)
+			}
+		}),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	client.RegisterProvider(&mockProvider{
+		name: "slow",
+		completionFunc: func(ctx context.Context, req *CompletionRequest) (*CompletionResponse, error) {
+			if calls.Add(1) == 1 {
+				close(started)
+				<-unblock
+			}
+			return &CompletionResponse{ID: "test"}, nil
+		},
+	})
+	client.RegisterProvider(&mockProvider{name: "fast"})
+
+	req := func(model string) *CompletionRequest {
+		return &CompletionRequest{Model: model, Messages: []Message{{Role: "user", Content: "Hi"}}}
+	}
+	done := make(chan error)
+	go func() {
+		_, err := client.Completion(context.Background(), req("slow/model"))
+		done <- err
+	}()
+	<-started
+
+	_, err = client.Completion(context.Background(), req("slow/model"))
+	var full *BulkheadFullError
+	if !errors.As(err, &full) {
+		t.Fatalf("Completion() error = %v, want *BulkheadFullError", err)
+	}
+	if full.Limit != 1 || full.Provider != "slow" {
+		t.Errorf("BulkheadFullError = %+v, want limit 1 for slow", full)
+	}
+	if got := calls.Load(); got != 1 {
+		t.Errorf("provider called %d times, want rejected request not retried", got)
+	}
+	if _, err := client.Completion(context.Background(), req("fast/model")); err != nil {
+		t.Errorf("Completion() on another provider error = %v", err)
+	}
+
+	stats := client.BulkheadStats()["slow"]
+	if stats != (BulkheadStats{Limit: 1, InFlight: 1, Rejected: 1}) {
+		t.Errorf("BulkheadStats() = %+v, want 1 in flight and 1 rejected", stats)
+	}
+	if got := warnings.Load(); got != 1 {
+		t.Errorf("got %d bulkhead warnings, want 1", got)
+	}
+
+	close(unblock)
+	if err := <-done; err != nil {
+		t.Fatalf("Completion() error = %v", err)
+	}
+	if _, err := client.Completion(context.Background(), req("slow/model")); err != nil {
+		t.Errorf("Completion() after release error = %v", err)
+	}
+}
+
+func TestBulkheadStreamHoldsSlot(t *testing.T) {
+	client, err := NewClient(WithBulkhead("*", 1, 0))
+	if err != nil {
+		t.Fatal(err)
+	}
+	client.RegisterProvider(&mockProvider{name: "mock"})
+
+	req := &CompletionRequest{Model: "mock/model", Messages: []Message{{Role: "user", Content: "Hi"}}}
+	stream, err := client.CompletionStream(context.Background(), req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var full *BulkheadFullError
+	if _, err := client.CompletionStream(context.Background(), req); !errors.As(err, &full) {
+		t.Fatalf("CompletionStream() error = %v, want *BulkheadFullError while stream is open", err)
+	}
+
+	stream.Close()
+	stream.Close()
+	stream, err = client.CompletionStream(context.Background(), req)
+	if err != nil {
+		t.Fatalf("CompletionStream() after Close error = %v", err)
+	}
+	stream.Close()
+	if got := client.BulkheadStats()["mock"].InFlight; got != 0 {
+		t.Errorf("InFlight = %d, want 0", got)
+	}
+}
+
+func TestBulkheadWait(t *testing.T) {
+	client, err := NewClient(WithBulkhead("mock", 1, time.Second))
+	if err != nil {
+		t.Fatal(err)
+	}
+	client.RegisterProvider(&mockProvider{name: "mock"})
+
+	req := &CompletionRequest{Model: "mock/model", Messages: []Message{{Role: "user", Content: "Hi"}}}
+	stream, err := client.CompletionStream(context.Background(), req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	time.AfterFunc(10*time.Millisecond, func() { stream.Close() })
+
+	if _, err := client.Completion(context.Background(), req); err != nil {
+		t.Errorf("Completion() error = %v, want slot freed while waiting", err)
+	}
+}
+
+func TestWithBulkheadValidation(t *testing.T) {
+	tests := []struct {
+		name     string
+		provider string
+		max      int
+		wait     time.Duration
+	}{
+		{"empty provider", "", 1, 0},
+		{"zero limit", "openai", 0, 0},
+		{"negative wait", "openai", 1, -time.Second},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := NewClient(WithBulkhead(tt.provider, tt.max, tt.wait)); err == nil {
+				t.Error("NewClient() error = nil, want configuration error")
+			}
+		})
+	}
+}
@@ -34,6 +34,50 @@ type Cache interface {
 	Close() error
 }
 
+// Stats contains cache effectiveness counters.
+//
+// Counters are cumulative since the cache was created (or since the last
+// Clear for Entries and Size).
+type Stats struct {
+	// Hits is the number of Get calls that returned a value.
+	Hits int64
+
+	// Misses is the number of Get calls that found no value (missing or expired).
+	Misses int64
+
+	// Evictions is the number of entries removed to make room for new entries.
+	Evictions int64
+
+	// Expirations is the number of entries removed because their TTL elapsed.
+	Expirations int64
+
+	// Entries is the number of entries currently stored.
+	Entries int
+
+	// Size is the total size of stored values in bytes.
+	Size int64
+}
+
+// HitRate returns the fraction of lookups that were served from the cache.
+//
+// Returns 0 if no lookups have been made.
+func (s Stats) HitRate() float64 {
+	total := s.Hits + s.Misses
+	if total == 0 {
+		return 0
+	}
+	return float64(s.Hits) / float64(total)
+}
+
+// StatsReporter is implemented by caches that track usage statistics.
+//
+// It is optional: the client checks for it with a type assertion, so custom
+// Cache implementations only need to implement it if they can report stats.
+type StatsReporter interface {
+	// Stats returns a snapshot of the cache counters.
+	Stats() Stats
+}
+
 // Key generates a deterministic cache key for a completion request.
 //
 // The key is generated from the model name and request parameters to ensure
@@ -139,3 +139,23 @@ func TestKeyNilParameters(t *testing.T) {
 		t.Error("Key() returned empty string")
 	}
 }
+
+func TestStatsHitRate(t *testing.T) {
+	tests := []struct {
+		name  string
+		stats Stats
+		want  float64
+	}{
+		{name: "no lookups", stats: Stats{}, want: 0},
+		{name: "all hits", stats: Stats{Hits: 4}, want: 1},
+		{name: "half", stats: Stats{Hits: 2, Misses: 2}, want: 0.5},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.stats.HitRate(); got != tt.want {
+				t.Errorf("HitRate() = %f, want %f", got, tt.want)
+			}
+		})
+	}
+}
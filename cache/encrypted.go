@@ -0,0 +1,91 @@
+package cache
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/blue-context/warp/encrypt"
+)
+
+// EncryptedCache encrypts values with AES-GCM before storing them in
+// another cache, so cached prompts and responses are never at rest in
+// plaintext.
+//
+// Each value is bound to its key, so values cannot be swapped between keys.
+// After a key rotation, values written with a previous key stay readable
+// while that key is available from the KeyProvider and expire normally.
+//
+// Thread Safety: Safe for concurrent use if the underlying cache is.
+type EncryptedCache struct {
+	cache Cache
+	keys  encrypt.KeyProvider
+}
+
+// Compile-time interface checks
+var (
+	_ Cache         = (*EncryptedCache)(nil)
+	_ StatsReporter = (*EncryptedCache)(nil)
+)
+
+// NewEncrypted wraps cache so values are encrypted with keys.
+//
+// Example:
+//
+//	keys, _ := encrypt.NewKeyRing("v1", key)
+//	client, err := warp.NewClient(
+//	    warp.WithCache(cache.NewEncrypted(cache.NewMemoryCache(100*1024*1024), keys)),
+//	)
+func NewEncrypted(cache Cache, keys encrypt.KeyProvider) *EncryptedCache {
+	return &EncryptedCache{cache: cache, keys: keys}
+}
+
+// Get retrieves and decrypts a value.
+//
+// Values that cannot be decrypted (e.g., written with a removed key) are
+// reported as errors, which callers treat as misses.
+func (c *EncryptedCache) Get(ctx context.Context, key string) ([]byte, error) {
+	sealed, err := c.cache.Get(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+
+	value, err := encrypt.Open(ctx, c.keys, sealed, []byte(key))
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt cached value: %w", err)
+	}
+	return value, nil
+}
+
+// Set encrypts a value with the current key and stores it.
+func (c *EncryptedCache) Set(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	sealed, err := encrypt.Seal(ctx, c.keys, value, []byte(key))
+	if err != nil {
+		return fmt.Errorf("failed to encrypt cached value: %w", err)
+	}
+	return c.cache.Set(ctx, key, sealed, ttl)
+}
+
+// Delete removes a value.
+func (c *EncryptedCache) Delete(ctx context.Context, key string) error {
+	return c.cache.Delete(ctx, key)
+}
+
+// Clear removes all values.
+func (c *EncryptedCache) Clear(ctx context.Context) error {
+	return c.cache.Clear(ctx)
+}
+
+// Stats returns the underlying cache's counters, or zero Stats if it does
+// not implement StatsReporter.
+func (c *EncryptedCache) Stats() Stats {
+	if reporter, ok := c.cache.(StatsReporter); ok {
+		return reporter.Stats()
+	}
+	return Stats{}
+}
+
+// Close closes the underlying cache.
+func (c *EncryptedCache) Close() error {
+	return c.cache.Close()
+}
@@ -0,0 +1,54 @@
+package cache
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/blue-context/warp/encrypt"
+)
+
+func TestEncryptedCache(t *testing.T) {
+	ctx := context.Background()
+	keys, err := encrypt.NewKeyRing("k1", bytes.Repeat([]byte{1}, 32))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	inner := NewMemoryCache(1024 * 1024)
+	c := NewEncrypted(inner, keys)
+	defer c.Close()
+
+	if err := c.Set(ctx, "a", []byte("secret response"), 0); err != nil {
+		t.Fatalf("Set() error = %v", err)
+	}
+
+	raw, _ := inner.Get(ctx, "a")
+	if bytes.Contains(raw, []byte("secret")) {
+		t.Error("underlying cache holds plaintext")
+	}
+
+	got, err := c.Get(ctx, "a")
+	if err != nil || string(got) != "secret response" {
+		t.Fatalf("Get() = %q, %v", got, err)
+	}
+
+	// A value moved to another key must not decrypt
+	inner.Set(ctx, "b", raw, 0)
+	if _, err := c.Get(ctx, "b"); err == nil {
+		t.Error("Get() of value stored under another key succeeded")
+	}
+
+	// Values from before a rotation remain readable
+	keys.Rotate("k2", bytes.Repeat([]byte{2}, 32))
+	if got, err := c.Get(ctx, "a"); err != nil || string(got) != "secret response" {
+		t.Errorf("Get() after rotation = %q, %v", got, err)
+	}
+
+	if _, err := c.Get(ctx, "missing"); err == nil {
+		t.Error("Get() of missing key succeeded")
+	}
+	if c.Stats().Entries != 2 {
+		t.Errorf("Stats().Entries = %d, want 2", c.Stats().Entries)
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"sync"
+	"sync/atomic"
 	"time"
 )
 
@@ -15,6 +16,7 @@ import (
 //   - Automatic expiration: cleanup goroutine removes expired entries
 //   - Size-based eviction: FIFO eviction when maxSize is reached
 //   - Zero-copy: stores byte slices directly
+//   - Statistics: hit/miss/eviction counters via Stats
 type MemoryCache struct {
 	data    map[string]*entry
 	maxSize int64
@@ -22,8 +24,20 @@ type MemoryCache struct {
 	mu      sync.RWMutex
 	done    chan struct{}
 	wg      sync.WaitGroup
+
+	// Counters are updated atomically so Get can keep using the read lock
+	hits        atomic.Int64
+	misses      atomic.Int64
+	evictions   atomic.Int64
+	expirations atomic.Int64
 }
 
+// Compile-time interface checks
+var (
+	_ Cache         = (*MemoryCache)(nil)
+	_ StatsReporter = (*MemoryCache)(nil)
+)
+
 // entry represents a cached value with expiration.
 type entry struct {
 	value      []byte
@@ -66,14 +80,17 @@ func (c *MemoryCache) Get(ctx context.Context, key string) ([]byte, error) {
 
 	e, exists := c.data[key]
 	if !exists {
+		c.misses.Add(1)
 		return nil, fmt.Errorf("key not found")
 	}
 
 	// Check expiration
 	if !e.expiration.IsZero() && time.Now().After(e.expiration) {
+		c.misses.Add(1)
 		return nil, fmt.Errorf("key expired")
 	}
 
+	c.hits.Add(1)
 	return e.value, nil
 }
 
@@ -142,6 +159,25 @@ func (c *MemoryCache) Clear(ctx context.Context) error {
 	return nil
 }
 
+// Stats returns a snapshot of the cache counters.
+//
+// Thread Safety: Safe for concurrent use.
+func (c *MemoryCache) Stats() Stats {
+	c.mu.RLock()
+	entries := len(c.data)
+	size := c.size
+	c.mu.RUnlock()
+
+	return Stats{
+		Hits:        c.hits.Load(),
+		Misses:      c.misses.Load(),
+		Evictions:   c.evictions.Load(),
+		Expirations: c.expirations.Load(),
+		Entries:     entries,
+		Size:        size,
+	}
+}
+
 // Close closes the cache and stops the cleanup goroutine.
 //
 // After calling Close, the cache should not be used.
@@ -179,6 +215,7 @@ func (c *MemoryCache) cleanup() {
 				if !e.expiration.IsZero() && now.After(e.expiration) {
 					c.size -= e.size
 					delete(c.data, key)
+					c.expirations.Add(1)
 				}
 			}
 			c.mu.Unlock()
@@ -197,6 +234,7 @@ func (c *MemoryCache) evict(needed int64) {
 	for key, e := range c.data {
 		c.size -= e.size
 		delete(c.data, key)
+		c.evictions.Add(1)
 
 		// Check if we have enough space now
 		if c.size+needed <= c.maxSize {
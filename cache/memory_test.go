@@ -341,3 +341,55 @@ func TestMemoryCache_SizeTracking(t *testing.T) {
 		t.Errorf("cache size = %d, want 12", size3)
 	}
 }
+
+func TestMemoryCache_Stats(t *testing.T) {
+	ctx := context.Background()
+	cache := NewMemoryCache(10)
+	defer cache.Close()
+
+	if stats := cache.Stats(); stats != (Stats{}) {
+		t.Errorf("Stats() on new cache = %+v, want zero", stats)
+	}
+
+	_ = cache.Set(ctx, "a", []byte("12345"), 0)
+	_, _ = cache.Get(ctx, "a")
+	_, _ = cache.Get(ctx, "a")
+	_, _ = cache.Get(ctx, "missing")
+
+	// Exceeds maxSize, evicting "a"
+	_ = cache.Set(ctx, "b", []byte("123456789"), 0)
+
+	stats := cache.Stats()
+	if stats.Hits != 2 {
+		t.Errorf("Hits = %d, want 2", stats.Hits)
+	}
+	if stats.Misses != 1 {
+		t.Errorf("Misses = %d, want 1", stats.Misses)
+	}
+	if stats.Evictions != 1 {
+		t.Errorf("Evictions = %d, want 1", stats.Evictions)
+	}
+	if stats.Entries != 1 {
+		t.Errorf("Entries = %d, want 1", stats.Entries)
+	}
+	if stats.Size != 9 {
+		t.Errorf("Size = %d, want 9", stats.Size)
+	}
+	if got := stats.HitRate(); got < 0.66 || got > 0.67 {
+		t.Errorf("HitRate() = %f, want ~0.667", got)
+	}
+}
+
+func TestMemoryCache_StatsExpiredMiss(t *testing.T) {
+	ctx := context.Background()
+	cache := NewMemoryCache(0)
+	defer cache.Close()
+
+	_ = cache.Set(ctx, "key", []byte("value"), 10*time.Millisecond)
+	time.Sleep(20 * time.Millisecond)
+	_, _ = cache.Get(ctx, "key")
+
+	if stats := cache.Stats(); stats.Misses != 1 || stats.Hits != 0 {
+		t.Errorf("Stats() = %+v, want 1 miss and 0 hits", stats)
+	}
+}
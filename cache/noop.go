@@ -44,6 +44,11 @@ func (c *NoopCache) Clear(ctx context.Context) error {
 	return nil
 }
 
+// Stats always returns zero counters.
+func (c *NoopCache) Stats() Stats {
+	return Stats{}
+}
+
 // Close does nothing and returns nil.
 func (c *NoopCache) Close() error {
 	return nil
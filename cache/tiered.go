@@ -0,0 +1,162 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync/atomic"
+	"time"
+)
+
+// TieredCache composes a fast L1 cache with a slower, larger L2 cache.
+//
+// Reads go to L1 first and fall through to L2. Values found only in L2 are
+// written back into L1 so subsequent reads are served locally. Writes,
+// deletes, and clears are applied to both tiers.
+//
+// A typical setup pairs an in-process MemoryCache (L1) with a shared Redis
+// or disk-backed cache (L2).
+//
+// Thread Safety: Safe for concurrent use if both tiers are.
+type TieredCache struct {
+	l1    Cache
+	l2    Cache
+	l1TTL time.Duration
+
+	hits   atomic.Int64
+	misses atomic.Int64
+}
+
+// DefaultL1TTL is the default TTL of entries stored in L1.
+//
+// L1 entries are not invalidated when L2 entries expire or are deleted by
+// other processes, so it bounds how long a process may serve stale values.
+const DefaultL1TTL = time.Minute
+
+// Compile-time interface checks
+var (
+	_ Cache         = (*TieredCache)(nil)
+	_ StatsReporter = (*TieredCache)(nil)
+)
+
+// TieredOption configures a TieredCache.
+type TieredOption func(*TieredCache)
+
+// WithL1TTL caps the TTL of entries stored in L1. It must be positive.
+//
+// The cap applies to both writes and L2 back-fills. Because the remaining TTL
+// of an L2 entry is not known, back-filled entries use this TTL as-is. The
+// default is DefaultL1TTL.
+//
+// Example:
+//
+//	tiered := cache.NewTiered(l1, l2, cache.WithL1TTL(5*time.Minute))
+func WithL1TTL(ttl time.Duration) TieredOption {
+	return func(c *TieredCache) {
+		c.l1TTL = ttl
+	}
+}
+
+// NewTiered creates a two-level cache from l1 and l2.
+//
+// Returns an error if either tier is nil or the L1 TTL is not positive.
+//
+// Example:
+//
+//	l1 := cache.NewMemoryCache(50 * 1024 * 1024)
+//	l2 := NewRedisCache(redisClient)
+//	tiered, err := cache.NewTiered(l1, l2)
+//	if err != nil {
+//	    return err
+//	}
+//	client, err := warp.NewClient(warp.WithCache(tiered))
+func NewTiered(l1, l2 Cache, opts ...TieredOption) (*TieredCache, error) {
+	if l1 == nil || l2 == nil {
+		return nil, fmt.Errorf("both cache tiers are required")
+	}
+
+	c := &TieredCache{
+		l1:    l1,
+		l2:    l2,
+		l1TTL: DefaultL1TTL,
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	if c.l1TTL <= 0 {
+		return nil, fmt.Errorf("L1 TTL must be positive, got %v", c.l1TTL)
+	}
+	return c, nil
+}
+
+// Get retrieves a value from L1, falling back to L2.
+//
+// A value found in L2 is back-filled into L1. Back-fill failures are ignored
+// since the value was still retrieved successfully.
+func (c *TieredCache) Get(ctx context.Context, key string) ([]byte, error) {
+	if value, err := c.l1.Get(ctx, key); err == nil {
+		c.hits.Add(1)
+		return value, nil
+	}
+
+	value, err := c.l2.Get(ctx, key)
+	if err != nil {
+		c.misses.Add(1)
+		return nil, err
+	}
+
+	c.hits.Add(1)
+	_ = c.l1.Set(ctx, key, value, c.l1TTL)
+	return value, nil
+}
+
+// Set stores a value in both tiers.
+//
+// The L1 TTL is capped by the configured L1 TTL.
+func (c *TieredCache) Set(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	if err := c.l2.Set(ctx, key, value, ttl); err != nil {
+		return fmt.Errorf("l2 set failed: %w", err)
+	}
+	if err := c.l1.Set(ctx, key, value, c.capL1TTL(ttl)); err != nil {
+		return fmt.Errorf("l1 set failed: %w", err)
+	}
+	return nil
+}
+
+// Delete removes a value from both tiers.
+func (c *TieredCache) Delete(ctx context.Context, key string) error {
+	return errors.Join(c.l1.Delete(ctx, key), c.l2.Delete(ctx, key))
+}
+
+// Clear removes all values from both tiers.
+func (c *TieredCache) Clear(ctx context.Context) error {
+	return errors.Join(c.l1.Clear(ctx), c.l2.Clear(ctx))
+}
+
+// Stats returns combined counters for the tiered cache.
+//
+// Hits and Misses count lookups against the tiered cache as a whole: a hit
+// is a value found in either tier. Evictions, Expirations, Entries, and Size
+// are reported from L1 when it implements StatsReporter.
+func (c *TieredCache) Stats() Stats {
+	var stats Stats
+	if reporter, ok := c.l1.(StatsReporter); ok {
+		stats = reporter.Stats()
+	}
+	stats.Hits = c.hits.Load()
+	stats.Misses = c.misses.Load()
+	return stats
+}
+
+// Close closes both tiers.
+func (c *TieredCache) Close() error {
+	return errors.Join(c.l1.Close(), c.l2.Close())
+}
+
+// capL1TTL returns the TTL to use for an L1 write given the caller's TTL.
+func (c *TieredCache) capL1TTL(ttl time.Duration) time.Duration {
+	if ttl <= 0 || ttl > c.l1TTL {
+		return c.l1TTL
+	}
+	return ttl
+}
@@ -0,0 +1,144 @@
+package cache
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestTieredCache_GetBackfill(t *testing.T) {
+	ctx := context.Background()
+	l1 := NewMemoryCache(0)
+	l2 := NewMemoryCache(0)
+	tiered, err := NewTiered(l1, l2)
+	if err != nil {
+		t.Fatalf("NewTiered() error = %v", err)
+	}
+	defer tiered.Close()
+
+	_ = l2.Set(ctx, "key", []byte("value"), 0)
+
+	value, err := tiered.Get(ctx, "key")
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	if string(value) != "value" {
+		t.Errorf("Get() = %q, want %q", value, "value")
+	}
+
+	if got, err := l1.Get(ctx, "key"); err != nil || string(got) != "value" {
+		t.Errorf("L1 not back-filled: got %q, err %v", got, err)
+	}
+
+	if _, err := tiered.Get(ctx, "missing"); err == nil {
+		t.Error("Get() expected error for missing key")
+	}
+
+	stats := tiered.Stats()
+	if stats.Hits != 1 || stats.Misses != 1 {
+		t.Errorf("Stats() = %+v, want 1 hit and 1 miss", stats)
+	}
+}
+
+func TestTieredCache_SetDeleteClear(t *testing.T) {
+	ctx := context.Background()
+	l1 := NewMemoryCache(0)
+	l2 := NewMemoryCache(0)
+	tiered, err := NewTiered(l1, l2)
+	if err != nil {
+		t.Fatalf("NewTiered() error = %v", err)
+	}
+	defer tiered.Close()
+
+	if err := tiered.Set(ctx, "key", []byte("value"), time.Hour); err != nil {
+		t.Fatalf("Set() error = %v", err)
+	}
+	for name, c := range map[string]Cache{"l1": l1, "l2": l2} {
+		if _, err := c.Get(ctx, "key"); err != nil {
+			t.Errorf("%s missing value after Set(): %v", name, err)
+		}
+	}
+
+	if err := tiered.Delete(ctx, "key"); err != nil {
+		t.Fatalf("Delete() error = %v", err)
+	}
+	if _, err := tiered.Get(ctx, "key"); err == nil {
+		t.Error("Get() expected error after Delete()")
+	}
+
+	_ = tiered.Set(ctx, "a", []byte("1"), 0)
+	if err := tiered.Clear(ctx); err != nil {
+		t.Fatalf("Clear() error = %v", err)
+	}
+	if _, err := l2.Get(ctx, "a"); err == nil {
+		t.Error("L2 not cleared")
+	}
+}
+
+func TestTieredCache_CapL1TTL(t *testing.T) {
+	tests := []struct {
+		name  string
+		l1TTL time.Duration
+		ttl   time.Duration
+		want  time.Duration
+	}{
+		{name: "shorter than cap", l1TTL: time.Hour, ttl: time.Minute, want: time.Minute},
+		{name: "longer than cap", l1TTL: time.Minute, ttl: time.Hour, want: time.Minute},
+		{name: "no expiry capped", l1TTL: time.Minute, ttl: 0, want: time.Minute},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, err := NewTiered(NewNoopCache(), NewNoopCache(), WithL1TTL(tt.l1TTL))
+			if err != nil {
+				t.Fatalf("NewTiered() error = %v", err)
+			}
+			if got := c.capL1TTL(tt.ttl); got != tt.want {
+				t.Errorf("capL1TTL(%v) = %v, want %v", tt.ttl, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTieredCache_BackfillExpires(t *testing.T) {
+	ctx := context.Background()
+	l1 := NewMemoryCache(0)
+	l2 := NewMemoryCache(0)
+	tiered, err := NewTiered(l1, l2, WithL1TTL(50*time.Millisecond))
+	if err != nil {
+		t.Fatalf("NewTiered() error = %v", err)
+	}
+	defer tiered.Close()
+
+	_ = l2.Set(ctx, "key", []byte("value"), time.Hour)
+	if _, err := tiered.Get(ctx, "key"); err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+
+	// Another process deletes the L2 entry; L1 stops serving it after its TTL
+	_ = l2.Delete(ctx, "key")
+	time.Sleep(100 * time.Millisecond)
+	if _, err := tiered.Get(ctx, "key"); err == nil {
+		t.Error("Get() served a back-filled value past the L1 TTL")
+	}
+}
+
+func TestNewTieredInvalid(t *testing.T) {
+	tests := []struct {
+		name   string
+		l1, l2 Cache
+		opts   []TieredOption
+	}{
+		{name: "nil l1", l2: NewNoopCache()},
+		{name: "nil l2", l1: NewNoopCache()},
+		{name: "zero L1 TTL", l1: NewNoopCache(), l2: NewNoopCache(), opts: []TieredOption{WithL1TTL(0)}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := NewTiered(tt.l1, tt.l2, tt.opts...); err == nil {
+				t.Error("NewTiered() error = nil, want error")
+			}
+		})
+	}
+}
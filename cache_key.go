@@ -0,0 +1,103 @@
+package warp
+
+import (
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+
+	"github.com/blue-context/warp/cache"
+)
+
+// CacheKeyFunc derives the cache key for a completion request.
+//
+// The request passed to the function is the caller's request, with the model
+// in "provider/model-name" format. Implementations must be deterministic:
+// identical requests must always produce identical keys. Returning an empty
+// string skips the cache for that request.
+//
+// Thread Safety: CacheKeyFunc implementations must be safe for concurrent use.
+type CacheKeyFunc func(req *CompletionRequest) string
+
+// alwaysExcludedKeyFields lists request fields that never influence the
+// generated response and are therefore never part of a full-request cache key.
+var alwaysExcludedKeyFields = []string{"api_key", "timeout", "num_retries", "fallbacks"}
+
+// DefaultCacheKey returns the cache key used when no CacheKeyFunc is configured.
+//
+// The key covers the model, messages, temperature, max tokens, and top-p.
+// Metadata and other request fields are not part of the key.
+//
+// Example:
+//
+//	key := warp.DefaultCacheKey(req)
+func DefaultCacheKey(req *CompletionRequest) string {
+	if req == nil {
+		return ""
+	}
+
+	messagesJSON, err := json.Marshal(req.Messages)
+	if err != nil {
+		return ""
+	}
+
+	return cache.Key(req.Model, messagesJSON, req.Temperature, req.MaxTokens, req.TopP)
+}
+
+// CacheKeyExcluding returns a CacheKeyFunc that hashes every request field
+// except the named ones.
+//
+// Fields are identified by their JSON name (e.g. "metadata", "tools").
+// Credentials and transport settings (api_key, timeout, num_retries, fallbacks)
+// are always excluded. Unlike DefaultCacheKey, the resulting key also covers
+// tools, stop sequences, response format, and every other sampling parameter.
+//
+// Example:
+//
+//	client, err := warp.NewClient(
+//	    warp.WithCache(cache.NewMemoryCache(0)),
+//	    warp.WithCacheKeyFunc(warp.CacheKeyExcluding("metadata")),
+//	)
+func CacheKeyExcluding(fields ...string) CacheKeyFunc {
+	excluded := make(map[string]bool, len(fields)+len(alwaysExcludedKeyFields))
+	for _, f := range alwaysExcludedKeyFields {
+		excluded[f] = true
+	}
+	for _, f := range fields {
+		excluded[f] = true
+	}
+
+	return func(req *CompletionRequest) string {
+		if req == nil {
+			return ""
+		}
+
+		data, err := json.Marshal(req)
+		if err != nil {
+			return ""
+		}
+
+		var fieldsByName map[string]json.RawMessage
+		if err := json.Unmarshal(data, &fieldsByName); err != nil {
+			return ""
+		}
+		for name := range excluded {
+			delete(fieldsByName, name)
+		}
+
+		// encoding/json sorts map keys, so the encoding is canonical
+		canonical, err := json.Marshal(fieldsByName)
+		if err != nil {
+			return ""
+		}
+
+		return fmt.Sprintf("warp:v1:%x", sha256.Sum256(canonical))
+	}
+}
+
+// cacheKey returns the cache key for req using the configured CacheKeyFunc.
+func (c *client) cacheKey(req *CompletionRequest) string {
+	if c.config.CacheKeyFunc != nil {
+		return c.config.CacheKeyFunc(req)
+	}
+	return DefaultCacheKey(req)
+}
@@ -0,0 +1,174 @@
+package warp
+
+import (
+	"context"
+	"testing"
+
+	"github.com/blue-context/warp/cache"
+)
+
+func TestDefaultCacheKey(t *testing.T) {
+	base := &CompletionRequest{
+		Model:    "openai/gpt-4",
+		Messages: []Message{{Role: "user", Content: "Hello"}},
+	}
+
+	if DefaultCacheKey(nil) != "" {
+		t.Error("DefaultCacheKey(nil) should return empty string")
+	}
+
+	key := DefaultCacheKey(base)
+	if key == "" {
+		t.Fatal("DefaultCacheKey() returned empty key")
+	}
+
+	withMetadata := *base
+	withMetadata.Metadata = map[string]any{"trace": "abc"}
+	if got := DefaultCacheKey(&withMetadata); got != key {
+		t.Errorf("DefaultCacheKey() changed with metadata: %s != %s", got, key)
+	}
+
+	otherProvider := *base
+	otherProvider.Model = "azure/gpt-4"
+	if got := DefaultCacheKey(&otherProvider); got == key {
+		t.Error("DefaultCacheKey() should differ between providers")
+	}
+}
+
+func TestCacheKeyExcluding(t *testing.T) {
+	keyFn := CacheKeyExcluding("metadata")
+
+	base := &CompletionRequest{
+		Model:    "openai/gpt-4",
+		Messages: []Message{{Role: "user", Content: "Hello"}},
+		Metadata: map[string]any{"request": 1},
+	}
+	key := keyFn(base)
+	if key == "" {
+		t.Fatal("CacheKeyExcluding() returned empty key")
+	}
+
+	tests := []struct {
+		name     string
+		modify   func(r *CompletionRequest)
+		wantSame bool
+	}{
+		{
+			name:     "different metadata",
+			modify:   func(r *CompletionRequest) { r.Metadata = map[string]any{"request": 2} },
+			wantSame: true,
+		},
+		{
+			name:     "different api key",
+			modify:   func(r *CompletionRequest) { r.APIKey = "sk-other" },
+			wantSame: true,
+		},
+		{
+			name:     "different retries",
+			modify:   func(r *CompletionRequest) { r.NumRetries = 5 },
+			wantSame: true,
+		},
+		{
+			name:     "different stop sequences",
+			modify:   func(r *CompletionRequest) { r.Stop = []string{"END"} },
+			wantSame: false,
+		},
+		{
+			name: "different tools",
+			modify: func(r *CompletionRequest) {
+				r.Tools = []Tool{{Type: "function", Function: Function{Name: "lookup"}}}
+			},
+			wantSame: false,
+		},
+		{
+			name:     "different messages",
+			modify:   func(r *CompletionRequest) { r.Messages = []Message{{Role: "user", Content: "Bye"}} },
+			wantSame: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			modified := *base
+			tt.modify(&modified)
+
+			got := keyFn(&modified)
+			if (got == key) != tt.wantSame {
+				t.Errorf("key equality = %v, want %v", got == key, tt.wantSame)
+			}
+		})
+	}
+}
+
+func TestWithCacheKeyFunc(t *testing.T) {
+	if err := WithCacheKeyFunc(nil)(defaultConfig()); err == nil {
+		t.Error("WithCacheKeyFunc(nil) expected error")
+	}
+
+	memCache := cache.NewMemoryCache(0)
+	client, err := NewClient(
+		WithCache(memCache),
+		WithCacheKeyFunc(CacheKeyExcluding("metadata")),
+	)
+	if err != nil {
+		t.Fatalf("NewClient() error = %v", err)
+	}
+	defer client.Close()
+
+	callCount := 0
+	mock := &mockProvider{
+		name: "test",
+		completionFunc: func(ctx context.Context, req *CompletionRequest) (*CompletionResponse, error) {
+			callCount++
+			return &CompletionResponse{
+				ID:      "test-123",
+				Model:   req.Model,
+				Choices: []Choice{{Index: 0, Message: Message{Role: "assistant", Content: "Test response"}, FinishReason: "stop"}},
+			}, nil
+		},
+	}
+	if err := client.RegisterProvider(mock); err != nil {
+		t.Fatalf("failed to register mock provider: %v", err)
+	}
+
+	for i := 0; i < 3; i++ {
+		_, err := client.Completion(context.Background(), &CompletionRequest{
+			Model:    "test/gpt-4",
+			Messages: []Message{{Role: "user", Content: "Hello"}},
+			Metadata: map[string]any{"call": i},
+		})
+		if err != nil {
+			t.Fatalf("Completion() error = %v", err)
+		}
+	}
+
+	if callCount != 1 {
+		t.Errorf("callCount = %d, want 1 (metadata excluded from key)", callCount)
+	}
+
+	// Returning an empty key bypasses the cache
+	bypass, err := NewClient(
+		WithCache(cache.NewMemoryCache(0)),
+		WithCacheKeyFunc(func(req *CompletionRequest) string { return "" }),
+	)
+	if err != nil {
+		t.Fatalf("NewClient() error = %v", err)
+	}
+	defer bypass.Close()
+	if err := bypass.RegisterProvider(mock); err != nil {
+		t.Fatalf("failed to register mock provider: %v", err)
+	}
+
+	callCount = 0
+	for i := 0; i < 2; i++ {
+		if _, err := bypass.Completion(context.Background(), &CompletionRequest{
+			Model:    "test/gpt-4",
+			Messages: []Message{{Role: "user", Content: "Hello"}},
+		}); err != nil {
+			t.Fatalf("Completion() error = %v", err)
+		}
+	}
+	if callCount != 2 {
+		t.Errorf("callCount = %d, want 2 (empty key skips cache)", callCount)
+	}
+}
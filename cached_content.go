@@ -0,0 +1,225 @@
+package warp
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// CachedContentRequest is a request to store content with a provider for
+// reuse across completion requests, such as a long document or system
+// prompt, so it is billed at the cached input price instead of being sent
+// and billed in full every time (Gemini's context caching).
+type CachedContentRequest struct {
+	// Model is the model the content is cached for, in
+	// "provider/model-name" format. Requests using the content must use
+	// the same model.
+	Model string
+
+	// DisplayName is a human-readable name for the content.
+	DisplayName string
+
+	// Messages is the content to cache. System messages become the
+	// cached system instruction.
+	Messages []Message
+
+	// Tools are cached with the content.
+	Tools []Tool
+
+	// TTL is how long the content is kept. Zero uses the provider's
+	// default (one hour for Gemini).
+	TTL time.Duration
+}
+
+// CachedContent is content stored with a provider for reuse across
+// requests. Reference it by setting CompletionRequest.CachedContent to its
+// Name.
+type CachedContent struct {
+	// Name identifies the content in requests.
+	Name string
+
+	// Model is the model the content is cached for, in
+	// "provider/model-name" format.
+	Model string
+
+	// DisplayName is the human-readable name given at creation.
+	DisplayName string
+
+	// Tokens is the number of tokens stored, the quantity billed for
+	// storage and for cached reads.
+	Tokens int
+
+	// CreateTime is when the content was created.
+	CreateTime time.Time
+
+	// ExpireTime is when the provider deletes the content.
+	ExpireTime time.Time
+}
+
+// CachedContentManager is implemented by providers that store content for
+// reuse across requests. Models are without the provider prefix, both in
+// the request passed to CreateCachedContent and in the CachedContent
+// returned; the client adds the prefix.
+type CachedContentManager interface {
+	// CreateCachedContent stores content.
+	CreateCachedContent(ctx context.Context, req *CachedContentRequest) (*CachedContent, error)
+
+	// GetCachedContent returns stored content by name.
+	GetCachedContent(ctx context.Context, name string) (*CachedContent, error)
+
+	// UpdateCachedContentTTL keeps stored content for ttl from now.
+	UpdateCachedContentTTL(ctx context.Context, name string, ttl time.Duration) (*CachedContent, error)
+
+	// DeleteCachedContent deletes stored content.
+	DeleteCachedContent(ctx context.Context, name string) error
+}
+
+// CreateCachedContent stores content with the provider of req.Model for
+// reuse across requests.
+//
+// Example:
+//
+//	cached, err := client.CreateCachedContent(ctx, &warp.CachedContentRequest{
+//	    Model:    "vertex/gemini-1.5-pro-002",
+//	    Messages: []warp.Message{{Role: "user", Content: manual}},
+//	    TTL:      time.Hour,
+//	})
+//	if err != nil {
+//	    return err
+//	}
+//	defer client.DeleteCachedContent(ctx, cached.Model, cached.Name)
+//
+//	resp, err := client.Completion(ctx, &warp.CompletionRequest{
+//	    Model:         cached.Model,
+//	    CachedContent: cached.Name,
+//	    Messages:      []warp.Message{{Role: "user", Content: "How do I reset the device?"}},
+//	})
+func (c *client) CreateCachedContent(ctx context.Context, req *CachedContentRequest) (*CachedContent, error) {
+	if req == nil {
+		return nil, fmt.Errorf("request cannot be nil")
+	}
+	if req.TTL < 0 {
+		return nil, fmt.Errorf("TTL must be non-negative, got %v", req.TTL)
+	}
+	providerName, modelName, manager, err := c.cachedContentManager(req.Model)
+	if err != nil {
+		return nil, err
+	}
+
+	r := *req
+	r.Model = modelName
+	cached, err := manager.CreateCachedContent(ctx, &r)
+	if err != nil {
+		return nil, err
+	}
+	return withProviderPrefix(cached, providerName), nil
+}
+
+// GetCachedContent returns the content stored under name with the
+// provider of model.
+func (c *client) GetCachedContent(ctx context.Context, model, name string) (*CachedContent, error) {
+	providerName, _, manager, err := c.cachedContentManager(model)
+	if err != nil {
+		return nil, err
+	}
+	cached, err := manager.GetCachedContent(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+	return withProviderPrefix(cached, providerName), nil
+}
+
+// UpdateCachedContentTTL keeps the content stored under name with the
+// provider of model for ttl from now.
+func (c *client) UpdateCachedContentTTL(ctx context.Context, model, name string, ttl time.Duration) (*CachedContent, error) {
+	if ttl <= 0 {
+		return nil, fmt.Errorf("TTL must be positive, got %v", ttl)
+	}
+	providerName, _, manager, err := c.cachedContentManager(model)
+	if err != nil {
+		return nil, err
+	}
+	cached, err := manager.UpdateCachedContentTTL(ctx, name, ttl)
+	if err != nil {
+		return nil, err
+	}
+	return withProviderPrefix(cached, providerName), nil
+}
+
+// DeleteCachedContent deletes the content stored under name with the
+// provider of model, ending its storage charges.
+func (c *client) DeleteCachedContent(ctx context.Context, model, name string) error {
+	_, _, manager, err := c.cachedContentManager(model)
+	if err != nil {
+		return err
+	}
+	return manager.DeleteCachedContent(ctx, name)
+}
+
+// CachedContentStorageCost calculates the cost of storing content from
+// its creation to its expiry, at the model's CacheStorageCostPer1MHour
+// price. Deleting the content earlier ends the charges sooner. Reads of
+// the content are billed with the completions that use it, as cached
+// input tokens (see CompletionCost).
+//
+// Returns an error if the content's times are missing or the model has
+// no storage pricing.
+func (c *client) CachedContentStorageCost(cached *CachedContent) (float64, error) {
+	if cached == nil {
+		return 0, fmt.Errorf("cached content cannot be nil")
+	}
+	if cached.CreateTime.IsZero() || cached.ExpireTime.IsZero() {
+		return 0, fmt.Errorf("cached content is missing its create or expire time")
+	}
+	providerName, modelName, err := parseModel(cached.Model)
+	if err != nil {
+		return 0, err
+	}
+	return c.costCalc.CalculateCacheStorage(providerName, modelName, cached.Tokens, cached.ExpireTime.Sub(cached.CreateTime))
+}
+
+// cachedContentManager returns the provider and model names of model and
+// its provider's CachedContentManager.
+func (c *client) cachedContentManager(model string) (string, string, CachedContentManager, error) {
+	providerName, modelName, err := parseModel(model)
+	if err != nil {
+		return "", "", nil, err
+	}
+	p, err := c.getProvider(providerName)
+	if err != nil {
+		return "", "", nil, fmt.Errorf("provider %q not found (did you register it?)", providerName)
+	}
+	manager, ok := p.(CachedContentManager)
+	if !ok {
+		return "", "", nil, NewInvalidRequestError(
+			fmt.Sprintf("provider %q does not support cached content", providerName), providerName, nil)
+	}
+	return providerName, modelName, manager, nil
+}
+
+// checkCachedContent returns an error if req references cached content
+// and its provider cannot use it, rather than silently sending the request
+// without the content.
+func checkCachedContent(p Provider, req *CompletionRequest, providerName string) error {
+	if req.CachedContent == "" {
+		return nil
+	}
+	if _, ok := p.(CachedContentManager); !ok {
+		return NewInvalidRequestError(
+			fmt.Sprintf("provider %q does not support cached content", providerName), providerName, nil)
+	}
+	return nil
+}
+
+// withProviderPrefix returns cached with its model in
+// "provider/model-name" format.
+func withProviderPrefix(cached *CachedContent, providerName string) *CachedContent {
+	if cached == nil {
+		return nil
+	}
+	out := *cached
+	if out.Model != "" {
+		out.Model = providerName + "/" + out.Model
+	}
+	return &out
+}
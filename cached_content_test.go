@@ -0,0 +1,110 @@
+package warp
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/blue-context/warp/types"
+)
+
+// cachingProvider is a mockProvider that implements CachedContentManager.
+type cachingProvider struct {
+	mockProvider
+	created *CachedContentRequest
+	deleted string
+}
+
+func (p *cachingProvider) CreateCachedContent(ctx context.Context, req *CachedContentRequest) (*CachedContent, error) {
+	p.created = req
+	created := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	return &CachedContent{Name: "cache-1", Model: req.Model, Tokens: 40000, CreateTime: created, ExpireTime: created.Add(req.TTL)}, nil
+}
+
+func (p *cachingProvider) GetCachedContent(ctx context.Context, name string) (*CachedContent, error) {
+	return &CachedContent{Name: name, Model: "m"}, nil
+}
+
+func (p *cachingProvider) UpdateCachedContentTTL(ctx context.Context, name string, ttl time.Duration) (*CachedContent, error) {
+	return &CachedContent{Name: name, Model: "m"}, nil
+}
+
+func (p *cachingProvider) DeleteCachedContent(ctx context.Context, name string) error {
+	p.deleted = name
+	return nil
+}
+
+func TestCachedContent(t *testing.T) {
+	var got *CompletionRequest
+	capture := func(ctx context.Context, req *CompletionRequest) (*CompletionResponse, error) {
+		got = req
+		return &CompletionResponse{Choices: []Choice{{Message: Message{Role: "assistant", Content: "ok"}}}}, nil
+	}
+
+	client, err := NewClient(WithMaxRetries(0))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+
+	caching := &cachingProvider{mockProvider: mockProvider{
+		name:           "caching",
+		completionFunc: capture,
+		modelInfo:      map[string]*types.ModelInfo{"m": {Name: "m", CacheStorageCostPer1MHour: 1.0}},
+	}}
+	client.RegisterProvider(caching)
+	client.RegisterProvider(&mockProvider{name: "plain", completionFunc: capture})
+	ctx := context.Background()
+
+	cached, err := client.CreateCachedContent(ctx, &CachedContentRequest{
+		Model:    "caching/m",
+		Messages: []Message{{Role: "user", Content: "a long document"}},
+		TTL:      2 * time.Hour,
+	})
+	if err != nil {
+		t.Fatalf("CreateCachedContent() error = %v", err)
+	}
+	if caching.created.Model != "m" {
+		t.Errorf("provider got model %q, want m", caching.created.Model)
+	}
+	if cached.Model != "caching/m" {
+		t.Errorf("Model = %q, want caching/m", cached.Model)
+	}
+
+	cost, err := client.CachedContentStorageCost(cached)
+	if err != nil {
+		t.Fatalf("CachedContentStorageCost() error = %v", err)
+	}
+	if diff := cost - 0.08; diff > 1e-9 || diff < -1e-9 {
+		t.Errorf("CachedContentStorageCost() = %f, want 0.08", cost)
+	}
+
+	req := &CompletionRequest{Model: cached.Model, CachedContent: cached.Name, Messages: []Message{{Role: "user", Content: "hi"}}}
+	if _, err := client.Completion(ctx, req); err != nil {
+		t.Fatalf("Completion() error = %v", err)
+	}
+	if got.CachedContent != "cache-1" {
+		t.Errorf("CachedContent = %q, want cache-1", got.CachedContent)
+	}
+
+	if err := client.DeleteCachedContent(ctx, cached.Model, cached.Name); err != nil || caching.deleted != "cache-1" {
+		t.Errorf("DeleteCachedContent() error = %v, deleted %q", err, caching.deleted)
+	}
+
+	got = nil
+	var invalid *InvalidRequestError
+	_, err = client.Completion(ctx, &CompletionRequest{Model: "plain/m", CachedContent: "cache-1", Messages: req.Messages})
+	if !errors.As(err, &invalid) {
+		t.Errorf("Completion() error = %v, want InvalidRequestError", err)
+	}
+	if got != nil {
+		t.Error("request was sent to a provider without cached content support")
+	}
+	if _, err := client.GetCachedContent(ctx, "plain/m", "cache-1"); !errors.As(err, &invalid) {
+		t.Errorf("GetCachedContent() error = %v, want InvalidRequestError", err)
+	}
+	if _, err := client.UpdateCachedContentTTL(ctx, "caching/m", "cache-1", 0); err == nil {
+		t.Error("UpdateCachedContentTTL() expected error for zero TTL")
+	}
+}
@@ -72,6 +72,34 @@ type FailureCallback func(ctx context.Context, event *FailureEvent)
 //	}
 type StreamCallback func(ctx context.Context, event *StreamEvent)
 
+// WarningCallback is called when the client changes a request in a way the
+// caller may not expect, such as restructuring system messages.
+//
+// Warning callbacks are informational only and cannot fail the request.
+//
+// Thread Safety: Must be safe for concurrent calls.
+//
+// Example:
+//
+//	func logWarning(ctx context.Context, event *WarningEvent) {
+//	    log.Printf("warning [%s]: %s", event.Code, event.Message)
+//	}
+type WarningCallback func(ctx context.Context, event *WarningEvent)
+
+// DebugCallback is called with the raw provider payloads of a request when
+// payload tracing is enabled.
+//
+// Debug callbacks are informational only and cannot fail the request.
+//
+// Thread Safety: Must be safe for concurrent calls.
+//
+// Example:
+//
+//	func logPayloads(ctx context.Context, event *DebugEvent) {
+//	    log.Printf("%s request: %s\nresponse: %s", event.Provider, event.RawRequest, event.RawResponse)
+//	}
+type DebugCallback func(ctx context.Context, event *DebugEvent)
+
 // BeforeRequestEvent contains data for before-request callbacks.
 type BeforeRequestEvent struct {
 	// RequestID uniquely identifies this request
@@ -102,12 +130,14 @@ type SuccessEvent struct {
 	// Provider is the provider name (e.g., "openai", "anthropic")
 	Provider string
 
-	// Request is the completion request that was sent.
-	// Type: *warp.CompletionRequest (interface{} to avoid circular import)
+	// Request is the request that was sent.
+	// Type: *warp.CompletionRequest, or *warp.TranscriptionRequest or
+	// *warp.SpeechRequest for audio (interface{} to avoid circular import)
 	Request interface{}
 
-	// Response is the completion response received.
-	// Type: *warp.CompletionResponse (interface{} to avoid circular import)
+	// Response is the response received.
+	// Type: *warp.CompletionResponse, *warp.TranscriptionResponse for
+	// transcription, or nil for speech (interface{} to avoid circular import)
 	Response interface{}
 
 	// StartTime is when the request started
@@ -122,6 +152,13 @@ type SuccessEvent struct {
 	// Cost is the estimated cost in USD (0 if not available)
 	Cost float64
 
+	// Currency is the ISO 4217 code of LocalCost (USD unless the client has a
+	// currency configured; empty for streaming where cost is not available)
+	Currency string
+
+	// LocalCost is Cost converted to Currency (equal to Cost for USD)
+	LocalCost float64
+
 	// Tokens is the total number of tokens used (0 if not available)
 	Tokens int
 }
@@ -137,8 +174,9 @@ type FailureEvent struct {
 	// Provider is the provider name (e.g., "openai", "anthropic")
 	Provider string
 
-	// Request is the completion request that was sent.
-	// Type: *warp.CompletionRequest (interface{} to avoid circular import)
+	// Request is the request that was sent.
+	// Type: *warp.CompletionRequest, or *warp.TranscriptionRequest or
+	// *warp.SpeechRequest for audio (interface{} to avoid circular import)
 	Request interface{}
 
 	// Error is the error that occurred
@@ -175,3 +213,50 @@ type StreamEvent struct {
 	// Timestamp is when this chunk was received
 	Timestamp time.Time
 }
+
+// WarningEvent contains data for warning callbacks.
+type WarningEvent struct {
+	// RequestID uniquely identifies this request
+	RequestID string
+
+	// Model is the model name (without provider prefix)
+	Model string
+
+	// Provider is the provider name (e.g., "openai", "anthropic")
+	Provider string
+
+	// Code identifies the kind of warning (e.g., "system_messages_merged")
+	Code string
+
+	// Message describes what happened
+	Message string
+
+	// Timestamp is when the warning was raised
+	Timestamp time.Time
+}
+
+// DebugEvent contains data for debug callbacks.
+type DebugEvent struct {
+	// RequestID uniquely identifies this request
+	RequestID string
+
+	// Model is the model name (without provider prefix)
+	Model string
+
+	// Provider is the provider name (e.g., "openai", "anthropic")
+	Provider string
+
+	// RawRequest is the body of the last HTTP request sent to the
+	// provider, with secrets redacted
+	RawRequest string
+
+	// RawResponse is the body of the provider's response as received,
+	// with secrets redacted (the event stream for streaming requests)
+	RawResponse string
+
+	// Error is the error that occurred, if any
+	Error error
+
+	// Timestamp is when the request finished
+	Timestamp time.Time
+}
@@ -24,6 +24,9 @@ type Registry struct {
 	success       []SuccessCallback
 	failure       []FailureCallback
 	stream        []StreamCallback
+	warning       []WarningCallback
+	debug         []DebugCallback
+	noRecover     bool // Let callback panics propagate (SetPanicRecovery)
 	mu            sync.RWMutex
 }
 
@@ -40,6 +43,8 @@ func NewRegistry() *Registry {
 		success:       make([]SuccessCallback, 0),
 		failure:       make([]FailureCallback, 0),
 		stream:        make([]StreamCallback, 0),
+		warning:       make([]WarningCallback, 0),
+		debug:         make([]DebugCallback, 0),
 	}
 }
 
@@ -136,6 +141,90 @@ func (r *Registry) RegisterStream(cb StreamCallback) {
 	r.stream = append(r.stream, cb)
 }
 
+// RegisterWarning registers a warning callback.
+//
+// The callback will be executed when the client changes a request in a
+// way the caller may not expect.
+// Callbacks are executed in registration order.
+//
+// If the callback is nil, this method is a no-op.
+//
+// Example:
+//
+//	registry.RegisterWarning(func(ctx context.Context, event *WarningEvent) {
+//	    log.Printf("Warning: %s", event.Message)
+//	})
+func (r *Registry) RegisterWarning(cb WarningCallback) {
+	if cb == nil {
+		return
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.warning = append(r.warning, cb)
+}
+
+// RegisterDebug registers a debug callback.
+//
+// The callback will be executed with the raw provider payloads of each
+// request when payload tracing is enabled.
+// Callbacks are executed in registration order.
+//
+// If the callback is nil, this method is a no-op.
+//
+// Example:
+//
+//	registry.RegisterDebug(func(ctx context.Context, event *DebugEvent) {
+//	    log.Printf("Sent: %s", event.RawRequest)
+//	})
+func (r *Registry) RegisterDebug(cb DebugCallback) {
+	if cb == nil {
+		return
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.debug = append(r.debug, cb)
+}
+
+// Clone returns a new registry with the callbacks registered so far.
+// Callbacks registered later on either registry do not affect the other.
+//
+// Example:
+//
+//	tenantRegistry := registry.Clone()
+//	tenantRegistry.RegisterSuccess(recordTenantUsage)
+func (r *Registry) Clone() *Registry {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return &Registry{
+		beforeRequest: append(make([]BeforeRequestCallback, 0, len(r.beforeRequest)), r.beforeRequest...),
+		success:       append(make([]SuccessCallback, 0, len(r.success)), r.success...),
+		failure:       append(make([]FailureCallback, 0, len(r.failure)), r.failure...),
+		stream:        append(make([]StreamCallback, 0, len(r.stream)), r.stream...),
+		warning:       append(make([]WarningCallback, 0, len(r.warning)), r.warning...),
+		debug:         append(make([]DebugCallback, 0, len(r.debug)), r.debug...),
+		noRecover:     r.noRecover,
+	}
+}
+
+// SetPanicRecovery sets whether panics in callbacks are recovered, which
+// is the default. Disabling recovery lets a panic crash with its original
+// stack trace, which helps when debugging a callback.
+//
+// Example:
+//
+//	registry.SetPanicRecovery(false)
+func (r *Registry) SetPanicRecovery(enabled bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.noRecover = !enabled
+}
+
 // ExecuteBeforeRequest executes all before-request callbacks.
 //
 // Callbacks are executed sequentially in registration order.
@@ -164,6 +253,7 @@ func (r *Registry) ExecuteBeforeRequest(ctx context.Context, event *BeforeReques
 	r.mu.RLock()
 	callbacks := make([]BeforeRequestCallback, len(r.beforeRequest))
 	copy(callbacks, r.beforeRequest)
+	noRecover := r.noRecover
 	r.mu.RUnlock()
 
 	// Early return if no callbacks (zero overhead)
@@ -186,6 +276,9 @@ func (r *Registry) ExecuteBeforeRequest(ctx context.Context, event *BeforeReques
 		// Execute callback with panic recovery
 		if err := func() (callbackErr error) {
 			defer func() {
+				if noRecover {
+					return
+				}
 				if r := recover(); r != nil {
 					callbackErr = fmt.Errorf("callback panic: %v", r)
 				}
@@ -227,6 +320,7 @@ func (r *Registry) ExecuteSuccess(ctx context.Context, event *SuccessEvent) {
 	r.mu.RLock()
 	callbacks := make([]SuccessCallback, len(r.success))
 	copy(callbacks, r.success)
+	noRecover := r.noRecover
 	r.mu.RUnlock()
 
 	// Early return if no callbacks (zero overhead)
@@ -246,6 +340,9 @@ func (r *Registry) ExecuteSuccess(ctx context.Context, event *SuccessEvent) {
 		// Execute callback with panic recovery
 		func() {
 			defer func() {
+				if noRecover {
+					return
+				}
 				if r := recover(); r != nil {
 					// Log panic but don't crash (informational callbacks only)
 					// In production, this would use a logger
@@ -279,6 +376,7 @@ func (r *Registry) ExecuteFailure(ctx context.Context, event *FailureEvent) {
 	r.mu.RLock()
 	callbacks := make([]FailureCallback, len(r.failure))
 	copy(callbacks, r.failure)
+	noRecover := r.noRecover
 	r.mu.RUnlock()
 
 	// Early return if no callbacks (zero overhead)
@@ -298,6 +396,9 @@ func (r *Registry) ExecuteFailure(ctx context.Context, event *FailureEvent) {
 		// Execute callback with panic recovery
 		func() {
 			defer func() {
+				if noRecover {
+					return
+				}
 				if r := recover(); r != nil {
 					// Log panic but don't crash (informational callbacks only)
 					// In production, this would use a logger
@@ -331,6 +432,7 @@ func (r *Registry) ExecuteStream(ctx context.Context, event *StreamEvent) {
 	r.mu.RLock()
 	callbacks := make([]StreamCallback, len(r.stream))
 	copy(callbacks, r.stream)
+	noRecover := r.noRecover
 	r.mu.RUnlock()
 
 	// Early return if no callbacks (zero overhead)
@@ -350,6 +452,9 @@ func (r *Registry) ExecuteStream(ctx context.Context, event *StreamEvent) {
 		// Execute callback with panic recovery
 		func() {
 			defer func() {
+				if noRecover {
+					return
+				}
 				if r := recover(); r != nil {
 					// Log panic but don't crash (informational callbacks only)
 					// In production, this would use a logger
@@ -361,3 +466,108 @@ func (r *Registry) ExecuteStream(ctx context.Context, event *StreamEvent) {
 		}()
 	}
 }
+
+// ExecuteWarning executes all warning callbacks.
+//
+// Callbacks are executed sequentially in registration order.
+// Panics from callbacks are ignored since warnings are informational.
+// Context cancellation is checked before each callback execution.
+//
+// Example:
+//
+//	registry.ExecuteWarning(ctx, &WarningEvent{
+//	    RequestID: "req-123",
+//	    Code: "system_messages_merged",
+//	    Message: "merged 2 system messages",
+//	    Timestamp: time.Now(),
+//	})
+func (r *Registry) ExecuteWarning(ctx context.Context, event *WarningEvent) {
+	// Snapshot callbacks under read lock
+	r.mu.RLock()
+	callbacks := make([]WarningCallback, len(r.warning))
+	copy(callbacks, r.warning)
+	noRecover := r.noRecover
+	r.mu.RUnlock()
+
+	// Early return if no callbacks (zero overhead)
+	if len(callbacks) == 0 {
+		return
+	}
+
+	// Execute all callbacks
+	for _, cb := range callbacks {
+		// Check context cancellation before each callback
+		select {
+		case <-ctx.Done():
+			return
+		default:
+		}
+
+		// Execute callback with panic recovery
+		func() {
+			defer func() {
+				if noRecover {
+					return
+				}
+				if r := recover(); r != nil {
+					// Ignore panics (informational callbacks only)
+					_ = r
+				}
+			}()
+
+			cb(ctx, event)
+		}()
+	}
+}
+
+// ExecuteDebug executes all debug callbacks.
+//
+// Callbacks are executed sequentially in registration order.
+// Panics from callbacks are ignored since debug events are informational.
+// Context cancellation is checked before each callback execution.
+//
+// Example:
+//
+//	registry.ExecuteDebug(ctx, &DebugEvent{
+//	    RequestID: "req-123",
+//	    RawRequest: `{"model":"gpt-4"}`,
+//	    Timestamp: time.Now(),
+//	})
+func (r *Registry) ExecuteDebug(ctx context.Context, event *DebugEvent) {
+	// Snapshot callbacks under read lock
+	r.mu.RLock()
+	callbacks := make([]DebugCallback, len(r.debug))
+	copy(callbacks, r.debug)
+	noRecover := r.noRecover
+	r.mu.RUnlock()
+
+	// Early return if no callbacks (zero overhead)
+	if len(callbacks) == 0 {
+		return
+	}
+
+	// Execute all callbacks
+	for _, cb := range callbacks {
+		// Check context cancellation before each callback
+		select {
+		case <-ctx.Done():
+			return
+		default:
+		}
+
+		// Execute callback with panic recovery
+		func() {
+			defer func() {
+				if noRecover {
+					return
+				}
+				if r := recover(); r != nil {
+					// Ignore panics (informational callbacks only)
+					_ = r
+				}
+			}()
+
+			cb(ctx, event)
+		}()
+	}
+}
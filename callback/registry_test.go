@@ -284,6 +284,53 @@ func TestRegistry_ExecuteStream(t *testing.T) {
 	}
 }
 
+func TestRegistry_ExecuteWarning(t *testing.T) {
+	registry := NewRegistry()
+	var codes []string
+
+	registry.RegisterWarning(nil)
+	registry.RegisterWarning(func(ctx context.Context, event *WarningEvent) {
+		panic("ignored")
+	})
+	registry.RegisterWarning(func(ctx context.Context, event *WarningEvent) {
+		codes = append(codes, event.Code)
+	})
+
+	registry.ExecuteWarning(context.Background(), &WarningEvent{
+		RequestID: "test-req",
+		Code:      "system_messages_merged",
+		Message:   "merged 2 system messages",
+		Timestamp: time.Now(),
+	})
+
+	if len(codes) != 1 || codes[0] != "system_messages_merged" {
+		t.Errorf("ExecuteWarning() codes = %v, expected [system_messages_merged]", codes)
+	}
+}
+
+func TestRegistry_ExecuteDebug(t *testing.T) {
+	registry := NewRegistry()
+	var requests []string
+
+	registry.RegisterDebug(nil)
+	registry.RegisterDebug(func(ctx context.Context, event *DebugEvent) {
+		panic("ignored")
+	})
+	registry.RegisterDebug(func(ctx context.Context, event *DebugEvent) {
+		requests = append(requests, event.RawRequest)
+	})
+
+	registry.Clone().ExecuteDebug(context.Background(), &DebugEvent{
+		RequestID:  "test-req",
+		RawRequest: `{"model":"gpt-4"}`,
+		Timestamp:  time.Now(),
+	})
+
+	if len(requests) != 1 || requests[0] != `{"model":"gpt-4"}` {
+		t.Errorf("ExecuteDebug() requests = %v, expected one raw request", requests)
+	}
+}
+
 func TestRegistry_ThreadSafety(t *testing.T) {
 	registry := NewRegistry()
 	var wg sync.WaitGroup
@@ -603,6 +650,22 @@ func TestRegistry_PanicRecovery_Stream(t *testing.T) {
 }
 
 // Tests for context cancellation
+func TestRegistry_PanicRecovery_Disabled(t *testing.T) {
+	registry := NewRegistry()
+	registry.SetPanicRecovery(false)
+	registry.RegisterSuccess(func(ctx context.Context, event *SuccessEvent) {
+		panic("test panic")
+	})
+
+	defer func() {
+		if r := recover(); r != "test panic" {
+			t.Errorf("recover() = %v, want callback panic to propagate", r)
+		}
+	}()
+	registry.ExecuteSuccess(context.Background(), &SuccessEvent{RequestID: "test-req"})
+	t.Error("ExecuteSuccess() returned, want panic")
+}
+
 func TestRegistry_ContextCancellation_BeforeRequest(t *testing.T) {
 	registry := NewRegistry()
 	executionCount := 0
@@ -768,3 +831,22 @@ func TestRegistry_ErrorAggregation_BeforeRequest(t *testing.T) {
 		t.Errorf("Expected aggregated error message to contain all errors, got: %s", errMsg)
 	}
 }
+
+func TestRegistry_Clone(t *testing.T) {
+	registry := NewRegistry()
+	var parentCalls, cloneCalls int
+	registry.RegisterSuccess(func(ctx context.Context, event *SuccessEvent) { parentCalls++ })
+
+	clone := registry.Clone()
+	clone.RegisterSuccess(func(ctx context.Context, event *SuccessEvent) { cloneCalls++ })
+
+	registry.ExecuteSuccess(context.Background(), &SuccessEvent{})
+	if parentCalls != 1 || cloneCalls != 0 {
+		t.Errorf("after parent execute: parent = %d, clone = %d, want 1, 0", parentCalls, cloneCalls)
+	}
+
+	clone.ExecuteSuccess(context.Background(), &SuccessEvent{})
+	if parentCalls != 2 || cloneCalls != 1 {
+		t.Errorf("after clone execute: parent = %d, clone = %d, want 2, 1", parentCalls, cloneCalls)
+	}
+}
@@ -7,6 +7,7 @@ import (
 	"testing"
 
 	"github.com/blue-context/warp/callback"
+	"github.com/blue-context/warp/cost"
 )
 
 func TestClient_WithBeforeRequestCallback(t *testing.T) {
@@ -309,6 +310,62 @@ func TestClient_CallbacksWithCostTracking(t *testing.T) {
 	}
 }
 
+func TestClient_CallbacksWithCurrency(t *testing.T) {
+	conv, err := cost.NewCurrencyConverter("EUR", cost.StaticRates{"EUR": 0.5}, 0)
+	if err != nil {
+		t.Fatalf("NewCurrencyConverter() error = %v", err)
+	}
+
+	var captured *callback.SuccessEvent
+	client, err := NewClient(
+		WithCostTracking(true),
+		WithCurrency(conv),
+		WithSuccessCallback(func(ctx context.Context, event *callback.SuccessEvent) {
+			captured = event
+		}),
+	)
+	if err != nil {
+		t.Fatalf("NewClient() error = %v", err)
+	}
+	defer client.Close()
+
+	mockProvider := &mockProvider{
+		name: "test",
+		completionFunc: func(ctx context.Context, req *CompletionRequest) (*CompletionResponse, error) {
+			return &CompletionResponse{
+				ID:    "test-123",
+				Model: "test/gpt-4",
+				Usage: &Usage{PromptTokens: 1_000_000, CompletionTokens: 0, TotalTokens: 1_000_000},
+			}, nil
+		},
+	}
+	if err := client.RegisterProvider(mockProvider); err != nil {
+		t.Fatalf("RegisterProvider() error = %v", err)
+	}
+
+	_, err = client.Completion(context.Background(), &CompletionRequest{
+		Model:    "test/gpt-4",
+		Messages: []Message{{Role: "user", Content: "Hello"}},
+	})
+	if err != nil {
+		t.Fatalf("Completion() error = %v", err)
+	}
+
+	if captured == nil {
+		t.Fatal("success callback was not called")
+	}
+	if captured.Cost != 10.0 {
+		t.Errorf("Cost = %f, want 10.0 USD", captured.Cost)
+	}
+	if captured.Currency != "EUR" || captured.LocalCost != 5.0 {
+		t.Errorf("LocalCost = %f %s, want 5.0 EUR", captured.LocalCost, captured.Currency)
+	}
+
+	if err := WithCurrency(nil)(defaultConfig()); err == nil {
+		t.Error("WithCurrency(nil) expected error")
+	}
+}
+
 func TestClient_CallbackExecutionOrder(t *testing.T) {
 	order := make([]string, 0)
 
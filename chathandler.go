@@ -0,0 +1,456 @@
+package warp
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"slices"
+	"time"
+)
+
+// maxChatRequestBytes bounds the body of a chat handler request.
+const maxChatRequestBytes = 32 << 20
+
+// ChatHandlerOption configures a handler created by NewChatHandler.
+type ChatHandlerOption func(*chatHandler)
+
+// WithChatAuth sets a function that authorizes each request, for example
+// by checking its Authorization header. A request it returns an error for
+// is rejected with 401 Unauthorized, or with the status of a warp error
+// such as *PermissionError.
+func WithChatAuth(auth func(r *http.Request) error) ChatHandlerOption {
+	return func(h *chatHandler) {
+		h.auth = auth
+	}
+}
+
+// WithChatCORS allows browsers on the given origins to call the handler,
+// answering CORS preflight requests. "*" allows any origin.
+func WithChatCORS(origins ...string) ChatHandlerOption {
+	return func(h *chatHandler) {
+		h.origins = append(h.origins, origins...)
+	}
+}
+
+// WithChatModels sets the models the handler serves, keyed by the name
+// callers use, with the warp model ("provider/model") as value. Requests
+// for other models are rejected with 404. By default the model of each
+// request is passed to the client as is.
+func WithChatModels(models map[string]string) ChatHandlerOption {
+	return func(h *chatHandler) {
+		h.models = models
+	}
+}
+
+// chatHandler serves OpenAI chat completions from a warp client.
+type chatHandler struct {
+	client  Client
+	auth    func(r *http.Request) error
+	origins []string
+	models  map[string]string
+}
+
+// NewChatHandler returns an http.Handler implementing a minimal
+// OpenAI-compatible chat completions endpoint backed by client, so that
+// internal models can be served to any OpenAI SDK. It accepts POST
+// requests with an OpenAI chat completion body at the path it is mounted
+// at, and streams Server-Sent Events when the body sets "stream". Errors
+// are returned in the OpenAI error format.
+//
+// Per-request credentials and routing (API keys, base URLs, fallbacks) are
+// not accepted from callers. client must not be nil.
+//
+// Example:
+//
+//	client, err := warp.NewClient()
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+//	client.RegisterProvider(internalProvider)
+//
+//	http.Handle("/v1/chat/completions", warp.NewChatHandler(client,
+//	    warp.WithChatModels(map[string]string{"assistant": "vllm/meta-llama/Llama-3.1-8B-Instruct"}),
+//	    warp.WithChatAuth(func(r *http.Request) error {
+//	        if r.Header.Get("Authorization") != "Bearer "+token {
+//	            return errors.New("invalid API key")
+//	        }
+//	        return nil
+//	    }),
+//	))
+//	log.Fatal(http.ListenAndServe(":8080", nil))
+func NewChatHandler(client Client, opts ...ChatHandlerOption) http.Handler {
+	if client == nil {
+		panic("warp: NewChatHandler called with a nil client")
+	}
+	h := &chatHandler{client: client}
+	for _, opt := range opts {
+		opt(h)
+	}
+	return h
+}
+
+// chatRequest is the OpenAI chat completion request body.
+type chatRequest struct {
+	Model               string          `json:"model"`
+	Messages            []Message       `json:"messages"`
+	Temperature         *float64        `json:"temperature"`
+	MaxTokens           *int            `json:"max_tokens"`
+	MaxCompletionTokens *int            `json:"max_completion_tokens"`
+	TopP                *float64        `json:"top_p"`
+	FrequencyPenalty    *float64        `json:"frequency_penalty"`
+	PresencePenalty     *float64        `json:"presence_penalty"`
+	Stop                json.RawMessage `json:"stop"`
+	N                   *int            `json:"n"`
+	Tools               []Tool          `json:"tools"`
+	ToolChoice          json.RawMessage `json:"tool_choice"`
+	ResponseFormat      *ResponseFormat `json:"response_format"`
+	ServiceTier         ServiceTier     `json:"service_tier"`
+	Stream              bool            `json:"stream"`
+	StreamOptions       *struct {
+		IncludeUsage bool `json:"include_usage"`
+	} `json:"stream_options"`
+}
+
+// chatError is the OpenAI error body.
+type chatError struct {
+	Error chatErrorDetail `json:"error"`
+}
+
+// chatErrorDetail describes an error.
+type chatErrorDetail struct {
+	Message string `json:"message"`
+	Type    string `json:"type"`
+	Code    string `json:"code,omitempty"`
+}
+
+// ServeHTTP handles a chat completion request.
+func (h *chatHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if h.cors(w, r) {
+		return
+	}
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", "POST, OPTIONS")
+		writeChatError(w, http.StatusMethodNotAllowed, "method not allowed", "")
+		return
+	}
+	if h.auth != nil {
+		if err := h.auth(r); err != nil {
+			status := errorStatus(err)
+			if status == http.StatusInternalServerError {
+				status = http.StatusUnauthorized
+			}
+			writeChatError(w, status, err.Error(), "")
+			return
+		}
+	}
+
+	var body chatRequest
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxChatRequestBytes)).Decode(&body); err != nil {
+		writeChatError(w, http.StatusBadRequest, fmt.Sprintf("invalid request body: %v", err), "")
+		return
+	}
+	req, err := h.completionRequest(&body)
+	if err != nil {
+		var notFound *modelNotFoundError
+		if errors.As(err, &notFound) {
+			writeChatError(w, http.StatusNotFound, err.Error(), "model_not_found")
+			return
+		}
+		writeChatError(w, http.StatusBadRequest, err.Error(), "")
+		return
+	}
+
+	if body.Stream {
+		h.stream(w, r.Context(), req, body.Model, body.StreamOptions != nil && body.StreamOptions.IncludeUsage)
+		return
+	}
+
+	resp, err := h.client.Completion(r.Context(), req)
+	if err != nil {
+		writeChatError(w, errorStatus(err), err.Error(), "")
+		return
+	}
+	out := *resp
+	out.HiddenParams = nil
+	out.Model = body.Model
+	if out.Object == "" {
+		out.Object = "chat.completion"
+	}
+	if out.Created == 0 {
+		out.Created = time.Now().Unix()
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(&out)
+}
+
+// modelNotFoundError is a request for a model the handler does not serve.
+type modelNotFoundError struct {
+	model string
+}
+
+// Error implements the error interface.
+func (e *modelNotFoundError) Error() string {
+	return fmt.Sprintf("the model %q does not exist", e.model)
+}
+
+// completionRequest converts a request body to a completion request.
+func (h *chatHandler) completionRequest(body *chatRequest) (*CompletionRequest, error) {
+	if body.Model == "" {
+		return nil, fmt.Errorf("model is required")
+	}
+	if len(body.Messages) == 0 {
+		return nil, fmt.Errorf("messages are required")
+	}
+
+	model := body.Model
+	if h.models != nil {
+		var ok bool
+		if model, ok = h.models[body.Model]; !ok {
+			return nil, &modelNotFoundError{model: body.Model}
+		}
+	}
+
+	req := &CompletionRequest{
+		Model:            model,
+		Messages:         body.Messages,
+		Temperature:      body.Temperature,
+		MaxTokens:        body.MaxTokens,
+		TopP:             body.TopP,
+		FrequencyPenalty: body.FrequencyPenalty,
+		PresencePenalty:  body.PresencePenalty,
+		N:                body.N,
+		Tools:            body.Tools,
+		ResponseFormat:   body.ResponseFormat,
+		ServiceTier:      body.ServiceTier,
+	}
+	if req.MaxTokens == nil {
+		req.MaxTokens = body.MaxCompletionTokens
+	}
+	for i := range req.Messages {
+		req.Messages[i].Metadata = nil
+		if parts, ok := req.Messages[i].Content.([]any); ok {
+			req.Messages[i].Content = decodeContentParts(parts)
+		}
+	}
+
+	if len(body.Stop) > 0 {
+		var stop string
+		if err := json.Unmarshal(body.Stop, &stop); err == nil {
+			req.Stop = []string{stop}
+		} else if err := json.Unmarshal(body.Stop, &req.Stop); err != nil {
+			return nil, fmt.Errorf("stop must be a string or an array of strings")
+		}
+	}
+	if len(body.ToolChoice) > 0 {
+		var mode string
+		if err := json.Unmarshal(body.ToolChoice, &mode); err == nil {
+			req.ToolChoice = &ToolChoice{Type: mode}
+		} else if err := json.Unmarshal(body.ToolChoice, &req.ToolChoice); err != nil {
+			return nil, fmt.Errorf("tool_choice must be a string or an object")
+		}
+	}
+	return req, nil
+}
+
+// chatChunk is a streamed chunk in the OpenAI format, whose tool call
+// deltas carry the index OpenAI clients use to assemble calls.
+type chatChunk struct {
+	ID      string            `json:"id"`
+	Object  string            `json:"object"`
+	Created int64             `json:"created"`
+	Model   string            `json:"model"`
+	Choices []chatChunkChoice `json:"choices"`
+	Usage   *Usage            `json:"usage,omitempty"`
+}
+
+// chatChunkChoice is the delta of one choice in a chatChunk.
+type chatChunkChoice struct {
+	Index        int            `json:"index"`
+	Delta        chatChunkDelta `json:"delta"`
+	FinishReason *string        `json:"finish_reason"`
+	Logprobs     *Logprobs      `json:"logprobs,omitempty"`
+}
+
+// chatChunkDelta is the content of a chatChunkChoice.
+type chatChunkDelta struct {
+	Role             string              `json:"role,omitempty"`
+	Content          string              `json:"content,omitempty"`
+	ReasoningContent string              `json:"reasoning_content,omitempty"`
+	ToolCalls        []chatToolCallDelta `json:"tool_calls,omitempty"`
+}
+
+// chatToolCallDelta is a tool call delta. Index identifies the call among
+// the calls of the choice.
+type chatToolCallDelta struct {
+	Index    int    `json:"index"`
+	ID       string `json:"id,omitempty"`
+	Type     string `json:"type,omitempty"`
+	Function struct {
+		Name      string `json:"name,omitempty"`
+		Arguments string `json:"arguments"`
+	} `json:"function"`
+}
+
+// stream sends the completion as Server-Sent Events. Errors after the
+// stream started are sent as an error event in place of [DONE].
+func (h *chatHandler) stream(w http.ResponseWriter, ctx context.Context, req *CompletionRequest, model string, includeUsage bool) {
+	stream, err := h.client.CompletionStream(ctx, req)
+	if err != nil {
+		writeChatError(w, errorStatus(err), err.Error(), "")
+		return
+	}
+	defer stream.Close()
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.WriteHeader(http.StatusOK)
+	flusher, _ := w.(http.Flusher)
+	send := func(v any) bool {
+		data, err := json.Marshal(v)
+		if err != nil {
+			return false
+		}
+		if _, err := fmt.Fprintf(w, "data: %s\n\n", data); err != nil {
+			return false
+		}
+		if flusher != nil {
+			flusher.Flush()
+		}
+		return true
+	}
+
+	// Tool calls seen per choice, for the delta indexes
+	calls := make(map[int]int)
+	created := time.Now().Unix()
+	for {
+		chunk, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			fmt.Fprint(w, "data: [DONE]\n\n")
+			if flusher != nil {
+				flusher.Flush()
+			}
+			return
+		}
+		if err != nil {
+			send(chatError{Error: chatErrorDetail{Message: err.Error(), Type: chatErrorType(errorStatus(err))}})
+			return
+		}
+
+		out := chatChunk{
+			ID:      chunk.ID,
+			Object:  "chat.completion.chunk",
+			Created: chunk.Created,
+			Model:   model,
+			Choices: make([]chatChunkChoice, len(chunk.Choices)),
+		}
+		if out.Created == 0 {
+			out.Created = created
+		}
+		if includeUsage {
+			out.Usage = chunk.Usage
+		}
+		for i, choice := range chunk.Choices {
+			out.Choices[i] = chatChunkChoice{
+				Index: choice.Index,
+				Delta: chatChunkDelta{
+					Role:             choice.Delta.Role,
+					Content:          choice.Delta.Content,
+					ReasoningContent: choice.Delta.ReasoningContent,
+				},
+				FinishReason: choice.FinishReason,
+				Logprobs:     choice.Logprobs,
+			}
+			for _, tc := range choice.Delta.ToolCalls {
+				if tc.ID != "" || calls[choice.Index] == 0 {
+					calls[choice.Index]++
+				}
+				delta := chatToolCallDelta{Index: calls[choice.Index] - 1, ID: tc.ID, Type: tc.Type}
+				delta.Function.Name = tc.Function.Name
+				delta.Function.Arguments = tc.Function.Arguments
+				out.Choices[i].Delta.ToolCalls = append(out.Choices[i].Delta.ToolCalls, delta)
+			}
+		}
+		if len(out.Choices) == 0 && out.Usage == nil {
+			continue
+		}
+		if !send(out) {
+			return
+		}
+	}
+}
+
+// cors sets the CORS headers of allowed origins and reports whether the
+// request was a preflight request, which it answers.
+func (h *chatHandler) cors(w http.ResponseWriter, r *http.Request) bool {
+	origin := r.Header.Get("Origin")
+	if origin == "" || len(h.origins) == 0 {
+		return false
+	}
+	switch {
+	case slices.Contains(h.origins, "*"):
+		w.Header().Set("Access-Control-Allow-Origin", "*")
+	case slices.Contains(h.origins, origin):
+		w.Header().Set("Access-Control-Allow-Origin", origin)
+		w.Header().Add("Vary", "Origin")
+	default:
+		return false
+	}
+
+	if r.Method != http.MethodOptions || r.Header.Get("Access-Control-Request-Method") == "" {
+		return false
+	}
+	w.Header().Set("Access-Control-Allow-Methods", "POST, OPTIONS")
+	w.Header().Set("Access-Control-Allow-Headers", "Authorization, Content-Type")
+	w.Header().Set("Access-Control-Max-Age", "600")
+	w.WriteHeader(http.StatusNoContent)
+	return true
+}
+
+// writeChatError writes an error in the OpenAI format.
+func writeChatError(w http.ResponseWriter, status int, message, code string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(chatError{Error: chatErrorDetail{Message: message, Type: chatErrorType(status), Code: code}})
+}
+
+// chatErrorType returns the OpenAI error type for an HTTP status.
+func chatErrorType(status int) string {
+	switch {
+	case status == http.StatusUnauthorized:
+		return "authentication_error"
+	case status == http.StatusForbidden:
+		return "permission_error"
+	case status == http.StatusNotFound:
+		return "not_found_error"
+	case status == http.StatusTooManyRequests:
+		return "rate_limit_error"
+	case status >= 400 && status < 500:
+		return "invalid_request_error"
+	}
+	return "api_error"
+}
+
+// errorStatus returns the HTTP status for an error returned by a client:
+// the status of warp errors that have one, 504 for timeouts, 499 for
+// canceled requests and 500 otherwise.
+func errorStatus(err error) int {
+	var timeout *TimeoutError
+	var warpErr interface{ httpStatus() int }
+	switch {
+	case errors.Is(err, context.Canceled):
+		return 499
+	case errors.As(err, &timeout), errors.Is(err, context.DeadlineExceeded):
+		return http.StatusGatewayTimeout
+	case errors.As(err, &warpErr) && warpErr.httpStatus() >= 400:
+		return warpErr.httpStatus()
+	}
+	return http.StatusInternalServerError
+}
+
+// httpStatus returns the HTTP status of the error, if it has one.
+func (e *WarpError) httpStatus() int {
+	return e.StatusCode
+}
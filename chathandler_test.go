@@ -0,0 +1,211 @@
+package warp
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func chatHandlerTestClient(t *testing.T, provider *mockProvider) Client {
+	t.Helper()
+	client, err := NewClient(WithMaxRetries(0))
+	if err != nil {
+		t.Fatal(err)
+	}
+	client.RegisterProvider(provider)
+	return client
+}
+
+func serveChat(h http.Handler, method, body string, header http.Header) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, "/v1/chat/completions", strings.NewReader(body))
+	for k, v := range header {
+		req.Header[k] = v
+	}
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestChatHandler(t *testing.T) {
+	var sent *CompletionRequest
+	client := chatHandlerTestClient(t, &mockProvider{
+		name: "internal",
+		completionFunc: func(ctx context.Context, req *CompletionRequest) (*CompletionResponse, error) {
+			sent = req
+			return &CompletionResponse{
+				ID:           "chatcmpl-1",
+				Model:        req.Model,
+				Choices:      []Choice{{Message: Message{Role: "assistant", Content: "Hi!"}, FinishReason: "stop"}},
+				HiddenParams: map[string]any{"_internal": true},
+			}, nil
+		},
+	})
+	h := NewChatHandler(client, WithChatModels(map[string]string{"assistant": "internal/llama-3"}))
+
+	rec := serveChat(h, http.MethodPost, `{
+		"model": "assistant",
+		"messages": [{"role": "user", "content": [{"type": "text", "text": "Hello"}]}],
+		"max_completion_tokens": 50,
+		"stop": "END",
+		"tool_choice": "none",
+		"api_key": "sk-caller"
+	}`, nil)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, body %s", rec.Code, rec.Body)
+	}
+	var resp CompletionResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+		t.Fatal(err)
+	}
+	if resp.Model != "assistant" || resp.Object != "chat.completion" || resp.Choices[0].Message.Content != "Hi!" {
+		t.Errorf("response = %+v", resp)
+	}
+	if strings.Contains(rec.Body.String(), "_internal") {
+		t.Errorf("response %s exposes hidden params", rec.Body)
+	}
+
+	if sent.Model != "llama-3" || *sent.MaxTokens != 50 || sent.APIKey != "" {
+		t.Errorf("provider request = %+v", sent)
+	}
+	if len(sent.Stop) != 1 || sent.Stop[0] != "END" || sent.ToolChoice.Type != "none" {
+		t.Errorf("stop = %v, tool choice = %+v", sent.Stop, sent.ToolChoice)
+	}
+	if parts, ok := sent.Messages[0].Content.([]ContentPart); !ok || parts[0].Text != "Hello" {
+		t.Errorf("content = %#v, want content parts", sent.Messages[0].Content)
+	}
+}
+
+func TestChatHandler_Stream(t *testing.T) {
+	stop := "tool_calls"
+	client := chatHandlerTestClient(t, &mockProvider{
+		name: "internal",
+		completionStreamFunc: func(ctx context.Context, req *CompletionRequest) (Stream, error) {
+			return &mockStream{chunks: []*CompletionChunk{
+				{ID: "c1", Choices: []ChunkChoice{{Delta: MessageDelta{Role: "assistant", Content: "Let me check."}}}},
+				{ID: "c1", Choices: []ChunkChoice{{Delta: MessageDelta{ToolCalls: []ToolCall{{ID: "call_a", Type: "function", Function: FunctionCall{Name: "a", Arguments: `{"x"`}}}}}}},
+				{ID: "c1", Choices: []ChunkChoice{{Delta: MessageDelta{ToolCalls: []ToolCall{{Function: FunctionCall{Arguments: `:1}`}}}}}}},
+				{ID: "c1", Choices: []ChunkChoice{{Delta: MessageDelta{ToolCalls: []ToolCall{{ID: "call_b", Type: "function", Function: FunctionCall{Name: "b", Arguments: `{}`}}}}, FinishReason: &stop}}},
+				{ID: "c1", Usage: &Usage{TotalTokens: 9}},
+			}}, nil
+		},
+	})
+	h := NewChatHandler(client)
+
+	rec := serveChat(h, http.MethodPost, `{"model": "internal/llama-3", "stream": true, "messages": [{"role": "user", "content": "Hi"}]}`, nil)
+	if rec.Code != http.StatusOK || rec.Header().Get("Content-Type") != "text/event-stream" {
+		t.Fatalf("status = %d, content type %q", rec.Code, rec.Header().Get("Content-Type"))
+	}
+
+	var chunks []chatChunk
+	events := strings.Split(strings.TrimSpace(rec.Body.String()), "\n\n")
+	if events[len(events)-1] != "data: [DONE]" {
+		t.Errorf("last event = %q, want [DONE]", events[len(events)-1])
+	}
+	for _, event := range events[:len(events)-1] {
+		var chunk chatChunk
+		if err := json.Unmarshal([]byte(strings.TrimPrefix(event, "data: ")), &chunk); err != nil {
+			t.Fatalf("event %q: %v", event, err)
+		}
+		chunks = append(chunks, chunk)
+	}
+
+	// The usage chunk is dropped without stream_options.include_usage
+	if len(chunks) != 4 {
+		t.Fatalf("got %d chunks, want 4", len(chunks))
+	}
+	var indexes []int
+	for _, chunk := range chunks {
+		if chunk.Object != "chat.completion.chunk" || chunk.Model != "internal/llama-3" {
+			t.Errorf("chunk = %+v", chunk)
+		}
+		for _, tc := range chunk.Choices[0].Delta.ToolCalls {
+			indexes = append(indexes, tc.Index)
+		}
+	}
+	if len(indexes) != 3 || indexes[0] != 0 || indexes[1] != 0 || indexes[2] != 1 {
+		t.Errorf("tool call indexes = %v, want [0 0 1]", indexes)
+	}
+
+	rec = serveChat(h, http.MethodPost, `{"model": "internal/llama-3", "stream": true, "stream_options": {"include_usage": true}, "messages": [{"role": "user", "content": "Hi"}]}`, nil)
+	if !strings.Contains(rec.Body.String(), `"total_tokens":9`) {
+		t.Errorf("stream %s, want the usage chunk", rec.Body)
+	}
+}
+
+func TestChatHandler_Errors(t *testing.T) {
+	client := chatHandlerTestClient(t, &mockProvider{
+		name: "internal",
+		completionFunc: func(ctx context.Context, req *CompletionRequest) (*CompletionResponse, error) {
+			return nil, NewRateLimitError("slow down", "internal", 0, nil)
+		},
+	})
+	h := NewChatHandler(client,
+		WithChatModels(map[string]string{"assistant": "internal/llama-3"}),
+		WithChatAuth(func(r *http.Request) error {
+			if r.Header.Get("Authorization") != "Bearer secret" {
+				return errors.New("invalid API key")
+			}
+			return nil
+		}),
+	)
+	auth := http.Header{"Authorization": {"Bearer secret"}}
+
+	tests := []struct {
+		name       string
+		method     string
+		body       string
+		header     http.Header
+		wantStatus int
+		wantType   string
+	}{
+		{"method", http.MethodGet, "", auth, http.StatusMethodNotAllowed, "invalid_request_error"},
+		{"auth", http.MethodPost, `{}`, nil, http.StatusUnauthorized, "authentication_error"},
+		{"malformed body", http.MethodPost, `{`, auth, http.StatusBadRequest, "invalid_request_error"},
+		{"no messages", http.MethodPost, `{"model": "assistant"}`, auth, http.StatusBadRequest, "invalid_request_error"},
+		{"unknown model", http.MethodPost, `{"model": "gpt-4o", "messages": [{"role": "user", "content": "Hi"}]}`, auth, http.StatusNotFound, "not_found_error"},
+		{"provider error", http.MethodPost, `{"model": "assistant", "messages": [{"role": "user", "content": "Hi"}]}`, auth, http.StatusTooManyRequests, "rate_limit_error"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := serveChat(h, tt.method, tt.body, tt.header)
+			var body chatError
+			if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+				t.Fatalf("body %s: %v", rec.Body, err)
+			}
+			if rec.Code != tt.wantStatus || body.Error.Type != tt.wantType || body.Error.Message == "" {
+				t.Errorf("status = %d, error = %+v, want %d %s", rec.Code, body.Error, tt.wantStatus, tt.wantType)
+			}
+		})
+	}
+}
+
+func TestChatHandler_CORS(t *testing.T) {
+	client := chatHandlerTestClient(t, &mockProvider{name: "internal"})
+	h := NewChatHandler(client, WithChatCORS("https://app.example.com"), WithChatAuth(func(r *http.Request) error {
+		return errors.New("unauthorized")
+	}))
+
+	// Preflight requests are answered before authorization
+	rec := serveChat(h, http.MethodOptions, "", http.Header{
+		"Origin":                        {"https://app.example.com"},
+		"Access-Control-Request-Method": {"POST"},
+	})
+	if rec.Code != http.StatusNoContent || rec.Header().Get("Access-Control-Allow-Origin") != "https://app.example.com" {
+		t.Errorf("preflight status = %d, headers %v", rec.Code, rec.Header())
+	}
+	if !strings.Contains(rec.Header().Get("Access-Control-Allow-Headers"), "Authorization") {
+		t.Errorf("Access-Control-Allow-Headers = %q", rec.Header().Get("Access-Control-Allow-Headers"))
+	}
+
+	rec = serveChat(h, http.MethodOptions, "", http.Header{
+		"Origin":                        {"https://evil.example.com"},
+		"Access-Control-Request-Method": {"POST"},
+	})
+	if rec.Header().Get("Access-Control-Allow-Origin") != "" {
+		t.Errorf("other origin allowed: %v", rec.Header())
+	}
+}
@@ -0,0 +1,264 @@
+package warp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"regexp"
+	"strings"
+)
+
+// ClassifyOptions configures Classify.
+type ClassifyOptions struct {
+	// Model is the completion model that classifies the input, in
+	// "provider/model-name" form. Required.
+	Model string
+
+	// Instructions describe the task to the model, such as "Classify the
+	// sentiment of the customer review." Optional.
+	Instructions string
+
+	// Temperature overrides the default temperature of 0.
+	Temperature *float64
+}
+
+// Classification is the result of Classify.
+type Classification struct {
+	// Label is the most likely label.
+	Label string
+
+	// Probabilities maps every label to its probability. They sum to 1.
+	Probabilities map[string]float64
+
+	// Usage is the token usage of the completion.
+	Usage *Usage
+}
+
+// classifyReply is the structured output requested by Classify.
+type classifyReply struct {
+	Label  string             `json:"label"`
+	Scores map[string]float64 `json:"scores"`
+}
+
+// Classify assigns input to one of labels (zero-shot classification).
+//
+// The model is asked for structured output naming the label and scoring
+// every label, constrained by a JSON schema where the provider supports
+// it. When ProbeCapabilities has found that the model returns token log
+// probabilities, the probability of the chosen label is taken from them
+// rather than from the model's own scores.
+//
+// Returns an error if fewer than two distinct labels are given, or the
+// model does not return a valid label.
+func (c *client) Classify(ctx context.Context, input string, labels []string, opts *ClassifyOptions) (*Classification, error) {
+	if opts == nil || opts.Model == "" {
+		return nil, fmt.Errorf("model is required")
+	}
+	if err := checkLabels(labels); err != nil {
+		return nil, err
+	}
+
+	req := classifyRequest(input, labels, opts)
+	if probed, ok := c.ProbedCapabilities(opts.Model); ok && probed.Logprobs {
+		req.ExtraBody = map[string]any{"logprobs": true}
+	}
+
+	resp, err := c.Completion(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	if len(resp.Choices) == 0 {
+		return nil, fmt.Errorf("response has no choices")
+	}
+
+	text := messageText(resp.Choices[0].Message.Content)
+	object, err := extractJSON(text, nil)
+	if err != nil {
+		return nil, fmt.Errorf("model did not return a classification: %w", err)
+	}
+	var reply classifyReply
+	if err := json.Unmarshal([]byte(object), &reply); err != nil {
+		return nil, fmt.Errorf("model did not return a classification: %w", err)
+	}
+	label, ok := matchLabel(reply.Label, labels)
+	if !ok {
+		return nil, fmt.Errorf("model returned unknown label %q", reply.Label)
+	}
+
+	probs := labelProbabilities(label, labels, reply.Scores)
+	if lp := resp.Choices[0].Logprobs; lp != nil {
+		if p, ok := labelLogprob(lp.Content, reply.Label); ok {
+			probs = calibrate(probs, label, p)
+		}
+	}
+
+	// The most probable label wins, even over the one the model named
+	for _, l := range labels {
+		if probs[l] > probs[label] {
+			label = l
+		}
+	}
+	return &Classification{Label: label, Probabilities: probs, Usage: resp.Usage}, nil
+}
+
+// checkLabels returns an error unless labels are at least two distinct,
+// non-empty strings.
+func checkLabels(labels []string) error {
+	if len(labels) < 2 {
+		return fmt.Errorf("at least two labels are required")
+	}
+	seen := make(map[string]bool, len(labels))
+	for _, l := range labels {
+		if l == "" {
+			return fmt.Errorf("labels cannot be empty")
+		}
+		if seen[l] {
+			return fmt.Errorf("duplicate label %q", l)
+		}
+		seen[l] = true
+	}
+	return nil
+}
+
+// classifyRequest builds the completion request for Classify.
+func classifyRequest(input string, labels []string, opts *ClassifyOptions) *CompletionRequest {
+	quoted := make([]string, len(labels))
+	scores := make(map[string]any, len(labels))
+	for i, l := range labels {
+		quoted[i] = fmt.Sprintf("%q", l)
+		scores[l] = map[string]any{"type": "number"}
+	}
+
+	prompt := "You are a text classifier. Assign the input to exactly one of these labels: " +
+		strings.Join(quoted, ", ") + ". Reply with a JSON object whose \"label\" is the chosen label " +
+		"and whose \"scores\" gives the probability, from 0 to 1, that each label is correct."
+	if opts.Instructions != "" {
+		prompt = opts.Instructions + "\n\n" + prompt
+	}
+
+	temperature := opts.Temperature
+	if temperature == nil {
+		temperature = Float64Ptr(0)
+	}
+	return &CompletionRequest{
+		Model: opts.Model,
+		Messages: []Message{
+			{Role: "system", Content: prompt},
+			{Role: "user", Content: input},
+		},
+		Temperature: temperature,
+		ResponseFormat: &ResponseFormat{
+			Type: "json_schema",
+			JSONSchema: &JSONSchema{
+				Name: "classification",
+				Schema: map[string]any{
+					"type": "object",
+					"properties": map[string]any{
+						"label": map[string]any{"type": "string", "enum": labels},
+						"scores": map[string]any{
+							"type":                 "object",
+							"properties":           scores,
+							"required":             labels,
+							"additionalProperties": false,
+						},
+					},
+					"required":             []string{"label", "scores"},
+					"additionalProperties": false,
+				},
+				Strict: BoolPtr(true),
+			},
+		},
+	}
+}
+
+// matchLabel returns the label equal to name, ignoring case and
+// surrounding space if there is no exact match.
+func matchLabel(name string, labels []string) (string, bool) {
+	for _, l := range labels {
+		if l == name {
+			return l, true
+		}
+	}
+	for _, l := range labels {
+		if strings.EqualFold(l, strings.TrimSpace(name)) {
+			return l, true
+		}
+	}
+	return "", false
+}
+
+// labelProbabilities normalizes the model's scores to probabilities. If
+// the scores are missing or all zero, the chosen label gets probability 1.
+func labelProbabilities(label string, labels []string, scores map[string]float64) map[string]float64 {
+	probs := make(map[string]float64, len(labels))
+	var sum float64
+	for _, l := range labels {
+		if s := scores[l]; s > 0 && !math.IsInf(s, 0) {
+			probs[l] = s
+			sum += s
+		}
+	}
+	if sum == 0 {
+		for _, l := range labels {
+			probs[l] = 0
+		}
+		probs[label] = 1
+		return probs
+	}
+	for _, l := range labels {
+		probs[l] /= sum
+	}
+	return probs
+}
+
+// calibrate sets the probability of label to p and scales the others to
+// share the remainder in their existing proportions.
+func calibrate(probs map[string]float64, label string, p float64) map[string]float64 {
+	rest := 1 - probs[label]
+	out := make(map[string]float64, len(probs))
+	for l, q := range probs {
+		switch {
+		case l == label:
+			out[l] = p
+		case rest > 0:
+			out[l] = q / rest * (1 - p)
+		default:
+			out[l] = (1 - p) / float64(len(probs)-1)
+		}
+	}
+	return out
+}
+
+// labelValue finds the value of the "label" field in a JSON reply.
+var labelValue = regexp.MustCompile(`"label"\s*:\s*"`)
+
+// labelLogprob returns the probability of the tokens that spell the label
+// value in a JSON reply, from the reply's token log probabilities.
+func labelLogprob(tokens []TokenLogprob, label string) (float64, bool) {
+	if len(tokens) == 0 || label == "" {
+		return 0, false
+	}
+	var sb strings.Builder
+	for _, t := range tokens {
+		sb.WriteString(t.Token)
+	}
+	text := sb.String()
+
+	loc := labelValue.FindStringIndex(text)
+	if loc == nil || !strings.HasPrefix(text[loc[1]:], label) {
+		return 0, false
+	}
+	start, end := loc[1], loc[1]+len(label)
+
+	var sum float64
+	pos := 0
+	for _, t := range tokens {
+		tokenStart, tokenEnd := pos, pos+len(t.Token)
+		pos = tokenEnd
+		if tokenEnd > start && tokenStart < end {
+			sum += t.Logprob
+		}
+	}
+	return math.Exp(sum), true
+}
@@ -0,0 +1,119 @@
+package warp
+
+import (
+	"context"
+	"math"
+	"testing"
+)
+
+func TestClassify(t *testing.T) {
+	labels := []string{"positive", "negative", "neutral"}
+
+	tests := []struct {
+		name      string
+		reply     string
+		logprobs  *Logprobs
+		wantLabel string
+		wantProb  float64
+		wantErr   bool
+	}{
+		{
+			name:      "model scores",
+			reply:     `{"label":"negative","scores":{"positive":0.1,"negative":0.6,"neutral":0.3}}`,
+			wantLabel: "negative",
+			wantProb:  0.6,
+		},
+		{
+			name:      "scores normalized",
+			reply:     `{"label":"Positive","scores":{"positive":2,"negative":1,"neutral":1}}`,
+			wantLabel: "positive",
+			wantProb:  0.5,
+		},
+		{
+			name:      "missing scores",
+			reply:     "```json\n{\"label\":\"neutral\"}\n```",
+			wantLabel: "neutral",
+			wantProb:  1,
+		},
+		{
+			name:  "logprobs",
+			reply: `{"label":"positive","scores":{"positive":0.5,"negative":0.25,"neutral":0.25}}`,
+			logprobs: &Logprobs{Content: []TokenLogprob{
+				{Token: `{"label":"`, Logprob: 0},
+				{Token: "pos", Logprob: math.Log(0.9)},
+				{Token: "itive", Logprob: 0},
+				{Token: `","scores":{...}}`, Logprob: math.Log(0.5)},
+			}},
+			wantLabel: "positive",
+			wantProb:  0.9,
+		},
+		{
+			name:    "unknown label",
+			reply:   `{"label":"angry","scores":{}}`,
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client, err := NewClient()
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer client.Close()
+
+			var got *CompletionRequest
+			client.RegisterProvider(&mockProvider{name: "mock", completionFunc: func(ctx context.Context, req *CompletionRequest) (*CompletionResponse, error) {
+				got = req
+				return &CompletionResponse{Choices: []Choice{{
+					Message:  Message{Role: "assistant", Content: tt.reply},
+					Logprobs: tt.logprobs,
+				}}}, nil
+			}})
+
+			result, err := client.Classify(context.Background(), "Great product!", labels, &ClassifyOptions{Model: "mock/m"})
+			if tt.wantErr {
+				if err == nil {
+					t.Fatal("Classify() error = nil, want error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Classify() error = %v", err)
+			}
+			if got.ResponseFormat == nil || got.ResponseFormat.Type != "json_schema" {
+				t.Errorf("ResponseFormat = %+v, want json_schema", got.ResponseFormat)
+			}
+			if result.Label != tt.wantLabel {
+				t.Errorf("Label = %q, want %q", result.Label, tt.wantLabel)
+			}
+			if p := result.Probabilities[tt.wantLabel]; math.Abs(p-tt.wantProb) > 1e-9 {
+				t.Errorf("Probabilities[%s] = %v, want %v", tt.wantLabel, p, tt.wantProb)
+			}
+			var sum float64
+			for _, p := range result.Probabilities {
+				sum += p
+			}
+			if math.Abs(sum-1) > 1e-9 || len(result.Probabilities) != len(labels) {
+				t.Errorf("Probabilities = %v, want %d labels summing to 1", result.Probabilities, len(labels))
+			}
+		})
+	}
+}
+
+func TestClassifyValidation(t *testing.T) {
+	client, err := NewClient()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+
+	opts := &ClassifyOptions{Model: "mock/m"}
+	for _, labels := range [][]string{nil, {"a"}, {"a", "a"}, {"a", ""}} {
+		if _, err := client.Classify(context.Background(), "x", labels, opts); err == nil {
+			t.Errorf("Classify(%q) error = nil, want error", labels)
+		}
+	}
+	if _, err := client.Classify(context.Background(), "x", []string{"a", "b"}, nil); err == nil {
+		t.Error("Classify() without model error = nil, want error")
+	}
+}
@@ -10,6 +10,7 @@ import (
 	"strings"
 	"sync"
 	"time"
+	"unicode/utf8"
 
 	"github.com/blue-context/warp/cache"
 	"github.com/blue-context/warp/callback"
@@ -146,16 +147,98 @@ type Client interface {
 	//   }
 	Rerank(ctx context.Context, req *RerankRequest) (*RerankResponse, error)
 
+	// Classify assigns input to one of labels with a completion model and
+	// returns the probability of each label
+	//
+	// Example:
+	//   result, err := client.Classify(ctx, review, []string{"positive", "negative", "neutral"},
+	//       &warp.ClassifyOptions{Model: "openai/gpt-4o-mini"})
+	//   if err != nil {
+	//       log.Fatal(err)
+	//   }
+	//   fmt.Println(result.Label, result.Probabilities[result.Label])
+	Classify(ctx context.Context, input string, labels []string, opts *ClassifyOptions) (*Classification, error)
+
 	// CompletionCost calculates the cost of a completion
 	//
 	// Returns 0 if pricing information is not available.
 	CompletionCost(resp *CompletionResponse) (float64, error)
 
+	// EstimateCompletionCost estimates the tokens and cost of a completion
+	// request before it is sent
+	EstimateCompletionCost(req *CompletionRequest) (*CostEstimate, error)
+
+	// RegisterModelPricing sets custom per-1M-token pricing for a model
+	//
+	// Useful for fine-tuned models, negotiated rates, and self-hosted models.
+	RegisterModelPricing(model string, inputPer1M, outputPer1M float64, opts ...cost.PricingOption) error
+
+	// EmbeddingCost calculates the cost of an embedding request
+	EmbeddingCost(resp *EmbeddingResponse) (float64, error)
+
+	// ImageCost calculates the cost of an image generation, edit, or variation
+	ImageCost(resp *ImageGenerationResponse) (float64, error)
+
+	// SpeechCost calculates the cost of synthesizing input with a text-to-speech model
+	SpeechCost(model, input string) (float64, error)
+
+	// TranscriptionCost calculates the cost of a transcription
+	TranscriptionCost(resp *TranscriptionResponse) (float64, error)
+
+	// Responses sends a request in the shape of OpenAI's Responses API,
+	// converting it to a completion request for providers without it
+	Responses(ctx context.Context, req *ResponsesRequest) (*ResponsesResponse, error)
+
+	// CreateCachedContent stores content with a provider for reuse across
+	// requests, referenced with CompletionRequest.CachedContent
+	//
+	// Returns an error if the provider does not implement
+	// CachedContentManager.
+	CreateCachedContent(ctx context.Context, req *CachedContentRequest) (*CachedContent, error)
+
+	// GetCachedContent returns cached content by name
+	//
+	// The model must be in "provider/model-name" format.
+	GetCachedContent(ctx context.Context, model, name string) (*CachedContent, error)
+
+	// UpdateCachedContentTTL keeps cached content for ttl from now
+	UpdateCachedContentTTL(ctx context.Context, model, name string, ttl time.Duration) (*CachedContent, error)
+
+	// DeleteCachedContent deletes cached content, ending its storage charges
+	DeleteCachedContent(ctx context.Context, model, name string) error
+
+	// CachedContentStorageCost calculates the cost of storing cached
+	// content until it expires
+	CachedContentStorageCost(cached *CachedContent) (float64, error)
+
+	// CacheStats returns hit/miss/eviction counters for the response cache
+	//
+	// Returns an error if no cache is configured or the cache does not
+	// implement cache.StatsReporter.
+	CacheStats() (cache.Stats, error)
+
+	// BulkheadStats returns the limit, in-flight, and rejected request
+	// counts of each provider bulkhead configured with WithBulkhead
+	BulkheadStats() map[string]BulkheadStats
+
+	// ProbeCapabilities tests which features a model's endpoint supports
+	// (tools, JSON mode, logprobs, vision) by issuing tiny requests
+	//
+	// The model must be in "provider/model-name" format.
+	ProbeCapabilities(ctx context.Context, model string) (*ProbeResult, error)
+
+	// ProbedCapabilities returns the last probe result for a model
+	ProbedCapabilities(model string) (*ProbeResult, bool)
+
 	// Close closes the client and releases resources
 	Close() error
 
 	// RegisterProvider registers a provider with the client
 	RegisterProvider(p Provider) error
+
+	// With returns a derived client that shares this client's providers
+	// but applies opts on top of its configuration
+	With(opts ...ClientOption) (Client, error)
 }
 
 // client implements the Client interface
@@ -167,6 +250,9 @@ type client struct {
 	budget           *cost.BudgetManager
 	cache            cache.Cache
 	callbacks        *callback.Registry
+	probed           map[string]*ProbeResult // Capabilities observed by ProbeCapabilities
+	bulkheads        *bulkheads              // Per-provider concurrency limits, nil if none
+	parent           *client                 // Client this one was derived from with With, if any
 	mu               sync.RWMutex
 	randMu           sync.Mutex
 	randSrc          *rand.Rand
@@ -211,23 +297,35 @@ func NewClient(opts ...ClientOption) (Client, error) {
 		return nil, fmt.Errorf("invalid configuration: %w", err)
 	}
 
+	if config.Callbacks != nil {
+		config.Callbacks.SetPanicRecovery(!config.DisablePanicRecovery)
+	}
+
 	// Create client
 	c := &client{
 		config:    config,
 		providers: make(map[string]Provider),
 		randSrc:   rand.New(rand.NewSource(time.Now().UnixNano())),
 		cache:     config.Cache,
-		callbacks: config.Callbacks,
+		callbacks: config.callbackRegistry(),
+		bulkheads: newBulkheads(config.Bulkheads),
 	}
 
 	// Create provider registry wrapper
 	c.providerRegistry = providerRegistry{client: c}
 
-	// Initialize cost tracking if enabled
+	// The calculator is always available for CompletionCost and custom
+	// pricing; TrackCost controls whether costs are computed per request.
+	c.costCalc = cost.NewCalculator(c.providerRegistry)
+
+	// Initialize budget tracking if enabled
 	if config.TrackCost {
-		c.costCalc = cost.NewCalculator(c.providerRegistry)
 		if config.MaxBudget > 0 {
-			c.budget = cost.NewBudgetManager(config.MaxBudget)
+			if config.Currency != nil {
+				c.budget = cost.NewBudgetManagerInCurrency(config.MaxBudget, config.Currency)
+			} else {
+				c.budget = cost.NewBudgetManager(config.MaxBudget)
+			}
 		}
 	}
 
@@ -269,6 +367,12 @@ func parseModel(model string) (provider, modelName string, err error) {
 
 // withRetry executes a function with retry logic
 func (c *client) withRetry(ctx context.Context, fn func() error) error {
+	return c.withRetryIf(ctx, fn, nil)
+}
+
+// withRetryIf executes a function with retry logic, retrying only while
+// canRetry (if not nil) reports true, e.g., while uploads can be resent.
+func (c *client) withRetryIf(ctx context.Context, fn func() error, canRetry func() bool) error {
 	var lastErr error
 
 	maxRetries := c.config.MaxRetries
@@ -284,8 +388,11 @@ func (c *client) withRetry(ctx context.Context, fn func() error) error {
 		default:
 		}
 
-		// Execute function
-		err := fn()
+		// Execute function, after any injected latency or error
+		err := c.injectFault(ctx, attempt)
+		if err == nil {
+			err = fn()
+		}
 		if err == nil {
 			return nil
 		}
@@ -293,7 +400,7 @@ func (c *client) withRetry(ctx context.Context, fn func() error) error {
 		lastErr = err
 
 		// Check if error is retryable
-		if !isRetryable(err) {
+		if !isRetryable(err) || (canRetry != nil && !canRetry()) {
 			return err
 		}
 
@@ -306,11 +413,8 @@ func (c *client) withRetry(ctx context.Context, fn func() error) error {
 		delay := c.calculateDelay(attempt)
 
 		// Wait with context cancellation support
-		select {
-		case <-time.After(delay):
-			// Continue to next attempt
-		case <-ctx.Done():
-			return ctx.Err()
+		if err := c.sleep(ctx, delay); err != nil {
+			return err
 		}
 	}
 
@@ -383,20 +487,210 @@ func (c *client) CompletionCost(resp *CompletionResponse) (float64, error) {
 		return 0, fmt.Errorf("usage information not available in response")
 	}
 
-	// Use cost calculator
-	if c.costCalc == nil {
-		c.costCalc = cost.NewCalculator(c.providerRegistry)
+	return c.costCalc.CalculateCompletion(resp)
+}
+
+// EmbeddingCost calculates the cost of an embedding request.
+//
+// Embeddings are billed per input token at the model's input price.
+// Returns the cost in USD, or error if pricing not available.
+//
+// Example:
+//
+//	resp, _ := client.Embedding(ctx, req)
+//	cost, err := client.EmbeddingCost(resp)
+func (c *client) EmbeddingCost(resp *EmbeddingResponse) (float64, error) {
+	if resp == nil {
+		return 0, fmt.Errorf("response cannot be nil")
+	}
+	if resp.Usage == nil {
+		return 0, fmt.Errorf("usage information not available in response")
+	}
+
+	// Providers report the bare model name; qualify it with the provider
+	// recorded by Embedding so pricing is looked up in the right place.
+	if resp.Provider != "" && !strings.HasPrefix(resp.Model, resp.Provider+"/") {
+		qualified := *resp
+		qualified.Model = resp.Provider + "/" + resp.Model
+		resp = &qualified
 	}
 
-	return c.costCalc.CalculateCompletion(resp)
+	return c.costCalc.CalculateEmbedding(resp)
+}
+
+// ImageCost calculates the cost of generated images.
+//
+// Images are billed per image by size and quality, using the metadata the
+// client records on the response.
+// Returns the cost in USD, or error if pricing not available.
+//
+// Example:
+//
+//	resp, _ := client.ImageGeneration(ctx, req)
+//	cost, err := client.ImageCost(resp)
+func (c *client) ImageCost(resp *ImageGenerationResponse) (float64, error) {
+	if resp == nil {
+		return 0, fmt.Errorf("response cannot be nil")
+	}
+	if resp.Provider == "" || resp.Model == "" {
+		return 0, fmt.Errorf("response is missing provider or model metadata")
+	}
+
+	return c.costCalc.CalculateImage(resp.Provider, resp.Model, resp.Size, resp.Quality, len(resp.Data))
+}
+
+// SpeechCost calculates the cost of text-to-speech for input.
+//
+// The model must be in "provider/model-name" format. Speech is billed per
+// input character (Unicode code point). With cost tracking enabled, the
+// success callbacks of Speech report this cost.
+// Returns the cost in USD, or error if pricing not available.
+//
+// Example:
+//
+//	cost, err := client.SpeechCost("openai/tts-1", req.Input)
+func (c *client) SpeechCost(model, input string) (float64, error) {
+	providerName, modelName, err := parseModel(model)
+	if err != nil {
+		return 0, err
+	}
+
+	return c.costCalc.CalculateSpeech(providerName, modelName, utf8.RuneCountInString(input))
+}
+
+// TranscriptionCost calculates the cost of a transcription.
+//
+// Transcription is billed per minute of audio. The duration comes from the
+// response (see TranscriptionResponse.AudioDuration), so request the
+// "verbose_json" response format to get a billable duration. With cost
+// tracking enabled, the success callbacks of Transcription report this cost.
+// Returns the cost in USD, or error if pricing or duration is not available.
+//
+// Example:
+//
+//	resp, _ := client.Transcription(ctx, req)
+//	cost, err := client.TranscriptionCost(resp)
+func (c *client) TranscriptionCost(resp *TranscriptionResponse) (float64, error) {
+	if resp == nil {
+		return 0, fmt.Errorf("response cannot be nil")
+	}
+	if resp.Provider == "" || resp.Model == "" {
+		return 0, fmt.Errorf("response is missing provider or model metadata")
+	}
+
+	duration := resp.AudioDuration()
+	if duration == 0 {
+		return 0, fmt.Errorf("audio duration not available in response (use verbose_json response format)")
+	}
+
+	return c.costCalc.CalculateTranscription(resp.Provider, resp.Model, duration)
+}
+
+// RegisterModelPricing sets custom pricing for a model.
+//
+// The model must be in "provider/model-name" format. Registered pricing takes
+// precedence over provider-reported pricing for all cost calculations.
+// Returns an error if the model format is invalid or a price is negative.
+//
+// Example:
+//
+//	// Negotiated enterprise rate with cached-input pricing
+//	err := client.RegisterModelPricing("openai/ft:gpt-4o-mini:acme", 0.30, 1.20,
+//	    cost.WithCachedInputPrice(0.15))
+func (c *client) RegisterModelPricing(model string, inputPer1M, outputPer1M float64, opts ...cost.PricingOption) error {
+	providerName, modelName, err := parseModel(model)
+	if err != nil {
+		return err
+	}
+
+	info, err := cost.NewModelPricing(providerName, modelName, inputPer1M, outputPer1M, opts...)
+	if err != nil {
+		return err
+	}
+
+	c.costCalc.AddPricingOverride(providerName, modelName, info)
+	return nil
+}
+
+// localCost converts a USD cost into the configured reporting currency.
+//
+// Falls back to USD if no currency is configured or conversion fails, so
+// callbacks always receive a consistent currency/amount pair.
+func (c *client) localCost(ctx context.Context, usd float64) (string, float64) {
+	if c.config.Currency == nil {
+		return cost.USD, usd
+	}
+
+	converted, err := c.config.Currency.Convert(ctx, usd)
+	if err != nil {
+		return cost.USD, usd
+	}
+	return c.config.Currency.Currency(), converted
+}
+
+// checkBudget returns *BudgetExceededError if spend has reached
+// MaxBudget. It is called before a request is sent, so requests are
+// refused rather than paid for once the budget is spent.
+func (c *client) checkBudget(providerName, modelName string) error {
+	if c.budget == nil {
+		return nil
+	}
+	if err := c.budget.Check(); err != nil {
+		return NewBudgetExceededError(err.Error(), providerName, modelName, err)
+	}
+	return nil
+}
+
+// chargeBudget records a request's USD cost against the client's budget.
+//
+// The request has already been paid for, so the cost is recorded even if
+// it takes spend past MaxBudget; checkBudget then refuses later requests.
+// Costs that cannot be converted to the budget currency are not charged,
+// just as callbacks fall back to USD.
+func (c *client) chargeBudget(providerName, modelName string, usd float64) {
+	if c.budget == nil {
+		return
+	}
+	_ = c.budget.RecordCost(usd, providerName+"/"+modelName, "")
+}
+
+// chargeImages charges the cost of generated images to the client's budget.
+func (c *client) chargeImages(resp *ImageGenerationResponse) {
+	if c.budget == nil {
+		return
+	}
+	if cost, err := c.ImageCost(resp); err == nil {
+		c.chargeBudget(resp.Provider, resp.Model, cost)
+	}
+}
+
+// CacheStats returns hit/miss/eviction counters for the response cache.
+//
+// Example:
+//
+//	stats, err := client.CacheStats()
+//	if err == nil {
+//	    fmt.Printf("Cache hit rate: %.1f%%\n", stats.HitRate()*100)
+//	}
+func (c *client) CacheStats() (cache.Stats, error) {
+	if c.cache == nil {
+		return cache.Stats{}, fmt.Errorf("no cache configured")
+	}
+
+	reporter, ok := c.cache.(cache.StatsReporter)
+	if !ok {
+		return cache.Stats{}, fmt.Errorf("cache %T does not report statistics", c.cache)
+	}
+
+	return reporter.Stats(), nil
 }
 
 // Close closes the client and releases resources.
 //
 // After calling Close, the client should not be used.
 func (c *client) Close() error {
-	// Close cache if present
-	if c.cache != nil {
+	// Close cache if present, unless it is shared with the parent client
+	if c.cache != nil && (c.parent == nil || c.cache != c.parent.cache) {
 		if err := c.cache.Close(); err != nil {
 			return fmt.Errorf("failed to close cache: %w", err)
 		}
@@ -434,23 +728,25 @@ func (c *client) RegisterProvider(p Provider) error {
 		return fmt.Errorf("provider name cannot be empty")
 	}
 
-	c.mu.Lock()
-	defer c.mu.Unlock()
+	root := c.root()
+	root.mu.Lock()
+	defer root.mu.Unlock()
 
-	if _, exists := c.providers[name]; exists {
+	if _, exists := root.providers[name]; exists {
 		return fmt.Errorf("provider %q already registered", name)
 	}
 
-	c.providers[name] = p
+	root.providers[name] = p
 	return nil
 }
 
 // getProvider retrieves a provider by name.
 func (c *client) getProvider(name string) (Provider, error) {
-	c.mu.RLock()
-	defer c.mu.RUnlock()
+	root := c.root()
+	root.mu.RLock()
+	defer root.mu.RUnlock()
 
-	p, exists := c.providers[name]
+	p, exists := root.providers[name]
 	if !exists {
 		return nil, fmt.Errorf("provider %q not found", name)
 	}
@@ -9,6 +9,7 @@ import (
 	"time"
 
 	"github.com/blue-context/warp/cache"
+	"github.com/blue-context/warp/cost"
 	"github.com/blue-context/warp/types"
 )
 
@@ -133,7 +134,11 @@ func (m *mockProvider) GetModelInfo(model string) *types.ModelInfo {
 }
 
 func (m *mockProvider) ListModels() []*types.ModelInfo {
-	return nil
+	models := make([]*types.ModelInfo, 0, len(m.modelInfo))
+	for _, info := range m.modelInfo {
+		models = append(models, info)
+	}
+	return models
 }
 
 func (m *mockProvider) Supports() interface{} {
@@ -1334,6 +1339,90 @@ func TestClientWithCostTrackingInitialization(t *testing.T) {
 	}
 }
 
+// TestCompletionBudgetEnforced tests that completion costs are charged to
+// MaxBudget and requests are refused once it is spent
+func TestCompletionBudgetEnforced(t *testing.T) {
+	client, err := NewClient(WithMaxBudget(1.5))
+	if err != nil {
+		t.Fatalf("NewClient() error = %v", err)
+	}
+	defer client.Close()
+
+	if err := client.RegisterModelPricing("test/m", 1000, 0); err != nil {
+		t.Fatalf("RegisterModelPricing() error = %v", err)
+	}
+	calls := 0
+	usage := &Usage{PromptTokens: 1000, TotalTokens: 1000}
+	client.RegisterProvider(&mockProvider{
+		name: "test",
+		completionFunc: func(ctx context.Context, req *CompletionRequest) (*CompletionResponse, error) {
+			calls++
+			return &CompletionResponse{
+				Model:   "test/m",
+				Choices: []Choice{{Message: Message{Role: "assistant", Content: "ok"}}},
+				Usage:   usage,
+			}, nil
+		},
+	})
+
+	// The first request is under budget; the second overshoots it but has
+	// been paid for, so it is returned and recorded
+	req := &CompletionRequest{Model: "test/m", Messages: []Message{{Role: "user", Content: "hi"}}}
+	if _, err := client.Completion(context.Background(), req); err != nil {
+		t.Fatalf("first Completion() error = %v", err)
+	}
+	resp, err := client.Completion(context.Background(), req)
+	if err != nil || resp == nil {
+		t.Fatalf("second Completion() = %v, %v, want the paid-for response", resp, err)
+	}
+
+	_, err = client.Completion(context.Background(), req)
+	var budgetErr *BudgetExceededError
+	if !errors.As(err, &budgetErr) {
+		t.Fatalf("third Completion() error = %v, want *BudgetExceededError", err)
+	}
+	if budgetErr.Provider != "test" || budgetErr.Model != "m" {
+		t.Errorf("BudgetExceededError = %+v, want provider test and model m", budgetErr)
+	}
+	if calls != 2 {
+		t.Errorf("provider calls = %d, want 2 (refused before sending)", calls)
+	}
+}
+
+// TestCompletionStreamBudget tests that stream usage is charged to MaxBudget
+func TestCompletionStreamBudget(t *testing.T) {
+	client, err := NewClient(WithMaxBudget(1.0))
+	if err != nil {
+		t.Fatalf("NewClient() error = %v", err)
+	}
+	defer client.Close()
+
+	if err := client.RegisterModelPricing("test/m", 1000, 0); err != nil {
+		t.Fatalf("RegisterModelPricing() error = %v", err)
+	}
+	client.RegisterProvider(&mockProvider{
+		name: "test",
+		completionStreamFunc: func(ctx context.Context, req *CompletionRequest) (Stream, error) {
+			return &mockStream{chunks: []*CompletionChunk{{Model: "m", Usage: &Usage{PromptTokens: 1000, TotalTokens: 1000}}}}, nil
+		},
+	})
+
+	req := &CompletionRequest{Model: "test/m", Messages: []Message{{Role: "user", Content: "hi"}}}
+	stream, err := client.CompletionStream(context.Background(), req)
+	if err != nil {
+		t.Fatalf("CompletionStream() error = %v", err)
+	}
+	if _, err := CollectStream(stream); err != nil {
+		t.Fatalf("CollectStream() error = %v", err)
+	}
+
+	_, err = client.CompletionStream(context.Background(), req)
+	var budgetErr *BudgetExceededError
+	if !errors.As(err, &budgetErr) {
+		t.Errorf("CompletionStream() after spend error = %v, want *BudgetExceededError", err)
+	}
+}
+
 // TestClientWithCache tests cache integration
 func TestClientWithCache(t *testing.T) {
 	memCache := cache.NewMemoryCache(1024 * 1024) // 1MB
@@ -1626,3 +1715,151 @@ func TestClientCacheClose(t *testing.T) {
 		t.Errorf("Close() error = %v", err)
 	}
 }
+
+// TestClientCacheStats tests cache statistics reporting through the client
+func TestClientCacheStats(t *testing.T) {
+	noCache, err := NewClient()
+	if err != nil {
+		t.Fatalf("NewClient() error = %v", err)
+	}
+	if _, err := noCache.CacheStats(); err == nil {
+		t.Error("CacheStats() expected error without cache")
+	}
+
+	client, err := NewClient(WithCache(cache.NewMemoryCache(0)))
+	if err != nil {
+		t.Fatalf("NewClient() error = %v", err)
+	}
+	defer client.Close()
+
+	mock := &mockProvider{
+		name: "test",
+		completionFunc: func(ctx context.Context, req *CompletionRequest) (*CompletionResponse, error) {
+			return &CompletionResponse{ID: "test-123", Model: req.Model}, nil
+		},
+	}
+	if err := client.RegisterProvider(mock); err != nil {
+		t.Fatalf("failed to register mock provider: %v", err)
+	}
+
+	req := &CompletionRequest{
+		Model:    "test/gpt-4",
+		Messages: []Message{{Role: "user", Content: "Hello"}},
+	}
+	for i := 0; i < 3; i++ {
+		if _, err := client.Completion(context.Background(), req); err != nil {
+			t.Fatalf("Completion() error = %v", err)
+		}
+	}
+
+	stats, err := client.CacheStats()
+	if err != nil {
+		t.Fatalf("CacheStats() error = %v", err)
+	}
+	if stats.Hits != 2 || stats.Misses != 1 {
+		t.Errorf("CacheStats() = %+v, want 2 hits and 1 miss", stats)
+	}
+	if stats.Entries != 1 {
+		t.Errorf("Entries = %d, want 1", stats.Entries)
+	}
+}
+
+// TestClientRegisterModelPricing tests custom per-model pricing
+func TestClientRegisterModelPricing(t *testing.T) {
+	client, err := NewClient()
+	if err != nil {
+		t.Fatalf("NewClient() error = %v", err)
+	}
+	defer client.Close()
+
+	if err := client.RegisterProvider(&mockProvider{name: "test"}); err != nil {
+		t.Fatalf("failed to register mock provider: %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		model   string
+		input   float64
+		output  float64
+		wantErr bool
+	}{
+		{name: "valid", model: "test/ft-model", input: 2.0, output: 4.0},
+		{name: "missing provider", model: "ft-model", input: 2.0, output: 4.0, wantErr: true},
+		{name: "negative price", model: "test/ft-model", input: -1, output: 4.0, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := client.RegisterModelPricing(tt.model, tt.input, tt.output)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("RegisterModelPricing() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+
+	got, err := client.CompletionCost(&CompletionResponse{
+		Model: "test/ft-model",
+		Usage: &Usage{PromptTokens: 1_000_000, CompletionTokens: 1_000_000},
+	})
+	if err != nil {
+		t.Fatalf("CompletionCost() error = %v", err)
+	}
+	if got != 6.0 {
+		t.Errorf("CompletionCost() = %f, want 6.0 (registered pricing)", got)
+	}
+}
+
+// TestClientEmbeddingAndImageCost tests embedding and image cost calculation
+func TestClientEmbeddingAndImageCost(t *testing.T) {
+	client, err := NewClient()
+	if err != nil {
+		t.Fatalf("NewClient() error = %v", err)
+	}
+	defer client.Close()
+
+	mock := &mockProvider{
+		name: "test",
+		embeddingFunc: func(ctx context.Context, req *EmbeddingRequest) (*EmbeddingResponse, error) {
+			return &EmbeddingResponse{
+				Model: req.Model,
+				Usage: &EmbeddingUsage{PromptTokens: 500_000, TotalTokens: 500_000},
+			}, nil
+		},
+	}
+	if err := client.RegisterProvider(mock); err != nil {
+		t.Fatalf("failed to register mock provider: %v", err)
+	}
+
+	resp, err := client.Embedding(context.Background(), &EmbeddingRequest{Model: "test/embed", Input: "hello"})
+	if err != nil {
+		t.Fatalf("Embedding() error = %v", err)
+	}
+	// Default mock pricing is $10 per 1M input tokens
+	if got, err := client.EmbeddingCost(resp); err != nil || got != 5.0 {
+		t.Errorf("EmbeddingCost() = %f, %v; want 5.0, nil", got, err)
+	}
+	if _, err := client.EmbeddingCost(&EmbeddingResponse{Model: "test/embed"}); err == nil {
+		t.Error("EmbeddingCost() expected error without usage")
+	}
+
+	if err := client.RegisterModelPricing("test/image", 0, 0,
+		cost.WithImagePrice("hd/1024x1024", 0.08),
+		cost.WithImagePrice("1024x1024", 0.04),
+	); err != nil {
+		t.Fatalf("RegisterModelPricing() error = %v", err)
+	}
+
+	imageResp := &ImageGenerationResponse{
+		Data:     []ImageData{{URL: "a"}, {URL: "b"}},
+		Provider: "test",
+		Model:    "image",
+		Size:     "1024x1024",
+		Quality:  "hd",
+	}
+	if got, err := client.ImageCost(imageResp); err != nil || got != 0.16 {
+		t.Errorf("ImageCost() = %f, %v; want 0.16, nil", got, err)
+	}
+	if _, err := client.ImageCost(&ImageGenerationResponse{}); err == nil {
+		t.Error("ImageCost() expected error without metadata")
+	}
+}
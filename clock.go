@@ -0,0 +1,154 @@
+package warp
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// Clock is the client's source of time. It drives request timestamps,
+// retry backoff, request timeouts, and injected latency, so tests can
+// replace it with a fake clock (see the warptest package) and exercise
+// resilience behavior deterministically.
+type Clock interface {
+	// Now returns the current time.
+	Now() time.Time
+
+	// NewTimer returns a timer that fires once d has elapsed.
+	NewTimer(d time.Duration) Timer
+}
+
+// Timer is a single-use timer created by a Clock.
+type Timer interface {
+	// C returns the channel that receives the time when the timer fires.
+	C() <-chan time.Time
+
+	// Stop prevents the timer from firing. It returns false if the timer
+	// already fired or was stopped.
+	Stop() bool
+}
+
+// realClock is the Clock backed by the time package.
+type realClock struct{}
+
+func (realClock) Now() time.Time { return time.Now() }
+
+func (realClock) NewTimer(d time.Duration) Timer { return realTimer{time.NewTimer(d)} }
+
+// realTimer adapts a time.Timer to Timer.
+type realTimer struct{ t *time.Timer }
+
+func (t realTimer) C() <-chan time.Time { return t.t.C }
+func (t realTimer) Stop() bool          { return t.t.Stop() }
+
+// FaultFunc decides artificial latency and errors for a provider call. It
+// is called before every attempt, including retries, with the attempt
+// number starting at 0; the provider and model are available from the
+// context (ProviderFromContext, ModelFromContext).
+//
+// The call waits latency on the client clock, failing if the context ends
+// first, and then fails with err instead of calling the provider if err
+// is non-nil. Returned errors go through the normal retry logic, so
+// injecting a RateLimitError exercises retries and a long latency
+// exercises timeouts.
+type FaultFunc func(ctx context.Context, attempt int) (latency time.Duration, err error)
+
+// now returns the current time on the client clock.
+func (c *client) now() time.Time {
+	return c.clock().Now()
+}
+
+// clock returns the configured clock, defaulting to the real clock.
+func (c *client) clock() Clock {
+	if c.config.Clock != nil {
+		return c.config.Clock
+	}
+	return realClock{}
+}
+
+// clientClock returns the clock of cl, or the real clock if cl was not
+// created by NewClient.
+func clientClock(cl Client) Clock {
+	if c, ok := cl.(*client); ok {
+		return c.clock()
+	}
+	return realClock{}
+}
+
+// sleep waits d on the client clock or until ctx is done.
+func (c *client) sleep(ctx context.Context, d time.Duration) error {
+	if d <= 0 {
+		return nil
+	}
+	timer := c.clock().NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C():
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// withTimeout is context.WithTimeout on the client clock.
+func (c *client) withTimeout(ctx context.Context, d time.Duration) (context.Context, context.CancelFunc) {
+	if c.config.Clock == nil {
+		return context.WithTimeout(ctx, d)
+	}
+	return newClockTimeout(ctx, c.config.Clock, d)
+}
+
+// injectFault applies the configured fault injection to an attempt.
+func (c *client) injectFault(ctx context.Context, attempt int) error {
+	if c.config.FaultInjection == nil {
+		return nil
+	}
+	latency, err := c.config.FaultInjection(ctx, attempt)
+	if sleepErr := c.sleep(ctx, latency); sleepErr != nil {
+		return sleepErr
+	}
+	return err
+}
+
+// clockTimeout is a context that expires when a Clock reaches its
+// deadline. Like a context from context.WithTimeout, its Err is
+// context.DeadlineExceeded once the deadline passes.
+type clockTimeout struct {
+	context.Context
+	deadline time.Time
+}
+
+// newClockTimeout returns a context that expires after d on clock.
+func newClockTimeout(parent context.Context, clock Clock, d time.Duration) (context.Context, context.CancelFunc) {
+	deadline := clock.Now().Add(d)
+	ctx, cancel := context.WithCancelCause(parent)
+	if d <= 0 {
+		cancel(context.DeadlineExceeded)
+	} else {
+		timer := clock.NewTimer(d)
+		go func() {
+			defer timer.Stop()
+			select {
+			case <-timer.C():
+				cancel(context.DeadlineExceeded)
+			case <-ctx.Done():
+			}
+		}()
+	}
+	return &clockTimeout{Context: ctx, deadline: deadline}, func() { cancel(context.Canceled) }
+}
+
+// Deadline returns the deadline on the clock.
+func (c *clockTimeout) Deadline() (time.Time, bool) {
+	return c.deadline, true
+}
+
+// Err returns context.DeadlineExceeded if the deadline passed, and the
+// parent's or the cancel error otherwise.
+func (c *clockTimeout) Err() error {
+	err := c.Context.Err()
+	if err != nil && errors.Is(context.Cause(c.Context), context.DeadlineExceeded) {
+		return context.DeadlineExceeded
+	}
+	return err
+}
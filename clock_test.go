@@ -0,0 +1,155 @@
+package warp
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+// instantClock is a Clock whose timers fire immediately and which records
+// the requested durations.
+type instantClock struct {
+	mu        sync.Mutex
+	now       time.Time
+	durations []time.Duration
+}
+
+func (c *instantClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+func (c *instantClock) NewTimer(d time.Duration) Timer {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.durations = append(c.durations, d)
+	c.now = c.now.Add(d)
+	t := &manualTimer{ch: make(chan time.Time, 1)}
+	t.ch <- c.now
+	return t
+}
+
+// manualTimer is a Timer fired by writing to ch.
+type manualTimer struct {
+	ch chan time.Time
+}
+
+func (t *manualTimer) C() <-chan time.Time { return t.ch }
+func (t *manualTimer) Stop() bool          { return false }
+
+// manualClock is a Clock whose single timer is fired by the test.
+type manualClock struct {
+	now   time.Time
+	timer *manualTimer
+}
+
+func (c *manualClock) Now() time.Time { return c.now }
+
+func (c *manualClock) NewTimer(d time.Duration) Timer {
+	return c.timer
+}
+
+func TestWithClockAndFaultInjection(t *testing.T) {
+	start := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	clock := &instantClock{now: start}
+
+	var attempts []int
+	client, err := NewClient(
+		WithClock(clock),
+		WithRetries(3, time.Second, 2),
+		WithFaultInjection(func(ctx context.Context, attempt int) (time.Duration, error) {
+			attempts = append(attempts, attempt)
+			if attempt < 2 {
+				return 0, NewRateLimitError("injected", ProviderFromContext(ctx), 0, nil)
+			}
+			return 500 * time.Millisecond, nil
+		}),
+	)
+	if err != nil {
+		t.Fatalf("NewClient() error = %v", err)
+	}
+	defer client.Close()
+
+	calls := 0
+	mock := &mockProvider{
+		name: "test",
+		completionFunc: func(ctx context.Context, req *CompletionRequest) (*CompletionResponse, error) {
+			calls++
+			return &CompletionResponse{ID: "test-123"}, nil
+		},
+	}
+	if err := client.RegisterProvider(mock); err != nil {
+		t.Fatalf("RegisterProvider() error = %v", err)
+	}
+
+	began := time.Now()
+	_, err = client.Completion(context.Background(), &CompletionRequest{
+		Model:    "test/gpt-4",
+		Messages: []Message{{Role: "user", Content: "Hello"}},
+	})
+	if err != nil {
+		t.Fatalf("Completion() error = %v", err)
+	}
+	if elapsed := time.Since(began); elapsed > time.Second {
+		t.Errorf("Completion() took %v of real time, want backoff on the fake clock", elapsed)
+	}
+
+	if calls != 1 {
+		t.Errorf("provider calls = %d, want 1 after two injected failures", calls)
+	}
+	if len(attempts) != 3 || attempts[0] != 0 || attempts[2] != 2 {
+		t.Errorf("fault attempts = %v, want [0 1 2]", attempts)
+	}
+
+	// Request timeout, two backoffs, then the injected latency
+	if len(clock.durations) != 4 {
+		t.Fatalf("timers = %v, want timeout, two backoffs and latency", clock.durations)
+	}
+	if d := clock.durations[0]; d != 60*time.Second {
+		t.Errorf("timeout timer = %v, want 60s", d)
+	}
+	for i, base := range []time.Duration{time.Second, 2 * time.Second} {
+		if d := clock.durations[i+1]; d < base*9/10 || d > base*11/10 {
+			t.Errorf("backoff %d = %v, want %v ±10%%", i, d, base)
+		}
+	}
+	if d := clock.durations[3]; d != 500*time.Millisecond {
+		t.Errorf("latency timer = %v, want 500ms", d)
+	}
+}
+
+func TestClockTimeout(t *testing.T) {
+	start := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+
+	t.Run("deadline", func(t *testing.T) {
+		clock := &manualClock{now: start, timer: &manualTimer{ch: make(chan time.Time, 1)}}
+		ctx, cancel := newClockTimeout(context.Background(), clock, time.Minute)
+		defer cancel()
+
+		if deadline, ok := ctx.Deadline(); !ok || !deadline.Equal(start.Add(time.Minute)) {
+			t.Errorf("Deadline() = %v, %v, want %v", deadline, ok, start.Add(time.Minute))
+		}
+		if ctx.Err() != nil {
+			t.Fatalf("Err() = %v before the deadline", ctx.Err())
+		}
+
+		clock.timer.ch <- start.Add(time.Minute)
+		<-ctx.Done()
+		if !errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			t.Errorf("Err() = %v, want context.DeadlineExceeded", ctx.Err())
+		}
+	})
+
+	t.Run("cancel", func(t *testing.T) {
+		clock := &manualClock{now: start, timer: &manualTimer{ch: make(chan time.Time, 1)}}
+		ctx, cancel := newClockTimeout(context.Background(), clock, time.Minute)
+		cancel()
+		if !errors.Is(ctx.Err(), context.Canceled) {
+			t.Errorf("Err() = %v, want context.Canceled", ctx.Err())
+		}
+	})
+
+}
@@ -0,0 +1,245 @@
+package warp
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/blue-context/warp/internal/vecmath"
+)
+
+// CompareOptions configures Compare.
+type CompareOptions struct {
+	// EmbeddingModel is the embedding model used to measure how similar
+	// the outputs are in meaning, in "provider/model-name" form. If empty,
+	// EmbeddingSimilarity is not computed.
+	EmbeddingModel string
+}
+
+// Comparison is the result of running one request across several models.
+type Comparison struct {
+	// Results holds the outcome for each model, in the order given.
+	Results []ModelResult
+
+	// Diffs compares each pair of models that both succeeded: every
+	// result with every later one.
+	Diffs []ModelDiff
+}
+
+// ModelResult is the outcome of a request for one model.
+type ModelResult struct {
+	// Model is the model, in "provider/model-name" form.
+	Model string
+
+	// Response is the completion, or nil if the request failed.
+	Response *CompletionResponse
+
+	// Err is the error of a failed request.
+	Err error
+
+	// Text is the content of the first choice.
+	Text string
+
+	// Latency is how long the request took.
+	Latency time.Duration
+
+	// Cost is the cost of the completion in USD (0 if pricing is unknown).
+	Cost float64
+
+	// Length is the length of Text in characters.
+	Length int
+}
+
+// ModelDiff compares the outputs of two models.
+type ModelDiff struct {
+	// A and B are the models compared, in "provider/model-name" form.
+	A, B string
+
+	// TokenOverlap is the Jaccard similarity of the lowercased words of
+	// both outputs: 1 for the same vocabulary, 0 for none in common.
+	TokenOverlap float64
+
+	// EmbeddingSimilarity is the cosine similarity of the outputs'
+	// embeddings, or nil if CompareOptions.EmbeddingModel is not set or
+	// the embedding request failed.
+	EmbeddingSimilarity *float64
+
+	// LengthRatio is B's output length divided by A's (0 if A's output is
+	// empty).
+	LengthRatio float64
+
+	// LatencyDelta is B's latency minus A's.
+	LatencyDelta time.Duration
+
+	// CostDelta is B's cost minus A's, in USD.
+	CostDelta float64
+}
+
+// Compare sends the same request to each model concurrently and compares
+// the outputs, to help decide whether one model can replace another.
+//
+// req.Model is ignored. A model that fails does not fail the comparison:
+// its ModelResult holds the error and it is left out of the diffs. The
+// error return is only for invalid arguments.
+//
+// Example:
+//
+//	cmp, err := warp.Compare(ctx, client, req, []string{
+//	    "openai/gpt-4o",
+//	    "anthropic/claude-3-5-haiku-20241022",
+//	}, &warp.CompareOptions{EmbeddingModel: "openai/text-embedding-3-small"})
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+//	for _, d := range cmp.Diffs {
+//	    log.Printf("%s vs %s: overlap %.2f, latency %+v, cost %+.4f",
+//	        d.A, d.B, d.TokenOverlap, d.LatencyDelta, d.CostDelta)
+//	}
+func Compare(ctx context.Context, client Client, req *CompletionRequest, models []string, opts *CompareOptions) (*Comparison, error) {
+	if req == nil {
+		return nil, fmt.Errorf("request cannot be nil")
+	}
+	if len(models) == 0 {
+		return nil, fmt.Errorf("at least one model is required")
+	}
+	if opts == nil {
+		opts = &CompareOptions{}
+	}
+
+	results := make([]ModelResult, len(models))
+	var wg sync.WaitGroup
+	for i, model := range models {
+		wg.Add(1)
+		go func(result *ModelResult, model string) {
+			defer wg.Done()
+			*result = compareModel(ctx, client, req, model)
+		}(&results[i], model)
+	}
+	wg.Wait()
+
+	var succeeded []int
+	for i := range results {
+		if results[i].Err == nil {
+			succeeded = append(succeeded, i)
+		}
+	}
+	similarity := compareEmbeddings(ctx, client, opts.EmbeddingModel, results, succeeded)
+
+	cmp := &Comparison{Results: results}
+	for x, i := range succeeded {
+		for y := x + 1; y < len(succeeded); y++ {
+			j := succeeded[y]
+			a, b := &results[i], &results[j]
+			diff := ModelDiff{
+				A:            a.Model,
+				B:            b.Model,
+				TokenOverlap: tokenOverlap(a.Text, b.Text),
+				LatencyDelta: b.Latency - a.Latency,
+				CostDelta:    b.Cost - a.Cost,
+			}
+			if a.Length > 0 {
+				diff.LengthRatio = float64(b.Length) / float64(a.Length)
+			}
+			if similarity != nil {
+				s := similarity[x][y]
+				diff.EmbeddingSimilarity = &s
+			}
+			cmp.Diffs = append(cmp.Diffs, diff)
+		}
+	}
+	return cmp, nil
+}
+
+// compareModel sends req to model.
+func compareModel(ctx context.Context, client Client, req *CompletionRequest, model string) ModelResult {
+	result := ModelResult{Model: model}
+	r := *req
+	r.Model = model
+
+	clock := clientClock(client)
+	start := clock.Now()
+	resp, err := client.Completion(ctx, &r)
+	result.Latency = clock.Now().Sub(start)
+	if err != nil {
+		result.Err = err
+		return result
+	}
+
+	result.Response = resp
+	if len(resp.Choices) > 0 {
+		result.Text = messageText(resp.Choices[0].Message.Content)
+	}
+	result.Length = utf8.RuneCountInString(result.Text)
+	if cost, err := client.CompletionCost(resp); err == nil {
+		result.Cost = cost
+	}
+	return result
+}
+
+// compareEmbeddings returns the cosine similarity of the outputs of the
+// succeeded results, indexed by position in succeeded, or nil if model is
+// empty or the embeddings are unavailable.
+func compareEmbeddings(ctx context.Context, client Client, model string, results []ModelResult, succeeded []int) [][]float64 {
+	if model == "" || len(succeeded) < 2 {
+		return nil
+	}
+	texts := make([]string, len(succeeded))
+	for x, i := range succeeded {
+		texts[x] = results[i].Text
+		if texts[x] == "" {
+			texts[x] = " "
+		}
+	}
+
+	resp, err := client.Embedding(ctx, &EmbeddingRequest{Model: model, Input: texts})
+	if err != nil || len(resp.Data) != len(texts) {
+		return nil
+	}
+	vectors := make([][]float64, len(texts))
+	for _, e := range resp.Data {
+		if e.Index < 0 || e.Index >= len(vectors) {
+			return nil
+		}
+		vectors[e.Index] = e.Embedding
+	}
+
+	similarity := make([][]float64, len(vectors))
+	for x := range vectors {
+		similarity[x] = make([]float64, len(vectors))
+		for y := range vectors {
+			similarity[x][y] = vecmath.Cosine(vectors[x], vectors[y])
+		}
+	}
+	return similarity
+}
+
+// tokenOverlap returns the Jaccard similarity of the lowercased words of a
+// and b. Two empty texts are identical.
+func tokenOverlap(a, b string) float64 {
+	wordsA, wordsB := wordSet(a), wordSet(b)
+	if len(wordsA) == 0 && len(wordsB) == 0 {
+		return 1
+	}
+	shared := 0
+	for word := range wordsA {
+		if wordsB[word] {
+			shared++
+		}
+	}
+	return float64(shared) / float64(len(wordsA)+len(wordsB)-shared)
+}
+
+// wordSet returns the distinct lowercased words of text.
+func wordSet(text string) map[string]bool {
+	words := make(map[string]bool)
+	for _, word := range strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsNumber(r)
+	}) {
+		words[word] = true
+	}
+	return words
+}
@@ -0,0 +1,110 @@
+package warp
+
+import (
+	"context"
+	"errors"
+	"math"
+	"testing"
+	"time"
+)
+
+func TestCompare(t *testing.T) {
+	replies := map[string]string{
+		"model-a": "The capital of France is Paris.",
+		"model-b": "Paris is the capital of France, of course.",
+	}
+	client, err := NewClient()
+	if err != nil {
+		t.Fatal(err)
+	}
+	client.RegisterProvider(&mockProvider{
+		name: "mock",
+		completionFunc: func(ctx context.Context, req *CompletionRequest) (*CompletionResponse, error) {
+			reply, ok := replies[req.Model]
+			if !ok {
+				return nil, errors.New("model not found")
+			}
+			return &CompletionResponse{
+				Model:   req.Model,
+				Choices: []Choice{{Message: Message{Role: "assistant", Content: reply}}},
+			}, nil
+		},
+		embeddingFunc: func(ctx context.Context, req *EmbeddingRequest) (*EmbeddingResponse, error) {
+			texts := req.Input.([]string)
+			resp := &EmbeddingResponse{}
+			for i := range texts {
+				resp.Data = append(resp.Data, Embedding{Index: i, Embedding: []float64{1, float64(i)}})
+			}
+			return resp, nil
+		},
+	})
+
+	req := &CompletionRequest{Messages: []Message{{Role: "user", Content: "What is the capital of France?"}}}
+	cmp, err := Compare(context.Background(), client, req, []string{"mock/model-a", "mock/missing", "mock/model-b"},
+		&CompareOptions{EmbeddingModel: "mock/embed"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(cmp.Results) != 3 || cmp.Results[1].Err == nil {
+		t.Fatalf("Results = %+v, want 3 with the missing model failed", cmp.Results)
+	}
+	if cmp.Results[0].Text != replies["model-a"] || cmp.Results[0].Length != 31 {
+		t.Errorf("Results[0] = %+v", cmp.Results[0])
+	}
+	if len(cmp.Diffs) != 1 {
+		t.Fatalf("Diffs = %+v, want 1 between the succeeded models", cmp.Diffs)
+	}
+	diff := cmp.Diffs[0]
+	if diff.A != "mock/model-a" || diff.B != "mock/model-b" {
+		t.Errorf("Diff models = %s, %s", diff.A, diff.B)
+	}
+	// 6 shared words of 7 distinct
+	if math.Abs(diff.TokenOverlap-6.0/7) > 1e-9 {
+		t.Errorf("TokenOverlap = %v, want %v", diff.TokenOverlap, 6.0/7)
+	}
+	if math.Abs(diff.LengthRatio-42.0/31) > 1e-9 {
+		t.Errorf("LengthRatio = %v, want %v", diff.LengthRatio, 42.0/31)
+	}
+	if diff.EmbeddingSimilarity == nil || math.Abs(*diff.EmbeddingSimilarity-1/math.Sqrt2) > 1e-9 {
+		t.Errorf("EmbeddingSimilarity = %v, want %v", diff.EmbeddingSimilarity, 1/math.Sqrt2)
+	}
+}
+
+func TestCompareErrors(t *testing.T) {
+	client, err := NewClient()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Compare(context.Background(), client, nil, []string{"mock/a"}, nil); err == nil {
+		t.Error("Compare() with nil request error = nil")
+	}
+	if _, err := Compare(context.Background(), client, &CompletionRequest{}, nil, nil); err == nil {
+		t.Error("Compare() without models error = nil")
+	}
+}
+
+// TestCompareLatencyClock tests that latency is measured on the client clock
+func TestCompareLatencyClock(t *testing.T) {
+	clock := &manualClock{now: time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC), timer: &manualTimer{ch: make(chan time.Time)}}
+	client, err := NewClient(WithClock(clock))
+	if err != nil {
+		t.Fatal(err)
+	}
+	client.RegisterProvider(&mockProvider{
+		name: "mock",
+		completionFunc: func(ctx context.Context, req *CompletionRequest) (*CompletionResponse, error) {
+			clock.now = clock.now.Add(3 * time.Second)
+			return &CompletionResponse{Choices: []Choice{{Message: Message{Role: "assistant", Content: "Paris."}}}}, nil
+		},
+	})
+
+	req := &CompletionRequest{Messages: []Message{{Role: "user", Content: "What is the capital of France?"}}}
+	cmp, err := Compare(context.Background(), client, req, []string{"mock/model-a"}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := cmp.Results[0].Latency; got != 3*time.Second {
+		t.Errorf("Latency = %v, want 3s", got)
+	}
+}
@@ -7,7 +7,6 @@ import (
 	"io"
 	"time"
 
-	"github.com/blue-context/warp/cache"
 	"github.com/blue-context/warp/callback"
 )
 
@@ -29,70 +28,43 @@ func (c *client) Completion(ctx context.Context, req *CompletionRequest) (*Compl
 		return nil, fmt.Errorf("request cannot be nil")
 	}
 
-	// Add request ID to context
-	if RequestIDFromContext(ctx) == "" {
-		ctx = WithGeneratedRequestID(ctx)
-	}
-
-	// Record start time
-	startTime := time.Now()
-	ctx = WithStartTime(ctx, startTime)
-
-	// Parse model string
-	providerName, modelName, err := parseModel(req.Model)
+	// Run the steps shared with CompletionStream and Responses
+	d, err := c.beginRequest(ctx, req)
 	if err != nil {
 		return nil, err
 	}
-
-	// Add provider and model to context
-	ctx = WithProvider(ctx, providerName)
-	ctx = WithModel(ctx, modelName)
-
-	// Execute before-request callbacks
-	if c.callbacks != nil {
-		beforeEvent := &callback.BeforeRequestEvent{
-			RequestID: RequestIDFromContext(ctx),
-			Model:     modelName,
-			Provider:  providerName,
-			Request:   req,
-			StartTime: startTime,
-		}
-		if err := c.callbacks.ExecuteBeforeRequest(ctx, beforeEvent); err != nil {
-			return nil, fmt.Errorf("before-request callback failed: %w", err)
-		}
-	}
-
-	// Get provider
-	p, err := c.getProvider(providerName)
-	if err != nil {
-		return nil, fmt.Errorf("provider %q not found (did you register it?)", providerName)
-	}
+	ctx, req, p := d.ctx, d.req, d.provider
+	providerName, modelName, startTime := d.providerName, d.modelName, d.startTime
 
 	// Check cache before API call
+	cacheKey := ""
 	if c.cache != nil {
-		// Marshal messages for cache key generation
-		messagesJSON, err := json.Marshal(req.Messages)
-		if err == nil {
-			cacheKey := cache.Key(modelName, messagesJSON, req.Temperature, req.MaxTokens, req.TopP)
-
+		cacheKey = c.cacheKey(req)
+		if cacheKey != "" {
 			// Try to get from cache
 			if cached, err := c.cache.Get(ctx, cacheKey); err == nil {
 				var resp CompletionResponse
 				if json.Unmarshal(cached, &resp) == nil {
+					resp.RequestID = RequestIDFromContext(ctx)
 					return &resp, nil
 				}
 			}
 		}
 	}
 
+	// Refuse the request once the budget is spent
+	if err := c.checkBudget(providerName, modelName); err != nil {
+		return nil, err
+	}
+
 	// Apply timeout if specified
 	if req.Timeout > 0 {
 		var cancel context.CancelFunc
-		ctx, cancel = context.WithTimeout(ctx, req.Timeout)
+		ctx, cancel = c.withTimeout(ctx, req.Timeout)
 		defer cancel()
 	} else if c.config.DefaultTimeout > 0 {
 		var cancel context.CancelFunc
-		ctx, cancel = context.WithTimeout(ctx, c.config.DefaultTimeout)
+		ctx, cancel = c.withTimeout(ctx, c.config.DefaultTimeout)
 		defer cancel()
 	}
 
@@ -101,18 +73,34 @@ func (c *client) Completion(ctx context.Context, req *CompletionRequest) (*Compl
 	providerReq.Model = modelName
 
 	// Call provider with retries
-	var resp *CompletionResponse
-	err = c.withRetry(ctx, func() error {
-		var callErr error
-		resp, callErr = p.Completion(ctx, &providerReq)
-		return callErr
-	})
+	resp, err := c.sendCompletion(ctx, p, &providerReq)
+
+	// Retry on a larger model or a shorter request if the context overflowed
+	if err != nil && c.config.ContextOverflowPolicy != nil {
+		req, err = c.recoverContextOverflow(ctx, d.unmodified, req, err, func(p Provider, r *CompletionRequest) error {
+			var callErr error
+			resp, callErr = c.sendCompletion(ctx, p, r)
+			return callErr
+		})
+		providerName, modelName, _ = parseModel(req.Model)
+	}
+
+	// Charge the cost to the budget
+	cost := 0.0
+	if err == nil && c.config.TrackCost {
+		if calculatedCost, costErr := c.costCalc.CalculateCompletion(resp); costErr == nil {
+			cost = calculatedCost
+		}
+		c.chargeBudget(providerName, modelName, cost)
+	}
 
 	// Record end time
-	endTime := time.Now()
+	endTime := c.now()
 	duration := endTime.Sub(startTime)
 
 	if err != nil {
+		c.tracePayloads(ctx, providerName, modelName, nil, err)
+
 		// Execute failure callbacks
 		if c.callbacks != nil {
 			failureEvent := &callback.FailureEvent{
@@ -129,30 +117,37 @@ func (c *client) Completion(ctx context.Context, req *CompletionRequest) (*Compl
 		}
 		return nil, err
 	}
+	resp.RequestID = RequestIDFromContext(ctx)
+	resp.ProviderRequestID = ProviderRequestIDFromContext(ctx)
+	NormalizeToolCallIDs(resp)
+	if resp.Truncated() {
+		c.warn(ctx, WarningOutputTruncated, fmt.Sprintf("%s output stopped at the token limit", modelName))
+	}
+	if req.PromptLogprobs && len(resp.PromptLogprobs) == 0 {
+		c.warn(ctx, WarningPromptLogprobsUnavailable, fmt.Sprintf("%s returned no prompt logprobs", providerName))
+	}
+	if d.upgradedFrom != "" {
+		if resp.HiddenParams == nil {
+			resp.HiddenParams = make(map[string]any)
+		}
+		resp.HiddenParams["_upgraded_from"] = d.upgradedFrom
+	}
 
 	// Store successful response in cache
-	if c.cache != nil && resp != nil {
-		// Marshal messages for cache key generation
-		if messagesJSON, err := json.Marshal(req.Messages); err == nil {
-			cacheKey := cache.Key(modelName, messagesJSON, req.Temperature, req.MaxTokens, req.TopP)
-
-			// Store in cache with 1 hour TTL
-			if data, err := json.Marshal(resp); err == nil {
-				// Ignore cache errors - don't fail the request if caching fails
-				_ = c.cache.Set(ctx, cacheKey, data, 1*time.Hour)
-			}
+	if c.cache != nil && cacheKey != "" && resp != nil {
+		// Store in cache with 1 hour TTL
+		if data, err := json.Marshal(resp); err == nil {
+			// Ignore cache errors - don't fail the request if caching fails
+			_ = c.cache.Set(ctx, cacheKey, data, 1*time.Hour)
 		}
 	}
 
+	// Attach the raw provider payloads (after caching, so they are not cached)
+	c.tracePayloads(ctx, providerName, modelName, resp, nil)
+
 	// Execute success callbacks
 	if c.callbacks != nil {
-		// Calculate cost if available
-		cost := 0.0
-		if c.costCalc != nil {
-			if calculatedCost, err := c.costCalc.CalculateCompletion(resp); err == nil {
-				cost = calculatedCost
-			}
-		}
+		currency, localCost := c.localCost(ctx, cost)
 
 		// Get token count
 		tokens := 0
@@ -170,6 +165,8 @@ func (c *client) Completion(ctx context.Context, req *CompletionRequest) (*Compl
 			EndTime:   endTime,
 			Duration:  duration,
 			Cost:      cost,
+			Currency:  currency,
+			LocalCost: localCost,
 			Tokens:    tokens,
 		}
 		c.callbacks.ExecuteSuccess(ctx, successEvent)
@@ -178,45 +175,47 @@ func (c *client) Completion(ctx context.Context, req *CompletionRequest) (*Compl
 	return resp, nil
 }
 
-// CompletionStream creates a streaming chat completion.
-//
-// The returned Stream must be closed by the caller to release resources.
-//
-// Example:
-//
-//	stream, err := client.CompletionStream(ctx, &warp.CompletionRequest{
-//	    Model: "openai/gpt-4",
-//	    Messages: []warp.Message{
-//	        {Role: "user", Content: "Write a poem"},
-//	    },
-//	})
-//	if err != nil {
-//	    log.Fatal(err)
-//	}
-//	defer stream.Close()
-//
-//	for {
-//	    chunk, err := stream.Recv()
-//	    if err == io.EOF {
-//	        break
-//	    }
-//	    if err != nil {
-//	        log.Fatal(err)
-//	    }
-//	    fmt.Print(chunk.Choices[0].Delta.Content)
-//	}
-func (c *client) CompletionStream(ctx context.Context, req *CompletionRequest) (Stream, error) {
-	if req == nil {
-		return nil, fmt.Errorf("request cannot be nil")
-	}
+// requestDispatch is a completion request that has passed the steps run
+// before any request is sent, with the provider it goes to.
+type requestDispatch struct {
+	ctx          context.Context
+	req          *CompletionRequest
+	unmodified   *CompletionRequest // Before middleware, for context overflow recovery
+	provider     Provider
+	providerName string
+	modelName    string
+	startTime    time.Time
+	upgradedFrom string // Model replaced by a larger-context sibling, if any
+}
 
+// beginRequest runs the steps shared by Completion, CompletionStream, and
+// Responses before a request is sent: configured defaults, language
+// tagging, context upgrades, request middleware, deprecation handling,
+// before-request callbacks, and preparation for the provider.
+func (c *client) beginRequest(ctx context.Context, req *CompletionRequest) (*requestDispatch, error) {
 	// Add request ID to context
 	if RequestIDFromContext(ctx) == "" {
 		ctx = WithGeneratedRequestID(ctx)
 	}
 
+	// Fill in configured defaults for the model
+	req = c.applyRequestDefaults(req)
+
+	// Tag the request with the language of the prompt
+	ctx = c.tagLanguage(ctx, req)
+
+	// Switch to a larger-context sibling if the prompt does not fit
+	req, upgradedFrom := c.upgradeForContext(ctx, req)
+
+	// Transform request before dispatch
+	unmodified := req
+	req, err := c.applyRequestMiddleware(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
 	// Record start time
-	startTime := time.Now()
+	startTime := c.now()
 	ctx = WithStartTime(ctx, startTime)
 
 	// Parse model string
@@ -228,6 +227,14 @@ func (c *client) CompletionStream(ctx context.Context, req *CompletionRequest) (
 	// Add provider and model to context
 	ctx = WithProvider(ctx, providerName)
 	ctx = WithModel(ctx, modelName)
+	ctx = c.withRequestHeaders(ctx)
+	if c.config.TracePayloads {
+		ctx = withPayloadTrace(ctx)
+	}
+
+	// Warn about a deprecated model, or switch to its replacement
+	req, modelName = c.checkDeprecation(ctx, req, providerName, modelName)
+	ctx = WithModel(ctx, modelName)
 
 	// Execute before-request callbacks
 	if c.callbacks != nil {
@@ -249,14 +256,179 @@ func (c *client) CompletionStream(ctx context.Context, req *CompletionRequest) (
 		return nil, fmt.Errorf("provider %q not found (did you register it?)", providerName)
 	}
 
+	// Fit the request to the provider and check it before sending
+	req, err = c.prepareForProvider(ctx, p, req, providerName, modelName)
+	if err != nil {
+		return nil, err
+	}
+
+	return &requestDispatch{
+		ctx:          ctx,
+		req:          req,
+		unmodified:   unmodified,
+		provider:     p,
+		providerName: providerName,
+		modelName:    modelName,
+		startTime:    startTime,
+		upgradedFrom: upgradedFrom,
+	}, nil
+}
+
+// prepareForProvider fits req to provider p and checks it before sending.
+//
+// It runs every step that depends on the provider, so requests substituted
+// after the provider is known (such as context overflow fallbacks) are
+// rewritten and refused exactly like the original. req.Model still has its
+// provider prefix; modelName is the model without it.
+func (c *client) prepareForProvider(ctx context.Context, p Provider, req *CompletionRequest, providerName, modelName string) (*CompletionRequest, error) {
+	var err error
+
+	// Refuse cached content the provider cannot use
+	if err := checkCachedContent(p, req, providerName); err != nil {
+		return nil, err
+	}
+
+	// Set privacy options, or refuse providers that cannot honor them
+	req, err = c.applyZeroDataRetention(p, req, providerName, modelName)
+	if err != nil {
+		return nil, err
+	}
+
+	// Send developer messages as system messages where unsupported
+	req = downgradeDeveloperRole(p, req, modelName)
+
+	// Fit stop sequences to the provider's limit
+	req, err = c.adaptStopSequences(ctx, p, req, providerName, modelName)
+	if err != nil {
+		return nil, err
+	}
+
+	// Give tool calls unique IDs and tool results a call to answer
+	req, err = c.repairToolCallIDs(ctx, req, providerName)
+	if err != nil {
+		return nil, err
+	}
+
+	// Restructure multiple or misplaced system messages
+	req, err = c.applySystemMessageStrategy(ctx, req, providerName)
+	if err != nil {
+		return nil, err
+	}
+
+	// Reject oversized requests before any network call
+	if err := c.checkRequestLimits(req, providerName, modelName); err != nil {
+		return nil, err
+	}
+
+	// Moderate the prompt for providers without a safety parameter
+	if err := c.checkSafety(ctx, req, providerName); err != nil {
+		return nil, err
+	}
+
+	return req, nil
+}
+
+// sendCompletion calls the provider with retries, emulating features the
+// model lacks natively. req.Model has no provider prefix.
+func (c *client) sendCompletion(ctx context.Context, p Provider, req *CompletionRequest) (*CompletionResponse, error) {
+	original := req
+	tools, format := req.Tools, req.ResponseFormat
+	emulateTools := c.needsToolEmulation(p, req)
+	if emulateTools {
+		emulated, err := emulateToolCalling(req)
+		if err != nil {
+			return nil, err
+		}
+		req = emulated
+	}
+	emulateJSON := c.needsJSONEmulation(p, req)
+	if emulateJSON {
+		emulated, err := emulateJSONMode(req)
+		if err != nil {
+			return nil, err
+		}
+		req = emulated
+	}
+
+	var resp *CompletionResponse
+	err := c.withRetry(ctx, func() error {
+		var callErr error
+		resp, callErr = c.complete(ctx, p, req)
+		return callErr
+	})
+	if spilled := c.spillServiceTier(ctx, req, err); spilled != nil {
+		resp, err = c.complete(ctx, p, spilled)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if emulateTools && resp != nil {
+		restoreToolCalls(resp, tools)
+	}
+	if emulateJSON && resp != nil {
+		if err := c.restoreJSON(ctx, p, req, resp, format); err != nil {
+			return nil, err
+		}
+	}
+
+	// Continue output cut off by the token limit
+	return c.continueTruncated(ctx, p, original, resp)
+}
+
+// CompletionStream creates a streaming chat completion.
+//
+// The returned Stream must be closed by the caller to release resources.
+//
+// Example:
+//
+//	stream, err := client.CompletionStream(ctx, &warp.CompletionRequest{
+//	    Model: "openai/gpt-4",
+//	    Messages: []warp.Message{
+//	        {Role: "user", Content: "Write a poem"},
+//	    },
+//	})
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+//	defer stream.Close()
+//
+//	for {
+//	    chunk, err := stream.Recv()
+//	    if err == io.EOF {
+//	        break
+//	    }
+//	    if err != nil {
+//	        log.Fatal(err)
+//	    }
+//	    fmt.Print(chunk.Choices[0].Delta.Content)
+//	}
+func (c *client) CompletionStream(ctx context.Context, req *CompletionRequest) (Stream, error) {
+	if req == nil {
+		return nil, fmt.Errorf("request cannot be nil")
+	}
+
+	// Run the steps shared with CompletionStream and Responses
+	d, err := c.beginRequest(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	ctx, req, p := d.ctx, d.req, d.provider
+	providerName, modelName, startTime := d.providerName, d.modelName, d.startTime
+
+	// Refuse the request once the budget is spent
+	if err := c.checkBudget(providerName, modelName); err != nil {
+		return nil, err
+	}
+
 	// Apply timeout if specified
 	if req.Timeout > 0 {
 		var cancel context.CancelFunc
-		ctx, cancel = context.WithTimeout(ctx, req.Timeout)
+		ctx, cancel = c.withTimeout(ctx, req.Timeout)
 		defer cancel()
 	} else if c.config.DefaultTimeout > 0 {
 		var cancel context.CancelFunc
-		ctx, cancel = context.WithTimeout(ctx, c.config.DefaultTimeout)
+		ctx, cancel = c.withTimeout(ctx, c.config.DefaultTimeout)
 		defer cancel()
 	}
 
@@ -265,11 +437,30 @@ func (c *client) CompletionStream(ctx context.Context, req *CompletionRequest) (
 	providerReq.Model = modelName
 
 	// Call provider (no retry for streaming)
-	stream, err := p.CompletionStream(ctx, &providerReq)
+	var stream Stream
+	err = c.injectFault(ctx, 0)
+	if err == nil {
+		stream, err = c.openStream(ctx, p, &providerReq)
+	}
+	if spilled := c.spillServiceTier(ctx, &providerReq, err); spilled != nil {
+		stream, err = c.openStream(ctx, p, spilled)
+	}
+
+	// Retry on a larger model or a shorter request if the context overflowed
+	if err != nil && c.config.ContextOverflowPolicy != nil {
+		req, err = c.recoverContextOverflow(ctx, d.unmodified, req, err, func(p Provider, r *CompletionRequest) error {
+			var callErr error
+			stream, callErr = c.openStream(ctx, p, r)
+			return callErr
+		})
+		providerName, modelName, _ = parseModel(req.Model)
+	}
 	if err != nil {
+		c.tracePayloads(ctx, providerName, modelName, nil, err)
+
 		// Execute failure callbacks
 		if c.callbacks != nil {
-			endTime := time.Now()
+			endTime := c.now()
 			failureEvent := &callback.FailureEvent{
 				RequestID: RequestIDFromContext(ctx),
 				Model:     modelName,
@@ -285,14 +476,63 @@ func (c *client) CompletionStream(ctx context.Context, req *CompletionRequest) (
 		return nil, err
 	}
 
+	stream = c.traceStream(ctx, stream, providerName, modelName)
+	if c.budget != nil {
+		stream = &budgetStream{Stream: stream, client: c, provider: providerName, model: modelName}
+	}
+
 	// Wrap stream with callback execution if callbacks are registered
 	if c.callbacks != nil {
-		return newCallbackStream(ctx, stream, c.callbacks, req, modelName, providerName, startTime), nil
+		return newCallbackStream(ctx, stream, c.callbacks, req, modelName, providerName, startTime, c.clock()), nil
 	}
 
 	return stream, nil
 }
 
+// budgetStream charges the cost of a stream to the client's budget when the
+// stream ends, using the usage reported in its chunks. Streams that report
+// no usage are not charged.
+type budgetStream struct {
+	Stream
+	client   *client
+	provider string
+	model    string
+	usage    *Usage
+	charged  bool
+}
+
+// Recv receives the next chunk, charging the budget at the end of the stream.
+func (s *budgetStream) Recv() (*CompletionChunk, error) {
+	chunk, err := s.Stream.Recv()
+	if err != nil {
+		s.charge()
+		return nil, err
+	}
+	if chunk != nil && chunk.Usage != nil {
+		s.usage = chunk.Usage
+	}
+	return chunk, nil
+}
+
+// Close closes the underlying stream, charging the usage reported so far.
+func (s *budgetStream) Close() error {
+	s.charge()
+	return s.Stream.Close()
+}
+
+// charge records the stream's cost once.
+func (s *budgetStream) charge() {
+	if s.charged || s.usage == nil {
+		return
+	}
+	s.charged = true
+
+	resp := &CompletionResponse{Model: s.provider + "/" + s.model, Usage: s.usage}
+	if cost, err := s.client.costCalc.CalculateCompletion(resp); err == nil {
+		s.client.chargeBudget(s.provider, s.model, cost)
+	}
+}
+
 // callbackStream wraps a Stream to execute callbacks for each chunk and on completion.
 type callbackStream struct {
 	underlying Stream
@@ -302,6 +542,7 @@ type callbackStream struct {
 	model      string
 	provider   string
 	startTime  time.Time
+	clock      Clock
 	chunkIndex int
 	finalErr   error
 	closed     bool
@@ -315,6 +556,7 @@ func newCallbackStream(
 	req *CompletionRequest,
 	model, provider string,
 	startTime time.Time,
+	clock Clock,
 ) Stream {
 	return &callbackStream{
 		underlying: underlying,
@@ -324,6 +566,7 @@ func newCallbackStream(
 		model:      model,
 		provider:   provider,
 		startTime:  startTime,
+		clock:      clock,
 		chunkIndex: 0,
 	}
 }
@@ -353,7 +596,7 @@ func (s *callbackStream) Recv() (*CompletionChunk, error) {
 			Provider:  s.provider,
 			Chunk:     chunk,
 			Index:     s.chunkIndex,
-			Timestamp: time.Now(),
+			Timestamp: s.clock.Now(),
 		}
 		s.callbacks.ExecuteStream(s.ctx, streamEvent)
 		s.chunkIndex++
@@ -379,7 +622,7 @@ func (s *callbackStream) Close() error {
 
 // executeSuccessCallback executes success callbacks after stream completion.
 func (s *callbackStream) executeSuccessCallback() {
-	endTime := time.Now()
+	endTime := s.clock.Now()
 	successEvent := &callback.SuccessEvent{
 		RequestID: RequestIDFromContext(s.ctx),
 		Model:     s.model,
@@ -397,7 +640,7 @@ func (s *callbackStream) executeSuccessCallback() {
 
 // executeFailureCallback executes failure callbacks after stream error.
 func (s *callbackStream) executeFailureCallback(err error) {
-	endTime := time.Now()
+	endTime := s.clock.Now()
 	failureEvent := &callback.FailureEvent{
 		RequestID: RequestIDFromContext(s.ctx),
 		Model:     s.model,
@@ -0,0 +1,382 @@
+// Package compress provides heuristic prompt compression for completion requests.
+//
+// Long retrieval-augmented contexts are often repetitive and padded with
+// low-information text. The Compressor shrinks such prompts before dispatch,
+// in the spirit of LLMLingua but without a scoring model:
+//
+//  1. Repeated context blocks are removed, as by the dedupe package in
+//     dedupe.ModeTrim: paragraphs of at least the minimum length that repeat
+//     an earlier paragraph.
+//  2. If the request exceeds the target token budget, the least informative
+//     sentences are dropped, favouring sentences that share terms with the
+//     final message (the question).
+//  3. If it still exceeds the budget, filler words are removed.
+//
+// The final message is never compressed, and JSON content such as
+// structured tool results is never split into sentences.
+//
+// Basic usage:
+//
+//	compressor := compress.New(compress.WithTargetTokens(4000))
+//	client, err := warp.NewClient(
+//	    warp.WithRequestMiddleware(compressor.Compress),
+//	)
+package compress
+
+import (
+	"context"
+	"encoding/json"
+	"slices"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/blue-context/warp"
+	"github.com/blue-context/warp/dedupe"
+	"github.com/blue-context/warp/token"
+)
+
+// Compressor shrinks completion requests toward a target token budget.
+//
+// Thread Safety: Compressor is safe for concurrent use.
+type Compressor struct {
+	targetTokens int
+	counter      token.Counter
+	dedupe       bool
+	minLength    int
+	roles        []string
+	deduper      *dedupe.Deduplicator
+}
+
+// Option configures a Compressor.
+type Option func(*Compressor)
+
+// WithTargetTokens sets the token budget for compressed requests.
+//
+// Requests at or under the budget are only deduplicated. The default of 0
+// disables budget-driven compression.
+func WithTargetTokens(n int) Option {
+	return func(c *Compressor) {
+		c.targetTokens = n
+	}
+}
+
+// WithCounter sets the token counter used to measure requests.
+//
+// The default is token.NewCounter().
+func WithCounter(counter token.Counter) Option {
+	return func(c *Compressor) {
+		c.counter = counter
+	}
+}
+
+// WithDeduplication enables or disables repeated block removal.
+//
+// Deduplication is enabled by default.
+func WithDeduplication(enabled bool) Option {
+	return func(c *Compressor) {
+		c.dedupe = enabled
+	}
+}
+
+// WithMinLength sets the minimum length in bytes of a repeated block to
+// remove, so short repeated lines such as code, CSV rows, and log lines
+// are kept.
+//
+// The default is dedupe.DefaultMinLength.
+func WithMinLength(n int) Option {
+	return func(c *Compressor) {
+		c.minLength = n
+	}
+}
+
+// WithRoles sets which message roles may be compressed.
+//
+// The default is "user", leaving system instructions and tool results
+// intact.
+//
+// Example:
+//
+//	compress.New(compress.WithRoles("user", "tool"))
+func WithRoles(roles ...string) Option {
+	return func(c *Compressor) {
+		c.roles = roles
+	}
+}
+
+// New creates a Compressor with the given options.
+//
+// Example:
+//
+//	compressor := compress.New(
+//	    compress.WithTargetTokens(8000),
+//	    compress.WithCounter(token.ProviderCounter("openai")),
+//	)
+func New(opts ...Option) *Compressor {
+	c := &Compressor{
+		counter:   token.NewCounter(),
+		dedupe:    true,
+		minLength: dedupe.DefaultMinLength,
+		roles:     []string{"user"},
+	}
+
+	for _, opt := range opts {
+		opt(c)
+	}
+
+	c.deduper = dedupe.New(
+		dedupe.WithMode(dedupe.ModeTrim),
+		dedupe.WithMinLength(c.minLength),
+		dedupe.WithRoles(c.roles...),
+	)
+
+	return c
+}
+
+// sentence is a sentence of a compressible message.
+type sentence struct {
+	text    string // Sentence text including trailing whitespace
+	msg     int    // Index of the message it belongs to
+	score   float64
+	tokens  int
+	removed bool
+}
+
+// Compress returns a compressed copy of req.
+//
+// Repeated blocks are removed from string content and the text parts of
+// multimodal content. Budget-driven compression only rewrites string content
+// that is not JSON; tool calls and the final message are left unchanged.
+// req itself is not modified.
+// The signature matches warp.RequestMiddleware.
+func (c *Compressor) Compress(ctx context.Context, req *warp.CompletionRequest) (*warp.CompletionRequest, error) {
+	if req == nil || len(req.Messages) < 2 {
+		return req, nil
+	}
+
+	out := *req
+	out.Messages = slices.Clone(req.Messages)
+	last := len(out.Messages) - 1
+
+	// Remove repeated blocks from all but the final message
+	if c.dedupe {
+		head, err := c.deduper.Dedupe(ctx, &warp.CompletionRequest{Messages: out.Messages[:last]})
+		if err != nil {
+			return nil, err
+		}
+		copy(out.Messages, head.Messages)
+	}
+
+	if c.targetTokens <= 0 {
+		return &out, nil
+	}
+
+	total := c.counter.CountRequest(&out)
+	if total <= c.targetTokens {
+		return &out, nil
+	}
+
+	// Split compressible messages into sentences
+	query := contentWords(textOf(out.Messages[last]))
+	var sentences []*sentence
+	byMsg := make(map[int][]*sentence)
+	for i, msg := range out.Messages[:last] {
+		text, ok := msg.Content.(string)
+		if !ok || !slices.Contains(c.roles, msg.Role) || isJSON(text) {
+			continue
+		}
+		for _, s := range splitSentences(text) {
+			sent := &sentence{text: s, msg: i}
+			sentences = append(sentences, sent)
+			byMsg[i] = append(byMsg[i], sent)
+		}
+	}
+
+	// Drop the least informative sentences first, keeping at least one
+	// sentence per message so tool results and turns stay non-empty
+	candidates := make([]*sentence, 0, len(sentences))
+	for _, s := range sentences {
+		if !s.removed {
+			s.score = score(s.text, query)
+			s.tokens = c.counter.CountText(s.text)
+			candidates = append(candidates, s)
+		}
+	}
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return candidates[i].score < candidates[j].score
+	})
+
+	remaining := make(map[int]int)
+	for _, s := range candidates {
+		remaining[s.msg]++
+	}
+	for _, s := range candidates {
+		if total <= c.targetTokens {
+			break
+		}
+		if remaining[s.msg] <= 1 {
+			continue
+		}
+		s.removed = true
+		remaining[s.msg]--
+		total -= s.tokens
+	}
+	c.rebuild(&out, byMsg)
+
+	// Still over budget: strip filler words from what is left
+	if c.counter.CountRequest(&out) > c.targetTokens {
+		for _, s := range sentences {
+			if !s.removed {
+				s.text = dropFiller(s.text)
+			}
+		}
+		c.rebuild(&out, byMsg)
+	}
+
+	return &out, nil
+}
+
+// rebuild rewrites compressed message content from the kept sentences.
+func (c *Compressor) rebuild(req *warp.CompletionRequest, byMsg map[int][]*sentence) {
+	for i, sents := range byMsg {
+		var b strings.Builder
+		for _, s := range sents {
+			if !s.removed {
+				b.WriteString(s.text)
+			}
+		}
+		req.Messages[i].Content = strings.TrimSpace(b.String())
+	}
+}
+
+// splitSentences splits text after sentence-ending punctuation followed by
+// whitespace, and at line breaks. Each sentence keeps its trailing whitespace
+// so the original layout survives reassembly.
+func splitSentences(text string) []string {
+	var out []string
+	start := 0
+	runes := []rune(text)
+	for i := 0; i < len(runes); i++ {
+		r := runes[i]
+		end := r == '\n' ||
+			((r == '.' || r == '!' || r == '?') && i+1 < len(runes) && unicode.IsSpace(runes[i+1]))
+		if !end {
+			continue
+		}
+		// Include following whitespace in this sentence
+		j := i + 1
+		for j < len(runes) && unicode.IsSpace(runes[j]) {
+			j++
+		}
+		out = append(out, string(runes[start:j]))
+		start = j
+		i = j - 1
+	}
+	if start < len(runes) {
+		out = append(out, string(runes[start:]))
+	}
+	return out
+}
+
+// isJSON reports whether text is a JSON object or array, which must be
+// kept whole.
+func isJSON(text string) bool {
+	trimmed := strings.TrimSpace(text)
+	if trimmed == "" || (trimmed[0] != '{' && trimmed[0] != '[') {
+		return false
+	}
+	return json.Valid([]byte(trimmed))
+}
+
+// score estimates a sentence's information content.
+//
+// Sentences dense in distinct content words, numbers, and names score
+// higher, as do sentences sharing terms with the query.
+func score(s string, query map[string]bool) float64 {
+	words := strings.Fields(s)
+	if len(words) == 0 {
+		return 0
+	}
+
+	content := contentWords(s)
+	density := float64(len(content)) / float64(len(words))
+
+	var specific, overlap int
+	for _, w := range words {
+		r := []rune(w)
+		if unicode.IsDigit(r[0]) || unicode.IsUpper(r[0]) {
+			specific++
+		}
+	}
+	for w := range content {
+		if query[w] {
+			overlap++
+		}
+	}
+
+	return density + 0.5*float64(specific)/float64(len(words)) + 2*float64(overlap)/float64(len(content)+1)
+}
+
+// contentWords returns the distinct lowercase non-filler words in s.
+func contentWords(s string) map[string]bool {
+	words := make(map[string]bool)
+	for _, w := range strings.Fields(strings.ToLower(s)) {
+		w = strings.TrimFunc(w, unicode.IsPunct)
+		if w != "" && !fillerWords[w] {
+			words[w] = true
+		}
+	}
+	return words
+}
+
+// dropFiller removes filler words, keeping trailing whitespace.
+func dropFiller(s string) string {
+	trimmed := strings.TrimRightFunc(s, unicode.IsSpace)
+	trailing := s[len(trimmed):]
+
+	words := strings.Fields(trimmed)
+	kept := words[:0]
+	for _, w := range words {
+		if !fillerWords[strings.ToLower(strings.TrimFunc(w, unicode.IsPunct))] {
+			kept = append(kept, w)
+		}
+	}
+	if len(kept) == 0 {
+		return s
+	}
+	return strings.Join(kept, " ") + trailing
+}
+
+// textOf returns the text of a message's content.
+func textOf(msg warp.Message) string {
+	switch content := msg.Content.(type) {
+	case string:
+		return content
+	case []warp.ContentPart:
+		var parts []string
+		for _, part := range content {
+			if part.Text != "" {
+				parts = append(parts, part.Text)
+			}
+		}
+		return strings.Join(parts, " ")
+	}
+	return ""
+}
+
+// fillerWords are common English words that carry little information.
+var fillerWords = map[string]bool{
+	"a": true, "an": true, "the": true, "and": true, "or": true, "but": true,
+	"of": true, "to": true, "in": true, "on": true, "at": true, "for": true,
+	"with": true, "by": true, "from": true, "as": true, "is": true, "are": true,
+	"was": true, "were": true, "be": true, "been": true, "being": true,
+	"it": true, "its": true, "this": true, "that": true, "these": true,
+	"those": true, "there": true, "here": true, "very": true, "really": true,
+	"just": true, "quite": true, "also": true, "so": true, "then": true,
+	"which": true, "who": true, "whom": true, "has": true, "have": true,
+	"had": true, "do": true, "does": true, "did": true, "can": true,
+	"could": true, "would": true, "should": true, "may": true, "might": true,
+	"will": true, "shall": true, "into": true, "about": true, "some": true,
+	"such": true, "than": true, "too": true, "basically": true,
+	"actually": true, "essentially": true, "generally": true,
+}
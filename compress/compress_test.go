@@ -0,0 +1,183 @@
+package compress
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/blue-context/warp"
+	"github.com/blue-context/warp/token"
+)
+
+var doc = "The launch is on May 5, the budget is $2M, and the team of 12 engineers " +
+	"reports to the platform group, which owns the rollout plan and the on-call rota."
+
+// TestCompressDeduplicates tests removal of repeated blocks across messages
+func TestCompressDeduplicates(t *testing.T) {
+	req := &warp.CompletionRequest{
+		Model: "openai/gpt-4o",
+		Messages: []warp.Message{
+			{Role: "system", Content: "Answer from the documents."},
+			{Role: "user", Content: "Context:\n\n" + doc},
+			{Role: "user", Content: doc + "\n\nThe team also has 2 designers."},
+			{Role: "user", Content: "When is the launch?"},
+		},
+	}
+
+	out, err := New().Compress(context.Background(), req)
+	if err != nil {
+		t.Fatalf("Compress() error = %v", err)
+	}
+	if got := out.Messages[2].Content; got != "The team also has 2 designers." {
+		t.Errorf("Messages[2] = %q, want duplicate removed", got)
+	}
+
+	// The original request is not modified
+	if req.Messages[2].Content != doc+"\n\nThe team also has 2 designers." {
+		t.Error("Compress() modified the input request")
+	}
+}
+
+// TestCompressDeduplicatesKeepsRepeatedMessage tests that a message repeating
+// earlier text entirely is not left empty
+func TestCompressDeduplicatesKeepsRepeatedMessage(t *testing.T) {
+	req := &warp.CompletionRequest{
+		Model: "openai/gpt-4o",
+		Messages: []warp.Message{
+			{Role: "user", Content: doc},
+			{Role: "assistant", Content: "Noted."},
+			{Role: "user", Content: doc},
+			{Role: "user", Content: "When is the launch?"},
+		},
+	}
+
+	out, err := New().Compress(context.Background(), req)
+	if err != nil {
+		t.Fatalf("Compress() error = %v", err)
+	}
+	if got, _ := out.Messages[2].Content.(string); !strings.Contains(got, "see message 1") {
+		t.Errorf("Messages[2] = %q, want a reference to the first copy", got)
+	}
+	if got := out.Messages[0].Content; got != doc {
+		t.Errorf("Messages[0] = %q, want unchanged", got)
+	}
+}
+
+// TestCompressKeepsShortLinesAndToolResults tests that short repeated lines
+// and tool results are left alone by default
+func TestCompressKeepsShortLinesAndToolResults(t *testing.T) {
+	csv := "id,name\n1,alpha\n2,beta"
+	result := `{"launch": "May 5", "budget": "$2M"}`
+	req := &warp.CompletionRequest{
+		Model: "openai/gpt-4o",
+		Messages: []warp.Message{
+			{Role: "user", Content: csv},
+			{Role: "assistant", Content: "", ToolCalls: []warp.ToolCall{{ID: "call_1", Type: "function"}}},
+			{Role: "tool", ToolCallID: "call_1", Content: result},
+			{Role: "tool", ToolCallID: "call_2", Content: result},
+			{Role: "user", Content: csv + "\n3,gamma"},
+			{Role: "user", Content: "Summarize."},
+		},
+	}
+
+	out, err := New().Compress(context.Background(), req)
+	if err != nil {
+		t.Fatalf("Compress() error = %v", err)
+	}
+	for i := range req.Messages {
+		if out.Messages[i].Content != req.Messages[i].Content {
+			t.Errorf("Messages[%d] = %q, want unchanged", i, out.Messages[i].Content)
+		}
+	}
+
+	// JSON content is never split into sentences, even over budget
+	req.Messages[2].Content = `{"notes": "First. Second. Third. Fourth. Fifth. Sixth."}`
+	out, err = New(WithRoles("user", "tool"), WithTargetTokens(1)).Compress(context.Background(), req)
+	if err != nil {
+		t.Fatalf("Compress() error = %v", err)
+	}
+	if out.Messages[2].Content != req.Messages[2].Content {
+		t.Errorf("Messages[2] = %q, want JSON kept whole", out.Messages[2].Content)
+	}
+}
+
+// TestCompressTargetTokens tests budget-driven sentence dropping
+func TestCompressTargetTokens(t *testing.T) {
+	var docs []string
+	for i := 0; i < 40; i++ {
+		docs = append(docs, "It is what it is and that is that, as they say, so to speak"+strings.Repeat(" really", i%3)+".")
+	}
+	docs = append(docs, "Warp supports Anthropic, OpenAI, and vLLM providers since version 0.4.")
+
+	req := &warp.CompletionRequest{
+		Model: "openai/gpt-4o",
+		Messages: []warp.Message{
+			{Role: "system", Content: "You are a helpful assistant."},
+			{Role: "user", Content: strings.Join(docs, " ")},
+			{Role: "user", Content: "Which providers does Warp support?"},
+		},
+	}
+
+	counter := token.NewCounter()
+	before := counter.CountRequest(req)
+	target := before / 4
+
+	out, err := New(WithTargetTokens(target)).Compress(context.Background(), req)
+	if err != nil {
+		t.Fatalf("Compress() error = %v", err)
+	}
+
+	if got := counter.CountRequest(out); got > target {
+		t.Errorf("CountRequest() = %d, want <= %d", got, target)
+	}
+
+	kept := out.Messages[1].Content.(string)
+	if !strings.Contains(kept, "Anthropic") {
+		t.Errorf("relevant sentence dropped: %q", kept)
+	}
+	if out.Messages[0].Content != req.Messages[0].Content {
+		t.Error("system message should not be compressed by default")
+	}
+	if out.Messages[2].Content != req.Messages[2].Content {
+		t.Error("final message should never be compressed")
+	}
+}
+
+// TestCompressUnderBudget tests that requests under budget keep their content
+func TestCompressUnderBudget(t *testing.T) {
+	req := &warp.CompletionRequest{
+		Model: "openai/gpt-4o",
+		Messages: []warp.Message{
+			{Role: "user", Content: "The sky is blue. The grass is green."},
+			{Role: "user", Content: "What color is the sky?"},
+		},
+	}
+
+	out, err := New(WithTargetTokens(10000)).Compress(context.Background(), req)
+	if err != nil {
+		t.Fatalf("Compress() error = %v", err)
+	}
+	if out.Messages[0].Content != "The sky is blue. The grass is green." {
+		t.Errorf("Messages[0] = %q, want unchanged", out.Messages[0].Content)
+	}
+}
+
+// TestSplitSentences tests sentence boundary detection
+func TestSplitSentences(t *testing.T) {
+	tests := []struct {
+		text string
+		want []string
+	}{
+		{"One. Two! Three?", []string{"One. ", "Two! ", "Three?"}},
+		{"Version 1.5 is out. Done", []string{"Version 1.5 is out. ", "Done"}},
+		{"Line one\nLine two", []string{"Line one\n", "Line two"}},
+		{"", nil},
+	}
+
+	for _, tt := range tests {
+		got := splitSentences(tt.text)
+		if strings.Join(got, "|") != strings.Join(tt.want, "|") {
+			t.Errorf("splitSentences(%q) = %q, want %q", tt.text, got, tt.want)
+		}
+	}
+}
@@ -2,13 +2,16 @@ package warp
 
 import (
 	"fmt"
+	"maps"
 	"net/http"
 	"os"
+	"path"
 	"strings"
 	"time"
 
 	"github.com/blue-context/warp/cache"
 	"github.com/blue-context/warp/callback"
+	"github.com/blue-context/warp/cost"
 )
 
 // HTTPClient defines the interface for HTTP clients.
@@ -47,21 +50,164 @@ type ClientConfig struct {
 	TrackCost bool
 
 	// MaxBudget is the maximum budget limit (0 means no limit)
+	//
+	// Expressed in the Currency converter's currency if one is set, otherwise USD.
 	MaxBudget float64
 
+	// Currency converts USD costs for budgets and callbacks (nil reports USD)
+	Currency *cost.CurrencyConverter
+
 	// HTTPClient is the HTTP client to use for requests (injectable for testing)
 	HTTPClient HTTPClient
 
 	// Cache is the cache implementation to use (nil disables caching)
 	Cache cache.Cache
 
+	// CacheKeyFunc derives completion cache keys (nil uses DefaultCacheKey)
+	CacheKeyFunc CacheKeyFunc
+
+	// RequestMiddleware transforms completion requests before dispatch, in order
+	RequestMiddleware []RequestMiddleware
+
 	// Callbacks is the callback registry for request lifecycle hooks
 	Callbacks *callback.Registry
+
+	// ZeroDataRetention asks providers not to retain completion data and
+	// refuses providers that cannot honor it
+	ZeroDataRetention bool
+
+	// ContextOverflowPolicy recovers from context window overflows
+	// (nil returns them as errors)
+	ContextOverflowPolicy *ContextOverflowPolicy
+
+	// ToolEmulation emulates tool calling through the prompt for models
+	// without native support
+	ToolEmulation bool
+
+	// JSONModeEmulation emulates JSON response formats through the prompt
+	// for models without native support
+	JSONModeEmulation bool
+
+	// SystemMessageStrategy restructures multiple or misplaced system
+	// messages before dispatch (empty leaves them to the provider)
+	SystemMessageStrategy SystemMessageStrategy
+
+	// ServiceTierSpillover retries priority-tier requests at the default
+	// tier when the provider is overloaded
+	ServiceTierSpillover bool
+
+	// Clock is the source of time for timestamps, retry backoff, timeouts
+	// and injected latency (nil uses the real clock)
+	Clock Clock
+
+	// FaultInjection adds artificial latency and errors to provider calls
+	FaultInjection FaultFunc
+
+	// UserAgent identifies the application in the User-Agent header, before
+	// DefaultUserAgent (empty sends DefaultUserAgent alone)
+	UserAgent string
+
+	// RequestHeaders are added to every provider HTTP request
+	RequestHeaders http.Header
+
+	// RequestLimits rejects oversized completion requests before they are
+	// sent (nil disables the checks)
+	RequestLimits *RequestLimits
+
+	// AudioTranscoder converts transcription uploads in formats providers
+	// do not accept (nil sends them as is)
+	AudioTranscoder AudioTranscoder
+
+	// UploadBufferSize limits the memory used to buffer an upload that
+	// cannot seek so it can be resent on retry (0 uses
+	// DefaultUploadBufferSize)
+	UploadBufferSize int
+
+	// RequestDefaults are default completion parameters per model or
+	// model group, merged under request values
+	RequestDefaults []RequestDefaults
+
+	// ReplaceDeprecatedModels sends requests for deprecated models to
+	// their replacement instead of only warning
+	ReplaceDeprecatedModels bool
+
+	// RerankEmbeddingModel ranks documents by embedding similarity when
+	// the rerank provider is not registered or cannot rerank (empty
+	// disables the fallback)
+	RerankEmbeddingModel string
+
+	// TokenCounter counts the tokens in text for estimates made before a
+	// request is sent (nil estimates one token per four characters)
+	TokenCounter func(text string) int
+
+	// LanguageDetection tags requests with the detected language of the
+	// prompt and fills in missing transcription languages
+	LanguageDetection bool
+
+	// Bulkheads limits concurrent requests per provider name; "*" applies
+	// to every provider without its own entry
+	Bulkheads map[string]Bulkhead
+
+	// DisablePanicRecovery lets panics in providers, middleware, and
+	// callbacks crash instead of returning an InternalError
+	DisablePanicRecovery bool
+
+	// TracePayloads captures the raw provider request and response bodies
+	// of completions
+	TracePayloads bool
+
+	// MaxResponseBytes limits the size of provider response bodies other
+	// than event streams (0 means no limit)
+	MaxResponseBytes int64
+
+	// AutoContinueTokens continues completions cut off by the token limit
+	// until they total this many output tokens (0 disables)
+	AutoContinueTokens int
+
+	// ContextWindowUpgrades maps a model to a larger-context sibling that
+	// requests too long for the model are sent to before they are tried
+	ContextWindowUpgrades map[string]string
+
+	// StopSequencePolicy adapts requests with more stop sequences than
+	// the provider accepts
+	StopSequencePolicy StopSequencePolicy
+
+	// ToolCallIDPolicy repairs or rejects requests with missing or
+	// duplicate tool call IDs or tool results that answer no call
+	ToolCallIDPolicy ToolCallIDPolicy
 }
 
 // ClientOption is a functional option for configuring the client.
 type ClientOption func(*ClientConfig) error
 
+// ConfigError reports an invalid client option or combination of options.
+// NewClient wraps it, so use errors.As to inspect it.
+//
+// Example:
+//
+//	var cfgErr *warp.ConfigError
+//	if errors.As(err, &cfgErr) && cfgErr.Field == "DefaultTimeout" {
+//	    // ...
+//	}
+type ConfigError struct {
+	// Field is the path of the invalid ClientConfig field, e.g.,
+	// "DefaultTimeout" or "APIKeys[openai]".
+	Field string
+
+	// Reason describes why the value is invalid.
+	Reason string
+}
+
+// Error implements the error interface.
+func (e *ConfigError) Error() string {
+	return e.Field + ": " + e.Reason
+}
+
+// configError creates a ConfigError with a formatted reason.
+func configError(field, format string, args ...any) *ConfigError {
+	return &ConfigError{Field: field, Reason: fmt.Sprintf(format, args...)}
+}
+
 // defaultConfig returns default configuration.
 func defaultConfig() *ClientConfig {
 	return &ClientConfig{
@@ -92,10 +238,10 @@ func defaultConfig() *ClientConfig {
 func WithAPIKey(provider, key string) ClientOption {
 	return func(c *ClientConfig) error {
 		if provider == "" {
-			return fmt.Errorf("provider name is required")
+			return configError("APIKeys", "provider name is required")
 		}
 		if key == "" {
-			return fmt.Errorf("API key is required for provider %s", provider)
+			return configError("APIKeys["+provider+"]", "API key is required for provider %s", provider)
 		}
 		if c.APIKeys == nil {
 			c.APIKeys = make(map[string]string)
@@ -116,10 +262,10 @@ func WithAPIKey(provider, key string) ClientOption {
 func WithAPIBase(provider, base string) ClientOption {
 	return func(c *ClientConfig) error {
 		if provider == "" {
-			return fmt.Errorf("provider name is required")
+			return configError("APIBases", "provider name is required")
 		}
 		if base == "" {
-			return fmt.Errorf("API base is required for provider %s", provider)
+			return configError("APIBases["+provider+"]", "API base is required for provider %s", provider)
 		}
 		if c.APIBases == nil {
 			c.APIBases = make(map[string]string)
@@ -139,7 +285,7 @@ func WithAPIBase(provider, base string) ClientOption {
 func WithTimeout(timeout time.Duration) ClientOption {
 	return func(c *ClientConfig) error {
 		if timeout <= 0 {
-			return fmt.Errorf("timeout must be positive, got %v", timeout)
+			return configError("DefaultTimeout", "timeout must be positive, got %v", timeout)
 		}
 		c.DefaultTimeout = timeout
 		return nil
@@ -161,13 +307,13 @@ func WithTimeout(timeout time.Duration) ClientOption {
 func WithRetries(maxRetries int, initialDelay time.Duration, multiplier float64) ClientOption {
 	return func(c *ClientConfig) error {
 		if maxRetries < 0 {
-			return fmt.Errorf("maxRetries must be non-negative, got %d", maxRetries)
+			return configError("MaxRetries", "maxRetries must be non-negative, got %d", maxRetries)
 		}
 		if initialDelay < 0 {
-			return fmt.Errorf("initialDelay must be non-negative, got %v", initialDelay)
+			return configError("RetryDelay", "initialDelay must be non-negative, got %v", initialDelay)
 		}
 		if multiplier <= 0 {
-			return fmt.Errorf("multiplier must be positive, got %f", multiplier)
+			return configError("RetryMultiplier", "multiplier must be positive, got %f", multiplier)
 		}
 		c.MaxRetries = maxRetries
 		c.RetryDelay = initialDelay
@@ -186,7 +332,7 @@ func WithRetries(maxRetries int, initialDelay time.Duration, multiplier float64)
 func WithMaxRetries(max int) ClientOption {
 	return func(c *ClientConfig) error {
 		if max < 0 {
-			return fmt.Errorf("max retries must be non-negative, got %d", max)
+			return configError("MaxRetries", "max retries must be non-negative, got %d", max)
 		}
 		c.MaxRetries = max
 		return nil
@@ -204,7 +350,7 @@ func WithMaxRetries(max int) ClientOption {
 func WithFallbacks(models ...string) ClientOption {
 	return func(c *ClientConfig) error {
 		if len(models) == 0 {
-			return fmt.Errorf("at least one fallback model is required")
+			return configError("FallbackModels", "at least one fallback model is required")
 		}
 		c.FallbackModels = models
 		return nil
@@ -213,7 +359,8 @@ func WithFallbacks(models ...string) ClientOption {
 
 // WithDebug enables or disables debug mode.
 //
-// In debug mode, all requests and responses are logged.
+// In debug mode, every completion is logged to slog.Default() with its
+// content hashed (see LogCompletions and DefaultRedactPolicy).
 //
 // Example:
 //
@@ -241,8 +388,12 @@ func WithCostTracking(track bool) ClientOption {
 
 // WithMaxBudget sets a maximum budget limit.
 //
-// If cost exceeds this limit, requests will fail with a budget error.
-// Set to 0 to disable budget limits.
+// Once tracked spend reaches this limit, requests are refused with
+// *BudgetExceededError before they are sent. The request that crosses the
+// limit has already been paid for, so it is returned and its full cost
+// recorded. Completions, streams, embeddings, images, and audio are charged.
+// A positive budget enables cost tracking, since the limit is checked
+// against tracked costs. Set to 0 to disable budget limits.
 // Returns an error if budget is negative.
 //
 // Example:
@@ -251,9 +402,38 @@ func WithCostTracking(track bool) ClientOption {
 func WithMaxBudget(budget float64) ClientOption {
 	return func(c *ClientConfig) error {
 		if budget < 0 {
-			return fmt.Errorf("budget must be non-negative, got %f", budget)
+			return configError("MaxBudget", "budget must be non-negative, got %f", budget)
 		}
 		c.MaxBudget = budget
+		if budget > 0 {
+			c.TrackCost = true
+		}
+		return nil
+	}
+}
+
+// WithCurrency reports costs in another currency.
+//
+// Budget limits set with WithMaxBudget are interpreted in the converter's
+// currency, and success callbacks receive the converted cost alongside the
+// USD cost. Exchange rates are cached by the converter; call its Refresh
+// method to update them on demand.
+// Returns an error if the converter is nil.
+//
+// Example:
+//
+//	conv, _ := cost.NewCurrencyConverter("EUR", cost.StaticRates{"EUR": 0.92}, time.Hour)
+//	client, err := warp.NewClient(
+//	    warp.WithCostTracking(true),
+//	    warp.WithCurrency(conv),
+//	    warp.WithMaxBudget(50), // 50 EUR
+//	)
+func WithCurrency(converter *cost.CurrencyConverter) ClientOption {
+	return func(c *ClientConfig) error {
+		if converter == nil {
+			return configError("Currency", "currency converter cannot be nil")
+		}
+		c.Currency = converter
 		return nil
 	}
 }
@@ -273,7 +453,7 @@ func WithMaxBudget(budget float64) ClientOption {
 func WithHTTPClient(client HTTPClient) ClientOption {
 	return func(c *ClientConfig) error {
 		if client == nil {
-			return fmt.Errorf("HTTP client cannot be nil")
+			return configError("HTTPClient", "HTTP client cannot be nil")
 		}
 		c.HTTPClient = client
 		return nil
@@ -302,6 +482,50 @@ func WithCache(c cache.Cache) ClientOption {
 	}
 }
 
+// WithCacheKeyFunc sets the function used to derive completion cache keys.
+//
+// By default the key covers the model, messages, temperature, max tokens,
+// and top-p (see DefaultCacheKey). Use CacheKeyExcluding to key on the full
+// request while ignoring fields that change per call, such as Metadata.
+// Returns an error if fn is nil.
+//
+// Example:
+//
+//	warp.WithCacheKeyFunc(warp.CacheKeyExcluding("metadata"))
+func WithCacheKeyFunc(fn CacheKeyFunc) ClientOption {
+	return func(c *ClientConfig) error {
+		if fn == nil {
+			return configError("CacheKeyFunc", "cache key function cannot be nil")
+		}
+		c.CacheKeyFunc = fn
+		return nil
+	}
+}
+
+// WithRequestMiddleware appends middleware that transforms completion
+// requests before dispatch.
+//
+// Middleware runs in the order added, for both Completion and
+// CompletionStream. Returns an error if any middleware is nil.
+//
+// Example with prompt compression:
+//
+//	compressor := compress.New(compress.WithTargetTokens(4000))
+//	client, err := warp.NewClient(
+//	    warp.WithRequestMiddleware(compressor.Compress),
+//	)
+func WithRequestMiddleware(mw ...RequestMiddleware) ClientOption {
+	return func(c *ClientConfig) error {
+		for i, m := range mw {
+			if m == nil {
+				return configError(fmt.Sprintf("RequestMiddleware[%d]", len(c.RequestMiddleware)+i), "request middleware cannot be nil")
+			}
+		}
+		c.RequestMiddleware = append(c.RequestMiddleware, mw...)
+		return nil
+	}
+}
+
 // LoadConfigFromEnv loads configuration from environment variables.
 //
 // Supported environment variables:
@@ -369,6 +593,675 @@ func LoadConfigFromEnv() ([]ClientOption, error) {
 	return opts, nil
 }
 
+// WithZeroDataRetention asks providers not to store prompts or completions.
+//
+// When enabled, each completion request is passed to the provider's
+// DataRetentionController, which sets its privacy options (e.g., OpenAI
+// "store": false, OpenRouter data_collection "deny"). Requests to providers
+// that cannot honor it fail with a *PolicyViolationError before they are
+// sent.
+//
+// Example:
+//
+//	client, err := warp.NewClient(
+//	    warp.WithZeroDataRetention(true),
+//	)
+func WithZeroDataRetention(enabled bool) ClientOption {
+	return func(c *ClientConfig) error {
+		c.ZeroDataRetention = enabled
+		return nil
+	}
+}
+
+// WithContextOverflowPolicy sets how requests that exceed the model's
+// context window are recovered: retried on a larger-context fallback model,
+// shortened with a truncation strategy, or both.
+//
+// Returns an error if policy is nil or maps a model to itself.
+//
+// Example:
+//
+//	client, err := warp.NewClient(
+//	    warp.WithContextOverflowPolicy(&warp.ContextOverflowPolicy{
+//	        Fallbacks: map[string]string{"openai/gpt-4o": "openai/gpt-4.1"},
+//	        Truncate:  warp.TruncateOldest(0.25),
+//	    }),
+//	)
+func WithContextOverflowPolicy(policy *ContextOverflowPolicy) ClientOption {
+	return func(c *ClientConfig) error {
+		if policy == nil {
+			return configError("ContextOverflowPolicy", "context overflow policy cannot be nil")
+		}
+		for from, to := range policy.Fallbacks {
+			if from == to {
+				return configError("ContextOverflowPolicy.Fallbacks["+from+"]", "context overflow fallback for %q cannot be itself", from)
+			}
+		}
+		c.ContextOverflowPolicy = policy
+		return nil
+	}
+}
+
+// WithToolEmulation enables or disables tool calling emulation.
+//
+// When enabled, completion requests with tools sent to a model without
+// native tool calling have the tools described in the system prompt
+// instead. The model is asked to reply with a JSON block naming the tools
+// to call, which is parsed back into ToolCalls with FinishReason
+// "tool_calls", so tool loops such as the agent package work unchanged.
+// Earlier tool calls and results in the conversation are rewritten as
+// text.
+//
+// A model lacks native tool calling if ProbeCapabilities found no tool
+// support, or, if it has not been probed, its provider does not declare
+// FunctionCalling. Streaming requests are not emulated.
+//
+// Example:
+//
+//	client, err := warp.NewClient(
+//	    warp.WithToolEmulation(true),
+//	)
+func WithToolEmulation(enabled bool) ClientOption {
+	return func(c *ClientConfig) error {
+		c.ToolEmulation = enabled
+		return nil
+	}
+}
+
+// WithJSONModeEmulation enables or disables JSON mode emulation.
+//
+// When enabled, completion requests with a json_object or json_schema
+// ResponseFormat sent to a model without native JSON mode have the format
+// replaced by an instruction (and the schema) in the system prompt. The
+// JSON object is extracted from each reply, discarding surrounding text
+// and code fences, and checked for the schema's required properties. A
+// reply without a valid object is re-requested once with the problem
+// explained; if it is still invalid, Completion returns an error. Callers
+// therefore get JSON content from every provider.
+//
+// A model lacks native JSON mode if ProbeCapabilities found no JSON mode
+// support, or, if it has not been probed, its provider does not declare
+// JSON. Streaming requests are not emulated.
+//
+// Example:
+//
+//	client, err := warp.NewClient(
+//	    warp.WithJSONModeEmulation(true),
+//	)
+func WithJSONModeEmulation(enabled bool) ClientOption {
+	return func(c *ClientConfig) error {
+		c.JSONModeEmulation = enabled
+		return nil
+	}
+}
+
+// WithSystemMessageStrategy sets how requests with several system messages,
+// or a system message after the conversation has started, are handled:
+// merged into one leading message, reduced to the first, or rejected.
+//
+// Warning callbacks are notified (code WarningSystemMessagesRestructured)
+// whenever messages are merged, moved, or dropped.
+//
+// Returns an error for an unknown strategy.
+//
+// Example:
+//
+//	client, err := warp.NewClient(
+//	    warp.WithSystemMessageStrategy(warp.SystemMessagesConcatenate),
+//	)
+func WithSystemMessageStrategy(strategy SystemMessageStrategy) ClientOption {
+	return func(c *ClientConfig) error {
+		switch strategy {
+		case SystemMessagesProvider, SystemMessagesConcatenate, SystemMessagesFirstOnly, SystemMessagesError:
+		default:
+			return configError("SystemMessageStrategy", "unknown system message strategy %q", strategy)
+		}
+		c.SystemMessageStrategy = strategy
+		return nil
+	}
+}
+
+// WithStopSequencePolicy sets how requests with more stop sequences than
+// their provider accepts are adapted before dispatch.
+//
+// Providers that limit stop sequences implement StopSequenceLimiter (e.g.,
+// OpenAI accepts 4, and none for o-series reasoning models; Gemini accepts
+// 5). By default the first sequences up to the limit are kept and a
+// WarningStopSequencesTruncated warning is raised; StopSequencesError
+// rejects the request instead. Empty and repeated sequences are always
+// dropped.
+//
+// Example:
+//
+//	client, err := warp.NewClient(
+//	    warp.WithStopSequencePolicy(warp.StopSequencesError),
+//	)
+func WithStopSequencePolicy(policy StopSequencePolicy) ClientOption {
+	return func(c *ClientConfig) error {
+		switch policy {
+		case StopSequencesTruncate, StopSequencesError:
+		default:
+			return configError("StopSequencePolicy", "unknown stop sequence policy %q", policy)
+		}
+		c.StopSequencePolicy = policy
+		return nil
+	}
+}
+
+// WithToolCallIDPolicy sets how requests with tool call IDs that would
+// break the tool-response round trip are handled before dispatch.
+//
+// Some providers return tool calls without IDs or with repeated IDs, and
+// histories assembled by hand may reference calls that do not exist. By
+// default such requests are repaired: calls get stable IDs, tool results
+// are pointed at the call they answer, tool results that answer no call
+// are dropped, and a WarningToolCallIDsRepaired warning is raised for each
+// change. ToolCallIDsError rejects them instead.
+//
+// Tool calls in responses are always given unique IDs (see
+// NormalizeToolCallIDs).
+//
+// Example:
+//
+//	client, err := warp.NewClient(
+//	    warp.WithToolCallIDPolicy(warp.ToolCallIDsError),
+//	)
+func WithToolCallIDPolicy(policy ToolCallIDPolicy) ClientOption {
+	return func(c *ClientConfig) error {
+		switch policy {
+		case ToolCallIDsRepair, ToolCallIDsError:
+		default:
+			return configError("ToolCallIDPolicy", "unknown tool call ID policy %q", policy)
+		}
+		c.ToolCallIDPolicy = policy
+		return nil
+	}
+}
+
+// WithServiceTierSpillover enables or disables service tier spillover.
+//
+// When enabled, a request with ServiceTier set to ServiceTierPriority that
+// fails with a rate limit, service unavailable, or overloaded error (after
+// retries) is sent once more at ServiceTierDefault. A
+// WarningServiceTierSpillover warning is raised for each spillover.
+//
+// Example:
+//
+//	client, err := warp.NewClient(
+//	    warp.WithServiceTierSpillover(true),
+//	)
+func WithServiceTierSpillover(enabled bool) ClientOption {
+	return func(c *ClientConfig) error {
+		c.ServiceTierSpillover = enabled
+		return nil
+	}
+}
+
+// WithClock sets the client's source of time.
+//
+// The clock drives request timestamps, retry backoff delays, request
+// timeouts, and latency added by WithFaultInjection. It is intended for
+// tests; warptest.Clock is a fake clock that only moves when advanced.
+// Returns an error if clock is nil.
+//
+// Example:
+//
+//	clock := warptest.NewClock(time.Now())
+//	client, err := warp.NewClient(warp.WithClock(clock))
+func WithClock(clock Clock) ClientOption {
+	return func(c *ClientConfig) error {
+		if clock == nil {
+			return configError("Clock", "clock cannot be nil")
+		}
+		c.Clock = clock
+		return nil
+	}
+}
+
+// WithFaultInjection adds artificial latency and errors to provider calls
+// for testing retry, timeout and fallback behavior.
+//
+// fn is called before every provider attempt; see FaultFunc. The warptest
+// package provides fault functions for common distributions.
+// Returns an error if fn is nil.
+//
+// Example:
+//
+//	warp.WithFaultInjection(warptest.Faults(
+//	    warptest.FailFirst(2, warp.NewRateLimitError("injected", "openai", 0, nil)),
+//	    warptest.Latency(warptest.Fixed(500*time.Millisecond)),
+//	))
+func WithFaultInjection(fn FaultFunc) ClientOption {
+	return func(c *ClientConfig) error {
+		if fn == nil {
+			return configError("FaultInjection", "fault function cannot be nil")
+		}
+		c.FaultInjection = fn
+		return nil
+	}
+}
+
+// WithUserAgent identifies the application to providers.
+//
+// ua is a product token such as "my-app/1.2.0", sent before the warp token:
+// "User-Agent: my-app/1.2.0 warp-go/0.1.0". Without it, providers receive
+// DefaultUserAgent. Returns an error if ua is empty.
+//
+// Example:
+//
+//	client, err := warp.NewClient(
+//	    warp.WithUserAgent("support-bot/2.3.1"),
+//	)
+func WithUserAgent(ua string) ClientOption {
+	return func(c *ClientConfig) error {
+		ua = strings.TrimSpace(ua)
+		if ua == "" {
+			return configError("UserAgent", "user agent cannot be empty")
+		}
+		c.UserAgent = ua
+		return nil
+	}
+}
+
+// WithRequestHeader adds a header to every provider HTTP request, for
+// example the app identification headers some providers ask for.
+//
+// Headers a provider sets itself, such as authentication, take precedence.
+// Returns an error if key is empty.
+//
+// Example:
+//
+//	client, err := warp.NewClient(
+//	    warp.WithRequestHeader("HTTP-Referer", "https://example.com"),
+//	    warp.WithRequestHeader("X-Title", "Support Bot"),
+//	)
+func WithRequestHeader(key, value string) ClientOption {
+	return func(c *ClientConfig) error {
+		if strings.TrimSpace(key) == "" {
+			return configError("RequestHeaders", "header name cannot be empty")
+		}
+		if c.RequestHeaders == nil {
+			c.RequestHeaders = make(http.Header)
+		}
+		c.RequestHeaders.Set(key, value)
+		return nil
+	}
+}
+
+// WithRequestLimits sets guards on the size of completion requests.
+//
+// Requests with more messages, images, or tool definitions than allowed,
+// or a larger JSON encoding, fail with *RequestLimitError before any
+// network call. Zero fields are unlimited.
+// Returns an error if a limit is negative.
+//
+// Example:
+//
+//	client, err := warp.NewClient(
+//	    warp.WithRequestLimits(warp.RequestLimits{
+//	        MaxBodyBytes: 1 << 20,
+//	        MaxMessages:  200,
+//	        MaxImages:    10,
+//	        MaxTools:     64,
+//	    }),
+//	)
+func WithRequestLimits(limits RequestLimits) ClientOption {
+	return func(c *ClientConfig) error {
+		if limits.MaxBodyBytes < 0 || limits.MaxMessages < 0 || limits.MaxImages < 0 || limits.MaxTools < 0 {
+			return configError("RequestLimits", "request limits cannot be negative")
+		}
+		c.RequestLimits = &limits
+		return nil
+	}
+}
+
+// WithAudioTranscoder sets the transcoder for transcription uploads.
+//
+// Transcription detects the format of uploaded audio from its content.
+// Audio in a format providers do not accept, such as Ogg or FLAC, is
+// converted with the transcoder before it is sent.
+// Returns an error if transcoder is nil.
+//
+// Example:
+//
+//	client, err := warp.NewClient(
+//	    warp.WithAudioTranscoder(ffmpegTranscoder{}),
+//	)
+func WithAudioTranscoder(transcoder AudioTranscoder) ClientOption {
+	return func(c *ClientConfig) error {
+		if transcoder == nil {
+			return configError("AudioTranscoder", "audio transcoder cannot be nil")
+		}
+		c.AudioTranscoder = transcoder
+		return nil
+	}
+}
+
+// WithUploadBufferSize sets the memory limit for buffering uploads.
+//
+// Files in transcription, image edit, and image variation requests are
+// consumed when they are sent. To resend them on retry, seekable readers
+// such as *os.File are rewound, and other readers are buffered as they
+// are read, up to size bytes per file. A larger upload is not retried.
+// The default is DefaultUploadBufferSize.
+// Returns an error if size is not positive.
+//
+// Example:
+//
+//	client, err := warp.NewClient(
+//	    warp.WithUploadBufferSize(64 << 20),
+//	)
+func WithUploadBufferSize(size int) ClientOption {
+	return func(c *ClientConfig) error {
+		if size <= 0 {
+			return configError("UploadBufferSize", "upload buffer size must be positive, got %d", size)
+		}
+		c.UploadBufferSize = size
+		return nil
+	}
+}
+
+// WithRequestDefaults adds default completion parameters for the models
+// matching defaults.Model.
+//
+// Defaults fill in Temperature, MaxTokens, and TopP when a request leaves
+// them unset, and prepend SystemPrompt to requests without a system
+// message. When several defaults match a model they are merged in the
+// order added, so add general defaults before specific ones.
+// Returns an error if the model pattern is empty or malformed.
+//
+// Example:
+//
+//	client, err := warp.NewClient(
+//	    warp.WithRequestDefaults(warp.RequestDefaults{
+//	        Model:     "*",
+//	        MaxTokens: warp.IntPtr(1024),
+//	    }),
+//	    warp.WithRequestDefaults(warp.RequestDefaults{
+//	        Model:        "anthropic/*",
+//	        Temperature:  warp.Float64Ptr(0.3),
+//	        SystemPrompt: "You are a concise support assistant.",
+//	    }),
+//	)
+func WithRequestDefaults(defaults RequestDefaults) ClientOption {
+	return func(c *ClientConfig) error {
+		field := fmt.Sprintf("RequestDefaults[%d]", len(c.RequestDefaults))
+		if defaults.Model == "" {
+			return configError(field, "model pattern is required")
+		}
+		if _, err := path.Match(defaults.Model, ""); err != nil {
+			return configError(field, "invalid model pattern %q: %v", defaults.Model, err)
+		}
+		c.RequestDefaults = append(c.RequestDefaults, defaults)
+		return nil
+	}
+}
+
+// WithDeprecatedModelReplacement enables or disables automatic replacement
+// of deprecated models.
+//
+// A completion request for a model that its provider's registry marks as
+// deprecated always raises a WarningModelDeprecated warning. When
+// replacement is enabled and the registry names a replacement model, the
+// request is sent to the replacement instead and a WarningModelReplaced
+// warning is raised.
+//
+// Example:
+//
+//	client, err := warp.NewClient(
+//	    warp.WithDeprecatedModelReplacement(true),
+//	)
+func WithDeprecatedModelReplacement(enabled bool) ClientOption {
+	return func(c *ClientConfig) error {
+		c.ReplaceDeprecatedModels = enabled
+		return nil
+	}
+}
+
+// WithRerankEmbeddingFallback ranks documents locally with an embedding
+// model when Rerank is called for a provider that is not registered or
+// does not support reranking.
+//
+// The query and documents are embedded with model in one request and the
+// documents are ranked by cosine similarity to the query. Scores are less
+// accurate than a dedicated rerank model, so a WarningRerankEmbeddingFallback
+// warning is raised each time the fallback is used.
+// Returns an error if model is not in "provider/model-name" form.
+//
+// Example:
+//
+//	client, err := warp.NewClient(
+//	    warp.WithRerankEmbeddingFallback("openai/text-embedding-3-small"),
+//	)
+func WithRerankEmbeddingFallback(model string) ClientOption {
+	return func(c *ClientConfig) error {
+		if _, _, err := parseModel(model); err != nil {
+			return configError("RerankEmbeddingModel", "%v", err)
+		}
+		c.RerankEmbeddingModel = model
+		return nil
+	}
+}
+
+// WithTokenCounter sets the function used to count tokens in text when
+// estimating requests before they are sent, as EstimateCompletionCost does.
+//
+// The default estimates one token per four characters. For closer
+// estimates, pass a real counter such as token.NewCounter().CountText.
+// Returns an error if count is nil.
+//
+// Example:
+//
+//	client, err := warp.NewClient(
+//	    warp.WithTokenCounter(token.NewCounter().CountText),
+//	)
+func WithTokenCounter(count func(text string) int) ClientOption {
+	return func(c *ClientConfig) error {
+		if count == nil {
+			return configError("TokenCounter", "token counter cannot be nil")
+		}
+		c.TokenCounter = count
+		return nil
+	}
+}
+
+// WithLanguageDetection enables detecting the language of requests with
+// DetectLanguage.
+//
+// Completion requests are tagged with the language of the last user
+// message, which middleware and callbacks read with LanguageFromContext,
+// for example to route other languages to a multilingual model.
+// Transcription requests without a Language get the language of their
+// Prompt, if it can be detected.
+//
+// Example:
+//
+//	client, err := warp.NewClient(
+//	    warp.WithLanguageDetection(true),
+//	)
+func WithLanguageDetection(enabled bool) ClientOption {
+	return func(c *ClientConfig) error {
+		c.LanguageDetection = enabled
+		return nil
+	}
+}
+
+// WithBulkhead limits the concurrent requests to a provider, so a slow
+// provider that saturates its connections cannot starve other providers
+// sharing the client.
+//
+// At most maxConcurrent requests to the provider are in flight at once;
+// streams hold their slot until they are closed. A request that finds the
+// bulkhead full waits up to maxWait for a slot, and then fails with a
+// *BulkheadFullError, which is not retried, and raises a
+// WarningBulkheadRejected warning. The provider "*" sets a bulkhead for
+// each provider without its own. Returns an error if maxConcurrent is not
+// positive or maxWait is negative.
+//
+// Example:
+//
+//	client, err := warp.NewClient(
+//	    warp.WithBulkhead("*", 64, 0),
+//	    warp.WithBulkhead("vllm", 8, 500*time.Millisecond),
+//	)
+func WithBulkhead(provider string, maxConcurrent int, maxWait time.Duration) ClientOption {
+	return func(c *ClientConfig) error {
+		if provider == "" {
+			return configError("Bulkheads", "provider cannot be empty")
+		}
+		if maxConcurrent <= 0 {
+			return configError("Bulkheads", "max concurrent requests must be positive, got %d", maxConcurrent)
+		}
+		if maxWait < 0 {
+			return configError("Bulkheads", "max wait must be non-negative, got %s", maxWait)
+		}
+		if c.Bulkheads == nil {
+			c.Bulkheads = make(map[string]Bulkhead)
+		}
+		c.Bulkheads[provider] = Bulkhead{MaxConcurrent: maxConcurrent, MaxWait: maxWait}
+		return nil
+	}
+}
+
+// WithPanicRecovery sets whether panics in provider calls, request
+// middleware, and callbacks are recovered, which is the default.
+//
+// A recovered panic fails the request with an *InternalError that holds
+// the panic value and stack trace in its HiddenParams, instead of
+// crashing the host process; callback panics are ignored or, for
+// before-request callbacks, returned as errors. Disabling recovery lets
+// panics propagate with their original stack, which helps when debugging.
+//
+// Example:
+//
+//	client, err := warp.NewClient(
+//	    warp.WithPanicRecovery(os.Getenv("WARP_DEBUG") == ""),
+//	)
+func WithPanicRecovery(enabled bool) ClientOption {
+	return func(c *ClientConfig) error {
+		c.DisablePanicRecovery = !enabled
+		return nil
+	}
+}
+
+// WithPayloadTracing sets whether the exact JSON sent to and received from
+// the provider is captured for each completion, to troubleshoot request
+// translation without a packet capture.
+//
+// The payloads are returned in the response's HiddenParams under
+// "_raw_request" and "_raw_response" and passed to debug callbacks (see
+// WithDebugCallback), which also receive them for failed requests. API
+// keys, tokens, and other secret fields are redacted, and each payload is
+// truncated to 1 MiB. Streaming responses are captured as the raw event
+// stream. Tracing copies every payload, so enable it only for debugging.
+//
+// Example:
+//
+//	client, err := warp.NewClient(
+//	    warp.WithPayloadTracing(true),
+//	)
+//	resp, err := client.Completion(ctx, req)
+//	log.Println(resp.HiddenParams["_raw_request"])
+func WithPayloadTracing(enabled bool) ClientOption {
+	return func(c *ClientConfig) error {
+		c.TracePayloads = enabled
+		return nil
+	}
+}
+
+// WithMaxResponseSize limits the size of provider response bodies to n
+// bytes, so a misbehaving endpoint cannot exhaust memory with an endless
+// or oversized response. Reading past the limit fails the request with a
+// *ResponseTooLargeError. Streaming responses are limited per event
+// instead (see the sse package). Zero removes the limit.
+//
+// Example:
+//
+//	client, err := warp.NewClient(
+//	    warp.WithMaxResponseSize(32 << 20), // 32 MiB
+//	)
+func WithMaxResponseSize(n int64) ClientOption {
+	return func(c *ClientConfig) error {
+		if n < 0 {
+			return configError("MaxResponseBytes", "max response size must be non-negative, got %d", n)
+		}
+		c.MaxResponseBytes = n
+		return nil
+	}
+}
+
+// WithAutoContinue continues completions that stop at the token limit
+// (finish reason "length") by asking the model to resume, until the reply
+// is complete or totals maxTokens output tokens. The pieces are joined
+// into one response whose usage covers every request; HiddenParams
+// "_continuations" holds the number of follow-up requests.
+//
+// Only non-streaming, single-choice text replies are continued. Each
+// follow-up resends the conversation, so long prompts cost more input
+// tokens per continuation. Zero disables auto-continue.
+//
+// Example:
+//
+//	client, err := warp.NewClient(
+//	    warp.WithAutoContinue(16000),
+//	)
+func WithAutoContinue(maxTokens int) ClientOption {
+	return func(c *ClientConfig) error {
+		if maxTokens < 0 {
+			return configError("AutoContinueTokens", "auto-continue budget must be non-negative, got %d", maxTokens)
+		}
+		c.AutoContinueTokens = maxTokens
+		return nil
+	}
+}
+
+// WithContextWindowUpgrades sends requests whose prompt does not fit the
+// requested model's context window to a larger-context sibling instead,
+// before any request is made.
+//
+// upgrades maps a model to its sibling, both in "provider/model" form.
+// Upgrades chain: if the prompt does not fit the sibling either, the
+// sibling's own upgrade is tried. The prompt is counted with the client's
+// TokenCounter, plus MaxTokens when the request sets it, and compared with
+// the ContextWindow of the model registry. Models with an unknown window
+// are not upgraded from, and a sibling with an unknown window is assumed to
+// fit.
+//
+// An upgraded request raises a WarningModelUpgraded warning, is reported to
+// the other callbacks under the sibling's name, and its response records
+// the requested model in HiddenParams["_upgraded_from"]. Unlike the
+// Fallbacks of WithContextOverflowPolicy, which retry after the provider
+// rejected a request, upgrades avoid the failed request.
+//
+// Returns an error if a model is not in "provider/model" form or is
+// upgraded to itself.
+//
+// Example:
+//
+//	client, err := warp.NewClient(
+//	    warp.WithContextWindowUpgrades(map[string]string{
+//	        "openai/gpt-4":        "openai/gpt-4-32k",
+//	        "openai/gpt-3.5-turbo": "openai/gpt-3.5-turbo-16k",
+//	    }),
+//	)
+func WithContextWindowUpgrades(upgrades map[string]string) ClientOption {
+	return func(c *ClientConfig) error {
+		for from, to := range upgrades {
+			if _, _, err := parseModel(from); err != nil {
+				return configError("ContextWindowUpgrades", "%v", err)
+			}
+			if _, _, err := parseModel(to); err != nil {
+				return configError("ContextWindowUpgrades["+from+"]", "%v", err)
+			}
+			if from == to {
+				return configError("ContextWindowUpgrades["+from+"]", "context window upgrade for %q cannot be itself", from)
+			}
+		}
+		c.ContextWindowUpgrades = maps.Clone(upgrades)
+		return nil
+	}
+}
+
 // WithBeforeRequestCallback registers a before-request callback.
 //
 // Before-request callbacks are executed before sending the request to the provider.
@@ -386,7 +1279,7 @@ func LoadConfigFromEnv() ([]ClientOption, error) {
 func WithBeforeRequestCallback(cb callback.BeforeRequestCallback) ClientOption {
 	return func(c *ClientConfig) error {
 		if cb == nil {
-			return fmt.Errorf("callback cannot be nil")
+			return configError("Callbacks", "callback cannot be nil")
 		}
 		if c.Callbacks == nil {
 			c.Callbacks = callback.NewRegistry()
@@ -412,7 +1305,7 @@ func WithBeforeRequestCallback(cb callback.BeforeRequestCallback) ClientOption {
 func WithSuccessCallback(cb callback.SuccessCallback) ClientOption {
 	return func(c *ClientConfig) error {
 		if cb == nil {
-			return fmt.Errorf("callback cannot be nil")
+			return configError("Callbacks", "callback cannot be nil")
 		}
 		if c.Callbacks == nil {
 			c.Callbacks = callback.NewRegistry()
@@ -438,7 +1331,7 @@ func WithSuccessCallback(cb callback.SuccessCallback) ClientOption {
 func WithFailureCallback(cb callback.FailureCallback) ClientOption {
 	return func(c *ClientConfig) error {
 		if cb == nil {
-			return fmt.Errorf("callback cannot be nil")
+			return configError("Callbacks", "callback cannot be nil")
 		}
 		if c.Callbacks == nil {
 			c.Callbacks = callback.NewRegistry()
@@ -448,6 +1341,59 @@ func WithFailureCallback(cb callback.FailureCallback) ClientOption {
 	}
 }
 
+// WithWarningCallback registers a warning callback.
+//
+// Warning callbacks are executed when the client changes a request in a way
+// the caller may not expect, such as merging system messages.
+//
+// The callback registry is created automatically on first use.
+// Returns an error if the callback is nil.
+//
+// Example:
+//
+//	warp.WithWarningCallback(func(ctx context.Context, event *callback.WarningEvent) {
+//	    log.Printf("warp warning [%s]: %s", event.Code, event.Message)
+//	})
+func WithWarningCallback(cb callback.WarningCallback) ClientOption {
+	return func(c *ClientConfig) error {
+		if cb == nil {
+			return configError("Callbacks", "callback cannot be nil")
+		}
+		if c.Callbacks == nil {
+			c.Callbacks = callback.NewRegistry()
+		}
+		c.Callbacks.RegisterWarning(cb)
+		return nil
+	}
+}
+
+// WithDebugCallback registers a debug callback.
+//
+// Debug callbacks receive the raw JSON sent to and received from the
+// provider for each completion when payload tracing is enabled with
+// WithPayloadTracing.
+//
+// The callback registry is created automatically on first use.
+// Returns an error if the callback is nil.
+//
+// Example:
+//
+//	warp.WithDebugCallback(func(ctx context.Context, event *callback.DebugEvent) {
+//	    log.Printf("sent %s\nreceived %s", event.RawRequest, event.RawResponse)
+//	})
+func WithDebugCallback(cb callback.DebugCallback) ClientOption {
+	return func(c *ClientConfig) error {
+		if cb == nil {
+			return configError("Callbacks", "callback cannot be nil")
+		}
+		if c.Callbacks == nil {
+			c.Callbacks = callback.NewRegistry()
+		}
+		c.Callbacks.RegisterDebug(cb)
+		return nil
+	}
+}
+
 // WithStreamCallback registers a streaming callback.
 //
 // Stream callbacks are executed for each chunk received during streaming.
@@ -464,7 +1410,7 @@ func WithFailureCallback(cb callback.FailureCallback) ClientOption {
 func WithStreamCallback(cb callback.StreamCallback) ClientOption {
 	return func(c *ClientConfig) error {
 		if cb == nil {
-			return fmt.Errorf("callback cannot be nil")
+			return configError("Callbacks", "callback cannot be nil")
 		}
 		if c.Callbacks == nil {
 			c.Callbacks = callback.NewRegistry()
@@ -476,7 +1422,8 @@ func WithStreamCallback(cb callback.StreamCallback) ClientOption {
 
 // Validate validates the configuration.
 //
-// Returns an error if any configuration value is invalid.
+// Returns a *ConfigError for the first invalid value or combination of
+// values.
 //
 // Checks:
 //   - DefaultTimeout must be positive
@@ -484,25 +1431,37 @@ func WithStreamCallback(cb callback.StreamCallback) ClientOption {
 //   - RetryDelay must be non-negative
 //   - RetryMultiplier must be positive
 //   - MaxBudget must be non-negative
+//   - Currency requires TrackCost
 //   - HTTPClient must not be nil
+//   - CacheKeyFunc requires Cache
+//   - ContextOverflowPolicy needs Fallbacks or Truncate
 func (c *ClientConfig) Validate() error {
 	if c.DefaultTimeout <= 0 {
-		return fmt.Errorf("default timeout must be positive")
+		return configError("DefaultTimeout", "default timeout must be positive")
 	}
 	if c.MaxRetries < 0 {
-		return fmt.Errorf("max retries must be non-negative")
+		return configError("MaxRetries", "max retries must be non-negative")
 	}
 	if c.RetryDelay < 0 {
-		return fmt.Errorf("retry delay must be non-negative")
+		return configError("RetryDelay", "retry delay must be non-negative")
 	}
 	if c.RetryMultiplier <= 0 {
-		return fmt.Errorf("retry multiplier must be positive")
+		return configError("RetryMultiplier", "retry multiplier must be positive")
 	}
 	if c.MaxBudget < 0 {
-		return fmt.Errorf("max budget must be non-negative")
+		return configError("MaxBudget", "max budget must be non-negative")
+	}
+	if c.Currency != nil && !c.TrackCost {
+		return configError("Currency", "currency conversion requires cost tracking (WithCostTracking(true))")
 	}
 	if c.HTTPClient == nil {
-		return fmt.Errorf("HTTP client cannot be nil")
+		return configError("HTTPClient", "HTTP client cannot be nil")
+	}
+	if c.CacheKeyFunc != nil && c.Cache == nil {
+		return configError("CacheKeyFunc", "cache key function requires a cache (WithCache)")
+	}
+	if p := c.ContextOverflowPolicy; p != nil && len(p.Fallbacks) == 0 && p.Truncate == nil {
+		return configError("ContextOverflowPolicy", "policy needs fallbacks or a truncation strategy")
 	}
 	return nil
 }
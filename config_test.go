@@ -1,6 +1,8 @@
 package warp
 
 import (
+	"context"
+	"errors"
 	"net/http"
 	"os"
 	"testing"
@@ -458,6 +460,9 @@ func TestWithMaxBudget(t *testing.T) {
 				if config.MaxBudget != tt.budget {
 					t.Errorf("MaxBudget = %f, want %f", config.MaxBudget, tt.budget)
 				}
+				if config.TrackCost != (tt.budget > 0) {
+					t.Errorf("TrackCost = %v, want %v", config.TrackCost, tt.budget > 0)
+				}
 			}
 		})
 	}
@@ -733,3 +738,34 @@ func TestMultipleOptions(t *testing.T) {
 		t.Errorf("Config validation failed: %v", err)
 	}
 }
+
+func TestConfigError(t *testing.T) {
+	tests := []struct {
+		name      string
+		opts      []ClientOption
+		wantField string
+	}{
+		{"option value", []ClientOption{WithTimeout(0)}, "DefaultTimeout"},
+		{"map key", []ClientOption{WithAPIKey("openai", "")}, "APIKeys[openai]"},
+		{"middleware index", []ClientOption{WithRequestMiddleware(func(ctx context.Context, req *CompletionRequest) (*CompletionRequest, error) {
+			return req, nil
+		}, nil)}, "RequestMiddleware[1]"},
+		{"cache key without cache", []ClientOption{WithCacheKeyFunc(DefaultCacheKey)}, "CacheKeyFunc"},
+		{"empty overflow policy", []ClientOption{WithContextOverflowPolicy(&ContextOverflowPolicy{})}, "ContextOverflowPolicy"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewClient(tt.opts...)
+			var cfgErr *ConfigError
+			if !errors.As(err, &cfgErr) {
+				t.Fatalf("NewClient() error = %v, want *ConfigError", err)
+			}
+			if cfgErr.Field != tt.wantField {
+				t.Errorf("Field = %q, want %q", cfgErr.Field, tt.wantField)
+			}
+			if cfgErr.Reason == "" {
+				t.Error("Reason is empty")
+			}
+		})
+	}
+}
@@ -16,6 +16,7 @@ const (
 	contextKeyProvider  contextKey = "litellm_provider"
 	contextKeyModel     contextKey = "litellm_model"
 	contextKeyStartTime contextKey = "litellm_start_time"
+	contextKeyLanguage  contextKey = "litellm_language"
 )
 
 // WithRequestID adds a request ID to the context.
@@ -171,3 +172,33 @@ func StartTimeFromContext(ctx context.Context) time.Time {
 	}
 	return time.Time{}
 }
+
+// WithLanguage adds the language of the request (ISO 639-1 code) to the
+// context.
+//
+// The client sets it when language detection is enabled (see
+// WithLanguageDetection), so middleware and callbacks can use it for
+// analytics or routing.
+//
+// Example:
+//
+//	ctx = warp.WithLanguage(ctx, "de")
+func WithLanguage(ctx context.Context, language string) context.Context {
+	return context.WithValue(ctx, contextKeyLanguage, language)
+}
+
+// LanguageFromContext retrieves the request language from the context.
+//
+// Returns an empty string if no language is found.
+//
+// Example:
+//
+//	if warp.LanguageFromContext(ctx) != "en" {
+//	    req.Model = "openai/gpt-4o"
+//	}
+func LanguageFromContext(ctx context.Context) string {
+	if language, ok := ctx.Value(contextKeyLanguage).(string); ok {
+		return language
+	}
+	return ""
+}
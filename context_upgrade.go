@@ -0,0 +1,72 @@
+package warp
+
+import (
+	"context"
+	"fmt"
+)
+
+// WarningModelUpgraded is the callback.WarningEvent code raised when a
+// request too long for its model is sent to a larger-context sibling.
+const WarningModelUpgraded = "model_upgraded"
+
+// upgradeForContext sends req to a larger-context sibling of its model when
+// the prompt does not fit the model's context window, following the
+// client's ContextWindowUpgrades. Requests using cached content are not
+// upgraded, since the content is cached for one model. It returns the
+// request to send and the model it was upgraded from (empty if it was not
+// upgraded).
+//
+// It runs before request middleware, so middleware such as a policy engine
+// sees the model that is sent.
+func (c *client) upgradeForContext(ctx context.Context, req *CompletionRequest) (*CompletionRequest, string) {
+	upgrades := c.config.ContextWindowUpgrades
+	if _, ok := upgrades[req.Model]; !ok || req.CachedContent != "" {
+		return req, ""
+	}
+	providerName, modelName, err := parseModel(req.Model)
+	if err != nil {
+		return req, ""
+	}
+	window := c.contextWindow(providerName, modelName)
+	if window == 0 {
+		return req, ""
+	}
+
+	tokens := c.countRequestTokens(req)
+	if req.MaxTokens != nil {
+		tokens += *req.MaxTokens
+	}
+	if tokens <= window {
+		return req, ""
+	}
+
+	tried := map[string]bool{req.Model: true}
+	for next, ok := upgrades[req.Model]; ok && !tried[next]; next, ok = upgrades[next] {
+		tried[next] = true
+		nextProvider, nextModel, err := parseModel(next)
+		if err != nil {
+			break
+		}
+		window := c.contextWindow(nextProvider, nextModel)
+		if window > 0 && tokens > window {
+			continue
+		}
+
+		c.warn(ctx, WarningModelUpgraded, fmt.Sprintf("request of about %d tokens does not fit %s; sending it to %s",
+			tokens, req.Model, next))
+		r := *req
+		r.Model = next
+		return &r, req.Model
+	}
+	return req, ""
+}
+
+// contextWindow returns the context window of a model in the model
+// registry, or 0 if it is unknown.
+func (c *client) contextWindow(providerName, modelName string) int {
+	info, err := c.costCalc.GetModelInfo(providerName, modelName)
+	if err != nil {
+		return 0
+	}
+	return info.ContextWindow
+}
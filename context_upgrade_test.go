@@ -0,0 +1,161 @@
+package warp
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/blue-context/warp/callback"
+	"github.com/blue-context/warp/types"
+)
+
+func TestContextWindowUpgrades(t *testing.T) {
+	modelInfo := map[string]*types.ModelInfo{
+		"small":  {Name: "small", ContextWindow: 100},
+		"medium": {Name: "medium", ContextWindow: 1000},
+		"large":  {Name: "large", ContextWindow: 10000},
+	}
+
+	tests := []struct {
+		name      string
+		prompt    int
+		maxTokens *int
+		wantModel string
+	}{
+		{"fits", 100, nil, "small"},
+		{"upgraded", 2000, nil, "medium"},
+		{"upgraded twice", 20000, nil, "large"},
+		{"fits nothing", 100000, nil, "small"},
+		{"output tokens counted", 200, IntPtr(100), "medium"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var warnings []*callback.WarningEvent
+			var successModel string
+			client, err := NewClient(
+				WithContextWindowUpgrades(map[string]string{
+					"mock/small":  "mock/medium",
+					"mock/medium": "mock/large",
+				}),
+				WithWarningCallback(func(ctx context.Context, event *callback.WarningEvent) {
+					warnings = append(warnings, event)
+				}),
+				WithSuccessCallback(func(ctx context.Context, event *callback.SuccessEvent) {
+					successModel = event.Model
+				}),
+			)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer client.Close()
+			var got string
+			client.RegisterProvider(&mockProvider{
+				name:      "mock",
+				modelInfo: modelInfo,
+				completionFunc: func(ctx context.Context, req *CompletionRequest) (*CompletionResponse, error) {
+					got = req.Model
+					return &CompletionResponse{ID: "test"}, nil
+				},
+			})
+
+			req := &CompletionRequest{
+				Model:     "mock/small",
+				Messages:  []Message{{Role: "user", Content: strings.Repeat("a", tt.prompt)}},
+				MaxTokens: tt.maxTokens,
+			}
+			resp, err := client.Completion(context.Background(), req)
+			if err != nil {
+				t.Fatalf("Completion() error = %v", err)
+			}
+			if got != tt.wantModel || successModel != tt.wantModel {
+				t.Errorf("provider model = %q, success model = %q, want %q", got, successModel, tt.wantModel)
+			}
+			if req.Model != "mock/small" {
+				t.Errorf("caller's request model = %q, want mock/small", req.Model)
+			}
+
+			upgraded := tt.wantModel != "small"
+			if from, _ := resp.HiddenParams["_upgraded_from"].(string); (from == "mock/small") != upgraded {
+				t.Errorf("_upgraded_from = %q, upgraded %v", from, upgraded)
+			}
+			if upgraded != (len(warnings) == 1 && warnings[0].Code == WarningModelUpgraded) {
+				t.Errorf("warnings = %+v, upgraded %v", warnings, upgraded)
+			}
+		})
+	}
+}
+
+func TestContextWindowUpgradesMiddleware(t *testing.T) {
+	var seen []string
+	client, err := NewClient(
+		WithContextWindowUpgrades(map[string]string{"mock/small": "mock/large"}),
+		WithRequestMiddleware(func(ctx context.Context, req *CompletionRequest) (*CompletionRequest, error) {
+			seen = append(seen, req.Model)
+			if req.Model == "mock/large" {
+				return nil, errors.New("model banned")
+			}
+			return req, nil
+		}),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+	calls := 0
+	client.RegisterProvider(&mockProvider{
+		name:      "mock",
+		modelInfo: map[string]*types.ModelInfo{"small": {ContextWindow: 100}, "large": {ContextWindow: 10000}},
+		completionFunc: func(ctx context.Context, req *CompletionRequest) (*CompletionResponse, error) {
+			calls++
+			return &CompletionResponse{ID: "test"}, nil
+		},
+	})
+
+	_, err = client.Completion(context.Background(), &CompletionRequest{
+		Model:    "mock/small",
+		Messages: []Message{{Role: "user", Content: strings.Repeat("a", 2000)}},
+	})
+	if err == nil || calls != 0 {
+		t.Errorf("Completion() error = %v after %d calls, want the middleware to refuse the upgraded model", err, calls)
+	}
+	if len(seen) != 1 || seen[0] != "mock/large" {
+		t.Errorf("middleware saw %v, want only the upgraded model", seen)
+	}
+}
+
+func TestContextWindowUpgradesUnknownWindow(t *testing.T) {
+	client, err := NewClient(WithContextWindowUpgrades(map[string]string{"mock/unknown": "mock/other"}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+	var got string
+	client.RegisterProvider(&mockProvider{
+		name: "mock",
+		completionFunc: func(ctx context.Context, req *CompletionRequest) (*CompletionResponse, error) {
+			got = req.Model
+			return &CompletionResponse{ID: "test"}, nil
+		},
+	})
+
+	req := &CompletionRequest{Model: "mock/unknown", Messages: []Message{{Role: "user", Content: strings.Repeat("a", 100000)}}}
+	if _, err := client.Completion(context.Background(), req); err != nil {
+		t.Fatalf("Completion() error = %v", err)
+	}
+	if got != "unknown" {
+		t.Errorf("provider model = %q, want unknown", got)
+	}
+}
+
+func TestWithContextWindowUpgradesValidation(t *testing.T) {
+	for _, upgrades := range []map[string]string{
+		{"gpt-4": "openai/gpt-4-32k"},
+		{"openai/gpt-4": "gpt-4-32k"},
+		{"openai/gpt-4": "openai/gpt-4"},
+	} {
+		if _, err := NewClient(WithContextWindowUpgrades(upgrades)); err == nil {
+			t.Errorf("NewClient(WithContextWindowUpgrades(%v)) error = nil", upgrades)
+		}
+	}
+}
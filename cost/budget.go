@@ -1,18 +1,28 @@
 package cost
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"sync"
 )
 
+// ErrBudgetExceeded is returned by UpdateCost when a cost would exceed the
+// budget limit, and by Check once the limit has been reached.
+var ErrBudgetExceeded = errors.New("budget exceeded")
+
 // BudgetManager manages cost tracking and budget limits.
 //
+// By default all amounts are in USD. A budget created with
+// NewBudgetManagerInCurrency tracks and limits spend in another currency.
+//
 // Thread Safety: BudgetManager is safe for concurrent use.
 type BudgetManager struct {
 	maxBudget   float64
 	currentCost float64
 	costByModel map[string]float64
 	costByUser  map[string]float64
+	converter   *CurrencyConverter
 	mu          sync.RWMutex
 }
 
@@ -27,19 +37,82 @@ func NewBudgetManager(maxBudget float64) *BudgetManager {
 	}
 }
 
+// NewBudgetManagerInCurrency creates a budget manager whose limit and
+// tracked totals are in the converter's currency.
+//
+// Costs passed to UpdateCost are still in USD and are converted on arrival.
+//
+// Example:
+//
+//	conv, _ := cost.NewCurrencyConverter("EUR", cost.StaticRates{"EUR": 0.92}, time.Hour)
+//	budget := cost.NewBudgetManagerInCurrency(50, conv) // 50 EUR limit
+func NewBudgetManagerInCurrency(maxBudget float64, converter *CurrencyConverter) *BudgetManager {
+	b := NewBudgetManager(maxBudget)
+	b.converter = converter
+	return b
+}
+
+// Currency returns the currency the budget is tracked in.
+func (b *BudgetManager) Currency() string {
+	if b.converter == nil {
+		return USD
+	}
+	return b.converter.Currency()
+}
+
 // UpdateCost updates the current cost.
 //
-// Returns error if budget would be exceeded.
+// cost is in USD. Returns an error wrapping ErrBudgetExceeded if the budget
+// would be exceeded, in which case the cost is not recorded, or an error if
+// the cost cannot be converted to the budget currency.
 func (b *BudgetManager) UpdateCost(cost float64, model, user string) error {
+	return b.addCost(cost, model, user, true)
+}
+
+// RecordCost records a cost that has already been incurred.
+//
+// cost is in USD. Unlike UpdateCost, the cost is recorded even if it takes
+// spend past the budget limit, so that Check refuses later requests.
+// Returns an error if the cost cannot be converted to the budget currency.
+func (b *BudgetManager) RecordCost(cost float64, model, user string) error {
+	return b.addCost(cost, model, user, false)
+}
+
+// Check returns an error wrapping ErrBudgetExceeded if spend has reached the
+// budget limit. Call it before incurring a cost.
+func (b *BudgetManager) Check() error {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	if b.maxBudget > 0 && b.currentCost >= b.maxBudget {
+		currency := b.Currency()
+		return fmt.Errorf("%w: current=%s, max=%s", ErrBudgetExceeded,
+			FormatCost(b.currentCost, currency), FormatCost(b.maxBudget, currency))
+	}
+	return nil
+}
+
+// addCost implements UpdateCost and RecordCost. With enforce set, a cost
+// that would exceed the budget limit is refused rather than recorded.
+func (b *BudgetManager) addCost(cost float64, model, user string, enforce bool) error {
+	if b.converter != nil {
+		converted, err := b.converter.Convert(context.Background(), cost)
+		if err != nil {
+			return fmt.Errorf("failed to convert cost: %w", err)
+		}
+		cost = converted
+	}
+
 	b.mu.Lock()
 	defer b.mu.Unlock()
 
 	newCost := b.currentCost + cost
 
 	// Check budget before updating (prevent exceeding)
-	if b.maxBudget > 0 && newCost > b.maxBudget {
-		return fmt.Errorf("budget exceeded: current=$%.4f, max=$%.4f, attempted=$%.4f",
-			b.currentCost, b.maxBudget, cost)
+	if enforce && b.maxBudget > 0 && newCost > b.maxBudget {
+		currency := b.Currency()
+		return fmt.Errorf("%w: current=%s, max=%s, attempted=%s", ErrBudgetExceeded,
+			FormatCost(b.currentCost, currency), FormatCost(b.maxBudget, currency), FormatCost(cost, currency))
 	}
 
 	// Update costs
@@ -52,7 +125,7 @@ func (b *BudgetManager) UpdateCost(cost float64, model, user string) error {
 	return nil
 }
 
-// GetCurrentCost returns the current total cost.
+// GetCurrentCost returns the current total cost in the budget currency.
 func (b *BudgetManager) GetCurrentCost() float64 {
 	b.mu.RLock()
 	defer b.mu.RUnlock()
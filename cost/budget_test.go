@@ -1,6 +1,7 @@
 package cost
 
 import (
+	"errors"
 	"fmt"
 	"strings"
 	"sync"
@@ -351,6 +352,38 @@ func TestBudgetManager_BudgetEnforcement(t *testing.T) {
 	}
 }
 
+func TestBudgetManager_RecordCost(t *testing.T) {
+	bm := NewBudgetManager(10.0)
+
+	if err := bm.Check(); err != nil {
+		t.Fatalf("Check() before spend error = %v", err)
+	}
+
+	// Incurred costs are recorded even past the limit
+	if err := bm.RecordCost(8.0, "gpt-4", "user1"); err != nil {
+		t.Fatalf("RecordCost() error = %v", err)
+	}
+	if err := bm.Check(); err != nil {
+		t.Errorf("Check() under limit error = %v", err)
+	}
+	if err := bm.RecordCost(4.0, "gpt-4", "user1"); err != nil {
+		t.Fatalf("RecordCost() over limit error = %v", err)
+	}
+	if got := bm.GetCurrentCost(); got != 12.0 {
+		t.Errorf("currentCost = %f, want 12", got)
+	}
+
+	err := bm.Check()
+	if !errors.Is(err, ErrBudgetExceeded) {
+		t.Errorf("Check() over limit error = %v, want ErrBudgetExceeded", err)
+	}
+
+	// No limit never refuses
+	if err := NewBudgetManager(0).Check(); err != nil {
+		t.Errorf("Check() without limit error = %v", err)
+	}
+}
+
 func TestBudgetManager_Concurrency(t *testing.T) {
 	bm := NewBudgetManager(0) // No limit for concurrency test
 
@@ -15,6 +15,10 @@ type ProviderGetter interface {
 	Get(name string) (interface{}, error)
 }
 
+// batchServiceTier is the service tier reported by responses served by a
+// provider's batch API.
+const batchServiceTier = "batch"
+
 // CompletionResponse represents a completion response with usage info.
 type CompletionResponse interface {
 	GetModel() string
@@ -34,6 +38,28 @@ type usageInfo interface {
 	GetTotalTokens() int
 }
 
+// cachedTokensInfo is implemented by usage types that report prompt cache reads.
+type cachedTokensInfo interface {
+	GetCachedTokens() int
+}
+
+// cacheWriteTokensInfo is implemented by usage types that report prompt
+// cache writes.
+type cacheWriteTokensInfo interface {
+	GetCacheWriteTokens() int
+}
+
+// reasoningTokensInfo is implemented by usage types that report reasoning tokens.
+type reasoningTokensInfo interface {
+	GetReasoningTokens() int
+}
+
+// serviceTierInfo is implemented by responses that report the service tier
+// that served the request.
+type serviceTierInfo interface {
+	GetServiceTier() string
+}
+
 // Calculator calculates costs for LLM requests and responses.
 //
 // The calculator queries providers for model pricing information and caches
@@ -209,7 +235,32 @@ func (c *Calculator) ClearCache() {
 //
 // Queries the provider for model pricing and calculates the total cost
 // based on token usage. Caches pricing information for performance.
+//
+// If the usage reports cached prompt tokens or reasoning tokens and the model
+// has CachedInputCostPer1M or ReasoningCostPer1M pricing, those tokens are
+// billed at their own rates instead of the standard input/output rates.
+// Prompt tokens written to the cache are billed at CacheWriteCostPer1M
+// likewise.
+//
+// If the response reports the service tier that served it and the model has
+// a ServiceTierMultipliers entry for that tier, the total is scaled by it.
+// Responses from the "batch" tier get the model's BatchDiscount, as with
+// CalculateBatchCompletion.
 func (c *Calculator) CalculateCompletion(resp CompletionResponse) (float64, error) {
+	return c.calculateCompletion(resp, false)
+}
+
+// CalculateBatchCompletion calculates cost for a completion served by a
+// provider's batch tier.
+//
+// Identical to CalculateCompletion, with the model's BatchDiscount applied
+// to the total. Models without a BatchDiscount are billed at standard rates.
+func (c *Calculator) CalculateBatchCompletion(resp CompletionResponse) (float64, error) {
+	return c.calculateCompletion(resp, true)
+}
+
+// calculateCompletion implements CalculateCompletion and CalculateBatchCompletion.
+func (c *Calculator) calculateCompletion(resp CompletionResponse, batch bool) (float64, error) {
 	if resp == nil {
 		return 0, fmt.Errorf("response cannot be nil")
 	}
@@ -234,11 +285,61 @@ func (c *Calculator) CalculateCompletion(resp CompletionResponse) (float64, erro
 		return 0, err
 	}
 
-	// Calculate cost using per-1M pricing
-	inputCost := float64(usage.GetPromptTokens()) / 1_000_000.0 * info.InputCostPer1M
-	outputCost := float64(usage.GetCompletionTokens()) / 1_000_000.0 * info.OutputCostPer1M
+	var cachedTokens, cacheWriteTokens, reasoningTokens int
+	if cached, ok := usageRaw.(cachedTokensInfo); ok {
+		cachedTokens = cached.GetCachedTokens()
+	}
+	if written, ok := usageRaw.(cacheWriteTokensInfo); ok {
+		cacheWriteTokens = written.GetCacheWriteTokens()
+	}
+	if reasoning, ok := usageRaw.(reasoningTokensInfo); ok {
+		reasoningTokens = reasoning.GetReasoningTokens()
+	}
 
-	return inputCost + outputCost, nil
+	total := tokenCost(info, usage.GetPromptTokens(), usage.GetCompletionTokens(), cachedTokens, cacheWriteTokens, reasoningTokens)
+	if tiered, ok := resp.(serviceTierInfo); ok {
+		tier := tiered.GetServiceTier()
+		if multiplier, ok := info.ServiceTierMultipliers[tier]; ok {
+			total *= multiplier
+		}
+		batch = batch || tier == batchServiceTier
+	}
+	if batch && info.BatchDiscount > 0 {
+		total *= 1 - info.BatchDiscount
+	}
+
+	return total, nil
+}
+
+// tokenCost prices a completion's tokens using per-1M pricing.
+//
+// Cached and cache-write tokens are subsets of prompt tokens and reasoning
+// tokens are a subset of completion tokens, matching how OpenAI-compatible
+// APIs report usage. Each subset is billed at its own rate when the model
+// defines one.
+func tokenCost(info *types.ModelInfo, promptTokens, completionTokens, cachedTokens, cacheWriteTokens, reasoningTokens int) float64 {
+	baseTokens := promptTokens
+	var inputCost float64
+	if info.CachedInputCostPer1M > 0 && cachedTokens > 0 {
+		cachedTokens = min(cachedTokens, baseTokens)
+		baseTokens -= cachedTokens
+		inputCost += float64(cachedTokens) / 1_000_000.0 * info.CachedInputCostPer1M
+	}
+	if info.CacheWriteCostPer1M > 0 && cacheWriteTokens > 0 {
+		cacheWriteTokens = min(cacheWriteTokens, baseTokens)
+		baseTokens -= cacheWriteTokens
+		inputCost += float64(cacheWriteTokens) / 1_000_000.0 * info.CacheWriteCostPer1M
+	}
+	inputCost += float64(baseTokens) / 1_000_000.0 * info.InputCostPer1M
+
+	outputCost := float64(completionTokens) / 1_000_000.0 * info.OutputCostPer1M
+	if info.ReasoningCostPer1M > 0 && reasoningTokens > 0 {
+		reasoningTokens = min(reasoningTokens, completionTokens)
+		outputCost = float64(completionTokens-reasoningTokens)/1_000_000.0*info.OutputCostPer1M +
+			float64(reasoningTokens)/1_000_000.0*info.ReasoningCostPer1M
+	}
+
+	return inputCost + outputCost
 }
 
 // CalculateEmbedding calculates cost for an embedding response.
@@ -275,6 +376,96 @@ func (c *Calculator) CalculateEmbedding(resp EmbeddingResponse) (float64, error)
 	return cost, nil
 }
 
+// CalculateImage calculates cost for generated images.
+//
+// Images are priced per image using the model's ImageCostPerImage table.
+// The most specific entry wins: "quality/size", then size, then quality,
+// then the flat "" entry. An empty quality is treated as "standard", and an
+// empty size as the model's DefaultImageSize.
+func (c *Calculator) CalculateImage(providerName, model, size, quality string, images int) (float64, error) {
+	if images < 0 {
+		return 0, fmt.Errorf("image count must be non-negative, got %d", images)
+	}
+
+	info, err := c.GetModelInfo(providerName, model)
+	if err != nil {
+		return 0, err
+	}
+
+	if quality == "" {
+		quality = "standard"
+	}
+	if size == "" {
+		size = info.DefaultImageSize
+	}
+
+	for _, key := range []string{quality + "/" + size, size, quality, ""} {
+		if price, ok := info.ImageCostPerImage[key]; ok {
+			return price * float64(images), nil
+		}
+	}
+
+	return 0, fmt.Errorf("no image pricing for %s/%s (size=%q, quality=%q)", providerName, model, size, quality)
+}
+
+// CalculateSpeech calculates cost for text-to-speech.
+//
+// Speech is billed per input character at the model's SpeechCostPer1MChars price.
+func (c *Calculator) CalculateSpeech(providerName, model string, characters int) (float64, error) {
+	if characters < 0 {
+		return 0, fmt.Errorf("character count must be non-negative, got %d", characters)
+	}
+
+	info, err := c.GetModelInfo(providerName, model)
+	if err != nil {
+		return 0, err
+	}
+	if info.SpeechCostPer1MChars == 0 {
+		return 0, fmt.Errorf("no speech pricing for %s/%s", providerName, model)
+	}
+
+	return float64(characters) / 1_000_000.0 * info.SpeechCostPer1MChars, nil
+}
+
+// CalculateTranscription calculates cost for speech-to-text.
+//
+// Transcription is billed per minute of audio at the model's
+// TranscriptionCostPerMinute price, prorated to the second.
+func (c *Calculator) CalculateTranscription(providerName, model string, duration time.Duration) (float64, error) {
+	if duration < 0 {
+		return 0, fmt.Errorf("duration must be non-negative, got %v", duration)
+	}
+
+	info, err := c.GetModelInfo(providerName, model)
+	if err != nil {
+		return 0, err
+	}
+	if info.TranscriptionCostPerMinute == 0 {
+		return 0, fmt.Errorf("no transcription pricing for %s/%s", providerName, model)
+	}
+
+	return duration.Minutes() * info.TranscriptionCostPerMinute, nil
+}
+
+// CalculateCacheStorage calculates the cost of storing tokens of
+// explicitly cached content for duration, at the model's
+// CacheStorageCostPer1MHour price, prorated to the second.
+func (c *Calculator) CalculateCacheStorage(providerName, model string, tokens int, duration time.Duration) (float64, error) {
+	if tokens < 0 || duration < 0 {
+		return 0, fmt.Errorf("tokens and duration must be non-negative, got %d and %v", tokens, duration)
+	}
+
+	info, err := c.GetModelInfo(providerName, model)
+	if err != nil {
+		return 0, err
+	}
+	if info.CacheStorageCostPer1MHour == 0 {
+		return 0, fmt.Errorf("no cache storage pricing for %s/%s", providerName, model)
+	}
+
+	return float64(tokens) / 1_000_000.0 * duration.Hours() * info.CacheStorageCostPer1MHour, nil
+}
+
 // EstimateCost estimates cost before sending request.
 //
 // Useful for budget management and displaying cost estimates to users.
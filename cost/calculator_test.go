@@ -14,19 +14,25 @@ type mockUsage struct {
 	promptTokens     int
 	completionTokens int
 	totalTokens      int
+	cachedTokens     int
+	reasoningTokens  int
 }
 
 func (m *mockUsage) GetPromptTokens() int     { return m.promptTokens }
 func (m *mockUsage) GetCompletionTokens() int { return m.completionTokens }
 func (m *mockUsage) GetTotalTokens() int      { return m.totalTokens }
+func (m *mockUsage) GetCachedTokens() int     { return m.cachedTokens }
+func (m *mockUsage) GetReasoningTokens() int  { return m.reasoningTokens }
 
 // mockCompletionResponse implements CompletionResponse for testing
 type mockCompletionResponse struct {
 	model string
 	usage *mockUsage
+	tier  string
 }
 
-func (m *mockCompletionResponse) GetModel() string { return m.model }
+func (m *mockCompletionResponse) GetModel() string       { return m.model }
+func (m *mockCompletionResponse) GetServiceTier() string { return m.tier }
 func (m *mockCompletionResponse) GetUsageInfo() interface{} {
 	if m.usage == nil {
 		return nil
@@ -899,3 +905,220 @@ func TestCalculatorConcurrentCacheAccess(t *testing.T) {
 		t.Error("Expected model info, got nil")
 	}
 }
+
+func TestCalculateCompletionDiscountedTokens(t *testing.T) {
+	calc := NewCalculator(newMockRegistry())
+	calc.AddPricingOverride("test", "priced", &types.ModelInfo{
+		Name:                 "priced",
+		Provider:             "test",
+		InputCostPer1M:       10.0,
+		OutputCostPer1M:      40.0,
+		CachedInputCostPer1M: 1.0,
+		ReasoningCostPer1M:   60.0,
+		BatchDiscount:        0.5,
+		ServiceTierMultipliers: map[string]float64{
+			"flex":     0.5,
+			"priority": 1.75,
+		},
+	})
+	calc.AddPricingOverride("test", "plain", &types.ModelInfo{
+		Name:            "plain",
+		Provider:        "test",
+		InputCostPer1M:  10.0,
+		OutputCostPer1M: 40.0,
+	})
+
+	tests := []struct {
+		name  string
+		model string
+		usage *mockUsage
+		tier  string
+		batch bool
+		want  float64
+	}{
+		{
+			name:  "cached prompt tokens",
+			model: "test/priced",
+			usage: &mockUsage{promptTokens: 1_000_000, cachedTokens: 500_000},
+			want:  5.0 + 0.5,
+		},
+		{
+			name:  "reasoning tokens",
+			model: "test/priced",
+			usage: &mockUsage{completionTokens: 1_000_000, reasoningTokens: 250_000},
+			want:  30.0 + 15.0,
+		},
+		{
+			name:  "cached tokens capped at prompt tokens",
+			model: "test/priced",
+			usage: &mockUsage{promptTokens: 100_000, cachedTokens: 200_000},
+			want:  0.1,
+		},
+		{
+			name:  "batch discount",
+			model: "test/priced",
+			usage: &mockUsage{promptTokens: 1_000_000, completionTokens: 1_000_000},
+			batch: true,
+			want:  25.0,
+		},
+		{
+			name:  "batch tier",
+			model: "test/priced",
+			usage: &mockUsage{promptTokens: 1_000_000, completionTokens: 1_000_000},
+			tier:  "batch",
+			want:  25.0,
+		},
+		{
+			name:  "flex tier",
+			model: "test/priced",
+			usage: &mockUsage{promptTokens: 1_000_000, completionTokens: 1_000_000},
+			tier:  "flex",
+			want:  25.0,
+		},
+		{
+			name:  "priority tier",
+			model: "test/priced",
+			usage: &mockUsage{promptTokens: 1_000_000},
+			tier:  "priority",
+			want:  17.5,
+		},
+		{
+			name:  "tier without multiplier",
+			model: "test/plain",
+			usage: &mockUsage{promptTokens: 1_000_000},
+			tier:  "priority",
+			want:  10.0,
+		},
+		{
+			name:  "no special pricing",
+			model: "test/plain",
+			usage: &mockUsage{promptTokens: 1_000_000, completionTokens: 1_000_000, cachedTokens: 500_000, reasoningTokens: 500_000},
+			batch: true,
+			want:  50.0,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp := &mockCompletionResponse{model: tt.model, usage: tt.usage, tier: tt.tier}
+
+			var got float64
+			var err error
+			if tt.batch {
+				got, err = calc.CalculateBatchCompletion(resp)
+			} else {
+				got, err = calc.CalculateCompletion(resp)
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if diff := got - tt.want; diff > 1e-9 || diff < -1e-9 {
+				t.Errorf("cost = %f, want %f", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCalculateImage(t *testing.T) {
+	calc := NewCalculator(newMockRegistry())
+	calc.AddPricingOverride("test", "image-model", &types.ModelInfo{
+		Name:     "image-model",
+		Provider: "test",
+		ImageCostPerImage: map[string]float64{
+			"hd/1024x1024": 0.08,
+			"1024x1024":    0.04,
+			"hd":           0.10,
+		},
+		DefaultImageSize: "1024x1024",
+	})
+	calc.AddPricingOverride("test", "flat", &types.ModelInfo{
+		Name:              "flat",
+		ImageCostPerImage: map[string]float64{"": 0.01},
+	})
+
+	tests := []struct {
+		name    string
+		model   string
+		size    string
+		quality string
+		images  int
+		want    float64
+		wantErr bool
+	}{
+		{name: "quality and size", model: "image-model", size: "1024x1024", quality: "hd", images: 2, want: 0.16},
+		{name: "size only", model: "image-model", size: "1024x1024", images: 1, want: 0.04},
+		{name: "quality only", model: "image-model", size: "512x512", quality: "hd", images: 1, want: 0.10},
+		{name: "default size", model: "image-model", images: 1, want: 0.04},
+		{name: "flat price", model: "flat", size: "256x256", images: 3, want: 0.03},
+		{name: "no matching price", model: "image-model", size: "512x512", images: 1, wantErr: true},
+		{name: "negative count", model: "flat", images: -1, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := calc.CalculateImage("test", tt.model, tt.size, tt.quality, tt.images)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("CalculateImage() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if diff := got - tt.want; diff > 1e-9 || diff < -1e-9 {
+				t.Errorf("CalculateImage() = %f, want %f", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCalculateAudio(t *testing.T) {
+	calc := NewCalculator(newMockRegistry())
+	calc.AddPricingOverride("test", "tts", &types.ModelInfo{Name: "tts", SpeechCostPer1MChars: 15.0})
+	calc.AddPricingOverride("test", "stt", &types.ModelInfo{Name: "stt", TranscriptionCostPerMinute: 0.006})
+	calc.AddPricingOverride("test", "plain", &types.ModelInfo{Name: "plain", InputCostPer1M: 1.0})
+
+	t.Run("speech", func(t *testing.T) {
+		got, err := calc.CalculateSpeech("test", "tts", 2000)
+		if err != nil {
+			t.Fatalf("CalculateSpeech() error = %v", err)
+		}
+		if diff := got - 0.03; diff > 1e-9 || diff < -1e-9 {
+			t.Errorf("CalculateSpeech() = %f, want 0.03", got)
+		}
+		if _, err := calc.CalculateSpeech("test", "plain", 10); err == nil {
+			t.Error("CalculateSpeech() expected error without speech pricing")
+		}
+		if _, err := calc.CalculateSpeech("test", "tts", -1); err == nil {
+			t.Error("CalculateSpeech() expected error for negative count")
+		}
+	})
+
+	t.Run("transcription", func(t *testing.T) {
+		got, err := calc.CalculateTranscription("test", "stt", 90*time.Second)
+		if err != nil {
+			t.Fatalf("CalculateTranscription() error = %v", err)
+		}
+		if diff := got - 0.009; diff > 1e-9 || diff < -1e-9 {
+			t.Errorf("CalculateTranscription() = %f, want 0.009", got)
+		}
+		if _, err := calc.CalculateTranscription("test", "plain", time.Minute); err == nil {
+			t.Error("CalculateTranscription() expected error without transcription pricing")
+		}
+	})
+}
+
+func TestCalculateCacheStorage(t *testing.T) {
+	calc := NewCalculator(newMockRegistry())
+	calc.AddPricingOverride("test", "cached", &types.ModelInfo{Name: "cached", CacheStorageCostPer1MHour: 4.5})
+	calc.AddPricingOverride("test", "plain", &types.ModelInfo{Name: "plain", InputCostPer1M: 1.0})
+
+	got, err := calc.CalculateCacheStorage("test", "cached", 100000, 30*time.Minute)
+	if err != nil {
+		t.Fatalf("CalculateCacheStorage() error = %v", err)
+	}
+	if diff := got - 0.225; diff > 1e-9 || diff < -1e-9 {
+		t.Errorf("CalculateCacheStorage() = %f, want 0.225", got)
+	}
+	if _, err := calc.CalculateCacheStorage("test", "plain", 100000, time.Hour); err == nil {
+		t.Error("CalculateCacheStorage() expected error without storage pricing")
+	}
+	if _, err := calc.CalculateCacheStorage("test", "cached", -1, time.Hour); err == nil {
+		t.Error("CalculateCacheStorage() expected error for negative tokens")
+	}
+}
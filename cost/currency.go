@@ -0,0 +1,197 @@
+package cost
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+)
+
+// USD is the currency all provider pricing is expressed in.
+const USD = "USD"
+
+// RateSource supplies exchange rates for cost conversion.
+//
+// Rates returns the number of units of each currency per 1 USD, keyed by
+// ISO 4217 code (e.g., {"EUR": 0.92, "JPY": 151.3}).
+//
+// Implementations may call an external API; results are cached by
+// CurrencyConverter, so Rates is only called on refresh.
+//
+// Thread Safety: Implementations must be safe for concurrent use.
+type RateSource interface {
+	Rates(ctx context.Context) (map[string]float64, error)
+}
+
+// StaticRates is a RateSource backed by fixed exchange rates.
+//
+// Example:
+//
+//	rates := cost.StaticRates{"EUR": 0.92, "GBP": 0.79}
+type StaticRates map[string]float64
+
+// Rates returns a copy of the static rates.
+func (s StaticRates) Rates(ctx context.Context) (map[string]float64, error) {
+	rates := make(map[string]float64, len(s))
+	for k, v := range s {
+		rates[strings.ToUpper(k)] = v
+	}
+	return rates, nil
+}
+
+// CurrencyConverter converts USD costs into a display currency.
+//
+// Rates are fetched from the RateSource on first use and cached for the
+// configured TTL. Call Refresh to force an update.
+//
+// Thread Safety: CurrencyConverter is safe for concurrent use.
+type CurrencyConverter struct {
+	currency  string
+	source    RateSource
+	ttl       time.Duration
+	rate      float64
+	fetchedAt time.Time
+	mu        sync.RWMutex
+}
+
+// NewCurrencyConverter creates a converter from USD to currency.
+//
+// A ttl of 0 caches rates until Refresh is called.
+// Returns an error if currency is empty or source is nil for a non-USD currency.
+//
+// Example:
+//
+//	conv, err := cost.NewCurrencyConverter("EUR", cost.StaticRates{"EUR": 0.92}, time.Hour)
+func NewCurrencyConverter(currency string, source RateSource, ttl time.Duration) (*CurrencyConverter, error) {
+	currency = strings.ToUpper(strings.TrimSpace(currency))
+	if currency == "" {
+		return nil, fmt.Errorf("currency cannot be empty")
+	}
+	if currency != USD && source == nil {
+		return nil, fmt.Errorf("rate source required for currency %s", currency)
+	}
+	if ttl < 0 {
+		return nil, fmt.Errorf("rate TTL must be non-negative, got %v", ttl)
+	}
+
+	return &CurrencyConverter{
+		currency: currency,
+		source:   source,
+		ttl:      ttl,
+	}, nil
+}
+
+// Currency returns the ISO 4217 code costs are converted to.
+func (c *CurrencyConverter) Currency() string {
+	return c.currency
+}
+
+// Refresh fetches the current exchange rate from the rate source.
+//
+// On error, the previously cached rate (if any) is kept.
+func (c *CurrencyConverter) Refresh(ctx context.Context) error {
+	if c.currency == USD {
+		return nil
+	}
+
+	rates, err := c.source.Rates(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to fetch exchange rates: %w", err)
+	}
+
+	rate, ok := rates[c.currency]
+	if !ok || rate <= 0 {
+		return fmt.Errorf("no exchange rate for %s", c.currency)
+	}
+
+	c.mu.Lock()
+	c.rate = rate
+	c.fetchedAt = time.Now()
+	c.mu.Unlock()
+
+	return nil
+}
+
+// Rate returns the cached exchange rate, refreshing it if missing or stale.
+//
+// If a refresh fails but a previously fetched rate exists, the stale rate is
+// returned so cost reporting keeps working during rate source outages.
+func (c *CurrencyConverter) Rate(ctx context.Context) (float64, error) {
+	if c.currency == USD {
+		return 1, nil
+	}
+
+	c.mu.RLock()
+	rate, fetchedAt := c.rate, c.fetchedAt
+	c.mu.RUnlock()
+
+	if rate > 0 && (c.ttl == 0 || time.Since(fetchedAt) < c.ttl) {
+		return rate, nil
+	}
+
+	if err := c.Refresh(ctx); err != nil {
+		if rate > 0 {
+			return rate, nil
+		}
+		return 0, err
+	}
+
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.rate, nil
+}
+
+// Convert converts a USD amount to the converter's currency.
+//
+// Example:
+//
+//	eur, err := conv.Convert(ctx, 0.0125)
+func (c *CurrencyConverter) Convert(ctx context.Context, usd float64) (float64, error) {
+	rate, err := c.Rate(ctx)
+	if err != nil {
+		return 0, err
+	}
+	return usd * rate, nil
+}
+
+// Format formats an amount in the converter's currency for display.
+func (c *CurrencyConverter) Format(amount float64) string {
+	return FormatCost(amount, c.currency)
+}
+
+// currencySymbols maps common currencies to their display symbol.
+var currencySymbols = map[string]string{
+	"USD": "$",
+	"EUR": "€",
+	"GBP": "£",
+	"JPY": "JP¥",
+	"CNY": "CN¥",
+	"INR": "₹",
+	"KRW": "₩",
+}
+
+// zeroDecimalCurrencies lists currencies without minor units.
+var zeroDecimalCurrencies = map[string]bool{
+	"JPY": true,
+	"KRW": true,
+}
+
+// FormatCost formats a cost for display in the given currency.
+//
+// Known currencies use their symbol (e.g., "€0.0125"); others are suffixed
+// with their code (e.g., "0.0125 CHF"). Costs are shown with four decimal
+// places, or two for currencies without minor units such as JPY.
+func FormatCost(amount float64, currency string) string {
+	currency = strings.ToUpper(currency)
+
+	decimals := 4
+	if zeroDecimalCurrencies[currency] {
+		decimals = 2
+	}
+
+	if symbol, ok := currencySymbols[currency]; ok {
+		return fmt.Sprintf("%s%.*f", symbol, decimals, amount)
+	}
+	return fmt.Sprintf("%.*f %s", decimals, amount, currency)
+}
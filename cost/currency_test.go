@@ -0,0 +1,155 @@
+package cost
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+)
+
+// countingRates is a RateSource that counts fetches and can be made to fail.
+type countingRates struct {
+	rates map[string]float64
+	err   error
+	calls int
+}
+
+func (c *countingRates) Rates(ctx context.Context) (map[string]float64, error) {
+	c.calls++
+	if c.err != nil {
+		return nil, c.err
+	}
+	return c.rates, nil
+}
+
+func TestNewCurrencyConverter(t *testing.T) {
+	tests := []struct {
+		name     string
+		currency string
+		source   RateSource
+		ttl      time.Duration
+		wantErr  bool
+	}{
+		{name: "valid", currency: "eur", source: StaticRates{"EUR": 0.9}, ttl: time.Hour},
+		{name: "usd without source", currency: "USD"},
+		{name: "empty currency", currency: " ", source: StaticRates{}, wantErr: true},
+		{name: "missing source", currency: "EUR", wantErr: true},
+		{name: "negative ttl", currency: "EUR", source: StaticRates{}, ttl: -time.Second, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewCurrencyConverter(tt.currency, tt.source, tt.ttl)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("NewCurrencyConverter() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestCurrencyConverterConvert(t *testing.T) {
+	ctx := context.Background()
+	source := &countingRates{rates: map[string]float64{"EUR": 0.5}}
+
+	conv, err := NewCurrencyConverter("eur", source, time.Hour)
+	if err != nil {
+		t.Fatalf("NewCurrencyConverter() error = %v", err)
+	}
+	if conv.Currency() != "EUR" {
+		t.Errorf("Currency() = %q, want EUR", conv.Currency())
+	}
+
+	for i := 0; i < 3; i++ {
+		got, err := conv.Convert(ctx, 2.0)
+		if err != nil {
+			t.Fatalf("Convert() error = %v", err)
+		}
+		if got != 1.0 {
+			t.Errorf("Convert(2.0) = %f, want 1.0", got)
+		}
+	}
+	if source.calls != 1 {
+		t.Errorf("rate source calls = %d, want 1 (rates cached)", source.calls)
+	}
+
+	// Refresh picks up new rates
+	source.rates = map[string]float64{"EUR": 0.25}
+	if err := conv.Refresh(ctx); err != nil {
+		t.Fatalf("Refresh() error = %v", err)
+	}
+	if got, _ := conv.Convert(ctx, 2.0); got != 0.5 {
+		t.Errorf("Convert(2.0) after refresh = %f, want 0.5", got)
+	}
+
+	// Failed refresh keeps the previous rate
+	source.err = fmt.Errorf("rate API down")
+	if err := conv.Refresh(ctx); err == nil {
+		t.Error("Refresh() expected error")
+	}
+	if got, err := conv.Convert(ctx, 2.0); err != nil || got != 0.5 {
+		t.Errorf("Convert(2.0) after failed refresh = %f, %v; want 0.5, nil", got, err)
+	}
+}
+
+func TestCurrencyConverterMissingRate(t *testing.T) {
+	conv, err := NewCurrencyConverter("GBP", StaticRates{"EUR": 0.9}, 0)
+	if err != nil {
+		t.Fatalf("NewCurrencyConverter() error = %v", err)
+	}
+	if _, err := conv.Convert(context.Background(), 1.0); err == nil {
+		t.Error("Convert() expected error for missing rate")
+	}
+}
+
+func TestFormatCost(t *testing.T) {
+	tests := []struct {
+		amount   float64
+		currency string
+		want     string
+	}{
+		{1.5, "USD", "$1.5000"},
+		{0.0125, "eur", "€0.0125"},
+		{2, "GBP", "£2.0000"},
+		{151.333, "JPY", "JP¥151.33"},
+		{0.5, "CNY", "CN¥0.5000"},
+		{3.2, "CHF", "3.2000 CHF"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.currency, func(t *testing.T) {
+			if got := FormatCost(tt.amount, tt.currency); got != tt.want {
+				t.Errorf("FormatCost(%v, %q) = %q, want %q", tt.amount, tt.currency, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBudgetManagerInCurrency(t *testing.T) {
+	conv, err := NewCurrencyConverter("EUR", StaticRates{"EUR": 0.5}, 0)
+	if err != nil {
+		t.Fatalf("NewCurrencyConverter() error = %v", err)
+	}
+
+	budget := NewBudgetManagerInCurrency(1.0, conv)
+	if budget.Currency() != "EUR" {
+		t.Errorf("Currency() = %q, want EUR", budget.Currency())
+	}
+
+	// $1.60 = €0.80
+	if err := budget.UpdateCost(1.6, "gpt-4", ""); err != nil {
+		t.Fatalf("UpdateCost() error = %v", err)
+	}
+	if got := budget.GetCurrentCost(); got != 0.8 {
+		t.Errorf("GetCurrentCost() = %f, want 0.8", got)
+	}
+
+	// $1.00 = €0.50 would exceed the €1 limit
+	err = budget.UpdateCost(1.0, "gpt-4", "")
+	if err == nil {
+		t.Fatal("UpdateCost() expected budget error")
+	}
+	if !strings.Contains(err.Error(), "€") {
+		t.Errorf("error %q should format amounts in EUR", err)
+	}
+}
@@ -0,0 +1,153 @@
+package cost
+
+import (
+	"fmt"
+
+	"github.com/blue-context/warp/types"
+)
+
+// PricingOption configures optional pricing on a custom model registration.
+type PricingOption func(*types.ModelInfo)
+
+// WithCachedInputPrice sets the price per 1M cached input tokens (USD).
+func WithCachedInputPrice(per1M float64) PricingOption {
+	return func(info *types.ModelInfo) {
+		info.CachedInputCostPer1M = per1M
+	}
+}
+
+// WithCacheWritePrice sets the price per 1M input tokens written to the
+// prompt cache (USD), as charged by Anthropic.
+func WithCacheWritePrice(per1M float64) PricingOption {
+	return func(info *types.ModelInfo) {
+		info.CacheWriteCostPer1M = per1M
+	}
+}
+
+// WithReasoningPrice sets the price per 1M reasoning tokens (USD).
+func WithReasoningPrice(per1M float64) PricingOption {
+	return func(info *types.ModelInfo) {
+		info.ReasoningCostPer1M = per1M
+	}
+}
+
+// WithCacheStoragePrice sets the price per 1M tokens of explicitly cached
+// content stored per hour (USD), as charged for Gemini context caching.
+func WithCacheStoragePrice(per1MHour float64) PricingOption {
+	return func(info *types.ModelInfo) {
+		info.CacheStorageCostPer1MHour = per1MHour
+	}
+}
+
+// WithBatchDiscount sets the fractional discount for batch-tier requests
+// (e.g., 0.5 for 50% off).
+func WithBatchDiscount(discount float64) PricingOption {
+	return func(info *types.ModelInfo) {
+		info.BatchDiscount = discount
+	}
+}
+
+// WithServiceTierMultiplier sets the token cost multiplier for requests
+// served by a service tier (e.g., "flex" at 0.5, "priority" at 1.75).
+func WithServiceTierMultiplier(tier string, multiplier float64) PricingOption {
+	return func(info *types.ModelInfo) {
+		if info.ServiceTierMultipliers == nil {
+			info.ServiceTierMultipliers = make(map[string]float64)
+		}
+		info.ServiceTierMultipliers[tier] = multiplier
+	}
+}
+
+// WithImagePrice sets the per-image price (USD) for a size/quality key.
+//
+// The key uses the same format as ModelInfo.ImageCostPerImage: "quality/size",
+// size alone, or "" for a flat price.
+func WithImagePrice(key string, perImage float64) PricingOption {
+	return func(info *types.ModelInfo) {
+		if info.ImageCostPerImage == nil {
+			info.ImageCostPerImage = make(map[string]float64)
+		}
+		info.ImageCostPerImage[key] = perImage
+	}
+}
+
+// WithSpeechPrice sets the text-to-speech price per 1M input characters (USD).
+func WithSpeechPrice(per1MChars float64) PricingOption {
+	return func(info *types.ModelInfo) {
+		info.SpeechCostPer1MChars = per1MChars
+	}
+}
+
+// WithTranscriptionPrice sets the speech-to-text price per minute of audio (USD).
+func WithTranscriptionPrice(perMinute float64) PricingOption {
+	return func(info *types.ModelInfo) {
+		info.TranscriptionCostPerMinute = perMinute
+	}
+}
+
+// WithContextWindow records the model's context window size.
+func WithContextWindow(tokens int) PricingOption {
+	return func(info *types.ModelInfo) {
+		info.ContextWindow = tokens
+	}
+}
+
+// NewModelPricing builds a ModelInfo suitable for AddPricingOverride.
+//
+// Returns an error if any price is negative or the batch discount is outside [0, 1].
+//
+// Example:
+//
+//	info, err := cost.NewModelPricing("vllm", "llama-3-70b", 0.40, 0.40)
+//	calc.AddPricingOverride("vllm", "llama-3-70b", info)
+func NewModelPricing(providerName, model string, inputPer1M, outputPer1M float64, opts ...PricingOption) (*types.ModelInfo, error) {
+	info := &types.ModelInfo{
+		Name:            model,
+		Provider:        providerName,
+		InputCostPer1M:  inputPer1M,
+		OutputCostPer1M: outputPer1M,
+	}
+	for _, opt := range opts {
+		opt(info)
+	}
+
+	if info.InputCostPer1M < 0 || info.OutputCostPer1M < 0 ||
+		info.CachedInputCostPer1M < 0 || info.CacheWriteCostPer1M < 0 || info.ReasoningCostPer1M < 0 || info.CacheStorageCostPer1MHour < 0 ||
+		info.SpeechCostPer1MChars < 0 || info.TranscriptionCostPerMinute < 0 {
+		return nil, fmt.Errorf("pricing for %s/%s must be non-negative", providerName, model)
+	}
+	for key, price := range info.ImageCostPerImage {
+		if price < 0 {
+			return nil, fmt.Errorf("image price for %q must be non-negative", key)
+		}
+	}
+	for tier, multiplier := range info.ServiceTierMultipliers {
+		if multiplier < 0 {
+			return nil, fmt.Errorf("service tier multiplier for %q must be non-negative", tier)
+		}
+	}
+	if info.BatchDiscount < 0 || info.BatchDiscount > 1 {
+		return nil, fmt.Errorf("batch discount must be between 0 and 1, got %f", info.BatchDiscount)
+	}
+
+	return info, nil
+}
+
+// GPUAmortizedPer1M converts the hourly cost of self-hosted hardware into a
+// per-1M-token price.
+//
+// tokensPerSecond is the sustained throughput of the deployment. Returns 0 if
+// throughput is not positive.
+//
+// Example:
+//
+//	// $2.50/hour GPU serving 1,500 tokens/second
+//	price := cost.GPUAmortizedPer1M(2.50, 1500)
+//	client.RegisterModelPricing("vllm/llama-3-70b", price, price)
+func GPUAmortizedPer1M(hourlyCost, tokensPerSecond float64) float64 {
+	if tokensPerSecond <= 0 {
+		return 0
+	}
+	tokensPerHour := tokensPerSecond * 3600
+	return hourlyCost / tokensPerHour * 1_000_000
+}
@@ -0,0 +1,57 @@
+package cost
+
+import (
+	"math"
+	"testing"
+)
+
+func TestNewModelPricing(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   float64
+		output  float64
+		opts    []PricingOption
+		wantErr bool
+	}{
+		{name: "basic", input: 1, output: 2},
+		{
+			name:   "with options",
+			input:  1,
+			output: 2,
+			opts:   []PricingOption{WithCachedInputPrice(0.5), WithReasoningPrice(3), WithBatchDiscount(0.5), WithContextWindow(8192)},
+		},
+		{name: "negative input", input: -1, output: 2, wantErr: true},
+		{name: "negative cached", input: 1, output: 2, opts: []PricingOption{WithCachedInputPrice(-1)}, wantErr: true},
+		{name: "discount too large", input: 1, output: 2, opts: []PricingOption{WithBatchDiscount(1.5)}, wantErr: true},
+		{name: "service tier", input: 1, output: 2, opts: []PricingOption{WithServiceTierMultiplier("flex", 0.5)}},
+		{name: "negative service tier", input: 1, output: 2, opts: []PricingOption{WithServiceTierMultiplier("flex", -1)}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			info, err := NewModelPricing("custom", "model", tt.input, tt.output, tt.opts...)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("NewModelPricing() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if info.Provider != "custom" || info.Name != "model" {
+				t.Errorf("NewModelPricing() = %s/%s, want custom/model", info.Provider, info.Name)
+			}
+			if info.InputCostPer1M != tt.input || info.OutputCostPer1M != tt.output {
+				t.Errorf("prices = %f/%f, want %f/%f", info.InputCostPer1M, info.OutputCostPer1M, tt.input, tt.output)
+			}
+		})
+	}
+}
+
+func TestGPUAmortizedPer1M(t *testing.T) {
+	// $3.60/hour at 1,000 tokens/second = 3.6M tokens/hour = $1 per 1M
+	if got := GPUAmortizedPer1M(3.60, 1000); math.Abs(got-1.0) > 1e-9 {
+		t.Errorf("GPUAmortizedPer1M(3.60, 1000) = %f, want 1.0", got)
+	}
+	if got := GPUAmortizedPer1M(3.60, 0); got != 0 {
+		t.Errorf("GPUAmortizedPer1M(3.60, 0) = %f, want 0", got)
+	}
+}
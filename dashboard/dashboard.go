@@ -0,0 +1,465 @@
+// Package dashboard provides an embedded HTML dashboard of live client
+// statistics for local development and small deployments.
+//
+// A Dashboard collects request counts, token usage, cost, latency and
+// error rates per model from client callbacks, reads cache effectiveness
+// from the response cache, reports the provider prompt cache hit rate of
+// each CompletionRequest.PromptCacheKey, and records routing decisions (region
+// failovers, upstream provider selection, service tier spillover and other
+// client warnings). It is an http.Handler that renders the statistics as a
+// self-refreshing HTML page, or as JSON when requested with ?format=json.
+//
+// Basic usage:
+//
+//	responseCache := cache.NewMemoryCache(100 * 1024 * 1024)
+//	dash := dashboard.New(dashboard.WithCache(responseCache))
+//	client, err := warp.NewClient(append(dash.ClientOptions(),
+//	    warp.WithCache(responseCache),
+//	)...)
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+//
+//	http.Handle("/debug/warp", dash)
+//	log.Fatal(http.ListenAndServe("localhost:8080", nil))
+//
+// The dashboard holds statistics in memory since it was created and is
+// not intended as a replacement for a metrics system.
+package dashboard
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/blue-context/warp"
+	"github.com/blue-context/warp/cache"
+	"github.com/blue-context/warp/callback"
+)
+
+// routingFields are response provider fields that record where a request
+// was routed, keyed by field name with the decision kind as value.
+var routingFields = map[string]string{
+	"region":   "region",   // multiregion failover
+	"provider": "upstream", // OpenRouter upstream selection
+}
+
+// ModelStats are the statistics of one provider/model pair.
+type ModelStats struct {
+	// Provider is the provider name.
+	Provider string `json:"provider"`
+
+	// Model is the model name (without provider prefix).
+	Model string `json:"model"`
+
+	// Requests is the number of completed requests, successful or not.
+	Requests int64 `json:"requests"`
+
+	// Errors is the number of failed requests.
+	Errors int64 `json:"errors"`
+
+	// PromptTokens is the number of prompt tokens used.
+	PromptTokens int64 `json:"prompt_tokens"`
+
+	// CompletionTokens is the number of completion tokens used.
+	CompletionTokens int64 `json:"completion_tokens"`
+
+	// Tokens is the total number of tokens used.
+	Tokens int64 `json:"tokens"`
+
+	// Cost is the estimated cost in USD.
+	Cost float64 `json:"cost"`
+
+	// TotalDuration is the summed duration of all requests.
+	TotalDuration time.Duration `json:"total_duration"`
+}
+
+// ErrorRate returns the fraction of requests that failed (0 if none).
+func (m ModelStats) ErrorRate() float64 {
+	if m.Requests == 0 {
+		return 0
+	}
+	return float64(m.Errors) / float64(m.Requests)
+}
+
+// AvgLatency returns the mean request duration (0 if none).
+func (m ModelStats) AvgLatency() time.Duration {
+	if m.Requests == 0 {
+		return 0
+	}
+	return m.TotalDuration / time.Duration(m.Requests)
+}
+
+// PromptCacheStats are the provider prompt cache statistics of the
+// successful requests with one CompletionRequest.PromptCacheKey, for tuning
+// prompt layout so requests share a longer cached prefix.
+type PromptCacheStats struct {
+	// Key is the prompt cache key.
+	Key string `json:"key"`
+
+	// Requests is the number of successful requests with the key.
+	Requests int64 `json:"requests"`
+
+	// PromptTokens is the number of prompt tokens used.
+	PromptTokens int64 `json:"prompt_tokens"`
+
+	// CachedTokens is the number of prompt tokens read from the provider's
+	// prompt cache.
+	CachedTokens int64 `json:"cached_tokens"`
+
+	// HitRate is the fraction of prompt tokens read from the cache.
+	HitRate float64 `json:"hit_rate"`
+}
+
+// Decision is a routing decision or client adjustment made for a request.
+type Decision struct {
+	// Time is when the decision was observed.
+	Time time.Time `json:"time"`
+
+	// RequestID identifies the request.
+	RequestID string `json:"request_id,omitempty"`
+
+	// Provider and Model identify the requested model.
+	Provider string `json:"provider"`
+	Model    string `json:"model"`
+
+	// Kind is the decision kind: "region", "upstream", or a warning code
+	// such as "service_tier_spillover".
+	Kind string `json:"kind"`
+
+	// Detail is the chosen region or upstream, or the warning message.
+	Detail string `json:"detail"`
+}
+
+// DecisionCount is the number of decisions of one kind and detail.
+type DecisionCount struct {
+	Kind   string `json:"kind"`
+	Detail string `json:"detail"`
+	Count  int64  `json:"count"`
+}
+
+// Snapshot is a point-in-time copy of the dashboard statistics.
+type Snapshot struct {
+	// Started is when the dashboard was created.
+	Started time.Time `json:"started"`
+
+	// Uptime is the time since Started.
+	Uptime time.Duration `json:"uptime"`
+
+	// Requests, Errors, Tokens and Cost are totals across all models.
+	Requests int64   `json:"requests"`
+	Errors   int64   `json:"errors"`
+	Tokens   int64   `json:"tokens"`
+	Cost     float64 `json:"cost"`
+
+	// ErrorRate is the fraction of all requests that failed.
+	ErrorRate float64 `json:"error_rate"`
+
+	// Models are the per-model statistics, by descending request count.
+	Models []ModelStats `json:"models"`
+
+	// Cache is the response cache statistics (nil without a cache).
+	Cache *cache.Stats `json:"cache,omitempty"`
+
+	// CacheHitRate is the response cache hit rate.
+	CacheHitRate float64 `json:"cache_hit_rate"`
+
+	// PromptCache are the prompt cache statistics per prompt cache key,
+	// by descending request count.
+	PromptCache []PromptCacheStats `json:"prompt_cache"`
+
+	// Decisions counts routing decisions by kind and detail, by
+	// descending count.
+	Decisions []DecisionCount `json:"decisions"`
+
+	// RecentDecisions are the most recent routing decisions, newest first.
+	RecentDecisions []Decision `json:"recent_decisions"`
+}
+
+// Dashboard collects client statistics and serves them over HTTP.
+//
+// Thread Safety: Dashboard is safe for concurrent use.
+type Dashboard struct {
+	title     string
+	refresh   time.Duration
+	maxRecent int
+	cache     cache.StatsReporter
+	now       func() time.Time
+	started   time.Time
+
+	mu          sync.Mutex
+	models      map[string]*ModelStats
+	promptCache map[string]*PromptCacheStats
+	decisions   map[[2]string]int64
+	recent      []Decision
+}
+
+// Option configures a Dashboard.
+type Option func(*Dashboard)
+
+// WithTitle sets the page title.
+//
+// Default: "warp"
+func WithTitle(title string) Option {
+	return func(d *Dashboard) {
+		d.title = title
+	}
+}
+
+// WithRefresh sets how often the HTML page reloads itself. Zero disables
+// reloading.
+//
+// Default: 5 seconds
+func WithRefresh(interval time.Duration) Option {
+	return func(d *Dashboard) {
+		d.refresh = interval
+	}
+}
+
+// WithRecentDecisions sets how many recent routing decisions are kept.
+//
+// Default: 50
+func WithRecentDecisions(n int) Option {
+	return func(d *Dashboard) {
+		d.maxRecent = n
+	}
+}
+
+// WithCache reports the hit rate of a response cache, typically the cache
+// passed to warp.WithCache. Without it the cache section is hidden.
+func WithCache(c cache.StatsReporter) Option {
+	return func(d *Dashboard) {
+		d.cache = c
+	}
+}
+
+// New creates a dashboard.
+func New(opts ...Option) *Dashboard {
+	d := &Dashboard{
+		title:       "warp",
+		refresh:     5 * time.Second,
+		maxRecent:   50,
+		now:         time.Now,
+		models:      make(map[string]*ModelStats),
+		promptCache: make(map[string]*PromptCacheStats),
+		decisions:   make(map[[2]string]int64),
+	}
+	for _, opt := range opts {
+		opt(d)
+	}
+	d.started = d.now()
+	return d
+}
+
+// ClientOptions returns the client options that feed the dashboard from
+// success, failure and warning callbacks.
+func (d *Dashboard) ClientOptions() []warp.ClientOption {
+	return []warp.ClientOption{
+		warp.WithSuccessCallback(d.OnSuccess),
+		warp.WithFailureCallback(d.OnFailure),
+		warp.WithWarningCallback(d.OnWarning),
+	}
+}
+
+// OnSuccess records a successful request. It is a callback.SuccessCallback.
+func (d *Dashboard) OnSuccess(ctx context.Context, event *callback.SuccessEvent) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	m := d.model(event.Provider, event.Model)
+	m.Requests++
+	m.Cost += event.Cost
+	m.TotalDuration += event.Duration
+
+	resp, _ := event.Response.(*warp.CompletionResponse)
+	if resp != nil && resp.Usage != nil {
+		m.PromptTokens += int64(resp.Usage.PromptTokens)
+		m.CompletionTokens += int64(resp.Usage.CompletionTokens)
+		m.Tokens += int64(resp.Usage.TotalTokens)
+	} else {
+		m.Tokens += int64(event.Tokens)
+	}
+
+	if resp == nil {
+		return
+	}
+	if req, _ := event.Request.(*warp.CompletionRequest); req != nil && req.PromptCacheKey != "" && resp.Usage != nil {
+		pc, ok := d.promptCache[req.PromptCacheKey]
+		if !ok {
+			pc = &PromptCacheStats{Key: req.PromptCacheKey}
+			d.promptCache[req.PromptCacheKey] = pc
+		}
+		pc.Requests++
+		pc.PromptTokens += int64(resp.Usage.PromptTokens)
+		pc.CachedTokens += int64(resp.Usage.GetCachedTokens())
+	}
+	for field, kind := range routingFields {
+		if v, ok := resp.ProviderFields[field]; ok && v != "" {
+			d.decide(Decision{
+				Time:      event.EndTime,
+				RequestID: event.RequestID,
+				Provider:  event.Provider,
+				Model:     event.Model,
+				Kind:      kind,
+				Detail:    fmt.Sprint(v),
+			})
+		}
+	}
+}
+
+// OnFailure records a failed request. It is a callback.FailureCallback.
+func (d *Dashboard) OnFailure(ctx context.Context, event *callback.FailureEvent) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	m := d.model(event.Provider, event.Model)
+	m.Requests++
+	m.Errors++
+	m.TotalDuration += event.Duration
+}
+
+// OnWarning records a client warning, such as service tier spillover, as a
+// routing decision. It is a callback.WarningCallback.
+func (d *Dashboard) OnWarning(ctx context.Context, event *callback.WarningEvent) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	d.decide(Decision{
+		Time:      event.Timestamp,
+		RequestID: event.RequestID,
+		Provider:  event.Provider,
+		Model:     event.Model,
+		Kind:      event.Code,
+		Detail:    event.Message,
+	})
+}
+
+// model returns the stats of a model, creating them if needed. The caller
+// must hold d.mu.
+func (d *Dashboard) model(provider, model string) *ModelStats {
+	key := provider + "/" + model
+	m, ok := d.models[key]
+	if !ok {
+		m = &ModelStats{Provider: provider, Model: model}
+		d.models[key] = m
+	}
+	return m
+}
+
+// decide records a routing decision. The caller must hold d.mu.
+//
+// Warning messages are unique per request, so only region and upstream
+// decisions are counted by detail; warnings are counted by kind alone.
+func (d *Dashboard) decide(dec Decision) {
+	if dec.Time.IsZero() {
+		dec.Time = d.now()
+	}
+	key := [2]string{dec.Kind, ""}
+	if _, routed := routingKinds[dec.Kind]; routed {
+		key[1] = dec.Detail
+	}
+	d.decisions[key]++
+
+	if d.maxRecent <= 0 {
+		return
+	}
+	d.recent = append(d.recent, dec)
+	if len(d.recent) > d.maxRecent {
+		d.recent = d.recent[len(d.recent)-d.maxRecent:]
+	}
+}
+
+// routingKinds are the decision kinds derived from routingFields.
+var routingKinds = func() map[string]struct{} {
+	kinds := make(map[string]struct{}, len(routingFields))
+	for _, kind := range routingFields {
+		kinds[kind] = struct{}{}
+	}
+	return kinds
+}()
+
+// Snapshot returns a copy of the current statistics.
+func (d *Dashboard) Snapshot() Snapshot {
+	d.mu.Lock()
+	snap := Snapshot{
+		Started: d.started,
+		Uptime:  d.now().Sub(d.started),
+		Models:  make([]ModelStats, 0, len(d.models)),
+	}
+	for _, m := range d.models {
+		snap.Models = append(snap.Models, *m)
+		snap.Requests += m.Requests
+		snap.Errors += m.Errors
+		snap.Tokens += m.Tokens
+		snap.Cost += m.Cost
+	}
+	snap.PromptCache = make([]PromptCacheStats, 0, len(d.promptCache))
+	for _, pc := range d.promptCache {
+		stats := *pc
+		if stats.PromptTokens > 0 {
+			stats.HitRate = float64(stats.CachedTokens) / float64(stats.PromptTokens)
+		}
+		snap.PromptCache = append(snap.PromptCache, stats)
+	}
+	snap.Decisions = make([]DecisionCount, 0, len(d.decisions))
+	for key, n := range d.decisions {
+		snap.Decisions = append(snap.Decisions, DecisionCount{Kind: key[0], Detail: key[1], Count: n})
+	}
+	snap.RecentDecisions = make([]Decision, len(d.recent))
+	for i, dec := range d.recent {
+		snap.RecentDecisions[len(d.recent)-1-i] = dec
+	}
+	d.mu.Unlock()
+
+	if snap.Requests > 0 {
+		snap.ErrorRate = float64(snap.Errors) / float64(snap.Requests)
+	}
+	sort.Slice(snap.Models, func(i, j int) bool {
+		a, b := snap.Models[i], snap.Models[j]
+		if a.Requests != b.Requests {
+			return a.Requests > b.Requests
+		}
+		if a.Provider != b.Provider {
+			return a.Provider < b.Provider
+		}
+		return a.Model < b.Model
+	})
+	sort.Slice(snap.PromptCache, func(i, j int) bool {
+		a, b := snap.PromptCache[i], snap.PromptCache[j]
+		if a.Requests != b.Requests {
+			return a.Requests > b.Requests
+		}
+		return a.Key < b.Key
+	})
+	sort.Slice(snap.Decisions, func(i, j int) bool {
+		a, b := snap.Decisions[i], snap.Decisions[j]
+		if a.Count != b.Count {
+			return a.Count > b.Count
+		}
+		if a.Kind != b.Kind {
+			return a.Kind < b.Kind
+		}
+		return a.Detail < b.Detail
+	})
+
+	if d.cache != nil {
+		stats := d.cache.Stats()
+		snap.Cache = &stats
+		snap.CacheHitRate = stats.HitRate()
+	}
+	return snap
+}
+
+// Reset clears all collected statistics.
+func (d *Dashboard) Reset() {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	d.started = d.now()
+	d.models = make(map[string]*ModelStats)
+	d.promptCache = make(map[string]*PromptCacheStats)
+	d.decisions = make(map[[2]string]int64)
+	d.recent = nil
+}
@@ -0,0 +1,231 @@
+package dashboard
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/blue-context/warp"
+	"github.com/blue-context/warp/cache"
+	"github.com/blue-context/warp/callback"
+	"github.com/blue-context/warp/internal/testutil"
+)
+
+// fakeCache reports fixed statistics.
+type fakeCache struct{ stats cache.Stats }
+
+func (f *fakeCache) Stats() cache.Stats { return f.stats }
+
+func newTestDashboard(opts ...Option) *Dashboard {
+	d := New(opts...)
+	now := time.Unix(1_700_000_000, 0)
+	d.now = func() time.Time { return now }
+	return d
+}
+
+func record(d *Dashboard) {
+	ctx := context.Background()
+	d.OnSuccess(ctx, &callback.SuccessEvent{
+		Provider: "openai",
+		Model:    "gpt-4o",
+		Response: &warp.CompletionResponse{Usage: &warp.Usage{PromptTokens: 10, CompletionTokens: 5, TotalTokens: 15}},
+		Cost:     0.01,
+		Duration: 100 * time.Millisecond,
+	})
+	d.OnSuccess(ctx, &callback.SuccessEvent{
+		Provider: "azure",
+		Model:    "gpt-4o",
+		Response: &warp.CompletionResponse{ProviderFields: map[string]any{"region": "westeurope"}},
+		Tokens:   20,
+		Duration: 300 * time.Millisecond,
+	})
+	d.OnFailure(ctx, &callback.FailureEvent{
+		Provider: "openai",
+		Model:    "gpt-4o",
+		Error:    errors.New("boom"),
+		Duration: 200 * time.Millisecond,
+	})
+	d.OnWarning(ctx, &callback.WarningEvent{
+		Provider: "openai",
+		Model:    "gpt-4o",
+		Code:     warp.WarningServiceTierSpillover,
+		Message:  "priority tier overloaded, retried on default",
+	})
+}
+
+func TestSnapshot(t *testing.T) {
+	d := newTestDashboard(WithCache(&fakeCache{cache.Stats{Hits: 3, Misses: 1}}))
+	record(d)
+
+	snap := d.Snapshot()
+	if snap.Requests != 3 || snap.Errors != 1 || snap.Tokens != 35 {
+		t.Errorf("totals = %d requests, %d errors, %d tokens, want 3, 1, 35", snap.Requests, snap.Errors, snap.Tokens)
+	}
+	if snap.Cost != 0.01 {
+		t.Errorf("Cost = %v, want 0.01", snap.Cost)
+	}
+	if snap.CacheHitRate != 0.75 {
+		t.Errorf("CacheHitRate = %v, want 0.75", snap.CacheHitRate)
+	}
+
+	if len(snap.Models) != 2 || snap.Models[0].Provider != "openai" {
+		t.Fatalf("Models = %+v, want openai first", snap.Models)
+	}
+	openai := snap.Models[0]
+	if openai.ErrorRate() != 0.5 || openai.AvgLatency() != 150*time.Millisecond {
+		t.Errorf("openai ErrorRate = %v, AvgLatency = %v, want 0.5, 150ms", openai.ErrorRate(), openai.AvgLatency())
+	}
+	if openai.PromptTokens != 10 || openai.CompletionTokens != 5 {
+		t.Errorf("openai tokens = %d/%d, want 10/5", openai.PromptTokens, openai.CompletionTokens)
+	}
+
+	want := map[string]string{"region": "westeurope", warp.WarningServiceTierSpillover: ""}
+	if len(snap.Decisions) != len(want) {
+		t.Fatalf("Decisions = %+v, want %d", snap.Decisions, len(want))
+	}
+	for _, dec := range snap.Decisions {
+		if detail, ok := want[dec.Kind]; !ok || dec.Detail != detail || dec.Count != 1 {
+			t.Errorf("decision %+v, want detail %q", dec, detail)
+		}
+	}
+	if len(snap.RecentDecisions) != 2 || snap.RecentDecisions[0].Kind != warp.WarningServiceTierSpillover {
+		t.Errorf("RecentDecisions = %+v, want newest first", snap.RecentDecisions)
+	}
+
+	d.Reset()
+	if snap := d.Snapshot(); snap.Requests != 0 || len(snap.Decisions) != 0 {
+		t.Errorf("Snapshot() after Reset = %+v, want empty", snap)
+	}
+}
+
+func TestPromptCache(t *testing.T) {
+	d := newTestDashboard()
+	ctx := context.Background()
+	success := func(key string, prompt, cached int) {
+		d.OnSuccess(ctx, &callback.SuccessEvent{
+			Provider: "openai",
+			Model:    "gpt-4o",
+			Request:  &warp.CompletionRequest{PromptCacheKey: key},
+			Response: &warp.CompletionResponse{Usage: &warp.Usage{
+				PromptTokens:  prompt,
+				PromptDetails: &warp.PromptTokensDetails{CachedTokens: cached},
+			}},
+		})
+	}
+	success("support-bot", 2000, 0)
+	success("support-bot", 2000, 1920)
+	success("support-bot", 2000, 1920)
+	success("summarizer", 1000, 768)
+	success("", 1000, 0)
+
+	snap := d.Snapshot()
+	if len(snap.PromptCache) != 2 {
+		t.Fatalf("PromptCache = %+v, want 2 keys", snap.PromptCache)
+	}
+	support := snap.PromptCache[0]
+	if support.Key != "support-bot" || support.Requests != 3 || support.PromptTokens != 6000 || support.CachedTokens != 3840 {
+		t.Errorf("PromptCache[0] = %+v, want support-bot with 3 requests, 3840/6000 cached", support)
+	}
+	if support.HitRate != 0.64 {
+		t.Errorf("HitRate = %v, want 0.64", support.HitRate)
+	}
+	if summarizer := snap.PromptCache[1]; summarizer.Key != "summarizer" || summarizer.HitRate != 0.768 {
+		t.Errorf("PromptCache[1] = %+v, want summarizer at 0.768", summarizer)
+	}
+
+	rec := httptest.NewRecorder()
+	d.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+	if body := rec.Body.String(); !strings.Contains(body, "support-bot") || !strings.Contains(body, "64.0%") {
+		t.Error("page is missing the prompt cache hit rate")
+	}
+
+	d.Reset()
+	if snap := d.Snapshot(); len(snap.PromptCache) != 0 {
+		t.Errorf("PromptCache after Reset = %+v, want empty", snap.PromptCache)
+	}
+}
+
+func TestRecentDecisionsLimit(t *testing.T) {
+	d := newTestDashboard(WithRecentDecisions(2))
+	for i := 0; i < 5; i++ {
+		d.OnWarning(context.Background(), &callback.WarningEvent{Code: "system_messages_restructured", Message: "merged"})
+	}
+
+	snap := d.Snapshot()
+	if len(snap.RecentDecisions) != 2 {
+		t.Errorf("len(RecentDecisions) = %d, want 2", len(snap.RecentDecisions))
+	}
+	if len(snap.Decisions) != 1 || snap.Decisions[0].Count != 5 {
+		t.Errorf("Decisions = %+v, want one kind counted 5 times", snap.Decisions)
+	}
+}
+
+func TestServeHTTP(t *testing.T) {
+	d := newTestDashboard(WithTitle("dev <proxy>"), WithCache(&fakeCache{cache.Stats{Hits: 1, Misses: 1}}))
+	record(d)
+
+	t.Run("html", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		d.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+
+		if ct := rec.Header().Get("Content-Type"); !strings.HasPrefix(ct, "text/html") {
+			t.Errorf("Content-Type = %q, want text/html", ct)
+		}
+		body := rec.Body.String()
+		for _, want := range []string{"dev &lt;proxy&gt;", "gpt-4o", "westeurope", "service_tier_spillover", "50.0%", `http-equiv="refresh"`} {
+			if !strings.Contains(body, want) {
+				t.Errorf("body missing %q", want)
+			}
+		}
+	})
+
+	t.Run("json", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		d.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/?format=json", nil))
+
+		var snap Snapshot
+		if err := json.Unmarshal(rec.Body.Bytes(), &snap); err != nil {
+			t.Fatalf("invalid JSON: %v", err)
+		}
+		if snap.Requests != 3 || snap.Cache == nil || snap.Cache.Hits != 1 {
+			t.Errorf("Snapshot = %+v, want 3 requests with cache stats", snap)
+		}
+	})
+
+	t.Run("method", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		d.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/", nil))
+		if rec.Code != http.StatusMethodNotAllowed {
+			t.Errorf("status = %d, want 405", rec.Code)
+		}
+	})
+}
+
+func TestClientOptions(t *testing.T) {
+	d := newTestDashboard()
+	client, err := warp.NewClient(d.ClientOptions()...)
+	if err != nil {
+		t.Fatalf("NewClient() error = %v", err)
+	}
+	client.RegisterProvider(&testutil.MockProvider{
+		NameFunc: func() string { return "mock" },
+		CompletionFunc: func(ctx context.Context, req *warp.CompletionRequest) (*warp.CompletionResponse, error) {
+			return &warp.CompletionResponse{Usage: &warp.Usage{TotalTokens: 7}}, nil
+		},
+	})
+
+	req := &warp.CompletionRequest{Model: "mock/model", Messages: []warp.Message{{Role: "user", Content: "Hi"}}}
+	if _, err := client.Completion(context.Background(), req); err != nil {
+		t.Fatalf("Completion() error = %v", err)
+	}
+
+	snap := d.Snapshot()
+	if snap.Requests != 1 || snap.Tokens != 7 || snap.Models[0].Provider != "mock" {
+		t.Errorf("Snapshot() = %+v, want one mock request with 7 tokens", snap)
+	}
+}
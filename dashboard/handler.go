@@ -0,0 +1,121 @@
+package dashboard
+
+import (
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// htmlPage is the data passed to the HTML template.
+type htmlPage struct {
+	Title   string
+	Refresh int
+	Snapshot
+}
+
+// ServeHTTP renders the dashboard. It responds with JSON if the format
+// query parameter is "json" or the request accepts only application/json,
+// and with an HTML page otherwise.
+func (d *Dashboard) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		w.Header().Set("Allow", "GET, HEAD")
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	snap := d.Snapshot()
+	w.Header().Set("Cache-Control", "no-store")
+
+	if wantsJSON(r) {
+		w.Header().Set("Content-Type", "application/json")
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		enc.Encode(snap)
+		return
+	}
+
+	page := htmlPage{
+		Title:    d.title,
+		Refresh:  int(d.refresh / time.Second),
+		Snapshot: snap,
+	}
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	if err := htmlTemplate.Execute(w, page); err != nil {
+		http.Error(w, fmt.Sprintf("failed to render dashboard: %v", err), http.StatusInternalServerError)
+	}
+}
+
+// wantsJSON reports whether a request asks for the JSON representation.
+func wantsJSON(r *http.Request) bool {
+	if r.URL.Query().Get("format") == "json" {
+		return true
+	}
+	accept := r.Header.Get("Accept")
+	return strings.HasPrefix(accept, "application/json") && !strings.Contains(accept, "text/html")
+}
+
+// htmlTemplate renders the dashboard page.
+var htmlTemplate = template.Must(template.New("dashboard").Funcs(template.FuncMap{
+	"usd":     func(v float64) string { return fmt.Sprintf("$%.4f", v) },
+	"percent": func(v float64) string { return fmt.Sprintf("%.1f%%", v*100) },
+	"latency": func(d time.Duration) string { return d.Round(time.Millisecond).String() },
+	"uptime":  func(d time.Duration) string { return d.Round(time.Second).String() },
+	"clock":   func(t time.Time) string { return t.Format("15:04:05") },
+}).Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+{{if .Refresh}}<meta http-equiv="refresh" content="{{.Refresh}}">{{end}}
+<title>{{.Title}} dashboard</title>
+<style>
+body{font-family:system-ui,sans-serif;max-width:1100px;margin:2em auto;padding:0 1em;color:#222}
+header{border-bottom:1px solid #ddd;margin-bottom:1em}
+.tiles{display:flex;flex-wrap:wrap;gap:1em;margin:1em 0}
+.tile{background:#f6f6f6;border-radius:8px;padding:.75em 1em;min-width:8em}
+.tile .v{font-size:1.5em;font-weight:600}
+.tile .k{font-size:.8em;color:#777;text-transform:uppercase}
+table{border-collapse:collapse;width:100%;margin-bottom:1.5em}
+th,td{text-align:left;padding:.35em .6em;border-bottom:1px solid #eee}
+td.n,th.n{text-align:right;font-variant-numeric:tabular-nums}
+.err{color:#b00020}
+.meta{font-size:.8em;color:#777}
+</style>
+</head>
+<body>
+<header>
+<h1>{{.Title}}</h1>
+<p class="meta">Up {{uptime .Uptime}} &middot; <a href="?format=json">JSON</a></p>
+</header>
+<div class="tiles">
+<div class="tile"><div class="v">{{.Requests}}</div><div class="k">Requests</div></div>
+<div class="tile"><div class="v">{{.Tokens}}</div><div class="k">Tokens</div></div>
+<div class="tile"><div class="v">{{usd .Cost}}</div><div class="k">Cost</div></div>
+<div class="tile"><div class="v{{if .Errors}} err{{end}}">{{percent .ErrorRate}}</div><div class="k">Error rate</div></div>
+{{with .Cache}}<div class="tile"><div class="v">{{percent $.CacheHitRate}}</div><div class="k">Cache hit rate ({{.Hits}}/{{.Misses}})</div></div>{{end}}
+</div>
+<h2>Models</h2>
+{{if .Models}}<table>
+<tr><th>Provider</th><th>Model</th><th class="n">Requests</th><th class="n">Errors</th><th class="n">Prompt</th><th class="n">Completion</th><th class="n">Tokens</th><th class="n">Cost</th><th class="n">Avg latency</th></tr>
+{{range .Models}}<tr><td>{{.Provider}}</td><td>{{.Model}}</td><td class="n">{{.Requests}}</td><td class="n{{if .Errors}} err{{end}}">{{.Errors}} ({{percent .ErrorRate}})</td><td class="n">{{.PromptTokens}}</td><td class="n">{{.CompletionTokens}}</td><td class="n">{{.Tokens}}</td><td class="n">{{usd .Cost}}</td><td class="n">{{latency .AvgLatency}}</td></tr>
+{{end}}</table>{{else}}<p class="meta">No requests yet.</p>{{end}}
+{{with .PromptCache}}<h2>Prompt cache</h2>
+<table>
+<tr><th>Key</th><th class="n">Requests</th><th class="n">Prompt</th><th class="n">Cached</th><th class="n">Hit rate</th></tr>
+{{range .}}<tr><td>{{.Key}}</td><td class="n">{{.Requests}}</td><td class="n">{{.PromptTokens}}</td><td class="n">{{.CachedTokens}}</td><td class="n">{{percent .HitRate}}</td></tr>
+{{end}}</table>{{end}}
+<h2>Routing decisions</h2>
+{{if .Decisions}}<table>
+<tr><th>Kind</th><th>Detail</th><th class="n">Count</th></tr>
+{{range .Decisions}}<tr><td>{{.Kind}}</td><td>{{.Detail}}</td><td class="n">{{.Count}}</td></tr>
+{{end}}</table>
+<h3>Recent</h3>
+<table>
+<tr><th>Time</th><th>Model</th><th>Kind</th><th>Detail</th></tr>
+{{range .RecentDecisions}}<tr><td>{{clock .Time}}</td><td>{{.Provider}}/{{.Model}}</td><td>{{.Kind}}</td><td>{{.Detail}}</td></tr>
+{{end}}</table>{{else}}<p class="meta">No routing decisions yet.</p>{{end}}
+</body>
+</html>
+`))
@@ -0,0 +1,252 @@
+// Package dedupe removes context blocks repeated across conversation turns.
+//
+// Chatbots that resend the whole history often repeat the same context on
+// every turn, such as retrieval chunks pasted into each user message. The
+// Deduplicator keeps the first occurrence of each block and replaces later
+// copies with a short reference to it, or drops them, so the prompt pays
+// for each block once.
+//
+// A block is a paragraph: text separated from its neighbours by a blank
+// line. Blocks shorter than the minimum length are left alone, so short
+// repeated phrases such as greetings are not touched. Earlier messages are
+// never rewritten, which keeps the prompt prefix stable for provider
+// prompt caching.
+//
+// Basic usage:
+//
+//	deduper := dedupe.New(dedupe.WithMinLength(200))
+//	client, err := warp.NewClient(
+//	    warp.WithRequestMiddleware(deduper.Dedupe),
+//	)
+package dedupe
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/blue-context/warp"
+)
+
+// DefaultMinLength is the default minimum length in bytes of a block to
+// deduplicate.
+const DefaultMinLength = 120
+
+// Mode is how a repeated block is removed.
+type Mode int
+
+const (
+	// ModeReference replaces a repeated block with a reference to its
+	// first occurrence.
+	ModeReference Mode = iota
+
+	// ModeTrim drops a repeated block.
+	ModeTrim
+)
+
+// referencePreviewLen is how much of a block a reference quotes.
+const referencePreviewLen = 60
+
+// Deduplicator replaces repeated context blocks in completion requests.
+//
+// Thread Safety: Deduplicator is safe for concurrent use.
+type Deduplicator struct {
+	minLength int
+	mode      Mode
+	roles     []string
+}
+
+// Option configures a Deduplicator.
+type Option func(*Deduplicator)
+
+// WithMinLength sets the minimum length in bytes of a block to
+// deduplicate.
+//
+// The default is DefaultMinLength.
+func WithMinLength(n int) Option {
+	return func(d *Deduplicator) {
+		d.minLength = n
+	}
+}
+
+// WithMode sets how repeated blocks are removed.
+//
+// The default is ModeReference.
+func WithMode(mode Mode) Option {
+	return func(d *Deduplicator) {
+		d.mode = mode
+	}
+}
+
+// WithRoles sets which message roles are deduplicated.
+//
+// The default is "user" and "tool". Blocks of other roles still count as
+// first occurrences.
+//
+// Example:
+//
+//	dedupe.New(dedupe.WithRoles("system", "user", "tool"))
+func WithRoles(roles ...string) Option {
+	return func(d *Deduplicator) {
+		d.roles = roles
+	}
+}
+
+// New creates a Deduplicator with the given options.
+//
+// Example:
+//
+//	deduper := dedupe.New(
+//	    dedupe.WithMode(dedupe.ModeTrim),
+//	    dedupe.WithMinLength(300),
+//	)
+func New(opts ...Option) *Deduplicator {
+	d := &Deduplicator{
+		minLength: DefaultMinLength,
+		mode:      ModeReference,
+		roles:     []string{"user", "tool"},
+	}
+
+	for _, opt := range opts {
+		opt(d)
+	}
+
+	return d
+}
+
+// blankLine separates blocks.
+var blankLine = regexp.MustCompile(`\n[ \t]*\n\s*`)
+
+// Dedupe returns a copy of req in which blocks repeating an earlier block
+// of the conversation are replaced or removed. It returns req itself if
+// nothing is repeated.
+//
+// String content and the text parts of multimodal content are
+// deduplicated. Text made only of repeated blocks keeps a reference to the
+// first of them even in ModeTrim, since providers reject empty messages.
+// req itself is not modified. The signature
+// matches warp.RequestMiddleware.
+func (d *Deduplicator) Dedupe(ctx context.Context, req *warp.CompletionRequest) (*warp.CompletionRequest, error) {
+	if req == nil || len(req.Messages) < 2 {
+		return req, nil
+	}
+
+	seen := make(map[string]int)
+	var out *warp.CompletionRequest
+	for i, msg := range req.Messages {
+		dedupe := slices.Contains(d.roles, msg.Role)
+		content, changed := d.dedupeContent(msg.Content, i, seen, dedupe)
+		if !changed {
+			continue
+		}
+		if out == nil {
+			r := *req
+			r.Messages = slices.Clone(req.Messages)
+			out = &r
+		}
+		out.Messages[i].Content = content
+	}
+	if out == nil {
+		return req, nil
+	}
+	return out, nil
+}
+
+// dedupeContent deduplicates the text of message msg's content, recording
+// its blocks in seen. It only rewrites the content if dedupe is set, and
+// reports whether it did.
+func (d *Deduplicator) dedupeContent(content any, msg int, seen map[string]int, dedupe bool) (any, bool) {
+	switch c := content.(type) {
+	case string:
+		return d.dedupeText(c, msg, seen, dedupe)
+	case []warp.ContentPart:
+		var parts []warp.ContentPart
+		for j, part := range c {
+			if part.Type != "text" {
+				continue
+			}
+			text, changed := d.dedupeText(part.Text, msg, seen, dedupe)
+			if !changed {
+				continue
+			}
+			if parts == nil {
+				parts = slices.Clone(c)
+			}
+			parts[j].Text = text
+		}
+		if parts == nil {
+			return content, false
+		}
+		return parts, true
+	}
+	return content, false
+}
+
+// dedupeText deduplicates the blocks of text.
+func (d *Deduplicator) dedupeText(text string, msg int, seen map[string]int, dedupe bool) (string, bool) {
+	blocks := blankLine.Split(text, -1)
+	changed := false
+	var kept []string
+	firstRef := ""
+	for _, block := range blocks {
+		key := normalize(block)
+		if len(key) < d.minLength {
+			kept = append(kept, block)
+			continue
+		}
+		first, repeated := seen[key]
+		if !repeated {
+			seen[key] = msg
+			kept = append(kept, block)
+			continue
+		}
+		if !dedupe {
+			kept = append(kept, block)
+			continue
+		}
+		changed = true
+		ref := reference(key, first, msg)
+		if firstRef == "" {
+			firstRef = ref
+		}
+		if d.mode == ModeReference {
+			kept = append(kept, ref)
+		}
+	}
+	if !changed {
+		return text, false
+	}
+
+	// Never leave the text empty
+	joined := strings.Join(kept, "\n\n")
+	if strings.TrimSpace(joined) == "" {
+		return firstRef, true
+	}
+	return joined, true
+}
+
+// reference returns the text that replaces a block first seen in message
+// first.
+func reference(key string, first, msg int) string {
+	preview := key
+	if len(preview) > referencePreviewLen {
+		cut := referencePreviewLen
+		for cut > 0 && !utf8.RuneStart(preview[cut]) {
+			cut--
+		}
+		preview = preview[:cut] + "…"
+	}
+	if first == msg {
+		return fmt.Sprintf("[Repeated context omitted, see above: %q]", preview)
+	}
+	return fmt.Sprintf("[Repeated context omitted, see message %d: %q]", first+1, preview)
+}
+
+// normalize returns a comparison key for a block, ignoring whitespace
+// differences.
+func normalize(block string) string {
+	return strings.Join(strings.Fields(block), " ")
+}
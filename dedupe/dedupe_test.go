@@ -0,0 +1,120 @@
+package dedupe
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/blue-context/warp"
+)
+
+var chunk = "Warp routes completion requests to Anthropic, OpenAI, Vertex AI, and vLLM providers " +
+	"through one client, with retries, cost tracking, and caching built in."
+
+// TestDedupeReference tests replacing a re-sent chunk with a reference
+func TestDedupeReference(t *testing.T) {
+	req := &warp.CompletionRequest{
+		Model: "openai/gpt-4o",
+		Messages: []warp.Message{
+			{Role: "system", Content: "Answer from the documents."},
+			{Role: "user", Content: "Context:\n\n" + chunk + "\n\nWhat is Warp?"},
+			{Role: "assistant", Content: "A client library for LLM providers."},
+			{Role: "user", Content: "Context:\n\n" + chunk + "\n\nWhich providers does it support?"},
+		},
+	}
+
+	out, err := New().Dedupe(context.Background(), req)
+	if err != nil {
+		t.Fatalf("Dedupe() error = %v", err)
+	}
+	got := out.Messages[3].Content.(string)
+	if strings.Contains(got, chunk) {
+		t.Errorf("Messages[3] = %q, want chunk replaced", got)
+	}
+	if !strings.Contains(got, "see message 2") || !strings.HasSuffix(got, "Which providers does it support?") {
+		t.Errorf("Messages[3] = %q, want reference and question", got)
+	}
+	if out.Messages[1].Content != req.Messages[1].Content {
+		t.Error("Dedupe() rewrote the first occurrence")
+	}
+
+	// The original request is not modified
+	if !strings.Contains(req.Messages[3].Content.(string), chunk) {
+		t.Error("Dedupe() modified the input request")
+	}
+}
+
+// TestDedupeTrim tests dropping repeated chunks, including within a message
+func TestDedupeTrim(t *testing.T) {
+	req := &warp.CompletionRequest{
+		Model: "openai/gpt-4o",
+		Messages: []warp.Message{
+			{Role: "user", Content: chunk + "\n\n" + chunk},
+			{Role: "user", Content: []warp.ContentPart{
+				{Type: "text", Text: chunk + "\n   \n" + "Summarize."},
+				{Type: "image_url", ImageURL: &warp.ImageURL{URL: "https://example.com/a.png"}},
+			}},
+		},
+	}
+
+	out, err := New(WithMode(ModeTrim)).Dedupe(context.Background(), req)
+	if err != nil {
+		t.Fatalf("Dedupe() error = %v", err)
+	}
+	if got := out.Messages[0].Content; got != chunk {
+		t.Errorf("Messages[0] = %q, want one copy of the chunk", got)
+	}
+	parts := out.Messages[1].Content.([]warp.ContentPart)
+	if parts[0].Text != "Summarize." || parts[1].ImageURL == nil {
+		t.Errorf("Messages[1] = %+v, want chunk dropped and image kept", parts)
+	}
+}
+
+// TestDedupeTrimKeepsReference tests that trimming never empties a message
+// or text part
+func TestDedupeTrimKeepsReference(t *testing.T) {
+	req := &warp.CompletionRequest{
+		Model: "openai/gpt-4o",
+		Messages: []warp.Message{
+			{Role: "user", Content: chunk},
+			{Role: "tool", Content: chunk, ToolCallID: "call_1"},
+			{Role: "user", Content: []warp.ContentPart{
+				{Type: "text", Text: chunk},
+				{Type: "image_url", ImageURL: &warp.ImageURL{URL: "https://example.com/a.png"}},
+			}},
+		},
+	}
+
+	out, err := New(WithMode(ModeTrim)).Dedupe(context.Background(), req)
+	if err != nil {
+		t.Fatalf("Dedupe() error = %v", err)
+	}
+	if got := out.Messages[1].Content.(string); !strings.Contains(got, "see message 1") {
+		t.Errorf("Messages[1] = %q, want a reference instead of empty content", got)
+	}
+	parts := out.Messages[2].Content.([]warp.ContentPart)
+	if !strings.Contains(parts[0].Text, "see message 1") {
+		t.Errorf("Messages[2] text = %q, want a reference instead of empty text", parts[0].Text)
+	}
+}
+
+// TestDedupeUnchanged tests that short and unique blocks are left alone
+func TestDedupeUnchanged(t *testing.T) {
+	req := &warp.CompletionRequest{
+		Model: "openai/gpt-4o",
+		Messages: []warp.Message{
+			{Role: "system", Content: chunk},
+			{Role: "user", Content: "Thanks!"},
+			{Role: "assistant", Content: chunk},
+			{Role: "user", Content: "Thanks!"},
+		},
+	}
+
+	out, err := New().Dedupe(context.Background(), req)
+	if err != nil {
+		t.Fatalf("Dedupe() error = %v", err)
+	}
+	if out != req {
+		t.Errorf("Dedupe() = %+v, want request unchanged", out.Messages)
+	}
+}
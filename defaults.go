@@ -0,0 +1,92 @@
+package warp
+
+import "path"
+
+// RequestDefaults are default completion parameters for the models
+// matching Model. They fill in parameters a request leaves unset; values
+// set on the request always win.
+type RequestDefaults struct {
+	// Model selects the models the defaults apply to, in
+	// "provider/model-name" form. It may be a glob pattern as in
+	// path.Match, such as "openai/*" or "anthropic/claude-3-*", and "*"
+	// matches every model.
+	Model string
+
+	// Temperature is the default temperature.
+	Temperature *float64
+
+	// MaxTokens is the default maximum number of tokens to generate.
+	MaxTokens *int
+
+	// TopP is the default nucleus sampling threshold.
+	TopP *float64
+
+	// SystemPrompt is prepended as a system message to requests without
+	// a system or developer message.
+	SystemPrompt string
+}
+
+// matches reports whether the defaults apply to model.
+func (d *RequestDefaults) matches(model string) bool {
+	if d.Model == "*" {
+		return true
+	}
+	ok, _ := path.Match(d.Model, model)
+	return ok
+}
+
+// applyRequestDefaults returns req with the configured defaults for its
+// model filled in, or req itself if no defaults apply. Matching defaults
+// are merged in the order they were added, later ones taking precedence.
+func (c *client) applyRequestDefaults(req *CompletionRequest) *CompletionRequest {
+	var merged RequestDefaults
+	found := false
+	for i := range c.config.RequestDefaults {
+		d := &c.config.RequestDefaults[i]
+		if !d.matches(req.Model) {
+			continue
+		}
+		found = true
+		if d.Temperature != nil {
+			merged.Temperature = d.Temperature
+		}
+		if d.MaxTokens != nil {
+			merged.MaxTokens = d.MaxTokens
+		}
+		if d.TopP != nil {
+			merged.TopP = d.TopP
+		}
+		if d.SystemPrompt != "" {
+			merged.SystemPrompt = d.SystemPrompt
+		}
+	}
+	if !found {
+		return req
+	}
+
+	r := *req
+	if r.Temperature == nil {
+		r.Temperature = merged.Temperature
+	}
+	if r.MaxTokens == nil {
+		r.MaxTokens = merged.MaxTokens
+	}
+	if r.TopP == nil {
+		r.TopP = merged.TopP
+	}
+	if merged.SystemPrompt != "" && !hasSystemMessage(r.Messages) {
+		r.Messages = append([]Message{{Role: "system", Content: merged.SystemPrompt}}, r.Messages...)
+	}
+	return &r
+}
+
+// hasSystemMessage reports whether messages include a system or developer
+// message.
+func hasSystemMessage(messages []Message) bool {
+	for _, msg := range messages {
+		if msg.Role == "system" || msg.Role == "developer" {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,91 @@
+package warp
+
+import (
+	"context"
+	"testing"
+)
+
+func TestRequestDefaults(t *testing.T) {
+	client, err := NewClient(
+		WithRequestDefaults(RequestDefaults{Model: "*", MaxTokens: IntPtr(1024), Temperature: Float64Ptr(1)}),
+		WithRequestDefaults(RequestDefaults{Model: "test/claude-*", Temperature: Float64Ptr(0.3), SystemPrompt: "Be concise."}),
+	)
+	if err != nil {
+		t.Fatalf("NewClient() error = %v", err)
+	}
+
+	var got *CompletionRequest
+	client.RegisterProvider(&mockProvider{
+		name: "test",
+		completionFunc: func(ctx context.Context, req *CompletionRequest) (*CompletionResponse, error) {
+			got = req
+			return &CompletionResponse{ID: "test"}, nil
+		},
+	})
+
+	tests := []struct {
+		name            string
+		req             CompletionRequest
+		wantTemperature float64
+		wantMaxTokens   int
+		wantMessages    int
+	}{
+		{
+			name:            "general defaults",
+			req:             CompletionRequest{Model: "test/gpt-4o", Messages: []Message{{Role: "user", Content: "Hi"}}},
+			wantTemperature: 1,
+			wantMaxTokens:   1024,
+			wantMessages:    1,
+		},
+		{
+			name:            "group defaults override general ones",
+			req:             CompletionRequest{Model: "test/claude-3-haiku", Messages: []Message{{Role: "user", Content: "Hi"}}},
+			wantTemperature: 0.3,
+			wantMaxTokens:   1024,
+			wantMessages:    2,
+		},
+		{
+			name: "request values win",
+			req: CompletionRequest{
+				Model:       "test/claude-3-haiku",
+				Messages:    []Message{{Role: "system", Content: "Be verbose."}, {Role: "user", Content: "Hi"}},
+				Temperature: Float64Ptr(0.9),
+				MaxTokens:   IntPtr(10),
+			},
+			wantTemperature: 0.9,
+			wantMaxTokens:   10,
+			wantMessages:    2,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := tt.req
+			if _, err := client.Completion(context.Background(), &req); err != nil {
+				t.Fatalf("Completion() error = %v", err)
+			}
+			if got.Temperature == nil || *got.Temperature != tt.wantTemperature {
+				t.Errorf("Temperature = %v, want %v", got.Temperature, tt.wantTemperature)
+			}
+			if got.MaxTokens == nil || *got.MaxTokens != tt.wantMaxTokens {
+				t.Errorf("MaxTokens = %v, want %v", got.MaxTokens, tt.wantMaxTokens)
+			}
+			if len(got.Messages) != tt.wantMessages {
+				t.Errorf("messages = %d, want %d", len(got.Messages), tt.wantMessages)
+			}
+			if tt.wantMessages == 2 && got.Messages[0].Role != "system" {
+				t.Errorf("first message role = %q, want system", got.Messages[0].Role)
+			}
+			if req.Temperature != tt.req.Temperature || len(req.Messages) != len(tt.req.Messages) {
+				t.Error("caller's request was modified")
+			}
+		})
+	}
+}
+
+func TestWithRequestDefaultsInvalid(t *testing.T) {
+	for _, pattern := range []string{"", "openai/[gpt"} {
+		if _, err := NewClient(WithRequestDefaults(RequestDefaults{Model: pattern})); err == nil {
+			t.Errorf("WithRequestDefaults(%q) error = nil", pattern)
+		}
+	}
+}
@@ -0,0 +1,47 @@
+package warp
+
+import (
+	"context"
+	"fmt"
+)
+
+const (
+	// WarningModelDeprecated is the callback.WarningEvent code raised when
+	// a request uses a deprecated model.
+	WarningModelDeprecated = "model_deprecated"
+
+	// WarningModelReplaced is the callback.WarningEvent code raised when a
+	// request for a deprecated model is sent to its replacement.
+	WarningModelReplaced = "model_replaced"
+)
+
+// checkDeprecation warns when modelName is deprecated according to its
+// provider's model registry. If the client replaces deprecated models and
+// the registry names a replacement, it returns a copy of req for the
+// replacement and the replacement's name; otherwise it returns req and
+// modelName unchanged.
+func (c *client) checkDeprecation(ctx context.Context, req *CompletionRequest, providerName, modelName string) (*CompletionRequest, string) {
+	info, err := c.costCalc.GetModelInfo(providerName, modelName)
+	if err != nil || !info.Deprecated {
+		return req, modelName
+	}
+
+	message := fmt.Sprintf("model %s/%s is deprecated", providerName, modelName)
+	if !info.ShutdownDate.IsZero() {
+		message += fmt.Sprintf(" and is shut down on %s", info.ShutdownDate.Format("2006-01-02"))
+	}
+	if info.ReplacedBy != "" {
+		message += fmt.Sprintf("; use %s/%s instead", providerName, info.ReplacedBy)
+	}
+	c.warn(ctx, WarningModelDeprecated, message)
+
+	if !c.config.ReplaceDeprecatedModels || info.ReplacedBy == "" {
+		return req, modelName
+	}
+	c.warn(ctx, WarningModelReplaced, fmt.Sprintf("sending request for deprecated model %s/%s to %s/%s",
+		providerName, modelName, providerName, info.ReplacedBy))
+
+	r := *req
+	r.Model = providerName + "/" + info.ReplacedBy
+	return &r, info.ReplacedBy
+}
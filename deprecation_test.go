@@ -0,0 +1,81 @@
+package warp
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/blue-context/warp/callback"
+	"github.com/blue-context/warp/types"
+)
+
+func TestDeprecatedModels(t *testing.T) {
+	modelInfo := map[string]*types.ModelInfo{
+		"old": {
+			Name:         "old",
+			Deprecated:   true,
+			ReplacedBy:   "new",
+			ShutdownDate: time.Date(2025, time.July, 21, 0, 0, 0, 0, time.UTC),
+		},
+		"new": {Name: "new"},
+	}
+
+	tests := []struct {
+		name         string
+		model        string
+		replace      bool
+		wantModel    string
+		wantWarnings []string
+	}{
+		{"current model", "mock/new", false, "new", nil},
+		{"deprecated model", "mock/old", false, "old", []string{WarningModelDeprecated}},
+		{"replaced model", "mock/old", true, "new", []string{WarningModelDeprecated, WarningModelReplaced}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var warnings []*callback.WarningEvent
+			client, err := NewClient(
+				WithDeprecatedModelReplacement(tt.replace),
+				WithWarningCallback(func(ctx context.Context, event *callback.WarningEvent) {
+					warnings = append(warnings, event)
+				}),
+			)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer client.Close()
+			var got string
+			client.RegisterProvider(&mockProvider{
+				name:      "mock",
+				modelInfo: modelInfo,
+				completionFunc: func(ctx context.Context, req *CompletionRequest) (*CompletionResponse, error) {
+					got = req.Model
+					return &CompletionResponse{ID: "test"}, nil
+				},
+			})
+
+			req := &CompletionRequest{Model: tt.model, Messages: []Message{{Role: "user", Content: "Hi"}}}
+			if _, err := client.Completion(context.Background(), req); err != nil {
+				t.Fatalf("Completion() error = %v", err)
+			}
+			if got != tt.wantModel {
+				t.Errorf("provider model = %q, want %q", got, tt.wantModel)
+			}
+			if req.Model != tt.model {
+				t.Errorf("caller's request model = %q, want %q", req.Model, tt.model)
+			}
+			if len(warnings) != len(tt.wantWarnings) {
+				t.Fatalf("warnings = %+v, want %v", warnings, tt.wantWarnings)
+			}
+			for i, code := range tt.wantWarnings {
+				if warnings[i].Code != code {
+					t.Errorf("warning %d code = %q, want %q", i, warnings[i].Code, code)
+				}
+			}
+			if len(warnings) > 0 && !strings.Contains(warnings[0].Message, "2025-07-21") {
+				t.Errorf("warning message = %q, want shutdown date", warnings[0].Message)
+			}
+		})
+	}
+}
@@ -0,0 +1,127 @@
+package warp
+
+import (
+	"fmt"
+	"maps"
+	"math/rand"
+	"time"
+
+	"github.com/blue-context/warp/callback"
+	"github.com/blue-context/warp/cost"
+)
+
+// With returns a client derived from c with opts applied on top of c's
+// configuration.
+//
+// The derived client shares c's registered providers, probed capabilities
+// and custom pricing, so providers registered on either client are
+// available to both. Options such as timeouts, retries, callbacks, request
+// headers, and the cache apply to the derived client only; callbacks are
+// added to those of c. A derived client with a different budget, currency,
+// or cost tracking setting tracks its own budget; otherwise it shares c's.
+// Likewise, a derived client with different bulkheads has its own slots.
+//
+// Closing a derived client closes only a cache that it set itself.
+//
+// Example:
+//
+//	tenant, err := client.With(
+//	    warp.WithTimeout(10*time.Second),
+//	    warp.WithMaxBudget(5),
+//	    warp.WithSuccessCallback(recordTenantUsage),
+//	)
+func (c *client) With(opts ...ClientOption) (Client, error) {
+	config := c.config.clone()
+	for _, opt := range opts {
+		if err := opt(config); err != nil {
+			return nil, fmt.Errorf("failed to apply option: %w", err)
+		}
+	}
+	if err := config.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid configuration: %w", err)
+	}
+
+	if config.Callbacks != nil {
+		config.Callbacks.SetPanicRecovery(!config.DisablePanicRecovery)
+	}
+
+	d := &client{
+		config:    config,
+		parent:    c.root(),
+		costCalc:  c.costCalc,
+		budget:    c.budget,
+		cache:     config.Cache,
+		callbacks: config.callbackRegistry(),
+		bulkheads: c.bulkheads,
+		randSrc:   rand.New(rand.NewSource(time.Now().UnixNano())),
+	}
+	if !maps.Equal(config.Bulkheads, c.config.Bulkheads) {
+		d.bulkheads = newBulkheads(config.Bulkheads)
+	}
+	if config.TrackCost != c.config.TrackCost || config.MaxBudget != c.config.MaxBudget || config.Currency != c.config.Currency {
+		d.budget = nil
+		if config.TrackCost && config.MaxBudget > 0 {
+			if config.Currency != nil {
+				d.budget = cost.NewBudgetManagerInCurrency(config.MaxBudget, config.Currency)
+			} else {
+				d.budget = cost.NewBudgetManager(config.MaxBudget)
+			}
+		}
+	}
+	return d, nil
+}
+
+// root returns the client that holds the providers shared by c and the
+// clients derived from it.
+func (c *client) root() *client {
+	if c.parent != nil {
+		return c.parent
+	}
+	return c
+}
+
+// clone returns a copy of the configuration whose maps, slices, and
+// callback registry can be changed without affecting c.
+func (c *ClientConfig) clone() *ClientConfig {
+	config := *c
+	config.APIKeys = make(map[string]string, len(c.APIKeys))
+	for k, v := range c.APIKeys {
+		config.APIKeys[k] = v
+	}
+	config.APIBases = make(map[string]string, len(c.APIBases))
+	for k, v := range c.APIBases {
+		config.APIBases[k] = v
+	}
+	config.FallbackModels = append([]string(nil), c.FallbackModels...)
+	config.RequestMiddleware = append([]RequestMiddleware(nil), c.RequestMiddleware...)
+	config.RequestHeaders = c.RequestHeaders.Clone()
+	config.RequestDefaults = append([]RequestDefaults(nil), c.RequestDefaults...)
+	config.Bulkheads = maps.Clone(c.Bulkheads)
+	config.ContextWindowUpgrades = maps.Clone(c.ContextWindowUpgrades)
+	if c.Callbacks != nil {
+		config.Callbacks = c.Callbacks.Clone()
+	}
+	if c.RequestLimits != nil {
+		limits := *c.RequestLimits
+		config.RequestLimits = &limits
+	}
+	return &config
+}
+
+// callbackRegistry returns the callbacks a client built from the
+// configuration runs: the configured callbacks, plus the completion logger
+// in debug mode (except in the minimal build profile). The configured registry is not modified.
+func (c *ClientConfig) callbackRegistry() *callback.Registry {
+	if !c.Debug {
+		return c.Callbacks
+	}
+	registry := callback.NewRegistry()
+	if c.Callbacks != nil {
+		registry = c.Callbacks.Clone()
+	}
+	registry.SetPanicRecovery(!c.DisablePanicRecovery)
+	if logger := debugLogger(); logger != nil {
+		registry.RegisterSuccess(logger)
+	}
+	return registry
+}
@@ -0,0 +1,87 @@
+package warp
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/blue-context/warp/callback"
+)
+
+func TestClientWith(t *testing.T) {
+	var parentEvents, tenantEvents int
+	parent, err := NewClient(
+		WithTimeout(30*time.Second),
+		WithRequestHeader("X-Title", "App"),
+		WithSuccessCallback(func(ctx context.Context, event *callback.SuccessEvent) { parentEvents++ }),
+	)
+	if err != nil {
+		t.Fatalf("NewClient() error = %v", err)
+	}
+	defer parent.Close()
+
+	tenant, err := parent.With(
+		WithTimeout(5*time.Second),
+		WithRequestHeader("X-Title", "Tenant"),
+		WithSuccessCallback(func(ctx context.Context, event *callback.SuccessEvent) { tenantEvents++ }),
+	)
+	if err != nil {
+		t.Fatalf("With() error = %v", err)
+	}
+	defer tenant.Close()
+
+	// Providers registered on either client are shared
+	var deadline time.Duration
+	var title string
+	mock := &mockProvider{
+		name: "test",
+		completionFunc: func(ctx context.Context, req *CompletionRequest) (*CompletionResponse, error) {
+			if d, ok := ctx.Deadline(); ok {
+				deadline = time.Until(d)
+			}
+			title = RequestHeadersFromContext(ctx).Get("X-Title")
+			return &CompletionResponse{ID: "test"}, nil
+		},
+	}
+	if err := tenant.RegisterProvider(mock); err != nil {
+		t.Fatalf("RegisterProvider() error = %v", err)
+	}
+
+	req := &CompletionRequest{Model: "test/model", Messages: []Message{{Role: "user", Content: "Hi"}}}
+	if _, err := parent.Completion(context.Background(), req); err != nil {
+		t.Fatalf("parent Completion() error = %v", err)
+	}
+	if deadline <= 5*time.Second || title != "App" {
+		t.Errorf("parent deadline = %v, X-Title = %q, want 30s and App", deadline, title)
+	}
+	if parentEvents != 1 || tenantEvents != 0 {
+		t.Errorf("parent request events = %d, %d, want 1, 0", parentEvents, tenantEvents)
+	}
+
+	if _, err := tenant.Completion(context.Background(), req); err != nil {
+		t.Fatalf("tenant Completion() error = %v", err)
+	}
+	if deadline > 5*time.Second || title != "Tenant" {
+		t.Errorf("tenant deadline = %v, X-Title = %q, want 5s and Tenant", deadline, title)
+	}
+	if parentEvents != 2 || tenantEvents != 1 {
+		t.Errorf("tenant request events = %d, %d, want 2, 1 (parent callbacks are inherited)", parentEvents, tenantEvents)
+	}
+
+	if err := parent.RegisterProvider(&mockProvider{name: "test"}); err == nil {
+		t.Error("RegisterProvider() on the parent accepted a provider already registered on the derived client")
+	}
+}
+
+func TestClientWithInvalidOption(t *testing.T) {
+	parent, err := NewClient()
+	if err != nil {
+		t.Fatalf("NewClient() error = %v", err)
+	}
+	_, err = parent.With(WithMaxBudget(-5))
+	var cfgErr *ConfigError
+	if !errors.As(err, &cfgErr) || cfgErr.Field != "MaxBudget" {
+		t.Errorf("With() error = %v, want MaxBudget ConfigError", err)
+	}
+}
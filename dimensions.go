@@ -0,0 +1,83 @@
+package warp
+
+import (
+	"fmt"
+	"math"
+)
+
+// TruncateEmbedding shortens an embedding to its first dims dimensions and
+// rescales it to unit length.
+//
+// Models trained with Matryoshka representation learning, such as OpenAI's
+// text-embedding-3 models, keep most of their accuracy when truncated this
+// way, so embeddings can be stored at a smaller size than the model
+// returns, or several sizes can be derived from one request.
+//
+// Returns an error if dims is not positive or larger than the embedding.
+//
+// Example:
+//
+//	short, err := warp.TruncateEmbedding(resp.Data[0].Embedding, 256)
+func TruncateEmbedding(embedding []float64, dims int) ([]float64, error) {
+	if dims <= 0 {
+		return nil, fmt.Errorf("dimensions must be positive, got %d", dims)
+	}
+	if dims > len(embedding) {
+		return nil, fmt.Errorf("cannot truncate %d-dimensional embedding to %d dimensions", len(embedding), dims)
+	}
+
+	out := make([]float64, dims)
+	copy(out, embedding)
+	var sum float64
+	for _, v := range out {
+		sum += v * v
+	}
+	if norm := math.Sqrt(sum); norm > 0 {
+		for i := range out {
+			out[i] /= norm
+		}
+	}
+	return out, nil
+}
+
+// Truncate shortens every embedding in the response to dims dimensions
+// with TruncateEmbedding.
+//
+// Example:
+//
+//	resp, err := client.Embedding(ctx, req)
+//	if err != nil {
+//	    return err
+//	}
+//	if err := resp.Truncate(512); err != nil {
+//	    return err
+//	}
+func (r *EmbeddingResponse) Truncate(dims int) error {
+	for i := range r.Data {
+		embedding, err := TruncateEmbedding(r.Data[i].Embedding, dims)
+		if err != nil {
+			return fmt.Errorf("embedding %d: %w", r.Data[i].Index, err)
+		}
+		r.Data[i].Embedding = embedding
+	}
+	return nil
+}
+
+// checkDimensions rejects a request for a reduced embedding size unless the
+// provider's model registry lists the model as supporting it. Models
+// missing from the registry are not checked.
+func (c *client) checkDimensions(req *EmbeddingRequest, providerName, modelName string) error {
+	if req.Dimensions == nil {
+		return nil
+	}
+	if *req.Dimensions <= 0 {
+		return NewInvalidRequestError(fmt.Sprintf("dimensions must be positive, got %d", *req.Dimensions), providerName, nil)
+	}
+	info, err := c.costCalc.GetModelInfo(providerName, modelName)
+	if err != nil || info.SupportsDimensions {
+		return nil
+	}
+	return NewInvalidRequestError(
+		fmt.Sprintf("model %s does not support dimensions; use warp.TruncateEmbedding on the response for models trained for it", modelName),
+		providerName, nil)
+}
@@ -0,0 +1,68 @@
+package warp
+
+import (
+	"context"
+	"errors"
+	"math"
+	"testing"
+
+	"github.com/blue-context/warp/types"
+)
+
+func TestTruncateEmbedding(t *testing.T) {
+	got, err := TruncateEmbedding([]float64{3, 4, 12}, 2)
+	if err != nil {
+		t.Fatalf("TruncateEmbedding() error = %v", err)
+	}
+	if len(got) != 2 || math.Abs(got[0]-0.6) > 1e-9 || math.Abs(got[1]-0.8) > 1e-9 {
+		t.Errorf("TruncateEmbedding() = %v, want [0.6 0.8]", got)
+	}
+
+	for _, dims := range []int{0, 4} {
+		if _, err := TruncateEmbedding([]float64{3, 4, 12}, dims); err == nil {
+			t.Errorf("TruncateEmbedding(%d) error = nil, want error", dims)
+		}
+	}
+
+	resp := &EmbeddingResponse{Data: []Embedding{{Embedding: []float64{0, 2, 1}}}}
+	if err := resp.Truncate(2); err != nil {
+		t.Fatalf("Truncate() error = %v", err)
+	}
+	if e := resp.Data[0].Embedding; len(e) != 2 || e[1] != 1 {
+		t.Errorf("Truncate() embedding = %v, want [0 1]", e)
+	}
+}
+
+func TestEmbeddingDimensionsValidation(t *testing.T) {
+	client, err := NewClient()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+	client.RegisterProvider(&mockProvider{
+		name: "mock",
+		modelInfo: map[string]*types.ModelInfo{
+			"mrl":    {Name: "mrl", SupportsDimensions: true},
+			"legacy": {Name: "legacy"},
+		},
+	})
+
+	tests := []struct {
+		model   string
+		dims    int
+		wantErr bool
+	}{
+		{"mock/mrl", 256, false},
+		{"mock/legacy", 256, true},
+		{"mock/unknown", 256, false},
+		{"mock/mrl", 0, true},
+	}
+	for _, tt := range tests {
+		dims := tt.dims
+		_, err := client.Embedding(context.Background(), &EmbeddingRequest{Model: tt.model, Input: "hi", Dimensions: &dims})
+		var invalid *InvalidRequestError
+		if tt.wantErr != errors.As(err, &invalid) {
+			t.Errorf("Embedding(%s, %d) error = %v, wantErr %v", tt.model, tt.dims, err, tt.wantErr)
+		}
+	}
+}
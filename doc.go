@@ -3,4 +3,25 @@
 // consistent OpenAI-compatible API format.
 //
 // This package is currently under active development.
+//
+// # Minimal build profile
+//
+// For WebAssembly edge runtimes, the warp_minimal build tag (set
+// automatically by TinyGo through its tinygo tag) leaves out OS-specific
+// and heavy dependencies: log/slog, runtime/debug, the testing package and
+// the file system. The client, the sse package and the OpenAI-compatible
+// provider (provider/openaicompat) compile to js/wasm and wasip1/wasm in
+// this profile:
+//
+//	GOOS=js GOARCH=wasm go build -tags warp_minimal ./...
+//
+// The profile differs from the default build in that:
+//   - ImageData.SaveToFile is not available
+//   - LogCompletions is not available and debug mode does not log
+//   - errors for recovered panics carry no stack trace
+//   - the provider compliance assertions (provider.AssertProviderCompliance
+//     and related) are not available
+//
+// On wasip1, requests need an HTTP client from the host, set with
+// WithHTTPClient; js/wasm uses the runtime's fetch API.
 package warp
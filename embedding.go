@@ -3,7 +3,6 @@ package warp
 import (
 	"context"
 	"fmt"
-	"time"
 )
 
 // Embedding creates embeddings for the given input.
@@ -25,7 +24,7 @@ func (c *client) Embedding(ctx context.Context, req *EmbeddingRequest) (*Embeddi
 	}
 
 	// Add start time to context
-	ctx = WithStartTime(ctx, time.Now())
+	ctx = WithStartTime(ctx, c.now())
 
 	// Parse model string
 	providerName, modelName, err := parseModel(req.Model)
@@ -36,6 +35,7 @@ func (c *client) Embedding(ctx context.Context, req *EmbeddingRequest) (*Embeddi
 	// Add provider and model to context
 	ctx = WithProvider(ctx, providerName)
 	ctx = WithModel(ctx, modelName)
+	ctx = c.withRequestHeaders(ctx)
 
 	// Get provider
 	p, err := c.getProvider(providerName)
@@ -43,27 +43,51 @@ func (c *client) Embedding(ctx context.Context, req *EmbeddingRequest) (*Embeddi
 		return nil, fmt.Errorf("provider %q not found (did you register it?)", providerName)
 	}
 
+	// Reject dimensions the model does not accept
+	if err := c.checkDimensions(req, providerName, modelName); err != nil {
+		return nil, err
+	}
+
+	// Refuse the request once the budget is spent
+	if err := c.checkBudget(providerName, modelName); err != nil {
+		return nil, err
+	}
+
 	// Update model name in request
 	req.Model = modelName
 
 	// Apply timeout
 	if c.config.DefaultTimeout > 0 {
 		var cancel context.CancelFunc
-		ctx, cancel = context.WithTimeout(ctx, c.config.DefaultTimeout)
+		ctx, cancel = c.withTimeout(ctx, c.config.DefaultTimeout)
 		defer cancel()
 	}
 
 	// Call provider with retries
 	var resp *EmbeddingResponse
 	err = c.withRetry(ctx, func() error {
-		var callErr error
-		resp, callErr = p.Embedding(ctx, req)
-		return callErr
+		return c.callProvider(ctx, providerName, func() error {
+			var callErr error
+			resp, callErr = p.Embedding(ctx, req)
+			return callErr
+		})
 	})
 
 	if err != nil {
 		return nil, err
 	}
 
+	// Set metadata
+	resp.Provider = providerName
+	resp.RequestID = RequestIDFromContext(ctx)
+	resp.ProviderRequestID = ProviderRequestIDFromContext(ctx)
+
+	// Charge the cost to the budget
+	if c.budget != nil {
+		if cost, err := c.EmbeddingCost(resp); err == nil {
+			c.chargeBudget(providerName, modelName, cost)
+		}
+	}
+
 	return resp, nil
 }
@@ -0,0 +1,238 @@
+// Package encrypt provides AES-GCM encryption at rest with key rotation.
+//
+// Keys are supplied by a KeyProvider. Data is sealed with the provider's
+// current key and records that key's ID, so data written before a rotation
+// can still be opened as long as the old key remains available.
+//
+// Basic usage:
+//
+//	keys, err := encrypt.NewKeyRing("2024-06", key)
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+//
+//	sealed, err := encrypt.Seal(ctx, keys, plaintext, nil)
+//	plaintext, err = encrypt.Open(ctx, keys, sealed, nil)
+//
+// Rotating keys:
+//
+//	keys.Rotate("2024-09", newKey) // new data uses 2024-09; 2024-06 still decrypts
+//
+// The cache, audit, and transcript packages build on this package to keep
+// prompts and responses out of storage in plaintext.
+package encrypt
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"fmt"
+	"sync"
+)
+
+// version is the first byte of sealed data.
+const version byte = 1
+
+// KeyProvider supplies encryption keys.
+//
+// Keys must be 16, 24, or 32 bytes (AES-128, AES-192, or AES-256). A
+// provider backed by a KMS or secret manager can fetch keys on demand.
+//
+// Thread Safety: Implementations must be safe for concurrent use.
+type KeyProvider interface {
+	// CurrentKey returns the ID and bytes of the key new data is sealed with.
+	CurrentKey(ctx context.Context) (id string, key []byte, err error)
+
+	// Key returns the key with the given ID, including retired keys that
+	// may still be needed to open old data.
+	Key(ctx context.Context, id string) ([]byte, error)
+}
+
+// KeyRing is an in-memory KeyProvider holding a current key and any number
+// of previous keys.
+//
+// Thread Safety: KeyRing is safe for concurrent use.
+type KeyRing struct {
+	mu      sync.RWMutex
+	current string
+	keys    map[string][]byte
+}
+
+// Compile-time interface check
+var _ KeyProvider = (*KeyRing)(nil)
+
+// NewKeyRing creates a key ring whose current key is key, identified by id.
+func NewKeyRing(id string, key []byte) (*KeyRing, error) {
+	r := &KeyRing{keys: make(map[string][]byte)}
+	if err := r.Rotate(id, key); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// Rotate adds key under id and makes it the current key. Previous keys
+// remain available for opening existing data.
+func (r *KeyRing) Rotate(id string, key []byte) error {
+	if id == "" || len(id) > 255 {
+		return fmt.Errorf("key ID must be 1 to 255 bytes")
+	}
+	if _, err := aes.NewCipher(key); err != nil {
+		return fmt.Errorf("invalid key %q: %w", id, err)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.keys[id] = append([]byte(nil), key...)
+	r.current = id
+	return nil
+}
+
+// Remove deletes a retired key. Data sealed with it can no longer be
+// opened. The current key cannot be removed.
+func (r *KeyRing) Remove(id string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if id == r.current {
+		return fmt.Errorf("cannot remove current key %q", id)
+	}
+	delete(r.keys, id)
+	return nil
+}
+
+// CurrentKey returns the current key.
+func (r *KeyRing) CurrentKey(ctx context.Context) (string, []byte, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return r.current, r.keys[r.current], nil
+}
+
+// Key returns the key with the given ID.
+func (r *KeyRing) Key(ctx context.Context, id string) ([]byte, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	key, ok := r.keys[id]
+	if !ok {
+		return nil, fmt.Errorf("unknown key %q", id)
+	}
+	return key, nil
+}
+
+// Seal encrypts plaintext with the provider's current key.
+//
+// additionalData is authenticated but not encrypted; the same value must be
+// passed to Open. Use it to bind ciphertext to its context (e.g., a cache
+// key) so it cannot be moved elsewhere. It may be nil.
+//
+// The result holds the format version, key ID, nonce, and ciphertext.
+func Seal(ctx context.Context, keys KeyProvider, plaintext, additionalData []byte) ([]byte, error) {
+	id, key, err := keys.CurrentKey(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get encryption key: %w", err)
+	}
+	if id == "" || len(id) > 255 {
+		return nil, fmt.Errorf("key ID must be 1 to 255 bytes")
+	}
+	aead, err := newAEAD(key)
+	if err != nil {
+		return nil, err
+	}
+
+	header := make([]byte, 0, 2+len(id)+aead.NonceSize())
+	header = append(header, version, byte(len(id)))
+	header = append(header, id...)
+
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, fmt.Errorf("failed to generate nonce: %w", err)
+	}
+	header = append(header, nonce...)
+
+	return aead.Seal(header, nonce, plaintext, additionalData), nil
+}
+
+// Open decrypts data produced by Seal, using the key it was sealed with.
+func Open(ctx context.Context, keys KeyProvider, data, additionalData []byte) ([]byte, error) {
+	id, rest, err := parse(data)
+	if err != nil {
+		return nil, err
+	}
+
+	key, err := keys.Key(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get decryption key: %w", err)
+	}
+	aead, err := newAEAD(key)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(rest) < aead.NonceSize() {
+		return nil, fmt.Errorf("sealed data is truncated")
+	}
+	nonce, ciphertext := rest[:aead.NonceSize()], rest[aead.NonceSize():]
+
+	plaintext, err := aead.Open(nil, nonce, ciphertext, additionalData)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt with key %q: %w", id, err)
+	}
+	return plaintext, nil
+}
+
+// KeyID returns the ID of the key data was sealed with.
+func KeyID(data []byte) (string, error) {
+	id, _, err := parse(data)
+	return id, err
+}
+
+// Reseal re-encrypts data with the current key if it was sealed with an
+// older one, reporting whether it changed. Use it to migrate stored data
+// before removing a retired key.
+func Reseal(ctx context.Context, keys KeyProvider, data, additionalData []byte) ([]byte, bool, error) {
+	id, err := KeyID(data)
+	if err != nil {
+		return nil, false, err
+	}
+	current, _, err := keys.CurrentKey(ctx)
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to get encryption key: %w", err)
+	}
+	if id == current {
+		return data, false, nil
+	}
+
+	plaintext, err := Open(ctx, keys, data, additionalData)
+	if err != nil {
+		return nil, false, err
+	}
+	sealed, err := Seal(ctx, keys, plaintext, additionalData)
+	if err != nil {
+		return nil, false, err
+	}
+	return sealed, true, nil
+}
+
+// parse splits sealed data into its key ID and the nonce and ciphertext.
+func parse(data []byte) (string, []byte, error) {
+	if len(data) < 2 || data[0] != version {
+		return "", nil, fmt.Errorf("data is not sealed or has an unsupported format")
+	}
+	n := int(data[1])
+	if n == 0 || len(data) < 2+n {
+		return "", nil, fmt.Errorf("sealed data is truncated")
+	}
+	return string(data[2 : 2+n]), data[2+n:], nil
+}
+
+// newAEAD creates an AES-GCM cipher from key.
+func newAEAD(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("invalid key: %w", err)
+	}
+	return cipher.NewGCM(block)
+}
@@ -0,0 +1,99 @@
+package encrypt
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+)
+
+func key(b byte) []byte {
+	return bytes.Repeat([]byte{b}, 32)
+}
+
+func TestSealOpen(t *testing.T) {
+	ctx := context.Background()
+	keys, err := NewKeyRing("k1", key(1))
+	if err != nil {
+		t.Fatalf("NewKeyRing() error = %v", err)
+	}
+
+	sealed, err := Seal(ctx, keys, []byte("secret prompt"), []byte("aad"))
+	if err != nil {
+		t.Fatalf("Seal() error = %v", err)
+	}
+	if bytes.Contains(sealed, []byte("secret")) {
+		t.Error("sealed data contains plaintext")
+	}
+	if id, _ := KeyID(sealed); id != "k1" {
+		t.Errorf("KeyID() = %q, want k1", id)
+	}
+
+	plaintext, err := Open(ctx, keys, sealed, []byte("aad"))
+	if err != nil || string(plaintext) != "secret prompt" {
+		t.Fatalf("Open() = %q, %v", plaintext, err)
+	}
+
+	if _, err := Open(ctx, keys, sealed, []byte("other")); err == nil {
+		t.Error("Open() with wrong additional data succeeded")
+	}
+
+	tampered := append([]byte(nil), sealed...)
+	tampered[len(tampered)-1] ^= 1
+	if _, err := Open(ctx, keys, tampered, []byte("aad")); err == nil {
+		t.Error("Open() of tampered data succeeded")
+	}
+
+	for _, bad := range [][]byte{nil, []byte("plain"), {version, 5, 'k'}, sealed[:6]} {
+		if _, err := Open(ctx, keys, bad, nil); err == nil {
+			t.Errorf("Open(%q) succeeded", bad)
+		}
+	}
+}
+
+func TestKeyRotation(t *testing.T) {
+	ctx := context.Background()
+	keys, _ := NewKeyRing("k1", key(1))
+	old, _ := Seal(ctx, keys, []byte("old"), nil)
+
+	if err := keys.Rotate("k2", key(2)); err != nil {
+		t.Fatalf("Rotate() error = %v", err)
+	}
+	current, _ := Seal(ctx, keys, []byte("new"), nil)
+	if id, _ := KeyID(current); id != "k2" {
+		t.Errorf("KeyID() after rotation = %q, want k2", id)
+	}
+	if plaintext, err := Open(ctx, keys, old, nil); err != nil || string(plaintext) != "old" {
+		t.Errorf("Open() of old data = %q, %v", plaintext, err)
+	}
+
+	resealed, changed, err := Reseal(ctx, keys, old, nil)
+	if err != nil || !changed {
+		t.Fatalf("Reseal() changed = %v, error = %v", changed, err)
+	}
+	if _, changed, _ := Reseal(ctx, keys, resealed, nil); changed {
+		t.Error("Reseal() of current data changed it")
+	}
+
+	if err := keys.Remove("k2"); err == nil {
+		t.Error("Remove() of current key succeeded")
+	}
+	if err := keys.Remove("k1"); err != nil {
+		t.Fatalf("Remove() error = %v", err)
+	}
+	if _, err := Open(ctx, keys, old, nil); err == nil || !strings.Contains(err.Error(), "unknown key") {
+		t.Errorf("Open() with removed key error = %v", err)
+	}
+	if plaintext, err := Open(ctx, keys, resealed, nil); err != nil || string(plaintext) != "old" {
+		t.Errorf("Open() of resealed data = %q, %v", plaintext, err)
+	}
+}
+
+func TestNewKeyRingErrors(t *testing.T) {
+	if _, err := NewKeyRing("", key(1)); err == nil {
+		t.Error("NewKeyRing() with empty ID succeeded")
+	}
+	if _, err := NewKeyRing("k", []byte("short")); err == nil {
+		t.Error("NewKeyRing() with invalid key succeeded")
+	}
+}
@@ -3,6 +3,7 @@ package warp
 import (
 	"encoding/json"
 	"fmt"
+	"sort"
 	"strings"
 	"time"
 )
@@ -178,6 +179,85 @@ func NewContentPolicyViolationError(message string, provider string, err error)
 	}
 }
 
+// ContentFilterResult is a provider's safety verdict for one category.
+type ContentFilterResult struct {
+	// Category is the provider's category name (e.g., "HARM_CATEGORY_HARASSMENT", "violence").
+	Category string
+
+	// Severity is the provider's severity or probability level
+	// (e.g., "HIGH", "medium").
+	Severity string
+
+	// Filtered reports whether this category caused the block.
+	Filtered bool
+}
+
+// ContentFilterError represents generation blocked by a provider's safety
+// or content filter, such as a Gemini SAFETY block or an Azure OpenAI
+// content filter. Results carries the per-category verdicts, so
+// applications can branch on what was filtered.
+//
+// ContentFilterError is also a *ContentPolicyViolationError, so callers
+// matching that type with errors.As keep matching content filter blocks.
+//
+// Example:
+//
+//	var filtered *warp.ContentFilterError
+//	if errors.As(err, &filtered) {
+//	    for _, r := range filtered.Results {
+//	        log.Printf("%s: %s (blocked: %v)", r.Category, r.Severity, r.Filtered)
+//	    }
+//	}
+type ContentFilterError struct {
+	ContentPolicyViolationError
+
+	// Prompt is true if the prompt was blocked, false if the output was.
+	Prompt bool
+
+	// Reason is the provider's block reason (e.g., "SAFETY", "content_filter").
+	Reason string
+
+	// Results holds the per-category verdicts reported by the provider.
+	Results []ContentFilterResult
+}
+
+// NewContentFilterError creates a new content filter error.
+func NewContentFilterError(message, provider, reason string, prompt bool, results []ContentFilterResult) *ContentFilterError {
+	return &ContentFilterError{
+		ContentPolicyViolationError: ContentPolicyViolationError{
+			WarpError: WarpError{
+				Message:    message,
+				StatusCode: 400,
+				Provider:   provider,
+			},
+		},
+		Prompt:  prompt,
+		Reason:  reason,
+		Results: results,
+	}
+}
+
+// As makes errors.As match a *ContentPolicyViolationError target with the
+// embedded error.
+func (e *ContentFilterError) As(target any) bool {
+	if t, ok := target.(**ContentPolicyViolationError); ok {
+		*t = &e.ContentPolicyViolationError
+		return true
+	}
+	return false
+}
+
+// FilteredCategories returns the categories that caused the block.
+func (e *ContentFilterError) FilteredCategories() []string {
+	var categories []string
+	for _, r := range e.Results {
+		if r.Filtered {
+			categories = append(categories, r.Category)
+		}
+	}
+	return categories
+}
+
 // InvalidRequestError represents an invalid request error (400).
 // This occurs when the request parameters are malformed or invalid.
 type InvalidRequestError struct {
@@ -262,6 +342,126 @@ func NewBadRequestError(message string, provider string, err error) *BadRequestE
 	}
 }
 
+// PolicyViolationError represents a request rejected by a client-side policy
+// before it was sent. See the policy package.
+type PolicyViolationError struct {
+	WarpError
+
+	// Rule identifies the violated rule (e.g., "allowed_providers",
+	// "banned_models", "max_context_tokens", "moderation").
+	Rule string
+
+	// Tenant is the tenant the request was made for, if any.
+	Tenant string
+}
+
+// NewPolicyViolationError creates a new policy violation error.
+func NewPolicyViolationError(message, rule, tenant, provider, model string) *PolicyViolationError {
+	return &PolicyViolationError{
+		WarpError: WarpError{
+			Message:    message,
+			StatusCode: 403,
+			Provider:   provider,
+			Model:      model,
+		},
+		Rule:   rule,
+		Tenant: tenant,
+	}
+}
+
+// BulkheadFullError represents a request rejected by the client because
+// its provider's bulkhead was full (see WithBulkhead). The request was not
+// sent.
+type BulkheadFullError struct {
+	WarpError
+
+	// Limit is the bulkhead's maximum number of concurrent requests.
+	Limit int
+}
+
+// NewBulkheadFullError creates a new bulkhead full error.
+func NewBulkheadFullError(provider string, limit int) *BulkheadFullError {
+	return &BulkheadFullError{
+		WarpError: WarpError{
+			Message:    fmt.Sprintf("bulkhead full: %d concurrent requests in flight", limit),
+			StatusCode: 503,
+			Provider:   provider,
+		},
+		Limit: limit,
+	}
+}
+
+// BudgetExceededError represents a request refused because the client's
+// spend has reached its budget limit (see WithMaxBudget). The request was
+// not sent.
+type BudgetExceededError struct {
+	WarpError
+}
+
+// NewBudgetExceededError creates a new budget exceeded error.
+func NewBudgetExceededError(message, provider, model string, err error) *BudgetExceededError {
+	return &BudgetExceededError{
+		WarpError: WarpError{
+			Message:       message,
+			StatusCode:    402,
+			Provider:      provider,
+			Model:         model,
+			OriginalError: err,
+		},
+	}
+}
+
+// ResponseTooLargeError represents a provider response body larger than
+// the configured limit (see WithMaxResponseSize). The body was not read
+// past the limit.
+type ResponseTooLargeError struct {
+	WarpError
+
+	// Limit is the maximum response size in bytes.
+	Limit int64
+}
+
+// NewResponseTooLargeError creates a new response too large error.
+func NewResponseTooLargeError(provider string, limit int64) *ResponseTooLargeError {
+	return &ResponseTooLargeError{
+		WarpError: WarpError{
+			Message:    fmt.Sprintf("response body exceeds %d bytes", limit),
+			StatusCode: 502,
+			Provider:   provider,
+		},
+		Limit: limit,
+	}
+}
+
+// InternalError represents a panic in provider or user code that the
+// client recovered instead of crashing the process. HiddenParams holds
+// the panic value under "_panic" and the goroutine's stack trace under
+// "_stack". See WithPanicRecovery.
+type InternalError struct {
+	WarpError
+
+	// HiddenParams contains debugging metadata (prefixed with _).
+	HiddenParams map[string]any
+}
+
+// NewInternalError creates a new internal error for a recovered panic
+// with value and stack trace.
+func NewInternalError(message, provider string, value any, stack []byte) *InternalError {
+	err, _ := value.(error)
+	return &InternalError{
+		WarpError: WarpError{
+			Message:       fmt.Sprintf("%s: %v", message, value),
+			StatusCode:    500,
+			Provider:      provider,
+			OriginalError: err,
+		},
+		HiddenParams: map[string]any{
+			"_panic": fmt.Sprint(value),
+			"_stack": string(stack),
+		},
+	}
+}
+
 // ParseProviderError parses a provider-specific error response into a typed Warp error.
 // This function attempts to parse JSON error responses and maps HTTP status codes
 // to appropriate error types.
@@ -280,9 +480,15 @@ func ParseProviderError(provider string, statusCode int, body []byte, err error)
 	// Attempt to parse JSON error response
 	var errorResp struct {
 		Error struct {
-			Message string `json:"message"`
-			Type    string `json:"type"`
-			Code    string `json:"code"`
+			Message    string `json:"message"`
+			Type       string `json:"type"`
+			Code       string `json:"code"`
+			InnerError struct {
+				ContentFilterResult map[string]struct {
+					Filtered bool   `json:"filtered"`
+					Severity string `json:"severity"`
+				} `json:"content_filter_result"`
+			} `json:"innererror"`
 		} `json:"error"`
 	}
 
@@ -299,6 +505,19 @@ func ParseProviderError(provider string, statusCode int, body []byte, err error)
 		message = fmt.Sprintf("HTTP %d error", statusCode)
 	}
 
+	// Content filter blocks (Azure OpenAI) carry per-category verdicts
+	if errorResp.Error.Code == "content_filter" {
+		var results []ContentFilterResult
+		for category, r := range errorResp.Error.InnerError.ContentFilterResult {
+			results = append(results, ContentFilterResult{Category: category, Severity: r.Severity, Filtered: r.Filtered})
+		}
+		sort.Slice(results, func(i, j int) bool { return results[i].Category < results[j].Category })
+		filterErr := NewContentFilterError(message, provider, errorResp.Error.Code, true, results)
+		filterErr.StatusCode = statusCode
+		filterErr.OriginalError = err
+		return filterErr
+	}
+
 	// Map status codes to specific error types
 	switch statusCode {
 	case 401:
@@ -170,6 +170,11 @@ func TestIsRetryable(t *testing.T) {
 			err:           NewContentPolicyViolationError("policy violation", "openai", nil),
 			wantRetryable: false,
 		},
+		{
+			name:          "PolicyViolationError is not retryable",
+			err:           NewPolicyViolationError("model banned", "banned_models", "acme", "openai", "gpt-4"),
+			wantRetryable: false,
+		},
 	}
 
 	for _, tt := range tests {
@@ -258,6 +263,13 @@ func TestParseProviderError(t *testing.T) {
 			body:       []byte(`{"error":{"message":"Content blocked by safety filter"}}`),
 			wantType:   "*warp.ContentPolicyViolationError",
 		},
+		{
+			name:       "400 with content_filter code returns ContentFilterError",
+			provider:   "azure",
+			statusCode: 400,
+			body:       []byte(`{"error":{"message":"The response was filtered","code":"content_filter","innererror":{"content_filter_result":{"hate":{"filtered":true,"severity":"high"}}}}}`),
+			wantType:   "*warp.ContentFilterError",
+		},
 		{
 			name:       "400 with invalid message returns InvalidRequestError",
 			provider:   "openai",
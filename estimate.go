@@ -0,0 +1,133 @@
+package warp
+
+import (
+	"encoding/json"
+	"fmt"
+	"unicode/utf8"
+)
+
+// DefaultEstimatedOutputTokens is the output length assumed by
+// EstimateCompletionCost for requests without MaxTokens.
+const DefaultEstimatedOutputTokens = 256
+
+// Token overheads of the chat format, following OpenAI's accounting.
+const (
+	tokensPerMessage = 3
+	tokensPerName    = 1
+	tokensPerReply   = 3
+	tokensPerImage   = 85
+)
+
+// CostEstimate is the estimated size and cost of a completion request.
+type CostEstimate struct {
+	// Model is the model the estimate is for, in "provider/model-name"
+	// form.
+	Model string
+
+	// InputTokens is the estimated number of prompt tokens.
+	InputTokens int
+
+	// OutputTokens is the expected number of completion tokens across all
+	// choices: MaxTokens if the request sets it, otherwise
+	// DefaultEstimatedOutputTokens, limited to the model's maximum output.
+	OutputTokens int
+
+	// Cost is the estimated cost in USD.
+	Cost float64
+}
+
+// EstimateCompletionCost estimates the tokens and cost of req without
+// sending it, so budgets can be checked or a model chosen beforehand.
+//
+// Prompt tokens are counted with the client's TokenCounter over message
+// text, names, tool calls, tool definitions, and response schema, plus
+// the chat format overhead; images count 85 tokens each. Configured
+// request defaults for the model are applied first. The cost uses the
+// model's pricing and service tier multiplier.
+//
+// Returns an error if the model has no pricing information.
+//
+// Example:
+//
+//	estimate, err := client.EstimateCompletionCost(req)
+//	if err == nil && estimate.Cost > 0.10 {
+//	    req.Model = "openai/gpt-4o-mini"
+//	}
+func (c *client) EstimateCompletionCost(req *CompletionRequest) (*CostEstimate, error) {
+	if req == nil {
+		return nil, fmt.Errorf("request cannot be nil")
+	}
+	req = c.applyRequestDefaults(req)
+
+	providerName, modelName, err := parseModel(req.Model)
+	if err != nil {
+		return nil, err
+	}
+	info, err := c.costCalc.GetModelInfo(providerName, modelName)
+	if err != nil {
+		return nil, fmt.Errorf("no pricing for %s: %w", req.Model, err)
+	}
+
+	input := c.countRequestTokens(req)
+	output := DefaultEstimatedOutputTokens
+	if req.MaxTokens != nil && *req.MaxTokens > 0 {
+		output = *req.MaxTokens
+	}
+	if info.MaxOutputTokens > 0 && output > info.MaxOutputTokens {
+		output = info.MaxOutputTokens
+	}
+	if req.N != nil && *req.N > 1 {
+		output *= *req.N
+	}
+
+	usd := float64(input)/1_000_000*info.InputCostPer1M + float64(output)/1_000_000*info.OutputCostPer1M
+	if multiplier, ok := info.ServiceTierMultipliers[string(req.ServiceTier)]; ok {
+		usd *= multiplier
+	}
+
+	return &CostEstimate{
+		Model:        req.Model,
+		InputTokens:  input,
+		OutputTokens: output,
+		Cost:         usd,
+	}, nil
+}
+
+// tokenCounter returns the configured TokenCounter, or an estimate of one
+// token per four characters.
+func (c *client) tokenCounter() func(text string) int {
+	if c.config.TokenCounter != nil {
+		return c.config.TokenCounter
+	}
+	return func(text string) int {
+		return (utf8.RuneCountInString(text) + 3) / 4
+	}
+}
+
+// countRequestTokens estimates the prompt tokens of req.
+func (c *client) countRequestTokens(req *CompletionRequest) int {
+	count := c.tokenCounter()
+
+	tokens := tokensPerReply
+	for _, msg := range req.Messages {
+		tokens += tokensPerMessage + count(messageText(msg.Content))
+		tokens += countImages(msg.Content) * tokensPerImage
+		if msg.Name != "" {
+			tokens += tokensPerName + count(msg.Name)
+		}
+		for _, call := range msg.ToolCalls {
+			tokens += count(call.Function.Name) + count(call.Function.Arguments)
+		}
+	}
+	if len(req.Tools) > 0 {
+		if data, err := json.Marshal(req.Tools); err == nil {
+			tokens += count(string(data))
+		}
+	}
+	if req.ResponseFormat != nil && req.ResponseFormat.JSONSchema != nil {
+		if data, err := json.Marshal(req.ResponseFormat.JSONSchema); err == nil {
+			tokens += count(string(data))
+		}
+	}
+	return tokens
+}
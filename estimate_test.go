@@ -0,0 +1,69 @@
+package warp
+
+import (
+	"math"
+	"testing"
+
+	"github.com/blue-context/warp/types"
+)
+
+func TestEstimateCompletionCost(t *testing.T) {
+	client, err := NewClient(WithTokenCounter(func(text string) int { return len(text) }))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+	client.RegisterProvider(&mockProvider{
+		name: "mock",
+		modelInfo: map[string]*types.ModelInfo{
+			"m": {
+				Name:                   "m",
+				MaxOutputTokens:        1000,
+				InputCostPer1M:         1,
+				OutputCostPer1M:        2,
+				ServiceTierMultipliers: map[string]float64{"flex": 0.5},
+			},
+		},
+	})
+
+	messages := []Message{
+		{Role: "system", Content: "abcd"},
+		{Role: "user", Content: []ContentPart{{Type: "text", Text: "ef"}, {Type: "image_url", ImageURL: &ImageURL{URL: "x"}}}},
+	}
+	// 3 reply + (3 + 4) + (3 + 2 + 85)
+	const wantInput = 100
+
+	tests := []struct {
+		name       string
+		req        CompletionRequest
+		wantOutput int
+		wantCost   float64
+	}{
+		{"default output", CompletionRequest{Model: "mock/m", Messages: messages},
+			DefaultEstimatedOutputTokens, (wantInput*1 + DefaultEstimatedOutputTokens*2) / 1e6},
+		{"max tokens", CompletionRequest{Model: "mock/m", Messages: messages, MaxTokens: IntPtr(50)},
+			50, (wantInput + 100) / 1e6},
+		{"capped at model maximum", CompletionRequest{Model: "mock/m", Messages: messages, MaxTokens: IntPtr(5000), N: IntPtr(2)},
+			2000, (wantInput + 4000) / 1e6},
+		{"service tier", CompletionRequest{Model: "mock/m", Messages: messages, MaxTokens: IntPtr(50), ServiceTier: "flex"},
+			50, (wantInput + 100) / 1e6 * 0.5},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			estimate, err := client.EstimateCompletionCost(&tt.req)
+			if err != nil {
+				t.Fatalf("EstimateCompletionCost() error = %v", err)
+			}
+			if estimate.InputTokens != wantInput || estimate.OutputTokens != tt.wantOutput {
+				t.Errorf("tokens = %d/%d, want %d/%d", estimate.InputTokens, estimate.OutputTokens, wantInput, tt.wantOutput)
+			}
+			if math.Abs(estimate.Cost-tt.wantCost) > 1e-12 {
+				t.Errorf("Cost = %v, want %v", estimate.Cost, tt.wantCost)
+			}
+		})
+	}
+
+	if _, err := client.EstimateCompletionCost(&CompletionRequest{Model: "other/m"}); err == nil {
+		t.Error("EstimateCompletionCost() for unknown provider error = nil, want error")
+	}
+}
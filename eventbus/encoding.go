@@ -0,0 +1,108 @@
+package eventbus
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"math"
+)
+
+// Encoding is an event wire format.
+type Encoding string
+
+const (
+	// EncodingJSON encodes events as JSON objects.
+	EncodingJSON Encoding = "json"
+
+	// EncodingProtobuf encodes events as the warp.events.v1.Event protobuf
+	// message defined in event.proto.
+	EncodingProtobuf Encoding = "protobuf"
+)
+
+// ContentType returns the MIME type of the encoding.
+func (e Encoding) ContentType() string {
+	if e == EncodingProtobuf {
+		return "application/x-protobuf"
+	}
+	return "application/json"
+}
+
+// Encode encodes an event.
+func (e Encoding) Encode(ev *Event) ([]byte, error) {
+	switch e {
+	case EncodingJSON, "":
+		return json.Marshal(ev)
+	case EncodingProtobuf:
+		return marshalProto(ev), nil
+	default:
+		return nil, fmt.Errorf("unsupported encoding %q", e)
+	}
+}
+
+// Protobuf field numbers of warp.events.v1.Event.
+const (
+	fieldType             = 1
+	fieldTimeUnixNano     = 2
+	fieldRequestID        = 3
+	fieldProvider         = 4
+	fieldModel            = 5
+	fieldDurationNanos    = 6
+	fieldPromptTokens     = 7
+	fieldCompletionTokens = 8
+	fieldTotalTokens      = 9
+	fieldCost             = 10
+	fieldError            = 11
+	fieldCode             = 12
+	fieldMessage          = 13
+)
+
+// Protobuf wire types.
+const (
+	wireVarint  = 0
+	wireFixed64 = 1
+	wireBytes   = 2
+)
+
+// marshalProto encodes ev in the protobuf wire format. Zero values are
+// omitted, as proto3 does.
+func marshalProto(ev *Event) []byte {
+	var b []byte
+	b = appendString(b, fieldType, ev.Type)
+	if !ev.Time.IsZero() {
+		b = appendVarint(b, fieldTimeUnixNano, uint64(ev.Time.UnixNano()))
+	}
+	b = appendString(b, fieldRequestID, ev.RequestID)
+	b = appendString(b, fieldProvider, ev.Provider)
+	b = appendString(b, fieldModel, ev.Model)
+	b = appendVarint(b, fieldDurationNanos, uint64(ev.Duration))
+	b = appendVarint(b, fieldPromptTokens, uint64(ev.PromptTokens))
+	b = appendVarint(b, fieldCompletionTokens, uint64(ev.CompletionTokens))
+	b = appendVarint(b, fieldTotalTokens, uint64(ev.TotalTokens))
+	if ev.Cost != 0 {
+		b = binary.AppendUvarint(b, fieldCost<<3|wireFixed64)
+		b = binary.LittleEndian.AppendUint64(b, math.Float64bits(ev.Cost))
+	}
+	b = appendString(b, fieldError, ev.Error)
+	b = appendString(b, fieldCode, ev.Code)
+	b = appendString(b, fieldMessage, ev.Message)
+	return b
+}
+
+// appendVarint appends a non-zero varint field.
+func appendVarint(b []byte, field int, v uint64) []byte {
+	if v == 0 {
+		return b
+	}
+	b = binary.AppendUvarint(b, uint64(field)<<3|wireVarint)
+	return binary.AppendUvarint(b, v)
+}
+
+// appendString appends a non-empty length-delimited string field.
+func appendString(b []byte, field int, s string) []byte {
+	if s == "" {
+		return b
+	}
+	b = binary.AppendUvarint(b, uint64(field)<<3|wireBytes)
+	b = binary.AppendUvarint(b, uint64(len(s)))
+	return append(b, s...)
+}
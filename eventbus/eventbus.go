@@ -0,0 +1,439 @@
+// Package eventbus publishes client lifecycle events to a message bus such
+// as NATS or Kafka, for organizations that centralize LLM telemetry in
+// streaming pipelines.
+//
+// A Sink receives success, failure and warning callbacks, converts them to
+// Events, encodes them as JSON or protobuf (see event.proto), and publishes
+// them in batches from a background goroutine so callbacks never block on
+// the bus. The bus client is behind the one-method Publisher interface;
+// nats_example.go and kafka_example.go show implementations.
+//
+// Basic usage:
+//
+//	sink := eventbus.New(NewNATSPublisher(nc),
+//	    eventbus.WithTopic("llm.events"),
+//	    eventbus.WithEncoding(eventbus.EncodingProtobuf),
+//	)
+//	defer sink.Close(context.Background())
+//
+//	client, err := warp.NewClient(
+//	    warp.WithSuccessCallback(sink.Success),
+//	    warp.WithFailureCallback(sink.Failure),
+//	    warp.WithWarningCallback(sink.Warning),
+//	)
+package eventbus
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/blue-context/warp"
+	"github.com/blue-context/warp/callback"
+)
+
+// Event types.
+const (
+	TypeSuccess = "success"
+	TypeFailure = "failure"
+	TypeWarning = "warning"
+)
+
+// Event is a client lifecycle event.
+type Event struct {
+	// Type is TypeSuccess, TypeFailure, or TypeWarning.
+	Type string `json:"type"`
+
+	// Time is when the request completed or the warning was raised.
+	Time time.Time `json:"time"`
+
+	// RequestID identifies the request.
+	RequestID string `json:"request_id,omitempty"`
+
+	// Provider and Model identify the endpoint called.
+	Provider string `json:"provider"`
+	Model    string `json:"model"`
+
+	// Duration is the request duration (success and failure only).
+	Duration time.Duration `json:"duration_ns,omitempty"`
+
+	// PromptTokens, CompletionTokens, and TotalTokens are the token usage.
+	PromptTokens     int `json:"prompt_tokens,omitempty"`
+	CompletionTokens int `json:"completion_tokens,omitempty"`
+	TotalTokens      int `json:"total_tokens,omitempty"`
+
+	// Cost is the estimated cost in USD.
+	Cost float64 `json:"cost,omitempty"`
+
+	// Error is the failure message.
+	Error string `json:"error,omitempty"`
+
+	// Code and Message are the warning code and message.
+	Code    string `json:"code,omitempty"`
+	Message string `json:"message,omitempty"`
+}
+
+// Message is an encoded event ready to publish.
+type Message struct {
+	// Topic is the subject (NATS) or topic (Kafka) to publish to.
+	Topic string
+
+	// Key is the request ID, for partitioning events of one request
+	// together. It is empty if the request has no ID.
+	Key []byte
+
+	// Value is the encoded event.
+	Value []byte
+
+	// ContentType is "application/json" or "application/x-protobuf".
+	ContentType string
+}
+
+// Publisher publishes a batch of messages to a message bus.
+//
+// Publish is called from a single goroutine, so implementations need not
+// be safe for concurrent use by the Sink.
+type Publisher interface {
+	Publish(ctx context.Context, msgs []Message) error
+}
+
+// PublisherFunc adapts a function to the Publisher interface.
+type PublisherFunc func(ctx context.Context, msgs []Message) error
+
+// Publish calls f(ctx, msgs).
+func (f PublisherFunc) Publish(ctx context.Context, msgs []Message) error {
+	return f(ctx, msgs)
+}
+
+// Sink publishes events asynchronously in batches.
+//
+// Thread Safety: Sink is safe for concurrent use.
+type Sink struct {
+	publisher     Publisher
+	topic         func(*Event) string
+	encoding      Encoding
+	batchSize     int
+	flushInterval time.Duration
+	bufferSize    int
+	timeout       time.Duration
+	onError       func(error)
+	now           func() time.Time
+
+	mu      sync.RWMutex // guards closed and sending on events
+	closed  bool
+	events  chan *Event
+	flushes chan chan struct{}
+	done    chan struct{}
+	dropped atomic.Int64
+}
+
+// Option configures a Sink.
+type Option func(*Sink)
+
+// WithTopic publishes all events to topic.
+//
+// Default: "warp.events"
+func WithTopic(topic string) Option {
+	return func(s *Sink) {
+		s.topic = func(*Event) string { return topic }
+	}
+}
+
+// WithTopicFunc chooses the topic per event, e.g. by type or provider.
+func WithTopicFunc(fn func(*Event) string) Option {
+	return func(s *Sink) {
+		s.topic = fn
+	}
+}
+
+// WithEncoding sets the event encoding.
+//
+// Default: EncodingJSON
+func WithEncoding(encoding Encoding) Option {
+	return func(s *Sink) {
+		s.encoding = encoding
+	}
+}
+
+// WithBatchSize sets the number of events published together.
+//
+// Default: 100
+func WithBatchSize(n int) Option {
+	return func(s *Sink) {
+		s.batchSize = n
+	}
+}
+
+// WithFlushInterval sets how long a partial batch waits before it is
+// published.
+//
+// Default: 1 second
+func WithFlushInterval(d time.Duration) Option {
+	return func(s *Sink) {
+		s.flushInterval = d
+	}
+}
+
+// WithBufferSize sets how many events can wait to be published. Events
+// arriving while the buffer is full are dropped and counted by Dropped,
+// so a slow or unavailable bus never blocks requests.
+//
+// Default: 10000
+func WithBufferSize(n int) Option {
+	return func(s *Sink) {
+		s.bufferSize = n
+	}
+}
+
+// WithPublishTimeout bounds each Publish call.
+//
+// Default: 10 seconds
+func WithPublishTimeout(d time.Duration) Option {
+	return func(s *Sink) {
+		s.timeout = d
+	}
+}
+
+// WithErrorHandler sets a function called when a batch cannot be encoded
+// or published. Without a handler these errors are dropped.
+func WithErrorHandler(fn func(error)) Option {
+	return func(s *Sink) {
+		s.onError = fn
+	}
+}
+
+// New creates a sink publishing to publisher and starts its background
+// goroutine. Call Close to flush remaining events and stop it.
+func New(publisher Publisher, opts ...Option) *Sink {
+	s := &Sink{
+		publisher:     publisher,
+		topic:         func(*Event) string { return "warp.events" },
+		encoding:      EncodingJSON,
+		batchSize:     100,
+		flushInterval: time.Second,
+		bufferSize:    10000,
+		timeout:       10 * time.Second,
+		now:           time.Now,
+		flushes:       make(chan chan struct{}),
+		done:          make(chan struct{}),
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	s.batchSize = max(s.batchSize, 1)
+	if s.flushInterval <= 0 {
+		s.flushInterval = time.Second
+	}
+	s.events = make(chan *Event, max(s.bufferSize, 0))
+
+	go s.run()
+	return s
+}
+
+// Success publishes a success event. Register it with
+// warp.WithSuccessCallback.
+func (s *Sink) Success(ctx context.Context, event *callback.SuccessEvent) {
+	ev := &Event{
+		Type:        TypeSuccess,
+		Time:        event.EndTime,
+		RequestID:   event.RequestID,
+		Provider:    event.Provider,
+		Model:       event.Model,
+		Duration:    event.Duration,
+		TotalTokens: event.Tokens,
+		Cost:        event.Cost,
+	}
+	if resp, _ := event.Response.(*warp.CompletionResponse); resp != nil && resp.Usage != nil {
+		ev.PromptTokens = resp.Usage.PromptTokens
+		ev.CompletionTokens = resp.Usage.CompletionTokens
+		ev.TotalTokens = resp.Usage.TotalTokens
+	}
+	s.Publish(ev)
+}
+
+// Failure publishes a failure event. Register it with
+// warp.WithFailureCallback.
+func (s *Sink) Failure(ctx context.Context, event *callback.FailureEvent) {
+	ev := &Event{
+		Type:      TypeFailure,
+		Time:      event.EndTime,
+		RequestID: event.RequestID,
+		Provider:  event.Provider,
+		Model:     event.Model,
+		Duration:  event.Duration,
+	}
+	if event.Error != nil {
+		ev.Error = event.Error.Error()
+	}
+	s.Publish(ev)
+}
+
+// Warning publishes a warning event. Register it with
+// warp.WithWarningCallback.
+func (s *Sink) Warning(ctx context.Context, event *callback.WarningEvent) {
+	s.Publish(&Event{
+		Type:      TypeWarning,
+		Time:      event.Timestamp,
+		RequestID: event.RequestID,
+		Provider:  event.Provider,
+		Model:     event.Model,
+		Code:      event.Code,
+		Message:   event.Message,
+	})
+}
+
+// Publish queues an event without blocking. It returns false and counts
+// the event as dropped if the buffer is full or the sink is closed.
+func (s *Sink) Publish(ev *Event) bool {
+	if ev.Time.IsZero() {
+		ev.Time = s.now()
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if s.closed {
+		s.dropped.Add(1)
+		return false
+	}
+	select {
+	case s.events <- ev:
+		return true
+	default:
+		s.dropped.Add(1)
+		return false
+	}
+}
+
+// Dropped returns the number of events dropped because the buffer was
+// full or the sink was closed.
+func (s *Sink) Dropped() int64 {
+	return s.dropped.Load()
+}
+
+// Flush publishes all queued events and waits until they have been
+// published or ctx is done.
+func (s *Sink) Flush(ctx context.Context) error {
+	ack := make(chan struct{})
+	select {
+	case s.flushes <- ack:
+	case <-s.done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+	select {
+	case <-ack:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Close stops accepting events, publishes the queued ones, and stops the
+// background goroutine. It waits until done or ctx is done.
+func (s *Sink) Close(ctx context.Context) error {
+	s.mu.Lock()
+	if !s.closed {
+		s.closed = true
+		close(s.events)
+	}
+	s.mu.Unlock()
+
+	select {
+	case <-s.done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// run batches queued events and publishes them until the sink is closed.
+func (s *Sink) run() {
+	defer close(s.done)
+
+	ticker := time.NewTicker(s.flushInterval)
+	defer ticker.Stop()
+
+	batch := make([]*Event, 0, s.batchSize)
+	publish := func() {
+		if len(batch) > 0 {
+			s.publish(batch)
+			batch = batch[:0]
+		}
+	}
+
+	for {
+		select {
+		case ev, ok := <-s.events:
+			if !ok {
+				publish()
+				return
+			}
+			batch = append(batch, ev)
+			if len(batch) >= s.batchSize {
+				publish()
+			}
+
+		case ack := <-s.flushes:
+			// Drain what is already queued so Flush covers events
+			// published before it was called.
+			for drained := false; !drained; {
+				select {
+				case ev, ok := <-s.events:
+					if !ok {
+						drained = true
+						break
+					}
+					batch = append(batch, ev)
+					if len(batch) >= s.batchSize {
+						publish()
+					}
+				default:
+					drained = true
+				}
+			}
+			publish()
+			close(ack)
+
+		case <-ticker.C:
+			publish()
+		}
+	}
+}
+
+// publish encodes and publishes one batch.
+func (s *Sink) publish(batch []*Event) {
+	msgs := make([]Message, 0, len(batch))
+	for _, ev := range batch {
+		value, err := s.encoding.Encode(ev)
+		if err != nil {
+			s.report(fmt.Errorf("failed to encode %s event: %w", ev.Type, err))
+			continue
+		}
+		msgs = append(msgs, Message{
+			Topic:       s.topic(ev),
+			Key:         []byte(ev.RequestID),
+			Value:       value,
+			ContentType: s.encoding.ContentType(),
+		})
+	}
+	if len(msgs) == 0 {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), s.timeout)
+	defer cancel()
+	if err := s.publisher.Publish(ctx, msgs); err != nil {
+		s.report(fmt.Errorf("failed to publish %d events: %w", len(msgs), err))
+	}
+}
+
+// report passes err to the error handler, if any.
+func (s *Sink) report(err error) {
+	if s.onError != nil {
+		s.onError(err)
+	}
+}
@@ -0,0 +1,199 @@
+package eventbus
+
+import (
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"math"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/blue-context/warp"
+	"github.com/blue-context/warp/callback"
+)
+
+// recorder is a Publisher that keeps published batches.
+type recorder struct {
+	mu      sync.Mutex
+	batches [][]Message
+	err     error
+	block   chan struct{}
+}
+
+func (r *recorder) Publish(ctx context.Context, msgs []Message) error {
+	if r.block != nil {
+		<-r.block
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.batches = append(r.batches, msgs)
+	return r.err
+}
+
+func (r *recorder) messages() []Message {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var all []Message
+	for _, b := range r.batches {
+		all = append(all, b...)
+	}
+	return all
+}
+
+func TestSinkPublishesCallbacks(t *testing.T) {
+	rec := &recorder{}
+	sink := New(rec, WithTopicFunc(func(ev *Event) string { return "llm." + ev.Type }))
+	ctx := context.Background()
+
+	sink.Success(ctx, &callback.SuccessEvent{
+		RequestID: "req-1",
+		Provider:  "openai",
+		Model:     "gpt-4o",
+		Response:  &warp.CompletionResponse{Usage: &warp.Usage{PromptTokens: 10, CompletionTokens: 5, TotalTokens: 15}},
+		Cost:      0.002,
+		Duration:  time.Second,
+	})
+	sink.Failure(ctx, &callback.FailureEvent{RequestID: "req-2", Provider: "openai", Model: "gpt-4o", Error: errors.New("boom")})
+	sink.Warning(ctx, &callback.WarningEvent{RequestID: "req-3", Code: "service_tier_spillover", Message: "retried"})
+
+	if err := sink.Close(ctx); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+
+	msgs := rec.messages()
+	if len(msgs) != 3 {
+		t.Fatalf("published %d messages, want 3", len(msgs))
+	}
+	if msgs[0].Topic != "llm.success" || string(msgs[0].Key) != "req-1" || msgs[0].ContentType != "application/json" {
+		t.Errorf("message = %+v, want llm.success keyed by request ID", msgs[0])
+	}
+
+	var ev Event
+	if err := json.Unmarshal(msgs[0].Value, &ev); err != nil {
+		t.Fatalf("invalid JSON: %v", err)
+	}
+	if ev.PromptTokens != 10 || ev.TotalTokens != 15 || ev.Cost != 0.002 || ev.Duration != time.Second {
+		t.Errorf("event = %+v, want usage, cost and duration", ev)
+	}
+	if ev.Time.IsZero() {
+		t.Error("event time should default to now")
+	}
+	if err := json.Unmarshal(msgs[1].Value, &ev); err != nil || ev.Error != "boom" {
+		t.Errorf("failure event = %+v, %v, want error", ev, err)
+	}
+	if msgs[2].Topic != "llm.warning" {
+		t.Errorf("warning topic = %q", msgs[2].Topic)
+	}
+
+	// Events after Close are dropped
+	if sink.Publish(&Event{Type: TypeWarning}) || sink.Dropped() != 1 {
+		t.Errorf("Publish after Close queued, Dropped() = %d", sink.Dropped())
+	}
+}
+
+func TestSinkBatching(t *testing.T) {
+	rec := &recorder{}
+	sink := New(rec, WithBatchSize(2), WithFlushInterval(time.Hour))
+	defer sink.Close(context.Background())
+
+	for i := 0; i < 5; i++ {
+		sink.Publish(&Event{Type: TypeSuccess})
+	}
+	if err := sink.Flush(context.Background()); err != nil {
+		t.Fatalf("Flush() error = %v", err)
+	}
+
+	rec.mu.Lock()
+	defer rec.mu.Unlock()
+	if len(rec.batches) != 3 || len(rec.batches[0]) != 2 || len(rec.batches[2]) != 1 {
+		t.Errorf("batches = %d, want 2+2+1", len(rec.batches))
+	}
+}
+
+func TestSinkDropsWhenFull(t *testing.T) {
+	rec := &recorder{block: make(chan struct{})}
+	var errs []error
+	rec.err = errors.New("bus unavailable")
+	sink := New(rec, WithBatchSize(1), WithBufferSize(1), WithErrorHandler(func(err error) { errs = append(errs, err) }))
+
+	// The first event is taken by the blocked publisher, the second fills
+	// the buffer, and the rest are dropped.
+	sink.Publish(&Event{Type: TypeSuccess})
+	deadline := time.Now().Add(time.Second)
+	for len(sink.events) != 0 && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	for i := 0; i < 3; i++ {
+		sink.Publish(&Event{Type: TypeSuccess})
+	}
+	if sink.Dropped() != 2 {
+		t.Errorf("Dropped() = %d, want 2", sink.Dropped())
+	}
+
+	close(rec.block)
+	sink.Close(context.Background())
+	if len(errs) != 2 {
+		t.Errorf("error handler called %d times, want 2", len(errs))
+	}
+}
+
+func TestEncodeProtobuf(t *testing.T) {
+	ev := &Event{
+		Type:        TypeSuccess,
+		Time:        time.Unix(1_700_000_000, 0),
+		Provider:    "openai",
+		TotalTokens: 300,
+		Cost:        0.5,
+	}
+	b, err := EncodingProtobuf.Encode(ev)
+	if err != nil {
+		t.Fatalf("Encode() error = %v", err)
+	}
+
+	fields := decodeProto(t, b)
+	if string(fields[fieldType].([]byte)) != "success" || string(fields[fieldProvider].([]byte)) != "openai" {
+		t.Errorf("string fields = %v", fields)
+	}
+	if fields[fieldTimeUnixNano] != uint64(ev.Time.UnixNano()) || fields[fieldTotalTokens] != uint64(300) {
+		t.Errorf("varint fields = %v", fields)
+	}
+	if fields[fieldCost] != 0.5 {
+		t.Errorf("cost = %v, want 0.5", fields[fieldCost])
+	}
+	if _, ok := fields[fieldModel]; ok {
+		t.Error("empty fields should be omitted")
+	}
+
+	if _, err := Encoding("avro").Encode(ev); err == nil {
+		t.Error("Encode() with unknown encoding should fail")
+	}
+}
+
+// decodeProto decodes the wire types marshalProto produces.
+func decodeProto(t *testing.T, b []byte) map[int]any {
+	t.Helper()
+	fields := make(map[int]any)
+	for len(b) > 0 {
+		key, n := binary.Uvarint(b)
+		b = b[n:]
+		field, wire := int(key>>3), key&7
+		switch wire {
+		case wireVarint:
+			v, n := binary.Uvarint(b)
+			fields[field] = v
+			b = b[n:]
+		case wireFixed64:
+			fields[field] = math.Float64frombits(binary.LittleEndian.Uint64(b))
+			b = b[8:]
+		case wireBytes:
+			l, n := binary.Uvarint(b)
+			fields[field] = b[n : n+int(l)]
+			b = b[n+int(l):]
+		default:
+			t.Fatalf("unexpected wire type %d", wire)
+		}
+	}
+	return fields
+}
@@ -0,0 +1,64 @@
+//go:build example
+// +build example
+
+package eventbus
+
+// This file is an EXAMPLE showing how to implement the Publisher interface
+// with Kafka. It is excluded from normal builds using the "example" build tag.
+//
+// Users should copy this pattern and use their own Kafka client.
+//
+// Example usage:
+//
+//	import (
+//	    "github.com/segmentio/kafka-go"
+//	    "github.com/blue-context/warp/eventbus"
+//	)
+//
+//	writer := &kafka.Writer{
+//	    Addr:     kafka.TCP("localhost:9092"),
+//	    Balancer: &kafka.Hash{}, // keeps a request's events on one partition
+//	}
+//	defer writer.Close()
+//
+//	sink := eventbus.New(NewKafkaPublisher(writer),
+//	    eventbus.WithTopic("llm-events"),
+//	    eventbus.WithEncoding(eventbus.EncodingProtobuf),
+//	)
+//	defer sink.Close(context.Background())
+
+/*
+Example Kafka publisher implementation (requires github.com/segmentio/kafka-go):
+
+import (
+	"context"
+
+	"github.com/segmentio/kafka-go"
+	"github.com/blue-context/warp/eventbus"
+)
+
+// KafkaPublisher publishes events as Kafka records keyed by request ID.
+type KafkaPublisher struct {
+	writer *kafka.Writer
+}
+
+// NewKafkaPublisher creates a publisher using writer. The writer must not
+// have a fixed Topic, since each message carries its own.
+func NewKafkaPublisher(writer *kafka.Writer) *KafkaPublisher {
+	return &KafkaPublisher{writer: writer}
+}
+
+// Publish writes msgs in one batch.
+func (p *KafkaPublisher) Publish(ctx context.Context, msgs []eventbus.Message) error {
+	records := make([]kafka.Message, len(msgs))
+	for i, m := range msgs {
+		records[i] = kafka.Message{
+			Topic:   m.Topic,
+			Key:     m.Key,
+			Value:   m.Value,
+			Headers: []kafka.Header{{Key: "content-type", Value: []byte(m.ContentType)}},
+		}
+	}
+	return p.writer.WriteMessages(ctx, records...)
+}
+*/
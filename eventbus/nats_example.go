@@ -0,0 +1,61 @@
+//go:build example
+// +build example
+
+package eventbus
+
+// This file is an EXAMPLE showing how to implement the Publisher interface
+// with NATS. It is excluded from normal builds using the "example" build tag.
+//
+// Users should copy this pattern and use their own NATS client.
+//
+// Example usage:
+//
+//	import (
+//	    "github.com/nats-io/nats.go"
+//	    "github.com/blue-context/warp/eventbus"
+//	)
+//
+//	nc, _ := nats.Connect(nats.DefaultURL)
+//	defer nc.Drain()
+//
+//	sink := eventbus.New(NewNATSPublisher(nc), eventbus.WithTopic("llm.events"))
+//	defer sink.Close(context.Background())
+
+/*
+Example NATS publisher implementation (requires github.com/nats-io/nats.go):
+
+import (
+	"context"
+
+	"github.com/nats-io/nats.go"
+	"github.com/blue-context/warp/eventbus"
+)
+
+// NATSPublisher publishes events as NATS messages, with the request ID and
+// content type as headers.
+type NATSPublisher struct {
+	nc *nats.Conn
+}
+
+// NewNATSPublisher creates a publisher using nc.
+func NewNATSPublisher(nc *nats.Conn) *NATSPublisher {
+	return &NATSPublisher{nc: nc}
+}
+
+// Publish sends msgs and flushes the connection so that publish errors
+// are reported for the batch.
+func (p *NATSPublisher) Publish(ctx context.Context, msgs []eventbus.Message) error {
+	for _, m := range msgs {
+		msg := nats.NewMsg(m.Topic)
+		msg.Data = m.Value
+		msg.Header.Set("Content-Type", m.ContentType)
+		if len(m.Key) > 0 {
+			msg.Header.Set("Warp-Request-Id", string(m.Key))
+		}
+		if err := p.nc.PublishMsg(msg); err != nil {
+			return err
+		}
+	}
+	return p.nc.FlushWithContext(ctx)
+}
+*/
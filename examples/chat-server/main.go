@@ -0,0 +1,64 @@
+// Package main serves a self-hosted vLLM model through an OpenAI-compatible
+// chat completions endpoint, with a bearer token and CORS for a web app.
+//
+// Any OpenAI SDK can then use the model:
+//
+//	curl http://localhost:8080/v1/chat/completions \
+//	    -H "Authorization: Bearer $CHAT_SERVER_TOKEN" \
+//	    -d '{"model": "assistant", "messages": [{"role": "user", "content": "Hello!"}]}'
+package main
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"os"
+
+	"github.com/blue-context/warp"
+	"github.com/blue-context/warp/provider/vllm"
+)
+
+func main() {
+	token := os.Getenv("CHAT_SERVER_TOKEN")
+	if token == "" {
+		log.Fatal("CHAT_SERVER_TOKEN is not set")
+	}
+	baseURL := os.Getenv("VLLM_BASE_URL")
+	if baseURL == "" {
+		baseURL = "http://localhost:8000"
+	}
+
+	provider, err := vllm.NewProvider(
+		vllm.WithBaseURL(baseURL),
+		vllm.WithChatCompletions(true),
+	)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	client, err := warp.NewClient(warp.WithDebug(true))
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer client.Close()
+	if err := client.RegisterProvider(provider); err != nil {
+		log.Fatal(err)
+	}
+
+	handler := warp.NewChatHandler(client,
+		warp.WithChatModels(map[string]string{
+			"assistant": "vllm/meta-llama/Llama-3.1-8B-Instruct",
+		}),
+		warp.WithChatAuth(func(r *http.Request) error {
+			if r.Header.Get("Authorization") != "Bearer "+token {
+				return errors.New("invalid API key")
+			}
+			return nil
+		}),
+		warp.WithChatCORS("http://localhost:3000"),
+	)
+
+	http.Handle("/v1/chat/completions", handler)
+	log.Println("Serving on http://localhost:8080/v1/chat/completions")
+	log.Fatal(http.ListenAndServe(":8080", nil))
+}
@@ -13,7 +13,6 @@ package main
 import (
 	"context"
 	"fmt"
-	"io"
 	"log"
 	"os"
 
@@ -133,18 +132,8 @@ func streamingCompletion(ctx context.Context, provider *openrouter.Provider) err
 	defer stream.Close()
 
 	fmt.Print("Response: ")
-	for {
-		chunk, err := stream.Recv()
-		if err == io.EOF {
-			break
-		}
-		if err != nil {
-			return fmt.Errorf("stream error: %w", err)
-		}
-
-		if len(chunk.Choices) > 0 && chunk.Choices[0].Delta.Content != "" {
-			fmt.Print(chunk.Choices[0].Delta.Content)
-		}
+	if _, err := warp.StreamToWriter(stream, os.Stdout, nil); err != nil {
+		return fmt.Errorf("stream error: %w", err)
 	}
 	fmt.Println()
 
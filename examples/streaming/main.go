@@ -2,9 +2,8 @@
 //
 // This example shows how to:
 //   - Use streaming completions
-//   - Process chunks as they arrive
-//   - Handle stream completion (io.EOF)
-//   - Show real-time output
+//   - Write text to the terminal as it arrives with StreamToWriter
+//   - Wrap the output at a column width
 //
 // To run:
 //
@@ -15,7 +14,6 @@ package main
 import (
 	"context"
 	"fmt"
-	"io"
 	"log"
 	"os"
 
@@ -70,20 +68,9 @@ func main() {
 	fmt.Println("Streaming response:")
 	fmt.Println("---")
 
-	for {
-		chunk, err := stream.Recv()
-		if err == io.EOF {
-			// Stream completed successfully
-			break
-		}
-		if err != nil {
-			log.Fatalf("Stream error: %v", err)
-		}
-
-		// Print content as it arrives
-		if len(chunk.Choices) > 0 && chunk.Choices[0].Delta.Content != "" {
-			fmt.Print(chunk.Choices[0].Delta.Content)
-		}
+	// Print content as it arrives, wrapped at 80 columns
+	if _, err := warp.StreamToWriter(stream, os.Stdout, &warp.StreamWriterOptions{Width: 80}); err != nil {
+		log.Fatalf("Stream error: %v", err)
 	}
 
 	fmt.Println()
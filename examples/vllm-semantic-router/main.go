@@ -3,7 +3,6 @@ package main
 import (
 	"context"
 	"fmt"
-	"io"
 	"log"
 	"os"
 
@@ -91,19 +90,9 @@ func streamingExample(ctx context.Context, provider *vllmsemanticrouter.Provider
 	defer stream.Close()
 
 	fmt.Print("Streaming response: ")
-	for {
-		chunk, err := stream.Recv()
-		if err == io.EOF {
-			break
-		}
-		if err != nil {
-			log.Printf("\nError receiving chunk: %v\n", err)
-			return
-		}
-
-		if len(chunk.Choices) > 0 && chunk.Choices[0].Delta.Content != "" {
-			fmt.Print(chunk.Choices[0].Delta.Content)
-		}
+	if _, err := warp.StreamToWriter(stream, os.Stdout, nil); err != nil {
+		log.Printf("\nError receiving chunk: %v\n", err)
+		return
 	}
 	fmt.Println()
 }
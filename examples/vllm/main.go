@@ -12,8 +12,8 @@ package main
 import (
 	"context"
 	"fmt"
-	"io"
 	"log"
+	"os"
 
 	"github.com/blue-context/warp"
 	"github.com/blue-context/warp/provider/vllm"
@@ -96,18 +96,8 @@ func streamingCompletion(provider *vllm.Provider) error {
 	defer stream.Close()
 
 	fmt.Print("Response: ")
-	for {
-		chunk, err := stream.Recv()
-		if err == io.EOF {
-			break
-		}
-		if err != nil {
-			return fmt.Errorf("stream recv failed: %w", err)
-		}
-
-		if len(chunk.Choices) > 0 {
-			fmt.Print(chunk.Choices[0].Delta.Content)
-		}
+	if _, err := warp.StreamToWriter(stream, os.Stdout, nil); err != nil {
+		return fmt.Errorf("stream recv failed: %w", err)
 	}
 	fmt.Println()
 
@@ -0,0 +1,357 @@
+package warp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+)
+
+// DefaultExtractChunkSize is the default size, in bytes, of the document
+// chunks Extract sends to the model.
+const DefaultExtractChunkSize = 16000
+
+// ExtractOptions configures Extract.
+type ExtractOptions struct {
+	// Model is the completion model that extracts the fields, in
+	// "provider/model-name" form. Required.
+	Model string
+
+	// Instructions add task-specific guidance to the prompt. Optional.
+	Instructions string
+
+	// ChunkSize is the maximum size of a document chunk in bytes
+	// (0 uses DefaultExtractChunkSize).
+	ChunkSize int
+
+	// ChunkOverlap is how many bytes consecutive chunks share, so fields
+	// split across a boundary are seen whole (0 uses a twentieth of
+	// ChunkSize).
+	ChunkOverlap int
+
+	// Resolve chooses the value of a field that chunks extracted
+	// differently. field is the dotted JSON path of the field and values
+	// are the distinct values in document order. If nil, the value found
+	// in the most chunks wins, ties going to the earliest.
+	Resolve func(field string, values []any) any
+}
+
+// Extract pulls the fields of T from a document with a completion model.
+//
+// T must be a struct; its JSON schema, derived from its fields and json
+// tags, constrains the model's output. A `description` struct tag is
+// passed to the model as the field's description. Long documents are split
+// into overlapping chunks that are extracted separately and then merged:
+// objects are merged field by field, lists are concatenated without
+// duplicates, and differing values are settled by opts.Resolve.
+//
+// Example:
+//
+//	type Invoice struct {
+//	    Number string    `json:"number"`
+//	    Total  float64   `json:"total" description:"Amount due, including tax"`
+//	    Items  []string  `json:"items"`
+//	}
+//
+//	invoice, err := warp.Extract[Invoice](ctx, client, text, &warp.ExtractOptions{
+//	    Model: "openai/gpt-4o-mini",
+//	})
+func Extract[T any](ctx context.Context, client Client, document string, opts *ExtractOptions) (*T, error) {
+	if opts == nil || opts.Model == "" {
+		return nil, fmt.Errorf("model is required")
+	}
+	t := reflect.TypeOf((*T)(nil)).Elem()
+	if t.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("extract target must be a struct, got %s", t)
+	}
+	schema := jsonSchemaOf(t)
+
+	size := opts.ChunkSize
+	if size <= 0 {
+		size = DefaultExtractChunkSize
+	}
+	overlap := opts.ChunkOverlap
+	if overlap <= 0 {
+		overlap = size / 20
+	}
+	chunks := chunkText(document, size, overlap)
+
+	objects := make([]map[string]any, 0, len(chunks))
+	for i, chunk := range chunks {
+		object, err := extractChunk(ctx, client, chunk, schema, opts)
+		if err != nil {
+			return nil, fmt.Errorf("chunk %d of %d: %w", i+1, len(chunks), err)
+		}
+		objects = append(objects, object)
+	}
+
+	merged, err := json.Marshal(mergeObjects(objects, "", opts.Resolve))
+	if err != nil {
+		return nil, fmt.Errorf("failed to merge extracted fields: %w", err)
+	}
+	var out T
+	if err := json.Unmarshal(merged, &out); err != nil {
+		return nil, fmt.Errorf("extracted fields do not match %s: %w", t, err)
+	}
+	return &out, nil
+}
+
+// extractChunk extracts the schema's fields from one chunk.
+func extractChunk(ctx context.Context, client Client, chunk string, schema map[string]any, opts *ExtractOptions) (map[string]any, error) {
+	prompt := "Extract the fields described by the JSON schema from the document excerpt. " +
+		"Use null for fields the excerpt does not mention. Reply with only the JSON object."
+	if opts.Instructions != "" {
+		prompt = opts.Instructions + "\n\n" + prompt
+	}
+
+	resp, err := client.Completion(ctx, &CompletionRequest{
+		Model: opts.Model,
+		Messages: []Message{
+			{Role: "system", Content: prompt},
+			{Role: "user", Content: chunk},
+		},
+		Temperature: Float64Ptr(0),
+		ResponseFormat: &ResponseFormat{
+			Type:       "json_schema",
+			JSONSchema: &JSONSchema{Name: "extraction", Schema: schema},
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(resp.Choices) == 0 {
+		return nil, fmt.Errorf("response has no choices")
+	}
+
+	text, err := extractJSON(messageText(resp.Choices[0].Message.Content), nil)
+	if err != nil {
+		return nil, fmt.Errorf("model did not return JSON: %w", err)
+	}
+	var object map[string]any
+	if err := json.Unmarshal([]byte(text), &object); err != nil {
+		return nil, fmt.Errorf("model did not return JSON: %w", err)
+	}
+	return object, nil
+}
+
+// chunkText splits text into chunks of at most size bytes, each starting
+// overlap bytes before the end of the previous one. Chunks end at a
+// paragraph, line, or word boundary in their second half when there is
+// one.
+func chunkText(text string, size, overlap int) []string {
+	if overlap >= size {
+		overlap = size / 2
+	}
+	var chunks []string
+	for start := 0; ; {
+		end := start + size
+		if end >= len(text) {
+			return append(chunks, text[start:])
+		}
+		end = chunkEnd(text, start, end)
+		chunks = append(chunks, text[start:end])
+
+		next := end - overlap
+		if next <= start {
+			next = end
+		}
+		// Do not start in the middle of a UTF-8 sequence
+		for next < end && !isRuneStart(text[next]) {
+			next++
+		}
+		start = next
+	}
+}
+
+// chunkEnd moves end back to the last paragraph, line, or word break after
+// the middle of text[start:end], or to a rune boundary if there is none.
+func chunkEnd(text string, start, end int) int {
+	mid := start + (end-start)/2
+	for _, sep := range []string{"\n\n", "\n", " "} {
+		if i := strings.LastIndex(text[mid:end], sep); i >= 0 {
+			return mid + i + len(sep)
+		}
+	}
+	for end > start+1 && !isRuneStart(text[end]) {
+		end--
+	}
+	return end
+}
+
+// isRuneStart reports whether b begins a UTF-8 encoded rune.
+func isRuneStart(b byte) bool {
+	return b&0xC0 != 0x80
+}
+
+// mergeObjects merges the objects extracted from each chunk. path is the
+// dotted path of the objects, used to name fields passed to resolve.
+func mergeObjects(objects []map[string]any, path string, resolve func(string, []any) any) map[string]any {
+	var keys []string
+	values := make(map[string][]any)
+	for _, object := range objects {
+		for key, value := range object {
+			if value == nil {
+				continue
+			}
+			if _, ok := values[key]; !ok {
+				keys = append(keys, key)
+			}
+			values[key] = append(values[key], value)
+		}
+	}
+
+	merged := make(map[string]any, len(keys))
+	for _, key := range keys {
+		merged[key] = mergeValues(values[key], joinPath(path, key), resolve)
+	}
+	return merged
+}
+
+// mergeValues merges the non-null values of one field. Differing scalar
+// values are settled by resolve, or by mostCommonValue if it is nil.
+func mergeValues(values []any, field string, resolve func(string, []any) any) any {
+	var objects []map[string]any
+	var items []any
+	lists := 0
+	for _, v := range values {
+		switch v := v.(type) {
+		case map[string]any:
+			objects = append(objects, v)
+		case []any:
+			items = append(items, v...)
+			lists++
+		}
+	}
+
+	switch {
+	case len(objects) == len(values):
+		return mergeObjects(objects, field, resolve)
+	case lists == len(values):
+		return distinctValues(items)
+	}
+
+	distinct := distinctValues(values)
+	switch {
+	case len(distinct) == 1:
+		return distinct[0]
+	case resolve != nil:
+		return resolve(field, distinct)
+	}
+	return mostCommonValue(values)
+}
+
+// mostCommonValue returns the value that occurs most often, the earliest
+// on a tie.
+func mostCommonValue(values []any) any {
+	keys := make([]string, len(values))
+	counts := make(map[string]int, len(values))
+	for i, v := range values {
+		key, _ := json.Marshal(v)
+		keys[i] = string(key)
+		counts[keys[i]]++
+	}
+
+	best := 0
+	for i := range values {
+		if counts[keys[i]] > counts[keys[best]] {
+			best = i
+		}
+	}
+	return values[best]
+}
+
+// distinctValues returns values without duplicates, in order.
+func distinctValues(values []any) []any {
+	seen := make(map[string]bool, len(values))
+	out := make([]any, 0, len(values))
+	for _, v := range values {
+		key, _ := json.Marshal(v)
+		if seen[string(key)] {
+			continue
+		}
+		seen[string(key)] = true
+		out = append(out, v)
+	}
+	return out
+}
+
+// joinPath appends key to a dotted path.
+func joinPath(path, key string) string {
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
+
+// timeType is the type of time.Time, which is encoded as a string.
+var timeType = reflect.TypeOf(time.Time{})
+
+// jsonSchemaOf returns a JSON schema describing the JSON encoding of t.
+func jsonSchemaOf(t reflect.Type) map[string]any {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if t == timeType {
+		return map[string]any{"type": "string", "format": "date-time"}
+	}
+
+	switch t.Kind() {
+	case reflect.String:
+		return map[string]any{"type": "string"}
+	case reflect.Bool:
+		return map[string]any{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return map[string]any{"type": "integer"}
+	case reflect.Float32, reflect.Float64:
+		return map[string]any{"type": "number"}
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return map[string]any{"type": "string"}
+		}
+		return map[string]any{"type": "array", "items": jsonSchemaOf(t.Elem())}
+	case reflect.Map:
+		return map[string]any{"type": "object", "additionalProperties": jsonSchemaOf(t.Elem())}
+	case reflect.Struct:
+		properties := make(map[string]any)
+		addStructFields(t, properties)
+		return map[string]any{"type": "object", "properties": properties}
+	}
+	return map[string]any{}
+}
+
+// addStructFields adds the schemas of t's JSON fields to properties,
+// flattening embedded structs as encoding/json does.
+func addStructFields(t reflect.Type, properties map[string]any) {
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		tag := f.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name, _, _ := strings.Cut(tag, ",")
+
+		ft := f.Type
+		for ft.Kind() == reflect.Pointer {
+			ft = ft.Elem()
+		}
+		if f.Anonymous && name == "" && ft.Kind() == reflect.Struct {
+			addStructFields(ft, properties)
+			continue
+		}
+		if !f.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = f.Name
+		}
+
+		schema := jsonSchemaOf(f.Type)
+		if desc := f.Tag.Get("description"); desc != "" {
+			schema["description"] = desc
+		}
+		properties[name] = schema
+	}
+}
@@ -0,0 +1,111 @@
+package warp
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+)
+
+type testInvoice struct {
+	Number   string   `json:"number"`
+	Total    float64  `json:"total" description:"Amount due"`
+	Items    []string `json:"items"`
+	Customer *struct {
+		Name string `json:"name"`
+		City string `json:"city"`
+	} `json:"customer"`
+	Due      time.Time `json:"due"`
+	internal string
+}
+
+func TestExtract(t *testing.T) {
+	client, err := NewClient()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+
+	replies := map[string]string{
+		"A": `{"number":"INV-1","total":10,"items":["bolts"],"customer":{"name":"Acme","city":null},"due":null}`,
+		"B": `{"number":"INV-1","total":12,"items":["bolts","nuts"],"customer":{"name":"Acme","city":"Oslo"}}`,
+		"C": `{"number":null,"total":12,"items":[],"due":"2025-01-31T00:00:00Z"}`,
+	}
+	var schema map[string]any
+	client.RegisterProvider(&mockProvider{name: "mock", completionFunc: func(ctx context.Context, req *CompletionRequest) (*CompletionResponse, error) {
+		schema = req.ResponseFormat.JSONSchema.Schema
+		chunk := strings.TrimSpace(messageText(req.Messages[1].Content))
+		return &CompletionResponse{Choices: []Choice{{Message: Message{Role: "assistant", Content: replies[chunk[:1]]}}}}, nil
+	}})
+
+	document := "A" + strings.Repeat(" x", 40) + "\n\nB" + strings.Repeat(" y", 40) + "\n\nC" + strings.Repeat(" z", 40)
+	invoice, err := Extract[testInvoice](context.Background(), client, document, &ExtractOptions{
+		Model:        "mock/m",
+		ChunkSize:    90,
+		ChunkOverlap: 1,
+	})
+	if err != nil {
+		t.Fatalf("Extract() error = %v", err)
+	}
+
+	if invoice.Number != "INV-1" || invoice.Total != 12 {
+		t.Errorf("Number, Total = %q, %v, want INV-1, 12", invoice.Number, invoice.Total)
+	}
+	if strings.Join(invoice.Items, ",") != "bolts,nuts" {
+		t.Errorf("Items = %v, want [bolts nuts]", invoice.Items)
+	}
+	if invoice.Customer == nil || invoice.Customer.Name != "Acme" || invoice.Customer.City != "Oslo" {
+		t.Errorf("Customer = %+v, want Acme in Oslo", invoice.Customer)
+	}
+	if invoice.Due.IsZero() {
+		t.Error("Due is zero, want 2025-01-31")
+	}
+
+	properties := schema["properties"].(map[string]any)
+	if len(properties) != 5 {
+		t.Errorf("schema properties = %v, want 5 fields", properties)
+	}
+	if total := properties["total"].(map[string]any); total["type"] != "number" || total["description"] != "Amount due" {
+		t.Errorf("total schema = %v", total)
+	}
+
+	// A custom resolver sees the conflicting totals
+	var conflicts []any
+	_, err = Extract[testInvoice](context.Background(), client, document, &ExtractOptions{
+		Model:        "mock/m",
+		ChunkSize:    90,
+		ChunkOverlap: 1,
+		Resolve: func(field string, values []any) any {
+			if field == "total" {
+				conflicts = values
+			}
+			return values[0]
+		},
+	})
+	if err != nil {
+		t.Fatalf("Extract() error = %v", err)
+	}
+	if len(conflicts) != 2 {
+		t.Errorf("total conflicts = %v, want [10 12]", conflicts)
+	}
+}
+
+func TestChunkText(t *testing.T) {
+	text := strings.Repeat("word ", 100)
+	chunks := chunkText(text, 64, 10)
+	if len(chunks) < 8 {
+		t.Fatalf("chunks = %d, want at least 8", len(chunks))
+	}
+	for i, chunk := range chunks {
+		if len(chunk) > 64 {
+			t.Errorf("chunk %d has %d bytes, want at most 64", i, len(chunk))
+		}
+	}
+	if !strings.HasSuffix(chunks[len(chunks)-1], "word ") {
+		t.Errorf("last chunk = %q, want end of text", chunks[len(chunks)-1])
+	}
+
+	if got := chunkText("short", 64, 10); len(got) != 1 || got[0] != "short" {
+		t.Errorf("chunkText(short) = %q", got)
+	}
+}
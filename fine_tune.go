@@ -0,0 +1,388 @@
+package warp
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"unicode/utf8"
+)
+
+// Fine-tuning limits applied by ValidateFineTuneDataset, matching OpenAI's
+// defaults.
+const (
+	// FineTuneMaxTokensPerExample is the length at which examples are truncated.
+	FineTuneMaxTokensPerExample = 16385
+
+	// FineTuneMinExamples is the minimum dataset size accepted by OpenAI.
+	FineTuneMinExamples = 10
+
+	fineTuneTargetEpochs      = 3
+	fineTuneMinTargetExamples = 100
+	fineTuneMaxTargetExamples = 25000
+	fineTuneMinDefaultEpochs  = 1
+	fineTuneMaxDefaultEpochs  = 25
+)
+
+// fineTuneTrainingPrices are OpenAI training prices in USD per 1M tokens,
+// keyed by base model prefix (longest match wins).
+var fineTuneTrainingPrices = map[string]float64{
+	"gpt-4.1-nano":  1.50,
+	"gpt-4.1-mini":  5.00,
+	"gpt-4.1":       25.00,
+	"gpt-4o-mini":   3.00,
+	"gpt-4o":        25.00,
+	"gpt-3.5-turbo": 8.00,
+	"davinci-002":   6.00,
+	"babbage-002":   0.40,
+}
+
+// FineTuneIssue is a problem found in a fine-tuning dataset.
+type FineTuneIssue struct {
+	// Line is the 1-based line number of the example, or 0 for issues
+	// concerning the whole dataset.
+	Line int
+
+	// Code identifies the kind of issue (e.g., "missing_assistant_message").
+	Code string
+
+	// Message describes the issue.
+	Message string
+}
+
+// String returns the issue as "line N: code: message".
+func (i FineTuneIssue) String() string {
+	if i.Line == 0 {
+		return i.Code + ": " + i.Message
+	}
+	return fmt.Sprintf("line %d: %s: %s", i.Line, i.Code, i.Message)
+}
+
+// FineTuneReport summarizes a fine-tuning dataset.
+type FineTuneReport struct {
+	// Examples is the number of non-empty lines.
+	Examples int
+
+	// Errors are problems that would cause the fine-tuning job to fail.
+	Errors []FineTuneIssue
+
+	// Warnings are problems that degrade training without failing it.
+	Warnings []FineTuneIssue
+
+	// MinTokens, MaxTokens, and MeanTokens describe tokens per example.
+	MinTokens  int
+	MaxTokens  int
+	MeanTokens float64
+
+	// AssistantTokens is the total number of assistant tokens, which are
+	// the tokens the model is trained to produce.
+	AssistantTokens int
+
+	// TruncatedExamples counts examples longer than FineTuneMaxTokensPerExample.
+	TruncatedExamples int
+
+	// BillingTokensPerEpoch is the number of billed tokens per epoch, after
+	// truncation.
+	BillingTokensPerEpoch int
+
+	// Epochs is the number of epochs OpenAI would choose by default.
+	Epochs int
+
+	// EstimatedCost is the estimated training cost in USD, or 0 if the
+	// model's training price is unknown.
+	EstimatedCost float64
+}
+
+// Valid reports whether the dataset has no errors.
+func (r *FineTuneReport) Valid() bool {
+	return len(r.Errors) == 0
+}
+
+// FineTuneOption configures ValidateFineTuneDataset.
+type FineTuneOption func(*fineTuneConfig)
+
+// fineTuneConfig holds ValidateFineTuneDataset options.
+type fineTuneConfig struct {
+	countTokens func(text string) int
+}
+
+// WithFineTuneTokenCounter sets the function used to count tokens in text.
+//
+// The default estimates one token per four characters. For closer
+// estimates, pass a real counter such as token.NewCounter().CountText.
+func WithFineTuneTokenCounter(count func(text string) int) FineTuneOption {
+	return func(c *fineTuneConfig) {
+		c.countTokens = count
+	}
+}
+
+// fineTuneMessageKeys are the keys allowed in a dataset message.
+var fineTuneMessageKeys = map[string]bool{
+	"role": true, "content": true, "name": true, "function_call": true,
+	"tool_calls": true, "tool_call_id": true, "weight": true,
+}
+
+// ValidateFineTuneDataset checks an OpenAI chat fine-tuning dataset in
+// JSONL format and estimates its training cost for model.
+//
+// It mirrors the checks of OpenAI's data preparation cookbook:
+//   - each line is a JSON object with a non-empty "messages" list
+//   - messages have a recognized role, content, and no unknown keys
+//   - system messages come first, tool results follow tool calls, and
+//     each example contains an assistant message
+//   - examples longer than FineTuneMaxTokensPerExample are flagged
+//   - datasets smaller than FineTuneMinExamples are errors, since OpenAI
+//     rejects the job
+//
+// Token counts are estimates following OpenAI's message overhead (3 tokens
+// per message, 1 per name, 3 for the reply primer). The default epoch count
+// and billing follow OpenAI's rules. model may include a provider prefix
+// ("openai/gpt-4o-mini-2024-07-18").
+//
+// Problems in the data are reported in the FineTuneReport; an error is
+// returned only if r cannot be read.
+//
+// Example:
+//
+//	f, err := os.Open("train.jsonl")
+//	if err != nil {
+//	    return err
+//	}
+//	defer f.Close()
+//
+//	report, err := warp.ValidateFineTuneDataset(f, "gpt-4o-mini-2024-07-18")
+//	if err != nil {
+//	    return err
+//	}
+//	for _, issue := range report.Errors {
+//	    fmt.Println(issue)
+//	}
+//	fmt.Printf("~$%.2f for %d epochs\n", report.EstimatedCost, report.Epochs)
+func ValidateFineTuneDataset(r io.Reader, model string, opts ...FineTuneOption) (*FineTuneReport, error) {
+	cfg := &fineTuneConfig{
+		countTokens: func(text string) int {
+			return (utf8.RuneCountInString(text) + 3) / 4
+		},
+	}
+	for _, opt := range opts {
+		opt(cfg)
+	}
+
+	report := &FineTuneReport{}
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 64*1024*1024)
+
+	var lengths []int
+	line := 0
+	for scanner.Scan() {
+		line++
+		data := bytes.TrimSpace(scanner.Bytes())
+		if len(data) == 0 {
+			continue
+		}
+		report.Examples++
+
+		v := fineTuneValidator{line: line, report: report, count: cfg.countTokens}
+		if tokens, ok := v.example(data); ok {
+			lengths = append(lengths, tokens)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read dataset: %w", err)
+	}
+
+	if report.Examples < FineTuneMinExamples {
+		report.Errors = append(report.Errors, FineTuneIssue{
+			Code:    "too_few_examples",
+			Message: fmt.Sprintf("dataset has %d examples; at least %d are required", report.Examples, FineTuneMinExamples),
+		})
+	}
+
+	if len(lengths) > 0 {
+		sort.Ints(lengths)
+		report.MinTokens = lengths[0]
+		report.MaxTokens = lengths[len(lengths)-1]
+		total := 0
+		for _, n := range lengths {
+			total += n
+			report.BillingTokensPerEpoch += min(n, FineTuneMaxTokensPerExample)
+		}
+		report.MeanTokens = float64(total) / float64(len(lengths))
+	}
+
+	report.Epochs = fineTuneEpochs(report.Examples)
+	if price, ok := fineTuneTrainingPrice(model); ok {
+		report.EstimatedCost = float64(report.BillingTokensPerEpoch*report.Epochs) / 1_000_000 * price
+	} else {
+		report.Warnings = append(report.Warnings, FineTuneIssue{
+			Code:    "unknown_price",
+			Message: fmt.Sprintf("no training price for model %q", model),
+		})
+	}
+
+	return report, nil
+}
+
+// fineTuneValidator checks a single example.
+type fineTuneValidator struct {
+	line   int
+	report *FineTuneReport
+	count  func(string) int
+}
+
+func (v *fineTuneValidator) errorf(code, format string, args ...any) {
+	v.report.Errors = append(v.report.Errors, FineTuneIssue{Line: v.line, Code: code, Message: fmt.Sprintf(format, args...)})
+}
+
+func (v *fineTuneValidator) warnf(code, format string, args ...any) {
+	v.report.Warnings = append(v.report.Warnings, FineTuneIssue{Line: v.line, Code: code, Message: fmt.Sprintf(format, args...)})
+}
+
+// example validates one JSONL line and returns its token count. ok is false
+// if the example is too malformed to count.
+func (v *fineTuneValidator) example(data []byte) (tokens int, ok bool) {
+	var obj map[string]any
+	if err := json.Unmarshal(data, &obj); err != nil {
+		v.errorf("invalid_json", "line is not a JSON object: %v", err)
+		return 0, false
+	}
+
+	messages, isList := obj["messages"].([]any)
+	if !isList || len(messages) == 0 {
+		v.errorf("missing_messages_list", "example has no messages list")
+		return 0, false
+	}
+
+	tokens = 3 // Every reply is primed with <|start|>assistant<|message|>
+	hasAssistant := false
+	prevRole := ""
+	for i, raw := range messages {
+		msg, isObj := raw.(map[string]any)
+		if !isObj {
+			v.errorf("invalid_message", "message %d is not an object", i)
+			continue
+		}
+
+		var unknown []string
+		for key := range msg {
+			if !fineTuneMessageKeys[key] {
+				unknown = append(unknown, key)
+			}
+		}
+		sort.Strings(unknown)
+		for _, key := range unknown {
+			v.errorf("message_unrecognized_key", "message %d has unrecognized key %q", i, key)
+		}
+
+		role, _ := msg["role"].(string)
+		switch role {
+		case "system", "user", "assistant", "tool", "function":
+		case "":
+			v.errorf("message_missing_key", "message %d has no role", i)
+		default:
+			v.errorf("unrecognized_role", "message %d has unrecognized role %q", i, role)
+		}
+
+		_, hasToolCalls := msg["tool_calls"]
+		_, hasFunctionCall := msg["function_call"]
+		text, content := fineTuneContent(msg["content"])
+		if !content && !(role == "assistant" && (hasToolCalls || hasFunctionCall)) {
+			v.errorf("missing_content", "message %d has no content", i)
+		}
+
+		// Role ordering
+		switch {
+		case role == "system" && i > 0:
+			v.errorf("role_order", "system message %d must come first", i)
+		case role == "tool" && prevRole != "assistant" && prevRole != "tool":
+			v.errorf("role_order", "tool message %d does not follow a tool call", i)
+		case role != "" && role == prevRole && (role == "user" || role == "assistant") && !hasToolCalls:
+			v.warnf("role_order", "consecutive %s messages at %d", role, i)
+		}
+		if role == "assistant" {
+			hasAssistant = true
+		}
+		prevRole = role
+
+		msgTokens := 3 + v.count(role) + v.count(text)
+		if name, _ := msg["name"].(string); name != "" {
+			msgTokens += 1 + v.count(name)
+		}
+		if hasToolCalls || hasFunctionCall {
+			calls, _ := json.Marshal([]any{msg["tool_calls"], msg["function_call"]})
+			msgTokens += v.count(string(calls))
+		}
+		tokens += msgTokens
+		if role == "assistant" {
+			v.report.AssistantTokens += msgTokens - 3
+		}
+	}
+
+	if !hasAssistant {
+		v.errorf("missing_assistant_message", "example has no assistant message")
+	}
+
+	if tools, ok := obj["tools"]; ok {
+		data, _ := json.Marshal(tools)
+		tokens += v.count(string(data))
+	}
+
+	if tokens > FineTuneMaxTokensPerExample {
+		v.report.TruncatedExamples++
+		v.warnf("too_long", "example has ~%d tokens and will be truncated to %d", tokens, FineTuneMaxTokensPerExample)
+	}
+
+	return tokens, true
+}
+
+// fineTuneContent extracts the text of message content, reporting whether
+// any content is present. Content may be a string or a list of parts.
+func fineTuneContent(content any) (string, bool) {
+	switch c := content.(type) {
+	case string:
+		return c, c != ""
+	case []any:
+		var b strings.Builder
+		for _, part := range c {
+			if p, ok := part.(map[string]any); ok {
+				if text, ok := p["text"].(string); ok {
+					b.WriteString(text)
+				}
+			}
+		}
+		return b.String(), len(c) > 0
+	}
+	return "", false
+}
+
+// fineTuneEpochs returns OpenAI's default epoch count for a dataset size.
+func fineTuneEpochs(examples int) int {
+	if examples == 0 {
+		return 0
+	}
+	epochs := fineTuneTargetEpochs
+	if examples*epochs < fineTuneMinTargetExamples {
+		epochs = min(fineTuneMaxDefaultEpochs, fineTuneMinTargetExamples/examples)
+	} else if examples*epochs > fineTuneMaxTargetExamples {
+		epochs = max(fineTuneMinDefaultEpochs, fineTuneMaxTargetExamples/examples)
+	}
+	return epochs
+}
+
+// fineTuneTrainingPrice returns the training price per 1M tokens for model.
+func fineTuneTrainingPrice(model string) (float64, bool) {
+	if i := strings.LastIndex(model, "/"); i >= 0 {
+		model = model[i+1:]
+	}
+	model = strings.TrimPrefix(model, "ft:")
+	best, price := "", 0.0
+	for prefix, p := range fineTuneTrainingPrices {
+		if strings.HasPrefix(model, prefix) && len(prefix) > len(best) {
+			best, price = prefix, p
+		}
+	}
+	return price, best != ""
+}
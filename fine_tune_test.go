@@ -0,0 +1,139 @@
+package warp
+
+import (
+	"math"
+	"strings"
+	"testing"
+)
+
+// validExample is a well-formed fine-tuning example.
+const validExample = `{"messages":[{"role":"system","content":"Be brief."},{"role":"user","content":"Hi"},{"role":"assistant","content":"Hello!"}]}`
+
+// TestValidateFineTuneDataset tests format and role ordering checks
+func TestValidateFineTuneDataset(t *testing.T) {
+	tests := []struct {
+		name     string
+		line     string
+		wantCode string
+		warning  bool
+	}{
+		{name: "valid", line: validExample},
+		{name: "not JSON", line: `{"messages":`, wantCode: "invalid_json"},
+		{name: "no messages", line: `{"prompt":"x"}`, wantCode: "missing_messages_list"},
+		{name: "unknown key", line: `{"messages":[{"role":"user","content":"a","extra":1},{"role":"assistant","content":"b"}]}`, wantCode: "message_unrecognized_key"},
+		{name: "unknown role", line: `{"messages":[{"role":"bot","content":"a"},{"role":"assistant","content":"b"}]}`, wantCode: "unrecognized_role"},
+		{name: "missing content", line: `{"messages":[{"role":"user"},{"role":"assistant","content":"b"}]}`, wantCode: "missing_content"},
+		{name: "no assistant", line: `{"messages":[{"role":"user","content":"a"}]}`, wantCode: "missing_assistant_message"},
+		{name: "late system", line: `{"messages":[{"role":"user","content":"a"},{"role":"system","content":"s"},{"role":"assistant","content":"b"}]}`, wantCode: "role_order"},
+		{name: "orphan tool", line: `{"messages":[{"role":"user","content":"a"},{"role":"tool","tool_call_id":"1","content":"r"},{"role":"assistant","content":"b"}]}`, wantCode: "role_order"},
+		{name: "consecutive users", line: `{"messages":[{"role":"user","content":"a"},{"role":"user","content":"b"},{"role":"assistant","content":"c"}]}`, wantCode: "role_order", warning: true},
+		{
+			name: "tool calls",
+			line: `{"messages":[{"role":"user","content":"a"},{"role":"assistant","tool_calls":[{"id":"1","type":"function","function":{"name":"f","arguments":"{}"}}]},{"role":"tool","tool_call_id":"1","content":"r"},{"role":"assistant","content":"b"}],"tools":[{"type":"function","function":{"name":"f"}}]}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			report, err := ValidateFineTuneDataset(strings.NewReader(tt.line+"\n"), "gpt-4o-mini-2024-07-18")
+			if err != nil {
+				t.Fatalf("ValidateFineTuneDataset() error = %v", err)
+			}
+
+			issues := report.Errors
+			if tt.warning {
+				issues = report.Warnings
+			}
+			if tt.wantCode == "" {
+				// A one-line dataset is too small, but the line is valid
+				for _, issue := range report.Errors {
+					if issue.Line == 1 {
+						t.Errorf("Errors = %v, want none for line 1", report.Errors)
+					}
+				}
+				return
+			}
+			found := false
+			for _, issue := range issues {
+				if issue.Code == tt.wantCode && issue.Line == 1 {
+					found = true
+				}
+			}
+			if !found {
+				t.Errorf("issues = %v, want code %q", issues, tt.wantCode)
+			}
+		})
+	}
+}
+
+// TestValidateFineTuneDatasetCost tests token statistics, epochs, and cost
+func TestValidateFineTuneDatasetCost(t *testing.T) {
+	lines := strings.Repeat(validExample+"\n", 20)
+	report, err := ValidateFineTuneDataset(strings.NewReader(lines+"\n"), "openai/gpt-4o-mini-2024-07-18",
+		WithFineTuneTokenCounter(func(text string) int { return len(strings.Fields(text)) }))
+	if err != nil {
+		t.Fatalf("ValidateFineTuneDataset() error = %v", err)
+	}
+
+	if report.Examples != 20 || !report.Valid() || len(report.Warnings) != 0 {
+		t.Fatalf("report = %+v", report)
+	}
+	// 3 primer + (3+1+2) system + (3+1+1) user + (3+1+1) assistant
+	if report.MinTokens != 19 || report.MaxTokens != 19 || report.MeanTokens != 19 {
+		t.Errorf("tokens min/max/mean = %d/%d/%v, want 19", report.MinTokens, report.MaxTokens, report.MeanTokens)
+	}
+	if report.AssistantTokens != 40 {
+		t.Errorf("AssistantTokens = %d, want 40", report.AssistantTokens)
+	}
+	if report.BillingTokensPerEpoch != 380 {
+		t.Errorf("BillingTokensPerEpoch = %d, want 380", report.BillingTokensPerEpoch)
+	}
+	// 20 examples * 3 epochs < 100 target, so 100/20 = 5 epochs
+	if report.Epochs != 5 {
+		t.Errorf("Epochs = %d, want 5", report.Epochs)
+	}
+	if want := 380.0 * 5 / 1_000_000 * 3.00; math.Abs(report.EstimatedCost-want) > 1e-12 {
+		t.Errorf("EstimatedCost = %v, want %v", report.EstimatedCost, want)
+	}
+}
+
+// TestValidateFineTuneDatasetWarnings tests dataset-level warnings and
+// errors
+func TestValidateFineTuneDatasetWarnings(t *testing.T) {
+	long := `{"messages":[{"role":"user","content":"` + strings.Repeat("x", 4*FineTuneMaxTokensPerExample) + `"},{"role":"assistant","content":"ok"}]}`
+	report, err := ValidateFineTuneDataset(strings.NewReader(long), "unknown-model")
+	if err != nil {
+		t.Fatalf("ValidateFineTuneDataset() error = %v", err)
+	}
+
+	codes := make(map[string]bool)
+	for _, w := range report.Warnings {
+		codes[w.Code] = true
+	}
+	for _, code := range []string{"too_long", "unknown_price"} {
+		if !codes[code] {
+			t.Errorf("missing warning %q in %v", code, report.Warnings)
+		}
+	}
+	if report.Valid() || report.Errors[0].Code != "too_few_examples" {
+		t.Errorf("Errors = %v, want too_few_examples", report.Errors)
+	}
+	if report.TruncatedExamples != 1 || report.BillingTokensPerEpoch != FineTuneMaxTokensPerExample {
+		t.Errorf("TruncatedExamples = %d, BillingTokensPerEpoch = %d", report.TruncatedExamples, report.BillingTokensPerEpoch)
+	}
+	if report.EstimatedCost != 0 {
+		t.Errorf("EstimatedCost = %v, want 0", report.EstimatedCost)
+	}
+}
+
+// TestFineTuneEpochs tests the default epoch heuristic
+func TestFineTuneEpochs(t *testing.T) {
+	tests := []struct{ examples, want int }{
+		{0, 0}, {1, 25}, {10, 10}, {50, 3}, {5000, 3}, {10000, 2}, {50000, 1},
+	}
+	for _, tt := range tests {
+		if got := fineTuneEpochs(tt.examples); got != tt.want {
+			t.Errorf("fineTuneEpochs(%d) = %d, want %d", tt.examples, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,123 @@
+package warp
+
+import "strings"
+
+// FinishReason explains why a model stopped generating.
+//
+// Providers report finish reasons in their own vocabularies (Anthropic
+// "end_turn", Gemini "MAX_TOKENS", ...). Choice.FinishReason holds the
+// normalized value; the provider's native value is kept in
+// CompletionResponse.ProviderFields under ProviderFieldNativeFinishReason.
+type FinishReason string
+
+// Normalized finish reasons.
+const (
+	// FinishReasonStop means the model finished naturally or hit a stop
+	// sequence.
+	FinishReasonStop FinishReason = "stop"
+
+	// FinishReasonLength means the output was cut off by the token limit.
+	FinishReasonLength FinishReason = "length"
+
+	// FinishReasonToolCalls means the model stopped to call tools.
+	FinishReasonToolCalls FinishReason = "tool_calls"
+
+	// FinishReasonContentFilter means output was blocked or cut off by a
+	// safety or content filter.
+	FinishReasonContentFilter FinishReason = "content_filter"
+
+	// FinishReasonError means generation failed on the provider side.
+	FinishReasonError FinishReason = "error"
+)
+
+// ProviderFieldNativeFinishReason is the CompletionResponse.ProviderFields
+// key holding the provider's native finish reason for the first choice,
+// when the provider's vocabulary differs from the normalized one.
+const ProviderFieldNativeFinishReason = "native_finish_reason"
+
+// IsStop reports whether the model finished naturally.
+func (r FinishReason) IsStop() bool { return r == FinishReasonStop }
+
+// IsTruncated reports whether the output was cut off by the token limit.
+func (r FinishReason) IsTruncated() bool { return r == FinishReasonLength }
+
+// IsToolCall reports whether the model stopped to call tools.
+func (r FinishReason) IsToolCall() bool { return r == FinishReasonToolCalls }
+
+// IsFiltered reports whether a content filter blocked or cut off output.
+func (r FinishReason) IsFiltered() bool { return r == FinishReasonContentFilter }
+
+// IsError reports whether generation failed on the provider side.
+func (r FinishReason) IsError() bool { return r == FinishReasonError }
+
+// IsKnown reports whether r is one of the normalized finish reasons.
+func (r FinishReason) IsKnown() bool {
+	switch r {
+	case FinishReasonStop, FinishReasonLength, FinishReasonToolCalls, FinishReasonContentFilter, FinishReasonError:
+		return true
+	}
+	return false
+}
+
+// Reason returns the choice's finish reason as a FinishReason, normalizing
+// native values a provider passed through unmapped.
+//
+// Example:
+//
+//	if resp.Choices[0].Reason().IsTruncated() {
+//	    // ask the model to continue
+//	}
+func (c Choice) Reason() FinishReason {
+	return NormalizeFinishReason(c.FinishReason)
+}
+
+// NormalizeFinishReason maps a native finish reason from any supported
+// provider to a normalized FinishReason. Matching is case-insensitive;
+// unrecognized values are returned unchanged.
+//
+// Example:
+//
+//	warp.NormalizeFinishReason("end_turn")   // FinishReasonStop
+//	warp.NormalizeFinishReason("MAX_TOKENS") // FinishReasonLength
+//	warp.NormalizeFinishReason("SAFETY")     // FinishReasonContentFilter
+func NormalizeFinishReason(reason string) FinishReason {
+	switch strings.ToLower(reason) {
+	case "stop", "end_turn", "stop_sequence", "complete", "finish", "eos", "pause_turn":
+		return FinishReasonStop
+	case "length", "max_tokens", "model_length", "max_output_tokens", "error_limit":
+		return FinishReasonLength
+	case "tool_calls", "tool_use", "function_call", "tool_call":
+		return FinishReasonToolCalls
+	case "content_filter", "content_filtered", "safety", "recitation", "blocklist",
+		"prohibited_content", "spii", "image_safety", "refusal", "guardrail_intervened", "error_toxic":
+		return FinishReasonContentFilter
+	case "error", "malformed_function_call":
+		return FinishReasonError
+	}
+	return FinishReason(reason)
+}
+
+// SetNativeFinishReason records a provider's native finish reason in
+// resp.ProviderFields under ProviderFieldNativeFinishReason. Empty values
+// are ignored. It is intended for provider implementations.
+func SetNativeFinishReason(resp *CompletionResponse, native string) {
+	if native == "" {
+		return
+	}
+	if resp.ProviderFields == nil {
+		resp.ProviderFields = make(map[string]any)
+	}
+	resp.ProviderFields[ProviderFieldNativeFinishReason] = native
+}
+
+// NativeFinishReason returns the provider's native finish reason for the
+// first choice, or the normalized value if the provider did not record one.
+func (r *CompletionResponse) NativeFinishReason() string {
+	if native, ok := r.ProviderFields[ProviderFieldNativeFinishReason].(string); ok {
+		return native
+	}
+	if len(r.Choices) > 0 {
+		return r.Choices[0].FinishReason
+	}
+	return ""
+}
@@ -0,0 +1,81 @@
+package warp
+
+import "testing"
+
+func TestNormalizeFinishReason(t *testing.T) {
+	tests := []struct {
+		input string
+		want  FinishReason
+	}{
+		{"stop", FinishReasonStop},
+		{"end_turn", FinishReasonStop},
+		{"STOP", FinishReasonStop},
+		{"COMPLETE", FinishReasonStop},
+		{"max_tokens", FinishReasonLength},
+		{"MAX_TOKENS", FinishReasonLength},
+		{"ERROR_LIMIT", FinishReasonLength},
+		{"tool_use", FinishReasonToolCalls},
+		{"function_call", FinishReasonToolCalls},
+		{"SAFETY", FinishReasonContentFilter},
+		{"RECITATION", FinishReasonContentFilter},
+		{"guardrail_intervened", FinishReasonContentFilter},
+		{"MALFORMED_FUNCTION_CALL", FinishReasonError},
+		{"something_new", "something_new"},
+		{"", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			if got := NormalizeFinishReason(tt.input); got != tt.want {
+				t.Errorf("NormalizeFinishReason(%q) = %q, want %q", tt.input, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFinishReasonPredicates(t *testing.T) {
+	if !FinishReasonStop.IsStop() || FinishReasonStop.IsTruncated() {
+		t.Error("FinishReasonStop predicates")
+	}
+	if !FinishReasonLength.IsTruncated() {
+		t.Error("FinishReasonLength.IsTruncated() = false")
+	}
+	if !FinishReasonToolCalls.IsToolCall() {
+		t.Error("FinishReasonToolCalls.IsToolCall() = false")
+	}
+	if !FinishReasonContentFilter.IsFiltered() {
+		t.Error("FinishReasonContentFilter.IsFiltered() = false")
+	}
+	if !FinishReasonError.IsError() {
+		t.Error("FinishReasonError.IsError() = false")
+	}
+	if !FinishReasonLength.IsKnown() || FinishReason("end_turn").IsKnown() {
+		t.Error("IsKnown")
+	}
+
+	choice := Choice{FinishReason: "MAX_TOKENS"}
+	if !choice.Reason().IsTruncated() {
+		t.Errorf("Reason() = %q, want length", choice.Reason())
+	}
+}
+
+func TestNativeFinishReason(t *testing.T) {
+	resp := &CompletionResponse{Choices: []Choice{{FinishReason: "stop"}}}
+	if got := resp.NativeFinishReason(); got != "stop" {
+		t.Errorf("NativeFinishReason() without native = %q, want %q", got, "stop")
+	}
+
+	SetNativeFinishReason(resp, "")
+	if resp.ProviderFields != nil {
+		t.Errorf("ProviderFields = %v, want nil for empty native reason", resp.ProviderFields)
+	}
+
+	SetNativeFinishReason(resp, "end_turn")
+	if got := resp.NativeFinishReason(); got != "end_turn" {
+		t.Errorf("NativeFinishReason() = %q, want %q", got, "end_turn")
+	}
+
+	if got := (&CompletionResponse{}).NativeFinishReason(); got != "" {
+		t.Errorf("NativeFinishReason() on empty response = %q", got)
+	}
+}
@@ -0,0 +1,343 @@
+// Package grpcserver exposes a warp client as a gRPC service, so services
+// written in other languages can use warp's routing, fallbacks, policies
+// and callbacks without speaking each provider's HTTP API.
+//
+// warp has no external dependencies, so this package does not import
+// google.golang.org/grpc. It provides the wire-independent half of the
+// server: Service runs requests against a warp client, streams chunks to a
+// send function, and reports failures as StatusError values carrying the
+// gRPC status code for each warp error type. The protocol definition is in
+// warp.proto; server_example.go shows the adapter from the stubs generated
+// from it to Service.
+//
+// Basic usage (with the adapter from server_example.go):
+//
+//	client, _ := warp.NewClient(warp.WithAPIKey("openai", os.Getenv("OPENAI_API_KEY")))
+//	svc, err := grpcserver.New(client, grpcserver.WithMetadata(tenantFromGRPCMetadata))
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+//
+//	server := grpc.NewServer()
+//	warppb.RegisterWarpServer(server, NewWarpServer(svc))
+//	lis, _ := net.Listen("tcp", ":50051")
+//	server.Serve(lis)
+package grpcserver
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"time"
+
+	"github.com/blue-context/warp"
+)
+
+// Code is a gRPC status code.
+//
+// Values match google.golang.org/grpc/codes, so adapters can convert with
+// codes.Code(code).
+type Code uint32
+
+const (
+	OK                 Code = 0
+	Canceled           Code = 1
+	Unknown            Code = 2
+	InvalidArgument    Code = 3
+	DeadlineExceeded   Code = 4
+	NotFound           Code = 5
+	PermissionDenied   Code = 7
+	ResourceExhausted  Code = 8
+	FailedPrecondition Code = 9
+	Unimplemented      Code = 12
+	Internal           Code = 13
+	Unavailable        Code = 14
+	Unauthenticated    Code = 16
+)
+
+// codeNames are the canonical gRPC names of the codes.
+var codeNames = map[Code]string{
+	OK:                 "OK",
+	Canceled:           "Canceled",
+	Unknown:            "Unknown",
+	InvalidArgument:    "InvalidArgument",
+	DeadlineExceeded:   "DeadlineExceeded",
+	NotFound:           "NotFound",
+	PermissionDenied:   "PermissionDenied",
+	ResourceExhausted:  "ResourceExhausted",
+	FailedPrecondition: "FailedPrecondition",
+	Unimplemented:      "Unimplemented",
+	Internal:           "Internal",
+	Unavailable:        "Unavailable",
+	Unauthenticated:    "Unauthenticated",
+}
+
+// String returns the canonical gRPC name of the code.
+func (c Code) String() string {
+	if name, ok := codeNames[c]; ok {
+		return name
+	}
+	return fmt.Sprintf("Code(%d)", uint32(c))
+}
+
+// StatusError is a failed call with its gRPC status code.
+type StatusError struct {
+	// Code is the gRPC status code.
+	Code Code
+
+	// Message is the status message returned to the caller.
+	Message string
+
+	// RetryAfter is how long the caller should wait before retrying
+	// (rate limits only; 0 if unknown).
+	RetryAfter time.Duration
+
+	// Err is the underlying warp error.
+	Err error
+}
+
+// Error implements the error interface.
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("%s: %s", e.Code, e.Message)
+}
+
+// Unwrap returns the underlying error.
+func (e *StatusError) Unwrap() error {
+	return e.Err
+}
+
+// CodeOf returns the gRPC status code for an error returned by a warp
+// client. It returns OK for nil and Unknown for unrecognized errors.
+func CodeOf(err error) Code {
+	if err == nil {
+		return OK
+	}
+
+	var status *StatusError
+	if errors.As(err, &status) {
+		return status.Code
+	}
+
+	switch {
+	case errors.Is(err, context.Canceled):
+		return Canceled
+	case errors.Is(err, context.DeadlineExceeded):
+		return DeadlineExceeded
+	}
+
+	var (
+		authErr        *warp.AuthenticationError
+		permissionErr  *warp.PermissionError
+		policyErr      *warp.PolicyViolationError
+		rateLimitErr   *warp.RateLimitError
+		contextErr     *warp.ContextWindowExceededError
+		contentErr     *warp.ContentPolicyViolationError
+		filterErr      *warp.ContentFilterError
+		invalidErr     *warp.InvalidRequestError
+		badRequestErr  *warp.BadRequestError
+		timeoutErr     *warp.TimeoutError
+		unavailableErr *warp.ServiceUnavailableError
+		apiErr         *warp.APIError
+	)
+	switch {
+	case errors.As(err, &authErr):
+		return Unauthenticated
+	case errors.As(err, &permissionErr), errors.As(err, &policyErr):
+		return PermissionDenied
+	case errors.As(err, &rateLimitErr):
+		return ResourceExhausted
+	case errors.As(err, &contextErr), errors.As(err, &contentErr), errors.As(err, &filterErr),
+		errors.As(err, &invalidErr), errors.As(err, &badRequestErr):
+		return InvalidArgument
+	case errors.As(err, &timeoutErr):
+		return DeadlineExceeded
+	case errors.As(err, &unavailableErr):
+		return Unavailable
+	case errors.As(err, &apiErr):
+		return codeForStatus(apiErr.StatusCode)
+	}
+	return Unknown
+}
+
+// codeForStatus maps an HTTP status code to a gRPC status code.
+func codeForStatus(status int) Code {
+	switch {
+	case status == 404:
+		return NotFound
+	case status == 429:
+		return ResourceExhausted
+	case status == 501:
+		return Unimplemented
+	case status >= 500:
+		return Unavailable
+	case status >= 400:
+		return InvalidArgument
+	}
+	return Unknown
+}
+
+// toStatus converts a warp client error to a StatusError.
+func toStatus(err error) error {
+	if err == nil {
+		return nil
+	}
+	var status *StatusError
+	if errors.As(err, &status) {
+		return err
+	}
+
+	status = &StatusError{Code: CodeOf(err), Message: err.Error(), Err: err}
+	var rateLimitErr *warp.RateLimitError
+	if errors.As(err, &rateLimitErr) {
+		status.RetryAfter = rateLimitErr.RetryAfter
+	}
+	return status
+}
+
+// Service runs gRPC calls against a warp client.
+//
+// Thread Safety: Service is safe for concurrent use if the client is.
+type Service struct {
+	client         warp.Client
+	metadata       func(ctx context.Context) map[string]any
+	allowOverrides bool
+}
+
+// Option configures a Service.
+type Option func(*Service)
+
+// WithMetadata sets a function that derives request metadata from the call
+// context, typically tenant or user identifiers read from incoming gRPC
+// metadata. Its values override metadata sent in the request, so callers
+// cannot claim another tenant's policies.
+func WithMetadata(fn func(ctx context.Context) map[string]any) Option {
+	return func(s *Service) {
+		s.metadata = fn
+	}
+}
+
+// WithRequestOverrides lets callers set per-request API keys, base URLs
+// and API versions. By default they are cleared, so remote callers cannot
+// redirect requests or use credentials other than the server's.
+func WithRequestOverrides() Option {
+	return func(s *Service) {
+		s.allowOverrides = true
+	}
+}
+
+// New creates a service backed by client.
+func New(client warp.Client, opts ...Option) (*Service, error) {
+	if client == nil {
+		return nil, fmt.Errorf("client cannot be nil")
+	}
+	s := &Service{client: client}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s, nil
+}
+
+// Completion runs a completion request.
+//
+// Errors are *StatusError values.
+func (s *Service) Completion(ctx context.Context, req *warp.CompletionRequest) (*warp.CompletionResponse, error) {
+	if err := s.prepareCompletion(ctx, req); err != nil {
+		return nil, err
+	}
+	resp, err := s.client.Completion(ctx, req)
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	return resp, nil
+}
+
+// CompletionStream runs a streaming completion request, calling send with
+// each chunk until the stream ends. It stops at the first send error,
+// which usually means the caller went away, and returns it unchanged.
+//
+// Other errors are *StatusError values.
+func (s *Service) CompletionStream(ctx context.Context, req *warp.CompletionRequest, send func(*warp.CompletionChunk) error) error {
+	if send == nil {
+		return &StatusError{Code: Internal, Message: "send function cannot be nil"}
+	}
+	if err := s.prepareCompletion(ctx, req); err != nil {
+		return err
+	}
+
+	stream, err := s.client.CompletionStream(ctx, req)
+	if err != nil {
+		return toStatus(err)
+	}
+	defer stream.Close()
+
+	for {
+		chunk, err := stream.Recv()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return toStatus(err)
+		}
+		if err := send(chunk); err != nil {
+			return err
+		}
+	}
+}
+
+// Embedding runs an embedding request.
+//
+// Errors are *StatusError values.
+func (s *Service) Embedding(ctx context.Context, req *warp.EmbeddingRequest) (*warp.EmbeddingResponse, error) {
+	if req == nil {
+		return nil, &StatusError{Code: InvalidArgument, Message: "request cannot be nil"}
+	}
+	if req.Model == "" {
+		return nil, &StatusError{Code: InvalidArgument, Message: "model is required"}
+	}
+	if !s.allowOverrides {
+		req.APIKey, req.APIBase, req.APIVersion = "", "", ""
+	}
+	req.Metadata = s.mergeMetadata(ctx, req.Metadata)
+
+	resp, err := s.client.Embedding(ctx, req)
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	return resp, nil
+}
+
+// prepareCompletion validates a completion request and applies the
+// service's override and metadata rules to it.
+func (s *Service) prepareCompletion(ctx context.Context, req *warp.CompletionRequest) error {
+	if req == nil {
+		return &StatusError{Code: InvalidArgument, Message: "request cannot be nil"}
+	}
+	if req.Model == "" {
+		return &StatusError{Code: InvalidArgument, Message: "model is required"}
+	}
+	if len(req.Messages) == 0 {
+		return &StatusError{Code: InvalidArgument, Message: "messages are required"}
+	}
+	if !s.allowOverrides {
+		req.APIKey, req.APIBase, req.APIVersion = "", "", ""
+	}
+	req.Metadata = s.mergeMetadata(ctx, req.Metadata)
+	return nil
+}
+
+// mergeMetadata overlays the context-derived metadata on the request's.
+func (s *Service) mergeMetadata(ctx context.Context, metadata map[string]any) map[string]any {
+	if s.metadata == nil {
+		return metadata
+	}
+	derived := s.metadata(ctx)
+	if len(derived) == 0 {
+		return metadata
+	}
+	merged := make(map[string]any, len(metadata)+len(derived))
+	maps.Copy(merged, metadata)
+	maps.Copy(merged, derived)
+	return merged
+}
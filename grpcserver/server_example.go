@@ -0,0 +1,296 @@
+//go:build example
+// +build example
+
+package grpcserver
+
+// This file is an EXAMPLE showing how to serve a Service with
+// google.golang.org/grpc. It is excluded from normal builds using the "example" build tag.
+//
+// Users should generate the stubs from warp.proto and copy this adapter
+// into their server.
+//
+// To serve warp over gRPC:
+//  1. Generate warppb from warp.proto with protoc-gen-go and protoc-gen-go-grpc
+//  2. Copy the adapter below next to the generated package
+//  3. Register it on a grpc.Server
+//
+// Example usage:
+//
+//	import (
+//	    "google.golang.org/grpc"
+//	    "google.golang.org/grpc/metadata"
+//	    "github.com/blue-context/warp"
+//	    "github.com/blue-context/warp/grpcserver"
+//	)
+//
+//	tenant := func(ctx context.Context) map[string]any {
+//	    md, _ := metadata.FromIncomingContext(ctx)
+//	    if v := md.Get("x-tenant"); len(v) > 0 {
+//	        return map[string]any{"tenant": v[0]}
+//	    }
+//	    return nil
+//	}
+//
+//	svc, _ := grpcserver.New(client, grpcserver.WithMetadata(tenant))
+//	server := grpc.NewServer()
+//	warppb.RegisterWarpServer(server, NewWarpServer(svc))
+
+/*
+Example adapter implementation (requires google.golang.org/grpc and the generated warppb package):
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/durationpb"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+
+	"github.com/blue-context/warp"
+	"github.com/blue-context/warp/grpcserver"
+	"example.com/yourservice/warppb"
+)
+
+// WarpServer implements warppb.WarpServer on top of a grpcserver.Service.
+type WarpServer struct {
+	warppb.UnimplementedWarpServer
+	svc *grpcserver.Service
+}
+
+// NewWarpServer creates a gRPC server for svc.
+func NewWarpServer(svc *grpcserver.Service) *WarpServer {
+	return &WarpServer{svc: svc}
+}
+
+// Completion implements warppb.WarpServer.
+func (s *WarpServer) Completion(ctx context.Context, in *warppb.CompletionRequest) (*warppb.CompletionResponse, error) {
+	req, err := completionRequest(in)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	resp, err := s.svc.Completion(ctx, req)
+	if err != nil {
+		return nil, grpcError(err)
+	}
+	return completionResponse(resp), nil
+}
+
+// CompletionStream implements warppb.WarpServer.
+func (s *WarpServer) CompletionStream(in *warppb.CompletionRequest, out warppb.Warp_CompletionStreamServer) error {
+	req, err := completionRequest(in)
+	if err != nil {
+		return status.Error(codes.InvalidArgument, err.Error())
+	}
+	err = s.svc.CompletionStream(out.Context(), req, func(chunk *warp.CompletionChunk) error {
+		return out.Send(completionChunk(chunk))
+	})
+	return grpcError(err)
+}
+
+// Embedding implements warppb.WarpServer.
+func (s *WarpServer) Embedding(ctx context.Context, in *warppb.EmbeddingRequest) (*warppb.EmbeddingResponse, error) {
+	req := &warp.EmbeddingRequest{
+		Model:    in.Model,
+		Input:    in.Input,
+		User:     in.User,
+		Metadata: stringMap(in.Metadata),
+		Timeout:  time.Duration(in.TimeoutMs) * time.Millisecond,
+	}
+	if in.Dimensions != nil {
+		req.Dimensions = warp.IntPtr(int(*in.Dimensions))
+	}
+	resp, err := s.svc.Embedding(ctx, req)
+	if err != nil {
+		return nil, grpcError(err)
+	}
+
+	out := &warppb.EmbeddingResponse{Model: resp.Model}
+	for _, e := range resp.Data {
+		out.Data = append(out.Data, &warppb.Embedding{Index: int32(e.Index), Embedding: e.Embedding})
+	}
+	if resp.Usage != nil {
+		out.Usage = &warppb.EmbeddingUsage{PromptTokens: int32(resp.Usage.PromptTokens), TotalTokens: int32(resp.Usage.TotalTokens)}
+	}
+	return out, nil
+}
+
+// grpcError converts a grpcserver.StatusError to a gRPC status, attaching
+// retry info to rate limit errors.
+func grpcError(err error) error {
+	if err == nil {
+		return nil
+	}
+	var se *grpcserver.StatusError
+	if !errors.As(err, &se) {
+		return err // send errors are already gRPC statuses
+	}
+	st := status.New(codes.Code(se.Code), se.Message)
+	if se.RetryAfter > 0 {
+		if detailed, err := st.WithDetails(&errdetails.RetryInfo{RetryDelay: durationpb.New(se.RetryAfter)}); err == nil {
+			st = detailed
+		}
+	}
+	return st.Err()
+}
+
+// completionRequest converts a protobuf request to a warp request.
+func completionRequest(in *warppb.CompletionRequest) (*warp.CompletionRequest, error) {
+	req := &warp.CompletionRequest{
+		Model:            in.Model,
+		Stop:             in.Stop,
+		Metadata:         stringMap(in.Metadata),
+		Fallbacks:        in.Fallbacks,
+		Timeout:          time.Duration(in.TimeoutMs) * time.Millisecond,
+		ServiceTier:      warp.ServiceTier(in.ServiceTier),
+		Temperature:      in.Temperature,
+		TopP:             in.TopP,
+		FrequencyPenalty: in.FrequencyPenalty,
+		PresencePenalty:  in.PresencePenalty,
+	}
+	if in.MaxTokens != nil {
+		req.MaxTokens = warp.IntPtr(int(*in.MaxTokens))
+	}
+	if in.N != nil {
+		req.N = warp.IntPtr(int(*in.N))
+	}
+	for _, m := range in.Messages {
+		req.Messages = append(req.Messages, message(m))
+	}
+	for _, t := range in.Tools {
+		var params map[string]any
+		if t.Function.GetParametersJson() != "" {
+			if err := json.Unmarshal([]byte(t.Function.ParametersJson), &params); err != nil {
+				return nil, fmt.Errorf("invalid parameters for tool %q: %w", t.Function.Name, err)
+			}
+		}
+		req.Tools = append(req.Tools, warp.Tool{
+			Type:     t.Type,
+			Function: warp.Function{Name: t.Function.GetName(), Description: t.Function.GetDescription(), Parameters: params},
+		})
+	}
+	if tc := in.ToolChoice; tc != nil {
+		req.ToolChoice = &warp.ToolChoice{Type: tc.Type}
+		if tc.FunctionName != "" {
+			req.ToolChoice.Function = &warp.Function{Name: tc.FunctionName}
+		}
+	}
+	if rf := in.ResponseFormat; rf != nil {
+		req.ResponseFormat = &warp.ResponseFormat{Type: rf.Type}
+		if rf.SchemaJson != "" {
+			var schema map[string]any
+			if err := json.Unmarshal([]byte(rf.SchemaJson), &schema); err != nil {
+				return nil, fmt.Errorf("invalid response schema: %w", err)
+			}
+			req.ResponseFormat.JSONSchema = &warp.JSONSchema{Name: rf.SchemaName, Schema: schema, Strict: rf.Strict}
+		}
+	}
+	if in.ExtraBodyJson != "" {
+		if err := json.Unmarshal([]byte(in.ExtraBodyJson), &req.ExtraBody); err != nil {
+			return nil, fmt.Errorf("invalid extra body: %w", err)
+		}
+	}
+	return req, nil
+}
+
+// message converts a protobuf message to a warp message.
+func message(m *warppb.Message) warp.Message {
+	msg := warp.Message{Role: m.Role, Name: m.Name, ToolCallID: m.ToolCallId, Content: m.Text}
+	if len(m.Parts) > 0 {
+		parts := make([]warp.ContentPart, 0, len(m.Parts))
+		for _, p := range m.Parts {
+			part := warp.ContentPart{Type: p.Type, Text: p.Text}
+			if p.ImageUrl != nil {
+				part.ImageURL = &warp.ImageURL{URL: p.ImageUrl.Url, Detail: p.ImageUrl.Detail}
+			}
+			parts = append(parts, part)
+		}
+		msg.Content = parts
+	}
+	for _, tc := range m.ToolCalls {
+		msg.ToolCalls = append(msg.ToolCalls, warp.ToolCall{
+			ID:       tc.Id,
+			Type:     tc.Type,
+			Function: warp.FunctionCall{Name: tc.Function.GetName(), Arguments: tc.Function.GetArguments()},
+		})
+	}
+	return msg
+}
+
+// completionResponse converts a warp response to protobuf.
+func completionResponse(resp *warp.CompletionResponse) *warppb.CompletionResponse {
+	out := &warppb.CompletionResponse{
+		Id:                resp.ID,
+		Created:           resp.Created,
+		Model:             resp.Model,
+		SystemFingerprint: resp.SystemFingerprint,
+		ServiceTier:       string(resp.ServiceTier),
+		Usage:             usage(resp.Usage),
+	}
+	for _, c := range resp.Choices {
+		text, _ := c.Message.Content.(string)
+		out.Choices = append(out.Choices, &warppb.Choice{
+			Index:        int32(c.Index),
+			Message:      &warppb.Message{Role: c.Message.Role, Text: text, ToolCalls: toolCalls(c.Message.ToolCalls)},
+			FinishReason: c.FinishReason,
+		})
+	}
+	if len(resp.ProviderFields) > 0 {
+		if b, err := json.Marshal(resp.ProviderFields); err == nil {
+			out.ProviderFieldsJson = string(b)
+		}
+	}
+	return out
+}
+
+// completionChunk converts a warp chunk to protobuf.
+func completionChunk(chunk *warp.CompletionChunk) *warppb.CompletionChunk {
+	out := &warppb.CompletionChunk{Id: chunk.ID, Created: chunk.Created, Model: chunk.Model, Usage: usage(chunk.Usage)}
+	for _, c := range chunk.Choices {
+		choice := &warppb.ChunkChoice{
+			Index:     int32(c.Index),
+			Role:      c.Delta.Role,
+			Content:   c.Delta.Content,
+			ToolCalls: toolCalls(c.Delta.ToolCalls),
+		}
+		if c.FinishReason != nil {
+			choice.FinishReason = *c.FinishReason
+		}
+		out.Choices = append(out.Choices, choice)
+	}
+	return out
+}
+
+func toolCalls(calls []warp.ToolCall) []*warppb.ToolCall {
+	var out []*warppb.ToolCall
+	for _, tc := range calls {
+		out = append(out, &warppb.ToolCall{
+			Id:       tc.ID,
+			Type:     tc.Type,
+			Function: &warppb.FunctionCall{Name: tc.Function.Name, Arguments: tc.Function.Arguments},
+		})
+	}
+	return out
+}
+
+func usage(u *warp.Usage) *warppb.Usage {
+	if u == nil {
+		return nil
+	}
+	return &warppb.Usage{PromptTokens: int32(u.PromptTokens), CompletionTokens: int32(u.CompletionTokens), TotalTokens: int32(u.TotalTokens)}
+}
+
+func stringMap(m map[string]string) map[string]any {
+	if len(m) == 0 {
+		return nil
+	}
+	out := make(map[string]any, len(m))
+	for k, v := range m {
+		out[k] = v
+	}
+	return out
+}
+*/
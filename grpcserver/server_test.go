@@ -0,0 +1,173 @@
+package grpcserver
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/blue-context/warp"
+	"github.com/blue-context/warp/internal/testutil"
+)
+
+func newTestService(t *testing.T, mock *testutil.MockProvider, opts ...Option) *Service {
+	t.Helper()
+	mock.NameFunc = func() string { return "mock" }
+	client, err := warp.NewClient(warp.WithMaxRetries(0))
+	if err != nil {
+		t.Fatalf("NewClient() error = %v", err)
+	}
+	client.RegisterProvider(mock)
+	svc, err := New(client, opts...)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	return svc
+}
+
+func request() *warp.CompletionRequest {
+	return &warp.CompletionRequest{Model: "mock/model", Messages: []warp.Message{{Role: "user", Content: "Hi"}}}
+}
+
+func TestCompletion(t *testing.T) {
+	var got *warp.CompletionRequest
+	svc := newTestService(t, &testutil.MockProvider{
+		CompletionFunc: func(ctx context.Context, req *warp.CompletionRequest) (*warp.CompletionResponse, error) {
+			got = req
+			return &warp.CompletionResponse{Choices: []warp.Choice{{Message: warp.Message{Role: "assistant", Content: "Hello"}}}}, nil
+		},
+	}, WithMetadata(func(ctx context.Context) map[string]any {
+		return map[string]any{"tenant": "acme"}
+	}))
+
+	req := request()
+	req.APIBase = "http://169.254.169.254"
+	req.APIKey = "caller-key"
+	req.Metadata = map[string]any{"tenant": "other", "trace": "abc"}
+
+	resp, err := svc.Completion(context.Background(), req)
+	if err != nil {
+		t.Fatalf("Completion() error = %v", err)
+	}
+	if resp.Choices[0].Message.Content != "Hello" {
+		t.Errorf("Content = %v, want Hello", resp.Choices[0].Message.Content)
+	}
+	if got.APIBase != "" || got.APIKey != "" {
+		t.Errorf("APIBase = %q, APIKey = %q, want overrides cleared", got.APIBase, got.APIKey)
+	}
+	if got.Metadata["tenant"] != "acme" || got.Metadata["trace"] != "abc" {
+		t.Errorf("Metadata = %v, want context tenant merged over request", got.Metadata)
+	}
+}
+
+func TestRequestOverrides(t *testing.T) {
+	var got *warp.CompletionRequest
+	svc := newTestService(t, &testutil.MockProvider{
+		CompletionFunc: func(ctx context.Context, req *warp.CompletionRequest) (*warp.CompletionResponse, error) {
+			got = req
+			return &warp.CompletionResponse{}, nil
+		},
+	}, WithRequestOverrides())
+
+	req := request()
+	req.APIKey = "caller-key"
+	if _, err := svc.Completion(context.Background(), req); err != nil {
+		t.Fatalf("Completion() error = %v", err)
+	}
+	if got.APIKey != "caller-key" {
+		t.Errorf("APIKey = %q, want caller key kept", got.APIKey)
+	}
+}
+
+func TestCompletionStream(t *testing.T) {
+	svc := newTestService(t, &testutil.MockProvider{
+		CompletionStreamFunc: func(ctx context.Context, req *warp.CompletionRequest) (warp.Stream, error) {
+			return testutil.NewMockStream(
+				&warp.CompletionChunk{Choices: []warp.ChunkChoice{{Delta: warp.MessageDelta{Content: "Hel"}}}},
+				&warp.CompletionChunk{Choices: []warp.ChunkChoice{{Delta: warp.MessageDelta{Content: "lo"}}}},
+			), nil
+		},
+	})
+
+	var text string
+	err := svc.CompletionStream(context.Background(), request(), func(chunk *warp.CompletionChunk) error {
+		text += chunk.Choices[0].Delta.Content
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("CompletionStream() error = %v", err)
+	}
+	if text != "Hello" {
+		t.Errorf("streamed %q, want Hello", text)
+	}
+
+	// A send failure stops the stream and is returned unchanged
+	sendErr := errors.New("client went away")
+	err = svc.CompletionStream(context.Background(), request(), func(chunk *warp.CompletionChunk) error {
+		return sendErr
+	})
+	if err != sendErr {
+		t.Errorf("CompletionStream() error = %v, want send error", err)
+	}
+}
+
+func TestErrorsAreStatuses(t *testing.T) {
+	svc := newTestService(t, &testutil.MockProvider{
+		CompletionFunc: func(ctx context.Context, req *warp.CompletionRequest) (*warp.CompletionResponse, error) {
+			return nil, warp.NewRateLimitError("slow down", "mock", 3*time.Second, nil)
+		},
+	})
+
+	_, err := svc.Completion(context.Background(), request())
+	var status *StatusError
+	if !errors.As(err, &status) {
+		t.Fatalf("Completion() error = %v, want *StatusError", err)
+	}
+	if status.Code != ResourceExhausted || status.RetryAfter != 3*time.Second {
+		t.Errorf("status = %+v, want ResourceExhausted retrying after 3s", status)
+	}
+	var rateLimit *warp.RateLimitError
+	if !errors.As(err, &rateLimit) {
+		t.Error("StatusError should unwrap to the warp error")
+	}
+
+	if _, err := svc.Completion(context.Background(), &warp.CompletionRequest{Model: "mock/model"}); CodeOf(err) != InvalidArgument {
+		t.Errorf("CodeOf(no messages) = %v, want InvalidArgument", CodeOf(err))
+	}
+	if _, err := svc.Embedding(context.Background(), &warp.EmbeddingRequest{}); CodeOf(err) != InvalidArgument {
+		t.Errorf("CodeOf(no model) = %v, want InvalidArgument", CodeOf(err))
+	}
+}
+
+func TestCodeOf(t *testing.T) {
+	tests := []struct {
+		err  error
+		want Code
+	}{
+		{nil, OK},
+		{context.Canceled, Canceled},
+		{fmt.Errorf("wrapped: %w", context.DeadlineExceeded), DeadlineExceeded},
+		{warp.NewAuthenticationError("bad key", "openai", nil), Unauthenticated},
+		{warp.NewPermissionError("forbidden", "openai", nil), PermissionDenied},
+		{warp.NewPolicyViolationError("banned", "banned_models", "acme", "openai", "gpt-4"), PermissionDenied},
+		{warp.NewContextWindowExceededError("too long", "openai", 8192, 9000, nil), InvalidArgument},
+		{warp.NewContentFilterError("filtered", "azure", "content_filter", true, nil), InvalidArgument},
+		{warp.NewTimeoutError("timeout", "openai", nil), DeadlineExceeded},
+		{warp.NewServiceUnavailableError("down", "openai", nil), Unavailable},
+		{warp.NewAPIError("no such model", 404, "openai", nil), NotFound},
+		{warp.NewAPIError("overloaded", 529, "anthropic", nil), Unavailable},
+		{errors.New("something else"), Unknown},
+	}
+	for _, tt := range tests {
+		if got := CodeOf(tt.err); got != tt.want {
+			t.Errorf("CodeOf(%v) = %v, want %v", tt.err, got, tt.want)
+		}
+	}
+}
+
+func TestNew(t *testing.T) {
+	if _, err := New(nil); err == nil {
+		t.Error("New(nil) error = nil, want error")
+	}
+}
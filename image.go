@@ -2,11 +2,9 @@ package warp
 
 import (
 	"context"
-	"encoding/base64"
 	"fmt"
 	"io"
 	"net/http"
-	"os"
 )
 
 // ImageGeneration generates images from text prompts using the specified model.
@@ -52,6 +50,7 @@ func (c *client) ImageGeneration(ctx context.Context, req *ImageGenerationReques
 
 	ctx = WithProvider(ctx, providerName)
 	ctx = WithModel(ctx, modelName)
+	ctx = c.withRequestHeaders(ctx)
 
 	// Get provider
 	prov, err := c.getProvider(providerName)
@@ -69,26 +68,33 @@ func (c *client) ImageGeneration(ctx context.Context, req *ImageGenerationReques
 		return nil, fmt.Errorf("provider %q does not support image generation", providerName)
 	}
 
+	// Refuse the request once the budget is spent
+	if err := c.checkBudget(providerName, modelName); err != nil {
+		return nil, err
+	}
+
 	// Update model name (strip provider prefix)
 	req.Model = modelName
 
 	// Apply timeout if configured
 	if c.config.DefaultTimeout > 0 && req.Timeout == 0 {
 		var cancel context.CancelFunc
-		ctx, cancel = context.WithTimeout(ctx, c.config.DefaultTimeout)
+		ctx, cancel = c.withTimeout(ctx, c.config.DefaultTimeout)
 		defer cancel()
 	} else if req.Timeout > 0 {
 		var cancel context.CancelFunc
-		ctx, cancel = context.WithTimeout(ctx, req.Timeout)
+		ctx, cancel = c.withTimeout(ctx, req.Timeout)
 		defer cancel()
 	}
 
 	// Execute with retry logic
 	var resp *ImageGenerationResponse
 	err = c.withRetry(ctx, func() error {
-		var retryErr error
-		resp, retryErr = imgProvider.ImageGeneration(ctx, req)
-		return retryErr
+		return c.callProvider(ctx, providerName, func() error {
+			var retryErr error
+			resp, retryErr = imgProvider.ImageGeneration(ctx, req)
+			return retryErr
+		})
 	})
 
 	if err != nil {
@@ -98,6 +104,11 @@ func (c *client) ImageGeneration(ctx context.Context, req *ImageGenerationReques
 	// Set metadata
 	resp.Provider = providerName
 	resp.Model = modelName
+	resp.RequestID = RequestIDFromContext(ctx)
+	resp.ProviderRequestID = ProviderRequestIDFromContext(ctx)
+	resp.Size = req.Size
+	resp.Quality = req.Quality
+	c.chargeImages(resp)
 
 	return resp, nil
 }
@@ -164,6 +175,7 @@ func (c *client) ImageEdit(ctx context.Context, req *ImageEditRequest) (*ImageGe
 
 	ctx = WithProvider(ctx, providerName)
 	ctx = WithModel(ctx, modelName)
+	ctx = c.withRequestHeaders(ctx)
 
 	// Get provider
 	prov, err := c.getProvider(providerName)
@@ -181,27 +193,42 @@ func (c *client) ImageEdit(ctx context.Context, req *ImageEditRequest) (*ImageGe
 		return nil, fmt.Errorf("provider %q does not support image editing", providerName)
 	}
 
+	// Refuse the request once the budget is spent
+	if err := c.checkBudget(providerName, modelName); err != nil {
+		return nil, err
+	}
+
 	// Update model name (strip provider prefix)
 	req.Model = modelName
 
 	// Apply timeout if configured
 	if c.config.DefaultTimeout > 0 && req.Timeout == 0 {
 		var cancel context.CancelFunc
-		ctx, cancel = context.WithTimeout(ctx, c.config.DefaultTimeout)
+		ctx, cancel = c.withTimeout(ctx, c.config.DefaultTimeout)
 		defer cancel()
 	} else if req.Timeout > 0 {
 		var cancel context.CancelFunc
-		ctx, cancel = context.WithTimeout(ctx, req.Timeout)
+		ctx, cancel = c.withTimeout(ctx, req.Timeout)
 		defer cancel()
 	}
 
+	// Make the image and mask readable again for retries
+	editReq := *req
+	req = &editReq
+	files := c.newUploads(&req.Image, &req.Mask)
+
 	// Execute with retry logic
 	var resp *ImageGenerationResponse
-	err = c.withRetry(ctx, func() error {
-		var retryErr error
-		resp, retryErr = imgEditProvider.ImageEdit(ctx, req)
-		return retryErr
-	})
+	err = c.withRetryIf(ctx, func() error {
+		if err := files.rewind(); err != nil {
+			return err
+		}
+		return c.callProvider(ctx, providerName, func() error {
+			var retryErr error
+			resp, retryErr = imgEditProvider.ImageEdit(ctx, req)
+			return retryErr
+		})
+	}, files.replayable)
 
 	if err != nil {
 		return nil, err
@@ -210,6 +237,10 @@ func (c *client) ImageEdit(ctx context.Context, req *ImageEditRequest) (*ImageGe
 	// Set metadata
 	resp.Provider = providerName
 	resp.Model = modelName
+	resp.RequestID = RequestIDFromContext(ctx)
+	resp.ProviderRequestID = ProviderRequestIDFromContext(ctx)
+	resp.Size = req.Size
+	c.chargeImages(resp)
 
 	return resp, nil
 }
@@ -270,6 +301,7 @@ func (c *client) ImageVariation(ctx context.Context, req *ImageVariationRequest)
 
 	ctx = WithProvider(ctx, providerName)
 	ctx = WithModel(ctx, modelName)
+	ctx = c.withRequestHeaders(ctx)
 
 	// Get provider
 	prov, err := c.getProvider(providerName)
@@ -287,27 +319,42 @@ func (c *client) ImageVariation(ctx context.Context, req *ImageVariationRequest)
 		return nil, fmt.Errorf("provider %q does not support image variation", providerName)
 	}
 
+	// Refuse the request once the budget is spent
+	if err := c.checkBudget(providerName, modelName); err != nil {
+		return nil, err
+	}
+
 	// Update model name (strip provider prefix)
 	req.Model = modelName
 
 	// Apply timeout if configured
 	if c.config.DefaultTimeout > 0 && req.Timeout == 0 {
 		var cancel context.CancelFunc
-		ctx, cancel = context.WithTimeout(ctx, c.config.DefaultTimeout)
+		ctx, cancel = c.withTimeout(ctx, c.config.DefaultTimeout)
 		defer cancel()
 	} else if req.Timeout > 0 {
 		var cancel context.CancelFunc
-		ctx, cancel = context.WithTimeout(ctx, req.Timeout)
+		ctx, cancel = c.withTimeout(ctx, req.Timeout)
 		defer cancel()
 	}
 
+	// Make the image readable again for retries
+	variationReq := *req
+	req = &variationReq
+	files := c.newUploads(&req.Image)
+
 	// Execute with retry logic
 	var resp *ImageGenerationResponse
-	err = c.withRetry(ctx, func() error {
-		var retryErr error
-		resp, retryErr = imgVarProvider.ImageVariation(ctx, req)
-		return retryErr
-	})
+	err = c.withRetryIf(ctx, func() error {
+		if err := files.rewind(); err != nil {
+			return err
+		}
+		return c.callProvider(ctx, providerName, func() error {
+			var retryErr error
+			resp, retryErr = imgVarProvider.ImageVariation(ctx, req)
+			return retryErr
+		})
+	}, files.replayable)
 
 	if err != nil {
 		return nil, err
@@ -316,46 +363,14 @@ func (c *client) ImageVariation(ctx context.Context, req *ImageVariationRequest)
 	// Set metadata
 	resp.Provider = providerName
 	resp.Model = modelName
+	resp.RequestID = RequestIDFromContext(ctx)
+	resp.ProviderRequestID = ProviderRequestIDFromContext(ctx)
+	resp.Size = req.Size
+	c.chargeImages(resp)
 
 	return resp, nil
 }
 
-// SaveToFile downloads and saves an image to a file.
-//
-// Works with both URL and base64-encoded images.
-// The file is created with 0644 permissions.
-//
-// Example:
-//
-//	err := imageData.SaveToFile(ctx, "output.png")
-func (img *ImageData) SaveToFile(ctx context.Context, path string) error {
-	var data []byte
-	var err error
-
-	if img.URL != "" {
-		// Download from URL
-		data, err = downloadImage(ctx, img.URL)
-		if err != nil {
-			return fmt.Errorf("failed to download image: %w", err)
-		}
-	} else if img.B64JSON != "" {
-		// Decode base64
-		data, err = base64.StdEncoding.DecodeString(img.B64JSON)
-		if err != nil {
-			return fmt.Errorf("failed to decode base64 image: %w", err)
-		}
-	} else {
-		return fmt.Errorf("no image data available (neither URL nor base64)")
-	}
-
-	// Write to file
-	if err := os.WriteFile(path, data, 0644); err != nil {
-		return fmt.Errorf("failed to write file: %w", err)
-	}
-
-	return nil
-}
-
 // downloadImage downloads an image from a URL.
 func downloadImage(ctx context.Context, url string) ([]byte, error) {
 	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
@@ -0,0 +1,46 @@
+//go:build !tinygo && !warp_minimal
+
+package warp
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"os"
+)
+
+// SaveToFile downloads and saves an image to a file.
+//
+// Works with both URL and base64-encoded images.
+// The file is created with 0644 permissions.
+//
+// Example:
+//
+//	err := imageData.SaveToFile(ctx, "output.png")
+func (img *ImageData) SaveToFile(ctx context.Context, path string) error {
+	var data []byte
+	var err error
+
+	if img.URL != "" {
+		// Download from URL
+		data, err = downloadImage(ctx, img.URL)
+		if err != nil {
+			return fmt.Errorf("failed to download image: %w", err)
+		}
+	} else if img.B64JSON != "" {
+		// Decode base64
+		data, err = base64.StdEncoding.DecodeString(img.B64JSON)
+		if err != nil {
+			return fmt.Errorf("failed to decode base64 image: %w", err)
+		}
+	} else {
+		return fmt.Errorf("no image data available (neither URL nor base64)")
+	}
+
+	// Write to file
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return fmt.Errorf("failed to write file: %w", err)
+	}
+
+	return nil
+}
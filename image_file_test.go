@@ -0,0 +1,136 @@
+//go:build !tinygo && !warp_minimal
+
+package warp
+
+import (
+	"context"
+	"encoding/base64"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestImageData_SaveToFile(t *testing.T) {
+	// Create temporary directory for test files
+	tempDir := t.TempDir()
+
+	// Create a test HTTP server for URL downloads
+	testImageData := []byte("fake image data")
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+		w.Write(testImageData)
+	}))
+	defer server.Close()
+
+	// Create base64 encoded test data
+	base64Data := base64.StdEncoding.EncodeToString(testImageData)
+
+	tests := []struct {
+		name      string
+		imageData ImageData
+		wantErr   bool
+		errString string
+	}{
+		{
+			name: "save from URL",
+			imageData: ImageData{
+				URL: server.URL + "/image.png",
+			},
+			wantErr: false,
+		},
+		{
+			name: "save from base64",
+			imageData: ImageData{
+				B64JSON: base64Data,
+			},
+			wantErr: false,
+		},
+		{
+			name:      "no image data",
+			imageData: ImageData{},
+			wantErr:   true,
+			errString: "no image data available",
+		},
+		{
+			name: "invalid base64",
+			imageData: ImageData{
+				B64JSON: "not-valid-base64!@#$",
+			},
+			wantErr:   true,
+			errString: "failed to decode base64 image",
+		},
+		{
+			name: "invalid URL",
+			imageData: ImageData{
+				URL: "http://invalid-domain-that-does-not-exist.test/image.png",
+			},
+			wantErr:   true,
+			errString: "failed to download image",
+		},
+		{
+			name: "HTTP error",
+			imageData: ImageData{
+				URL: server.URL + "/notfound",
+			},
+			wantErr: false, // Server returns 200 for all requests in this test
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(tempDir, tt.name+".png")
+
+			err := tt.imageData.SaveToFile(context.Background(), path)
+
+			if tt.wantErr {
+				if err == nil {
+					t.Fatal("expected error, got nil")
+				}
+				if tt.errString != "" && !strings.Contains(err.Error(), tt.errString) {
+					t.Errorf("error %q does not contain %q", err.Error(), tt.errString)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			// Verify file was created
+			data, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatalf("failed to read saved file: %v", err)
+			}
+
+			if len(data) == 0 {
+				t.Error("saved file is empty")
+			}
+		})
+	}
+}
+
+func TestImageData_SaveToFile_ContextCancellation(t *testing.T) {
+	// Create a server that delays response
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-r.Context().Done()
+	}))
+	defer server.Close()
+
+	img := ImageData{
+		URL: server.URL + "/image.png",
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel() // Cancel immediately
+
+	tempDir := t.TempDir()
+	path := filepath.Join(tempDir, "cancelled.png")
+
+	err := img.SaveToFile(ctx, path)
+	if err == nil {
+		t.Error("expected error for cancelled context")
+	}
+}
@@ -0,0 +1,237 @@
+// Package jsonschema validates JSON documents against the subset of JSON
+// Schema used for function parameters and structured outputs. It is shared
+// by the agent package, which checks tool call arguments, and the synth
+// package, which checks generated outputs.
+package jsonschema
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"unicode/utf8"
+)
+
+// Validate checks a JSON document against a JSON Schema and returns each
+// mismatch as "path: message", or nil if the document matches.
+//
+// It supports type, properties, required, additionalProperties (as a
+// boolean), items, enum, const, minimum, maximum, minLength, maxLength,
+// minItems, and maxItems. Unsupported keywords are ignored. An empty or nil
+// schema accepts any JSON object.
+func Validate(schema map[string]any, document string) []string {
+	dec := json.NewDecoder(bytes.NewReader([]byte(document)))
+	dec.UseNumber()
+	var value any
+	if err := dec.Decode(&value); err != nil {
+		return []string{"not valid JSON: " + err.Error()}
+	}
+
+	// Round-trip the schema through JSON so Go-typed literals (e.g.,
+	// []string enums or nested map types) are handled uniformly
+	schema, err := normalizeSchema(schema)
+	if err != nil {
+		return []string{"invalid schema: " + err.Error()}
+	}
+
+	v := &validator{}
+	if len(schema) == 0 {
+		if _, ok := value.(map[string]any); !ok {
+			v.addf("$", "expected object")
+		}
+	} else {
+		v.validate("$", schema, value)
+	}
+	return v.problems
+}
+
+// validator accumulates schema mismatches.
+type validator struct {
+	problems []string
+}
+
+func (v *validator) addf(path, format string, args ...any) {
+	v.problems = append(v.problems, path+": "+fmt.Sprintf(format, args...))
+}
+
+// validate checks value against schema at path.
+func (v *validator) validate(path string, schema map[string]any, value any) {
+	if enum, ok := schema["enum"].([]any); ok && !containsJSON(enum, value) {
+		v.addf(path, "must be one of %s", compactJSON(enum))
+	}
+	if c, ok := schema["const"]; ok && !equalJSON(c, value) {
+		v.addf(path, "must equal %s", compactJSON(c))
+	}
+
+	if !v.checkType(path, schema["type"], value) {
+		return
+	}
+
+	switch val := value.(type) {
+	case map[string]any:
+		v.validateObject(path, schema, val)
+	case []any:
+		if items, ok := schema["items"].(map[string]any); ok {
+			for i, item := range val {
+				v.validate(fmt.Sprintf("%s[%d]", path, i), items, item)
+			}
+		}
+		if n, ok := number(schema["minItems"]); ok && float64(len(val)) < n {
+			v.addf(path, "must have at least %v items", n)
+		}
+		if n, ok := number(schema["maxItems"]); ok && float64(len(val)) > n {
+			v.addf(path, "must have at most %v items", n)
+		}
+	case string:
+		length := float64(utf8.RuneCountInString(val))
+		if n, ok := number(schema["minLength"]); ok && length < n {
+			v.addf(path, "must be at least %v characters", n)
+		}
+		if n, ok := number(schema["maxLength"]); ok && length > n {
+			v.addf(path, "must be at most %v characters", n)
+		}
+	case json.Number:
+		f, _ := val.Float64()
+		if n, ok := number(schema["minimum"]); ok && f < n {
+			v.addf(path, "must be >= %v", n)
+		}
+		if n, ok := number(schema["maximum"]); ok && f > n {
+			v.addf(path, "must be <= %v", n)
+		}
+	}
+}
+
+// validateObject checks required, properties, and additionalProperties.
+func (v *validator) validateObject(path string, schema map[string]any, obj map[string]any) {
+	properties, _ := schema["properties"].(map[string]any)
+
+	for _, name := range stringList(schema["required"]) {
+		if _, ok := obj[name]; !ok {
+			v.addf(path+"."+name, "is required")
+		}
+	}
+
+	names := make([]string, 0, len(obj))
+	for name := range obj {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		propSchema, known := properties[name].(map[string]any)
+		if known {
+			v.validate(path+"."+name, propSchema, obj[name])
+			continue
+		}
+		if additional, ok := schema["additionalProperties"].(bool); ok && !additional {
+			v.addf(path+"."+name, "is not an allowed property")
+		}
+	}
+}
+
+// checkType reports whether value matches the schema type (a string or a
+// list of strings), recording a problem if it does not.
+func (v *validator) checkType(path string, schemaType any, value any) bool {
+	types := stringList(schemaType)
+	if s, ok := schemaType.(string); ok {
+		types = []string{s}
+	}
+	if len(types) == 0 {
+		return true
+	}
+
+	actual := jsonType(value)
+	for _, t := range types {
+		if t == actual || (t == "number" && actual == "integer") {
+			return true
+		}
+	}
+	v.addf(path, "expected %s, got %s", strings.Join(types, " or "), actual)
+	return false
+}
+
+// jsonType returns the JSON Schema type name of a decoded value.
+func jsonType(value any) string {
+	switch val := value.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case string:
+		return "string"
+	case []any:
+		return "array"
+	case map[string]any:
+		return "object"
+	case json.Number:
+		if f, err := val.Float64(); err == nil && f == math.Trunc(f) && !strings.ContainsAny(val.String(), ".eE") {
+			return "integer"
+		}
+		return "number"
+	}
+	return fmt.Sprintf("%T", value)
+}
+
+// number converts a schema numeric keyword to float64.
+func number(v any) (float64, bool) {
+	n, ok := v.(float64)
+	return n, ok
+}
+
+// stringList converts a []any of strings to []string.
+func stringList(v any) []string {
+	list, _ := v.([]any)
+	out := make([]string, 0, len(list))
+	for _, item := range list {
+		if s, ok := item.(string); ok {
+			out = append(out, s)
+		}
+	}
+	return out
+}
+
+// normalizeSchema converts a schema to its generic JSON representation.
+func normalizeSchema(schema map[string]any) (map[string]any, error) {
+	if len(schema) == 0 {
+		return nil, nil
+	}
+	data, err := json.Marshal(schema)
+	if err != nil {
+		return nil, err
+	}
+	var normalized map[string]any
+	if err := json.Unmarshal(data, &normalized); err != nil {
+		return nil, err
+	}
+	return normalized, nil
+}
+
+// containsJSON reports whether list contains a value equal to value.
+func containsJSON(list []any, value any) bool {
+	for _, item := range list {
+		if equalJSON(item, value) {
+			return true
+		}
+	}
+	return false
+}
+
+// equalJSON compares two values by their canonical JSON encoding.
+func equalJSON(a, b any) bool {
+	return compactJSON(a) == compactJSON(b)
+}
+
+// compactJSON returns the JSON encoding of v with numbers normalized.
+func compactJSON(v any) string {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprint(v)
+	}
+	var normalized any
+	if json.Unmarshal(data, &normalized) == nil {
+		data, _ = json.Marshal(normalized)
+	}
+	return string(data)
+}
@@ -0,0 +1,49 @@
+package jsonschema
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestValidate(t *testing.T) {
+	schema := map[string]any{
+		"type": "object",
+		"properties": map[string]any{
+			"name":  map[string]any{"type": "string", "minLength": 2},
+			"count": map[string]any{"type": "integer", "minimum": 1},
+			"tags":  map[string]any{"type": "array", "items": map[string]any{"type": "string"}},
+		},
+		"required":             []string{"name"},
+		"additionalProperties": false,
+	}
+
+	tests := []struct {
+		name     string
+		schema   map[string]any
+		document string
+		want     string
+	}{
+		{name: "valid", schema: schema, document: `{"name":"go","count":2,"tags":["a"]}`},
+		{name: "missing required", schema: schema, document: `{"count":2}`, want: "$.name: is required"},
+		{name: "wrong item type", schema: schema, document: `{"name":"go","tags":[1]}`, want: "$.tags[0]: expected string, got integer"},
+		{name: "extra property", schema: schema, document: `{"name":"go","x":1}`, want: "$.x: is not an allowed property"},
+		{name: "malformed", schema: schema, document: `{"name":`, want: "not valid JSON"},
+		{name: "empty schema accepts object", document: `{"any":1}`},
+		{name: "empty schema rejects array", document: `[1]`, want: "$: expected object"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			problems := Validate(tt.schema, tt.document)
+			if tt.want == "" {
+				if len(problems) > 0 {
+					t.Errorf("Validate() = %v, want none", problems)
+				}
+				return
+			}
+			if got := strings.Join(problems, "; "); !strings.Contains(got, tt.want) {
+				t.Errorf("Validate() = %q, want containing %q", got, tt.want)
+			}
+		})
+	}
+}
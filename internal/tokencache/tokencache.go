@@ -0,0 +1,215 @@
+// Package tokencache caches expiring bearer tokens for providers that
+// authenticate with OAuth, such as Vertex AI service accounts and Azure AD.
+//
+// A Cache renews its token before it expires: once a token enters its
+// refresh window, the next caller starts a refresh in the background and
+// keeps using the current token until the new one arrives. The refresh
+// window is shortened by a random jitter per token, so processes started
+// together do not renew together, and tokens are treated as expired a
+// little early to tolerate clock skew between the host and the token
+// endpoint. Concurrent callers share one refresh, so a burst of requests
+// with an expired token makes a single call to the token endpoint.
+package tokencache
+
+import (
+	"context"
+	"errors"
+	"math/rand"
+	"sync"
+	"time"
+)
+
+const (
+	// DefaultRefreshBefore is how long before expiry a token is renewed.
+	DefaultRefreshBefore = 5 * time.Minute
+
+	// DefaultJitter is the maximum random extra time a token is renewed
+	// early.
+	DefaultJitter = time.Minute
+
+	// DefaultClockSkew is how long before its expiry a token is no longer
+	// used.
+	DefaultClockSkew = 30 * time.Second
+)
+
+// Token is an access token and its expiry.
+type Token struct {
+	// AccessToken is the bearer token.
+	AccessToken string
+
+	// Expiry is when the token expires. The zero time means it does not.
+	Expiry time.Time
+}
+
+// FetchFunc fetches a new token from the token endpoint.
+type FetchFunc func(ctx context.Context) (*Token, error)
+
+// Cache caches the token returned by a FetchFunc.
+//
+// Thread Safety: Cache is safe for concurrent use.
+type Cache struct {
+	fetch         FetchFunc
+	refreshBefore time.Duration
+	jitter        time.Duration
+	skew          time.Duration
+	now           func() time.Time
+
+	mu        sync.Mutex
+	token     *Token
+	refreshAt time.Time
+	inflight  *refresh
+}
+
+// refresh is a fetch in progress, shared by the callers waiting for it.
+type refresh struct {
+	done  chan struct{}
+	token *Token
+	err   error
+}
+
+// Option configures a Cache.
+type Option func(*Cache)
+
+// WithRefreshBefore sets how long before expiry a token is renewed in the
+// background.
+//
+// The default is DefaultRefreshBefore.
+func WithRefreshBefore(d time.Duration) Option {
+	return func(c *Cache) {
+		c.refreshBefore = d
+	}
+}
+
+// WithJitter sets the maximum random extra time a token is renewed early.
+//
+// The default is DefaultJitter.
+func WithJitter(d time.Duration) Option {
+	return func(c *Cache) {
+		c.jitter = d
+	}
+}
+
+// WithClockSkew sets how long before its expiry a token is no longer used,
+// to tolerate clocks that differ between the host and the token endpoint.
+//
+// The default is DefaultClockSkew.
+func WithClockSkew(d time.Duration) Option {
+	return func(c *Cache) {
+		c.skew = d
+	}
+}
+
+// New creates a Cache that fetches tokens with fetch.
+//
+// Example:
+//
+//	tokens := tokencache.New(func(ctx context.Context) (*tokencache.Token, error) {
+//	    return exchangeCredentials(ctx)
+//	})
+//	token, err := tokens.Token(ctx)
+func New(fetch FetchFunc, opts ...Option) *Cache {
+	c := &Cache{
+		fetch:         fetch,
+		refreshBefore: DefaultRefreshBefore,
+		jitter:        DefaultJitter,
+		skew:          DefaultClockSkew,
+		now:           time.Now,
+	}
+
+	for _, opt := range opts {
+		opt(c)
+	}
+
+	return c
+}
+
+// Token returns a valid access token, fetching one if the cached token is
+// missing or expired. A token in its refresh window is returned at once
+// while a new one is fetched in the background.
+func (c *Cache) Token(ctx context.Context) (string, error) {
+	c.mu.Lock()
+	now := c.now()
+	if c.token != nil && c.valid(now) {
+		token := c.token.AccessToken
+		if !c.refreshAt.IsZero() && !now.Before(c.refreshAt) && c.inflight == nil {
+			c.startRefresh(context.WithoutCancel(ctx))
+		}
+		c.mu.Unlock()
+		return token, nil
+	}
+
+	r := c.inflight
+	if r == nil {
+		r = c.startRefresh(context.WithoutCancel(ctx))
+	}
+	c.mu.Unlock()
+
+	select {
+	case <-r.done:
+	case <-ctx.Done():
+		return "", ctx.Err()
+	}
+	if r.err != nil {
+		return "", r.err
+	}
+	return r.token.AccessToken, nil
+}
+
+// Invalidate discards the cached token, for example after the provider
+// rejected it, so the next call to Token fetches a new one.
+func (c *Cache) Invalidate() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.token = nil
+}
+
+// valid reports whether the cached token can still be used at now. c.mu
+// must be held.
+func (c *Cache) valid(now time.Time) bool {
+	return c.token.Expiry.IsZero() || now.Before(c.token.Expiry.Add(-c.skew))
+}
+
+// startRefresh starts fetching a new token. c.mu must be held.
+func (c *Cache) startRefresh(ctx context.Context) *refresh {
+	r := &refresh{done: make(chan struct{})}
+	c.inflight = r
+
+	go func() {
+		token, err := c.fetch(ctx)
+		if err == nil && (token == nil || token.AccessToken == "") {
+			err = errors.New("token endpoint returned no access token")
+		}
+
+		c.mu.Lock()
+		if err == nil {
+			c.token = token
+			c.refreshAt = c.refreshTime(token)
+		}
+		r.token, r.err = token, err
+		c.inflight = nil
+		c.mu.Unlock()
+		close(r.done)
+	}()
+	return r
+}
+
+// refreshTime returns when token should be renewed: the refresh window
+// plus a random jitter before it expires, but not before half its
+// remaining lifetime has passed. It returns the zero time for a token that
+// does not expire.
+func (c *Cache) refreshTime(token *Token) time.Time {
+	if token.Expiry.IsZero() {
+		return time.Time{}
+	}
+	now := c.now()
+	early := c.refreshBefore
+	if c.jitter > 0 {
+		early += time.Duration(rand.Int63n(int64(c.jitter)))
+	}
+	lifetime := token.Expiry.Sub(now)
+	if early > lifetime/2 {
+		early = lifetime / 2
+	}
+	return token.Expiry.Add(-early)
+}
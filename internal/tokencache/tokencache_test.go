@@ -0,0 +1,150 @@
+package tokencache
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// fakeNow is a settable clock.
+type fakeNow struct {
+	mu  sync.Mutex
+	now time.Time
+}
+
+func (f *fakeNow) Now() time.Time {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.now
+}
+
+func (f *fakeNow) Add(d time.Duration) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.now = f.now.Add(d)
+}
+
+func TestTokenSingleFlight(t *testing.T) {
+	var calls atomic.Int32
+	release := make(chan struct{})
+	c := New(func(ctx context.Context) (*Token, error) {
+		calls.Add(1)
+		<-release
+		return &Token{AccessToken: "token", Expiry: time.Now().Add(time.Hour)}, nil
+	})
+
+	var wg sync.WaitGroup
+	errs := make(chan error, 20)
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			token, err := c.Token(context.Background())
+			if err == nil && token != "token" {
+				err = fmt.Errorf("Token() = %q, want token", token)
+			}
+			errs <- err
+		}()
+	}
+	time.Sleep(10 * time.Millisecond)
+	close(release)
+	wg.Wait()
+	close(errs)
+
+	for err := range errs {
+		if err != nil {
+			t.Error(err)
+		}
+	}
+	if got := calls.Load(); got != 1 {
+		t.Errorf("fetched %d times, want 1", got)
+	}
+}
+
+func TestTokenProactiveRefresh(t *testing.T) {
+	clock := &fakeNow{now: time.Unix(1_700_000_000, 0)}
+	var calls atomic.Int32
+	refreshed := make(chan struct{}, 1)
+	c := New(func(ctx context.Context) (*Token, error) {
+		n := calls.Add(1)
+		if n > 1 {
+			refreshed <- struct{}{}
+		}
+		return &Token{AccessToken: fmt.Sprintf("token-%d", n), Expiry: clock.Now().Add(time.Hour)}, nil
+	}, WithJitter(0))
+	c.now = clock.Now
+
+	if token, _ := c.Token(context.Background()); token != "token-1" {
+		t.Fatalf("Token() = %q, want token-1", token)
+	}
+
+	// Inside the refresh window the current token is still returned while
+	// a new one is fetched in the background.
+	clock.Add(56 * time.Minute)
+	if token, _ := c.Token(context.Background()); token != "token-1" {
+		t.Errorf("Token() in refresh window = %q, want token-1", token)
+	}
+	<-refreshed
+	deadline := time.Now().Add(time.Second)
+	token, _ := c.Token(context.Background())
+	for token != "token-2" && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+		token, _ = c.Token(context.Background())
+	}
+	if token != "token-2" {
+		t.Errorf("Token() after refresh = %q, want token-2", token)
+	}
+	if got := calls.Load(); got != 2 {
+		t.Errorf("fetched %d times, want 2", got)
+	}
+}
+
+func TestTokenClockSkew(t *testing.T) {
+	clock := &fakeNow{now: time.Unix(1_700_000_000, 0)}
+	var calls atomic.Int32
+	c := New(func(ctx context.Context) (*Token, error) {
+		n := calls.Add(1)
+		return &Token{AccessToken: fmt.Sprintf("token-%d", n), Expiry: clock.Now().Add(10 * time.Minute)}, nil
+	}, WithRefreshBefore(0), WithJitter(0), WithClockSkew(time.Minute))
+	c.now = clock.Now
+
+	c.Token(context.Background())
+	clock.Add(9*time.Minute + 30*time.Second)
+	if token, _ := c.Token(context.Background()); token != "token-2" {
+		t.Errorf("Token() within clock skew of expiry = %q, want a new token", token)
+	}
+}
+
+func TestTokenErrors(t *testing.T) {
+	fail := errors.New("token endpoint unavailable")
+	c := New(func(ctx context.Context) (*Token, error) {
+		return nil, fail
+	})
+	if _, err := c.Token(context.Background()); !errors.Is(err, fail) {
+		t.Errorf("Token() error = %v, want %v", err, fail)
+	}
+
+	c = New(func(ctx context.Context) (*Token, error) {
+		return &Token{}, nil
+	})
+	if _, err := c.Token(context.Background()); err == nil {
+		t.Error("Token() error = nil, want error for empty access token")
+	}
+}
+
+func TestInvalidate(t *testing.T) {
+	var calls atomic.Int32
+	c := New(func(ctx context.Context) (*Token, error) {
+		return &Token{AccessToken: fmt.Sprintf("token-%d", calls.Add(1))}, nil
+	})
+
+	c.Token(context.Background())
+	c.Invalidate()
+	if token, _ := c.Token(context.Background()); token != "token-2" {
+		t.Errorf("Token() after Invalidate = %q, want token-2", token)
+	}
+}
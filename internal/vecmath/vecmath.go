@@ -0,0 +1,24 @@
+// Package vecmath provides the vector arithmetic shared by the packages
+// that compare embeddings, such as the rerank fallback, response
+// comparison and synthetic dataset deduplication.
+package vecmath
+
+import "math"
+
+// Cosine returns the cosine similarity of a and b, or 0 if their lengths
+// differ or either is zero.
+func Cosine(a, b []float64) float64 {
+	if len(a) != len(b) {
+		return 0
+	}
+	var dot, na, nb float64
+	for i := range a {
+		dot += a[i] * b[i]
+		na += a[i] * a[i]
+		nb += b[i] * b[i]
+	}
+	if na == 0 || nb == 0 {
+		return 0
+	}
+	return dot / (math.Sqrt(na) * math.Sqrt(nb))
+}
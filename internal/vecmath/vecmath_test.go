@@ -0,0 +1,26 @@
+package vecmath
+
+import "testing"
+
+func TestCosine(t *testing.T) {
+	tests := []struct {
+		name string
+		a, b []float64
+		want float64
+	}{
+		{name: "identical", a: []float64{1, 0}, b: []float64{1, 0}, want: 1},
+		{name: "orthogonal", a: []float64{1, 0}, b: []float64{0, 1}, want: 0},
+		{name: "opposite", a: []float64{1, 2}, b: []float64{-1, -2}, want: -1},
+		{name: "mismatched", a: []float64{1}, b: []float64{1, 0}, want: 0},
+		{name: "zero", a: []float64{0, 0}, b: []float64{1, 0}, want: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Cosine(tt.a, tt.b)
+			if diff := got - tt.want; diff > 1e-9 || diff < -1e-9 {
+				t.Errorf("Cosine() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
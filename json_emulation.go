@@ -0,0 +1,145 @@
+package warp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/blue-context/warp/internal/jsonschema"
+	"github.com/blue-context/warp/types"
+)
+
+// jsonModePrompt is added to the system prompt of emulated JSON requests.
+const jsonModePrompt = "Respond with only a single valid JSON object. Do not include any text, explanation, or code fences outside the JSON."
+
+// needsJSONEmulation reports whether req's response format must be
+// emulated for p: JSON mode emulation is enabled, req asks for JSON, and
+// the model lacks native JSON mode. A ProbeCapabilities result for the
+// model takes precedence over the provider's declared capabilities.
+func (c *client) needsJSONEmulation(p Provider, req *CompletionRequest) bool {
+	if !c.config.JSONModeEmulation || req.ResponseFormat == nil {
+		return false
+	}
+	if req.ResponseFormat.Type != "json_object" && req.ResponseFormat.Type != "json_schema" {
+		return false
+	}
+	if result, ok := c.ProbedCapabilities(p.Name() + "/" + req.Model); ok {
+		return !result.JSONMode
+	}
+	caps, ok := p.Supports().(types.Capabilities)
+	return ok && !caps.JSON
+}
+
+// emulateJSONMode returns a copy of req with the response format replaced
+// by an instruction in the system prompt, including the schema for
+// json_schema formats.
+func emulateJSONMode(req *CompletionRequest) (*CompletionRequest, error) {
+	prompt := jsonModePrompt
+	if schema := req.ResponseFormat.JSONSchema; schema != nil && schema.Schema != nil {
+		data, err := json.Marshal(schema.Schema)
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode response schema: %w", err)
+		}
+		prompt += "\n\nThe JSON object must match this JSON Schema:\n" + string(data)
+		if schema.Description != "" {
+			prompt += "\n\nIt describes: " + schema.Description
+		}
+	}
+
+	r := *req
+	r.ResponseFormat = nil
+	r.Messages = make([]Message, 0, len(req.Messages)+1)
+	if len(req.Messages) > 0 && req.Messages[0].Role == "system" {
+		system := req.Messages[0]
+		system.Content = messageText(system.Content) + "\n\n" + prompt
+		r.Messages = append(r.Messages, system)
+		r.Messages = append(r.Messages, req.Messages[1:]...)
+	} else {
+		r.Messages = append(r.Messages, Message{Role: "system", Content: prompt})
+		r.Messages = append(r.Messages, req.Messages...)
+	}
+	return &r, nil
+}
+
+// restoreJSON replaces each choice's content with the JSON object extracted
+// from it. A choice without a valid object is re-requested once with the
+// validation error; if that also fails, an error is returned.
+//
+// Choices that made tool calls are left unchanged.
+func (c *client) restoreJSON(ctx context.Context, p Provider, req *CompletionRequest, resp *CompletionResponse, format *ResponseFormat) error {
+	for i := range resp.Choices {
+		choice := &resp.Choices[i]
+		if len(choice.Message.ToolCalls) > 0 {
+			continue
+		}
+
+		text := messageText(choice.Message.Content)
+		object, err := extractJSON(text, format)
+		if err != nil {
+			object, err = c.repairJSON(ctx, p, req, text, err, format)
+		}
+		if err != nil {
+			return &WarpError{
+				Message:       fmt.Sprintf("model did not return valid JSON: %v", err),
+				Provider:      p.Name(),
+				Model:         req.Model,
+				OriginalError: err,
+			}
+		}
+		choice.Message.Content = object
+	}
+	return nil
+}
+
+// repairJSON asks the model to correct an invalid reply.
+func (c *client) repairJSON(ctx context.Context, p Provider, req *CompletionRequest, reply string, invalid error, format *ResponseFormat) (string, error) {
+	r := *req
+	r.N = nil
+	r.Messages = make([]Message, 0, len(req.Messages)+2)
+	r.Messages = append(r.Messages, req.Messages...)
+	r.Messages = append(r.Messages,
+		Message{Role: "assistant", Content: reply},
+		Message{Role: "user", Content: fmt.Sprintf("Your reply was not valid: %v. Reply again with only the corrected JSON object.", invalid)},
+	)
+
+	var resp *CompletionResponse
+	err := c.withRetry(ctx, func() error {
+		var callErr error
+		resp, callErr = c.complete(ctx, p, &r)
+		return callErr
+	})
+	if err != nil {
+		return "", err
+	}
+	if len(resp.Choices) == 0 {
+		return "", fmt.Errorf("response has no choices")
+	}
+	return extractJSON(messageText(resp.Choices[0].Message.Content), format)
+}
+
+// extractJSON returns the first JSON object in text, tolerating surrounding
+// prose and code fences. For json_schema formats, the object must match the
+// schema, as it would with native structured output.
+func extractJSON(text string, format *ResponseFormat) (string, error) {
+	for start := strings.IndexByte(text, '{'); start >= 0; {
+		dec := json.NewDecoder(strings.NewReader(text[start:]))
+		var object map[string]any
+		if dec.Decode(&object) == nil {
+			document := strings.TrimSpace(text[start : start+int(dec.InputOffset())])
+			if format != nil && format.JSONSchema != nil {
+				if problems := jsonschema.Validate(format.JSONSchema.Schema, document); len(problems) > 0 {
+					return "", fmt.Errorf("does not match the schema: %s", strings.Join(problems, "; "))
+				}
+			}
+			return document, nil
+		}
+
+		next := strings.IndexByte(text[start+1:], '{')
+		if next < 0 {
+			break
+		}
+		start += next + 1
+	}
+	return "", fmt.Errorf("no JSON object found")
+}
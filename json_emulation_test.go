@@ -0,0 +1,134 @@
+package warp
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestJSONModeEmulation(t *testing.T) {
+	var requests []*CompletionRequest
+	replies := []string{"Here you go:\n```json\n{\"city\": \"Paris\", \"temp\": 21}\n```"}
+	client, err := NewClient(WithJSONModeEmulation(true), WithMaxRetries(0))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+	client.RegisterProvider(&toollessProvider{mockProvider{name: "local", completionFunc: func(ctx context.Context, req *CompletionRequest) (*CompletionResponse, error) {
+		requests = append(requests, req)
+		reply := replies[0]
+		replies = replies[1:]
+		return &CompletionResponse{Choices: []Choice{{Message: Message{Role: "assistant", Content: reply}}}}, nil
+	}}})
+
+	schema := &JSONSchema{Name: "weather", Schema: map[string]any{
+		"type":     "object",
+		"required": []any{"city", "temp"},
+	}}
+	req := &CompletionRequest{
+		Model:          "local/llama",
+		Messages:       []Message{{Role: "user", Content: "Weather in Paris?"}},
+		ResponseFormat: &ResponseFormat{Type: "json_schema", JSONSchema: schema},
+	}
+	resp, err := client.Completion(context.Background(), req)
+	if err != nil {
+		t.Fatalf("Completion() error = %v", err)
+	}
+	if got := resp.Choices[0].Message.Content; got != `{"city": "Paris", "temp": 21}` {
+		t.Errorf("content = %q, want extracted object", got)
+	}
+	sent := requests[0]
+	if sent.ResponseFormat != nil || sent.Messages[0].Role != "system" || !strings.Contains(sent.Messages[0].Content.(string), `"required"`) {
+		t.Errorf("request = %+v, want response format moved to system prompt", sent)
+	}
+
+	// An invalid reply is re-requested once
+	requests = nil
+	replies = []string{`{"city": "Paris"}`, `{"city": "Paris", "temp": 21}`}
+	resp, err = client.Completion(context.Background(), req)
+	if err != nil {
+		t.Fatalf("Completion() error = %v", err)
+	}
+	if len(requests) != 2 || resp.Choices[0].Message.Content != `{"city": "Paris", "temp": 21}` {
+		t.Errorf("requests = %d, content = %v, want repaired object", len(requests), resp.Choices[0].Message.Content)
+	}
+	if last := requests[1].Messages[len(requests[1].Messages)-1]; !strings.Contains(last.Content.(string), "temp: is required") {
+		t.Errorf("repair message = %q, want missing property named", last.Content)
+	}
+
+	// Still invalid after repair
+	replies = []string{"no", "still no"}
+	_, err = client.Completion(context.Background(), req)
+	var warpErr *WarpError
+	if !errors.As(err, &warpErr) || !strings.Contains(err.Error(), "valid JSON") {
+		t.Errorf("error = %v, want invalid JSON error", err)
+	}
+}
+
+func TestExtractJSON(t *testing.T) {
+	tests := []struct {
+		name    string
+		text    string
+		want    string
+		wantErr bool
+	}{
+		{"bare", `{"a":1}`, `{"a":1}`, false},
+		{"fenced", "```json\n{\"a\": {\"b\": 2}}\n```", `{"a": {"b": 2}}`, false},
+		{"prose", `The answer is {"a":1}. Hope it helps {really}.`, `{"a":1}`, false},
+		{"braces before", `Using {placeholder}: {"a":1}`, `{"a":1}`, false},
+		{"none", `no json here`, "", true},
+		{"array", `[1, 2]`, "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := extractJSON(tt.text, nil)
+			if (err != nil) != tt.wantErr || got != tt.want {
+				t.Errorf("extractJSON() = %q, %v, want %q", got, err, tt.want)
+			}
+		})
+	}
+}
+
+func TestExtractJSONSchema(t *testing.T) {
+	format := &ResponseFormat{Type: "json_schema", JSONSchema: &JSONSchema{Name: "weather", Schema: map[string]any{
+		"type":     "object",
+		"required": []any{"city", "temp", "wind"},
+		"properties": map[string]any{
+			"city": map[string]any{"type": "string"},
+			"temp": map[string]any{"type": "number"},
+			"wind": map[string]any{
+				"type":     "object",
+				"required": []any{"unit"},
+				"properties": map[string]any{
+					"unit": map[string]any{"type": "string", "enum": []any{"kmh", "mph"}},
+				},
+			},
+		},
+	}}}
+
+	tests := []struct {
+		name    string
+		text    string
+		wantErr string
+	}{
+		{"valid", `{"city": "Paris", "temp": 21, "wind": {"unit": "kmh"}}`, ""},
+		{"wrong type", `{"city": "Paris", "temp": "warm", "wind": {"unit": "kmh"}}`, "$.temp: expected number"},
+		{"nested required", `{"city": "Paris", "temp": 21, "wind": {}}`, "$.wind.unit: is required"},
+		{"enum", `{"city": "Paris", "temp": 21, "wind": {"unit": "knots"}}`, "$.wind.unit: must be one of"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := extractJSON(tt.text, format)
+			if tt.wantErr == "" {
+				if err != nil || got != tt.text {
+					t.Errorf("extractJSON() = %q, %v, want %q", got, err, tt.text)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("extractJSON() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,75 @@
+// Package jsoncodec selects the JSON implementation used on warp's hot
+// paths: decoding streamed events (sse.Reader.Decode) and encoding request
+// bodies and decoding responses in providercore.
+//
+// The default is encoding/json. Performance-sensitive users can install a
+// faster implementation, such as github.com/bytedance/sonic or
+// github.com/goccy/go-json, once at program start:
+//
+//	type sonicCodec struct{}
+//
+//	func (sonicCodec) Marshal(v any) ([]byte, error)      { return sonic.Marshal(v) }
+//	func (sonicCodec) Unmarshal(data []byte, v any) error { return sonic.Unmarshal(data, v) }
+//
+//	func main() {
+//	    jsoncodec.Set(sonicCodec{})
+//	    ...
+//	}
+//
+// A codec must be a drop-in replacement for encoding/json: it must honor
+// struct tags and the json.Marshaler and json.Unmarshaler interfaces, which
+// many warp types implement. Run the streaming benchmark in package sse
+// with the codec installed to measure the gain before switching.
+package jsoncodec
+
+import (
+	"encoding/json"
+	"sync/atomic"
+)
+
+// Codec encodes and decodes JSON.
+//
+// Thread Safety: a Codec must be safe for concurrent use.
+type Codec interface {
+	// Marshal returns the JSON encoding of v, like json.Marshal.
+	Marshal(v any) ([]byte, error)
+
+	// Unmarshal decodes data into v, like json.Unmarshal.
+	Unmarshal(data []byte, v any) error
+}
+
+// Std is the encoding/json codec, the default. Code with a streaming
+// encoding/json path keeps it when Get returns Std, since Marshal and
+// Unmarshal need the whole value in memory.
+var Std Codec = stdCodec{}
+
+type stdCodec struct{}
+
+func (stdCodec) Marshal(v any) ([]byte, error)      { return json.Marshal(v) }
+func (stdCodec) Unmarshal(data []byte, v any) error { return json.Unmarshal(data, v) }
+
+// holder wraps the codec so every value stored in current has the same
+// concrete type, as atomic.Value requires.
+type holder struct {
+	codec Codec
+}
+
+var current atomic.Value
+
+// Set installs c as the codec of all clients; nil restores Std. Call it
+// before making requests: streams already open keep decoding with the
+// codec they started with.
+func Set(c Codec) {
+	if c == nil {
+		c = Std
+	}
+	current.Store(holder{c})
+}
+
+// Get returns the installed codec.
+func Get() Codec {
+	if h, ok := current.Load().(holder); ok {
+		return h.codec
+	}
+	return Std
+}
@@ -0,0 +1,41 @@
+package jsoncodec
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+type upperCodec struct{}
+
+func (upperCodec) Marshal(v any) ([]byte, error)      { return []byte(`"UPPER"`), nil }
+func (upperCodec) Unmarshal(data []byte, v any) error { return json.Unmarshal(data, v) }
+
+func TestSet(t *testing.T) {
+	t.Cleanup(func() { Set(nil) })
+
+	if Get() != Std {
+		t.Fatal("Get() is not Std by default")
+	}
+
+	Set(upperCodec{})
+	data, err := Get().Marshal("lower")
+	if err != nil || string(data) != `"UPPER"` {
+		t.Errorf("Marshal() = %s, %v", data, err)
+	}
+
+	Set(nil)
+	if Get() != Std {
+		t.Error("Set(nil) did not restore Std")
+	}
+}
+
+func TestStd(t *testing.T) {
+	data, err := Std.Marshal(map[string]int{"b": 2, "a": 1})
+	if err != nil || string(data) != `{"a":1,"b":2}` {
+		t.Errorf("Marshal() = %s, %v", data, err)
+	}
+	var out map[string]int
+	if err := Std.Unmarshal(data, &out); err != nil || out["a"] != 1 {
+		t.Errorf("Unmarshal() = %v, %v", out, err)
+	}
+}
@@ -0,0 +1,615 @@
+// Package jsonstream parses JSON incrementally as it streams from a model,
+// so structured output can be rendered before the response is complete.
+//
+// A Parser is fed text as it arrives and reports path-level events: the
+// start and end of objects and arrays, text appended to string values, and
+// completed values. Value returns the partial document parsed so far, with
+// unfinished strings included as far as they have arrived.
+//
+// Text before the first '{' or '[' (such as a code fence or a sentence of
+// preamble) and anything after the document ends is ignored.
+//
+// Basic usage:
+//
+//	stream, err := client.CompletionStream(ctx, req)
+//	if err != nil {
+//	    return err
+//	}
+//	defer stream.Close()
+//
+//	result, err := jsonstream.Parse(stream, func(e jsonstream.Event) error {
+//	    if e.Type == jsonstream.EventDelta && e.Path == "summary" {
+//	        fmt.Print(e.Value)
+//	    }
+//	    return nil
+//	})
+package jsonstream
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"unicode/utf16"
+	"unicode/utf8"
+
+	"github.com/blue-context/warp"
+)
+
+// EventType identifies what happened at a path.
+type EventType int
+
+const (
+	// EventStart is an object or array starting. Value is an empty
+	// map[string]any or []any.
+	EventStart EventType = iota
+
+	// EventEnd is an object or array ending. Value is the complete
+	// map[string]any or []any.
+	EventEnd
+
+	// EventDelta is text appended to a string value. Value is the new
+	// text, and a string produces at least one delta even if it is empty.
+	EventDelta
+
+	// EventValue is a complete string, number (float64), boolean, or null
+	// value.
+	EventValue
+)
+
+// String returns the name of the event type.
+func (t EventType) String() string {
+	switch t {
+	case EventStart:
+		return "start"
+	case EventEnd:
+		return "end"
+	case EventDelta:
+		return "delta"
+	case EventValue:
+		return "value"
+	}
+	return fmt.Sprintf("EventType(%d)", int(t))
+}
+
+// Event is a parse event.
+type Event struct {
+	// Type is what happened.
+	Type EventType
+
+	// Path locates the value in the document, with object keys joined by
+	// dots and array indexes in brackets, such as "items[2].name". The
+	// top-level value has an empty path.
+	Path string
+
+	// Value depends on Type.
+	Value any
+}
+
+// parser states
+type state int
+
+const (
+	statePreamble state = iota
+	stateValue
+	stateKeyOrEnd
+	stateKey
+	stateColon
+	stateAfterValue
+	stateString
+	stateNumber
+	stateLiteral
+	stateDone
+)
+
+// node is a value in the partial document.
+type node struct {
+	object map[string]*node
+	keys   []string
+	array  []*node
+	isObj  bool
+	isArr  bool
+	str    strings.Builder
+	isStr  bool
+	scalar any
+	set    bool
+}
+
+// value converts n to map[string]any, []any, or a scalar.
+func (n *node) value() any {
+	switch {
+	case n.isObj:
+		m := make(map[string]any, len(n.keys))
+		for _, k := range n.keys {
+			if child := n.object[k]; child.set {
+				m[k] = child.value()
+			}
+		}
+		return m
+	case n.isArr:
+		a := make([]any, 0, len(n.array))
+		for _, child := range n.array {
+			if child.set {
+				a = append(a, child.value())
+			}
+		}
+		return a
+	case n.isStr:
+		return n.str.String()
+	}
+	return n.scalar
+}
+
+// frame is an open object or array.
+type frame struct {
+	node  *node
+	key   string
+	index int
+}
+
+// Parser parses one JSON document incrementally.
+//
+// Thread Safety: Parser is NOT safe for concurrent use.
+type Parser struct {
+	state  state
+	stack  []*frame
+	root   *node
+	cur    *node // string, number, or literal being read
+	tok    strings.Builder
+	isKey  bool
+	escape []byte // pending escape sequence, starting with '\\'
+	surr   rune   // pending high surrogate from a \u escape
+	events []Event
+	delta  strings.Builder
+	pos    int
+	rest   string // incomplete UTF-8 sequence from the last Write
+}
+
+// NewParser creates a parser.
+func NewParser() *Parser {
+	return &Parser{}
+}
+
+// Write parses the next piece of the document and returns the events it
+// completed. Text appended to a string is reported as one delta per call.
+//
+// Returns an error if the document is malformed; the parser cannot be used
+// after an error.
+func (p *Parser) Write(text string) ([]Event, error) {
+	p.events = p.events[:0]
+	text, p.rest = p.rest+text, ""
+	for i := 0; i < len(text); {
+		if !utf8.FullRuneInString(text[i:]) {
+			// Wait for the rest of a rune split across writes
+			p.rest = text[i:]
+			break
+		}
+		r, size := utf8.DecodeRuneInString(text[i:])
+		consumed, err := p.step(r)
+		if err != nil {
+			return nil, fmt.Errorf("jsonstream: offset %d: %w", p.pos, err)
+		}
+		if consumed {
+			i += size
+			p.pos += size
+		}
+	}
+	p.flushDelta()
+	return append([]Event(nil), p.events...), nil
+}
+
+// Value returns the document parsed so far, or nil before it starts.
+// Objects are map[string]any and arrays []any; numbers and literals appear
+// once complete, strings as far as they have arrived.
+func (p *Parser) Value() any {
+	if p.root == nil || !p.root.set {
+		return nil
+	}
+	return p.root.value()
+}
+
+// Done reports whether the document is complete.
+func (p *Parser) Done() bool {
+	return p.state == stateDone
+}
+
+// Close finishes the document, completing a top-level number at the end of
+// the input. Returns io.ErrUnexpectedEOF if the document is incomplete.
+func (p *Parser) Close() ([]Event, error) {
+	p.events = p.events[:0]
+	if p.state == stateNumber && len(p.stack) == 0 {
+		if err := p.endNumber(); err != nil {
+			return nil, err
+		}
+	}
+	if p.state != stateDone {
+		return nil, io.ErrUnexpectedEOF
+	}
+	return append([]Event(nil), p.events...), nil
+}
+
+// step handles one rune and reports whether it was consumed; a rune that
+// ends a number is handled again in the next state.
+func (p *Parser) step(r rune) (bool, error) {
+	switch p.state {
+	case statePreamble:
+		if r == '{' || r == '[' {
+			p.state = stateValue
+			return false, nil
+		}
+		return true, nil
+
+	case stateDone:
+		return true, nil
+
+	case stateValue:
+		if isSpace(r) {
+			return true, nil
+		}
+		if r == ']' && len(p.stack) > 0 {
+			if top := p.stack[len(p.stack)-1]; top.node.isArr && len(top.node.array) == 0 {
+				p.endContainer()
+				return true, nil
+			}
+		}
+		return true, p.startValue(r)
+
+	case stateKeyOrEnd, stateKey:
+		switch {
+		case isSpace(r):
+		case r == '"':
+			p.isKey = true
+			p.state = stateString
+		case r == '}' && p.state == stateKeyOrEnd:
+			p.endContainer()
+		default:
+			return false, fmt.Errorf("expected object key, found %q", r)
+		}
+		return true, nil
+
+	case stateColon:
+		switch {
+		case isSpace(r):
+		case r == ':':
+			p.state = stateValue
+		default:
+			return false, fmt.Errorf("expected ':', found %q", r)
+		}
+		return true, nil
+
+	case stateAfterValue:
+		top := p.stack[len(p.stack)-1]
+		switch {
+		case isSpace(r):
+		case r == ',' && top.node.isObj:
+			p.state = stateKey
+		case r == ',':
+			top.index++
+			p.state = stateValue
+		case r == '}' && top.node.isObj, r == ']' && top.node.isArr:
+			p.endContainer()
+		default:
+			return false, fmt.Errorf("expected ',' or end of container, found %q", r)
+		}
+		return true, nil
+
+	case stateString:
+		return true, p.stringRune(r)
+
+	case stateNumber:
+		if strings.ContainsRune("0123456789+-.eE", r) {
+			p.tok.WriteRune(r)
+			return true, nil
+		}
+		return false, p.endNumber()
+
+	case stateLiteral:
+		p.tok.WriteRune(r)
+		tok := p.tok.String()
+		if v, ok := literals[tok]; ok {
+			p.cur.scalar, p.cur.set = v, true
+			p.emit(EventValue, v)
+			p.endValue()
+			return true, nil
+		}
+		for lit := range literals {
+			if strings.HasPrefix(lit, tok) {
+				return true, nil
+			}
+		}
+		return false, fmt.Errorf("invalid literal %q", tok)
+	}
+	return false, fmt.Errorf("invalid parser state %d", p.state)
+}
+
+// literals are the JSON literal values.
+var literals = map[string]any{"true": true, "false": false, "null": nil}
+
+// escapes are the single-character JSON escapes.
+var escapes = map[byte]rune{'"': '"', '\\': '\\', '/': '/', 'b': '\b', 'f': '\f', 'n': '\n', 'r': '\r', 't': '\t'}
+
+// startValue begins the value that starts with r.
+func (p *Parser) startValue(r rune) error {
+	n := &node{}
+	switch {
+	case r == '{':
+		n.isObj, n.object = true, make(map[string]*node)
+	case r == '[':
+		n.isArr = true
+	case r == '"':
+		n.isStr = true
+	case r == '-' || (r >= '0' && r <= '9'), r == 't' || r == 'f' || r == 'n':
+		p.tok.Reset()
+		p.tok.WriteRune(r)
+	default:
+		return fmt.Errorf("unexpected %q", r)
+	}
+	p.attach(n)
+
+	switch {
+	case n.isObj || n.isArr:
+		n.set = true
+		if n.isObj {
+			p.emit(EventStart, map[string]any{})
+			p.state = stateKeyOrEnd
+		} else {
+			p.emit(EventStart, []any{})
+			p.state = stateValue
+		}
+		p.stack = append(p.stack, &frame{node: n})
+	case n.isStr:
+		n.set = true
+		p.cur, p.isKey = n, false
+		p.state = stateString
+	case r == 't' || r == 'f' || r == 'n':
+		p.cur = n
+		p.state = stateLiteral
+	default:
+		p.cur = n
+		p.state = stateNumber
+	}
+	return nil
+}
+
+// attach adds n to the open container, or makes it the root.
+func (p *Parser) attach(n *node) {
+	if len(p.stack) == 0 {
+		p.root = n
+		return
+	}
+	top := p.stack[len(p.stack)-1]
+	if top.node.isObj {
+		if _, ok := top.node.object[top.key]; !ok {
+			top.node.keys = append(top.node.keys, top.key)
+		}
+		top.node.object[top.key] = n
+		return
+	}
+	top.node.array = append(top.node.array, n)
+}
+
+// stringRune handles a rune inside a string.
+func (p *Parser) stringRune(r rune) error {
+	if p.escape != nil {
+		p.escape = append(p.escape, string(r)...)
+		if p.escape[1] != 'u' {
+			decoded, ok := escapes[p.escape[1]]
+			if !ok {
+				return fmt.Errorf("invalid escape %q", p.escape)
+			}
+			p.escape = nil
+			p.stringText(decoded)
+			return nil
+		}
+		if len(p.escape) < 6 {
+			return nil
+		}
+		code, err := strconv.ParseUint(string(p.escape[2:6]), 16, 32)
+		if err != nil {
+			return fmt.Errorf("invalid escape %q", p.escape)
+		}
+		p.escape = nil
+		c := rune(code)
+		switch {
+		case utf16.IsSurrogate(c) && p.surr == 0:
+			p.surr = c
+			return nil
+		case p.surr != 0:
+			c = utf16.DecodeRune(p.surr, c)
+			p.surr = 0
+		}
+		p.stringText(c)
+		return nil
+	}
+
+	switch r {
+	case '\\':
+		p.escape = []byte{'\\'}
+	case '"':
+		p.endString()
+	default:
+		p.stringText(r)
+	}
+	return nil
+}
+
+// stringText appends a decoded rune to the current key or string value.
+func (p *Parser) stringText(r rune) {
+	if p.surr != 0 {
+		// A high surrogate not followed by a low one
+		p.surr = 0
+		p.stringText(utf8.RuneError)
+	}
+	if p.isKey {
+		p.tok.WriteRune(r)
+		return
+	}
+	p.cur.str.WriteRune(r)
+	p.delta.WriteRune(r)
+}
+
+// endString finishes the current key or string value.
+func (p *Parser) endString() {
+	if p.isKey {
+		p.stack[len(p.stack)-1].key = p.tok.String()
+		p.tok.Reset()
+		p.isKey = false
+		p.state = stateColon
+		return
+	}
+	p.flushDeltaAlways()
+	p.emit(EventValue, p.cur.str.String())
+	p.endValue()
+}
+
+// endNumber finishes the current number.
+func (p *Parser) endNumber() error {
+	v, err := strconv.ParseFloat(p.tok.String(), 64)
+	if err != nil {
+		return fmt.Errorf("invalid number %q", p.tok.String())
+	}
+	p.cur.scalar, p.cur.set = v, true
+	p.emit(EventValue, v)
+	p.endValue()
+	return nil
+}
+
+// endValue moves past a completed scalar value.
+func (p *Parser) endValue() {
+	p.cur = nil
+	p.tok.Reset()
+	if len(p.stack) == 0 {
+		p.state = stateDone
+		return
+	}
+	p.state = stateAfterValue
+}
+
+// endContainer closes the innermost object or array.
+func (p *Parser) endContainer() {
+	top := p.stack[len(p.stack)-1]
+	p.stack = p.stack[:len(p.stack)-1]
+	p.emitAt(p.path(), EventEnd, top.node.value())
+	if len(p.stack) == 0 {
+		p.state = stateDone
+		return
+	}
+	p.state = stateAfterValue
+}
+
+// flushDelta reports text appended to the current string in this Write.
+func (p *Parser) flushDelta() {
+	if p.delta.Len() > 0 {
+		p.flushDeltaAlways()
+	}
+}
+
+// flushDeltaAlways reports text appended to the current string, even if
+// there is none, so every string produces a delta.
+func (p *Parser) flushDeltaAlways() {
+	if p.delta.Len() == 0 && p.cur.str.Len() > 0 {
+		return
+	}
+	p.emit(EventDelta, p.delta.String())
+	p.delta.Reset()
+}
+
+// emit records an event for the value being parsed, whose container is
+// at the top of the stack.
+func (p *Parser) emit(t EventType, v any) {
+	p.emitAt(p.path(), t, v)
+}
+
+// emitAt records an event.
+func (p *Parser) emitAt(path string, t EventType, v any) {
+	p.events = append(p.events, Event{Type: t, Path: path, Value: v})
+}
+
+// path returns the path of the value at the top of the stack.
+func (p *Parser) path() string {
+	var sb strings.Builder
+	for _, f := range p.stack {
+		if f.node.isObj {
+			if sb.Len() > 0 {
+				sb.WriteByte('.')
+			}
+			sb.WriteString(f.key)
+		} else {
+			fmt.Fprintf(&sb, "[%d]", f.index)
+		}
+	}
+	return sb.String()
+}
+
+// isSpace reports whether r is JSON whitespace.
+func isSpace(r rune) bool {
+	return r == ' ' || r == '\t' || r == '\n' || r == '\r'
+}
+
+// Parse reads a completion stream, parses the JSON document in the content
+// of the choice with index 0, and calls handle with each event as it is
+// parsed. It returns the complete document. Deltas of other choices, sent
+// when the request asked for N > 1, are ignored.
+//
+// Returns the first error from the stream, the parser, or handle, and
+// io.ErrUnexpectedEOF if the stream ends before the document does.
+func Parse(stream warp.Stream, handle func(Event) error) (any, error) {
+	p := NewParser()
+	for {
+		chunk, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		content, ok := firstChoiceContent(chunk)
+		if !ok {
+			continue
+		}
+		events, err := p.Write(content)
+		if err != nil {
+			return nil, err
+		}
+		if err := dispatch(events, handle); err != nil {
+			return nil, err
+		}
+		if p.Done() {
+			break
+		}
+	}
+
+	events, err := p.Close()
+	if err != nil {
+		return nil, err
+	}
+	if err := dispatch(events, handle); err != nil {
+		return nil, err
+	}
+	return p.Value(), nil
+}
+
+// firstChoiceContent returns the content delta of the choice with index 0
+// in chunk, and false if the chunk has none.
+func firstChoiceContent(chunk *warp.CompletionChunk) (string, bool) {
+	for _, choice := range chunk.Choices {
+		if choice.Index == 0 {
+			return choice.Delta.Content, true
+		}
+	}
+	return "", false
+}
+
+// dispatch calls handle with each event.
+func dispatch(events []Event, handle func(Event) error) error {
+	if handle == nil {
+		return nil
+	}
+	for _, e := range events {
+		if err := handle(e); err != nil {
+			return err
+		}
+	}
+	return nil
+}
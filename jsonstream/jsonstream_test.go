@@ -0,0 +1,156 @@
+package jsonstream
+
+import (
+	"errors"
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/blue-context/warp"
+)
+
+func TestParserEvents(t *testing.T) {
+	doc := "```json\n" + `{"title": "Hi \"there\" é😀", "n": -1.5e2, "tags": ["a", true, null], "empty": [], "obj": {}}` + "\n```"
+
+	p := NewParser()
+	var events []Event
+	for i := 0; i < len(doc); i += 3 {
+		end := min(i+3, len(doc))
+		got, err := p.Write(doc[i:end])
+		if err != nil {
+			t.Fatalf("Write(%q) error = %v", doc[i:end], err)
+		}
+		events = append(events, got...)
+	}
+	if !p.Done() {
+		t.Fatal("Done() = false after complete document")
+	}
+
+	want := map[string]any{
+		"title": "Hi \"there\" é😀",
+		"n":     -150.0,
+		"tags":  []any{"a", true, nil},
+		"empty": []any{},
+		"obj":   map[string]any{},
+	}
+	if got := p.Value(); !reflect.DeepEqual(got, want) {
+		t.Errorf("Value() = %#v, want %#v", got, want)
+	}
+
+	var title strings.Builder
+	var values, ends []string
+	for _, e := range events {
+		switch e.Type {
+		case EventDelta:
+			if e.Path == "title" {
+				title.WriteString(e.Value.(string))
+			}
+		case EventValue:
+			values = append(values, e.Path)
+		case EventEnd:
+			ends = append(ends, e.Path)
+		}
+	}
+	if title.String() != want["title"] {
+		t.Errorf("title deltas = %q, want %q", title.String(), want["title"])
+	}
+	if w := []string{"title", "n", "tags[0]", "tags[1]", "tags[2]"}; !reflect.DeepEqual(values, w) {
+		t.Errorf("value paths = %q, want %q", values, w)
+	}
+	if w := []string{"tags", "empty", "obj", ""}; !reflect.DeepEqual(ends, w) {
+		t.Errorf("end paths = %q, want %q", ends, w)
+	}
+}
+
+func TestParserPartialValue(t *testing.T) {
+	p := NewParser()
+	if _, err := p.Write(`{"items": [{"name": "bo`); err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]any{"items": []any{map[string]any{"name": "bo"}}}
+	if got := p.Value(); !reflect.DeepEqual(got, want) {
+		t.Errorf("Value() = %#v, want %#v", got, want)
+	}
+	if p.Done() {
+		t.Error("Done() = true for partial document")
+	}
+	if _, err := p.Close(); !errors.Is(err, io.ErrUnexpectedEOF) {
+		t.Errorf("Close() error = %v, want io.ErrUnexpectedEOF", err)
+	}
+}
+
+func TestParserErrors(t *testing.T) {
+	for _, doc := range []string{`{"a" 1}`, `{"a": tru}`, `[1 2]`, `{"a": "\q"}`, `{1: 2}`} {
+		if _, err := NewParser().Write(doc); err == nil {
+			t.Errorf("Write(%q) error = nil, want error", doc)
+		}
+	}
+}
+
+type chunkStream struct {
+	chunks []string
+}
+
+func (s *chunkStream) Recv() (*warp.CompletionChunk, error) {
+	if len(s.chunks) == 0 {
+		return nil, io.EOF
+	}
+	chunk := &warp.CompletionChunk{Choices: []warp.ChunkChoice{{Delta: warp.MessageDelta{Content: s.chunks[0]}}}}
+	s.chunks = s.chunks[1:]
+	return chunk, nil
+}
+
+func (s *chunkStream) Close() error { return nil }
+
+func TestParse(t *testing.T) {
+	stream := &chunkStream{chunks: []string{`{"summary": "Go`, `od news", "score"`, `: 9}`}}
+	var deltas []string
+	got, err := Parse(stream, func(e Event) error {
+		if e.Type == EventDelta {
+			deltas = append(deltas, e.Value.(string))
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	if want := map[string]any{"summary": "Good news", "score": 9.0}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Parse() = %#v, want %#v", got, want)
+	}
+	if !reflect.DeepEqual(deltas, []string{"Go", "od news"}) {
+		t.Errorf("deltas = %q", deltas)
+	}
+
+	if _, err := Parse(&chunkStream{chunks: []string{`{"a": `}}, nil); !errors.Is(err, io.ErrUnexpectedEOF) {
+		t.Errorf("Parse() of truncated stream error = %v, want io.ErrUnexpectedEOF", err)
+	}
+}
+
+func TestParseMultipleChoices(t *testing.T) {
+	stream := &choicesStream{chunks: []*warp.CompletionChunk{
+		{Choices: []warp.ChunkChoice{{Index: 1, Delta: warp.MessageDelta{Content: `{"b"`}}}},
+		{Choices: []warp.ChunkChoice{{Index: 0, Delta: warp.MessageDelta{Content: `{"a": 1`}}, {Index: 1, Delta: warp.MessageDelta{Content: `: 2}`}}}},
+		{Choices: []warp.ChunkChoice{{Index: 0, Delta: warp.MessageDelta{Content: `}`}}}},
+	}}
+	got, err := Parse(stream, func(Event) error { return nil })
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	if want := map[string]any{"a": 1.0}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Parse() = %#v, want %#v", got, want)
+	}
+}
+
+type choicesStream struct{ chunks []*warp.CompletionChunk }
+
+func (s *choicesStream) Recv() (*warp.CompletionChunk, error) {
+	if len(s.chunks) == 0 {
+		return nil, io.EOF
+	}
+	chunk := s.chunks[0]
+	s.chunks = s.chunks[1:]
+	return chunk, nil
+}
+
+func (s *choicesStream) Close() error { return nil }
@@ -0,0 +1,203 @@
+package warp
+
+import (
+	"context"
+	"strings"
+	"unicode"
+)
+
+// languageSampleLen is how much text DetectLanguage looks at.
+const languageSampleLen = 4096
+
+// languageScripts maps Unicode scripts to the language written in them.
+// Han and Cyrillic are refined by detectScriptLanguage.
+var languageScripts = []struct {
+	table    *unicode.RangeTable
+	language string
+}{
+	{unicode.Han, "zh"},
+	{unicode.Hiragana, "ja"},
+	{unicode.Katakana, "ja"},
+	{unicode.Hangul, "ko"},
+	{unicode.Cyrillic, "ru"},
+	{unicode.Arabic, "ar"},
+	{unicode.Hebrew, "he"},
+	{unicode.Greek, "el"},
+	{unicode.Thai, "th"},
+	{unicode.Devanagari, "hi"},
+	{unicode.Latin, ""},
+}
+
+// languageWords are common words of languages written in Latin script.
+// Words shared by several languages count for each of them.
+var languageWords = map[string][]string{
+	"en": {"the", "and", "is", "are", "of", "to", "that", "it", "you", "for", "with", "this", "was", "what", "have", "be", "not", "my", "please", "how"},
+	"es": {"el", "los", "las", "y", "es", "por", "para", "con", "una", "del", "está", "como", "pero", "qué", "muy", "yo", "hola", "cómo", "gracias"},
+	"fr": {"le", "les", "des", "et", "est", "une", "du", "pour", "pas", "vous", "je", "il", "dans", "avec", "ce", "sur", "bonjour", "qui", "mais", "merci"},
+	"de": {"der", "die", "das", "und", "ist", "nicht", "ich", "sie", "ein", "eine", "zu", "mit", "den", "von", "auf", "für", "wie", "sind", "auch", "bitte"},
+	"it": {"il", "gli", "di", "che", "è", "per", "non", "sono", "della", "come", "questo", "ciao", "anche", "ma", "mi", "ho", "una", "grazie"},
+	"pt": {"os", "não", "você", "está", "uma", "com", "do", "da", "em", "para", "muito", "obrigado", "isso", "olá", "como", "são"},
+	"nl": {"het", "een", "en", "van", "ik", "niet", "dat", "op", "zijn", "met", "voor", "je", "wat", "maar", "ook", "hoe", "dit", "bedankt"},
+}
+
+// languageWordIndex maps each common word to its languages.
+var languageWordIndex = func() map[string][]string {
+	index := make(map[string][]string)
+	for language, words := range languageWords {
+		for _, word := range words {
+			index[word] = append(index[word], language)
+		}
+	}
+	return index
+}()
+
+// languageLetters are letters specific to one Latin-script language.
+var languageLetters = map[rune]string{
+	'ñ': "es", '¿': "es", '¡': "es",
+	'ã': "pt", 'õ': "pt",
+	'ß': "de",
+}
+
+// DetectLanguage returns the ISO 639-1 code of the language text is most
+// likely written in, or "" if it cannot tell. It is a lightweight
+// heuristic: languages with their own script are recognized from any
+// amount of text, while English, Spanish, French, German, Italian,
+// Portuguese, and Dutch are told apart by common words, so short or
+// mixed Latin-script text may not be recognized.
+//
+// Example:
+//
+//	warp.DetectLanguage("¿Dónde está la estación?") // "es"
+//	warp.DetectLanguage("東京はどこですか")              // "ja"
+func DetectLanguage(text string) string {
+	if len(text) > languageSampleLen {
+		text = text[:languageSampleLen]
+	}
+
+	if language, ok := detectScriptLanguage(text); ok {
+		return language
+	}
+	return detectLatinLanguage(text)
+}
+
+// detectScriptLanguage returns the language of the script most letters of
+// text are written in. It returns false if most are Latin or text has no
+// letters.
+func detectScriptLanguage(text string) (string, bool) {
+	counts := make(map[string]int)
+	var kana, ukrainian, persian, letters int
+	for _, r := range text {
+		if !unicode.IsLetter(r) {
+			continue
+		}
+		letters++
+		for _, s := range languageScripts {
+			if unicode.Is(s.table, r) {
+				counts[s.language]++
+				break
+			}
+		}
+		switch {
+		case unicode.In(r, unicode.Hiragana, unicode.Katakana):
+			kana++
+		case strings.ContainsRune("іїєґІЇЄҐ", r):
+			ukrainian++
+		case strings.ContainsRune("پچژگ", r):
+			persian++
+		}
+	}
+	if letters == 0 {
+		return "", false
+	}
+
+	best, bestCount := "", 0
+	for language, count := range counts {
+		if count > bestCount || (count == bestCount && language < best) {
+			best, bestCount = language, count
+		}
+	}
+	// Japanese mixes kanji with kana
+	if kana > 0 && (best == "zh" || best == "ja") {
+		return "ja", true
+	}
+	if best == "" || bestCount*2 < letters {
+		return "", false
+	}
+	switch {
+	case best == "ru" && ukrainian > 0:
+		return "uk", true
+	case best == "ar" && persian > 0:
+		return "fa", true
+	}
+	return best, true
+}
+
+// detectLatinLanguage returns the Latin-script language whose common words
+// and letters appear most in text, or "" if none does or there is a tie.
+func detectLatinLanguage(text string) string {
+	scores := make(map[string]int)
+	words := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && r != '\''
+	})
+	for _, word := range words {
+		for _, language := range languageWordIndex[word] {
+			scores[language]++
+		}
+	}
+	for _, r := range strings.ToLower(text) {
+		if language, ok := languageLetters[r]; ok {
+			scores[language]++
+		}
+	}
+
+	best, bestScore, tied := "", 0, false
+	for language, score := range scores {
+		switch {
+		case score > bestScore:
+			best, bestScore, tied = language, score, false
+		case score == bestScore:
+			tied = true
+		}
+	}
+	if tied {
+		return ""
+	}
+	return best
+}
+
+// tagLanguage adds the language of the last user message of req to ctx if
+// language detection is enabled.
+func (c *client) tagLanguage(ctx context.Context, req *CompletionRequest) context.Context {
+	if !c.config.LanguageDetection {
+		return ctx
+	}
+	for i := len(req.Messages) - 1; i >= 0; i-- {
+		if req.Messages[i].Role != "user" {
+			continue
+		}
+		if language := DetectLanguage(messageText(req.Messages[i].Content)); language != "" {
+			return WithLanguage(ctx, language)
+		}
+		break
+	}
+	return ctx
+}
+
+// detectTranscriptionLanguage returns req with Language set to the
+// language of its prompt if language detection is enabled and the request
+// has no language, and the context tagged with the language.
+func (c *client) detectTranscriptionLanguage(ctx context.Context, req *TranscriptionRequest) (context.Context, *TranscriptionRequest) {
+	if !c.config.LanguageDetection {
+		return ctx, req
+	}
+	if req.Language != "" {
+		return WithLanguage(ctx, req.Language), req
+	}
+	language := DetectLanguage(req.Prompt)
+	if language == "" {
+		return ctx, req
+	}
+	r := *req
+	r.Language = language
+	return WithLanguage(ctx, language), &r
+}
@@ -0,0 +1,94 @@
+package warp
+
+import (
+	"context"
+	"testing"
+)
+
+func TestDetectLanguage(t *testing.T) {
+	tests := []struct {
+		text string
+		want string
+	}{
+		{"What is the capital of France? Please answer briefly.", "en"},
+		{"¿Dónde está la estación de tren?", "es"},
+		{"Bonjour, je cherche la gare pour aller à Paris avec vous.", "fr"},
+		{"Ich habe heute keine Zeit, aber morgen ist es gut für mich.", "de"},
+		{"Ciao, come stai? Sono contento di vederti anche oggi.", "it"},
+		{"Olá, você pode me ajudar com isso? Muito obrigado.", "pt"},
+		{"Hoe gaat het met je? Ik ben niet thuis maar ook op het werk.", "nl"},
+		{"Привет, как дела?", "ru"},
+		{"Привіт, як справи? Їжак є.", "uk"},
+		{"东京在哪里", "zh"},
+		{"東京はどこですか", "ja"},
+		{"안녕하세요", "ko"},
+		{"مرحبا كيف حالك", "ar"},
+		{"Γεια σου", "el"},
+		{"12345 !!!", ""},
+		{"", ""},
+	}
+	for _, tt := range tests {
+		if got := DetectLanguage(tt.text); got != tt.want {
+			t.Errorf("DetectLanguage(%q) = %q, want %q", tt.text, got, tt.want)
+		}
+	}
+}
+
+func TestCompletionLanguageTag(t *testing.T) {
+	var got string
+	client, err := NewClient(WithLanguageDetection(true))
+	if err != nil {
+		t.Fatal(err)
+	}
+	client.RegisterProvider(&mockProvider{
+		name: "mock",
+		completionFunc: func(ctx context.Context, req *CompletionRequest) (*CompletionResponse, error) {
+			got = LanguageFromContext(ctx)
+			return &CompletionResponse{ID: "test"}, nil
+		},
+	})
+
+	_, err = client.Completion(context.Background(), &CompletionRequest{
+		Model: "mock/model",
+		Messages: []Message{
+			{Role: "system", Content: "You are a helpful assistant."},
+			{Role: "user", Content: "Wie spät ist es? Ich bin nicht sicher."},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != "de" {
+		t.Errorf("LanguageFromContext() = %q, want %q", got, "de")
+	}
+}
+
+func TestDetectTranscriptionLanguage(t *testing.T) {
+	tests := []struct {
+		name    string
+		enabled bool
+		req     *TranscriptionRequest
+		want    string
+	}{
+		{"disabled", false, &TranscriptionRequest{Prompt: "Réunion avec le client pour le budget"}, ""},
+		{"from prompt", true, &TranscriptionRequest{Prompt: "Réunion avec le client pour le budget"}, "fr"},
+		{"explicit language", true, &TranscriptionRequest{Language: "en", Prompt: "Réunion avec le client pour le budget"}, "en"},
+		{"no prompt", true, &TranscriptionRequest{}, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := &client{config: &ClientConfig{LanguageDetection: tt.enabled}}
+			original := tt.req.Language
+			ctx, req := c.detectTranscriptionLanguage(context.Background(), tt.req)
+			if req.Language != tt.want {
+				t.Errorf("Language = %q, want %q", req.Language, tt.want)
+			}
+			if got := LanguageFromContext(ctx); got != tt.want {
+				t.Errorf("LanguageFromContext() = %q, want %q", got, tt.want)
+			}
+			if tt.req.Language != original {
+				t.Error("request was modified")
+			}
+		})
+	}
+}
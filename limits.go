@@ -0,0 +1,120 @@
+package warp
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// RequestLimits guards against oversized completion requests, such as an
+// accidentally attached file or a runaway conversation history. Requests
+// over a limit fail with *RequestLimitError before any network call.
+//
+// Zero fields are unlimited.
+type RequestLimits struct {
+	// MaxBodyBytes limits the JSON-encoded size of the request.
+	MaxBodyBytes int
+
+	// MaxMessages limits the number of messages.
+	MaxMessages int
+
+	// MaxImages limits the number of image parts across all messages.
+	MaxImages int
+
+	// MaxTools limits the number of tool definitions.
+	MaxTools int
+}
+
+// Limit identifiers reported in RequestLimitError.Limit.
+const (
+	LimitBodyBytes = "max_body_bytes"
+	LimitMessages  = "max_messages"
+	LimitImages    = "max_images"
+	LimitTools     = "max_tools"
+)
+
+// RequestLimitError represents a request rejected by the client's
+// RequestLimits before it was sent.
+type RequestLimitError struct {
+	WarpError
+
+	// Limit identifies the exceeded limit (e.g., LimitMessages).
+	Limit string
+
+	// Max is the configured limit.
+	Max int
+
+	// Actual is the request's value.
+	Actual int
+}
+
+// NewRequestLimitError creates a new request limit error.
+func NewRequestLimitError(limit string, max, actual int, provider, model string) *RequestLimitError {
+	return &RequestLimitError{
+		WarpError: WarpError{
+			Message:    fmt.Sprintf("request exceeds %s: %d > %d", limit, actual, max),
+			StatusCode: 413,
+			Provider:   provider,
+			Model:      model,
+		},
+		Limit:  limit,
+		Max:    max,
+		Actual: actual,
+	}
+}
+
+// checkRequestLimits returns a *RequestLimitError if req exceeds the
+// configured RequestLimits. The body size is checked last, since it
+// requires encoding the request.
+func (c *client) checkRequestLimits(req *CompletionRequest, providerName, modelName string) error {
+	limits := c.config.RequestLimits
+	if limits == nil {
+		return nil
+	}
+
+	if limits.MaxMessages > 0 && len(req.Messages) > limits.MaxMessages {
+		return NewRequestLimitError(LimitMessages, limits.MaxMessages, len(req.Messages), providerName, modelName)
+	}
+	if limits.MaxTools > 0 && len(req.Tools) > limits.MaxTools {
+		return NewRequestLimitError(LimitTools, limits.MaxTools, len(req.Tools), providerName, modelName)
+	}
+	if limits.MaxImages > 0 {
+		images := 0
+		for _, msg := range req.Messages {
+			images += countImages(msg.Content)
+		}
+		if images > limits.MaxImages {
+			return NewRequestLimitError(LimitImages, limits.MaxImages, images, providerName, modelName)
+		}
+	}
+	if limits.MaxBodyBytes > 0 {
+		body, err := json.Marshal(req)
+		if err != nil {
+			return fmt.Errorf("failed to marshal request: %w", err)
+		}
+		if len(body) > limits.MaxBodyBytes {
+			return NewRequestLimitError(LimitBodyBytes, limits.MaxBodyBytes, len(body), providerName, modelName)
+		}
+	}
+	return nil
+}
+
+// countImages returns the number of image parts in message content.
+// Content decoded from JSON as []any is accepted.
+func countImages(content any) int {
+	count := 0
+	switch c := content.(type) {
+	case []ContentPart:
+		for _, part := range c {
+			if part.Type == "image_url" {
+				count++
+			}
+		}
+	case []any:
+		for _, part := range c {
+			if m, ok := part.(map[string]any); ok && m["type"] == "image_url" {
+				count++
+			}
+		}
+	}
+	return count
+}
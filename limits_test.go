@@ -0,0 +1,102 @@
+package warp
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestRequestLimits(t *testing.T) {
+	image := ContentPart{Type: "image_url", ImageURL: &ImageURL{URL: "https://example.com/a.png"}}
+	tests := []struct {
+		name      string
+		limits    RequestLimits
+		req       CompletionRequest
+		wantLimit string
+		wantValue int
+	}{
+		{
+			name:   "within limits",
+			limits: RequestLimits{MaxBodyBytes: 1 << 10, MaxMessages: 2, MaxImages: 1, MaxTools: 1},
+			req:    CompletionRequest{Messages: []Message{{Role: "user", Content: []ContentPart{image}}}},
+		},
+		{
+			name:      "messages",
+			limits:    RequestLimits{MaxMessages: 1},
+			req:       CompletionRequest{Messages: []Message{{Role: "user", Content: "a"}, {Role: "assistant", Content: "b"}}},
+			wantLimit: LimitMessages,
+			wantValue: 2,
+		},
+		{
+			name:   "images across messages and decoded JSON",
+			limits: RequestLimits{MaxImages: 2},
+			req: CompletionRequest{Messages: []Message{
+				{Role: "user", Content: []ContentPart{image, {Type: "text", Text: "compare"}, image}},
+				{Role: "user", Content: []any{map[string]any{"type": "image_url"}}},
+			}},
+			wantLimit: LimitImages,
+			wantValue: 3,
+		},
+		{
+			name:      "tools",
+			limits:    RequestLimits{MaxTools: 1},
+			req:       CompletionRequest{Tools: []Tool{{Type: "function"}, {Type: "function"}}},
+			wantLimit: LimitTools,
+			wantValue: 2,
+		},
+		{
+			name:      "body bytes",
+			limits:    RequestLimits{MaxBodyBytes: 1 << 10},
+			req:       CompletionRequest{Messages: []Message{{Role: "user", Content: strings.Repeat("x", 2<<10)}}},
+			wantLimit: LimitBodyBytes,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			called := false
+			client, err := NewClient(WithRequestLimits(tt.limits))
+			if err != nil {
+				t.Fatalf("NewClient() error = %v", err)
+			}
+			client.RegisterProvider(&mockProvider{
+				name: "test",
+				completionFunc: func(ctx context.Context, req *CompletionRequest) (*CompletionResponse, error) {
+					called = true
+					return &CompletionResponse{ID: "test"}, nil
+				},
+			})
+
+			req := tt.req
+			req.Model = "test/model"
+			_, err = client.Completion(context.Background(), &req)
+
+			if tt.wantLimit == "" {
+				if err != nil || !called {
+					t.Fatalf("Completion() error = %v, called = %v", err, called)
+				}
+				return
+			}
+			var limitErr *RequestLimitError
+			if !errors.As(err, &limitErr) {
+				t.Fatalf("Completion() error = %T %v, want *RequestLimitError", err, err)
+			}
+			if called {
+				t.Error("provider was called for a request over the limit")
+			}
+			if limitErr.Limit != tt.wantLimit || limitErr.Provider != "test" || limitErr.Model != "model" {
+				t.Errorf("error = %+v, want limit %s", limitErr, tt.wantLimit)
+			}
+			if tt.wantValue != 0 && limitErr.Actual != tt.wantValue {
+				t.Errorf("Actual = %d, want %d", limitErr.Actual, tt.wantValue)
+			}
+		})
+	}
+}
+
+func TestWithRequestLimitsNegative(t *testing.T) {
+	if _, err := NewClient(WithRequestLimits(RequestLimits{MaxMessages: -1})); err == nil {
+		t.Error("NewClient() error = nil for a negative limit")
+	}
+}
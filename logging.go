@@ -0,0 +1,48 @@
+//go:build !tinygo && !warp_minimal
+
+package warp
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+
+	"github.com/blue-context/warp/callback"
+)
+
+// LogCompletions returns a success callback that logs each completion to
+// logger at info level, with the response redacted according to policy.
+// Debug mode (WithDebug) installs it with slog.Default() and
+// DefaultRedactPolicy.
+//
+// Example:
+//
+//	logger := slog.New(slog.NewJSONHandler(os.Stderr, nil))
+//	client, err := warp.NewClient(
+//	    warp.WithSuccessCallback(warp.LogCompletions(logger, warp.RedactPolicy{Content: warp.RedactHash})),
+//	)
+func LogCompletions(logger *slog.Logger, policy RedactPolicy) callback.SuccessCallback {
+	return func(ctx context.Context, event *callback.SuccessEvent) {
+		attrs := []slog.Attr{
+			slog.String("request_id", event.RequestID),
+			slog.String("provider", event.Provider),
+			slog.String("model", event.Model),
+			slog.Duration("duration", event.Duration),
+			slog.Int("tokens", event.Tokens),
+		}
+		if event.Cost > 0 {
+			attrs = append(attrs, slog.Float64("cost", event.Cost))
+		}
+		if resp, ok := event.Response.(*CompletionResponse); ok && resp != nil {
+			if data, err := json.Marshal(Redact(resp, policy)); err == nil {
+				attrs = append(attrs, slog.String("response", string(data)))
+			}
+		}
+		logger.LogAttrs(ctx, slog.LevelInfo, "completion", attrs...)
+	}
+}
+
+// debugLogger returns the completion logger of debug mode.
+func debugLogger() callback.SuccessCallback {
+	return LogCompletions(slog.Default(), DefaultRedactPolicy)
+}
@@ -0,0 +1,11 @@
+//go:build tinygo || warp_minimal
+
+package warp
+
+import "github.com/blue-context/warp/callback"
+
+// debugLogger returns nil: the minimal build profile leaves out log/slog,
+// so debug mode does not log.
+func debugLogger() callback.SuccessCallback {
+	return nil
+}
@@ -0,0 +1,70 @@
+//go:build !tinygo && !warp_minimal
+
+package warp
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/blue-context/warp/callback"
+)
+
+func TestLogCompletions(t *testing.T) {
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewTextHandler(&buf, nil))
+	log := LogCompletions(logger, DefaultRedactPolicy)
+	log(context.Background(), &callback.SuccessEvent{
+		RequestID: "req_1",
+		Provider:  "openai",
+		Model:     "gpt-4o",
+		Response:  redactTestResponse(),
+	})
+
+	out := buf.String()
+	if !strings.Contains(out, "request_id=req_1") || !strings.Contains(out, "sha256:") || !strings.Contains(out, "total_tokens") {
+		t.Errorf("log = %s, want the request ID, hashed content and usage", out)
+	}
+	if strings.Contains(out, "123-45-6789") {
+		t.Errorf("log = %s, contains message content", out)
+	}
+}
+
+func TestDebugLogging(t *testing.T) {
+	var buf bytes.Buffer
+	defer slog.SetDefault(slog.Default())
+	slog.SetDefault(slog.New(slog.NewTextHandler(&buf, nil)))
+
+	client, err := NewClient(WithDebug(true))
+	if err != nil {
+		t.Fatal(err)
+	}
+	client.RegisterProvider(&mockProvider{
+		name: "mock",
+		completionFunc: func(ctx context.Context, req *CompletionRequest) (*CompletionResponse, error) {
+			return redactTestResponse(), nil
+		},
+	})
+	derived, err := client.With(WithTimeout(time.Minute))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	req := &CompletionRequest{Model: "mock/model", Messages: []Message{{Role: "user", Content: "Hi"}}}
+	for _, c := range []Client{client, derived} {
+		if _, err := c.Completion(context.Background(), req); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// One line per completion; the derived client does not log twice
+	if got := strings.Count(buf.String(), "msg=completion"); got != 2 {
+		t.Errorf("logged %d completions, want 2:\n%s", got, buf.String())
+	}
+	if strings.Contains(buf.String(), "123-45-6789") {
+		t.Errorf("log = %s, contains message content", buf.String())
+	}
+}
@@ -0,0 +1,199 @@
+package warp
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ContentToString returns the text of a message, joining the text parts of
+// multimodal content with newlines. Image parts are omitted.
+//
+// Example:
+//
+//	text := warp.ContentToString(resp.Choices[0].Message)
+func ContentToString(msg Message) string {
+	return messageText(msg.Content)
+}
+
+// NormalizeMessages rewrites messages into the most portable form, so that
+// the strictest provider constraints (notably Anthropic's) are met without
+// provider-specific code in the application:
+//
+//   - Multimodal content with only text parts is flattened to a string.
+//   - Consecutive messages with the same role are merged, except tool
+//     results, which stay separate. Tool calls of merged assistant
+//     messages are combined.
+//
+// It then validates role ordering and returns an error if a message has an
+// unknown role, a system or developer message follows conversation
+// messages, the conversation starts with an assistant message, or a tool
+// result does not answer a preceding assistant tool call.
+//
+// The input is not modified.
+//
+// Example:
+//
+//	messages, err := warp.NormalizeMessages(history)
+//	if err != nil {
+//	    return err
+//	}
+func NormalizeMessages(messages []Message) ([]Message, error) {
+	normalized := make([]Message, 0, len(messages))
+	for _, msg := range messages {
+		msg.Content = flattenContent(msg.Content)
+
+		if n := len(normalized); n > 0 && msg.Role != "tool" && normalized[n-1].Role == msg.Role && normalized[n-1].Name == msg.Name {
+			prev := &normalized[n-1]
+			prev.Content = mergeContent(prev.Content, msg.Content)
+			if len(msg.ToolCalls) > 0 {
+				prev.ToolCalls = append(append([]ToolCall(nil), prev.ToolCalls...), msg.ToolCalls...)
+			}
+			continue
+		}
+		normalized = append(normalized, msg)
+	}
+
+	if err := validateRoles(normalized); err != nil {
+		return nil, err
+	}
+	return normalized, nil
+}
+
+// validateRoles checks the role ordering of normalized messages.
+func validateRoles(messages []Message) error {
+	pending := make(map[string]bool) // unanswered tool call IDs
+	conversation := false
+	for i, msg := range messages {
+		switch msg.Role {
+		case "system", "developer":
+			if conversation {
+				return fmt.Errorf("message %d: %s message must precede user and assistant messages", i, msg.Role)
+			}
+		case "user":
+			conversation = true
+		case "assistant":
+			if !conversation {
+				return fmt.Errorf("message %d: conversation must start with a user message", i)
+			}
+			for _, call := range msg.ToolCalls {
+				pending[call.ID] = true
+			}
+		case "tool":
+			if !pending[msg.ToolCallID] {
+				return fmt.Errorf("message %d: tool result %q does not answer a preceding tool call", i, msg.ToolCallID)
+			}
+			delete(pending, msg.ToolCallID)
+		default:
+			return fmt.Errorf("message %d: unknown role %q", i, msg.Role)
+		}
+	}
+	return nil
+}
+
+// DeveloperRoleSupporter is implemented by providers that accept the
+// "developer" message role natively (OpenAI reasoning models). Other
+// providers receive developer messages as system messages.
+type DeveloperRoleSupporter interface {
+	// SupportsDeveloperRole reports whether model accepts "developer"
+	// messages. model has no provider prefix.
+	SupportsDeveloperRole(model string) bool
+}
+
+// downgradeDeveloperRole returns req with "developer" messages sent as
+// "system" messages, unless p supports the developer role for model.
+func downgradeDeveloperRole(p Provider, req *CompletionRequest, model string) *CompletionRequest {
+	if supporter, ok := p.(DeveloperRoleSupporter); ok && supporter.SupportsDeveloperRole(model) {
+		return req
+	}
+
+	var r *CompletionRequest
+	for i, msg := range req.Messages {
+		if msg.Role != "developer" {
+			continue
+		}
+		if r == nil {
+			copied := *req
+			copied.Messages = append([]Message(nil), req.Messages...)
+			r = &copied
+		}
+		r.Messages[i].Role = "system"
+	}
+	if r == nil {
+		return req
+	}
+	return r
+}
+
+// flattenContent returns multimodal content with only text parts as a
+// string, and other content unchanged.
+func flattenContent(content any) any {
+	parts, ok := content.([]ContentPart)
+	if !ok {
+		return content
+	}
+	for _, part := range parts {
+		if part.Type != "text" {
+			return content
+		}
+	}
+	return messageText(parts)
+}
+
+// mergeContent concatenates the content of two messages, separating text
+// with a blank line.
+func mergeContent(a, b any) any {
+	as, aText := a.(string)
+	bs, bText := b.(string)
+	switch {
+	case aText && bText:
+		if as == "" || bs == "" {
+			return as + bs
+		}
+		return as + "\n\n" + bs
+	case a == nil:
+		return b
+	case b == nil:
+		return a
+	}
+	return append(contentParts(a), contentParts(b)...)
+}
+
+// contentParts returns content as a new slice of parts.
+func contentParts(content any) []ContentPart {
+	switch c := content.(type) {
+	case string:
+		return []ContentPart{{Type: "text", Text: c}}
+	case []ContentPart:
+		return append([]ContentPart(nil), c...)
+	default:
+		return nil
+	}
+}
+
+// messageText returns the text of message content, joining the text parts
+// of multimodal content. Content decoded from JSON as []any is accepted.
+func messageText(content any) string {
+	switch c := content.(type) {
+	case string:
+		return c
+	case []ContentPart:
+		var parts []string
+		for _, part := range c {
+			if part.Type == "text" {
+				parts = append(parts, part.Text)
+			}
+		}
+		return strings.Join(parts, "\n")
+	case []any:
+		var parts []string
+		for _, part := range c {
+			if m, ok := part.(map[string]any); ok && m["type"] == "text" {
+				text, _ := m["text"].(string)
+				parts = append(parts, text)
+			}
+		}
+		return strings.Join(parts, "\n")
+	default:
+		return ""
+	}
+}
@@ -0,0 +1,133 @@
+package warp
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+func TestContentToString(t *testing.T) {
+	tests := []struct {
+		name string
+		msg  Message
+		want string
+	}{
+		{"string", Message{Content: "hello"}, "hello"},
+		{"parts", Message{Content: []ContentPart{
+			{Type: "text", Text: "look"},
+			{Type: "image_url", ImageURL: &ImageURL{URL: "https://example.com/a.png"}},
+			{Type: "text", Text: "at this"},
+		}}, "look\nat this"},
+		{"decoded parts", Message{Content: []any{map[string]any{"type": "text", "text": "hi"}}}, "hi"},
+		{"nil", Message{}, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ContentToString(tt.msg); got != tt.want {
+				t.Errorf("ContentToString() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNormalizeMessages(t *testing.T) {
+	image := ContentPart{Type: "image_url", ImageURL: &ImageURL{URL: "https://example.com/a.png"}}
+	input := []Message{
+		{Role: "system", Content: "Be brief."},
+		{Role: "system", Content: []ContentPart{{Type: "text", Text: "Be kind."}}},
+		{Role: "user", Content: "Hi"},
+		{Role: "user", Content: []ContentPart{image}},
+		{Role: "assistant", ToolCalls: []ToolCall{{ID: "a"}}},
+		{Role: "assistant", ToolCalls: []ToolCall{{ID: "b"}}},
+		{Role: "tool", ToolCallID: "a", Content: "1"},
+		{Role: "tool", ToolCallID: "b", Content: "2"},
+		{Role: "assistant", Content: "Done."},
+	}
+
+	got, err := NormalizeMessages(input)
+	if err != nil {
+		t.Fatalf("NormalizeMessages() error = %v", err)
+	}
+
+	var roles []string
+	for _, msg := range got {
+		roles = append(roles, msg.Role)
+	}
+	if strings.Join(roles, ",") != "system,user,assistant,tool,tool,assistant" {
+		t.Fatalf("roles = %v", roles)
+	}
+	if got[0].Content != "Be brief.\n\nBe kind." {
+		t.Errorf("system = %q", got[0].Content)
+	}
+	if parts, ok := got[1].Content.([]ContentPart); !ok || len(parts) != 2 || parts[0].Text != "Hi" || parts[1].Type != "image_url" {
+		t.Errorf("user = %+v, want text and image parts", got[1].Content)
+	}
+	if len(got[2].ToolCalls) != 2 {
+		t.Errorf("tool calls = %+v, want both merged", got[2].ToolCalls)
+	}
+	if len(input[4].ToolCalls) != 1 {
+		t.Error("input was modified")
+	}
+}
+
+func TestNormalizeMessagesInvalid(t *testing.T) {
+	tests := []struct {
+		name     string
+		messages []Message
+		want     string
+	}{
+		{"late system", []Message{{Role: "user", Content: "hi"}, {Role: "system", Content: "x"}}, "system message"},
+		{"assistant first", []Message{{Role: "system", Content: "x"}, {Role: "assistant", Content: "hi"}}, "start with a user"},
+		{"orphan tool", []Message{{Role: "user", Content: "hi"}, {Role: "tool", ToolCallID: "x"}}, "does not answer"},
+		{"unknown role", []Message{{Role: "robot", Content: "hi"}}, "unknown role"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NormalizeMessages(tt.messages)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("error = %v, want %q", err, tt.want)
+			}
+		})
+	}
+}
+
+// developerProvider is a mockProvider that supports the developer role.
+type developerProvider struct {
+	mockProvider
+}
+
+func (p *developerProvider) SupportsDeveloperRole(model string) bool {
+	return true
+}
+
+func TestDeveloperRole(t *testing.T) {
+	var got *CompletionRequest
+	capture := func(ctx context.Context, req *CompletionRequest) (*CompletionResponse, error) {
+		got = req
+		return &CompletionResponse{Choices: []Choice{{Message: Message{Role: "assistant", Content: "ok"}}}}, nil
+	}
+
+	client, err := NewClient()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+	client.RegisterProvider(&developerProvider{mockProvider{name: "native", completionFunc: capture}})
+	client.RegisterProvider(&mockProvider{name: "plain", completionFunc: capture})
+
+	messages := []Message{{Role: "developer", Content: "Think step by step."}, {Role: "user", Content: "Hi"}}
+	for _, tt := range []struct{ model, want string }{
+		{"native/o3", "developer"},
+		{"plain/m", "system"},
+	} {
+		if _, err := client.Completion(context.Background(), &CompletionRequest{Model: tt.model, Messages: messages}); err != nil {
+			t.Fatalf("%s: Completion() error = %v", tt.model, err)
+		}
+		if got.Messages[0].Role != tt.want {
+			t.Errorf("%s: role = %q, want %q", tt.model, got.Messages[0].Role, tt.want)
+		}
+	}
+	if messages[0].Role != "developer" {
+		t.Error("caller's messages were modified")
+	}
+}
@@ -0,0 +1,37 @@
+package warp
+
+import (
+	"context"
+	"fmt"
+)
+
+// RequestMiddleware transforms a completion request before it is dispatched.
+//
+// Middleware runs before callbacks, caching, and provider dispatch, so the
+// transformed request is what providers see and what cache keys are derived
+// from. The request passed in has the model in "provider/model-name" format.
+// Implementations must not modify req; return a modified copy instead.
+// Returning an error aborts the request.
+//
+// Thread Safety: RequestMiddleware implementations must be safe for concurrent use.
+type RequestMiddleware func(ctx context.Context, req *CompletionRequest) (*CompletionRequest, error)
+
+// applyRequestMiddleware runs the configured middleware in order.
+func (c *client) applyRequestMiddleware(ctx context.Context, req *CompletionRequest) (*CompletionRequest, error) {
+	for _, mw := range c.config.RequestMiddleware {
+		next, err := c.runRequestMiddleware(ctx, mw, req)
+		if err != nil {
+			return nil, fmt.Errorf("request middleware failed: %w", err)
+		}
+		if next != nil {
+			req = next
+		}
+	}
+	return req, nil
+}
+
+// runRequestMiddleware calls mw, converting a panic into an error.
+func (c *client) runRequestMiddleware(ctx context.Context, mw RequestMiddleware, req *CompletionRequest) (next *CompletionRequest, err error) {
+	defer c.recoverPanic("", "request middleware panicked", &err)
+	return mw(ctx, req)
+}
@@ -0,0 +1,91 @@
+package warp
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+// TestRequestMiddleware tests that middleware transforms requests before dispatch
+func TestRequestMiddleware(t *testing.T) {
+	var seen *CompletionRequest
+	mock := &mockProvider{
+		name: "test",
+		completionFunc: func(ctx context.Context, req *CompletionRequest) (*CompletionResponse, error) {
+			seen = req
+			return &CompletionResponse{ID: "1"}, nil
+		},
+	}
+
+	var order []string
+	first := func(ctx context.Context, req *CompletionRequest) (*CompletionRequest, error) {
+		order = append(order, "first")
+		out := *req
+		out.Messages = append([]Message{{Role: "system", Content: "Be brief."}}, req.Messages...)
+		return &out, nil
+	}
+	second := func(ctx context.Context, req *CompletionRequest) (*CompletionRequest, error) {
+		order = append(order, "second")
+		return nil, nil // nil keeps the request unchanged
+	}
+
+	client, err := NewClient(WithRequestMiddleware(first, second))
+	if err != nil {
+		t.Fatalf("NewClient() error = %v", err)
+	}
+	defer client.Close()
+	if err := client.RegisterProvider(mock); err != nil {
+		t.Fatalf("RegisterProvider() error = %v", err)
+	}
+
+	req := &CompletionRequest{Model: "test/model", Messages: []Message{{Role: "user", Content: "Hi"}}}
+	if _, err := client.Completion(context.Background(), req); err != nil {
+		t.Fatalf("Completion() error = %v", err)
+	}
+
+	if len(order) != 2 || order[0] != "first" || order[1] != "second" {
+		t.Errorf("middleware order = %v, want [first second]", order)
+	}
+	if seen == nil || len(seen.Messages) != 2 {
+		t.Fatalf("provider request = %+v, want 2 messages", seen)
+	}
+	if len(req.Messages) != 1 {
+		t.Error("middleware modified the caller's request")
+	}
+}
+
+// TestRequestMiddlewareError tests that middleware errors abort the request
+func TestRequestMiddlewareError(t *testing.T) {
+	called := false
+	mock := &mockProvider{
+		name: "test",
+		completionFunc: func(ctx context.Context, req *CompletionRequest) (*CompletionResponse, error) {
+			called = true
+			return &CompletionResponse{}, nil
+		},
+	}
+
+	errBlocked := errors.New("blocked")
+	client, err := NewClient(WithRequestMiddleware(func(ctx context.Context, req *CompletionRequest) (*CompletionRequest, error) {
+		return nil, errBlocked
+	}))
+	if err != nil {
+		t.Fatalf("NewClient() error = %v", err)
+	}
+	defer client.Close()
+	if err := client.RegisterProvider(mock); err != nil {
+		t.Fatalf("RegisterProvider() error = %v", err)
+	}
+
+	_, err = client.Completion(context.Background(), &CompletionRequest{Model: "test/model"})
+	if !errors.Is(err, errBlocked) {
+		t.Errorf("Completion() error = %v, want %v", err, errBlocked)
+	}
+	if called {
+		t.Error("provider called after middleware error")
+	}
+
+	if _, err := NewClient(WithRequestMiddleware(nil)); err == nil {
+		t.Error("WithRequestMiddleware(nil) expected error")
+	}
+}
@@ -3,7 +3,6 @@ package warp
 import (
 	"context"
 	"fmt"
-	"time"
 )
 
 // Moderation checks content for policy violations.
@@ -61,7 +60,7 @@ func (c *client) Moderation(ctx context.Context, req *ModerationRequest) (*Moder
 	}
 
 	// Add start time to context
-	ctx = WithStartTime(ctx, time.Now())
+	ctx = WithStartTime(ctx, c.now())
 
 	// Parse model string
 	providerName, modelName, err := parseModel(req.Model)
@@ -72,6 +71,7 @@ func (c *client) Moderation(ctx context.Context, req *ModerationRequest) (*Moder
 	// Add provider and model to context
 	ctx = WithProvider(ctx, providerName)
 	ctx = WithModel(ctx, modelName)
+	ctx = c.withRequestHeaders(ctx)
 
 	// Get provider
 	p, err := c.getProvider(providerName)
@@ -114,16 +114,18 @@ func (c *client) Moderation(ctx context.Context, req *ModerationRequest) (*Moder
 	// Apply timeout
 	if c.config.DefaultTimeout > 0 {
 		var cancel context.CancelFunc
-		ctx, cancel = context.WithTimeout(ctx, c.config.DefaultTimeout)
+		ctx, cancel = c.withTimeout(ctx, c.config.DefaultTimeout)
 		defer cancel()
 	}
 
 	// Call provider with retries
 	var resp *ModerationResponse
 	err = c.withRetry(ctx, func() error {
-		var callErr error
-		resp, callErr = p.Moderation(ctx, req)
-		return callErr
+		return c.callProvider(ctx, providerName, func() error {
+			var callErr error
+			resp, callErr = p.Moderation(ctx, req)
+			return callErr
+		})
 	})
 
 	if err != nil {
@@ -131,5 +133,7 @@ func (c *client) Moderation(ctx context.Context, req *ModerationRequest) (*Moder
 	}
 
 	resp.Provider = providerName
+	resp.RequestID = RequestIDFromContext(ctx)
+	resp.ProviderRequestID = ProviderRequestIDFromContext(ctx)
 	return resp, nil
 }
@@ -0,0 +1,178 @@
+package warp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+)
+
+// TruncationStrategy shortens a request that exceeded the model's context
+// window.
+//
+// It returns the request to retry, which must be a copy if it differs from
+// req, or nil if nothing more can be removed. overflow carries the limits
+// reported by the provider; MaxTokens and Tokens are zero when the provider
+// did not report them.
+type TruncationStrategy func(ctx context.Context, req *CompletionRequest, overflow *ContextWindowExceededError) (*CompletionRequest, error)
+
+// ContextOverflowPolicy controls how the client recovers from
+// *ContextWindowExceededError.
+//
+// A request that overflows is first retried on the fallback model for its
+// model, if any. Fallbacks chain: if the fallback also overflows, its own
+// fallback is tried. Once no fallback remains, the request is shortened
+// with Truncate and retried, up to MaxTruncations times. The last error is
+// returned if the request still does not fit.
+type ContextOverflowPolicy struct {
+	// Fallbacks maps a model to a larger-context model to retry on, both
+	// in "provider/model" form, e.g.,
+	// {"openai/gpt-4o": "openai/gpt-4.1"}.
+	Fallbacks map[string]string
+
+	// Truncate shortens the request once no fallback remains (nil
+	// disables truncation).
+	Truncate TruncationStrategy
+
+	// MaxTruncations limits truncation retries (default 3).
+	MaxTruncations int
+}
+
+// maxTruncations returns MaxTruncations or its default.
+func (p *ContextOverflowPolicy) maxTruncations() int {
+	if p.MaxTruncations > 0 {
+		return p.MaxTruncations
+	}
+	return 3
+}
+
+// TruncateOldest returns a TruncationStrategy that drops the oldest fraction
+// (0 to 1) of the conversation on each attempt.
+//
+// System messages and the final message are always kept, and at least one
+// message is dropped per attempt. When the provider reported token counts,
+// the fraction is raised to the share of tokens over the limit. Tool results
+// whose tool call was dropped are dropped with it, so the conversation
+// stays valid.
+//
+// Example:
+//
+//	warp.WithContextOverflowPolicy(&warp.ContextOverflowPolicy{
+//	    Fallbacks: map[string]string{"openai/gpt-4o": "openai/gpt-4.1"},
+//	    Truncate:  warp.TruncateOldest(0.25),
+//	})
+func TruncateOldest(fraction float64) TruncationStrategy {
+	return func(ctx context.Context, req *CompletionRequest, overflow *ContextWindowExceededError) (*CompletionRequest, error) {
+		f := fraction
+		if overflow != nil && overflow.MaxTokens > 0 && overflow.Tokens > overflow.MaxTokens {
+			f = max(f, 1-float64(overflow.MaxTokens)/float64(overflow.Tokens))
+		}
+
+		// Indexes of messages that may be dropped, oldest first
+		var droppable []int
+		for i, msg := range req.Messages[:max(len(req.Messages)-1, 0)] {
+			if msg.Role != "system" {
+				droppable = append(droppable, i)
+			}
+		}
+		if len(droppable) == 0 {
+			return nil, nil
+		}
+
+		n := min(max(int(math.Ceil(f*float64(len(droppable)))), 1), len(droppable))
+		drop := make(map[int]bool, n)
+		for _, i := range droppable[:n] {
+			drop[i] = true
+		}
+
+		// Drop tool results answering dropped tool calls
+		droppedCalls := make(map[string]bool)
+		for i := range drop {
+			for _, call := range req.Messages[i].ToolCalls {
+				droppedCalls[call.ID] = true
+			}
+		}
+
+		r := *req
+		r.Messages = make([]Message, 0, len(req.Messages)-n)
+		for i, msg := range req.Messages {
+			if drop[i] || (msg.Role == "tool" && droppedCalls[msg.ToolCallID] && i != len(req.Messages)-1) {
+				continue
+			}
+			r.Messages = append(r.Messages, msg)
+		}
+		return &r, nil
+	}
+}
+
+// recoverContextOverflow retries a request that failed with
+// *ContextWindowExceededError according to the client's
+// ContextOverflowPolicy.
+//
+// unmodified is req before request middleware. Each fallback or truncated
+// request is derived from it and passed through the middleware and
+// prepareForProvider again, so substituted requests are checked and
+// transformed like the original, for the provider they are sent to.
+//
+// call sends a request to a provider, with the model already stripped of its
+// provider prefix. It returns the request that was last attempted (with the
+// provider prefix) and its error; req and err are returned unchanged when
+// the policy does not apply.
+func (c *client) recoverContextOverflow(ctx context.Context, unmodified, req *CompletionRequest, err error, call func(p Provider, req *CompletionRequest) error) (*CompletionRequest, error) {
+	policy := c.config.ContextOverflowPolicy
+	if policy == nil {
+		return req, err
+	}
+
+	tried := map[string]bool{req.Model: true}
+	truncations := 0
+
+	var overflow *ContextWindowExceededError
+	for errors.As(err, &overflow) {
+		var next *CompletionRequest
+		if fallback, ok := policy.Fallbacks[req.Model]; ok && !tried[fallback] {
+			tried[fallback] = true
+			r := *unmodified
+			r.Model = fallback
+			next = &r
+		} else if policy.Truncate != nil && truncations < policy.maxTruncations() {
+			truncations++
+			truncated, truncErr := policy.Truncate(ctx, unmodified, overflow)
+			if truncErr != nil {
+				return req, fmt.Errorf("context overflow truncation failed: %w", truncErr)
+			}
+			if truncated == nil {
+				return req, err
+			}
+			next = truncated
+		} else {
+			return req, err
+		}
+		unmodified = next
+
+		next, mwErr := c.applyRequestMiddleware(ctx, next)
+		if mwErr != nil {
+			return req, mwErr
+		}
+
+		providerName, modelName, parseErr := parseModel(next.Model)
+		if parseErr != nil {
+			return req, fmt.Errorf("invalid context overflow fallback: %w", parseErr)
+		}
+		p, providerErr := c.getProvider(providerName)
+		if providerErr != nil {
+			return req, fmt.Errorf("context overflow fallback provider %q not found (did you register it?)", providerName)
+		}
+		next, err = c.prepareForProvider(ctx, p, next, providerName, modelName)
+		if err != nil {
+			return req, err
+		}
+
+		providerReq := *next
+		providerReq.Model = modelName
+		req = next
+		err = call(p, &providerReq)
+	}
+
+	return req, err
+}
@@ -0,0 +1,274 @@
+package warp
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+// overflowAbove returns a completion func that overflows when a request
+// has more than limit messages, recording the models it was called with.
+func overflowAbove(limit int, models *[]string) func(ctx context.Context, req *CompletionRequest) (*CompletionResponse, error) {
+	return func(ctx context.Context, req *CompletionRequest) (*CompletionResponse, error) {
+		*models = append(*models, req.Model)
+		if len(req.Messages) > limit {
+			return nil, NewContextWindowExceededError("maximum context length exceeded", "mock", 0, 0, nil)
+		}
+		return &CompletionResponse{Model: req.Model, Choices: []Choice{{Message: Message{Role: "assistant", Content: "ok"}}}}, nil
+	}
+}
+
+func conversation(n int) []Message {
+	messages := []Message{{Role: "system", Content: "be brief"}}
+	for i := 0; i < n; i++ {
+		role := "user"
+		if i%2 == 1 {
+			role = "assistant"
+		}
+		messages = append(messages, Message{Role: role, Content: "turn"})
+	}
+	return messages
+}
+
+func TestContextOverflowFallback(t *testing.T) {
+	var small, large []string
+	client, err := NewClient(
+		WithMaxRetries(0),
+		WithContextOverflowPolicy(&ContextOverflowPolicy{
+			Fallbacks: map[string]string{"small/m": "large/m-long"},
+		}),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+
+	var streamed []string
+	streamFunc := func(ctx context.Context, req *CompletionRequest) (Stream, error) {
+		streamed = append(streamed, req.Model)
+		if req.Model == "m" {
+			return nil, NewContextWindowExceededError("maximum context length exceeded", "small", 0, 0, nil)
+		}
+		return &mockStream{}, nil
+	}
+	client.RegisterProvider(&mockProvider{name: "small", completionFunc: overflowAbove(2, &small), completionStreamFunc: streamFunc})
+	client.RegisterProvider(&mockProvider{name: "large", completionFunc: overflowAbove(100, &large), completionStreamFunc: streamFunc})
+
+	resp, err := client.Completion(context.Background(), &CompletionRequest{Model: "small/m", Messages: conversation(5)})
+	if err != nil {
+		t.Fatalf("Completion() error = %v", err)
+	}
+	if resp.Model != "m-long" || len(small) != 1 || len(large) != 1 {
+		t.Errorf("served by %q after calls small=%v large=%v, want one call each", resp.Model, small, large)
+	}
+
+	if _, err := client.CompletionStream(context.Background(), &CompletionRequest{Model: "small/m", Messages: conversation(5)}); err != nil {
+		t.Errorf("CompletionStream() error = %v", err)
+	}
+	if len(streamed) != 2 || streamed[1] != "m-long" {
+		t.Errorf("stream models = %v, want fallback after overflow", streamed)
+	}
+}
+
+func TestContextOverflowMiddleware(t *testing.T) {
+	var small, large []string
+	var seen []string
+	client, err := NewClient(
+		WithMaxRetries(0),
+		WithContextOverflowPolicy(&ContextOverflowPolicy{
+			Fallbacks: map[string]string{"small/m": "large/m-long"},
+			Truncate:  TruncateOldest(0.5),
+		}),
+		WithRequestMiddleware(func(ctx context.Context, req *CompletionRequest) (*CompletionRequest, error) {
+			seen = append(seen, req.Model)
+			if req.Model == "large/m-long" {
+				return nil, errors.New("model banned")
+			}
+			// Not idempotent: substituted requests must start from the
+			// caller's request
+			r := *req
+			r.Messages = append([]Message{{Role: "system", Content: "context"}}, req.Messages...)
+			return &r, nil
+		}),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+	client.RegisterProvider(&mockProvider{name: "small", completionFunc: overflowAbove(4, &small)})
+	client.RegisterProvider(&mockProvider{name: "large", completionFunc: overflowAbove(100, &large)})
+
+	_, err = client.Completion(context.Background(), &CompletionRequest{Model: "small/m", Messages: conversation(5)})
+	if err == nil || err.Error() != "request middleware failed: model banned" {
+		t.Errorf("Completion() error = %v, want the middleware to refuse the fallback", err)
+	}
+	if len(large) != 0 || len(seen) != 2 || seen[1] != "large/m-long" {
+		t.Errorf("large calls = %v, middleware saw %v, want the fallback refused before sending", large, seen)
+	}
+
+	client, err = NewClient(
+		WithMaxRetries(0),
+		WithContextOverflowPolicy(&ContextOverflowPolicy{Truncate: TruncateOldest(0.5)}),
+		WithRequestMiddleware(func(ctx context.Context, req *CompletionRequest) (*CompletionRequest, error) {
+			r := *req
+			r.Messages = append([]Message{{Role: "system", Content: "context"}}, req.Messages...)
+			return &r, nil
+		}),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+	var got *CompletionRequest
+	client.RegisterProvider(&mockProvider{name: "small", completionFunc: func(ctx context.Context, req *CompletionRequest) (*CompletionResponse, error) {
+		got = req
+		return overflowAbove(4, &small)(ctx, req)
+	}})
+	if _, err := client.Completion(context.Background(), &CompletionRequest{Model: "small/m", Messages: conversation(5)}); err != nil {
+		t.Fatalf("Completion() error = %v", err)
+	}
+	if got.Messages[0].Content != "context" || got.Messages[1].Content == "context" {
+		t.Errorf("truncated request = %+v, want the middleware applied once", got.Messages)
+	}
+}
+
+func TestContextOverflowFallbackPreparedForProvider(t *testing.T) {
+	client, err := NewClient(
+		WithMaxRetries(0),
+		WithContextOverflowPolicy(&ContextOverflowPolicy{
+			Fallbacks: map[string]string{"small/m": "large/m-long"},
+		}),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+
+	var small []string
+	var got *CompletionRequest
+	client.RegisterProvider(&developerProvider{mockProvider{name: "small", completionFunc: overflowAbove(2, &small)}})
+	client.RegisterProvider(&mockProvider{name: "large", completionFunc: func(ctx context.Context, req *CompletionRequest) (*CompletionResponse, error) {
+		got = req
+		return &CompletionResponse{Model: req.Model, Choices: []Choice{{Message: Message{Role: "assistant", Content: "ok"}}}}, nil
+	}})
+
+	messages := append([]Message{{Role: "developer", Content: "be terse"}}, conversation(3)[1:]...)
+	if _, err := client.Completion(context.Background(), &CompletionRequest{Model: "small/m", Messages: messages}); err != nil {
+		t.Fatalf("Completion() error = %v", err)
+	}
+	if got == nil {
+		t.Fatal("fallback provider was not called")
+	}
+	for _, msg := range got.Messages {
+		if msg.Role == "developer" {
+			t.Errorf("fallback request = %+v, want developer messages downgraded for the fallback provider", got.Messages)
+		}
+	}
+}
+
+func TestContextOverflowTruncation(t *testing.T) {
+	var models []string
+	client, err := NewClient(
+		WithMaxRetries(0),
+		WithContextOverflowPolicy(&ContextOverflowPolicy{Truncate: TruncateOldest(0.25)}),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+
+	var last *CompletionRequest
+	limit := overflowAbove(4, &models)
+	client.RegisterProvider(&mockProvider{name: "mock", completionFunc: func(ctx context.Context, req *CompletionRequest) (*CompletionResponse, error) {
+		last = req
+		return limit(ctx, req)
+	}})
+
+	messages := conversation(5)
+	if _, err := client.Completion(context.Background(), &CompletionRequest{Model: "mock/m", Messages: messages}); err != nil {
+		t.Fatalf("Completion() error = %v", err)
+	}
+	if len(models) != 3 {
+		t.Errorf("calls = %d, want 3", len(models))
+	}
+	if len(last.Messages) != 4 || last.Messages[0].Role != "system" {
+		t.Errorf("final messages = %+v, want system prompt and 3 latest turns", last.Messages)
+	}
+	if len(messages) != 6 {
+		t.Error("caller's messages were modified")
+	}
+
+	// Give up after MaxTruncations
+	models = nil
+	_, err = client.Completion(context.Background(), &CompletionRequest{Model: "mock/m", Messages: conversation(20)})
+	var overflow *ContextWindowExceededError
+	if !errors.As(err, &overflow) {
+		t.Errorf("error = %v, want ContextWindowExceededError", err)
+	}
+	if len(models) != 4 {
+		t.Errorf("calls = %d, want 4", len(models))
+	}
+}
+
+func TestContextOverflowWithoutPolicy(t *testing.T) {
+	var models []string
+	client, err := NewClient(WithMaxRetries(0))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+	client.RegisterProvider(&mockProvider{name: "mock", completionFunc: overflowAbove(0, &models)})
+
+	_, err = client.Completion(context.Background(), &CompletionRequest{Model: "mock/m", Messages: conversation(1)})
+	var overflow *ContextWindowExceededError
+	if !errors.As(err, &overflow) || len(models) != 1 {
+		t.Errorf("error = %v after %d calls, want ContextWindowExceededError after 1", err, len(models))
+	}
+}
+
+func TestTruncateOldest(t *testing.T) {
+	req := &CompletionRequest{Messages: []Message{
+		{Role: "system", Content: "sys"},
+		{Role: "user", Content: "look it up"},
+		{Role: "assistant", ToolCalls: []ToolCall{{ID: "call_1"}}},
+		{Role: "tool", ToolCallID: "call_1", Content: "result"},
+		{Role: "assistant", Content: "found it"},
+		{Role: "user", Content: "thanks"},
+	}}
+
+	got, err := TruncateOldest(0.4)(context.Background(), req, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var roles []string
+	for _, msg := range got.Messages {
+		roles = append(roles, msg.Role)
+	}
+	want := []string{"system", "assistant", "user"}
+	if len(roles) != len(want) || roles[0] != want[0] || roles[1] != want[1] || roles[2] != want[2] {
+		t.Errorf("roles = %v, want %v", roles, want)
+	}
+
+	// Reported token counts raise the fraction
+	overflow := NewContextWindowExceededError("too long", "mock", 100, 1000, nil)
+	got, _ = TruncateOldest(0.1)(context.Background(), req, overflow)
+	if len(got.Messages) != 2 {
+		t.Errorf("len(Messages) = %d, want 2", len(got.Messages))
+	}
+
+	// Nothing left to drop
+	minimal := &CompletionRequest{Messages: []Message{{Role: "system", Content: "sys"}, {Role: "user", Content: "hi"}}}
+	if got, _ := TruncateOldest(0.5)(context.Background(), minimal, nil); got != nil {
+		t.Errorf("TruncateOldest() = %+v, want nil", got)
+	}
+}
+
+func TestWithContextOverflowPolicyValidation(t *testing.T) {
+	if _, err := NewClient(WithContextOverflowPolicy(nil)); err == nil {
+		t.Error("expected error for nil policy")
+	}
+	policy := &ContextOverflowPolicy{Fallbacks: map[string]string{"openai/gpt-4o": "openai/gpt-4o"}}
+	if _, err := NewClient(WithContextOverflowPolicy(policy)); err == nil {
+		t.Error("expected error for self fallback")
+	}
+}
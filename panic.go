@@ -0,0 +1,102 @@
+package warp
+
+import "context"
+
+// callProvider runs fn, a call to provider, in the provider's bulkhead.
+// A panic in fn is returned as an *InternalError unless panic recovery is
+// disabled.
+func (c *client) callProvider(ctx context.Context, provider string, fn func() error) (err error) {
+	release, err := c.acquireBulkhead(ctx, provider)
+	if err != nil {
+		return err
+	}
+	defer release()
+	defer c.recoverPanic(provider, "provider panicked", &err)
+	return fn()
+}
+
+// complete sends a completion request to p with callProvider.
+func (c *client) complete(ctx context.Context, p Provider, req *CompletionRequest) (resp *CompletionResponse, err error) {
+	err = c.callProvider(ctx, p.Name(), func() error {
+		var callErr error
+		resp, callErr = c.providerCompletion(ctx, p, req)
+		return callErr
+	})
+	return resp, err
+}
+
+// openStream opens a completion stream from p in its bulkhead, holding
+// the slot until the stream is closed. Panics opening or reading the
+// stream are returned as an *InternalError unless panic recovery is
+// disabled.
+func (c *client) openStream(ctx context.Context, p Provider, req *CompletionRequest) (stream Stream, err error) {
+	release, err := c.acquireBulkhead(ctx, p.Name())
+	if err != nil {
+		return nil, err
+	}
+	func() {
+		defer c.recoverPanic(p.Name(), "provider panicked", &err)
+		stream, err = c.providerStream(ctx, p, req)
+	}()
+	if err != nil {
+		release()
+		return nil, err
+	}
+
+	stream = &requestIDStream{Stream: c.recoverStream(stream, p.Name()), ctx: ctx}
+	if c.bulkheads.get(p.Name()) != nil {
+		stream = &bulkheadStream{Stream: stream, release: release}
+	}
+	return stream, nil
+}
+
+// recoverPanic converts a panic into an *InternalError stored in *err. It
+// must be deferred directly. With panic recovery disabled the panic
+// continues with its original stack.
+func (c *client) recoverPanic(provider, message string, err *error) {
+	if c.config.DisablePanicRecovery {
+		return
+	}
+	if r := recover(); r != nil {
+		*err = NewInternalError(message, provider, r, panicStack())
+	}
+}
+
+// recoverStream returns stream wrapped so that panics in its Recv and
+// Close are returned as an *InternalError, or stream itself if panic
+// recovery is disabled.
+func (c *client) recoverStream(stream Stream, provider string) Stream {
+	if c.config.DisablePanicRecovery || stream == nil {
+		return stream
+	}
+	return &recoverStream{underlying: stream, client: c, provider: provider}
+}
+
+// recoverStream converts panics of a provider stream into errors. After a
+// panic, Recv keeps returning the error.
+type recoverStream struct {
+	underlying Stream
+	client     *client
+	provider   string
+	err        error
+}
+
+func (s *recoverStream) Recv() (chunk *CompletionChunk, err error) {
+	if s.err != nil {
+		return nil, s.err
+	}
+	defer func() {
+		if err != nil && chunk == nil {
+			if _, ok := err.(*InternalError); ok {
+				s.err = err
+			}
+		}
+	}()
+	defer s.client.recoverPanic(s.provider, "provider stream panicked", &err)
+	return s.underlying.Recv()
+}
+
+func (s *recoverStream) Close() (err error) {
+	defer s.client.recoverPanic(s.provider, "provider stream panicked", &err)
+	return s.underlying.Close()
+}
@@ -0,0 +1,10 @@
+//go:build !tinygo && !warp_minimal
+
+package warp
+
+import "runtime/debug"
+
+// panicStack returns the stack of the panicking goroutine.
+func panicStack() []byte {
+	return debug.Stack()
+}
@@ -0,0 +1,9 @@
+//go:build tinygo || warp_minimal
+
+package warp
+
+// panicStack returns nil: the minimal build profile does not record panic
+// stacks.
+func panicStack() []byte {
+	return nil
+}
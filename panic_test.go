@@ -0,0 +1,113 @@
+package warp
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+// panicStream panics on Recv.
+type panicStream struct{}
+
+func (panicStream) Recv() (*CompletionChunk, error) { panic("recv failed") }
+func (panicStream) Close() error                    { return nil }
+
+func TestCompletionPanicRecovered(t *testing.T) {
+	client, err := NewClient()
+	if err != nil {
+		t.Fatal(err)
+	}
+	client.RegisterProvider(&mockProvider{
+		name: "mock",
+		completionFunc: func(ctx context.Context, req *CompletionRequest) (*CompletionResponse, error) {
+			panic("boom")
+		},
+	})
+
+	_, err = client.Completion(context.Background(), &CompletionRequest{
+		Model:    "mock/model",
+		Messages: []Message{{Role: "user", Content: "Hi"}},
+	})
+	var internal *InternalError
+	if !errors.As(err, &internal) {
+		t.Fatalf("Completion() error = %v, want *InternalError", err)
+	}
+	if internal.Provider != "mock" || internal.StatusCode != 500 {
+		t.Errorf("InternalError = %+v, want provider mock and status 500", internal)
+	}
+	if internal.HiddenParams["_panic"] != "boom" {
+		t.Errorf("HiddenParams[_panic] = %v, want boom", internal.HiddenParams["_panic"])
+	}
+	if stack, _ := internal.HiddenParams["_stack"].(string); stack == "" {
+		t.Error("HiddenParams[_stack] is empty")
+	}
+}
+
+func TestCompletionStreamPanicRecovered(t *testing.T) {
+	client, err := NewClient()
+	if err != nil {
+		t.Fatal(err)
+	}
+	client.RegisterProvider(&mockProvider{
+		name: "mock",
+		completionStreamFunc: func(ctx context.Context, req *CompletionRequest) (Stream, error) {
+			return panicStream{}, nil
+		},
+	})
+
+	stream, err := client.CompletionStream(context.Background(), &CompletionRequest{
+		Model:    "mock/model",
+		Messages: []Message{{Role: "user", Content: "Hi"}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer stream.Close()
+
+	for i := 0; i < 2; i++ {
+		var internal *InternalError
+		if _, err := stream.Recv(); !errors.As(err, &internal) {
+			t.Fatalf("Recv() error = %v, want *InternalError", err)
+		}
+	}
+}
+
+func TestRequestMiddlewarePanicRecovered(t *testing.T) {
+	client, err := NewClient(WithRequestMiddleware(func(ctx context.Context, req *CompletionRequest) (*CompletionRequest, error) {
+		panic("middleware failed")
+	}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	client.RegisterProvider(&mockProvider{name: "mock"})
+
+	_, err = client.Completion(context.Background(), &CompletionRequest{
+		Model:    "mock/model",
+		Messages: []Message{{Role: "user", Content: "Hi"}},
+	})
+	var internal *InternalError
+	if !errors.As(err, &internal) {
+		t.Fatalf("Completion() error = %v, want *InternalError", err)
+	}
+}
+
+func TestWithPanicRecoveryDisabled(t *testing.T) {
+	client, err := NewClient(WithPanicRecovery(false))
+	if err != nil {
+		t.Fatal(err)
+	}
+	client.RegisterProvider(&mockProvider{
+		name: "mock",
+		embeddingFunc: func(ctx context.Context, req *EmbeddingRequest) (*EmbeddingResponse, error) {
+			panic("boom")
+		},
+	})
+
+	defer func() {
+		if r := recover(); r != "boom" {
+			t.Errorf("recover() = %v, want provider panic to propagate", r)
+		}
+	}()
+	client.Embedding(context.Background(), &EmbeddingRequest{Model: "mock/model", Input: "Hi"})
+	t.Error("Embedding() returned, want panic")
+}
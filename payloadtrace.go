@@ -0,0 +1,184 @@
+package warp
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"regexp"
+	"strings"
+	"sync"
+
+	"github.com/blue-context/warp/callback"
+)
+
+// maxTracedPayload is the most bytes of a payload kept by payload tracing.
+const maxTracedPayload = 1 << 20
+
+// contextKeyPayloadTrace carries the payload trace of a request.
+const contextKeyPayloadTrace contextKey = "warp_payload_trace"
+
+// secretField matches JSON string fields holding credentials.
+var secretField = regexp.MustCompile(`(?i)("(?:api[_-]?key|access[_-]?token|refresh[_-]?token|id[_-]?token|client[_-]?secret|secret|password|authorization|private[_-]?key)"\s*:\s*)"(?:[^"\\]|\\.)*"`)
+
+// payloadTrace holds the raw bodies of the last provider HTTP exchange of
+// a request.
+type payloadTrace struct {
+	mu       sync.Mutex
+	request  []byte
+	response []byte
+}
+
+// withPayloadTrace adds a new payload trace to ctx.
+func withPayloadTrace(ctx context.Context) context.Context {
+	return context.WithValue(ctx, contextKeyPayloadTrace, &payloadTrace{})
+}
+
+// payloadTraceFromContext returns the payload trace of ctx, or nil if
+// tracing is disabled.
+func payloadTraceFromContext(ctx context.Context) *payloadTrace {
+	trace, _ := ctx.Value(contextKeyPayloadTrace).(*payloadTrace)
+	return trace
+}
+
+// recordRequest captures the body of req, replacing any earlier exchange.
+// Bodies that are not JSON or text, such as uploaded audio, are recorded
+// by type only.
+func (t *payloadTrace) recordRequest(req *http.Request) {
+	var body []byte
+	if req.GetBody != nil {
+		if contentType := req.Header.Get("Content-Type"); !tracedContentType(contentType) {
+			body = []byte(fmt.Sprintf("[%s body omitted]", contentType))
+		} else if r, err := req.GetBody(); err == nil {
+			body, _ = io.ReadAll(io.LimitReader(r, maxTracedPayload))
+			r.Close()
+		}
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.request = body
+	t.response = nil
+}
+
+// traceResponse makes resp capture its body as it is read.
+func (t *payloadTrace) traceResponse(resp *http.Response) {
+	if contentType := resp.Header.Get("Content-Type"); !tracedContentType(contentType) {
+		t.mu.Lock()
+		t.response = []byte(fmt.Sprintf("[%s body omitted]", contentType))
+		t.mu.Unlock()
+		return
+	}
+	resp.Body = &traceBody{ReadCloser: resp.Body, trace: t}
+}
+
+// payloads returns the captured request and response bodies with secrets
+// redacted.
+func (t *payloadTrace) payloads() (request, response string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return redactPayload(t.request), redactPayload(t.response)
+}
+
+// traceBody copies what is read from a response body into a trace.
+type traceBody struct {
+	io.ReadCloser
+	trace *payloadTrace
+}
+
+func (b *traceBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	if n > 0 {
+		b.trace.mu.Lock()
+		if room := maxTracedPayload - len(b.trace.response); room > 0 {
+			b.trace.response = append(b.trace.response, p[:min(n, room)]...)
+		}
+		b.trace.mu.Unlock()
+	}
+	return n, err
+}
+
+// tracedContentType reports whether bodies of contentType are captured.
+func tracedContentType(contentType string) bool {
+	return contentType == "" || strings.Contains(contentType, "json") ||
+		strings.HasPrefix(contentType, "text/")
+}
+
+// redactPayload replaces the values of credential fields in a JSON
+// payload.
+func redactPayload(payload []byte) string {
+	return string(secretField.ReplaceAll(payload, []byte(`${1}"[REDACTED]"`)))
+}
+
+// tracePayloads reports the payloads captured for a completion, if
+// payload tracing is enabled, in resp's HiddenParams and to the debug
+// callbacks.
+func (c *client) tracePayloads(ctx context.Context, provider, model string, resp *CompletionResponse, err error) {
+	trace := payloadTraceFromContext(ctx)
+	if trace == nil {
+		return
+	}
+	request, response := trace.payloads()
+
+	if resp != nil {
+		if resp.HiddenParams == nil {
+			resp.HiddenParams = make(map[string]any, 2)
+		}
+		resp.HiddenParams["_raw_request"] = request
+		resp.HiddenParams["_raw_response"] = response
+	}
+	if c.callbacks != nil {
+		c.callbacks.ExecuteDebug(ctx, &callback.DebugEvent{
+			RequestID:   RequestIDFromContext(ctx),
+			Model:       model,
+			Provider:    provider,
+			RawRequest:  request,
+			RawResponse: response,
+			Error:       err,
+			Timestamp:   c.now(),
+		})
+	}
+}
+
+// traceStream returns stream wrapped to report its payloads once it ends,
+// or stream itself if payload tracing is disabled.
+func (c *client) traceStream(ctx context.Context, stream Stream, provider, model string) Stream {
+	if payloadTraceFromContext(ctx) == nil {
+		return stream
+	}
+	return &payloadTraceStream{Stream: stream, ctx: ctx, client: c, provider: provider, model: model}
+}
+
+// payloadTraceStream reports the payloads of a stream when it ends.
+type payloadTraceStream struct {
+	Stream
+	ctx      context.Context
+	client   *client
+	provider string
+	model    string
+	once     sync.Once
+}
+
+func (s *payloadTraceStream) Recv() (*CompletionChunk, error) {
+	chunk, err := s.Stream.Recv()
+	if err != nil {
+		s.report(err)
+	}
+	return chunk, err
+}
+
+func (s *payloadTraceStream) Close() error {
+	err := s.Stream.Close()
+	s.report(nil)
+	return err
+}
+
+// report reports the payloads once.
+func (s *payloadTraceStream) report(err error) {
+	if err == io.EOF {
+		err = nil
+	}
+	s.once.Do(func() {
+		s.client.tracePayloads(s.ctx, s.provider, s.model, nil, err)
+	})
+}
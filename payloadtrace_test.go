@@ -0,0 +1,107 @@
+package warp
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/blue-context/warp/callback"
+)
+
+func TestPayloadTracing(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"id":"chatcmpl-1","choices":[]}`))
+	}))
+	defer server.Close()
+
+	var events []*callback.DebugEvent
+	client, err := NewClient(
+		WithPayloadTracing(true),
+		WithDebugCallback(func(ctx context.Context, event *callback.DebugEvent) {
+			events = append(events, event)
+		}),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	client.RegisterProvider(&mockProvider{
+		name: "mock",
+		completionFunc: func(ctx context.Context, req *CompletionRequest) (*CompletionResponse, error) {
+			body := []byte(`{"model":"model","api_key":"sk-secret","messages":[{"role":"user","content":"Hi"}]}`)
+			httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, server.URL, bytes.NewReader(body))
+			if err != nil {
+				return nil, err
+			}
+			httpReq.Header.Set("Content-Type", "application/json")
+			SetRequestHeaders(httpReq)
+			httpResp, err := server.Client().Do(httpReq)
+			if err != nil {
+				return nil, err
+			}
+			defer httpResp.Body.Close()
+			RecordResponse(httpResp)
+
+			var resp CompletionResponse
+			return &resp, json.NewDecoder(httpResp.Body).Decode(&resp)
+		},
+	})
+
+	resp, err := client.Completion(context.Background(), &CompletionRequest{
+		Model:    "mock/model",
+		Messages: []Message{{Role: "user", Content: "Hi"}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	wantRequest := `{"model":"model","api_key":"[REDACTED]","messages":[{"role":"user","content":"Hi"}]}`
+	wantResponse := `{"id":"chatcmpl-1","choices":[]}`
+	if got := resp.HiddenParams["_raw_request"]; got != wantRequest {
+		t.Errorf("_raw_request = %v, want %s", got, wantRequest)
+	}
+	if got := resp.HiddenParams["_raw_response"]; got != wantResponse {
+		t.Errorf("_raw_response = %v, want %s", got, wantResponse)
+	}
+	if len(events) != 1 || events[0].RawRequest != wantRequest || events[0].RawResponse != wantResponse {
+		t.Errorf("debug events = %+v, want one with the raw payloads", events)
+	}
+}
+
+func TestPayloadTracingDisabled(t *testing.T) {
+	client, err := NewClient()
+	if err != nil {
+		t.Fatal(err)
+	}
+	client.RegisterProvider(&mockProvider{name: "mock"})
+
+	resp, err := client.Completion(context.Background(), &CompletionRequest{
+		Model:    "mock/model",
+		Messages: []Message{{Role: "user", Content: "Hi"}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := resp.HiddenParams["_raw_request"]; ok {
+		t.Error("_raw_request set with payload tracing disabled")
+	}
+}
+
+func TestRedactPayload(t *testing.T) {
+	tests := []struct {
+		in, want string
+	}{
+		{`{"apiKey": "abc"}`, `{"apiKey": "[REDACTED]"}`},
+		{`{"access_token":"a\"b","x":1}`, `{"access_token":"[REDACTED]","x":1}`},
+		{`{"Authorization":"Bearer x"}`, `{"Authorization":"[REDACTED]"}`},
+		{`{"token":"Hello","max_tokens":5}`, `{"token":"Hello","max_tokens":5}`},
+	}
+	for _, tt := range tests {
+		if got := redactPayload([]byte(tt.in)); got != tt.want {
+			t.Errorf("redactPayload(%s) = %s, want %s", tt.in, got, tt.want)
+		}
+	}
+}
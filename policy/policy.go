@@ -0,0 +1,380 @@
+// Package policy enforces administrator-declared rules on completion requests.
+//
+// Rules restrict which providers, regions, and models a tenant may use, cap
+// the context size, and require moderation of requests carrying certain
+// tags. Requests that violate a rule fail before they are sent with a
+// *warp.PolicyViolationError.
+//
+// The tenant and tags of a request are read from its Metadata under
+// MetadataTenant and MetadataTags.
+//
+// Basic usage:
+//
+//	engine := policy.New(
+//	    policy.WithProviderRegion("azure-eu", "eu"),
+//	    policy.WithProviderRegion("openai", "us"),
+//	    policy.WithRule(policy.Rule{
+//	        Tenant:         "acme-gmbh",
+//	        AllowedRegions: []string{"eu"},
+//	    }),
+//	    policy.WithRule(policy.Rule{
+//	        BannedModels:     []string{"openai/gpt-3.5*"},
+//	        MaxContextTokens: 32000,
+//	    }),
+//	    policy.WithViolationHandler(auditLog.Violation),
+//	)
+//
+//	client, err := warp.NewClient(
+//	    warp.WithRequestMiddleware(engine.Enforce),
+//	)
+//
+//	_, err = client.Completion(ctx, &warp.CompletionRequest{
+//	    Model:    "openai/gpt-4o",
+//	    Messages: messages,
+//	    Metadata: map[string]any{policy.MetadataTenant: "acme-gmbh"},
+//	})
+//	var violation *warp.PolicyViolationError
+//	if errors.As(err, &violation) {
+//	    log.Printf("denied by %s", violation.Rule)
+//	}
+package policy
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"slices"
+	"strings"
+
+	"github.com/blue-context/warp"
+	"github.com/blue-context/warp/token"
+)
+
+// Request metadata keys read by the engine.
+const (
+	// MetadataTenant holds the tenant identifier (string).
+	MetadataTenant = "tenant"
+
+	// MetadataTags holds request tags ([]string or comma-separated string).
+	MetadataTags = "tags"
+)
+
+// Rule identifiers reported in PolicyViolationError.Rule.
+const (
+	RuleAllowedProviders = "allowed_providers"
+	RuleAllowedRegions   = "allowed_regions"
+	RuleBannedModels     = "banned_models"
+	RuleMaxContextTokens = "max_context_tokens"
+	RuleModeration       = "moderation"
+)
+
+// Rule is a set of restrictions. Empty fields impose no restriction.
+type Rule struct {
+	// Tenant limits the rule to one tenant. Empty applies it to all requests,
+	// including those without a tenant.
+	Tenant string
+
+	// AllowedProviders lists the providers the tenant may use.
+	AllowedProviders []string
+
+	// AllowedRegions lists the regions the tenant's requests may be
+	// processed in. Provider regions are declared with WithProviderRegion;
+	// providers without a declared region are denied.
+	AllowedRegions []string
+
+	// BannedModels lists forbidden models as "provider/model" patterns,
+	// matched with path.Match (e.g., "openai/gpt-3.5*" or "*/llama-2*").
+	BannedModels []string
+
+	// MaxContextTokens caps the estimated prompt size.
+	MaxContextTokens int
+
+	// ModerateTags lists request tags that require the prompt to pass
+	// moderation before it is sent. "*" requires moderation for all requests.
+	ModerateTags []string
+}
+
+// Moderator reports whether text violates a content policy.
+type Moderator func(ctx context.Context, text string) (flagged bool, err error)
+
+// Engine evaluates requests against rules.
+//
+// Thread Safety: Engine is safe for concurrent use once created.
+type Engine struct {
+	rules       []Rule
+	regions     map[string][]string
+	counter     token.Counter
+	moderator   Moderator
+	onViolation func(ctx context.Context, err *warp.PolicyViolationError)
+}
+
+// Option configures an Engine.
+type Option func(*Engine)
+
+// New creates a policy engine.
+func New(opts ...Option) *Engine {
+	e := &Engine{
+		regions: make(map[string][]string),
+		counter: token.NewCounter(),
+	}
+	for _, opt := range opts {
+		opt(e)
+	}
+	return e
+}
+
+// WithRule adds rules. Every rule that applies to a request must pass.
+func WithRule(rules ...Rule) Option {
+	return func(e *Engine) {
+		e.rules = append(e.rules, rules...)
+	}
+}
+
+// WithProviderRegion declares a region a registered provider processes
+// data in, for Rule.AllowedRegions. Declare each region of a multi-region
+// provider (see the provider/multiregion package); such a provider is
+// allowed if any of its regions is, and AllowedRegions restricts it to
+// those.
+func WithProviderRegion(provider, region string) Option {
+	return func(e *Engine) {
+		if !slices.Contains(e.regions[provider], region) {
+			e.regions[provider] = append(e.regions[provider], region)
+		}
+	}
+}
+
+// WithCounter sets the token counter for Rule.MaxContextTokens.
+//
+// The default is token.NewCounter().
+func WithCounter(counter token.Counter) Option {
+	return func(e *Engine) {
+		e.counter = counter
+	}
+}
+
+// WithModerator sets the moderator for Rule.ModerateTags. Without one,
+// requests that require moderation are denied.
+func WithModerator(m Moderator) Option {
+	return func(e *Engine) {
+		e.moderator = m
+	}
+}
+
+// WithViolationHandler sets a function called for every violation, e.g.,
+// audit.Logger.Violation.
+func WithViolationHandler(fn func(ctx context.Context, err *warp.PolicyViolationError)) Option {
+	return func(e *Engine) {
+		e.onViolation = fn
+	}
+}
+
+// ClientModerator returns a Moderator that calls client.Moderation with
+// model ("provider/model-name").
+//
+// Moderation requests do not pass through request middleware, so the
+// client the engine protects may be used once it has been created.
+func ClientModerator(client warp.Client, model string) Moderator {
+	return func(ctx context.Context, text string) (bool, error) {
+		resp, err := client.Moderation(ctx, &warp.ModerationRequest{Model: model, Input: text})
+		if err != nil {
+			return false, err
+		}
+		for _, result := range resp.Results {
+			if result.Flagged {
+				return true, nil
+			}
+		}
+		return false, nil
+	}
+}
+
+// Enforce is a warp.RequestMiddleware that rejects requests violating
+// policy. It never modifies the request.
+func (e *Engine) Enforce(ctx context.Context, req *warp.CompletionRequest) (*warp.CompletionRequest, error) {
+	if err := e.Check(ctx, req); err != nil {
+		return nil, err
+	}
+	return req, nil
+}
+
+// Check evaluates req against the rules that apply to its tenant.
+//
+// It returns a *warp.PolicyViolationError for the first violated rule, or
+// the moderator's error if moderation could not be performed.
+func (e *Engine) Check(ctx context.Context, req *warp.CompletionRequest) error {
+	tenant, _ := req.Metadata[MetadataTenant].(string)
+	providerName, modelName, _ := strings.Cut(req.Model, "/")
+
+	var violation *warp.PolicyViolationError
+	deny := func(rule, format string, args ...any) {
+		violation = warp.NewPolicyViolationError(fmt.Sprintf(format, args...), rule, tenant, providerName, modelName)
+	}
+
+	moderate := false
+	tags := requestTags(req.Metadata)
+
+	for _, rule := range e.rules {
+		if rule.Tenant != "" && rule.Tenant != tenant {
+			continue
+		}
+
+		switch {
+		case len(rule.AllowedProviders) > 0 && !slices.Contains(rule.AllowedProviders, providerName):
+			deny(RuleAllowedProviders, "provider %q is not allowed", providerName)
+		case len(rule.AllowedRegions) > 0 && !e.regionAllowed(rule.AllowedRegions, providerName):
+			if regions := e.regions[providerName]; len(regions) == 1 {
+				deny(RuleAllowedRegions, "provider %q region %q is not allowed", providerName, regions[0])
+			} else if len(regions) > 1 {
+				deny(RuleAllowedRegions, "provider %q regions %q are not allowed", providerName, regions)
+			} else {
+				deny(RuleAllowedRegions, "provider %q has no declared region", providerName)
+			}
+		case matchesAny(rule.BannedModels, req.Model):
+			deny(RuleBannedModels, "model %q is banned", req.Model)
+		case rule.MaxContextTokens > 0:
+			if tokens := e.counter.CountRequest(req); tokens > rule.MaxContextTokens {
+				deny(RuleMaxContextTokens, "request has ~%d tokens, limit is %d", tokens, rule.MaxContextTokens)
+			}
+		}
+		if violation != nil {
+			return e.violate(ctx, violation)
+		}
+
+		if !moderate {
+			for _, tag := range rule.ModerateTags {
+				if tag == "*" || slices.Contains(tags, tag) {
+					moderate = true
+					break
+				}
+			}
+		}
+	}
+
+	if moderate {
+		if e.moderator == nil {
+			deny(RuleModeration, "moderation is required but no moderator is configured")
+			return e.violate(ctx, violation)
+		}
+
+		flagged, err := e.moderator(ctx, promptText(req.Messages))
+		if err != nil {
+			return fmt.Errorf("policy moderation failed: %w", err)
+		}
+		if flagged {
+			deny(RuleModeration, "request was flagged by moderation")
+			return e.violate(ctx, violation)
+		}
+	}
+
+	return nil
+}
+
+// violate reports a violation to the handler and returns it.
+func (e *Engine) violate(ctx context.Context, err *warp.PolicyViolationError) error {
+	if e.onViolation != nil {
+		e.onViolation(ctx, err)
+	}
+	return err
+}
+
+// AllowedRegions returns the regions the tenant in metadata may use: the
+// intersection of the AllowedRegions of every rule that applies to it, or
+// nil if no rule restricts regions. Its signature matches
+// multiregion.RegionFilter, so a multi-region provider fails over only
+// between allowed regions.
+//
+// Example:
+//
+//	p, err := multiregion.New("azure", regions,
+//	    multiregion.WithRegionFilter(engine.AllowedRegions),
+//	)
+func (e *Engine) AllowedRegions(ctx context.Context, metadata map[string]any) []string {
+	tenant, _ := metadata[MetadataTenant].(string)
+
+	var allowed []string
+	restricted := false
+	for _, rule := range e.rules {
+		if (rule.Tenant != "" && rule.Tenant != tenant) || len(rule.AllowedRegions) == 0 {
+			continue
+		}
+		if !restricted {
+			allowed = append([]string{}, rule.AllowedRegions...)
+			restricted = true
+			continue
+		}
+		allowed = slices.DeleteFunc(allowed, func(region string) bool {
+			return !slices.Contains(rule.AllowedRegions, region)
+		})
+	}
+	return allowed
+}
+
+// regionAllowed reports whether any of the provider's declared regions is
+// allowed.
+func (e *Engine) regionAllowed(allowed []string, provider string) bool {
+	for _, region := range e.regions[provider] {
+		if slices.Contains(allowed, region) {
+			return true
+		}
+	}
+	return false
+}
+
+// matchesAny reports whether model matches any of the patterns.
+func matchesAny(patterns []string, model string) bool {
+	for _, pattern := range patterns {
+		if ok, _ := path.Match(pattern, model); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// requestTags reads MetadataTags as []string, []any, or a comma-separated
+// string.
+func requestTags(metadata map[string]any) []string {
+	switch v := metadata[MetadataTags].(type) {
+	case []string:
+		return v
+	case []any:
+		tags := make([]string, 0, len(v))
+		for _, tag := range v {
+			if s, ok := tag.(string); ok {
+				tags = append(tags, s)
+			}
+		}
+		return tags
+	case string:
+		var tags []string
+		for _, tag := range strings.Split(v, ",") {
+			if tag = strings.TrimSpace(tag); tag != "" {
+				tags = append(tags, tag)
+			}
+		}
+		return tags
+	}
+	return nil
+}
+
+// promptText joins the text of all non-assistant messages for moderation.
+func promptText(messages []warp.Message) string {
+	var sb strings.Builder
+	for _, msg := range messages {
+		if msg.Role == "assistant" {
+			continue
+		}
+		switch content := msg.Content.(type) {
+		case string:
+			sb.WriteString(content)
+			sb.WriteString("\n")
+		case []warp.ContentPart:
+			for _, part := range content {
+				if part.Type == "text" {
+					sb.WriteString(part.Text)
+					sb.WriteString("\n")
+				}
+			}
+		}
+	}
+	return strings.TrimSpace(sb.String())
+}
@@ -0,0 +1,199 @@
+package policy
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/blue-context/warp"
+	"github.com/blue-context/warp/internal/testutil"
+)
+
+func request(model string, metadata map[string]any) *warp.CompletionRequest {
+	return &warp.CompletionRequest{
+		Model:    model,
+		Messages: []warp.Message{{Role: "user", Content: "Hello there"}},
+		Metadata: metadata,
+	}
+}
+
+func TestCheck(t *testing.T) {
+	engine := New(
+		WithProviderRegion("azure-eu", "eu"),
+		WithProviderRegion("openai", "us"),
+		WithRule(
+			Rule{Tenant: "acme", AllowedRegions: []string{"eu"}},
+			Rule{Tenant: "globex", AllowedProviders: []string{"anthropic"}},
+			Rule{BannedModels: []string{"openai/gpt-3.5*", "*/llama-2*"}, MaxContextTokens: 50},
+		),
+	)
+
+	tests := []struct {
+		name     string
+		req      *warp.CompletionRequest
+		wantRule string
+	}{
+		{name: "no tenant allowed", req: request("openai/gpt-4o", nil)},
+		{name: "tenant region allowed", req: request("azure-eu/gpt-4o", map[string]any{MetadataTenant: "acme"})},
+		{name: "tenant region denied", req: request("openai/gpt-4o", map[string]any{MetadataTenant: "acme"}), wantRule: RuleAllowedRegions},
+		{name: "undeclared region denied", req: request("groq/llama-3", map[string]any{MetadataTenant: "acme"}), wantRule: RuleAllowedRegions},
+		{name: "provider denied", req: request("openai/gpt-4o", map[string]any{MetadataTenant: "globex"}), wantRule: RuleAllowedProviders},
+		{name: "provider allowed", req: request("anthropic/claude", map[string]any{MetadataTenant: "globex"})},
+		{name: "banned model", req: request("openai/gpt-3.5-turbo", nil), wantRule: RuleBannedModels},
+		{name: "banned model glob provider", req: request("together/llama-2-70b", nil), wantRule: RuleBannedModels},
+		{name: "context too large", req: &warp.CompletionRequest{
+			Model:    "openai/gpt-4o",
+			Messages: []warp.Message{{Role: "user", Content: strings.Repeat("word ", 200)}},
+		}, wantRule: RuleMaxContextTokens},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := engine.Check(context.Background(), tt.req)
+			if tt.wantRule == "" {
+				if err != nil {
+					t.Errorf("Check() error = %v, want nil", err)
+				}
+				return
+			}
+
+			var violation *warp.PolicyViolationError
+			if !errors.As(err, &violation) {
+				t.Fatalf("Check() error = %v, want PolicyViolationError", err)
+			}
+			if violation.Rule != tt.wantRule {
+				t.Errorf("Rule = %q, want %q", violation.Rule, tt.wantRule)
+			}
+			tenant, _ := tt.req.Metadata[MetadataTenant].(string)
+			if violation.Tenant != tenant {
+				t.Errorf("Tenant = %q, want %q", violation.Tenant, tenant)
+			}
+		})
+	}
+}
+
+func TestModeration(t *testing.T) {
+	var moderated []string
+	moderator := func(ctx context.Context, text string) (bool, error) {
+		moderated = append(moderated, text)
+		if strings.Contains(text, "bad") {
+			return true, nil
+		}
+		if strings.Contains(text, "down") {
+			return false, errors.New("moderation unavailable")
+		}
+		return false, nil
+	}
+	rule := WithRule(Rule{ModerateTags: []string{"public"}})
+
+	tagged := func(text string) *warp.CompletionRequest {
+		req := request("openai/gpt-4o", map[string]any{MetadataTags: "internal, public"})
+		req.Messages[0].Content = text
+		return req
+	}
+
+	engine := New(rule, WithModerator(moderator))
+	if err := engine.Check(context.Background(), request("openai/gpt-4o", nil)); err != nil || len(moderated) != 0 {
+		t.Errorf("untagged request: err = %v, moderated = %v", err, moderated)
+	}
+	if err := engine.Check(context.Background(), tagged("fine")); err != nil || len(moderated) != 1 {
+		t.Errorf("clean request: err = %v, moderated = %v", err, moderated)
+	}
+
+	var violation *warp.PolicyViolationError
+	if err := engine.Check(context.Background(), tagged("bad")); !errors.As(err, &violation) || violation.Rule != RuleModeration {
+		t.Errorf("flagged request error = %v", err)
+	}
+	if err := engine.Check(context.Background(), tagged("down")); err == nil || errors.As(err, &violation) {
+		t.Errorf("moderator error = %v, want non-violation error", err)
+	}
+
+	engine = New(rule)
+	if err := engine.Check(context.Background(), tagged("fine")); !errors.As(err, &violation) || violation.Rule != RuleModeration {
+		t.Errorf("missing moderator error = %v", err)
+	}
+}
+
+func TestEnforceMiddleware(t *testing.T) {
+	var reported []*warp.PolicyViolationError
+	engine := New(
+		WithRule(Rule{AllowedProviders: []string{"mock"}}),
+		WithViolationHandler(func(ctx context.Context, err *warp.PolicyViolationError) {
+			reported = append(reported, err)
+		}),
+	)
+
+	client, err := warp.NewClient(warp.WithRequestMiddleware(engine.Enforce))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+
+	mock := &testutil.MockProvider{}
+	client.RegisterProvider(mock)
+
+	if _, err := client.Completion(context.Background(), request("mock/m", nil)); err != nil {
+		t.Errorf("allowed request error = %v", err)
+	}
+
+	_, err = client.Completion(context.Background(), request("openai/gpt-4o", nil))
+	var violation *warp.PolicyViolationError
+	if !errors.As(err, &violation) || violation.Provider != "openai" || violation.Model != "gpt-4o" {
+		t.Errorf("denied request error = %v", err)
+	}
+	if len(reported) != 1 || reported[0] != violation {
+		t.Errorf("reported = %v", reported)
+	}
+	if mock.CompletionCalls != 1 {
+		t.Errorf("provider called %d times, want 1", mock.CompletionCalls)
+	}
+}
+
+func TestMultiRegionProvider(t *testing.T) {
+	engine := New(
+		WithProviderRegion("azure", "eu"),
+		WithProviderRegion("azure", "us"),
+		WithRule(
+			Rule{Tenant: "acme", AllowedRegions: []string{"eu"}},
+			Rule{Tenant: "initech", AllowedRegions: []string{"apac"}},
+		),
+	)
+
+	if err := engine.Check(context.Background(), request("azure/gpt-4o", map[string]any{MetadataTenant: "acme"})); err != nil {
+		t.Errorf("Check() error = %v, want nil when one region is allowed", err)
+	}
+	var violation *warp.PolicyViolationError
+	err := engine.Check(context.Background(), request("azure/gpt-4o", map[string]any{MetadataTenant: "initech"}))
+	if !errors.As(err, &violation) || violation.Rule != RuleAllowedRegions {
+		t.Errorf("Check() error = %v, want allowed_regions violation", err)
+	}
+}
+
+func TestAllowedRegions(t *testing.T) {
+	engine := New(WithRule(
+		Rule{AllowedRegions: []string{"eu", "us"}},
+		Rule{Tenant: "acme", AllowedRegions: []string{"eu", "apac"}},
+		Rule{Tenant: "globex", BannedModels: []string{"*/gpt-3.5*"}},
+	))
+
+	tests := []struct {
+		tenant string
+		want   []string
+	}{
+		{"", []string{"eu", "us"}},
+		{"acme", []string{"eu"}},
+		{"globex", []string{"eu", "us"}},
+	}
+	for _, tt := range tests {
+		got := engine.AllowedRegions(context.Background(), map[string]any{MetadataTenant: tt.tenant})
+		if !slices.Equal(got, tt.want) {
+			t.Errorf("AllowedRegions(%q) = %v, want %v", tt.tenant, got, tt.want)
+		}
+	}
+
+	if got := New().AllowedRegions(context.Background(), nil); got != nil {
+		t.Errorf("AllowedRegions() without rules = %v, want nil", got)
+	}
+}
@@ -0,0 +1,175 @@
+package warp
+
+import (
+	"encoding/json"
+	"fmt"
+	"maps"
+	"regexp"
+	"slices"
+)
+
+// toolNamePattern matches the function names providers accept.
+var toolNamePattern = regexp.MustCompile(`^[a-zA-Z0-9_-]{1,64}$`)
+
+// PreparedRequest is a completion request whose static parts, the system
+// prompt, tools and response format, are validated and encoded once, so
+// that services sending many requests with the same setup only pay for the
+// messages that change. Create one with PrepareRequest.
+//
+// Thread Safety: PreparedRequest is safe for concurrent use. The tool
+// schemas of the prepared request must not be modified.
+type PreparedRequest struct {
+	base   CompletionRequest
+	system []Message
+}
+
+// PrepareRequest validates req and encodes its tools and response format
+// for reuse. req holds the static parts of the request: its messages must
+// all be system or developer messages, which start every request built
+// from it.
+//
+// It returns an error if the model is malformed, a message is not a system
+// message, a tool is invalid (not a function, a missing, malformed or
+// duplicate name, or parameters that are not an object schema), or the
+// response format is invalid.
+//
+// Example:
+//
+//	prepared, err := warp.PrepareRequest(&warp.CompletionRequest{
+//	    Model:    "openai/gpt-4o",
+//	    Messages: []warp.Message{{Role: "system", Content: supportPrompt}},
+//	    Tools:    supportTools,
+//	})
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+//
+//	// Per request
+//	resp, err := client.Completion(ctx, prepared.Request(
+//	    warp.Message{Role: "user", Content: question},
+//	))
+func PrepareRequest(req *CompletionRequest) (*PreparedRequest, error) {
+	if req == nil {
+		return nil, fmt.Errorf("request cannot be nil")
+	}
+	if req.Model != "" {
+		if _, _, err := parseModel(req.Model); err != nil {
+			return nil, err
+		}
+	}
+	for i, msg := range req.Messages {
+		if msg.Role != "system" && msg.Role != "developer" {
+			return nil, fmt.Errorf("message %d: prepared requests hold only system messages, got %q", i, msg.Role)
+		}
+	}
+
+	p := &PreparedRequest{base: *req, system: slices.Clone(req.Messages)}
+	p.base.Messages = nil
+
+	if len(req.Tools) > 0 {
+		tools, err := prepareTools(req.Tools)
+		if err != nil {
+			return nil, err
+		}
+		p.base.Tools = tools
+	}
+
+	if req.ResponseFormat != nil {
+		format, err := prepareResponseFormat(req.ResponseFormat)
+		if err != nil {
+			return nil, err
+		}
+		p.base.ResponseFormat = format
+	}
+
+	return p, nil
+}
+
+// Request returns a new request with the prepared system messages followed
+// by messages. The request can be modified without affecting the prepared
+// request or other requests built from it, except for the tools and
+// response format, which are shared and must not be modified.
+func (p *PreparedRequest) Request(messages ...Message) *CompletionRequest {
+	req := p.base
+	req.Messages = make([]Message, 0, len(p.system)+len(messages))
+	req.Messages = append(req.Messages, p.system...)
+	req.Messages = append(req.Messages, messages...)
+	req.ExtraBody = maps.Clone(p.base.ExtraBody)
+	req.Metadata = maps.Clone(p.base.Metadata)
+	return &req
+}
+
+// prepareTools validates tools and returns a copy with each tool's
+// encoding cached.
+func prepareTools(tools []Tool) ([]Tool, error) {
+	prepared := slices.Clone(tools)
+	names := make(map[string]bool, len(tools))
+	for i := range prepared {
+		tool := &prepared[i]
+		if tool.Type != "function" {
+			return nil, fmt.Errorf("tool %d: unsupported type %q", i, tool.Type)
+		}
+		name := tool.Function.Name
+		if !toolNamePattern.MatchString(name) {
+			return nil, fmt.Errorf("tool %d: invalid function name %q", i, name)
+		}
+		if names[name] {
+			return nil, fmt.Errorf("tool %d: duplicate function name %q", i, name)
+		}
+		names[name] = true
+		if params := tool.Function.Parameters; params != nil && params["type"] != "object" {
+			return nil, fmt.Errorf("tool %q: parameters must be an object schema", name)
+		}
+
+		tool.encoded = nil
+		encoded, err := json.Marshal(tool)
+		if err != nil {
+			return nil, fmt.Errorf("tool %q: %w", name, err)
+		}
+		tool.encoded = encoded
+	}
+	return prepared, nil
+}
+
+// prepareResponseFormat validates format and returns a copy with its
+// encoding cached.
+func prepareResponseFormat(format *ResponseFormat) (*ResponseFormat, error) {
+	switch format.Type {
+	case "text", "json_object":
+	case "json_schema":
+		if format.JSONSchema == nil || format.JSONSchema.Name == "" || format.JSONSchema.Schema == nil {
+			return nil, fmt.Errorf("response format json_schema requires a named schema")
+		}
+	default:
+		return nil, fmt.Errorf("unsupported response format type %q", format.Type)
+	}
+
+	prepared := *format
+	prepared.encoded = nil
+	encoded, err := json.Marshal(&prepared)
+	if err != nil {
+		return nil, fmt.Errorf("response format: %w", err)
+	}
+	prepared.encoded = encoded
+	return &prepared, nil
+}
+
+// MarshalJSON encodes the tool, reusing the encoding cached by
+// PrepareRequest.
+func (t *Tool) MarshalJSON() ([]byte, error) {
+	if t.encoded != nil {
+		return t.encoded, nil
+	}
+	type plain Tool
+	return json.Marshal((*plain)(t))
+}
+
+// MarshalJSON encodes the response format, reusing the encoding cached by
+// PrepareRequest.
+func (f *ResponseFormat) MarshalJSON() ([]byte, error) {
+	if f.encoded != nil {
+		return f.encoded, nil
+	}
+	type plain ResponseFormat
+	return json.Marshal((*plain)(f))
+}
@@ -0,0 +1,177 @@
+package warp
+
+import (
+	"context"
+	"encoding/json"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func preparedTestRequest() *CompletionRequest {
+	return &CompletionRequest{
+		Model:    "openai/gpt-4o",
+		Messages: []Message{{Role: "system", Content: "You are a support agent."}},
+		Tools: []Tool{{
+			Type: "function",
+			Function: Function{
+				Name: "lookup_order",
+				Parameters: map[string]any{
+					"type":       "object",
+					"properties": map[string]any{"id": map[string]any{"type": "string"}},
+				},
+			},
+		}},
+		ResponseFormat: &ResponseFormat{Type: "json_object"},
+		ExtraBody:      map[string]any{"user": "svc"},
+	}
+}
+
+func TestPrepareRequest(t *testing.T) {
+	original := preparedTestRequest()
+	prepared, err := PrepareRequest(original)
+	if err != nil {
+		t.Fatalf("PrepareRequest() error = %v", err)
+	}
+
+	req := prepared.Request(Message{Role: "user", Content: "Where is order 42?"})
+	if len(req.Messages) != 2 || req.Messages[0].Role != "system" || req.Messages[1].Content != "Where is order 42?" {
+		t.Fatalf("Messages = %+v, want the system prompt then the user message", req.Messages)
+	}
+	if req.Model != "openai/gpt-4o" || len(req.Tools) != 1 || req.ResponseFormat.Type != "json_object" {
+		t.Errorf("request = %+v, want the prepared static parts", req)
+	}
+
+	// Requests are independent of each other and of the prepared request
+	req.Messages[0].Content = "changed"
+	req.ExtraBody["user"] = "changed"
+	other := prepared.Request(Message{Role: "user", Content: "hi"})
+	if other.Messages[0].Content != "You are a support agent." || other.ExtraBody["user"] != "svc" {
+		t.Errorf("request = %+v, modified by another request", other)
+	}
+	if len(original.Messages) != 1 {
+		t.Errorf("original messages = %+v, want unchanged", original.Messages)
+	}
+}
+
+func TestPrepareRequest_Encoding(t *testing.T) {
+	prepared, err := PrepareRequest(preparedTestRequest())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The cached encoding is what encoding the request would produce
+	got, err := json.Marshal(prepared.Request(Message{Role: "user", Content: "hi"}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	plain := preparedTestRequest()
+	plain.Messages = append(plain.Messages, Message{Role: "user", Content: "hi"})
+	want, err := json.Marshal(plain)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(got) != string(want) {
+		t.Errorf("encoding = %s\nwant %s", got, want)
+	}
+}
+
+func TestPrepareRequest_Validation(t *testing.T) {
+	tool := func(name string, params map[string]any) Tool {
+		return Tool{Type: "function", Function: Function{Name: name, Parameters: params}}
+	}
+	tests := []struct {
+		name    string
+		modify  func(*CompletionRequest)
+		wantErr string
+	}{
+		{"malformed model", func(r *CompletionRequest) { r.Model = "gpt-4o" }, "provider/model"},
+		{"user message", func(r *CompletionRequest) {
+			r.Messages = append(r.Messages, Message{Role: "user", Content: "hi"})
+		}, "only system messages"},
+		{"tool type", func(r *CompletionRequest) { r.Tools[0].Type = "retrieval" }, "unsupported type"},
+		{"tool name", func(r *CompletionRequest) { r.Tools = []Tool{tool("look up", nil)} }, "invalid function name"},
+		{"duplicate tool", func(r *CompletionRequest) { r.Tools = []Tool{tool("a", nil), tool("a", nil)} }, "duplicate"},
+		{"tool parameters", func(r *CompletionRequest) {
+			r.Tools = []Tool{tool("a", map[string]any{"type": "string"})}
+		}, "object schema"},
+		{"unencodable schema", func(r *CompletionRequest) {
+			r.Tools = []Tool{tool("a", map[string]any{"type": "object", "x": func() {}})}
+		}, "tool \"a\""},
+		{"response format type", func(r *CompletionRequest) { r.ResponseFormat = &ResponseFormat{Type: "xml"} }, "unsupported response format"},
+		{"json schema", func(r *CompletionRequest) {
+			r.ResponseFormat = &ResponseFormat{Type: "json_schema", JSONSchema: &JSONSchema{Name: "x"}}
+		}, "named schema"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := preparedTestRequest()
+			tt.modify(req)
+			_, err := PrepareRequest(req)
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("PrepareRequest() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+
+	if _, err := PrepareRequest(nil); err == nil {
+		t.Error("PrepareRequest(nil) error = nil")
+	}
+}
+
+func TestPrepareRequest_Completion(t *testing.T) {
+	var sent *CompletionRequest
+	client, err := NewClient()
+	if err != nil {
+		t.Fatal(err)
+	}
+	client.RegisterProvider(&mockProvider{
+		name: "openai",
+		completionFunc: func(ctx context.Context, req *CompletionRequest) (*CompletionResponse, error) {
+			sent = req
+			return &CompletionResponse{Choices: []Choice{{Message: Message{Role: "assistant", Content: "ok"}}}}, nil
+		},
+	})
+
+	prepared, err := PrepareRequest(preparedTestRequest())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := client.Completion(context.Background(), prepared.Request(Message{Role: "user", Content: "hi"})); err != nil {
+		t.Fatalf("Completion() error = %v", err)
+	}
+	if sent.Model != "gpt-4o" || len(sent.Messages) != 2 || len(sent.Tools) != 1 {
+		t.Errorf("provider request = %+v", sent)
+	}
+}
+
+func BenchmarkPreparedRequestEncoding(b *testing.B) {
+	req := preparedTestRequest()
+	for i := 0; i < 20; i++ {
+		t := req.Tools[0]
+		t.Function.Name = "tool_" + strings.Repeat("x", i)
+		req.Tools = append(req.Tools, t)
+	}
+	req.Tools = req.Tools[1:]
+	prepared, err := PrepareRequest(req)
+	if err != nil {
+		b.Fatal(err)
+	}
+	msg := Message{Role: "user", Content: "hi"}
+
+	b.Run("Plain", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			r := *req
+			r.Messages = append(slices.Clip(req.Messages), msg)
+			json.Marshal(&r)
+		}
+	})
+	b.Run("Prepared", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			json.Marshal(prepared.Request(msg))
+		}
+	})
+}
@@ -0,0 +1,223 @@
+package warp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+)
+
+// probePixel is a 1x1 red PNG used to probe vision support.
+const probePixel = "data:image/png;base64,iVBORw0KGgoAAAANSUhEUgAAAAEAAAABCAYAAAAfFcSJAAAADUlEQVR42mP8z8DwHwAFBQIAX8jx0gAAAABJRU5ErkJggg=="
+
+// ProbeResult records the features an endpoint was observed to support.
+//
+// A false value means the probe request failed or the response did not show
+// the feature; Failures holds the reason.
+type ProbeResult struct {
+	// Model is the probed model in "provider/model-name" format.
+	Model string
+
+	// Tools reports whether the model returned a tool call when forced to.
+	Tools bool
+
+	// JSONMode reports whether json_object response format returned valid JSON.
+	JSONMode bool
+
+	// Logprobs reports whether token log probabilities were returned.
+	Logprobs bool
+
+	// Vision reports whether an image input was accepted.
+	Vision bool
+
+	// Failures maps each unsupported feature ("tools", "json_mode",
+	// "logprobs", "vision") to the reason its probe failed.
+	Failures map[string]string
+
+	// ProbedAt is when the probe completed.
+	ProbedAt time.Time
+}
+
+// probe is a single feature test.
+type probe struct {
+	feature string
+	req     CompletionRequest
+	check   func(resp *CompletionResponse) error
+}
+
+// ProbeCapabilities empirically tests which features an endpoint supports
+// by issuing tiny requests to model ("provider/model-name").
+//
+// It first sends a plain completion; if that fails the endpoint is
+// unusable and the error is returned. It then probes tool calling, JSON
+// mode, logprobs, and vision concurrently. Probes bypass the cache,
+// callbacks, middleware, and retries, and each costs a few tokens.
+//
+// The result is recorded in the client and returned by ProbedCapabilities
+// until the model is probed again. This is useful for OpenAI-compatible
+// gateways whose feature sets vary by deployment.
+//
+// Example:
+//
+//	result, err := client.ProbeCapabilities(ctx, "gateway/llama-3.1-70b")
+//	if err != nil {
+//	    return err
+//	}
+//	if !result.Tools {
+//	    log.Printf("tools unsupported: %s", result.Failures["tools"])
+//	}
+func (c *client) ProbeCapabilities(ctx context.Context, model string) (*ProbeResult, error) {
+	providerName, modelName, err := parseModel(model)
+	if err != nil {
+		return nil, err
+	}
+	p, err := c.getProvider(providerName)
+	if err != nil {
+		return nil, fmt.Errorf("provider %q not found (did you register it?)", providerName)
+	}
+
+	if c.config.DefaultTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = c.withTimeout(ctx, c.config.DefaultTimeout)
+		defer cancel()
+	}
+
+	base := CompletionRequest{
+		Model:       modelName,
+		Temperature: Float64Ptr(0),
+		MaxTokens:   IntPtr(16),
+	}
+	call := func(req CompletionRequest) (*CompletionResponse, error) {
+		resp, err := c.providerCompletion(ctx, p, &req)
+		if err != nil {
+			return nil, err
+		}
+		if len(resp.Choices) == 0 {
+			return nil, fmt.Errorf("response has no choices")
+		}
+		return resp, nil
+	}
+
+	plain := base
+	plain.Messages = []Message{{Role: "user", Content: "Reply with OK."}}
+	if _, err := call(plain); err != nil {
+		return nil, fmt.Errorf("probe request failed: %w", err)
+	}
+
+	probes := probeRequests(base)
+	result := &ProbeResult{Model: model, Failures: make(map[string]string)}
+	supported := make([]bool, len(probes))
+	failures := make([]string, len(probes))
+
+	var wg sync.WaitGroup
+	for i, pr := range probes {
+		wg.Add(1)
+		go func(i int, pr probe) {
+			defer wg.Done()
+			resp, err := call(pr.req)
+			if err == nil {
+				err = pr.check(resp)
+			}
+			if err != nil {
+				failures[i] = err.Error()
+				return
+			}
+			supported[i] = true
+		}(i, pr)
+	}
+	wg.Wait()
+
+	for i, pr := range probes {
+		if !supported[i] {
+			result.Failures[pr.feature] = failures[i]
+		}
+		switch pr.feature {
+		case "tools":
+			result.Tools = supported[i]
+		case "json_mode":
+			result.JSONMode = supported[i]
+		case "logprobs":
+			result.Logprobs = supported[i]
+		case "vision":
+			result.Vision = supported[i]
+		}
+	}
+	result.ProbedAt = c.now()
+
+	root := c.root()
+	root.mu.Lock()
+	if root.probed == nil {
+		root.probed = make(map[string]*ProbeResult)
+	}
+	root.probed[model] = result
+	root.mu.Unlock()
+
+	return result, nil
+}
+
+// ProbedCapabilities returns the last ProbeCapabilities result for model,
+// or false if it has not been probed.
+func (c *client) ProbedCapabilities(model string) (*ProbeResult, bool) {
+	root := c.root()
+	root.mu.RLock()
+	defer root.mu.RUnlock()
+
+	result, ok := root.probed[model]
+	return result, ok
+}
+
+// probeRequests builds the feature probes from a base request.
+func probeRequests(base CompletionRequest) []probe {
+	clock := Function{
+		Name:        "get_time",
+		Description: "Returns the current time.",
+		Parameters:  map[string]any{"type": "object", "properties": map[string]any{}},
+	}
+
+	tools := base
+	tools.Messages = []Message{{Role: "user", Content: "What time is it? Use the get_time tool."}}
+	tools.Tools = []Tool{{Type: "function", Function: clock}}
+	tools.ToolChoice = &ToolChoice{Type: "function", Function: &Function{Name: clock.Name}}
+
+	jsonMode := base
+	jsonMode.Messages = []Message{{Role: "user", Content: `Reply with the JSON object {"ok": true}.`}}
+	jsonMode.ResponseFormat = &ResponseFormat{Type: "json_object"}
+
+	logprobs := base
+	logprobs.Messages = []Message{{Role: "user", Content: "Reply with OK."}}
+	logprobs.ExtraBody = map[string]any{"logprobs": true, "top_logprobs": 1}
+
+	vision := base
+	vision.Messages = []Message{{Role: "user", Content: []ContentPart{
+		{Type: "text", Text: "What color is this image? Answer in one word."},
+		{Type: "image_url", ImageURL: &ImageURL{URL: probePixel}},
+	}}}
+
+	return []probe{
+		{feature: "tools", req: tools, check: func(resp *CompletionResponse) error {
+			if len(resp.Choices[0].Message.ToolCalls) == 0 {
+				return fmt.Errorf("no tool call in response")
+			}
+			return nil
+		}},
+		{feature: "json_mode", req: jsonMode, check: func(resp *CompletionResponse) error {
+			content, _ := resp.Choices[0].Message.Content.(string)
+			var obj map[string]any
+			if err := json.Unmarshal([]byte(strings.TrimSpace(content)), &obj); err != nil {
+				return fmt.Errorf("response is not a JSON object: %w", err)
+			}
+			return nil
+		}},
+		{feature: "logprobs", req: logprobs, check: func(resp *CompletionResponse) error {
+			if lp := resp.Choices[0].Logprobs; lp == nil || len(lp.Content) == 0 {
+				return fmt.Errorf("no logprobs in response")
+			}
+			return nil
+		}},
+		{feature: "vision", req: vision, check: func(resp *CompletionResponse) error {
+			return nil
+		}},
+	}
+}
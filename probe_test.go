@@ -0,0 +1,106 @@
+package warp
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+)
+
+// gatewayMock simulates an endpoint that supports tools and JSON mode but
+// rejects images and ignores logprobs.
+func gatewayMock() *mockProvider {
+	return &mockProvider{
+		name: "gateway",
+		completionFunc: func(ctx context.Context, req *CompletionRequest) (*CompletionResponse, error) {
+			if req.Model != "llama" {
+				return nil, errors.New("unexpected model " + req.Model)
+			}
+			if _, ok := req.Messages[0].Content.([]ContentPart); ok {
+				return nil, &WarpError{Message: "image input not supported", Provider: "gateway"}
+			}
+			msg := Message{Role: "assistant", Content: "OK"}
+			if req.ToolChoice != nil {
+				msg.ToolCalls = []ToolCall{{ID: "1", Type: "function", Function: FunctionCall{Name: "get_time", Arguments: "{}"}}}
+			}
+			if req.ResponseFormat != nil {
+				msg.Content = `{"ok": true}`
+			}
+			return &CompletionResponse{Choices: []Choice{{Message: msg}}}, nil
+		},
+	}
+}
+
+// TestProbeCapabilities tests feature detection and recording
+func TestProbeCapabilities(t *testing.T) {
+	client, err := NewClient()
+	if err != nil {
+		t.Fatalf("NewClient() error = %v", err)
+	}
+	defer client.Close()
+	if err := client.RegisterProvider(gatewayMock()); err != nil {
+		t.Fatalf("RegisterProvider() error = %v", err)
+	}
+
+	if _, ok := client.ProbedCapabilities("gateway/llama"); ok {
+		t.Error("ProbedCapabilities() before probing returned a result")
+	}
+
+	result, err := client.ProbeCapabilities(context.Background(), "gateway/llama")
+	if err != nil {
+		t.Fatalf("ProbeCapabilities() error = %v", err)
+	}
+
+	if !result.Tools || !result.JSONMode || result.Logprobs || result.Vision {
+		t.Errorf("result = %+v, want tools and JSON mode only", result)
+	}
+	if !strings.Contains(result.Failures["vision"], "image input not supported") {
+		t.Errorf("vision failure = %q", result.Failures["vision"])
+	}
+	if result.Failures["logprobs"] != "no logprobs in response" {
+		t.Errorf("logprobs failure = %q", result.Failures["logprobs"])
+	}
+	if _, ok := result.Failures["tools"]; ok {
+		t.Error("supported feature has a failure entry")
+	}
+	if result.ProbedAt.IsZero() {
+		t.Error("ProbedAt not set")
+	}
+
+	recorded, ok := client.ProbedCapabilities("gateway/llama")
+	if !ok || recorded != result {
+		t.Errorf("ProbedCapabilities() = %v, %v", recorded, ok)
+	}
+}
+
+// TestProbeCapabilitiesErrors tests unusable endpoints
+func TestProbeCapabilitiesErrors(t *testing.T) {
+	client, err := NewClient()
+	if err != nil {
+		t.Fatalf("NewClient() error = %v", err)
+	}
+	defer client.Close()
+
+	down := &mockProvider{
+		name: "down",
+		completionFunc: func(ctx context.Context, req *CompletionRequest) (*CompletionResponse, error) {
+			return nil, errors.New("connection refused")
+		},
+	}
+	if err := client.RegisterProvider(down); err != nil {
+		t.Fatalf("RegisterProvider() error = %v", err)
+	}
+
+	if _, err := client.ProbeCapabilities(context.Background(), "down/model"); err == nil || !strings.Contains(err.Error(), "connection refused") {
+		t.Errorf("ProbeCapabilities() error = %v, want connection refused", err)
+	}
+	if _, ok := client.ProbedCapabilities("down/model"); ok {
+		t.Error("failed probe was recorded")
+	}
+	if _, err := client.ProbeCapabilities(context.Background(), "missing/model"); err == nil {
+		t.Error("ProbeCapabilities() with unknown provider succeeded")
+	}
+	if _, err := client.ProbeCapabilities(context.Background(), "no-slash"); err == nil {
+		t.Error("ProbeCapabilities() with invalid model succeeded")
+	}
+}
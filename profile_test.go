@@ -0,0 +1,52 @@
+package warp
+
+import (
+	"os"
+	"os/exec"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// minimalProfilePackages are the packages that compile in the minimal
+// build profile.
+var minimalProfilePackages = []string{".", "./sse", "./provider/providercore", "./provider/openaicompat"}
+
+// minimalProfileExcluded are packages the minimal build profile must not
+// depend on.
+var minimalProfileExcluded = []string{"flag", "log/slog", "os/exec", "runtime/debug", "testing"}
+
+func TestMinimalProfile(t *testing.T) {
+	if testing.Short() {
+		t.Skip("builds for WebAssembly")
+	}
+	goTool, err := exec.LookPath("go")
+	if err != nil {
+		t.Skip("go command not found")
+	}
+
+	for _, goos := range []string{"js", "wasip1"} {
+		t.Run(goos, func(t *testing.T) {
+			env := append(os.Environ(), "GOOS="+goos, "GOARCH=wasm", "CGO_ENABLED=0")
+
+			list := exec.Command(goTool, append([]string{"list", "-deps", "-tags", "warp_minimal"}, minimalProfilePackages...)...)
+			list.Env = env
+			out, err := list.Output()
+			if err != nil {
+				t.Fatalf("go list error = %v", err)
+			}
+			deps := strings.Fields(string(out))
+			for _, excluded := range minimalProfileExcluded {
+				if slices.Contains(deps, excluded) {
+					t.Errorf("minimal profile depends on %s", excluded)
+				}
+			}
+
+			build := exec.Command(goTool, append([]string{"build", "-tags", "warp_minimal"}, minimalProfilePackages...)...)
+			build.Env = env
+			if out, err := build.CombinedOutput(); err != nil {
+				t.Errorf("go build error = %v\n%s", err, out)
+			}
+		})
+	}
+}
@@ -0,0 +1,244 @@
+// Package promptcontext injects standard context into system prompts.
+//
+// Models do not know the current date, the user's timezone, or their
+// locale. The Injector renders this context with a template and adds it to
+// the system prompt of every completion request, so each service does not
+// have to.
+//
+// Client-wide defaults are set with options; individual requests override
+// them through Metadata (MetadataTimezone, MetadataLocale, MetadataValues,
+// MetadataDisable).
+//
+// Basic usage:
+//
+//	injector, err := promptcontext.New(
+//	    promptcontext.WithLocation(time.UTC),
+//	    promptcontext.WithValue("Product", "Acme Support"),
+//	)
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+//
+//	client, err := warp.NewClient(
+//	    warp.WithRequestMiddleware(injector.Inject),
+//	)
+//
+//	resp, err := client.Completion(ctx, &warp.CompletionRequest{
+//	    Model:    "openai/gpt-4o",
+//	    Messages: messages,
+//	    Metadata: map[string]any{
+//	        promptcontext.MetadataTimezone: "Europe/Berlin",
+//	        promptcontext.MetadataLocale:   "de-DE",
+//	    },
+//	})
+package promptcontext
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/blue-context/warp"
+)
+
+// Request metadata keys read by the Injector.
+const (
+	// MetadataTimezone overrides the timezone with an IANA name (string),
+	// e.g., "America/New_York".
+	MetadataTimezone = "timezone"
+
+	// MetadataLocale overrides the locale (string), e.g., "fr-CA".
+	MetadataLocale = "locale"
+
+	// MetadataValues adds or overrides template values
+	// (map[string]string or map[string]any).
+	MetadataValues = "prompt_context"
+
+	// MetadataDisable skips injection for the request when true (bool).
+	MetadataDisable = "prompt_context_disabled"
+)
+
+// DefaultTemplate renders the date, time, timezone, locale, and values.
+const DefaultTemplate = `Current date and time: {{.Now.Format "Monday, January 2, 2006 15:04"}} ({{.Timezone}})
+{{- if .Locale}}
+User locale: {{.Locale}}
+{{- end}}
+{{- range $key, $value := .Values}}
+{{$key}}: {{$value}}
+{{- end}}`
+
+// Data is the template data.
+type Data struct {
+	// Now is the current time in the request's timezone.
+	Now time.Time
+
+	// Timezone is the IANA timezone name.
+	Timezone string
+
+	// Locale is the user's locale, or empty if unknown.
+	Locale string
+
+	// Values holds custom key-value context, iterated in key order.
+	Values map[string]string
+}
+
+// Injector adds rendered context to completion request system prompts.
+//
+// Thread Safety: Injector is safe for concurrent use once created.
+type Injector struct {
+	text     string
+	tmpl     *template.Template
+	location *time.Location
+	locale   string
+	values   map[string]string
+	now      func() time.Time
+}
+
+// Option configures an Injector.
+type Option func(*Injector)
+
+// New creates an Injector. It returns an error if the template is invalid.
+func New(opts ...Option) (*Injector, error) {
+	i := &Injector{
+		text:     DefaultTemplate,
+		location: time.Local,
+		values:   make(map[string]string),
+		now:      time.Now,
+	}
+	for _, opt := range opts {
+		opt(i)
+	}
+
+	tmpl, err := template.New("promptcontext").Option("missingkey=zero").Parse(i.text)
+	if err != nil {
+		return nil, fmt.Errorf("invalid prompt context template: %w", err)
+	}
+	i.tmpl = tmpl
+
+	return i, nil
+}
+
+// WithTemplate sets the text/template used to render context, executed
+// with Data. The default is DefaultTemplate.
+//
+// Example:
+//
+//	promptcontext.WithTemplate(`Today is {{.Now.Format "2006-01-02"}}.`)
+func WithTemplate(text string) Option {
+	return func(i *Injector) {
+		i.text = text
+	}
+}
+
+// WithLocation sets the default timezone. The default is time.Local.
+func WithLocation(loc *time.Location) Option {
+	return func(i *Injector) {
+		i.location = loc
+	}
+}
+
+// WithLocale sets the default locale.
+func WithLocale(locale string) Option {
+	return func(i *Injector) {
+		i.locale = locale
+	}
+}
+
+// WithValue adds a default template value.
+func WithValue(key, value string) Option {
+	return func(i *Injector) {
+		i.values[key] = value
+	}
+}
+
+// WithClock sets the function returning the current time, for testing.
+func WithClock(now func() time.Time) Option {
+	return func(i *Injector) {
+		i.now = now
+	}
+}
+
+// Inject is a warp.RequestMiddleware that adds rendered context to the
+// request's system prompt.
+//
+// The context is appended to the first message if it is a system message,
+// or added as a new leading system message otherwise. The request is not
+// modified; a copy is returned.
+func (i *Injector) Inject(ctx context.Context, req *warp.CompletionRequest) (*warp.CompletionRequest, error) {
+	if disabled, _ := req.Metadata[MetadataDisable].(bool); disabled {
+		return req, nil
+	}
+
+	text, err := i.Render(req.Metadata)
+	if err != nil {
+		return nil, err
+	}
+	if text == "" {
+		return req, nil
+	}
+
+	r := *req
+	r.Messages = make([]warp.Message, 0, len(req.Messages)+1)
+
+	if len(req.Messages) > 0 && req.Messages[0].Role == "system" {
+		system := req.Messages[0]
+		switch content := system.Content.(type) {
+		case string:
+			system.Content = content + "\n\n" + text
+		case []warp.ContentPart:
+			parts := make([]warp.ContentPart, 0, len(content)+1)
+			parts = append(parts, content...)
+			system.Content = append(parts, warp.ContentPart{Type: "text", Text: text})
+		default:
+			system.Content = text
+		}
+		r.Messages = append(r.Messages, system)
+		r.Messages = append(r.Messages, req.Messages[1:]...)
+	} else {
+		r.Messages = append(r.Messages, warp.Message{Role: "system", Content: text})
+		r.Messages = append(r.Messages, req.Messages...)
+	}
+
+	return &r, nil
+}
+
+// Render returns the context text for a request with the given metadata.
+func (i *Injector) Render(metadata map[string]any) (string, error) {
+	loc := i.location
+	if name, _ := metadata[MetadataTimezone].(string); name != "" {
+		l, err := time.LoadLocation(name)
+		if err != nil {
+			return "", fmt.Errorf("invalid timezone %q: %w", name, err)
+		}
+		loc = l
+	}
+
+	data := Data{
+		Now:      i.now().In(loc),
+		Timezone: loc.String(),
+		Locale:   i.locale,
+		Values:   maps.Clone(i.values),
+	}
+	if locale, _ := metadata[MetadataLocale].(string); locale != "" {
+		data.Locale = locale
+	}
+	switch values := metadata[MetadataValues].(type) {
+	case map[string]string:
+		for k, v := range values {
+			data.Values[k] = v
+		}
+	case map[string]any:
+		for k, v := range values {
+			data.Values[k] = fmt.Sprint(v)
+		}
+	}
+
+	var sb strings.Builder
+	if err := i.tmpl.Execute(&sb, data); err != nil {
+		return "", fmt.Errorf("failed to render prompt context: %w", err)
+	}
+	return strings.TrimSpace(sb.String()), nil
+}
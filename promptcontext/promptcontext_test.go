@@ -0,0 +1,155 @@
+package promptcontext
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/blue-context/warp"
+)
+
+var fixed = time.Date(2024, 3, 15, 14, 30, 0, 0, time.UTC)
+
+func clock() time.Time {
+	return fixed
+}
+
+func TestRender(t *testing.T) {
+	injector, err := New(WithClock(clock), WithLocation(time.UTC), WithValue("Product", "Acme"))
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		metadata map[string]any
+		want     string
+		wantErr  bool
+	}{
+		{
+			name: "defaults",
+			want: "Current date and time: Friday, March 15, 2024 14:30 (UTC)\nProduct: Acme",
+		},
+		{
+			name: "request overrides",
+			metadata: map[string]any{
+				MetadataTimezone: "Asia/Tokyo",
+				MetadataLocale:   "ja-JP",
+				MetadataValues:   map[string]any{"Product": "Acme JP", "Plan": "pro"},
+			},
+			want: "Current date and time: Friday, March 15, 2024 23:30 (Asia/Tokyo)\nUser locale: ja-JP\nPlan: pro\nProduct: Acme JP",
+		},
+		{
+			name:     "invalid timezone",
+			metadata: map[string]any{MetadataTimezone: "Mars/Olympus"},
+			wantErr:  true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := injector.Render(tt.metadata)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Render() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("Render() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+
+	// Request values must not leak into defaults
+	if got, _ := injector.Render(nil); strings.Contains(got, "Plan") {
+		t.Errorf("Render() after override = %q", got)
+	}
+}
+
+func TestInject(t *testing.T) {
+	injector, err := New(WithClock(clock), WithTemplate(`Today is {{.Now.Format "2006-01-02"}}.`))
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	user := warp.Message{Role: "user", Content: "Hi"}
+	tests := []struct {
+		name     string
+		messages []warp.Message
+		metadata map[string]any
+		want     []warp.Message
+	}{
+		{
+			name:     "no system message",
+			messages: []warp.Message{user},
+			want:     []warp.Message{{Role: "system", Content: "Today is 2024-03-15."}, user},
+		},
+		{
+			name:     "string system message",
+			messages: []warp.Message{{Role: "system", Content: "Be brief."}, user},
+			want:     []warp.Message{{Role: "system", Content: "Be brief.\n\nToday is 2024-03-15."}, user},
+		},
+		{
+			name:     "multipart system message",
+			messages: []warp.Message{{Role: "system", Content: []warp.ContentPart{{Type: "text", Text: "Be brief."}}}, user},
+			want: []warp.Message{{Role: "system", Content: []warp.ContentPart{
+				{Type: "text", Text: "Be brief."},
+				{Type: "text", Text: "Today is 2024-03-15."},
+			}}, user},
+		},
+		{
+			name:     "disabled",
+			messages: []warp.Message{user},
+			metadata: map[string]any{MetadataDisable: true},
+			want:     []warp.Message{user},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := &warp.CompletionRequest{Model: "openai/gpt-4o", Messages: tt.messages, Metadata: tt.metadata}
+			original := tt.messages[0].Content
+
+			got, err := injector.Inject(context.Background(), req)
+			if err != nil {
+				t.Fatalf("Inject() error = %v", err)
+			}
+			if len(got.Messages) != len(tt.want) {
+				t.Fatalf("Inject() messages = %+v, want %+v", got.Messages, tt.want)
+			}
+			for i := range tt.want {
+				if got.Messages[i].Role != tt.want[i].Role || !equalContent(got.Messages[i].Content, tt.want[i].Content) {
+					t.Errorf("message %d = %+v, want %+v", i, got.Messages[i], tt.want[i])
+				}
+			}
+			if !equalContent(req.Messages[0].Content, original) || len(req.Messages) != len(tt.messages) {
+				t.Error("Inject() modified the original request")
+			}
+		})
+	}
+}
+
+func TestNewInvalidTemplate(t *testing.T) {
+	if _, err := New(WithTemplate("{{.Now")); err == nil {
+		t.Error("New() with invalid template succeeded")
+	}
+}
+
+func equalContent(a, b any) bool {
+	ap, aok := a.([]warp.ContentPart)
+	bp, bok := b.([]warp.ContentPart)
+	if aok != bok {
+		return false
+	}
+	if !aok {
+		return a == b
+	}
+	if len(ap) != len(bp) {
+		return false
+	}
+	for i := range ap {
+		if ap[i].Type != bp[i].Type || ap[i].Text != bp[i].Text {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,101 @@
+package warp
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math"
+)
+
+// WarningPromptLogprobsUnavailable is the warning code for requests that
+// asked for prompt logprobs from a provider that returned none.
+const WarningPromptLogprobsUnavailable = "prompt_logprobs_unavailable"
+
+// PromptLogprobs are the log probabilities of the prompt tokens of a
+// completion, in prompt order. The first prompt token has no log
+// probability, since nothing precedes it, and is not included.
+//
+// It decodes both its own encoding, a list of TokenLogprob, and vLLM's
+// prompt_logprobs format, a list of null or maps from token ID to
+// {"logprob", "rank", "decoded_token"} whose first entry is the prompt
+// token, so OpenAI-compatible responses from vLLM decode directly.
+//
+// Example:
+//
+//	resp, err := client.Completion(ctx, &warp.CompletionRequest{
+//	    Model:          "vllm/meta-llama/Llama-3.1-8B",
+//	    Messages:       []warp.Message{{Role: "user", Content: text}},
+//	    MaxTokens:      warp.IntPtr(1),
+//	    PromptLogprobs: true,
+//	})
+//	if err != nil {
+//	    return err
+//	}
+//	fmt.Printf("perplexity: %.2f\n", resp.PromptLogprobs.Perplexity())
+type PromptLogprobs []TokenLogprob
+
+// Perplexity returns the perplexity of the tokens, the exponential of
+// their negative mean log probability. It returns 0 if there are none.
+func (p PromptLogprobs) Perplexity() float64 {
+	if len(p) == 0 {
+		return 0
+	}
+	var sum float64
+	for _, t := range p {
+		sum += t.Logprob
+	}
+	return math.Exp(-sum / float64(len(p)))
+}
+
+// UnmarshalJSON decodes prompt logprobs in either supported format.
+func (p *PromptLogprobs) UnmarshalJSON(data []byte) error {
+	var entries []json.RawMessage
+	if err := json.Unmarshal(data, &entries); err != nil {
+		return err
+	}
+
+	out := make(PromptLogprobs, 0, len(entries))
+	for i, entry := range entries {
+		if bytes.Equal(entry, []byte("null")) {
+			continue
+		}
+		token, err := decodePromptLogprob(entry)
+		if err != nil {
+			return fmt.Errorf("prompt logprob %d: %w", i, err)
+		}
+		out = append(out, token)
+	}
+	*p = out
+	return nil
+}
+
+// decodePromptLogprob decodes one prompt token, either a TokenLogprob or a
+// vLLM map of candidate tokens whose first entry is the prompt token.
+func decodePromptLogprob(data []byte) (TokenLogprob, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	if tok, err := dec.Token(); err != nil || tok != json.Delim('{') {
+		return TokenLogprob{}, fmt.Errorf("expected an object")
+	}
+	key, err := dec.Token()
+	if err != nil {
+		return TokenLogprob{}, err
+	}
+
+	switch key {
+	case json.Delim('}'):
+		return TokenLogprob{}, fmt.Errorf("no tokens")
+	case "token", "logprob", "bytes":
+		var token TokenLogprob
+		err := json.Unmarshal(data, &token)
+		return token, err
+	}
+
+	var candidate struct {
+		Logprob      float64 `json:"logprob"`
+		DecodedToken string  `json:"decoded_token"`
+	}
+	if err := dec.Decode(&candidate); err != nil {
+		return TokenLogprob{}, err
+	}
+	return TokenLogprob{Token: candidate.DecodedToken, Logprob: candidate.Logprob}, nil
+}
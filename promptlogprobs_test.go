@@ -0,0 +1,84 @@
+package warp
+
+import (
+	"context"
+	"encoding/json"
+	"math"
+	"reflect"
+	"testing"
+
+	"github.com/blue-context/warp/callback"
+)
+
+func TestPromptLogprobsUnmarshal(t *testing.T) {
+	want := PromptLogprobs{{Token: " world", Logprob: -2}, {Token: "!", Logprob: -0.5}}
+	tests := []struct {
+		name string
+		data string
+	}{
+		{"own format", `[{"token":" world","logprob":-2},{"token":"!","logprob":-0.5}]`},
+		{"vllm format", `[null, {"1917": {"logprob": -2, "rank": 3, "decoded_token": " world"}}, {"0": {"logprob": -0.5, "rank": 1, "decoded_token": "!"}, "13": {"logprob": -1.2, "rank": 2, "decoded_token": "."}}]`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got PromptLogprobs
+			if err := json.Unmarshal([]byte(tt.data), &got); err != nil {
+				t.Fatalf("Unmarshal() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("PromptLogprobs = %+v, want %+v", got, want)
+			}
+		})
+	}
+
+	var got PromptLogprobs
+	if err := json.Unmarshal([]byte(`[{}]`), &got); err == nil {
+		t.Error("Unmarshal() of an empty entry error = nil")
+	}
+}
+
+func TestPromptLogprobsPerplexity(t *testing.T) {
+	if got := PromptLogprobs(nil).Perplexity(); got != 0 {
+		t.Errorf("Perplexity() of no tokens = %v, want 0", got)
+	}
+	p := PromptLogprobs{{Logprob: math.Log(0.5)}, {Logprob: math.Log(0.125)}}
+	if got := p.Perplexity(); math.Abs(got-4) > 1e-9 {
+		t.Errorf("Perplexity() = %v, want 4", got)
+	}
+}
+
+func TestPromptLogprobsWarning(t *testing.T) {
+	var warnings []string
+	client, err := NewClient(WithWarningCallback(func(ctx context.Context, event *callback.WarningEvent) {
+		warnings = append(warnings, event.Code)
+	}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var logprobs PromptLogprobs
+	client.RegisterProvider(&mockProvider{
+		name: "mock",
+		completionFunc: func(ctx context.Context, req *CompletionRequest) (*CompletionResponse, error) {
+			return &CompletionResponse{
+				Choices:        []Choice{{Message: Message{Role: "assistant", Content: "x"}}},
+				PromptLogprobs: logprobs,
+			}, nil
+		},
+	})
+	req := &CompletionRequest{Model: "mock/model", Messages: []Message{{Role: "user", Content: "Hi"}}, PromptLogprobs: true}
+
+	if _, err := client.Completion(context.Background(), req); err != nil {
+		t.Fatal(err)
+	}
+	if len(warnings) != 1 || warnings[0] != WarningPromptLogprobsUnavailable {
+		t.Errorf("warnings = %v, want %s", warnings, WarningPromptLogprobsUnavailable)
+	}
+
+	logprobs = PromptLogprobs{{Token: "Hi", Logprob: -1}}
+	if _, err := client.Completion(context.Background(), req); err != nil {
+		t.Fatal(err)
+	}
+	if len(warnings) != 1 {
+		t.Errorf("warnings = %v, want none for a response with prompt logprobs", warnings)
+	}
+}
@@ -0,0 +1,244 @@
+// Package promptstore manages versioned prompts outside application code.
+//
+// Prompts are fetched from a Store by name and version, so they can be
+// changed without redeploying. A Library adds version pinning and canary
+// rollouts on top of a Store, and tags each request with the prompt name
+// and version so callbacks can attribute results to the prompt that
+// produced them.
+//
+// Two stores are provided: FileStore reads "<dir>/<name>/<version>.txt"
+// files, and HTTPStore fetches prompts from a prompt service.
+//
+// Basic usage:
+//
+//	lib := promptstore.NewLibrary(promptstore.NewFileStore("prompts"))
+//	lib.SetRollout("support-agent", promptstore.Rollout{
+//	    Stable:        "3",
+//	    Canary:        "4",
+//	    CanaryPercent: 10,
+//	})
+//
+//	req := &warp.CompletionRequest{Model: "openai/gpt-4o", Messages: messages}
+//	err := lib.Apply(ctx, req, "support-agent", userID, map[string]any{"Product": "Acme"})
+//
+// In a callback:
+//
+//	name, version, ok := promptstore.FromRequest(event.Request)
+package promptstore
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"strings"
+	"sync"
+	"text/template"
+
+	"github.com/blue-context/warp"
+)
+
+// Request metadata keys set by Tag.
+const (
+	MetadataPromptName    = "prompt_name"
+	MetadataPromptVersion = "prompt_version"
+)
+
+// Latest requests the newest version of a prompt from a Store.
+const Latest = ""
+
+// ErrNotFound is returned by stores when a prompt or version does not exist.
+var ErrNotFound = errors.New("prompt not found")
+
+// Prompt is a versioned prompt template.
+type Prompt struct {
+	// Name identifies the prompt.
+	Name string `json:"name"`
+
+	// Version identifies this revision of the prompt.
+	Version string `json:"version"`
+
+	// Template is the prompt text, a text/template.
+	Template string `json:"template"`
+}
+
+// Render executes the prompt template with data.
+func (p *Prompt) Render(data any) (string, error) {
+	tmpl, err := template.New(p.Name).Option("missingkey=error").Parse(p.Template)
+	if err != nil {
+		return "", fmt.Errorf("prompt %s@%s: invalid template: %w", p.Name, p.Version, err)
+	}
+
+	var sb strings.Builder
+	if err := tmpl.Execute(&sb, data); err != nil {
+		return "", fmt.Errorf("prompt %s@%s: %w", p.Name, p.Version, err)
+	}
+	return sb.String(), nil
+}
+
+// Store retrieves prompts.
+//
+// Thread Safety: Implementations must be safe for concurrent use.
+type Store interface {
+	// Get returns the given version of the named prompt, or the newest
+	// version if version is Latest. It returns an error wrapping
+	// ErrNotFound if the prompt or version does not exist.
+	Get(ctx context.Context, name, version string) (*Prompt, error)
+}
+
+// Rollout splits traffic for a prompt between a stable and a canary version.
+type Rollout struct {
+	// Stable is the version served to most traffic. Latest serves the
+	// newest version.
+	Stable string
+
+	// Canary is the version under evaluation.
+	Canary string
+
+	// CanaryPercent is the share of traffic (0-100) served Canary.
+	CanaryPercent float64
+}
+
+// Library resolves prompts through pins and rollouts.
+//
+// Resolution order for a name: a pinned version, then a rollout, then the
+// newest version in the store.
+//
+// Thread Safety: Library is safe for concurrent use.
+type Library struct {
+	store Store
+
+	mu       sync.RWMutex
+	pins     map[string]string
+	rollouts map[string]Rollout
+}
+
+// NewLibrary creates a library backed by store.
+func NewLibrary(store Store) *Library {
+	return &Library{
+		store:    store,
+		pins:     make(map[string]string),
+		rollouts: make(map[string]Rollout),
+	}
+}
+
+// Pin serves version for name regardless of rollouts, e.g., to roll back.
+func (l *Library) Pin(name, version string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.pins[name] = version
+}
+
+// Unpin removes a pin set by Pin.
+func (l *Library) Unpin(name string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	delete(l.pins, name)
+}
+
+// SetRollout configures a canary rollout for name.
+func (l *Library) SetRollout(name string, rollout Rollout) error {
+	if rollout.CanaryPercent < 0 || rollout.CanaryPercent > 100 {
+		return fmt.Errorf("canary percent must be between 0 and 100, got %v", rollout.CanaryPercent)
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.rollouts[name] = rollout
+	return nil
+}
+
+// ClearRollout removes the rollout for name.
+func (l *Library) ClearRollout(name string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	delete(l.rollouts, name)
+}
+
+// Version returns the version that would be served for name and key,
+// without fetching it.
+//
+// key assigns requests to the canary deterministically, so a user (or
+// session) sees the same version on every request. An empty key is
+// assigned to the stable version.
+func (l *Library) Version(name, key string) string {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+
+	if version, ok := l.pins[name]; ok {
+		return version
+	}
+	rollout, ok := l.rollouts[name]
+	if !ok {
+		return Latest
+	}
+	if key != "" && bucket(name, key) < rollout.CanaryPercent {
+		return rollout.Canary
+	}
+	return rollout.Stable
+}
+
+// Get returns the prompt served for name and key.
+func (l *Library) Get(ctx context.Context, name, key string) (*Prompt, error) {
+	return l.store.Get(ctx, name, l.Version(name, key))
+}
+
+// Apply renders the prompt served for name and key with data, inserts it
+// as the request's leading system message (replacing an existing one), and
+// tags the request with the prompt name and version.
+func (l *Library) Apply(ctx context.Context, req *warp.CompletionRequest, name, key string, data any) error {
+	prompt, err := l.Get(ctx, name, key)
+	if err != nil {
+		return err
+	}
+	text, err := prompt.Render(data)
+	if err != nil {
+		return err
+	}
+
+	system := warp.Message{Role: "system", Content: text}
+	messages := make([]warp.Message, 0, len(req.Messages)+1)
+	messages = append(messages, system)
+	if len(req.Messages) > 0 && req.Messages[0].Role == "system" {
+		messages = append(messages, req.Messages[1:]...)
+	} else {
+		messages = append(messages, req.Messages...)
+	}
+	req.Messages = messages
+
+	Tag(req, prompt)
+	return nil
+}
+
+// Tag records the prompt name and version in the request metadata, where
+// callbacks can read them with FromRequest.
+func Tag(req *warp.CompletionRequest, prompt *Prompt) {
+	metadata := make(map[string]any, len(req.Metadata)+2)
+	for k, v := range req.Metadata {
+		metadata[k] = v
+	}
+	metadata[MetadataPromptName] = prompt.Name
+	metadata[MetadataPromptVersion] = prompt.Version
+	req.Metadata = metadata
+}
+
+// FromRequest returns the prompt name and version recorded by Tag. It
+// accepts the Request field of callback events.
+func FromRequest(req any) (name, version string, ok bool) {
+	r, _ := req.(*warp.CompletionRequest)
+	if r == nil {
+		return "", "", false
+	}
+	name, _ = r.Metadata[MetadataPromptName].(string)
+	version, _ = r.Metadata[MetadataPromptVersion].(string)
+	return name, version, name != ""
+}
+
+// bucket maps name and key to a stable value in [0, 100).
+func bucket(name, key string) float64 {
+	h := fnv.New64a()
+	h.Write([]byte(name))
+	h.Write([]byte{0})
+	h.Write([]byte(key))
+	return float64(h.Sum64()%10000) / 100
+}
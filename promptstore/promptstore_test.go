@@ -0,0 +1,239 @@
+package promptstore
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/blue-context/warp"
+)
+
+// mockHTTPClient is a mock HTTP client for testing
+type mockHTTPClient struct {
+	doFunc func(req *http.Request) (*http.Response, error)
+}
+
+func (m *mockHTTPClient) Do(req *http.Request) (*http.Response, error) {
+	return m.doFunc(req)
+}
+
+func writePrompts(t *testing.T, prompts map[string]string) string {
+	t.Helper()
+	dir := t.TempDir()
+	for path, text := range prompts {
+		full := filepath.Join(dir, path)
+		if err := os.MkdirAll(filepath.Dir(full), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(full, []byte(text), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dir
+}
+
+func TestFileStore(t *testing.T) {
+	dir := writePrompts(t, map[string]string{
+		"support/v2.txt":  "Version two",
+		"support/v10.txt": "Version ten",
+		"support/v9.txt":  "Version nine",
+		"support/notes":   "ignored",
+	})
+	store := NewFileStore(dir)
+	ctx := context.Background()
+
+	p, err := store.Get(ctx, "support", Latest)
+	if err != nil || p.Version != "v10" || p.Template != "Version ten" {
+		t.Errorf("Get(latest) = %+v, %v", p, err)
+	}
+	p, err = store.Get(ctx, "support", "v9")
+	if err != nil || p.Template != "Version nine" {
+		t.Errorf("Get(v9) = %+v, %v", p, err)
+	}
+
+	for _, tt := range []struct{ name, version string }{
+		{"support", "v3"},
+		{"missing", Latest},
+	} {
+		if _, err := store.Get(ctx, tt.name, tt.version); !errors.Is(err, ErrNotFound) {
+			t.Errorf("Get(%s, %s) error = %v, want ErrNotFound", tt.name, tt.version, err)
+		}
+	}
+	if _, err := store.Get(ctx, "..", "x"); err == nil || errors.Is(err, ErrNotFound) {
+		t.Errorf("Get(..) error = %v, want invalid name", err)
+	}
+	if _, err := store.Get(ctx, "support", "../support/v2"); err == nil {
+		t.Error("Get() with path in version succeeded")
+	}
+}
+
+func TestCompareVersions(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want int
+	}{
+		{"1", "2", -1},
+		{"v10", "v9", 1},
+		{"1.10", "1.9", 1},
+		{"1.2", "1.2.1", -1},
+		{"2024-06-01", "2024-06-01", 0},
+		{"1.0-beta", "1.0-rc", -1},
+	}
+	for _, tt := range tests {
+		got := compareVersions(tt.a, tt.b)
+		if (got < 0) != (tt.want < 0) || (got > 0) != (tt.want > 0) {
+			t.Errorf("compareVersions(%q, %q) = %d, want sign %d", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
+
+func TestHTTPStore(t *testing.T) {
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	var paths []string
+	latest := "2"
+	client := &mockHTTPClient{doFunc: func(req *http.Request) (*http.Response, error) {
+		paths = append(paths, req.URL.Path)
+		if req.Header.Get("Authorization") != "Bearer t" {
+			t.Errorf("Authorization = %q", req.Header.Get("Authorization"))
+		}
+		status, body := http.StatusOK, ""
+		switch req.URL.Path {
+		case "/prompts/greet/latest":
+			body = fmt.Sprintf(`{"version":%q,"template":"Hello v%s"}`, latest, latest)
+		case "/prompts/greet/1":
+			body = `{"template":"Hello v1"}`
+		case "/prompts/broken/1":
+			status, body = http.StatusInternalServerError, "boom"
+		default:
+			status = http.StatusNotFound
+		}
+		return &http.Response{StatusCode: status, Body: io.NopCloser(strings.NewReader(body))}, nil
+	}}
+
+	store := NewHTTPStore("http://host/prompts/", WithHTTPClient(client), WithHeader("Authorization", "Bearer t"), WithCacheTTL(time.Minute))
+	store.now = func() time.Time { return now }
+	ctx := context.Background()
+
+	p, err := store.Get(ctx, "greet", Latest)
+	if err != nil || p.Name != "greet" || p.Version != "2" || p.Template != "Hello v2" {
+		t.Fatalf("Get(latest) = %+v, %v", p, err)
+	}
+	p, err = store.Get(ctx, "greet", "1")
+	if err != nil || p.Version != "1" || p.Template != "Hello v1" {
+		t.Fatalf("Get(1) = %+v, %v", p, err)
+	}
+
+	// Cached responses
+	latest = "3"
+	store.Get(ctx, "greet", Latest)
+	store.Get(ctx, "greet", "1")
+	if len(paths) != 2 {
+		t.Errorf("requests = %v, want 2 (cached)", paths)
+	}
+
+	// Latest expires, pinned versions do not
+	now = now.Add(2 * time.Minute)
+	if p, _ := store.Get(ctx, "greet", Latest); p.Version != "3" {
+		t.Errorf("Get(latest) after TTL = %+v, want version 3", p)
+	}
+	store.Get(ctx, "greet", "1")
+	if len(paths) != 3 {
+		t.Errorf("requests = %v, want 3", paths)
+	}
+
+	if _, err := store.Get(ctx, "missing", "1"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Get(missing) error = %v, want ErrNotFound", err)
+	}
+	if _, err := store.Get(ctx, "broken", "1"); err == nil || !strings.Contains(err.Error(), "boom") {
+		t.Errorf("Get(broken) error = %v", err)
+	}
+}
+
+func TestLibraryRollout(t *testing.T) {
+	dir := writePrompts(t, map[string]string{
+		"agent/1.txt": "Stable for {{.Product}}",
+		"agent/2.txt": "Canary for {{.Product}}",
+		"agent/3.txt": "Newest",
+	})
+	lib := NewLibrary(NewFileStore(dir))
+
+	if v := lib.Version("agent", "user-1"); v != Latest {
+		t.Errorf("Version() without rollout = %q, want latest", v)
+	}
+
+	if err := lib.SetRollout("agent", Rollout{Stable: "1", Canary: "2", CanaryPercent: 150}); err == nil {
+		t.Error("SetRollout() with 150% succeeded")
+	}
+	if err := lib.SetRollout("agent", Rollout{Stable: "1", Canary: "2", CanaryPercent: 25}); err != nil {
+		t.Fatalf("SetRollout() error = %v", err)
+	}
+
+	canary := 0
+	for i := 0; i < 1000; i++ {
+		key := fmt.Sprintf("user-%d", i)
+		v := lib.Version("agent", key)
+		if v != lib.Version("agent", key) {
+			t.Fatalf("Version(%q) is not sticky", key)
+		}
+		if v == "2" {
+			canary++
+		}
+	}
+	if canary < 180 || canary > 320 {
+		t.Errorf("canary share = %d/1000, want ~250", canary)
+	}
+	if v := lib.Version("agent", ""); v != "1" {
+		t.Errorf("Version() with empty key = %q, want stable", v)
+	}
+
+	lib.Pin("agent", "3")
+	if v := lib.Version("agent", "user-1"); v != "3" {
+		t.Errorf("Version() when pinned = %q, want 3", v)
+	}
+	lib.Unpin("agent")
+	lib.ClearRollout("agent")
+	if v := lib.Version("agent", "user-1"); v != Latest {
+		t.Errorf("Version() after clear = %q, want latest", v)
+	}
+}
+
+func TestLibraryApply(t *testing.T) {
+	dir := writePrompts(t, map[string]string{"agent/1.txt": "You support {{.Product}}."})
+	lib := NewLibrary(NewFileStore(dir))
+	ctx := context.Background()
+
+	metadata := map[string]any{"team": "search"}
+	req := &warp.CompletionRequest{
+		Model:    "openai/gpt-4o",
+		Messages: []warp.Message{{Role: "system", Content: "old"}, {Role: "user", Content: "Hi"}},
+		Metadata: metadata,
+	}
+	if err := lib.Apply(ctx, req, "agent", "user-1", map[string]any{"Product": "Acme"}); err != nil {
+		t.Fatalf("Apply() error = %v", err)
+	}
+
+	if len(req.Messages) != 2 || req.Messages[0].Content != "You support Acme." || req.Messages[1].Content != "Hi" {
+		t.Errorf("Messages = %+v", req.Messages)
+	}
+	name, version, ok := FromRequest(req)
+	if !ok || name != "agent" || version != "1" || req.Metadata["team"] != "search" {
+		t.Errorf("FromRequest() = %q, %q, %v; metadata = %v", name, version, ok, req.Metadata)
+	}
+	if _, ok := metadata[MetadataPromptName]; ok {
+		t.Error("Apply() modified the caller's metadata map")
+	}
+
+	if err := lib.Apply(ctx, req, "agent", "", map[string]any{}); err == nil {
+		t.Error("Apply() with missing template value succeeded")
+	}
+	if _, _, ok := FromRequest("not a request"); ok {
+		t.Error("FromRequest() of non-request succeeded")
+	}
+}
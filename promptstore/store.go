@@ -0,0 +1,294 @@
+package promptstore
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/blue-context/warp"
+)
+
+// FileStore reads prompts from a directory tree laid out as
+// "<dir>/<name>/<version>.txt".
+//
+// Files are read on every Get, so edits take effect immediately. The newest
+// version is the highest by version order, comparing numeric segments
+// numerically ("v10" > "v9", "1.10" > "1.9").
+//
+// Thread Safety: FileStore is safe for concurrent use.
+type FileStore struct {
+	dir string
+}
+
+// Compile-time interface check
+var _ Store = (*FileStore)(nil)
+
+// NewFileStore creates a store reading prompts from dir.
+func NewFileStore(dir string) *FileStore {
+	return &FileStore{dir: dir}
+}
+
+// Get reads a prompt version, or the newest version for Latest.
+func (s *FileStore) Get(ctx context.Context, name, version string) (*Prompt, error) {
+	if err := validName(name); err != nil {
+		return nil, err
+	}
+
+	if version == Latest {
+		versions, err := s.Versions(name)
+		if err != nil {
+			return nil, err
+		}
+		version = versions[len(versions)-1]
+	} else if err := validName(version); err != nil {
+		return nil, err
+	}
+
+	data, err := os.ReadFile(filepath.Join(s.dir, name, version+".txt"))
+	if os.IsNotExist(err) {
+		return nil, fmt.Errorf("%w: %s@%s", ErrNotFound, name, version)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read prompt %s@%s: %w", name, version, err)
+	}
+
+	return &Prompt{Name: name, Version: version, Template: string(data)}, nil
+}
+
+// Versions lists the versions of a prompt, oldest first.
+func (s *FileStore) Versions(name string) ([]string, error) {
+	if err := validName(name); err != nil {
+		return nil, err
+	}
+
+	entries, err := os.ReadDir(filepath.Join(s.dir, name))
+	if os.IsNotExist(err) {
+		return nil, fmt.Errorf("%w: %s", ErrNotFound, name)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to list prompt %s: %w", name, err)
+	}
+
+	var versions []string
+	for _, entry := range entries {
+		if version, ok := strings.CutSuffix(entry.Name(), ".txt"); ok && !entry.IsDir() && version != "" {
+			versions = append(versions, version)
+		}
+	}
+	if len(versions) == 0 {
+		return nil, fmt.Errorf("%w: %s has no versions", ErrNotFound, name)
+	}
+
+	sort.Slice(versions, func(i, j int) bool {
+		return compareVersions(versions[i], versions[j]) < 0
+	})
+	return versions, nil
+}
+
+// HTTPStore fetches prompts from a prompt service.
+//
+// Get requests GET <baseURL>/<name>/<version>, with version "latest" for
+// Latest, and expects a JSON Prompt in response. A 404 status is reported
+// as ErrNotFound.
+//
+// Specific versions are cached indefinitely, since versions are immutable;
+// the latest version is cached for the TTL set by WithCacheTTL.
+//
+// Thread Safety: HTTPStore is safe for concurrent use.
+type HTTPStore struct {
+	baseURL    string
+	httpClient warp.HTTPClient
+	headers    map[string]string
+	ttl        time.Duration
+	now        func() time.Time
+
+	mu    sync.Mutex
+	cache map[string]cachedPrompt
+}
+
+// cachedPrompt is a cached HTTPStore response.
+type cachedPrompt struct {
+	prompt  *Prompt
+	expires time.Time // zero for no expiry
+}
+
+// Compile-time interface check
+var _ Store = (*HTTPStore)(nil)
+
+// HTTPOption configures an HTTPStore.
+type HTTPOption func(*HTTPStore)
+
+// NewHTTPStore creates a store fetching prompts from baseURL.
+//
+// Example:
+//
+//	store := promptstore.NewHTTPStore("https://prompts.internal/v1/prompts",
+//	    promptstore.WithHeader("Authorization", "Bearer "+token),
+//	    promptstore.WithCacheTTL(30*time.Second),
+//	)
+func NewHTTPStore(baseURL string, opts ...HTTPOption) *HTTPStore {
+	s := &HTTPStore{
+		baseURL:    strings.TrimRight(baseURL, "/"),
+		httpClient: &http.Client{Timeout: 10 * time.Second},
+		headers:    make(map[string]string),
+		ttl:        time.Minute,
+		now:        time.Now,
+		cache:      make(map[string]cachedPrompt),
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+// WithHTTPClient sets the HTTP client.
+func WithHTTPClient(client warp.HTTPClient) HTTPOption {
+	return func(s *HTTPStore) {
+		s.httpClient = client
+	}
+}
+
+// WithHeader adds a header sent with every request.
+func WithHeader(key, value string) HTTPOption {
+	return func(s *HTTPStore) {
+		s.headers[key] = value
+	}
+}
+
+// WithCacheTTL sets how long the latest version of a prompt is cached
+// (default 1 minute). A TTL of 0 disables caching of latest versions.
+func WithCacheTTL(ttl time.Duration) HTTPOption {
+	return func(s *HTTPStore) {
+		s.ttl = ttl
+	}
+}
+
+// Get fetches a prompt version, or the latest version for Latest.
+func (s *HTTPStore) Get(ctx context.Context, name, version string) (*Prompt, error) {
+	pathVersion := version
+	if version == Latest {
+		pathVersion = "latest"
+	}
+	cacheKey := name + "@" + pathVersion
+
+	s.mu.Lock()
+	cached, ok := s.cache[cacheKey]
+	s.mu.Unlock()
+	if ok && (cached.expires.IsZero() || s.now().Before(cached.expires)) {
+		return cached.prompt, nil
+	}
+
+	u := s.baseURL + "/" + url.PathEscape(name) + "/" + url.PathEscape(pathVersion)
+	req, err := http.NewRequestWithContext(ctx, "GET", u, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Accept", "application/json")
+	for k, v := range s.headers {
+		req.Header.Set(k, v)
+	}
+
+	resp, err := s.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch prompt %s@%s: %w", name, pathVersion, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, fmt.Errorf("%w: %s@%s", ErrNotFound, name, pathVersion)
+	}
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return nil, fmt.Errorf("failed to fetch prompt %s@%s: status %d: %s", name, pathVersion, resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+
+	var prompt Prompt
+	if err := json.NewDecoder(resp.Body).Decode(&prompt); err != nil {
+		return nil, fmt.Errorf("failed to decode prompt %s@%s: %w", name, pathVersion, err)
+	}
+	if prompt.Name == "" {
+		prompt.Name = name
+	}
+	if prompt.Version == "" {
+		if version == Latest {
+			return nil, fmt.Errorf("prompt %s@latest response has no version", name)
+		}
+		prompt.Version = version
+	}
+
+	entry := cachedPrompt{prompt: &prompt}
+	if version == Latest {
+		entry.expires = s.now().Add(s.ttl)
+	}
+	if version != Latest || s.ttl > 0 {
+		s.mu.Lock()
+		s.cache[cacheKey] = entry
+		s.mu.Unlock()
+	}
+
+	return &prompt, nil
+}
+
+// validName rejects names that could escape the store directory.
+func validName(name string) error {
+	if name == "" || name == "." || name == ".." || strings.ContainsAny(name, `/\`) {
+		return fmt.Errorf("invalid prompt name or version %q", name)
+	}
+	return nil
+}
+
+// compareVersions compares versions segment by segment, splitting on
+// non-alphanumeric characters and between digits and letters, and comparing
+// numeric segments numerically.
+func compareVersions(a, b string) int {
+	as, bs := versionSegments(a), versionSegments(b)
+	for i := 0; i < len(as) && i < len(bs); i++ {
+		an, aErr := strconv.Atoi(as[i])
+		bn, bErr := strconv.Atoi(bs[i])
+		switch {
+		case aErr == nil && bErr == nil:
+			if an != bn {
+				if an < bn {
+					return -1
+				}
+				return 1
+			}
+		case as[i] != bs[i]:
+			return strings.Compare(as[i], bs[i])
+		}
+	}
+	return len(as) - len(bs)
+}
+
+// versionSegments splits a version into alternating digit and letter runs.
+func versionSegments(v string) []string {
+	var segments []string
+	start := -1
+	digit := false
+	for i, r := range v {
+		isDigit := r >= '0' && r <= '9'
+		isAlnum := isDigit || (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z')
+		if start >= 0 && (!isAlnum || isDigit != digit) {
+			segments = append(segments, v[start:i])
+			start = -1
+		}
+		if isAlnum && start < 0 {
+			start = i
+			digit = isDigit
+		}
+	}
+	if start >= 0 {
+		segments = append(segments, v[start:])
+	}
+	return segments
+}
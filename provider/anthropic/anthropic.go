@@ -22,12 +22,13 @@ package anthropic
 
 import (
 	"context"
+	"fmt"
 	"io"
 	"net/http"
-	"time"
 
 	"github.com/blue-context/warp"
 	"github.com/blue-context/warp/provider"
+	"github.com/blue-context/warp/provider/providercore"
 )
 
 // Provider implements the provider.Provider interface for Anthropic Claude.
@@ -39,6 +40,8 @@ type Provider struct {
 	apiBase    string
 	apiVersion string
 	httpClient warp.HTTPClient
+	zdr        bool
+	thinking   int
 }
 
 // Compile-time interface check
@@ -62,7 +65,7 @@ func NewProvider(opts ...Option) (*Provider, error) {
 	p := &Provider{
 		apiBase:    "https://api.anthropic.com",
 		apiVersion: "2023-06-01",
-		httpClient: &http.Client{Timeout: 60 * time.Second},
+		httpClient: providercore.NewHTTPClient(),
 	}
 
 	for _, opt := range opts {
@@ -75,6 +78,12 @@ func NewProvider(opts ...Option) (*Provider, error) {
 			Provider: "anthropic",
 		}
 	}
+	if p.thinking != 0 && p.thinking < minThinkingBudget {
+		return nil, &warp.WarpError{
+			Message:  fmt.Sprintf("Anthropic thinking budget must be at least %d tokens, got %d", minThinkingBudget, p.thinking),
+			Provider: "anthropic",
+		}
+	}
 
 	return p, nil
 }
@@ -148,6 +157,100 @@ func WithHTTPClient(client warp.HTTPClient) Option {
 	}
 }
 
+// WithZeroDataRetention declares that the organization has a zero data
+// retention arrangement with Anthropic.
+//
+// Anthropic's retention is set per organization rather than per request, so
+// a client created with warp.WithZeroDataRetention(true) refuses this
+// provider unless this option is set.
+//
+// Example:
+//
+//	provider, err := anthropic.NewProvider(
+//	    anthropic.WithAPIKey("sk-ant-..."),
+//	    anthropic.WithZeroDataRetention(),
+//	)
+func WithZeroDataRetention() Option {
+	return func(p *Provider) {
+		p.zdr = true
+	}
+}
+
+// minThinkingBudget is the smallest thinking budget Anthropic accepts.
+const minThinkingBudget = 1024
+
+// WithThinking enables extended thinking with a budget of budgetTokens
+// reasoning tokens. The reasoning is returned in Message.ReasoningContent,
+// and streamed in MessageDelta.ReasoningContent ahead of the answer.
+//
+// Anthropic requires a budget of at least 1024, so NewProvider returns an
+// error for smaller budgets; zero leaves thinking disabled. Requests must
+// leave room for the answer: a MaxTokens at or below the budget is
+// rejected, and requests without MaxTokens are sent with the budget plus
+// the default of 1024. Requests with a temperature other than 1 are
+// rejected, as Anthropic does not allow it with thinking. Thinking blocks
+// are not sent back in later turns, so requests with tools are rejected
+// too.
+//
+// Example:
+//
+//	provider, err := anthropic.NewProvider(
+//	    anthropic.WithAPIKey("sk-ant-..."),
+//	    anthropic.WithThinking(4096),
+//	)
+func WithThinking(budgetTokens int) Option {
+	return func(p *Provider) {
+		p.thinking = budgetTokens
+	}
+}
+
+// applyThinking enables extended thinking on req if WithThinking was set.
+// It returns an error if orig cannot be sent with thinking.
+func (p *Provider) applyThinking(req *anthropicRequest, orig *warp.CompletionRequest) error {
+	if p.thinking == 0 {
+		return nil
+	}
+	switch {
+	case len(req.Tools) > 0:
+		return warp.NewInvalidRequestError("tools are not supported with extended thinking", "anthropic", nil)
+	case orig.Temperature != nil && *orig.Temperature != 1:
+		return warp.NewInvalidRequestError(fmt.Sprintf("temperature %v is not supported with extended thinking; use 1 or leave it unset", *orig.Temperature), "anthropic", nil)
+	case orig.MaxTokens != nil && *orig.MaxTokens <= p.thinking:
+		return warp.NewInvalidRequestError(fmt.Sprintf("max_tokens %d must exceed the thinking budget of %d", *orig.MaxTokens, p.thinking), "anthropic", nil)
+	}
+	req.Thinking = &anthropicThinking{Type: "enabled", BudgetTokens: p.thinking}
+	if orig.MaxTokens == nil {
+		req.MaxTokens = p.thinking + 1024
+	}
+	return nil
+}
+
+// DisableDataRetention implements warp.DataRetentionController.
+//
+// It returns an error unless WithZeroDataRetention was set, and otherwise
+// leaves the request unchanged; in particular, no metadata identifying the
+// end user is sent.
+func (p *Provider) DisableDataRetention(req *warp.CompletionRequest) error {
+	if !p.zdr {
+		return fmt.Errorf("anthropic retention is set per organization; declare a zero data retention arrangement with WithZeroDataRetention")
+	}
+	return nil
+}
+
+// core returns the HTTP client for the Anthropic API.
+func (p *Provider) core() *providercore.Client {
+	return &providercore.Client{
+		Provider:   "anthropic",
+		BaseURL:    p.apiBase,
+		HTTPClient: p.httpClient,
+		Prepare: func(req *http.Request) error {
+			req.Header.Set("x-api-key", p.apiKey)
+			req.Header.Set("anthropic-version", p.apiVersion)
+			return nil
+		},
+	}
+}
+
 // Name returns the provider name "anthropic".
 //
 // This is used for provider identification in the registry and error messages.
@@ -3,12 +3,14 @@ package anthropic
 import (
 	"context"
 	"io"
+	"math"
 	"net/http"
 	"os"
 	"strings"
 	"testing"
 
 	"github.com/blue-context/warp"
+	"github.com/blue-context/warp/cost"
 	prov "github.com/blue-context/warp/provider"
 )
 
@@ -476,6 +478,25 @@ func TestTransformRequest(t *testing.T) {
 				}
 			},
 		},
+		{
+			name: "with multiple system messages",
+			req: &warp.CompletionRequest{
+				Model: "claude-3-opus-20240229",
+				Messages: []warp.Message{
+					{Role: "system", Content: "You are helpful"},
+					{Role: "system", Content: []warp.ContentPart{{Type: "text", Text: "Be brief"}}},
+					{Role: "user", Content: "Hello"},
+				},
+			},
+			validate: func(t *testing.T, result *anthropicRequest) {
+				if result.System != "You are helpful\n\nBe brief" {
+					t.Errorf("System = %q, want both system messages joined", result.System)
+				}
+				if len(result.Messages) != 1 {
+					t.Errorf("len(Messages) = %v, want 1", len(result.Messages))
+				}
+			},
+		},
 		{
 			name: "with stop sequences",
 			req: &warp.CompletionRequest{
@@ -644,6 +665,7 @@ func TestMapStopReason(t *testing.T) {
 		{"max_tokens", "length"},
 		{"tool_use", "tool_calls"},
 		{"stop_sequence", "stop"},
+		{"refusal", "content_filter"},
 		{"unknown", "unknown"},
 	}
 
@@ -657,6 +679,79 @@ func TestMapStopReason(t *testing.T) {
 	}
 }
 
+// TestServiceTier tests service tier translation in both directions
+func TestServiceTier(t *testing.T) {
+	tests := []struct {
+		tier warp.ServiceTier
+		want string
+	}{
+		{warp.ServiceTierPriority, "auto"},
+		{warp.ServiceTierAuto, "auto"},
+		{warp.ServiceTierDefault, "standard_only"},
+		{warp.ServiceTierFlex, ""},
+		{"", ""},
+	}
+	for _, tt := range tests {
+		req, err := transformRequest(&warp.CompletionRequest{
+			Model:       "claude-sonnet-4",
+			Messages:    []warp.Message{{Role: "user", Content: "Hi"}},
+			ServiceTier: tt.tier,
+		})
+		if err != nil {
+			t.Fatalf("transformRequest() error = %v", err)
+		}
+		if req.ServiceTier != tt.want {
+			t.Errorf("ServiceTier(%q) = %q, want %q", tt.tier, req.ServiceTier, tt.want)
+		}
+	}
+
+	resp := transformResponse(&anthropicResponse{Usage: anthropicUsage{ServiceTier: "standard"}})
+	if resp.ServiceTier != warp.ServiceTierDefault {
+		t.Errorf("resp.ServiceTier = %q, want default", resp.ServiceTier)
+	}
+	resp = transformResponse(&anthropicResponse{Usage: anthropicUsage{ServiceTier: "priority"}})
+	if resp.ServiceTier != warp.ServiceTierPriority {
+		t.Errorf("resp.ServiceTier = %q, want priority", resp.ServiceTier)
+	}
+}
+
+// TestUsageToWarpUsage tests prompt cache accounting in usage conversion
+func TestUsageToWarpUsage(t *testing.T) {
+	usage := (&anthropicUsage{
+		InputTokens:              100,
+		OutputTokens:             20,
+		CacheCreationInputTokens: 50,
+		CacheReadInputTokens:     1000,
+	}).toWarpUsage()
+
+	if usage.PromptTokens != 1150 {
+		t.Errorf("PromptTokens = %v, want 1150", usage.PromptTokens)
+	}
+	if usage.TotalTokens != 1170 {
+		t.Errorf("TotalTokens = %v, want 1170", usage.TotalTokens)
+	}
+	if usage.GetCachedTokens() != 1000 {
+		t.Errorf("GetCachedTokens() = %v, want 1000", usage.GetCachedTokens())
+	}
+	if usage.GetCacheWriteTokens() != 50 {
+		t.Errorf("GetCacheWriteTokens() = %v, want 50", usage.GetCacheWriteTokens())
+	}
+
+	// 100 input, 50 cache writes at 1.25x, and 1000 cache reads at 0.1x
+	calc := cost.NewCalculator(nil)
+	info := modelRegistry["claude-3-5-sonnet-20241022"]
+	calc.AddPricingOverride("anthropic", info.Name, info)
+	got, err := calc.CalculateCompletion(&warp.CompletionResponse{Model: "anthropic/" + info.Name, Usage: usage})
+	if want := (100*3.00 + 50*3.75 + 1000*0.30 + 20*15.00) / 1_000_000; err != nil || math.Abs(got-want) > 1e-12 {
+		t.Errorf("CalculateCompletion() = %v, %v, want %v", got, err, want)
+	}
+
+	plain := (&anthropicUsage{InputTokens: 10, OutputTokens: 8}).toWarpUsage()
+	if plain.PromptDetails != nil {
+		t.Errorf("PromptDetails = %+v, want nil without cache reads", plain.PromptDetails)
+	}
+}
+
 // TestCompletionStream tests the CompletionStream method
 func TestCompletionStream(t *testing.T) {
 	tests := []struct {
@@ -941,3 +1036,16 @@ func TestCompletionStreamIntegration(t *testing.T) {
 
 	t.Logf("Received %d chunks, content: %s", chunkCount, content.String())
 }
+
+// TestDisableDataRetention tests that zero data retention must be declared
+func TestDisableDataRetention(t *testing.T) {
+	p, _ := NewProvider(WithAPIKey("sk-ant-test"))
+	if err := p.DisableDataRetention(&warp.CompletionRequest{}); err == nil {
+		t.Error("DisableDataRetention() without declaration succeeded")
+	}
+
+	p, _ = NewProvider(WithAPIKey("sk-ant-test"), WithZeroDataRetention())
+	if err := p.DisableDataRetention(&warp.CompletionRequest{}); err != nil {
+		t.Errorf("DisableDataRetention() error = %v", err)
+	}
+}
@@ -1,12 +1,9 @@
 package anthropic
 
 import (
-	"bytes"
 	"context"
-	"encoding/json"
 	"fmt"
-	"io"
-	"net/http"
+	"strings"
 	"time"
 
 	"github.com/blue-context/warp"
@@ -25,6 +22,14 @@ type anthropicRequest struct {
 	Tools         []anthropicTool      `json:"tools,omitempty"`
 	ToolChoice    *anthropicToolChoice `json:"tool_choice,omitempty"`
 	Metadata      map[string]any       `json:"metadata,omitempty"`
+	ServiceTier   string               `json:"service_tier,omitempty"`
+	Thinking      *anthropicThinking   `json:"thinking,omitempty"`
+}
+
+// anthropicThinking enables extended thinking.
+type anthropicThinking struct {
+	Type         string `json:"type"`
+	BudgetTokens int    `json:"budget_tokens"`
 }
 
 // anthropicMessage represents a message in Anthropic format.
@@ -35,9 +40,10 @@ type anthropicMessage struct {
 
 // anthropicContentBlock represents a content block in Anthropic format.
 type anthropicContentBlock struct {
-	Type   string                `json:"type"`
-	Text   string                `json:"text,omitempty"`
-	Source *anthropicImageSource `json:"source,omitempty"`
+	Type     string                `json:"type"`
+	Text     string                `json:"text,omitempty"`
+	Thinking string                `json:"thinking,omitempty"`
+	Source   *anthropicImageSource `json:"source,omitempty"`
 }
 
 // anthropicImageSource represents an image source in Anthropic format.
@@ -74,8 +80,34 @@ type anthropicResponse struct {
 
 // anthropicUsage represents token usage in Anthropic format.
 type anthropicUsage struct {
-	InputTokens  int `json:"input_tokens"`
-	OutputTokens int `json:"output_tokens"`
+	InputTokens              int    `json:"input_tokens"`
+	OutputTokens             int    `json:"output_tokens"`
+	CacheCreationInputTokens int    `json:"cache_creation_input_tokens,omitempty"`
+	CacheReadInputTokens     int    `json:"cache_read_input_tokens,omitempty"`
+	ServiceTier              string `json:"service_tier,omitempty"`
+}
+
+// toWarpUsage converts Anthropic usage to Warp usage format.
+//
+// Anthropic reports prompt cache reads and writes separately from
+// input_tokens, so they are folded into PromptTokens with cache reads
+// surfaced as PromptDetails.CachedTokens, matching OpenAI's accounting, and
+// cache writes as PromptDetails.CacheWriteTokens, which are billed at their
+// own rate.
+func (u *anthropicUsage) toWarpUsage() *warp.Usage {
+	promptTokens := u.InputTokens + u.CacheCreationInputTokens + u.CacheReadInputTokens
+	usage := &warp.Usage{
+		PromptTokens:     promptTokens,
+		CompletionTokens: u.OutputTokens,
+		TotalTokens:      promptTokens + u.OutputTokens,
+	}
+	if u.CacheReadInputTokens > 0 || u.CacheCreationInputTokens > 0 {
+		usage.PromptDetails = &warp.PromptTokensDetails{
+			CachedTokens:     u.CacheReadInputTokens,
+			CacheWriteTokens: u.CacheCreationInputTokens,
+		}
+	}
+	return usage
 }
 
 // Completion sends a chat completion request to Anthropic.
@@ -101,41 +133,14 @@ func (p *Provider) Completion(ctx context.Context, req *warp.CompletionRequest)
 	if err != nil {
 		return nil, fmt.Errorf("failed to transform request: %w", err)
 	}
-
-	// Marshal to JSON
-	body, err := json.Marshal(anthropicReq)
-	if err != nil {
-		return nil, fmt.Errorf("failed to marshal request: %w", err)
-	}
-
-	// Create HTTP request
-	httpReq, err := http.NewRequestWithContext(ctx, "POST", p.apiBase+"/v1/messages", bytes.NewReader(body))
-	if err != nil {
-		return nil, fmt.Errorf("failed to create request: %w", err)
-	}
-
-	// Set Anthropic-specific headers
-	httpReq.Header.Set("Content-Type", "application/json")
-	httpReq.Header.Set("x-api-key", p.apiKey)
-	httpReq.Header.Set("anthropic-version", p.apiVersion)
-
-	// Send request
-	httpResp, err := p.httpClient.Do(httpReq)
-	if err != nil {
-		return nil, fmt.Errorf("failed to send request: %w", err)
-	}
-	defer httpResp.Body.Close()
-
-	// Check status code
-	if httpResp.StatusCode != http.StatusOK {
-		body, _ := io.ReadAll(httpResp.Body)
-		return nil, warp.ParseProviderError("anthropic", httpResp.StatusCode, body, nil)
+	if err := p.applyThinking(anthropicReq, req); err != nil {
+		return nil, err
 	}
 
-	// Parse response
+	// Send request and parse response
 	var anthropicResp anthropicResponse
-	if err := json.NewDecoder(httpResp.Body).Decode(&anthropicResp); err != nil {
-		return nil, fmt.Errorf("failed to decode response: %w", err)
+	if err := p.core().PostJSON(ctx, "/v1/messages", anthropicReq, &anthropicResp); err != nil {
+		return nil, err
 	}
 
 	// Transform to Warp format
@@ -145,7 +150,7 @@ func (p *Provider) Completion(ctx context.Context, req *warp.CompletionRequest)
 // transformRequest transforms a Warp request to Anthropic format.
 //
 // Key transformations:
-// - Extract system messages to separate "system" parameter
+// - Extract system messages to separate "system" parameter (concatenated)
 // - Ensure max_tokens is always set (required by Anthropic, default 1024)
 // - Convert stop to stop_sequences
 // - Handle multimodal content (images)
@@ -155,18 +160,14 @@ func transformRequest(req *warp.CompletionRequest) (*anthropicRequest, error) {
 		Model: req.Model,
 	}
 
-	// Extract system message and filter messages
-	var systemMessage string
+	// Extract system messages into the system parameter, in order
+	var systemParts []string
 	messages := make([]anthropicMessage, 0, len(req.Messages))
 
 	for _, msg := range req.Messages {
 		if msg.Role == "system" {
-			// Extract system content
-			switch content := msg.Content.(type) {
-			case string:
-				systemMessage = content
-			default:
-				return nil, fmt.Errorf("system message must have string content")
+			if text := warp.ContentToString(msg); text != "" {
+				systemParts = append(systemParts, text)
 			}
 		} else {
 			// Transform message content
@@ -208,10 +209,8 @@ func transformRequest(req *warp.CompletionRequest) (*anthropicRequest, error) {
 
 	anthropicReq.Messages = messages
 
-	// Set system message if present
-	if systemMessage != "" {
-		anthropicReq.System = systemMessage
-	}
+	// Set system prompt if present
+	anthropicReq.System = strings.Join(systemParts, "\n\n")
 
 	// max_tokens is REQUIRED by Anthropic
 	if req.MaxTokens != nil {
@@ -230,6 +229,7 @@ func transformRequest(req *warp.CompletionRequest) (*anthropicRequest, error) {
 	if len(req.Stop) > 0 {
 		anthropicReq.StopSequences = req.Stop
 	}
+	anthropicReq.ServiceTier = mapServiceTier(req.ServiceTier)
 	// Note: Stream is not set here - handled separately by Completion vs CompletionStream
 
 	// Transform tools
@@ -264,18 +264,21 @@ func transformRequest(req *warp.CompletionRequest) (*anthropicRequest, error) {
 // - Maps stop_reason to finish_reason
 // - Transforms usage information
 func transformResponse(resp *anthropicResponse) *warp.CompletionResponse {
-	// Extract text content from content blocks
-	var content string
+	// Extract text and thinking content from content blocks
+	var content, reasoning string
 	for _, block := range resp.Content {
-		if block.Type == "text" {
+		switch block.Type {
+		case "text":
 			content += block.Text
+		case "thinking":
+			reasoning += block.Thinking
 		}
 	}
 
 	// Map stop_reason to OpenAI finish_reason
 	finishReason := mapStopReason(resp.StopReason)
 
-	return &warp.CompletionResponse{
+	warpResp := &warp.CompletionResponse{
 		ID:      resp.ID,
 		Object:  "chat.completion",
 		Created: time.Now().Unix(),
@@ -284,32 +287,40 @@ func transformResponse(resp *anthropicResponse) *warp.CompletionResponse {
 			{
 				Index: 0,
 				Message: warp.Message{
-					Role:    resp.Role,
-					Content: content,
+					Role:             resp.Role,
+					Content:          content,
+					ReasoningContent: reasoning,
 				},
 				FinishReason: finishReason,
 			},
 		},
-		Usage: &warp.Usage{
-			PromptTokens:     resp.Usage.InputTokens,
-			CompletionTokens: resp.Usage.OutputTokens,
-			TotalTokens:      resp.Usage.InputTokens + resp.Usage.OutputTokens,
-		},
+		Usage: resp.Usage.toWarpUsage(),
+	}
+	warp.SetNativeFinishReason(warpResp, resp.StopReason)
+	if resp.Usage.ServiceTier == "standard" {
+		warpResp.ServiceTier = warp.ServiceTierDefault
+	} else {
+		warpResp.ServiceTier = warp.ServiceTier(resp.Usage.ServiceTier)
 	}
+
+	return warpResp
 }
 
-// mapStopReason maps Anthropic's stop_reason to OpenAI's finish_reason.
-func mapStopReason(stopReason string) string {
-	switch stopReason {
-	case "end_turn":
-		return "stop"
-	case "max_tokens":
-		return "length"
-	case "tool_use":
-		return "tool_calls"
-	case "stop_sequence":
-		return "stop"
+// mapServiceTier maps a Warp service tier to Anthropic's service_tier
+// parameter. Anthropic offers Priority Tier capacity through "auto" and
+// has no flex tier, so flex requests use the default behavior.
+func mapServiceTier(tier warp.ServiceTier) string {
+	switch tier {
+	case warp.ServiceTierAuto, warp.ServiceTierPriority:
+		return "auto"
+	case warp.ServiceTierDefault:
+		return "standard_only"
 	default:
-		return stopReason
+		return ""
 	}
 }
+
+// mapStopReason maps Anthropic's stop_reason to OpenAI's finish_reason.
+func mapStopReason(stopReason string) string {
+	return string(warp.NormalizeFinishReason(stopReason))
+}
@@ -2,6 +2,7 @@ package anthropic
 
 import (
 	"sort"
+	"time"
 
 	"github.com/blue-context/warp/types"
 )
@@ -11,60 +12,78 @@ import (
 var modelRegistry = map[string]*types.ModelInfo{
 	// Claude 3.5 Models
 	"claude-3-5-sonnet-20241022": {
-		Name:              "claude-3-5-sonnet-20241022",
-		Provider:          "anthropic",
-		ContextWindow:     200000,
-		MaxOutputTokens:   8192,
-		InputCostPer1M:    3.00,
-		OutputCostPer1M:   15.00,
-		SupportsVision:    true,
-		SupportsFunctions: true,
-		SupportsJSON:      false,
-		SupportsStreaming: true,
+		Name:                 "claude-3-5-sonnet-20241022",
+		Provider:             "anthropic",
+		ContextWindow:        200000,
+		MaxOutputTokens:      8192,
+		InputCostPer1M:       3.00,
+		OutputCostPer1M:      15.00,
+		CachedInputCostPer1M: 0.30,
+		CacheWriteCostPer1M:  3.75,
+		BatchDiscount:        0.5,
+		SupportsVision:       true,
+		SupportsFunctions:    true,
+		SupportsJSON:         false,
+		SupportsStreaming:    true,
 		Capabilities: types.Capabilities{
 			Completion:      true,
 			Streaming:       true,
 			FunctionCalling: true,
 			Vision:          true,
 		},
+		Deprecated:   true,
+		ReplacedBy:   "claude-sonnet-4-5-20250929",
+		ShutdownDate: time.Date(2025, time.October, 22, 0, 0, 0, 0, time.UTC),
 	},
 	"claude-3-5-sonnet-20240620": {
-		Name:              "claude-3-5-sonnet-20240620",
-		Provider:          "anthropic",
-		ContextWindow:     200000,
-		MaxOutputTokens:   8192,
-		InputCostPer1M:    3.00,
-		OutputCostPer1M:   15.00,
-		SupportsVision:    true,
-		SupportsFunctions: true,
-		SupportsJSON:      false,
-		SupportsStreaming: true,
+		Name:                 "claude-3-5-sonnet-20240620",
+		Provider:             "anthropic",
+		ContextWindow:        200000,
+		MaxOutputTokens:      8192,
+		InputCostPer1M:       3.00,
+		OutputCostPer1M:      15.00,
+		CachedInputCostPer1M: 0.30,
+		CacheWriteCostPer1M:  3.75,
+		BatchDiscount:        0.5,
+		SupportsVision:       true,
+		SupportsFunctions:    true,
+		SupportsJSON:         false,
+		SupportsStreaming:    true,
 		Capabilities: types.Capabilities{
 			Completion:      true,
 			Streaming:       true,
 			FunctionCalling: true,
 			Vision:          true,
 		},
+		Deprecated:   true,
+		ReplacedBy:   "claude-sonnet-4-5-20250929",
+		ShutdownDate: time.Date(2025, time.October, 22, 0, 0, 0, 0, time.UTC),
 	},
 
 	// Claude 3 Models
 	"claude-3-opus-20240229": {
-		Name:              "claude-3-opus-20240229",
-		Provider:          "anthropic",
-		ContextWindow:     200000,
-		MaxOutputTokens:   4096,
-		InputCostPer1M:    15.00,
-		OutputCostPer1M:   75.00,
-		SupportsVision:    true,
-		SupportsFunctions: true,
-		SupportsJSON:      false,
-		SupportsStreaming: true,
+		Name:                 "claude-3-opus-20240229",
+		Provider:             "anthropic",
+		ContextWindow:        200000,
+		MaxOutputTokens:      4096,
+		InputCostPer1M:       15.00,
+		OutputCostPer1M:      75.00,
+		CachedInputCostPer1M: 1.50,
+		CacheWriteCostPer1M:  18.75,
+		BatchDiscount:        0.5,
+		SupportsVision:       true,
+		SupportsFunctions:    true,
+		SupportsJSON:         false,
+		SupportsStreaming:    true,
 		Capabilities: types.Capabilities{
 			Completion:      true,
 			Streaming:       true,
 			FunctionCalling: true,
 			Vision:          true,
 		},
+		Deprecated:   true,
+		ReplacedBy:   "claude-opus-4-1-20250805",
+		ShutdownDate: time.Date(2026, time.January, 5, 0, 0, 0, 0, time.UTC),
 	},
 	"claude-3-sonnet-20240229": {
 		Name:              "claude-3-sonnet-20240229",
@@ -73,6 +92,7 @@ var modelRegistry = map[string]*types.ModelInfo{
 		MaxOutputTokens:   4096,
 		InputCostPer1M:    3.00,
 		OutputCostPer1M:   15.00,
+		BatchDiscount:     0.5,
 		SupportsVision:    true,
 		SupportsFunctions: true,
 		SupportsJSON:      false,
@@ -83,18 +103,24 @@ var modelRegistry = map[string]*types.ModelInfo{
 			FunctionCalling: true,
 			Vision:          true,
 		},
+		Deprecated:   true,
+		ReplacedBy:   "claude-sonnet-4-20250514",
+		ShutdownDate: time.Date(2025, time.July, 21, 0, 0, 0, 0, time.UTC),
 	},
 	"claude-3-haiku-20240307": {
-		Name:              "claude-3-haiku-20240307",
-		Provider:          "anthropic",
-		ContextWindow:     200000,
-		MaxOutputTokens:   4096,
-		InputCostPer1M:    0.25,
-		OutputCostPer1M:   1.25,
-		SupportsVision:    true,
-		SupportsFunctions: true,
-		SupportsJSON:      false,
-		SupportsStreaming: true,
+		Name:                 "claude-3-haiku-20240307",
+		Provider:             "anthropic",
+		ContextWindow:        200000,
+		MaxOutputTokens:      4096,
+		InputCostPer1M:       0.25,
+		OutputCostPer1M:      1.25,
+		CachedInputCostPer1M: 0.03,
+		CacheWriteCostPer1M:  0.3125,
+		BatchDiscount:        0.5,
+		SupportsVision:       true,
+		SupportsFunctions:    true,
+		SupportsJSON:         false,
+		SupportsStreaming:    true,
 		Capabilities: types.Capabilities{
 			Completion:      true,
 			Streaming:       true,
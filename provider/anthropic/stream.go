@@ -1,16 +1,13 @@
 package anthropic
 
 import (
-	"bufio"
-	"bytes"
 	"context"
-	"encoding/json"
 	"fmt"
 	"io"
-	"net/http"
 	"time"
 
 	"github.com/blue-context/warp"
+	"github.com/blue-context/warp/sse"
 )
 
 // CompletionStream sends a streaming chat completion request to Anthropic.
@@ -49,39 +46,17 @@ func (p *Provider) CompletionStream(ctx context.Context, req *warp.CompletionReq
 	if err != nil {
 		return nil, fmt.Errorf("failed to transform request: %w", err)
 	}
+	if err := p.applyThinking(anthropicReq, req); err != nil {
+		return nil, err
+	}
 
 	// Enable streaming for this request
 	anthropicReq.Stream = true
 
-	// Marshal to JSON
-	body, err := json.Marshal(anthropicReq)
-	if err != nil {
-		return nil, fmt.Errorf("failed to marshal request: %w", err)
-	}
-
-	// Create HTTP request
-	httpReq, err := http.NewRequestWithContext(ctx, "POST", p.apiBase+"/v1/messages", bytes.NewReader(body))
-	if err != nil {
-		return nil, fmt.Errorf("failed to create request: %w", err)
-	}
-
-	// Set Anthropic-specific headers
-	httpReq.Header.Set("Content-Type", "application/json")
-	httpReq.Header.Set("x-api-key", p.apiKey)
-	httpReq.Header.Set("anthropic-version", p.apiVersion)
-	httpReq.Header.Set("Accept", "text/event-stream")
-
 	// Send request
-	httpResp, err := p.httpClient.Do(httpReq)
+	httpResp, err := p.core().PostStream(ctx, "/v1/messages", anthropicReq)
 	if err != nil {
-		return nil, fmt.Errorf("failed to send request: %w", err)
-	}
-
-	// Check status code
-	if httpResp.StatusCode != http.StatusOK {
-		defer httpResp.Body.Close()
-		body, _ := io.ReadAll(httpResp.Body)
-		return nil, warp.ParseProviderError("anthropic", httpResp.StatusCode, body, nil)
+		return nil, err
 	}
 
 	// Create SSE stream
@@ -102,6 +77,7 @@ type anthropicStreamEvent struct {
 type anthropicDelta struct {
 	Type         string  `json:"type"`
 	Text         string  `json:"text,omitempty"`
+	Thinking     string  `json:"thinking,omitempty"`
 	StopReason   string  `json:"stop_reason,omitempty"`
 	StopSequence *string `json:"stop_sequence,omitempty"`
 }
@@ -114,21 +90,20 @@ type anthropicDelta struct {
 // Anthropic uses a multi-event streaming protocol:
 // - message_start: Initial message metadata
 // - content_block_start: Start of a content block
-// - content_block_delta: Incremental text updates
+// - content_block_delta: Incremental text and thinking updates
 // - message_delta: Message-level updates
 // - message_stop: End of stream
 //
 // Thread Safety: anthropicStream is NOT safe for concurrent use.
 // Only one goroutine should call Recv() at a time.
 type anthropicStream struct {
-	reader       *bufio.Reader
-	closer       io.Closer
-	ctx          context.Context
-	err          error // Cached error for subsequent Recv calls
-	model        string
-	messageID    string
-	currentIndex int
-	created      int64
+	reader    *sse.Reader
+	closer    io.Closer
+	ctx       context.Context
+	err       error // Cached error for subsequent Recv calls
+	model     string
+	messageID string
+	created   int64
 }
 
 // newAnthropicStream creates a new Anthropic SSE stream from an HTTP response body.
@@ -137,7 +112,7 @@ type anthropicStream struct {
 // CompletionChunk objects.
 func newAnthropicStream(ctx context.Context, body io.ReadCloser, model string) warp.Stream {
 	return &anthropicStream{
-		reader:  bufio.NewReader(body),
+		reader:  sse.NewReader(body, sse.WithBufferReuse()),
 		closer:  body,
 		ctx:     ctx,
 		model:   model,
@@ -166,54 +141,32 @@ func (s *anthropicStream) Recv() (*warp.CompletionChunk, error) {
 		default:
 		}
 
-		// Read line
-		line, err := s.reader.ReadBytes('\n')
-		if err != nil {
+		// Read the next event
+		if _, err := s.reader.Next(); err != nil {
 			if err == io.EOF {
 				s.err = io.EOF
 				return nil, io.EOF
 			}
-			s.err = fmt.Errorf("failed to read line: %w", err)
+			s.err = fmt.Errorf("failed to read event: %w", err)
 			return nil, s.err
 		}
 
-		// Trim whitespace
-		line = bytes.TrimSpace(line)
-
-		// Skip empty lines
-		if len(line) == 0 {
-			continue
-		}
-
-		// Parse SSE field
-		if !bytes.HasPrefix(line, []byte("event: ")) && !bytes.HasPrefix(line, []byte("data: ")) {
-			continue
-		}
-
-		// Handle event type line
-		if bytes.HasPrefix(line, []byte("event: ")) {
-			// Store event type but continue reading for data
-			continue
-		}
-
-		// Extract data after "data: " prefix
-		data := bytes.TrimPrefix(line, []byte("data: "))
-
-		// Parse JSON event
-		var event anthropicStreamEvent
-		if err := json.Unmarshal(data, &event); err != nil {
+		// Parse JSON event. The event type is repeated in the JSON
+		// payload, so only the data is used
+		var streamEvent anthropicStreamEvent
+		if err := s.reader.Decode(&streamEvent); err != nil {
 			// Skip malformed events
 			continue
 		}
 
 		// Process event based on type
-		chunk := s.processEvent(&event)
+		chunk := s.processEvent(&streamEvent)
 		if chunk != nil {
 			return chunk, nil
 		}
 
 		// Check for stream completion
-		if event.Type == "message_stop" {
+		if streamEvent.Type == "message_stop" {
 			s.err = io.EOF
 			return nil, io.EOF
 		}
@@ -247,29 +200,33 @@ func (s *anthropicStream) processEvent(event *anthropicStreamEvent) *warp.Comple
 		}
 
 	case "content_block_start":
-		// Track the content block index
-		s.currentIndex = event.Index
+		// Content blocks are parts of the one choice; thinking blocks
+		// precede the text blocks
 		return nil
 
 	case "content_block_delta":
-		// Return incremental text content
-		if event.Delta != nil && event.Delta.Type == "text_delta" {
-			return &warp.CompletionChunk{
-				ID:      s.messageID,
-				Object:  "chat.completion.chunk",
-				Created: s.created,
-				Model:   s.model,
-				Choices: []warp.ChunkChoice{
-					{
-						Index: s.currentIndex,
-						Delta: warp.MessageDelta{
-							Content: event.Delta.Text,
-						},
-					},
-				},
-			}
+		// Return incremental text or thinking content
+		if event.Delta == nil {
+			return nil
+		}
+		var delta warp.MessageDelta
+		switch event.Delta.Type {
+		case "text_delta":
+			delta.Content = event.Delta.Text
+		case "thinking_delta":
+			delta.ReasoningContent = event.Delta.Thinking
+		default:
+			// Signature deltas only verify thinking blocks, which are not
+			// sent back
+			return nil
+		}
+		return &warp.CompletionChunk{
+			ID:      s.messageID,
+			Object:  "chat.completion.chunk",
+			Created: s.created,
+			Model:   s.model,
+			Choices: []warp.ChunkChoice{{Index: 0, Delta: delta}},
 		}
-		return nil
 
 	case "message_delta":
 		// Return final chunk with finish reason
@@ -307,11 +264,7 @@ func (s *anthropicStream) buildUsage(usage *anthropicUsage) *warp.Usage {
 	if usage == nil {
 		return nil
 	}
-	return &warp.Usage{
-		PromptTokens:     usage.InputTokens,
-		CompletionTokens: usage.OutputTokens,
-		TotalTokens:      usage.InputTokens + usage.OutputTokens,
-	}
+	return usage.toWarpUsage()
 }
 
 // Close closes the stream and releases resources.
@@ -319,5 +272,7 @@ func (s *anthropicStream) buildUsage(usage *anthropicUsage) *warp.Usage {
 // It is safe to call Close multiple times.
 // Close must be called even if Recv returns an error.
 func (s *anthropicStream) Close() error {
-	return s.closer.Close()
+	err := s.closer.Close()
+	s.reader.Release()
+	return err
 }
@@ -0,0 +1,147 @@
+package anthropic
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/blue-context/warp"
+)
+
+func TestThinking(t *testing.T) {
+	stream := `event: message_start
+data: {"type":"message_start","message":{"id":"msg_1","role":"assistant","content":[],"usage":{"input_tokens":10,"output_tokens":0}}}
+
+event: content_block_start
+data: {"type":"content_block_start","index":0,"content_block":{"type":"thinking","thinking":""}}
+
+event: content_block_delta
+data: {"type":"content_block_delta","index":0,"delta":{"type":"thinking_delta","thinking":"Two plus "}}
+
+event: content_block_delta
+data: {"type":"content_block_delta","index":0,"delta":{"type":"thinking_delta","thinking":"two is four."}}
+
+event: content_block_delta
+data: {"type":"content_block_delta","index":0,"delta":{"type":"signature_delta","signature":"EqQBCgIYAhIM"}}
+
+event: content_block_stop
+data: {"type":"content_block_stop","index":0}
+
+event: content_block_start
+data: {"type":"content_block_start","index":1,"content_block":{"type":"text","text":""}}
+
+event: content_block_delta
+data: {"type":"content_block_delta","index":1,"delta":{"type":"text_delta","text":"4"}}
+
+event: message_delta
+data: {"type":"message_delta","delta":{"stop_reason":"end_turn"},"usage":{"output_tokens":12}}
+
+event: message_stop
+data: {"type":"message_stop"}
+
+`
+	response := `{"id":"msg_2","role":"assistant","model":"claude-sonnet-4-5","stop_reason":"end_turn",` +
+		`"content":[{"type":"thinking","thinking":"Two plus two is four.","signature":"EqQB"},{"type":"text","text":"4"}],` +
+		`"usage":{"input_tokens":10,"output_tokens":12}}`
+
+	var body map[string]any
+	client := &mockHTTPClient{
+		doFunc: func(req *http.Request) (*http.Response, error) {
+			data, _ := io.ReadAll(req.Body)
+			body = nil
+			json.Unmarshal(data, &body)
+			payload := response
+			if body["stream"] == true {
+				payload = stream
+			}
+			return &http.Response{StatusCode: http.StatusOK, Body: io.NopCloser(strings.NewReader(payload)), Header: make(http.Header)}, nil
+		},
+	}
+	p, err := NewProvider(WithAPIKey("sk-ant-test"), WithHTTPClient(client), WithThinking(2048))
+	if err != nil {
+		t.Fatalf("NewProvider() error = %v", err)
+	}
+	req := &warp.CompletionRequest{Model: "claude-sonnet-4-5", Messages: []warp.Message{{Role: "user", Content: "2+2?"}}}
+
+	s, err := p.CompletionStream(context.Background(), req)
+	if err != nil {
+		t.Fatalf("CompletionStream() error = %v", err)
+	}
+	streamed, err := warp.CollectStream(s)
+	if err != nil {
+		t.Fatalf("CollectStream() error = %v", err)
+	}
+	thinking, _ := body["thinking"].(map[string]any)
+	if thinking["type"] != "enabled" || thinking["budget_tokens"] != float64(2048) || body["max_tokens"] != float64(3072) {
+		t.Errorf("request thinking = %v, max_tokens = %v", body["thinking"], body["max_tokens"])
+	}
+
+	resp, err := p.Completion(context.Background(), req)
+	if err != nil {
+		t.Fatalf("Completion() error = %v", err)
+	}
+
+	for name, r := range map[string]*warp.CompletionResponse{"stream": streamed, "completion": resp} {
+		if len(r.Choices) != 1 {
+			t.Fatalf("%s: choices = %+v, want 1", name, r.Choices)
+		}
+		msg := r.Choices[0].Message
+		if msg.Content != "4" || msg.ReasoningContent != "Two plus two is four." {
+			t.Errorf("%s: content = %q, reasoning = %q", name, msg.Content, msg.ReasoningContent)
+		}
+	}
+}
+
+func TestThinkingValidation(t *testing.T) {
+	if _, err := NewProvider(WithAPIKey("sk-ant-test"), WithThinking(512)); err == nil {
+		t.Error("NewProvider() with a 512-token budget: want error")
+	}
+
+	var body map[string]any
+	client := &mockHTTPClient{
+		doFunc: func(req *http.Request) (*http.Response, error) {
+			data, _ := io.ReadAll(req.Body)
+			body = nil
+			json.Unmarshal(data, &body)
+			payload := `{"id":"msg_1","role":"assistant","stop_reason":"end_turn","content":[{"type":"text","text":"4"}],"usage":{"input_tokens":10,"output_tokens":1}}`
+			return &http.Response{StatusCode: http.StatusOK, Body: io.NopCloser(strings.NewReader(payload)), Header: make(http.Header)}, nil
+		},
+	}
+	p, err := NewProvider(WithAPIKey("sk-ant-test"), WithHTTPClient(client), WithThinking(2048))
+	if err != nil {
+		t.Fatalf("NewProvider() error = %v", err)
+	}
+	msgs := []warp.Message{{Role: "user", Content: "2+2?"}}
+	tools := []warp.Tool{{Type: "function", Function: warp.Function{Name: "add", Parameters: map[string]any{"type": "object"}}}}
+
+	invalid := map[string]*warp.CompletionRequest{
+		"max tokens":  {Model: "claude-sonnet-4-5", Messages: msgs, MaxTokens: warp.IntPtr(2048)},
+		"temperature": {Model: "claude-sonnet-4-5", Messages: msgs, Temperature: warp.Float64Ptr(0.5)},
+		"tools":       {Model: "claude-sonnet-4-5", Messages: msgs, Tools: tools},
+	}
+	for name, req := range invalid {
+		body = nil
+		if _, err := p.Completion(context.Background(), req); err == nil {
+			t.Errorf("%s: Completion() want error", name)
+		} else if _, ok := err.(*warp.InvalidRequestError); !ok {
+			t.Errorf("%s: Completion() error = %T, want *warp.InvalidRequestError", name, err)
+		}
+		if _, err := p.CompletionStream(context.Background(), req); err == nil {
+			t.Errorf("%s: CompletionStream() want error", name)
+		}
+		if body != nil {
+			t.Errorf("%s: request was sent", name)
+		}
+	}
+
+	req := &warp.CompletionRequest{Model: "claude-sonnet-4-5", Messages: msgs, MaxTokens: warp.IntPtr(4096), Temperature: warp.Float64Ptr(1)}
+	if _, err := p.Completion(context.Background(), req); err != nil {
+		t.Fatalf("Completion() error = %v", err)
+	}
+	if body["max_tokens"] != float64(4096) {
+		t.Errorf("max_tokens = %v, want the caller's 4096", body["max_tokens"])
+	}
+}
@@ -5,7 +5,7 @@
 //
 // Azure OpenAI differs from standard OpenAI in URL structure and authentication:
 // - URL: {base}/openai/deployments/{deployment}/chat/completions?api-version={version}
-// - Auth: api-key header (not Authorization: Bearer)
+// - Auth: api-key header (not Authorization: Bearer), or an Azure AD bearer token
 // - Uses deployment names instead of model names
 //
 // Basic usage:
@@ -35,7 +35,9 @@ import (
 	"time"
 
 	"github.com/blue-context/warp"
+	"github.com/blue-context/warp/internal/tokencache"
 	"github.com/blue-context/warp/provider"
+	"github.com/blue-context/warp/provider/providercore"
 )
 
 // Provider implements the provider.Provider interface for Azure OpenAI.
@@ -44,6 +46,7 @@ import (
 // Multiple goroutines may call methods on the same Provider instance simultaneously.
 type Provider struct {
 	apiKey     string
+	tokens     *tokencache.Cache
 	apiBase    string
 	apiVersion string
 	deployment string
@@ -58,7 +61,8 @@ type Option func(*Provider)
 
 // NewProvider creates a new Azure OpenAI provider with the given options.
 //
-// The provider requires an API key, endpoint, and deployment name to be set.
+// The provider requires an API key (or an Azure AD token source), endpoint,
+// and deployment name to be set.
 // API version is optional and defaults to "2024-02-15-preview".
 //
 // Example:
@@ -71,7 +75,7 @@ type Option func(*Provider)
 func NewProvider(opts ...Option) (*Provider, error) {
 	p := &Provider{
 		apiVersion: "2024-02-15-preview",
-		httpClient: &http.Client{Timeout: 60 * time.Second},
+		httpClient: providercore.NewHTTPClient(),
 	}
 
 	for _, opt := range opts {
@@ -79,9 +83,9 @@ func NewProvider(opts ...Option) (*Provider, error) {
 	}
 
 	// Validate required fields
-	if p.apiKey == "" {
+	if p.apiKey == "" && p.tokens == nil {
 		return nil, &warp.WarpError{
-			Message:  "Azure API key is required",
+			Message:  "Azure API key is required (or use WithTokenSource for Azure AD)",
 			Provider: "azure",
 		}
 	}
@@ -123,6 +127,40 @@ func WithAPIKey(key string) Option {
 	}
 }
 
+// WithTokenSource authenticates with Azure AD (Microsoft Entra ID) bearer
+// tokens instead of an API key.
+//
+// source fetches a new access token for the
+// https://cognitiveservices.azure.com/.default scope and returns it with
+// its expiry. Tokens are cached and renewed in the background before they
+// expire, with jittered early renewal and a clock skew margin; concurrent
+// requests share one call to source, so bursts do not stampede the token
+// endpoint.
+//
+// Example:
+//
+//	provider, err := azure.NewProvider(
+//	    azure.WithTokenSource(func(ctx context.Context) (string, time.Time, error) {
+//	        tok, err := credential.GetToken(ctx, policy.TokenRequestOptions{
+//	            Scopes: []string{"https://cognitiveservices.azure.com/.default"},
+//	        })
+//	        return tok.Token, tok.ExpiresOn, err
+//	    }),
+//	    azure.WithEndpoint("https://your-resource.openai.azure.com"),
+//	    azure.WithDeployment("gpt-4-deployment"),
+//	)
+func WithTokenSource(source func(ctx context.Context) (token string, expiry time.Time, err error)) Option {
+	return func(p *Provider) {
+		p.tokens = tokencache.New(func(ctx context.Context) (*tokencache.Token, error) {
+			token, expiry, err := source(ctx)
+			if err != nil {
+				return nil, err
+			}
+			return &tokencache.Token{AccessToken: token, Expiry: expiry}, nil
+		})
+	}
+}
+
 // WithEndpoint sets the Azure OpenAI endpoint URL.
 //
 // This option is required. Without it, NewProvider will return an error.
@@ -200,6 +238,31 @@ func WithHTTPClient(client warp.HTTPClient) Option {
 	}
 }
 
+// core returns the HTTP client for the Azure OpenAI API.
+func (p *Provider) core() *providercore.Client {
+	return &providercore.Client{
+		Provider:   "azure",
+		BaseURL:    p.apiBase,
+		HTTPClient: p.httpClient,
+		Prepare: func(req *http.Request) error {
+			if p.tokens != nil {
+				token, err := p.tokens.Token(req.Context())
+				if err != nil {
+					return &warp.WarpError{
+						Message:       "failed to get Azure AD token",
+						Provider:      "azure",
+						OriginalError: err,
+					}
+				}
+				req.Header.Set("Authorization", "Bearer "+token)
+				return nil
+			}
+			req.Header.Set("api-key", p.apiKey) // Azure uses api-key, not Bearer token
+			return nil
+		},
+	}
+}
+
 // Name returns the provider name "azure".
 //
 // This is used for provider identification in the registry and error messages.
@@ -10,9 +10,11 @@ import (
 	"os"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/blue-context/warp"
 	prov "github.com/blue-context/warp/provider"
+	"github.com/blue-context/warp/provider/providercore"
 )
 
 // mockHTTPClient is a mock HTTP client for testing
@@ -371,28 +373,28 @@ func TestCompletion(t *testing.T) {
 	}
 }
 
-// TestTransformMessages tests the transformMessages function
+// TestTransformMessages tests the conversion of messages to the OpenAI format
 func TestTransformMessages(t *testing.T) {
 	tests := []struct {
 		name     string
 		messages []warp.Message
-		validate func(*testing.T, []map[string]any)
+		validate func(*testing.T, []providercore.Message)
 	}{
 		{
 			name: "simple text message",
 			messages: []warp.Message{
 				{Role: "user", Content: "Hello"},
 			},
-			validate: func(t *testing.T, result []map[string]any) {
+			validate: func(t *testing.T, result []providercore.Message) {
 				if len(result) != 1 {
 					t.Errorf("len(result) = %v, want 1", len(result))
 					return
 				}
-				if result[0]["role"] != "user" {
-					t.Errorf("role = %v, want user", result[0]["role"])
+				if result[0].Role != "user" {
+					t.Errorf("role = %v, want user", result[0].Role)
 				}
-				if result[0]["content"] != "Hello" {
-					t.Errorf("content = %v, want Hello", result[0]["content"])
+				if result[0].Content != "Hello" {
+					t.Errorf("content = %v, want Hello", result[0].Content)
 				}
 			},
 		},
@@ -412,14 +414,14 @@ func TestTransformMessages(t *testing.T) {
 					},
 				},
 			},
-			validate: func(t *testing.T, result []map[string]any) {
+			validate: func(t *testing.T, result []providercore.Message) {
 				if len(result) != 1 {
 					t.Errorf("len(result) = %v, want 1", len(result))
 					return
 				}
-				content, ok := result[0]["content"].([]map[string]any)
+				content, ok := result[0].Content.([]warp.ContentPart)
 				if !ok {
-					t.Errorf("content type = %T, want []map[string]any", result[0]["content"])
+					t.Errorf("content type = %T, want []warp.ContentPart", result[0].Content)
 					return
 				}
 				if len(content) != 2 {
@@ -444,25 +446,21 @@ func TestTransformMessages(t *testing.T) {
 					},
 				},
 			},
-			validate: func(t *testing.T, result []map[string]any) {
+			validate: func(t *testing.T, result []providercore.Message) {
 				if len(result) != 1 {
 					t.Errorf("len(result) = %v, want 1", len(result))
 					return
 				}
-				toolCalls, ok := result[0]["tool_calls"]
-				if !ok {
+				if len(result[0].ToolCalls) == 0 {
 					t.Error("tool_calls not present in result")
 				}
-				if toolCalls == nil {
-					t.Error("tool_calls is nil")
-				}
 			},
 		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			result := transformMessages(tt.messages)
+			result := providercore.Messages(tt.messages)
 			if tt.validate != nil {
 				tt.validate(t, result)
 			}
@@ -897,6 +895,184 @@ func TestHTTPClientError(t *testing.T) {
 	}
 }
 
+func TestCompletionContentFilter(t *testing.T) {
+	tests := []struct {
+		name       string
+		statusCode int
+		body       string
+		wantPrompt bool
+		wantBlock  string
+	}{
+		{
+			name:       "prompt filtered",
+			statusCode: http.StatusBadRequest,
+			body: `{"error": {"message": "The prompt was filtered", "code": "content_filter", "innererror": {
+				"code": "ResponsibleAIPolicyViolation",
+				"content_filter_result": {
+					"hate": {"filtered": false, "severity": "safe"},
+					"violence": {"filtered": true, "severity": "medium"}
+				}
+			}}}`,
+			wantPrompt: true,
+			wantBlock:  "violence",
+		},
+		{
+			name:       "completion filtered",
+			statusCode: http.StatusOK,
+			body: `{"id": "chatcmpl-123", "choices": [{
+				"index": 0,
+				"message": {"role": "assistant", "content": null},
+				"finish_reason": "content_filter",
+				"content_filter_results": {
+					"self_harm": {"filtered": true, "severity": "high"},
+					"sexual": {"filtered": false, "severity": "safe"}
+				}
+			}]}`,
+			wantBlock: "self_harm",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockClient := &mockHTTPClient{
+				doFunc: func(req *http.Request) (*http.Response, error) {
+					return &http.Response{
+						StatusCode: tt.statusCode,
+						Body:       io.NopCloser(strings.NewReader(tt.body)),
+						Header:     make(http.Header),
+					}, nil
+				},
+			}
+			provider, err := NewProvider(
+				WithAPIKey("test-key"),
+				WithEndpoint("https://test.openai.azure.com"),
+				WithDeployment("gpt-4-deployment"),
+				WithHTTPClient(mockClient),
+			)
+			if err != nil {
+				t.Fatalf("NewProvider() error = %v", err)
+			}
+
+			_, err = provider.Completion(context.Background(), &warp.CompletionRequest{
+				Model:    "gpt-4",
+				Messages: []warp.Message{{Role: "user", Content: "Hello"}},
+			})
+			var filtered *warp.ContentFilterError
+			if !errors.As(err, &filtered) {
+				t.Fatalf("Completion() error = %v, want ContentFilterError", err)
+			}
+			if filtered.Prompt != tt.wantPrompt {
+				t.Errorf("Prompt = %v, want %v", filtered.Prompt, tt.wantPrompt)
+			}
+			if len(filtered.Results) != 2 {
+				t.Errorf("Results = %+v, want 2 categories", filtered.Results)
+			}
+			if got := filtered.FilteredCategories(); len(got) != 1 || got[0] != tt.wantBlock {
+				t.Errorf("FilteredCategories() = %v, want [%s]", got, tt.wantBlock)
+			}
+		})
+	}
+}
+
+func TestCompletionSafetySettings(t *testing.T) {
+	body := `{"id": "chatcmpl-123",
+		"prompt_filter_results": [{"prompt_index": 0, "content_filter_results": {
+			"hate": {"filtered": false, "severity": "safe"}
+		}}],
+		"choices": [{
+			"index": 0,
+			"message": {"role": "assistant", "content": "Hi"},
+			"finish_reason": "stop",
+			"content_filter_results": {
+				"hate": {"filtered": false, "severity": "safe"},
+				"violence": {"filtered": false, "severity": "low"}
+			}
+		}]}`
+	mockClient := &mockHTTPClient{
+		doFunc: func(req *http.Request) (*http.Response, error) {
+			return &http.Response{
+				StatusCode: http.StatusOK,
+				Body:       io.NopCloser(strings.NewReader(body)),
+				Header:     make(http.Header),
+			}, nil
+		},
+	}
+	provider, err := NewProvider(
+		WithAPIKey("test-key"),
+		WithEndpoint("https://test.openai.azure.com"),
+		WithDeployment("gpt-4-deployment"),
+		WithHTTPClient(mockClient),
+	)
+	if err != nil {
+		t.Fatalf("NewProvider() error = %v", err)
+	}
+
+	req := &warp.CompletionRequest{
+		Model:          "gpt-4",
+		Messages:       []warp.Message{{Role: "user", Content: "Hello"}},
+		SafetySettings: warp.SafetySettings{warp.HarmViolence: warp.SafetyBlockMedium},
+	}
+	if _, err := provider.Completion(context.Background(), req); err != nil {
+		t.Fatalf("Completion() error = %v", err)
+	}
+
+	req.SafetySettings[warp.HarmViolence] = warp.SafetyBlockLow
+	_, err = provider.Completion(context.Background(), req)
+	var filtered *warp.ContentFilterError
+	if !errors.As(err, &filtered) {
+		t.Fatalf("Completion() error = %v, want ContentFilterError", err)
+	}
+	if filtered.Prompt {
+		t.Error("Prompt = true, want false")
+	}
+	if got := filtered.FilteredCategories(); len(got) != 1 || got[0] != "violence" {
+		t.Errorf("FilteredCategories() = %v, want [violence]", got)
+	}
+}
+
+func TestWithTokenSource(t *testing.T) {
+	var fetches int
+	var auth, apiKey []string
+	mockClient := &mockHTTPClient{
+		doFunc: func(req *http.Request) (*http.Response, error) {
+			auth = append(auth, req.Header.Get("Authorization"))
+			apiKey = append(apiKey, req.Header.Get("api-key"))
+			return &http.Response{
+				StatusCode: http.StatusOK,
+				Body:       io.NopCloser(strings.NewReader(`{"id": "chatcmpl-123", "choices": []}`)),
+				Header:     make(http.Header),
+			}, nil
+		},
+	}
+	provider, err := NewProvider(
+		WithTokenSource(func(ctx context.Context) (string, time.Time, error) {
+			fetches++
+			return "ad-token", time.Now().Add(time.Hour), nil
+		}),
+		WithEndpoint("https://test.openai.azure.com"),
+		WithDeployment("gpt-4-deployment"),
+		WithHTTPClient(mockClient),
+	)
+	if err != nil {
+		t.Fatalf("NewProvider() error = %v", err)
+	}
+
+	req := &warp.CompletionRequest{Model: "gpt-4", Messages: []warp.Message{{Role: "user", Content: "Hello"}}}
+	for i := 0; i < 2; i++ {
+		if _, err := provider.Completion(context.Background(), req); err != nil {
+			t.Fatalf("Completion() error = %v", err)
+		}
+	}
+	if fetches != 1 {
+		t.Errorf("token source called %d times, want 1", fetches)
+	}
+	for i := range auth {
+		if auth[i] != "Bearer ad-token" || apiKey[i] != "" {
+			t.Errorf("request %d: Authorization = %q, api-key = %q, want bearer token only", i, auth[i], apiKey[i])
+		}
+	}
+}
+
 // Integration tests (requires Azure OpenAI credentials)
 
 func TestIntegrationCompletion(t *testing.T) {
@@ -1,14 +1,14 @@
 package azure
 
 import (
-	"bytes"
 	"context"
 	"encoding/json"
 	"fmt"
 	"io"
-	"net/http"
+	"sort"
 
 	"github.com/blue-context/warp"
+	"github.com/blue-context/warp/provider/providercore"
 )
 
 // Completion sends a chat completion request to Azure OpenAI.
@@ -37,44 +37,133 @@ func (p *Provider) Completion(ctx context.Context, req *warp.CompletionRequest)
 	// Transform request to Azure format (same as OpenAI)
 	azureReq := transformRequest(req)
 
-	// Marshal to JSON
-	body, err := json.Marshal(azureReq)
-	if err != nil {
-		return nil, fmt.Errorf("failed to marshal request: %w", err)
-	}
-
-	// Create HTTP request
-	httpReq, err := http.NewRequestWithContext(ctx, "POST", url, bytes.NewReader(body))
-	if err != nil {
-		return nil, fmt.Errorf("failed to create request: %w", err)
-	}
-
-	// Set Azure-specific headers
-	httpReq.Header.Set("Content-Type", "application/json")
-	httpReq.Header.Set("api-key", p.apiKey) // Azure uses api-key, not Bearer token
-
 	// Send request
-	httpResp, err := p.httpClient.Do(httpReq)
+	httpResp, err := p.core().Post(ctx, url, azureReq)
 	if err != nil {
-		return nil, fmt.Errorf("failed to send request: %w", err)
+		return nil, err
 	}
 	defer httpResp.Body.Close()
 
-	// Check status code
-	if httpResp.StatusCode != http.StatusOK {
-		body, _ := io.ReadAll(httpResp.Body)
-		return nil, warp.ParseProviderError("azure", httpResp.StatusCode, body, nil)
+	respBody, err := io.ReadAll(httpResp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response: %w", err)
 	}
 
 	// Parse response (same format as OpenAI)
 	var resp warp.CompletionResponse
-	if err := json.NewDecoder(httpResp.Body).Decode(&resp); err != nil {
+	if err := json.Unmarshal(respBody, &resp); err != nil {
 		return nil, fmt.Errorf("failed to decode response: %w", err)
 	}
 
+	if err := contentFilterError(&resp, respBody); err != nil {
+		return nil, err
+	}
+	if err := safetySettingsError(req.SafetySettings, respBody); err != nil {
+		return nil, err
+	}
+
 	return &resp, nil
 }
 
+// contentFilterError returns a *warp.ContentFilterError if every choice was
+// stopped by the Azure content filter without producing output, and nil
+// otherwise. body is the raw response, which carries the per-category
+// content_filter_results that warp.CompletionResponse does not.
+func contentFilterError(resp *warp.CompletionResponse, body []byte) error {
+	if len(resp.Choices) == 0 {
+		return nil
+	}
+	for _, choice := range resp.Choices {
+		if choice.FinishReason != "content_filter" || warp.ContentToString(choice.Message) != "" || len(choice.Message.ToolCalls) > 0 {
+			return nil
+		}
+	}
+
+	var raw struct {
+		Choices []struct {
+			ContentFilterResults map[string]struct {
+				Filtered bool   `json:"filtered"`
+				Severity string `json:"severity"`
+			} `json:"content_filter_results"`
+		} `json:"choices"`
+	}
+	var results []warp.ContentFilterResult
+	if err := json.Unmarshal(body, &raw); err == nil && len(raw.Choices) > 0 {
+		for category, r := range raw.Choices[0].ContentFilterResults {
+			results = append(results, warp.ContentFilterResult{Category: category, Severity: r.Severity, Filtered: r.Filtered})
+		}
+		sort.Slice(results, func(i, j int) bool { return results[i].Category < results[j].Category })
+	}
+
+	return warp.NewContentFilterError("response blocked by content filter", "azure", "content_filter", false, results)
+}
+
+// azureHarmCategories maps Azure content filter categories to harm
+// categories.
+var azureHarmCategories = map[string]warp.HarmCategory{
+	"hate":      warp.HarmHate,
+	"sexual":    warp.HarmSexual,
+	"violence":  warp.HarmViolence,
+	"self_harm": warp.HarmSelfHarm,
+}
+
+// safetySettingsError returns a *warp.ContentFilterError if the prompt or
+// first choice has a content filter severity that settings block, and nil
+// otherwise. Azure filters are configured per deployment, so stricter
+// request settings are enforced on the returned severities.
+func safetySettingsError(settings warp.SafetySettings, body []byte) error {
+	if len(settings) == 0 {
+		return nil
+	}
+
+	type filterResults map[string]struct {
+		Filtered bool   `json:"filtered"`
+		Severity string `json:"severity"`
+	}
+	var raw struct {
+		PromptFilterResults []struct {
+			ContentFilterResults filterResults `json:"content_filter_results"`
+		} `json:"prompt_filter_results"`
+		Choices []struct {
+			ContentFilterResults filterResults `json:"content_filter_results"`
+		} `json:"choices"`
+	}
+	if err := json.Unmarshal(body, &raw); err != nil {
+		return nil
+	}
+
+	check := func(filters filterResults) []warp.ContentFilterResult {
+		var results []warp.ContentFilterResult
+		blocked := false
+		for category, r := range filters {
+			harm, ok := azureHarmCategories[category]
+			if !ok {
+				continue
+			}
+			filtered := r.Filtered || settings.Blocks(harm, r.Severity)
+			blocked = blocked || filtered
+			results = append(results, warp.ContentFilterResult{Category: category, Severity: r.Severity, Filtered: filtered})
+		}
+		if !blocked {
+			return nil
+		}
+		sort.Slice(results, func(i, j int) bool { return results[i].Category < results[j].Category })
+		return results
+	}
+
+	for _, prompt := range raw.PromptFilterResults {
+		if results := check(prompt.ContentFilterResults); results != nil {
+			return warp.NewContentFilterError("prompt blocked by safety settings", "azure", "safety_settings", true, results)
+		}
+	}
+	if len(raw.Choices) > 0 {
+		if results := check(raw.Choices[0].ContentFilterResults); results != nil {
+			return warp.NewContentFilterError("response blocked by safety settings", "azure", "safety_settings", false, results)
+		}
+	}
+	return nil
+}
+
 // transformRequest transforms a Warp request to Azure OpenAI format.
 //
 // Azure OpenAI uses the same request format as OpenAI, so we reuse the
@@ -82,7 +171,7 @@ func (p *Provider) Completion(ctx context.Context, req *warp.CompletionRequest)
 // since Azure uses deployment names instead.
 func transformRequest(req *warp.CompletionRequest) map[string]any {
 	azureReq := map[string]any{
-		"messages": transformMessages(req.Messages),
+		"messages": providercore.Messages(req.Messages),
 	}
 
 	// Optional parameters (same as OpenAI)
@@ -125,52 +214,9 @@ func transformRequest(req *warp.CompletionRequest) map[string]any {
 	return azureReq
 }
 
-// transformMessages transforms Warp messages to Azure OpenAI format.
+// MaxStopSequences reports that Azure OpenAI accepts 4 stop sequences.
 //
-// This function handles both simple text content and multimodal content
-// (text + images) according to OpenAI's message format, which Azure also uses.
-func transformMessages(messages []warp.Message) []map[string]any {
-	azureMessages := make([]map[string]any, len(messages))
-
-	for i, msg := range messages {
-		azureMsg := map[string]any{
-			"role": msg.Role,
-		}
-
-		// Handle content (can be string or []ContentPart for multimodal)
-		switch content := msg.Content.(type) {
-		case string:
-			azureMsg["content"] = content
-		case []warp.ContentPart:
-			// Multimodal content (text + images)
-			parts := make([]map[string]any, len(content))
-			for j, part := range content {
-				parts[j] = map[string]any{
-					"type": part.Type,
-				}
-				if part.Text != "" {
-					parts[j]["text"] = part.Text
-				}
-				if part.ImageURL != nil {
-					parts[j]["image_url"] = part.ImageURL
-				}
-			}
-			azureMsg["content"] = parts
-		}
-
-		// Optional fields
-		if msg.Name != "" {
-			azureMsg["name"] = msg.Name
-		}
-		if len(msg.ToolCalls) > 0 {
-			azureMsg["tool_calls"] = msg.ToolCalls
-		}
-		if msg.ToolCallID != "" {
-			azureMsg["tool_call_id"] = msg.ToolCallID
-		}
-
-		azureMessages[i] = azureMsg
-	}
-
-	return azureMessages
+// It implements warp.StopSequenceLimiter.
+func (p *Provider) MaxStopSequences(model string) int {
+	return 4
 }
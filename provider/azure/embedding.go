@@ -1,12 +1,8 @@
 package azure
 
 import (
-	"bytes"
 	"context"
-	"encoding/json"
 	"fmt"
-	"io"
-	"net/http"
 
 	"github.com/blue-context/warp"
 )
@@ -63,39 +59,10 @@ func (p *Provider) Embedding(ctx context.Context, req *warp.EmbeddingRequest) (*
 		azureReq["user"] = req.User
 	}
 
-	// Marshal to JSON
-	body, err := json.Marshal(azureReq)
-	if err != nil {
-		return nil, fmt.Errorf("failed to marshal request: %w", err)
-	}
-
-	// Create HTTP request
-	httpReq, err := http.NewRequestWithContext(ctx, "POST", url, bytes.NewReader(body))
-	if err != nil {
-		return nil, fmt.Errorf("failed to create request: %w", err)
-	}
-
-	// Set Azure-specific headers
-	httpReq.Header.Set("Content-Type", "application/json")
-	httpReq.Header.Set("api-key", p.apiKey) // Azure uses api-key, not Bearer token
-
-	// Send request
-	httpResp, err := p.httpClient.Do(httpReq)
-	if err != nil {
-		return nil, fmt.Errorf("failed to send request: %w", err)
-	}
-	defer httpResp.Body.Close()
-
-	// Check status code
-	if httpResp.StatusCode != http.StatusOK {
-		body, _ := io.ReadAll(httpResp.Body)
-		return nil, warp.ParseProviderError("azure", httpResp.StatusCode, body, nil)
-	}
-
-	// Parse response (same format as OpenAI)
+	// Send request and parse response
 	var resp warp.EmbeddingResponse
-	if err := json.NewDecoder(httpResp.Body).Decode(&resp); err != nil {
-		return nil, fmt.Errorf("failed to decode response: %w", err)
+	if err := p.core().PostJSON(ctx, url, azureReq, &resp); err != nil {
+		return nil, err
 	}
 
 	return &resp, nil
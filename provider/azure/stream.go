@@ -1,15 +1,12 @@
 package azure
 
 import (
-	"bufio"
-	"bytes"
 	"context"
-	"encoding/json"
 	"fmt"
 	"io"
-	"net/http"
 
 	"github.com/blue-context/warp"
+	"github.com/blue-context/warp/sse"
 )
 
 // CompletionStream sends a streaming chat completion request to Azure OpenAI.
@@ -54,34 +51,10 @@ func (p *Provider) CompletionStream(ctx context.Context, req *warp.CompletionReq
 	// Enable streaming for this request
 	azureReq["stream"] = true
 
-	// Marshal to JSON
-	body, err := json.Marshal(azureReq)
-	if err != nil {
-		return nil, fmt.Errorf("failed to marshal request: %w", err)
-	}
-
-	// Create HTTP request
-	httpReq, err := http.NewRequestWithContext(ctx, "POST", url, bytes.NewReader(body))
-	if err != nil {
-		return nil, fmt.Errorf("failed to create request: %w", err)
-	}
-
-	// Set Azure-specific headers
-	httpReq.Header.Set("Content-Type", "application/json")
-	httpReq.Header.Set("api-key", p.apiKey) // Azure uses api-key, not Bearer token
-	httpReq.Header.Set("Accept", "text/event-stream")
-
 	// Send request
-	httpResp, err := p.httpClient.Do(httpReq)
+	httpResp, err := p.core().PostStream(ctx, url, azureReq)
 	if err != nil {
-		return nil, fmt.Errorf("failed to send request: %w", err)
-	}
-
-	// Check status code
-	if httpResp.StatusCode != http.StatusOK {
-		defer httpResp.Body.Close()
-		body, _ := io.ReadAll(httpResp.Body)
-		return nil, warp.ParseProviderError("azure", httpResp.StatusCode, body, nil)
+		return nil, err
 	}
 
 	// Create SSE stream (Azure uses same format as OpenAI)
@@ -97,7 +70,7 @@ func (p *Provider) CompletionStream(ctx context.Context, req *warp.CompletionReq
 // Thread Safety: sseStream is NOT safe for concurrent use.
 // Only one goroutine should call Recv() at a time.
 type sseStream struct {
-	reader *bufio.Reader
+	reader *sse.Reader
 	closer io.Closer
 	ctx    context.Context
 	err    error // Cached error for subsequent Recv calls
@@ -109,7 +82,7 @@ type sseStream struct {
 // CompletionChunk objects.
 func newSSEStream(ctx context.Context, body io.ReadCloser) warp.Stream {
 	return &sseStream{
-		reader: bufio.NewReader(body),
+		reader: sse.NewReader(body, sse.WithBufferReuse()),
 		closer: body,
 		ctx:    ctx,
 	}
@@ -136,42 +109,32 @@ func (s *sseStream) Recv() (*warp.CompletionChunk, error) {
 		default:
 		}
 
-		// Read line
-		line, err := s.reader.ReadBytes('\n')
+		// Read the next event
+		event, err := s.reader.Next()
 		if err != nil {
 			if err == io.EOF {
 				s.err = io.EOF
 				return nil, io.EOF
 			}
-			s.err = fmt.Errorf("failed to read line: %w", err)
+			s.err = fmt.Errorf("failed to read event: %w", err)
 			return nil, s.err
 		}
 
-		// Trim whitespace
-		line = bytes.TrimSpace(line)
-
-		// Skip empty lines
-		if len(line) == 0 {
+		// Skip keep-alive events without data
+		data := event.Data
+		if len(data) == 0 {
 			continue
 		}
 
-		// Parse SSE field - must have "data: " prefix
-		if !bytes.HasPrefix(line, []byte("data: ")) {
-			continue
-		}
-
-		// Extract data after "data: " prefix
-		data := bytes.TrimPrefix(line, []byte("data: "))
-
 		// Check for [DONE] marker
-		if bytes.Equal(data, []byte("[DONE]")) {
+		if event.Done() {
 			s.err = io.EOF
 			return nil, io.EOF
 		}
 
 		// Parse JSON chunk
 		var chunk warp.CompletionChunk
-		if err := json.Unmarshal(data, &chunk); err != nil {
+		if err := s.reader.Decode(&chunk); err != nil {
 			s.err = fmt.Errorf("failed to parse chunk: %w", err)
 			return nil, s.err
 		}
@@ -185,5 +148,7 @@ func (s *sseStream) Recv() (*warp.CompletionChunk, error) {
 // It is safe to call Close multiple times.
 // Close must be called even if Recv returns an error.
 func (s *sseStream) Close() error {
-	return s.closer.Close()
+	err := s.closer.Close()
+	s.reader.Release()
+	return err
 }
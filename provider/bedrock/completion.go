@@ -113,6 +113,9 @@ func (p *Provider) Completion(ctx context.Context, req *warp.CompletionRequest)
 	httpReq.Header.Set("Content-Type", "application/json")
 	httpReq.Header.Set("Accept", "application/json")
 
+	// Add User-Agent and client request headers
+	warp.SetRequestHeaders(httpReq)
+
 	// Sign request with AWS Signature V4 (session token is included by signer)
 	if err := p.signer.SignRequest(httpReq, body); err != nil {
 		return nil, &warp.WarpError{
@@ -133,6 +136,7 @@ func (p *Provider) Completion(ctx context.Context, req *warp.CompletionRequest)
 			OriginalError: err,
 		}
 	}
+	warp.RecordResponse(httpResp)
 	defer httpResp.Body.Close()
 
 	// Check for errors
@@ -136,6 +136,9 @@ func (p *Provider) CompletionStream(ctx context.Context, req *warp.CompletionReq
 	httpReq.Header.Set("Content-Type", "application/json")
 	httpReq.Header.Set("Accept", "application/vnd.amazon.eventstream")
 
+	// Add User-Agent and client request headers
+	warp.SetRequestHeaders(httpReq)
+
 	// Sign request with AWS Signature V4 (session token is included by signer)
 	if err := p.signer.SignRequest(httpReq, body); err != nil {
 		return nil, &warp.WarpError{
@@ -156,6 +159,7 @@ func (p *Provider) CompletionStream(ctx context.Context, req *warp.CompletionReq
 			OriginalError: err,
 		}
 	}
+	warp.RecordResponse(httpResp)
 
 	// Check for errors
 	if httpResp.StatusCode != http.StatusOK {
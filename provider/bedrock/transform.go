@@ -286,6 +286,8 @@ func parseClaudeResponse(body io.Reader) (*warp.CompletionResponse, error) {
 		finishReason = "stop"
 	case "tool_use":
 		finishReason = "tool_calls"
+	case "guardrail_intervened", "content_filtered":
+		finishReason = "content_filter"
 	}
 
 	// Build response
@@ -311,6 +313,7 @@ func parseClaudeResponse(body io.Reader) (*warp.CompletionResponse, error) {
 		},
 	}
 
+	warp.SetNativeFinishReason(resp, bedrockResp.StopReason)
 	return resp, nil
 }
 
@@ -364,6 +367,7 @@ func parseLlamaResponse(body io.Reader) (*warp.CompletionResponse, error) {
 		},
 	}
 
+	warp.SetNativeFinishReason(resp, bedrockResp.StopReason)
 	return resp, nil
 }
 
@@ -432,6 +436,7 @@ func parseTitanResponse(body io.Reader) (*warp.CompletionResponse, error) {
 		},
 	}
 
+	warp.SetNativeFinishReason(resp, result.CompletionReason)
 	return resp, nil
 }
 
@@ -348,6 +348,9 @@ func TestParseClaudeResponseStopSequence(t *testing.T) {
 	if resp.Choices[0].FinishReason != "stop" {
 		t.Errorf("finish_reason = %q, want %q", resp.Choices[0].FinishReason, "stop")
 	}
+	if resp.NativeFinishReason() != "stop_sequence" {
+		t.Errorf("NativeFinishReason() = %q, want %q", resp.NativeFinishReason(), "stop_sequence")
+	}
 }
 
 func TestTransformClaudeRequestWithTools(t *testing.T) {
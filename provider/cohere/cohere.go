@@ -24,10 +24,10 @@ package cohere
 
 import (
 	"net/http"
-	"time"
 
 	"github.com/blue-context/warp"
 	"github.com/blue-context/warp/provider"
+	"github.com/blue-context/warp/provider/providercore"
 )
 
 // Provider implements the provider.Provider interface for Cohere.
@@ -62,7 +62,7 @@ type Option func(*Provider)
 func NewProvider(opts ...Option) (*Provider, error) {
 	p := &Provider{
 		apiBase:    "https://api.cohere.ai/v1",
-		httpClient: &http.Client{Timeout: 60 * time.Second},
+		httpClient: providercore.NewHTTPClient(),
 	}
 
 	for _, opt := range opts {
@@ -132,6 +132,19 @@ func WithHTTPClient(client warp.HTTPClient) Option {
 	}
 }
 
+// core returns the HTTP client for the Cohere API.
+func (p *Provider) core() *providercore.Client {
+	return &providercore.Client{
+		Provider:   "cohere",
+		BaseURL:    p.apiBase,
+		HTTPClient: p.httpClient,
+		Prepare: func(req *http.Request) error {
+			req.Header.Set("Authorization", "Bearer "+p.apiKey)
+			return nil
+		},
+	}
+}
+
 // Name returns the provider name "cohere".
 //
 // This is used for provider identification in the registry and error messages.
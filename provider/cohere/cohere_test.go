@@ -3,9 +3,11 @@ package cohere
 import (
 	"bytes"
 	"context"
+	"encoding/json"
 	"errors"
 	"io"
 	"net/http"
+	"reflect"
 	"strings"
 	"testing"
 
@@ -536,6 +538,9 @@ func TestMapCohereFinishReason(t *testing.T) {
 		{"COMPLETE", "stop"},
 		{"MAX_TOKENS", "length"},
 		{"ERROR", "error"},
+		{"ERROR_TOXIC", "content_filter"},
+		{"ERROR_LIMIT", "length"},
+		{"TOOL_CALL", "tool_calls"},
 		{"UNKNOWN", "stop"},
 	}
 
@@ -549,6 +554,42 @@ func TestMapCohereFinishReason(t *testing.T) {
 	}
 }
 
+// TestMapCohereFinishReasonNormalizes tests that mapped finish reasons
+// agree with warp.NormalizeFinishReason on the native values.
+func TestMapCohereFinishReasonNormalizes(t *testing.T) {
+	for _, native := range []string{"COMPLETE", "MAX_TOKENS", "ERROR", "ERROR_TOXIC", "ERROR_LIMIT", "TOOL_CALL"} {
+		t.Run(native, func(t *testing.T) {
+			mapped := warp.NormalizeFinishReason(mapCohereFinishReason(native))
+			if want := warp.NormalizeFinishReason(native); mapped != want {
+				t.Errorf("mapCohereFinishReason(%v) normalizes to %v, native normalizes to %v", native, mapped, want)
+			}
+		})
+	}
+}
+
+func TestTransformCohereCitations(t *testing.T) {
+	body := `{
+		"generation_id": "gen-1",
+		"text": "Go was released in 2009.",
+		"finish_reason": "COMPLETE",
+		"citations": [{"start": 19, "end": 23, "text": "2009", "document_ids": ["doc_0", "doc_1"]}],
+		"documents": [{"id": "doc_0", "title": "Go", "url": "https://go.dev"}, {"id": "doc_1", "snippet": "..."}]
+	}`
+	var cohereResp cohereResponse
+	if err := json.Unmarshal([]byte(body), &cohereResp); err != nil {
+		t.Fatal(err)
+	}
+
+	got := transformFromCohereResponse(&cohereResp).Citations
+	want := []warp.Citation{
+		{URL: "https://go.dev", Title: "Go", Text: "2009", StartIndex: 19, EndIndex: 23, DocumentID: "doc_0"},
+		{Text: "2009", StartIndex: 19, EndIndex: 23, DocumentID: "doc_1"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Citations = %+v, want %+v", got, want)
+	}
+}
+
 // TestConcurrentRequests tests thread safety
 func TestConcurrentRequests(t *testing.T) {
 	mockClient := &mockHTTPClient{
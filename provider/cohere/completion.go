@@ -1,12 +1,8 @@
 package cohere
 
 import (
-	"bytes"
 	"context"
-	"encoding/json"
-	"fmt"
 	"io"
-	"net/http"
 
 	"github.com/blue-context/warp"
 )
@@ -31,39 +27,10 @@ func (p *Provider) Completion(ctx context.Context, req *warp.CompletionRequest)
 	// Transform request to Cohere format
 	cohereReq := transformToCohereRequest(req)
 
-	// Marshal to JSON
-	body, err := json.Marshal(cohereReq)
-	if err != nil {
-		return nil, fmt.Errorf("failed to marshal request: %w", err)
-	}
-
-	// Create HTTP request
-	httpReq, err := http.NewRequestWithContext(ctx, "POST", p.apiBase+"/chat", bytes.NewReader(body))
-	if err != nil {
-		return nil, fmt.Errorf("failed to create request: %w", err)
-	}
-
-	// Set headers
-	httpReq.Header.Set("Content-Type", "application/json")
-	httpReq.Header.Set("Authorization", "Bearer "+p.apiKey)
-
-	// Send request
-	httpResp, err := p.httpClient.Do(httpReq)
-	if err != nil {
-		return nil, fmt.Errorf("failed to send request: %w", err)
-	}
-	defer httpResp.Body.Close()
-
-	// Check status code
-	if httpResp.StatusCode != http.StatusOK {
-		body, _ := io.ReadAll(httpResp.Body)
-		return nil, warp.ParseProviderError("cohere", httpResp.StatusCode, body, nil)
-	}
-
-	// Parse response
+	// Send request and parse response
 	var cohereResp cohereResponse
-	if err := json.NewDecoder(httpResp.Body).Decode(&cohereResp); err != nil {
-		return nil, fmt.Errorf("failed to decode response: %w", err)
+	if err := p.core().PostJSON(ctx, "/chat", cohereReq, &cohereResp); err != nil {
+		return nil, err
 	}
 
 	// Transform to Warp format
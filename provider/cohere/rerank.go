@@ -1,12 +1,9 @@
 package cohere
 
 import (
-	"bytes"
 	"context"
 	"encoding/json"
 	"fmt"
-	"io"
-	"net/http"
 
 	"github.com/blue-context/warp"
 )
@@ -35,6 +32,9 @@ import (
 //	    TopN: warp.IntPtr(2),
 //	    ReturnDocuments: warp.BoolPtr(true),
 //	})
+//
+// Structured documents are sent as objects with rank_fields selecting the
+// fields to rank.
 func (p *Provider) Rerank(ctx context.Context, req *warp.RerankRequest) (*warp.RerankResponse, error) {
 	// Build Cohere request
 	cohereReq := map[string]any{
@@ -42,6 +42,12 @@ func (p *Provider) Rerank(ctx context.Context, req *warp.RerankRequest) (*warp.R
 		"query":     req.Query,
 		"documents": req.Documents,
 	}
+	if len(req.StructuredDocuments) > 0 {
+		cohereReq["documents"] = req.StructuredDocuments
+		if len(req.RankFields) > 0 {
+			cohereReq["rank_fields"] = req.RankFields
+		}
+	}
 
 	// Add optional parameters
 	if req.TopN != nil {
@@ -54,44 +60,20 @@ func (p *Provider) Rerank(ctx context.Context, req *warp.RerankRequest) (*warp.R
 		cohereReq["max_chunks_per_doc"] = *req.MaxChunksPerDoc
 	}
 
-	// Marshal request
-	body, err := json.Marshal(cohereReq)
-	if err != nil {
-		return nil, fmt.Errorf("failed to marshal request: %w", err)
-	}
-
-	// Create HTTP request
-	httpReq, err := http.NewRequestWithContext(ctx, "POST", p.apiBase+"/rerank", bytes.NewReader(body))
-	if err != nil {
-		return nil, fmt.Errorf("failed to create request: %w", err)
-	}
-
-	// Set headers
-	httpReq.Header.Set("Content-Type", "application/json")
-	httpReq.Header.Set("Authorization", "Bearer "+p.apiKey)
-
 	// Send request
-	httpResp, err := p.httpClient.Do(httpReq)
+	httpResp, err := p.core().Post(ctx, "/rerank", cohereReq)
 	if err != nil {
-		return nil, fmt.Errorf("failed to send request: %w", err)
+		return nil, err
 	}
 	defer httpResp.Body.Close()
 
-	// Check status code
-	if httpResp.StatusCode != http.StatusOK {
-		bodyBytes, _ := io.ReadAll(httpResp.Body)
-		return nil, warp.ParseProviderError("cohere", httpResp.StatusCode, bodyBytes, nil)
-	}
-
 	// Parse Cohere response
 	var cohereResp struct {
 		ID      string `json:"id"`
 		Results []struct {
-			Index          int     `json:"index"`
-			RelevanceScore float64 `json:"relevance_score"`
-			Document       *struct {
-				Text string `json:"text"`
-			} `json:"document,omitempty"`
+			Index          int            `json:"index"`
+			RelevanceScore float64        `json:"relevance_score"`
+			Document       map[string]any `json:"document,omitempty"`
 		} `json:"results"`
 		Meta *warp.RerankMeta `json:"meta,omitempty"`
 	}
@@ -112,8 +94,8 @@ func (p *Provider) Rerank(ctx context.Context, req *warp.RerankRequest) (*warp.R
 			Index:          r.Index,
 			RelevanceScore: r.RelevanceScore,
 		}
-		if r.Document != nil {
-			result.Document = r.Document.Text
+		if text, ok := r.Document["text"].(string); ok {
+			result.Document = text
 		}
 		resp.Results[i] = result
 	}
@@ -349,3 +349,42 @@ func TestRerankContextCancellation(t *testing.T) {
 		t.Error("Rerank() expected error with cancelled context, got nil")
 	}
 }
+
+func TestRerankStructuredDocuments(t *testing.T) {
+	var reqBody map[string]any
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewDecoder(r.Body).Decode(&reqBody)
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]any{
+			"id":      "test",
+			"results": []map[string]any{{"index": 0, "relevance_score": 0.9}},
+		})
+	}))
+	defer server.Close()
+
+	provider, err := NewProvider(WithAPIKey("test-key"), WithAPIBase(server.URL))
+	if err != nil {
+		t.Fatalf("NewProvider() error: %v", err)
+	}
+
+	_, err = provider.Rerank(context.Background(), &warp.RerankRequest{
+		Model:               "rerank-v3.5",
+		Query:               "test query",
+		StructuredDocuments: []map[string]any{{"title": "Doc", "body": "text"}},
+		RankFields:          []string{"title", "body"},
+	})
+	if err != nil {
+		t.Fatalf("Rerank() error: %v", err)
+	}
+
+	docs, ok := reqBody["documents"].([]any)
+	if !ok || len(docs) != 1 {
+		t.Fatalf("Request documents = %v, want one object", reqBody["documents"])
+	}
+	if doc, ok := docs[0].(map[string]any); !ok || doc["title"] != "Doc" {
+		t.Errorf("Request document = %v, want original object", docs[0])
+	}
+	if fields, ok := reqBody["rank_fields"].([]any); !ok || len(fields) != 2 || fields[0] != "title" {
+		t.Errorf("Request rank_fields = %v, want [title body]", reqBody["rank_fields"])
+	}
+}
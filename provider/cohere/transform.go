@@ -29,12 +29,22 @@ type cohereMessage struct {
 
 // cohereResponse represents a Cohere chat response.
 type cohereResponse struct {
-	ResponseID   string          `json:"response_id"`
-	Text         string          `json:"text"`
-	GenerationID string          `json:"generation_id"`
-	ChatHistory  []cohereMessage `json:"chat_history"`
-	FinishReason string          `json:"finish_reason"`
-	Meta         cohereMeta      `json:"meta"`
+	ResponseID   string           `json:"response_id"`
+	Text         string           `json:"text"`
+	GenerationID string           `json:"generation_id"`
+	ChatHistory  []cohereMessage  `json:"chat_history"`
+	FinishReason string           `json:"finish_reason"`
+	Meta         cohereMeta       `json:"meta"`
+	Citations    []cohereCitation `json:"citations,omitempty"`
+	Documents    []map[string]any `json:"documents,omitempty"`
+}
+
+// cohereCitation is a span of the response text citing documents.
+type cohereCitation struct {
+	Start       int      `json:"start"`
+	End         int      `json:"end"`
+	Text        string   `json:"text"`
+	DocumentIDs []string `json:"document_ids"`
 }
 
 // cohereMeta contains metadata about the response.
@@ -143,7 +153,7 @@ func extractTextContent(content any) string {
 // - Convert role names back to OpenAI format
 // - Extract token usage from meta
 func transformFromCohereResponse(cohereResp *cohereResponse) *warp.CompletionResponse {
-	return &warp.CompletionResponse{
+	resp := &warp.CompletionResponse{
 		ID:      cohereResp.GenerationID,
 		Object:  "chat.completion",
 		Created: 0,  // Cohere doesn't provide timestamp
@@ -164,6 +174,34 @@ func transformFromCohereResponse(cohereResp *cohereResponse) *warp.CompletionRes
 			TotalTokens:      cohereResp.Meta.BilledUnits.InputTokens + cohereResp.Meta.BilledUnits.OutputTokens,
 		},
 	}
+	warp.SetNativeFinishReason(resp, cohereResp.FinishReason)
+	resp.Citations = transformCohereCitations(cohereResp)
+
+	return resp
+}
+
+// transformCohereCitations returns a citation for each document each span
+// of the response cites, with the document's title and URL if it has them.
+func transformCohereCitations(cohereResp *cohereResponse) []warp.Citation {
+	documents := make(map[string]map[string]any, len(cohereResp.Documents))
+	for _, doc := range cohereResp.Documents {
+		if id, ok := doc["id"].(string); ok {
+			documents[id] = doc
+		}
+	}
+
+	var citations []warp.Citation
+	for _, c := range cohereResp.Citations {
+		for _, id := range c.DocumentIDs {
+			citation := warp.Citation{Text: c.Text, StartIndex: c.Start, EndIndex: c.End, DocumentID: id}
+			if doc, ok := documents[id]; ok {
+				citation.URL, _ = doc["url"].(string)
+				citation.Title, _ = doc["title"].(string)
+			}
+			citations = append(citations, citation)
+		}
+	}
+	return citations
 }
 
 // mapCohereFinishReason maps Cohere finish reasons to OpenAI format.
@@ -175,6 +213,12 @@ func mapCohereFinishReason(reason string) string {
 		return "length"
 	case "ERROR":
 		return "error"
+	case "ERROR_TOXIC":
+		return "content_filter"
+	case "ERROR_LIMIT":
+		return "length"
+	case "TOOL_CALL":
+		return "tool_calls"
 	default:
 		return "stop"
 	}
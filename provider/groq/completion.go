@@ -1,14 +1,10 @@
 package groq
 
 import (
-	"bytes"
 	"context"
-	"encoding/json"
-	"fmt"
-	"io"
-	"net/http"
 
 	"github.com/blue-context/warp"
+	"github.com/blue-context/warp/provider/providercore"
 )
 
 // Completion sends a chat completion request to Groq.
@@ -32,39 +28,10 @@ func (p *Provider) Completion(ctx context.Context, req *warp.CompletionRequest)
 	// Transform request to Groq format (OpenAI-compatible)
 	groqReq := transformRequest(req)
 
-	// Marshal to JSON
-	body, err := json.Marshal(groqReq)
-	if err != nil {
-		return nil, fmt.Errorf("failed to marshal request: %w", err)
-	}
-
-	// Create HTTP request
-	httpReq, err := http.NewRequestWithContext(ctx, "POST", p.apiBase+"/chat/completions", bytes.NewReader(body))
-	if err != nil {
-		return nil, fmt.Errorf("failed to create request: %w", err)
-	}
-
-	// Set headers
-	httpReq.Header.Set("Content-Type", "application/json")
-	httpReq.Header.Set("Authorization", "Bearer "+p.apiKey)
-
-	// Send request
-	httpResp, err := p.httpClient.Do(httpReq)
-	if err != nil {
-		return nil, fmt.Errorf("failed to send request: %w", err)
-	}
-	defer httpResp.Body.Close()
-
-	// Check status code
-	if httpResp.StatusCode != http.StatusOK {
-		body, _ := io.ReadAll(httpResp.Body)
-		return nil, warp.ParseProviderError("groq", httpResp.StatusCode, body, nil)
-	}
-
-	// Parse response
+	// Send request and parse response
 	var resp warp.CompletionResponse
-	if err := json.NewDecoder(httpResp.Body).Decode(&resp); err != nil {
-		return nil, fmt.Errorf("failed to decode response: %w", err)
+	if err := p.core().PostJSON(ctx, "/chat/completions", groqReq, &resp); err != nil {
+		return nil, err
 	}
 
 	return &resp, nil
@@ -77,7 +44,7 @@ func (p *Provider) Completion(ctx context.Context, req *warp.CompletionRequest)
 func transformRequest(req *warp.CompletionRequest) map[string]any {
 	groqReq := map[string]any{
 		"model":    req.Model,
-		"messages": transformMessages(req.Messages),
+		"messages": providercore.Messages(req.Messages),
 	}
 
 	// Optional parameters
@@ -119,51 +86,9 @@ func transformRequest(req *warp.CompletionRequest) map[string]any {
 	return groqReq
 }
 
-// transformMessages transforms Warp messages to Groq format.
+// MaxStopSequences reports that Groq accepts 4 stop sequences.
 //
-// Groq uses OpenAI-compatible message format.
-func transformMessages(messages []warp.Message) []map[string]any {
-	groqMessages := make([]map[string]any, len(messages))
-
-	for i, msg := range messages {
-		groqMsg := map[string]any{
-			"role": msg.Role,
-		}
-
-		// Handle content (can be string or []ContentPart for multimodal)
-		switch content := msg.Content.(type) {
-		case string:
-			groqMsg["content"] = content
-		case []warp.ContentPart:
-			// Multimodal content (though Groq doesn't support vision yet)
-			parts := make([]map[string]any, len(content))
-			for j, part := range content {
-				parts[j] = map[string]any{
-					"type": part.Type,
-				}
-				if part.Text != "" {
-					parts[j]["text"] = part.Text
-				}
-				if part.ImageURL != nil {
-					parts[j]["image_url"] = part.ImageURL
-				}
-			}
-			groqMsg["content"] = parts
-		}
-
-		// Optional fields
-		if msg.Name != "" {
-			groqMsg["name"] = msg.Name
-		}
-		if len(msg.ToolCalls) > 0 {
-			groqMsg["tool_calls"] = msg.ToolCalls
-		}
-		if msg.ToolCallID != "" {
-			groqMsg["tool_call_id"] = msg.ToolCallID
-		}
-
-		groqMessages[i] = groqMsg
-	}
-
-	return groqMessages
+// It implements warp.StopSequenceLimiter.
+func (p *Provider) MaxStopSequences(model string) int {
+	return 4
 }
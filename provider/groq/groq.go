@@ -26,10 +26,10 @@ import (
 	"context"
 	"io"
 	"net/http"
-	"time"
 
 	"github.com/blue-context/warp"
 	"github.com/blue-context/warp/provider"
+	"github.com/blue-context/warp/provider/providercore"
 )
 
 // Provider implements the provider.Provider interface for Groq.
@@ -65,7 +65,7 @@ type Option func(*Provider)
 func NewProvider(opts ...Option) (*Provider, error) {
 	p := &Provider{
 		apiBase:    "https://api.groq.com/openai/v1",
-		httpClient: &http.Client{Timeout: 60 * time.Second},
+		httpClient: providercore.NewHTTPClient(),
 	}
 
 	for _, opt := range opts {
@@ -135,6 +135,19 @@ func WithHTTPClient(client warp.HTTPClient) Option {
 	}
 }
 
+// core returns the HTTP client for the Groq API.
+func (p *Provider) core() *providercore.Client {
+	return &providercore.Client{
+		Provider:   "groq",
+		BaseURL:    p.apiBase,
+		HTTPClient: p.httpClient,
+		Prepare: func(req *http.Request) error {
+			req.Header.Set("Authorization", "Bearer "+p.apiKey)
+			return nil
+		},
+	}
+}
+
 // Name returns the provider name "groq".
 //
 // This is used for provider identification in the registry and error messages.
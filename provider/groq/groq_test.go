@@ -11,6 +11,7 @@ import (
 
 	"github.com/blue-context/warp"
 	prov "github.com/blue-context/warp/provider"
+	"github.com/blue-context/warp/provider/providercore"
 )
 
 // mockHTTPClient is a mock HTTP client for testing
@@ -471,7 +472,7 @@ func TestTransformRequest(t *testing.T) {
 				if m["model"] != "llama3-70b-8192" {
 					t.Errorf("model = %v, want llama3-70b-8192", m["model"])
 				}
-				messages, ok := m["messages"].([]map[string]any)
+				messages, ok := m["messages"].([]providercore.Message)
 				if !ok || len(messages) != 1 {
 					t.Errorf("messages invalid or wrong length")
 				}
@@ -0,0 +1,731 @@
+// Package multiregion combines regional deployments of one provider (Azure
+// OpenAI regions, Bedrock regions, Vertex AI locations) into a single
+// provider with region-aware failover.
+//
+// Requests go to the first healthy region, in the order the regions were
+// given. A region that fails with a retryable or network error is marked
+// unhealthy after a number of consecutive failures and skipped until a
+// cooldown passes; the request fails over to the next region. Requests
+// rejected for other reasons (invalid requests, authentication) are
+// returned without failover.
+//
+// An optional RegionFilter restricts the regions a request may use, for
+// data residency. The policy engine's AllowedRegions method is one.
+//
+// Cached content is stored in the region that created it, and requests
+// that reference it are sent only to that region.
+//
+// Basic usage:
+//
+//	eastUS, _ := azure.NewProvider(azure.WithEndpoint("https://acme-eastus.openai.azure.com"), ...)
+//	westEU, _ := azure.NewProvider(azure.WithEndpoint("https://acme-westeurope.openai.azure.com"), ...)
+//
+//	p, err := multiregion.New("azure",
+//	    []multiregion.Region{
+//	        {Name: "eastus", Provider: eastUS},
+//	        {Name: "westeurope", Provider: westEU},
+//	    },
+//	    multiregion.WithRegionFilter(engine.AllowedRegions),
+//	)
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+//	client.RegisterProvider(p)
+package multiregion
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/blue-context/warp"
+	"github.com/blue-context/warp/provider"
+)
+
+// ProviderFieldRegion is the CompletionResponse.ProviderFields key holding
+// the name of the region that served the request.
+const ProviderFieldRegion = "region"
+
+// ruleAllowedRegions matches policy.RuleAllowedRegions.
+const ruleAllowedRegions = "allowed_regions"
+
+// Region is one regional deployment of a provider.
+type Region struct {
+	// Name identifies the region (e.g., "eastus", "us-east-1", "europe-west4").
+	Name string
+
+	// Provider sends requests to this region.
+	Provider provider.Provider
+}
+
+// RegionFilter returns the names of the regions a request with the given
+// metadata may be processed in. A nil result allows all regions; an empty,
+// non-nil result allows none.
+type RegionFilter func(ctx context.Context, metadata map[string]any) []string
+
+// RegionHealth reports the health of a region.
+type RegionHealth struct {
+	// Name is the region name.
+	Name string
+
+	// Healthy reports whether the region receives requests in order.
+	Healthy bool
+
+	// ConsecutiveFailures counts failovers since the last success.
+	ConsecutiveFailures int
+
+	// LastError is the error of the most recent failure, if any.
+	LastError error
+
+	// DownUntil is when an unhealthy region is tried again in order.
+	DownUntil time.Time
+}
+
+// regionState tracks the health of a region.
+type regionState struct {
+	Region
+
+	mu        sync.Mutex
+	failures  int
+	lastErr   error
+	downUntil time.Time
+}
+
+// Provider routes requests to regional deployments of a provider with
+// failover.
+//
+// Thread Safety: Provider is safe for concurrent use.
+type Provider struct {
+	name      string
+	regions   []*regionState
+	threshold int
+	cooldown  time.Duration
+	filter    RegionFilter
+	now       func() time.Time
+}
+
+// Compile-time interface checks
+var (
+	_ provider.Provider            = (*Provider)(nil)
+	_ warp.DataRetentionController = (*Provider)(nil)
+	_ warp.DeveloperRoleSupporter  = (*Provider)(nil)
+	_ warp.StopSequenceLimiter     = (*Provider)(nil)
+	_ warp.QuotaReporter           = (*Provider)(nil)
+	_ warp.CachedContentManager    = (*Provider)(nil)
+	_ warp.ResponsesProvider       = (*Provider)(nil)
+)
+
+// errRegionUnsupported is returned by a call function when a region does
+// not support the operation; the region is skipped without counting a
+// failure.
+var errRegionUnsupported = errors.New("operation not supported by region")
+
+// Option is a functional option for configuring the provider.
+type Option func(*Provider)
+
+// WithFailureThreshold sets how many consecutive failures mark a region
+// unhealthy.
+//
+// Default: 1
+func WithFailureThreshold(n int) Option {
+	return func(p *Provider) {
+		p.threshold = n
+	}
+}
+
+// WithCooldown sets how long an unhealthy region is skipped.
+//
+// Default: 30 seconds
+func WithCooldown(d time.Duration) Option {
+	return func(p *Provider) {
+		p.cooldown = d
+	}
+}
+
+// WithRegionFilter restricts the regions each request may use.
+//
+// Example:
+//
+//	multiregion.WithRegionFilter(engine.AllowedRegions)
+func WithRegionFilter(filter RegionFilter) Option {
+	return func(p *Provider) {
+		p.filter = filter
+	}
+}
+
+// New creates a provider named name that routes requests across regions,
+// in order of preference.
+//
+// Returns an error if no regions are given, or a region has no name or
+// provider, or two regions share a name.
+func New(name string, regions []Region, opts ...Option) (*Provider, error) {
+	if name == "" {
+		return nil, fmt.Errorf("provider name is required")
+	}
+	if len(regions) == 0 {
+		return nil, fmt.Errorf("at least one region is required")
+	}
+
+	p := &Provider{
+		name:      name,
+		threshold: 1,
+		cooldown:  30 * time.Second,
+		now:       time.Now,
+	}
+
+	seen := make(map[string]bool, len(regions))
+	for _, region := range regions {
+		if region.Name == "" || region.Provider == nil {
+			return nil, fmt.Errorf("region name and provider are required")
+		}
+		if seen[region.Name] {
+			return nil, fmt.Errorf("duplicate region %q", region.Name)
+		}
+		seen[region.Name] = true
+		p.regions = append(p.regions, &regionState{Region: region})
+	}
+
+	for _, opt := range opts {
+		opt(p)
+	}
+	if p.threshold < 1 {
+		return nil, fmt.Errorf("failure threshold must be at least 1")
+	}
+	if p.cooldown < 0 {
+		return nil, fmt.Errorf("cooldown must be non-negative")
+	}
+
+	return p, nil
+}
+
+// Name returns the provider name.
+func (p *Provider) Name() string {
+	return p.name
+}
+
+// Health reports the health of each region, in order of preference.
+func (p *Provider) Health() []RegionHealth {
+	now := p.now()
+	health := make([]RegionHealth, len(p.regions))
+	for i, r := range p.regions {
+		r.mu.Lock()
+		health[i] = RegionHealth{
+			Name:                r.Name,
+			Healthy:             !now.Before(r.downUntil),
+			ConsecutiveFailures: r.failures,
+			LastError:           r.lastErr,
+			DownUntil:           r.downUntil,
+		}
+		r.mu.Unlock()
+	}
+	return health
+}
+
+// candidates returns the regions a request may use: healthy regions in
+// order of preference, then unhealthy regions, soonest to recover first.
+func (p *Provider) candidates(ctx context.Context, metadata map[string]any) ([]*regionState, error) {
+	var allowed map[string]bool
+	if p.filter != nil {
+		if names := p.filter(ctx, metadata); names != nil {
+			allowed = make(map[string]bool, len(names))
+			for _, name := range names {
+				allowed[name] = true
+			}
+		}
+	}
+
+	now := p.now()
+	var healthy []*regionState
+	var unhealthy []downRegion
+	for _, r := range p.regions {
+		if allowed != nil && !allowed[r.Name] {
+			continue
+		}
+		r.mu.Lock()
+		downUntil := r.downUntil
+		r.mu.Unlock()
+		if now.Before(downUntil) {
+			unhealthy = append(unhealthy, downRegion{r, downUntil})
+		} else {
+			healthy = append(healthy, r)
+		}
+	}
+
+	if len(healthy)+len(unhealthy) == 0 {
+		return nil, warp.NewPolicyViolationError(
+			"no region of the provider satisfies the data residency constraints",
+			ruleAllowedRegions, "", p.name, "")
+	}
+
+	sort.SliceStable(unhealthy, func(i, j int) bool {
+		return unhealthy[i].until.Before(unhealthy[j].until)
+	})
+	for _, d := range unhealthy {
+		healthy = append(healthy, d.region)
+	}
+	return healthy, nil
+}
+
+// downRegion is an unhealthy region and the end of its cooldown.
+type downRegion struct {
+	region *regionState
+	until  time.Time
+}
+
+// recordSuccess marks the region healthy.
+func (p *Provider) recordSuccess(r *regionState) {
+	r.mu.Lock()
+	r.failures = 0
+	r.lastErr = nil
+	r.downUntil = time.Time{}
+	r.mu.Unlock()
+}
+
+// recordFailure counts a failure and marks the region unhealthy once the
+// threshold is reached.
+func (p *Provider) recordFailure(r *regionState, err error) {
+	r.mu.Lock()
+	r.failures++
+	r.lastErr = err
+	if r.failures >= p.threshold {
+		r.downUntil = p.now().Add(p.cooldown)
+	}
+	r.mu.Unlock()
+}
+
+// shouldFailover reports whether err may succeed in another region:
+// retryable provider errors and errors outside the warp error hierarchy,
+// such as network failures. Context cancellation never fails over.
+func shouldFailover(ctx context.Context, err error) bool {
+	if ctx.Err() != nil || errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+
+	var retryable interface{ IsRetryable() bool }
+	if errors.As(err, &retryable) {
+		return retryable.IsRetryable()
+	}
+	return true
+}
+
+// call sends a request to each candidate region until one succeeds or
+// fails with an error that does not warrant failover.
+func call[T any](ctx context.Context, p *Provider, metadata map[string]any, fn func(provider.Provider) (T, error)) (T, string, error) {
+	regions, err := p.candidates(ctx, metadata)
+	if err != nil {
+		var zero T
+		return zero, "", err
+	}
+	return callIn(ctx, p, regions, fn)
+}
+
+// callIn is call with the candidate regions already chosen.
+func callIn[T any](ctx context.Context, p *Provider, regions []*regionState, fn func(provider.Provider) (T, error)) (T, string, error) {
+	var zero T
+	var lastErr error
+	for _, r := range regions {
+		result, err := fn(r.Provider)
+		if errors.Is(err, errRegionUnsupported) {
+			continue
+		}
+		if err == nil {
+			p.recordSuccess(r)
+			return result, r.Name, nil
+		}
+		if !shouldFailover(ctx, err) {
+			return zero, r.Name, err
+		}
+		p.recordFailure(r, err)
+		lastErr = fmt.Errorf("region %s: %w", r.Name, err)
+	}
+	if lastErr == nil {
+		return zero, "", warp.NewInvalidRequestError("no region supports the operation", p.name, nil)
+	}
+	return zero, "", lastErr
+}
+
+// Completion sends a chat completion request to the first available region.
+//
+// The serving region is recorded in the response's ProviderFields under
+// ProviderFieldRegion.
+func (p *Provider) Completion(ctx context.Context, req *warp.CompletionRequest) (*warp.CompletionResponse, error) {
+	regions, req, err := p.completionRegions(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	resp, region, err := callIn(ctx, p, regions, func(rp provider.Provider) (*warp.CompletionResponse, error) {
+		return rp.Completion(ctx, req)
+	})
+	if err != nil {
+		return nil, err
+	}
+	if resp != nil {
+		if resp.ProviderFields == nil {
+			resp.ProviderFields = make(map[string]any)
+		}
+		resp.ProviderFields[ProviderFieldRegion] = region
+	}
+	return resp, nil
+}
+
+// CompletionStream opens a streaming chat completion in the first available
+// region. Failover happens only while opening the stream.
+func (p *Provider) CompletionStream(ctx context.Context, req *warp.CompletionRequest) (warp.Stream, error) {
+	regions, req, err := p.completionRegions(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	stream, _, err := callIn(ctx, p, regions, func(rp provider.Provider) (warp.Stream, error) {
+		return rp.CompletionStream(ctx, req)
+	})
+	return stream, err
+}
+
+// Embedding sends an embedding request to the first available region.
+func (p *Provider) Embedding(ctx context.Context, req *warp.EmbeddingRequest) (*warp.EmbeddingResponse, error) {
+	resp, _, err := call(ctx, p, req.Metadata, func(rp provider.Provider) (*warp.EmbeddingResponse, error) {
+		return rp.Embedding(ctx, req)
+	})
+	return resp, err
+}
+
+// ImageGeneration sends an image generation request to the first available region.
+func (p *Provider) ImageGeneration(ctx context.Context, req *warp.ImageGenerationRequest) (*warp.ImageGenerationResponse, error) {
+	resp, _, err := call(ctx, p, req.Metadata, func(rp provider.Provider) (*warp.ImageGenerationResponse, error) {
+		return rp.ImageGeneration(ctx, req)
+	})
+	return resp, err
+}
+
+// ImageEdit sends an image edit request to the first available region.
+func (p *Provider) ImageEdit(ctx context.Context, req *warp.ImageEditRequest) (*warp.ImageGenerationResponse, error) {
+	resp, _, err := call(ctx, p, req.Metadata, func(rp provider.Provider) (*warp.ImageGenerationResponse, error) {
+		return rp.ImageEdit(ctx, req)
+	})
+	return resp, err
+}
+
+// ImageVariation sends an image variation request to the first available region.
+func (p *Provider) ImageVariation(ctx context.Context, req *warp.ImageVariationRequest) (*warp.ImageGenerationResponse, error) {
+	resp, _, err := call(ctx, p, req.Metadata, func(rp provider.Provider) (*warp.ImageGenerationResponse, error) {
+		return rp.ImageVariation(ctx, req)
+	})
+	return resp, err
+}
+
+// Transcription sends a transcription request to the first available region.
+func (p *Provider) Transcription(ctx context.Context, req *warp.TranscriptionRequest) (*warp.TranscriptionResponse, error) {
+	resp, _, err := call(ctx, p, req.Metadata, func(rp provider.Provider) (*warp.TranscriptionResponse, error) {
+		return rp.Transcription(ctx, req)
+	})
+	return resp, err
+}
+
+// Speech sends a text-to-speech request to the first available region.
+func (p *Provider) Speech(ctx context.Context, req *warp.SpeechRequest) (io.ReadCloser, error) {
+	resp, _, err := call(ctx, p, req.Metadata, func(rp provider.Provider) (io.ReadCloser, error) {
+		return rp.Speech(ctx, req)
+	})
+	return resp, err
+}
+
+// Moderation sends a moderation request to the first available region.
+func (p *Provider) Moderation(ctx context.Context, req *warp.ModerationRequest) (*warp.ModerationResponse, error) {
+	resp, _, err := call(ctx, p, req.Metadata, func(rp provider.Provider) (*warp.ModerationResponse, error) {
+		return rp.Moderation(ctx, req)
+	})
+	return resp, err
+}
+
+// Rerank sends a rerank request to the first available region.
+func (p *Provider) Rerank(ctx context.Context, req *warp.RerankRequest) (*warp.RerankResponse, error) {
+	resp, _, err := call(ctx, p, req.Metadata, func(rp provider.Provider) (*warp.RerankResponse, error) {
+		return rp.Rerank(ctx, req)
+	})
+	return resp, err
+}
+
+// Supports returns the capabilities of the preferred region.
+func (p *Provider) Supports() interface{} {
+	return p.regions[0].Provider.Supports()
+}
+
+// GetModelInfo returns model metadata from the preferred region.
+func (p *Provider) GetModelInfo(model string) *provider.ModelInfo {
+	return p.regions[0].Provider.GetModelInfo(model)
+}
+
+// ListModels returns the models of the preferred region.
+func (p *Provider) ListModels() []*provider.ModelInfo {
+	if models := p.regions[0].Provider.ListModels(); models != nil {
+		return models
+	}
+	return []*provider.ModelInfo{}
+}
+
+// DisableDataRetention sets the privacy options of every region on req,
+// since a request may fail over to any of them. It returns an error if a
+// region cannot disable data retention.
+//
+// It implements warp.DataRetentionController.
+func (p *Provider) DisableDataRetention(req *warp.CompletionRequest) error {
+	for _, r := range p.regions {
+		controller, ok := r.Provider.(warp.DataRetentionController)
+		if !ok {
+			return fmt.Errorf("region %q does not support disabling data retention", r.Name)
+		}
+		if err := controller.DisableDataRetention(req); err != nil {
+			return fmt.Errorf("region %q: %w", r.Name, err)
+		}
+	}
+	return nil
+}
+
+// SupportsDeveloperRole reports whether every region accepts "developer"
+// messages for model.
+//
+// It implements warp.DeveloperRoleSupporter.
+func (p *Provider) SupportsDeveloperRole(model string) bool {
+	for _, r := range p.regions {
+		supporter, ok := r.Provider.(warp.DeveloperRoleSupporter)
+		if !ok || !supporter.SupportsDeveloperRole(model) {
+			return false
+		}
+	}
+	return true
+}
+
+// MaxStopSequences returns the lowest number of stop sequences any region
+// accepts for model, or a negative number if no region has a limit.
+//
+// It implements warp.StopSequenceLimiter.
+func (p *Provider) MaxStopSequences(model string) int {
+	limit := -1
+	for _, r := range p.regions {
+		limiter, ok := r.Provider.(warp.StopSequenceLimiter)
+		if !ok {
+			continue
+		}
+		if n := limiter.MaxStopSequences(model); n >= 0 && (limit < 0 || n < limit) {
+			limit = n
+		}
+	}
+	return limit
+}
+
+// completionRegions returns the regions a completion request may use.
+//
+// Cached content lives in the region that created it, so a request that
+// references cached content is sent only to that region, with the region
+// removed from the cached content name.
+func (p *Provider) completionRegions(ctx context.Context, req *warp.CompletionRequest) ([]*regionState, *warp.CompletionRequest, error) {
+	regions, err := p.candidates(ctx, req.Metadata)
+	if err != nil || req.CachedContent == "" {
+		return regions, req, err
+	}
+
+	region, name, err := p.splitCachedContentName(req.CachedContent)
+	if err != nil {
+		return nil, nil, err
+	}
+	for _, r := range regions {
+		if r == region {
+			r := *req
+			r.CachedContent = name
+			return []*regionState{region}, &r, nil
+		}
+	}
+	return nil, nil, warp.NewPolicyViolationError(
+		fmt.Sprintf("cached content is stored in region %q, which the data residency constraints do not allow", region.Name),
+		ruleAllowedRegions, "", p.name, req.Model)
+}
+
+// QuotaUsage sums the quota usage of every region that reports it. The
+// combined limit is unlimited if any region's is, and the quota resets
+// when the first region's does. It returns an error if no region reports
+// usage or regions report different units.
+//
+// It implements warp.QuotaReporter.
+func (p *Provider) QuotaUsage(ctx context.Context) (*warp.QuotaUsage, error) {
+	var total *warp.QuotaUsage
+	unlimited := false
+	for _, r := range p.regions {
+		reporter, ok := r.Provider.(warp.QuotaReporter)
+		if !ok {
+			continue
+		}
+		usage, err := reporter.QuotaUsage(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("region %q: %w", r.Name, err)
+		}
+		if usage == nil {
+			return nil, fmt.Errorf("region %q reported no usage", r.Name)
+		}
+		if total == nil {
+			total = &warp.QuotaUsage{Unit: usage.Unit}
+		} else if usage.Unit != total.Unit {
+			return nil, fmt.Errorf("region %q reports quota in %q, not %q", r.Name, usage.Unit, total.Unit)
+		}
+
+		total.Used += usage.Used
+		total.Limit += usage.Limit
+		unlimited = unlimited || usage.Limit <= 0
+		if !usage.ResetsAt.IsZero() && (total.ResetsAt.IsZero() || usage.ResetsAt.Before(total.ResetsAt)) {
+			total.ResetsAt = usage.ResetsAt
+		}
+	}
+	if total == nil {
+		return nil, fmt.Errorf("no region of provider %q reports quota usage", p.name)
+	}
+	if unlimited {
+		total.Limit = 0
+	}
+	return total, nil
+}
+
+// CreateCachedContent stores content in the first available region that
+// supports cached content.
+//
+// The returned name is prefixed with the region, e.g.
+// "europe-west4/cachedContents/abc", so requests that reference it are
+// sent to that region.
+//
+// It implements warp.CachedContentManager.
+func (p *Provider) CreateCachedContent(ctx context.Context, req *warp.CachedContentRequest) (*warp.CachedContent, error) {
+	cached, region, err := call(ctx, p, nil, func(rp provider.Provider) (*warp.CachedContent, error) {
+		manager, ok := rp.(warp.CachedContentManager)
+		if !ok {
+			return nil, errRegionUnsupported
+		}
+		return manager.CreateCachedContent(ctx, req)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return withRegion(cached, region), nil
+}
+
+// GetCachedContent returns cached content from the region in its name.
+//
+// It implements warp.CachedContentManager.
+func (p *Provider) GetCachedContent(ctx context.Context, name string) (*warp.CachedContent, error) {
+	region, manager, name, err := p.cachedContentManager(name)
+	if err != nil {
+		return nil, err
+	}
+	cached, err := manager.GetCachedContent(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+	return withRegion(cached, region.Name), nil
+}
+
+// UpdateCachedContentTTL keeps cached content in the region in its name for
+// ttl from now.
+//
+// It implements warp.CachedContentManager.
+func (p *Provider) UpdateCachedContentTTL(ctx context.Context, name string, ttl time.Duration) (*warp.CachedContent, error) {
+	region, manager, name, err := p.cachedContentManager(name)
+	if err != nil {
+		return nil, err
+	}
+	cached, err := manager.UpdateCachedContentTTL(ctx, name, ttl)
+	if err != nil {
+		return nil, err
+	}
+	return withRegion(cached, region.Name), nil
+}
+
+// DeleteCachedContent deletes cached content from the region in its name.
+//
+// It implements warp.CachedContentManager.
+func (p *Provider) DeleteCachedContent(ctx context.Context, name string) error {
+	_, manager, name, err := p.cachedContentManager(name)
+	if err != nil {
+		return err
+	}
+	return manager.DeleteCachedContent(ctx, name)
+}
+
+// cachedContentManager returns the region of a region-prefixed cached
+// content name, its cached content manager, and the name within the region.
+func (p *Provider) cachedContentManager(name string) (*regionState, warp.CachedContentManager, string, error) {
+	region, name, err := p.splitCachedContentName(name)
+	if err != nil {
+		return nil, nil, "", err
+	}
+	manager, ok := region.Provider.(warp.CachedContentManager)
+	if !ok {
+		return nil, nil, "", warp.NewInvalidRequestError(
+			fmt.Sprintf("region %q does not support cached content", region.Name), p.name, nil)
+	}
+	return region, manager, name, nil
+}
+
+// splitCachedContentName splits a region-prefixed cached content name.
+func (p *Provider) splitCachedContentName(name string) (*regionState, string, error) {
+	regionName, rest, ok := strings.Cut(name, "/")
+	if ok {
+		for _, r := range p.regions {
+			if r.Name == regionName {
+				return r, rest, nil
+			}
+		}
+	}
+	return nil, "", warp.NewInvalidRequestError(
+		fmt.Sprintf("cached content %q was not created through provider %q (missing region prefix)", name, p.name), p.name, nil)
+}
+
+// withRegion returns cached with its name prefixed by region.
+func withRegion(cached *warp.CachedContent, region string) *warp.CachedContent {
+	if cached == nil {
+		return nil
+	}
+	c := *cached
+	c.Name = region + "/" + c.Name
+	return &c
+}
+
+// Responses sends a Responses API request to the first available region.
+//
+// Regions that do not serve the Responses API receive it as a completion
+// request, unless the conversion would drop fields of the request or it
+// continues a stored response; those regions are then skipped.
+//
+// It implements warp.ResponsesProvider.
+func (p *Provider) Responses(ctx context.Context, req *warp.ResponsesRequest) (*warp.ResponsesResponse, error) {
+	resp, _, err := call(ctx, p, nil, func(rp provider.Provider) (*warp.ResponsesResponse, error) {
+		if native, ok := rp.(warp.ResponsesProvider); ok {
+			return native.Responses(ctx, req)
+		}
+		completionReq, lost := warp.CompletionRequestFromResponses(req)
+		if len(lost) > 0 || req.PreviousResponseID != "" {
+			return nil, errRegionUnsupported
+		}
+		completion, err := rp.Completion(ctx, completionReq)
+		if err != nil {
+			return nil, err
+		}
+		return warp.ResponsesResponseFromCompletion(completion), nil
+	})
+	return resp, err
+}
+
+// ResponsesOnly reports whether any region serves model only through the
+// Responses API. Completion requests for the model are then sent as
+// Responses API requests, which every region can serve.
+//
+// It implements warp.ResponsesProvider.
+func (p *Provider) ResponsesOnly(model string) bool {
+	for _, r := range p.regions {
+		if rp, ok := r.Provider.(warp.ResponsesProvider); ok && rp.ResponsesOnly(model) {
+			return true
+		}
+	}
+	return false
+}
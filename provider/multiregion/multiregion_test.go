@@ -0,0 +1,408 @@
+package multiregion
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/blue-context/warp"
+	"github.com/blue-context/warp/internal/testutil"
+	"github.com/blue-context/warp/provider"
+)
+
+// regionalMock returns a mock provider that fails with err, if non-nil,
+// and otherwise answers with its region name.
+func regionalMock(region string, err *error) *testutil.MockProvider {
+	return &testutil.MockProvider{
+		CompletionFunc: func(ctx context.Context, req *warp.CompletionRequest) (*warp.CompletionResponse, error) {
+			if *err != nil {
+				return nil, *err
+			}
+			return &warp.CompletionResponse{Choices: []warp.Choice{{Message: warp.Message{Content: region}}}}, nil
+		},
+	}
+}
+
+func newTestProvider(t *testing.T, opts ...Option) (*Provider, *error, *error, *time.Time) {
+	t.Helper()
+	var eastErr, westErr error
+	p, err := New("azure", []Region{
+		{Name: "eastus", Provider: regionalMock("eastus", &eastErr)},
+		{Name: "westeurope", Provider: regionalMock("westeurope", &westErr)},
+	}, opts...)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	now := time.Unix(1_700_000_000, 0)
+	p.now = func() time.Time { return now }
+	return p, &eastErr, &westErr, &now
+}
+
+func complete(t *testing.T, p *Provider, metadata map[string]any) (string, error) {
+	t.Helper()
+	resp, err := p.Completion(context.Background(), &warp.CompletionRequest{
+		Model:    "gpt-4o",
+		Messages: []warp.Message{{Role: "user", Content: "Hi"}},
+		Metadata: metadata,
+	})
+	if err != nil {
+		return "", err
+	}
+	if resp.ProviderFields[ProviderFieldRegion] != resp.Choices[0].Message.Content {
+		t.Errorf("ProviderFields[region] = %v, want %v", resp.ProviderFields[ProviderFieldRegion], resp.Choices[0].Message.Content)
+	}
+	return resp.Choices[0].Message.Content.(string), nil
+}
+
+func TestFailover(t *testing.T) {
+	p, eastErr, _, now := newTestProvider(t, WithCooldown(time.Minute))
+
+	if region, err := complete(t, p, nil); err != nil || region != "eastus" {
+		t.Fatalf("Completion() = %q, %v, want eastus", region, err)
+	}
+
+	*eastErr = warp.NewServiceUnavailableError("down", "azure", nil)
+	if region, err := complete(t, p, nil); err != nil || region != "westeurope" {
+		t.Fatalf("Completion() = %q, %v, want failover to westeurope", region, err)
+	}
+	health := p.Health()
+	if health[0].Healthy || health[0].ConsecutiveFailures != 1 || !health[1].Healthy {
+		t.Errorf("Health() = %+v, want eastus unhealthy", health)
+	}
+
+	// eastus recovers but stays skipped until the cooldown passes
+	*eastErr = nil
+	if region, _ := complete(t, p, nil); region != "westeurope" {
+		t.Errorf("region during cooldown = %q, want westeurope", region)
+	}
+	*now = now.Add(time.Minute)
+	if region, _ := complete(t, p, nil); region != "eastus" {
+		t.Errorf("region after cooldown = %q, want eastus", region)
+	}
+	if !p.Health()[0].Healthy {
+		t.Error("eastus should be healthy after a success")
+	}
+}
+
+func TestNoFailoverOnClientErrors(t *testing.T) {
+	p, eastErr, _, _ := newTestProvider(t)
+
+	*eastErr = warp.NewInvalidRequestError("bad request", "azure", nil)
+	_, err := complete(t, p, nil)
+	var invalid *warp.InvalidRequestError
+	if !errors.As(err, &invalid) {
+		t.Errorf("Completion() error = %v, want InvalidRequestError without failover", err)
+	}
+	if !p.Health()[0].Healthy {
+		t.Error("client errors should not mark the region unhealthy")
+	}
+}
+
+func TestAllRegionsFail(t *testing.T) {
+	p, eastErr, westErr, _ := newTestProvider(t)
+
+	*eastErr = fmt.Errorf("failed to send request: connection refused")
+	*westErr = warp.NewRateLimitError("slow down", "azure", 0, nil)
+	_, err := complete(t, p, nil)
+	var rateLimit *warp.RateLimitError
+	if !errors.As(err, &rateLimit) {
+		t.Errorf("Completion() error = %v, want last region's error", err)
+	}
+}
+
+func TestFailureThreshold(t *testing.T) {
+	p, eastErr, _, _ := newTestProvider(t, WithFailureThreshold(2))
+
+	*eastErr = warp.NewTimeoutError("timeout", "azure", nil)
+	complete(t, p, nil)
+	if !p.Health()[0].Healthy {
+		t.Error("eastus unhealthy after one failure, want threshold of 2")
+	}
+	complete(t, p, nil)
+	if p.Health()[0].Healthy {
+		t.Error("eastus healthy after two failures")
+	}
+}
+
+func TestRegionFilter(t *testing.T) {
+	filter := func(ctx context.Context, metadata map[string]any) []string {
+		switch metadata["tenant"] {
+		case "acme-gmbh":
+			return []string{"westeurope"}
+		case "nowhere":
+			return []string{}
+		}
+		return nil
+	}
+	p, eastErr, _, _ := newTestProvider(t, WithRegionFilter(filter))
+
+	if region, _ := complete(t, p, map[string]any{"tenant": "acme-gmbh"}); region != "westeurope" {
+		t.Errorf("region = %q, want westeurope", region)
+	}
+	if region, _ := complete(t, p, nil); region != "eastus" {
+		t.Errorf("unrestricted region = %q, want eastus", region)
+	}
+
+	_, err := complete(t, p, map[string]any{"tenant": "nowhere"})
+	var violation *warp.PolicyViolationError
+	if !errors.As(err, &violation) || violation.Rule != "allowed_regions" {
+		t.Errorf("Completion() error = %v, want allowed_regions violation", err)
+	}
+
+	// A residency-restricted request never fails over out of its regions
+	p.filter = func(ctx context.Context, metadata map[string]any) []string { return []string{"eastus"} }
+	*eastErr = warp.NewServiceUnavailableError("down", "azure", nil)
+	if _, err := complete(t, p, nil); err == nil {
+		t.Error("Completion() error = nil, want error without failover outside allowed regions")
+	}
+}
+
+func TestNew(t *testing.T) {
+	mock := &testutil.MockProvider{}
+	tests := []struct {
+		name    string
+		regions []Region
+		opts    []Option
+	}{
+		{"no regions", nil, nil},
+		{"missing name", []Region{{Provider: mock}}, nil},
+		{"missing provider", []Region{{Name: "eastus"}}, nil},
+		{"duplicate", []Region{{Name: "eastus", Provider: mock}, {Name: "eastus", Provider: mock}}, nil},
+		{"bad threshold", []Region{{Name: "eastus", Provider: mock}}, []Option{WithFailureThreshold(0)}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := New("azure", tt.regions, tt.opts...); err == nil {
+				t.Error("New() error = nil, want error")
+			}
+		})
+	}
+}
+
+func TestProviderCompliance(t *testing.T) {
+	p, err := New("azure", []Region{{Name: "eastus", Provider: &testutil.MockProvider{}}})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	provider.AssertProviderCompliance(t, p)
+	provider.AssertMethodCount(t, p, "Health", "DisableDataRetention", "SupportsDeveloperRole", "MaxStopSequences",
+		"QuotaUsage", "CreateCachedContent", "GetCachedContent", "UpdateCachedContentTTL", "DeleteCachedContent",
+		"Responses", "ResponsesOnly")
+}
+
+// capableMock is a mock provider with the optional client interfaces.
+type capableMock struct {
+	*testutil.MockProvider
+	developer bool
+	maxStop   int
+	zdrErr    error
+	quota     *warp.QuotaUsage
+	cached    map[string]bool // Names of cached contents stored
+	responses string          // Text of Responses API replies
+	respOnly  bool
+}
+
+func (m *capableMock) QuotaUsage(ctx context.Context) (*warp.QuotaUsage, error) {
+	return m.quota, nil
+}
+
+func (m *capableMock) CreateCachedContent(ctx context.Context, req *warp.CachedContentRequest) (*warp.CachedContent, error) {
+	m.cached["cachedContents/1"] = true
+	return &warp.CachedContent{Name: "cachedContents/1", Model: req.Model}, nil
+}
+
+func (m *capableMock) GetCachedContent(ctx context.Context, name string) (*warp.CachedContent, error) {
+	if !m.cached[name] {
+		return nil, fmt.Errorf("cached content %q not found", name)
+	}
+	return &warp.CachedContent{Name: name}, nil
+}
+
+func (m *capableMock) UpdateCachedContentTTL(ctx context.Context, name string, ttl time.Duration) (*warp.CachedContent, error) {
+	return m.GetCachedContent(ctx, name)
+}
+
+func (m *capableMock) DeleteCachedContent(ctx context.Context, name string) error {
+	delete(m.cached, name)
+	return nil
+}
+
+func (m *capableMock) Responses(ctx context.Context, req *warp.ResponsesRequest) (*warp.ResponsesResponse, error) {
+	return &warp.ResponsesResponse{Object: "response", Output: []warp.ResponseItem{{
+		Type: "message", Role: "assistant", Content: []warp.ResponseContent{{Type: "output_text", Text: m.responses}},
+	}}}, nil
+}
+
+func (m *capableMock) ResponsesOnly(model string) bool { return m.respOnly }
+
+func (m *capableMock) SupportsDeveloperRole(model string) bool { return m.developer }
+
+func (m *capableMock) MaxStopSequences(model string) int { return m.maxStop }
+
+func (m *capableMock) DisableDataRetention(req *warp.CompletionRequest) error {
+	if m.zdrErr != nil {
+		return m.zdrErr
+	}
+	req.ExtraBody["store"] = false
+	return nil
+}
+
+// TestOptionalInterfaces tests that the optional client interfaces hold
+// for every region
+func TestOptionalInterfaces(t *testing.T) {
+	mock := &testutil.MockProvider{}
+	east := &capableMock{MockProvider: mock, developer: true, maxStop: 4, quota: &warp.QuotaUsage{Used: 1, Limit: 10, Unit: "usd"}, respOnly: true}
+	west := &capableMock{MockProvider: mock, developer: true, maxStop: -1, quota: &warp.QuotaUsage{Used: 2, Limit: 5, Unit: "usd"}}
+	plain := &testutil.MockProvider{}
+
+	tests := []struct {
+		name      string
+		regions   []provider.Provider
+		developer bool
+		maxStop   int
+		zdr       bool
+		quota     *warp.QuotaUsage
+		respOnly  bool
+	}{
+		{name: "all capable", regions: []provider.Provider{east, west}, developer: true, maxStop: 4, zdr: true,
+			quota: &warp.QuotaUsage{Used: 3, Limit: 15, Unit: "usd"}, respOnly: true},
+		{name: "unlimited", regions: []provider.Provider{west, west}, developer: true, maxStop: -1, zdr: true,
+			quota: &warp.QuotaUsage{Used: 4, Limit: 10, Unit: "usd"}},
+		{name: "plain region", regions: []provider.Provider{east, plain}, developer: false, maxStop: 4, zdr: false,
+			quota: &warp.QuotaUsage{Used: 1, Limit: 10, Unit: "usd"}, respOnly: true},
+		{name: "retention error", regions: []provider.Provider{east, &capableMock{MockProvider: mock, zdrErr: errors.New("no"), quota: &warp.QuotaUsage{Used: 1, Unit: "usd"}}}, maxStop: 0, zdr: false,
+			quota: &warp.QuotaUsage{Used: 2, Unit: "usd"}, respOnly: true},
+		{name: "no quota", regions: []provider.Provider{plain}, maxStop: -1, zdr: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			regions := make([]Region, len(tt.regions))
+			for i, rp := range tt.regions {
+				regions[i] = Region{Name: fmt.Sprintf("region%d", i), Provider: rp}
+			}
+			p, err := New("azure", regions)
+			if err != nil {
+				t.Fatalf("New() error = %v", err)
+			}
+
+			if got := p.SupportsDeveloperRole("gpt-4o"); got != tt.developer {
+				t.Errorf("SupportsDeveloperRole() = %v, want %v", got, tt.developer)
+			}
+			if got := p.MaxStopSequences("gpt-4o"); got != tt.maxStop {
+				t.Errorf("MaxStopSequences() = %d, want %d", got, tt.maxStop)
+			}
+			req := &warp.CompletionRequest{ExtraBody: map[string]any{}}
+			if err := p.DisableDataRetention(req); (err == nil) != tt.zdr {
+				t.Errorf("DisableDataRetention() error = %v, want success %v", err, tt.zdr)
+			}
+			quota, err := p.QuotaUsage(context.Background())
+			if tt.quota == nil {
+				if err == nil {
+					t.Errorf("QuotaUsage() = %+v, want error", quota)
+				}
+			} else if err != nil || *quota != *tt.quota {
+				t.Errorf("QuotaUsage() = %+v, %v, want %+v", quota, err, tt.quota)
+			}
+			if got := p.ResponsesOnly("gpt-5-pro"); got != tt.respOnly {
+				t.Errorf("ResponsesOnly() = %v, want %v", got, tt.respOnly)
+			}
+		})
+	}
+}
+
+// TestCachedContentRegion tests that cached content is pinned to the region
+// that created it
+func TestCachedContentRegion(t *testing.T) {
+	var served []string
+	regionMock := func(region string) *capableMock {
+		return &capableMock{
+			MockProvider: &testutil.MockProvider{
+				CompletionFunc: func(ctx context.Context, req *warp.CompletionRequest) (*warp.CompletionResponse, error) {
+					served = append(served, region+":"+req.CachedContent)
+					return &warp.CompletionResponse{}, nil
+				},
+			},
+			cached: map[string]bool{},
+		}
+	}
+	west, north := regionMock("westeurope"), regionMock("northeurope")
+	var allowed []string
+	p, err := New("vertex", []Region{
+		{Name: "eastus", Provider: &testutil.MockProvider{}},
+		{Name: "westeurope", Provider: west},
+		{Name: "northeurope", Provider: north},
+	}, WithRegionFilter(func(ctx context.Context, metadata map[string]any) []string { return allowed }))
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	ctx := context.Background()
+
+	// Created in the first region that supports cached content
+	cached, err := p.CreateCachedContent(ctx, &warp.CachedContentRequest{Model: "gemini-2.5-pro"})
+	if err != nil {
+		t.Fatalf("CreateCachedContent() error = %v", err)
+	}
+	if cached.Name != "westeurope/cachedContents/1" {
+		t.Fatalf("Name = %q, want region prefix", cached.Name)
+	}
+	if got, err := p.GetCachedContent(ctx, cached.Name); err != nil || got.Name != cached.Name {
+		t.Errorf("GetCachedContent() = %+v, %v", got, err)
+	}
+
+	req := &warp.CompletionRequest{Model: "gemini-2.5-pro", CachedContent: cached.Name}
+	if _, err := p.Completion(ctx, req); err != nil {
+		t.Fatalf("Completion() error = %v", err)
+	}
+	if len(served) != 1 || served[0] != "westeurope:cachedContents/1" {
+		t.Errorf("served = %v, want the creating region", served)
+	}
+
+	allowed = []string{"eastus"}
+	var violation *warp.PolicyViolationError
+	if _, err := p.Completion(ctx, req); !errors.As(err, &violation) {
+		t.Errorf("Completion() error = %v, want policy violation outside the allowed regions", err)
+	}
+
+	if err := p.DeleteCachedContent(ctx, cached.Name); err != nil || west.cached["cachedContents/1"] {
+		t.Errorf("DeleteCachedContent() error = %v, stored = %v", err, west.cached)
+	}
+	if _, err := p.GetCachedContent(ctx, "cachedContents/1"); err == nil {
+		t.Error("GetCachedContent() without region prefix succeeded")
+	}
+}
+
+// TestResponses tests native and converted Responses API requests
+func TestResponses(t *testing.T) {
+	native := &capableMock{MockProvider: &testutil.MockProvider{}, responses: "native"}
+	var regionErr error
+	converted := regionalMock("converted", &regionErr)
+
+	p, err := New("azure", []Region{{Name: "eastus", Provider: converted}, {Name: "westeurope", Provider: native}})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	req := &warp.ResponsesRequest{Model: "gpt-4o", Input: []warp.ResponseItem{
+		{Type: "message", Role: "user", Content: []warp.ResponseContent{{Type: "input_text", Text: "Hi"}}},
+	}}
+	resp, err := p.Responses(context.Background(), req)
+	if err != nil {
+		t.Fatalf("Responses() error = %v", err)
+	}
+	if got := resp.OutputText(); got != "converted" {
+		t.Errorf("output = %q, want converted completion from the preferred region", got)
+	}
+
+	// Stored conversations cannot be converted, so the native region serves them
+	req.PreviousResponseID = "resp_1"
+	resp, err = p.Responses(context.Background(), req)
+	if err != nil {
+		t.Fatalf("Responses() error = %v", err)
+	}
+	if got := resp.OutputText(); got != "native" {
+		t.Errorf("output = %q, want native region", got)
+	}
+}
@@ -46,11 +46,15 @@ func (p *Provider) Completion(ctx context.Context, req *warp.CompletionRequest)
 	// Set headers (no auth needed for Ollama)
 	httpReq.Header.Set("Content-Type", "application/json")
 
+	// Add User-Agent and client request headers
+	warp.SetRequestHeaders(httpReq)
+
 	// Send request
 	httpResp, err := p.httpClient.Do(httpReq)
 	if err != nil {
 		return nil, fmt.Errorf("failed to send request: %w", err)
 	}
+	warp.RecordResponse(httpResp)
 	defer httpResp.Body.Close()
 
 	// Check status code
@@ -61,11 +61,15 @@ func (p *Provider) CompletionStream(ctx context.Context, req *warp.CompletionReq
 	// Set headers (no auth needed for Ollama)
 	httpReq.Header.Set("Content-Type", "application/json")
 
+	// Add User-Agent and client request headers
+	warp.SetRequestHeaders(httpReq)
+
 	// Send request
 	httpResp, err := p.httpClient.Do(httpReq)
 	if err != nil {
 		return nil, fmt.Errorf("failed to send request: %w", err)
 	}
+	warp.RecordResponse(httpResp)
 
 	// Check status code
 	if httpResp.StatusCode != http.StatusOK {
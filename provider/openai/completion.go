@@ -1,14 +1,10 @@
 package openai
 
 import (
-	"bytes"
 	"context"
-	"encoding/json"
-	"fmt"
-	"io"
-	"net/http"
 
 	"github.com/blue-context/warp"
+	"github.com/blue-context/warp/provider/providercore"
 )
 
 // Completion sends a chat completion request to OpenAI.
@@ -32,42 +28,13 @@ func (p *Provider) Completion(ctx context.Context, req *warp.CompletionRequest)
 	// Transform request to OpenAI format
 	openaiReq := transformRequest(req)
 
-	// Marshal to JSON
-	body, err := json.Marshal(openaiReq)
-	if err != nil {
-		return nil, fmt.Errorf("failed to marshal request: %w", err)
+	// Send request and parse response
+	var resp providercore.Completion
+	if err := p.core().PostJSON(ctx, "/chat/completions", openaiReq, &resp); err != nil {
+		return nil, err
 	}
 
-	// Create HTTP request
-	httpReq, err := http.NewRequestWithContext(ctx, "POST", p.apiBase+"/chat/completions", bytes.NewReader(body))
-	if err != nil {
-		return nil, fmt.Errorf("failed to create request: %w", err)
-	}
-
-	// Set headers
-	httpReq.Header.Set("Content-Type", "application/json")
-	httpReq.Header.Set("Authorization", "Bearer "+p.apiKey)
-
-	// Send request
-	httpResp, err := p.httpClient.Do(httpReq)
-	if err != nil {
-		return nil, fmt.Errorf("failed to send request: %w", err)
-	}
-	defer httpResp.Body.Close()
-
-	// Check status code
-	if httpResp.StatusCode != http.StatusOK {
-		body, _ := io.ReadAll(httpResp.Body)
-		return nil, warp.ParseProviderError("openai", httpResp.StatusCode, body, nil)
-	}
-
-	// Parse response
-	var resp warp.CompletionResponse
-	if err := json.NewDecoder(httpResp.Body).Decode(&resp); err != nil {
-		return nil, fmt.Errorf("failed to decode response: %w", err)
-	}
-
-	return &resp, nil
+	return &resp.CompletionResponse, nil
 }
 
 // transformRequest transforms a Warp request to OpenAI format.
@@ -77,7 +44,7 @@ func (p *Provider) Completion(ctx context.Context, req *warp.CompletionRequest)
 func transformRequest(req *warp.CompletionRequest) map[string]any {
 	openaiReq := map[string]any{
 		"model":    req.Model,
-		"messages": transformMessages(req.Messages),
+		"messages": providercore.Messages(req.Messages),
 	}
 
 	// Optional parameters
@@ -117,55 +84,60 @@ func transformRequest(req *warp.CompletionRequest) map[string]any {
 		openaiReq["response_format"] = req.ResponseFormat
 	}
 
+	// Processing tier
+	if req.ServiceTier != "" {
+		openaiReq["service_tier"] = req.ServiceTier
+	}
+
+	// Prompt cache routing and abuse detection
+	if req.PromptCacheKey != "" {
+		openaiReq["prompt_cache_key"] = req.PromptCacheKey
+	}
+	if req.SafetyIdentifier != "" {
+		openaiReq["safety_identifier"] = req.SafetyIdentifier
+	}
+
+	// Provider-specific fields override generated ones
+	for k, v := range req.ExtraBody {
+		openaiReq[k] = v
+	}
+
 	return openaiReq
 }
 
-// transformMessages transforms Warp messages to OpenAI format.
+// DisableDataRetention sets "store": false so OpenAI does not store the
+// completion for distillation, evals, or the dashboard.
+//
+// It implements warp.DataRetentionController for WithZeroDataRetention.
+// Abuse-monitoring retention is governed by the organization's data
+// controls, not by the request.
+func (p *Provider) DisableDataRetention(req *warp.CompletionRequest) error {
+	req.ExtraBody["store"] = false
+	return nil
+}
+
+// SupportsDeveloperRole reports that OpenAI accepts "developer" messages,
+// which reasoning models require in place of system messages and other
+// models treat as system messages.
 //
-// This function handles both simple text content and multimodal content
-// (text + images) according to OpenAI's message format.
-func transformMessages(messages []warp.Message) []map[string]any {
-	openaiMessages := make([]map[string]any, len(messages))
-
-	for i, msg := range messages {
-		openaiMsg := map[string]any{
-			"role": msg.Role,
-		}
-
-		// Handle content (can be string or []ContentPart for multimodal)
-		switch content := msg.Content.(type) {
-		case string:
-			openaiMsg["content"] = content
-		case []warp.ContentPart:
-			// Multimodal content (text + images)
-			parts := make([]map[string]any, len(content))
-			for j, part := range content {
-				parts[j] = map[string]any{
-					"type": part.Type,
-				}
-				if part.Text != "" {
-					parts[j]["text"] = part.Text
-				}
-				if part.ImageURL != nil {
-					parts[j]["image_url"] = part.ImageURL
-				}
-			}
-			openaiMsg["content"] = parts
-		}
-
-		// Optional fields
-		if msg.Name != "" {
-			openaiMsg["name"] = msg.Name
-		}
-		if len(msg.ToolCalls) > 0 {
-			openaiMsg["tool_calls"] = msg.ToolCalls
-		}
-		if msg.ToolCallID != "" {
-			openaiMsg["tool_call_id"] = msg.ToolCallID
-		}
-
-		openaiMessages[i] = openaiMsg
-	}
-
-	return openaiMessages
+// It implements warp.DeveloperRoleSupporter.
+func (p *Provider) SupportsDeveloperRole(model string) bool {
+	return true
+}
+
+// MaxStopSequences reports that OpenAI accepts 4 stop sequences, and none
+// for o-series reasoning models, which reject the stop parameter.
+//
+// It implements warp.StopSequenceLimiter.
+func (p *Provider) MaxStopSequences(model string) int {
+	if isReasoningModel(model) {
+		return 0
+	}
+	return 4
+}
+
+// isReasoningModel reports whether model is an o-series reasoning model,
+// such as o1, o3-mini, or o4-mini.
+func isReasoningModel(model string) bool {
+	return len(model) >= 2 && model[0] == 'o' && model[1] >= '1' && model[1] <= '9'
 }
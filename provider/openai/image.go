@@ -89,11 +89,15 @@ func (p *Provider) ImageGeneration(ctx context.Context, req *warp.ImageGeneratio
 	httpReq.Header.Set("Content-Type", "application/json")
 	httpReq.Header.Set("Authorization", "Bearer "+apiKey)
 
+	// Add User-Agent and client request headers
+	warp.SetRequestHeaders(httpReq)
+
 	// Send request
 	httpResp, err := p.httpClient.Do(httpReq)
 	if err != nil {
 		return nil, fmt.Errorf("failed to send request: %w", err)
 	}
+	warp.RecordResponse(httpResp)
 	defer httpResp.Body.Close()
 
 	// Read response body
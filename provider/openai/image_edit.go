@@ -1,15 +1,11 @@
 package openai
 
 import (
-	"bytes"
 	"context"
-	"encoding/json"
 	"fmt"
-	"io"
-	"mime/multipart"
-	"net/http"
 
 	"github.com/blue-context/warp"
+	"github.com/blue-context/warp/provider/providercore"
 )
 
 // ImageEdit edits an image using DALL-E.
@@ -70,103 +66,33 @@ func (p *Provider) ImageEdit(ctx context.Context, req *warp.ImageEditRequest) (*
 		fields["user"] = req.User
 	}
 
-	// Create multipart form with image and optional mask
-	body, contentType, err := createImageEditForm(req, fields)
-	if err != nil {
-		return nil, fmt.Errorf("failed to create multipart form: %w", err)
-	}
-
-	// Determine API key and base URL
-	apiKey := p.apiKey
-	if req.APIKey != "" {
-		apiKey = req.APIKey
-	}
-
-	apiBase := p.apiBase
-	if req.APIBase != "" {
-		apiBase = req.APIBase
-	}
-
-	// Create HTTP request
-	httpReq, err := http.NewRequestWithContext(ctx, "POST", apiBase+"/images/edits", bytes.NewReader(body))
-	if err != nil {
-		return nil, fmt.Errorf("failed to create HTTP request: %w", err)
-	}
-
-	// Set headers
-	httpReq.Header.Set("Content-Type", contentType)
-	httpReq.Header.Set("Authorization", "Bearer "+apiKey)
-
-	// Send request
-	httpResp, err := p.httpClient.Do(httpReq)
-	if err != nil {
-		return nil, fmt.Errorf("failed to send request: %w", err)
-	}
-	defer httpResp.Body.Close()
+	// Build the form; images are streamed as the request is sent
+	form := imageEditForm(req, fields)
+	form.OnProgress(req.OnProgress)
 
-	// Read response body
-	respBody, err := io.ReadAll(httpResp.Body)
+	httpResp, err := p.coreWith(req.APIKey, req.APIBase).PostFormStream(ctx, "/images/edits", form)
 	if err != nil {
-		return nil, fmt.Errorf("failed to read response body: %w", err)
+		return nil, err
 	}
 
-	// Check status code
-	if httpResp.StatusCode != http.StatusOK {
-		return nil, warp.ParseProviderError("openai", httpResp.StatusCode, respBody, nil)
-	}
-
-	// Parse response
 	var resp warp.ImageGenerationResponse
-	if err := json.Unmarshal(respBody, &resp); err != nil {
-		return nil, fmt.Errorf("failed to decode response: %w", err)
+	if err := providercore.Decode(httpResp, &resp); err != nil {
+		return nil, err
 	}
 
 	return &resp, nil
 }
 
-// createImageEditForm creates a multipart form with image and optional mask.
-//
-// The form includes:
-// - image file (required)
-// - mask file (optional)
-// - text fields (prompt, n, size, etc.)
-//
-// Returns the form body, content type header value, and any error.
-func createImageEditForm(req *warp.ImageEditRequest, fields map[string]string) ([]byte, string, error) {
-	var buf bytes.Buffer
-	writer := multipart.NewWriter(&buf)
-
-	// Add image file
-	imagePart, err := writer.CreateFormFile("image", req.ImageFilename)
-	if err != nil {
-		return nil, "", fmt.Errorf("failed to create image field: %w", err)
-	}
-	if _, err := io.Copy(imagePart, req.Image); err != nil {
-		return nil, "", fmt.Errorf("failed to copy image data: %w", err)
-	}
-
-	// Add mask file (optional)
+// imageEditForm creates a multipart form with the image, the optional
+// mask, and text fields (prompt, n, size, etc.).
+func imageEditForm(req *warp.ImageEditRequest, fields map[string]string) *providercore.Form {
+	form := providercore.NewForm()
+	form.AddFile("image", req.ImageFilename, "", req.Image)
 	if req.Mask != nil {
-		maskPart, err := writer.CreateFormFile("mask", req.MaskFilename)
-		if err != nil {
-			return nil, "", fmt.Errorf("failed to create mask field: %w", err)
-		}
-		if _, err := io.Copy(maskPart, req.Mask); err != nil {
-			return nil, "", fmt.Errorf("failed to copy mask data: %w", err)
-		}
+		form.AddFile("mask", req.MaskFilename, "", req.Mask)
 	}
-
-	// Add text fields
 	for key, value := range fields {
-		if err := writer.WriteField(key, value); err != nil {
-			return nil, "", fmt.Errorf("failed to write field %s: %w", key, err)
-		}
+		form.AddField(key, value)
 	}
-
-	// Close writer to finalize multipart message
-	if err := writer.Close(); err != nil {
-		return nil, "", fmt.Errorf("failed to close multipart writer: %w", err)
-	}
-
-	return buf.Bytes(), writer.FormDataContentType(), nil
+	return form
 }
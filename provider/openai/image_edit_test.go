@@ -322,7 +322,7 @@ func TestProvider_ImageEdit(t *testing.T) {
 	}
 }
 
-func TestCreateImageEditForm(t *testing.T) {
+func TestImageEditForm(t *testing.T) {
 	tests := []struct {
 		name           string
 		req            *warp.ImageEditRequest
@@ -380,7 +380,7 @@ func TestCreateImageEditForm(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			body, contentType, err := createImageEditForm(tt.req, tt.fields)
+			body, contentType, err := imageEditForm(tt.req, tt.fields).Encode()
 
 			if tt.wantErr {
 				if err == nil {
@@ -451,3 +451,46 @@ func TestProvider_ImageGeneration_ContextCancellation(t *testing.T) {
 		t.Error("expected error for cancelled context")
 	}
 }
+
+func TestImageCostDefaultSize(t *testing.T) {
+	provider, err := NewProvider(WithAPIKey("sk-test"))
+	if err != nil {
+		t.Fatalf("failed to create provider: %v", err)
+	}
+	client, err := warp.NewClient()
+	if err != nil {
+		t.Fatalf("failed to create client: %v", err)
+	}
+	defer client.Close()
+	if err := client.RegisterProvider(provider); err != nil {
+		t.Fatalf("failed to register provider: %v", err)
+	}
+
+	tests := []struct {
+		model   string
+		quality string
+		want    float64
+	}{
+		{model: "dall-e-2", want: 0.020},
+		{model: "dall-e-3", want: 0.040},
+		{model: "dall-e-3", quality: "hd", want: 0.080},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.model+"/"+tt.quality, func(t *testing.T) {
+			// Requests without a size get the model's default size
+			got, err := client.ImageCost(&warp.ImageGenerationResponse{
+				Data:     []warp.ImageData{{URL: "a"}},
+				Provider: "openai",
+				Model:    tt.model,
+				Quality:  tt.quality,
+			})
+			if err != nil {
+				t.Fatalf("ImageCost() error = %v", err)
+			}
+			if diff := got - tt.want; diff > 1e-9 || diff < -1e-9 {
+				t.Errorf("ImageCost() = %f, want %f", got, tt.want)
+			}
+		})
+	}
+}
@@ -236,7 +236,7 @@ func TestProvider_ImageVariation(t *testing.T) {
 	}
 }
 
-func TestCreateImageVariationForm(t *testing.T) {
+func TestImageVariationForm(t *testing.T) {
 	tests := []struct {
 		name       string
 		req        *warp.ImageVariationRequest
@@ -301,7 +301,7 @@ func TestCreateImageVariationForm(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			body, contentType, err := createImageVariationForm(tt.req, tt.fields)
+			body, contentType, err := imageVariationForm(tt.req, tt.fields).Encode()
 
 			if tt.wantErr {
 				if err == nil {
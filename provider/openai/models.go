@@ -18,6 +18,7 @@ var modelRegistry = map[string]*types.ModelInfo{
 		MaxOutputTokens:   4096,
 		InputCostPer1M:    30.00,
 		OutputCostPer1M:   60.00,
+		BatchDiscount:     0.5,
 		SupportsVision:    false,
 		SupportsFunctions: true,
 		SupportsJSON:      true,
@@ -36,6 +37,7 @@ var modelRegistry = map[string]*types.ModelInfo{
 		MaxOutputTokens:   4096,
 		InputCostPer1M:    10.00,
 		OutputCostPer1M:   30.00,
+		BatchDiscount:     0.5,
 		SupportsVision:    true,
 		SupportsFunctions: true,
 		SupportsJSON:      true,
@@ -55,6 +57,7 @@ var modelRegistry = map[string]*types.ModelInfo{
 		MaxOutputTokens:   4096,
 		InputCostPer1M:    10.00,
 		OutputCostPer1M:   30.00,
+		BatchDiscount:     0.5,
 		SupportsVision:    true,
 		SupportsFunctions: true,
 		SupportsJSON:      true,
@@ -74,6 +77,7 @@ var modelRegistry = map[string]*types.ModelInfo{
 		MaxOutputTokens:   4096,
 		InputCostPer1M:    10.00,
 		OutputCostPer1M:   30.00,
+		BatchDiscount:     0.5,
 		SupportsVision:    false,
 		SupportsFunctions: true,
 		SupportsJSON:      true,
@@ -92,6 +96,7 @@ var modelRegistry = map[string]*types.ModelInfo{
 		MaxOutputTokens:   4096,
 		InputCostPer1M:    10.00,
 		OutputCostPer1M:   30.00,
+		BatchDiscount:     0.5,
 		SupportsVision:    false,
 		SupportsFunctions: true,
 		SupportsJSON:      true,
@@ -112,6 +117,7 @@ var modelRegistry = map[string]*types.ModelInfo{
 		MaxOutputTokens:   4096,
 		InputCostPer1M:    0.50,
 		OutputCostPer1M:   1.50,
+		BatchDiscount:     0.5,
 		SupportsVision:    false,
 		SupportsFunctions: true,
 		SupportsJSON:      true,
@@ -130,6 +136,7 @@ var modelRegistry = map[string]*types.ModelInfo{
 		MaxOutputTokens:   4096,
 		InputCostPer1M:    0.50,
 		OutputCostPer1M:   1.50,
+		BatchDiscount:     0.5,
 		SupportsVision:    false,
 		SupportsFunctions: true,
 		SupportsJSON:      true,
@@ -148,6 +155,7 @@ var modelRegistry = map[string]*types.ModelInfo{
 		MaxOutputTokens:   4096,
 		InputCostPer1M:    1.00,
 		OutputCostPer1M:   2.00,
+		BatchDiscount:     0.5,
 		SupportsVision:    false,
 		SupportsFunctions: true,
 		SupportsJSON:      true,
@@ -166,6 +174,7 @@ var modelRegistry = map[string]*types.ModelInfo{
 		MaxOutputTokens:   4096,
 		InputCostPer1M:    3.00,
 		OutputCostPer1M:   4.00,
+		BatchDiscount:     0.5,
 		SupportsVision:    false,
 		SupportsFunctions: true,
 		SupportsJSON:      true,
@@ -186,6 +195,7 @@ var modelRegistry = map[string]*types.ModelInfo{
 		MaxOutputTokens:   0,
 		InputCostPer1M:    0.10,
 		OutputCostPer1M:   0.00,
+		BatchDiscount:     0.5,
 		SupportsVision:    false,
 		SupportsFunctions: false,
 		SupportsJSON:      false,
@@ -195,35 +205,103 @@ var modelRegistry = map[string]*types.ModelInfo{
 		},
 	},
 	"text-embedding-3-small": {
-		Name:              "text-embedding-3-small",
-		Provider:          "openai",
-		ContextWindow:     8191,
-		MaxOutputTokens:   0,
-		InputCostPer1M:    0.02,
-		OutputCostPer1M:   0.00,
-		SupportsVision:    false,
-		SupportsFunctions: false,
-		SupportsJSON:      false,
-		SupportsStreaming: false,
+		Name:               "text-embedding-3-small",
+		Provider:           "openai",
+		ContextWindow:      8191,
+		MaxOutputTokens:    0,
+		InputCostPer1M:     0.02,
+		OutputCostPer1M:    0.00,
+		BatchDiscount:      0.5,
+		SupportsVision:     false,
+		SupportsFunctions:  false,
+		SupportsJSON:       false,
+		SupportsStreaming:  false,
+		SupportsDimensions: true,
 		Capabilities: types.Capabilities{
 			Embedding: true,
 		},
 	},
 	"text-embedding-3-large": {
-		Name:              "text-embedding-3-large",
-		Provider:          "openai",
-		ContextWindow:     8191,
-		MaxOutputTokens:   0,
-		InputCostPer1M:    0.13,
-		OutputCostPer1M:   0.00,
-		SupportsVision:    false,
-		SupportsFunctions: false,
-		SupportsJSON:      false,
-		SupportsStreaming: false,
+		Name:               "text-embedding-3-large",
+		Provider:           "openai",
+		ContextWindow:      8191,
+		MaxOutputTokens:    0,
+		InputCostPer1M:     0.13,
+		OutputCostPer1M:    0.00,
+		BatchDiscount:      0.5,
+		SupportsVision:     false,
+		SupportsFunctions:  false,
+		SupportsJSON:       false,
+		SupportsStreaming:  false,
+		SupportsDimensions: true,
 		Capabilities: types.Capabilities{
 			Embedding: true,
 		},
 	},
+
+	// Image Models
+	"dall-e-2": {
+		Name:          "dall-e-2",
+		Provider:      "openai",
+		ContextWindow: 1000, // Maximum prompt length in characters
+		ImageCostPerImage: map[string]float64{
+			"256x256":   0.016,
+			"512x512":   0.018,
+			"1024x1024": 0.020,
+		},
+		DefaultImageSize: "1024x1024",
+		Capabilities: types.Capabilities{
+			ImageGeneration: true,
+			ImageEdit:       true,
+			ImageVariation:  true,
+		},
+	},
+	"dall-e-3": {
+		Name:          "dall-e-3",
+		Provider:      "openai",
+		ContextWindow: 4000, // Maximum prompt length in characters
+		ImageCostPerImage: map[string]float64{
+			"standard/1024x1024": 0.040,
+			"standard/1024x1792": 0.080,
+			"standard/1792x1024": 0.080,
+			"hd/1024x1024":       0.080,
+			"hd/1024x1792":       0.120,
+			"hd/1792x1024":       0.120,
+		},
+		DefaultImageSize: "1024x1024",
+		Capabilities: types.Capabilities{
+			ImageGeneration: true,
+		},
+	},
+
+	// Audio Models
+	"tts-1": {
+		Name:                 "tts-1",
+		Provider:             "openai",
+		ContextWindow:        4096, // Maximum input length in characters
+		SpeechCostPer1MChars: 15.00,
+		Capabilities: types.Capabilities{
+			Speech: true,
+		},
+	},
+	"tts-1-hd": {
+		Name:                 "tts-1-hd",
+		Provider:             "openai",
+		ContextWindow:        4096, // Maximum input length in characters
+		SpeechCostPer1MChars: 30.00,
+		Capabilities: types.Capabilities{
+			Speech: true,
+		},
+	},
+	"whisper-1": {
+		Name:                       "whisper-1",
+		Provider:                   "openai",
+		ContextWindow:              224, // Maximum prompt length in tokens
+		TranscriptionCostPerMinute: 0.006,
+		Capabilities: types.Capabilities{
+			Transcription: true,
+		},
+	},
 }
 
 // GetModelInfo returns metadata for a specific model.
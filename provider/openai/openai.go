@@ -23,10 +23,10 @@ package openai
 import (
 	"context"
 	"net/http"
-	"time"
 
 	"github.com/blue-context/warp"
 	"github.com/blue-context/warp/provider"
+	"github.com/blue-context/warp/provider/providercore"
 )
 
 // Provider implements the provider.Provider interface for OpenAI.
@@ -59,7 +59,7 @@ type Option func(*Provider)
 func NewProvider(opts ...Option) (*Provider, error) {
 	p := &Provider{
 		apiBase:    "https://api.openai.com/v1",
-		httpClient: &http.Client{Timeout: 60 * time.Second},
+		httpClient: providercore.NewHTTPClient(),
 	}
 
 	for _, opt := range opts {
@@ -129,6 +129,31 @@ func WithHTTPClient(client warp.HTTPClient) Option {
 	}
 }
 
+// core returns the HTTP client for the OpenAI API.
+func (p *Provider) core() *providercore.Client {
+	return p.coreWith("", "")
+}
+
+// coreWith returns the HTTP client for the OpenAI API with a request's API
+// key and base overrides. Empty values use the provider's.
+func (p *Provider) coreWith(apiKey, apiBase string) *providercore.Client {
+	if apiKey == "" {
+		apiKey = p.apiKey
+	}
+	if apiBase == "" {
+		apiBase = p.apiBase
+	}
+	return &providercore.Client{
+		Provider:   "openai",
+		BaseURL:    apiBase,
+		HTTPClient: p.httpClient,
+		Prepare: func(req *http.Request) error {
+			req.Header.Set("Authorization", "Bearer "+apiKey)
+			return nil
+		},
+	}
+}
+
 // Name returns the provider name "openai".
 //
 // This is used for provider identification in the registry and error messages.
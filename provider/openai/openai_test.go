@@ -13,6 +13,7 @@ import (
 
 	"github.com/blue-context/warp"
 	prov "github.com/blue-context/warp/provider"
+	"github.com/blue-context/warp/provider/providercore"
 )
 
 // mockHTTPClient is a mock HTTP client for testing
@@ -305,28 +306,28 @@ func TestCompletion(t *testing.T) {
 	}
 }
 
-// TestTransformMessages tests the transformMessages function
+// TestTransformMessages tests the conversion of messages to the OpenAI format
 func TestTransformMessages(t *testing.T) {
 	tests := []struct {
 		name     string
 		messages []warp.Message
-		validate func(*testing.T, []map[string]any)
+		validate func(*testing.T, []providercore.Message)
 	}{
 		{
 			name: "simple text message",
 			messages: []warp.Message{
 				{Role: "user", Content: "Hello"},
 			},
-			validate: func(t *testing.T, result []map[string]any) {
+			validate: func(t *testing.T, result []providercore.Message) {
 				if len(result) != 1 {
 					t.Errorf("len(result) = %v, want 1", len(result))
 					return
 				}
-				if result[0]["role"] != "user" {
-					t.Errorf("role = %v, want user", result[0]["role"])
+				if result[0].Role != "user" {
+					t.Errorf("role = %v, want user", result[0].Role)
 				}
-				if result[0]["content"] != "Hello" {
-					t.Errorf("content = %v, want Hello", result[0]["content"])
+				if result[0].Content != "Hello" {
+					t.Errorf("content = %v, want Hello", result[0].Content)
 				}
 			},
 		},
@@ -346,14 +347,14 @@ func TestTransformMessages(t *testing.T) {
 					},
 				},
 			},
-			validate: func(t *testing.T, result []map[string]any) {
+			validate: func(t *testing.T, result []providercore.Message) {
 				if len(result) != 1 {
 					t.Errorf("len(result) = %v, want 1", len(result))
 					return
 				}
-				content, ok := result[0]["content"].([]map[string]any)
+				content, ok := result[0].Content.([]warp.ContentPart)
 				if !ok {
-					t.Errorf("content type = %T, want []map[string]any", result[0]["content"])
+					t.Errorf("content type = %T, want []warp.ContentPart", result[0].Content)
 					return
 				}
 				if len(content) != 2 {
@@ -378,25 +379,21 @@ func TestTransformMessages(t *testing.T) {
 					},
 				},
 			},
-			validate: func(t *testing.T, result []map[string]any) {
+			validate: func(t *testing.T, result []providercore.Message) {
 				if len(result) != 1 {
 					t.Errorf("len(result) = %v, want 1", len(result))
 					return
 				}
-				toolCalls, ok := result[0]["tool_calls"]
-				if !ok {
+				if len(result[0].ToolCalls) == 0 {
 					t.Error("tool_calls not present in result")
 				}
-				if toolCalls == nil {
-					t.Error("tool_calls is nil")
-				}
 			},
 		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			result := transformMessages(tt.messages)
+			result := providercore.Messages(tt.messages)
 			if tt.validate != nil {
 				tt.validate(t, result)
 			}
@@ -1033,3 +1030,177 @@ func TestProviderListModels(t *testing.T) {
 		}
 	}
 }
+
+// TestDisableDataRetention tests that store is disabled and ExtraBody is sent
+func TestDisableDataRetention(t *testing.T) {
+	var captured map[string]any
+	client := &mockHTTPClient{
+		doFunc: func(req *http.Request) (*http.Response, error) {
+			body, _ := io.ReadAll(req.Body)
+			json.Unmarshal(body, &captured)
+			return &http.Response{
+				StatusCode: http.StatusOK,
+				Body:       io.NopCloser(strings.NewReader(`{"id":"1","choices":[]}`)),
+			}, nil
+		},
+	}
+
+	p, err := NewProvider(WithAPIKey("sk-test"), WithHTTPClient(client))
+	if err != nil {
+		t.Fatalf("NewProvider() error = %v", err)
+	}
+
+	req := &warp.CompletionRequest{
+		Model:     "gpt-4o",
+		Messages:  []warp.Message{{Role: "user", Content: "Hi"}},
+		ExtraBody: map[string]any{"seed": 7},
+	}
+	if err := p.DisableDataRetention(req); err != nil {
+		t.Fatalf("DisableDataRetention() error = %v", err)
+	}
+	if _, err := p.Completion(context.Background(), req); err != nil {
+		t.Fatalf("Completion() error = %v", err)
+	}
+
+	if captured["store"] != false || captured["seed"] != float64(7) {
+		t.Errorf("body = %v, want store=false and seed=7", captured)
+	}
+}
+
+func TestMaxStopSequences(t *testing.T) {
+	p, err := NewProvider(WithAPIKey("sk-test"))
+	if err != nil {
+		t.Fatalf("NewProvider() error = %v", err)
+	}
+	for model, want := range map[string]int{"gpt-4o": 4, "o1": 0, "o3-mini": 0, "o4-mini": 0, "omni-moderation-latest": 4} {
+		if got := p.MaxStopSequences(model); got != want {
+			t.Errorf("MaxStopSequences(%q) = %d, want %d", model, got, want)
+		}
+	}
+}
+
+func TestServiceTier(t *testing.T) {
+	var captured map[string]any
+	client := &mockHTTPClient{
+		doFunc: func(req *http.Request) (*http.Response, error) {
+			body, _ := io.ReadAll(req.Body)
+			json.Unmarshal(body, &captured)
+			return &http.Response{
+				StatusCode: http.StatusOK,
+				Body:       io.NopCloser(strings.NewReader(`{"id":"1","choices":[],"service_tier":"flex"}`)),
+			}, nil
+		},
+	}
+
+	p, err := NewProvider(WithAPIKey("sk-test"), WithHTTPClient(client))
+	if err != nil {
+		t.Fatalf("NewProvider() error = %v", err)
+	}
+
+	resp, err := p.Completion(context.Background(), &warp.CompletionRequest{
+		Model:       "o3",
+		Messages:    []warp.Message{{Role: "user", Content: "Hi"}},
+		ServiceTier: warp.ServiceTierFlex,
+	})
+	if err != nil {
+		t.Fatalf("Completion() error = %v", err)
+	}
+
+	if captured["service_tier"] != "flex" {
+		t.Errorf("service_tier = %v, want flex", captured["service_tier"])
+	}
+	if resp.ServiceTier != warp.ServiceTierFlex {
+		t.Errorf("resp.ServiceTier = %q, want flex", resp.ServiceTier)
+	}
+}
+
+func TestPromptCacheKey(t *testing.T) {
+	var captured map[string]any
+	client := &mockHTTPClient{
+		doFunc: func(req *http.Request) (*http.Response, error) {
+			body, _ := io.ReadAll(req.Body)
+			json.Unmarshal(body, &captured)
+			return &http.Response{
+				StatusCode: http.StatusOK,
+				Body: io.NopCloser(strings.NewReader(`{"id":"1","choices":[],` +
+					`"usage":{"prompt_tokens":2048,"completion_tokens":10,"total_tokens":2058,"prompt_tokens_details":{"cached_tokens":1920}}}`)),
+			}, nil
+		},
+	}
+
+	p, err := NewProvider(WithAPIKey("sk-test"), WithHTTPClient(client))
+	if err != nil {
+		t.Fatalf("NewProvider() error = %v", err)
+	}
+
+	resp, err := p.Completion(context.Background(), &warp.CompletionRequest{
+		Model:            "gpt-4o",
+		Messages:         []warp.Message{{Role: "user", Content: "Hi"}},
+		PromptCacheKey:   "support-bot-v2",
+		SafetyIdentifier: "user-5f2b",
+	})
+	if err != nil {
+		t.Fatalf("Completion() error = %v", err)
+	}
+
+	if captured["prompt_cache_key"] != "support-bot-v2" || captured["safety_identifier"] != "user-5f2b" {
+		t.Errorf("request = %v, want prompt_cache_key and safety_identifier", captured)
+	}
+	if got := resp.Usage.GetCachedTokens(); got != 1920 {
+		t.Errorf("cached tokens = %d, want 1920", got)
+	}
+}
+
+func TestResponses(t *testing.T) {
+	var path string
+	var captured map[string]any
+	body := `{"id":"resp_1","object":"response","model":"o3-pro","status":"completed",` +
+		`"output":[{"type":"message","role":"assistant","content":[{"type":"output_text","text":"Hi!"}]}],` +
+		`"usage":{"input_tokens":5,"output_tokens":2,"total_tokens":7}}`
+	client := &mockHTTPClient{
+		doFunc: func(req *http.Request) (*http.Response, error) {
+			path = req.URL.Path
+			data, _ := io.ReadAll(req.Body)
+			json.Unmarshal(data, &captured)
+			return &http.Response{StatusCode: http.StatusOK, Body: io.NopCloser(strings.NewReader(body))}, nil
+		},
+	}
+
+	p, err := NewProvider(WithAPIKey("sk-test"), WithHTTPClient(client))
+	if err != nil {
+		t.Fatalf("NewProvider() error = %v", err)
+	}
+
+	resp, err := p.Responses(context.Background(), &warp.ResponsesRequest{
+		Model: "o3-pro",
+		Input: []warp.ResponseItem{{
+			Type:    "message",
+			Role:    "user",
+			Content: []warp.ResponseContent{{Type: "input_text", Text: "Hello"}},
+		}},
+		ExtraBody: map[string]any{"store": false},
+	})
+	if err != nil {
+		t.Fatalf("Responses() error = %v", err)
+	}
+	if path != "/v1/responses" {
+		t.Errorf("path = %q, want /v1/responses", path)
+	}
+	if captured["model"] != "o3-pro" || captured["store"] != false || captured["input"] == nil {
+		t.Errorf("request = %v, want model, input, and store from ExtraBody", captured)
+	}
+	if resp.OutputText() != "Hi!" || resp.Usage.TotalTokens != 7 {
+		t.Errorf("Responses() = %+v", resp)
+	}
+
+	body = `{"id":"resp_2","status":"failed","error":{"code":"server_error","message":"try again"},"output":[]}`
+	if _, err := p.Responses(context.Background(), &warp.ResponsesRequest{Model: "o3-pro"}); err == nil || !strings.Contains(err.Error(), "try again") {
+		t.Errorf("Responses() error = %v, want the failure", err)
+	}
+
+	for model, want := range map[string]bool{"o3-pro": true, "o1-pro-2025-03-19": true, "codex-mini-latest": true, "o3": false, "gpt-4o": false} {
+		if got := p.ResponsesOnly(model); got != want {
+			t.Errorf("ResponsesOnly(%q) = %v, want %v", model, got, want)
+		}
+	}
+}
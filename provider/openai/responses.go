@@ -0,0 +1,89 @@
+package openai
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/blue-context/warp"
+)
+
+// responsesOnlyModels are the prefixes of models that OpenAI serves only
+// through the Responses API.
+var responsesOnlyModels = []string{
+	"o1-pro",
+	"o3-pro",
+	"o3-deep-research",
+	"o4-mini-deep-research",
+	"codex-mini",
+	"computer-use-preview",
+	"gpt-5-pro",
+	"gpt-5-codex",
+}
+
+// Responses sends a request to OpenAI's Responses API.
+//
+// It implements warp.ResponsesProvider.
+//
+// Example:
+//
+//	resp, err := provider.Responses(ctx, &warp.ResponsesRequest{
+//	    Model: "o3-pro",
+//	    Input: []warp.ResponseItem{{
+//	        Type:    "message",
+//	        Role:    "user",
+//	        Content: []warp.ResponseContent{{Type: "input_text", Text: "Hello!"}},
+//	    }},
+//	})
+func (p *Provider) Responses(ctx context.Context, req *warp.ResponsesRequest) (*warp.ResponsesResponse, error) {
+	body, err := transformResponsesRequest(req)
+	if err != nil {
+		return nil, err
+	}
+
+	var resp warp.ResponsesResponse
+	if err := p.core().PostJSON(ctx, "/responses", body, &resp); err != nil {
+		return nil, err
+	}
+	if resp.Status == "failed" {
+		message := "response failed"
+		if resp.Error != nil {
+			message = fmt.Sprintf("response failed: %s: %s", resp.Error.Code, resp.Error.Message)
+		}
+		return nil, warp.NewAPIError(message, 0, "openai", nil)
+	}
+	return &resp, nil
+}
+
+// ResponsesOnly reports whether model is served only by the Responses
+// API, such as o1-pro, o3-pro, and the deep research and Codex models.
+//
+// It implements warp.ResponsesProvider.
+func (p *Provider) ResponsesOnly(model string) bool {
+	for _, prefix := range responsesOnlyModels {
+		if strings.HasPrefix(model, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// transformResponsesRequest returns the request body of a Responses API
+// request, with its ExtraBody fields merged in.
+func transformResponsesRequest(req *warp.ResponsesRequest) (map[string]any, error) {
+	data, err := json.Marshal(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode request: %w", err)
+	}
+	body := make(map[string]any)
+	if err := json.Unmarshal(data, &body); err != nil {
+		return nil, fmt.Errorf("failed to encode request: %w", err)
+	}
+
+	// Provider-specific fields override generated ones
+	for k, v := range req.ExtraBody {
+		body[k] = v
+	}
+	return body, nil
+}
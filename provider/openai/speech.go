@@ -81,11 +81,15 @@ func (p *Provider) Speech(ctx context.Context, req *warp.SpeechRequest) (io.Read
 	httpReq.Header.Set("Content-Type", "application/json")
 	httpReq.Header.Set("Authorization", "Bearer "+apiKey)
 
+	// Add User-Agent and client request headers
+	warp.SetRequestHeaders(httpReq)
+
 	// Send request
 	httpResp, err := p.httpClient.Do(httpReq)
 	if err != nil {
 		return nil, fmt.Errorf("failed to send request: %w", err)
 	}
+	warp.RecordResponse(httpResp)
 
 	// Check status
 	if httpResp.StatusCode != http.StatusOK {
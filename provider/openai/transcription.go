@@ -1,16 +1,14 @@
 package openai
 
 import (
-	"bytes"
 	"context"
 	"encoding/json"
 	"fmt"
 	"io"
-	"net/http"
 	"strconv"
 
 	"github.com/blue-context/warp"
-	"github.com/blue-context/warp/internal/multipart"
+	"github.com/blue-context/warp/provider/providercore"
 )
 
 // Transcription transcribes audio to text using OpenAI's Whisper model.
@@ -19,6 +17,9 @@ import (
 // response formats (json, text, srt, vtt, verbose_json), and timestamp
 // granularities (word, segment).
 //
+// The file is streamed as the request is sent rather than buffered, so
+// large files can be uploaded; set OnProgress to follow the upload.
+//
 // Thread Safety: This method is safe for concurrent use.
 //
 // Example:
@@ -57,81 +58,31 @@ func (p *Provider) Transcription(ctx context.Context, req *warp.TranscriptionReq
 		}
 	}
 
-	// Use request-specific API key/base if provided
-	apiKey := p.apiKey
-	if req.APIKey != "" {
-		apiKey = req.APIKey
-	}
-
-	apiBase := p.apiBase
-	if req.APIBase != "" {
-		apiBase = req.APIBase
-	}
-
-	// Build form fields
-	fields := map[string]string{
-		"model": req.Model,
-	}
-
+	// Build the form; the file is streamed as the request is sent
+	form := providercore.NewForm()
+	form.AddFile("file", req.Filename, "", req.File)
+	form.AddField("model", req.Model)
 	if req.Language != "" {
-		fields["language"] = req.Language
+		form.AddField("language", req.Language)
 	}
-
 	if req.Prompt != "" {
-		fields["prompt"] = req.Prompt
+		form.AddField("prompt", req.Prompt)
 	}
-
 	if req.ResponseFormat != "" {
-		fields["response_format"] = req.ResponseFormat
+		form.AddField("response_format", req.ResponseFormat)
 	}
-
 	if req.Temperature != nil {
-		fields["temperature"] = strconv.FormatFloat(*req.Temperature, 'f', -1, 64)
+		form.AddField("temperature", strconv.FormatFloat(*req.Temperature, 'f', -1, 64))
 	}
-
-	if len(req.TimestampGranularities) > 0 {
-		// OpenAI expects timestamp_granularities[] as multiple form fields
-		for _, granularity := range req.TimestampGranularities {
-			// Note: For multiple values, we'll handle this specially
-			// For now, join with comma (will be split in multipart encoding if needed)
-			if _, exists := fields["timestamp_granularities[]"]; exists {
-				fields["timestamp_granularities[]"] += "," + granularity
-			} else {
-				fields["timestamp_granularities[]"] = granularity
-			}
-		}
+	// OpenAI expects one timestamp_granularities[] field per value
+	for _, granularity := range req.TimestampGranularities {
+		form.AddField("timestamp_granularities[]", granularity)
 	}
+	form.OnProgress(req.OnProgress)
 
-	// Create multipart form data
-	body, contentType, err := multipart.CreateFormFile("file", req.Filename, req.File, fields)
+	httpResp, err := p.coreWith(req.APIKey, req.APIBase).PostFormStream(ctx, "/audio/transcriptions", form)
 	if err != nil {
-		return nil, &warp.WarpError{
-			Message:  fmt.Sprintf("failed to create multipart form: %v", err),
-			Provider: "openai",
-		}
-	}
-
-	// Create HTTP request
-	url := apiBase + "/audio/transcriptions"
-	httpReq, err := http.NewRequestWithContext(ctx, "POST", url, bytes.NewReader(body))
-	if err != nil {
-		return nil, &warp.WarpError{
-			Message:  fmt.Sprintf("failed to create HTTP request: %v", err),
-			Provider: "openai",
-		}
-	}
-
-	// Set headers
-	httpReq.Header.Set("Content-Type", contentType)
-	httpReq.Header.Set("Authorization", "Bearer "+apiKey)
-
-	// Send request
-	httpResp, err := p.httpClient.Do(httpReq)
-	if err != nil {
-		return nil, &warp.WarpError{
-			Message:  fmt.Sprintf("HTTP request failed: %v", err),
-			Provider: "openai",
-		}
+		return nil, err
 	}
 	defer httpResp.Body.Close()
 
@@ -144,11 +95,6 @@ func (p *Provider) Transcription(ctx context.Context, req *warp.TranscriptionReq
 		}
 	}
 
-	// Check for errors
-	if httpResp.StatusCode != http.StatusOK {
-		return nil, warp.ParseProviderError("openai", httpResp.StatusCode, respBody, nil)
-	}
-
 	// Parse response based on format
 	responseFormat := req.ResponseFormat
 	if responseFormat == "" {
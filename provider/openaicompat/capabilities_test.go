@@ -0,0 +1,19 @@
+//go:build !tinygo && !warp_minimal
+
+package openaicompat
+
+import (
+	"testing"
+
+	"github.com/blue-context/warp/provider"
+)
+
+// TestOpenAICompatCapabilitiesAccuracy verifies that Supports() accurately reflects actual implementation.
+func TestOpenAICompatCapabilitiesAccuracy(t *testing.T) {
+	p, err := New("http://localhost:8080", getTestOptions()...)
+	if err != nil {
+		t.Fatalf("Failed to create provider: %v", err)
+	}
+
+	provider.AssertCapabilitiesAccuracy(t, p)
+}
@@ -0,0 +1,243 @@
+package openaicompat
+
+import (
+	"context"
+	"strings"
+
+	"github.com/blue-context/warp"
+	"github.com/blue-context/warp/provider/providercore"
+)
+
+// Completion sends a chat completion request.
+//
+// Example:
+//
+//	resp, err := provider.Completion(ctx, &warp.CompletionRequest{
+//	    Model: "llama-3.1-8b-instruct",
+//	    Messages: []warp.Message{
+//	        {Role: "user", Content: "Hello!"},
+//	    },
+//	})
+func (p *Provider) Completion(ctx context.Context, req *warp.CompletionRequest) (*warp.CompletionResponse, error) {
+	var resp providercore.Completion
+	if err := p.core().PostJSON(ctx, p.chatPath, p.transformRequest(req, false), &resp); err != nil {
+		return nil, p.learnFromError(req.Model, err)
+	}
+
+	p.learnModel(req.Model, 0)
+	return &resp.CompletionResponse, nil
+}
+
+// Embedding creates embeddings via the embeddings endpoint.
+//
+// Returns a not-supported error if embeddings were not declared with
+// WithCapabilities.
+func (p *Provider) Embedding(ctx context.Context, req *warp.EmbeddingRequest) (*warp.EmbeddingResponse, error) {
+	if !p.caps.Embedding {
+		return nil, p.unsupported("embedding")
+	}
+
+	body := map[string]any{
+		"model": req.Model,
+		"input": req.Input,
+	}
+	if req.EncodingFormat != "" {
+		body["encoding_format"] = req.EncodingFormat
+	}
+	if req.Dimensions != nil {
+		body["dimensions"] = *req.Dimensions
+	}
+	if req.User != "" {
+		body["user"] = req.User
+	}
+
+	var resp warp.EmbeddingResponse
+	if err := p.core().PostJSON(ctx, p.embeddingsPath, body, &resp); err != nil {
+		return nil, err
+	}
+
+	return &resp, nil
+}
+
+// transformRequest converts a Warp request to an OpenAI chat completions
+// body, applying the configured quirks.
+func (p *Provider) transformRequest(req *warp.CompletionRequest, stream bool) map[string]any {
+	body := map[string]any{
+		"model":    req.Model,
+		"messages": p.transformMessages(req.Messages),
+	}
+
+	if req.Temperature != nil {
+		body["temperature"] = *req.Temperature
+	}
+	if req.MaxTokens != nil {
+		if p.quirks&QuirkMaxCompletionTokens != 0 {
+			body["max_completion_tokens"] = *req.MaxTokens
+		} else {
+			body["max_tokens"] = *req.MaxTokens
+		}
+	}
+	if req.TopP != nil {
+		body["top_p"] = *req.TopP
+	}
+	if req.FrequencyPenalty != nil {
+		body["frequency_penalty"] = *req.FrequencyPenalty
+	}
+	if req.PresencePenalty != nil {
+		body["presence_penalty"] = *req.PresencePenalty
+	}
+	if len(req.Stop) > 0 {
+		body["stop"] = req.Stop
+	}
+	if req.N != nil {
+		body["n"] = *req.N
+	}
+	if req.PromptLogprobs {
+		// vLLM and SGLang extension
+		body["prompt_logprobs"] = 0
+	}
+	if len(req.Tools) > 0 {
+		body["tools"] = req.Tools
+	}
+	if req.ToolChoice != nil && p.quirks&QuirkNoToolChoice == 0 {
+		body["tool_choice"] = toolChoice(req.ToolChoice)
+	}
+	if req.ResponseFormat != nil && p.quirks&QuirkNoResponseFormat == 0 {
+		body["response_format"] = req.ResponseFormat
+	}
+
+	// Processing tier, prompt cache routing, and abuse detection, for
+	// servers that proxy OpenAI
+	if req.ServiceTier != "" {
+		body["service_tier"] = req.ServiceTier
+	}
+	if req.PromptCacheKey != "" {
+		body["prompt_cache_key"] = req.PromptCacheKey
+	}
+	if req.SafetyIdentifier != "" {
+		body["safety_identifier"] = req.SafetyIdentifier
+	}
+
+	if stream {
+		body["stream"] = true
+		if p.quirks&QuirkStreamUsage != 0 {
+			body["stream_options"] = map[string]any{"include_usage": true}
+		}
+	}
+
+	// Provider-specific fields override generated ones
+	for k, v := range req.ExtraBody {
+		body[k] = v
+	}
+
+	return body
+}
+
+// toolChoice converts a ToolChoice to the OpenAI wire format: a string for
+// modes, or an object naming a function.
+func toolChoice(tc *warp.ToolChoice) any {
+	if tc.Function != nil {
+		return map[string]any{
+			"type":     "function",
+			"function": map[string]any{"name": tc.Function.Name},
+		}
+	}
+	return tc.Type
+}
+
+// transformMessages converts Warp messages to OpenAI format, applying the
+// content and system-role quirks.
+func (p *Provider) transformMessages(messages []warp.Message) []map[string]any {
+	var system []string
+	out := make([]map[string]any, 0, len(messages))
+
+	for _, msg := range messages {
+		if msg.Role == "system" && p.quirks&QuirkNoSystemRole != 0 {
+			if text := contentText(msg.Content); text != "" {
+				system = append(system, text)
+			}
+			continue
+		}
+
+		m := map[string]any{"role": msg.Role}
+		switch content := msg.Content.(type) {
+		case string:
+			m["content"] = content
+		case []warp.ContentPart:
+			if p.quirks&QuirkStringContent != 0 {
+				m["content"] = contentText(content)
+			} else {
+				m["content"] = content
+			}
+		case nil:
+			if len(msg.ToolCalls) == 0 {
+				m["content"] = ""
+			} else {
+				m["content"] = nil
+			}
+		default:
+			m["content"] = content
+		}
+
+		if msg.Name != "" {
+			m["name"] = msg.Name
+		}
+		if len(msg.ToolCalls) > 0 {
+			m["tool_calls"] = msg.ToolCalls
+		}
+		if msg.ToolCallID != "" {
+			m["tool_call_id"] = msg.ToolCallID
+		}
+		if p.msgMetadata {
+			for k, v := range msg.Metadata {
+				if _, ok := m[k]; !ok {
+					m[k] = v
+				}
+			}
+		}
+
+		out = append(out, m)
+	}
+
+	if len(system) > 0 {
+		prefix := strings.Join(system, "\n\n")
+		merged := false
+		for _, m := range out {
+			if m["role"] == "user" {
+				text, _ := m["content"].(string)
+				if p.quirks&QuirkStringContent == 0 {
+					if parts, ok := m["content"].([]warp.ContentPart); ok {
+						m["content"] = append([]warp.ContentPart{{Type: "text", Text: prefix + "\n\n"}}, parts...)
+						merged = true
+						break
+					}
+				}
+				m["content"] = prefix + "\n\n" + text
+				merged = true
+				break
+			}
+		}
+		if !merged {
+			out = append([]map[string]any{{"role": "user", "content": prefix}}, out...)
+		}
+	}
+
+	return out
+}
+
+// contentText returns the text of message content, joining text parts.
+func contentText(content any) string {
+	switch c := content.(type) {
+	case string:
+		return c
+	case []warp.ContentPart:
+		var parts []string
+		for _, part := range c {
+			if part.Type == "text" && part.Text != "" {
+				parts = append(parts, part.Text)
+			}
+		}
+		return strings.Join(parts, "\n")
+	}
+	return ""
+}
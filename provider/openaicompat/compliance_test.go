@@ -0,0 +1,28 @@
+//go:build !tinygo && !warp_minimal
+
+package openaicompat
+
+import (
+	"testing"
+
+	"github.com/blue-context/warp/provider"
+)
+
+// TestProviderCompliance verifies that this provider implements the Provider interface correctly.
+func TestProviderCompliance(t *testing.T) {
+	p, err := New("http://localhost:8080", getTestOptions()...)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	provider.AssertProviderCompliance(t, p)
+	provider.AssertMethodCount(t, p, "DisableDataRetention", "SupportsDeveloperRole", "MaxStopSequences", "RefreshModels")
+}
+
+// getTestOptions returns options for creating a test provider instance.
+// These options use test values and don't make real API calls.
+func getTestOptions() []Option {
+	return []Option{
+		WithAPIKey("test-key"),
+	}
+}
@@ -0,0 +1,113 @@
+package openaicompat
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"regexp"
+	"strconv"
+
+	"github.com/blue-context/warp"
+)
+
+// contextLimitPattern finds the context window in the message of a
+// context window error, as worded by OpenAI, vLLM, and servers copying
+// them ("This model's maximum context length is 4096 tokens").
+var contextLimitPattern = regexp.MustCompile(`(?i)maximum context length is (\d+)`)
+
+// listedModel is an entry of the models endpoint. Servers that report a
+// context window use one of several field names.
+type listedModel struct {
+	ID               string `json:"id"`
+	MaxModelLen      int    `json:"max_model_len"`      // vLLM
+	ContextWindow    int    `json:"context_window"`     // Groq
+	ContextLength    int    `json:"context_length"`     // OpenRouter
+	MaxContextLength int    `json:"max_context_length"` // LM Studio
+}
+
+// window returns the first context window reported, or 0.
+func (m listedModel) window() int {
+	for _, n := range []int{m.MaxModelLen, m.ContextWindow, m.ContextLength, m.MaxContextLength} {
+		if n > 0 {
+			return n
+		}
+	}
+	return 0
+}
+
+// RefreshModels learns the models served from the server's models
+// endpoint (GET /v1/models by default; see WithModelsPath), with their
+// context windows when the server reports them. ListModels and
+// GetModelInfo include what was learned.
+//
+// The endpoint is optional and many servers do not provide it: if it is
+// missing (404, 405, or 501), RefreshModels returns nil and the provider
+// keeps working with the models declared with WithModels and those
+// learned from requests. Other failures are returned and leave what was
+// learned unchanged.
+//
+// Example:
+//
+//	if err := provider.RefreshModels(ctx); err != nil {
+//	    log.Printf("listing models of %s: %v", provider.Name(), err)
+//	}
+func (p *Provider) RefreshModels(ctx context.Context) error {
+	var resp struct {
+		Data []listedModel `json:"data"`
+	}
+	if err := p.core().GetJSON(ctx, p.modelsPath, &resp); err != nil {
+		var apiErr *warp.APIError
+		if errors.As(err, &apiErr) {
+			switch apiErr.StatusCode {
+			case http.StatusNotFound, http.StatusMethodNotAllowed, http.StatusNotImplemented:
+				return nil
+			}
+		}
+		return err
+	}
+
+	for _, m := range resp.Data {
+		if m.ID != "" {
+			p.learnModel(m.ID, m.window())
+		}
+	}
+	return nil
+}
+
+// learnModel records that the server serves model, with its context
+// window if window is positive. A known window is never cleared.
+func (p *Provider) learnModel(model string, window int) {
+	if model == "" {
+		return
+	}
+	p.modelsMu.Lock()
+	defer p.modelsMu.Unlock()
+	if p.learned == nil {
+		p.learned = make(map[string]int)
+	}
+	if window > 0 || p.learned[model] == 0 {
+		p.learned[model] = window
+	}
+}
+
+// learnFromError records the context window of model stated by a context
+// window error, filling in the error's MaxTokens, and returns err.
+func (p *Provider) learnFromError(model string, err error) error {
+	var cwErr *warp.ContextWindowExceededError
+	if !errors.As(err, &cwErr) {
+		return err
+	}
+	match := contextLimitPattern.FindStringSubmatch(cwErr.Message)
+	if match == nil {
+		return err
+	}
+	window, convErr := strconv.Atoi(match[1])
+	if convErr != nil || window <= 0 {
+		return err
+	}
+	p.learnModel(model, window)
+	if cwErr.MaxTokens == 0 {
+		cwErr.MaxTokens = window
+	}
+	return err
+}
@@ -0,0 +1,488 @@
+// Package openaicompat implements a generic provider for OpenAI-compatible servers.
+//
+// Many servers expose the OpenAI chat completions API: LocalAI, the LiteLLM
+// proxy, FastChat, llama.cpp's server, LM Studio, and most hosted gateways.
+// Their feature sets and edge cases vary, so this provider makes the URL
+// layout, authentication header, declared capabilities, and request quirks
+// configurable.
+//
+// Basic usage:
+//
+//	provider, err := openaicompat.New("http://localhost:4000",
+//	    openaicompat.WithName("litellm"),
+//	    openaicompat.WithAPIKey(os.Getenv("LITELLM_API_KEY")),
+//	)
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+//	client.RegisterProvider(provider)
+//
+//	resp, err := client.Completion(ctx, &warp.CompletionRequest{
+//	    Model: "litellm/gpt-4o-mini",
+//	    Messages: []warp.Message{
+//	        {Role: "user", Content: "Hello!"},
+//	    },
+//	})
+package openaicompat
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/blue-context/warp"
+	"github.com/blue-context/warp/provider"
+	"github.com/blue-context/warp/provider/providercore"
+	"github.com/blue-context/warp/types"
+)
+
+// Quirk enables a workaround for a server that deviates from the OpenAI API.
+// Quirks can be combined with |.
+type Quirk uint
+
+const (
+	// QuirkNoToolChoice omits tool_choice, which some servers reject.
+	QuirkNoToolChoice Quirk = 1 << iota
+
+	// QuirkNoResponseFormat omits response_format, which some servers reject.
+	QuirkNoResponseFormat
+
+	// QuirkStringContent flattens multimodal content to a string of its text
+	// parts, for servers that only accept string content.
+	QuirkStringContent
+
+	// QuirkNoSystemRole merges system messages into the first user message,
+	// for models whose chat template has no system role.
+	QuirkNoSystemRole
+
+	// QuirkMaxCompletionTokens sends max_completion_tokens instead of
+	// max_tokens.
+	QuirkMaxCompletionTokens
+
+	// QuirkStreamUsage requests token usage in the final stream chunk via
+	// stream_options, for servers that support it.
+	QuirkStreamUsage
+)
+
+// Provider implements the provider.Provider interface for any
+// OpenAI-compatible server.
+//
+// Thread Safety: Provider is safe for concurrent use.
+// Multiple goroutines may call methods on the same Provider instance simultaneously.
+type Provider struct {
+	name           string
+	baseURL        string
+	pathPrefix     string
+	chatPath       string
+	embeddingsPath string
+	modelsPath     string
+	apiKey         string
+	authHeader     string
+	headers        map[string]string
+	httpClient     warp.HTTPClient
+	caps           provider.Capabilities
+	quirks         Quirk
+	models         []string
+	zdr            bool
+	developerRole  bool
+	msgMetadata    bool
+	maxStop        int
+
+	// Models learned from the server, by name, with their context window
+	// (0 if unknown)
+	modelsMu sync.RWMutex
+	learned  map[string]int
+}
+
+// Compile-time interface check
+var _ provider.Provider = (*Provider)(nil)
+
+// Option is a functional option for configuring the provider.
+type Option func(*Provider)
+
+// New creates a provider for the OpenAI-compatible server at baseURL.
+//
+// Requests go to baseURL + path prefix + endpoint path, which by default is
+// baseURL + "/v1/chat/completions". The API key is optional, since many
+// self-hosted servers do not require one.
+//
+// Example:
+//
+//	provider, err := openaicompat.New("http://localhost:8080",
+//	    openaicompat.WithName("localai"),
+//	    openaicompat.WithQuirks(openaicompat.QuirkNoToolChoice),
+//	)
+func New(baseURL string, opts ...Option) (*Provider, error) {
+	if baseURL == "" {
+		return nil, &warp.WarpError{
+			Message:  "base URL is required",
+			Provider: "openaicompat",
+		}
+	}
+
+	p := &Provider{
+		name:           "openaicompat",
+		baseURL:        strings.TrimRight(baseURL, "/"),
+		pathPrefix:     "/v1",
+		chatPath:       "/chat/completions",
+		embeddingsPath: "/embeddings",
+		modelsPath:     "/models",
+		authHeader:     "Authorization",
+		headers:        make(map[string]string),
+		httpClient:     providercore.NewHTTPClient(),
+		maxStop:        -1,
+		caps: provider.Capabilities{
+			Completion:      true,
+			Streaming:       true,
+			Embedding:       true,
+			FunctionCalling: true,
+			JSON:            true,
+		},
+	}
+
+	for _, opt := range opts {
+		opt(p)
+	}
+
+	if p.name == "" {
+		return nil, &warp.WarpError{
+			Message:  "provider name cannot be empty",
+			Provider: "openaicompat",
+		}
+	}
+
+	return p, nil
+}
+
+// WithName sets the provider name used in model strings ("name/model").
+//
+// The default is "openaicompat". Use distinct names to register several
+// servers with one client.
+func WithName(name string) Option {
+	return func(p *Provider) {
+		p.name = name
+	}
+}
+
+// WithAPIKey sets the API key sent with each request.
+func WithAPIKey(key string) Option {
+	return func(p *Provider) {
+		p.apiKey = key
+	}
+}
+
+// WithAuthHeader sets the header carrying the API key.
+//
+// The default "Authorization" header sends "Bearer <key>"; any other header
+// sends the key as is (e.g., "api-key" or "X-API-Key").
+func WithAuthHeader(name string) Option {
+	return func(p *Provider) {
+		p.authHeader = name
+	}
+}
+
+// WithHeader adds a header sent with every request.
+func WithHeader(key, value string) Option {
+	return func(p *Provider) {
+		p.headers[key] = value
+	}
+}
+
+// WithPathPrefix sets the path between the base URL and endpoint paths.
+//
+// The default is "/v1". Use "" for servers that mount the API at the root,
+// or e.g. "/openai/v1" for gateways with a custom prefix.
+func WithPathPrefix(prefix string) Option {
+	return func(p *Provider) {
+		p.pathPrefix = strings.TrimRight(prefix, "/")
+	}
+}
+
+// WithChatCompletionsPath sets the chat completions endpoint path
+// (default "/chat/completions").
+func WithChatCompletionsPath(path string) Option {
+	return func(p *Provider) {
+		p.chatPath = path
+	}
+}
+
+// WithEmbeddingsPath sets the embeddings endpoint path (default "/embeddings").
+func WithEmbeddingsPath(path string) Option {
+	return func(p *Provider) {
+		p.embeddingsPath = path
+	}
+}
+
+// WithModelsPath sets the models endpoint path (default "/models"), used
+// by RefreshModels.
+func WithModelsPath(path string) Option {
+	return func(p *Provider) {
+		p.modelsPath = path
+	}
+}
+
+// WithHTTPClient sets a custom HTTP client.
+//
+// This is useful for configuring custom timeouts, transport settings,
+// or injecting mock clients for testing.
+func WithHTTPClient(client warp.HTTPClient) Option {
+	return func(p *Provider) {
+		p.httpClient = client
+	}
+}
+
+// WithCapabilities declares the features the server supports.
+//
+// The default declares completion, streaming, embeddings, function calling,
+// and JSON mode. Only Completion, Streaming, Embedding, FunctionCalling,
+// Vision, and JSON are meaningful; other endpoints are never called.
+// warp.Client.ProbeCapabilities can determine them empirically.
+func WithCapabilities(caps provider.Capabilities) Option {
+	return func(p *Provider) {
+		p.caps = caps
+		p.caps.ImageGeneration = false
+		p.caps.ImageEdit = false
+		p.caps.ImageVariation = false
+		p.caps.Transcription = false
+		p.caps.Speech = false
+		p.caps.Moderation = false
+		p.caps.Rerank = false
+	}
+}
+
+// WithQuirks enables workarounds for server deviations.
+//
+// Example:
+//
+//	openaicompat.WithQuirks(openaicompat.QuirkNoToolChoice | openaicompat.QuirkStringContent)
+func WithQuirks(quirks Quirk) Option {
+	return func(p *Provider) {
+		p.quirks |= quirks
+	}
+}
+
+// WithModels declares the models served, returned by ListModels along with
+// those learned from the server.
+func WithModels(models ...string) Option {
+	return func(p *Provider) {
+		p.models = append(p.models, models...)
+	}
+}
+
+// WithZeroDataRetention declares that the server does not retain prompts
+// or completions, e.g., a self-hosted server without request logging.
+//
+// A client created with warp.WithZeroDataRetention(true) refuses this
+// provider unless this option is set.
+func WithZeroDataRetention() Option {
+	return func(p *Provider) {
+		p.zdr = true
+	}
+}
+
+// WithDeveloperRole declares that the server accepts the "developer"
+// message role. Without it, developer messages are sent as system messages.
+func WithDeveloperRole() Option {
+	return func(p *Provider) {
+		p.developerRole = true
+	}
+}
+
+// WithMessageMetadata sends each message's Metadata entries as extra
+// fields of the message, for servers that accept them (e.g., to feed chat
+// templates). Entries never replace standard fields such as role or
+// content.
+func WithMessageMetadata() Option {
+	return func(p *Provider) {
+		p.msgMetadata = true
+	}
+}
+
+// WithMaxStopSequences declares the number of stop sequences the server
+// accepts (0 for none). Without it, all of a request's stop sequences are
+// sent.
+func WithMaxStopSequences(n int) Option {
+	return func(p *Provider) {
+		p.maxStop = n
+	}
+}
+
+// SupportsDeveloperRole implements warp.DeveloperRoleSupporter.
+//
+// It reports whether WithDeveloperRole was set.
+func (p *Provider) SupportsDeveloperRole(model string) bool {
+	return p.developerRole
+}
+
+// MaxStopSequences implements warp.StopSequenceLimiter.
+//
+// It returns the limit set with WithMaxStopSequences, or -1 for no limit.
+func (p *Provider) MaxStopSequences(model string) int {
+	return p.maxStop
+}
+
+// DisableDataRetention implements warp.DataRetentionController.
+//
+// It returns an error unless WithZeroDataRetention was set.
+func (p *Provider) DisableDataRetention(req *warp.CompletionRequest) error {
+	if !p.zdr {
+		return fmt.Errorf("%s has not declared zero data retention; use WithZeroDataRetention", p.name)
+	}
+	return nil
+}
+
+// Name returns the configured provider name.
+func (p *Provider) Name() string {
+	return p.name
+}
+
+// Supports returns the declared capabilities.
+func (p *Provider) Supports() interface{} {
+	return p.caps
+}
+
+// GetModelInfo returns generic metadata for model.
+//
+// The server's models need not be known in advance, so any model name is
+// accepted and reported with the declared capabilities and no pricing.
+// Embedding models are reported as supporting dimensions, leaving it to the
+// server to refuse them. The context window is 0 (unknown) unless learned
+// from the server's models endpoint (RefreshModels) or from an error
+// response that states it. Register pricing with
+// warp.Client.RegisterModelPricing.
+func (p *Provider) GetModelInfo(model string) *types.ModelInfo {
+	p.modelsMu.RLock()
+	window := p.learned[model]
+	p.modelsMu.RUnlock()
+
+	return &types.ModelInfo{
+		Name:               model,
+		Provider:           p.name,
+		ContextWindow:      window,
+		SupportsVision:     p.caps.Vision,
+		SupportsFunctions:  p.caps.FunctionCalling,
+		SupportsJSON:       p.caps.JSON,
+		SupportsStreaming:  p.caps.Streaming,
+		SupportsDimensions: p.caps.Embedding,
+		Capabilities:       p.caps,
+	}
+}
+
+// ListModels returns the models declared with WithModels, listed by the
+// server's models endpoint (RefreshModels), or used in successful
+// requests, sorted by name. It never calls the server.
+func (p *Provider) ListModels() []*types.ModelInfo {
+	names := make(map[string]bool, len(p.models))
+	for _, name := range p.models {
+		names[name] = true
+	}
+	p.modelsMu.RLock()
+	for name := range p.learned {
+		names[name] = true
+	}
+	p.modelsMu.RUnlock()
+
+	models := make([]*types.ModelInfo, 0, len(names))
+	for name := range names {
+		models = append(models, p.GetModelInfo(name))
+	}
+
+	sort.Slice(models, func(i, j int) bool {
+		return models[i].Name < models[j].Name
+	})
+
+	return models
+}
+
+// core returns the HTTP client for the server.
+func (p *Provider) core() *providercore.Client {
+	return &providercore.Client{
+		Provider:   p.name,
+		BaseURL:    p.url(""),
+		HTTPClient: p.httpClient,
+		Prepare: func(req *http.Request) error {
+			p.setHeaders(req)
+			return nil
+		},
+	}
+}
+
+// setHeaders sets the authentication and custom headers.
+func (p *Provider) setHeaders(req *http.Request) {
+	if p.apiKey != "" {
+		if strings.EqualFold(p.authHeader, "Authorization") {
+			req.Header.Set(p.authHeader, "Bearer "+p.apiKey)
+		} else {
+			req.Header.Set(p.authHeader, p.apiKey)
+		}
+	}
+	for k, v := range p.headers {
+		req.Header.Set(k, v)
+	}
+}
+
+// url returns the full URL of an endpoint path.
+func (p *Provider) url(path string) string {
+	return p.baseURL + p.pathPrefix + path
+}
+
+// unsupported returns the error for a feature the server does not provide.
+func (p *Provider) unsupported(feature string) error {
+	return &warp.WarpError{
+		Message:  feature + " is not supported by " + p.name,
+		Provider: p.name,
+	}
+}
+
+// Rerank ranks documents by relevance to a query.
+//
+// The OpenAI API has no rerank endpoint, so this is not supported.
+func (p *Provider) Rerank(ctx context.Context, req *warp.RerankRequest) (*warp.RerankResponse, error) {
+	return nil, p.unsupported("rerank")
+}
+
+// Moderation checks content for policy violations.
+//
+// Moderation is not supported by generic servers.
+func (p *Provider) Moderation(ctx context.Context, req *warp.ModerationRequest) (*warp.ModerationResponse, error) {
+	return nil, p.unsupported("moderation")
+}
+
+// Transcription transcribes audio to text.
+//
+// Transcription is not supported by generic servers.
+func (p *Provider) Transcription(ctx context.Context, req *warp.TranscriptionRequest) (*warp.TranscriptionResponse, error) {
+	return nil, p.unsupported("transcription")
+}
+
+// Speech converts text to speech.
+//
+// Speech synthesis is not supported by generic servers.
+func (p *Provider) Speech(ctx context.Context, req *warp.SpeechRequest) (io.ReadCloser, error) {
+	return nil, p.unsupported("speech synthesis")
+}
+
+// ImageGeneration generates images from text prompts.
+//
+// Image generation is not supported by generic servers.
+func (p *Provider) ImageGeneration(ctx context.Context, req *warp.ImageGenerationRequest) (*warp.ImageGenerationResponse, error) {
+	return nil, p.unsupported("image generation")
+}
+
+// ImageEdit edits an image using AI based on a text prompt.
+//
+// Image editing is not supported by generic servers.
+func (p *Provider) ImageEdit(ctx context.Context, req *warp.ImageEditRequest) (*warp.ImageGenerationResponse, error) {
+	return nil, p.unsupported("image editing")
+}
+
+// ImageVariation creates variations of an existing image.
+//
+// Image variation is not supported by generic servers.
+func (p *Provider) ImageVariation(ctx context.Context, req *warp.ImageVariationRequest) (*warp.ImageGenerationResponse, error) {
+	return nil, p.unsupported("image variation")
+}
@@ -0,0 +1,434 @@
+package openaicompat
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/blue-context/warp"
+	prov "github.com/blue-context/warp/provider"
+)
+
+// mockHTTPClient is a mock HTTP client for testing
+type mockHTTPClient struct {
+	doFunc func(req *http.Request) (*http.Response, error)
+}
+
+func (m *mockHTTPClient) Do(req *http.Request) (*http.Response, error) {
+	return m.doFunc(req)
+}
+
+// capture returns a client that records the request and body and replies
+// with status and response.
+func capture(got **http.Request, body *map[string]any, status int, response string) *mockHTTPClient {
+	return &mockHTTPClient{doFunc: func(req *http.Request) (*http.Response, error) {
+		*got = req
+		*body = nil
+		if req.Body != nil {
+			data, _ := io.ReadAll(req.Body)
+			json.Unmarshal(data, body)
+		}
+		return &http.Response{StatusCode: status, Body: io.NopCloser(strings.NewReader(response))}, nil
+	}}
+}
+
+const chatResponse = `{"id":"1","object":"chat.completion","model":"m","choices":[{"index":0,"message":{"role":"assistant","content":"Hi"},"finish_reason":"stop"}]}`
+
+// TestNew tests the constructor
+func TestNew(t *testing.T) {
+	if _, err := New(""); err == nil {
+		t.Error("New(\"\") error = nil, want error")
+	}
+	if _, err := New("http://x", WithName("")); err == nil {
+		t.Error("New() with empty name error = nil, want error")
+	}
+
+	p, err := New("http://localhost:4000/", WithName("litellm"))
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	if p.Name() != "litellm" {
+		t.Errorf("Name() = %q", p.Name())
+	}
+	if got := p.url("/chat/completions"); got != "http://localhost:4000/v1/chat/completions" {
+		t.Errorf("url = %q", got)
+	}
+}
+
+// TestCompletion tests URL layout, authentication, and response decoding
+func TestCompletion(t *testing.T) {
+	tests := []struct {
+		name       string
+		opts       []Option
+		wantURL    string
+		wantHeader string
+		wantValue  string
+	}{
+		{
+			name:       "defaults",
+			opts:       []Option{WithAPIKey("k")},
+			wantURL:    "http://host/v1/chat/completions",
+			wantHeader: "Authorization",
+			wantValue:  "Bearer k",
+		},
+		{
+			name:       "custom paths and header",
+			opts:       []Option{WithAPIKey("k"), WithAuthHeader("api-key"), WithPathPrefix("/openai/v2/"), WithChatCompletionsPath("/chat")},
+			wantURL:    "http://host/openai/v2/chat",
+			wantHeader: "api-key",
+			wantValue:  "k",
+		},
+		{
+			name:       "no key",
+			opts:       []Option{WithPathPrefix(""), WithHeader("X-Team", "search")},
+			wantURL:    "http://host/chat/completions",
+			wantHeader: "X-Team",
+			wantValue:  "search",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var req *http.Request
+			var body map[string]any
+			opts := append(tt.opts, WithHTTPClient(capture(&req, &body, http.StatusOK, chatResponse)))
+			p, err := New("http://host", opts...)
+			if err != nil {
+				t.Fatalf("New() error = %v", err)
+			}
+
+			resp, err := p.Completion(context.Background(), &warp.CompletionRequest{
+				Model:    "m",
+				Messages: []warp.Message{{Role: "user", Content: "Hello"}},
+			})
+			if err != nil {
+				t.Fatalf("Completion() error = %v", err)
+			}
+			if resp.Choices[0].Message.Content != "Hi" {
+				t.Errorf("content = %v", resp.Choices[0].Message.Content)
+			}
+			if req.URL.String() != tt.wantURL {
+				t.Errorf("URL = %q, want %q", req.URL, tt.wantURL)
+			}
+			if got := req.Header.Get(tt.wantHeader); got != tt.wantValue {
+				t.Errorf("header %s = %q, want %q", tt.wantHeader, got, tt.wantValue)
+			}
+			if tt.name == "no key" && req.Header.Get("Authorization") != "" {
+				t.Error("Authorization header sent without API key")
+			}
+		})
+	}
+}
+
+// TestQuirks tests request rewriting for server deviations
+func TestQuirks(t *testing.T) {
+	req := &warp.CompletionRequest{
+		Model: "m",
+		Messages: []warp.Message{
+			{Role: "system", Content: "Be brief."},
+			{Role: "user", Content: []warp.ContentPart{{Type: "text", Text: "Describe"}, {Type: "image_url", ImageURL: &warp.ImageURL{URL: "http://img"}}}},
+		},
+		MaxTokens:        warp.IntPtr(10),
+		Tools:            []warp.Tool{{Type: "function", Function: warp.Function{Name: "f"}}},
+		ToolChoice:       &warp.ToolChoice{Function: &warp.Function{Name: "f"}},
+		ResponseFormat:   &warp.ResponseFormat{Type: "json_object"},
+		ServiceTier:      warp.ServiceTierFlex,
+		PromptCacheKey:   "k",
+		SafetyIdentifier: "u1",
+		ExtraBody:        map[string]any{"top_k": 5},
+	}
+
+	p, _ := New("http://host")
+	body := p.transformRequest(req, false)
+	if body["max_tokens"] != 10 || body["top_k"] != 5 || body["response_format"] == nil {
+		t.Errorf("default body = %v", body)
+	}
+	if body["service_tier"] != warp.ServiceTierFlex || body["prompt_cache_key"] != "k" || body["safety_identifier"] != "u1" {
+		t.Errorf("routing fields = %v, %v, %v", body["service_tier"], body["prompt_cache_key"], body["safety_identifier"])
+	}
+	if tc, _ := json.Marshal(body["tool_choice"]); string(tc) != `{"function":{"name":"f"},"type":"function"}` {
+		t.Errorf("tool_choice = %s", tc)
+	}
+	if _, ok := body["stream_options"]; ok {
+		t.Error("stream_options sent without QuirkStreamUsage")
+	}
+
+	p, _ = New("http://host", WithQuirks(QuirkNoToolChoice|QuirkNoResponseFormat|QuirkStringContent|QuirkNoSystemRole|QuirkMaxCompletionTokens|QuirkStreamUsage))
+	body = p.transformRequest(req, true)
+	for _, key := range []string{"tool_choice", "response_format", "max_tokens"} {
+		if _, ok := body[key]; ok {
+			t.Errorf("%s sent despite quirk", key)
+		}
+	}
+	if body["max_completion_tokens"] != 10 {
+		t.Errorf("max_completion_tokens = %v", body["max_completion_tokens"])
+	}
+	if body["stream_options"] == nil || body["stream"] != true {
+		t.Errorf("stream fields = %v, %v", body["stream"], body["stream_options"])
+	}
+	messages := body["messages"].([]map[string]any)
+	if len(messages) != 1 || messages[0]["role"] != "user" || messages[0]["content"] != "Be brief.\n\nDescribe" {
+		t.Errorf("messages = %v", messages)
+	}
+}
+
+// TestCompletionError tests error status handling
+func TestMessageMetadata(t *testing.T) {
+	req := &warp.CompletionRequest{
+		Model: "m",
+		Messages: []warp.Message{
+			{Role: "user", Content: "Hi", Metadata: map[string]any{"turn_id": "t1", "role": "ignored"}},
+		},
+	}
+
+	p, _ := New("http://host")
+	msg := p.transformRequest(req, false)["messages"].([]map[string]any)[0]
+	if _, ok := msg["turn_id"]; ok {
+		t.Error("metadata sent without WithMessageMetadata")
+	}
+	if p.SupportsDeveloperRole("m") {
+		t.Error("SupportsDeveloperRole() = true without WithDeveloperRole")
+	}
+
+	p, _ = New("http://host", WithMessageMetadata(), WithDeveloperRole())
+	msg = p.transformRequest(req, false)["messages"].([]map[string]any)[0]
+	if msg["turn_id"] != "t1" || msg["role"] != "user" {
+		t.Errorf("message = %v, want turn_id added and role kept", msg)
+	}
+	if !p.SupportsDeveloperRole("m") {
+		t.Error("SupportsDeveloperRole() = false with WithDeveloperRole")
+	}
+}
+
+func TestCompletionError(t *testing.T) {
+	var req *http.Request
+	var body map[string]any
+	p, _ := New("http://host", WithName("gw"), WithHTTPClient(capture(&req, &body, http.StatusUnauthorized, `{"error":{"message":"bad key"}}`)))
+
+	_, err := p.Completion(context.Background(), &warp.CompletionRequest{Model: "m"})
+	if err == nil || !strings.Contains(err.Error(), "bad key") {
+		t.Errorf("Completion() error = %v, want bad key", err)
+	}
+}
+
+// TestCompletionStream tests SSE parsing, with and without a [DONE] marker
+func TestCompletionStream(t *testing.T) {
+	for _, done := range []bool{true, false} {
+		stream := "data: {\"choices\":[{\"index\":0,\"delta\":{\"content\":\"Hel\"}}]}\n\n" +
+			": keep-alive\n\n" +
+			"data:{\"choices\":[{\"index\":0,\"delta\":{\"content\":\"lo\"}}]}"
+		if done {
+			stream += "\n\ndata: [DONE]\n\n"
+		}
+
+		var req *http.Request
+		var body map[string]any
+		p, _ := New("http://host", WithHTTPClient(capture(&req, &body, http.StatusOK, stream)))
+		s, err := p.CompletionStream(context.Background(), &warp.CompletionRequest{Model: "m"})
+		if err != nil {
+			t.Fatalf("CompletionStream() error = %v", err)
+		}
+
+		var text bytes.Buffer
+		for {
+			chunk, err := s.Recv()
+			if err == io.EOF {
+				break
+			}
+			if err != nil {
+				t.Fatalf("Recv() error = %v", err)
+			}
+			text.WriteString(chunk.Choices[0].Delta.Content)
+		}
+		s.Close()
+
+		if text.String() != "Hello" {
+			t.Errorf("done=%v: streamed %q, want Hello", done, text.String())
+		}
+		if body["stream"] != true || req.Header.Get("Accept") != "text/event-stream" {
+			t.Errorf("done=%v: stream not requested", done)
+		}
+	}
+}
+
+// TestCompletionStreamReasoning tests DeepSeek-style reasoning_content deltas
+func TestCompletionStreamReasoning(t *testing.T) {
+	stream := "data: {\"choices\":[{\"index\":0,\"delta\":{\"role\":\"assistant\",\"reasoning_content\":\"Add \"}}]}\n\n" +
+		"data: {\"choices\":[{\"index\":0,\"delta\":{\"reasoning_content\":\"them.\"}}]}\n\n" +
+		"data: {\"choices\":[{\"index\":0,\"delta\":{\"content\":\"4\"},\"finish_reason\":\"stop\"}]}\n\n" +
+		"data: [DONE]\n\n"
+
+	var req *http.Request
+	var body map[string]any
+	p, _ := New("http://host", WithHTTPClient(capture(&req, &body, http.StatusOK, stream)))
+	s, err := p.CompletionStream(context.Background(), &warp.CompletionRequest{Model: "deepseek-reasoner"})
+	if err != nil {
+		t.Fatalf("CompletionStream() error = %v", err)
+	}
+	resp, err := warp.CollectStream(s)
+	if err != nil {
+		t.Fatalf("CollectStream() error = %v", err)
+	}
+	if msg := resp.Choices[0].Message; msg.Content != "4" || msg.ReasoningContent != "Add them." {
+		t.Errorf("content = %q, reasoning = %q", msg.Content, msg.ReasoningContent)
+	}
+}
+
+// TestEmbedding tests embeddings and the capability gate
+func TestEmbedding(t *testing.T) {
+	var req *http.Request
+	var body map[string]any
+	client := capture(&req, &body, http.StatusOK, `{"object":"list","data":[{"object":"embedding","embedding":[0.1,0.2],"index":0}],"model":"e"}`)
+
+	p, _ := New("http://host", WithHTTPClient(client), WithEmbeddingsPath("/embed"))
+	resp, err := p.Embedding(context.Background(), &warp.EmbeddingRequest{Model: "e", Input: "hi", Dimensions: warp.IntPtr(2)})
+	if err != nil {
+		t.Fatalf("Embedding() error = %v", err)
+	}
+	if len(resp.Data) != 1 || len(resp.Data[0].Embedding) != 2 {
+		t.Errorf("Embedding() = %+v", resp)
+	}
+	if req.URL.Path != "/v1/embed" || body["dimensions"] != float64(2) {
+		t.Errorf("request path = %q, body = %v", req.URL.Path, body)
+	}
+
+	p, _ = New("http://host", WithCapabilities(prov.Capabilities{Completion: true, Rerank: true}))
+	if _, err := p.Embedding(context.Background(), &warp.EmbeddingRequest{Model: "e", Input: "hi"}); err == nil {
+		t.Error("Embedding() with undeclared capability succeeded")
+	}
+	if caps := p.Supports().(prov.Capabilities); caps.Rerank || caps.Embedding {
+		t.Errorf("Supports() = %+v, want rerank and embedding off", caps)
+	}
+}
+
+// TestEmbeddingDimensionsThroughClient tests that the client forwards
+// dimensions for models it knows nothing about
+func TestEmbeddingDimensionsThroughClient(t *testing.T) {
+	var req *http.Request
+	var body map[string]any
+	httpClient := capture(&req, &body, http.StatusOK, `{"object":"list","data":[{"object":"embedding","embedding":[0.1,0.2],"index":0}],"model":"nomic-embed"}`)
+
+	p, _ := New("http://host", WithHTTPClient(httpClient))
+	client, err := warp.NewClient()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+	client.RegisterProvider(p)
+
+	_, err = client.Embedding(context.Background(), &warp.EmbeddingRequest{Model: "openaicompat/nomic-embed", Input: "hi", Dimensions: warp.IntPtr(2)})
+	if err != nil {
+		t.Fatalf("Embedding() error = %v", err)
+	}
+	if req == nil || body["dimensions"] != float64(2) {
+		t.Errorf("request body = %v, want dimensions forwarded", body)
+	}
+}
+
+// TestModels tests model metadata
+func TestModels(t *testing.T) {
+	p, _ := New("http://host", WithName("gw"), WithModels("b", "a"))
+
+	info := p.GetModelInfo("anything")
+	if info == nil || info.Name != "anything" || info.Provider != "gw" || !info.Capabilities.Completion {
+		t.Errorf("GetModelInfo() = %+v", info)
+	}
+
+	models := p.ListModels()
+	if len(models) != 2 || models[0].Name != "a" || models[1].Name != "b" {
+		t.Errorf("ListModels() = %+v", models)
+	}
+}
+
+// TestRefreshModels tests model discovery and servers without a models
+// endpoint
+func TestRefreshModels(t *testing.T) {
+	var req *http.Request
+	var body map[string]any
+	client := capture(&req, &body, 200, `{"object":"list","data":[{"id":"llama-3.1-8b","max_model_len":8192},{"id":"qwen2.5","context_length":32768},{"id":"phi-3"}]}`)
+	p, _ := New("http://host", WithModels("a"), WithHTTPClient(client), WithModelsPath("/models/list"))
+
+	if err := p.RefreshModels(context.Background()); err != nil {
+		t.Fatalf("RefreshModels() error = %v", err)
+	}
+	if req.Method != http.MethodGet || req.URL.String() != "http://host/v1/models/list" {
+		t.Errorf("request = %s %s", req.Method, req.URL)
+	}
+	models := p.ListModels()
+	if len(models) != 4 || models[0].Name != "a" || models[1].Name != "llama-3.1-8b" {
+		t.Fatalf("ListModels() = %+v", models)
+	}
+	windows := map[string]int{"a": 0, "llama-3.1-8b": 8192, "qwen2.5": 32768, "phi-3": 0}
+	for name, want := range windows {
+		if got := p.GetModelInfo(name).ContextWindow; got != want {
+			t.Errorf("GetModelInfo(%q).ContextWindow = %d, want %d", name, got, want)
+		}
+	}
+
+	for _, status := range []int{404, 405, 501} {
+		p, _ := New("http://host", WithModels("a"), WithHTTPClient(capture(&req, &body, status, "not found")))
+		if err := p.RefreshModels(context.Background()); err != nil {
+			t.Errorf("RefreshModels() with status %d error = %v, want nil", status, err)
+		}
+		if models := p.ListModels(); len(models) != 1 || models[0].Name != "a" {
+			t.Errorf("ListModels() with status %d = %+v", status, models)
+		}
+	}
+
+	p, _ = New("http://host", WithHTTPClient(capture(&req, &body, 401, `{"error":{"message":"bad key"}}`)))
+	if err := p.RefreshModels(context.Background()); err == nil {
+		t.Error("RefreshModels() with status 401 error = nil, want error")
+	}
+}
+
+// TestLearnModels tests model metadata learned from requests
+func TestLearnModels(t *testing.T) {
+	var req *http.Request
+	var body map[string]any
+	p, _ := New("http://host", WithHTTPClient(capture(&req, &body, 200, chatResponse)))
+	msgs := []warp.Message{{Role: "user", Content: "hi"}}
+
+	if _, err := p.Completion(context.Background(), &warp.CompletionRequest{Model: "llama", Messages: msgs}); err != nil {
+		t.Fatalf("Completion() error = %v", err)
+	}
+	if models := p.ListModels(); len(models) != 1 || models[0].Name != "llama" || models[0].ContextWindow != 0 {
+		t.Errorf("ListModels() = %+v", models)
+	}
+
+	p.httpClient = capture(&req, &body, 400, `{"error":{"message":"This model's maximum context length is 4096 tokens. However, you requested 5000 tokens."}}`)
+	_, err := p.Completion(context.Background(), &warp.CompletionRequest{Model: "llama", Messages: msgs})
+	var cwErr *warp.ContextWindowExceededError
+	if !errors.As(err, &cwErr) || cwErr.MaxTokens != 4096 {
+		t.Fatalf("Completion() error = %#v, want context window error with MaxTokens 4096", err)
+	}
+	if got := p.GetModelInfo("llama").ContextWindow; got != 4096 {
+		t.Errorf("ContextWindow = %d, want 4096", got)
+	}
+
+	// A later success does not forget the window
+	p.httpClient = capture(&req, &body, 200, chatResponse)
+	if _, err := p.Completion(context.Background(), &warp.CompletionRequest{Model: "llama", Messages: msgs}); err != nil {
+		t.Fatalf("Completion() error = %v", err)
+	}
+	if got := p.GetModelInfo("llama").ContextWindow; got != 4096 {
+		t.Errorf("ContextWindow after success = %d, want 4096", got)
+	}
+}
+
+// TestDisableDataRetention tests that zero data retention must be declared
+func TestDisableDataRetention(t *testing.T) {
+	p, _ := New("http://host")
+	if err := p.DisableDataRetention(&warp.CompletionRequest{}); err == nil {
+		t.Error("DisableDataRetention() without declaration succeeded")
+	}
+
+	p, _ = New("http://host", WithZeroDataRetention())
+	if err := p.DisableDataRetention(&warp.CompletionRequest{}); err != nil {
+		t.Errorf("DisableDataRetention() error = %v", err)
+	}
+}
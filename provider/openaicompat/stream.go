@@ -0,0 +1,117 @@
+package openaicompat
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+
+	"github.com/blue-context/warp"
+	"github.com/blue-context/warp/sse"
+)
+
+// CompletionStream sends a streaming chat completion request.
+//
+// The caller must close the returned stream to release resources.
+//
+// Example:
+//
+//	stream, err := provider.CompletionStream(ctx, req)
+//	if err != nil {
+//	    return err
+//	}
+//	defer stream.Close()
+//
+//	for {
+//	    chunk, err := stream.Recv()
+//	    if err == io.EOF {
+//	        break
+//	    }
+//	    if err != nil {
+//	        return err
+//	    }
+//	    fmt.Print(chunk.Choices[0].Delta.Content)
+//	}
+func (p *Provider) CompletionStream(ctx context.Context, req *warp.CompletionRequest) (warp.Stream, error) {
+	httpResp, err := p.core().PostStream(ctx, p.chatPath, p.transformRequest(req, true))
+	if err != nil {
+		return nil, p.learnFromError(req.Model, err)
+	}
+	p.learnModel(req.Model, 0)
+
+	return &sseStream{
+		reader: sse.NewReader(httpResp.Body, sse.WithBufferReuse()),
+		closer: httpResp.Body,
+		ctx:    ctx,
+	}, nil
+}
+
+// sseStream implements warp.Stream for Server-Sent Events.
+//
+// Servers that close the connection without a [DONE] marker end the
+// stream with io.EOF as well.
+//
+// Thread Safety: sseStream is NOT safe for concurrent use.
+// Only one goroutine should call Recv() at a time.
+type sseStream struct {
+	reader *sse.Reader
+	closer io.Closer
+	ctx    context.Context
+	err    error // Cached error for subsequent Recv calls
+}
+
+// Recv receives the next chunk from the stream.
+//
+// Returns io.EOF when the stream is complete.
+// After receiving io.EOF or any error, subsequent calls will return the same error.
+func (s *sseStream) Recv() (*warp.CompletionChunk, error) {
+	if s.err != nil {
+		return nil, s.err
+	}
+
+	for {
+		select {
+		case <-s.ctx.Done():
+			s.err = s.ctx.Err()
+			return nil, s.err
+		default:
+		}
+
+		event, err := s.reader.Next()
+		if err != nil {
+			if err == io.EOF {
+				s.err = io.EOF
+				return nil, io.EOF
+			}
+			s.err = fmt.Errorf("failed to read event: %w", err)
+			return nil, s.err
+		}
+
+		data := event.Data
+		if len(bytes.TrimSpace(data)) == 0 {
+			continue
+		}
+
+		if event.Done() {
+			s.err = io.EOF
+			return nil, io.EOF
+		}
+
+		var chunk warp.CompletionChunk
+		if err := s.reader.Decode(&chunk); err != nil {
+			s.err = fmt.Errorf("failed to parse chunk: %w", err)
+			return nil, s.err
+		}
+
+		return &chunk, nil
+	}
+}
+
+// Close closes the stream and releases resources.
+//
+// It is safe to call Close multiple times.
+func (s *sseStream) Close() error {
+	err := s.closer.Close()
+	s.reader.Release()
+	return err
+}
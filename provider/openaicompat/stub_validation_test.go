@@ -0,0 +1,19 @@
+//go:build !tinygo && !warp_minimal
+
+package openaicompat
+
+import (
+	"testing"
+
+	"github.com/blue-context/warp/provider"
+)
+
+// TestStubMethodsReturnWarpError verifies that unsupported methods return proper WarpError.
+func TestStubMethodsReturnWarpError(t *testing.T) {
+	p, err := New("http://localhost:8080", getTestOptions()...)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	provider.AssertStubMethodsReturnWarpError(t, p)
+}
@@ -0,0 +1,207 @@
+package openrouter
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"slices"
+	"sort"
+	"strconv"
+
+	"github.com/blue-context/warp"
+	"github.com/blue-context/warp/types"
+)
+
+// ModelChange describes a model whose catalog entry changed during RefreshModels.
+type ModelChange struct {
+	// Model is the model ID (e.g., "openai/gpt-4o").
+	Model string
+
+	// Previous is the entry before the refresh (nil for newly added models).
+	Previous *types.ModelInfo
+
+	// Current is the entry after the refresh.
+	Current *types.ModelInfo
+}
+
+// WithModelChangeCallback registers a function called after RefreshModels
+// with the models that were added or whose pricing or limits changed.
+//
+// The callback is not called if nothing changed.
+//
+// Example:
+//
+//	provider, err := openrouter.NewProvider(
+//	    openrouter.WithAPIKey("sk-or-v1-..."),
+//	    openrouter.WithModelChangeCallback(func(changes []openrouter.ModelChange) {
+//	        for _, c := range changes {
+//	            log.Printf("model %s updated", c.Model)
+//	        }
+//	    }),
+//	)
+func WithModelChangeCallback(fn func(changes []ModelChange)) Option {
+	return func(p *Provider) {
+		p.onModelsChange = fn
+	}
+}
+
+// catalogModel is a model entry from OpenRouter's /models endpoint.
+type catalogModel struct {
+	ID            string `json:"id"`
+	ContextLength int    `json:"context_length"`
+	Pricing       struct {
+		Prompt     string `json:"prompt"`
+		Completion string `json:"completion"`
+	} `json:"pricing"`
+	TopProvider struct {
+		MaxCompletionTokens int `json:"max_completion_tokens"`
+	} `json:"top_provider"`
+	Architecture struct {
+		InputModalities []string `json:"input_modalities"`
+	} `json:"architecture"`
+	SupportedParameters []string `json:"supported_parameters"`
+}
+
+// RefreshModels fetches the live model catalog and merges it with the
+// compiled-in table.
+//
+// Context windows, output limits, and pricing from the live catalog replace
+// the static values; models missing from the static table are added with
+// capabilities inferred from their modalities and supported parameters.
+// Static entries not in the live catalog are kept.
+//
+// Thread Safety: Safe for concurrent use with GetModelInfo and ListModels.
+//
+// Example:
+//
+//	if err := provider.RefreshModels(ctx); err != nil {
+//	    log.Printf("using compiled-in catalog: %v", err)
+//	}
+func (p *Provider) RefreshModels(ctx context.Context) error {
+	httpReq, err := http.NewRequestWithContext(ctx, "GET", p.apiBase+"/models", nil)
+	if err != nil {
+		return fmt.Errorf("failed to create models request: %w", err)
+	}
+	httpReq.Header.Set("Authorization", "Bearer "+p.apiKey)
+
+	// Add User-Agent and client request headers
+	warp.SetRequestHeaders(httpReq)
+
+	httpResp, err := p.httpClient.Do(httpReq)
+	if err != nil {
+		return fmt.Errorf("failed to send models request: %w", err)
+	}
+	warp.RecordResponse(httpResp)
+	defer httpResp.Body.Close()
+
+	if httpResp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(httpResp.Body)
+		return warp.ParseProviderError("openrouter", httpResp.StatusCode, body, nil)
+	}
+
+	var resp struct {
+		Data []catalogModel `json:"data"`
+	}
+	if err := json.NewDecoder(httpResp.Body).Decode(&resp); err != nil {
+		return fmt.Errorf("failed to decode models response: %w", err)
+	}
+
+	live := make(map[string]*types.ModelInfo, len(resp.Data))
+	for _, m := range resp.Data {
+		if m.ID == "" {
+			continue
+		}
+		live[m.ID] = mergeCatalogModel(modelRegistry[m.ID], m)
+	}
+
+	p.modelsMu.Lock()
+	var changes []ModelChange
+	for id, current := range live {
+		previous, ok := p.liveModels[id]
+		if !ok {
+			previous = modelRegistry[id]
+		}
+		if modelChanged(previous, current) {
+			changes = append(changes, ModelChange{Model: id, Previous: previous, Current: current})
+		}
+	}
+	p.liveModels = live
+	p.modelsMu.Unlock()
+
+	if len(changes) > 0 && p.onModelsChange != nil {
+		sort.Slice(changes, func(i, j int) bool {
+			return changes[i].Model < changes[j].Model
+		})
+		p.onModelsChange(changes)
+	}
+
+	return nil
+}
+
+// mergeCatalogModel builds a ModelInfo from a live catalog entry, starting
+// from the static entry if one exists.
+func mergeCatalogModel(static *types.ModelInfo, m catalogModel) *types.ModelInfo {
+	var info types.ModelInfo
+	if static != nil {
+		info = *static
+	} else {
+		info = types.ModelInfo{
+			Name:     m.ID,
+			Provider: "openrouter",
+			Capabilities: types.Capabilities{
+				Completion:      true,
+				Streaming:       true,
+				Vision:          slices.Contains(m.Architecture.InputModalities, "image"),
+				FunctionCalling: slices.Contains(m.SupportedParameters, "tools"),
+				JSON:            slices.Contains(m.SupportedParameters, "response_format"),
+			},
+		}
+		info.SupportsStreaming = true
+		info.SupportsVision = info.Capabilities.Vision
+		info.SupportsFunctions = info.Capabilities.FunctionCalling
+		info.SupportsJSON = info.Capabilities.JSON
+	}
+
+	if m.ContextLength > 0 {
+		info.ContextWindow = m.ContextLength
+	}
+	if m.TopProvider.MaxCompletionTokens > 0 {
+		info.MaxOutputTokens = m.TopProvider.MaxCompletionTokens
+	}
+	if price, ok := perTokenToPer1M(m.Pricing.Prompt); ok {
+		info.InputCostPer1M = price
+	}
+	if price, ok := perTokenToPer1M(m.Pricing.Completion); ok {
+		info.OutputCostPer1M = price
+	}
+
+	return &info
+}
+
+// perTokenToPer1M converts OpenRouter's per-token USD price string to a
+// per-1M-token price.
+//
+// Returns false for missing or negative (variable, e.g. the auto router) prices.
+func perTokenToPer1M(price string) (float64, bool) {
+	if price == "" {
+		return 0, false
+	}
+	v, err := strconv.ParseFloat(price, 64)
+	if err != nil || v < 0 {
+		return 0, false
+	}
+	return v * 1_000_000, true
+}
+
+// modelChanged reports whether a refresh changed a model's limits or pricing.
+func modelChanged(previous, current *types.ModelInfo) bool {
+	if previous == nil {
+		return true
+	}
+	return previous.ContextWindow != current.ContextWindow ||
+		previous.MaxOutputTokens != current.MaxOutputTokens ||
+		previous.InputCostPer1M != current.InputCostPer1M ||
+		previous.OutputCostPer1M != current.OutputCostPer1M
+}
@@ -0,0 +1,130 @@
+package openrouter
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+const catalogResponse = `{"data": [
+	{
+		"id": "openai/gpt-4o",
+		"context_length": 256000,
+		"pricing": {"prompt": "0.0000025", "completion": "0.00001"},
+		"top_provider": {"max_completion_tokens": 32768}
+	},
+	{
+		"id": "acme/new-model",
+		"context_length": 32000,
+		"pricing": {"prompt": "0.000001", "completion": "0.000002"},
+		"architecture": {"input_modalities": ["text", "image"]},
+		"supported_parameters": ["tools", "temperature"]
+	},
+	{
+		"id": "openrouter/auto",
+		"context_length": 2000000,
+		"pricing": {"prompt": "-1", "completion": "-1"}
+	}
+]}`
+
+// TestRefreshModels tests live catalog sync and change notifications
+func TestRefreshModels(t *testing.T) {
+	mockClient := &mockHTTPClient{
+		doFunc: func(req *http.Request) (*http.Response, error) {
+			if !strings.HasSuffix(req.URL.Path, "/models") {
+				t.Errorf("request path = %s, want suffix /models", req.URL.Path)
+			}
+			return &http.Response{
+				StatusCode: http.StatusOK,
+				Body:       io.NopCloser(strings.NewReader(catalogResponse)),
+				Header:     make(http.Header),
+			}, nil
+		},
+	}
+
+	var notified []ModelChange
+	provider, err := NewProvider(
+		WithAPIKey("sk-or-v1-test"),
+		WithHTTPClient(mockClient),
+		WithModelChangeCallback(func(changes []ModelChange) {
+			notified = append(notified, changes...)
+		}),
+	)
+	if err != nil {
+		t.Fatalf("NewProvider() error = %v", err)
+	}
+
+	staticCount := len(provider.ListModels())
+
+	if err := provider.RefreshModels(context.Background()); err != nil {
+		t.Fatalf("RefreshModels() error = %v", err)
+	}
+
+	gpt := provider.GetModelInfo("openai/gpt-4o")
+	if gpt.ContextWindow != 256000 || gpt.MaxOutputTokens != 32768 {
+		t.Errorf("gpt-4o limits = %d/%d, want 256000/32768", gpt.ContextWindow, gpt.MaxOutputTokens)
+	}
+	if gpt.InputCostPer1M != 2.5 || gpt.OutputCostPer1M != 10 {
+		t.Errorf("gpt-4o pricing = %f/%f, want 2.5/10", gpt.InputCostPer1M, gpt.OutputCostPer1M)
+	}
+	if !gpt.Capabilities.Vision {
+		t.Error("gpt-4o should keep static capabilities")
+	}
+
+	added := provider.GetModelInfo("acme/new-model")
+	if added == nil {
+		t.Fatal("GetModelInfo(acme/new-model) = nil, want live entry")
+	}
+	if !added.Capabilities.Vision || !added.Capabilities.FunctionCalling || added.Capabilities.JSON {
+		t.Errorf("inferred capabilities = %+v", added.Capabilities)
+	}
+
+	// Variable pricing keeps the static value
+	if auto := provider.GetModelInfo("openrouter/auto"); auto != nil && auto.InputCostPer1M < 0 {
+		t.Errorf("openrouter/auto pricing = %f, want non-negative", auto.InputCostPer1M)
+	}
+
+	if got := len(provider.ListModels()); got != staticCount+1 {
+		t.Errorf("ListModels() = %d models, want %d", got, staticCount+1)
+	}
+
+	if len(notified) < 2 {
+		t.Errorf("change callback got %d changes, want at least 2", len(notified))
+	}
+
+	// A second identical refresh reports no changes
+	notified = nil
+	if err := provider.RefreshModels(context.Background()); err != nil {
+		t.Fatalf("RefreshModels() error = %v", err)
+	}
+	if len(notified) != 0 {
+		t.Errorf("change callback got %d changes on unchanged catalog, want 0", len(notified))
+	}
+}
+
+// TestRefreshModelsError tests that a failed refresh keeps the static catalog
+func TestRefreshModelsError(t *testing.T) {
+	mockClient := &mockHTTPClient{
+		doFunc: func(req *http.Request) (*http.Response, error) {
+			return &http.Response{
+				StatusCode: http.StatusInternalServerError,
+				Body:       io.NopCloser(strings.NewReader(`{"error": {"message": "boom"}}`)),
+				Header:     make(http.Header),
+			}, nil
+		},
+	}
+
+	provider, err := NewProvider(WithAPIKey("sk-or-v1-test"), WithHTTPClient(mockClient))
+	if err != nil {
+		t.Fatalf("NewProvider() error = %v", err)
+	}
+
+	if err := provider.RefreshModels(context.Background()); err == nil {
+		t.Error("RefreshModels() expected error")
+	}
+	if provider.GetModelInfo("openai/gpt-4o") == nil {
+		t.Error("static catalog should remain available after failed refresh")
+	}
+}
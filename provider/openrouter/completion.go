@@ -1,14 +1,13 @@
 package openrouter
 
 import (
-	"bytes"
 	"context"
 	"encoding/json"
 	"fmt"
 	"io"
-	"net/http"
 
 	"github.com/blue-context/warp"
+	"github.com/blue-context/warp/provider/providercore"
 )
 
 // Completion sends a chat completion request to OpenRouter.
@@ -34,50 +33,29 @@ func (p *Provider) Completion(ctx context.Context, req *warp.CompletionRequest)
 
 	// Transform request to OpenRouter format (OpenAI-compatible)
 	openrouterReq := transformRequest(req)
-
-	// Marshal to JSON
-	body, err := json.Marshal(openrouterReq)
-	if err != nil {
-		return nil, fmt.Errorf("failed to marshal request for model %s: %w", req.Model, err)
-	}
-
-	// Create HTTP request
-	httpReq, err := http.NewRequestWithContext(ctx, "POST", p.apiBase+"/chat/completions", bytes.NewReader(body))
-	if err != nil {
-		return nil, fmt.Errorf("failed to create request for model %s: %w", req.Model, err)
-	}
-
-	// Set standard headers
-	httpReq.Header.Set("Content-Type", "application/json")
-	httpReq.Header.Set("Authorization", "Bearer "+p.apiKey)
-
-	// Set optional custom headers for rankings and analytics
-	if p.httpReferer != "" {
-		httpReq.Header.Set("HTTP-Referer", p.httpReferer)
-	}
-	if p.appTitle != "" {
-		httpReq.Header.Set("X-Title", p.appTitle)
-	}
+	p.applyRouting(openrouterReq, req)
 
 	// Send request
-	httpResp, err := p.httpClient.Do(httpReq)
+	httpResp, err := p.core().Post(ctx, "/chat/completions", openrouterReq)
 	if err != nil {
-		return nil, fmt.Errorf("failed to send request to model %s: %w", req.Model, err)
+		return nil, err
 	}
 	defer httpResp.Body.Close()
 
-	// Check status code
-	if httpResp.StatusCode != http.StatusOK {
-		body, _ := io.ReadAll(httpResp.Body)
-		return nil, warp.ParseProviderError("openrouter", httpResp.StatusCode, body, nil)
+	respBody, err := io.ReadAll(httpResp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response from model %s: %w", req.Model, err)
 	}
 
 	// Parse response (OpenAI-compatible format)
 	var resp warp.CompletionResponse
-	if err := json.NewDecoder(httpResp.Body).Decode(&resp); err != nil {
+	if err := json.Unmarshal(respBody, &resp); err != nil {
 		return nil, fmt.Errorf("failed to decode response from model %s: %w", req.Model, err)
 	}
 
+	// Surface which upstream provider served the request
+	recordUpstreamProvider(&resp, respBody)
+
 	return &resp, nil
 }
 
@@ -89,7 +67,7 @@ func (p *Provider) Completion(ctx context.Context, req *warp.CompletionRequest)
 func transformRequest(req *warp.CompletionRequest) map[string]any {
 	openrouterReq := map[string]any{
 		"model":    req.Model,
-		"messages": transformMessages(req.Messages),
+		"messages": providercore.Messages(req.Messages),
 	}
 
 	// Optional parameters
@@ -131,53 +109,3 @@ func transformRequest(req *warp.CompletionRequest) map[string]any {
 
 	return openrouterReq
 }
-
-// transformMessages transforms Warp messages to OpenRouter format.
-//
-// This function handles both simple text content and multimodal content
-// (text + images) according to OpenRouter's OpenAI-compatible message format.
-func transformMessages(messages []warp.Message) []map[string]any {
-	openrouterMessages := make([]map[string]any, len(messages))
-
-	for i, msg := range messages {
-		openrouterMsg := map[string]any{
-			"role": msg.Role,
-		}
-
-		// Handle content (can be string or []ContentPart for multimodal)
-		switch content := msg.Content.(type) {
-		case string:
-			openrouterMsg["content"] = content
-		case []warp.ContentPart:
-			// Multimodal content (text + images)
-			parts := make([]map[string]any, len(content))
-			for j, part := range content {
-				parts[j] = map[string]any{
-					"type": part.Type,
-				}
-				if part.Text != "" {
-					parts[j]["text"] = part.Text
-				}
-				if part.ImageURL != nil {
-					parts[j]["image_url"] = part.ImageURL
-				}
-			}
-			openrouterMsg["content"] = parts
-		}
-
-		// Optional fields
-		if msg.Name != "" {
-			openrouterMsg["name"] = msg.Name
-		}
-		if len(msg.ToolCalls) > 0 {
-			openrouterMsg["tool_calls"] = msg.ToolCalls
-		}
-		if msg.ToolCallID != "" {
-			openrouterMsg["tool_call_id"] = msg.ToolCallID
-		}
-
-		openrouterMessages[i] = openrouterMsg
-	}
-
-	return openrouterMessages
-}
@@ -1,12 +1,9 @@
 package openrouter
 
 import (
-	"bytes"
 	"context"
 	"encoding/json"
 	"fmt"
-	"io"
-	"net/http"
 
 	"github.com/blue-context/warp"
 )
@@ -64,43 +61,13 @@ func (p *Provider) Embedding(ctx context.Context, req *warp.EmbeddingRequest) (*
 		openrouterReq["user"] = req.User
 	}
 
-	// Marshal to JSON
-	body, err := json.Marshal(openrouterReq)
-	if err != nil {
-		return nil, fmt.Errorf("failed to marshal embedding request for model %s: %w", req.Model, err)
-	}
-
-	// Create HTTP request
-	httpReq, err := http.NewRequestWithContext(ctx, "POST", p.apiBase+"/embeddings", bytes.NewReader(body))
-	if err != nil {
-		return nil, fmt.Errorf("failed to create embedding request for model %s: %w", req.Model, err)
-	}
-
-	// Set standard headers
-	httpReq.Header.Set("Content-Type", "application/json")
-	httpReq.Header.Set("Authorization", "Bearer "+p.apiKey)
-
-	// Set optional custom headers for rankings and analytics
-	if p.httpReferer != "" {
-		httpReq.Header.Set("HTTP-Referer", p.httpReferer)
-	}
-	if p.appTitle != "" {
-		httpReq.Header.Set("X-Title", p.appTitle)
-	}
-
 	// Send request
-	httpResp, err := p.httpClient.Do(httpReq)
+	httpResp, err := p.core().Post(ctx, "/embeddings", openrouterReq)
 	if err != nil {
-		return nil, fmt.Errorf("failed to send embedding request to model %s: %w", req.Model, err)
+		return nil, err
 	}
 	defer httpResp.Body.Close()
 
-	// Check status code
-	if httpResp.StatusCode != http.StatusOK {
-		body, _ := io.ReadAll(httpResp.Body)
-		return nil, warp.ParseProviderError("openrouter", httpResp.StatusCode, body, nil)
-	}
-
 	// Parse response (OpenAI-compatible format)
 	var resp warp.EmbeddingResponse
 	if err := json.NewDecoder(httpResp.Body).Decode(&resp); err != nil {
@@ -0,0 +1,109 @@
+package openrouter
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/blue-context/warp"
+)
+
+// KeyInfo describes the credit balance and rate limits of an OpenRouter API key.
+type KeyInfo struct {
+	// Label is the key's display label.
+	Label string `json:"label"`
+
+	// Usage is the number of credits (USD) used by the key.
+	Usage float64 `json:"usage"`
+
+	// Limit is the key's credit limit in USD (nil means unlimited).
+	Limit *float64 `json:"limit"`
+
+	// LimitRemaining is the remaining credit in USD (nil means unlimited).
+	LimitRemaining *float64 `json:"limit_remaining"`
+
+	// IsFreeTier reports whether the key has never purchased credits.
+	IsFreeTier bool `json:"is_free_tier"`
+
+	// RateLimit is the request rate limit applied to the key.
+	RateLimit RateLimit `json:"rate_limit"`
+}
+
+// RateLimit describes an OpenRouter request rate limit.
+type RateLimit struct {
+	// Requests is the number of requests allowed per Interval.
+	Requests int `json:"requests"`
+
+	// Interval is the rate limit window (e.g., "10s").
+	Interval string `json:"interval"`
+}
+
+// Exhausted reports whether the key has a credit limit and no credit left.
+func (k *KeyInfo) Exhausted() bool {
+	return k != nil && k.LimitRemaining != nil && *k.LimitRemaining <= 0
+}
+
+// GetKeyInfo fetches the remaining credits and rate limits for the API key.
+//
+// Wraps GET /auth/key. Useful for surfacing balance to users or skipping
+// OpenRouter before credits run out.
+//
+// Example:
+//
+//	info, err := provider.GetKeyInfo(ctx)
+//	if err != nil {
+//	    return err
+//	}
+//	if info.Exhausted() {
+//	    log.Println("OpenRouter credits exhausted")
+//	}
+func (p *Provider) GetKeyInfo(ctx context.Context) (*KeyInfo, error) {
+	httpReq, err := http.NewRequestWithContext(ctx, "GET", p.apiBase+"/auth/key", nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create key info request: %w", err)
+	}
+	httpReq.Header.Set("Authorization", "Bearer "+p.apiKey)
+
+	// Add User-Agent and client request headers
+	warp.SetRequestHeaders(httpReq)
+
+	httpResp, err := p.httpClient.Do(httpReq)
+	if err != nil {
+		return nil, fmt.Errorf("failed to send key info request: %w", err)
+	}
+	warp.RecordResponse(httpResp)
+	defer httpResp.Body.Close()
+
+	if httpResp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(httpResp.Body)
+		return nil, warp.ParseProviderError("openrouter", httpResp.StatusCode, body, nil)
+	}
+
+	var resp struct {
+		Data KeyInfo `json:"data"`
+	}
+	if err := json.NewDecoder(httpResp.Body).Decode(&resp); err != nil {
+		return nil, fmt.Errorf("failed to decode key info response: %w", err)
+	}
+
+	return &resp.Data, nil
+}
+
+// QuotaUsage reports the key's credit usage against its credit limit, in
+// USD. Keys without a credit limit report a zero Limit.
+//
+// It implements warp.QuotaReporter for the quota package.
+func (p *Provider) QuotaUsage(ctx context.Context) (*warp.QuotaUsage, error) {
+	info, err := p.GetKeyInfo(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	usage := &warp.QuotaUsage{Used: info.Usage, Unit: "usd"}
+	if info.Limit != nil {
+		usage.Limit = *info.Limit
+	}
+	return usage, nil
+}
@@ -0,0 +1,113 @@
+package openrouter
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+// TestGetKeyInfo tests the key info endpoint
+func TestGetKeyInfo(t *testing.T) {
+	tests := []struct {
+		name          string
+		statusCode    int
+		body          string
+		wantErr       bool
+		wantExhausted bool
+		wantUnlimited bool
+	}{
+		{
+			name:       "limited key",
+			statusCode: http.StatusOK,
+			body: `{"data": {"label": "sk-or-v1-abc", "usage": 12.5, "limit": 20, "limit_remaining": 7.5,
+				"is_free_tier": false, "rate_limit": {"requests": 100, "interval": "10s"}}}`,
+		},
+		{
+			name:          "exhausted key",
+			statusCode:    http.StatusOK,
+			body:          `{"data": {"usage": 20, "limit": 20, "limit_remaining": 0}}`,
+			wantExhausted: true,
+		},
+		{
+			name:          "unlimited key",
+			statusCode:    http.StatusOK,
+			body:          `{"data": {"usage": 3, "limit": null, "limit_remaining": null}}`,
+			wantUnlimited: true,
+		},
+		{
+			name:       "unauthorized",
+			statusCode: http.StatusUnauthorized,
+			body:       `{"error": {"message": "Invalid API key"}}`,
+			wantErr:    true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var capturedReq *http.Request
+			mockClient := &mockHTTPClient{
+				doFunc: func(req *http.Request) (*http.Response, error) {
+					capturedReq = req
+					return &http.Response{
+						StatusCode: tt.statusCode,
+						Body:       io.NopCloser(strings.NewReader(tt.body)),
+						Header:     make(http.Header),
+					}, nil
+				},
+			}
+
+			provider, err := NewProvider(WithAPIKey("sk-or-v1-test"), WithHTTPClient(mockClient))
+			if err != nil {
+				t.Fatalf("NewProvider() error = %v", err)
+			}
+
+			info, err := provider.GetKeyInfo(context.Background())
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("GetKeyInfo() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			if !strings.HasSuffix(capturedReq.URL.Path, "/auth/key") {
+				t.Errorf("request path = %s, want suffix /auth/key", capturedReq.URL.Path)
+			}
+			if auth := capturedReq.Header.Get("Authorization"); auth != "Bearer sk-or-v1-test" {
+				t.Errorf("Authorization = %q, want Bearer sk-or-v1-test", auth)
+			}
+
+			if tt.wantErr {
+				return
+			}
+			if info.Exhausted() != tt.wantExhausted {
+				t.Errorf("Exhausted() = %v, want %v", info.Exhausted(), tt.wantExhausted)
+			}
+			if (info.Limit == nil) != tt.wantUnlimited {
+				t.Errorf("Limit = %v, want unlimited=%v", info.Limit, tt.wantUnlimited)
+			}
+		})
+	}
+}
+
+func TestQuotaUsage(t *testing.T) {
+	mockClient := &mockHTTPClient{
+		doFunc: func(req *http.Request) (*http.Response, error) {
+			return &http.Response{
+				StatusCode: http.StatusOK,
+				Body:       io.NopCloser(strings.NewReader(`{"data": {"usage": 12.5, "limit": 20, "limit_remaining": 7.5}}`)),
+				Header:     make(http.Header),
+			}, nil
+		},
+	}
+	provider, err := NewProvider(WithAPIKey("sk-or-v1-test"), WithHTTPClient(mockClient))
+	if err != nil {
+		t.Fatalf("NewProvider() error = %v", err)
+	}
+
+	usage, err := provider.QuotaUsage(context.Background())
+	if err != nil {
+		t.Fatalf("QuotaUsage() error = %v", err)
+	}
+	if usage.Used != 12.5 || usage.Limit != 20 || usage.Unit != "usd" || usage.Remaining() != 7.5 {
+		t.Errorf("QuotaUsage() = %+v", usage)
+	}
+}
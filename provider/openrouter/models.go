@@ -381,31 +381,33 @@ var modelRegistry = map[string]*types.ModelInfo{
 		},
 	},
 	"openai/text-embedding-3-small": {
-		Name:              "openai/text-embedding-3-small",
-		Provider:          "openrouter",
-		ContextWindow:     8191,
-		MaxOutputTokens:   0,
-		InputCostPer1M:    0.02,
-		OutputCostPer1M:   0.00,
-		SupportsVision:    false,
-		SupportsFunctions: false,
-		SupportsJSON:      false,
-		SupportsStreaming: false,
+		Name:               "openai/text-embedding-3-small",
+		Provider:           "openrouter",
+		ContextWindow:      8191,
+		MaxOutputTokens:    0,
+		InputCostPer1M:     0.02,
+		OutputCostPer1M:    0.00,
+		SupportsVision:     false,
+		SupportsFunctions:  false,
+		SupportsJSON:       false,
+		SupportsStreaming:  false,
+		SupportsDimensions: true,
 		Capabilities: types.Capabilities{
 			Embedding: true,
 		},
 	},
 	"openai/text-embedding-3-large": {
-		Name:              "openai/text-embedding-3-large",
-		Provider:          "openrouter",
-		ContextWindow:     8191,
-		MaxOutputTokens:   0,
-		InputCostPer1M:    0.13,
-		OutputCostPer1M:   0.00,
-		SupportsVision:    false,
-		SupportsFunctions: false,
-		SupportsJSON:      false,
-		SupportsStreaming: false,
+		Name:               "openai/text-embedding-3-large",
+		Provider:           "openrouter",
+		ContextWindow:      8191,
+		MaxOutputTokens:    0,
+		InputCostPer1M:     0.13,
+		OutputCostPer1M:    0.00,
+		SupportsVision:     false,
+		SupportsFunctions:  false,
+		SupportsJSON:       false,
+		SupportsStreaming:  false,
+		SupportsDimensions: true,
 		Capabilities: types.Capabilities{
 			Embedding: true,
 		},
@@ -468,7 +470,15 @@ var modelRegistry = map[string]*types.ModelInfo{
 //
 // Thread Safety: Safe for concurrent use.
 func (p *Provider) GetModelInfo(model string) *types.ModelInfo {
-	info, exists := modelRegistry[model]
+	// Live catalog entries (from RefreshModels) take precedence
+	p.modelsMu.RLock()
+	info, exists := p.liveModels[model]
+	p.modelsMu.RUnlock()
+	if exists {
+		return info
+	}
+
+	info, exists = modelRegistry[model]
 	if exists {
 		return info
 	}
@@ -480,14 +490,22 @@ func (p *Provider) GetModelInfo(model string) *types.ModelInfo {
 // ListModels returns all supported models.
 //
 // Returns a slice of ModelInfo sorted alphabetically by model name.
-// This includes chat models, embedding models, and the auto router.
+// This includes chat models, embedding models, and the auto router, merged
+// with the live catalog if RefreshModels has been called.
 //
 // Thread Safety: Safe for concurrent use.
 func (p *Provider) ListModels() []*types.ModelInfo {
-	models := make([]*types.ModelInfo, 0, len(modelRegistry))
-	for _, info := range modelRegistry {
+	p.modelsMu.RLock()
+	models := make([]*types.ModelInfo, 0, len(modelRegistry)+len(p.liveModels))
+	for _, info := range p.liveModels {
 		models = append(models, info)
 	}
+	for name, info := range modelRegistry {
+		if _, live := p.liveModels[name]; !live {
+			models = append(models, info)
+		}
+	}
+	p.modelsMu.RUnlock()
 
 	// Sort by name for consistent output
 	sort.Slice(models, func(i, j int) bool {
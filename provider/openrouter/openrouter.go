@@ -32,10 +32,13 @@ package openrouter
 
 import (
 	"net/http"
+	"sync"
 	"time"
 
 	"github.com/blue-context/warp"
 	"github.com/blue-context/warp/provider"
+	"github.com/blue-context/warp/provider/providercore"
+	"github.com/blue-context/warp/types"
 )
 
 // Provider implements the provider.Provider interface for OpenRouter.
@@ -53,6 +56,15 @@ type Provider struct {
 	// Optional custom headers for rankings and analytics
 	httpReferer string // HTTP-Referer header for site identification
 	appTitle    string // X-Title header for app name
+
+	// Default routing applied to every completion request
+	preferences *ProviderPreferences // "provider" routing object
+	transforms  []string             // "transforms" list
+
+	// Live model catalog populated by RefreshModels
+	liveModels     map[string]*types.ModelInfo
+	onModelsChange func(changes []ModelChange)
+	modelsMu       sync.RWMutex
 }
 
 // Compile-time interface check
@@ -182,6 +194,27 @@ func WithAppTitle(title string) Option {
 	}
 }
 
+// core returns the HTTP client for the OpenRouter API.
+func (p *Provider) core() *providercore.Client {
+	return &providercore.Client{
+		Provider:   "openrouter",
+		BaseURL:    p.apiBase,
+		HTTPClient: p.httpClient,
+		Prepare: func(req *http.Request) error {
+			req.Header.Set("Authorization", "Bearer "+p.apiKey)
+
+			// Optional custom headers for rankings and analytics
+			if p.httpReferer != "" {
+				req.Header.Set("HTTP-Referer", p.httpReferer)
+			}
+			if p.appTitle != "" {
+				req.Header.Set("X-Title", p.appTitle)
+			}
+			return nil
+		},
+	}
+}
+
 // Name returns the provider name "openrouter".
 //
 // This is used for provider identification in the registry and error messages.
@@ -9,6 +9,7 @@ import (
 
 	"github.com/blue-context/warp"
 	prov "github.com/blue-context/warp/provider"
+	"github.com/blue-context/warp/provider/providercore"
 )
 
 // mockHTTPClient is a mock HTTP client for testing
@@ -732,7 +733,7 @@ func TestTransformRequest(t *testing.T) {
 				if result["model"] != "openai/gpt-4o" {
 					t.Errorf("model = %v, want openai/gpt-4o", result["model"])
 				}
-				messages, ok := result["messages"].([]map[string]any)
+				messages, ok := result["messages"].([]providercore.Message)
 				if !ok || len(messages) != 1 {
 					t.Error("invalid messages format")
 				}
@@ -889,7 +890,7 @@ func TestTransformMessages(t *testing.T) {
 	tests := []struct {
 		name     string
 		messages []warp.Message
-		validate func(*testing.T, []map[string]any)
+		validate func(*testing.T, []providercore.Message)
 	}{
 		{
 			name: "simple text messages",
@@ -897,15 +898,15 @@ func TestTransformMessages(t *testing.T) {
 				{Role: "user", Content: "Hello"},
 				{Role: "assistant", Content: "Hi there"},
 			},
-			validate: func(t *testing.T, result []map[string]any) {
+			validate: func(t *testing.T, result []providercore.Message) {
 				if len(result) != 2 {
 					t.Errorf("len(result) = %v, want 2", len(result))
 				}
-				if result[0]["role"] != "user" {
-					t.Errorf("role = %v, want user", result[0]["role"])
+				if result[0].Role != "user" {
+					t.Errorf("role = %v, want user", result[0].Role)
 				}
-				if result[0]["content"] != "Hello" {
-					t.Errorf("content = %v, want Hello", result[0]["content"])
+				if result[0].Content != "Hello" {
+					t.Errorf("content = %v, want Hello", result[0].Content)
 				}
 			},
 		},
@@ -928,23 +929,23 @@ func TestTransformMessages(t *testing.T) {
 					},
 				},
 			},
-			validate: func(t *testing.T, result []map[string]any) {
+			validate: func(t *testing.T, result []providercore.Message) {
 				if len(result) != 1 {
 					t.Errorf("len(result) = %v, want 1", len(result))
 				}
-				parts, ok := result[0]["content"].([]map[string]any)
+				parts, ok := result[0].Content.([]warp.ContentPart)
 				if !ok {
-					t.Error("content is not []map[string]any")
+					t.Error("content is not []warp.ContentPart")
 					return
 				}
 				if len(parts) != 2 {
 					t.Errorf("len(parts) = %v, want 2", len(parts))
 				}
-				if parts[0]["type"] != "text" {
-					t.Errorf("type = %v, want text", parts[0]["type"])
+				if parts[0].Type != "text" {
+					t.Errorf("type = %v, want text", parts[0].Type)
 				}
-				if parts[1]["type"] != "image_url" {
-					t.Errorf("type = %v, want image_url", parts[1]["type"])
+				if parts[1].Type != "image_url" {
+					t.Errorf("type = %v, want image_url", parts[1].Type)
 				}
 			},
 		},
@@ -966,17 +967,13 @@ func TestTransformMessages(t *testing.T) {
 					},
 				},
 			},
-			validate: func(t *testing.T, result []map[string]any) {
+			validate: func(t *testing.T, result []providercore.Message) {
 				if len(result) != 1 {
 					t.Errorf("len(result) = %v, want 1", len(result))
 				}
-				toolCalls, ok := result[0]["tool_calls"]
-				if !ok {
+				if len(result[0].ToolCalls) == 0 {
 					t.Error("tool_calls missing")
 				}
-				if toolCalls == nil {
-					t.Error("tool_calls is nil")
-				}
 			},
 		},
 		{
@@ -989,15 +986,15 @@ func TestTransformMessages(t *testing.T) {
 					ToolCallID: "call_123",
 				},
 			},
-			validate: func(t *testing.T, result []map[string]any) {
+			validate: func(t *testing.T, result []providercore.Message) {
 				if len(result) != 1 {
 					t.Errorf("len(result) = %v, want 1", len(result))
 				}
-				if result[0]["name"] != "get_weather" {
-					t.Errorf("name = %v, want get_weather", result[0]["name"])
+				if result[0].Name != "get_weather" {
+					t.Errorf("name = %v, want get_weather", result[0].Name)
 				}
-				if result[0]["tool_call_id"] != "call_123" {
-					t.Errorf("tool_call_id = %v, want call_123", result[0]["tool_call_id"])
+				if result[0].ToolCallID != "call_123" {
+					t.Errorf("tool_call_id = %v, want call_123", result[0].ToolCallID)
 				}
 			},
 		},
@@ -1005,7 +1002,7 @@ func TestTransformMessages(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			result := transformMessages(tt.messages)
+			result := providercore.Messages(tt.messages)
 			if tt.validate != nil {
 				tt.validate(t, result)
 			}
@@ -0,0 +1,211 @@
+package openrouter
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/blue-context/warp"
+)
+
+// ProviderPreferences controls how OpenRouter routes a request across the
+// upstream providers serving a model.
+//
+// It is sent as the "provider" object of the request body. Zero-valued fields
+// are omitted so OpenRouter's defaults apply.
+//
+// See https://openrouter.ai/docs/features/provider-routing
+//
+// Example:
+//
+//	prefs := openrouter.ProviderPreferences{
+//	    Order:          []string{"Anthropic", "Amazon Bedrock"},
+//	    AllowFallbacks: warp.BoolPtr(false),
+//	    DataCollection: openrouter.DataCollectionDeny,
+//	}
+type ProviderPreferences struct {
+	// Order lists upstream providers to try, in order (e.g., "OpenAI", "Together").
+	Order []string `json:"order,omitempty"`
+
+	// AllowFallbacks permits routing to providers outside Order when they fail.
+	// Defaults to true on OpenRouter when unset.
+	AllowFallbacks *bool `json:"allow_fallbacks,omitempty"`
+
+	// RequireParameters restricts routing to providers that support every
+	// parameter in the request.
+	RequireParameters *bool `json:"require_parameters,omitempty"`
+
+	// DataCollection is DataCollectionAllow or DataCollectionDeny.
+	// Deny excludes providers that may store or train on prompts.
+	DataCollection string `json:"data_collection,omitempty"`
+
+	// Only restricts routing to these upstream providers.
+	Only []string `json:"only,omitempty"`
+
+	// Ignore excludes these upstream providers.
+	Ignore []string `json:"ignore,omitempty"`
+
+	// Quantizations restricts routing to providers serving these quantization
+	// levels (e.g., "fp8", "bf16").
+	Quantizations []string `json:"quantizations,omitempty"`
+
+	// Sort orders candidate providers by "price", "throughput", or "latency".
+	Sort string `json:"sort,omitempty"`
+}
+
+// Data collection policies for ProviderPreferences.DataCollection.
+const (
+	DataCollectionAllow = "allow"
+	DataCollectionDeny  = "deny"
+)
+
+// TransformMiddleOut compresses prompts that exceed the model's context by
+// removing messages from the middle of the conversation.
+const TransformMiddleOut = "middle-out"
+
+// WithProviderPreferences sets default routing preferences for all requests.
+//
+// A request can override the defaults by setting "provider" in
+// CompletionRequest.ExtraBody (see WithRouting).
+//
+// Example:
+//
+//	provider, err := openrouter.NewProvider(
+//	    openrouter.WithAPIKey("sk-or-v1-..."),
+//	    openrouter.WithProviderPreferences(openrouter.ProviderPreferences{
+//	        DataCollection: openrouter.DataCollectionDeny,
+//	    }),
+//	)
+func WithProviderPreferences(prefs ProviderPreferences) Option {
+	return func(p *Provider) {
+		p.preferences = &prefs
+	}
+}
+
+// WithTransforms sets default prompt transforms for all requests.
+//
+// Example:
+//
+//	provider, err := openrouter.NewProvider(
+//	    openrouter.WithAPIKey("sk-or-v1-..."),
+//	    openrouter.WithTransforms(openrouter.TransformMiddleOut),
+//	)
+func WithTransforms(transforms ...string) Option {
+	return func(p *Provider) {
+		p.transforms = transforms
+	}
+}
+
+// WithRouting sets per-request routing preferences and transforms.
+//
+// It stores them in req.ExtraBody, overriding any provider-level defaults.
+// Pass a nil prefs or no transforms to leave that field unchanged.
+//
+// Example:
+//
+//	req := &warp.CompletionRequest{Model: "openrouter/meta-llama/llama-3-70b-instruct", ...}
+//	openrouter.WithRouting(req, &openrouter.ProviderPreferences{
+//	    Order: []string{"Groq"},
+//	})
+func WithRouting(req *warp.CompletionRequest, prefs *ProviderPreferences, transforms ...string) {
+	if req.ExtraBody == nil {
+		req.ExtraBody = make(map[string]any)
+	}
+	if prefs != nil {
+		req.ExtraBody["provider"] = prefs
+	}
+	if len(transforms) > 0 {
+		req.ExtraBody["transforms"] = transforms
+	}
+}
+
+// UpstreamProvider returns the upstream provider that served a completion
+// (e.g., "Together"), or "" if it was not reported. Streamed chunks carry
+// it in ProviderFields["provider"], so it is also set on responses
+// assembled by warp.CollectStream or warp.StreamAccumulator.
+//
+// Example:
+//
+//	resp, _ := client.Completion(ctx, req)
+//	log.Printf("served by %s", openrouter.UpstreamProvider(resp))
+func UpstreamProvider(resp *warp.CompletionResponse) string {
+	if resp == nil {
+		return ""
+	}
+	name, _ := resp.ProviderFields["provider"].(string)
+	return name
+}
+
+// DisableDataRetention routes the request only to upstream providers that
+// do not store or train on prompts, by setting the data_collection
+// preference to "deny".
+//
+// It implements warp.DataRetentionController for WithZeroDataRetention.
+// Per-request preferences in req.ExtraBody["provider"] are kept, otherwise
+// the provider-level defaults are used.
+func (p *Provider) DisableDataRetention(req *warp.CompletionRequest) error {
+	var prefs ProviderPreferences
+	switch v := req.ExtraBody["provider"].(type) {
+	case nil:
+		if p.preferences != nil {
+			prefs = *p.preferences
+		}
+	case ProviderPreferences:
+		prefs = v
+	case *ProviderPreferences:
+		if v != nil {
+			prefs = *v
+		}
+	case map[string]any:
+		m := make(map[string]any, len(v)+1)
+		for k, val := range v {
+			m[k] = val
+		}
+		m["data_collection"] = DataCollectionDeny
+		req.ExtraBody["provider"] = m
+		return nil
+	default:
+		return fmt.Errorf("unsupported provider preferences type %T", v)
+	}
+
+	prefs.DataCollection = DataCollectionDeny
+	req.ExtraBody["provider"] = &prefs
+	return nil
+}
+
+// applyRouting adds provider-level routing defaults and request ExtraBody
+// fields to an OpenRouter request body.
+func (p *Provider) applyRouting(body map[string]any, req *warp.CompletionRequest) {
+	if p.preferences != nil {
+		body["provider"] = p.preferences
+	}
+	if len(p.transforms) > 0 {
+		body["transforms"] = p.transforms
+	}
+	for k, v := range req.ExtraBody {
+		body[k] = v
+	}
+}
+
+// recordUpstreamProvider copies the "provider" field of an OpenRouter
+// response into resp.ProviderFields.
+func recordUpstreamProvider(resp *warp.CompletionResponse, raw []byte) {
+	var meta struct {
+		Provider string `json:"provider"`
+	}
+	if err := json.Unmarshal(raw, &meta); err != nil {
+		return
+	}
+	setUpstreamProvider(&resp.ProviderFields, meta.Provider)
+}
+
+// setUpstreamProvider stores a non-empty upstream provider name in fields
+// under "provider".
+func setUpstreamProvider(fields *map[string]any, name string) {
+	if name == "" {
+		return
+	}
+	if *fields == nil {
+		*fields = make(map[string]any)
+	}
+	(*fields)["provider"] = name
+}
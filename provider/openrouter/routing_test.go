@@ -0,0 +1,268 @@
+package openrouter
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/blue-context/warp"
+)
+
+// routingMockClient captures the request body and returns a response
+// reporting the upstream provider.
+func routingMockClient(captured *map[string]any) *mockHTTPClient {
+	return &mockHTTPClient{
+		doFunc: func(req *http.Request) (*http.Response, error) {
+			body, _ := io.ReadAll(req.Body)
+			_ = json.Unmarshal(body, captured)
+			mockResp := `{
+				"id": "gen-123",
+				"object": "chat.completion",
+				"created": 1234567890,
+				"model": "meta-llama/llama-3-70b-instruct",
+				"provider": "Together",
+				"choices": [{"index": 0, "message": {"role": "assistant", "content": "Hi"}, "finish_reason": "stop"}]
+			}`
+			return &http.Response{
+				StatusCode: http.StatusOK,
+				Body:       io.NopCloser(strings.NewReader(mockResp)),
+				Header:     make(http.Header),
+			}, nil
+		},
+	}
+}
+
+// TestRoutingPreferences tests provider routing defaults and per-request overrides
+func TestRoutingPreferences(t *testing.T) {
+	tests := []struct {
+		name           string
+		opts           []Option
+		prefs          *ProviderPreferences
+		transforms     []string
+		wantProvider   map[string]any
+		wantTransforms []any
+	}{
+		{
+			name:         "no routing",
+			wantProvider: nil,
+		},
+		{
+			name: "provider defaults",
+			opts: []Option{
+				WithProviderPreferences(ProviderPreferences{
+					Order:          []string{"Groq"},
+					AllowFallbacks: warp.BoolPtr(false),
+					DataCollection: DataCollectionDeny,
+				}),
+				WithTransforms(TransformMiddleOut),
+			},
+			wantProvider: map[string]any{
+				"order":           []any{"Groq"},
+				"allow_fallbacks": false,
+				"data_collection": "deny",
+			},
+			wantTransforms: []any{"middle-out"},
+		},
+		{
+			name: "request overrides defaults",
+			opts: []Option{
+				WithProviderPreferences(ProviderPreferences{Order: []string{"Groq"}}),
+				WithTransforms(TransformMiddleOut),
+			},
+			prefs:          &ProviderPreferences{RequireParameters: warp.BoolPtr(true)},
+			transforms:     []string{},
+			wantProvider:   map[string]any{"require_parameters": true},
+			wantTransforms: []any{"middle-out"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var captured map[string]any
+			opts := append([]Option{WithAPIKey("sk-or-v1-test"), WithHTTPClient(routingMockClient(&captured))}, tt.opts...)
+			provider, err := NewProvider(opts...)
+			if err != nil {
+				t.Fatalf("NewProvider() error = %v", err)
+			}
+
+			req := &warp.CompletionRequest{
+				Model:    "meta-llama/llama-3-70b-instruct",
+				Messages: []warp.Message{{Role: "user", Content: "Hi"}},
+			}
+			if tt.prefs != nil {
+				WithRouting(req, tt.prefs, tt.transforms...)
+			}
+
+			resp, err := provider.Completion(context.Background(), req)
+			if err != nil {
+				t.Fatalf("Completion() error = %v", err)
+			}
+
+			gotProvider, _ := captured["provider"].(map[string]any)
+			if !jsonEqual(gotProvider, tt.wantProvider) {
+				t.Errorf("provider = %v, want %v", gotProvider, tt.wantProvider)
+			}
+			gotTransforms, _ := captured["transforms"].([]any)
+			if !jsonEqual(gotTransforms, tt.wantTransforms) {
+				t.Errorf("transforms = %v, want %v", gotTransforms, tt.wantTransforms)
+			}
+
+			if got := UpstreamProvider(resp); got != "Together" {
+				t.Errorf("UpstreamProvider() = %q, want %q", got, "Together")
+			}
+		})
+	}
+}
+
+// TestUpstreamProviderStream tests that streamed chunks report the
+// upstream provider
+func TestUpstreamProviderStream(t *testing.T) {
+	mockResp := `data: {"id":"gen-123","object":"chat.completion.chunk","created":1234567890,"model":"openai/gpt-4o","provider":"Azure","choices":[{"index":0,"delta":{"role":"assistant","content":"Hi"},"finish_reason":null}]}
+
+data: {"id":"gen-123","object":"chat.completion.chunk","created":1234567890,"model":"openai/gpt-4o","provider":"Azure","choices":[{"index":0,"delta":{},"finish_reason":"stop"}]}
+
+data: [DONE]
+`
+	mockClient := &mockHTTPClient{
+		doFunc: func(req *http.Request) (*http.Response, error) {
+			return &http.Response{
+				StatusCode: http.StatusOK,
+				Body:       io.NopCloser(strings.NewReader(mockResp)),
+				Header:     make(http.Header),
+			}, nil
+		},
+	}
+	provider, err := NewProvider(WithAPIKey("sk-or-v1-test"), WithHTTPClient(mockClient))
+	if err != nil {
+		t.Fatalf("NewProvider() error = %v", err)
+	}
+
+	stream, err := provider.CompletionStream(context.Background(), &warp.CompletionRequest{
+		Model:    "openai/gpt-4o",
+		Messages: []warp.Message{{Role: "user", Content: "Hi"}},
+	})
+	if err != nil {
+		t.Fatalf("CompletionStream() error = %v", err)
+	}
+
+	defer stream.Close()
+
+	acc := warp.NewStreamAccumulator()
+	for {
+		chunk, err := stream.Recv()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatalf("Recv() error = %v", err)
+		}
+		if got := chunk.ProviderFields["provider"]; got != "Azure" {
+			t.Errorf("chunk provider = %v, want Azure", got)
+		}
+		acc.Add(chunk)
+	}
+
+	resp := acc.Response()
+	if resp.Choices[0].Message.Content != "Hi" {
+		t.Errorf("content = %v, want %q", resp.Choices[0].Message.Content, "Hi")
+	}
+	if got := UpstreamProvider(resp); got != "Azure" {
+		t.Errorf("UpstreamProvider() = %q, want %q", got, "Azure")
+	}
+}
+
+// TestExtraBody tests that arbitrary ExtraBody fields are sent
+func TestExtraBody(t *testing.T) {
+	var captured map[string]any
+	provider, err := NewProvider(WithAPIKey("sk-or-v1-test"), WithHTTPClient(routingMockClient(&captured)))
+	if err != nil {
+		t.Fatalf("NewProvider() error = %v", err)
+	}
+
+	_, err = provider.Completion(context.Background(), &warp.CompletionRequest{
+		Model:     "openai/gpt-4o",
+		Messages:  []warp.Message{{Role: "user", Content: "Hi"}},
+		ExtraBody: map[string]any{"models": []string{"openai/gpt-4o", "anthropic/claude-3.5-sonnet"}},
+	})
+	if err != nil {
+		t.Fatalf("Completion() error = %v", err)
+	}
+
+	if models, _ := captured["models"].([]any); len(models) != 2 {
+		t.Errorf("models = %v, want 2 entries", captured["models"])
+	}
+}
+
+// jsonEqual compares two values by their JSON encoding.
+func jsonEqual(a, b any) bool {
+	aj, _ := json.Marshal(a)
+	bj, _ := json.Marshal(b)
+	return string(aj) == string(bj)
+}
+
+// TestDisableDataRetention tests that data collection is denied while
+// other routing preferences are kept
+func TestDisableDataRetention(t *testing.T) {
+	tests := []struct {
+		name      string
+		opts      []Option
+		extra     map[string]any
+		wantOrder bool
+	}{
+		{
+			name: "no preferences",
+		},
+		{
+			name:      "provider defaults",
+			opts:      []Option{WithProviderPreferences(ProviderPreferences{Order: []string{"Groq"}})},
+			wantOrder: true,
+		},
+		{
+			name:      "request preferences",
+			extra:     map[string]any{"provider": &ProviderPreferences{Order: []string{"Groq"}, DataCollection: DataCollectionAllow}},
+			wantOrder: true,
+		},
+		{
+			name:      "request preferences map",
+			extra:     map[string]any{"provider": map[string]any{"order": []string{"Groq"}}},
+			wantOrder: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var captured map[string]any
+			opts := append([]Option{WithAPIKey("sk-or-v1-test"), WithHTTPClient(routingMockClient(&captured))}, tt.opts...)
+			provider, err := NewProvider(opts...)
+			if err != nil {
+				t.Fatalf("NewProvider() error = %v", err)
+			}
+
+			req := &warp.CompletionRequest{
+				Model:     "openai/gpt-4o",
+				Messages:  []warp.Message{{Role: "user", Content: "Hi"}},
+				ExtraBody: map[string]any{},
+			}
+			for k, v := range tt.extra {
+				req.ExtraBody[k] = v
+			}
+			if err := provider.DisableDataRetention(req); err != nil {
+				t.Fatalf("DisableDataRetention() error = %v", err)
+			}
+			if _, err := provider.Completion(context.Background(), req); err != nil {
+				t.Fatalf("Completion() error = %v", err)
+			}
+
+			prefs, _ := captured["provider"].(map[string]any)
+			if prefs["data_collection"] != DataCollectionDeny {
+				t.Errorf("provider = %v, want data_collection deny", captured["provider"])
+			}
+			if _, ok := prefs["order"]; ok != tt.wantOrder {
+				t.Errorf("provider = %v, order present = %v, want %v", prefs, ok, tt.wantOrder)
+			}
+		})
+	}
+}
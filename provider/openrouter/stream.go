@@ -1,15 +1,12 @@
 package openrouter
 
 import (
-	"bufio"
-	"bytes"
 	"context"
-	"encoding/json"
 	"fmt"
 	"io"
-	"net/http"
 
 	"github.com/blue-context/warp"
+	"github.com/blue-context/warp/sse"
 )
 
 // CompletionStream sends a streaming chat completion request to OpenRouter.
@@ -51,46 +48,15 @@ func (p *Provider) CompletionStream(ctx context.Context, req *warp.CompletionReq
 
 	// Transform request to OpenRouter format (OpenAI-compatible)
 	openrouterReq := transformRequest(req)
+	p.applyRouting(openrouterReq, req)
 
 	// Enable streaming for this request
 	openrouterReq["stream"] = true
 
-	// Marshal to JSON
-	body, err := json.Marshal(openrouterReq)
-	if err != nil {
-		return nil, fmt.Errorf("failed to marshal stream request for model %s: %w", req.Model, err)
-	}
-
-	// Create HTTP request
-	httpReq, err := http.NewRequestWithContext(ctx, "POST", p.apiBase+"/chat/completions", bytes.NewReader(body))
-	if err != nil {
-		return nil, fmt.Errorf("failed to create stream request for model %s: %w", req.Model, err)
-	}
-
-	// Set standard headers
-	httpReq.Header.Set("Content-Type", "application/json")
-	httpReq.Header.Set("Authorization", "Bearer "+p.apiKey)
-	httpReq.Header.Set("Accept", "text/event-stream")
-
-	// Set optional custom headers for rankings and analytics
-	if p.httpReferer != "" {
-		httpReq.Header.Set("HTTP-Referer", p.httpReferer)
-	}
-	if p.appTitle != "" {
-		httpReq.Header.Set("X-Title", p.appTitle)
-	}
-
 	// Send request
-	httpResp, err := p.httpClient.Do(httpReq)
+	httpResp, err := p.core().PostStream(ctx, "/chat/completions", openrouterReq)
 	if err != nil {
-		return nil, fmt.Errorf("failed to send stream request to model %s: %w", req.Model, err)
-	}
-
-	// Check status code
-	if httpResp.StatusCode != http.StatusOK {
-		defer httpResp.Body.Close()
-		body, _ := io.ReadAll(httpResp.Body)
-		return nil, warp.ParseProviderError("openrouter", httpResp.StatusCode, body, nil)
+		return nil, err
 	}
 
 	// Create SSE stream (OpenAI-compatible)
@@ -106,7 +72,7 @@ func (p *Provider) CompletionStream(ctx context.Context, req *warp.CompletionReq
 // Thread Safety: sseStream is NOT safe for concurrent use.
 // Only one goroutine should call Recv() at a time.
 type sseStream struct {
-	reader *bufio.Reader
+	reader *sse.Reader
 	closer io.Closer
 	ctx    context.Context
 	err    error // Cached error for subsequent Recv calls
@@ -118,7 +84,7 @@ type sseStream struct {
 // and return them as CompletionChunk objects.
 func newSSEStream(ctx context.Context, body io.ReadCloser) warp.Stream {
 	return &sseStream{
-		reader: bufio.NewReader(body),
+		reader: sse.NewReader(body, sse.WithBufferReuse()),
 		closer: body,
 		ctx:    ctx,
 	}
@@ -145,14 +111,14 @@ func (s *sseStream) Recv() (*warp.CompletionChunk, error) {
 		default:
 		}
 
-		// Read line (blocking operation)
-		line, err := s.reader.ReadBytes('\n')
+		// Read the next event
+		event, err := s.reader.Next()
 		if err != nil {
 			if err == io.EOF {
 				s.err = io.EOF
 				return nil, io.EOF
 			}
-			s.err = fmt.Errorf("failed to read line: %w", err)
+			s.err = fmt.Errorf("failed to read event: %w", err)
 			return nil, s.err
 		}
 
@@ -164,35 +130,31 @@ func (s *sseStream) Recv() (*warp.CompletionChunk, error) {
 		default:
 		}
 
-		// Trim whitespace
-		line = bytes.TrimSpace(line)
-
-		// Skip empty lines
-		if len(line) == 0 {
+		// Skip keep-alive events without data
+		data := event.Data
+		if len(data) == 0 {
 			continue
 		}
 
-		// Parse SSE field - must have "data: " prefix
-		if !bytes.HasPrefix(line, []byte("data: ")) {
-			continue
-		}
-
-		// Extract data after "data: " prefix
-		data := bytes.TrimPrefix(line, []byte("data: "))
-
 		// Check for [DONE] marker (OpenAI-compatible)
-		if bytes.Equal(data, []byte("[DONE]")) {
+		if event.Done() {
 			s.err = io.EOF
 			return nil, io.EOF
 		}
 
-		// Parse JSON chunk
-		var chunk warp.CompletionChunk
-		if err := json.Unmarshal(data, &chunk); err != nil {
+		// Parse JSON chunk, along with the upstream provider that
+		// OpenRouter adds to each one
+		var raw struct {
+			warp.CompletionChunk
+			Provider string `json:"provider"`
+		}
+		if err := s.reader.Decode(&raw); err != nil {
 			s.err = fmt.Errorf("failed to parse chunk: %w", err)
 			return nil, s.err
 		}
 
+		chunk := raw.CompletionChunk
+		setUpstreamProvider(&chunk.ProviderFields, raw.Provider)
 		return &chunk, nil
 	}
 }
@@ -205,5 +167,7 @@ func (s *sseStream) Close() error {
 	if s.closer == nil {
 		return nil
 	}
-	return s.closer.Close()
+	err := s.closer.Close()
+	s.reader.Release()
+	return err
 }
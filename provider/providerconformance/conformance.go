@@ -0,0 +1,308 @@
+//go:build !tinygo && !warp_minimal
+
+package providerconformance
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/blue-context/warp"
+	"github.com/blue-context/warp/provider"
+)
+
+// maxChunks bounds the chunks read from a stream, so a stream that never
+// ends fails the test instead of hanging it.
+const maxChunks = 10000
+
+// Factory creates the provider under test, configured to send its requests
+// to the server at baseURL. The server answers every path, so the factory
+// may append the API's path prefix.
+type Factory func(baseURL string) (provider.Provider, error)
+
+// Run tests a provider against fixtures, each as a subtest named after the
+// fixture, plus the checks every provider must pass whatever its wire
+// format. For each fixture it serves the fixture's response from a test
+// server, sends the fixture's request, and checks the result:
+//
+//   - Completions return an assistant message with the expected content,
+//     tool calls, finish reason, and usage.
+//   - Streams deliver non-nil chunks that assemble to the expected result,
+//     end with io.EOF, keep returning io.EOF after it, and can be closed
+//     more than once.
+//   - Errors are of the expected kind, whether a stream returns them when
+//     it is opened or from Recv.
+//
+// The provider-wide checks verify that it has a name and that canceling
+// the context ends a request that the server never answers.
+//
+// Example:
+//
+//	func TestConformance(t *testing.T) {
+//	    providerconformance.Run(t, func(baseURL string) (provider.Provider, error) {
+//	        return myprovider.New(myprovider.WithAPIKey("test"), myprovider.WithBaseURL(baseURL+"/v1"))
+//	    }, providerconformance.OpenAIFixtures())
+//	}
+func Run(t *testing.T, factory Factory, fixtures []Fixture) {
+	t.Helper()
+
+	t.Run("Name", func(t *testing.T) {
+		p, err := factory("http://127.0.0.1:0")
+		if err != nil {
+			t.Fatalf("factory error = %v", err)
+		}
+		if p.Name() == "" {
+			t.Error("Name() returned empty string")
+		}
+	})
+
+	t.Run("Cancellation", func(t *testing.T) {
+		testCancellation(t, factory)
+	})
+
+	for _, fixture := range fixtures {
+		fixture := fixture
+		t.Run(fixture.Name, func(t *testing.T) {
+			RunFixture(t, factory, fixture)
+		})
+	}
+}
+
+// RunFixture tests a provider against one fixture, as Run does.
+func RunFixture(t *testing.T, factory Factory, fixture Fixture) {
+	t.Helper()
+
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		io.Copy(io.Discard, r.Body)
+		for key, value := range fixture.Headers {
+			w.Header().Set(key, value)
+		}
+		if w.Header().Get("Content-Type") == "" {
+			w.Header().Set("Content-Type", "application/json")
+		}
+		status := fixture.Status
+		if status == 0 {
+			status = http.StatusOK
+		}
+		w.WriteHeader(status)
+		io.WriteString(w, fixture.Body)
+	}))
+	defer server.Close()
+
+	p, err := factory(server.URL)
+	if err != nil {
+		t.Fatalf("factory error = %v", err)
+	}
+
+	req := fixture.Request
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	var resp *warp.CompletionResponse
+	if fixture.Stream {
+		resp, err = readStream(t, p, ctx, &req)
+	} else {
+		resp, err = p.Completion(ctx, &req)
+	}
+	if requests.Load() == 0 {
+		t.Errorf("provider sent no request to the server (error = %v)", err)
+	}
+
+	if kind := fixture.Want.Error; kind != "" {
+		is := ErrorKinds[kind]
+		switch {
+		case is == nil:
+			t.Fatalf("unknown error kind %q", kind)
+		case err == nil:
+			t.Fatalf("got a response, want a %s error", kind)
+		case !is(err):
+			t.Fatalf("error = %v (%T), want a %s error", err, err, kind)
+		}
+		return
+	}
+	if err != nil {
+		t.Fatalf("error = %v", err)
+	}
+	checkResponse(t, resp, fixture.Want)
+}
+
+// readStream opens a stream, reads it to the end while checking the
+// stream contract, and returns the assembled response.
+func readStream(t *testing.T, p provider.Provider, ctx context.Context, req *warp.CompletionRequest) (*warp.CompletionResponse, error) {
+	t.Helper()
+
+	stream, err := p.CompletionStream(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	if stream == nil {
+		t.Fatal("CompletionStream() returned a nil stream and no error")
+	}
+
+	acc := warp.NewStreamAccumulator()
+	var recvErr error
+	for i := 0; ; i++ {
+		if i == maxChunks {
+			t.Fatalf("stream did not end after %d chunks", maxChunks)
+		}
+		chunk, err := stream.Recv()
+		if err != nil {
+			recvErr = err
+			break
+		}
+		if chunk == nil {
+			t.Fatal("Recv() returned a nil chunk and no error")
+		}
+		acc.Add(chunk)
+	}
+
+	if _, err := stream.Recv(); err != recvErr && !(errors.Is(err, recvErr) && errors.Is(recvErr, err)) {
+		t.Errorf("Recv() after the end = %v, want %v again", err, recvErr)
+	}
+	if err := stream.Close(); err != nil {
+		t.Errorf("Close() error = %v", err)
+	}
+	stream.Close()
+
+	if recvErr != io.EOF {
+		return nil, recvErr
+	}
+	return acc.Response(), nil
+}
+
+// checkResponse compares a response with the expectation.
+func checkResponse(t *testing.T, resp *warp.CompletionResponse, want Expectation) {
+	t.Helper()
+
+	if resp == nil {
+		t.Fatal("got a nil response and no error")
+	}
+	if len(resp.Choices) == 0 {
+		t.Fatal("response has no choices")
+	}
+	choice := resp.Choices[0]
+	msg := choice.Message
+
+	if msg.Role != "assistant" {
+		t.Errorf("Role = %q, want assistant", msg.Role)
+	}
+	if want.Content != "" {
+		if got, _ := msg.Content.(string); got != want.Content {
+			t.Errorf("Content = %#v, want %q", msg.Content, want.Content)
+		}
+	}
+	if want.FinishReason != "" && choice.FinishReason != want.FinishReason {
+		t.Errorf("FinishReason = %q, want %q", choice.FinishReason, want.FinishReason)
+	}
+	if want.ToolCalls != nil {
+		checkToolCalls(t, msg.ToolCalls, want.ToolCalls)
+	}
+	if want.Usage != nil {
+		got := resp.Usage
+		switch {
+		case got == nil:
+			t.Errorf("Usage = nil, want %+v", *want.Usage)
+		case got.PromptTokens != want.Usage.PromptTokens ||
+			got.CompletionTokens != want.Usage.CompletionTokens ||
+			got.TotalTokens != want.Usage.TotalTokens:
+			t.Errorf("Usage = %d prompt, %d completion, %d total tokens; want %d, %d, %d",
+				got.PromptTokens, got.CompletionTokens, got.TotalTokens,
+				want.Usage.PromptTokens, want.Usage.CompletionTokens, want.Usage.TotalTokens)
+		}
+	}
+}
+
+// checkToolCalls compares tool calls, with arguments compared as JSON.
+func checkToolCalls(t *testing.T, got, want []warp.ToolCall) {
+	t.Helper()
+
+	if len(got) != len(want) {
+		t.Fatalf("got %d tool calls, want %d: %+v", len(got), len(want), got)
+	}
+	for i := range want {
+		g, w := got[i], want[i]
+		if g.ID != w.ID || g.Function.Name != w.Function.Name {
+			t.Errorf("tool call %d = %s %s, want %s %s", i, g.ID, g.Function.Name, w.ID, w.Function.Name)
+		}
+		if w.Type != "" && g.Type != w.Type {
+			t.Errorf("tool call %d Type = %q, want %q", i, g.Type, w.Type)
+		}
+		if !jsonEqual(g.Function.Arguments, w.Function.Arguments) {
+			t.Errorf("tool call %d Arguments = %s, want %s", i, g.Function.Arguments, w.Function.Arguments)
+		}
+	}
+}
+
+// jsonEqual reports whether a and b are equal JSON values.
+func jsonEqual(a, b string) bool {
+	var va, vb any
+	if json.Unmarshal([]byte(a), &va) != nil || json.Unmarshal([]byte(b), &vb) != nil {
+		return a == b
+	}
+	return reflect.DeepEqual(va, vb)
+}
+
+// testCancellation checks that canceling the context ends a request the
+// server never answers, for both completions and streams.
+func testCancellation(t *testing.T, factory Factory) {
+	t.Helper()
+
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-r.Context().Done():
+		case <-release:
+		}
+	}))
+	defer server.Close()
+	defer close(release)
+
+	p, err := factory(server.URL)
+	if err != nil {
+		t.Fatalf("factory error = %v", err)
+	}
+	req := &warp.CompletionRequest{
+		Model:    "test-model",
+		Messages: []warp.Message{{Role: "user", Content: "Say hello."}},
+	}
+
+	calls := map[string]func(ctx context.Context) error{
+		"Completion": func(ctx context.Context) error {
+			_, err := p.Completion(ctx, req)
+			return err
+		},
+		"CompletionStream": func(ctx context.Context) error {
+			stream, err := p.CompletionStream(ctx, req)
+			if err != nil {
+				return err
+			}
+			defer stream.Close()
+			_, err = stream.Recv()
+			return err
+		},
+	}
+	for name, call := range calls {
+		ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+		done := make(chan error, 1)
+		go func() { done <- call(ctx) }()
+
+		select {
+		case err := <-done:
+			if err == nil {
+				t.Errorf("%s() succeeded without a response", name)
+			}
+		case <-time.After(10 * time.Second):
+			t.Errorf("%s() did not return after its context was canceled", name)
+		}
+		cancel()
+	}
+}
@@ -0,0 +1,72 @@
+package providerconformance
+
+import (
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/blue-context/warp/provider"
+	"github.com/blue-context/warp/provider/groq"
+	"github.com/blue-context/warp/provider/openai"
+	"github.com/blue-context/warp/provider/openaicompat"
+	"github.com/blue-context/warp/provider/together"
+	"github.com/blue-context/warp/provider/vllm"
+)
+
+// TestOpenAICompatibleProviders runs the OpenAI fixtures against the
+// built-in providers of OpenAI-compatible APIs
+func TestOpenAICompatibleProviders(t *testing.T) {
+	factories := map[string]Factory{
+		"openai": func(baseURL string) (provider.Provider, error) {
+			return openai.NewProvider(openai.WithAPIKey("test-key"), openai.WithAPIBase(baseURL+"/v1"))
+		},
+		"openaicompat": func(baseURL string) (provider.Provider, error) {
+			return openaicompat.New(baseURL)
+		},
+		"groq": func(baseURL string) (provider.Provider, error) {
+			return groq.NewProvider(groq.WithAPIKey("test-key"), groq.WithAPIBase(baseURL+"/openai/v1"))
+		},
+		"together": func(baseURL string) (provider.Provider, error) {
+			return together.NewProvider(together.WithAPIKey("test-key"), together.WithAPIBase(baseURL+"/v1"))
+		},
+		"vllm": func(baseURL string) (provider.Provider, error) {
+			return vllm.NewProvider(vllm.WithBaseURL(baseURL), vllm.WithChatCompletions(true))
+		},
+	}
+
+	fixtures := OpenAIFixtures()
+	if len(fixtures) == 0 {
+		t.Fatal("OpenAIFixtures() returned no fixtures")
+	}
+	for name, factory := range factories {
+		t.Run(name, func(t *testing.T) {
+			Run(t, factory, fixtures)
+		})
+	}
+}
+
+// TestLoadFixtures tests fixture loading and validation
+func TestLoadFixtures(t *testing.T) {
+	fsys := fstest.MapFS{
+		"conformance/b.json":     {Data: []byte(`{"body": "{}", "want": {"content": "hi"}}`)},
+		"conformance/a.json":     {Data: []byte(`{"status": 429, "body": "{}", "want": {"error": "rate_limit"}}`)},
+		"conformance/notes.txt":  {Data: []byte("not a fixture")},
+		"invalid/kind.json":      {Data: []byte(`{"want": {"error": "teapot"}}`)},
+		"invalid/malformed.json": {Data: []byte(`{`)},
+	}
+
+	fixtures, err := LoadFixtures(fsys, "conformance")
+	if err != nil {
+		t.Fatalf("LoadFixtures() error = %v", err)
+	}
+	if len(fixtures) != 2 || fixtures[0].Name != "a" || fixtures[1].Name != "b" {
+		t.Fatalf("LoadFixtures() = %+v, want fixtures a and b", fixtures)
+	}
+	if fixtures[0].Status != 429 || fixtures[1].Want.Content != "hi" {
+		t.Errorf("LoadFixtures() = %+v", fixtures)
+	}
+
+	if _, err := LoadFixtures(fsys, "invalid"); err == nil || !strings.Contains(err.Error(), "teapot") {
+		t.Errorf("LoadFixtures() error = %v, want unknown error kind", err)
+	}
+}
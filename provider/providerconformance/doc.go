@@ -0,0 +1,11 @@
+// Package providerconformance tests that a provider.Provider meets warp's
+// behavioral contract, so third-party providers can check that they work
+// with warp clients the way the built-in ones do.
+//
+// A test runs the provider against fixtures: recorded responses in the
+// provider's wire format, served from a local test server, with the result
+// the provider must produce from each. OpenAIFixtures covers the OpenAI
+// Chat Completions format used by most OpenAI-compatible servers; providers
+// of other APIs write fixtures of their own and read them with
+// LoadFixtures.
+package providerconformance
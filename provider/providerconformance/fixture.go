@@ -0,0 +1,146 @@
+package providerconformance
+
+import (
+	"embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/blue-context/warp"
+)
+
+//go:embed fixtures
+var fixtureFiles embed.FS
+
+// Fixture is a recorded exchange with a provider's API: the request sent
+// through the Provider interface, the HTTP response the server returns in
+// the provider's wire format, and the result the provider must produce
+// from it.
+//
+// Fixtures are stored as JSON files, one per fixture, named after the
+// fixture.
+type Fixture struct {
+	// Name identifies the fixture; LoadFixtures sets it from the file name.
+	Name string `json:"-"`
+
+	// Description says what the fixture covers.
+	Description string `json:"description,omitempty"`
+
+	// Request is the request passed to the provider.
+	Request warp.CompletionRequest `json:"request"`
+
+	// Stream sends the request with CompletionStream instead of
+	// Completion.
+	Stream bool `json:"stream,omitempty"`
+
+	// Status is the HTTP status code of the response (default 200).
+	Status int `json:"status,omitempty"`
+
+	// Headers are the response headers.
+	Headers map[string]string `json:"headers,omitempty"`
+
+	// Body is the response body, a server-sent event stream for
+	// successful streaming fixtures.
+	Body string `json:"body"`
+
+	// Want is the result the provider must produce.
+	Want Expectation `json:"want"`
+}
+
+// Expectation is the result of a fixture. Empty fields are not checked.
+type Expectation struct {
+	// Content is the text of the first choice.
+	Content string `json:"content,omitempty"`
+
+	// ToolCalls are the tool calls of the first choice. Arguments are
+	// compared as JSON, so formatting does not matter.
+	ToolCalls []warp.ToolCall `json:"tool_calls,omitempty"`
+
+	// FinishReason is the finish reason of the first choice.
+	FinishReason string `json:"finish_reason,omitempty"`
+
+	// Usage is the token usage.
+	Usage *warp.Usage `json:"usage,omitempty"`
+
+	// Error is the kind of error the provider must return instead of a
+	// response, one of the keys of ErrorKinds.
+	Error string `json:"error,omitempty"`
+}
+
+// ErrorKinds maps the error kinds of Expectation.Error to a check that an
+// error is of that kind.
+var ErrorKinds = map[string]func(error) bool{
+	"authentication":           isError[*warp.AuthenticationError],
+	"permission":               isError[*warp.PermissionError],
+	"rate_limit":               isError[*warp.RateLimitError],
+	"context_window_exceeded":  isError[*warp.ContextWindowExceededError],
+	"content_policy_violation": isError[*warp.ContentPolicyViolationError],
+	"content_filter":           isError[*warp.ContentFilterError],
+	"invalid_request":          isError[*warp.InvalidRequestError],
+	"bad_request":              isError[*warp.BadRequestError],
+	"timeout":                  isError[*warp.TimeoutError],
+	"service_unavailable":      isError[*warp.ServiceUnavailableError],
+	"api":                      isError[*warp.APIError],
+}
+
+// LoadFixtures reads the fixtures in the JSON files of dir in fsys, in
+// file name order.
+//
+// Example:
+//
+//	//go:embed testdata/conformance
+//	var fixtures embed.FS
+//
+//	func TestConformance(t *testing.T) {
+//	    all, err := providerconformance.LoadFixtures(fixtures, "testdata/conformance")
+//	    if err != nil {
+//	        t.Fatal(err)
+//	    }
+//	    providerconformance.Run(t, newTestProvider, all)
+//	}
+func LoadFixtures(fsys fs.FS, dir string) ([]Fixture, error) {
+	names, err := fs.Glob(fsys, path.Join(dir, "*.json"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list fixtures: %w", err)
+	}
+	sort.Strings(names)
+
+	fixtures := make([]Fixture, 0, len(names))
+	for _, name := range names {
+		data, err := fs.ReadFile(fsys, name)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read fixture %s: %w", name, err)
+		}
+		var fixture Fixture
+		if err := json.Unmarshal(data, &fixture); err != nil {
+			return nil, fmt.Errorf("failed to decode fixture %s: %w", name, err)
+		}
+		if kind := fixture.Want.Error; kind != "" && ErrorKinds[kind] == nil {
+			return nil, fmt.Errorf("fixture %s: unknown error kind %q", name, kind)
+		}
+		fixture.Name = strings.TrimSuffix(path.Base(name), ".json")
+		fixtures = append(fixtures, fixture)
+	}
+	return fixtures, nil
+}
+
+// OpenAIFixtures returns the golden fixtures of the OpenAI Chat
+// Completions wire format, for providers of OpenAI-compatible APIs. They
+// cover completions, streaming, tool calls, usage, and error mapping.
+func OpenAIFixtures() []Fixture {
+	fixtures, err := LoadFixtures(fixtureFiles, "fixtures/openai")
+	if err != nil {
+		panic(err)
+	}
+	return fixtures
+}
+
+// isError reports whether err is, or wraps, an error of type T.
+func isError[T error](err error) bool {
+	var target T
+	return errors.As(err, &target)
+}
@@ -0,0 +1,82 @@
+package providercore
+
+import (
+	"encoding/json"
+
+	"github.com/blue-context/warp"
+)
+
+// Completion decodes an OpenAI-format chat completion response. Besides
+// the fields of warp.CompletionResponse, it collects the citations of the
+// first choice from OpenAI url_citation annotations and Perplexity
+// search_results into Citations.
+//
+// Example:
+//
+//	var resp providercore.Completion
+//	if err := p.core().PostJSON(ctx, "/chat/completions", body, &resp); err != nil {
+//	    return nil, err
+//	}
+//	return &resp.CompletionResponse, nil
+type Completion struct {
+	warp.CompletionResponse
+}
+
+// completionCitations are the citation fields of an OpenAI-format
+// response.
+type completionCitations struct {
+	Choices []struct {
+		Message struct {
+			Annotations []struct {
+				Type        string `json:"type"`
+				URLCitation struct {
+					URL        string `json:"url"`
+					Title      string `json:"title"`
+					StartIndex int    `json:"start_index"`
+					EndIndex   int    `json:"end_index"`
+				} `json:"url_citation"`
+			} `json:"annotations"`
+		} `json:"message"`
+	} `json:"choices"`
+	SearchResults []struct {
+		Title string `json:"title"`
+		URL   string `json:"url"`
+	} `json:"search_results"`
+}
+
+// UnmarshalJSON decodes the response and its citations.
+func (c *Completion) UnmarshalJSON(data []byte) error {
+	if err := json.Unmarshal(data, &c.CompletionResponse); err != nil {
+		return err
+	}
+
+	var extra completionCitations
+	if err := json.Unmarshal(data, &extra); err != nil {
+		return err
+	}
+	// search_results supersede the bare URLs of Perplexity's citations
+	if len(extra.SearchResults) > 0 {
+		c.Citations = c.Citations[:0]
+		for _, r := range extra.SearchResults {
+			c.Citations = append(c.Citations, warp.Citation{URL: r.URL, Title: r.Title})
+		}
+	}
+	if len(extra.Choices) == 0 {
+		return nil
+	}
+
+	content, _ := c.Choices[0].Message.Content.(string)
+	runes := []rune(content)
+	for _, a := range extra.Choices[0].Message.Annotations {
+		if a.Type != "url_citation" {
+			continue
+		}
+		u := a.URLCitation
+		citation := warp.Citation{URL: u.URL, Title: u.Title, StartIndex: u.StartIndex, EndIndex: u.EndIndex}
+		if u.StartIndex >= 0 && u.StartIndex < u.EndIndex && u.EndIndex <= len(runes) {
+			citation.Text = string(runes[u.StartIndex:u.EndIndex])
+		}
+		c.Citations = append(c.Citations, citation)
+	}
+	return nil
+}
@@ -0,0 +1,180 @@
+package providercore
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/textproto"
+	"strings"
+)
+
+// Form builds a multipart/form-data request body. Errors are kept until
+// the body is built, so fields can be added without checking each call.
+//
+// File contents are not read until the body is built: Encode buffers the
+// whole body, while Reader streams it, so large uploads are sent without
+// holding them in memory.
+//
+// Example:
+//
+//	form := providercore.NewForm()
+//	form.AddFile("file", "audio.mp3", "", req.File)
+//	form.AddField("model", req.Model)
+//	if req.Language != "" {
+//	    form.AddField("language", req.Language)
+//	}
+//	err := p.core().PostForm(ctx, "/audio/transcriptions", form, &resp)
+type Form struct {
+	parts    []formPart
+	progress func(sent int64)
+	err      error
+}
+
+// formPart is a text field or a file of a Form.
+type formPart struct {
+	name        string
+	value       string
+	filename    string
+	contentType string
+	file        io.Reader
+}
+
+// NewForm creates an empty form.
+func NewForm() *Form {
+	return &Form{}
+}
+
+// AddField adds a text field.
+func (f *Form) AddField(name, value string) {
+	f.parts = append(f.parts, formPart{name: name, value: value})
+}
+
+// AddFile adds a file field with the content of r. An empty contentType
+// means application/octet-stream.
+func (f *Form) AddFile(name, filename, contentType string, r io.Reader) {
+	if f.err != nil {
+		return
+	}
+	if r == nil {
+		f.err = fmt.Errorf("file %s has no content", name)
+		return
+	}
+	if contentType == "" {
+		contentType = "application/octet-stream"
+	}
+	f.parts = append(f.parts, formPart{name: name, filename: filename, contentType: contentType, file: r})
+}
+
+// OnProgress sets a function called with the number of body bytes sent so
+// far as the body from Reader is read. A nil fn disables progress reports.
+func (f *Form) OnProgress(fn func(sent int64)) {
+	f.progress = fn
+}
+
+// Encode builds the form and returns its body and Content-Type, or the
+// first error from building it.
+func (f *Form) Encode() ([]byte, string, error) {
+	if f.err != nil {
+		return nil, "", f.err
+	}
+	var buf bytes.Buffer
+	writer := multipart.NewWriter(&buf)
+	if err := f.write(writer); err != nil {
+		return nil, "", err
+	}
+	return buf.Bytes(), writer.FormDataContentType(), nil
+}
+
+// Reader returns the form body as a stream and its Content-Type. Files are
+// copied into the stream as it is read, so the body is sent chunked
+// without being buffered. Errors from building the form are returned by
+// Read.
+//
+// The caller must close the reader, which stops the stream if it was not
+// read to the end and waits until the files are no longer read. The body
+// can be read only once.
+func (f *Form) Reader() (io.ReadCloser, string) {
+	pr, pw := io.Pipe()
+	var dst io.Writer = pw
+	if f.progress != nil {
+		dst = &progressWriter{w: pw, progress: f.progress}
+	}
+	writer := multipart.NewWriter(dst)
+
+	body := &formBody{PipeReader: pr, done: make(chan struct{})}
+	go func() {
+		defer close(body.done)
+		err := f.err
+		if err == nil {
+			err = f.write(writer)
+		}
+		pw.CloseWithError(err)
+	}()
+	return body, writer.FormDataContentType()
+}
+
+// formBody is a streamed form body. Close waits until the form stops
+// reading its files, so a retry can rewind them safely.
+type formBody struct {
+	*io.PipeReader
+	done chan struct{}
+}
+
+func (b *formBody) Close() error {
+	err := b.PipeReader.Close()
+	<-b.done
+	return err
+}
+
+// write writes every part of the form to writer and closes it.
+func (f *Form) write(writer *multipart.Writer) error {
+	for _, p := range f.parts {
+		if p.file == nil {
+			if err := writer.WriteField(p.name, p.value); err != nil {
+				return fmt.Errorf("failed to write field %s: %w", p.name, err)
+			}
+			continue
+		}
+
+		header := make(textproto.MIMEHeader)
+		header.Set("Content-Disposition", fmt.Sprintf(`form-data; name="%s"; filename="%s"`,
+			escapeQuotes(p.name), escapeQuotes(p.filename)))
+		header.Set("Content-Type", p.contentType)
+
+		part, err := writer.CreatePart(header)
+		if err != nil {
+			return fmt.Errorf("failed to create form file %s: %w", p.name, err)
+		}
+		if _, err := io.Copy(part, p.file); err != nil {
+			return fmt.Errorf("failed to copy file %s: %w", p.name, err)
+		}
+	}
+	if err := writer.Close(); err != nil {
+		return fmt.Errorf("failed to close multipart writer: %w", err)
+	}
+	return nil
+}
+
+// progressWriter reports the bytes written through it.
+type progressWriter struct {
+	w        io.Writer
+	sent     int64
+	progress func(sent int64)
+}
+
+func (w *progressWriter) Write(p []byte) (int, error) {
+	n, err := w.w.Write(p)
+	if n > 0 {
+		w.sent += int64(n)
+		w.progress(w.sent)
+	}
+	return n, err
+}
+
+// quoteEscaper escapes form-data parameter values like mime/multipart.
+var quoteEscaper = strings.NewReplacer("\\", "\\\\", `"`, "\\\"")
+
+func escapeQuotes(s string) string {
+	return quoteEscaper.Replace(s)
+}
@@ -0,0 +1,38 @@
+package providercore
+
+import "github.com/blue-context/warp"
+
+// Message is a chat message in the OpenAI format. It refers to the
+// content of the warp.Message it is built from instead of copying it, so
+// large prompts are not duplicated before they are encoded.
+type Message struct {
+	Role string `json:"role"`
+
+	// Content is a string or a []warp.ContentPart, or nil for messages
+	// without content, such as assistant tool calls.
+	Content any `json:"content,omitempty"`
+
+	Name       string          `json:"name,omitempty"`
+	ToolCalls  []warp.ToolCall `json:"tool_calls,omitempty"`
+	ToolCallID string          `json:"tool_call_id,omitempty"`
+}
+
+// Messages converts warp messages to the OpenAI format. Content of other
+// types than string and []warp.ContentPart is dropped, and Metadata is not
+// sent.
+func Messages(messages []warp.Message) []Message {
+	out := make([]Message, len(messages))
+	for i, msg := range messages {
+		out[i] = Message{
+			Role:       msg.Role,
+			Name:       msg.Name,
+			ToolCalls:  msg.ToolCalls,
+			ToolCallID: msg.ToolCallID,
+		}
+		switch content := msg.Content.(type) {
+		case string, []warp.ContentPart:
+			out[i].Content = content
+		}
+	}
+	return out
+}
@@ -0,0 +1,383 @@
+// Package providercore provides the HTTP plumbing shared by provider
+// implementations: building JSON and multipart requests, sending them with
+// optional transport retries, mapping error responses to warp errors, and
+// decoding responses.
+//
+// A provider describes its API once in a Client and keeps only the
+// translation between warp and provider formats:
+//
+//	func (p *Provider) core() *providercore.Client {
+//	    return &providercore.Client{
+//	        Provider:   "groq",
+//	        BaseURL:    p.apiBase,
+//	        HTTPClient: p.httpClient,
+//	        Prepare: func(req *http.Request) error {
+//	            req.Header.Set("Authorization", "Bearer "+p.apiKey)
+//	            return nil
+//	        },
+//	    }
+//	}
+//
+//	func (p *Provider) Completion(ctx context.Context, req *warp.CompletionRequest) (*warp.CompletionResponse, error) {
+//	    var resp warp.CompletionResponse
+//	    if err := p.core().PostJSON(ctx, "/chat/completions", transformRequest(req), &resp); err != nil {
+//	        return nil, err
+//	    }
+//	    return &resp, nil
+//	}
+package providercore
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/blue-context/warp"
+	"github.com/blue-context/warp/jsoncodec"
+)
+
+// DefaultTimeout is the timeout of the default provider HTTP client.
+const DefaultTimeout = 60 * time.Second
+
+// DefaultRetryDelay is the delay before the first transport retry.
+const DefaultRetryDelay = 500 * time.Millisecond
+
+// NewHTTPClient returns the default HTTP client for providers, with
+// DefaultTimeout.
+func NewHTTPClient() *http.Client {
+	return &http.Client{Timeout: DefaultTimeout}
+}
+
+// defaultHTTPClient is used by clients without an HTTPClient.
+var defaultHTTPClient = NewHTTPClient()
+
+// Client sends requests to a provider API.
+//
+// Thread Safety: Client is safe for concurrent use if Prepare is.
+type Client struct {
+	// Provider is the provider name used in errors.
+	Provider string
+
+	// BaseURL is prepended to request paths.
+	BaseURL string
+
+	// HTTPClient sends the requests. Nil uses a client with DefaultTimeout.
+	HTTPClient warp.HTTPClient
+
+	// Prepare sets provider headers, such as authentication and API
+	// versions, on every request before it is sent (and on every retry).
+	Prepare func(req *http.Request) error
+
+	// MaxRetries is how many times a request is resent after a transport
+	// error or a 502, 503 or 504 response. The default 0 leaves retries to
+	// the warp client, which retries whole operations.
+	MaxRetries int
+
+	// RetryDelay is the delay before the first retry, doubled for each
+	// later one. Zero means DefaultRetryDelay.
+	RetryDelay time.Duration
+}
+
+// Request is an HTTP request to the provider API.
+type Request struct {
+	// Method is the HTTP method. Empty means POST.
+	Method string
+
+	// Path is appended to the client's BaseURL. A full URL is used as is.
+	Path string
+
+	// Body is the request body. It is a byte slice so retries can resend it.
+	Body []byte
+
+	// BodyReader streams the request body instead of Body. It can be read
+	// only once, so the request is not retried.
+	BodyReader io.Reader
+
+	// JSON, if Body and BodyReader are not set, is encoded as the body of
+	// each attempt into a pooled buffer that is recycled as soon as the
+	// body has been sent, rather than held until the response is closed.
+	JSON any
+
+	// ContentType is the Content-Type of Body.
+	ContentType string
+
+	// Header holds additional headers.
+	Header http.Header
+
+	// Stream requests a Server-Sent Events response.
+	Stream bool
+}
+
+// Do sends req and returns the response. Responses with a non-2xx status
+// are read, closed, and returned as the error from warp.ParseProviderError.
+//
+// The caller must close the response body.
+func (c *Client) Do(ctx context.Context, req *Request) (*http.Response, error) {
+	delay := c.RetryDelay
+	if delay <= 0 {
+		delay = DefaultRetryDelay
+	}
+
+	for attempt := 0; ; attempt++ {
+		resp, err := c.send(ctx, req)
+		retry := attempt < c.MaxRetries && req.BodyReader == nil && ctx.Err() == nil &&
+			(err != nil || resp.StatusCode == http.StatusBadGateway ||
+				resp.StatusCode == http.StatusServiceUnavailable || resp.StatusCode == http.StatusGatewayTimeout)
+		if !retry {
+			if err != nil {
+				return nil, err
+			}
+			return c.check(resp)
+		}
+		if resp != nil {
+			io.Copy(io.Discard, resp.Body)
+			resp.Body.Close()
+		}
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, ctx.Err()
+		case <-timer.C:
+		}
+		delay *= 2
+	}
+}
+
+// send sends req once.
+func (c *Client) send(ctx context.Context, req *Request) (*http.Response, error) {
+	method := req.Method
+	if method == "" {
+		method = http.MethodPost
+	}
+	var body io.Reader
+	var encoded *jsonBody
+	if req.BodyReader != nil {
+		body = req.BodyReader
+	} else if req.Body != nil {
+		body = bytes.NewReader(req.Body)
+	} else if req.JSON != nil {
+		var err error
+		if encoded, err = newJSONBody(req.JSON); err != nil {
+			return nil, err
+		}
+		body = encoded
+	}
+
+	httpReq, err := http.NewRequestWithContext(ctx, method, c.url(req.Path), body)
+	if err != nil {
+		if encoded != nil {
+			encoded.Close()
+		}
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	if encoded != nil {
+		httpReq.ContentLength = int64(encoded.buf.Len())
+		httpReq.GetBody = func() (io.ReadCloser, error) {
+			return newJSONBody(req.JSON)
+		}
+	}
+	for key, values := range req.Header {
+		httpReq.Header[key] = values
+	}
+	if req.ContentType != "" {
+		httpReq.Header.Set("Content-Type", req.ContentType)
+	}
+	if req.Stream {
+		httpReq.Header.Set("Accept", "text/event-stream")
+	}
+	if c.Prepare != nil {
+		if err := c.Prepare(httpReq); err != nil {
+			if encoded != nil {
+				encoded.Close()
+			}
+			return nil, err
+		}
+	}
+	// Add User-Agent and client request headers
+	warp.SetRequestHeaders(httpReq)
+
+	httpClient := c.HTTPClient
+	if httpClient == nil {
+		httpClient = defaultHTTPClient
+	}
+	resp, err := httpClient.Do(httpReq)
+	if err != nil {
+		return nil, fmt.Errorf("failed to send request: %w", err)
+	}
+	warp.RecordResponse(resp)
+	return resp, nil
+}
+
+// check returns resp if it succeeded, and its provider error otherwise.
+func (c *Client) check(resp *http.Response) (*http.Response, error) {
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return resp, nil
+	}
+	defer resp.Body.Close()
+	body, _ := io.ReadAll(resp.Body)
+	return nil, warp.ParseProviderError(c.Provider, resp.StatusCode, body, nil)
+}
+
+// url resolves a request path against BaseURL.
+func (c *Client) url(path string) string {
+	if strings.HasPrefix(path, "http://") || strings.HasPrefix(path, "https://") {
+		return path
+	}
+	return c.BaseURL + path
+}
+
+// Post posts in as JSON to path and returns the successful response. The
+// caller must close its body.
+func (c *Client) Post(ctx context.Context, path string, in any) (*http.Response, error) {
+	return c.post(ctx, path, in, false)
+}
+
+// PostJSON posts in as JSON to path and decodes the JSON response into
+// out. A nil out discards the response.
+func (c *Client) PostJSON(ctx context.Context, path string, in, out any) error {
+	resp, err := c.post(ctx, path, in, false)
+	if err != nil {
+		return err
+	}
+	return Decode(resp, out)
+}
+
+// PostStream posts in as JSON to path, accepting a Server-Sent Events
+// response, and returns the open response. The caller must close its
+// body.
+func (c *Client) PostStream(ctx context.Context, path string, in any) (*http.Response, error) {
+	return c.post(ctx, path, in, true)
+}
+
+// GetJSON gets path and decodes the JSON response into out.
+func (c *Client) GetJSON(ctx context.Context, path string, out any) error {
+	resp, err := c.Do(ctx, &Request{Method: http.MethodGet, Path: path})
+	if err != nil {
+		return err
+	}
+	return Decode(resp, out)
+}
+
+// post posts in as JSON to path.
+func (c *Client) post(ctx context.Context, path string, in any, stream bool) (*http.Response, error) {
+	return c.Do(ctx, &Request{Path: path, JSON: in, ContentType: "application/json", Stream: stream})
+}
+
+// maxPooledBody is the largest encoded body whose buffer is pooled, so a
+// rare huge request does not pin its memory for the life of the process.
+const maxPooledBody = 8 << 20
+
+var bodyPool = sync.Pool{
+	New: func() any {
+		return new(bytes.Buffer)
+	},
+}
+
+// jsonBody is a request body encoded into a pooled buffer, which is
+// returned to the pool when the transport closes the body after sending
+// it. Streaming responses otherwise keep the whole request alive until
+// they end.
+type jsonBody struct {
+	mu  sync.Mutex
+	buf *bytes.Buffer
+}
+
+// newJSONBody encodes v into a pooled buffer.
+func newJSONBody(v any) (*jsonBody, error) {
+	buf := bodyPool.Get().(*bytes.Buffer)
+	buf.Reset()
+	if codec := jsoncodec.Get(); codec != jsoncodec.Std {
+		data, err := codec.Marshal(v)
+		if err != nil {
+			bodyPool.Put(buf)
+			return nil, fmt.Errorf("failed to marshal request: %w", err)
+		}
+		buf.Write(data)
+		return &jsonBody{buf: buf}, nil
+	}
+	if err := json.NewEncoder(buf).Encode(v); err != nil {
+		bodyPool.Put(buf)
+		return nil, fmt.Errorf("failed to marshal request: %w", err)
+	}
+	// Drop the newline Encode adds, for the same body as json.Marshal
+	buf.Truncate(buf.Len() - 1)
+	return &jsonBody{buf: buf}, nil
+}
+
+func (b *jsonBody) Read(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.buf == nil {
+		return 0, io.ErrClosedPipe
+	}
+	return b.buf.Read(p)
+}
+
+// Close returns the buffer to the pool. Later reads fail.
+func (b *jsonBody) Close() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.buf != nil && b.buf.Cap() <= maxPooledBody {
+		bodyPool.Put(b.buf)
+	}
+	b.buf = nil
+	return nil
+}
+
+// PostForm posts a multipart form to path and decodes the JSON response
+// into out.
+func (c *Client) PostForm(ctx context.Context, path string, form *Form, out any) error {
+	body, contentType, err := form.Encode()
+	if err != nil {
+		return err
+	}
+	resp, err := c.Do(ctx, &Request{Path: path, Body: body, ContentType: contentType})
+	if err != nil {
+		return err
+	}
+	return Decode(resp, out)
+}
+
+// PostFormStream streams a multipart form to path and returns the
+// successful response. Files are read as the body is sent rather than
+// buffered, so the request is not retried. The caller must close the
+// response body.
+func (c *Client) PostFormStream(ctx context.Context, path string, form *Form) (*http.Response, error) {
+	body, contentType := form.Reader()
+	defer body.Close()
+	return c.Do(ctx, &Request{Path: path, BodyReader: body, ContentType: contentType})
+}
+
+// Decode decodes the JSON body of resp into out and closes it, with the
+// installed jsoncodec. A nil out discards the body.
+func Decode(resp *http.Response, out any) error {
+	defer resp.Body.Close()
+	if out == nil {
+		io.Copy(io.Discard, resp.Body)
+		return nil
+	}
+	if codec := jsoncodec.Get(); codec != jsoncodec.Std {
+		data, err := io.ReadAll(resp.Body)
+		if err == nil {
+			err = codec.Unmarshal(data, out)
+		}
+		if err != nil {
+			return fmt.Errorf("failed to decode response: %w", err)
+		}
+		return nil
+	}
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("failed to decode response: %w", err)
+	}
+	return nil
+}
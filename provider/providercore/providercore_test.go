@@ -0,0 +1,378 @@
+package providercore
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+	"testing/iotest"
+	"time"
+
+	"github.com/blue-context/warp"
+	"github.com/blue-context/warp/jsoncodec"
+)
+
+func newTestClient(server *httptest.Server) *Client {
+	return &Client{
+		Provider:   "test",
+		BaseURL:    server.URL + "/v1",
+		HTTPClient: server.Client(),
+		Prepare: func(req *http.Request) error {
+			req.Header.Set("Authorization", "Bearer key")
+			return nil
+		},
+		RetryDelay: time.Millisecond,
+	}
+}
+
+func TestPostJSON(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/chat" || r.Method != http.MethodPost {
+			t.Errorf("request = %s %s", r.Method, r.URL.Path)
+		}
+		if r.Header.Get("Authorization") != "Bearer key" || r.Header.Get("Content-Type") != "application/json" ||
+			r.Header.Get("User-Agent") != warp.DefaultUserAgent {
+			t.Errorf("headers = %v", r.Header)
+		}
+		var in map[string]string
+		json.NewDecoder(r.Body).Decode(&in)
+		json.NewEncoder(w).Encode(map[string]string{"echo": in["msg"]})
+	}))
+	defer server.Close()
+
+	var out map[string]string
+	err := newTestClient(server).PostJSON(context.Background(), "/chat", map[string]string{"msg": "hi"}, &out)
+	if err != nil {
+		t.Fatalf("PostJSON() error = %v", err)
+	}
+	if out["echo"] != "hi" {
+		t.Errorf("response = %v", out)
+	}
+}
+
+func TestDoMapsErrors(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusTooManyRequests)
+		io.WriteString(w, `{"error":{"message":"slow down"}}`)
+	}))
+	defer server.Close()
+
+	err := newTestClient(server).PostJSON(context.Background(), "/chat", map[string]string{}, nil)
+	var rateLimitErr *warp.RateLimitError
+	if !errors.As(err, &rateLimitErr) {
+		t.Fatalf("PostJSON() error = %T %v, want *warp.RateLimitError", err, err)
+	}
+	if rateLimitErr.Provider != "test" {
+		t.Errorf("Provider = %q, want test", rateLimitErr.Provider)
+	}
+}
+
+func TestDoRetries(t *testing.T) {
+	calls := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		body, _ := io.ReadAll(r.Body)
+		if string(body) != `{"n":1}` {
+			t.Errorf("attempt %d body = %q, want the original body", calls, body)
+		}
+		if calls < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		io.WriteString(w, `{}`)
+	}))
+	defer server.Close()
+
+	client := newTestClient(server)
+	if err := client.PostJSON(context.Background(), "/chat", map[string]int{"n": 1}, nil); err == nil {
+		t.Fatal("PostJSON() without retries error = nil, want 503")
+	}
+
+	calls = 0
+	client.MaxRetries = 2
+	if err := client.PostJSON(context.Background(), "/chat", map[string]int{"n": 1}, nil); err != nil {
+		t.Fatalf("PostJSON() error = %v", err)
+	}
+	if calls != 3 {
+		t.Errorf("calls = %d, want 3", calls)
+	}
+}
+
+func TestPostJSONBody(t *testing.T) {
+	prompt := strings.Repeat("a long prompt <with> \"quotes\" ", 10000)
+	in := map[string]any{"model": "m", "messages": Messages([]warp.Message{{Role: "user", Content: prompt}})}
+	want, _ := json.Marshal(in)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		if string(body) != string(want) {
+			t.Errorf("body differs from json.Marshal: %d bytes, want %d", len(body), len(want))
+		}
+		if r.ContentLength != int64(len(want)) {
+			t.Errorf("Content-Length = %d, want %d", r.ContentLength, len(want))
+		}
+		io.WriteString(w, `{}`)
+	}))
+	defer server.Close()
+
+	client := newTestClient(server)
+	for i := 0; i < 3; i++ {
+		if err := client.PostJSON(context.Background(), "/chat", in, nil); err != nil {
+			t.Fatalf("PostJSON() error = %v", err)
+		}
+	}
+
+	if err := client.PostJSON(context.Background(), "/chat", map[string]any{"f": func() {}}, nil); err == nil {
+		t.Error("PostJSON() of unsupported value error = nil")
+	}
+}
+
+// countingCodec is encoding/json counting its calls.
+type countingCodec struct {
+	marshal, unmarshal *int
+}
+
+func (c countingCodec) Marshal(v any) ([]byte, error) {
+	*c.marshal++
+	return json.Marshal(v)
+}
+
+func (c countingCodec) Unmarshal(data []byte, v any) error {
+	*c.unmarshal++
+	return json.Unmarshal(data, v)
+}
+
+func TestPostJSONCodec(t *testing.T) {
+	var marshal, unmarshal int
+	jsoncodec.Set(countingCodec{&marshal, &unmarshal})
+	t.Cleanup(func() { jsoncodec.Set(nil) })
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		if string(body) != `{"msg":"hi"}` {
+			t.Errorf("body = %s", body)
+		}
+		io.WriteString(w, `{"echo":"hi"}`)
+	}))
+	defer server.Close()
+
+	var out map[string]string
+	err := newTestClient(server).PostJSON(context.Background(), "/chat", map[string]string{"msg": "hi"}, &out)
+	if err != nil {
+		t.Fatalf("PostJSON() error = %v", err)
+	}
+	if out["echo"] != "hi" {
+		t.Errorf("response = %v", out)
+	}
+	if marshal != 1 || unmarshal != 1 {
+		t.Errorf("codec calls = %d marshal, %d unmarshal, want 1 each", marshal, unmarshal)
+	}
+}
+
+func TestJSONBodyGetBody(t *testing.T) {
+	// GetBody encodes a fresh body, so redirects can resend it after the
+	// first one was closed and recycled
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/v1/old" {
+			http.Redirect(w, r, "/v1/new", http.StatusTemporaryRedirect)
+			return
+		}
+		body, _ := io.ReadAll(r.Body)
+		w.Write(body)
+	}))
+	defer server.Close()
+
+	var out map[string]int
+	if err := newTestClient(server).PostJSON(context.Background(), "/old", map[string]int{"n": 1}, &out); err != nil {
+		t.Fatalf("PostJSON() error = %v", err)
+	}
+	if out["n"] != 1 {
+		t.Errorf("response = %v, want the body resent after the redirect", out)
+	}
+}
+
+func TestMessages(t *testing.T) {
+	messages := []warp.Message{
+		{Role: "system", Content: "Be brief.", Metadata: map[string]any{"id": 1}},
+		{Role: "user", Content: []warp.ContentPart{
+			{Type: "text", Text: "What is this?"},
+			{Type: "image_url", ImageURL: &warp.ImageURL{URL: "https://example.com/a.png"}},
+		}},
+		{Role: "assistant", ToolCalls: []warp.ToolCall{{ID: "call_1", Type: "function", Function: warp.FunctionCall{Name: "f", Arguments: "{}"}}}},
+		{Role: "tool", Content: "", Name: "f", ToolCallID: "call_1"},
+	}
+
+	got, err := json.Marshal(Messages(messages))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `[{"role":"system","content":"Be brief."},` +
+		`{"role":"user","content":[{"type":"text","text":"What is this?"},{"type":"image_url","image_url":{"url":"https://example.com/a.png"}}]},` +
+		`{"role":"assistant","tool_calls":[{"id":"call_1","type":"function","function":{"name":"f","arguments":"{}"}}]},` +
+		`{"role":"tool","content":"","name":"f","tool_call_id":"call_1"}]`
+	if string(got) != want {
+		t.Errorf("Messages() = %s\nwant %s", got, want)
+	}
+}
+
+func TestPostStream(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Accept") != "text/event-stream" {
+			t.Errorf("Accept = %q", r.Header.Get("Accept"))
+		}
+		io.WriteString(w, "data: [DONE]\n\n")
+	}))
+	defer server.Close()
+
+	// A full URL bypasses BaseURL
+	resp, err := newTestClient(server).PostStream(context.Background(), server.URL+"/v1/stream", map[string]bool{"stream": true})
+	if err != nil {
+		t.Fatalf("PostStream() error = %v", err)
+	}
+	defer resp.Body.Close()
+	body, _ := io.ReadAll(resp.Body)
+	if string(body) != "data: [DONE]\n\n" {
+		t.Errorf("body = %q", body)
+	}
+}
+
+func TestForm(t *testing.T) {
+	form := NewForm()
+	form.AddField("model", "whisper-1")
+	form.AddFile("file", `audio "1".mp3`, "audio/mpeg", strings.NewReader("ID3"))
+	body, contentType, err := form.Encode()
+	if err != nil {
+		t.Fatalf("Encode() error = %v", err)
+	}
+
+	_, params, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		t.Fatalf("ParseMediaType() error = %v", err)
+	}
+	reader := multipart.NewReader(strings.NewReader(string(body)), params["boundary"])
+	parsed, err := reader.ReadForm(1 << 20)
+	if err != nil {
+		t.Fatalf("ReadForm() error = %v", err)
+	}
+	if got := parsed.Value["model"]; len(got) != 1 || got[0] != "whisper-1" {
+		t.Errorf("model = %v", got)
+	}
+	files := parsed.File["file"]
+	if len(files) != 1 || files[0].Filename != `audio "1".mp3` || files[0].Header.Get("Content-Type") != "audio/mpeg" {
+		t.Fatalf("file = %+v", files)
+	}
+
+	form = NewForm()
+	form.AddFile("file", "a.mp3", "", nil)
+	form.AddField("model", "whisper-1")
+	if _, _, err := form.Encode(); err == nil {
+		t.Error("Encode() error = nil for a file without content")
+	}
+}
+
+func TestPostFormStream(t *testing.T) {
+	calls := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		if len(r.TransferEncoding) == 0 || r.TransferEncoding[0] != "chunked" {
+			t.Errorf("TransferEncoding = %v, want chunked", r.TransferEncoding)
+		}
+		if err := r.ParseMultipartForm(1 << 20); err != nil {
+			t.Fatalf("ParseMultipartForm() error = %v", err)
+		}
+		file, _, err := r.FormFile("file")
+		if err != nil {
+			t.Fatalf("FormFile() error = %v", err)
+		}
+		data, _ := io.ReadAll(file)
+		if len(data) != 64<<10 || r.FormValue("model") != "whisper-1" {
+			t.Errorf("file = %d bytes, model = %q", len(data), r.FormValue("model"))
+		}
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	var sent int64
+	form := NewForm()
+	form.AddFile("file", "audio.mp3", "audio/mpeg", strings.NewReader(strings.Repeat("x", 64<<10)))
+	form.AddField("model", "whisper-1")
+	form.OnProgress(func(n int64) { sent = n })
+
+	client := newTestClient(server)
+	client.MaxRetries = 2
+	if _, err := client.PostFormStream(context.Background(), "/audio", form); err == nil {
+		t.Fatal("PostFormStream() error = nil, want 503")
+	}
+	if calls != 1 {
+		t.Errorf("calls = %d, want 1 since a streamed body cannot be resent", calls)
+	}
+	if sent <= 64<<10 {
+		t.Errorf("progress = %d bytes, want the whole body", sent)
+	}
+}
+
+func TestFormReaderError(t *testing.T) {
+	errRead := errors.New("disk error")
+	form := NewForm()
+	form.AddFile("file", "a.mp3", "", io.MultiReader(strings.NewReader("ID3"), iotest.ErrReader(errRead)))
+	body, _ := form.Reader()
+	defer body.Close()
+	if _, err := io.ReadAll(body); !errors.Is(err, errRead) {
+		t.Errorf("ReadAll() error = %v, want the file's read error", err)
+	}
+}
+
+func TestCompletionCitations(t *testing.T) {
+	tests := []struct {
+		name string
+		body string
+		want []warp.Citation
+	}{
+		{
+			name: "openai annotations",
+			body: `{"choices": [{"message": {"role": "assistant", "content": "Héllo, see docs.", "annotations": [
+				{"type": "url_citation", "url_citation": {"url": "https://example.com", "title": "Example", "start_index": 11, "end_index": 15}},
+				{"type": "file_citation"}
+			]}}]}`,
+			want: []warp.Citation{{URL: "https://example.com", Title: "Example", Text: "docs", StartIndex: 11, EndIndex: 15}},
+		},
+		{
+			name: "perplexity citations",
+			body: `{"choices": [{"message": {"role": "assistant", "content": "Hi [1]"}}], "citations": ["https://a.example"]}`,
+			want: []warp.Citation{{URL: "https://a.example"}},
+		},
+		{
+			name: "perplexity search results",
+			body: `{"choices": [{"message": {"role": "assistant", "content": "Hi [1]"}}],
+				"citations": ["https://a.example"],
+				"search_results": [{"title": "A", "url": "https://a.example", "date": "2025-01-01"}]}`,
+			want: []warp.Citation{{URL: "https://a.example", Title: "A"}},
+		},
+		{
+			name: "none",
+			body: `{"choices": [{"message": {"role": "assistant", "content": "Hi"}}]}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var resp Completion
+			if err := json.Unmarshal([]byte(tt.body), &resp); err != nil {
+				t.Fatal(err)
+			}
+			if len(resp.Choices) != 1 {
+				t.Fatalf("got %d choices, want 1", len(resp.Choices))
+			}
+			if !reflect.DeepEqual(resp.Citations, tt.want) {
+				t.Errorf("Citations = %+v, want %+v", resp.Citations, tt.want)
+			}
+		})
+	}
+}
@@ -1,3 +1,5 @@
+//go:build !tinygo && !warp_minimal
+
 package provider
 
 import (
@@ -282,12 +284,19 @@ func AssertProviderCompliance(t *testing.T, p Provider) {
 	})
 }
 
-// AssertMethodCount verifies that the provider has exactly 14 methods.
+// AssertMethodCount verifies that the provider has exactly 14 methods plus
+// any declared provider-specific extensions.
 //
 // This ensures no methods are accidentally removed or added without updating the interface.
+// Providers that deliberately expose extra methods (e.g., account or catalog
+// endpoints) list them in extra; each must exist and the total must match.
 //
 // Note: This counts only exported methods. Private methods are not counted.
-func AssertMethodCount(t *testing.T, p Provider) {
+//
+// Example:
+//
+//	provider.AssertMethodCount(t, p, "GetKeyInfo")
+func AssertMethodCount(t *testing.T, p Provider, extra ...string) {
 	t.Helper()
 
 	if p == nil {
@@ -297,7 +306,13 @@ func AssertMethodCount(t *testing.T, p Provider) {
 	providerType := reflect.TypeOf(p)
 	methodCount := providerType.NumMethod()
 
-	expectedCount := 14 // Based on Provider interface definition
+	expectedCount := 14 + len(extra) // Based on Provider interface definition
+
+	for _, name := range extra {
+		if _, ok := providerType.MethodByName(name); !ok {
+			t.Errorf("Provider is missing declared extension method %s", name)
+		}
+	}
 
 	if methodCount != expectedCount {
 		t.Errorf("Provider has %d methods, want %d", methodCount, expectedCount)
@@ -1,14 +1,10 @@
 package together
 
 import (
-	"bytes"
 	"context"
-	"encoding/json"
-	"fmt"
-	"io"
-	"net/http"
 
 	"github.com/blue-context/warp"
+	"github.com/blue-context/warp/provider/providercore"
 )
 
 // Completion sends a chat completion request to Together AI.
@@ -32,39 +28,10 @@ func (p *Provider) Completion(ctx context.Context, req *warp.CompletionRequest)
 	// Transform request to Together AI format (OpenAI-compatible)
 	togetherReq := transformRequest(req)
 
-	// Marshal to JSON
-	body, err := json.Marshal(togetherReq)
-	if err != nil {
-		return nil, fmt.Errorf("failed to marshal request: %w", err)
-	}
-
-	// Create HTTP request
-	httpReq, err := http.NewRequestWithContext(ctx, "POST", p.apiBase+"/chat/completions", bytes.NewReader(body))
-	if err != nil {
-		return nil, fmt.Errorf("failed to create request: %w", err)
-	}
-
-	// Set headers
-	httpReq.Header.Set("Content-Type", "application/json")
-	httpReq.Header.Set("Authorization", "Bearer "+p.apiKey)
-
-	// Send request
-	httpResp, err := p.httpClient.Do(httpReq)
-	if err != nil {
-		return nil, fmt.Errorf("failed to send request: %w", err)
-	}
-	defer httpResp.Body.Close()
-
-	// Check status code
-	if httpResp.StatusCode != http.StatusOK {
-		body, _ := io.ReadAll(httpResp.Body)
-		return nil, warp.ParseProviderError("together", httpResp.StatusCode, body, nil)
-	}
-
-	// Parse response
+	// Send request and parse response
 	var resp warp.CompletionResponse
-	if err := json.NewDecoder(httpResp.Body).Decode(&resp); err != nil {
-		return nil, fmt.Errorf("failed to decode response: %w", err)
+	if err := p.core().PostJSON(ctx, "/chat/completions", togetherReq, &resp); err != nil {
+		return nil, err
 	}
 
 	return &resp, nil
@@ -77,7 +44,7 @@ func (p *Provider) Completion(ctx context.Context, req *warp.CompletionRequest)
 func transformRequest(req *warp.CompletionRequest) map[string]any {
 	togetherReq := map[string]any{
 		"model":    req.Model,
-		"messages": transformMessages(req.Messages),
+		"messages": providercore.Messages(req.Messages),
 	}
 
 	// Optional parameters
@@ -119,52 +86,3 @@ func transformRequest(req *warp.CompletionRequest) map[string]any {
 
 	return togetherReq
 }
-
-// transformMessages transforms Warp messages to Together AI format.
-//
-// Together AI uses OpenAI-compatible message format.
-func transformMessages(messages []warp.Message) []map[string]any {
-	togetherMessages := make([]map[string]any, len(messages))
-
-	for i, msg := range messages {
-		togetherMsg := map[string]any{
-			"role": msg.Role,
-		}
-
-		// Handle content (can be string or []ContentPart for multimodal)
-		switch content := msg.Content.(type) {
-		case string:
-			togetherMsg["content"] = content
-		case []warp.ContentPart:
-			// Multimodal content (though Together AI doesn't support vision yet)
-			parts := make([]map[string]any, len(content))
-			for j, part := range content {
-				parts[j] = map[string]any{
-					"type": part.Type,
-				}
-				if part.Text != "" {
-					parts[j]["text"] = part.Text
-				}
-				if part.ImageURL != nil {
-					parts[j]["image_url"] = part.ImageURL
-				}
-			}
-			togetherMsg["content"] = parts
-		}
-
-		// Optional fields
-		if msg.Name != "" {
-			togetherMsg["name"] = msg.Name
-		}
-		if len(msg.ToolCalls) > 0 {
-			togetherMsg["tool_calls"] = msg.ToolCalls
-		}
-		if msg.ToolCallID != "" {
-			togetherMsg["tool_call_id"] = msg.ToolCallID
-		}
-
-		togetherMessages[i] = togetherMsg
-	}
-
-	return togetherMessages
-}
@@ -27,10 +27,10 @@ import (
 	"context"
 	"io"
 	"net/http"
-	"time"
 
 	"github.com/blue-context/warp"
 	"github.com/blue-context/warp/provider"
+	"github.com/blue-context/warp/provider/providercore"
 )
 
 // Provider implements the provider.Provider interface for Together AI.
@@ -65,7 +65,7 @@ type Option func(*Provider)
 func NewProvider(opts ...Option) (*Provider, error) {
 	p := &Provider{
 		apiBase:    "https://api.together.xyz/v1",
-		httpClient: &http.Client{Timeout: 60 * time.Second},
+		httpClient: providercore.NewHTTPClient(),
 	}
 
 	for _, opt := range opts {
@@ -135,6 +135,19 @@ func WithHTTPClient(client warp.HTTPClient) Option {
 	}
 }
 
+// core returns the HTTP client for the Together AI API.
+func (p *Provider) core() *providercore.Client {
+	return &providercore.Client{
+		Provider:   "together",
+		BaseURL:    p.apiBase,
+		HTTPClient: p.httpClient,
+		Prepare: func(req *http.Request) error {
+			req.Header.Set("Authorization", "Bearer "+p.apiKey)
+			return nil
+		},
+	}
+}
+
 // Name returns the provider name "together".
 //
 // This is used for provider identification in the registry and error messages.
@@ -5,6 +5,7 @@
 package vertex
 
 import (
+	"context"
 	"crypto"
 	"crypto/rand"
 	"crypto/rsa"
@@ -17,8 +18,10 @@ import (
 	"io"
 	"net/http"
 	"net/url"
-	"sync"
+	"strings"
 	"time"
+
+	"github.com/blue-context/warp/internal/tokencache"
 )
 
 const (
@@ -30,9 +33,6 @@ const (
 
 	// JWT validity duration
 	jwtExpiration = 1 * time.Hour
-
-	// Token refresh buffer - refresh token this much before expiration
-	tokenRefreshBuffer = 5 * time.Minute
 )
 
 // TokenProvider manages OAuth2 access tokens for GCP service accounts.
@@ -40,19 +40,17 @@ const (
 // This implementation creates and signs JWT assertions using RS256 (RSA + SHA256)
 // and exchanges them for OAuth2 access tokens via Google's token endpoint.
 //
-// Token caching is implemented to avoid unnecessary token exchanges. Tokens are
-// refreshed automatically when they approach expiration.
+// Tokens are cached and renewed in the background before they expire, with
+// jittered early renewal and a clock skew margin. Concurrent callers share
+// one token exchange, so a burst of requests does not stampede the token
+// endpoint.
 //
 // Thread Safety: TokenProvider is safe for concurrent use.
 // Multiple goroutines may call GetToken simultaneously.
 type TokenProvider struct {
 	serviceAccountKey *ServiceAccountKey
 	httpClient        *http.Client
-
-	// Token cache with mutex protection
-	mu         sync.RWMutex
-	token      string
-	expiration time.Time
+	tokens            *tokencache.Cache
 }
 
 // ServiceAccountKey represents a GCP service account key file.
@@ -118,20 +116,20 @@ func NewTokenProvider(serviceAccountJSON []byte) (*TokenProvider, error) {
 		return nil, fmt.Errorf("invalid private key: %w", err)
 	}
 
-	return &TokenProvider{
+	t := &TokenProvider{
 		serviceAccountKey: &key,
 		httpClient:        &http.Client{Timeout: 30 * time.Second},
-	}, nil
+	}
+	t.tokens = tokencache.New(t.fetchToken)
+	return t, nil
 }
 
 // GetToken returns a valid OAuth2 access token, refreshing if needed.
 //
-// This method implements token caching to avoid unnecessary token exchanges.
-// If a cached token exists and is still valid (not expired and not close to
-// expiration), it is returned immediately.
-//
-// Otherwise, a new JWT assertion is created, signed with the service account's
-// private key, and exchanged for a fresh access token.
+// A cached token is returned immediately while it is valid; one close to
+// expiration is still returned while a fresh token is fetched in the
+// background. Otherwise, a new JWT assertion is created, signed with the
+// service account's private key, and exchanged for a fresh access token.
 //
 // Thread Safety: This method is safe for concurrent use.
 // Multiple goroutines may call GetToken simultaneously.
@@ -144,41 +142,30 @@ func NewTokenProvider(serviceAccountJSON []byte) (*TokenProvider, error) {
 //	}
 //	req.Header.Set("Authorization", "Bearer "+token)
 func (t *TokenProvider) GetToken() (string, error) {
-	// Fast path: return cached token if still valid
-	t.mu.RLock()
-	if t.token != "" && time.Now().Add(tokenRefreshBuffer).Before(t.expiration) {
-		token := t.token
-		t.mu.RUnlock()
-		return token, nil
-	}
-	t.mu.RUnlock()
-
-	// Slow path: refresh token
-	t.mu.Lock()
-	defer t.mu.Unlock()
+	return t.GetTokenContext(context.Background())
+}
 
-	// Double-check after acquiring write lock (another goroutine may have refreshed)
-	if t.token != "" && time.Now().Add(tokenRefreshBuffer).Before(t.expiration) {
-		return t.token, nil
-	}
+// GetTokenContext is like GetToken but stops waiting for a token exchange
+// when ctx is done.
+func (t *TokenProvider) GetTokenContext(ctx context.Context) (string, error) {
+	return t.tokens.Token(ctx)
+}
 
+// fetchToken exchanges a new JWT assertion for an access token.
+func (t *TokenProvider) fetchToken(ctx context.Context) (*tokencache.Token, error) {
 	// Create JWT assertion
 	jwt, err := t.createJWT()
 	if err != nil {
-		return "", fmt.Errorf("failed to create JWT: %w", err)
+		return nil, fmt.Errorf("failed to create JWT: %w", err)
 	}
 
 	// Exchange JWT for access token
-	token, expiration, err := t.exchangeJWT(jwt)
+	token, expiration, err := t.exchangeJWT(ctx, jwt)
 	if err != nil {
-		return "", fmt.Errorf("failed to exchange JWT for token: %w", err)
+		return nil, fmt.Errorf("failed to exchange JWT for token: %w", err)
 	}
 
-	// Cache token
-	t.token = token
-	t.expiration = expiration
-
-	return token, nil
+	return &tokencache.Token{AccessToken: token, Expiry: expiration}, nil
 }
 
 // createJWT creates a signed JWT assertion for OAuth2 token exchange.
@@ -294,7 +281,7 @@ func (t *TokenProvider) signJWT(input string) ([]byte, error) {
 //	token_type: "Bearer"
 //
 // Reference: https://developers.google.com/identity/protocols/oauth2/service-account#httprest
-func (t *TokenProvider) exchangeJWT(jwt string) (token string, expiration time.Time, err error) {
+func (t *TokenProvider) exchangeJWT(ctx context.Context, jwt string) (token string, expiration time.Time, err error) {
 	// Build form data
 	data := url.Values{
 		"grant_type": {"urn:ietf:params:oauth:grant-type:jwt-bearer"},
@@ -302,7 +289,12 @@ func (t *TokenProvider) exchangeJWT(jwt string) (token string, expiration time.T
 	}
 
 	// POST to token endpoint
-	resp, err := t.httpClient.PostForm(t.serviceAccountKey.TokenURI, data)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, t.serviceAccountKey.TokenURI, strings.NewReader(data.Encode()))
+	if err != nil {
+		return "", time.Time{}, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	resp, err := t.httpClient.Do(req)
 	if err != nil {
 		return "", time.Time{}, fmt.Errorf("HTTP request failed: %w", err)
 	}
@@ -11,7 +11,6 @@ import (
 	"net/http/httptest"
 	"strings"
 	"testing"
-	"time"
 )
 
 // generateTestServiceAccountKey generates a test service account key with a real RSA key.
@@ -257,7 +256,7 @@ func TestTokenProvider_GetToken(t *testing.T) {
 	}
 
 	// Test 3: Expire token and get new one
-	provider.expiration = time.Now().Add(-1 * time.Hour) // Force expiration
+	provider.tokens.Invalidate() // Force expiration
 	token3, err := provider.GetToken()
 	if err != nil {
 		t.Fatalf("GetToken() third call failed: %v", err)
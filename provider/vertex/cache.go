@@ -0,0 +1,262 @@
+package vertex
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/blue-context/warp"
+)
+
+// Compile-time interface check
+var _ warp.CachedContentManager = (*Provider)(nil)
+
+// vertexCachedContent represents a Vertex AI cachedContents resource.
+type vertexCachedContent struct {
+	Name              string               `json:"name,omitempty"`
+	Model             string               `json:"model,omitempty"`
+	DisplayName       string               `json:"displayName,omitempty"`
+	Contents          []vertexContent      `json:"contents,omitempty"`
+	SystemInstruction *vertexContent       `json:"systemInstruction,omitempty"`
+	Tools             []vertexTool         `json:"tools,omitempty"`
+	TTL               string               `json:"ttl,omitempty"`
+	CreateTime        *time.Time           `json:"createTime,omitempty"`
+	ExpireTime        *time.Time           `json:"expireTime,omitempty"`
+	UsageMetadata     *vertexUsageMetadata `json:"usageMetadata,omitempty"`
+}
+
+// CreateCachedContent stores messages and tools with Vertex AI context
+// caching, for reuse by requests to the same model that set
+// CompletionRequest.CachedContent to the returned name.
+//
+// Vertex AI requires a minimum number of tokens for cached content (32,768
+// for Gemini 1.5) and rejects smaller content.
+//
+// Thread Safety: This method is safe for concurrent use.
+func (p *Provider) CreateCachedContent(ctx context.Context, req *warp.CachedContentRequest) (*warp.CachedContent, error) {
+	if req == nil {
+		return nil, &warp.WarpError{
+			Message:  "cached content request cannot be nil",
+			Provider: "vertex",
+		}
+	}
+	if req.Model == "" {
+		return nil, &warp.WarpError{
+			Message:  "model is required",
+			Provider: "vertex",
+		}
+	}
+
+	// Reuse the completion transformation for the content
+	vReq, err := transformRequest(&warp.CompletionRequest{Model: req.Model, Messages: req.Messages, Tools: req.Tools})
+	if err != nil {
+		return nil, &warp.WarpError{
+			Message:       fmt.Sprintf("failed to transform cached content: %v", err),
+			Provider:      "vertex",
+			OriginalError: err,
+		}
+	}
+
+	body := &vertexCachedContent{
+		Model:             p.modelName(strings.TrimPrefix(req.Model, "vertex/")),
+		DisplayName:       req.DisplayName,
+		Contents:          vReq.Contents,
+		SystemInstruction: vReq.SystemInstruction,
+		Tools:             vReq.Tools,
+	}
+	if req.TTL > 0 {
+		body.TTL = formatTTL(req.TTL)
+	}
+
+	return p.doCachedContent(ctx, "POST", p.cachedContentsURL(), body)
+}
+
+// GetCachedContent returns cached content by name, either the full
+// resource name or its ID.
+//
+// Thread Safety: This method is safe for concurrent use.
+func (p *Provider) GetCachedContent(ctx context.Context, name string) (*warp.CachedContent, error) {
+	if name == "" {
+		return nil, &warp.WarpError{
+			Message:  "cached content name is required",
+			Provider: "vertex",
+		}
+	}
+	return p.doCachedContent(ctx, "GET", p.cachedContentURL(name), nil)
+}
+
+// UpdateCachedContentTTL keeps cached content for ttl from now.
+//
+// Thread Safety: This method is safe for concurrent use.
+func (p *Provider) UpdateCachedContentTTL(ctx context.Context, name string, ttl time.Duration) (*warp.CachedContent, error) {
+	if name == "" {
+		return nil, &warp.WarpError{
+			Message:  "cached content name is required",
+			Provider: "vertex",
+		}
+	}
+	if ttl <= 0 {
+		return nil, &warp.WarpError{
+			Message:  fmt.Sprintf("TTL must be positive, got %v", ttl),
+			Provider: "vertex",
+		}
+	}
+	body := &vertexCachedContent{TTL: formatTTL(ttl)}
+	return p.doCachedContent(ctx, "PATCH", p.cachedContentURL(name)+"?updateMask=ttl", body)
+}
+
+// DeleteCachedContent deletes cached content, ending its storage charges.
+//
+// Thread Safety: This method is safe for concurrent use.
+func (p *Provider) DeleteCachedContent(ctx context.Context, name string) error {
+	if name == "" {
+		return &warp.WarpError{
+			Message:  "cached content name is required",
+			Provider: "vertex",
+		}
+	}
+	_, err := p.doCachedContent(ctx, "DELETE", p.cachedContentURL(name), nil)
+	return err
+}
+
+// doCachedContent sends a cachedContents request and parses the resource
+// in the response, if any.
+func (p *Provider) doCachedContent(ctx context.Context, method, url string, body *vertexCachedContent) (*warp.CachedContent, error) {
+	// Get OAuth2 access token
+	token, err := p.tokenProvider.GetTokenContext(ctx)
+	if err != nil {
+		return nil, &warp.WarpError{
+			Message:       fmt.Sprintf("failed to get access token: %v", err),
+			Provider:      "vertex",
+			OriginalError: err,
+		}
+	}
+
+	var reqBody io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return nil, &warp.WarpError{
+				Message:       fmt.Sprintf("failed to marshal request: %v", err),
+				Provider:      "vertex",
+				OriginalError: err,
+			}
+		}
+		reqBody = bytes.NewReader(data)
+	}
+
+	httpReq, err := http.NewRequestWithContext(ctx, method, url, reqBody)
+	if err != nil {
+		return nil, &warp.WarpError{
+			Message:       fmt.Sprintf("failed to create HTTP request: %v", err),
+			Provider:      "vertex",
+			OriginalError: err,
+		}
+	}
+	if body != nil {
+		httpReq.Header.Set("Content-Type", "application/json")
+	}
+	httpReq.Header.Set("Authorization", "Bearer "+token)
+	warp.SetRequestHeaders(httpReq)
+
+	httpResp, err := p.httpClient.Do(httpReq)
+	if err != nil {
+		return nil, &warp.WarpError{
+			Message:       fmt.Sprintf("HTTP request failed: %v", err),
+			Provider:      "vertex",
+			OriginalError: err,
+		}
+	}
+	warp.RecordResponse(httpResp)
+	defer httpResp.Body.Close()
+
+	respBody, err := io.ReadAll(httpResp.Body)
+	if err != nil {
+		return nil, &warp.WarpError{
+			Message:       fmt.Sprintf("failed to read response body: %v", err),
+			Provider:      "vertex",
+			OriginalError: err,
+		}
+	}
+	if httpResp.StatusCode != http.StatusOK {
+		return nil, warp.ParseProviderError("vertex", httpResp.StatusCode, respBody, nil)
+	}
+	if method == "DELETE" {
+		return nil, nil
+	}
+
+	var resource vertexCachedContent
+	if err := json.Unmarshal(respBody, &resource); err != nil {
+		return nil, &warp.WarpError{
+			Message:       fmt.Sprintf("failed to parse response: %v", err),
+			Provider:      "vertex",
+			OriginalError: err,
+		}
+	}
+	return transformCachedContent(&resource), nil
+}
+
+// transformCachedContent converts a cachedContents resource to cached
+// content, with the model name without its resource path.
+func transformCachedContent(resource *vertexCachedContent) *warp.CachedContent {
+	cached := &warp.CachedContent{
+		Name:        resource.Name,
+		Model:       resource.Model,
+		DisplayName: resource.DisplayName,
+	}
+	if resource.CreateTime != nil {
+		cached.CreateTime = *resource.CreateTime
+	}
+	if resource.ExpireTime != nil {
+		cached.ExpireTime = *resource.ExpireTime
+	}
+	if i := strings.LastIndex(cached.Model, "/models/"); i >= 0 {
+		cached.Model = cached.Model[i+len("/models/"):]
+	}
+	if resource.UsageMetadata != nil {
+		cached.Tokens = resource.UsageMetadata.TotalTokenCount
+	}
+	return cached
+}
+
+// cachedContentsURL returns the URL of the cachedContents collection.
+func (p *Provider) cachedContentsURL() string {
+	return fmt.Sprintf("https://%s-aiplatform.googleapis.com/v1/projects/%s/locations/%s/cachedContents",
+		p.location,
+		p.projectID,
+		p.location,
+	)
+}
+
+// cachedContentURL returns the URL of the cached content with the given
+// name or ID.
+func (p *Provider) cachedContentURL(name string) string {
+	return fmt.Sprintf("https://%s-aiplatform.googleapis.com/v1/%s", p.location, p.cachedContentName(name))
+}
+
+// cachedContentName expands a cached content ID to its full resource name,
+// projects/{project}/locations/{location}/cachedContents/{id}. Full names
+// and empty names are returned unchanged.
+func (p *Provider) cachedContentName(name string) string {
+	if name == "" || strings.Contains(name, "/") {
+		return name
+	}
+	return fmt.Sprintf("projects/%s/locations/%s/cachedContents/%s", p.projectID, p.location, name)
+}
+
+// modelName returns the full resource name of a Google model.
+func (p *Provider) modelName(model string) string {
+	return fmt.Sprintf("projects/%s/locations/%s/publishers/google/models/%s", p.projectID, p.location, model)
+}
+
+// formatTTL formats a TTL as a protobuf Duration in seconds, such as
+// "3600s".
+func formatTTL(ttl time.Duration) string {
+	return strconv.FormatFloat(ttl.Seconds(), 'f', -1, 64) + "s"
+}
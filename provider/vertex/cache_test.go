@@ -0,0 +1,186 @@
+package vertex
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/blue-context/warp"
+)
+
+// pathTransport sends Vertex AI requests to the test server, keeping their
+// path and query.
+type pathTransport struct {
+	vertexURL string
+}
+
+func (t *pathTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if strings.Contains(req.URL.Host, "aiplatform.googleapis.com") {
+		req.URL.Scheme = "http"
+		req.URL.Host = strings.TrimPrefix(t.vertexURL, "http://")
+	}
+	return http.DefaultTransport.RoundTrip(req)
+}
+
+// newCacheTestProvider returns a provider sending its requests to handler.
+func newCacheTestProvider(t *testing.T, handler http.HandlerFunc) *Provider {
+	t.Helper()
+
+	tokenServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"access_token": "test-token",
+			"expires_in":   3600,
+			"token_type":   "Bearer",
+		})
+	}))
+	t.Cleanup(tokenServer.Close)
+
+	var key ServiceAccountKey
+	json.Unmarshal(generateTestServiceAccountKey(t), &key)
+	key.TokenURI = tokenServer.URL
+	keyJSON, _ := json.Marshal(key)
+
+	vertexServer := httptest.NewServer(handler)
+	t.Cleanup(vertexServer.Close)
+
+	p, err := NewProvider(
+		WithProjectID("test-project"),
+		WithServiceAccountKey(keyJSON),
+		WithHTTPClient(&http.Client{Transport: &pathTransport{vertexURL: vertexServer.URL}}),
+	)
+	if err != nil {
+		t.Fatalf("NewProvider() failed: %v", err)
+	}
+	return p
+}
+
+func TestProvider_CachedContent(t *testing.T) {
+	const name = "projects/test-project/locations/us-central1/cachedContents/123"
+	resource := `{
+		"name": "` + name + `",
+		"model": "projects/test-project/locations/us-central1/publishers/google/models/gemini-1.5-pro-002",
+		"displayName": "manual",
+		"createTime": "2024-01-01T00:00:00Z",
+		"expireTime": "2024-01-01T01:00:00Z",
+		"usageMetadata": {"totalTokenCount": 40000}
+	}`
+
+	var method, path, query string
+	var body map[string]interface{}
+	p := newCacheTestProvider(t, func(w http.ResponseWriter, r *http.Request) {
+		method, path, query = r.Method, r.URL.Path, r.URL.RawQuery
+		body = nil
+		data, _ := io.ReadAll(r.Body)
+		json.Unmarshal(data, &body)
+		if r.Method == "DELETE" {
+			io.WriteString(w, "{}")
+			return
+		}
+		io.WriteString(w, resource)
+	})
+	ctx := context.Background()
+
+	cached, err := p.CreateCachedContent(ctx, &warp.CachedContentRequest{
+		Model:       "gemini-1.5-pro-002",
+		DisplayName: "manual",
+		Messages: []warp.Message{
+			{Role: "system", Content: "Answer from the manual."},
+			{Role: "user", Content: "The manual."},
+		},
+		TTL: time.Hour,
+	})
+	if err != nil {
+		t.Fatalf("CreateCachedContent() error = %v", err)
+	}
+	if method != "POST" || path != "/v1/projects/test-project/locations/us-central1/cachedContents" {
+		t.Errorf("request = %s %s", method, path)
+	}
+	if body["model"] != "projects/test-project/locations/us-central1/publishers/google/models/gemini-1.5-pro-002" {
+		t.Errorf("model = %v", body["model"])
+	}
+	if body["ttl"] != "3600s" || body["systemInstruction"] == nil || body["contents"] == nil {
+		t.Errorf("body = %v, want ttl, systemInstruction and contents", body)
+	}
+	if _, ok := body["createTime"]; ok {
+		t.Error("request sent createTime")
+	}
+	want := &warp.CachedContent{
+		Name:        name,
+		Model:       "gemini-1.5-pro-002",
+		DisplayName: "manual",
+		Tokens:      40000,
+		CreateTime:  time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+		ExpireTime:  time.Date(2024, 1, 1, 1, 0, 0, 0, time.UTC),
+	}
+	if cached.Name != want.Name || cached.Model != want.Model || cached.DisplayName != want.DisplayName ||
+		cached.Tokens != want.Tokens || !cached.CreateTime.Equal(want.CreateTime) || !cached.ExpireTime.Equal(want.ExpireTime) {
+		t.Errorf("CreateCachedContent() = %+v, want %+v", cached, want)
+	}
+
+	if _, err := p.GetCachedContent(ctx, "123"); err != nil {
+		t.Fatalf("GetCachedContent() error = %v", err)
+	}
+	if method != "GET" || path != "/v1/"+name {
+		t.Errorf("request = %s %s, want GET /v1/%s", method, path, name)
+	}
+
+	if _, err := p.UpdateCachedContentTTL(ctx, name, 90*time.Minute); err != nil {
+		t.Fatalf("UpdateCachedContentTTL() error = %v", err)
+	}
+	if method != "PATCH" || query != "updateMask=ttl" || body["ttl"] != "5400s" {
+		t.Errorf("request = %s ?%s %v", method, query, body)
+	}
+
+	if err := p.DeleteCachedContent(ctx, name); err != nil {
+		t.Fatalf("DeleteCachedContent() error = %v", err)
+	}
+	if method != "DELETE" || path != "/v1/"+name {
+		t.Errorf("request = %s %s, want DELETE", method, path)
+	}
+}
+
+func TestProvider_CompletionCachedContent(t *testing.T) {
+	var req vertexRequest
+	p := newCacheTestProvider(t, func(w http.ResponseWriter, r *http.Request) {
+		json.NewDecoder(r.Body).Decode(&req)
+		io.WriteString(w, `{
+			"candidates": [{"content": {"role": "model", "parts": [{"text": "Hold the button."}]}, "finishReason": "STOP"}],
+			"usageMetadata": {"promptTokenCount": 40010, "candidatesTokenCount": 4, "totalTokenCount": 40014, "cachedContentTokenCount": 40000}
+		}`)
+	})
+
+	resp, err := p.Completion(context.Background(), &warp.CompletionRequest{
+		Model:         "gemini-1.5-pro-002",
+		CachedContent: "123",
+		Messages:      []warp.Message{{Role: "user", Content: "How do I reset the device?"}},
+	})
+	if err != nil {
+		t.Fatalf("Completion() error = %v", err)
+	}
+	if want := "projects/test-project/locations/us-central1/cachedContents/123"; req.CachedContent != want {
+		t.Errorf("cachedContent = %q, want %q", req.CachedContent, want)
+	}
+	if got := resp.Usage.GetCachedTokens(); got != 40000 {
+		t.Errorf("cached tokens = %d, want 40000", got)
+	}
+}
+
+func TestProvider_CachedContentError(t *testing.T) {
+	p := newCacheTestProvider(t, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+		io.WriteString(w, `{"error": {"code": 400, "message": "The cached content is of 1000 tokens. The minimum token count to start caching is 32768.", "status": "INVALID_ARGUMENT"}}`)
+	})
+
+	_, err := p.CreateCachedContent(context.Background(), &warp.CachedContentRequest{
+		Model:    "gemini-1.5-flash-002",
+		Messages: []warp.Message{{Role: "user", Content: "Too short."}},
+	})
+	if err == nil || !strings.Contains(err.Error(), "minimum token count") {
+		t.Errorf("CreateCachedContent() error = %v, want the provider's error", err)
+	}
+}
@@ -42,7 +42,7 @@ func (p *Provider) Completion(ctx context.Context, req *warp.CompletionRequest)
 	}
 
 	// Get OAuth2 access token
-	token, err := p.tokenProvider.GetToken()
+	token, err := p.tokenProvider.GetTokenContext(ctx)
 	if err != nil {
 		return nil, &warp.WarpError{
 			Message:       fmt.Sprintf("failed to get access token: %v", err),
@@ -63,6 +63,7 @@ func (p *Provider) Completion(ctx context.Context, req *warp.CompletionRequest)
 			OriginalError: err,
 		}
 	}
+	vertexReq.CachedContent = p.cachedContentName(vertexReq.CachedContent)
 
 	// Marshal request body
 	body, err := json.Marshal(vertexReq)
@@ -88,6 +89,9 @@ func (p *Provider) Completion(ctx context.Context, req *warp.CompletionRequest)
 	httpReq.Header.Set("Content-Type", "application/json")
 	httpReq.Header.Set("Authorization", "Bearer "+token)
 
+	// Add User-Agent and client request headers
+	warp.SetRequestHeaders(httpReq)
+
 	// Send request
 	httpResp, err := p.httpClient.Do(httpReq)
 	if err != nil {
@@ -97,6 +101,7 @@ func (p *Provider) Completion(ctx context.Context, req *warp.CompletionRequest)
 			OriginalError: err,
 		}
 	}
+	warp.RecordResponse(httpResp)
 	defer httpResp.Body.Close()
 
 	// Read response body
@@ -125,12 +130,13 @@ func (p *Provider) Completion(ctx context.Context, req *warp.CompletionRequest)
 	}
 
 	// Check for prompt feedback (content blocked, safety issues, etc.)
-	if vertexResp.PromptFeedback != nil && vertexResp.PromptFeedback.BlockReason != "" {
-		return nil, warp.NewContentPolicyViolationError(
-			fmt.Sprintf("prompt blocked: %s", vertexResp.PromptFeedback.BlockReason),
-			"vertex",
-			nil,
-		)
+	if err := promptBlockedError(&vertexResp); err != nil {
+		return nil, err
+	}
+
+	// Check for candidates stopped by safety filters before any output
+	if err := candidatesBlockedError(&vertexResp); err != nil {
+		return nil, err
 	}
 
 	// Check if we have any candidates
@@ -153,3 +159,10 @@ func (p *Provider) Completion(ctx context.Context, req *warp.CompletionRequest)
 
 	return resp, nil
 }
+
+// MaxStopSequences reports that Gemini accepts 5 stop sequences.
+//
+// It implements warp.StopSequenceLimiter.
+func (p *Provider) MaxStopSequences(model string) int {
+	return 5
+}
@@ -17,7 +17,7 @@ func TestProviderCompliance(t *testing.T) {
 
 	// Run compliance checks
 	provider.AssertProviderCompliance(t, p)
-	provider.AssertMethodCount(t, p)
+	provider.AssertMethodCount(t, p, "MaxStopSequences", "CreateCachedContent", "GetCachedContent", "UpdateCachedContentTTL", "DeleteCachedContent")
 }
 
 // getTestOptions returns options for creating a test provider instance.
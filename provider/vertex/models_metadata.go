@@ -11,16 +11,18 @@ import (
 var modelRegistry = map[string]*types.ModelInfo{
 	// Gemini 1.5 Models
 	"gemini-1.5-pro": {
-		Name:              "gemini-1.5-pro",
-		Provider:          "vertex",
-		ContextWindow:     1048576, // 1M tokens
-		MaxOutputTokens:   8192,
-		InputCostPer1M:    1.25,
-		OutputCostPer1M:   5.00,
-		SupportsVision:    true,
-		SupportsFunctions: true,
-		SupportsJSON:      true,
-		SupportsStreaming: true,
+		Name:                      "gemini-1.5-pro",
+		Provider:                  "vertex",
+		ContextWindow:             1048576, // 1M tokens
+		MaxOutputTokens:           8192,
+		InputCostPer1M:            1.25,
+		OutputCostPer1M:           5.00,
+		CachedInputCostPer1M:      0.3125,
+		CacheStorageCostPer1MHour: 4.50,
+		SupportsVision:            true,
+		SupportsFunctions:         true,
+		SupportsJSON:              true,
+		SupportsStreaming:         true,
 		Capabilities: types.Capabilities{
 			Completion:      true,
 			Streaming:       true,
@@ -30,16 +32,18 @@ var modelRegistry = map[string]*types.ModelInfo{
 		},
 	},
 	"gemini-1.5-flash": {
-		Name:              "gemini-1.5-flash",
-		Provider:          "vertex",
-		ContextWindow:     1048576,
-		MaxOutputTokens:   8192,
-		InputCostPer1M:    0.075,
-		OutputCostPer1M:   0.30,
-		SupportsVision:    true,
-		SupportsFunctions: true,
-		SupportsJSON:      true,
-		SupportsStreaming: true,
+		Name:                      "gemini-1.5-flash",
+		Provider:                  "vertex",
+		ContextWindow:             1048576,
+		MaxOutputTokens:           8192,
+		InputCostPer1M:            0.075,
+		OutputCostPer1M:           0.30,
+		CachedInputCostPer1M:      0.01875,
+		CacheStorageCostPer1MHour: 1.00,
+		SupportsVision:            true,
+		SupportsFunctions:         true,
+		SupportsJSON:              true,
+		SupportsStreaming:         true,
 		Capabilities: types.Capabilities{
 			Completion:      true,
 			Streaming:       true,
@@ -1,7 +1,6 @@
 package vertex
 
 import (
-	"bufio"
 	"bytes"
 	"context"
 	"encoding/json"
@@ -11,6 +10,7 @@ import (
 	"strings"
 
 	"github.com/blue-context/warp"
+	"github.com/blue-context/warp/sse"
 )
 
 // CompletionStream sends a streaming chat completion request to Vertex AI.
@@ -55,7 +55,7 @@ func (p *Provider) CompletionStream(ctx context.Context, req *warp.CompletionReq
 	}
 
 	// Get OAuth2 access token
-	token, err := p.tokenProvider.GetToken()
+	token, err := p.tokenProvider.GetTokenContext(ctx)
 	if err != nil {
 		return nil, &warp.WarpError{
 			Message:       fmt.Sprintf("failed to get access token: %v", err),
@@ -76,6 +76,7 @@ func (p *Provider) CompletionStream(ctx context.Context, req *warp.CompletionReq
 			OriginalError: err,
 		}
 	}
+	vertexReq.CachedContent = p.cachedContentName(vertexReq.CachedContent)
 
 	// Marshal request body
 	body, err := json.Marshal(vertexReq)
@@ -101,6 +102,9 @@ func (p *Provider) CompletionStream(ctx context.Context, req *warp.CompletionReq
 	httpReq.Header.Set("Content-Type", "application/json")
 	httpReq.Header.Set("Authorization", "Bearer "+token)
 
+	// Add User-Agent and client request headers
+	warp.SetRequestHeaders(httpReq)
+
 	// Send request
 	httpResp, err := p.httpClient.Do(httpReq)
 	if err != nil {
@@ -110,6 +114,7 @@ func (p *Provider) CompletionStream(ctx context.Context, req *warp.CompletionReq
 			OriginalError: err,
 		}
 	}
+	warp.RecordResponse(httpResp)
 
 	// Check HTTP status before starting stream
 	if httpResp.StatusCode != http.StatusOK {
@@ -122,7 +127,7 @@ func (p *Provider) CompletionStream(ctx context.Context, req *warp.CompletionReq
 	return &vertexStream{
 		model:    req.Model,
 		response: httpResp,
-		reader:   bufio.NewReader(httpResp.Body),
+		reader:   sse.NewReader(httpResp.Body, sse.WithBufferReuse()),
 	}, nil
 }
 
@@ -139,7 +144,7 @@ func (p *Provider) CompletionStream(ctx context.Context, req *warp.CompletionReq
 type vertexStream struct {
 	model    string
 	response *http.Response
-	reader   *bufio.Reader
+	reader   *sse.Reader
 	err      error
 	closed   bool
 }
@@ -157,8 +162,8 @@ func (s *vertexStream) Recv() (*warp.CompletionChunk, error) {
 	}
 
 	for {
-		// Read next line
-		line, err := s.reader.ReadBytes('\n')
+		// Read next event
+		event, err := s.reader.Next()
 		if err != nil {
 			if err == io.EOF {
 				s.err = io.EOF
@@ -168,44 +173,28 @@ func (s *vertexStream) Recv() (*warp.CompletionChunk, error) {
 			return nil, s.err
 		}
 
-		// Trim whitespace
-		line = bytes.TrimSpace(line)
-
-		// Skip empty lines
-		if len(line) == 0 {
+		// Skip events without a JSON payload
+		jsonData := event.Data
+		if len(jsonData) == 0 {
 			continue
 		}
 
-		// Parse SSE line
-		// Expected format: "data: {...}"
-		if !bytes.HasPrefix(line, []byte("data: ")) {
-			// Skip non-data lines (e.g., event: message)
-			continue
-		}
-
-		// Extract JSON payload (after "data: " prefix)
-		jsonData := bytes.TrimPrefix(line, []byte("data: "))
-
 		// Skip special SSE messages
-		if bytes.Equal(jsonData, []byte("[DONE]")) {
+		if event.Done() {
 			s.err = io.EOF
 			return nil, io.EOF
 		}
 
 		// Parse Vertex AI response chunk
 		var vertexResp vertexResponse
-		if err := json.Unmarshal(jsonData, &vertexResp); err != nil {
+		if err := s.reader.Decode(&vertexResp); err != nil {
 			s.err = fmt.Errorf("failed to parse stream chunk: %w", err)
 			return nil, s.err
 		}
 
 		// Check for prompt feedback (blocking/safety issues)
-		if vertexResp.PromptFeedback != nil && vertexResp.PromptFeedback.BlockReason != "" {
-			s.err = warp.NewContentPolicyViolationError(
-				fmt.Sprintf("prompt blocked: %s", vertexResp.PromptFeedback.BlockReason),
-				"vertex",
-				nil,
-			)
+		if err := promptBlockedError(&vertexResp); err != nil {
+			s.err = err
 			return nil, s.err
 		}
 
@@ -233,11 +222,13 @@ func (s *vertexStream) Close() error {
 
 	s.closed = true
 
+	var err error
 	if s.response != nil && s.response.Body != nil {
-		return s.response.Body.Close()
+		err = s.response.Body.Close()
 	}
+	s.reader.Release()
 
-	return nil
+	return err
 }
 
 // transformStreamChunk converts a Vertex AI streaming response to a CompletionChunk.
@@ -268,11 +259,7 @@ func transformStreamChunk(vResp *vertexResponse, model string) *warp.CompletionC
 
 	// Add usage metadata in final chunk (if present)
 	if vResp.UsageMetadata != nil {
-		chunk.Usage = &warp.Usage{
-			PromptTokens:     vResp.UsageMetadata.PromptTokenCount,
-			CompletionTokens: vResp.UsageMetadata.CandidatesTokenCount,
-			TotalTokens:      vResp.UsageMetadata.TotalTokenCount,
-		}
+		chunk.Usage = transformUsage(vResp.UsageMetadata)
 	}
 
 	return chunk
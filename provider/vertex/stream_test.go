@@ -1,7 +1,6 @@
 package vertex
 
 import (
-	"bufio"
 	"bytes"
 	"context"
 	"encoding/json"
@@ -13,6 +12,7 @@ import (
 	"testing"
 
 	"github.com/blue-context/warp"
+	"github.com/blue-context/warp/sse"
 )
 
 func TestProvider_CompletionStream(t *testing.T) {
@@ -315,7 +315,7 @@ func TestVertexStream_Close(t *testing.T) {
 	stream := &vertexStream{
 		model:    "gemini-pro",
 		response: resp,
-		reader:   bufio.NewReader(bytes.NewReader([]byte{})),
+		reader:   sse.NewReader(bytes.NewReader([]byte{})),
 	}
 
 	// Close once
@@ -335,7 +335,7 @@ func TestVertexStream_Recv_EOF(t *testing.T) {
 	// Create stream with empty body (immediate EOF)
 	stream := &vertexStream{
 		model:  "gemini-pro",
-		reader: bufio.NewReader(bytes.NewReader([]byte{})),
+		reader: sse.NewReader(bytes.NewReader([]byte{})),
 	}
 
 	_, err := stream.Recv()
@@ -354,7 +354,7 @@ func TestVertexStream_Recv_InvalidJSON(t *testing.T) {
 	// Create stream with invalid JSON
 	stream := &vertexStream{
 		model:  "gemini-pro",
-		reader: bufio.NewReader(bytes.NewReader([]byte("data: {invalid json}\n\n"))),
+		reader: sse.NewReader(bytes.NewReader([]byte("data: {invalid json}\n\n"))),
 	}
 
 	_, err := stream.Recv()
@@ -386,7 +386,7 @@ func TestVertexStream_Recv_SkipEmptyLines(t *testing.T) {
 
 	stream := &vertexStream{
 		model:  "gemini-pro",
-		reader: bufio.NewReader(bytes.NewReader([]byte(data))),
+		reader: sse.NewReader(bytes.NewReader([]byte(data))),
 	}
 
 	chunk1, err := stream.Recv()
@@ -422,7 +422,7 @@ func TestVertexStream_Recv_SkipNonDataLines(t *testing.T) {
 
 	stream := &vertexStream{
 		model:  "gemini-pro",
-		reader: bufio.NewReader(bytes.NewReader([]byte(data))),
+		reader: sse.NewReader(bytes.NewReader([]byte(data))),
 	}
 
 	chunk1, err := stream.Recv()
@@ -441,7 +441,7 @@ func TestVertexStream_Recv_DoneMessage(t *testing.T) {
 
 	stream := &vertexStream{
 		model:  "gemini-pro",
-		reader: bufio.NewReader(bytes.NewReader([]byte(data))),
+		reader: sse.NewReader(bytes.NewReader([]byte(data))),
 	}
 
 	_, err := stream.Recv()
@@ -474,7 +474,7 @@ func TestVertexStream_Recv_EmptyCandidates(t *testing.T) {
 
 	stream := &vertexStream{
 		model:  "gemini-pro",
-		reader: bufio.NewReader(bytes.NewReader([]byte(data))),
+		reader: sse.NewReader(bytes.NewReader([]byte(data))),
 	}
 
 	// Should skip empty chunk and return second chunk
@@ -16,10 +16,12 @@ import (
 // - Different role naming ("user" and "model" instead of "assistant")
 // - Generation config as separate object
 type vertexRequest struct {
-	Contents         []vertexContent         `json:"contents"`
-	GenerationConfig *vertexGenerationConfig `json:"generationConfig,omitempty"`
-	SafetySettings   []vertexSafetySetting   `json:"safetySettings,omitempty"`
-	Tools            []vertexTool            `json:"tools,omitempty"`
+	Contents          []vertexContent         `json:"contents"`
+	SystemInstruction *vertexContent          `json:"systemInstruction,omitempty"`
+	GenerationConfig  *vertexGenerationConfig `json:"generationConfig,omitempty"`
+	SafetySettings    []vertexSafetySetting   `json:"safetySettings,omitempty"`
+	Tools             []vertexTool            `json:"tools,omitempty"`
+	CachedContent     string                  `json:"cachedContent,omitempty"`
 }
 
 // vertexContent represents a message in Vertex AI format.
@@ -107,6 +109,7 @@ type vertexPromptFeedback struct {
 type vertexSafetyRating struct {
 	Category    string `json:"category"`
 	Probability string `json:"probability"`
+	Severity    string `json:"severity,omitempty"`
 	Blocked     bool   `json:"blocked,omitempty"`
 }
 
@@ -115,6 +118,10 @@ type vertexUsageMetadata struct {
 	PromptTokenCount     int `json:"promptTokenCount"`
 	CandidatesTokenCount int `json:"candidatesTokenCount"`
 	TotalTokenCount      int `json:"totalTokenCount"`
+
+	// CachedContentTokenCount is the part of the prompt read from cached
+	// content.
+	CachedContentTokenCount int `json:"cachedContentTokenCount,omitempty"`
 }
 
 // transformRequest converts a Warp CompletionRequest to Vertex AI format.
@@ -123,9 +130,11 @@ type vertexUsageMetadata struct {
 //   - messages -> contents (with role mapping)
 //   - message content -> parts array
 //   - "assistant" role -> "model"
-//   - "system" messages -> prepended to first user message
+//   - "system" messages -> systemInstruction (concatenated)
 //   - temperature, maxTokens, etc. -> generationConfig
 //   - tools -> functionDeclarations
+//   - cached content -> cachedContent (expanded by the caller, which knows
+//     the project and location)
 func transformRequest(req *warp.CompletionRequest) (*vertexRequest, error) {
 	if req == nil {
 		return nil, fmt.Errorf("request cannot be nil")
@@ -145,7 +154,7 @@ func transformRequest(req *warp.CompletionRequest) (*vertexRequest, error) {
 		// Handle system messages separately
 		if msg.Role == "system" {
 			// Collect system message content
-			content := extractTextContent(msg.Content)
+			content := warp.ContentToString(msg)
 			if systemPrompt != "" {
 				systemPrompt += "\n\n"
 			}
@@ -161,20 +170,11 @@ func transformRequest(req *warp.CompletionRequest) (*vertexRequest, error) {
 		vReq.Contents = append(vReq.Contents, content)
 	}
 
-	// Prepend system prompt to first user message if present
-	if systemPrompt != "" && len(vReq.Contents) > 0 {
-		// Find first user message
-		for i := range vReq.Contents {
-			if vReq.Contents[i].Role == "user" {
-				// Prepend system prompt to first part
-				if len(vReq.Contents[i].Parts) > 0 {
-					originalText := vReq.Contents[i].Parts[0].Text
-					vReq.Contents[i].Parts[0].Text = systemPrompt + "\n\n" + originalText
-				} else {
-					vReq.Contents[i].Parts = append([]vertexPart{{Text: systemPrompt}}, vReq.Contents[i].Parts...)
-				}
-				break
-			}
+	// Map system messages to the system instruction
+	if systemPrompt != "" {
+		vReq.SystemInstruction = &vertexContent{
+			Role:  "system",
+			Parts: []vertexPart{{Text: systemPrompt}},
 		}
 	}
 
@@ -196,9 +196,47 @@ func transformRequest(req *warp.CompletionRequest) (*vertexRequest, error) {
 		vReq.Tools = transformTools(req.Tools)
 	}
 
+	vReq.SafetySettings = transformSafetySettings(req.SafetySettings)
+	vReq.CachedContent = req.CachedContent
+
 	return vReq, nil
 }
 
+// vertexHarmCategories maps harm categories to Gemini categories. Gemini
+// has no self-harm or violence category.
+var vertexHarmCategories = map[warp.HarmCategory]string{
+	warp.HarmHarassment: "HARM_CATEGORY_HARASSMENT",
+	warp.HarmHate:       "HARM_CATEGORY_HATE_SPEECH",
+	warp.HarmSexual:     "HARM_CATEGORY_SEXUALLY_EXPLICIT",
+	warp.HarmDangerous:  "HARM_CATEGORY_DANGEROUS_CONTENT",
+}
+
+// vertexThresholds maps safety thresholds to Gemini block thresholds.
+var vertexThresholds = map[warp.SafetyThreshold]string{
+	warp.SafetyBlockNone:   "BLOCK_NONE",
+	warp.SafetyBlockHigh:   "BLOCK_ONLY_HIGH",
+	warp.SafetyBlockMedium: "BLOCK_MEDIUM_AND_ABOVE",
+	warp.SafetyBlockLow:    "BLOCK_LOW_AND_ABOVE",
+}
+
+// transformSafetySettings converts safety settings to Gemini safety
+// settings, skipping categories and thresholds Gemini does not have.
+func transformSafetySettings(settings warp.SafetySettings) []vertexSafetySetting {
+	var result []vertexSafetySetting
+	for _, category := range settings.Categories() {
+		vCategory, ok := vertexHarmCategories[category]
+		if !ok {
+			continue
+		}
+		threshold, ok := vertexThresholds[settings[category]]
+		if !ok {
+			continue
+		}
+		result = append(result, vertexSafetySetting{Category: vCategory, Threshold: threshold})
+	}
+	return result
+}
+
 // transformMessage converts a single message to Vertex content.
 func transformMessage(msg warp.Message) (vertexContent, error) {
 	content := vertexContent{
@@ -409,19 +447,91 @@ func transformResponse(vResp *vertexResponse, model string) (*warp.CompletionRes
 		}
 		resp.Choices = append(resp.Choices, choice)
 	}
+	if len(vResp.Candidates) > 0 {
+		warp.SetNativeFinishReason(resp, vResp.Candidates[0].FinishReason)
+	}
 
 	// Transform usage metadata
 	if vResp.UsageMetadata != nil {
-		resp.Usage = &warp.Usage{
-			PromptTokens:     vResp.UsageMetadata.PromptTokenCount,
-			CompletionTokens: vResp.UsageMetadata.CandidatesTokenCount,
-			TotalTokens:      vResp.UsageMetadata.TotalTokenCount,
-		}
+		resp.Usage = transformUsage(vResp.UsageMetadata)
 	}
 
 	return resp, nil
 }
 
+// transformUsage converts Vertex usage metadata to usage. Tokens read from
+// cached content are reported as cached prompt tokens.
+func transformUsage(meta *vertexUsageMetadata) *warp.Usage {
+	usage := &warp.Usage{
+		PromptTokens:     meta.PromptTokenCount,
+		CompletionTokens: meta.CandidatesTokenCount,
+		TotalTokens:      meta.TotalTokenCount,
+	}
+	if meta.CachedContentTokenCount > 0 {
+		usage.PromptDetails = &warp.PromptTokensDetails{CachedTokens: meta.CachedContentTokenCount}
+	}
+	return usage
+}
+
+// promptBlockedError returns a *warp.ContentFilterError if the prompt was
+// blocked, and nil otherwise.
+func promptBlockedError(vResp *vertexResponse) error {
+	feedback := vResp.PromptFeedback
+	if feedback == nil || feedback.BlockReason == "" {
+		return nil
+	}
+	return warp.NewContentFilterError(
+		fmt.Sprintf("prompt blocked: %s", feedback.BlockReason),
+		"vertex",
+		feedback.BlockReason,
+		true,
+		transformSafetyRatings(feedback.SafetyRatings),
+	)
+}
+
+// candidatesBlockedError returns a *warp.ContentFilterError if every
+// candidate was stopped by a safety filter before producing any content,
+// and nil otherwise.
+func candidatesBlockedError(vResp *vertexResponse) error {
+	if len(vResp.Candidates) == 0 {
+		return nil
+	}
+	for _, candidate := range vResp.Candidates {
+		if !warp.NormalizeFinishReason(candidate.FinishReason).IsFiltered() || len(candidate.Content.Parts) > 0 {
+			return nil
+		}
+	}
+	candidate := vResp.Candidates[0]
+	return warp.NewContentFilterError(
+		fmt.Sprintf("response blocked: %s", candidate.FinishReason),
+		"vertex",
+		candidate.FinishReason,
+		false,
+		transformSafetyRatings(candidate.SafetyRatings),
+	)
+}
+
+// transformSafetyRatings converts Vertex safety ratings to content filter
+// results, preferring the severity over the probability when both are set.
+func transformSafetyRatings(ratings []vertexSafetyRating) []warp.ContentFilterResult {
+	if len(ratings) == 0 {
+		return nil
+	}
+	results := make([]warp.ContentFilterResult, len(ratings))
+	for i, rating := range ratings {
+		severity := rating.Severity
+		if severity == "" {
+			severity = rating.Probability
+		}
+		results[i] = warp.ContentFilterResult{
+			Category: rating.Category,
+			Severity: severity,
+			Filtered: rating.Blocked,
+		}
+	}
+	return results
+}
+
 // transformContent converts Vertex content to Warp message.
 func transformContent(content vertexContent) warp.Message {
 	msg := warp.Message{
@@ -477,19 +587,11 @@ func inverseTransformRole(role string) string {
 }
 
 // transformFinishReason converts Vertex finish reason to OpenAI format.
+//
+// Safety-related reasons (SAFETY, RECITATION, BLOCKLIST, PROHIBITED_CONTENT,
+// SPII) map to "content_filter"; unrecognized reasons pass through.
 func transformFinishReason(reason string) string {
-	switch reason {
-	case "STOP":
-		return "stop"
-	case "MAX_TOKENS":
-		return "length"
-	case "SAFETY":
-		return "content_filter"
-	case "RECITATION":
-		return "content_filter"
-	default:
-		return reason
-	}
+	return string(warp.NormalizeFinishReason(reason))
 }
 
 // Helper functions
@@ -1,6 +1,8 @@
 package vertex
 
 import (
+	"errors"
+	"reflect"
 	"testing"
 
 	"github.com/blue-context/warp"
@@ -162,7 +164,7 @@ func TestTransformRequest(t *testing.T) {
 
 			// Verify basic structure
 			if len(vReq.Contents) == 0 && len(tt.req.Messages) > 0 {
-				// System-only messages are prepended, so this might be empty
+				// System-only messages map to the system instruction, so this might be empty
 				hasNonSystem := false
 				for _, msg := range tt.req.Messages {
 					if msg.Role != "system" {
@@ -178,6 +180,55 @@ func TestTransformRequest(t *testing.T) {
 	}
 }
 
+func TestTransformRequest_SystemInstruction(t *testing.T) {
+	vReq, err := transformRequest(&warp.CompletionRequest{
+		Model: "gemini-pro",
+		Messages: []warp.Message{
+			{Role: "system", Content: "You are helpful"},
+			{Role: "user", Content: "Hello"},
+			{Role: "system", Content: "Be brief"},
+		},
+	})
+	if err != nil {
+		t.Fatalf("transformRequest() error = %v", err)
+	}
+
+	if vReq.SystemInstruction == nil || len(vReq.SystemInstruction.Parts) != 1 {
+		t.Fatalf("SystemInstruction = %+v, want one part", vReq.SystemInstruction)
+	}
+	if got := vReq.SystemInstruction.Parts[0].Text; got != "You are helpful\n\nBe brief" {
+		t.Errorf("SystemInstruction text = %q, want both system messages joined", got)
+	}
+	if len(vReq.Contents) != 1 || vReq.Contents[0].Parts[0].Text != "Hello" {
+		t.Errorf("Contents = %+v, want user message unchanged", vReq.Contents)
+	}
+}
+
+func TestTransformRequest_SafetySettings(t *testing.T) {
+	vReq, err := transformRequest(&warp.CompletionRequest{
+		Model:    "gemini-pro",
+		Messages: []warp.Message{{Role: "user", Content: "Hello"}},
+		SafetySettings: warp.SafetySettings{
+			warp.HarmHate:       warp.SafetyBlockLow,
+			warp.HarmDangerous:  warp.SafetyBlockNone,
+			warp.HarmHarassment: warp.SafetyBlockHigh,
+			warp.HarmViolence:   warp.SafetyBlockMedium,
+		},
+	})
+	if err != nil {
+		t.Fatalf("transformRequest() error = %v", err)
+	}
+
+	want := []vertexSafetySetting{
+		{Category: "HARM_CATEGORY_DANGEROUS_CONTENT", Threshold: "BLOCK_NONE"},
+		{Category: "HARM_CATEGORY_HARASSMENT", Threshold: "BLOCK_ONLY_HIGH"},
+		{Category: "HARM_CATEGORY_HATE_SPEECH", Threshold: "BLOCK_LOW_AND_ABOVE"},
+	}
+	if !reflect.DeepEqual(vReq.SafetySettings, want) {
+		t.Errorf("SafetySettings = %+v, want %+v", vReq.SafetySettings, want)
+	}
+}
+
 func TestTransformResponse(t *testing.T) {
 	tests := []struct {
 		name     string
@@ -217,6 +268,9 @@ func TestTransformResponse(t *testing.T) {
 				if resp.Choices[0].FinishReason != "stop" {
 					t.Errorf("unexpected finish reason: %s", resp.Choices[0].FinishReason)
 				}
+				if resp.NativeFinishReason() != "STOP" {
+					t.Errorf("unexpected native finish reason: %s", resp.NativeFinishReason())
+				}
 				if resp.Usage == nil {
 					t.Error("usage is nil")
 				} else if resp.Usage.TotalTokens != 15 {
@@ -347,6 +401,8 @@ func TestTransformFinishReason(t *testing.T) {
 		{"MAX_TOKENS", "length"},
 		{"SAFETY", "content_filter"},
 		{"RECITATION", "content_filter"},
+		{"PROHIBITED_CONTENT", "content_filter"},
+		{"MALFORMED_FUNCTION_CALL", "error"},
 		{"UNKNOWN", "UNKNOWN"},
 	}
 
@@ -360,6 +416,49 @@ func TestTransformFinishReason(t *testing.T) {
 	}
 }
 
+func TestSafetyBlockErrors(t *testing.T) {
+	ratings := []vertexSafetyRating{
+		{Category: "HARM_CATEGORY_HARASSMENT", Probability: "NEGLIGIBLE"},
+		{Category: "HARM_CATEGORY_DANGEROUS_CONTENT", Probability: "HIGH", Severity: "HARM_SEVERITY_HIGH", Blocked: true},
+	}
+
+	err := promptBlockedError(&vertexResponse{PromptFeedback: &vertexPromptFeedback{BlockReason: "SAFETY", SafetyRatings: ratings}})
+	filtered, ok := err.(*warp.ContentFilterError)
+	if !ok {
+		t.Fatalf("promptBlockedError() = %v, want ContentFilterError", err)
+	}
+	if !filtered.Prompt || filtered.Reason != "SAFETY" {
+		t.Errorf("Prompt = %v, Reason = %q", filtered.Prompt, filtered.Reason)
+	}
+	if got := filtered.FilteredCategories(); len(got) != 1 || got[0] != "HARM_CATEGORY_DANGEROUS_CONTENT" {
+		t.Errorf("FilteredCategories() = %v", got)
+	}
+	if filtered.Results[1].Severity != "HARM_SEVERITY_HIGH" || filtered.Results[0].Severity != "NEGLIGIBLE" {
+		t.Errorf("Results = %+v", filtered.Results)
+	}
+	var policy *warp.ContentPolicyViolationError
+	if !errors.As(err, &policy) || policy.Provider != "vertex" {
+		t.Errorf("errors.As(%v, *ContentPolicyViolationError) = false, want prompt blocks to still match", err)
+	}
+
+	blocked := &vertexResponse{Candidates: []vertexCandidate{{FinishReason: "SAFETY", SafetyRatings: ratings}}}
+	err = candidatesBlockedError(blocked)
+	if filtered, ok := err.(*warp.ContentFilterError); !ok || filtered.Prompt {
+		t.Errorf("candidatesBlockedError() = %v, want output ContentFilterError", err)
+	}
+
+	partial := &vertexResponse{Candidates: []vertexCandidate{{
+		Content:      vertexContent{Role: "model", Parts: []vertexPart{{Text: "Once upon"}}},
+		FinishReason: "SAFETY",
+	}}}
+	if err := candidatesBlockedError(partial); err != nil {
+		t.Errorf("candidatesBlockedError() with partial output = %v, want nil", err)
+	}
+	if err := promptBlockedError(&vertexResponse{}); err != nil {
+		t.Errorf("promptBlockedError() without feedback = %v, want nil", err)
+	}
+}
+
 func TestParseDataURI(t *testing.T) {
 	tests := []struct {
 		name         string
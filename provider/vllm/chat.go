@@ -0,0 +1,193 @@
+package vllm
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/blue-context/warp"
+	"github.com/blue-context/warp/sse"
+)
+
+// WithChatCompletions routes completions through vLLM's OpenAI-compatible
+// /v1/chat/completions endpoint instead of the native generate endpoint.
+//
+// The chat endpoint applies the model's own chat template server-side and
+// supports tool calling, tool_choice, and response_format. It requires
+// vLLM's OpenAI-compatible server (vllm serve). When enabled, Supports()
+// reports FunctionCalling.
+//
+// Example:
+//
+//	provider, err := vllm.NewProvider(
+//	    vllm.WithBaseURL("http://localhost:8000"),
+//	    vllm.WithChatCompletions(true),
+//	)
+func WithChatCompletions(enabled bool) Option {
+	return func(p *Provider) {
+		p.chatAPI = enabled
+	}
+}
+
+// transformToChatRequest transforms a Warp request to vLLM's
+// OpenAI-compatible chat completions format.
+//
+// Messages are passed through unchanged so vLLM can apply the model's chat
+// template. The stream parameter is passed explicitly by caller.
+func transformToChatRequest(req *warp.CompletionRequest, stream bool) map[string]any {
+	chatReq := map[string]any{
+		"model":    req.Model,
+		"messages": req.Messages,
+	}
+
+	if stream {
+		chatReq["stream"] = true
+		// Ask for usage in the final chunk so callers get token counts
+		chatReq["stream_options"] = map[string]any{"include_usage": true}
+	}
+	if req.Temperature != nil {
+		chatReq["temperature"] = *req.Temperature
+	}
+	if req.MaxTokens != nil {
+		chatReq["max_tokens"] = *req.MaxTokens
+	}
+	if req.TopP != nil {
+		chatReq["top_p"] = *req.TopP
+	}
+	if req.FrequencyPenalty != nil {
+		chatReq["frequency_penalty"] = *req.FrequencyPenalty
+	}
+	if req.PresencePenalty != nil {
+		chatReq["presence_penalty"] = *req.PresencePenalty
+	}
+	if len(req.Stop) > 0 {
+		chatReq["stop"] = req.Stop
+	}
+	if req.N != nil && *req.N > 1 {
+		chatReq["n"] = *req.N
+	}
+
+	if req.PromptLogprobs {
+		chatReq["prompt_logprobs"] = 0
+	}
+
+	// Function calling
+	if len(req.Tools) > 0 {
+		chatReq["tools"] = req.Tools
+	}
+	if req.ToolChoice != nil {
+		chatReq["tool_choice"] = req.ToolChoice
+	}
+
+	// Structured output: json_schema is sent as guided_json
+	guided := guidedParams(req)
+	if _, ok := guided[guidedJSON]; !ok && req.ResponseFormat != nil {
+		chatReq["response_format"] = req.ResponseFormat
+	}
+	for k, v := range guided {
+		chatReq[k] = v
+	}
+
+	// Provider-specific fields (e.g., repetition_penalty, best_of)
+	for k, v := range req.ExtraBody {
+		chatReq[k] = v
+	}
+
+	return chatReq
+}
+
+// chatHTTPRequest builds a POST request to the chat completions endpoint.
+func (p *Provider) chatHTTPRequest(ctx context.Context, chatReq map[string]any) (*http.Request, error) {
+	body, err := json.Marshal(chatReq)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	httpReq, err := http.NewRequestWithContext(ctx, "POST", p.baseURL+"/v1/chat/completions", bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	httpReq.Header.Set("Content-Type", "application/json")
+	if p.apiKey != "" {
+		httpReq.Header.Set("Authorization", "Bearer "+p.apiKey)
+	}
+
+	return httpReq, nil
+}
+
+// chatCompletion sends a completion request to /v1/chat/completions.
+func (p *Provider) chatCompletion(ctx context.Context, req *warp.CompletionRequest) (*warp.CompletionResponse, error) {
+	httpReq, err := p.chatHTTPRequest(ctx, transformToChatRequest(req, false))
+	if err != nil {
+		return nil, err
+	}
+
+	// Add User-Agent and client request headers
+	warp.SetRequestHeaders(httpReq)
+
+	httpResp, err := p.httpClient.Do(httpReq)
+	if err != nil {
+		return nil, fmt.Errorf("failed to send request: %w", err)
+	}
+	warp.RecordResponse(httpResp)
+	defer httpResp.Body.Close()
+
+	if httpResp.StatusCode != http.StatusOK {
+		body, err := io.ReadAll(httpResp.Body)
+		if err != nil {
+			body = []byte("failed to read error response")
+		}
+		return nil, warp.ParseProviderError("vllm", httpResp.StatusCode, body, nil)
+	}
+
+	// The chat endpoint returns OpenAI chat completion format directly
+	var resp warp.CompletionResponse
+	if err := json.NewDecoder(httpResp.Body).Decode(&resp); err != nil {
+		return nil, fmt.Errorf("failed to decode response: %w", err)
+	}
+
+	if resp.Usage == nil && len(resp.Choices) > 0 {
+		text, _ := resp.Choices[0].Message.Content.(string)
+		resp.Usage = estimateTokenUsage(req, text)
+	}
+
+	return &resp, nil
+}
+
+// chatCompletionStream sends a streaming request to /v1/chat/completions.
+func (p *Provider) chatCompletionStream(ctx context.Context, req *warp.CompletionRequest) (warp.Stream, error) {
+	httpReq, err := p.chatHTTPRequest(ctx, transformToChatRequest(req, true))
+	if err != nil {
+		return nil, err
+	}
+	httpReq.Header.Set("Accept", "text/event-stream")
+
+	// Add User-Agent and client request headers
+	warp.SetRequestHeaders(httpReq)
+
+	httpResp, err := p.httpClient.Do(httpReq)
+	if err != nil {
+		return nil, fmt.Errorf("failed to send request: %w", err)
+	}
+	warp.RecordResponse(httpResp)
+
+	if httpResp.StatusCode != http.StatusOK {
+		defer httpResp.Body.Close()
+		body, err := io.ReadAll(httpResp.Body)
+		if err != nil {
+			body = []byte("failed to read error response")
+		}
+		return nil, warp.ParseProviderError("vllm", httpResp.StatusCode, body, nil)
+	}
+
+	return &vllmStream{
+		reader: sse.NewReader(httpResp.Body, sse.WithBufferReuse()),
+		closer: httpResp.Body,
+		ctx:    ctx,
+		chat:   true,
+	}, nil
+}
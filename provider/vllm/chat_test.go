@@ -0,0 +1,235 @@
+package vllm
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/blue-context/warp"
+	prov "github.com/blue-context/warp/provider"
+)
+
+// TestChatCompletion tests completions through /v1/chat/completions
+func TestChatCompletion(t *testing.T) {
+	var captured map[string]any
+	mockClient := &mockHTTPClient{
+		doFunc: func(req *http.Request) (*http.Response, error) {
+			if req.URL.Path != "/v1/chat/completions" {
+				t.Errorf("request path = %s, want /v1/chat/completions", req.URL.Path)
+			}
+			body, _ := io.ReadAll(req.Body)
+			_ = json.Unmarshal(body, &captured)
+
+			mockResp := `{
+				"id": "chatcmpl-1",
+				"object": "chat.completion",
+				"created": 1234567890,
+				"model": "meta-llama/Llama-3.1-8B-Instruct",
+				"choices": [{
+					"index": 0,
+					"message": {
+						"role": "assistant",
+						"content": null,
+						"tool_calls": [{"id": "call_1", "type": "function", "function": {"name": "get_weather", "arguments": "{\"city\":\"Paris\"}"}}]
+					},
+					"finish_reason": "tool_calls"
+				}],
+				"usage": {"prompt_tokens": 20, "completion_tokens": 10, "total_tokens": 30}
+			}`
+			return &http.Response{
+				StatusCode: http.StatusOK,
+				Body:       io.NopCloser(strings.NewReader(mockResp)),
+				Header:     make(http.Header),
+			}, nil
+		},
+	}
+
+	provider, err := NewProvider(WithHTTPClient(mockClient), WithChatCompletions(true))
+	if err != nil {
+		t.Fatalf("NewProvider() error = %v", err)
+	}
+
+	resp, err := provider.Completion(context.Background(), &warp.CompletionRequest{
+		Model: "meta-llama/Llama-3.1-8B-Instruct",
+		Messages: []warp.Message{
+			{Role: "system", Content: "You are helpful."},
+			{Role: "user", Content: "Weather in Paris?"},
+		},
+		Stop: []string{"</s>", "\n\n"},
+		Tools: []warp.Tool{{
+			Type:     "function",
+			Function: warp.Function{Name: "get_weather", Parameters: map[string]any{"type": "object"}},
+		}},
+		ToolChoice: &warp.ToolChoice{Type: "auto"},
+		ExtraBody:  map[string]any{"repetition_penalty": 1.1},
+	})
+	if err != nil {
+		t.Fatalf("Completion() error = %v", err)
+	}
+
+	// Messages are sent as-is so the server applies the chat template
+	if messages, _ := captured["messages"].([]any); len(messages) != 2 {
+		t.Errorf("messages = %v, want 2 entries", captured["messages"])
+	}
+	if _, ok := captured["prompt"]; ok {
+		t.Error("chat request should not contain a prompt")
+	}
+	if stop, _ := captured["stop"].([]any); len(stop) != 2 {
+		t.Errorf("stop = %v, want 2 entries", captured["stop"])
+	}
+	if tools, _ := captured["tools"].([]any); len(tools) != 1 {
+		t.Errorf("tools = %v, want 1 entry", captured["tools"])
+	}
+	if choice, _ := captured["tool_choice"].(map[string]any); choice["type"] != "auto" {
+		t.Errorf("tool_choice = %v, want auto", captured["tool_choice"])
+	}
+	if captured["repetition_penalty"] != 1.1 {
+		t.Errorf("repetition_penalty = %v, want 1.1", captured["repetition_penalty"])
+	}
+
+	if len(resp.Choices) != 1 || len(resp.Choices[0].Message.ToolCalls) != 1 {
+		t.Fatalf("tool calls = %+v, want 1", resp.Choices)
+	}
+	if got := resp.Choices[0].Message.ToolCalls[0].Function.Name; got != "get_weather" {
+		t.Errorf("tool call name = %q, want %q", got, "get_weather")
+	}
+	if resp.Choices[0].FinishReason != "tool_calls" {
+		t.Errorf("FinishReason = %q, want %q", resp.Choices[0].FinishReason, "tool_calls")
+	}
+	if resp.Usage == nil || resp.Usage.TotalTokens != 30 {
+		t.Errorf("Usage = %+v, want 30 total tokens", resp.Usage)
+	}
+}
+
+// TestChatCompletionStream tests streaming through /v1/chat/completions
+func TestChatCompletionStream(t *testing.T) {
+	mockStreamData := `data: {"id":"chatcmpl-1","object":"chat.completion.chunk","created":1234567890,"model":"m","choices":[{"index":0,"delta":{"role":"assistant","content":"Hello"},"finish_reason":null}]}
+
+data: {"id":"chatcmpl-1","object":"chat.completion.chunk","created":1234567890,"model":"m","choices":[{"index":0,"delta":{"content":"!"},"finish_reason":"stop"}]}
+
+data: {"id":"chatcmpl-1","object":"chat.completion.chunk","created":1234567890,"model":"m","choices":[],"usage":{"prompt_tokens":5,"completion_tokens":2,"total_tokens":7}}
+
+data: [DONE]
+`
+
+	var captured map[string]any
+	mockClient := &mockHTTPClient{
+		doFunc: func(req *http.Request) (*http.Response, error) {
+			if req.URL.Path != "/v1/chat/completions" {
+				t.Errorf("request path = %s, want /v1/chat/completions", req.URL.Path)
+			}
+			body, _ := io.ReadAll(req.Body)
+			_ = json.Unmarshal(body, &captured)
+			return &http.Response{
+				StatusCode: http.StatusOK,
+				Body:       io.NopCloser(strings.NewReader(mockStreamData)),
+				Header:     make(http.Header),
+			}, nil
+		},
+	}
+
+	provider, err := NewProvider(WithHTTPClient(mockClient), WithChatCompletions(true))
+	if err != nil {
+		t.Fatalf("NewProvider() error = %v", err)
+	}
+
+	stream, err := provider.CompletionStream(context.Background(), &warp.CompletionRequest{
+		Model:    "m",
+		Messages: []warp.Message{{Role: "user", Content: "Hi"}},
+	})
+	if err != nil {
+		t.Fatalf("CompletionStream() error = %v", err)
+	}
+	defer stream.Close()
+
+	if captured["stream"] != true {
+		t.Errorf("stream = %v, want true", captured["stream"])
+	}
+
+	var content strings.Builder
+	var usage *warp.Usage
+	for {
+		chunk, err := stream.Recv()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatalf("Recv() error = %v", err)
+		}
+		for _, c := range chunk.Choices {
+			content.WriteString(c.Delta.Content)
+		}
+		if chunk.Usage != nil {
+			usage = chunk.Usage
+		}
+	}
+
+	if content.String() != "Hello!" {
+		t.Errorf("content = %q, want %q", content.String(), "Hello!")
+	}
+	if usage == nil || usage.TotalTokens != 7 {
+		t.Errorf("usage = %+v, want 7 total tokens", usage)
+	}
+}
+
+// TestChatCompletionsSupportsFunctionCalling tests that the chat endpoint enables tools
+func TestChatCompletionsSupportsFunctionCalling(t *testing.T) {
+	native, _ := NewProvider()
+	if native.Supports().(prov.Capabilities).FunctionCalling {
+		t.Error("native endpoint should not report FunctionCalling")
+	}
+
+	chat, _ := NewProvider(WithChatCompletions(true))
+	if !chat.Supports().(prov.Capabilities).FunctionCalling {
+		t.Error("chat endpoint should report FunctionCalling")
+	}
+}
+
+// TestPromptLogprobs tests prompt logprobs on the native and chat endpoints
+func TestPromptLogprobs(t *testing.T) {
+	logprobs := `[null, {"9906": {"logprob": -3.5, "rank": 12, "decoded_token": " there"}}]`
+	for _, chat := range []bool{false, true} {
+		var captured map[string]any
+		mockClient := &mockHTTPClient{
+			doFunc: func(req *http.Request) (*http.Response, error) {
+				body, _ := io.ReadAll(req.Body)
+				_ = json.Unmarshal(body, &captured)
+
+				// The completions format reports prompt logprobs per choice,
+				// the chat format once per response
+				mockResp := `{"id": "1", "choices": [{"index": 0, "text": "!", "prompt_logprobs": ` + logprobs + `, "finish_reason": "length"}]}`
+				if chat {
+					mockResp = `{"id": "1", "choices": [{"index": 0, "message": {"role": "assistant", "content": "!"}, "finish_reason": "length"}], "prompt_logprobs": ` + logprobs + `}`
+				}
+				return &http.Response{
+					StatusCode: http.StatusOK,
+					Body:       io.NopCloser(strings.NewReader(mockResp)),
+					Header:     make(http.Header),
+				}, nil
+			},
+		}
+
+		provider, err := NewProvider(WithHTTPClient(mockClient), WithChatCompletions(chat))
+		if err != nil {
+			t.Fatalf("NewProvider() error = %v", err)
+		}
+		resp, err := provider.Completion(context.Background(), &warp.CompletionRequest{
+			Model:          "meta-llama/Llama-3.1-8B",
+			Messages:       []warp.Message{{Role: "user", Content: "Hi there"}},
+			PromptLogprobs: true,
+		})
+		if err != nil {
+			t.Fatalf("Completion() chat=%v error = %v", chat, err)
+		}
+
+		if captured["prompt_logprobs"] != 0.0 {
+			t.Errorf("chat=%v: prompt_logprobs = %v, want 0", chat, captured["prompt_logprobs"])
+		}
+		if got := resp.PromptLogprobs; len(got) != 1 || got[0].Token != " there" || got[0].Logprob != -3.5 {
+			t.Errorf("chat=%v: PromptLogprobs = %+v, want \" there\" at -3.5", chat, got)
+		}
+	}
+}
@@ -20,7 +20,8 @@ import (
 // - Error parsing and classification
 // - Response transformation to Warp format
 //
-// Uses vLLM's native /inference/v1/generate endpoint.
+// Uses vLLM's native /inference/v1/generate endpoint, or the OpenAI-compatible
+// /v1/chat/completions endpoint when WithChatCompletions is set.
 //
 // Example:
 //
@@ -36,6 +37,10 @@ func (p *Provider) Completion(ctx context.Context, req *warp.CompletionRequest)
 		return nil, err
 	}
 
+	if p.chatAPI {
+		return p.chatCompletion(ctx, req)
+	}
+
 	// Transform request to vLLM format (non-streaming)
 	vllmReq := transformToVLLMRequest(req, false)
 
@@ -59,11 +64,15 @@ func (p *Provider) Completion(ctx context.Context, req *warp.CompletionRequest)
 		httpReq.Header.Set("Authorization", "Bearer "+p.apiKey)
 	}
 
+	// Add User-Agent and client request headers
+	warp.SetRequestHeaders(httpReq)
+
 	// Send request
 	httpResp, err := p.httpClient.Do(httpReq)
 	if err != nil {
 		return nil, fmt.Errorf("failed to send request: %w", err)
 	}
+	warp.RecordResponse(httpResp)
 	defer httpResp.Body.Close()
 
 	// Check status code
@@ -149,11 +158,15 @@ func (p *Provider) Embedding(ctx context.Context, req *warp.EmbeddingRequest) (*
 		httpReq.Header.Set("Authorization", "Bearer "+p.apiKey)
 	}
 
+	// Add User-Agent and client request headers
+	warp.SetRequestHeaders(httpReq)
+
 	// Send request
 	httpResp, err := p.httpClient.Do(httpReq)
 	if err != nil {
 		return nil, fmt.Errorf("failed to send request: %w", err)
 	}
+	warp.RecordResponse(httpResp)
 	defer httpResp.Body.Close()
 
 	// Check status code
@@ -261,11 +274,15 @@ func (p *Provider) Rerank(ctx context.Context, req *warp.RerankRequest) (*warp.R
 		httpReq.Header.Set("Authorization", "Bearer "+p.apiKey)
 	}
 
+	// Add User-Agent and client request headers
+	warp.SetRequestHeaders(httpReq)
+
 	// Send request
 	httpResp, err := p.httpClient.Do(httpReq)
 	if err != nil {
 		return nil, fmt.Errorf("failed to send request: %w", err)
 	}
+	warp.RecordResponse(httpResp)
 	defer httpResp.Body.Close()
 
 	// Check status code
@@ -0,0 +1,108 @@
+package vllm
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/blue-context/warp"
+	"github.com/blue-context/warp/types"
+)
+
+// servedModel is a model entry from vLLM's /v1/models endpoint.
+type servedModel struct {
+	ID          string `json:"id"`
+	Root        string `json:"root"`
+	MaxModelLen int    `json:"max_model_len"`
+}
+
+// RefreshModels discovers the models served by the vLLM server.
+//
+// Wraps GET /v1/models. After a successful refresh, ListModels returns the
+// served models and GetModelInfo reports each model's real context length
+// (max_model_len) instead of the compiled-in defaults. Served names that
+// match a registry entry, directly or via the root model, keep its metadata.
+//
+// Thread Safety: Safe for concurrent use with GetModelInfo and ListModels.
+//
+// Example:
+//
+//	if err := provider.RefreshModels(ctx); err != nil {
+//	    log.Printf("using compiled-in models: %v", err)
+//	}
+//	for _, m := range provider.ListModels() {
+//	    fmt.Println(m.Name, m.ContextWindow)
+//	}
+func (p *Provider) RefreshModels(ctx context.Context) error {
+	httpReq, err := http.NewRequestWithContext(ctx, "GET", p.baseURL+"/v1/models", nil)
+	if err != nil {
+		return fmt.Errorf("failed to create models request: %w", err)
+	}
+	if p.apiKey != "" {
+		httpReq.Header.Set("Authorization", "Bearer "+p.apiKey)
+	}
+
+	// Add User-Agent and client request headers
+	warp.SetRequestHeaders(httpReq)
+
+	httpResp, err := p.httpClient.Do(httpReq)
+	if err != nil {
+		return fmt.Errorf("failed to send models request: %w", err)
+	}
+	warp.RecordResponse(httpResp)
+	defer httpResp.Body.Close()
+
+	if httpResp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(httpResp.Body)
+		return warp.ParseProviderError("vllm", httpResp.StatusCode, body, nil)
+	}
+
+	var resp struct {
+		Data []servedModel `json:"data"`
+	}
+	if err := json.NewDecoder(httpResp.Body).Decode(&resp); err != nil {
+		return fmt.Errorf("failed to decode models response: %w", err)
+	}
+
+	live := make(map[string]*types.ModelInfo, len(resp.Data))
+	for _, m := range resp.Data {
+		if m.ID == "" {
+			continue
+		}
+		live[m.ID] = servedModelInfo(m)
+	}
+
+	p.modelsMu.Lock()
+	p.liveModels = live
+	p.modelsMu.Unlock()
+
+	return nil
+}
+
+// servedModelInfo builds a ModelInfo for a served model, starting from the
+// registry entry for its name or root model if one exists.
+func servedModelInfo(m servedModel) *types.ModelInfo {
+	static, ok := modelRegistry[m.ID]
+	if !ok {
+		static = modelRegistry[m.Root]
+	}
+
+	var info types.ModelInfo
+	if static != nil {
+		info = *static
+	} else {
+		info = *defaultModelInfo(m.ID)
+	}
+	info.Name = m.ID
+
+	if m.MaxModelLen > 0 {
+		info.ContextWindow = m.MaxModelLen
+		if info.MaxOutputTokens > m.MaxModelLen {
+			info.MaxOutputTokens = m.MaxModelLen
+		}
+	}
+
+	return &info
+}
@@ -0,0 +1,87 @@
+package vllm
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+// TestRefreshModels tests model discovery from /v1/models
+func TestRefreshModels(t *testing.T) {
+	mockClient := &mockHTTPClient{
+		doFunc: func(req *http.Request) (*http.Response, error) {
+			if req.URL.Path != "/v1/models" {
+				t.Errorf("request path = %s, want /v1/models", req.URL.Path)
+			}
+			mockResp := `{"object": "list", "data": [
+				{"id": "llama", "object": "model", "root": "meta-llama/Meta-Llama-3.1-8B-Instruct", "max_model_len": 32768},
+				{"id": "my-finetune", "object": "model", "root": "/models/my-finetune", "max_model_len": 2048}
+			]}`
+			return &http.Response{
+				StatusCode: http.StatusOK,
+				Body:       io.NopCloser(strings.NewReader(mockResp)),
+				Header:     make(http.Header),
+			}, nil
+		},
+	}
+
+	provider, err := NewProvider(WithHTTPClient(mockClient))
+	if err != nil {
+		t.Fatalf("NewProvider() error = %v", err)
+	}
+
+	if err := provider.RefreshModels(context.Background()); err != nil {
+		t.Fatalf("RefreshModels() error = %v", err)
+	}
+
+	models := provider.ListModels()
+	if len(models) != 2 {
+		t.Fatalf("ListModels() = %d models, want 2", len(models))
+	}
+	if models[0].Name != "llama" || models[1].Name != "my-finetune" {
+		t.Errorf("ListModels() names = %s, %s", models[0].Name, models[1].Name)
+	}
+
+	// Served alias keeps the root model's metadata with the served context length
+	llama := provider.GetModelInfo("llama")
+	if llama.ContextWindow != 32768 {
+		t.Errorf("llama ContextWindow = %d, want 32768", llama.ContextWindow)
+	}
+	if !llama.Capabilities.Completion {
+		t.Error("llama should keep registry capabilities")
+	}
+
+	// Unknown model gets defaults, capped to the served context length
+	custom := provider.GetModelInfo("my-finetune")
+	if custom.ContextWindow != 2048 || custom.MaxOutputTokens != 2048 {
+		t.Errorf("my-finetune limits = %d/%d, want 2048/2048", custom.ContextWindow, custom.MaxOutputTokens)
+	}
+}
+
+// TestRefreshModelsError tests that a failed refresh keeps the registry
+func TestRefreshModelsError(t *testing.T) {
+	mockClient := &mockHTTPClient{
+		doFunc: func(req *http.Request) (*http.Response, error) {
+			return &http.Response{
+				StatusCode: http.StatusNotFound,
+				Body:       io.NopCloser(strings.NewReader(`{"error": "not found"}`)),
+				Header:     make(http.Header),
+			}, nil
+		},
+	}
+
+	provider, err := NewProvider(WithHTTPClient(mockClient))
+	if err != nil {
+		t.Fatalf("NewProvider() error = %v", err)
+	}
+
+	staticCount := len(provider.ListModels())
+	if err := provider.RefreshModels(context.Background()); err == nil {
+		t.Error("RefreshModels() expected error")
+	}
+	if got := len(provider.ListModels()); got != staticCount {
+		t.Errorf("ListModels() = %d models, want %d", got, staticCount)
+	}
+}
@@ -0,0 +1,76 @@
+package vllm
+
+import "github.com/blue-context/warp"
+
+// vLLM guided decoding parameters.
+//
+// These constrain generation with vLLM's structured output backend
+// (outlines/xgrammar), so the model can only emit matching output.
+const (
+	guidedJSON    = "guided_json"
+	guidedRegex   = "guided_regex"
+	guidedChoice  = "guided_choice"
+	guidedGrammar = "guided_grammar"
+)
+
+// WithGuidedRegex constrains the completion to match a regular expression.
+//
+// The constraint is sent as vLLM's guided_regex parameter in req.ExtraBody.
+//
+// Example:
+//
+//	req := &warp.CompletionRequest{
+//	    Model:    "meta-llama/Llama-3.1-8B-Instruct",
+//	    Messages: []warp.Message{{Role: "user", Content: "Give me a US phone number"}},
+//	}
+//	vllm.WithGuidedRegex(req, `\(\d{3}\) \d{3}-\d{4}`)
+func WithGuidedRegex(req *warp.CompletionRequest, pattern string) {
+	setExtra(req, guidedRegex, pattern)
+}
+
+// WithGuidedChoice constrains the completion to exactly one of choices.
+//
+// The constraint is sent as vLLM's guided_choice parameter in req.ExtraBody.
+//
+// Example:
+//
+//	vllm.WithGuidedChoice(req, "positive", "negative", "neutral")
+func WithGuidedChoice(req *warp.CompletionRequest, choices ...string) {
+	setExtra(req, guidedChoice, choices)
+}
+
+// WithGuidedGrammar constrains the completion to an EBNF grammar.
+//
+// The constraint is sent as vLLM's guided_grammar parameter in req.ExtraBody.
+func WithGuidedGrammar(req *warp.CompletionRequest, grammar string) {
+	setExtra(req, guidedGrammar, grammar)
+}
+
+// setExtra sets a key in req.ExtraBody, allocating the map if needed.
+func setExtra(req *warp.CompletionRequest, key string, value any) {
+	if req.ExtraBody == nil {
+		req.ExtraBody = make(map[string]any)
+	}
+	req.ExtraBody[key] = value
+}
+
+// guidedParams returns the guided decoding parameters for a request.
+//
+// A "json_schema" ResponseFormat maps to guided_json, giving local models the
+// same schema-constrained decoding as OpenAI strict mode. Guided parameters
+// set in req.ExtraBody take precedence.
+func guidedParams(req *warp.CompletionRequest) map[string]any {
+	params := make(map[string]any)
+
+	if rf := req.ResponseFormat; rf != nil && rf.Type == "json_schema" && rf.JSONSchema != nil && rf.JSONSchema.Schema != nil {
+		params[guidedJSON] = rf.JSONSchema.Schema
+	}
+
+	for _, key := range []string{guidedJSON, guidedRegex, guidedChoice, guidedGrammar} {
+		if v, ok := req.ExtraBody[key]; ok {
+			params[key] = v
+		}
+	}
+
+	return params
+}
@@ -0,0 +1,119 @@
+package vllm
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/blue-context/warp"
+)
+
+// guidedMockClient captures the request body and returns a minimal response
+// for either endpoint.
+func guidedMockClient(captured *map[string]any) *mockHTTPClient {
+	return &mockHTTPClient{
+		doFunc: func(req *http.Request) (*http.Response, error) {
+			body, _ := io.ReadAll(req.Body)
+			_ = json.Unmarshal(body, captured)
+
+			mockResp := `{"id":"1","choices":[{"index":0,"text":"{}","finish_reason":"stop"}]}`
+			if req.URL.Path == "/v1/chat/completions" {
+				mockResp = `{"id":"1","choices":[{"index":0,"message":{"role":"assistant","content":"{}"},"finish_reason":"stop"}]}`
+			}
+			return &http.Response{
+				StatusCode: http.StatusOK,
+				Body:       io.NopCloser(strings.NewReader(mockResp)),
+				Header:     make(http.Header),
+			}, nil
+		},
+	}
+}
+
+// TestGuidedDecoding tests that structured output maps to vLLM guided parameters
+func TestGuidedDecoding(t *testing.T) {
+	schema := map[string]any{
+		"type":       "object",
+		"properties": map[string]any{"city": map[string]any{"type": "string"}},
+	}
+
+	tests := []struct {
+		name       string
+		setup      func(req *warp.CompletionRequest)
+		wantKey    string
+		wantFormat bool
+	}{
+		{
+			name: "json schema",
+			setup: func(req *warp.CompletionRequest) {
+				req.ResponseFormat = &warp.ResponseFormat{
+					Type:       "json_schema",
+					JSONSchema: &warp.JSONSchema{Name: "city", Schema: schema},
+				}
+			},
+			wantKey: "guided_json",
+		},
+		{
+			name:    "regex",
+			setup:   func(req *warp.CompletionRequest) { WithGuidedRegex(req, `\d+`) },
+			wantKey: "guided_regex",
+		},
+		{
+			name:    "choice",
+			setup:   func(req *warp.CompletionRequest) { WithGuidedChoice(req, "yes", "no") },
+			wantKey: "guided_choice",
+		},
+		{
+			name:    "grammar",
+			setup:   func(req *warp.CompletionRequest) { WithGuidedGrammar(req, `root ::= "a"`) },
+			wantKey: "guided_grammar",
+		},
+		{
+			name: "json object keeps response_format",
+			setup: func(req *warp.CompletionRequest) {
+				req.ResponseFormat = &warp.ResponseFormat{Type: "json_object"}
+			},
+			wantFormat: true,
+		},
+	}
+
+	for _, tt := range tests {
+		for _, chat := range []bool{false, true} {
+			name := tt.name + "/native"
+			if chat {
+				name = tt.name + "/chat"
+			}
+			t.Run(name, func(t *testing.T) {
+				var captured map[string]any
+				provider, err := NewProvider(
+					WithHTTPClient(guidedMockClient(&captured)),
+					WithChatCompletions(chat),
+				)
+				if err != nil {
+					t.Fatalf("NewProvider() error = %v", err)
+				}
+
+				req := &warp.CompletionRequest{
+					Model:    "meta-llama/Llama-3.1-8B-Instruct",
+					Messages: []warp.Message{{Role: "user", Content: "Hi"}},
+				}
+				tt.setup(req)
+
+				if _, err := provider.Completion(context.Background(), req); err != nil {
+					t.Fatalf("Completion() error = %v", err)
+				}
+
+				if tt.wantKey != "" {
+					if _, ok := captured[tt.wantKey]; !ok {
+						t.Errorf("request missing %s: %v", tt.wantKey, captured)
+					}
+				}
+				if _, ok := captured["response_format"]; ok != tt.wantFormat {
+					t.Errorf("response_format present = %v, want %v", ok, tt.wantFormat)
+				}
+			})
+		}
+	}
+}
@@ -0,0 +1,123 @@
+package vllm
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/blue-context/warp"
+)
+
+// ServerMetrics is a snapshot of a vLLM server's load, scraped from /metrics.
+//
+// Use it to balance traffic across several vLLM replicas, e.g. by sending
+// requests to the replica with the fewest pending requests.
+type ServerMetrics struct {
+	// RequestsRunning is the number of requests currently being processed.
+	RequestsRunning int
+
+	// RequestsWaiting is the number of requests queued for processing.
+	RequestsWaiting int
+
+	// KVCacheUsage is the fraction of KV cache blocks in use (0 to 1).
+	KVCacheUsage float64
+}
+
+// PendingRequests returns the number of running and waiting requests.
+func (m *ServerMetrics) PendingRequests() int {
+	return m.RequestsRunning + m.RequestsWaiting
+}
+
+// Metrics scrapes the server's Prometheus /metrics endpoint.
+//
+// Request counts are summed across served models; KV cache usage is the
+// highest reported value. Both the vLLM V0 (gpu_cache_usage_perc) and V1
+// (kv_cache_usage_perc) cache gauges are recognized.
+//
+// Example:
+//
+//	m, err := provider.Metrics(ctx)
+//	if err != nil {
+//	    return err
+//	}
+//	fmt.Printf("pending=%d kv=%.0f%%\n", m.PendingRequests(), m.KVCacheUsage*100)
+func (p *Provider) Metrics(ctx context.Context) (*ServerMetrics, error) {
+	httpReq, err := http.NewRequestWithContext(ctx, "GET", p.baseURL+"/metrics", nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create metrics request: %w", err)
+	}
+	if p.apiKey != "" {
+		httpReq.Header.Set("Authorization", "Bearer "+p.apiKey)
+	}
+
+	// Add User-Agent and client request headers
+	warp.SetRequestHeaders(httpReq)
+
+	httpResp, err := p.httpClient.Do(httpReq)
+	if err != nil {
+		return nil, fmt.Errorf("failed to send metrics request: %w", err)
+	}
+	warp.RecordResponse(httpResp)
+	defer httpResp.Body.Close()
+
+	if httpResp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(httpResp.Body)
+		return nil, warp.ParseProviderError("vllm", httpResp.StatusCode, body, nil)
+	}
+
+	return parseMetrics(httpResp.Body)
+}
+
+// parseMetrics extracts load gauges from Prometheus text exposition format.
+func parseMetrics(r io.Reader) (*ServerMetrics, error) {
+	m := &ServerMetrics{}
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		// Sample lines are: name{labels} value [timestamp]
+		name := line
+		if i := strings.IndexAny(line, "{ "); i >= 0 {
+			name = line[:i]
+		}
+		rest := line[len(name):]
+		if strings.HasPrefix(rest, "{") {
+			end := strings.LastIndex(rest, "}")
+			if end < 0 {
+				continue
+			}
+			rest = rest[end+1:]
+		}
+		fields := strings.Fields(rest)
+		if len(fields) == 0 {
+			continue
+		}
+		value, err := strconv.ParseFloat(fields[0], 64)
+		if err != nil {
+			continue
+		}
+
+		switch name {
+		case "vllm:num_requests_running":
+			m.RequestsRunning += int(value)
+		case "vllm:num_requests_waiting":
+			m.RequestsWaiting += int(value)
+		case "vllm:gpu_cache_usage_perc", "vllm:kv_cache_usage_perc":
+			m.KVCacheUsage = max(m.KVCacheUsage, value)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read metrics: %w", err)
+	}
+
+	return m, nil
+}
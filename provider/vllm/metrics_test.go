@@ -0,0 +1,73 @@
+package vllm
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+const metricsResponse = `# HELP vllm:num_requests_running Number of requests in model execution batches.
+# TYPE vllm:num_requests_running gauge
+vllm:num_requests_running{engine="0",model_name="llama"} 3.0
+vllm:num_requests_running{engine="0",model_name="qwen"} 1.0
+# HELP vllm:num_requests_waiting Number of requests waiting to be processed.
+# TYPE vllm:num_requests_waiting gauge
+vllm:num_requests_waiting{engine="0",model_name="llama"} 5.0
+# HELP vllm:kv_cache_usage_perc KV-cache usage. 1 means 100 percent usage.
+# TYPE vllm:kv_cache_usage_perc gauge
+vllm:kv_cache_usage_perc{engine="0",model_name="llama"} 0.42
+vllm:kv_cache_usage_perc{engine="0",model_name="qwen"} 0.17
+process_cpu_seconds_total 12.5
+`
+
+// TestMetrics tests scraping load gauges from /metrics
+func TestMetrics(t *testing.T) {
+	mockClient := &mockHTTPClient{
+		doFunc: func(req *http.Request) (*http.Response, error) {
+			if req.URL.Path != "/metrics" {
+				t.Errorf("request path = %s, want /metrics", req.URL.Path)
+			}
+			return &http.Response{
+				StatusCode: http.StatusOK,
+				Body:       io.NopCloser(strings.NewReader(metricsResponse)),
+				Header:     make(http.Header),
+			}, nil
+		},
+	}
+
+	provider, err := NewProvider(WithHTTPClient(mockClient))
+	if err != nil {
+		t.Fatalf("NewProvider() error = %v", err)
+	}
+
+	m, err := provider.Metrics(context.Background())
+	if err != nil {
+		t.Fatalf("Metrics() error = %v", err)
+	}
+
+	if m.RequestsRunning != 4 {
+		t.Errorf("RequestsRunning = %d, want 4", m.RequestsRunning)
+	}
+	if m.RequestsWaiting != 5 {
+		t.Errorf("RequestsWaiting = %d, want 5", m.RequestsWaiting)
+	}
+	if m.PendingRequests() != 9 {
+		t.Errorf("PendingRequests() = %d, want 9", m.PendingRequests())
+	}
+	if m.KVCacheUsage != 0.42 {
+		t.Errorf("KVCacheUsage = %v, want 0.42", m.KVCacheUsage)
+	}
+}
+
+// TestParseMetricsV0 tests the vLLM V0 cache gauge name
+func TestParseMetricsV0(t *testing.T) {
+	m, err := parseMetrics(strings.NewReader("vllm:gpu_cache_usage_perc{model_name=\"x\"} 0.9\n"))
+	if err != nil {
+		t.Fatalf("parseMetrics() error = %v", err)
+	}
+	if m.KVCacheUsage != 0.9 {
+		t.Errorf("KVCacheUsage = %v, want 0.9", m.KVCacheUsage)
+	}
+}
@@ -208,13 +208,26 @@ var modelRegistry = map[string]*types.ModelInfo{
 // Returns nil if the model is unknown. For vLLM, since it's self-hosted,
 // users can run any model. This registry only contains common models.
 func (p *Provider) GetModelInfo(model string) *types.ModelInfo {
-	info, exists := modelRegistry[model]
+	// Models discovered from the server (RefreshModels) take precedence
+	p.modelsMu.RLock()
+	info, exists := p.liveModels[model]
+	p.modelsMu.RUnlock()
+	if exists {
+		return info
+	}
+
+	info, exists = modelRegistry[model]
 	if exists {
 		return info
 	}
 
 	// For unknown vLLM models, return a default with $0 cost
 	// Users can customize this via overrides if needed
+	return defaultModelInfo(model)
+}
+
+// defaultModelInfo returns metadata for a model not in the registry.
+func defaultModelInfo(model string) *types.ModelInfo {
 	return &types.ModelInfo{
 		Name:              model,
 		Provider:          "vllm",
@@ -236,12 +249,22 @@ func (p *Provider) GetModelInfo(model string) *types.ModelInfo {
 
 // ListModels returns all supported vLLM models.
 //
+// After a successful RefreshModels, returns the models served by the server;
+// otherwise returns the compiled-in registry.
 // Returns a slice of ModelInfo sorted alphabetically by model name.
+//
+// Thread Safety: Safe for concurrent use.
 func (p *Provider) ListModels() []*types.ModelInfo {
-	models := make([]*types.ModelInfo, 0, len(modelRegistry))
-	for _, info := range modelRegistry {
+	p.modelsMu.RLock()
+	source := modelRegistry
+	if len(p.liveModels) > 0 {
+		source = p.liveModels
+	}
+	models := make([]*types.ModelInfo, 0, len(source))
+	for _, info := range source {
 		models = append(models, info)
 	}
+	p.modelsMu.RUnlock()
 
 	// Sort by name for consistent output
 	sort.Slice(models, func(i, j int) bool {
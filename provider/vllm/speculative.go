@@ -0,0 +1,540 @@
+package vllm
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/blue-context/warp"
+)
+
+// SpeculativeConfig configures client-side speculative decoding.
+type SpeculativeConfig struct {
+	// DraftModel is a small model served by the same vLLM server that
+	// proposes continuations. When empty, drafts come from n-gram prompt
+	// lookup instead, which works well for summarization and RAG answers
+	// that copy from the prompt.
+	DraftModel string
+
+	// NumSpeculativeTokens is the draft length per round (default 5).
+	// With n-gram lookup it is measured in words.
+	NumSpeculativeTokens int
+
+	// NGramSize is the number of trailing words matched against the prompt
+	// during n-gram lookup (default 3).
+	NGramSize int
+
+	// EOSTokens are the verifier's end-of-sequence tokens. A round in which
+	// the verifier chooses one of them finishes with "stop" instead of
+	// emitting it (default defaultEOSTokens).
+	EOSTokens []string
+}
+
+// defaultEOSTokens are the end-of-sequence tokens of common model families.
+var defaultEOSTokens = []string{
+	"</s>", "<|endoftext|>", "<|end_of_text|>", "<|eot_id|>",
+	"<|im_end|>", "<|end|>", "<eos>", "<end_of_turn>",
+}
+
+// SpeculativeStream generates a completion with draft-and-verify speculative
+// decoding, exposing the accepted tokens as a single Stream.
+//
+// Each round, a draft continuation is proposed by cfg.DraftModel or by
+// n-gram prompt lookup, and req.Model verifies the whole draft in a single
+// forward pass via /v1/completions with echo and logprobs. The longest
+// prefix of draft tokens matching the verifier's greedy choice is accepted,
+// plus one token from the verifier, so every round makes progress and the
+// output equals the verifier's greedy decoding.
+//
+// Decoding is greedy: req.Temperature and req.TopP are ignored. req.MaxTokens
+// (default 256) and req.Stop are honoured. The prompt is built the same way
+// as for the native endpoint, so this requires vLLM's OpenAI-compatible
+// server and a verifier without a mandatory chat template. Each round
+// resubmits the prompt; enable vLLM's automatic prefix caching so only new
+// tokens are prefilled.
+//
+// With a draft model, drafting is pipelined with verification: while round k
+// is verified, the draft for round k+1 is requested on the assumption that
+// the whole draft is accepted. The pipelined draft is used when that holds
+// and its first tokens match the verifier's bonus token; otherwise it is
+// discarded and a fresh draft is requested.
+//
+// Example:
+//
+//	stream, err := provider.SpeculativeStream(ctx, req, vllm.SpeculativeConfig{
+//	    DraftModel: "meta-llama/Llama-3.2-1B",
+//	})
+//	if err != nil {
+//	    return err
+//	}
+//	defer stream.Close()
+func (p *Provider) SpeculativeStream(ctx context.Context, req *warp.CompletionRequest, cfg SpeculativeConfig) (warp.Stream, error) {
+	if req == nil {
+		return nil, fmt.Errorf("request cannot be nil")
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	if cfg.NumSpeculativeTokens <= 0 {
+		cfg.NumSpeculativeTokens = 5
+	}
+	if cfg.NGramSize <= 0 {
+		cfg.NGramSize = 3
+	}
+	if len(cfg.EOSTokens) == 0 {
+		cfg.EOSTokens = defaultEOSTokens
+	}
+	maxTokens := 256
+	if req.MaxTokens != nil && *req.MaxTokens > 0 {
+		maxTokens = *req.MaxTokens
+	}
+
+	return &speculativeStream{
+		p:         p,
+		ctx:       ctx,
+		cfg:       cfg,
+		model:     req.Model,
+		prompt:    messagesToPrompt(req.Messages),
+		stop:      req.Stop,
+		maxTokens: maxTokens,
+		id:        fmt.Sprintf("spec-%d", time.Now().UnixNano()),
+		created:   time.Now().Unix(),
+	}, nil
+}
+
+// speculativeStream runs one draft-and-verify round per Recv call.
+//
+// Thread Safety: speculativeStream is NOT safe for concurrent use.
+// The pipelined draft runs in its own goroutine and only reads immutable
+// fields.
+type speculativeStream struct {
+	p         *Provider
+	ctx       context.Context
+	cfg       SpeculativeConfig
+	model     string
+	prompt    string
+	stop      []string
+	maxTokens int
+	id        string
+	created   int64
+
+	generated strings.Builder
+	tokens    int
+	sentRole  bool
+	done      bool
+	err       error
+
+	// pending is the draft for the next round, requested while the
+	// current round is verified
+	pending *pendingDraft
+}
+
+// pendingDraft is a draft model request running in the background.
+type pendingDraft struct {
+	base   string
+	cancel context.CancelFunc
+	result chan draftResult
+}
+
+// draftResult is the outcome of a pipelined draft request.
+type draftResult struct {
+	text string
+	err  error
+}
+
+// completionsResponse is a /v1/completions response with logprobs.
+type completionsResponse struct {
+	Choices []struct {
+		Text     string `json:"text"`
+		Logprobs *struct {
+			Tokens      []string             `json:"tokens"`
+			TopLogprobs []map[string]float64 `json:"top_logprobs"`
+			TextOffset  []int                `json:"text_offset"`
+		} `json:"logprobs"`
+		FinishReason string `json:"finish_reason"`
+	} `json:"choices"`
+}
+
+// Recv returns the tokens accepted in the next round.
+//
+// The final chunk carries a finish reason ("stop" or "length") and usage
+// with the number of completion tokens generated.
+func (s *speculativeStream) Recv() (*warp.CompletionChunk, error) {
+	if s.err != nil {
+		return nil, s.err
+	}
+	if s.done {
+		s.err = io.EOF
+		return nil, s.err
+	}
+	if err := s.ctx.Err(); err != nil {
+		s.err = err
+		return nil, err
+	}
+
+	prefix := s.prompt + s.generated.String()
+
+	draft, err := s.nextDraft(prefix)
+	if err != nil {
+		s.err = err
+		return nil, err
+	}
+
+	// Draft the next round while this one is verified
+	s.startDraft(prefix + draft)
+
+	accepted, finished, err := s.verify(prefix, draft)
+	if err != nil {
+		s.discardDraft()
+		s.err = err
+		return nil, err
+	}
+
+	// Never generate more than maxTokens, even if more were accepted
+	if remaining := s.maxTokens - s.tokens; len(accepted) > remaining {
+		accepted = accepted[:remaining]
+		finished = false
+	}
+	text := strings.Join(accepted, "")
+	s.tokens += len(accepted)
+
+	// Apply stop sequences to the full output so matches spanning rounds are found
+	finishReason := ""
+	if finished {
+		finishReason = "stop"
+	}
+	if cut, ok := s.cutAtStop(text); ok {
+		text = cut
+		finishReason = "stop"
+	} else if s.tokens >= s.maxTokens {
+		finishReason = "length"
+	}
+	s.generated.WriteString(text)
+
+	chunk := &warp.CompletionChunk{
+		ID:      s.id,
+		Object:  "chat.completion.chunk",
+		Created: s.created,
+		Model:   s.model,
+		Choices: []warp.ChunkChoice{{Delta: warp.MessageDelta{Content: text}}},
+	}
+	if !s.sentRole {
+		chunk.Choices[0].Delta.Role = "assistant"
+		s.sentRole = true
+	}
+	if finishReason != "" {
+		s.done = true
+		s.discardDraft()
+		chunk.Choices[0].FinishReason = &finishReason
+		chunk.Usage = &warp.Usage{CompletionTokens: s.tokens, TotalTokens: s.tokens}
+	}
+
+	return chunk, nil
+}
+
+// cutAtStop truncates text at the first stop sequence found in the output.
+func (s *speculativeStream) cutAtStop(text string) (string, bool) {
+	if len(s.stop) == 0 {
+		return text, false
+	}
+	previous := s.generated.String()
+	full := previous + text
+	cut := -1
+	for _, stop := range s.stop {
+		if stop == "" {
+			continue
+		}
+		if i := strings.Index(full, stop); i >= 0 && (cut < 0 || i < cut) {
+			cut = i
+		}
+	}
+	if cut < 0 {
+		return text, false
+	}
+	if cut <= len(previous) {
+		return "", true
+	}
+	return full[len(previous):cut], true
+}
+
+// nextDraft returns the draft for a round starting at prefix, reusing the
+// pipelined draft when the previous round accepted everything it assumed.
+func (s *speculativeStream) nextDraft(prefix string) (string, error) {
+	if p := s.pending; p != nil {
+		s.pending = nil
+		if strings.HasPrefix(prefix, p.base) {
+			res := <-p.result
+			p.cancel()
+			full := p.base + res.text
+			if res.err == nil && strings.HasPrefix(full, prefix) {
+				return full[len(prefix):], nil
+			}
+		} else {
+			p.cancel()
+		}
+	}
+	return s.draft(s.ctx, prefix)
+}
+
+// startDraft requests a draft continuing base in the background.
+//
+// N-gram drafts are computed locally and are not pipelined.
+func (s *speculativeStream) startDraft(base string) {
+	if s.cfg.DraftModel == "" {
+		return
+	}
+	ctx, cancel := context.WithCancel(s.ctx)
+	p := &pendingDraft{base: base, cancel: cancel, result: make(chan draftResult, 1)}
+	go func() {
+		text, err := s.draft(ctx, base)
+		p.result <- draftResult{text: text, err: err}
+	}()
+	s.pending = p
+}
+
+// discardDraft cancels the pipelined draft, if any.
+func (s *speculativeStream) discardDraft() {
+	if s.pending != nil {
+		s.pending.cancel()
+		s.pending = nil
+	}
+}
+
+// draft proposes a continuation of prefix.
+func (s *speculativeStream) draft(ctx context.Context, prefix string) (string, error) {
+	if s.cfg.DraftModel == "" {
+		return ngramDraft(prefix, s.cfg.NGramSize, s.cfg.NumSpeculativeTokens), nil
+	}
+
+	resp, err := s.p.rawCompletion(ctx, map[string]any{
+		"model":       s.cfg.DraftModel,
+		"prompt":      prefix,
+		"max_tokens":  s.cfg.NumSpeculativeTokens,
+		"temperature": 0,
+	})
+	if err != nil {
+		return "", fmt.Errorf("draft model failed: %w", err)
+	}
+	if len(resp.Choices) == 0 {
+		return "", nil
+	}
+	return resp.Choices[0].Text, nil
+}
+
+// verify scores prefix+draft with the verifier in one forward pass.
+//
+// Returns the accepted token strings and whether generation finished,
+// either because the server stopped or because the verifier chose an
+// end-of-sequence token.
+func (s *speculativeStream) verify(prefix, draft string) ([]string, bool, error) {
+	resp, err := s.p.rawCompletion(s.ctx, map[string]any{
+		"model":       s.model,
+		"prompt":      prefix + draft,
+		"max_tokens":  1,
+		"temperature": 0,
+		"echo":        true,
+		"logprobs":    1,
+	})
+	if err != nil {
+		return nil, false, err
+	}
+	if len(resp.Choices) == 0 || resp.Choices[0].Logprobs == nil {
+		return nil, false, fmt.Errorf("verifier response missing logprobs")
+	}
+	choice := resp.Choices[0]
+	lp := choice.Logprobs
+
+	// Locate the first draft token by character offset
+	boundary := utf8.RuneCountInString(prefix)
+	start := len(lp.Tokens)
+	for j, offset := range lp.TextOffset {
+		if offset >= boundary {
+			start = j
+			break
+		}
+	}
+
+	// The echoed tokens are prompt + draft; when the verifier generated a
+	// token it is the last one and is the verifier's own choice
+	end := len(lp.Tokens)
+	if choice.FinishReason != "stop" && end > start {
+		end--
+	}
+
+	var accepted []string
+
+	// A token spanning the boundary holds the end of the prefix and the
+	// start of the draft. Its draft part is accepted if the verifier chose
+	// the whole token; otherwise the draft is dropped and the round is
+	// verified again without it.
+	if j := start - 1; draft != "" && j >= 0 && j < len(lp.TextOffset) && j < len(lp.Tokens) {
+		runes := []rune(lp.Tokens[j])
+		if split := boundary - lp.TextOffset[j]; split < len(runes) {
+			if predicted := argmax(lp.TopLogprobs, j); predicted != "" && predicted != lp.Tokens[j] {
+				return s.verify(prefix, "")
+			}
+			accepted = append(accepted, string(runes[split:]))
+		}
+	}
+
+	for j := start; j < end; j++ {
+		predicted := argmax(lp.TopLogprobs, j)
+		if s.isEOS(predicted) {
+			return accepted, true, nil
+		}
+		if predicted == "" || predicted == lp.Tokens[j] {
+			accepted = append(accepted, lp.Tokens[j])
+			continue
+		}
+		// First mismatch: take the verifier's token instead
+		return append(accepted, predicted), false, nil
+	}
+
+	if choice.FinishReason == "stop" {
+		return accepted, true, nil
+	}
+	if end < len(lp.Tokens) {
+		if s.isEOS(lp.Tokens[end]) {
+			return accepted, true, nil
+		}
+		accepted = append(accepted, lp.Tokens[end])
+	}
+	return accepted, false, nil
+}
+
+// isEOS reports whether token is one of the verifier's end-of-sequence tokens.
+func (s *speculativeStream) isEOS(token string) bool {
+	return token != "" && slices.Contains(s.cfg.EOSTokens, token)
+}
+
+// argmax returns the most likely token at position j, or "" if unknown.
+func argmax(top []map[string]float64, j int) string {
+	if j >= len(top) || len(top[j]) == 0 {
+		return ""
+	}
+	best, bestLP := "", 0.0
+	for tok, lp := range top[j] {
+		if best == "" || lp > bestLP || (lp == bestLP && tok < best) {
+			best, bestLP = tok, lp
+		}
+	}
+	return best
+}
+
+// rawCompletion posts a request to /v1/completions.
+func (p *Provider) rawCompletion(ctx context.Context, body map[string]any) (*completionsResponse, error) {
+	data, err := json.Marshal(body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	httpReq, err := http.NewRequestWithContext(ctx, "POST", p.baseURL+"/v1/completions", bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+	if p.apiKey != "" {
+		httpReq.Header.Set("Authorization", "Bearer "+p.apiKey)
+	}
+
+	// Add User-Agent and client request headers
+	warp.SetRequestHeaders(httpReq)
+
+	httpResp, err := p.httpClient.Do(httpReq)
+	if err != nil {
+		return nil, fmt.Errorf("failed to send request: %w", err)
+	}
+	warp.RecordResponse(httpResp)
+	defer httpResp.Body.Close()
+
+	if httpResp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(httpResp.Body)
+		return nil, warp.ParseProviderError("vllm", httpResp.StatusCode, body, nil)
+	}
+
+	var resp completionsResponse
+	if err := json.NewDecoder(httpResp.Body).Decode(&resp); err != nil {
+		return nil, fmt.Errorf("failed to decode response: %w", err)
+	}
+	return &resp, nil
+}
+
+// ngramDraft proposes up to k words by prompt lookup: it finds the most
+// recent earlier occurrence of the last n words of text and returns the
+// words that followed it, with their original spacing.
+func ngramDraft(text string, n, k int) string {
+	trimmed := strings.TrimRightFunc(text, unicode.IsSpace)
+	words := splitWords(trimmed)
+	if len(words) <= n {
+		return ""
+	}
+
+	key := make([]string, n)
+	for i, w := range words[len(words)-n:] {
+		key[i] = strings.TrimSpace(w)
+	}
+
+	for i := len(words) - n - 1; i >= 0; i-- {
+		match := true
+		for j := 0; j < n; j++ {
+			if strings.TrimSpace(words[i+j]) != key[j] {
+				match = false
+				break
+			}
+		}
+		if !match {
+			continue
+		}
+
+		from := i + n
+		to := min(from+k, len(words))
+		if from >= to {
+			return ""
+		}
+		draft := strings.Join(words[from:to], "")
+		// The text already ends with whitespace
+		if len(trimmed) < len(text) {
+			draft = strings.TrimLeftFunc(draft, unicode.IsSpace)
+		}
+		return draft
+	}
+
+	return ""
+}
+
+// splitWords splits text into words, each keeping its leading whitespace.
+func splitWords(text string) []string {
+	var words []string
+	start := 0
+	inWord := false
+	for i, r := range text {
+		space := unicode.IsSpace(r)
+		if space && inWord {
+			words = append(words, text[start:i])
+			start = i
+			inWord = false
+		} else if !space {
+			inWord = true
+		}
+	}
+	if start < len(text) {
+		words = append(words, text[start:])
+	}
+	return words
+}
+
+// Close releases resources. It is safe to call Close multiple times.
+func (s *speculativeStream) Close() error {
+	s.done = true
+	s.discardDraft()
+	return nil
+}
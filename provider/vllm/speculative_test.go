@@ -0,0 +1,419 @@
+package vllm
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+	"unicode/utf8"
+
+	"github.com/blue-context/warp"
+)
+
+// verifierMock emulates a greedy verifier that always continues target,
+// tokenizing on words, and counts verification rounds.
+func verifierMock(t *testing.T, target string, draftText func(prompt string) string, rounds *int) *mockHTTPClient {
+	targetWords := splitWords(target)
+	return &mockHTTPClient{
+		doFunc: func(req *http.Request) (*http.Response, error) {
+			if req.URL.Path != "/v1/completions" {
+				t.Errorf("request path = %s, want /v1/completions", req.URL.Path)
+			}
+			var body map[string]any
+			_ = json.NewDecoder(req.Body).Decode(&body)
+			prompt, _ := body["prompt"].(string)
+
+			var resp any
+			if body["echo"] != true {
+				resp = map[string]any{"choices": []any{map[string]any{"text": draftText(prompt), "finish_reason": "length"}}}
+			} else {
+				*rounds++
+				tokens := splitWords(prompt)
+				offsets := make([]int, len(tokens))
+				top := make([]any, len(tokens))
+				pos := 0
+				for j, tok := range tokens {
+					offsets[j] = pos
+					pos += utf8.RuneCountInString(tok)
+					if j < len(targetWords) {
+						top[j] = map[string]float64{targetWords[j]: -0.1, tok: -2}
+					}
+				}
+				finish := "stop"
+				// Generate the next target word if the echoed text is on target
+				if len(tokens) < len(targetWords) && strings.HasPrefix(target, prompt) {
+					tokens = append(tokens, targetWords[len(tokens)])
+					offsets = append(offsets, pos)
+					top = append(top, map[string]float64{targetWords[len(tokens)-1]: -0.1})
+					finish = "length"
+				}
+				resp = map[string]any{"choices": []any{map[string]any{
+					"text":          strings.Join(tokens, ""),
+					"finish_reason": finish,
+					"logprobs":      map[string]any{"tokens": tokens, "top_logprobs": top, "text_offset": offsets},
+				}}}
+			}
+
+			data, _ := json.Marshal(resp)
+			return &http.Response{
+				StatusCode: http.StatusOK,
+				Body:       io.NopCloser(strings.NewReader(string(data))),
+				Header:     make(http.Header),
+			}, nil
+		},
+	}
+}
+
+// collect reads a stream to completion.
+func collect(t *testing.T, stream warp.Stream) (string, string) {
+	t.Helper()
+	var text strings.Builder
+	finish := ""
+	for {
+		chunk, err := stream.Recv()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatalf("Recv() error = %v", err)
+		}
+		text.WriteString(chunk.Choices[0].Delta.Content)
+		if chunk.Choices[0].FinishReason != nil {
+			finish = *chunk.Choices[0].FinishReason
+		}
+	}
+	return text.String(), finish
+}
+
+// TestSpeculativeStreamDraftModel tests draft-model speculation with a partially wrong draft
+func TestSpeculativeStreamDraftModel(t *testing.T) {
+	req := &warp.CompletionRequest{
+		Model:    "big",
+		Messages: []warp.Message{{Role: "user", Content: "Describe the cat."}},
+	}
+	prompt := messagesToPrompt(req.Messages)
+	answer := " The cat sat on the mat and purred."
+	target := prompt + answer
+
+	// The draft model gets the first words right, then goes wrong once
+	draftText := func(prefix string) string {
+		rest := strings.TrimPrefix(target, prefix)
+		words := splitWords(rest)
+		if len(words) > 4 && strings.HasSuffix(prefix, "Assistant:") {
+			return strings.Join(words[:3], "") + " dog"
+		}
+		return strings.Join(words[:min(4, len(words))], "")
+	}
+
+	var rounds int
+	provider, err := NewProvider(WithHTTPClient(verifierMock(t, target, draftText, &rounds)))
+	if err != nil {
+		t.Fatalf("NewProvider() error = %v", err)
+	}
+
+	stream, err := provider.SpeculativeStream(context.Background(), req, SpeculativeConfig{DraftModel: "small", NumSpeculativeTokens: 4})
+	if err != nil {
+		t.Fatalf("SpeculativeStream() error = %v", err)
+	}
+	defer stream.Close()
+
+	text, finish := collect(t, stream)
+	if text != answer {
+		t.Errorf("output = %q, want %q", text, answer)
+	}
+	if finish != "stop" {
+		t.Errorf("finish reason = %q, want stop", finish)
+	}
+	// 8 words at up to 5 per round must take fewer rounds than tokens
+	if rounds >= len(splitWords(answer)) {
+		t.Errorf("rounds = %d, want fewer than %d", rounds, len(splitWords(answer)))
+	}
+}
+
+// TestSpeculativeStreamPipelined tests that the next draft is requested while
+// the current round is verified
+func TestSpeculativeStreamPipelined(t *testing.T) {
+	req := &warp.CompletionRequest{
+		Model:    "big",
+		Messages: []warp.Message{{Role: "user", Content: "Describe the cat."}},
+	}
+	prompt := messagesToPrompt(req.Messages)
+	answer := " The cat sat on the mat and purred."
+	target := prompt + answer
+
+	draftText := func(prefix string) string {
+		words := splitWords(strings.TrimPrefix(target, prefix))
+		return strings.Join(words[:min(3, len(words))], "")
+	}
+
+	var rounds int
+	inner := verifierMock(t, target, draftText, &rounds)
+
+	// The second draft and the first verification wait for each other, so
+	// they only both proceed promptly when they are in flight together
+	var drafts, verifies atomic.Int32
+	var overlapped atomic.Bool
+	draftStarted := make(chan struct{})
+	verifyStarted := make(chan struct{})
+	client := &mockHTTPClient{
+		doFunc: func(r *http.Request) (*http.Response, error) {
+			data, _ := io.ReadAll(r.Body)
+			r.Body = io.NopCloser(bytes.NewReader(data))
+			var body map[string]any
+			_ = json.Unmarshal(data, &body)
+
+			if body["echo"] != true {
+				if drafts.Add(1) == 2 {
+					close(draftStarted)
+					select {
+					case <-verifyStarted:
+					case <-time.After(time.Second):
+					}
+				}
+			} else if verifies.Add(1) == 1 {
+				close(verifyStarted)
+				select {
+				case <-draftStarted:
+					overlapped.Store(true)
+				case <-time.After(time.Second):
+				}
+			}
+			return inner.doFunc(r)
+		},
+	}
+
+	provider, err := NewProvider(WithHTTPClient(client))
+	if err != nil {
+		t.Fatalf("NewProvider() error = %v", err)
+	}
+
+	stream, err := provider.SpeculativeStream(context.Background(), req, SpeculativeConfig{DraftModel: "small", NumSpeculativeTokens: 3})
+	if err != nil {
+		t.Fatalf("SpeculativeStream() error = %v", err)
+	}
+	defer stream.Close()
+
+	text, _ := collect(t, stream)
+	if text != answer {
+		t.Errorf("output = %q, want %q", text, answer)
+	}
+	if !overlapped.Load() {
+		t.Error("next draft was not requested during verification")
+	}
+	// Accepted pipelined drafts are reused, so drafts are not requested
+	// once per round on top of the pipelined ones
+	if got := int(drafts.Load()); got > rounds+1 {
+		t.Errorf("draft requests = %d, want at most %d", got, rounds+1)
+	}
+}
+
+// TestSpeculativeStreamNGram tests prompt-lookup drafting, stop sequences, and max tokens
+func TestSpeculativeStreamNGram(t *testing.T) {
+	doc := "Warp is a unified client for many LLM providers."
+	req := &warp.CompletionRequest{
+		Model:    "big",
+		Messages: []warp.Message{{Role: "user", Content: doc + " Repeat the first sentence."}},
+		Stop:     []string{"providers"},
+	}
+	prompt := messagesToPrompt(req.Messages)
+	target := prompt + " Warp is a unified client for many LLM providers. Done."
+
+	var rounds int
+	provider, err := NewProvider(WithHTTPClient(verifierMock(t, target, nil, &rounds)))
+	if err != nil {
+		t.Fatalf("NewProvider() error = %v", err)
+	}
+
+	stream, err := provider.SpeculativeStream(context.Background(), req, SpeculativeConfig{NGramSize: 1})
+	if err != nil {
+		t.Fatalf("SpeculativeStream() error = %v", err)
+	}
+	text, finish := collect(t, stream)
+	if text != " Warp is a unified client for many LLM " {
+		t.Errorf("output = %q", text)
+	}
+	if finish != "stop" {
+		t.Errorf("finish reason = %q, want stop", finish)
+	}
+
+	req.Stop = nil
+	req.MaxTokens = warp.IntPtr(2)
+	stream, err = provider.SpeculativeStream(context.Background(), req, SpeculativeConfig{})
+	if err != nil {
+		t.Fatalf("SpeculativeStream() error = %v", err)
+	}
+	if _, finish := collect(t, stream); finish != "length" {
+		t.Errorf("finish reason = %q, want length", finish)
+	}
+}
+
+// TestNGramDraft tests prompt lookup proposals
+func TestNGramDraft(t *testing.T) {
+	tests := []struct {
+		text string
+		n, k int
+		want string
+	}{
+		{"the quick brown fox jumps. Again: the quick brown", 3, 2, " fox jumps."},
+		{"the quick brown fox. the quick ", 2, 1, "brown"},
+		{"no repeats here", 2, 3, ""},
+	}
+
+	for _, tt := range tests {
+		if got := ngramDraft(tt.text, tt.n, tt.k); got != tt.want {
+			t.Errorf("ngramDraft(%q) = %q, want %q", tt.text, got, tt.want)
+		}
+	}
+}
+
+// logprobsMock returns a verifier response built by respond for each prompt.
+func logprobsMock(respond func(prompt string) (tokens []string, top []map[string]float64, finish string)) *mockHTTPClient {
+	return &mockHTTPClient{
+		doFunc: func(req *http.Request) (*http.Response, error) {
+			var body map[string]any
+			_ = json.NewDecoder(req.Body).Decode(&body)
+			prompt, _ := body["prompt"].(string)
+
+			tokens, top, finish := respond(prompt)
+			offsets := make([]int, len(tokens))
+			pos := 0
+			for j, tok := range tokens {
+				offsets[j] = pos
+				pos += utf8.RuneCountInString(tok)
+			}
+			data, _ := json.Marshal(map[string]any{"choices": []any{map[string]any{
+				"text":          strings.Join(tokens, ""),
+				"finish_reason": finish,
+				"logprobs":      map[string]any{"tokens": tokens, "top_logprobs": top, "text_offset": offsets},
+			}}})
+			return &http.Response{
+				StatusCode: http.StatusOK,
+				Body:       io.NopCloser(bytes.NewReader(data)),
+				Header:     make(http.Header),
+			}, nil
+		},
+	}
+}
+
+// TestSpeculativeVerifyEOS tests that an end-of-sequence choice finishes
+// generation instead of being emitted
+func TestSpeculativeVerifyEOS(t *testing.T) {
+	tests := []struct {
+		name   string
+		tokens []string
+		top    []map[string]float64
+		want   []string
+	}{
+		{
+			name:   "mismatch with EOS",
+			tokens: []string{"Hi", " a", " b", " c"},
+			top:    []map[string]float64{nil, {" a": -0.1}, {"</s>": -0.1, " b": -2}, {" c": -0.1}},
+			want:   []string{" a"},
+		},
+		{
+			name:   "generated EOS",
+			tokens: []string{"Hi", " a", " b", "<|eot_id|>"},
+			top:    []map[string]float64{nil, {" a": -0.1}, {" b": -0.1}, {"<|eot_id|>": -0.1}},
+			want:   []string{" a", " b"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			provider, err := NewProvider(WithHTTPClient(logprobsMock(func(string) ([]string, []map[string]float64, string) {
+				return tt.tokens, tt.top, "length"
+			})))
+			if err != nil {
+				t.Fatalf("NewProvider() error = %v", err)
+			}
+			s := &speculativeStream{p: provider, ctx: context.Background(), cfg: SpeculativeConfig{EOSTokens: defaultEOSTokens}}
+
+			accepted, finished, err := s.verify("Hi", " a b")
+			if err != nil {
+				t.Fatalf("verify() error = %v", err)
+			}
+			if !finished || strings.Join(accepted, "|") != strings.Join(tt.want, "|") {
+				t.Errorf("verify() = %q, %v, want %q, true", accepted, finished, tt.want)
+			}
+		})
+	}
+}
+
+// TestSpeculativeVerifySpanningToken tests a token that holds both the end
+// of the prefix and the start of the draft
+func TestSpeculativeVerifySpanningToken(t *testing.T) {
+	prefix := "Hello wor"
+	var prompts []string
+	provider, err := NewProvider(WithHTTPClient(logprobsMock(func(prompt string) ([]string, []map[string]float64, string) {
+		prompts = append(prompts, prompt)
+		if prompt == prefix {
+			return []string{"Hello", " wor", "ld"}, []map[string]float64{nil, {" wor": -0.1}, {"ld": -0.1}}, "length"
+		}
+		first := " world"
+		if strings.HasSuffix(prompt, " ") {
+			first = " word"
+		}
+		return []string{"Hello", " world", " again", "!"}, []map[string]float64{nil, {first: -0.1}, {" again": -0.1}, {"!": -0.1}}, "length"
+	})))
+	if err != nil {
+		t.Fatalf("NewProvider() error = %v", err)
+	}
+	s := &speculativeStream{p: provider, ctx: context.Background(), cfg: SpeculativeConfig{EOSTokens: defaultEOSTokens}}
+
+	// The verifier chose the spanning token, so its draft part is kept
+	accepted, _, err := s.verify(prefix, "ld again")
+	if err != nil {
+		t.Fatalf("verify() error = %v", err)
+	}
+	if got := strings.Join(accepted, ""); got != "ld again!" {
+		t.Errorf("accepted = %q, want %q", got, "ld again!")
+	}
+
+	// The verifier disagrees with it, so the round is verified without the draft
+	prompts = nil
+	accepted, _, err = s.verify(prefix, "ld again ")
+	if err != nil {
+		t.Fatalf("verify() error = %v", err)
+	}
+	if got := strings.Join(accepted, ""); got != "ld" || len(prompts) != 2 || prompts[1] != prefix {
+		t.Errorf("accepted = %q after prompts %q, want %q from a draft-free retry", got, prompts, "ld")
+	}
+}
+
+// TestSpeculativeStreamMaxTokens tests that a round never goes past MaxTokens
+func TestSpeculativeStreamMaxTokens(t *testing.T) {
+	req := &warp.CompletionRequest{
+		Model:     "big",
+		Messages:  []warp.Message{{Role: "user", Content: "Describe the cat."}},
+		MaxTokens: warp.IntPtr(3),
+	}
+	prompt := messagesToPrompt(req.Messages)
+	target := prompt + " The cat sat on the mat and purred."
+
+	draftText := func(prefix string) string {
+		words := splitWords(strings.TrimPrefix(target, prefix))
+		return strings.Join(words[:min(5, len(words))], "")
+	}
+
+	var rounds int
+	provider, err := NewProvider(WithHTTPClient(verifierMock(t, target, draftText, &rounds)))
+	if err != nil {
+		t.Fatalf("NewProvider() error = %v", err)
+	}
+
+	stream, err := provider.SpeculativeStream(context.Background(), req, SpeculativeConfig{DraftModel: "small", NumSpeculativeTokens: 5})
+	if err != nil {
+		t.Fatalf("SpeculativeStream() error = %v", err)
+	}
+	defer stream.Close()
+
+	text, finish := collect(t, stream)
+	if text != " The cat sat" || finish != "length" {
+		t.Errorf("output = %q, %q, want %q, length", text, finish, " The cat sat")
+	}
+}
@@ -1,7 +1,6 @@
 package vllm
 
 import (
-	"bufio"
 	"bytes"
 	"context"
 	"encoding/json"
@@ -10,6 +9,7 @@ import (
 	"net/http"
 
 	"github.com/blue-context/warp"
+	"github.com/blue-context/warp/sse"
 )
 
 // CompletionStream sends a streaming chat completion request to vLLM.
@@ -19,7 +19,8 @@ import (
 //
 // The caller must close the returned stream to release resources.
 //
-// Uses vLLM's native /inference/v1/generate endpoint with streaming enabled.
+// Uses vLLM's native /inference/v1/generate endpoint with streaming enabled,
+// or /v1/chat/completions when WithChatCompletions is set.
 //
 // Example:
 //
@@ -50,6 +51,10 @@ func (p *Provider) CompletionStream(ctx context.Context, req *warp.CompletionReq
 		return nil, err
 	}
 
+	if p.chatAPI {
+		return p.chatCompletionStream(ctx, req)
+	}
+
 	// Transform request to vLLM format (streaming)
 	vllmReq := transformToVLLMRequest(req, true)
 
@@ -74,11 +79,15 @@ func (p *Provider) CompletionStream(ctx context.Context, req *warp.CompletionReq
 		httpReq.Header.Set("Authorization", "Bearer "+p.apiKey)
 	}
 
+	// Add User-Agent and client request headers
+	warp.SetRequestHeaders(httpReq)
+
 	// Send request
 	httpResp, err := p.httpClient.Do(httpReq)
 	if err != nil {
 		return nil, fmt.Errorf("failed to send request: %w", err)
 	}
+	warp.RecordResponse(httpResp)
 
 	// Check status code
 	if httpResp.StatusCode != http.StatusOK {
@@ -102,10 +111,11 @@ func (p *Provider) CompletionStream(ctx context.Context, req *warp.CompletionReq
 // Thread Safety: vllmStream is NOT safe for concurrent use.
 // Only one goroutine should call Recv() at a time.
 type vllmStream struct {
-	reader *bufio.Reader
+	reader *sse.Reader
 	closer io.Closer
 	ctx    context.Context
 	err    error // Cached error for subsequent Recv calls
+	chat   bool  // Chunks are OpenAI chat completion chunks (/v1/chat/completions)
 }
 
 // vllmStreamChunk represents a chunk in vLLM's streaming response.
@@ -133,7 +143,7 @@ type vllmStreamChoice struct {
 // CompletionChunk objects.
 func newVLLMStream(ctx context.Context, body io.ReadCloser) warp.Stream {
 	return &vllmStream{
-		reader: bufio.NewReader(body),
+		reader: sse.NewReader(body, sse.WithBufferReuse()),
 		closer: body,
 		ctx:    ctx,
 	}
@@ -160,42 +170,42 @@ func (s *vllmStream) Recv() (*warp.CompletionChunk, error) {
 		default:
 		}
 
-		// Read line
-		line, err := s.reader.ReadBytes('\n')
+		// Read the next event
+		event, err := s.reader.Next()
 		if err != nil {
 			if err == io.EOF {
 				s.err = io.EOF
 				return nil, io.EOF
 			}
-			s.err = fmt.Errorf("failed to read line: %w", err)
+			s.err = fmt.Errorf("failed to read event: %w", err)
 			return nil, s.err
 		}
 
-		// Trim whitespace
-		line = bytes.TrimSpace(line)
-
-		// Skip empty lines
-		if len(line) == 0 {
+		// Skip keep-alive events without data
+		data := event.Data
+		if len(data) == 0 {
 			continue
 		}
 
-		// Check for SSE data prefix
-		if !bytes.HasPrefix(line, []byte("data: ")) {
-			continue
-		}
-
-		// Extract data after "data: " prefix
-		data := bytes.TrimPrefix(line, []byte("data: "))
-
 		// Check for [DONE] message
-		if bytes.Equal(data, []byte("[DONE]")) {
+		if event.Done() {
 			s.err = io.EOF
 			return nil, io.EOF
 		}
 
+		// Chat completion chunks are already in Warp format
+		if s.chat {
+			var chunk warp.CompletionChunk
+			if err := s.reader.Decode(&chunk); err != nil {
+				s.err = fmt.Errorf("failed to parse chunk: %w", err)
+				return nil, s.err
+			}
+			return &chunk, nil
+		}
+
 		// Parse JSON chunk
 		var vllmChunk vllmStreamChunk
-		if err := json.Unmarshal(data, &vllmChunk); err != nil {
+		if err := s.reader.Decode(&vllmChunk); err != nil {
 			s.err = fmt.Errorf("failed to parse chunk: %w", err)
 			return nil, s.err
 		}
@@ -258,5 +268,7 @@ func (s *vllmStream) Close() error {
 	if s.closer == nil {
 		return nil
 	}
-	return s.closer.Close()
+	err := s.closer.Close()
+	s.reader.Release()
+	return err
 }
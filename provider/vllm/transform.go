@@ -23,7 +23,12 @@ type vllmRequest struct {
 	Stop              []string `json:"stop,omitempty"`
 	Stream            bool     `json:"stream,omitempty"`
 	Logprobs          *int     `json:"logprobs,omitempty"`
+	PromptLogprobs    *int     `json:"prompt_logprobs,omitempty"`
 	ResponseFormat    *string  `json:"response_format,omitempty"` // For JSON mode
+	GuidedJSON        any      `json:"guided_json,omitempty"`
+	GuidedRegex       any      `json:"guided_regex,omitempty"`
+	GuidedChoice      any      `json:"guided_choice,omitempty"`
+	GuidedGrammar     any      `json:"guided_grammar,omitempty"`
 }
 
 // vllmResponse represents a vLLM native generate response.
@@ -40,10 +45,11 @@ type vllmResponse struct {
 
 // vllmChoice represents a single completion choice in vLLM response.
 type vllmChoice struct {
-	Index        int    `json:"index"`
-	Text         string `json:"text"`
-	Logprobs     any    `json:"logprobs,omitempty"`
-	FinishReason string `json:"finish_reason"`
+	Index          int                 `json:"index"`
+	Text           string              `json:"text"`
+	Logprobs       any                 `json:"logprobs,omitempty"`
+	PromptLogprobs warp.PromptLogprobs `json:"prompt_logprobs,omitempty"`
+	FinishReason   string              `json:"finish_reason"`
 }
 
 // vllmUsage represents token usage in vLLM response.
@@ -76,12 +82,25 @@ func transformToVLLMRequest(req *warp.CompletionRequest, stream bool) *vllmReque
 		vllmReq.N = req.N
 	}
 
+	// Log probabilities of the prompt tokens themselves, without
+	// alternatives
+	if req.PromptLogprobs {
+		vllmReq.PromptLogprobs = warp.IntPtr(0)
+	}
+
 	// Handle JSON mode via response_format
 	if req.ResponseFormat != nil && req.ResponseFormat.Type == "json_object" {
 		jsonFormat := "json"
 		vllmReq.ResponseFormat = &jsonFormat
 	}
 
+	// Structured output via guided decoding
+	guided := guidedParams(req)
+	vllmReq.GuidedJSON = guided[guidedJSON]
+	vllmReq.GuidedRegex = guided[guidedRegex]
+	vllmReq.GuidedChoice = guided[guidedChoice]
+	vllmReq.GuidedGrammar = guided[guidedGrammar]
+
 	return vllmReq
 }
 
@@ -176,13 +195,20 @@ func transformFromVLLMResponse(vllmResp *vllmResponse) *warp.CompletionResponse
 		}
 	}
 
+	// Every choice continues the same prompt
+	var promptLogprobs warp.PromptLogprobs
+	if len(vllmResp.Choices) > 0 {
+		promptLogprobs = vllmResp.Choices[0].PromptLogprobs
+	}
+
 	return &warp.CompletionResponse{
-		ID:      vllmResp.ID,
-		Object:  "chat.completion",
-		Created: vllmResp.Created,
-		Model:   vllmResp.Model,
-		Choices: choices,
-		Usage:   usage,
+		ID:             vllmResp.ID,
+		Object:         "chat.completion",
+		Created:        vllmResp.Created,
+		Model:          vllmResp.Model,
+		Choices:        choices,
+		Usage:          usage,
+		PromptLogprobs: promptLogprobs,
 	}
 }
 
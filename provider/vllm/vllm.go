@@ -26,16 +26,19 @@ import (
 	"context"
 	"io"
 	"net/http"
+	"sync"
 	"time"
 
 	"github.com/blue-context/warp"
 	"github.com/blue-context/warp/provider"
+	"github.com/blue-context/warp/types"
 )
 
 // Provider implements the provider.Provider interface for vLLM.
 //
 // vLLM is a self-hosted LLM inference engine that doesn't require authentication.
-// It uses native endpoints (/inference/v1/generate) rather than OpenAI-compatible endpoints.
+// It uses native endpoints (/inference/v1/generate) by default; WithChatCompletions
+// switches completions to the OpenAI-compatible /v1/chat/completions endpoint.
 //
 // Thread Safety: Provider is safe for concurrent use.
 // Multiple goroutines may call methods on the same Provider instance simultaneously.
@@ -43,6 +46,11 @@ type Provider struct {
 	baseURL    string
 	apiKey     string // Optional for self-hosted deployments
 	httpClient warp.HTTPClient
+	chatAPI    bool // Use /v1/chat/completions instead of the native generate endpoint
+
+	// liveModels holds models discovered by RefreshModels, guarded by modelsMu.
+	liveModels map[string]*types.ModelInfo
+	modelsMu   sync.RWMutex
 }
 
 // Compile-time interface check
@@ -147,7 +155,7 @@ func (p *Provider) Supports() interface{} {
 		Transcription:   false,
 		Speech:          false,
 		Moderation:      false,
-		FunctionCalling: false,
+		FunctionCalling: p.chatAPI, // tools require the chat completions endpoint
 		Vision:          false,
 		JSON:            true, // vLLM supports JSON mode
 		Rerank:          true, // via /rerank endpoint
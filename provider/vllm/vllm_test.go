@@ -1,7 +1,6 @@
 package vllm
 
 import (
-	"bufio"
 	"context"
 	"io"
 	"net/http"
@@ -10,6 +9,7 @@ import (
 
 	"github.com/blue-context/warp"
 	prov "github.com/blue-context/warp/provider"
+	"github.com/blue-context/warp/sse"
 )
 
 // mockHTTPClient is a mock HTTP client for testing
@@ -764,7 +764,7 @@ func TestStreamErrorHandling(t *testing.T) {
 		ctx, cancel := context.WithCancel(context.Background())
 		stream := &vllmStream{
 			ctx:    ctx,
-			reader: bufio.NewReader(strings.NewReader("data: test\n")),
+			reader: sse.NewReader(strings.NewReader("data: test\n")),
 			closer: io.NopCloser(strings.NewReader("")),
 		}
 
@@ -785,7 +785,7 @@ func TestStreamErrorHandling(t *testing.T) {
 	t.Run("stream recv EOF", func(t *testing.T) {
 		stream := &vllmStream{
 			ctx:    context.Background(),
-			reader: bufio.NewReader(strings.NewReader("")),
+			reader: sse.NewReader(strings.NewReader("")),
 			closer: io.NopCloser(strings.NewReader("")),
 		}
 
@@ -798,7 +798,7 @@ func TestStreamErrorHandling(t *testing.T) {
 	t.Run("stream recv invalid JSON", func(t *testing.T) {
 		stream := &vllmStream{
 			ctx:    context.Background(),
-			reader: bufio.NewReader(strings.NewReader("data: {invalid json}\n")),
+			reader: sse.NewReader(strings.NewReader("data: {invalid json}\n")),
 			closer: io.NopCloser(strings.NewReader("")),
 		}
 
@@ -811,7 +811,7 @@ func TestStreamErrorHandling(t *testing.T) {
 	t.Run("stream recv DONE message", func(t *testing.T) {
 		stream := &vllmStream{
 			ctx:    context.Background(),
-			reader: bufio.NewReader(strings.NewReader("data: [DONE]\n")),
+			reader: sse.NewReader(strings.NewReader("data: [DONE]\n")),
 			closer: io.NopCloser(strings.NewReader("")),
 		}
 
@@ -0,0 +1,115 @@
+package vllmsemanticrouter
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/blue-context/warp"
+)
+
+// Classification is the router's intent classification for a piece of text.
+//
+// It reports the routing decision the router would make for a request,
+// without issuing a completion.
+type Classification struct {
+	// Category is the detected intent category (e.g., "math", "code").
+	Category string
+
+	// Confidence is the classifier's confidence in Category (0 to 1).
+	Confidence float64
+
+	// SelectedModel is the backend model the router would select.
+	SelectedModel string
+
+	// RoutingDecision describes how the model was chosen
+	// (e.g., "high_confidence_specialized").
+	RoutingDecision string
+
+	// Probabilities maps each category to its probability, when returned.
+	Probabilities map[string]float64
+
+	// ProcessingTime is the server-side classification latency.
+	ProcessingTime time.Duration
+}
+
+// classifyResponse is the intent classification API response.
+type classifyResponse struct {
+	Classification struct {
+		Category         string  `json:"category"`
+		Confidence       float64 `json:"confidence"`
+		ProcessingTimeMs int64   `json:"processing_time_ms"`
+	} `json:"classification"`
+	Probabilities    map[string]float64 `json:"probabilities,omitempty"`
+	RecommendedModel string             `json:"recommended_model"`
+	RoutingDecision  string             `json:"routing_decision"`
+}
+
+// Classify classifies text with the router's intent classifier.
+//
+// Wraps POST /api/v1/classify/intent on the classification URL. Use it to
+// inspect routing decisions or to pre-route requests before sending them.
+//
+// Example:
+//
+//	c, err := provider.Classify(ctx, "What is the derivative of x^2?")
+//	if err != nil {
+//	    return err
+//	}
+//	fmt.Printf("%s (%.2f) -> %s\n", c.Category, c.Confidence, c.SelectedModel)
+func (p *Provider) Classify(ctx context.Context, text string) (*Classification, error) {
+	if text == "" {
+		return nil, fmt.Errorf("text cannot be empty")
+	}
+
+	body, err := json.Marshal(map[string]any{
+		"text":    text,
+		"options": map[string]any{"return_probabilities": true},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	httpReq, err := http.NewRequestWithContext(ctx, "POST", p.classificationURL+"/api/v1/classify/intent", bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	httpReq.Header.Set("Content-Type", "application/json")
+	if p.apiKey != "" {
+		httpReq.Header.Set("Authorization", "Bearer "+p.apiKey)
+	}
+
+	// Add User-Agent and client request headers
+	warp.SetRequestHeaders(httpReq)
+
+	httpResp, err := p.httpClient.Do(httpReq)
+	if err != nil {
+		return nil, fmt.Errorf("failed to send request: %w", err)
+	}
+	warp.RecordResponse(httpResp)
+	defer httpResp.Body.Close()
+
+	if httpResp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(httpResp.Body)
+		return nil, warp.ParseProviderError("vllm-semantic-router", httpResp.StatusCode, body, nil)
+	}
+
+	var resp classifyResponse
+	if err := json.NewDecoder(httpResp.Body).Decode(&resp); err != nil {
+		return nil, fmt.Errorf("failed to decode response: %w", err)
+	}
+
+	return &Classification{
+		Category:        resp.Classification.Category,
+		Confidence:      resp.Classification.Confidence,
+		SelectedModel:   resp.RecommendedModel,
+		RoutingDecision: resp.RoutingDecision,
+		Probabilities:   resp.Probabilities,
+		ProcessingTime:  time.Duration(resp.Classification.ProcessingTimeMs) * time.Millisecond,
+	}, nil
+}
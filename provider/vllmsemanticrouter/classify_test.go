@@ -0,0 +1,96 @@
+package vllmsemanticrouter
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+// TestClassify tests the intent classification API
+func TestClassify(t *testing.T) {
+	mockClient := &mockHTTPClient{
+		doFunc: func(req *http.Request) (*http.Response, error) {
+			if req.URL.String() != "http://classifier:8080/api/v1/classify/intent" {
+				t.Errorf("request URL = %s, want classification endpoint", req.URL)
+			}
+			var body map[string]any
+			_ = json.NewDecoder(req.Body).Decode(&body)
+			if body["text"] != "What is 2+2?" {
+				t.Errorf("text = %v, want %q", body["text"], "What is 2+2?")
+			}
+
+			mockResp := `{
+				"classification": {"category": "math", "confidence": 0.956, "processing_time_ms": 45},
+				"probabilities": {"math": 0.956, "other": 0.044},
+				"recommended_model": "qwen-math",
+				"routing_decision": "high_confidence_specialized"
+			}`
+			return &http.Response{
+				StatusCode: http.StatusOK,
+				Body:       io.NopCloser(strings.NewReader(mockResp)),
+				Header:     make(http.Header),
+			}, nil
+		},
+	}
+
+	provider, err := NewProvider(
+		WithClassificationURL("http://classifier:8080"),
+		WithHTTPClient(mockClient),
+	)
+	if err != nil {
+		t.Fatalf("NewProvider() error = %v", err)
+	}
+
+	c, err := provider.Classify(context.Background(), "What is 2+2?")
+	if err != nil {
+		t.Fatalf("Classify() error = %v", err)
+	}
+
+	if c.Category != "math" {
+		t.Errorf("Category = %q, want %q", c.Category, "math")
+	}
+	if c.Confidence != 0.956 {
+		t.Errorf("Confidence = %v, want 0.956", c.Confidence)
+	}
+	if c.SelectedModel != "qwen-math" {
+		t.Errorf("SelectedModel = %q, want %q", c.SelectedModel, "qwen-math")
+	}
+	if c.RoutingDecision != "high_confidence_specialized" {
+		t.Errorf("RoutingDecision = %q", c.RoutingDecision)
+	}
+	if c.Probabilities["other"] != 0.044 {
+		t.Errorf("Probabilities = %v", c.Probabilities)
+	}
+	if c.ProcessingTime != 45*time.Millisecond {
+		t.Errorf("ProcessingTime = %v, want 45ms", c.ProcessingTime)
+	}
+}
+
+// TestClassifyErrors tests input validation and error responses
+func TestClassifyErrors(t *testing.T) {
+	mockClient := &mockHTTPClient{
+		doFunc: func(req *http.Request) (*http.Response, error) {
+			return &http.Response{
+				StatusCode: http.StatusServiceUnavailable,
+				Body:       io.NopCloser(strings.NewReader(`{"error": "classifier unavailable"}`)),
+				Header:     make(http.Header),
+			}, nil
+		},
+	}
+
+	provider, err := NewProvider(WithHTTPClient(mockClient))
+	if err != nil {
+		t.Fatalf("NewProvider() error = %v", err)
+	}
+
+	if _, err := provider.Classify(context.Background(), ""); err == nil {
+		t.Error("Classify(\"\") expected error")
+	}
+	if _, err := provider.Classify(context.Background(), "hello"); err == nil {
+		t.Error("Classify() expected error on 503")
+	}
+}
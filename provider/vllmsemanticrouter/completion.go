@@ -1,14 +1,11 @@
 package vllmsemanticrouter
 
 import (
-	"bytes"
 	"context"
-	"encoding/json"
 	"fmt"
-	"io"
-	"net/http"
 
 	"github.com/blue-context/warp"
+	"github.com/blue-context/warp/provider/providercore"
 )
 
 // Completion sends a chat completion request to vLLM Semantic Router.
@@ -44,41 +41,10 @@ func (p *Provider) Completion(ctx context.Context, req *warp.CompletionRequest)
 	// Transform request to OpenAI-compatible format
 	routerReq := transformRequest(req)
 
-	// Marshal to JSON
-	body, err := json.Marshal(routerReq)
-	if err != nil {
-		return nil, fmt.Errorf("failed to marshal request: %w", err)
-	}
-
-	// Create HTTP request
-	httpReq, err := http.NewRequestWithContext(ctx, "POST", p.baseURL+"/v1/chat/completions", bytes.NewReader(body))
-	if err != nil {
-		return nil, fmt.Errorf("failed to create request: %w", err)
-	}
-
-	// Set headers
-	httpReq.Header.Set("Content-Type", "application/json")
-	if p.apiKey != "" {
-		httpReq.Header.Set("Authorization", "Bearer "+p.apiKey)
-	}
-
-	// Send request
-	httpResp, err := p.httpClient.Do(httpReq)
-	if err != nil {
-		return nil, fmt.Errorf("failed to send request: %w", err)
-	}
-	defer httpResp.Body.Close()
-
-	// Check status code
-	if httpResp.StatusCode != http.StatusOK {
-		body, _ := io.ReadAll(httpResp.Body)
-		return nil, warp.ParseProviderError("vllm-semantic-router", httpResp.StatusCode, body, nil)
-	}
-
-	// Parse response
+	// Send request and parse response
 	var resp warp.CompletionResponse
-	if err := json.NewDecoder(httpResp.Body).Decode(&resp); err != nil {
-		return nil, fmt.Errorf("failed to decode response: %w", err)
+	if err := p.core().PostJSON(ctx, "/v1/chat/completions", routerReq, &resp); err != nil {
+		return nil, err
 	}
 
 	return &resp, nil
@@ -91,7 +57,7 @@ func (p *Provider) Completion(ctx context.Context, req *warp.CompletionRequest)
 func transformRequest(req *warp.CompletionRequest) map[string]any {
 	routerReq := map[string]any{
 		"model":    req.Model,
-		"messages": transformMessages(req.Messages),
+		"messages": providercore.Messages(req.Messages),
 	}
 
 	// Optional parameters
@@ -132,52 +98,3 @@ func transformRequest(req *warp.CompletionRequest) map[string]any {
 
 	return routerReq
 }
-
-// transformMessages transforms Warp messages to OpenAI-compatible format.
-//
-// This function handles all message types including text and multimodal content.
-func transformMessages(messages []warp.Message) []map[string]any {
-	routerMessages := make([]map[string]any, len(messages))
-
-	for i, msg := range messages {
-		routerMsg := map[string]any{
-			"role": msg.Role,
-		}
-
-		// Handle content (can be string or []ContentPart for multimodal)
-		switch content := msg.Content.(type) {
-		case string:
-			routerMsg["content"] = content
-		case []warp.ContentPart:
-			// Multimodal content
-			parts := make([]map[string]any, len(content))
-			for j, part := range content {
-				parts[j] = map[string]any{
-					"type": part.Type,
-				}
-				if part.Text != "" {
-					parts[j]["text"] = part.Text
-				}
-				if part.ImageURL != nil {
-					parts[j]["image_url"] = part.ImageURL
-				}
-			}
-			routerMsg["content"] = parts
-		}
-
-		// Optional fields
-		if msg.Name != "" {
-			routerMsg["name"] = msg.Name
-		}
-		if len(msg.ToolCalls) > 0 {
-			routerMsg["tool_calls"] = msg.ToolCalls
-		}
-		if msg.ToolCallID != "" {
-			routerMsg["tool_call_id"] = msg.ToolCallID
-		}
-
-		routerMessages[i] = routerMsg
-	}
-
-	return routerMessages
-}
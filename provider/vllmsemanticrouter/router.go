@@ -32,6 +32,7 @@ import (
 
 	"github.com/blue-context/warp"
 	"github.com/blue-context/warp/provider"
+	"github.com/blue-context/warp/provider/providercore"
 )
 
 // Provider implements the provider.Provider interface for vLLM Semantic Router.
@@ -149,6 +150,21 @@ func WithHTTPClient(client warp.HTTPClient) Option {
 	}
 }
 
+// core returns the HTTP client for the vLLM Semantic Router API.
+func (p *Provider) core() *providercore.Client {
+	return &providercore.Client{
+		Provider:   "vllm-semantic-router",
+		BaseURL:    p.baseURL,
+		HTTPClient: p.httpClient,
+		Prepare: func(req *http.Request) error {
+			if p.apiKey != "" {
+				req.Header.Set("Authorization", "Bearer "+p.apiKey)
+			}
+			return nil
+		},
+	}
+}
+
 // Name returns the provider name "vllm-semantic-router".
 //
 // This is used for provider identification in the registry and error messages.
@@ -11,6 +11,7 @@ import (
 
 	"github.com/blue-context/warp"
 	prov "github.com/blue-context/warp/provider"
+	"github.com/blue-context/warp/provider/providercore"
 )
 
 // mockHTTPClient is a mock HTTP client for testing
@@ -730,7 +731,7 @@ func TestTransformRequest(t *testing.T) {
 				if m["model"] != "auto" {
 					t.Errorf("model = %v, want auto", m["model"])
 				}
-				messages, ok := m["messages"].([]map[string]any)
+				messages, ok := m["messages"].([]providercore.Message)
 				if !ok || len(messages) != 1 {
 					t.Errorf("messages invalid or wrong length")
 				}
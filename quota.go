@@ -0,0 +1,45 @@
+package warp
+
+import (
+	"context"
+	"time"
+)
+
+// QuotaUsage is a provider account's consumption of an account-level quota,
+// as reported by the provider's usage or limits API.
+type QuotaUsage struct {
+	// Used is the amount consumed in the current quota period.
+	Used float64
+
+	// Limit is the quota for the period (0 means unlimited or unknown).
+	Limit float64
+
+	// Unit is the unit of Used and Limit (e.g., "usd", "tokens", "requests").
+	Unit string
+
+	// ResetsAt is when the quota period ends (zero if it does not reset).
+	ResetsAt time.Time
+}
+
+// Remaining returns the unused quota, or -1 if the quota is unlimited.
+func (u *QuotaUsage) Remaining() float64 {
+	if u.Limit <= 0 {
+		return -1
+	}
+	return max(u.Limit-u.Used, 0)
+}
+
+// Utilization returns the fraction of the quota used (0 if unlimited).
+func (u *QuotaUsage) Utilization() float64 {
+	if u.Limit <= 0 {
+		return 0
+	}
+	return u.Used / u.Limit
+}
+
+// QuotaReporter is implemented by providers whose API reports account-level
+// usage against a quota. See the quota package for scheduling on it.
+type QuotaReporter interface {
+	// QuotaUsage fetches the account's current quota usage.
+	QuotaUsage(ctx context.Context) (*QuotaUsage, error)
+}
@@ -0,0 +1,393 @@
+// Package quota schedules completion requests around account-level provider
+// quotas.
+//
+// A Scheduler periodically polls providers that report their quota usage
+// (warp.QuotaReporter, e.g. OpenRouter credit limits), projects when each
+// quota will be exhausted from the observed burn rate, and, as a request
+// middleware, throttles or reroutes requests to providers nearing their
+// quota. Status snapshots, including the projected exhaustion time, are
+// passed to a status handler after every poll for export as metrics.
+//
+// Basic usage:
+//
+//	scheduler := quota.New(
+//	    quota.WithReporter("openrouter", openrouterProvider),
+//	    quota.WithThrottle(0.8, 2),
+//	    quota.WithReroute("openrouter", "anthropic/claude-sonnet-4"),
+//	    quota.WithStatusHandler(func(s quota.Status) {
+//	        exhaustionGauge.Set(time.Until(s.ProjectedExhaustion).Seconds())
+//	    }),
+//	)
+//	go scheduler.Run(ctx)
+//
+//	client, err := warp.NewClient(
+//	    warp.WithRequestMiddleware(scheduler.Schedule),
+//	)
+package quota
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/blue-context/warp"
+)
+
+// Action is what the scheduler does with requests to a provider.
+type Action string
+
+const (
+	// ActionNone sends requests unchanged.
+	ActionNone Action = ""
+
+	// ActionThrottle limits the request rate to the provider.
+	ActionThrottle Action = "throttle"
+
+	// ActionReroute sends requests to the provider's reroute model.
+	ActionReroute Action = "reroute"
+)
+
+// maxSamples is the number of usage observations kept per provider for
+// burn rate estimation.
+const maxSamples = 10
+
+// Status is a snapshot of a provider's quota after a poll.
+type Status struct {
+	// Provider is the provider name.
+	Provider string
+
+	// Usage is the most recently reported usage.
+	Usage warp.QuotaUsage
+
+	// ObservedAt is when Usage was reported.
+	ObservedAt time.Time
+
+	// BurnRate is the observed consumption in Usage.Unit per hour.
+	BurnRate float64
+
+	// ProjectedExhaustion is when the quota runs out at the current burn
+	// rate. It is zero if the quota is unlimited, usage is not growing, or
+	// the quota resets first.
+	ProjectedExhaustion time.Time
+
+	// Action is applied to requests until the next poll.
+	Action Action
+
+	// Err is the error of the last poll, if it failed. The other fields
+	// then describe the last successful poll.
+	Err error
+}
+
+// sample is one usage observation.
+type sample struct {
+	at   time.Time
+	used float64
+}
+
+// providerState is the scheduler's view of one provider.
+type providerState struct {
+	samples []sample
+	status  Status
+	next    time.Time // earliest admission while throttled
+}
+
+// Scheduler polls provider quotas and throttles or reroutes requests.
+//
+// Thread Safety: Scheduler is safe for concurrent use.
+type Scheduler struct {
+	reporters    map[string]warp.QuotaReporter
+	reroutes     map[string]string
+	interval     time.Duration
+	throttleAt   float64
+	throttleRate float64
+	rerouteAt    float64
+	horizon      time.Duration
+	onStatus     func(Status)
+	clock        warp.Clock
+
+	mu     sync.Mutex
+	states map[string]*providerState
+}
+
+// Option configures a Scheduler.
+type Option func(*Scheduler)
+
+// WithReporter adds a provider to poll. name must match the provider
+// prefix used in request models.
+func WithReporter(name string, reporter warp.QuotaReporter) Option {
+	return func(s *Scheduler) {
+		s.reporters[name] = reporter
+	}
+}
+
+// WithInterval sets how often quotas are polled by Run. Non-positive
+// durations keep the default.
+//
+// Default: 1 minute
+func WithInterval(d time.Duration) Option {
+	return func(s *Scheduler) {
+		if d > 0 {
+			s.interval = d
+		}
+	}
+}
+
+// WithClock sets the scheduler's source of time for usage observations,
+// throttling waits, and polling, so tests can use a fake clock such as
+// warptest.Clock.
+//
+// Default: the system clock
+func WithClock(clock warp.Clock) Option {
+	return func(s *Scheduler) {
+		s.clock = clock
+	}
+}
+
+// WithThrottle throttles providers whose quota utilization reaches
+// utilization (0 to 1) to requestsPerSecond. Requests wait for their turn,
+// or fail if their context ends first.
+//
+// Default: 0.9 utilization, 1 request per second
+func WithThrottle(utilization, requestsPerSecond float64) Option {
+	return func(s *Scheduler) {
+		s.throttleAt = utilization
+		s.throttleRate = requestsPerSecond
+	}
+}
+
+// WithExhaustionHorizon also throttles providers projected to exhaust
+// their quota within d, regardless of utilization.
+func WithExhaustionHorizon(d time.Duration) Option {
+	return func(s *Scheduler) {
+		s.horizon = d
+	}
+}
+
+// WithReroute sends requests for provider to model ("provider/model-name")
+// once the provider's quota utilization reaches the reroute threshold.
+func WithReroute(provider, model string) Option {
+	return func(s *Scheduler) {
+		s.reroutes[provider] = model
+	}
+}
+
+// WithRerouteThreshold sets the quota utilization (0 to 1) at which
+// requests are rerouted.
+//
+// Default: 0.98
+func WithRerouteThreshold(utilization float64) Option {
+	return func(s *Scheduler) {
+		s.rerouteAt = utilization
+	}
+}
+
+// WithStatusHandler sets a function called with each provider's Status
+// after every poll, e.g. to export quota metrics.
+func WithStatusHandler(fn func(Status)) Option {
+	return func(s *Scheduler) {
+		s.onStatus = fn
+	}
+}
+
+// New creates a scheduler.
+func New(opts ...Option) *Scheduler {
+	s := &Scheduler{
+		reporters:    make(map[string]warp.QuotaReporter),
+		reroutes:     make(map[string]string),
+		interval:     time.Minute,
+		throttleAt:   0.9,
+		throttleRate: 1,
+		rerouteAt:    0.98,
+		states:       make(map[string]*providerState),
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+// Run polls quotas immediately and then at the configured interval until
+// ctx is done.
+func (s *Scheduler) Run(ctx context.Context) {
+	for {
+		s.Poll(ctx)
+		if err := s.wait(ctx, s.interval); err != nil {
+			return
+		}
+	}
+}
+
+// Poll fetches the quota usage of every provider once, updates their
+// status, and calls the status handler. It returns the joined poll errors.
+func (s *Scheduler) Poll(ctx context.Context) error {
+	names := make([]string, 0, len(s.reporters))
+	for name := range s.reporters {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var errs []error
+	for _, name := range names {
+		usage, err := s.reporters[name].QuotaUsage(ctx)
+		if err == nil && usage == nil {
+			err = errors.New("reporter returned no usage")
+		}
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", name, err))
+		}
+		status := s.observe(name, usage, err)
+		if s.onStatus != nil {
+			s.onStatus(status)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// observe records a poll result and returns the provider's new status.
+func (s *Scheduler) observe(name string, usage *warp.QuotaUsage, err error) Status {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	st, ok := s.states[name]
+	if !ok {
+		st = &providerState{status: Status{Provider: name}}
+		s.states[name] = st
+	}
+	if err != nil {
+		st.status.Err = err
+		return st.status
+	}
+
+	now := s.now()
+	if n := len(st.samples); n > 0 && usage.Used < st.samples[n-1].used {
+		st.samples = nil // the quota period reset
+	}
+	st.samples = append(st.samples, sample{at: now, used: usage.Used})
+	if len(st.samples) > maxSamples {
+		st.samples = st.samples[len(st.samples)-maxSamples:]
+	}
+
+	status := Status{Provider: name, Usage: *usage, ObservedAt: now}
+	first, last := st.samples[0], st.samples[len(st.samples)-1]
+	if hours := last.at.Sub(first.at).Hours(); hours > 0 {
+		status.BurnRate = (last.used - first.used) / hours
+	}
+	if status.BurnRate > 0 && usage.Limit > 0 {
+		remaining := time.Duration(usage.Remaining() / status.BurnRate * float64(time.Hour))
+		exhaustion := now.Add(remaining)
+		if usage.ResetsAt.IsZero() || exhaustion.Before(usage.ResetsAt) {
+			status.ProjectedExhaustion = exhaustion
+		}
+	}
+	status.Action = s.action(name, status)
+
+	st.status = status
+	return status
+}
+
+// action decides how requests to a provider are handled given its status.
+func (s *Scheduler) action(name string, status Status) Action {
+	if status.Usage.Limit <= 0 {
+		return ActionNone
+	}
+	utilization := status.Usage.Utilization()
+	if _, ok := s.reroutes[name]; ok && utilization >= s.rerouteAt {
+		return ActionReroute
+	}
+	if utilization >= s.throttleAt {
+		return ActionThrottle
+	}
+	if s.horizon > 0 && !status.ProjectedExhaustion.IsZero() && status.ProjectedExhaustion.Sub(status.ObservedAt) <= s.horizon {
+		return ActionThrottle
+	}
+	return ActionNone
+}
+
+// Status returns the last status of a provider, and false if it has not
+// been polled.
+func (s *Scheduler) Status(provider string) (Status, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	st, ok := s.states[provider]
+	if !ok {
+		return Status{}, false
+	}
+	return st.status, true
+}
+
+// Schedule is a warp.RequestMiddleware that throttles or reroutes requests
+// to providers nearing their quota. Requests to providers without a
+// reporter pass through unchanged.
+func (s *Scheduler) Schedule(ctx context.Context, req *warp.CompletionRequest) (*warp.CompletionRequest, error) {
+	providerName, _, _ := strings.Cut(req.Model, "/")
+
+	s.mu.Lock()
+	st, ok := s.states[providerName]
+	if !ok {
+		s.mu.Unlock()
+		return req, nil
+	}
+
+	switch st.status.Action {
+	case ActionReroute:
+		s.mu.Unlock()
+		r := *req
+		r.Model = s.reroutes[providerName]
+		return &r, nil
+
+	case ActionThrottle:
+		now := s.now()
+		admit := st.next
+		if admit.Before(now) {
+			admit = now
+		}
+		if s.throttleRate > 0 {
+			st.next = admit.Add(time.Duration(float64(time.Second) / s.throttleRate))
+		}
+		s.mu.Unlock()
+
+		if err := s.wait(ctx, admit.Sub(now)); err != nil {
+			return nil, err
+		}
+		return req, nil
+	}
+
+	s.mu.Unlock()
+	return req, nil
+}
+
+// now returns the current time on the scheduler's clock.
+func (s *Scheduler) now() time.Time {
+	if s.clock != nil {
+		return s.clock.Now()
+	}
+	return time.Now()
+}
+
+// wait waits d on the scheduler's clock or until ctx is done.
+func (s *Scheduler) wait(ctx context.Context, d time.Duration) error {
+	if d <= 0 {
+		return nil
+	}
+	var fired <-chan time.Time
+	if s.clock != nil {
+		timer := s.clock.NewTimer(d)
+		defer timer.Stop()
+		fired = timer.C()
+	} else {
+		timer := time.NewTimer(d)
+		defer timer.Stop()
+		fired = timer.C
+	}
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-fired:
+		return nil
+	}
+}
@@ -0,0 +1,219 @@
+package quota
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/blue-context/warp"
+	"github.com/blue-context/warp/warptest"
+)
+
+// fakeReporter reports usage from a field the test updates.
+type fakeReporter struct {
+	usage warp.QuotaUsage
+	err   error
+}
+
+func (f *fakeReporter) QuotaUsage(ctx context.Context) (*warp.QuotaUsage, error) {
+	if f.err != nil {
+		return nil, f.err
+	}
+	usage := f.usage
+	return &usage, nil
+}
+
+func newTestScheduler(reporter *fakeReporter, opts ...Option) (*Scheduler, *warptest.Clock) {
+	clock := warptest.NewClock(time.Unix(1_700_000_000, 0))
+	s := New(append([]Option{WithReporter("openrouter", reporter), WithClock(clock)}, opts...)...)
+	return s, clock
+}
+
+func request() *warp.CompletionRequest {
+	return &warp.CompletionRequest{Model: "openrouter/openai/gpt-4o", Messages: []warp.Message{{Role: "user", Content: "Hi"}}}
+}
+
+func TestProjectedExhaustion(t *testing.T) {
+	reporter := &fakeReporter{usage: warp.QuotaUsage{Used: 10, Limit: 100, Unit: "usd"}}
+	var statuses []Status
+	s, clock := newTestScheduler(reporter, WithStatusHandler(func(st Status) {
+		statuses = append(statuses, st)
+	}))
+
+	if err := s.Poll(context.Background()); err != nil {
+		t.Fatalf("Poll() error = %v", err)
+	}
+	clock.Advance(time.Hour)
+	reporter.usage.Used = 20
+	s.Poll(context.Background())
+
+	status, ok := s.Status("openrouter")
+	if !ok {
+		t.Fatal("Status() not found")
+	}
+	if status.BurnRate != 10 {
+		t.Errorf("BurnRate = %v, want 10 per hour", status.BurnRate)
+	}
+	if want := clock.Now().Add(8 * time.Hour); !status.ProjectedExhaustion.Equal(want) {
+		t.Errorf("ProjectedExhaustion = %v, want %v", status.ProjectedExhaustion, want)
+	}
+	if status.Action != ActionNone {
+		t.Errorf("Action = %q, want none", status.Action)
+	}
+	if len(statuses) != 2 {
+		t.Errorf("status handler called %d times, want 2", len(statuses))
+	}
+
+	// A quota that resets before exhaustion is not projected to run out
+	reporter.usage.ResetsAt = clock.Now().Add(2 * time.Hour)
+	clock.Advance(time.Hour)
+	reporter.usage.Used = 30
+	s.Poll(context.Background())
+	if status, _ := s.Status("openrouter"); !status.ProjectedExhaustion.IsZero() {
+		t.Errorf("ProjectedExhaustion = %v, want zero before reset", status.ProjectedExhaustion)
+	}
+
+	// Usage dropping starts a new period
+	clock.Advance(time.Hour)
+	reporter.usage.Used = 1
+	s.Poll(context.Background())
+	if status, _ := s.Status("openrouter"); status.BurnRate != 0 {
+		t.Errorf("BurnRate after reset = %v, want 0", status.BurnRate)
+	}
+}
+
+func TestScheduleReroute(t *testing.T) {
+	reporter := &fakeReporter{usage: warp.QuotaUsage{Used: 99, Limit: 100}}
+	s, _ := newTestScheduler(reporter, WithReroute("openrouter", "anthropic/claude-sonnet-4"))
+	s.Poll(context.Background())
+
+	req := request()
+	got, err := s.Schedule(context.Background(), req)
+	if err != nil {
+		t.Fatalf("Schedule() error = %v", err)
+	}
+	if got.Model != "anthropic/claude-sonnet-4" {
+		t.Errorf("Model = %q, want rerouted", got.Model)
+	}
+	if req.Model != "openrouter/openai/gpt-4o" {
+		t.Error("Schedule() modified the request")
+	}
+}
+
+func TestScheduleThrottle(t *testing.T) {
+	reporter := &fakeReporter{usage: warp.QuotaUsage{Used: 95, Limit: 100}}
+	s, clock := newTestScheduler(reporter, WithThrottle(0.9, 1))
+	s.Poll(context.Background())
+
+	if status, _ := s.Status("openrouter"); status.Action != ActionThrottle {
+		t.Fatalf("Action = %q, want throttle", status.Action)
+	}
+
+	// The first request is admitted immediately; the second waits a
+	// second on the scheduler's clock.
+	if _, err := s.Schedule(context.Background(), request()); err != nil {
+		t.Fatalf("Schedule() error = %v", err)
+	}
+	done := make(chan error, 1)
+	go func() {
+		_, err := s.Schedule(context.Background(), request())
+		done <- err
+	}()
+	clock.BlockUntil(1)
+	select {
+	case err := <-done:
+		t.Fatalf("Schedule() returned %v before its turn", err)
+	default:
+	}
+	clock.Advance(time.Second)
+	if err := <-done; err != nil {
+		t.Errorf("Schedule() error = %v", err)
+	}
+
+	// A request whose context ends first fails
+	ctx, cancel := context.WithCancel(context.Background())
+	go func() {
+		clock.BlockUntil(1)
+		cancel()
+	}()
+	if _, err := s.Schedule(ctx, request()); !errors.Is(err, context.Canceled) {
+		t.Errorf("Schedule() error = %v, want canceled while throttled", err)
+	}
+
+	// Providers without a reporter pass through
+	other := &warp.CompletionRequest{Model: "openai/gpt-4o"}
+	if got, err := s.Schedule(ctx, other); err != nil || got != other {
+		t.Errorf("Schedule() = %v, %v, want request unchanged", got, err)
+	}
+}
+
+func TestExhaustionHorizon(t *testing.T) {
+	reporter := &fakeReporter{usage: warp.QuotaUsage{Used: 10, Limit: 100}}
+	s, clock := newTestScheduler(reporter, WithExhaustionHorizon(12*time.Hour))
+	s.Poll(context.Background())
+	clock.Advance(time.Hour)
+	reporter.usage.Used = 20
+	s.Poll(context.Background())
+
+	if status, _ := s.Status("openrouter"); status.Action != ActionThrottle {
+		t.Errorf("Action = %q, want throttle when exhaustion is projected within the horizon", status.Action)
+	}
+}
+
+func TestPollNilUsage(t *testing.T) {
+	s := New(WithReporter("openrouter", nilReporter{}))
+	if err := s.Poll(context.Background()); err == nil {
+		t.Error("Poll() error = nil, want error for missing usage")
+	}
+	if status, _ := s.Status("openrouter"); status.Err == nil {
+		t.Errorf("Status() = %+v, want error", status)
+	}
+}
+
+// nilReporter reports neither usage nor an error.
+type nilReporter struct{}
+
+func (nilReporter) QuotaUsage(ctx context.Context) (*warp.QuotaUsage, error) {
+	return nil, nil
+}
+
+func TestRun(t *testing.T) {
+	reporter := &fakeReporter{usage: warp.QuotaUsage{Used: 10, Limit: 100}}
+	polls := make(chan Status, 3)
+	s, clock := newTestScheduler(reporter, WithInterval(0), WithStatusHandler(func(st Status) {
+		polls <- st
+	}))
+	if s.interval != time.Minute {
+		t.Errorf("interval = %v, want the default for WithInterval(0)", s.interval)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		s.Run(ctx)
+		close(done)
+	}()
+	<-polls
+	clock.BlockUntil(1)
+	clock.Advance(time.Minute)
+	<-polls
+	clock.BlockUntil(1)
+	cancel()
+	<-done
+}
+
+func TestPollError(t *testing.T) {
+	reporter := &fakeReporter{usage: warp.QuotaUsage{Used: 10, Limit: 100}}
+	s, _ := newTestScheduler(reporter)
+	s.Poll(context.Background())
+
+	reporter.err = errors.New("unavailable")
+	if err := s.Poll(context.Background()); err == nil {
+		t.Fatal("Poll() error = nil, want error")
+	}
+	status, _ := s.Status("openrouter")
+	if status.Err == nil || status.Usage.Used != 10 {
+		t.Errorf("Status() = %+v, want last usage with error", status)
+	}
+}
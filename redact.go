@@ -0,0 +1,154 @@
+package warp
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"maps"
+	"slices"
+	"unicode/utf8"
+)
+
+// RedactMode controls how Redact treats message content.
+type RedactMode string
+
+const (
+	// RedactStrip removes content (default).
+	RedactStrip RedactMode = ""
+
+	// RedactHash replaces content with "sha256:" and the first 16 hex
+	// digits of its SHA-256 hash, so equal content can be correlated
+	// across log lines without being readable.
+	RedactHash RedactMode = "hash"
+
+	// RedactKeep leaves content as it is.
+	RedactKeep RedactMode = "keep"
+)
+
+// RedactPolicy controls what Redact removes from a response.
+type RedactPolicy struct {
+	// Content is how message content, reasoning, and citation text are
+	// redacted.
+	// Token logprobs, provider fields, and message metadata, which may
+	// hold content too, are removed unless Content is RedactKeep.
+	Content RedactMode
+
+	// MaxToolArguments is the number of bytes of tool call arguments
+	// kept; longer arguments are cut and end with "...". Zero removes the
+	// arguments and a negative value keeps them whole. Tool names are
+	// always kept.
+	MaxToolArguments int
+}
+
+// DefaultRedactPolicy hashes content and keeps the first 256 bytes of tool
+// arguments. It is the policy of the debug mode logger.
+var DefaultRedactPolicy = RedactPolicy{Content: RedactHash, MaxToolArguments: 256}
+
+// Redact returns a copy of resp that is safe to log: message content is
+// stripped or hashed and tool arguments truncated according to policy,
+// while IDs, model, usage, finish reasons, and hidden params are kept. resp
+// is not modified. Redact returns nil for a nil response.
+//
+// Example:
+//
+//	warp.WithSuccessCallback(func(ctx context.Context, event *callback.SuccessEvent) {
+//	    resp := warp.Redact(event.Response.(*warp.CompletionResponse), warp.DefaultRedactPolicy)
+//	    data, _ := json.Marshal(resp)
+//	    log.Printf("completion %s: %s", event.RequestID, data)
+//	})
+func Redact(resp *CompletionResponse, policy RedactPolicy) *CompletionResponse {
+	if resp == nil {
+		return nil
+	}
+
+	out := *resp
+	out.HiddenParams = maps.Clone(resp.HiddenParams)
+	if resp.Usage != nil {
+		usage := *resp.Usage
+		out.Usage = &usage
+	}
+	if policy.Content != RedactKeep {
+		out.ProviderFields = nil
+		out.PromptLogprobs = nil
+	}
+
+	out.Choices = slices.Clone(resp.Choices)
+	for i := range out.Choices {
+		choice := &out.Choices[i]
+		choice.Message = redactMessage(choice.Message, policy)
+		if policy.Content != RedactKeep {
+			choice.Logprobs = nil
+		}
+	}
+
+	out.Citations = slices.Clone(resp.Citations)
+	for i := range out.Citations {
+		out.Citations[i].Text = redactText(out.Citations[i].Text, policy.Content)
+	}
+
+	return &out
+}
+
+// redactMessage returns a copy of msg redacted according to policy.
+func redactMessage(msg Message, policy RedactPolicy) Message {
+	switch content := msg.Content.(type) {
+	case string:
+		msg.Content = redactText(content, policy.Content)
+	case []ContentPart:
+		parts := make([]ContentPart, len(content))
+		for i, part := range content {
+			parts[i] = ContentPart{Type: part.Type, Text: redactText(part.Text, policy.Content)}
+			if part.ImageURL != nil {
+				parts[i].ImageURL = &ImageURL{URL: redactText(part.ImageURL.URL, policy.Content), Detail: part.ImageURL.Detail}
+			}
+		}
+		msg.Content = parts
+	case nil:
+	default:
+		if policy.Content != RedactKeep {
+			msg.Content = nil
+		}
+	}
+
+	msg.ReasoningContent = redactText(msg.ReasoningContent, policy.Content)
+
+	msg.ToolCalls = slices.Clone(msg.ToolCalls)
+	for i := range msg.ToolCalls {
+		args := &msg.ToolCalls[i].Function.Arguments
+		*args = truncateArguments(*args, policy.MaxToolArguments)
+	}
+	if policy.Content != RedactKeep {
+		msg.Metadata = nil
+	} else {
+		msg.Metadata = maps.Clone(msg.Metadata)
+	}
+	return msg
+}
+
+// redactText strips or hashes text. Empty text stays empty.
+func redactText(text string, mode RedactMode) string {
+	switch {
+	case text == "" || mode == RedactKeep:
+		return text
+	case mode == RedactHash:
+		sum := sha256.Sum256([]byte(text))
+		return "sha256:" + hex.EncodeToString(sum[:8])
+	default:
+		return ""
+	}
+}
+
+// truncateArguments cuts args to at most max bytes without splitting a
+// UTF-8 sequence.
+func truncateArguments(args string, max int) string {
+	if max < 0 || len(args) <= max {
+		return args
+	}
+	if max == 0 {
+		return ""
+	}
+	cut := max
+	for cut > 0 && !utf8.RuneStart(args[cut]) {
+		cut--
+	}
+	return args[:cut] + "..."
+}
@@ -0,0 +1,83 @@
+package warp
+
+import (
+	"strings"
+	"testing"
+)
+
+func redactTestResponse() *CompletionResponse {
+	return &CompletionResponse{
+		ID:    "chatcmpl-1",
+		Model: "gpt-4o",
+		Choices: []Choice{{
+			Message: Message{
+				Role:             "assistant",
+				Content:          "my SSN is 123-45-6789",
+				ReasoningContent: "my SSN is 123-45-6789",
+				ToolCalls: []ToolCall{{
+					ID:       "call_1",
+					Type:     "function",
+					Function: FunctionCall{Name: "lookup", Arguments: `{"name":"Zoë Ünal"}`},
+				}},
+			},
+			FinishReason: "tool_calls",
+			Logprobs:     &Logprobs{Content: []TokenLogprob{{Token: "my", Logprob: -0.1}}},
+		}},
+		Usage:          &Usage{TotalTokens: 42},
+		ProviderFields: map[string]any{"reasoning_content": "secret"},
+		HiddenParams:   map[string]any{"_attempts": 1},
+	}
+}
+
+func TestRedact(t *testing.T) {
+	tests := []struct {
+		name     string
+		policy   RedactPolicy
+		wantText string
+		wantArgs string
+	}{
+		{"strip", RedactPolicy{}, "", ""},
+		{"hash", RedactPolicy{Content: RedactHash, MaxToolArguments: 12}, "sha256:", `{"name":"Zo...`},
+		{"keep", RedactPolicy{Content: RedactKeep, MaxToolArguments: -1}, "my SSN is 123-45-6789", `{"name":"Zoë Ünal"}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp := redactTestResponse()
+			got := Redact(resp, tt.policy)
+
+			text := got.Choices[0].Message.Content.(string)
+			if !strings.HasPrefix(text, tt.wantText) || (tt.policy.Content == RedactHash && len(text) != len("sha256:")+16) {
+				t.Errorf("content = %q, want %q", text, tt.wantText)
+			}
+			if reasoning := got.Choices[0].Message.ReasoningContent; reasoning != text {
+				t.Errorf("reasoning = %q, want %q like the equal content", reasoning, text)
+			}
+			call := got.Choices[0].Message.ToolCalls[0]
+			if call.Function.Arguments != tt.wantArgs || call.Function.Name != "lookup" {
+				t.Errorf("tool call = %+v, want arguments %q", call, tt.wantArgs)
+			}
+			if got.Usage.TotalTokens != 42 || got.HiddenParams["_attempts"] != 1 || got.Choices[0].FinishReason != "tool_calls" {
+				t.Errorf("Redact() = %+v, want usage and metadata kept", got)
+			}
+			kept := tt.policy.Content == RedactKeep
+			if (got.ProviderFields != nil) != kept || (got.Choices[0].Logprobs != nil) != kept {
+				t.Errorf("provider fields = %v, logprobs = %v, want kept only with RedactKeep", got.ProviderFields, got.Choices[0].Logprobs)
+			}
+
+			// The original is unchanged
+			if resp.Choices[0].Message.Content != "my SSN is 123-45-6789" || resp.Choices[0].Message.ToolCalls[0].Function.Arguments != `{"name":"Zoë Ünal"}` {
+				t.Errorf("original modified: %+v", resp.Choices[0].Message)
+			}
+		})
+	}
+
+	// Equal content hashes equally
+	a := Redact(&CompletionResponse{Choices: []Choice{{Message: Message{Content: "x"}}}}, RedactPolicy{Content: RedactHash})
+	b := Redact(&CompletionResponse{Choices: []Choice{{Message: Message{Content: "x"}}}}, RedactPolicy{Content: RedactHash})
+	if a.Choices[0].Message.Content != b.Choices[0].Message.Content {
+		t.Error("hashes of equal content differ")
+	}
+	if Redact(nil, DefaultRedactPolicy) != nil {
+		t.Error("Redact(nil) != nil")
+	}
+}
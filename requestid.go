@@ -0,0 +1,109 @@
+package warp
+
+import (
+	"context"
+	"net/http"
+	"sync"
+)
+
+// RequestIDHeader is the header that carries the warp request ID to
+// providers, so provider-side logs can be correlated with warp's.
+const RequestIDHeader = "X-Request-ID"
+
+// providerRequestIDHeaders are the response headers providers return their
+// own request ID in: OpenAI, Groq and most compatible APIs use
+// x-request-id, Anthropic request-id, Bedrock x-amzn-requestid, and Azure
+// apim-request-id.
+var providerRequestIDHeaders = []string{"X-Request-Id", "Request-Id", "X-Amzn-Requestid", "Apim-Request-Id"}
+
+// contextKeyProviderRequestID carries the provider request ID recorder.
+const contextKeyProviderRequestID contextKey = "warp_provider_request_id"
+
+// providerRequestID records the request ID of the last provider response.
+type providerRequestID struct {
+	mu sync.Mutex
+	id string
+}
+
+// withProviderRequestID adds a recorder for the provider request ID to
+// ctx, unless it already has one.
+func withProviderRequestID(ctx context.Context) context.Context {
+	if _, ok := ctx.Value(contextKeyProviderRequestID).(*providerRequestID); ok {
+		return ctx
+	}
+	return context.WithValue(ctx, contextKeyProviderRequestID, &providerRequestID{})
+}
+
+// ProviderRequestIDFromContext returns the request ID the provider
+// returned for the request made with ctx, or an empty string if the
+// provider has not responded or returns none. After retries it is the ID
+// of the last response.
+//
+// The client records it for the contexts it passes to providers and
+// callbacks; responses also carry it in their ProviderRequestID field.
+//
+// Example:
+//
+//	client, _ := warp.NewClient(
+//	    warp.WithFailureCallback(func(ctx context.Context, event *callback.FailureEvent) {
+//	        log.Printf("request %s failed (provider request %s): %v",
+//	            event.RequestID, warp.ProviderRequestIDFromContext(ctx), event.Error)
+//	    }),
+//	)
+func ProviderRequestIDFromContext(ctx context.Context) string {
+	recorder, ok := ctx.Value(contextKeyProviderRequestID).(*providerRequestID)
+	if !ok {
+		return ""
+	}
+	recorder.mu.Lock()
+	defer recorder.mu.Unlock()
+	return recorder.id
+}
+
+// RecordResponse records the provider request ID from the headers of a
+// provider HTTP response, so the client can return it with the response,
+// limits the size of the response body (see WithMaxResponseSize), and
+// captures the body if payload tracing is enabled (see
+// WithPayloadTracing). Error responses are recorded too, since their IDs
+// are the ones support needs.
+//
+// Providers call it on every HTTP response they receive, before reading
+// the body; the providercore package does so automatically.
+func RecordResponse(resp *http.Response) {
+	if resp == nil || resp.Request == nil {
+		return
+	}
+	ctx := resp.Request.Context()
+	limitResponse(ctx, resp)
+	if trace := payloadTraceFromContext(ctx); trace != nil {
+		trace.traceResponse(resp)
+	}
+	recorder, ok := ctx.Value(contextKeyProviderRequestID).(*providerRequestID)
+	if !ok {
+		return
+	}
+	for _, header := range providerRequestIDHeaders {
+		if id := resp.Header.Get(header); id != "" {
+			recorder.mu.Lock()
+			recorder.id = id
+			recorder.mu.Unlock()
+			return
+		}
+	}
+}
+
+// requestIDStream sets the warp and provider request IDs on the chunks of
+// a stream.
+type requestIDStream struct {
+	Stream
+	ctx context.Context
+}
+
+func (s *requestIDStream) Recv() (*CompletionChunk, error) {
+	chunk, err := s.Stream.Recv()
+	if chunk != nil {
+		chunk.RequestID = RequestIDFromContext(s.ctx)
+		chunk.ProviderRequestID = ProviderRequestIDFromContext(s.ctx)
+	}
+	return chunk, err
+}
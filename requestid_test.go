@@ -0,0 +1,105 @@
+package warp
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestRequestIDPropagation(t *testing.T) {
+	var sent string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		sent = r.Header.Get(RequestIDHeader)
+		w.Header().Set("Request-Id", "req_provider_123")
+	}))
+	defer server.Close()
+
+	call := func(ctx context.Context) error {
+		httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, server.URL, nil)
+		if err != nil {
+			return err
+		}
+		SetRequestHeaders(httpReq)
+		httpResp, err := server.Client().Do(httpReq)
+		if err != nil {
+			return err
+		}
+		defer httpResp.Body.Close()
+		RecordResponse(httpResp)
+		_, err = io.Copy(io.Discard, httpResp.Body)
+		return err
+	}
+
+	client, err := NewClient()
+	if err != nil {
+		t.Fatal(err)
+	}
+	client.RegisterProvider(&mockProvider{
+		name: "mock",
+		completionFunc: func(ctx context.Context, req *CompletionRequest) (*CompletionResponse, error) {
+			return &CompletionResponse{ID: "test"}, call(ctx)
+		},
+		embeddingFunc: func(ctx context.Context, req *EmbeddingRequest) (*EmbeddingResponse, error) {
+			return &EmbeddingResponse{}, call(ctx)
+		},
+	})
+
+	ctx := WithRequestID(context.Background(), "req-abc")
+	resp, err := client.Completion(ctx, &CompletionRequest{
+		Model:    "mock/model",
+		Messages: []Message{{Role: "user", Content: "Hi"}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sent != "req-abc" {
+		t.Errorf("%s header = %q, want %q", RequestIDHeader, sent, "req-abc")
+	}
+	if resp.RequestID != "req-abc" || resp.ProviderRequestID != "req_provider_123" {
+		t.Errorf("RequestID, ProviderRequestID = %q, %q, want req-abc, req_provider_123", resp.RequestID, resp.ProviderRequestID)
+	}
+
+	embedding, err := client.Embedding(context.Background(), &EmbeddingRequest{Model: "mock/model", Input: "Hi"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if embedding.RequestID == "" || sent != embedding.RequestID {
+		t.Errorf("RequestID = %q, sent %q, want generated ID sent to provider", embedding.RequestID, sent)
+	}
+	if embedding.ProviderRequestID != "req_provider_123" {
+		t.Errorf("ProviderRequestID = %q, want req_provider_123", embedding.ProviderRequestID)
+	}
+}
+
+func TestRequestIDStream(t *testing.T) {
+	client, err := NewClient()
+	if err != nil {
+		t.Fatal(err)
+	}
+	client.RegisterProvider(&mockProvider{
+		name: "mock",
+		completionStreamFunc: func(ctx context.Context, req *CompletionRequest) (Stream, error) {
+			return &mockStream{chunks: []*CompletionChunk{{ID: "1"}}}, nil
+		},
+	})
+
+	ctx := WithRequestID(context.Background(), "req-abc")
+	stream, err := client.CompletionStream(ctx, &CompletionRequest{
+		Model:    "mock/model",
+		Messages: []Message{{Role: "user", Content: "Hi"}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer stream.Close()
+
+	chunk, err := stream.Recv()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if chunk.RequestID != "req-abc" {
+		t.Errorf("chunk RequestID = %q, want req-abc", chunk.RequestID)
+	}
+}
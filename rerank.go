@@ -3,7 +3,8 @@ package warp
 import (
 	"context"
 	"fmt"
-	"time"
+	"sort"
+	"strings"
 )
 
 // Rerank ranks documents by relevance to a query.
@@ -65,9 +66,12 @@ func (c *client) Rerank(ctx context.Context, req *RerankRequest) (*RerankRespons
 	if req.Query == "" {
 		return nil, fmt.Errorf("query is required")
 	}
-	if len(req.Documents) == 0 {
+	if len(req.Documents) == 0 && len(req.StructuredDocuments) == 0 {
 		return nil, fmt.Errorf("documents are required")
 	}
+	if len(req.Documents) > 0 && len(req.StructuredDocuments) > 0 {
+		return nil, fmt.Errorf("documents and structured documents cannot both be set")
+	}
 
 	// Add request ID to context
 	if RequestIDFromContext(ctx) == "" {
@@ -75,7 +79,7 @@ func (c *client) Rerank(ctx context.Context, req *RerankRequest) (*RerankRespons
 	}
 
 	// Add start time to context
-	ctx = WithStartTime(ctx, time.Now())
+	ctx = WithStartTime(ctx, c.now())
 
 	// Parse model string
 	providerName, modelName, err := parseModel(req.Model)
@@ -86,10 +90,14 @@ func (c *client) Rerank(ctx context.Context, req *RerankRequest) (*RerankRespons
 	// Add provider and model to context
 	ctx = WithProvider(ctx, providerName)
 	ctx = WithModel(ctx, modelName)
+	ctx = c.withRequestHeaders(ctx)
 
 	// Get provider
 	p, err := c.getProvider(providerName)
 	if err != nil {
+		if c.config.RerankEmbeddingModel != "" {
+			return c.rerankWithEmbeddings(ctx, req)
+		}
 		return nil, fmt.Errorf("provider %q not found (did you register it?)", providerName)
 	}
 
@@ -122,25 +130,33 @@ func (c *client) Rerank(ctx context.Context, req *RerankRequest) (*RerankRespons
 	}
 
 	if !supportsRerank {
+		if c.config.RerankEmbeddingModel != "" {
+			return c.rerankWithEmbeddings(ctx, req)
+		}
 		return nil, fmt.Errorf("provider %q does not support rerank", providerName)
 	}
 
+	// Send structured documents as text to providers that rank strings
+	req = structuredRerankRequest(req)
+
 	// Update model name in request
 	req.Model = modelName
 
 	// Apply timeout
 	if c.config.DefaultTimeout > 0 {
 		var cancel context.CancelFunc
-		ctx, cancel = context.WithTimeout(ctx, c.config.DefaultTimeout)
+		ctx, cancel = c.withTimeout(ctx, c.config.DefaultTimeout)
 		defer cancel()
 	}
 
 	// Call provider with retries
 	var resp *RerankResponse
 	err = c.withRetry(ctx, func() error {
-		var callErr error
-		resp, callErr = p.Rerank(ctx, req)
-		return callErr
+		return c.callProvider(ctx, providerName, func() error {
+			var callErr error
+			resp, callErr = p.Rerank(ctx, req)
+			return callErr
+		})
 	})
 
 	if err != nil {
@@ -149,6 +165,61 @@ func (c *client) Rerank(ctx context.Context, req *RerankRequest) (*RerankRespons
 
 	resp.Provider = providerName
 	resp.Model = modelName
+	resp.RequestID = RequestIDFromContext(ctx)
+	resp.ProviderRequestID = ProviderRequestIDFromContext(ctx)
+
+	// Return the original document objects
+	attachStructuredDocuments(req, resp)
 
 	return resp, nil
 }
+
+// attachStructuredDocuments sets the original document object on each
+// result of a request with StructuredDocuments.
+func attachStructuredDocuments(req *RerankRequest, resp *RerankResponse) {
+	for i := range resp.Results {
+		if idx := resp.Results[i].Index; idx >= 0 && idx < len(req.StructuredDocuments) {
+			resp.Results[i].StructuredDocument = req.StructuredDocuments[idx]
+		}
+	}
+}
+
+// structuredRerankRequest returns a copy of req whose StructuredDocuments
+// are also rendered as Documents for providers that rank only strings, or
+// req itself if it has no structured documents.
+func structuredRerankRequest(req *RerankRequest) *RerankRequest {
+	if len(req.StructuredDocuments) == 0 {
+		return req
+	}
+
+	r := *req
+	r.Documents = make([]string, len(req.StructuredDocuments))
+	for i, doc := range req.StructuredDocuments {
+		r.Documents[i] = documentText(doc, req.RankFields)
+	}
+	return &r
+}
+
+// documentText renders the fields of doc as "field: value" lines. With no
+// fields given, every field is rendered in name order.
+func documentText(doc map[string]any, fields []string) string {
+	if len(fields) == 0 {
+		for field := range doc {
+			fields = append(fields, field)
+		}
+		sort.Strings(fields)
+	}
+
+	var sb strings.Builder
+	for _, field := range fields {
+		value, ok := doc[field]
+		if !ok {
+			continue
+		}
+		if sb.Len() > 0 {
+			sb.WriteByte('\n')
+		}
+		fmt.Fprintf(&sb, "%s: %v", field, value)
+	}
+	return sb.String()
+}
@@ -0,0 +1,75 @@
+package warp
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/blue-context/warp/internal/vecmath"
+)
+
+// WarningRerankEmbeddingFallback is the callback.WarningEvent code raised
+// when documents are ranked with the embedding model configured by
+// WithRerankEmbeddingFallback instead of a rerank provider.
+const WarningRerankEmbeddingFallback = "rerank_embedding_fallback"
+
+// rerankWithEmbeddings ranks req's documents by the cosine similarity of
+// their embeddings to the query's, using the client's RerankEmbeddingModel.
+// The response has the same shape as a rerank provider's, with scores in
+// [-1, 1].
+func (c *client) rerankWithEmbeddings(ctx context.Context, req *RerankRequest) (*RerankResponse, error) {
+	c.warn(ctx, WarningRerankEmbeddingFallback, fmt.Sprintf("ranking documents for %s with embeddings from %s",
+		req.Model, c.config.RerankEmbeddingModel))
+
+	req = structuredRerankRequest(req)
+	inputs := make([]string, 0, len(req.Documents)+1)
+	inputs = append(inputs, req.Query)
+	inputs = append(inputs, req.Documents...)
+
+	embResp, err := c.Embedding(ctx, &EmbeddingRequest{
+		Model:    c.config.RerankEmbeddingModel,
+		Input:    inputs,
+		Metadata: req.Metadata,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("rerank embedding fallback failed: %w", err)
+	}
+	if len(embResp.Data) != len(inputs) {
+		return nil, fmt.Errorf("rerank embedding fallback: got %d embeddings for %d inputs", len(embResp.Data), len(inputs))
+	}
+
+	vectors := make([][]float64, len(inputs))
+	for i, data := range embResp.Data {
+		idx := data.Index
+		if idx < 0 || idx >= len(inputs) {
+			idx = i
+		}
+		vectors[idx] = data.Embedding
+	}
+
+	results := make([]RerankResult, len(req.Documents))
+	for i := range req.Documents {
+		results[i] = RerankResult{Index: i, RelevanceScore: vecmath.Cosine(vectors[0], vectors[i+1])}
+	}
+	sort.SliceStable(results, func(i, j int) bool {
+		return results[i].RelevanceScore > results[j].RelevanceScore
+	})
+	if req.TopN != nil && *req.TopN > 0 && *req.TopN < len(results) {
+		results = results[:*req.TopN]
+	}
+	if req.ReturnDocuments != nil && *req.ReturnDocuments {
+		for i := range results {
+			results[i].Document = req.Documents[results[i].Index]
+		}
+	}
+
+	_, modelName, _ := parseModel(c.config.RerankEmbeddingModel)
+	resp := &RerankResponse{
+		ID:       RequestIDFromContext(ctx),
+		Results:  results,
+		Provider: embResp.Provider,
+		Model:    modelName,
+	}
+	attachStructuredDocuments(req, resp)
+	return resp, nil
+}
@@ -0,0 +1,68 @@
+package warp
+
+import (
+	"context"
+	"testing"
+
+	"github.com/blue-context/warp/callback"
+)
+
+func TestRerankEmbeddingFallback(t *testing.T) {
+	var warnings []*callback.WarningEvent
+	client, err := NewClient(
+		WithRerankEmbeddingFallback("emb/small"),
+		WithWarningCallback(func(ctx context.Context, event *callback.WarningEvent) {
+			warnings = append(warnings, event)
+		}),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+
+	vectors := map[string][]float64{
+		"capital of France": {1, 0},
+		"Paris":             {0.9, 0.1},
+		"Bananas":           {0, 1},
+		"Lyon":              {0.5, 0.5},
+	}
+	client.RegisterProvider(&mockProvider{
+		name: "emb",
+		embeddingFunc: func(ctx context.Context, req *EmbeddingRequest) (*EmbeddingResponse, error) {
+			resp := &EmbeddingResponse{}
+			for i, text := range req.Input.([]string) {
+				resp.Data = append(resp.Data, Embedding{Index: i, Embedding: vectors[text]})
+			}
+			return resp, nil
+		},
+	})
+
+	resp, err := client.Rerank(context.Background(), &RerankRequest{
+		Model:           "cohere/rerank-v3.5",
+		Query:           "capital of France",
+		Documents:       []string{"Bananas", "Paris", "Lyon"},
+		TopN:            IntPtr(2),
+		ReturnDocuments: BoolPtr(true),
+	})
+	if err != nil {
+		t.Fatalf("Rerank() error = %v", err)
+	}
+	if len(resp.Results) != 2 || resp.Results[0].Index != 1 || resp.Results[1].Index != 2 {
+		t.Fatalf("Results = %+v, want Paris then Lyon", resp.Results)
+	}
+	if resp.Results[0].Document != "Paris" || resp.Results[0].RelevanceScore <= resp.Results[1].RelevanceScore {
+		t.Errorf("Results[0] = %+v", resp.Results[0])
+	}
+	if resp.Provider != "emb" || resp.Model != "small" {
+		t.Errorf("Provider/Model = %s/%s, want emb/small", resp.Provider, resp.Model)
+	}
+	if len(warnings) != 1 || warnings[0].Code != WarningRerankEmbeddingFallback {
+		t.Errorf("warnings = %+v, want one fallback warning", warnings)
+	}
+}
+
+func TestRerankEmbeddingFallbackOption(t *testing.T) {
+	if _, err := NewClient(WithRerankEmbeddingFallback("small")); err == nil {
+		t.Error("NewClient() error = nil, want error for model without provider")
+	}
+}
@@ -294,3 +294,49 @@ func TestRerankContext(t *testing.T) {
 		t.Errorf("Context model is empty")
 	}
 }
+
+func TestRerankStructuredDocuments(t *testing.T) {
+	mock := &mockProvider{
+		name:         "test",
+		capabilities: Capabilities{Rerank: true},
+		rerankResp: &RerankResponse{
+			Results: []RerankResult{{Index: 1, RelevanceScore: 0.9}, {Index: 0, RelevanceScore: 0.1}},
+		},
+	}
+	c := &client{
+		config:    defaultConfig(),
+		providers: map[string]Provider{"test": mock},
+	}
+
+	docs := []map[string]any{
+		{"title": "London", "text": "Capital of England", "id": 7},
+		{"title": "Paris", "text": "Capital of France", "id": 8},
+	}
+	resp, err := c.Rerank(context.Background(), &RerankRequest{
+		Model:               "test/rerank",
+		Query:               "capital of France",
+		StructuredDocuments: docs,
+		RankFields:          []string{"title", "text"},
+	})
+	if err != nil {
+		t.Fatalf("Rerank() error = %v", err)
+	}
+
+	want := []string{"title: London\ntext: Capital of England", "title: Paris\ntext: Capital of France"}
+	if len(mock.rerankReq.Documents) != 2 || mock.rerankReq.Documents[0] != want[0] || mock.rerankReq.Documents[1] != want[1] {
+		t.Errorf("provider documents = %q, want %q", mock.rerankReq.Documents, want)
+	}
+	if got := resp.Results[0].StructuredDocument; got["id"] != 8 {
+		t.Errorf("Results[0].StructuredDocument = %v, want Paris document", got)
+	}
+
+	_, err = c.Rerank(context.Background(), &RerankRequest{
+		Model:               "test/rerank",
+		Query:               "q",
+		Documents:           []string{"a"},
+		StructuredDocuments: docs,
+	})
+	if err == nil {
+		t.Error("Rerank() with documents and structured documents error = nil, want error")
+	}
+}
@@ -0,0 +1,81 @@
+package warp
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// WarningOutputTruncated is the callback.WarningEvent code raised when a
+// completion stopped because it reached its token limit.
+const WarningOutputTruncated = "output_truncated"
+
+const contextKeyResponseLimit contextKey = "warp_response_limit"
+
+// withResponseLimit returns ctx with the limit on provider response bodies.
+func withResponseLimit(ctx context.Context, limit int64) context.Context {
+	return context.WithValue(ctx, contextKeyResponseLimit, limit)
+}
+
+// limitResponse makes resp fail with a *ResponseTooLargeError once its
+// body exceeds the limit in its request's context. Event streams are not
+// limited as a whole, since the sse package limits each event.
+func limitResponse(ctx context.Context, resp *http.Response) {
+	limit, _ := ctx.Value(contextKeyResponseLimit).(int64)
+	if limit <= 0 || resp.Body == nil || strings.HasPrefix(resp.Header.Get("Content-Type"), "text/event-stream") {
+		return
+	}
+	body := &limitedBody{ReadCloser: resp.Body, remaining: limit, limit: limit, provider: ProviderFromContext(ctx)}
+	if resp.ContentLength > limit {
+		body.err = NewResponseTooLargeError(body.provider, limit)
+	}
+	resp.Body = body
+}
+
+// limitedBody is a response body that fails after limit bytes.
+type limitedBody struct {
+	io.ReadCloser
+	remaining int64
+	limit     int64
+	provider  string
+	err       error
+}
+
+func (b *limitedBody) Read(p []byte) (int, error) {
+	if b.err != nil {
+		return 0, b.err
+	}
+	// Read one byte past the limit to tell a body of exactly the limit
+	// from a larger one
+	if int64(len(p)) > b.remaining+1 {
+		p = p[:b.remaining+1]
+	}
+	n, err := b.ReadCloser.Read(p)
+	if int64(n) > b.remaining {
+		n = int(b.remaining)
+		b.remaining = 0
+		b.err = NewResponseTooLargeError(b.provider, b.limit)
+		return n, b.err
+	}
+	b.remaining -= int64(n)
+	return n, err
+}
+
+// Truncated reports whether any choice stopped because it reached the
+// token limit, so the output is incomplete and can be continued.
+//
+// Example:
+//
+//	if resp.Truncated() {
+//	    req.Messages = append(req.Messages, resp.Choices[0].Message,
+//	        warp.Message{Role: "user", Content: "Continue."})
+//	}
+func (r *CompletionResponse) Truncated() bool {
+	for _, choice := range r.Choices {
+		if choice.Reason().IsTruncated() {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,152 @@
+package warp
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"testing/iotest"
+
+	"github.com/blue-context/warp/callback"
+)
+
+func TestMaxResponseSize(t *testing.T) {
+	var body string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		// Flush first so the body is chunked, without a Content-Length
+		w.(http.Flusher).Flush()
+		io.WriteString(w, body)
+	}))
+	defer server.Close()
+
+	client, err := NewClient(WithMaxResponseSize(100))
+	if err != nil {
+		t.Fatal(err)
+	}
+	client.RegisterProvider(&mockProvider{
+		name: "mock",
+		completionFunc: func(ctx context.Context, req *CompletionRequest) (*CompletionResponse, error) {
+			httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, server.URL, nil)
+			if err != nil {
+				return nil, err
+			}
+			httpResp, err := server.Client().Do(httpReq)
+			if err != nil {
+				return nil, err
+			}
+			defer httpResp.Body.Close()
+			RecordResponse(httpResp)
+			var resp CompletionResponse
+			if err := json.NewDecoder(httpResp.Body).Decode(&resp); err != nil {
+				return nil, fmt.Errorf("failed to decode response: %w", err)
+			}
+			return &resp, nil
+		},
+	})
+	req := &CompletionRequest{Model: "mock/model", Messages: []Message{{Role: "user", Content: "Hi"}}}
+
+	body = `{"id":"small"}`
+	if _, err := client.Completion(context.Background(), req); err != nil {
+		t.Fatalf("Completion() of a small response error = %v", err)
+	}
+
+	body = `{"id":"` + strings.Repeat("x", 1000) + `"}`
+	_, err = client.Completion(context.Background(), req)
+	var tooLarge *ResponseTooLargeError
+	if !errors.As(err, &tooLarge) || tooLarge.Limit != 100 || tooLarge.Provider != "mock" {
+		t.Errorf("Completion() of a large response error = %v, want *ResponseTooLargeError", err)
+	}
+}
+
+func TestLimitedBody(t *testing.T) {
+	tests := []struct {
+		name          string
+		body          string
+		contentLength int64
+		wantErr       bool
+	}{
+		{"under limit", "12345", -1, false},
+		{"exactly limit", "1234567890", -1, false},
+		{"over limit", "12345678901", -1, true},
+		{"declared over limit", "1", 11, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := withResponseLimit(context.Background(), 10)
+			resp := &http.Response{
+				Header:        http.Header{},
+				Body:          io.NopCloser(iotest.OneByteReader(strings.NewReader(tt.body))),
+				ContentLength: tt.contentLength,
+			}
+			limitResponse(ctx, resp)
+
+			data, err := io.ReadAll(resp.Body)
+			var tooLarge *ResponseTooLargeError
+			if got := errors.As(err, &tooLarge); got != tt.wantErr {
+				t.Fatalf("ReadAll() error = %v, want too large %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && string(data) != tt.body {
+				t.Errorf("body = %q, want %q", data, tt.body)
+			}
+			if len(data) > 10 {
+				t.Errorf("read %d bytes past the limit", len(data))
+			}
+		})
+	}
+
+	// Event streams are limited per event by the sse package
+	ctx := withResponseLimit(context.Background(), 1)
+	resp := &http.Response{
+		Header: http.Header{"Content-Type": {"text/event-stream"}},
+		Body:   io.NopCloser(strings.NewReader("data: long event\n\n")),
+	}
+	limitResponse(ctx, resp)
+	if _, err := io.ReadAll(resp.Body); err != nil {
+		t.Errorf("ReadAll() of event stream error = %v", err)
+	}
+}
+
+func TestTruncatedWarning(t *testing.T) {
+	var warnings []string
+	client, err := NewClient(WithWarningCallback(func(ctx context.Context, event *callback.WarningEvent) {
+		warnings = append(warnings, event.Code)
+	}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	finish := "stop"
+	client.RegisterProvider(&mockProvider{
+		name: "mock",
+		completionFunc: func(ctx context.Context, req *CompletionRequest) (*CompletionResponse, error) {
+			return &CompletionResponse{Choices: []Choice{
+				{Index: 0, FinishReason: "stop"},
+				{Index: 1, FinishReason: finish},
+			}}, nil
+		},
+	})
+	req := &CompletionRequest{Model: "mock/model", Messages: []Message{{Role: "user", Content: "Hi"}}}
+
+	resp, err := client.Completion(context.Background(), req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.Truncated() || len(warnings) != 0 {
+		t.Errorf("Truncated() = %v, warnings %v, want neither", resp.Truncated(), warnings)
+	}
+
+	finish = "max_tokens"
+	resp, err = client.Completion(context.Background(), req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !resp.Truncated() || len(warnings) != 1 || warnings[0] != WarningOutputTruncated {
+		t.Errorf("Truncated() = %v, warnings %v, want a truncation warning", resp.Truncated(), warnings)
+	}
+}
@@ -0,0 +1,777 @@
+package warp
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// ResponsesRequest is a request in the shape of OpenAI's Responses API,
+// the successor of Chat Completions: the conversation is a list of input
+// items instead of messages, and tool calls and their results are items of
+// their own.
+//
+// Send it with Client.Responses to any provider; providers without the
+// Responses API get it as a CompletionRequest (see
+// CompletionRequestFromResponses).
+//
+// Thread Safety: ResponsesRequest is safe for concurrent reads after
+// creation.
+type ResponsesRequest struct {
+	// Model is the model in "provider/model-name" format.
+	Model string `json:"model"`
+
+	// Instructions is the system prompt.
+	Instructions string `json:"instructions,omitempty"`
+
+	// Input is the conversation: messages, function calls, and function
+	// call outputs, in order.
+	Input []ResponseItem `json:"input"`
+
+	// Tools are the tools the model may call. Function tools work with
+	// every provider; built-in tools such as "web_search" need the
+	// Responses API.
+	Tools []ResponseTool `json:"tools,omitempty"`
+
+	// ToolChoice controls which tool the model calls.
+	ToolChoice *ResponseToolChoice `json:"tool_choice,omitempty"`
+
+	// Temperature controls randomness.
+	Temperature *float64 `json:"temperature,omitempty"`
+
+	// TopP controls nucleus sampling.
+	TopP *float64 `json:"top_p,omitempty"`
+
+	// MaxOutputTokens limits the generated tokens, reasoning included.
+	MaxOutputTokens *int `json:"max_output_tokens,omitempty"`
+
+	// Text sets the output format, such as a JSON schema.
+	Text *ResponseText `json:"text,omitempty"`
+
+	// Reasoning configures reasoning models.
+	Reasoning *ResponseReasoning `json:"reasoning,omitempty"`
+
+	// PreviousResponseID continues the conversation of a stored response,
+	// whose items the provider prepends to Input. Only providers with the
+	// Responses API can continue a stored response.
+	PreviousResponseID string `json:"previous_response_id,omitempty"`
+
+	// Store asks the provider to store the response for
+	// PreviousResponseID. Nil uses the provider's default.
+	Store *bool `json:"store,omitempty"`
+
+	// ServiceTier requests a provider processing tier.
+	ServiceTier ServiceTier `json:"service_tier,omitempty"`
+
+	// PromptCacheKey groups requests that share a prompt prefix (see
+	// CompletionRequest.PromptCacheKey).
+	PromptCacheKey string `json:"prompt_cache_key,omitempty"`
+
+	// SafetyIdentifier is a stable identifier of the end user (see
+	// CompletionRequest.SafetyIdentifier).
+	SafetyIdentifier string `json:"safety_identifier,omitempty"`
+
+	// ExtraBody contains provider-specific fields merged into the request
+	// body. Keys set here override fields generated from the request.
+	ExtraBody map[string]any `json:"-"`
+
+	// Timeout specifies the maximum duration for this request.
+	Timeout time.Duration `json:"-"`
+}
+
+// ResponseItem is an item of a Responses API conversation. Type selects
+// which fields are set:
+//
+//   - "message": Role and Content
+//   - "function_call": CallID, Name, and Arguments
+//   - "function_call_output": CallID and Output
+//   - "reasoning": Summary
+type ResponseItem struct {
+	// Type is the item type.
+	Type string `json:"type"`
+
+	// ID identifies an output item.
+	ID string `json:"id,omitempty"`
+
+	// Status is the status of an output item, such as "completed".
+	Status string `json:"status,omitempty"`
+
+	// Role is the role of a message: "system", "developer", "user", or
+	// "assistant".
+	Role string `json:"role,omitempty"`
+
+	// Content is the content of a message.
+	Content []ResponseContent `json:"content,omitempty"`
+
+	// CallID links a function call to its output.
+	CallID string `json:"call_id,omitempty"`
+
+	// Name is the name of the called function.
+	Name string `json:"name,omitempty"`
+
+	// Arguments are the JSON arguments of a function call.
+	Arguments string `json:"arguments,omitempty"`
+
+	// Output is the result of a function call.
+	Output string `json:"output,omitempty"`
+
+	// Summary is the reasoning summary of a reasoning item.
+	Summary []ResponseContent `json:"summary,omitempty"`
+}
+
+// ResponseContent is a part of a message or reasoning summary. Type is
+// "input_text" or "output_text" with Text, "input_image" with ImageURL,
+// "refusal" with Refusal, or "summary_text" with Text.
+type ResponseContent struct {
+	// Type is the content type.
+	Type string `json:"type"`
+
+	// Text is the text of a text part.
+	Text string `json:"text,omitempty"`
+
+	// ImageURL is the URL or data URI of an image.
+	ImageURL string `json:"image_url,omitempty"`
+
+	// Detail is the image detail level: "auto", "low", or "high".
+	Detail string `json:"detail,omitempty"`
+
+	// Refusal is the model's explanation of a refusal.
+	Refusal string `json:"refusal,omitempty"`
+}
+
+// ResponseTool is a tool of a ResponsesRequest. Function tools have Type
+// "function" with Name, Description, Parameters, and Strict; other types
+// are the provider's built-in tools, with their options in Options.
+type ResponseTool struct {
+	// Type is the tool type.
+	Type string `json:"type"`
+
+	// Name is the function name.
+	Name string `json:"name,omitempty"`
+
+	// Description explains what the function does.
+	Description string `json:"description,omitempty"`
+
+	// Parameters is the function's parameter schema in JSON Schema format.
+	Parameters map[string]any `json:"parameters,omitempty"`
+
+	// Strict enables strict schema adherence for the arguments.
+	Strict *bool `json:"strict,omitempty"`
+
+	// Options are the options of a built-in tool, merged into the tool
+	// when it is sent.
+	Options map[string]any `json:"-"`
+}
+
+// MarshalJSON encodes the tool with its Options.
+func (t ResponseTool) MarshalJSON() ([]byte, error) {
+	type tool ResponseTool
+	data, err := json.Marshal(tool(t))
+	if err != nil || len(t.Options) == 0 {
+		return data, err
+	}
+	fields := make(map[string]any, len(t.Options)+1)
+	for k, v := range t.Options {
+		fields[k] = v
+	}
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return nil, err
+	}
+	return json.Marshal(fields)
+}
+
+// ResponseToolChoice controls which tool the model calls: Type "auto",
+// "none", or "required", or Type "function" with the Name of the function
+// to call.
+type ResponseToolChoice struct {
+	// Type is the choice.
+	Type string
+
+	// Name is the function to call.
+	Name string
+}
+
+// MarshalJSON encodes the choice as a string, or as an object for a
+// function.
+func (c ResponseToolChoice) MarshalJSON() ([]byte, error) {
+	if c.Name == "" {
+		return json.Marshal(c.Type)
+	}
+	return json.Marshal(map[string]string{"type": c.Type, "name": c.Name})
+}
+
+// UnmarshalJSON decodes a choice encoded as a string or an object.
+func (c *ResponseToolChoice) UnmarshalJSON(data []byte) error {
+	if len(data) > 0 && data[0] == '"' {
+		*c = ResponseToolChoice{}
+		return json.Unmarshal(data, &c.Type)
+	}
+	var choice struct {
+		Type string `json:"type"`
+		Name string `json:"name"`
+	}
+	if err := json.Unmarshal(data, &choice); err != nil {
+		return err
+	}
+	*c = ResponseToolChoice{Type: choice.Type, Name: choice.Name}
+	return nil
+}
+
+// ResponseText configures the text output of a response.
+type ResponseText struct {
+	// Format is the output format.
+	Format *ResponseTextFormat `json:"format,omitempty"`
+}
+
+// ResponseTextFormat is the output format of a response: Type "text",
+// "json_object", or "json_schema" with Name and Schema.
+type ResponseTextFormat struct {
+	// Type is the format.
+	Type string `json:"type"`
+
+	// Name identifies the schema.
+	Name string `json:"name,omitempty"`
+
+	// Description explains what the response represents.
+	Description string `json:"description,omitempty"`
+
+	// Schema is the JSON Schema the response must match.
+	Schema map[string]any `json:"schema,omitempty"`
+
+	// Strict enables strict schema adherence.
+	Strict *bool `json:"strict,omitempty"`
+}
+
+// ResponseReasoning configures reasoning models.
+type ResponseReasoning struct {
+	// Effort is the reasoning effort: "minimal", "low", "medium", or
+	// "high".
+	Effort string `json:"effort,omitempty"`
+
+	// Summary asks for a reasoning summary: "auto", "concise", or
+	// "detailed".
+	Summary string `json:"summary,omitempty"`
+}
+
+// ResponsesResponse is a response of the Responses API.
+type ResponsesResponse struct {
+	// ID is a unique identifier for the response.
+	ID string `json:"id"`
+
+	// Object is the object type, "response".
+	Object string `json:"object"`
+
+	// CreatedAt is the Unix timestamp (in seconds) of when the response
+	// was created.
+	CreatedAt int64 `json:"created_at"`
+
+	// Model is the model that generated the response.
+	Model string `json:"model"`
+
+	// Status is "completed", or "incomplete" with the reason in
+	// IncompleteDetails.
+	Status string `json:"status"`
+
+	// IncompleteDetails says why an incomplete response stopped.
+	IncompleteDetails *ResponseIncompleteDetails `json:"incomplete_details,omitempty"`
+
+	// Error describes why a failed response failed.
+	Error *ResponseError `json:"error,omitempty"`
+
+	// Output are the generated items: messages, function calls, and
+	// reasoning.
+	Output []ResponseItem `json:"output"`
+
+	// Usage is the token usage.
+	Usage *ResponsesUsage `json:"usage,omitempty"`
+
+	// ServiceTier is the processing tier that served the request.
+	ServiceTier ServiceTier `json:"service_tier,omitempty"`
+
+	// RequestID is the warp request ID (internal metadata).
+	RequestID string `json:"-"`
+}
+
+// OutputText returns the text of the output messages.
+func (r *ResponsesResponse) OutputText() string {
+	if r == nil {
+		return ""
+	}
+	var text strings.Builder
+	for _, item := range r.Output {
+		if item.Type != "message" {
+			continue
+		}
+		for _, part := range item.Content {
+			if part.Type == "output_text" {
+				text.WriteString(part.Text)
+			}
+		}
+	}
+	return text.String()
+}
+
+// ResponseIncompleteDetails says why a response is incomplete.
+type ResponseIncompleteDetails struct {
+	// Reason is "max_output_tokens" or "content_filter".
+	Reason string `json:"reason"`
+}
+
+// ResponseError is the error of a failed response.
+type ResponseError struct {
+	// Code is the error code.
+	Code string `json:"code"`
+
+	// Message describes the error.
+	Message string `json:"message"`
+}
+
+// ResponsesUsage is the token usage of a response.
+type ResponsesUsage struct {
+	// InputTokens is the number of input tokens.
+	InputTokens int `json:"input_tokens"`
+
+	// OutputTokens is the number of output tokens, reasoning included.
+	OutputTokens int `json:"output_tokens"`
+
+	// TotalTokens is the sum of input and output tokens.
+	TotalTokens int `json:"total_tokens"`
+
+	// InputTokensDetails breaks down the input tokens.
+	InputTokensDetails *ResponsesInputTokensDetails `json:"input_tokens_details,omitempty"`
+
+	// OutputTokensDetails breaks down the output tokens.
+	OutputTokensDetails *ResponsesOutputTokensDetails `json:"output_tokens_details,omitempty"`
+}
+
+// ResponsesInputTokensDetails breaks down the input tokens of a response.
+type ResponsesInputTokensDetails struct {
+	// CachedTokens is the number of input tokens read from the prompt
+	// cache.
+	CachedTokens int `json:"cached_tokens"`
+}
+
+// ResponsesOutputTokensDetails breaks down the output tokens of a
+// response.
+type ResponsesOutputTokensDetails struct {
+	// ReasoningTokens is the number of tokens spent on reasoning.
+	ReasoningTokens int `json:"reasoning_tokens"`
+}
+
+// ResponsesRequestFromCompletion converts a completion request to a
+// Responses API request. It returns the names of the request fields the
+// Responses API has no equivalent for, which are dropped: stop sequences,
+// penalties, multiple choices, prompt logprobs, cached content, message
+// names, and assistant reasoning content.
+//
+// Tool calls of assistant messages become function call items and tool
+// messages function call outputs. Request options other than Timeout, such
+// as Fallbacks, are not part of the API and are not converted.
+func ResponsesRequestFromCompletion(req *CompletionRequest) (*ResponsesRequest, []string) {
+	var lost lossList
+	out := &ResponsesRequest{
+		Model:            req.Model,
+		Input:            make([]ResponseItem, 0, len(req.Messages)),
+		Temperature:      req.Temperature,
+		TopP:             req.TopP,
+		MaxOutputTokens:  req.MaxTokens,
+		ServiceTier:      req.ServiceTier,
+		PromptCacheKey:   req.PromptCacheKey,
+		SafetyIdentifier: req.SafetyIdentifier,
+		ExtraBody:        req.ExtraBody,
+		Timeout:          req.Timeout,
+	}
+
+	for _, msg := range req.Messages {
+		if msg.Name != "" {
+			lost.add("message names")
+		}
+		switch msg.Role {
+		case "tool":
+			out.Input = append(out.Input, ResponseItem{
+				Type:   "function_call_output",
+				CallID: msg.ToolCallID,
+				Output: messageText(msg.Content),
+			})
+			continue
+		case "assistant":
+			if msg.ReasoningContent != "" {
+				lost.add("reasoning content")
+			}
+		}
+		if content := responseContent(msg); len(content) > 0 || len(msg.ToolCalls) == 0 {
+			out.Input = append(out.Input, ResponseItem{Type: "message", Role: msg.Role, Content: content})
+		}
+		for _, call := range msg.ToolCalls {
+			out.Input = append(out.Input, ResponseItem{
+				Type:      "function_call",
+				CallID:    call.ID,
+				Name:      call.Function.Name,
+				Arguments: call.Function.Arguments,
+			})
+		}
+	}
+
+	for _, tool := range req.Tools {
+		out.Tools = append(out.Tools, ResponseTool{
+			Type:        "function",
+			Name:        tool.Function.Name,
+			Description: tool.Function.Description,
+			Parameters:  tool.Function.Parameters,
+		})
+	}
+	if choice := req.ToolChoice; choice != nil {
+		if choice.Function != nil {
+			out.ToolChoice = &ResponseToolChoice{Type: "function", Name: choice.Function.Name}
+		} else if choice.Type != "" {
+			out.ToolChoice = &ResponseToolChoice{Type: choice.Type}
+		}
+	}
+	if format := req.ResponseFormat; format != nil {
+		textFormat := &ResponseTextFormat{Type: format.Type}
+		if schema := format.JSONSchema; schema != nil {
+			textFormat.Name = schema.Name
+			textFormat.Description = schema.Description
+			textFormat.Schema = schema.Schema
+			textFormat.Strict = schema.Strict
+		}
+		out.Text = &ResponseText{Format: textFormat}
+	}
+
+	if len(req.Stop) > 0 {
+		lost.add("stop")
+	}
+	if req.FrequencyPenalty != nil {
+		lost.add("frequency_penalty")
+	}
+	if req.PresencePenalty != nil {
+		lost.add("presence_penalty")
+	}
+	if req.N != nil && *req.N > 1 {
+		lost.add("n")
+	}
+	if req.PromptLogprobs {
+		lost.add("prompt_logprobs")
+	}
+	if req.CachedContent != "" {
+		lost.add("cached_content")
+	}
+	return out, lost
+}
+
+// CompletionRequestFromResponses converts a Responses API request to a
+// completion request. It returns the names of the request fields Chat
+// Completions has no equivalent for, which are dropped: built-in tools,
+// reasoning options, reasoning items, Store, and input parts other than
+// text and images.
+//
+// Instructions become a leading system message, function call items tool
+// calls of an assistant message, and function call outputs tool messages.
+// PreviousResponseID cannot be converted, since the stored conversation is
+// only available through the Responses API; the caller must refuse it.
+func CompletionRequestFromResponses(req *ResponsesRequest) (*CompletionRequest, []string) {
+	var lost lossList
+	out := &CompletionRequest{
+		Model:            req.Model,
+		Temperature:      req.Temperature,
+		TopP:             req.TopP,
+		MaxTokens:        req.MaxOutputTokens,
+		ServiceTier:      req.ServiceTier,
+		PromptCacheKey:   req.PromptCacheKey,
+		SafetyIdentifier: req.SafetyIdentifier,
+		ExtraBody:        req.ExtraBody,
+		Timeout:          req.Timeout,
+	}
+
+	if req.Instructions != "" {
+		out.Messages = append(out.Messages, Message{Role: "system", Content: req.Instructions})
+	}
+	for _, item := range req.Input {
+		switch item.Type {
+		case "message", "":
+			content, dropped := messageContent(item.Content)
+			if dropped {
+				lost.add("input parts other than text and images")
+			}
+			out.Messages = append(out.Messages, Message{Role: item.Role, Content: content})
+		case "function_call":
+			call := ToolCall{ID: item.CallID, Type: "function", Function: FunctionCall{Name: item.Name, Arguments: item.Arguments}}
+			// Calls following an assistant message, or each other, are
+			// made by that message
+			if n := len(out.Messages); n > 0 && out.Messages[n-1].Role == "assistant" {
+				out.Messages[n-1].ToolCalls = append(out.Messages[n-1].ToolCalls, call)
+			} else {
+				out.Messages = append(out.Messages, Message{Role: "assistant", Content: "", ToolCalls: []ToolCall{call}})
+			}
+		case "function_call_output":
+			out.Messages = append(out.Messages, Message{Role: "tool", ToolCallID: item.CallID, Content: item.Output})
+		case "reasoning":
+			lost.add("reasoning items")
+		default:
+			lost.add(item.Type + " items")
+		}
+	}
+
+	for _, tool := range req.Tools {
+		if tool.Type != "function" {
+			lost.add(tool.Type + " tool")
+			continue
+		}
+		out.Tools = append(out.Tools, Tool{
+			Type:     "function",
+			Function: Function{Name: tool.Name, Description: tool.Description, Parameters: tool.Parameters},
+		})
+	}
+	if choice := req.ToolChoice; choice != nil {
+		if choice.Name != "" {
+			out.ToolChoice = &ToolChoice{Type: "function", Function: &Function{Name: choice.Name}}
+		} else {
+			out.ToolChoice = &ToolChoice{Type: choice.Type}
+		}
+	}
+	if req.Text != nil && req.Text.Format != nil && req.Text.Format.Type != "text" {
+		format := req.Text.Format
+		out.ResponseFormat = &ResponseFormat{Type: format.Type}
+		if format.Type == "json_schema" {
+			out.ResponseFormat.JSONSchema = &JSONSchema{
+				Name:        format.Name,
+				Description: format.Description,
+				Schema:      format.Schema,
+				Strict:      format.Strict,
+			}
+		}
+	}
+
+	if req.Reasoning != nil {
+		lost.add("reasoning")
+	}
+	if req.Store != nil && *req.Store {
+		lost.add("store")
+	}
+	return out, lost
+}
+
+// CompletionResponseFromResponses converts a Responses API response to a
+// completion response with one choice. Output text becomes the message
+// content, function calls its tool calls, and reasoning summaries its
+// reasoning content.
+func CompletionResponseFromResponses(resp *ResponsesResponse) *CompletionResponse {
+	msg := Message{Role: "assistant"}
+	var text, reasoning strings.Builder
+	for _, item := range resp.Output {
+		switch item.Type {
+		case "message":
+			for _, part := range item.Content {
+				switch part.Type {
+				case "output_text":
+					text.WriteString(part.Text)
+				case "refusal":
+					text.WriteString(part.Refusal)
+				}
+			}
+		case "function_call":
+			msg.ToolCalls = append(msg.ToolCalls, ToolCall{
+				ID:       item.CallID,
+				Type:     "function",
+				Function: FunctionCall{Name: item.Name, Arguments: item.Arguments},
+			})
+		case "reasoning":
+			for _, part := range item.Summary {
+				if reasoning.Len() > 0 {
+					reasoning.WriteString("\n\n")
+				}
+				reasoning.WriteString(part.Text)
+			}
+		}
+	}
+	msg.Content = text.String()
+	msg.ReasoningContent = reasoning.String()
+
+	finishReason := "stop"
+	switch {
+	case resp.Status == "incomplete" && resp.IncompleteDetails != nil && resp.IncompleteDetails.Reason == "max_output_tokens":
+		finishReason = "length"
+	case resp.Status == "incomplete" && resp.IncompleteDetails != nil && resp.IncompleteDetails.Reason == "content_filter":
+		finishReason = "content_filter"
+	case len(msg.ToolCalls) > 0:
+		finishReason = "tool_calls"
+	}
+
+	out := &CompletionResponse{
+		ID:          resp.ID,
+		Object:      "chat.completion",
+		Created:     resp.CreatedAt,
+		Model:       resp.Model,
+		Choices:     []Choice{{Index: 0, Message: msg, FinishReason: finishReason}},
+		ServiceTier: resp.ServiceTier,
+		RequestID:   resp.RequestID,
+	}
+	if u := resp.Usage; u != nil {
+		out.Usage = &Usage{PromptTokens: u.InputTokens, CompletionTokens: u.OutputTokens, TotalTokens: u.TotalTokens}
+		if u.InputTokensDetails != nil && u.InputTokensDetails.CachedTokens > 0 {
+			out.Usage.PromptDetails = &PromptTokensDetails{CachedTokens: u.InputTokensDetails.CachedTokens}
+		}
+		if u.OutputTokensDetails != nil && u.OutputTokensDetails.ReasoningTokens > 0 {
+			out.Usage.CompletionDetails = &CompletionTokensDetails{ReasoningTokens: u.OutputTokensDetails.ReasoningTokens}
+		}
+	}
+	return out
+}
+
+// ResponsesResponseFromCompletion converts the first choice of a
+// completion response to a Responses API response. Reasoning content
+// becomes a reasoning item, message content an output message, and tool
+// calls function call items. Citations and logprobs are not converted.
+func ResponsesResponseFromCompletion(resp *CompletionResponse) *ResponsesResponse {
+	out := &ResponsesResponse{
+		ID:          resp.ID,
+		Object:      "response",
+		CreatedAt:   resp.Created,
+		Model:       resp.Model,
+		Status:      "completed",
+		Output:      []ResponseItem{},
+		ServiceTier: resp.ServiceTier,
+		RequestID:   resp.RequestID,
+	}
+
+	if len(resp.Choices) > 0 {
+		choice := resp.Choices[0]
+		msg := choice.Message
+		if msg.ReasoningContent != "" {
+			out.Output = append(out.Output, ResponseItem{
+				Type:    "reasoning",
+				Summary: []ResponseContent{{Type: "summary_text", Text: msg.ReasoningContent}},
+			})
+		}
+		if text := messageText(msg.Content); text != "" {
+			out.Output = append(out.Output, ResponseItem{
+				Type:    "message",
+				Role:    "assistant",
+				Status:  "completed",
+				Content: []ResponseContent{{Type: "output_text", Text: text}},
+			})
+		}
+		for _, call := range msg.ToolCalls {
+			out.Output = append(out.Output, ResponseItem{
+				Type:      "function_call",
+				Status:    "completed",
+				CallID:    call.ID,
+				Name:      call.Function.Name,
+				Arguments: call.Function.Arguments,
+			})
+		}
+
+		switch choice.FinishReason {
+		case "length":
+			out.Status = "incomplete"
+			out.IncompleteDetails = &ResponseIncompleteDetails{Reason: "max_output_tokens"}
+		case "content_filter":
+			out.Status = "incomplete"
+			out.IncompleteDetails = &ResponseIncompleteDetails{Reason: "content_filter"}
+		}
+	}
+
+	if u := resp.Usage; u != nil {
+		out.Usage = &ResponsesUsage{InputTokens: u.PromptTokens, OutputTokens: u.CompletionTokens, TotalTokens: u.TotalTokens}
+		if cached := u.GetCachedTokens(); cached > 0 {
+			out.Usage.InputTokensDetails = &ResponsesInputTokensDetails{CachedTokens: cached}
+		}
+		if u.CompletionDetails != nil && u.CompletionDetails.ReasoningTokens > 0 {
+			out.Usage.OutputTokensDetails = &ResponsesOutputTokensDetails{ReasoningTokens: u.CompletionDetails.ReasoningTokens}
+		}
+	}
+	return out
+}
+
+// responseContent returns the content of a message as Responses API
+// parts: output text for assistant messages, input text and images for
+// others.
+func responseContent(msg Message) []ResponseContent {
+	textType := "input_text"
+	if msg.Role == "assistant" {
+		textType = "output_text"
+	}
+	switch c := msg.Content.(type) {
+	case string:
+		if c == "" {
+			return nil
+		}
+		return []ResponseContent{{Type: textType, Text: c}}
+	case []ContentPart:
+		parts := make([]ResponseContent, 0, len(c))
+		for _, part := range c {
+			switch {
+			case part.Type == "text":
+				parts = append(parts, ResponseContent{Type: textType, Text: part.Text})
+			case part.Type == "image_url" && part.ImageURL != nil:
+				parts = append(parts, ResponseContent{Type: "input_image", ImageURL: part.ImageURL.URL, Detail: part.ImageURL.Detail})
+			}
+		}
+		return parts
+	default:
+		if text := messageText(c); text != "" {
+			return []ResponseContent{{Type: textType, Text: text}}
+		}
+		return nil
+	}
+}
+
+// messageContent returns Responses API parts as message content: a string
+// if they are all text, and multimodal parts otherwise. It reports whether
+// parts other than text and images were dropped.
+func messageContent(parts []ResponseContent) (any, bool) {
+	var converted []ContentPart
+	dropped, images := false, false
+	for _, part := range parts {
+		switch part.Type {
+		case "input_text", "output_text", "text":
+			converted = append(converted, ContentPart{Type: "text", Text: part.Text})
+		case "refusal":
+			converted = append(converted, ContentPart{Type: "text", Text: part.Refusal})
+		case "input_image":
+			if part.ImageURL == "" {
+				dropped = true
+				continue
+			}
+			images = true
+			converted = append(converted, ContentPart{Type: "image_url", ImageURL: &ImageURL{URL: part.ImageURL, Detail: part.Detail}})
+		default:
+			dropped = true
+		}
+	}
+	if !images {
+		return messageText(converted), dropped
+	}
+	return converted, dropped
+}
+
+// lossList collects the names of fields dropped by a conversion, each
+// once.
+type lossList []string
+
+// add records a dropped field.
+func (l *lossList) add(name string) {
+	for _, n := range *l {
+		if n == name {
+			return
+		}
+	}
+	*l = append(*l, name)
+}
+
+// String returns the dropped fields as a comma-separated list.
+func (l lossList) String() string {
+	return strings.Join(l, ", ")
+}
+
+// checkBridgeable returns an error if req cannot be sent as a completion
+// request at all.
+func checkBridgeable(req *ResponsesRequest, providerName string) error {
+	if req.PreviousResponseID != "" {
+		return NewInvalidRequestError(
+			fmt.Sprintf("provider %q does not support the Responses API, so previous_response_id cannot be used; send the conversation in Input instead", providerName),
+			providerName, nil)
+	}
+	return nil
+}
@@ -0,0 +1,386 @@
+package warp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/blue-context/warp/callback"
+)
+
+// ResponsesProvider is implemented by providers that serve OpenAI's
+// Responses API. Client.Responses sends requests to them as is; requests
+// to other providers are converted to completion requests.
+//
+// Completion requests for models that only the Responses API serves are
+// converted to Responses API requests, so callers can use either request
+// type with any model.
+type ResponsesProvider interface {
+	// Responses sends a Responses API request. req.Model has no provider
+	// prefix.
+	Responses(ctx context.Context, req *ResponsesRequest) (*ResponsesResponse, error)
+
+	// ResponsesOnly reports whether model is served only by the Responses
+	// API, not by chat completions. model has no provider prefix.
+	ResponsesOnly(model string) bool
+}
+
+// WarningAPIBridgeLossy is the callback.WarningEvent code raised when a
+// request is converted between the Responses and Chat Completions APIs
+// and fields the target API has no equivalent for are dropped, or a
+// stream is delivered as a single chunk.
+const WarningAPIBridgeLossy = "api_bridge_lossy"
+
+// Responses sends a Responses API request.
+//
+// Providers that implement ResponsesProvider receive the request as is.
+// It passes through the same stages as Completion: request defaults,
+// context upgrades, request middleware, deprecation handling, callbacks,
+// preparation for the provider (zero data retention, request size limits,
+// safety checks, and message repairs), the response cache, the budget,
+// timeouts, retries, and payload tracing. These stages see the request as a
+// CompletionRequest, converted with CompletionRequestFromResponses, and the
+// response as a CompletionResponse. Changes they make are applied to the
+// request; fields the CompletionRequest cannot hold, such as built-in tools
+// and reasoning options, are kept.
+//
+// For other providers the request is converted with
+// CompletionRequestFromResponses, sent with Completion, and the response
+// converted back; fields that Chat Completions cannot express are dropped
+// with a WarningAPIBridgeLossy warning.
+//
+// Returns an *InvalidRequestError if req.PreviousResponseID is set and the
+// provider does not support the Responses API.
+//
+// Example:
+//
+//	resp, err := client.Responses(ctx, &warp.ResponsesRequest{
+//	    Model:        "anthropic/claude-3-5-sonnet-20241022",
+//	    Instructions: "Answer in one sentence.",
+//	    Input: []warp.ResponseItem{{
+//	        Type:    "message",
+//	        Role:    "user",
+//	        Content: []warp.ResponseContent{{Type: "input_text", Text: "Why is the sky blue?"}},
+//	    }},
+//	})
+//	if err != nil {
+//	    return err
+//	}
+//	fmt.Println(resp.OutputText())
+func (c *client) Responses(ctx context.Context, req *ResponsesRequest) (*ResponsesResponse, error) {
+	if req == nil {
+		return nil, fmt.Errorf("request cannot be nil")
+	}
+
+	// Add request ID to context
+	if RequestIDFromContext(ctx) == "" {
+		ctx = WithGeneratedRequestID(ctx)
+	}
+
+	// Parse model string
+	providerName, _, err := parseModel(req.Model)
+	if err != nil {
+		return nil, err
+	}
+
+	// Get provider
+	p, err := c.getProvider(providerName)
+	if err != nil {
+		return nil, fmt.Errorf("provider %q not found (did you register it?)", providerName)
+	}
+	if _, ok := p.(ResponsesProvider); !ok {
+		return c.responsesWithCompletion(ctx, req, providerName)
+	}
+
+	// Run the steps shared with Completion on the request's
+	// CompletionRequest view, and apply the changes they make
+	view, lost := CompletionRequestFromResponses(req)
+	d, err := c.beginRequest(ctx, view)
+	if err != nil {
+		return nil, err
+	}
+	ctx = d.ctx
+	if d.req != view {
+		req = mergeResponsesRequest(req, d.req)
+		if dropped := unmergedLosses(lost); len(dropped) > 0 {
+			c.warn(ctx, WarningAPIBridgeLossy, fmt.Sprintf("the request was changed before sending; dropped %s", dropped))
+		}
+		view = d.req
+	}
+	providerName, modelName, startTime := d.providerName, d.modelName, d.startTime
+	rp, ok := d.provider.(ResponsesProvider)
+	if !ok {
+		return nil, NewInvalidRequestError(
+			fmt.Sprintf("request middleware routed the request to provider %q, which does not support the Responses API", providerName),
+			providerName, nil)
+	}
+
+	// Copy the request with provider prefix removed
+	r := *req
+	r.Model = modelName
+
+	// Check cache before API call. Requests the view does not fully
+	// describe, or that depend on stored responses, are not cached.
+	cacheKey := ""
+	if c.cache != nil && len(lost) == 0 && req.PreviousResponseID == "" && req.Store == nil {
+		if key := c.cacheKey(view); key != "" {
+			cacheKey = "responses:" + key
+			if cached, err := c.cache.Get(ctx, cacheKey); err == nil {
+				var resp ResponsesResponse
+				if json.Unmarshal(cached, &resp) == nil {
+					resp.RequestID = RequestIDFromContext(ctx)
+					return &resp, nil
+				}
+			}
+		}
+	}
+
+	// Refuse the request once the budget is spent
+	if err := c.checkBudget(providerName, modelName); err != nil {
+		return nil, err
+	}
+
+	// Apply timeout if specified
+	if req.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = c.withTimeout(ctx, req.Timeout)
+		defer cancel()
+	} else if c.config.DefaultTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = c.withTimeout(ctx, c.config.DefaultTimeout)
+		defer cancel()
+	}
+
+	// Call provider with retries
+	var resp *ResponsesResponse
+	err = c.withRetry(ctx, func() error {
+		return c.callProvider(ctx, providerName, func() error {
+			var callErr error
+			resp, callErr = rp.Responses(ctx, &r)
+			return callErr
+		})
+	})
+
+	// Charge the cost to the budget
+	cost := 0.0
+	var completion *CompletionResponse
+	if err == nil {
+		completion = CompletionResponseFromResponses(resp)
+		if c.config.TrackCost {
+			if calculatedCost, costErr := c.costCalc.CalculateCompletion(completion); costErr == nil {
+				cost = calculatedCost
+			}
+			c.chargeBudget(providerName, modelName, cost)
+		}
+	}
+
+	// Record end time
+	endTime := c.now()
+	duration := endTime.Sub(startTime)
+
+	if err != nil {
+		c.tracePayloads(ctx, providerName, modelName, nil, err)
+
+		// Execute failure callbacks
+		if c.callbacks != nil {
+			failureEvent := &callback.FailureEvent{
+				RequestID: RequestIDFromContext(ctx),
+				Model:     modelName,
+				Provider:  providerName,
+				Request:   view,
+				Error:     err,
+				StartTime: startTime,
+				EndTime:   endTime,
+				Duration:  duration,
+			}
+			c.callbacks.ExecuteFailure(ctx, failureEvent)
+		}
+		return nil, err
+	}
+	resp.RequestID = RequestIDFromContext(ctx)
+
+	// Store successful response in cache
+	if cacheKey != "" {
+		if data, err := json.Marshal(resp); err == nil {
+			// Ignore cache errors - don't fail the request if caching fails
+			_ = c.cache.Set(ctx, cacheKey, data, 1*time.Hour)
+		}
+	}
+
+	// Attach the raw provider payloads (after caching, so they are not cached)
+	completion.RequestID = resp.RequestID
+	c.tracePayloads(ctx, providerName, modelName, completion, nil)
+
+	// Execute success callbacks
+	if c.callbacks != nil {
+
+		currency, localCost := c.localCost(ctx, cost)
+
+		// Get token count
+		tokens := 0
+		if completion.Usage != nil {
+			tokens = completion.Usage.TotalTokens
+		}
+
+		successEvent := &callback.SuccessEvent{
+			RequestID: RequestIDFromContext(ctx),
+			Model:     modelName,
+			Provider:  providerName,
+			Request:   view,
+			Response:  completion,
+			StartTime: startTime,
+			EndTime:   endTime,
+			Duration:  duration,
+			Cost:      cost,
+			Currency:  currency,
+			LocalCost: localCost,
+			Tokens:    tokens,
+		}
+		c.callbacks.ExecuteSuccess(ctx, successEvent)
+	}
+
+	return resp, nil
+}
+
+// mergeResponsesRequest returns req with the changes middleware made to
+// its CompletionRequest view applied. Fields the view cannot hold are
+// kept from req.
+func mergeResponsesRequest(req *ResponsesRequest, view *CompletionRequest) *ResponsesRequest {
+	merged, _ := ResponsesRequestFromCompletion(view)
+	merged.Reasoning = req.Reasoning
+	merged.PreviousResponseID = req.PreviousResponseID
+	merged.Store = req.Store
+	for _, tool := range req.Tools {
+		if tool.Type != "function" {
+			merged.Tools = append(merged.Tools, tool)
+		}
+	}
+	if merged.ToolChoice == nil {
+		merged.ToolChoice = req.ToolChoice
+	}
+	if merged.Text == nil {
+		merged.Text = req.Text
+	}
+	return merged
+}
+
+// unmergedLosses returns the fields in lost that mergeResponsesRequest
+// does not restore: input items and parts the view has no equivalent for.
+func unmergedLosses(lost []string) lossList {
+	var dropped lossList
+	for _, name := range lost {
+		if name == "reasoning" || name == "store" || strings.HasSuffix(name, " tool") {
+			continue
+		}
+		dropped.add(name)
+	}
+	return dropped
+}
+
+// responsesWithCompletion sends a Responses API request to a provider
+// without the Responses API as a completion request.
+func (c *client) responsesWithCompletion(ctx context.Context, req *ResponsesRequest, providerName string) (*ResponsesResponse, error) {
+	if err := checkBridgeable(req, providerName); err != nil {
+		return nil, err
+	}
+
+	completionReq, lost := CompletionRequestFromResponses(req)
+	if len(lost) > 0 {
+		c.warn(ctx, WarningAPIBridgeLossy, fmt.Sprintf("%s does not support the Responses API; dropped %s",
+			providerName, lossList(lost)))
+	}
+
+	resp, err := c.Completion(ctx, completionReq)
+	if err != nil {
+		return nil, err
+	}
+	return ResponsesResponseFromCompletion(resp), nil
+}
+
+// providerCompletion sends a completion request to p, through the
+// Responses API if the model is served only by it. req.Model has no
+// provider prefix.
+func (c *client) providerCompletion(ctx context.Context, p Provider, req *CompletionRequest) (*CompletionResponse, error) {
+	rp, ok := p.(ResponsesProvider)
+	if !ok || !rp.ResponsesOnly(req.Model) {
+		return p.Completion(ctx, req)
+	}
+
+	responsesReq, lost := ResponsesRequestFromCompletion(req)
+	if len(lost) > 0 {
+		c.warn(ctx, WarningAPIBridgeLossy, fmt.Sprintf("%s is only served by the Responses API; dropped %s",
+			req.Model, lossList(lost)))
+	}
+
+	resp, err := rp.Responses(ctx, responsesReq)
+	if err != nil {
+		return nil, err
+	}
+	return CompletionResponseFromResponses(resp), nil
+}
+
+// providerStream opens a completion stream from p. Models served only by
+// the Responses API are sent a non-streaming request, whose response is
+// delivered as a single chunk. req.Model has no provider prefix.
+func (c *client) providerStream(ctx context.Context, p Provider, req *CompletionRequest) (Stream, error) {
+	rp, ok := p.(ResponsesProvider)
+	if !ok || !rp.ResponsesOnly(req.Model) {
+		return p.CompletionStream(ctx, req)
+	}
+
+	c.warn(ctx, WarningAPIBridgeLossy, fmt.Sprintf("%s is only served by the Responses API; the stream is delivered as one chunk", req.Model))
+	resp, err := c.providerCompletion(ctx, p, req)
+	if err != nil {
+		return nil, err
+	}
+	return &responseStream{chunk: chunkFromResponse(resp)}, nil
+}
+
+// responseStream is a stream of one chunk holding a whole response.
+type responseStream struct {
+	chunk *CompletionChunk
+	done  bool
+}
+
+// Recv returns the chunk, then io.EOF.
+func (s *responseStream) Recv() (*CompletionChunk, error) {
+	if s.done {
+		return nil, io.EOF
+	}
+	s.done = true
+	return s.chunk, nil
+}
+
+// Close does nothing; the response has already been received.
+func (s *responseStream) Close() error {
+	return nil
+}
+
+// chunkFromResponse returns a chunk with the content of each choice of
+// resp as its delta.
+func chunkFromResponse(resp *CompletionResponse) *CompletionChunk {
+	chunk := &CompletionChunk{
+		ID:      resp.ID,
+		Object:  "chat.completion.chunk",
+		Created: resp.Created,
+		Model:   resp.Model,
+		Usage:   resp.Usage,
+	}
+	for _, choice := range resp.Choices {
+		finishReason := choice.FinishReason
+		chunk.Choices = append(chunk.Choices, ChunkChoice{
+			Index: choice.Index,
+			Delta: MessageDelta{
+				Role:             choice.Message.Role,
+				Content:          messageText(choice.Message.Content),
+				ReasoningContent: choice.Message.ReasoningContent,
+				ToolCalls:        choice.Message.ToolCalls,
+			},
+			FinishReason: &finishReason,
+		})
+	}
+	return chunk
+}
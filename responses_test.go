@@ -0,0 +1,405 @@
+package warp
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/blue-context/warp/callback"
+)
+
+// responsesProvider is a mockProvider that implements ResponsesProvider,
+// serving models named "*-pro" only through the Responses API.
+type responsesProvider struct {
+	mockProvider
+	got  *ResponsesRequest
+	resp *ResponsesResponse
+}
+
+func (p *responsesProvider) Responses(ctx context.Context, req *ResponsesRequest) (*ResponsesResponse, error) {
+	p.got = req
+	return p.resp, nil
+}
+
+func (p *responsesProvider) ResponsesOnly(model string) bool {
+	return strings.HasSuffix(model, "-pro")
+}
+
+func TestResponsesRequestFromCompletion(t *testing.T) {
+	req := &CompletionRequest{
+		Model: "openai/o3-pro",
+		Messages: []Message{
+			{Role: "system", Content: "Be brief."},
+			{Role: "user", Content: []ContentPart{
+				{Type: "text", Text: "What is this?"},
+				{Type: "image_url", ImageURL: &ImageURL{URL: "https://example.com/cat.png", Detail: "low"}},
+			}},
+			{Role: "assistant", Content: "Let me check.", ToolCalls: []ToolCall{
+				{ID: "call_1", Type: "function", Function: FunctionCall{Name: "lookup", Arguments: `{"q":"cat"}`}},
+			}},
+			{Role: "tool", ToolCallID: "call_1", Content: "a cat"},
+		},
+		MaxTokens:      IntPtr(100),
+		Stop:           []string{"END"},
+		Tools:          []Tool{{Type: "function", Function: Function{Name: "lookup", Parameters: map[string]any{"type": "object"}}}},
+		ToolChoice:     &ToolChoice{Type: "required"},
+		ResponseFormat: &ResponseFormat{Type: "json_schema", JSONSchema: &JSONSchema{Name: "answer", Schema: map[string]any{"type": "object"}}},
+		PromptCacheKey: "k",
+	}
+
+	got, lost := ResponsesRequestFromCompletion(req)
+
+	want := []ResponseItem{
+		{Type: "message", Role: "system", Content: []ResponseContent{{Type: "input_text", Text: "Be brief."}}},
+		{Type: "message", Role: "user", Content: []ResponseContent{
+			{Type: "input_text", Text: "What is this?"},
+			{Type: "input_image", ImageURL: "https://example.com/cat.png", Detail: "low"},
+		}},
+		{Type: "message", Role: "assistant", Content: []ResponseContent{{Type: "output_text", Text: "Let me check."}}},
+		{Type: "function_call", CallID: "call_1", Name: "lookup", Arguments: `{"q":"cat"}`},
+		{Type: "function_call_output", CallID: "call_1", Output: "a cat"},
+	}
+	if !reflect.DeepEqual(got.Input, want) {
+		t.Errorf("Input = %+v, want %+v", got.Input, want)
+	}
+	if *got.MaxOutputTokens != 100 || got.PromptCacheKey != "k" {
+		t.Errorf("MaxOutputTokens = %d, PromptCacheKey = %q", *got.MaxOutputTokens, got.PromptCacheKey)
+	}
+	if len(got.Tools) != 1 || got.Tools[0].Name != "lookup" || got.ToolChoice.Type != "required" {
+		t.Errorf("Tools = %+v, ToolChoice = %+v", got.Tools, got.ToolChoice)
+	}
+	if f := got.Text.Format; f.Type != "json_schema" || f.Name != "answer" {
+		t.Errorf("Text.Format = %+v, want the answer schema", f)
+	}
+	if !reflect.DeepEqual(lost, []string{"stop"}) {
+		t.Errorf("lost = %v, want [stop]", lost)
+	}
+}
+
+func TestCompletionRequestFromResponses(t *testing.T) {
+	req := &ResponsesRequest{
+		Model:        "anthropic/claude",
+		Instructions: "Be brief.",
+		Input: []ResponseItem{
+			{Type: "message", Role: "user", Content: []ResponseContent{{Type: "input_text", Text: "Weather in Paris and Rome?"}}},
+			{Type: "reasoning", Summary: []ResponseContent{{Type: "summary_text", Text: "Two lookups."}}},
+			{Type: "function_call", CallID: "c1", Name: "weather", Arguments: `{"city":"Paris"}`},
+			{Type: "function_call", CallID: "c2", Name: "weather", Arguments: `{"city":"Rome"}`},
+			{Type: "function_call_output", CallID: "c1", Output: "sunny"},
+			{Type: "function_call_output", CallID: "c2", Output: "rainy"},
+		},
+		Tools: []ResponseTool{
+			{Type: "function", Name: "weather", Parameters: map[string]any{"type": "object"}},
+			{Type: "web_search"},
+		},
+		ToolChoice: &ResponseToolChoice{Type: "function", Name: "weather"},
+		Reasoning:  &ResponseReasoning{Effort: "low"},
+	}
+
+	got, lost := CompletionRequestFromResponses(req)
+
+	if len(got.Messages) != 5 {
+		t.Fatalf("Messages = %+v, want system, user, assistant, and two tool results", got.Messages)
+	}
+	if got.Messages[0].Role != "system" || got.Messages[0].Content != "Be brief." {
+		t.Errorf("Messages[0] = %+v, want the instructions", got.Messages[0])
+	}
+	if got.Messages[1].Content != "Weather in Paris and Rome?" {
+		t.Errorf("Messages[1].Content = %#v, want a string", got.Messages[1].Content)
+	}
+	if calls := got.Messages[2].ToolCalls; got.Messages[2].Role != "assistant" || len(calls) != 2 || calls[1].ID != "c2" {
+		t.Errorf("Messages[2] = %+v, want one assistant message with both calls", got.Messages[2])
+	}
+	if got.Messages[4].Role != "tool" || got.Messages[4].ToolCallID != "c2" || got.Messages[4].Content != "rainy" {
+		t.Errorf("Messages[4] = %+v, want the c2 result", got.Messages[4])
+	}
+	if len(got.Tools) != 1 || got.ToolChoice.Function.Name != "weather" {
+		t.Errorf("Tools = %+v, ToolChoice = %+v", got.Tools, got.ToolChoice)
+	}
+	if want := []string{"reasoning items", "web_search tool", "reasoning"}; !reflect.DeepEqual(lost, want) {
+		t.Errorf("lost = %v, want %v", lost, want)
+	}
+}
+
+func TestResponseConversions(t *testing.T) {
+	resp := &ResponsesResponse{
+		ID:        "resp_1",
+		CreatedAt: 1700000000,
+		Model:     "o3-pro",
+		Status:    "completed",
+		Output: []ResponseItem{
+			{Type: "reasoning", Summary: []ResponseContent{{Type: "summary_text", Text: "Need the weather."}}},
+			{Type: "message", Role: "assistant", Content: []ResponseContent{{Type: "output_text", Text: "Checking."}}},
+			{Type: "function_call", CallID: "c1", Name: "weather", Arguments: `{}`},
+		},
+		Usage: &ResponsesUsage{
+			InputTokens: 100, OutputTokens: 50, TotalTokens: 150,
+			InputTokensDetails:  &ResponsesInputTokensDetails{CachedTokens: 64},
+			OutputTokensDetails: &ResponsesOutputTokensDetails{ReasoningTokens: 40},
+		},
+	}
+
+	completion := CompletionResponseFromResponses(resp)
+	choice := completion.Choices[0]
+	if choice.Message.Content != "Checking." || choice.Message.ReasoningContent != "Need the weather." || choice.FinishReason != "tool_calls" {
+		t.Errorf("Choice = %+v", choice)
+	}
+	if len(choice.Message.ToolCalls) != 1 || choice.Message.ToolCalls[0].ID != "c1" {
+		t.Errorf("ToolCalls = %+v", choice.Message.ToolCalls)
+	}
+	if u := completion.Usage; u.PromptTokens != 100 || u.GetCachedTokens() != 64 || u.GetReasoningTokens() != 40 {
+		t.Errorf("Usage = %+v", u)
+	}
+
+	back := ResponsesResponseFromCompletion(completion)
+	if back.OutputText() != "Checking." || len(back.Output) != 3 || back.Output[2].CallID != "c1" {
+		t.Errorf("Output = %+v", back.Output)
+	}
+	if back.Usage.InputTokensDetails.CachedTokens != 64 || back.Usage.OutputTokensDetails.ReasoningTokens != 40 {
+		t.Errorf("Usage = %+v", back.Usage)
+	}
+
+	resp.Status = "incomplete"
+	resp.IncompleteDetails = &ResponseIncompleteDetails{Reason: "max_output_tokens"}
+	completion = CompletionResponseFromResponses(resp)
+	if completion.Choices[0].FinishReason != "length" {
+		t.Errorf("FinishReason = %q, want length", completion.Choices[0].FinishReason)
+	}
+	if back := ResponsesResponseFromCompletion(completion); back.Status != "incomplete" || back.IncompleteDetails.Reason != "max_output_tokens" {
+		t.Errorf("Status = %q, IncompleteDetails = %+v", back.Status, back.IncompleteDetails)
+	}
+}
+
+func TestResponsesJSON(t *testing.T) {
+	req := &ResponsesRequest{
+		Model:      "o3-pro",
+		Tools:      []ResponseTool{{Type: "web_search", Options: map[string]any{"search_context_size": "low"}}},
+		ToolChoice: &ResponseToolChoice{Type: "auto"},
+	}
+	data, err := json.Marshal(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{`"tool_choice":"auto"`, `"search_context_size":"low"`, `"type":"web_search"`} {
+		if !strings.Contains(string(data), want) {
+			t.Errorf("JSON %s missing %s", data, want)
+		}
+	}
+
+	var choice ResponseToolChoice
+	if err := json.Unmarshal([]byte(`{"type":"function","name":"weather"}`), &choice); err != nil || choice.Name != "weather" {
+		t.Errorf("Unmarshal() = %+v, %v", choice, err)
+	}
+	data, _ = json.Marshal(choice)
+	if string(data) != `{"name":"weather","type":"function"}` {
+		t.Errorf("Marshal() = %s", data)
+	}
+}
+
+func TestClientResponses(t *testing.T) {
+	var warnings []*callback.WarningEvent
+	client, err := NewClient(WithMaxRetries(0), WithWarningCallback(func(ctx context.Context, event *callback.WarningEvent) {
+		warnings = append(warnings, event)
+	}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+
+	var got *CompletionRequest
+	client.RegisterProvider(&mockProvider{name: "chat", completionFunc: func(ctx context.Context, req *CompletionRequest) (*CompletionResponse, error) {
+		got = req
+		return &CompletionResponse{ID: "1", Choices: []Choice{{Message: Message{Role: "assistant", Content: "Blue."}, FinishReason: "stop"}}}, nil
+	}})
+	native := &responsesProvider{
+		mockProvider: mockProvider{name: "native"},
+		resp:         &ResponsesResponse{ID: "resp_1", Status: "completed"},
+	}
+	client.RegisterProvider(native)
+	ctx := context.Background()
+
+	req := &ResponsesRequest{
+		Model: "chat/m",
+		Input: []ResponseItem{{Type: "message", Role: "user", Content: []ResponseContent{{Type: "input_text", Text: "Sky color?"}}}},
+		Tools: []ResponseTool{{Type: "web_search"}},
+	}
+	resp, err := client.Responses(ctx, req)
+	if err != nil {
+		t.Fatalf("Responses() error = %v", err)
+	}
+	if resp.OutputText() != "Blue." || resp.RequestID == "" {
+		t.Errorf("Responses() = %+v, want the bridged completion", resp)
+	}
+	if got.Model != "m" || got.Messages[0].Content != "Sky color?" {
+		t.Errorf("completion request = %+v", got)
+	}
+	if len(warnings) != 1 || warnings[0].Code != WarningAPIBridgeLossy || !strings.Contains(warnings[0].Message, "web_search tool") {
+		t.Errorf("warnings = %+v, want one api_bridge_lossy warning", warnings)
+	}
+
+	var invalid *InvalidRequestError
+	if _, err := client.Responses(ctx, &ResponsesRequest{Model: "chat/m", PreviousResponseID: "resp_0"}); !errors.As(err, &invalid) {
+		t.Errorf("Responses() with previous_response_id error = %v, want InvalidRequestError", err)
+	}
+
+	req.Model = "native/m"
+	if _, err := client.Responses(ctx, req); err != nil {
+		t.Fatalf("Responses() error = %v", err)
+	}
+	if native.got.Model != "m" || len(native.got.Tools) != 1 {
+		t.Errorf("native request = %+v, want it as sent", native.got)
+	}
+}
+
+func TestClientResponsesPipeline(t *testing.T) {
+	var success *callback.SuccessEvent
+	client, err := NewClient(
+		WithMaxRetries(0),
+		WithRequestMiddleware(func(ctx context.Context, req *CompletionRequest) (*CompletionRequest, error) {
+			if req.SafetyIdentifier == "banned" {
+				return nil, errors.New("user is banned")
+			}
+			r := *req
+			r.Messages = append([]Message{{Role: "system", Content: "Be kind."}}, req.Messages...)
+			return &r, nil
+		}),
+		WithSuccessCallback(func(ctx context.Context, event *callback.SuccessEvent) {
+			success = event
+		}),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+
+	native := &responsesProvider{
+		mockProvider: mockProvider{name: "native"},
+		resp: &ResponsesResponse{
+			ID:     "resp_1",
+			Status: "completed",
+			Output: []ResponseItem{{Type: "message", Role: "assistant", Content: []ResponseContent{{Type: "output_text", Text: "Blue."}}}},
+			Usage:  &ResponsesUsage{InputTokens: 5, OutputTokens: 1, TotalTokens: 6},
+		},
+	}
+	client.RegisterProvider(native)
+
+	req := &ResponsesRequest{
+		Model:     "native/m",
+		Input:     []ResponseItem{{Type: "message", Role: "user", Content: []ResponseContent{{Type: "input_text", Text: "Sky color?"}}}},
+		Tools:     []ResponseTool{{Type: "web_search"}},
+		Reasoning: &ResponseReasoning{Effort: "low"},
+	}
+	if _, err := client.Responses(context.Background(), req); err != nil {
+		t.Fatalf("Responses() error = %v", err)
+	}
+	if got := native.got; len(got.Input) != 2 || got.Input[0].Role != "system" || len(got.Tools) != 1 || got.Reasoning == nil {
+		t.Errorf("native request = %+v, want the middleware's message with tools and reasoning kept", got)
+	}
+	if success == nil {
+		t.Fatal("success callback not called")
+	}
+	if resp, _ := success.Response.(*CompletionResponse); resp == nil || resp.Usage.TotalTokens != 6 || success.Tokens != 6 {
+		t.Errorf("success event = %+v, want the converted response", success)
+	}
+
+	native.got = nil
+	if _, err := client.Responses(context.Background(), &ResponsesRequest{Model: "native/m", SafetyIdentifier: "banned"}); err == nil || native.got != nil {
+		t.Errorf("Responses() error = %v, want the middleware to refuse the request", err)
+	}
+}
+
+// TestClientResponsesGuards tests that native Responses requests get the
+// same defaults and pre-send checks as completions
+func TestClientResponsesGuards(t *testing.T) {
+	client, err := NewClient(
+		WithMaxRetries(0),
+		WithRequestDefaults(RequestDefaults{Model: "native/*", MaxTokens: IntPtr(64)}),
+		WithRequestLimits(RequestLimits{MaxMessages: 2}),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+
+	native := &responsesProvider{
+		mockProvider: mockProvider{name: "native"},
+		resp:         &ResponsesResponse{ID: "resp_1", Status: "completed"},
+	}
+	client.RegisterProvider(native)
+
+	message := func(text string) ResponseItem {
+		return ResponseItem{Type: "message", Role: "user", Content: []ResponseContent{{Type: "input_text", Text: text}}}
+	}
+	if _, err := client.Responses(context.Background(), &ResponsesRequest{Model: "native/m", Input: []ResponseItem{message("Hi")}}); err != nil {
+		t.Fatalf("Responses() error = %v", err)
+	}
+	if got := native.got.MaxOutputTokens; got == nil || *got != 64 {
+		t.Errorf("MaxOutputTokens = %v, want the configured default 64", got)
+	}
+
+	native.got = nil
+	_, err = client.Responses(context.Background(), &ResponsesRequest{
+		Model: "native/m",
+		Input: []ResponseItem{message("a"), message("b"), message("c")},
+	})
+	var limitErr *RequestLimitError
+	if !errors.As(err, &limitErr) || native.got != nil {
+		t.Errorf("Responses() error = %v, want *RequestLimitError before sending", err)
+	}
+}
+
+func TestCompletionResponsesOnlyModel(t *testing.T) {
+	client, err := NewClient(WithMaxRetries(0))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+
+	p := &responsesProvider{
+		mockProvider: mockProvider{name: "openai"},
+		resp: &ResponsesResponse{
+			ID:     "resp_1",
+			Model:  "o3-pro",
+			Status: "completed",
+			Output: []ResponseItem{{Type: "message", Role: "assistant", Content: []ResponseContent{{Type: "output_text", Text: "42"}}}},
+			Usage:  &ResponsesUsage{InputTokens: 5, OutputTokens: 1, TotalTokens: 6},
+		},
+	}
+	client.RegisterProvider(p)
+	req := &CompletionRequest{Model: "openai/o3-pro", Messages: []Message{{Role: "user", Content: "Answer?"}}}
+
+	resp, err := client.Completion(context.Background(), req)
+	if err != nil {
+		t.Fatalf("Completion() error = %v", err)
+	}
+	if resp.Choices[0].Message.Content != "42" || resp.Usage.TotalTokens != 6 {
+		t.Errorf("Completion() = %+v, want the Responses API output", resp)
+	}
+	if p.got == nil || p.got.Model != "o3-pro" {
+		t.Errorf("Responses request = %+v", p.got)
+	}
+
+	stream, err := client.CompletionStream(context.Background(), req)
+	if err != nil {
+		t.Fatalf("CompletionStream() error = %v", err)
+	}
+	defer stream.Close()
+	acc := NewStreamAccumulator()
+	for {
+		chunk, err := stream.Recv()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatalf("Recv() error = %v", err)
+		}
+		acc.Add(chunk)
+	}
+	if streamed := acc.Response(); streamed.Choices[0].Message.Content != "42" || streamed.Choices[0].FinishReason != "stop" {
+		t.Errorf("streamed = %+v, want the Responses API output", streamed)
+	}
+}
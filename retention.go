@@ -0,0 +1,53 @@
+package warp
+
+import (
+	"fmt"
+	"maps"
+)
+
+// RuleZeroDataRetention is the PolicyViolationError.Rule for requests
+// refused because the provider cannot honor zero data retention.
+const RuleZeroDataRetention = "zero_data_retention"
+
+// DataRetentionController is implemented by providers that can ask their
+// API not to retain prompts and completions.
+//
+// When the client is created with WithZeroDataRetention(true), completion
+// requests to providers that do not implement it are refused.
+type DataRetentionController interface {
+	// DisableDataRetention sets the provider's privacy options on req,
+	// typically in req.ExtraBody, which the caller has already copied.
+	// It returns an error if retention cannot be disabled.
+	DisableDataRetention(req *CompletionRequest) error
+}
+
+// applyZeroDataRetention returns a copy of req with the provider's privacy
+// options set, or a *PolicyViolationError if the provider cannot honor zero
+// data retention. It returns req unchanged when the option is disabled.
+func (c *client) applyZeroDataRetention(p Provider, req *CompletionRequest, providerName, modelName string) (*CompletionRequest, error) {
+	if !c.config.ZeroDataRetention {
+		return req, nil
+	}
+
+	controller, ok := p.(DataRetentionController)
+	if !ok {
+		return nil, NewPolicyViolationError(
+			fmt.Sprintf("provider %q cannot honor zero data retention", providerName),
+			RuleZeroDataRetention, "", providerName, modelName)
+	}
+
+	r := *req
+	r.ExtraBody = maps.Clone(req.ExtraBody)
+	if r.ExtraBody == nil {
+		r.ExtraBody = make(map[string]any)
+	}
+	if err := controller.DisableDataRetention(&r); err != nil {
+		violation := NewPolicyViolationError(
+			fmt.Sprintf("provider %q cannot honor zero data retention: %v", providerName, err),
+			RuleZeroDataRetention, "", providerName, modelName)
+		violation.OriginalError = err
+		return nil, violation
+	}
+
+	return &r, nil
+}
@@ -0,0 +1,68 @@
+package warp
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+// retentionProvider is a mockProvider that implements DataRetentionController.
+type retentionProvider struct {
+	mockProvider
+	err error
+}
+
+func (p *retentionProvider) DisableDataRetention(req *CompletionRequest) error {
+	if p.err != nil {
+		return p.err
+	}
+	req.ExtraBody["store"] = false
+	return nil
+}
+
+func TestZeroDataRetention(t *testing.T) {
+	var got *CompletionRequest
+	capture := func(ctx context.Context, req *CompletionRequest) (*CompletionResponse, error) {
+		got = req
+		return &CompletionResponse{Choices: []Choice{{Message: Message{Role: "assistant", Content: "ok"}}}}, nil
+	}
+
+	client, err := NewClient(WithZeroDataRetention(true), WithMaxRetries(0))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+
+	client.RegisterProvider(&retentionProvider{mockProvider: mockProvider{name: "private", completionFunc: capture}})
+	client.RegisterProvider(&retentionProvider{mockProvider: mockProvider{name: "shared", completionFunc: capture}, err: errors.New("no agreement")})
+	client.RegisterProvider(&mockProvider{name: "plain", completionFunc: capture})
+
+	extra := map[string]any{"user_field": 1}
+	req := &CompletionRequest{Model: "private/m", Messages: []Message{{Role: "user", Content: "hi"}}, ExtraBody: extra}
+	if _, err := client.Completion(context.Background(), req); err != nil {
+		t.Fatalf("Completion() error = %v", err)
+	}
+	if got.ExtraBody["store"] != false || got.ExtraBody["user_field"] != 1 {
+		t.Errorf("ExtraBody = %v, want store=false and user_field", got.ExtraBody)
+	}
+	if _, ok := extra["store"]; ok {
+		t.Error("caller's ExtraBody was modified")
+	}
+
+	for _, model := range []string{"shared/m", "plain/m"} {
+		got = nil
+		_, err := client.Completion(context.Background(), &CompletionRequest{Model: model, Messages: req.Messages})
+
+		var violation *PolicyViolationError
+		if !errors.As(err, &violation) || violation.Rule != RuleZeroDataRetention {
+			t.Errorf("%s: error = %v, want zero data retention violation", model, err)
+		}
+		if got != nil {
+			t.Errorf("%s: request was sent", model)
+		}
+
+		if _, err := client.CompletionStream(context.Background(), &CompletionRequest{Model: model, Messages: req.Messages}); !errors.As(err, &violation) {
+			t.Errorf("%s: stream error = %v, want violation", model, err)
+		}
+	}
+}
@@ -0,0 +1,211 @@
+package warp
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// HarmCategory is a category of harmful content.
+type HarmCategory string
+
+const (
+	// HarmHarassment is harassment or bullying.
+	HarmHarassment HarmCategory = "harassment"
+
+	// HarmHate is hate speech.
+	HarmHate HarmCategory = "hate"
+
+	// HarmSexual is sexually explicit content.
+	HarmSexual HarmCategory = "sexual"
+
+	// HarmDangerous is content that facilitates dangerous activities.
+	HarmDangerous HarmCategory = "dangerous"
+
+	// HarmSelfHarm is content that promotes or depicts self-harm.
+	HarmSelfHarm HarmCategory = "self_harm"
+
+	// HarmViolence is violent content.
+	HarmViolence HarmCategory = "violence"
+)
+
+// SafetyThreshold is the lowest severity of a harm category that is
+// blocked.
+type SafetyThreshold string
+
+const (
+	// SafetyBlockNone blocks nothing.
+	SafetyBlockNone SafetyThreshold = "none"
+
+	// SafetyBlockHigh blocks high severity content only.
+	SafetyBlockHigh SafetyThreshold = "high"
+
+	// SafetyBlockMedium blocks medium and high severity content.
+	SafetyBlockMedium SafetyThreshold = "medium"
+
+	// SafetyBlockLow blocks low, medium, and high severity content.
+	SafetyBlockLow SafetyThreshold = "low"
+)
+
+// SafetySettings are per-category safety thresholds. Categories without a
+// threshold keep the provider's default.
+//
+// Providers apply them as follows:
+//   - Gemini (vertex) sends them as safety settings; it has no self-harm
+//     or violence category, so those are ignored.
+//   - Azure OpenAI filters are configured per deployment, so responses
+//     are checked against them using the returned content filter
+//     severities, which can only be stricter. Streamed responses are not
+//     checked.
+//   - OpenAI has no safety parameter, so the prompt is checked with the
+//     moderation API first, blocking categories whose score reaches
+//     0.8, 0.5, or 0.2 for high, medium, and low thresholds.
+//
+// Other providers ignore them. A blocked request fails with a
+// *ContentFilterError.
+//
+// Example:
+//
+//	req.SafetySettings = warp.SafetySettings{
+//	    warp.HarmHate:       warp.SafetyBlockLow,
+//	    warp.HarmHarassment: warp.SafetyBlockMedium,
+//	    warp.HarmSexual:     warp.SafetyBlockNone,
+//	}
+type SafetySettings map[HarmCategory]SafetyThreshold
+
+// severityRanks orders content severities. Gemini levels such as
+// "HARM_SEVERITY_HIGH" or "HARM_PROBABILITY_LOW" are ranked by their
+// suffix.
+var severityRanks = map[string]int{
+	"safe":       0,
+	"negligible": 0,
+	"low":        1,
+	"medium":     2,
+	"high":       3,
+}
+
+// thresholdRanks are the lowest severity rank each threshold blocks.
+var thresholdRanks = map[SafetyThreshold]int{
+	SafetyBlockLow:    1,
+	SafetyBlockMedium: 2,
+	SafetyBlockHigh:   3,
+}
+
+// Blocks reports whether content of category at severity (e.g., "low",
+// "medium", "HIGH") should be blocked. Unknown severities and categories
+// without a threshold are not blocked.
+func (s SafetySettings) Blocks(category HarmCategory, severity string) bool {
+	limit, ok := thresholdRanks[s[category]]
+	if !ok {
+		return false
+	}
+	severity = strings.ToLower(severity)
+	severity = strings.TrimPrefix(severity, "harm_severity_")
+	severity = strings.TrimPrefix(severity, "harm_probability_")
+	rank, ok := severityRanks[severity]
+	return ok && rank >= limit
+}
+
+// Categories returns the categories with a threshold, sorted.
+func (s SafetySettings) Categories() []HarmCategory {
+	categories := make([]HarmCategory, 0, len(s))
+	for category := range s {
+		categories = append(categories, category)
+	}
+	sort.Slice(categories, func(i, j int) bool { return categories[i] < categories[j] })
+	return categories
+}
+
+// moderationScoreThresholds are the moderation scores at or above which
+// each threshold blocks a category.
+var moderationScoreThresholds = map[SafetyThreshold]float64{
+	SafetyBlockHigh:   0.8,
+	SafetyBlockMedium: 0.5,
+	SafetyBlockLow:    0.2,
+}
+
+// moderationScore returns the highest moderation score of category, or
+// false if the moderation API has no matching category.
+func moderationScore(scores ModerationCategoryScores, category HarmCategory) (float64, bool) {
+	switch category {
+	case HarmHarassment:
+		return max(scores.Harassment, scores.HarassmentThreatening), true
+	case HarmHate:
+		return max(scores.Hate, scores.HateThreatening), true
+	case HarmSexual:
+		return max(scores.Sexual, scores.SexualMinors), true
+	case HarmSelfHarm:
+		return max(scores.SelfHarm, scores.SelfHarmIntent, scores.SelfHarmInstructions), true
+	case HarmViolence:
+		return max(scores.Violence, scores.ViolenceGraphic), true
+	}
+	return 0, false
+}
+
+// checkSafety enforces req's safety settings for OpenAI, whose API has no
+// safety parameter, by moderating the user messages before the request is
+// sent. It returns a *ContentFilterError if a category reaches its
+// threshold.
+func (c *client) checkSafety(ctx context.Context, req *CompletionRequest, providerName string) error {
+	if providerName != "openai" || len(req.SafetySettings) == 0 {
+		return nil
+	}
+	active := false
+	for _, threshold := range req.SafetySettings {
+		if _, ok := moderationScoreThresholds[threshold]; ok {
+			active = true
+		}
+	}
+	if !active {
+		return nil
+	}
+
+	var input []string
+	for _, msg := range req.Messages {
+		if msg.Role != "user" {
+			continue
+		}
+		if text := messageText(msg.Content); text != "" {
+			input = append(input, text)
+		}
+	}
+	if len(input) == 0 {
+		return nil
+	}
+
+	resp, err := c.Moderation(ctx, &ModerationRequest{Input: input, APIKey: req.APIKey, APIBase: req.APIBase})
+	if err != nil {
+		return fmt.Errorf("safety moderation check failed: %w", err)
+	}
+
+	var results []ContentFilterResult
+	blocked := false
+	for _, category := range req.SafetySettings.Categories() {
+		limit, ok := moderationScoreThresholds[req.SafetySettings[category]]
+		if !ok {
+			continue
+		}
+		var score float64
+		found := false
+		for _, result := range resp.Results {
+			if s, ok := moderationScore(result.CategoryScores, category); ok {
+				score, found = max(score, s), true
+			}
+		}
+		if !found {
+			continue
+		}
+		filtered := score >= limit
+		blocked = blocked || filtered
+		results = append(results, ContentFilterResult{
+			Category: string(category),
+			Severity: fmt.Sprintf("%.2f", score),
+			Filtered: filtered,
+		})
+	}
+	if !blocked {
+		return nil
+	}
+	return NewContentFilterError("prompt blocked by safety settings", providerName, "moderation", true, results)
+}
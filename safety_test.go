@@ -0,0 +1,83 @@
+package warp
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestSafetySettingsBlocks(t *testing.T) {
+	settings := SafetySettings{
+		HarmHate:       SafetyBlockLow,
+		HarmHarassment: SafetyBlockHigh,
+		HarmSexual:     SafetyBlockNone,
+	}
+
+	tests := []struct {
+		category HarmCategory
+		severity string
+		want     bool
+	}{
+		{HarmHate, "safe", false},
+		{HarmHate, "low", true},
+		{HarmHate, "HARM_SEVERITY_MEDIUM", true},
+		{HarmHarassment, "medium", false},
+		{HarmHarassment, "HIGH", true},
+		{HarmHarassment, "HARM_PROBABILITY_HIGH", true},
+		{HarmSexual, "high", false},
+		{HarmViolence, "high", false},
+		{HarmHate, "unknown", false},
+	}
+	for _, tt := range tests {
+		if got := settings.Blocks(tt.category, tt.severity); got != tt.want {
+			t.Errorf("Blocks(%s, %s) = %v, want %v", tt.category, tt.severity, got, tt.want)
+		}
+	}
+}
+
+func TestCompletionSafetyModeration(t *testing.T) {
+	c, err := NewClient()
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+	defer c.Close()
+
+	provider := &mockModerationProvider{
+		name: "openai",
+		moderationResp: &ModerationResponse{
+			Results: []ModerationResult{{CategoryScores: ModerationCategoryScores{Hate: 0.3, Violence: 0.6}}},
+		},
+		supportsModeration: true,
+	}
+	if err := c.RegisterProvider(provider); err != nil {
+		t.Fatalf("Failed to register provider: %v", err)
+	}
+
+	req := &CompletionRequest{
+		Model:    "openai/gpt-4o",
+		Messages: []Message{{Role: "user", Content: "Hello"}},
+		SafetySettings: SafetySettings{
+			HarmHate:     SafetyBlockMedium,
+			HarmViolence: SafetyBlockMedium,
+		},
+	}
+	_, err = c.Completion(context.Background(), req)
+	var filtered *ContentFilterError
+	if !errors.As(err, &filtered) {
+		t.Fatalf("Completion() error = %v, want *ContentFilterError", err)
+	}
+	if !filtered.Prompt {
+		t.Error("Prompt = false, want true")
+	}
+	if got := filtered.FilteredCategories(); !reflect.DeepEqual(got, []string{"violence"}) {
+		t.Errorf("FilteredCategories() = %v, want [violence]", got)
+	}
+
+	// Below the thresholds the request reaches the provider
+	req.SafetySettings = SafetySettings{HarmHate: SafetyBlockHigh, HarmViolence: SafetyBlockNone}
+	_, err = c.Completion(context.Background(), req)
+	if err == nil || errors.As(err, &filtered) {
+		t.Errorf("Completion() error = %v, want provider error", err)
+	}
+}
@@ -0,0 +1,69 @@
+package warp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// ServiceTier is a provider processing tier, trading latency and
+// availability against price.
+//
+// Providers translate tiers to their own parameters: OpenAI sends
+// "service_tier" as-is, and Anthropic maps ServiceTierPriority and
+// ServiceTierAuto to "auto" (use Priority Tier capacity when available) and
+// ServiceTierDefault to "standard_only". Providers without service tiers
+// ignore the field.
+type ServiceTier string
+
+const (
+	// ServiceTierAuto lets the provider choose the tier, typically priority
+	// capacity when the account has it.
+	ServiceTierAuto ServiceTier = "auto"
+
+	// ServiceTierDefault is the provider's standard tier.
+	ServiceTierDefault ServiceTier = "default"
+
+	// ServiceTierFlex is a cheaper tier with higher latency and occasional
+	// unavailability (OpenAI flex processing).
+	ServiceTierFlex ServiceTier = "flex"
+
+	// ServiceTierPriority is a premium tier with faster, more reliable
+	// processing.
+	ServiceTierPriority ServiceTier = "priority"
+
+	// ServiceTierBatch marks a response served by a provider's batch API,
+	// such as OpenAI Batch or Anthropic Message Batches. It is not sent in
+	// requests: set it on the CompletionResponse of each batch result so
+	// CompletionCost and cost callbacks apply the model's BatchDiscount.
+	ServiceTierBatch ServiceTier = "batch"
+)
+
+// WarningServiceTierSpillover is the callback.WarningEvent code raised when
+// a priority-tier request is retried at the default tier.
+const WarningServiceTierSpillover = "service_tier_spillover"
+
+// spillServiceTier returns req at the default tier if it was sent at the
+// priority tier and err shows the provider is overloaded, and nil
+// otherwise. Spillover must be enabled with WithServiceTierSpillover.
+func (c *client) spillServiceTier(ctx context.Context, req *CompletionRequest, err error) *CompletionRequest {
+	if !c.config.ServiceTierSpillover || req.ServiceTier != ServiceTierPriority || !isOverloaded(err) {
+		return nil
+	}
+
+	r := *req
+	r.ServiceTier = ServiceTierDefault
+	c.warn(ctx, WarningServiceTierSpillover, fmt.Sprintf("priority tier overloaded, retrying at the default tier: %v", err))
+	return &r
+}
+
+// isOverloaded reports whether err is a rate limit, service unavailable,
+// or overloaded (529) error.
+func isOverloaded(err error) bool {
+	var rateLimit *RateLimitError
+	var unavailable *ServiceUnavailableError
+	var apiErr *APIError
+	return errors.As(err, &rateLimit) ||
+		errors.As(err, &unavailable) ||
+		(errors.As(err, &apiErr) && apiErr.StatusCode == 529)
+}
@@ -0,0 +1,82 @@
+package warp
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/blue-context/warp/callback"
+)
+
+func TestServiceTierSpillover(t *testing.T) {
+	overloaded := map[string]error{
+		"rate limit":  NewRateLimitError("slow down", "mock", 0, nil),
+		"unavailable": NewServiceUnavailableError("down", "mock", nil),
+		"overloaded":  NewAPIError("overloaded", 529, "mock", nil),
+	}
+	for name, providerErr := range overloaded {
+		t.Run(name, func(t *testing.T) {
+			var tiers []ServiceTier
+			var warnings []*callback.WarningEvent
+			client, err := NewClient(
+				WithMaxRetries(0),
+				WithServiceTierSpillover(true),
+				WithWarningCallback(func(ctx context.Context, event *callback.WarningEvent) {
+					warnings = append(warnings, event)
+				}),
+			)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer client.Close()
+			client.RegisterProvider(&mockProvider{name: "mock", completionFunc: func(ctx context.Context, req *CompletionRequest) (*CompletionResponse, error) {
+				tiers = append(tiers, req.ServiceTier)
+				if req.ServiceTier == ServiceTierPriority {
+					return nil, providerErr
+				}
+				return &CompletionResponse{Choices: []Choice{{Message: Message{Role: "assistant", Content: "ok"}}}}, nil
+			}})
+
+			_, err = client.Completion(context.Background(), &CompletionRequest{
+				Model:       "mock/m",
+				Messages:    []Message{{Role: "user", Content: "Hi"}},
+				ServiceTier: ServiceTierPriority,
+			})
+			if err != nil {
+				t.Fatalf("Completion() error = %v", err)
+			}
+			if len(tiers) != 2 || tiers[1] != ServiceTierDefault {
+				t.Errorf("tiers = %v, want [priority default]", tiers)
+			}
+			if len(warnings) != 1 || warnings[0].Code != WarningServiceTierSpillover {
+				t.Errorf("warnings = %+v, want one spillover warning", warnings)
+			}
+		})
+	}
+}
+
+func TestServiceTierSpilloverDisabled(t *testing.T) {
+	calls := 0
+	client, err := NewClient(WithMaxRetries(0))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+	client.RegisterProvider(&mockProvider{name: "mock", completionFunc: func(ctx context.Context, req *CompletionRequest) (*CompletionResponse, error) {
+		calls++
+		return nil, NewRateLimitError("slow down", "mock", 0, nil)
+	}})
+
+	_, err = client.Completion(context.Background(), &CompletionRequest{
+		Model:       "mock/m",
+		Messages:    []Message{{Role: "user", Content: "Hi"}},
+		ServiceTier: ServiceTierPriority,
+	})
+	var rateLimit *RateLimitError
+	if !errors.As(err, &rateLimit) {
+		t.Errorf("error = %v, want RateLimitError", err)
+	}
+	if calls != 1 {
+		t.Errorf("calls = %d, want 1 without spillover", calls)
+	}
+}
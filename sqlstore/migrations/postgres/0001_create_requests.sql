@@ -0,0 +1,15 @@
+CREATE TABLE warp_requests (
+    id                BIGSERIAL PRIMARY KEY,
+    request_id        TEXT NOT NULL DEFAULT '',
+    created_at        TIMESTAMPTZ NOT NULL,
+    tenant            TEXT NOT NULL DEFAULT '',
+    provider          TEXT NOT NULL,
+    model             TEXT NOT NULL,
+    status            TEXT NOT NULL,
+    error             TEXT NOT NULL DEFAULT '',
+    duration_ms       BIGINT NOT NULL DEFAULT 0,
+    prompt_tokens     BIGINT NOT NULL DEFAULT 0,
+    completion_tokens BIGINT NOT NULL DEFAULT 0,
+    total_tokens      BIGINT NOT NULL DEFAULT 0,
+    cost              DOUBLE PRECISION NOT NULL DEFAULT 0
+);
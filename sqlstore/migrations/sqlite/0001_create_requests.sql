@@ -0,0 +1,15 @@
+CREATE TABLE warp_requests (
+    id                INTEGER PRIMARY KEY AUTOINCREMENT,
+    request_id        TEXT NOT NULL DEFAULT '',
+    created_at        TIMESTAMP NOT NULL,
+    tenant            TEXT NOT NULL DEFAULT '',
+    provider          TEXT NOT NULL,
+    model             TEXT NOT NULL,
+    status            TEXT NOT NULL,
+    error             TEXT NOT NULL DEFAULT '',
+    duration_ms       INTEGER NOT NULL DEFAULT 0,
+    prompt_tokens     INTEGER NOT NULL DEFAULT 0,
+    completion_tokens INTEGER NOT NULL DEFAULT 0,
+    total_tokens      INTEGER NOT NULL DEFAULT 0,
+    cost              REAL NOT NULL DEFAULT 0
+);
@@ -0,0 +1,3 @@
+CREATE INDEX warp_requests_created_at_idx ON warp_requests (created_at);
+CREATE INDEX warp_requests_model_idx ON warp_requests (provider, model, created_at);
+CREATE INDEX warp_requests_tenant_idx ON warp_requests (tenant, created_at);
@@ -0,0 +1,225 @@
+package sqlstore
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Filter restricts the requests a query covers. Zero fields match all.
+type Filter struct {
+	// Since and Until bound CreatedAt (Since inclusive, Until exclusive).
+	Since time.Time
+	Until time.Time
+
+	// Tenant, Provider, Model and Status match exactly.
+	Tenant   string
+	Provider string
+	Model    string
+	Status   string
+}
+
+// where returns the WHERE clause and arguments for the filter.
+func (f Filter) where(dialect Dialect) (string, []any) {
+	var conds []string
+	var args []any
+	add := func(cond string, arg any) {
+		args = append(args, arg)
+		conds = append(conds, fmt.Sprintf(cond, dialect.placeholder(len(args))))
+	}
+
+	if !f.Since.IsZero() {
+		add("created_at >= %s", f.Since.UTC())
+	}
+	if !f.Until.IsZero() {
+		add("created_at < %s", f.Until.UTC())
+	}
+	if f.Tenant != "" {
+		add("tenant = %s", f.Tenant)
+	}
+	if f.Provider != "" {
+		add("provider = %s", f.Provider)
+	}
+	if f.Model != "" {
+		add("model = %s", f.Model)
+	}
+	if f.Status != "" {
+		add("status = %s", f.Status)
+	}
+
+	if len(conds) == 0 {
+		return "", nil
+	}
+	return " WHERE " + strings.Join(conds, " AND "), args
+}
+
+// Usage is aggregated usage of a group of requests.
+type Usage struct {
+	Requests         int64
+	Errors           int64
+	PromptTokens     int64
+	CompletionTokens int64
+	TotalTokens      int64
+	Cost             float64
+
+	// AvgLatency is the mean request duration.
+	AvgLatency time.Duration
+}
+
+// ErrorRate returns the fraction of requests that failed (0 if none).
+func (u Usage) ErrorRate() float64 {
+	if u.Requests == 0 {
+		return 0
+	}
+	return float64(u.Errors) / float64(u.Requests)
+}
+
+// ModelUsage is the usage of one provider/model pair.
+type ModelUsage struct {
+	Provider string
+	Model    string
+	Usage
+}
+
+// DailyUsage is the usage of one UTC day.
+type DailyUsage struct {
+	// Day is the day in YYYY-MM-DD form.
+	Day string
+	Usage
+}
+
+// TenantUsage is the usage of one tenant.
+type TenantUsage struct {
+	Tenant string
+	Usage
+}
+
+// usageColumns are the aggregate columns scanned into usageDest.
+const usageColumns = `COUNT(*),
+    COALESCE(SUM(CASE WHEN status = 'failure' THEN 1 ELSE 0 END), 0),
+    COALESCE(SUM(prompt_tokens), 0),
+    COALESCE(SUM(completion_tokens), 0),
+    COALESCE(SUM(total_tokens), 0),
+    COALESCE(SUM(cost), 0),
+    COALESCE(AVG(duration_ms), 0)`
+
+// usageDest returns the scan destinations for usageColumns.
+func usageDest(u *Usage, avgMillis *float64) []any {
+	return []any{&u.Requests, &u.Errors, &u.PromptTokens, &u.CompletionTokens, &u.TotalTokens, &u.Cost, avgMillis}
+}
+
+// Totals returns the aggregate usage of the matching requests.
+func (s *Store) Totals(ctx context.Context, f Filter) (*Usage, error) {
+	where, args := f.where(s.dialect)
+	query := "SELECT " + usageColumns + " FROM warp_requests" + where
+
+	var u Usage
+	var avg float64
+	if err := s.db.QueryRowContext(ctx, query, args...).Scan(usageDest(&u, &avg)...); err != nil {
+		return nil, fmt.Errorf("failed to query totals: %w", err)
+	}
+	u.AvgLatency = millis(avg)
+	return &u, nil
+}
+
+// UsageByModel returns usage grouped by provider and model, by descending
+// cost.
+func (s *Store) UsageByModel(ctx context.Context, f Filter) ([]ModelUsage, error) {
+	where, args := f.where(s.dialect)
+	query := "SELECT provider, model, " + usageColumns + " FROM warp_requests" + where +
+		" GROUP BY provider, model ORDER BY 8 DESC, provider, model"
+
+	return queryGroups(ctx, s, query, args, func(m *ModelUsage) []any {
+		return []any{&m.Provider, &m.Model}
+	}, func(m *ModelUsage) *Usage { return &m.Usage })
+}
+
+// UsageByDay returns usage grouped by UTC day, oldest first.
+func (s *Store) UsageByDay(ctx context.Context, f Filter) ([]DailyUsage, error) {
+	where, args := f.where(s.dialect)
+	day := s.dialect.day("created_at")
+	query := "SELECT " + day + ", " + usageColumns + " FROM warp_requests" + where +
+		" GROUP BY " + day + " ORDER BY 1"
+
+	return queryGroups(ctx, s, query, args, func(d *DailyUsage) []any {
+		return []any{&d.Day}
+	}, func(d *DailyUsage) *Usage { return &d.Usage })
+}
+
+// UsageByTenant returns usage grouped by tenant, by descending cost.
+// Requests without a tenant are grouped under the empty tenant.
+func (s *Store) UsageByTenant(ctx context.Context, f Filter) ([]TenantUsage, error) {
+	where, args := f.where(s.dialect)
+	query := "SELECT tenant, " + usageColumns + " FROM warp_requests" + where +
+		" GROUP BY tenant ORDER BY 7 DESC, tenant"
+
+	return queryGroups(ctx, s, query, args, func(t *TenantUsage) []any {
+		return []any{&t.Tenant}
+	}, func(t *TenantUsage) *Usage { return &t.Usage })
+}
+
+// Requests returns the most recent matching requests, newest first, up to
+// limit (0 for no limit).
+func (s *Store) Requests(ctx context.Context, f Filter, limit int) ([]Request, error) {
+	where, args := f.where(s.dialect)
+	query := `SELECT id, request_id, created_at, tenant, provider, model, status, error,
+    duration_ms, prompt_tokens, completion_tokens, total_tokens, cost
+FROM warp_requests` + where + " ORDER BY created_at DESC, id DESC"
+	if limit > 0 {
+		args = append(args, limit)
+		query += " LIMIT " + s.dialect.placeholder(len(args))
+	}
+
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query requests: %w", err)
+	}
+	defer rows.Close()
+
+	var requests []Request
+	for rows.Next() {
+		var r Request
+		var durationMillis int64
+		if err := rows.Scan(&r.ID, &r.RequestID, &r.CreatedAt, &r.Tenant, &r.Provider, &r.Model, &r.Status, &r.Error,
+			&durationMillis, &r.PromptTokens, &r.CompletionTokens, &r.TotalTokens, &r.Cost); err != nil {
+			return nil, fmt.Errorf("failed to scan request: %w", err)
+		}
+		r.Duration = time.Duration(durationMillis) * time.Millisecond
+		requests = append(requests, r)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to query requests: %w", err)
+	}
+	return requests, nil
+}
+
+// queryGroups runs a grouped usage query whose rows are the group key
+// columns followed by usageColumns.
+func queryGroups[T any](ctx context.Context, s *Store, query string, args []any, keys func(*T) []any, usage func(*T) *Usage) ([]T, error) {
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query usage: %w", err)
+	}
+	defer rows.Close()
+
+	var groups []T
+	for rows.Next() {
+		var g T
+		var avg float64
+		if err := rows.Scan(append(keys(&g), usageDest(usage(&g), &avg)...)...); err != nil {
+			return nil, fmt.Errorf("failed to scan usage: %w", err)
+		}
+		usage(&g).AvgLatency = millis(avg)
+		groups = append(groups, g)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to query usage: %w", err)
+	}
+	return groups, nil
+}
+
+// millis converts fractional milliseconds to a duration.
+func millis(ms float64) time.Duration {
+	return time.Duration(ms * float64(time.Millisecond))
+}
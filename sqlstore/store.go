@@ -0,0 +1,233 @@
+// Package sqlstore records request metadata, usage, cost, latency and
+// errors in a SQL database for durable analytics, and provides queries over
+// them.
+//
+// It uses database/sql and works with Postgres and SQLite; the application
+// imports the driver. Migrate creates and upgrades the schema (the
+// migrations are embedded SQL files under migrations/). Message content is
+// never stored.
+//
+// Basic usage:
+//
+//	import _ "github.com/jackc/pgx/v5/stdlib"
+//
+//	db, err := sql.Open("pgx", os.Getenv("DATABASE_URL"))
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+//	store := sqlstore.New(db, sqlstore.DialectPostgres)
+//	if err := store.Migrate(ctx); err != nil {
+//	    log.Fatal(err)
+//	}
+//
+//	client, err := warp.NewClient(
+//	    warp.WithSuccessCallback(store.Success),
+//	    warp.WithFailureCallback(store.Failure),
+//	)
+//
+//	// Later: cost per model over the last week
+//	usage, err := store.UsageByModel(ctx, sqlstore.Filter{Since: time.Now().AddDate(0, 0, -7)})
+package sqlstore
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/blue-context/warp"
+	"github.com/blue-context/warp/callback"
+	"github.com/blue-context/warp/policy"
+)
+
+// Request statuses.
+const (
+	StatusSuccess = "success"
+	StatusFailure = "failure"
+)
+
+// Dialect selects the SQL flavor of the database.
+type Dialect string
+
+const (
+	// DialectPostgres is PostgreSQL ($1 placeholders, TIMESTAMPTZ).
+	DialectPostgres Dialect = "postgres"
+
+	// DialectSQLite is SQLite (? placeholders).
+	DialectSQLite Dialect = "sqlite"
+)
+
+// placeholder returns the bind parameter for the nth (1-based) argument.
+func (d Dialect) placeholder(n int) string {
+	if d == DialectPostgres {
+		return fmt.Sprintf("$%d", n)
+	}
+	return "?"
+}
+
+// day returns the expression truncating a timestamp column to its UTC day.
+func (d Dialect) day(column string) string {
+	if d == DialectPostgres {
+		return fmt.Sprintf("to_char(%s AT TIME ZONE 'UTC', 'YYYY-MM-DD')", column)
+	}
+	return fmt.Sprintf("strftime('%%Y-%%m-%%d', %s)", column)
+}
+
+// Request is one recorded request.
+type Request struct {
+	// ID is the row ID, assigned by the database.
+	ID int64
+
+	// RequestID is the client request ID.
+	RequestID string
+
+	// CreatedAt is when the request completed (UTC).
+	CreatedAt time.Time
+
+	// Tenant is the policy.MetadataTenant request metadata value, if any.
+	Tenant string
+
+	// Provider and Model identify the endpoint called.
+	Provider string
+	Model    string
+
+	// Status is StatusSuccess or StatusFailure.
+	Status string
+
+	// Error is the failure message.
+	Error string
+
+	// Duration is the request duration, stored in milliseconds.
+	Duration time.Duration
+
+	// PromptTokens, CompletionTokens, and TotalTokens are the token usage.
+	PromptTokens     int
+	CompletionTokens int
+	TotalTokens      int
+
+	// Cost is the estimated cost in USD.
+	Cost float64
+}
+
+// Store writes requests to and queries them from a database.
+//
+// Thread Safety: Store is safe for concurrent use.
+type Store struct {
+	db      *sql.DB
+	dialect Dialect
+	onError func(error)
+	now     func() time.Time
+}
+
+// Option configures a Store.
+type Option func(*Store)
+
+// WithErrorHandler sets a function called when Success or Failure cannot
+// write a request. Callbacks cannot return errors, so without a handler
+// these errors are dropped.
+func WithErrorHandler(fn func(error)) Option {
+	return func(s *Store) {
+		s.onError = fn
+	}
+}
+
+// New creates a store using db.
+func New(db *sql.DB, dialect Dialect, opts ...Option) *Store {
+	s := &Store{
+		db:      db,
+		dialect: dialect,
+		now:     time.Now,
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+// Record inserts a request and sets its ID.
+func (s *Store) Record(ctx context.Context, req *Request) error {
+	if req.CreatedAt.IsZero() {
+		req.CreatedAt = s.now()
+	}
+	req.CreatedAt = req.CreatedAt.UTC()
+
+	columns := []string{
+		"request_id", "created_at", "tenant", "provider", "model", "status", "error",
+		"duration_ms", "prompt_tokens", "completion_tokens", "total_tokens", "cost",
+	}
+	args := []any{
+		req.RequestID, req.CreatedAt, req.Tenant, req.Provider, req.Model, req.Status, req.Error,
+		req.Duration.Milliseconds(), req.PromptTokens, req.CompletionTokens, req.TotalTokens, req.Cost,
+	}
+	placeholders := make([]string, len(args))
+	for i := range args {
+		placeholders[i] = s.dialect.placeholder(i + 1)
+	}
+	query := fmt.Sprintf("INSERT INTO warp_requests (%s) VALUES (%s) RETURNING id",
+		strings.Join(columns, ", "), strings.Join(placeholders, ", "))
+
+	if err := s.db.QueryRowContext(ctx, query, args...).Scan(&req.ID); err != nil {
+		return fmt.Errorf("failed to record request: %w", err)
+	}
+	return nil
+}
+
+// Success records a successful request. Register it with
+// warp.WithSuccessCallback.
+func (s *Store) Success(ctx context.Context, event *callback.SuccessEvent) {
+	req := &Request{
+		RequestID:   event.RequestID,
+		CreatedAt:   event.EndTime,
+		Tenant:      tenant(event.Request),
+		Provider:    event.Provider,
+		Model:       event.Model,
+		Status:      StatusSuccess,
+		Duration:    event.Duration,
+		TotalTokens: event.Tokens,
+		Cost:        event.Cost,
+	}
+	if resp, _ := event.Response.(*warp.CompletionResponse); resp != nil && resp.Usage != nil {
+		req.PromptTokens = resp.Usage.PromptTokens
+		req.CompletionTokens = resp.Usage.CompletionTokens
+		req.TotalTokens = resp.Usage.TotalTokens
+	}
+	s.record(ctx, req)
+}
+
+// Failure records a failed request. Register it with
+// warp.WithFailureCallback.
+func (s *Store) Failure(ctx context.Context, event *callback.FailureEvent) {
+	req := &Request{
+		RequestID: event.RequestID,
+		CreatedAt: event.EndTime,
+		Tenant:    tenant(event.Request),
+		Provider:  event.Provider,
+		Model:     event.Model,
+		Status:    StatusFailure,
+		Duration:  event.Duration,
+	}
+	if event.Error != nil {
+		req.Error = event.Error.Error()
+	}
+	s.record(ctx, req)
+}
+
+// record inserts req from a callback, reporting errors to the handler.
+func (s *Store) record(ctx context.Context, req *Request) {
+	// Callbacks run after the request, so a cancelled request context must
+	// not prevent the row from being written.
+	if err := s.Record(context.WithoutCancel(ctx), req); err != nil && s.onError != nil {
+		s.onError(err)
+	}
+}
+
+// tenant returns the tenant metadata of a completion request.
+func tenant(request interface{}) string {
+	req, ok := request.(*warp.CompletionRequest)
+	if !ok || req == nil {
+		return ""
+	}
+	t, _ := req.Metadata[policy.MetadataTenant].(string)
+	return t
+}
@@ -0,0 +1,285 @@
+package sqlstore
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/blue-context/warp"
+	"github.com/blue-context/warp/callback"
+)
+
+// fakeDB is a database/sql driver that records statements and answers
+// queries from a handler. It supports just what the store uses.
+type fakeDB struct {
+	mu    sync.Mutex
+	execs []statement
+	query func(query string, args []driver.NamedValue) ([]string, [][]driver.Value, error)
+}
+
+// statement is a recorded statement.
+type statement struct {
+	query string
+	args  []driver.Value
+}
+
+var fakeDBs sync.Map
+
+func init() {
+	sql.Register("sqlstore-fake", fakeDriver{})
+}
+
+type fakeDriver struct{}
+
+func (fakeDriver) Open(name string) (driver.Conn, error) {
+	db, ok := fakeDBs.Load(name)
+	if !ok {
+		return nil, fmt.Errorf("unknown fake database %q", name)
+	}
+	return &fakeConn{db: db.(*fakeDB)}, nil
+}
+
+type fakeConn struct{ db *fakeDB }
+
+func (c *fakeConn) Prepare(query string) (driver.Stmt, error) {
+	return nil, errors.New("prepare not supported")
+}
+func (c *fakeConn) Close() error              { return nil }
+func (c *fakeConn) Begin() (driver.Tx, error) { return fakeTx{}, nil }
+
+func (c *fakeConn) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	c.db.mu.Lock()
+	defer c.db.mu.Unlock()
+	c.db.execs = append(c.db.execs, statement{query: query, args: values(args)})
+	return driver.RowsAffected(1), nil
+}
+
+func (c *fakeConn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	c.db.mu.Lock()
+	handler := c.db.query
+	c.db.execs = append(c.db.execs, statement{query: query, args: values(args)})
+	c.db.mu.Unlock()
+
+	columns, rows, err := handler(query, args)
+	if err != nil {
+		return nil, err
+	}
+	return &fakeRows{columns: columns, rows: rows}, nil
+}
+
+type fakeTx struct{}
+
+func (fakeTx) Commit() error   { return nil }
+func (fakeTx) Rollback() error { return nil }
+
+type fakeRows struct {
+	columns []string
+	rows    [][]driver.Value
+}
+
+func (r *fakeRows) Columns() []string { return r.columns }
+func (r *fakeRows) Close() error      { return nil }
+func (r *fakeRows) Next(dest []driver.Value) error {
+	if len(r.rows) == 0 {
+		return io.EOF
+	}
+	copy(dest, r.rows[0])
+	r.rows = r.rows[1:]
+	return nil
+}
+
+func values(args []driver.NamedValue) []driver.Value {
+	vals := make([]driver.Value, len(args))
+	for i, a := range args {
+		vals[i] = a.Value
+	}
+	return vals
+}
+
+func newFakeStore(t *testing.T, dialect Dialect, opts ...Option) (*Store, *fakeDB) {
+	t.Helper()
+	fake := &fakeDB{query: func(string, []driver.NamedValue) ([]string, [][]driver.Value, error) {
+		return []string{"id"}, [][]driver.Value{{int64(1)}}, nil
+	}}
+	fakeDBs.Store(t.Name(), fake)
+	db, err := sql.Open("sqlstore-fake", t.Name())
+	if err != nil {
+		t.Fatalf("sql.Open() error = %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+	return New(db, dialect, opts...), fake
+}
+
+func TestMigrations(t *testing.T) {
+	for _, dialect := range []Dialect{DialectPostgres, DialectSQLite} {
+		migrations, err := Migrations(dialect)
+		if err != nil {
+			t.Fatalf("Migrations(%s) error = %v", dialect, err)
+		}
+		if len(migrations) < 2 || migrations[0].Version != 1 || migrations[0].Name != "create_requests" {
+			t.Errorf("Migrations(%s) = %+v", dialect, migrations)
+		}
+		for i := 1; i < len(migrations); i++ {
+			if migrations[i].Version <= migrations[i-1].Version {
+				t.Errorf("migrations out of order: %d after %d", migrations[i].Version, migrations[i-1].Version)
+			}
+		}
+	}
+	if _, err := Migrations("oracle"); err == nil {
+		t.Error("Migrations(oracle) error = nil, want unsupported dialect")
+	}
+}
+
+func TestMigrate(t *testing.T) {
+	store, fake := newFakeStore(t, DialectPostgres)
+	fake.query = func(query string, args []driver.NamedValue) ([]string, [][]driver.Value, error) {
+		// Version 1 is already applied
+		return []string{"version"}, [][]driver.Value{{int64(1)}}, nil
+	}
+
+	if err := store.Migrate(context.Background()); err != nil {
+		t.Fatalf("Migrate() error = %v", err)
+	}
+
+	var created, indexes, recorded int
+	for _, stmt := range fake.execs {
+		switch {
+		case strings.HasPrefix(stmt.query, "CREATE TABLE warp_requests"):
+			created++
+		case strings.HasPrefix(stmt.query, "CREATE INDEX"):
+			indexes++
+		case strings.HasPrefix(stmt.query, "INSERT INTO warp_schema_migrations"):
+			recorded++
+			if stmt.args[0] != int64(2) || !strings.Contains(stmt.query, "$1") {
+				t.Errorf("recorded migration %v with %q, want version 2", stmt.args, stmt.query)
+			}
+		}
+	}
+	if created != 0 || indexes != 3 || recorded != 1 {
+		t.Errorf("created=%d indexes=%d recorded=%d, want only migration 2 applied", created, indexes, recorded)
+	}
+}
+
+func TestCallbacksRecordRequests(t *testing.T) {
+	store, fake := newFakeStore(t, DialectSQLite)
+	end := time.Date(2026, 3, 1, 12, 0, 0, 0, time.FixedZone("CET", 3600))
+
+	store.Success(context.Background(), &callback.SuccessEvent{
+		RequestID: "req-1",
+		Provider:  "openai",
+		Model:     "gpt-4o",
+		Request:   &warp.CompletionRequest{Metadata: map[string]any{"tenant": "acme"}},
+		Response:  &warp.CompletionResponse{Usage: &warp.Usage{PromptTokens: 10, CompletionTokens: 5, TotalTokens: 15}},
+		EndTime:   end,
+		Duration:  1500 * time.Millisecond,
+		Cost:      0.25,
+	})
+	store.Failure(context.Background(), &callback.FailureEvent{
+		Provider: "openai",
+		Model:    "gpt-4o",
+		Error:    errors.New("rate limited"),
+	})
+
+	if len(fake.execs) != 2 {
+		t.Fatalf("ran %d statements, want 2 inserts", len(fake.execs))
+	}
+	insert := fake.execs[0]
+	if !strings.HasPrefix(insert.query, "INSERT INTO warp_requests") || strings.Contains(insert.query, "$1") {
+		t.Errorf("query = %q, want SQLite insert", insert.query)
+	}
+	want := []driver.Value{"req-1", end.UTC(), "acme", "openai", "gpt-4o", StatusSuccess, "", int64(1500), int64(10), int64(5), int64(15), 0.25}
+	for i, v := range want {
+		if got := insert.args[i]; got != v {
+			t.Errorf("arg %d = %v, want %v", i, got, v)
+		}
+	}
+	if failure := fake.execs[1].args; failure[5] != StatusFailure || failure[6] != "rate limited" {
+		t.Errorf("failure args = %v", failure)
+	}
+}
+
+func TestRecordErrorHandler(t *testing.T) {
+	var errs []error
+	store, fake := newFakeStore(t, DialectPostgres, WithErrorHandler(func(err error) { errs = append(errs, err) }))
+	fake.query = func(string, []driver.NamedValue) ([]string, [][]driver.Value, error) {
+		return nil, nil, errors.New("connection refused")
+	}
+
+	store.Failure(context.Background(), &callback.FailureEvent{Provider: "openai", Model: "gpt-4o"})
+	if len(errs) != 1 || !strings.Contains(errs[0].Error(), "connection refused") {
+		t.Errorf("errors = %v, want insert failure", errs)
+	}
+}
+
+func TestUsageByModel(t *testing.T) {
+	store, fake := newFakeStore(t, DialectPostgres)
+	var gotQuery string
+	var gotArgs []driver.NamedValue
+	fake.query = func(query string, args []driver.NamedValue) ([]string, [][]driver.Value, error) {
+		gotQuery, gotArgs = query, args
+		return []string{"provider", "model", "n", "errors", "prompt", "completion", "total", "cost", "avg"}, [][]driver.Value{
+			{"openai", "gpt-4o", int64(4), int64(1), int64(100), int64(50), int64(150), 1.5, 250.0},
+			{"anthropic", "claude-sonnet-4", int64(2), int64(0), int64(10), int64(5), int64(15), 0.5, 100.0},
+		}, nil
+	}
+
+	since := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+	usage, err := store.UsageByModel(context.Background(), Filter{Since: since, Tenant: "acme"})
+	if err != nil {
+		t.Fatalf("UsageByModel() error = %v", err)
+	}
+	if !strings.Contains(gotQuery, "WHERE created_at >= $1 AND tenant = $2") || !strings.Contains(gotQuery, "GROUP BY provider, model") {
+		t.Errorf("query = %q", gotQuery)
+	}
+	if len(gotArgs) != 2 || gotArgs[0].Value != since || gotArgs[1].Value != "acme" {
+		t.Errorf("args = %v", gotArgs)
+	}
+
+	if len(usage) != 2 {
+		t.Fatalf("len(usage) = %d, want 2", len(usage))
+	}
+	u := usage[0]
+	if u.Provider != "openai" || u.Requests != 4 || u.TotalTokens != 150 || u.Cost != 1.5 {
+		t.Errorf("usage[0] = %+v", u)
+	}
+	if u.ErrorRate() != 0.25 || u.AvgLatency != 250*time.Millisecond {
+		t.Errorf("ErrorRate = %v, AvgLatency = %v, want 0.25, 250ms", u.ErrorRate(), u.AvgLatency)
+	}
+}
+
+func TestRequests(t *testing.T) {
+	store, fake := newFakeStore(t, DialectSQLite)
+	created := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	var gotQuery string
+	fake.query = func(query string, args []driver.NamedValue) ([]string, [][]driver.Value, error) {
+		gotQuery = query
+		return make([]string, 13), [][]driver.Value{
+			{int64(7), "req-7", created, "", "openai", "gpt-4o", StatusFailure, "timeout", int64(30000), int64(0), int64(0), int64(0), 0.0},
+		}, nil
+	}
+
+	requests, err := store.Requests(context.Background(), Filter{Status: StatusFailure}, 10)
+	if err != nil {
+		t.Fatalf("Requests() error = %v", err)
+	}
+	if !strings.Contains(gotQuery, "WHERE status = ?") || !strings.HasSuffix(gotQuery, "LIMIT ?") {
+		t.Errorf("query = %q", gotQuery)
+	}
+	if len(requests) != 1 || requests[0].ID != 7 || requests[0].Error != "timeout" || requests[0].Duration != 30*time.Second {
+		t.Errorf("Requests() = %+v", requests)
+	}
+}
+
+func TestSplitStatements(t *testing.T) {
+	got := splitStatements("CREATE TABLE a (x INT);\nCREATE INDEX b ON a (x);\n\n")
+	if len(got) != 2 || got[1] != "CREATE INDEX b ON a (x)" {
+		t.Errorf("splitStatements() = %q", got)
+	}
+}
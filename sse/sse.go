@@ -0,0 +1,387 @@
+// Package sse parses Server-Sent Events streams, the text/event-stream
+// format most providers use for streaming responses.
+//
+// The parser follows the WHATWG event stream format: lines end in LF, CR or
+// CRLF; lines starting with a colon are comments; "data" lines of one event
+// are joined with newlines; an empty line dispatches the event; and
+// "event", "id" and "retry" fields set the event type, last event ID and
+// reconnection time. Events without data are not dispatched.
+//
+// Basic usage:
+//
+//	reader := sse.NewReader(resp.Body)
+//	for {
+//	    event, err := reader.Next()
+//	    if err == io.EOF {
+//	        break
+//	    }
+//	    if err != nil {
+//	        return err
+//	    }
+//	    if event.Done() {
+//	        break
+//	    }
+//	    var chunk warp.CompletionChunk
+//	    if err := json.Unmarshal(event.Data, &chunk); err != nil {
+//	        return err
+//	    }
+//	}
+//
+// High-throughput streams can avoid most per-event allocations: with
+// WithBufferReuse, Next reuses the event and its data buffer, Decode
+// unmarshals the current event with a decoder kept for the stream, and
+// Release returns the read buffers to a shared pool when the stream is
+// done:
+//
+//	reader := sse.NewReader(resp.Body, sse.WithBufferReuse())
+//	defer reader.Release()
+//	for {
+//	    event, err := reader.Next()
+//	    ...
+//	    var chunk warp.CompletionChunk
+//	    if err := reader.Decode(&chunk); err != nil {
+//	        return err
+//	    }
+//	}
+//
+// Decode uses the codec installed with jsoncodec.Set when the reader was
+// created, so a faster JSON implementation speeds up every provider
+// stream.
+package sse
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/blue-context/warp/jsoncodec"
+)
+
+// DefaultMaxEventSize is the default limit on the size of one event.
+const DefaultMaxEventSize = 16 << 20
+
+// maxPooledBuffer is the largest line or data buffer returned to the pool,
+// so one huge event does not pin its memory for the life of the process.
+const maxPooledBuffer = 64 << 10
+
+// errReleased is returned by a Reader used after Release.
+var errReleased = errors.New("sse: reader used after Release")
+
+// buffers are the per-stream buffers shared through bufferPool.
+type buffers struct {
+	r    *bufio.Reader
+	line []byte
+	data []byte
+
+	// src feeds event data to dec; fed is the number of bytes fed so far,
+	// which locates each event in the decoder's input offsets.
+	src *bytes.Reader
+	dec *json.Decoder
+	fed int64
+}
+
+var bufferPool = sync.Pool{
+	New: func() any {
+		return &buffers{r: bufio.NewReader(nil)}
+	},
+}
+
+// Event is a dispatched event.
+type Event struct {
+	// Type is the "event" field, or empty for the default "message" type.
+	Type string
+
+	// Data is the "data" lines of the event joined with newlines.
+	Data []byte
+
+	// ID is the last event ID seen on the stream, which carries over to
+	// later events that do not set one.
+	ID string
+
+	// Retry is the reconnection time from a "retry" field in this event,
+	// or 0 if it had none.
+	Retry time.Duration
+}
+
+// Done reports whether the event is the "[DONE]" marker that
+// OpenAI-compatible APIs send to end a stream.
+func (e *Event) Done() bool {
+	return string(e.Data) == "[DONE]"
+}
+
+// Reader reads events from an event stream.
+//
+// Thread Safety: Reader is NOT safe for concurrent use, except that Release
+// may be called while Next is blocked in another goroutine once the
+// underlying reader has been closed.
+type Reader struct {
+	mu      sync.Mutex
+	buf     *buffers
+	event   Event
+	current *Event
+	skipLF  bool
+	lastID  string
+	maxSize int
+	reuse   bool
+	codec   jsoncodec.Codec
+}
+
+// Option configures a Reader.
+type Option func(*Reader)
+
+// WithMaxEventSize limits the size of one event (its lines, including
+// field names). Next fails on larger events rather than buffering an
+// unbounded stream. The default is DefaultMaxEventSize.
+func WithMaxEventSize(n int) Option {
+	return func(r *Reader) {
+		r.maxSize = n
+	}
+}
+
+// WithBufferReuse makes Next reuse the returned Event and its Data for
+// the next event, so an event is only valid until the next call. Callers
+// that decode each event before reading the next one, as provider streams
+// do, save an allocation per event.
+func WithBufferReuse() Option {
+	return func(r *Reader) {
+		r.reuse = true
+	}
+}
+
+// NewReader creates a reader of the event stream r. Its buffers come from
+// a pool shared by all readers; call Release when done to return them.
+func NewReader(r io.Reader, opts ...Option) *Reader {
+	buf := bufferPool.Get().(*buffers)
+	buf.r.Reset(r)
+	reader := &Reader{
+		buf:     buf,
+		maxSize: DefaultMaxEventSize,
+		codec:   jsoncodec.Get(),
+	}
+	for _, opt := range opts {
+		opt(reader)
+	}
+	return reader
+}
+
+// Release returns the reader's buffers to the pool. The reader must not
+// be used afterwards: Next and Decode return an error. Release waits for a
+// Next in progress, so close the underlying reader first to unblock it.
+// Calling Release more than once, or on a nil Reader, is a no-op.
+func (r *Reader) Release() {
+	if r == nil {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	buf := r.buf
+	if buf == nil {
+		return
+	}
+	r.buf = nil
+	r.current = nil
+	r.event = Event{}
+
+	buf.r.Reset(nil)
+	if cap(buf.line) > maxPooledBuffer {
+		buf.line = nil
+	}
+	if cap(buf.data) > maxPooledBuffer {
+		buf.data = nil
+	}
+	// A decoder whose input ended mid-value holds that value in its
+	// buffer; start the next stream with a fresh one
+	if buf.dec != nil && buf.dec.InputOffset() != buf.fed {
+		buf.src, buf.dec, buf.fed = nil, nil, 0
+	}
+	bufferPool.Put(buf)
+}
+
+// Decode unmarshals the data of the event last returned by Next into v,
+// like json.Unmarshal, using a decoder kept for the stream, or with the
+// installed jsoncodec if it is not the standard one. Data holding anything
+// but one JSON value is an error.
+func (r *Reader) Decode(v any) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.buf == nil {
+		return errReleased
+	}
+	if r.current == nil {
+		return errors.New("sse: no event to decode")
+	}
+	buf := r.buf
+	data := r.current.Data
+	if r.codec != jsoncodec.Std {
+		return r.codec.Unmarshal(data, v)
+	}
+
+	if buf.dec == nil {
+		buf.src = bytes.NewReader(nil)
+		buf.dec = json.NewDecoder(buf.src)
+		buf.fed = 0
+	}
+	start := buf.fed
+	buf.src.Reset(data)
+	buf.fed += int64(len(data))
+
+	err := buf.dec.Decode(v)
+	if err == nil {
+		// The decoder stops after the first value; the rest of the data
+		// must be whitespace, as json.Unmarshal requires
+		rest := bytes.TrimLeft(data[buf.dec.InputOffset()-start:], " \t\r\n")
+		if len(rest) > 0 {
+			err = fmt.Errorf("sse: invalid character %q after top-level value", rest[0])
+		}
+	}
+	if err != nil {
+		// Decoder errors are sticky; start over with a fresh one
+		buf.src, buf.dec, buf.fed = nil, nil, 0
+		if err == io.EOF {
+			err = io.ErrUnexpectedEOF
+		}
+		return err
+	}
+	return nil
+}
+
+// Next returns the next event. It returns io.EOF at the end of the stream.
+//
+// Unlike browsers, Next dispatches a final event that is not followed by an
+// empty line, since servers often close the connection right after the
+// last data line. An unexpected end inside an event is otherwise not an
+// error.
+func (r *Reader) Next() (*Event, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.buf == nil {
+		return nil, errReleased
+	}
+	r.current = nil
+
+	event := &r.event
+	var data []byte
+	if r.reuse {
+		*event = Event{}
+		data = r.buf.data[:0]
+	} else {
+		event = &Event{}
+	}
+	hasData := false
+	size := 0
+
+	dispatch := func() (*Event, error) {
+		event.Data = data
+		event.ID = r.lastID
+		if r.reuse {
+			r.buf.data = data
+		}
+		r.current = event
+		return event, nil
+	}
+
+	for {
+		line, err := r.readLine()
+		if err != nil {
+			if err == io.EOF && hasData {
+				return dispatch()
+			}
+			return nil, err
+		}
+
+		size += len(line)
+		if r.maxSize > 0 && size > r.maxSize {
+			return nil, fmt.Errorf("sse: event exceeds %d bytes", r.maxSize)
+		}
+
+		// An empty line dispatches the event
+		if len(line) == 0 {
+			if !hasData {
+				*event = Event{}
+				size = 0
+				continue
+			}
+			return dispatch()
+		}
+
+		// Comment
+		if line[0] == ':' {
+			continue
+		}
+
+		field, value := line, []byte(nil)
+		if i := bytes.IndexByte(line, ':'); i >= 0 {
+			field, value = line[:i], line[i+1:]
+			if len(value) > 0 && value[0] == ' ' {
+				value = value[1:]
+			}
+		}
+
+		switch string(field) {
+		case "event":
+			event.Type = string(value)
+		case "data":
+			if hasData {
+				data = append(data, '\n')
+			}
+			data = append(data, value...)
+			hasData = true
+		case "id":
+			// IDs containing NUL are ignored, per the specification
+			if bytes.IndexByte(value, 0) < 0 {
+				r.lastID = string(value)
+			}
+		case "retry":
+			if ms, err := strconv.ParseUint(string(value), 10, 63); err == nil {
+				event.Retry = time.Duration(ms) * time.Millisecond
+			}
+		}
+		// Other fields are ignored
+	}
+}
+
+// readLine reads a line ending in LF, CR or CRLF, without the terminator.
+// The returned slice is valid until the next call.
+func (r *Reader) readLine() ([]byte, error) {
+	buf := r.buf
+	buf.line = buf.line[:0]
+	for {
+		b, err := buf.r.ReadByte()
+		if err != nil {
+			if err == io.EOF && len(buf.line) > 0 {
+				return buf.line, nil
+			}
+			return nil, err
+		}
+
+		// Return at a CR without waiting for the next byte, and drop the
+		// LF of a CRLF on the next read
+		if r.skipLF {
+			r.skipLF = false
+			if b == '\n' {
+				continue
+			}
+		}
+		switch b {
+		case '\n':
+			return buf.line, nil
+		case '\r':
+			r.skipLF = true
+			return buf.line, nil
+		}
+
+		buf.line = append(buf.line, b)
+		if r.maxSize > 0 && len(buf.line) > r.maxSize {
+			return nil, fmt.Errorf("sse: event exceeds %d bytes", r.maxSize)
+		}
+	}
+}
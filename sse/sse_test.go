@@ -0,0 +1,455 @@
+package sse
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+	"testing/iotest"
+	"time"
+
+	"github.com/blue-context/warp/jsoncodec"
+)
+
+// readAll reads every event from r.
+func readAll(r io.Reader, opts ...Option) ([]Event, error) {
+	reader := NewReader(r, opts...)
+	var events []Event
+	for {
+		event, err := reader.Next()
+		if err == io.EOF {
+			return events, nil
+		}
+		if err != nil {
+			return events, err
+		}
+		events = append(events, *event)
+	}
+}
+
+func TestReader(t *testing.T) {
+	tests := []struct {
+		name   string
+		stream string
+		want   []Event
+	}{
+		{
+			name:   "data lines",
+			stream: "data: {\"a\":1}\n\ndata: [DONE]\n\n",
+			want:   []Event{{Data: []byte(`{"a":1}`)}, {Data: []byte("[DONE]")}},
+		},
+		{
+			name:   "multi-line data",
+			stream: "data: first\ndata:second\ndata:  third\n\n",
+			want:   []Event{{Data: []byte("first\nsecond\n third")}},
+		},
+		{
+			name:   "CRLF and CR line endings",
+			stream: "event: delta\r\ndata: a\r\n\r\ndata: b\r\rdata: c\n\n",
+			want:   []Event{{Type: "delta", Data: []byte("a")}, {Data: []byte("b")}, {Data: []byte("c")}},
+		},
+		{
+			name:   "comments and unknown fields",
+			stream: ": keep-alive\n\nfoo: bar\ndata: x\n: inline\n\n",
+			want:   []Event{{Data: []byte("x")}},
+		},
+		{
+			name:   "event without data is not dispatched",
+			stream: "event: ping\n\nevent: message_stop\ndata: {}\n\n",
+			want:   []Event{{Type: "message_stop", Data: []byte("{}")}},
+		},
+		{
+			name:   "empty data field",
+			stream: "data\n\ndata:\n\n",
+			want:   []Event{{Data: []byte{}}, {Data: []byte{}}},
+		},
+		{
+			name:   "id carries over and retry",
+			stream: "id: 7\nretry: 1500\ndata: a\n\nretry: soon\ndata: b\n\nid\ndata: c\n\n",
+			want: []Event{
+				{ID: "7", Retry: 1500 * time.Millisecond, Data: []byte("a")},
+				{ID: "7", Data: []byte("b")},
+				{ID: "", Data: []byte("c")},
+			},
+		},
+		{
+			name:   "final event without blank line",
+			stream: "data: a\n\ndata: b",
+			want:   []Event{{Data: []byte("a")}, {Data: []byte("b")}},
+		},
+		{
+			name:   "empty stream",
+			stream: "",
+			want:   nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for _, r := range []io.Reader{
+				strings.NewReader(tt.stream),
+				iotest.OneByteReader(strings.NewReader(tt.stream)),
+				iotest.HalfReader(strings.NewReader(tt.stream)),
+			} {
+				got, err := readAll(r)
+				if err != nil {
+					t.Fatalf("Next() error = %v", err)
+				}
+				if !eventsEqual(got, tt.want) {
+					t.Errorf("events = %q, want %q", got, tt.want)
+				}
+			}
+		})
+	}
+}
+
+func TestReaderDone(t *testing.T) {
+	event := Event{Data: []byte("[DONE]")}
+	if !event.Done() {
+		t.Error("Done() = false for [DONE]")
+	}
+	event.Data = []byte(`{"done":true}`)
+	if event.Done() {
+		t.Error("Done() = true for JSON data")
+	}
+}
+
+func TestReaderMaxEventSize(t *testing.T) {
+	stream := "data: " + strings.Repeat("x", 100) + "\n\n"
+	if _, err := readAll(strings.NewReader(stream), WithMaxEventSize(50)); err == nil {
+		t.Error("Next() error = nil, want event size error")
+	}
+
+	stream = strings.Repeat("data: xxxxxxxxxx\n", 10) + "\n"
+	if _, err := readAll(strings.NewReader(stream), WithMaxEventSize(50)); err == nil {
+		t.Error("Next() error = nil for many lines, want event size error")
+	}
+
+	stream = strings.Repeat("data: xxxxxxxxxx\n\n", 10)
+	events, err := readAll(strings.NewReader(stream), WithMaxEventSize(50))
+	if err != nil || len(events) != 10 {
+		t.Errorf("got %d events, error %v, want 10 small events", len(events), err)
+	}
+}
+
+func TestReaderReadError(t *testing.T) {
+	errRead := errors.New("connection reset")
+	r := io.MultiReader(strings.NewReader("data: a\n\ndata: b\n"), iotest.ErrReader(errRead))
+
+	reader := NewReader(r)
+	if event, err := reader.Next(); err != nil || string(event.Data) != "a" {
+		t.Fatalf("Next() = %v, %v, want event a", event, err)
+	}
+	if _, err := reader.Next(); !errors.Is(err, errRead) {
+		t.Errorf("Next() error = %v, want read error", err)
+	}
+}
+
+func TestReaderBufferReuse(t *testing.T) {
+	stream := "event: a\ndata: first\n\nid: 2\ndata: second event\n\ndata: 3"
+
+	reader := NewReader(strings.NewReader(stream), WithBufferReuse())
+	defer reader.Release()
+	var events []Event
+	var first *Event
+	for {
+		event, err := reader.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatalf("Next() error = %v", err)
+		}
+		if first == nil {
+			first = event
+		} else if event != first {
+			t.Error("Next() returned a new event with buffer reuse")
+		}
+		copied := *event
+		copied.Data = bytes.Clone(event.Data)
+		events = append(events, copied)
+	}
+
+	want, err := readAll(strings.NewReader(stream))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !eventsEqual(events, want) {
+		t.Errorf("events = %q, want %q", events, want)
+	}
+}
+
+func TestReaderDecode(t *testing.T) {
+	stream := "data: {\"n\":1}\n\n" +
+		"data: 2\n\n" +
+		"data: {\"n\":\ndata: 3}\n\n" +
+		"data: {\"n\":\n\n" +
+		"data: {\"n\":4} {\"n\":5}\n\n" +
+		"data: {\"n\":6}  \n\n"
+
+	reader := NewReader(strings.NewReader(stream), WithBufferReuse())
+	defer reader.Release()
+	next := func() {
+		t.Helper()
+		if _, err := reader.Next(); err != nil {
+			t.Fatalf("Next() error = %v", err)
+		}
+	}
+	type value struct{ N int }
+
+	var v value
+	next()
+	if err := reader.Decode(&v); err != nil || v.N != 1 {
+		t.Errorf("Decode() = %+v, %v, want n 1", v, err)
+	}
+	var n int
+	next()
+	if err := reader.Decode(&n); err != nil || n != 2 {
+		t.Errorf("Decode() = %d, %v, want 2", n, err)
+	}
+	next()
+	if err := reader.Decode(&v); err != nil || v.N != 3 {
+		t.Errorf("Decode() of multi-line data = %+v, %v, want n 3", v, err)
+	}
+	next()
+	if err := reader.Decode(&v); err == nil {
+		t.Error("Decode() of truncated JSON error = nil")
+	}
+	next()
+	if err := reader.Decode(&v); err == nil {
+		t.Error("Decode() of two values error = nil")
+	}
+	next()
+	v = value{}
+	if err := reader.Decode(&v); err != nil || v.N != 6 {
+		t.Errorf("Decode() after errors = %+v, %v, want n 6", v, err)
+	}
+}
+
+func TestReaderDecodeMatchesUnmarshal(t *testing.T) {
+	for _, data := range []string{`{"a":[1,2]}`, `"s"`, `null`, `{"a":}`, `[1,`, `1 2`, ``, `  `} {
+		var want, got any
+		wantErr := json.Unmarshal([]byte(data), &want)
+
+		reader := NewReader(strings.NewReader("data: " + data + "\n\n"))
+		if _, err := reader.Next(); err != nil {
+			t.Fatalf("Next() error = %v", err)
+		}
+		gotErr := reader.Decode(&got)
+		reader.Release()
+
+		if (gotErr != nil) != (wantErr != nil) || (wantErr == nil && !reflect.DeepEqual(got, want)) {
+			t.Errorf("Decode(%q) = %v, %v, want %v, %v", data, got, gotErr, want, wantErr)
+		}
+	}
+}
+
+// funcCodec is a codec decoding with a function.
+type funcCodec func(data []byte, v any) error
+
+func (f funcCodec) Marshal(v any) ([]byte, error)      { return json.Marshal(v) }
+func (f funcCodec) Unmarshal(data []byte, v any) error { return f(data, v) }
+
+func TestReaderDecodeCodec(t *testing.T) {
+	var decoded []string
+	jsoncodec.Set(funcCodec(func(data []byte, v any) error {
+		decoded = append(decoded, string(data))
+		return json.Unmarshal(data, v)
+	}))
+	reader := NewReader(strings.NewReader("data: {\"n\":1}\n\ndata: {\"n\":2}\n\n"), WithBufferReuse())
+	defer reader.Release()
+	// The reader keeps the codec it was created with
+	jsoncodec.Set(nil)
+
+	var v struct{ N int }
+	for want := 1; want <= 2; want++ {
+		if _, err := reader.Next(); err != nil {
+			t.Fatalf("Next() error = %v", err)
+		}
+		if err := reader.Decode(&v); err != nil || v.N != want {
+			t.Errorf("Decode() = %+v, %v, want n %d", v, err, want)
+		}
+	}
+	if !reflect.DeepEqual(decoded, []string{`{"n":1}`, `{"n":2}`}) {
+		t.Errorf("codec decoded %q", decoded)
+	}
+}
+
+func TestReaderRelease(t *testing.T) {
+	reader := NewReader(strings.NewReader("data: {}\n\ndata: {}\n\n"), WithBufferReuse())
+	if _, err := reader.Next(); err != nil {
+		t.Fatal(err)
+	}
+	reader.Release()
+	reader.Release()
+
+	if _, err := reader.Next(); err == nil {
+		t.Error("Next() after Release error = nil")
+	}
+	if err := reader.Decode(new(any)); err == nil {
+		t.Error("Decode() after Release error = nil")
+	}
+
+	var nilReader *Reader
+	nilReader.Release()
+}
+
+func TestReaderReleaseDuringNext(t *testing.T) {
+	pr, pw := io.Pipe()
+	reader := NewReader(pr)
+
+	done := make(chan error, 1)
+	go func() {
+		_, err := reader.Next()
+		done <- err
+	}()
+
+	pw.CloseWithError(errors.New("closed"))
+	reader.Release()
+	if err := <-done; err == nil {
+		t.Error("Next() error = nil after close")
+	}
+}
+
+func BenchmarkReaderDecode(b *testing.B) {
+	chunk := `{"id":"chatcmpl-1","object":"chat.completion.chunk","model":"gpt-4o",` +
+		`"choices":[{"index":0,"delta":{"content":"Hello"},"finish_reason":null}]}`
+	stream := strings.Repeat("data: "+chunk+"\n\n", 100)
+	type chunkType struct {
+		ID      string `json:"id"`
+		Choices []struct {
+			Delta struct {
+				Content string `json:"content"`
+			} `json:"delta"`
+		} `json:"choices"`
+	}
+
+	b.Run("Unmarshal", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			reader := NewReader(strings.NewReader(stream))
+			for {
+				event, err := reader.Next()
+				if err != nil {
+					break
+				}
+				var c chunkType
+				if err := json.Unmarshal(event.Data, &c); err != nil {
+					b.Fatal(err)
+				}
+			}
+		}
+	})
+	b.Run("Decode", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			reader := NewReader(strings.NewReader(stream), WithBufferReuse())
+			for {
+				if _, err := reader.Next(); err != nil {
+					break
+				}
+				var c chunkType
+				if err := reader.Decode(&c); err != nil {
+					b.Fatal(err)
+				}
+			}
+			reader.Release()
+		}
+	})
+}
+
+// eventsEqual compares events, treating nil and empty data as equal.
+func eventsEqual(got, want []Event) bool {
+	if len(got) != len(want) {
+		return false
+	}
+	for i := range got {
+		g, w := got[i], want[i]
+		if !bytes.Equal(g.Data, w.Data) {
+			return false
+		}
+		g.Data, w.Data = nil, nil
+		if !reflect.DeepEqual(g, w) {
+			return false
+		}
+	}
+	return true
+}
+
+func FuzzReader(f *testing.F) {
+	f.Add("data: {\"a\":1}\n\ndata: [DONE]\n\n")
+	f.Add("event: delta\r\ndata: a\r\ndata: b\r\n\r\n")
+	f.Add(": comment\rid: 1\rretry: 10\rdata\r\r")
+	f.Add("data: no terminator")
+
+	f.Fuzz(func(t *testing.T, stream string) {
+		whole, err := readAll(strings.NewReader(stream))
+		if err != nil {
+			t.Fatalf("Next() error = %v", err)
+		}
+		split, err := readAll(iotest.OneByteReader(strings.NewReader(stream)))
+		if err != nil {
+			t.Fatalf("Next() with one-byte reads error = %v", err)
+		}
+		if !eventsEqual(whole, split) {
+			t.Fatalf("events differ with partial reads: %q vs %q", whole, split)
+		}
+
+		for _, event := range whole {
+			if bytes.ContainsAny(event.Data, "\r") || strings.ContainsAny(event.Type, "\r\n") || strings.ContainsAny(event.ID, "\r\n\x00") {
+				t.Fatalf("event %q contains a line terminator", event)
+			}
+		}
+	})
+}
+
+// BenchmarkReaderDecodeCodec shows how much of the cost of reading a stream
+// is JSON decoding, the part a faster jsoncodec speeds up: "Std" decodes
+// each event with encoding/json and "Framing" with a codec that does
+// nothing, the floor a faster codec approaches. To measure a codec, add a
+// case installing it.
+func BenchmarkReaderDecodeCodec(b *testing.B) {
+	chunk := `{"id":"chatcmpl-1","object":"chat.completion.chunk","model":"gpt-4o",` +
+		`"choices":[{"index":0,"delta":{"content":"Hello"},"finish_reason":null}]}`
+	stream := strings.Repeat("data: "+chunk+"\n\n", 100)
+	type chunkType struct {
+		ID      string `json:"id"`
+		Choices []struct {
+			Delta struct {
+				Content string `json:"content"`
+			} `json:"delta"`
+		} `json:"choices"`
+	}
+
+	codecs := []struct {
+		name  string
+		codec jsoncodec.Codec
+	}{
+		{"Std", jsoncodec.Std},
+		{"Framing", funcCodec(func([]byte, any) error { return nil })},
+	}
+	for _, tc := range codecs {
+		b.Run(tc.name, func(b *testing.B) {
+			jsoncodec.Set(tc.codec)
+			defer jsoncodec.Set(nil)
+			b.ReportAllocs()
+			b.SetBytes(int64(len(stream)))
+			for i := 0; i < b.N; i++ {
+				reader := NewReader(strings.NewReader(stream), WithBufferReuse())
+				for {
+					if _, err := reader.Next(); err != nil {
+						break
+					}
+					var c chunkType
+					if err := reader.Decode(&c); err != nil {
+						b.Fatal(err)
+					}
+				}
+				reader.Release()
+			}
+		})
+	}
+}
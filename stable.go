@@ -0,0 +1,160 @@
+package warp
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+)
+
+// ResponseSchemaVersion is the version of the format written by
+// MarshalStable.
+//
+// Version history:
+//   - 0: the plain JSON encoding of CompletionResponse, unversioned
+//   - 1: canonical JSON with schema_version, request_id and
+//     provider_request_id; _hidden_params are not stored
+const ResponseSchemaVersion = 1
+
+// responseUpgrades[v] upgrades a decoded response from version v to v+1.
+var responseUpgrades = []func(doc map[string]any) error{
+	upgradeResponseV0,
+}
+
+// MarshalStable encodes the response for long-term storage: canonical
+// JSON with object keys in sorted order, no HTML escaping, and a
+// schema_version field, so equal responses encode to equal bytes and
+// stored responses can be read by later versions with UnmarshalResponse.
+//
+// The request IDs are included; HiddenParams, which hold internal
+// debugging state, are not.
+//
+// Example:
+//
+//	data, err := resp.MarshalStable()
+//	if err != nil {
+//	    return err
+//	}
+//	err = store.Put(ctx, resp.RequestID, data)
+func (r *CompletionResponse) MarshalStable() ([]byte, error) {
+	if r == nil {
+		return nil, fmt.Errorf("response cannot be nil")
+	}
+	data, err := json.Marshal(r)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode response: %w", err)
+	}
+	doc, err := decodeDocument(data)
+	if err != nil {
+		return nil, err
+	}
+
+	delete(doc, "_hidden_params")
+	doc["schema_version"] = ResponseSchemaVersion
+	if r.RequestID != "" {
+		doc["request_id"] = r.RequestID
+	}
+	if r.ProviderRequestID != "" {
+		doc["provider_request_id"] = r.ProviderRequestID
+	}
+
+	// Maps encode with sorted keys
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(doc); err != nil {
+		return nil, fmt.Errorf("failed to encode response: %w", err)
+	}
+	return bytes.TrimSuffix(buf.Bytes(), []byte("\n")), nil
+}
+
+// UnmarshalResponse decodes a response written by MarshalStable, upgrading
+// it from older schema versions. Unversioned data is read as the plain
+// JSON encoding of a CompletionResponse.
+//
+// Returns an error if the data is not a JSON object or was written by a
+// newer version than ResponseSchemaVersion.
+func UnmarshalResponse(data []byte) (*CompletionResponse, error) {
+	doc, err := decodeDocument(data)
+	if err != nil {
+		return nil, err
+	}
+
+	version := 0
+	if v, ok := doc["schema_version"]; ok {
+		n, ok := v.(json.Number)
+		if !ok {
+			return nil, fmt.Errorf("invalid schema_version %v", v)
+		}
+		parsed, err := n.Int64()
+		if err != nil || parsed < 0 {
+			return nil, fmt.Errorf("invalid schema_version %s", n)
+		}
+		version = int(parsed)
+	}
+	if version > ResponseSchemaVersion {
+		return nil, fmt.Errorf("response schema version %d is newer than supported version %d", version, ResponseSchemaVersion)
+	}
+	for v := version; v < ResponseSchemaVersion; v++ {
+		if err := responseUpgrades[v](doc); err != nil {
+			return nil, fmt.Errorf("failed to upgrade response from schema version %d: %w", v, err)
+		}
+	}
+
+	requestID, _ := doc["request_id"].(string)
+	providerRequestID, _ := doc["provider_request_id"].(string)
+	delete(doc, "schema_version")
+	delete(doc, "request_id")
+	delete(doc, "provider_request_id")
+
+	upgraded, err := json.Marshal(doc)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode response: %w", err)
+	}
+	var resp CompletionResponse
+	if err := json.Unmarshal(upgraded, &resp); err != nil {
+		return nil, fmt.Errorf("failed to decode response: %w", err)
+	}
+	resp.RequestID = requestID
+	resp.ProviderRequestID = providerRequestID
+	for i := range resp.Choices {
+		msg := &resp.Choices[i].Message
+		if parts, ok := msg.Content.([]any); ok {
+			msg.Content = decodeContentParts(parts)
+		}
+	}
+	return &resp, nil
+}
+
+// upgradeResponseV0 upgrades the plain encoding: hidden params are dropped.
+func upgradeResponseV0(doc map[string]any) error {
+	delete(doc, "_hidden_params")
+	return nil
+}
+
+// decodeDocument decodes a JSON object, keeping numbers as written.
+func decodeDocument(data []byte) (map[string]any, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	var doc map[string]any
+	if err := dec.Decode(&doc); err != nil {
+		return nil, fmt.Errorf("failed to decode response: %w", err)
+	}
+	if doc == nil {
+		return nil, fmt.Errorf("failed to decode response: not an object")
+	}
+	return doc, nil
+}
+
+// decodeContentParts converts multimodal content decoded as []any to
+// []ContentPart, returning it unchanged if it does not decode.
+func decodeContentParts(content []any) any {
+	data, err := json.Marshal(content)
+	if err != nil {
+		return content
+	}
+	var parts []ContentPart
+	if err := json.Unmarshal(data, &parts); err != nil {
+		return content
+	}
+	return parts
+}
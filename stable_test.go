@@ -0,0 +1,80 @@
+package warp
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func stableTestResponse() *CompletionResponse {
+	return &CompletionResponse{
+		ID:      "chatcmpl-1",
+		Object:  "chat.completion",
+		Created: 1700000000,
+		Model:   "gpt-4o",
+		Choices: []Choice{{
+			Message:      Message{Role: "assistant", Content: []ContentPart{{Type: "text", Text: "a < b & c"}}},
+			FinishReason: "stop",
+		}},
+		Usage:             &Usage{PromptTokens: 3, CompletionTokens: 5, TotalTokens: 8},
+		ProviderFields:    map[string]any{"z": 1, "a": 0.1},
+		HiddenParams:      map[string]any{"_attempts": 2},
+		RequestID:         "req_1",
+		ProviderRequestID: "prov_1",
+	}
+}
+
+func TestMarshalStable(t *testing.T) {
+	data, err := stableTestResponse().MarshalStable()
+	if err != nil {
+		t.Fatalf("MarshalStable() error = %v", err)
+	}
+	text := string(data)
+	if !strings.Contains(text, `"schema_version":1`) || !strings.Contains(text, `"request_id":"req_1"`) {
+		t.Errorf("MarshalStable() = %s, want schema_version and request_id", text)
+	}
+	if strings.Contains(text, "_hidden_params") || !strings.Contains(text, "a < b & c") {
+		t.Errorf("MarshalStable() = %s, want no hidden params and no HTML escaping", text)
+	}
+	if !strings.Contains(text, `"provider_specific_fields":{"a":0.1,"z":1}`) {
+		t.Errorf("MarshalStable() = %s, want sorted keys", text)
+	}
+
+	again, err := stableTestResponse().MarshalStable()
+	if err != nil || string(again) != text {
+		t.Errorf("MarshalStable() is not deterministic:\n%s\n%s", text, again)
+	}
+
+	got, err := UnmarshalResponse(data)
+	if err != nil {
+		t.Fatalf("UnmarshalResponse() error = %v", err)
+	}
+	want := stableTestResponse()
+	want.HiddenParams = nil
+	want.ProviderFields = map[string]any{"z": 1.0, "a": 0.1}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("UnmarshalResponse() = %+v\nwant %+v", got, want)
+	}
+}
+
+func TestUnmarshalResponse_Versions(t *testing.T) {
+	// Unversioned data is the plain encoding
+	plain, err := json.Marshal(stableTestResponse())
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := UnmarshalResponse(plain)
+	if err != nil {
+		t.Fatalf("UnmarshalResponse() of version 0 error = %v", err)
+	}
+	if got.ID != "chatcmpl-1" || got.HiddenParams != nil || got.Usage.TotalTokens != 8 {
+		t.Errorf("UnmarshalResponse() of version 0 = %+v", got)
+	}
+
+	for _, data := range []string{`{"schema_version": 2}`, `{"schema_version": "1"}`, `[]`, `null`} {
+		if _, err := UnmarshalResponse([]byte(data)); err == nil {
+			t.Errorf("UnmarshalResponse(%s) error = nil", data)
+		}
+	}
+}
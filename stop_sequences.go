@@ -0,0 +1,94 @@
+package warp
+
+import (
+	"context"
+	"fmt"
+)
+
+// StopSequenceLimiter is implemented by providers that limit the number of
+// stop sequences a request may set. Requests to other providers are sent
+// with all their stop sequences.
+type StopSequenceLimiter interface {
+	// MaxStopSequences returns the number of stop sequences model accepts:
+	// 0 if it accepts none, or a negative number if it has no limit. model
+	// has no provider prefix.
+	MaxStopSequences(model string) int
+}
+
+// StopSequencePolicy controls how requests with more stop sequences than
+// the provider accepts are adapted before dispatch.
+type StopSequencePolicy string
+
+const (
+	// StopSequencesTruncate keeps as many stop sequences as the provider
+	// accepts, in order, and drops the rest (default).
+	StopSequencesTruncate StopSequencePolicy = ""
+
+	// StopSequencesError rejects the request with an *InvalidRequestError.
+	StopSequencesError StopSequencePolicy = "error"
+)
+
+// WarningStopSequencesTruncated is the callback.WarningEvent code raised
+// when stop sequences the provider does not accept are dropped.
+const WarningStopSequencesTruncated = "stop_sequences_truncated"
+
+// adaptStopSequences returns req with its stop sequences adapted to the
+// limit of p for model according to the client's StopSequencePolicy.
+// Empty and repeated sequences, which providers reject, are always
+// dropped. Requests that need no change are returned unchanged.
+func (c *client) adaptStopSequences(ctx context.Context, p Provider, req *CompletionRequest, providerName, modelName string) (*CompletionRequest, error) {
+	if len(req.Stop) == 0 {
+		return req, nil
+	}
+
+	stop := uniqueStopSequences(req.Stop)
+	limit := -1
+	if limiter, ok := p.(StopSequenceLimiter); ok {
+		limit = limiter.MaxStopSequences(modelName)
+	}
+	if limit >= 0 && len(stop) > limit {
+		if c.config.StopSequencePolicy == StopSequencesError {
+			message := fmt.Sprintf("%s/%s accepts at most %d stop sequences, got %d", providerName, modelName, limit, len(stop))
+			if limit == 0 {
+				message = fmt.Sprintf("%s/%s does not accept stop sequences", providerName, modelName)
+			}
+			return nil, NewInvalidRequestError(message, providerName, nil)
+		}
+		c.warn(ctx, WarningStopSequencesTruncated, fmt.Sprintf("%s/%s accepts at most %d stop sequences; dropped %q",
+			providerName, modelName, limit, stop[limit:]))
+		stop = stop[:limit]
+	}
+	if len(stop) == len(req.Stop) {
+		return req, nil
+	}
+
+	r := *req
+	r.Stop = stop
+	if len(stop) == 0 {
+		r.Stop = nil
+	}
+	return &r, nil
+}
+
+// uniqueStopSequences returns stop without empty and repeated sequences,
+// or stop itself if it has none.
+func uniqueStopSequences(stop []string) []string {
+	seen := make(map[string]bool, len(stop))
+	var out []string
+	for i, s := range stop {
+		if s != "" && !seen[s] {
+			seen[s] = true
+			if out != nil {
+				out = append(out, s)
+			}
+			continue
+		}
+		if out == nil {
+			out = append(make([]string, 0, len(stop)-1), stop[:i]...)
+		}
+	}
+	if out == nil {
+		return stop
+	}
+	return out
+}
@@ -0,0 +1,110 @@
+package warp
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/blue-context/warp/callback"
+)
+
+// stopLimitProvider is a mockProvider that limits stop sequences.
+type stopLimitProvider struct {
+	mockProvider
+	max int
+}
+
+func (p *stopLimitProvider) MaxStopSequences(model string) int {
+	return p.max
+}
+
+func TestStopSequences(t *testing.T) {
+	stop := []string{"a", "b", "", "a", "c", "d", "e"}
+
+	tests := []struct {
+		name        string
+		model       string
+		policy      StopSequencePolicy
+		want        []string
+		wantWarning bool
+		wantErr     bool
+	}{
+		{"no limit", "plain/m", StopSequencesTruncate, []string{"a", "b", "c", "d", "e"}, false, false},
+		{"within limit", "five/m", StopSequencesTruncate, []string{"a", "b", "c", "d", "e"}, false, false},
+		{"truncated", "four/m", StopSequencesTruncate, []string{"a", "b", "c", "d"}, true, false},
+		{"none accepted", "none/m", StopSequencesTruncate, nil, true, false},
+		{"error", "four/m", StopSequencesError, nil, false, true},
+		{"error within limit", "five/m", StopSequencesError, []string{"a", "b", "c", "d", "e"}, false, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var warnings []*callback.WarningEvent
+			client, err := NewClient(
+				WithStopSequencePolicy(tt.policy),
+				WithMaxRetries(0),
+				WithWarningCallback(func(ctx context.Context, event *callback.WarningEvent) {
+					warnings = append(warnings, event)
+				}),
+			)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer client.Close()
+
+			var got *CompletionRequest
+			capture := func(ctx context.Context, req *CompletionRequest) (*CompletionResponse, error) {
+				got = req
+				return &CompletionResponse{ID: "test"}, nil
+			}
+			client.RegisterProvider(&mockProvider{name: "plain", completionFunc: capture})
+			client.RegisterProvider(&stopLimitProvider{mockProvider{name: "five", completionFunc: capture}, 5})
+			client.RegisterProvider(&stopLimitProvider{mockProvider{name: "four", completionFunc: capture}, 4})
+			client.RegisterProvider(&stopLimitProvider{mockProvider{name: "none", completionFunc: capture}, 0})
+
+			req := &CompletionRequest{Model: tt.model, Messages: []Message{{Role: "user", Content: "Hi"}}, Stop: stop}
+			_, err = client.Completion(context.Background(), req)
+			if tt.wantErr {
+				var invalid *InvalidRequestError
+				if !errors.As(err, &invalid) || got != nil {
+					t.Fatalf("Completion() error = %v, sent %v, want *InvalidRequestError", err, got != nil)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Completion() error = %v", err)
+			}
+			if !reflect.DeepEqual(got.Stop, tt.want) {
+				t.Errorf("stop = %q, want %q", got.Stop, tt.want)
+			}
+			if tt.wantWarning != (len(warnings) == 1 && warnings[0].Code == WarningStopSequencesTruncated) {
+				t.Errorf("warnings = %+v, want warning %v", warnings, tt.wantWarning)
+			}
+			if len(req.Stop) != len(stop) {
+				t.Error("caller's stop sequences were modified")
+			}
+		})
+	}
+}
+
+func TestUniqueStopSequences(t *testing.T) {
+	stop := []string{"a", "b"}
+	if got := uniqueStopSequences(stop); &got[0] != &stop[0] {
+		t.Error("uniqueStopSequences() copied sequences without empty or repeated ones")
+	}
+	for _, tt := range []struct{ in, want []string }{
+		{[]string{""}, []string{}},
+		{[]string{"a", "a"}, []string{"a"}},
+		{[]string{"", "a", "b", "a"}, []string{"a", "b"}},
+	} {
+		if got := uniqueStopSequences(tt.in); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("uniqueStopSequences(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestWithStopSequencePolicyInvalid(t *testing.T) {
+	if _, err := NewClient(WithStopSequencePolicy("drop")); err == nil {
+		t.Error("NewClient(WithStopSequencePolicy(\"drop\")) error = nil")
+	}
+}
@@ -0,0 +1,267 @@
+package warp
+
+import (
+	"context"
+	"errors"
+	"io"
+	"maps"
+	"slices"
+	"strings"
+)
+
+// StreamAccumulator assembles the chunks of a completion stream into a
+// CompletionResponse. Streams requested with N > 1 interleave the deltas
+// of every choice; the accumulator keeps them apart by choice index.
+//
+// Thread Safety: StreamAccumulator is NOT safe for concurrent use.
+//
+// Example:
+//
+//	acc := warp.NewStreamAccumulator()
+//	for {
+//	    chunk, err := stream.Recv()
+//	    if err == io.EOF {
+//	        break
+//	    }
+//	    if err != nil {
+//	        return err
+//	    }
+//	    acc.Add(chunk)
+//	}
+//	for _, choice := range acc.Response().Choices {
+//	    fmt.Println(choice.Index, choice.Message.Content)
+//	}
+type StreamAccumulator struct {
+	resp    CompletionResponse
+	choices map[int]*choiceAccumulator
+}
+
+// choiceAccumulator holds the deltas of one choice.
+type choiceAccumulator struct {
+	role      string
+	content   strings.Builder
+	reasoning strings.Builder
+	toolCalls []ToolCall
+	finish    string
+	logprobs  *Logprobs
+}
+
+// NewStreamAccumulator creates an empty accumulator.
+func NewStreamAccumulator() *StreamAccumulator {
+	return &StreamAccumulator{choices: make(map[int]*choiceAccumulator)}
+}
+
+// Add adds the deltas of chunk to the choices they belong to.
+func (a *StreamAccumulator) Add(chunk *CompletionChunk) {
+	if chunk == nil {
+		return
+	}
+	if a.resp.ID == "" {
+		a.resp.ID = chunk.ID
+		a.resp.Created = chunk.Created
+		a.resp.Model = chunk.Model
+	}
+	if chunk.RequestID != "" {
+		a.resp.RequestID = chunk.RequestID
+	}
+	if chunk.ProviderRequestID != "" {
+		a.resp.ProviderRequestID = chunk.ProviderRequestID
+	}
+	if chunk.Usage != nil {
+		a.resp.Usage = chunk.Usage
+	}
+	if len(chunk.ProviderFields) > 0 {
+		if a.resp.ProviderFields == nil {
+			a.resp.ProviderFields = make(map[string]any, len(chunk.ProviderFields))
+		}
+		maps.Copy(a.resp.ProviderFields, chunk.ProviderFields)
+	}
+
+	for _, delta := range chunk.Choices {
+		choice := a.choices[delta.Index]
+		if choice == nil {
+			choice = &choiceAccumulator{}
+			a.choices[delta.Index] = choice
+		}
+		choice.add(&delta)
+	}
+}
+
+// add adds one delta to the choice. A tool call delta with an ID starts a
+// new call; deltas without one continue the last call.
+func (c *choiceAccumulator) add(delta *ChunkChoice) {
+	if delta.Delta.Role != "" {
+		c.role = delta.Delta.Role
+	}
+	c.content.WriteString(delta.Delta.Content)
+	c.reasoning.WriteString(delta.Delta.ReasoningContent)
+	for _, tc := range delta.Delta.ToolCalls {
+		if tc.ID != "" || len(c.toolCalls) == 0 {
+			c.toolCalls = append(c.toolCalls, tc)
+			continue
+		}
+		last := &c.toolCalls[len(c.toolCalls)-1]
+		last.Function.Arguments += tc.Function.Arguments
+		if last.Function.Name == "" {
+			last.Function.Name = tc.Function.Name
+		}
+	}
+	if delta.FinishReason != nil {
+		c.finish = *delta.FinishReason
+	}
+	if delta.Logprobs != nil {
+		if c.logprobs == nil {
+			c.logprobs = &Logprobs{}
+		}
+		c.logprobs.Content = append(c.logprobs.Content, delta.Logprobs.Content...)
+	}
+}
+
+// Choices returns the indexes of the choices seen so far, in order.
+func (a *StreamAccumulator) Choices() []int {
+	indexes := make([]int, 0, len(a.choices))
+	for index := range a.choices {
+		indexes = append(indexes, index)
+	}
+	slices.Sort(indexes)
+	return indexes
+}
+
+// Content returns the text of the choice with the given index so far.
+func (a *StreamAccumulator) Content(index int) string {
+	if choice := a.choices[index]; choice != nil {
+		return choice.content.String()
+	}
+	return ""
+}
+
+// Reasoning returns the reasoning of the choice with the given index so
+// far, which is kept apart from its content.
+func (a *StreamAccumulator) Reasoning(index int) string {
+	if choice := a.choices[index]; choice != nil {
+		return choice.reasoning.String()
+	}
+	return ""
+}
+
+// Response returns the response assembled so far, with one choice per
+// index in index order. Tool calls streamed without a unique ID are given
+// one, as by NormalizeToolCallIDs.
+func (a *StreamAccumulator) Response() *CompletionResponse {
+	resp := a.resp
+	resp.Object = "chat.completion"
+	for _, index := range a.Choices() {
+		choice := a.choices[index]
+		role := choice.role
+		if role == "" {
+			role = "assistant"
+		}
+		resp.Choices = append(resp.Choices, Choice{
+			Index: index,
+			Message: Message{
+				Role:             role,
+				Content:          choice.content.String(),
+				ReasoningContent: choice.reasoning.String(),
+				ToolCalls:        slices.Clone(choice.toolCalls),
+			},
+			FinishReason: choice.finish,
+			Logprobs:     choice.logprobs,
+		})
+	}
+	NormalizeToolCallIDs(&resp)
+	return &resp
+}
+
+// CollectStream reads stream to the end, closes it, and returns the
+// assembled response.
+//
+// Example:
+//
+//	stream, err := client.CompletionStream(ctx, &warp.CompletionRequest{
+//	    Model:    "openai/gpt-4o",
+//	    Messages: messages,
+//	    N:        warp.IntPtr(3),
+//	})
+//	if err != nil {
+//	    return err
+//	}
+//	resp, err := warp.CollectStream(stream)
+func CollectStream(stream Stream) (*CompletionResponse, error) {
+	defer stream.Close()
+
+	acc := NewStreamAccumulator()
+	for {
+		chunk, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			return acc.Response(), nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		acc.Add(chunk)
+	}
+}
+
+// ChoiceDelta is the part of a stream chunk that belongs to one choice.
+type ChoiceDelta struct {
+	// Index is the choice index.
+	Index int
+
+	// Delta is the content of the choice in this chunk.
+	Delta MessageDelta
+
+	// FinishReason is set in the last delta of the choice.
+	FinishReason *string
+
+	// Err is the error that ended the stream, set only in the last value
+	// sent before the channel is closed.
+	Err error
+}
+
+// StreamChoices reads stream in a goroutine and sends the delta of each
+// choice in each chunk on the returned channel, so consumers of N > 1
+// streams can route deltas by Index. The channel is closed at the end of
+// the stream, after a final value with Err set if the stream failed, or
+// when ctx is done. StreamChoices closes the stream.
+//
+// Example:
+//
+//	outputs := make([]strings.Builder, 3)
+//	for delta := range warp.StreamChoices(ctx, stream) {
+//	    if delta.Err != nil {
+//	        return delta.Err
+//	    }
+//	    outputs[delta.Index].WriteString(delta.Delta.Content)
+//	}
+func StreamChoices(ctx context.Context, stream Stream) <-chan ChoiceDelta {
+	ch := make(chan ChoiceDelta)
+	go func() {
+		defer close(ch)
+		defer stream.Close()
+
+		send := func(delta ChoiceDelta) bool {
+			select {
+			case ch <- delta:
+				return true
+			case <-ctx.Done():
+				return false
+			}
+		}
+		for {
+			chunk, err := stream.Recv()
+			if errors.Is(err, io.EOF) {
+				return
+			}
+			if err != nil {
+				send(ChoiceDelta{Err: err})
+				return
+			}
+			for _, choice := range chunk.Choices {
+				if !send(ChoiceDelta{Index: choice.Index, Delta: choice.Delta, FinishReason: choice.FinishReason}) {
+					return
+				}
+			}
+		}
+	}()
+	return ch
+}
@@ -0,0 +1,156 @@
+package warp
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func choiceChunk(index int, content string, finish *string) *CompletionChunk {
+	return &CompletionChunk{
+		ID:      "chatcmpl-1",
+		Model:   "gpt-4o",
+		Choices: []ChunkChoice{{Index: index, Delta: MessageDelta{Content: content}, FinishReason: finish}},
+	}
+}
+
+func TestStreamAccumulator(t *testing.T) {
+	stop := "stop"
+	acc := NewStreamAccumulator()
+	acc.Add(&CompletionChunk{ID: "chatcmpl-1", Model: "gpt-4o", Choices: []ChunkChoice{
+		{Index: 0, Delta: MessageDelta{Role: "assistant", Content: "Hel"}},
+		{Index: 1, Delta: MessageDelta{Role: "assistant", Content: "Bon"}},
+	}})
+	acc.Add(choiceChunk(1, "jour", &stop))
+	acc.Add(choiceChunk(0, "lo", nil))
+	acc.Add(&CompletionChunk{Choices: []ChunkChoice{{Index: 2, Delta: MessageDelta{
+		ToolCalls: []ToolCall{{ID: "call_1", Type: "function", Function: FunctionCall{Name: "lookup", Arguments: `{"id":`}}},
+	}}}})
+	acc.Add(&CompletionChunk{Choices: []ChunkChoice{{Index: 2, Delta: MessageDelta{
+		ToolCalls: []ToolCall{{Function: FunctionCall{Arguments: `42}`}}},
+	}}}})
+	acc.Add(choiceChunk(0, "", &stop))
+	acc.Add(&CompletionChunk{Usage: &Usage{PromptTokens: 5, CompletionTokens: 9, TotalTokens: 14}})
+
+	if got := acc.Content(1); got != "Bonjour" {
+		t.Errorf("Content(1) = %q, want %q", got, "Bonjour")
+	}
+	resp := acc.Response()
+	if resp.ID != "chatcmpl-1" || resp.Model != "gpt-4o" || resp.Usage == nil || resp.Usage.TotalTokens != 14 {
+		t.Errorf("response = %+v", resp)
+	}
+	if len(resp.Choices) != 3 {
+		t.Fatalf("len(Choices) = %d, want 3", len(resp.Choices))
+	}
+	for i, want := range []string{"Hello", "Bonjour", ""} {
+		choice := resp.Choices[i]
+		if choice.Index != i || choice.Message.Content != want || choice.Message.Role != "assistant" {
+			t.Errorf("Choices[%d] = %+v, want content %q", i, choice, want)
+		}
+	}
+	if resp.Choices[0].FinishReason != "stop" || resp.Choices[2].FinishReason != "" {
+		t.Errorf("finish reasons = %q, %q", resp.Choices[0].FinishReason, resp.Choices[2].FinishReason)
+	}
+	calls := resp.Choices[2].Message.ToolCalls
+	if len(calls) != 1 || calls[0].Function.Name != "lookup" || calls[0].Function.Arguments != `{"id":42}` {
+		t.Errorf("tool calls = %+v", calls)
+	}
+}
+
+func TestStreamAccumulatorReasoning(t *testing.T) {
+	acc := NewStreamAccumulator()
+	acc.Add(&CompletionChunk{Choices: []ChunkChoice{{Delta: MessageDelta{Role: "assistant", ReasoningContent: "The user "}}}})
+	acc.Add(&CompletionChunk{Choices: []ChunkChoice{{Delta: MessageDelta{ReasoningContent: "greets me."}}}})
+	acc.Add(choiceChunk(0, "Hello!", nil))
+
+	if got := acc.Reasoning(0); got != "The user greets me." {
+		t.Errorf("Reasoning(0) = %q", got)
+	}
+	if got := acc.Content(0); got != "Hello!" {
+		t.Errorf("Content(0) = %q, want reasoning kept apart", got)
+	}
+	if msg := acc.Response().Choices[0].Message; msg.ReasoningContent != "The user greets me." || msg.Content != "Hello!" {
+		t.Errorf("message = %+v", msg)
+	}
+}
+
+func TestStreamAccumulatorProviderFields(t *testing.T) {
+	acc := NewStreamAccumulator()
+	acc.Add(&CompletionChunk{ProviderFields: map[string]any{"provider": "Azure"}, Choices: []ChunkChoice{{Delta: MessageDelta{Content: "Hi"}}}})
+	acc.Add(&CompletionChunk{ProviderFields: map[string]any{"region": "eu"}})
+
+	fields := acc.Response().ProviderFields
+	if fields["provider"] != "Azure" || fields["region"] != "eu" {
+		t.Errorf("ProviderFields = %v, want fields of every chunk", fields)
+	}
+}
+
+func TestCollectStream(t *testing.T) {
+	stream := &mockStream{chunks: []*CompletionChunk{choiceChunk(1, "b", nil), choiceChunk(0, "a", nil)}}
+	resp, err := CollectStream(stream)
+	if err != nil {
+		t.Fatalf("CollectStream() error = %v", err)
+	}
+	if len(resp.Choices) != 2 || resp.Choices[0].Message.Content != "a" || resp.Choices[1].Message.Content != "b" {
+		t.Errorf("Choices = %+v", resp.Choices)
+	}
+
+	failing := &errStream{err: errors.New("connection reset")}
+	if _, err := CollectStream(failing); err == nil || !failing.closed {
+		t.Errorf("CollectStream() error = %v, closed = %v", err, failing.closed)
+	}
+}
+
+func TestStreamChoices(t *testing.T) {
+	stream := &mockStream{chunks: []*CompletionChunk{
+		{Choices: []ChunkChoice{{Index: 0, Delta: MessageDelta{Content: "x"}}, {Index: 1, Delta: MessageDelta{Content: "y"}}}},
+		choiceChunk(1, "z", nil),
+	}}
+	outputs := make([]string, 2)
+	for delta := range StreamChoices(context.Background(), stream) {
+		if delta.Err != nil {
+			t.Fatalf("delta error = %v", delta.Err)
+		}
+		outputs[delta.Index] += delta.Delta.Content
+	}
+	if outputs[0] != "x" || outputs[1] != "yz" {
+		t.Errorf("outputs = %q", outputs)
+	}
+
+	var last ChoiceDelta
+	for delta := range StreamChoices(context.Background(), &errStream{err: errors.New("boom")}) {
+		last = delta
+	}
+	if last.Err == nil {
+		t.Error("last delta error = nil, want the stream error")
+	}
+
+	// Cancelling stops the reader and closes the stream
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	endless := &errStream{}
+	for range StreamChoices(ctx, endless) {
+	}
+	if !endless.closed {
+		t.Error("stream not closed after cancellation")
+	}
+}
+
+// errStream returns err from Recv, or an endless run of chunks if err is
+// nil.
+type errStream struct {
+	err    error
+	closed bool
+}
+
+func (s *errStream) Recv() (*CompletionChunk, error) {
+	if s.err != nil {
+		return nil, s.err
+	}
+	return choiceChunk(0, "x", nil), nil
+}
+
+func (s *errStream) Close() error {
+	s.closed = true
+	return nil
+}
@@ -0,0 +1,291 @@
+package warp
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"time"
+)
+
+// StreamWriterOptions configures StreamToWriter.
+type StreamWriterOptions struct {
+	// FlushInterval is how often the writer is flushed if it has a Flush
+	// method, such as a bufio.Writer or an http.ResponseWriter. Zero
+	// flushes after every delta; a positive interval flushes text written
+	// since the last flush once the interval has passed, and at the end of
+	// the stream.
+	FlushInterval time.Duration
+
+	// Width wraps lines at this many columns, breaking between words and
+	// cutting words longer than a line. ANSI escape sequences, such as
+	// colors, take no columns and are never split. Every character,
+	// including a tab, takes one column. Zero disables wrapping.
+	//
+	// While wrapping, the last word of a delta is held until it is
+	// complete, since only then is it known whether it fits on the line.
+	Width int
+}
+
+// StreamToWriter writes the text deltas of the first choice of stream to w
+// as they arrive, until the stream ends. It returns the assembled
+// response, as built by StreamAccumulator, for its usage, finish reason,
+// and tool calls.
+//
+// The stream is not closed. Returns the stream's error if it fails, after
+// writing the text received before it, or an error if writing fails.
+//
+// Example:
+//
+//	stream, err := client.CompletionStream(ctx, req)
+//	if err != nil {
+//	    return err
+//	}
+//	defer stream.Close()
+//	resp, err := warp.StreamToWriter(stream, os.Stdout, &warp.StreamWriterOptions{Width: 80})
+//	if err != nil {
+//	    return err
+//	}
+//	fmt.Printf("\n(%d tokens)\n", resp.Usage.TotalTokens)
+func StreamToWriter(stream Stream, w io.Writer, opts *StreamWriterOptions) (*CompletionResponse, error) {
+	if stream == nil {
+		return nil, fmt.Errorf("stream cannot be nil")
+	}
+	if w == nil {
+		return nil, fmt.Errorf("writer cannot be nil")
+	}
+	if opts == nil {
+		opts = &StreamWriterOptions{}
+	}
+
+	fw := newFlushWriter(w, opts.FlushInterval)
+	var out io.StringWriter = fw
+	var wrapper *wordWrapper
+	if opts.Width > 0 {
+		wrapper = &wordWrapper{w: fw, width: opts.Width}
+		out = wrapper
+	}
+
+	acc := NewStreamAccumulator()
+	var recvErr error
+	for {
+		chunk, err := stream.Recv()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			recvErr = err
+			break
+		}
+		acc.Add(chunk)
+		for _, choice := range chunk.Choices {
+			if choice.Index != 0 || choice.Delta.Content == "" {
+				continue
+			}
+			if _, err := out.WriteString(choice.Delta.Content); err != nil {
+				fw.Close()
+				return nil, fmt.Errorf("failed to write stream: %w", err)
+			}
+		}
+	}
+
+	var err error
+	if wrapper != nil {
+		err = wrapper.Flush()
+	}
+	if closeErr := fw.Close(); err == nil {
+		err = closeErr
+	}
+	if recvErr != nil {
+		return nil, recvErr
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to write stream: %w", err)
+	}
+	return acc.Response(), nil
+}
+
+// flushWriter writes to a writer and flushes it, if it can be flushed,
+// after every write or, with an interval, from a timer once the interval
+// has passed since the first write not yet flushed.
+type flushWriter struct {
+	mu       sync.Mutex
+	w        io.Writer
+	flush    func() error
+	interval time.Duration
+	timer    *time.Timer
+	closed   bool
+	err      error
+}
+
+// newFlushWriter returns a flushWriter for w.
+func newFlushWriter(w io.Writer, interval time.Duration) *flushWriter {
+	f := &flushWriter{w: w, interval: interval}
+	switch fl := w.(type) {
+	case interface{ Flush() error }:
+		f.flush = fl.Flush
+	case interface{ Flush() }:
+		f.flush = func() error {
+			fl.Flush()
+			return nil
+		}
+	}
+	return f
+}
+
+// WriteString writes s and flushes or schedules a flush.
+func (f *flushWriter) WriteString(s string) (int, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.err != nil {
+		return 0, f.err
+	}
+	n, err := io.WriteString(f.w, s)
+	if err != nil {
+		f.err = err
+		return n, err
+	}
+	switch {
+	case f.flush == nil:
+	case f.interval <= 0:
+		f.err = f.flush()
+	case f.timer == nil:
+		f.timer = time.AfterFunc(f.interval, f.flushPending)
+	}
+	return n, f.err
+}
+
+// flushPending flushes on the timer.
+func (f *flushWriter) flushPending() {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.timer = nil
+	if !f.closed && f.err == nil {
+		f.err = f.flush()
+	}
+}
+
+// Close flushes pending writes and stops the timer. The writer is not
+// used after Close returns.
+func (f *flushWriter) Close() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.closed = true
+	if f.timer != nil {
+		f.timer.Stop()
+		f.timer = nil
+		if f.err == nil {
+			f.err = f.flush()
+		}
+	}
+	return f.err
+}
+
+// Escape sequence states of wordWrapper.
+const (
+	escNone   = iota
+	escStart  // after ESC
+	escCSI    // in a control sequence, ESC [ ... final byte
+	escOSC    // in an operating system command, ESC ] ... BEL or ESC \
+	escOSCEnd // after ESC in an operating system command
+)
+
+// wordWrapper wraps text written in pieces at a column width. Whitespace
+// and the current word are held until the word ends, so the line can be
+// broken before it.
+type wordWrapper struct {
+	w         io.StringWriter
+	width     int
+	col       int // columns taken on the current line
+	space     strings.Builder
+	word      strings.Builder
+	wordWidth int
+	esc       int
+}
+
+// WriteString wraps s.
+func (ww *wordWrapper) WriteString(s string) (int, error) {
+	for _, r := range s {
+		if ww.esc != escNone {
+			ww.word.WriteRune(r)
+			ww.esc = nextEscState(ww.esc, r)
+			continue
+		}
+
+		var err error
+		switch r {
+		case '\x1b':
+			ww.word.WriteRune(r)
+			ww.esc = escStart
+		case '\n':
+			if err = ww.Flush(); err == nil {
+				_, err = ww.w.WriteString("\n")
+				ww.space.Reset()
+				ww.col = 0
+			}
+		case ' ', '\t':
+			if err = ww.Flush(); err == nil {
+				ww.space.WriteRune(r)
+			}
+		default:
+			ww.word.WriteRune(r)
+			ww.wordWidth++
+			if ww.wordWidth >= ww.width {
+				// Too long for any line: cut it here
+				err = ww.Flush()
+			}
+		}
+		if err != nil {
+			return 0, err
+		}
+	}
+	return len(s), nil
+}
+
+// Flush writes the held word, on a new line if it does not fit on the
+// current one, in which case the whitespace before it is dropped.
+func (ww *wordWrapper) Flush() error {
+	if ww.word.Len() == 0 {
+		return nil
+	}
+	var text string
+	if ww.wordWidth > 0 && ww.col > 0 && ww.col+ww.space.Len()+ww.wordWidth > ww.width {
+		text = "\n" + ww.word.String()
+		ww.col = 0
+	} else {
+		text = ww.space.String() + ww.word.String()
+		ww.col += ww.space.Len()
+	}
+	ww.col += ww.wordWidth
+	ww.space.Reset()
+	ww.word.Reset()
+	ww.wordWidth = 0
+	_, err := ww.w.WriteString(text)
+	return err
+}
+
+// nextEscState returns the escape sequence state after r.
+func nextEscState(state int, r rune) int {
+	switch state {
+	case escStart:
+		switch r {
+		case '[':
+			return escCSI
+		case ']':
+			return escOSC
+		}
+	case escCSI:
+		if r < 0x40 || r > 0x7e {
+			return escCSI
+		}
+	case escOSC:
+		switch r {
+		case '\a':
+			return escNone
+		case '\x1b':
+			return escOSCEnd
+		}
+		return escOSC
+	}
+	return escNone
+}
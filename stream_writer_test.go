@@ -0,0 +1,131 @@
+package warp
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+// textStream returns a stream of first-choice deltas.
+func textStream(deltas ...string) *mockStream {
+	stream := &mockStream{}
+	for _, delta := range deltas {
+		stream.chunks = append(stream.chunks, choiceChunk(0, delta, nil))
+	}
+	return stream
+}
+
+func TestStreamToWriter(t *testing.T) {
+	stream := textStream("Hello", ", ", "world")
+	stream.chunks = append(stream.chunks, choiceChunk(1, "ignored", nil))
+
+	var buf bytes.Buffer
+	resp, err := StreamToWriter(stream, &buf, nil)
+	if err != nil {
+		t.Fatalf("StreamToWriter() error = %v", err)
+	}
+	if buf.String() != "Hello, world" {
+		t.Errorf("wrote %q, want %q", buf.String(), "Hello, world")
+	}
+	if len(resp.Choices) != 2 || resp.Choices[0].Message.Content != "Hello, world" {
+		t.Errorf("Response = %+v", resp)
+	}
+
+	errStop := errors.New("connection reset")
+	buf.Reset()
+	if _, err := StreamToWriter(&errStream{err: errStop}, &buf, nil); !errors.Is(err, errStop) {
+		t.Errorf("StreamToWriter() error = %v, want %v", err, errStop)
+	}
+}
+
+func TestStreamToWriterWrap(t *testing.T) {
+	tests := []struct {
+		name   string
+		width  int
+		deltas []string
+		want   string
+	}{
+		{
+			name:   "words split across deltas",
+			width:  10,
+			deltas: []string{"The qu", "ick brown f", "ox jumps"},
+			want:   "The quick\nbrown fox\njumps",
+		},
+		{
+			name:   "explicit newlines and indentation kept",
+			width:  10,
+			deltas: []string{"one two  \n  - three four"},
+			want:   "one two\n  - three\nfour",
+		},
+		{
+			name:   "long word cut",
+			width:  4,
+			deltas: []string{"ab abcdefghij"},
+			want:   "ab\nabcd\nefgh\nij",
+		},
+		{
+			name:   "escape sequences take no columns",
+			width:  9,
+			deltas: []string{"\x1b[1mbold\x1b", "[0m text \x1b]8;;https://x\x1b\\link\x1b]8;;\a"},
+			want:   "\x1b[1mbold\x1b[0m text\n\x1b]8;;https://x\x1b\\link\x1b]8;;\a",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			if _, err := StreamToWriter(textStream(tt.deltas...), &buf, &StreamWriterOptions{Width: tt.width}); err != nil {
+				t.Fatalf("StreamToWriter() error = %v", err)
+			}
+			if buf.String() != tt.want {
+				t.Errorf("wrote %q, want %q", buf.String(), tt.want)
+			}
+		})
+	}
+}
+
+// flushCounter is a bufio.Writer that counts flushes.
+type flushCounter struct {
+	*bufio.Writer
+	flushes int
+}
+
+func (f *flushCounter) Flush() error {
+	f.flushes++
+	return f.Writer.Flush()
+}
+
+func TestStreamToWriterFlush(t *testing.T) {
+	var buf bytes.Buffer
+	w := &flushCounter{Writer: bufio.NewWriter(&buf)}
+	if _, err := StreamToWriter(textStream("a", "b", "c"), w, nil); err != nil {
+		t.Fatalf("StreamToWriter() error = %v", err)
+	}
+	if w.flushes != 3 || buf.String() != "abc" {
+		t.Errorf("flushes = %d, wrote %q; want 3 flushes of %q", w.flushes, buf.String(), "abc")
+	}
+
+	buf.Reset()
+	w = &flushCounter{Writer: bufio.NewWriter(&buf)}
+	if _, err := StreamToWriter(textStream("a", "b", "c"), w, &StreamWriterOptions{FlushInterval: time.Hour}); err != nil {
+		t.Fatalf("StreamToWriter() error = %v", err)
+	}
+	if w.flushes != 1 || buf.String() != "abc" {
+		t.Errorf("flushes = %d, wrote %q; want 1 flush of %q", w.flushes, buf.String(), "abc")
+	}
+}
+
+// failingWriter fails every write.
+type failingWriter struct{}
+
+func (failingWriter) Write(p []byte) (int, error) { return 0, errors.New("disk full") }
+
+func TestStreamToWriterWriteError(t *testing.T) {
+	_, err := StreamToWriter(textStream("a"), failingWriter{}, nil)
+	if err == nil || !strings.Contains(err.Error(), "disk full") {
+		t.Errorf("StreamToWriter() error = %v, want write error", err)
+	}
+}
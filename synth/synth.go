@@ -0,0 +1,350 @@
+// Package synth generates synthetic fine-tuning data.
+//
+// A Generator renders a seed prompt template, asks one or more models for
+// completions at a high temperature, and keeps only outputs that are valid
+// and sufficiently different from those already accepted:
+//
+//   - Outputs are checked against an optional JSON Schema.
+//   - Exact duplicates (ignoring case and whitespace) are always dropped.
+//   - With an embedding model, near-duplicates above a cosine similarity
+//     threshold are dropped too.
+//
+// Accepted examples can be written as OpenAI fine-tuning JSONL.
+//
+// Basic usage:
+//
+//	gen := synth.New(client,
+//	    synth.WithModels("openai/gpt-4o-mini", "anthropic/claude-3-5-haiku-20241022"),
+//	    synth.WithEmbeddingModel("openai/text-embedding-3-small"),
+//	)
+//	examples, err := gen.Generate(ctx, synth.Seed{
+//	    System: "You are a support agent for Acme.",
+//	    Prompt: "Write a customer question about {{.Topic}} and answer it.",
+//	    Variables: []map[string]any{{"Topic": "billing"}, {"Topic": "shipping"}},
+//	}, 100)
+//	if err != nil {
+//	    return err
+//	}
+//	err = synth.WriteJSONL(file, examples)
+package synth
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"text/template"
+
+	"github.com/blue-context/warp"
+	"github.com/blue-context/warp/internal/jsonschema"
+	"github.com/blue-context/warp/internal/vecmath"
+	"github.com/blue-context/warp/transcript"
+)
+
+// Seed describes what to generate.
+type Seed struct {
+	// System is the system prompt sent with each generation and recorded
+	// in the resulting examples.
+	System string
+
+	// Prompt is a text/template rendered with one entry of Variables per
+	// generation. The rendered prompt becomes the user message of the
+	// example.
+	Prompt string
+
+	// Variables are cycled through across generations for diversity.
+	// If empty, Prompt is rendered with no data.
+	Variables []map[string]any
+}
+
+// Example is an accepted generation.
+type Example struct {
+	// System is the system prompt used.
+	System string `json:"system,omitempty"`
+
+	// Prompt is the rendered user prompt.
+	Prompt string `json:"prompt"`
+
+	// Completion is the model output.
+	Completion string `json:"completion"`
+
+	// Model is the model that produced the completion.
+	Model string `json:"model"`
+}
+
+// Generator produces diverse, validated examples from a Seed.
+//
+// Thread Safety: Generator is safe for concurrent use.
+type Generator struct {
+	client         warp.Client
+	models         []string
+	embeddingModel string
+	threshold      float64
+	schema         map[string]any
+	temperature    float64
+	concurrency    int
+	maxAttempts    int
+}
+
+// Option configures a Generator.
+type Option func(*Generator)
+
+// WithModels sets the models generations are spread across, round-robin.
+// Mixing providers increases diversity.
+func WithModels(models ...string) Option {
+	return func(g *Generator) {
+		g.models = models
+	}
+}
+
+// WithEmbeddingModel enables near-duplicate detection using embeddings
+// from model.
+func WithEmbeddingModel(model string) Option {
+	return func(g *Generator) {
+		g.embeddingModel = model
+	}
+}
+
+// WithSimilarityThreshold sets the cosine similarity at or above which an
+// output is considered a near-duplicate (default 0.92).
+func WithSimilarityThreshold(threshold float64) Option {
+	return func(g *Generator) {
+		g.threshold = threshold
+	}
+}
+
+// WithSchema requires outputs to be JSON matching schema. The schema is
+// also sent as a json_schema response format.
+func WithSchema(schema map[string]any) Option {
+	return func(g *Generator) {
+		g.schema = schema
+	}
+}
+
+// WithTemperature sets the sampling temperature (default 1.0).
+func WithTemperature(temperature float64) Option {
+	return func(g *Generator) {
+		g.temperature = temperature
+	}
+}
+
+// WithConcurrency sets how many generations run in parallel (default 4).
+func WithConcurrency(n int) Option {
+	return func(g *Generator) {
+		g.concurrency = n
+	}
+}
+
+// WithMaxAttempts limits the total number of generations per Generate call,
+// including rejected ones (default 3 per requested example).
+func WithMaxAttempts(n int) Option {
+	return func(g *Generator) {
+		g.maxAttempts = n
+	}
+}
+
+// New creates a Generator using client.
+func New(client warp.Client, opts ...Option) *Generator {
+	g := &Generator{
+		client:      client,
+		threshold:   0.92,
+		temperature: 1.0,
+		concurrency: 4,
+	}
+
+	for _, opt := range opts {
+		opt(g)
+	}
+
+	if g.concurrency < 1 {
+		g.concurrency = 1
+	}
+
+	return g
+}
+
+// candidate is a generation awaiting deduplication.
+type candidate struct {
+	example Example
+	err     error
+}
+
+// Generate produces n accepted examples from seed.
+//
+// Generations that fail, do not match the schema, or duplicate an accepted
+// example are discarded. If the attempt limit is reached first, the
+// examples accepted so far are returned with an error.
+func (g *Generator) Generate(ctx context.Context, seed Seed, n int) ([]Example, error) {
+	if len(g.models) == 0 {
+		return nil, fmt.Errorf("at least one model is required")
+	}
+	if n <= 0 {
+		return nil, nil
+	}
+
+	tmpl, err := template.New("seed").Option("missingkey=error").Parse(seed.Prompt)
+	if err != nil {
+		return nil, fmt.Errorf("invalid seed prompt: %w", err)
+	}
+
+	maxAttempts := g.maxAttempts
+	if maxAttempts <= 0 {
+		maxAttempts = 3 * n
+	}
+
+	var (
+		accepted   []Example
+		embeddings [][]float64
+		seen       = make(map[string]bool)
+		attempts   int
+		lastErr    error
+	)
+
+	for len(accepted) < n && attempts < maxAttempts {
+		if err := ctx.Err(); err != nil {
+			return accepted, err
+		}
+
+		batch := min(g.concurrency, n-len(accepted), maxAttempts-attempts)
+		candidates := make([]candidate, batch)
+		var wg sync.WaitGroup
+		for i := 0; i < batch; i++ {
+			wg.Add(1)
+			go func(i, attempt int) {
+				defer wg.Done()
+				candidates[i].example, candidates[i].err = g.generate(ctx, tmpl, seed, attempt)
+			}(i, attempts+i)
+		}
+		wg.Wait()
+		attempts += batch
+
+		for _, c := range candidates {
+			if len(accepted) == n {
+				break
+			}
+			if c.err != nil {
+				lastErr = c.err
+				continue
+			}
+
+			key := strings.Join(strings.Fields(strings.ToLower(c.example.Completion)), " ")
+			if seen[key] {
+				continue
+			}
+
+			if g.embeddingModel != "" {
+				vec, err := g.embed(ctx, c.example.Completion)
+				if err != nil {
+					return accepted, err
+				}
+				if nearDuplicate(vec, embeddings, g.threshold) {
+					continue
+				}
+				embeddings = append(embeddings, vec)
+			}
+
+			seen[key] = true
+			accepted = append(accepted, c.example)
+		}
+	}
+
+	if len(accepted) < n {
+		err := fmt.Errorf("generated %d of %d examples in %d attempts", len(accepted), n, attempts)
+		if lastErr != nil {
+			err = fmt.Errorf("%w: last error: %w", err, lastErr)
+		}
+		return accepted, err
+	}
+	return accepted, nil
+}
+
+// generate renders the prompt for an attempt and calls the model.
+func (g *Generator) generate(ctx context.Context, tmpl *template.Template, seed Seed, attempt int) (Example, error) {
+	var data map[string]any
+	if len(seed.Variables) > 0 {
+		data = seed.Variables[attempt%len(seed.Variables)]
+	}
+	var prompt strings.Builder
+	if err := tmpl.Execute(&prompt, data); err != nil {
+		return Example{}, fmt.Errorf("failed to render seed prompt: %w", err)
+	}
+
+	model := g.models[attempt%len(g.models)]
+	req := &warp.CompletionRequest{
+		Model:       model,
+		Temperature: warp.Float64Ptr(g.temperature),
+	}
+	if seed.System != "" {
+		req.Messages = append(req.Messages, warp.Message{Role: "system", Content: seed.System})
+	}
+	req.Messages = append(req.Messages, warp.Message{Role: "user", Content: prompt.String()})
+	if g.schema != nil {
+		req.ResponseFormat = &warp.ResponseFormat{
+			Type:       "json_schema",
+			JSONSchema: &warp.JSONSchema{Name: "example", Schema: g.schema},
+		}
+	}
+
+	resp, err := g.client.Completion(ctx, req)
+	if err != nil {
+		return Example{}, err
+	}
+	if len(resp.Choices) == 0 {
+		return Example{}, fmt.Errorf("model %s returned no choices", model)
+	}
+	completion, _ := resp.Choices[0].Message.Content.(string)
+	completion = strings.TrimSpace(completion)
+	if completion == "" {
+		return Example{}, fmt.Errorf("model %s returned empty content", model)
+	}
+
+	if g.schema != nil {
+		if problems := jsonschema.Validate(g.schema, completion); len(problems) > 0 {
+			return Example{}, fmt.Errorf("output does not match schema: %s", strings.Join(problems, "; "))
+		}
+	}
+
+	return Example{System: seed.System, Prompt: prompt.String(), Completion: completion, Model: model}, nil
+}
+
+// embed returns the embedding of text.
+func (g *Generator) embed(ctx context.Context, text string) ([]float64, error) {
+	resp, err := g.client.Embedding(ctx, &warp.EmbeddingRequest{Model: g.embeddingModel, Input: text})
+	if err != nil {
+		return nil, fmt.Errorf("embedding failed: %w", err)
+	}
+	if len(resp.Data) == 0 {
+		return nil, fmt.Errorf("embedding response contained no data")
+	}
+	return resp.Data[0].Embedding, nil
+}
+
+// nearDuplicate reports whether vec is at least threshold similar to any
+// of accepted.
+func nearDuplicate(vec []float64, accepted [][]float64, threshold float64) bool {
+	for _, other := range accepted {
+		if vecmath.Cosine(vec, other) >= threshold {
+			return true
+		}
+	}
+	return false
+}
+
+// WriteJSONL writes examples in OpenAI fine-tuning JSONL format, one
+// system/user/assistant conversation per line.
+func WriteJSONL(w io.Writer, examples []Example) error {
+	conversations := make([]*transcript.Conversation, len(examples))
+	for i, ex := range examples {
+		conv := &transcript.Conversation{}
+		if ex.System != "" {
+			conv.Messages = append(conv.Messages, warp.Message{Role: "system", Content: ex.System})
+		}
+		conv.Messages = append(conv.Messages,
+			warp.Message{Role: "user", Content: ex.Prompt},
+			warp.Message{Role: "assistant", Content: ex.Completion},
+		)
+		conversations[i] = conv
+	}
+	return transcript.WriteJSONL(w, conversations...)
+}
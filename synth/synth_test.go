@@ -0,0 +1,163 @@
+package synth
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/blue-context/warp"
+	"github.com/blue-context/warp/internal/testutil"
+	"github.com/blue-context/warp/transcript"
+)
+
+// newTestClient returns a client with mock registered as provider "mock".
+func newTestClient(t *testing.T, mock *testutil.MockProvider) warp.Client {
+	t.Helper()
+	client, err := warp.NewClient()
+	if err != nil {
+		t.Fatalf("NewClient() error = %v", err)
+	}
+	t.Cleanup(func() { client.Close() })
+	if err := client.RegisterProvider(mock); err != nil {
+		t.Fatalf("RegisterProvider() error = %v", err)
+	}
+	return client
+}
+
+// replies returns a CompletionFunc answering with outputs in order.
+func replies(requests *[]*warp.CompletionRequest, outputs ...string) func(context.Context, *warp.CompletionRequest) (*warp.CompletionResponse, error) {
+	return func(ctx context.Context, req *warp.CompletionRequest) (*warp.CompletionResponse, error) {
+		*requests = append(*requests, req)
+		out := outputs[(len(*requests)-1)%len(outputs)]
+		return &warp.CompletionResponse{Choices: []warp.Choice{{Message: warp.Message{Content: out}}}}, nil
+	}
+}
+
+// TestGenerate tests templating, model rotation, and exact deduplication
+func TestGenerate(t *testing.T) {
+	var requests []*warp.CompletionRequest
+	mock := &testutil.MockProvider{
+		CompletionFunc: replies(&requests, "Answer one", "answer  ONE", "Answer two", "Answer three"),
+	}
+
+	gen := New(newTestClient(t, mock), WithModels("mock/a", "mock/b"), WithConcurrency(1))
+	examples, err := gen.Generate(context.Background(), Seed{
+		System:    "Be brief.",
+		Prompt:    "Question about {{.Topic}}",
+		Variables: []map[string]any{{"Topic": "billing"}, {"Topic": "shipping"}},
+	}, 3)
+	if err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+
+	if len(examples) != 3 {
+		t.Fatalf("len(examples) = %d, want 3", len(examples))
+	}
+	if len(requests) != 4 {
+		t.Errorf("requests = %d, want 4 (one duplicate rejected)", len(requests))
+	}
+	want := []Example{
+		{System: "Be brief.", Prompt: "Question about billing", Completion: "Answer one", Model: "mock/a"},
+		{System: "Be brief.", Prompt: "Question about billing", Completion: "Answer two", Model: "mock/a"},
+		{System: "Be brief.", Prompt: "Question about shipping", Completion: "Answer three", Model: "mock/b"},
+	}
+	for i := range want {
+		if examples[i] != want[i] {
+			t.Errorf("examples[%d] = %+v, want %+v", i, examples[i], want[i])
+		}
+	}
+	if got := requests[0]; got.Messages[0].Role != "system" || *got.Temperature != 1.0 {
+		t.Errorf("request = %+v", got)
+	}
+}
+
+// TestGenerateSchema tests schema validation and the attempt limit
+func TestGenerateSchema(t *testing.T) {
+	var requests []*warp.CompletionRequest
+	mock := &testutil.MockProvider{
+		CompletionFunc: replies(&requests, `{"q":"a"}`, `not json`, `{"x":1}`),
+	}
+
+	schema := map[string]any{
+		"type":       "object",
+		"properties": map[string]any{"q": map[string]any{"type": "string"}},
+		"required":   []string{"q"},
+	}
+	gen := New(newTestClient(t, mock), WithModels("mock/a"), WithSchema(schema), WithConcurrency(1), WithMaxAttempts(3))
+	examples, err := gen.Generate(context.Background(), Seed{Prompt: "Go"}, 2)
+
+	if err == nil || !strings.Contains(err.Error(), "generated 1 of 2 examples in 3 attempts") {
+		t.Errorf("Generate() error = %v", err)
+	}
+	if !strings.Contains(err.Error(), "does not match schema") {
+		t.Errorf("Generate() error = %v, want schema error", err)
+	}
+	if len(examples) != 1 || examples[0].Completion != `{"q":"a"}` {
+		t.Errorf("examples = %+v", examples)
+	}
+	if rf := requests[0].ResponseFormat; rf == nil || rf.Type != "json_schema" {
+		t.Errorf("ResponseFormat = %+v", rf)
+	}
+}
+
+// TestGenerateEmbeddingDedup tests near-duplicate rejection
+func TestGenerateEmbeddingDedup(t *testing.T) {
+	var requests []*warp.CompletionRequest
+	vectors := map[string][]float64{
+		"The cat sat.":       {1, 0},
+		"A cat was sitting.": {0.99, 0.05},
+		"Stocks fell today.": {0, 1},
+	}
+	mock := &testutil.MockProvider{
+		CompletionFunc: replies(&requests, "The cat sat.", "A cat was sitting.", "Stocks fell today."),
+		EmbeddingFunc: func(ctx context.Context, req *warp.EmbeddingRequest) (*warp.EmbeddingResponse, error) {
+			return &warp.EmbeddingResponse{Data: []warp.Embedding{{Embedding: vectors[req.Input.(string)]}}}, nil
+		},
+	}
+
+	gen := New(newTestClient(t, mock), WithModels("mock/a"), WithEmbeddingModel("mock/embed"), WithConcurrency(1))
+	examples, err := gen.Generate(context.Background(), Seed{Prompt: "Write a sentence."}, 2)
+	if err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+	if len(examples) != 2 || examples[1].Completion != "Stocks fell today." {
+		t.Errorf("examples = %+v", examples)
+	}
+}
+
+// TestGenerateErrors tests configuration errors
+func TestGenerateErrors(t *testing.T) {
+	gen := New(nil)
+	if _, err := gen.Generate(context.Background(), Seed{Prompt: "x"}, 1); err == nil {
+		t.Error("Generate() without models succeeded")
+	}
+
+	gen = New(nil, WithModels("mock/a"))
+	if _, err := gen.Generate(context.Background(), Seed{Prompt: "{{"}, 1); err == nil {
+		t.Error("Generate() with invalid template succeeded")
+	}
+}
+
+// TestWriteJSONL tests fine-tuning output
+func TestWriteJSONL(t *testing.T) {
+	var buf bytes.Buffer
+	err := WriteJSONL(&buf, []Example{
+		{System: "Be brief.", Prompt: "Hi", Completion: "Hello"},
+		{Prompt: "Bye", Completion: "Goodbye"},
+	})
+	if err != nil {
+		t.Fatalf("WriteJSONL() error = %v", err)
+	}
+
+	convs, err := transcript.ReadJSONL(&buf)
+	if err != nil {
+		t.Fatalf("ReadJSONL() error = %v", err)
+	}
+	if len(convs) != 2 || len(convs[0].Messages) != 3 || len(convs[1].Messages) != 2 {
+		t.Fatalf("conversations = %+v", convs)
+	}
+	if m := convs[0].Messages[2]; m.Role != "assistant" || m.Content != "Hello" {
+		t.Errorf("assistant message = %+v", m)
+	}
+}
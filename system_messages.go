@@ -0,0 +1,109 @@
+package warp
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/blue-context/warp/callback"
+)
+
+// SystemMessageStrategy controls how requests with several system messages,
+// or a system message after the conversation has started, are restructured
+// before dispatch.
+//
+// Providers such as Anthropic and Gemini accept a single system prompt in a
+// dedicated field rather than system messages in the conversation.
+type SystemMessageStrategy string
+
+const (
+	// SystemMessagesProvider leaves system messages to the provider
+	// (default). Providers with a single system field concatenate them.
+	SystemMessagesProvider SystemMessageStrategy = ""
+
+	// SystemMessagesConcatenate joins all system messages, in order, into
+	// one leading system message.
+	SystemMessagesConcatenate SystemMessageStrategy = "concatenate"
+
+	// SystemMessagesFirstOnly keeps the first system message as the leading
+	// system message and drops the rest.
+	SystemMessagesFirstOnly SystemMessageStrategy = "first_only"
+
+	// SystemMessagesError rejects the request with an *InvalidRequestError.
+	SystemMessagesError SystemMessageStrategy = "error"
+)
+
+// WarningSystemMessagesRestructured is the callback.WarningEvent code raised
+// when system messages are merged, moved, or dropped.
+const WarningSystemMessagesRestructured = "system_messages_restructured"
+
+// applySystemMessageStrategy returns req with its system messages
+// restructured according to the client's SystemMessageStrategy. Requests
+// with at most one system message, in first position, are returned
+// unchanged.
+func (c *client) applySystemMessageStrategy(ctx context.Context, req *CompletionRequest, providerName string) (*CompletionRequest, error) {
+	strategy := c.config.SystemMessageStrategy
+	if strategy == SystemMessagesProvider {
+		return req, nil
+	}
+
+	var system []Message
+	for _, msg := range req.Messages {
+		if msg.Role == "system" {
+			system = append(system, msg)
+		}
+	}
+	if len(system) == 0 || (len(system) == 1 && req.Messages[0].Role == "system") {
+		return req, nil
+	}
+
+	if strategy == SystemMessagesError {
+		return nil, NewInvalidRequestError(
+			fmt.Sprintf("only a single leading system message is allowed, found %d system messages", len(system)),
+			providerName, nil)
+	}
+
+	leading := system[0]
+	warning := "moved the system message to the start of the conversation"
+	switch {
+	case len(system) == 1:
+	case strategy == SystemMessagesConcatenate:
+		texts := make([]string, len(system))
+		for i, msg := range system {
+			texts[i] = ContentToString(msg)
+		}
+		leading.Content = strings.Join(texts, "\n\n")
+		warning = fmt.Sprintf("merged %d system messages into one leading system message", len(system))
+	case strategy == SystemMessagesFirstOnly:
+		warning = fmt.Sprintf("kept the first of %d system messages and dropped the rest", len(system))
+	default:
+		return nil, fmt.Errorf("unknown system message strategy %q", strategy)
+	}
+
+	r := *req
+	r.Messages = make([]Message, 0, len(req.Messages)-len(system)+1)
+	r.Messages = append(r.Messages, leading)
+	for _, msg := range req.Messages {
+		if msg.Role != "system" {
+			r.Messages = append(r.Messages, msg)
+		}
+	}
+
+	c.warn(ctx, WarningSystemMessagesRestructured, warning)
+	return &r, nil
+}
+
+// warn executes the warning callbacks.
+func (c *client) warn(ctx context.Context, code, message string) {
+	if c.callbacks == nil {
+		return
+	}
+	c.callbacks.ExecuteWarning(ctx, &callback.WarningEvent{
+		RequestID: RequestIDFromContext(ctx),
+		Model:     ModelFromContext(ctx),
+		Provider:  ProviderFromContext(ctx),
+		Code:      code,
+		Message:   message,
+		Timestamp: c.now(),
+	})
+}
@@ -0,0 +1,87 @@
+package warp
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/blue-context/warp/callback"
+)
+
+func TestSystemMessageStrategy(t *testing.T) {
+	messages := []Message{
+		{Role: "system", Content: "Be helpful."},
+		{Role: "user", Content: "Hi"},
+		{Role: "system", Content: "Be brief."},
+	}
+
+	tests := []struct {
+		strategy    SystemMessageStrategy
+		wantSystem  any
+		wantLen     int
+		wantWarning bool
+	}{
+		{SystemMessagesProvider, "Be helpful.", 3, false},
+		{SystemMessagesConcatenate, "Be helpful.\n\nBe brief.", 2, true},
+		{SystemMessagesFirstOnly, "Be helpful.", 2, true},
+	}
+	for _, tt := range tests {
+		t.Run(string(tt.strategy), func(t *testing.T) {
+			var got *CompletionRequest
+			var warnings []*callback.WarningEvent
+			client, err := NewClient(
+				WithSystemMessageStrategy(tt.strategy),
+				WithWarningCallback(func(ctx context.Context, event *callback.WarningEvent) {
+					warnings = append(warnings, event)
+				}),
+			)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer client.Close()
+			client.RegisterProvider(&mockProvider{name: "mock", completionFunc: func(ctx context.Context, req *CompletionRequest) (*CompletionResponse, error) {
+				got = req
+				return &CompletionResponse{Choices: []Choice{{Message: Message{Role: "assistant", Content: "ok"}}}}, nil
+			}})
+
+			if _, err := client.Completion(context.Background(), &CompletionRequest{Model: "mock/m", Messages: messages}); err != nil {
+				t.Fatalf("Completion() error = %v", err)
+			}
+			if len(got.Messages) != tt.wantLen || got.Messages[0].Content != tt.wantSystem {
+				t.Errorf("messages = %+v", got.Messages)
+			}
+			if tt.wantWarning {
+				if len(warnings) != 1 || warnings[0].Code != WarningSystemMessagesRestructured || warnings[0].Provider != "mock" {
+					t.Errorf("warnings = %+v, want one restructuring warning", warnings)
+				}
+			} else if len(warnings) != 0 {
+				t.Errorf("warnings = %+v, want none", warnings)
+			}
+		})
+	}
+}
+
+func TestSystemMessageStrategyError(t *testing.T) {
+	client, err := NewClient(WithSystemMessageStrategy(SystemMessagesError))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+	client.RegisterProvider(&mockProvider{name: "mock"})
+
+	ok := []Message{{Role: "system", Content: "Be helpful."}, {Role: "user", Content: "Hi"}}
+	if _, err := client.Completion(context.Background(), &CompletionRequest{Model: "mock/m", Messages: ok}); err != nil {
+		t.Errorf("single leading system message: error = %v", err)
+	}
+
+	late := []Message{{Role: "user", Content: "Hi"}, {Role: "system", Content: "Be brief."}}
+	_, err = client.Completion(context.Background(), &CompletionRequest{Model: "mock/m", Messages: late})
+	var invalid *InvalidRequestError
+	if !errors.As(err, &invalid) {
+		t.Errorf("error = %v, want InvalidRequestError", err)
+	}
+
+	if _, err := NewClient(WithSystemMessageStrategy("shuffle")); err == nil {
+		t.Error("expected error for unknown strategy")
+	}
+}
@@ -0,0 +1,177 @@
+package warp
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/blue-context/warp/types"
+)
+
+// TextOptions configures Summarize, Title, and TranslateText.
+type TextOptions struct {
+	// Model is the completion model to use, in "provider/model-name" form.
+	// If empty, the cheapest non-deprecated completion model with known
+	// pricing listed by the client's providers whose context window fits
+	// the text is used; if there is none, Model is required.
+	Model string
+
+	// Instructions add guidance to the built-in prompt, such as "Write for
+	// a technical audience." Optional.
+	Instructions string
+
+	// MaxWords limits the length of a summary (0 lets the model decide).
+	// Only Summarize uses it.
+	MaxWords int
+}
+
+// Summarize returns a summary of text.
+//
+// Responses are cached by the client's cache, if configured, so repeated
+// calls for the same text and options do not reach the provider.
+//
+// Example:
+//
+//	summary, err := warp.Summarize(ctx, client, article, &warp.TextOptions{MaxWords: 100})
+func Summarize(ctx context.Context, client Client, text string, opts *TextOptions) (string, error) {
+	prompt := "Summarize the text provided by the user. Keep the key facts, names, and figures, " +
+		"and reply with only the summary."
+	if opts != nil && opts.MaxWords > 0 {
+		prompt += fmt.Sprintf(" Use at most %d words.", opts.MaxWords)
+	}
+	return runTextTask(ctx, client, prompt, text, opts)
+}
+
+// Title returns a short title for text, such as a conversation or
+// document, without surrounding quotes.
+//
+// Example:
+//
+//	title, err := warp.Title(ctx, client, transcript, nil)
+func Title(ctx context.Context, client Client, text string, opts *TextOptions) (string, error) {
+	prompt := "Write a concise title of at most eight words for the text provided by the user. " +
+		"Reply with only the title, without quotes or a trailing period."
+	title, err := runTextTask(ctx, client, prompt, text, opts)
+	if err != nil {
+		return "", err
+	}
+	title = strings.Trim(title, "\"'“”")
+	return strings.TrimSuffix(title, "."), nil
+}
+
+// TranslateText translates text into language, which may be a name such as
+// "German" or a code such as "de".
+//
+// Example:
+//
+//	german, err := warp.TranslateText(ctx, client, "Good morning", "German", nil)
+func TranslateText(ctx context.Context, client Client, text, language string, opts *TextOptions) (string, error) {
+	if language == "" {
+		return "", fmt.Errorf("target language is required")
+	}
+	prompt := fmt.Sprintf("Translate the text provided by the user into %s. Preserve its meaning, tone, "+
+		"and formatting, and reply with only the translation.", language)
+	return runTextTask(ctx, client, prompt, text, opts)
+}
+
+// textTaskReplyTokens is the room left for the reply when choosing a model
+// whose context window fits a text task.
+const textTaskReplyTokens = 1024
+
+// runTextTask sends text with a task prompt and returns the trimmed reply.
+func runTextTask(ctx context.Context, client Client, prompt, text string, opts *TextOptions) (string, error) {
+	if strings.TrimSpace(text) == "" {
+		return "", fmt.Errorf("text is required")
+	}
+	if opts == nil {
+		opts = &TextOptions{}
+	}
+	if opts.Instructions != "" {
+		prompt += "\n\n" + opts.Instructions
+	}
+
+	model := opts.Model
+	if model == "" {
+		selector, ok := client.(interface {
+			tokenCounter() func(text string) int
+			cheapestCompletionModel(tokens int) (string, error)
+		})
+		if !ok {
+			return "", fmt.Errorf("model is required")
+		}
+		count := selector.tokenCounter()
+		var err error
+		if model, err = selector.cheapestCompletionModel(count(prompt) + count(text) + textTaskReplyTokens); err != nil {
+			return "", err
+		}
+	}
+
+	resp, err := client.Completion(ctx, &CompletionRequest{
+		Model: model,
+		Messages: []Message{
+			{Role: "system", Content: prompt},
+			{Role: "user", Content: text},
+		},
+		Temperature: Float64Ptr(0),
+	})
+	if err != nil {
+		return "", err
+	}
+	if len(resp.Choices) == 0 {
+		return "", fmt.Errorf("response has no choices")
+	}
+	return strings.TrimSpace(messageText(resp.Choices[0].Message.Content)), nil
+}
+
+// cheapestCompletionModel returns the cheapest non-deprecated completion
+// model listed by the registered providers whose context window holds
+// tokens, in "provider/model-name" form. Cost is the sum of input and
+// output prices, including pricing registered with RegisterModelPricing;
+// models without pricing are skipped, since their cost is unknown rather
+// than zero. Ties go to the first model by name.
+func (c *client) cheapestCompletionModel(tokens int) (string, error) {
+	type modelLister interface {
+		ListModels() []*types.ModelInfo
+	}
+
+	root := c.root()
+	root.mu.RLock()
+	names := make([]string, 0, len(root.providers))
+	providers := make(map[string]Provider, len(root.providers))
+	for name, p := range root.providers {
+		names = append(names, name)
+		providers[name] = p
+	}
+	root.mu.RUnlock()
+	sort.Strings(names)
+
+	best, bestPrice := "", 0.0
+	for _, name := range names {
+		lister, ok := providers[name].(modelLister)
+		if !ok {
+			continue
+		}
+		for _, info := range lister.ListModels() {
+			if info == nil || !info.Capabilities.Completion || info.Deprecated {
+				continue
+			}
+			if info.ContextWindow > 0 && info.ContextWindow < tokens {
+				continue
+			}
+			pricing, err := c.costCalc.GetModelInfo(name, info.Name)
+			if err != nil || (pricing.InputCostPer1M <= 0 && pricing.OutputCostPer1M <= 0) {
+				continue
+			}
+			model := name + "/" + info.Name
+			price := pricing.InputCostPer1M + pricing.OutputCostPer1M
+			if best == "" || price < bestPrice || (price == bestPrice && model < best) {
+				best, bestPrice = model, price
+			}
+		}
+	}
+	if best == "" {
+		return "", fmt.Errorf("no registered completion model with known pricing fits %d tokens; set TextOptions.Model", tokens)
+	}
+	return best, nil
+}
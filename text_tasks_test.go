@@ -0,0 +1,114 @@
+package warp
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/blue-context/warp/types"
+)
+
+func TestTextTasks(t *testing.T) {
+	client, err := NewClient()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+
+	var got *CompletionRequest
+	reply := ""
+	client.RegisterProvider(&mockProvider{
+		name: "mock",
+		modelInfo: map[string]*types.ModelInfo{
+			"large":  {Name: "large", InputCostPer1M: 5, OutputCostPer1M: 15, Capabilities: types.Capabilities{Completion: true}},
+			"small":  {Name: "small", InputCostPer1M: 0.1, OutputCostPer1M: 0.4, Capabilities: types.Capabilities{Completion: true}},
+			"old":    {Name: "old", Deprecated: true, Capabilities: types.Capabilities{Completion: true}},
+			"embed":  {Name: "embed", Capabilities: types.Capabilities{Embedding: true}},
+			"narrow": {Name: "narrow", ContextWindow: 512, InputCostPer1M: 0.01, Capabilities: types.Capabilities{Completion: true}},
+			"local":  {Name: "local", Capabilities: types.Capabilities{Completion: true}},
+		},
+		completionFunc: func(ctx context.Context, req *CompletionRequest) (*CompletionResponse, error) {
+			got = req
+			return &CompletionResponse{Choices: []Choice{{Message: Message{Role: "assistant", Content: reply}}}}, nil
+		},
+	})
+	ctx := context.Background()
+
+	reply = " A short summary. "
+	summary, err := Summarize(ctx, client, "A long article.", &TextOptions{MaxWords: 50})
+	if err != nil {
+		t.Fatalf("Summarize() error = %v", err)
+	}
+	if summary != "A short summary." {
+		t.Errorf("Summarize() = %q", summary)
+	}
+	if got.Model != "small" {
+		t.Errorf("model = %q, want cheapest priced model small", got.Model)
+	}
+	if prompt := messageText(got.Messages[0].Content); !strings.Contains(prompt, "at most 50 words") {
+		t.Errorf("prompt = %q, want word limit", prompt)
+	}
+
+	reply = `"Quarterly Results Overview."`
+	title, err := Title(ctx, client, "Our revenue grew...", &TextOptions{Model: "mock/large"})
+	if err != nil {
+		t.Fatalf("Title() error = %v", err)
+	}
+	if title != "Quarterly Results Overview" || got.Model != "large" {
+		t.Errorf("Title() = %q with %s", title, got.Model)
+	}
+
+	reply = "Guten Morgen"
+	german, err := TranslateText(ctx, client, "Good morning", "German", nil)
+	if err != nil || german != "Guten Morgen" {
+		t.Errorf("TranslateText() = %q, %v", german, err)
+	}
+	if prompt := messageText(got.Messages[0].Content); !strings.Contains(prompt, "into German") {
+		t.Errorf("prompt = %q, want target language", prompt)
+	}
+
+	if _, err := TranslateText(ctx, client, "Hi", "", nil); err == nil {
+		t.Error("TranslateText() without language error = nil, want error")
+	}
+	if _, err := Summarize(ctx, client, "  ", nil); err == nil {
+		t.Error("Summarize() of empty text error = nil, want error")
+	}
+}
+
+// TestTextTasksUnpricedModels tests that models without pricing are not
+// chosen, even with pricing registered for another model
+func TestTextTasksUnpricedModels(t *testing.T) {
+	client, err := NewClient()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+
+	var got *CompletionRequest
+	client.RegisterProvider(&mockProvider{
+		name: "mock",
+		modelInfo: map[string]*types.ModelInfo{
+			"learned": {Name: "learned", Capabilities: types.Capabilities{Completion: true}},
+			"custom":  {Name: "custom", Capabilities: types.Capabilities{Completion: true}},
+		},
+		completionFunc: func(ctx context.Context, req *CompletionRequest) (*CompletionResponse, error) {
+			got = req
+			return &CompletionResponse{Choices: []Choice{{Message: Message{Role: "assistant", Content: "Hi"}}}}, nil
+		},
+	})
+	ctx := context.Background()
+
+	if _, err := Summarize(ctx, client, "A long article.", nil); err == nil || !strings.Contains(err.Error(), "TextOptions.Model") {
+		t.Errorf("Summarize() error = %v, want Model required", err)
+	}
+
+	if err := client.RegisterModelPricing("mock/custom", 1, 2); err != nil {
+		t.Fatalf("RegisterModelPricing() error = %v", err)
+	}
+	if _, err := Summarize(ctx, client, "A long article.", nil); err != nil {
+		t.Fatalf("Summarize() error = %v", err)
+	}
+	if got.Model != "custom" {
+		t.Errorf("model = %q, want the model with registered pricing", got.Model)
+	}
+}
@@ -0,0 +1,170 @@
+package warp
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strconv"
+)
+
+// ToolCallIDPolicy controls how requests whose tool call IDs would break
+// the tool-response round trip are handled before dispatch: assistant tool
+// calls without an ID or with the ID of an earlier call, and tool results
+// that do not reference a preceding call.
+type ToolCallIDPolicy string
+
+const (
+	// ToolCallIDsRepair assigns stable IDs to calls without a unique ID,
+	// points each tool result that references no call at the next
+	// unanswered call of the preceding assistant message, and drops tool
+	// results left without a call (default).
+	ToolCallIDsRepair ToolCallIDPolicy = ""
+
+	// ToolCallIDsError rejects the request with an *InvalidRequestError.
+	ToolCallIDsError ToolCallIDPolicy = "error"
+)
+
+// WarningToolCallIDsRepaired is the callback.WarningEvent code raised when
+// tool call IDs of a request are assigned or tool results re-pointed or
+// dropped.
+const WarningToolCallIDsRepaired = "tool_call_ids_repaired"
+
+// pendingCall is a tool call of the latest assistant message.
+type pendingCall struct {
+	original, id string
+	answered     bool
+}
+
+// repairToolCallIDs returns req with its tool call IDs repaired according
+// to the client's ToolCallIDPolicy. Requests with consistent IDs are
+// returned unchanged.
+func (c *client) repairToolCallIDs(ctx context.Context, req *CompletionRequest, providerName string) (*CompletionRequest, error) {
+	var r *CompletionRequest
+	var repairs []string
+	copyOnWrite := func() {
+		if r == nil {
+			copied := *req
+			copied.Messages = append([]Message(nil), req.Messages...)
+			r = &copied
+		}
+	}
+
+	seen := make(map[string]bool)
+	var pending []pendingCall
+	drop := make(map[int]bool)
+	for i, msg := range req.Messages {
+		switch msg.Role {
+		case "assistant":
+			pending = pending[:0]
+			cloned := false
+			for j, call := range msg.ToolCalls {
+				id := call.ID
+				if id == "" || seen[id] {
+					id = uniqueToolCallID(strconv.Itoa(i), j, seen)
+					if call.ID == "" {
+						repairs = append(repairs, fmt.Sprintf("message %d: assigned ID %q to tool call %d", i, id, j))
+					} else {
+						repairs = append(repairs, fmt.Sprintf("message %d: renamed duplicate tool call ID %q to %q", i, call.ID, id))
+					}
+					copyOnWrite()
+					if !cloned {
+						r.Messages[i].ToolCalls = append([]ToolCall(nil), msg.ToolCalls...)
+						cloned = true
+					}
+					r.Messages[i].ToolCalls[j].ID = id
+				}
+				seen[id] = true
+				pending = append(pending, pendingCall{original: call.ID, id: id})
+			}
+		case "tool":
+			call := matchToolCall(pending, msg.ToolCallID)
+			switch {
+			case call == nil:
+				repairs = append(repairs, fmt.Sprintf("message %d: dropped tool result %q that answers no tool call", i, msg.ToolCallID))
+				drop[i] = true
+			case call.id != msg.ToolCallID:
+				repairs = append(repairs, fmt.Sprintf("message %d: pointed tool result %q at tool call %q", i, msg.ToolCallID, call.id))
+				copyOnWrite()
+				r.Messages[i].ToolCallID = call.id
+			}
+			if call != nil {
+				call.answered = true
+			}
+		default:
+			pending = pending[:0]
+		}
+	}
+	if len(repairs) == 0 {
+		return req, nil
+	}
+
+	if c.config.ToolCallIDPolicy == ToolCallIDsError {
+		return nil, NewInvalidRequestError(fmt.Sprintf("inconsistent tool call IDs: %s", repairs[0]), providerName, nil)
+	}
+	if len(drop) > 0 {
+		messages := r.Messages[:0:0]
+		for i, msg := range r.Messages {
+			if !drop[i] {
+				messages = append(messages, msg)
+			}
+		}
+		r.Messages = messages
+	}
+	for _, repair := range repairs {
+		c.warn(ctx, WarningToolCallIDsRepaired, repair)
+	}
+	return r, nil
+}
+
+// matchToolCall returns the unanswered call a tool result with the given
+// ID answers: the call with that ID, else the call that had it before it
+// was renamed, else the first unanswered call. It returns nil if every
+// call is answered.
+func matchToolCall(pending []pendingCall, id string) *pendingCall {
+	for _, match := range []func(*pendingCall) bool{
+		func(call *pendingCall) bool { return id != "" && call.id == id },
+		func(call *pendingCall) bool { return id != "" && call.original == id },
+		func(call *pendingCall) bool { return true },
+	} {
+		for k := range pending {
+			if call := &pending[k]; !call.answered && match(call) {
+				return call
+			}
+		}
+	}
+	return nil
+}
+
+// NormalizeToolCallIDs assigns IDs to the tool calls of resp that have no
+// ID or repeat an earlier call's ID. IDs are derived from the response ID
+// and the call's position, so normalizing the same response again yields
+// the same IDs. The client normalizes every completion response; call it
+// for responses assembled elsewhere.
+func NormalizeToolCallIDs(resp *CompletionResponse) {
+	if resp == nil {
+		return
+	}
+	seen := make(map[string]bool)
+	for i := range resp.Choices {
+		calls := resp.Choices[i].Message.ToolCalls
+		for j := range calls {
+			if calls[j].ID == "" || seen[calls[j].ID] {
+				calls[j].ID = uniqueToolCallID(resp.ID+"/"+strconv.Itoa(resp.Choices[i].Index), j, seen)
+			}
+			seen[calls[j].ID] = true
+		}
+	}
+}
+
+// uniqueToolCallID returns a tool call ID derived from seed and index that
+// is not in seen.
+func uniqueToolCallID(seed string, index int, seen map[string]bool) string {
+	for n := 0; ; n++ {
+		sum := sha256.Sum256([]byte(seed + "/" + strconv.Itoa(index) + "/" + strconv.Itoa(n)))
+		id := "call_" + hex.EncodeToString(sum[:12])
+		if !seen[id] {
+			return id
+		}
+	}
+}
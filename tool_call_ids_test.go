@@ -0,0 +1,211 @@
+package warp
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/blue-context/warp/callback"
+)
+
+func toolCall(id, name string) ToolCall {
+	return ToolCall{ID: id, Type: "function", Function: FunctionCall{Name: name, Arguments: "{}"}}
+}
+
+func TestRepairToolCallIDs(t *testing.T) {
+	user := Message{Role: "user", Content: "Weather in Paris and Rome?"}
+	tests := []struct {
+		name     string
+		messages []Message
+		// want maps each tool result in the repaired request to the name
+		// of the call it answers
+		want        []string
+		wantRepairs int
+	}{
+		{
+			name: "consistent",
+			messages: []Message{user,
+				{Role: "assistant", ToolCalls: []ToolCall{toolCall("a", "paris"), toolCall("b", "rome")}},
+				{Role: "tool", ToolCallID: "b", Content: "rome"},
+				{Role: "tool", ToolCallID: "a", Content: "paris"},
+			},
+			want: []string{"rome", "paris"},
+		},
+		{
+			name: "missing IDs",
+			messages: []Message{user,
+				{Role: "assistant", ToolCalls: []ToolCall{toolCall("", "paris"), toolCall("", "rome")}},
+				{Role: "tool", Content: "paris"},
+				{Role: "tool", Content: "rome"},
+			},
+			want:        []string{"paris", "rome"},
+			wantRepairs: 4,
+		},
+		{
+			name: "duplicate IDs",
+			messages: []Message{user,
+				{Role: "assistant", ToolCalls: []ToolCall{toolCall("call_0", "paris"), toolCall("call_0", "rome")}},
+				{Role: "tool", ToolCallID: "call_0", Content: "paris"},
+				{Role: "tool", ToolCallID: "call_0", Content: "rome"},
+			},
+			want:        []string{"paris", "rome"},
+			wantRepairs: 2,
+		},
+		{
+			name: "ID repeated across turns",
+			messages: []Message{user,
+				{Role: "assistant", ToolCalls: []ToolCall{toolCall("call_0", "paris")}},
+				{Role: "tool", ToolCallID: "call_0", Content: "paris"},
+				{Role: "assistant", ToolCalls: []ToolCall{toolCall("call_0", "rome")}},
+				{Role: "tool", ToolCallID: "call_0", Content: "rome"},
+			},
+			want:        []string{"paris", "rome"},
+			wantRepairs: 2,
+		},
+		{
+			name: "unknown and orphan results",
+			messages: []Message{user,
+				{Role: "assistant", ToolCalls: []ToolCall{toolCall("a", "paris")}},
+				{Role: "tool", ToolCallID: "typo", Content: "paris"},
+				{Role: "tool", ToolCallID: "extra", Content: "extra"},
+			},
+			want:        []string{"paris"},
+			wantRepairs: 2,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for _, policy := range []ToolCallIDPolicy{ToolCallIDsRepair, ToolCallIDsError} {
+				var warnings []*callback.WarningEvent
+				client, err := NewClient(
+					WithToolCallIDPolicy(policy),
+					WithWarningCallback(func(ctx context.Context, event *callback.WarningEvent) {
+						warnings = append(warnings, event)
+					}),
+				)
+				if err != nil {
+					t.Fatal(err)
+				}
+				var got *CompletionRequest
+				client.RegisterProvider(&mockProvider{
+					name: "mock",
+					completionFunc: func(ctx context.Context, req *CompletionRequest) (*CompletionResponse, error) {
+						got = req
+						return &CompletionResponse{ID: "test"}, nil
+					},
+				})
+
+				original := cloneMessages(tt.messages)
+				_, err = client.Completion(context.Background(), &CompletionRequest{Model: "mock/m", Messages: tt.messages})
+				client.Close()
+				if !reflect.DeepEqual(tt.messages, original) {
+					t.Errorf("%s: caller's messages were modified", policy)
+				}
+
+				if policy == ToolCallIDsError {
+					var invalid *InvalidRequestError
+					if (tt.wantRepairs > 0) != errors.As(err, &invalid) {
+						t.Errorf("%s: Completion() error = %v, want error %v", policy, err, tt.wantRepairs > 0)
+					}
+					continue
+				}
+				if err != nil {
+					t.Fatalf("Completion() error = %v", err)
+				}
+				if len(warnings) != tt.wantRepairs {
+					t.Errorf("warnings = %d, want %d", len(warnings), tt.wantRepairs)
+				}
+
+				// Every call ID is unique, and every result answers a call
+				calls := make(map[string]string)
+				var answered []string
+				for _, msg := range got.Messages {
+					for _, call := range msg.ToolCalls {
+						if call.ID == "" || calls[call.ID] != "" {
+							t.Errorf("tool call ID %q is empty or repeated", call.ID)
+						}
+						calls[call.ID] = call.Function.Name
+					}
+					if msg.Role == "tool" {
+						answered = append(answered, calls[msg.ToolCallID])
+					}
+				}
+				if !reflect.DeepEqual(answered, tt.want) {
+					t.Errorf("tool results answer %q, want %q", answered, tt.want)
+				}
+			}
+		})
+	}
+}
+
+// cloneMessages returns a deep copy of the tool call fields of messages.
+func cloneMessages(messages []Message) []Message {
+	out := append([]Message(nil), messages...)
+	for i := range out {
+		out[i].ToolCalls = append([]ToolCall(nil), out[i].ToolCalls...)
+	}
+	return out
+}
+
+func TestNormalizeToolCallIDs(t *testing.T) {
+	newResp := func() *CompletionResponse {
+		return &CompletionResponse{ID: "resp_1", Choices: []Choice{
+			{Index: 0, Message: Message{ToolCalls: []ToolCall{toolCall("", "a"), toolCall("x", "b"), toolCall("x", "c")}}},
+			{Index: 1, Message: Message{ToolCalls: []ToolCall{toolCall("", "a")}}},
+		}}
+	}
+	resp := newResp()
+	NormalizeToolCallIDs(resp)
+
+	seen := make(map[string]bool)
+	for _, choice := range resp.Choices {
+		for _, call := range choice.Message.ToolCalls {
+			if call.ID == "" || seen[call.ID] {
+				t.Errorf("tool call ID %q is empty or repeated", call.ID)
+			}
+			seen[call.ID] = true
+		}
+	}
+	if resp.Choices[0].Message.ToolCalls[1].ID != "x" {
+		t.Error("unique ID was replaced")
+	}
+
+	again := newResp()
+	NormalizeToolCallIDs(again)
+	if !reflect.DeepEqual(again, resp) {
+		t.Error("IDs are not stable")
+	}
+	NormalizeToolCallIDs(nil)
+}
+
+func TestCompletionNormalizesToolCallIDs(t *testing.T) {
+	client, err := NewClient()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+	client.RegisterProvider(&mockProvider{
+		name: "mock",
+		completionFunc: func(ctx context.Context, req *CompletionRequest) (*CompletionResponse, error) {
+			return &CompletionResponse{ID: "test", Choices: []Choice{{Message: Message{
+				Role:      "assistant",
+				ToolCalls: []ToolCall{toolCall("", "lookup")},
+			}}}}, nil
+		},
+	})
+
+	resp, err := client.Completion(context.Background(), &CompletionRequest{Model: "mock/m", Messages: []Message{{Role: "user", Content: "Hi"}}})
+	if err != nil {
+		t.Fatalf("Completion() error = %v", err)
+	}
+	if id := resp.Choices[0].Message.ToolCalls[0].ID; id == "" {
+		t.Error("tool call ID is empty")
+	}
+}
+
+func TestWithToolCallIDPolicyInvalid(t *testing.T) {
+	if _, err := NewClient(WithToolCallIDPolicy("ignore")); err == nil {
+		t.Error("NewClient(WithToolCallIDPolicy(\"ignore\")) error = nil")
+	}
+}
@@ -0,0 +1,219 @@
+package warp
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/blue-context/warp/types"
+)
+
+// toolEmulationPrompt introduces the tool descriptions injected into the
+// system prompt of emulated requests.
+const toolEmulationPrompt = `You can call the following tools. Each is described by its name, purpose, and a JSON Schema for its arguments.
+
+%s
+
+To call tools, reply with only a JSON object in this exact form and no other text:
+{"tool_calls": [{"name": "<tool name>", "arguments": {<arguments>}}]}
+
+Tool results will be sent back to you in the next message.`
+
+// emulatedToolCalls is the JSON block models reply with to call tools.
+type emulatedToolCalls struct {
+	ToolCalls []emulatedToolCall `json:"tool_calls"`
+}
+
+// emulatedToolCall is a single call in an emulatedToolCalls block.
+type emulatedToolCall struct {
+	Name      string          `json:"name"`
+	Arguments json.RawMessage `json:"arguments"`
+}
+
+// needsToolEmulation reports whether req must be emulated for p: tool
+// emulation is enabled, req has tools, and the model lacks native tool
+// calling. A ProbeCapabilities result for the model takes precedence over
+// the provider's declared capabilities.
+func (c *client) needsToolEmulation(p Provider, req *CompletionRequest) bool {
+	if !c.config.ToolEmulation || len(req.Tools) == 0 {
+		return false
+	}
+	if result, ok := c.ProbedCapabilities(p.Name() + "/" + req.Model); ok {
+		return !result.Tools
+	}
+	caps, ok := p.Supports().(types.Capabilities)
+	return ok && !caps.FunctionCalling
+}
+
+// emulateToolCalling returns a copy of req with its tools described in the system
+// prompt instead of sent natively.
+//
+// Earlier assistant tool calls are rewritten as the JSON block the model is
+// asked to produce, and tool results as user messages, since providers
+// without tool support typically reject both. A "none" tool choice drops
+// the tools; "required" or a specific function is stated in the prompt.
+func emulateToolCalling(req *CompletionRequest) (*CompletionRequest, error) {
+	r := *req
+	r.Tools = nil
+	r.ToolChoice = nil
+
+	messages := make([]Message, 0, len(req.Messages)+1)
+	names := make(map[string]string) // tool call ID -> function name
+	for _, msg := range req.Messages {
+		switch {
+		case msg.Role == "assistant" && len(msg.ToolCalls) > 0:
+			block := emulatedToolCalls{ToolCalls: make([]emulatedToolCall, len(msg.ToolCalls))}
+			for i, call := range msg.ToolCalls {
+				names[call.ID] = call.Function.Name
+				args := json.RawMessage(call.Function.Arguments)
+				if !json.Valid(args) {
+					args, _ = json.Marshal(call.Function.Arguments)
+				}
+				block.ToolCalls[i] = emulatedToolCall{Name: call.Function.Name, Arguments: args}
+			}
+			data, err := json.Marshal(block)
+			if err != nil {
+				return nil, fmt.Errorf("failed to encode tool calls: %w", err)
+			}
+			messages = append(messages, Message{Role: "assistant", Content: string(data)})
+		case msg.Role == "tool":
+			name := names[msg.ToolCallID]
+			if name == "" {
+				name = msg.Name
+			}
+			messages = append(messages, Message{
+				Role:    "user",
+				Content: fmt.Sprintf("Result of tool %q:\n%s", name, messageText(msg.Content)),
+			})
+		default:
+			messages = append(messages, msg)
+		}
+	}
+
+	if req.ToolChoice == nil || req.ToolChoice.Type != "none" || req.ToolChoice.Function != nil {
+		prompt, err := toolPrompt(req.Tools, req.ToolChoice)
+		if err != nil {
+			return nil, err
+		}
+		if len(messages) > 0 && messages[0].Role == "system" {
+			messages[0].Content = messageText(messages[0].Content) + "\n\n" + prompt
+		} else {
+			messages = append([]Message{{Role: "system", Content: prompt}}, messages...)
+		}
+	}
+	r.Messages = messages
+
+	return &r, nil
+}
+
+// toolPrompt describes tools and the reply format for the system prompt.
+func toolPrompt(tools []Tool, choice *ToolChoice) (string, error) {
+	var sb strings.Builder
+	for _, tool := range tools {
+		schema, err := json.Marshal(tool.Function.Parameters)
+		if err != nil {
+			return "", fmt.Errorf("failed to encode parameters of tool %q: %w", tool.Function.Name, err)
+		}
+		fmt.Fprintf(&sb, "- %s: %s\n  Arguments schema: %s\n", tool.Function.Name, tool.Function.Description, schema)
+	}
+
+	prompt := fmt.Sprintf(toolEmulationPrompt, strings.TrimRight(sb.String(), "\n"))
+	switch {
+	case choice != nil && choice.Function != nil:
+		prompt += fmt.Sprintf("\n\nYou must call the %q tool now.", choice.Function.Name)
+	case choice != nil && choice.Type == "required":
+		prompt += "\n\nYou must call at least one tool now."
+	default:
+		prompt += "\n\nIf no tool is needed, reply normally."
+	}
+	return prompt, nil
+}
+
+// restoreToolCalls converts emulated tool call blocks in resp back into
+// ToolCalls, setting FinishReason to "tool_calls". Calls to tools not in
+// tools are left as text.
+func restoreToolCalls(resp *CompletionResponse, tools []Tool) {
+	known := make(map[string]bool, len(tools))
+	for _, tool := range tools {
+		known[tool.Function.Name] = true
+	}
+
+	for i := range resp.Choices {
+		choice := &resp.Choices[i]
+		calls, ok := parseEmulatedToolCalls(messageText(choice.Message.Content), known)
+		if !ok {
+			continue
+		}
+		choice.Message.ToolCalls = calls
+		choice.Message.Content = ""
+		choice.FinishReason = "tool_calls"
+	}
+}
+
+// parseEmulatedToolCalls finds the first JSON object in text that is a valid
+// tool call block, tolerating surrounding prose and code fences. A bare
+// {"name": ..., "arguments": ...} object is accepted as a single call.
+func parseEmulatedToolCalls(text string, known map[string]bool) ([]ToolCall, bool) {
+	for start := strings.IndexByte(text, '{'); start >= 0; {
+		dec := json.NewDecoder(strings.NewReader(text[start:]))
+		var raw json.RawMessage
+		if dec.Decode(&raw) == nil {
+			var block emulatedToolCalls
+			if json.Unmarshal(raw, &block) != nil || len(block.ToolCalls) == 0 {
+				var single emulatedToolCall
+				if json.Unmarshal(raw, &single) == nil && single.Name != "" {
+					block.ToolCalls = []emulatedToolCall{single}
+				}
+			}
+			if calls, ok := toToolCalls(block.ToolCalls, known); ok {
+				return calls, true
+			}
+		}
+
+		next := strings.IndexByte(text[start+1:], '{')
+		if next < 0 {
+			break
+		}
+		start += next + 1
+	}
+	return nil, false
+}
+
+// toToolCalls converts emulated calls to ToolCalls with generated IDs. It
+// fails if there are no calls or any names an unknown tool.
+func toToolCalls(emulated []emulatedToolCall, known map[string]bool) ([]ToolCall, bool) {
+	if len(emulated) == 0 {
+		return nil, false
+	}
+
+	calls := make([]ToolCall, len(emulated))
+	for i, call := range emulated {
+		if !known[call.Name] {
+			return nil, false
+		}
+		args := string(call.Arguments)
+		if args == "" || args == "null" {
+			args = "{}"
+		}
+		// Arguments sent as a JSON string are unwrapped
+		var s string
+		if json.Unmarshal(call.Arguments, &s) == nil && json.Valid([]byte(s)) {
+			args = s
+		}
+		calls[i] = ToolCall{
+			ID:       newToolCallID(),
+			Type:     "function",
+			Function: FunctionCall{Name: call.Name, Arguments: args},
+		}
+	}
+	return calls, true
+}
+
+// newToolCallID generates an ID for an emulated tool call.
+func newToolCallID() string {
+	b := make([]byte, 12)
+	_, _ = rand.Read(b)
+	return "call_" + hex.EncodeToString(b)
+}
@@ -0,0 +1,165 @@
+package warp
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/blue-context/warp/types"
+)
+
+// toollessProvider is a mockProvider declaring no tool calling support.
+type toollessProvider struct {
+	mockProvider
+}
+
+func (p *toollessProvider) Supports() interface{} {
+	return types.Capabilities{Completion: true}
+}
+
+var weatherTool = Tool{Type: "function", Function: Function{
+	Name:        "get_weather",
+	Description: "Returns the weather for a city.",
+	Parameters:  map[string]any{"type": "object", "properties": map[string]any{"city": map[string]any{"type": "string"}}},
+}}
+
+func TestToolEmulation(t *testing.T) {
+	var got *CompletionRequest
+	reply := "Sure.\n```json\n{\"tool_calls\": [{\"name\": \"get_weather\", \"arguments\": {\"city\": \"Paris\"}}]}\n```"
+	client, err := NewClient(WithToolEmulation(true), WithMaxRetries(0))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+	client.RegisterProvider(&toollessProvider{mockProvider{name: "local", completionFunc: func(ctx context.Context, req *CompletionRequest) (*CompletionResponse, error) {
+		got = req
+		return &CompletionResponse{Choices: []Choice{{Message: Message{Role: "assistant", Content: reply}, FinishReason: "stop"}}}, nil
+	}}})
+
+	resp, err := client.Completion(context.Background(), &CompletionRequest{
+		Model: "local/llama",
+		Messages: []Message{
+			{Role: "user", Content: "Weather in Paris and Rome?"},
+			{Role: "assistant", ToolCalls: []ToolCall{{ID: "call_1", Type: "function", Function: FunctionCall{Name: "get_weather", Arguments: `{"city":"Rome"}`}}}},
+			{Role: "tool", ToolCallID: "call_1", Content: "sunny"},
+		},
+		Tools: []Tool{weatherTool},
+	})
+	if err != nil {
+		t.Fatalf("Completion() error = %v", err)
+	}
+
+	if len(got.Tools) != 0 || got.ToolChoice != nil {
+		t.Error("tools were sent natively")
+	}
+	if len(got.Messages) != 4 || got.Messages[0].Role != "system" || !strings.Contains(got.Messages[0].Content.(string), "get_weather") {
+		t.Fatalf("messages = %+v, want tool prompt then conversation", got.Messages)
+	}
+	if content := got.Messages[2].Content.(string); len(got.Messages[2].ToolCalls) != 0 || !strings.Contains(content, `"city":"Rome"`) {
+		t.Errorf("assistant tool call = %+v, want JSON block", got.Messages[2])
+	}
+	if got.Messages[3].Role != "user" || !strings.Contains(got.Messages[3].Content.(string), "sunny") {
+		t.Errorf("tool result = %+v, want user message", got.Messages[3])
+	}
+
+	choice := resp.Choices[0]
+	if choice.FinishReason != "tool_calls" || len(choice.Message.ToolCalls) != 1 {
+		t.Fatalf("choice = %+v, want one tool call", choice)
+	}
+	call := choice.Message.ToolCalls[0]
+	if call.Function.Name != "get_weather" || call.Function.Arguments != `{"city": "Paris"}` || !strings.HasPrefix(call.ID, "call_") {
+		t.Errorf("tool call = %+v", call)
+	}
+}
+
+func TestToolEmulationSkipped(t *testing.T) {
+	var got *CompletionRequest
+	capture := func(ctx context.Context, req *CompletionRequest) (*CompletionResponse, error) {
+		got = req
+		return &CompletionResponse{Choices: []Choice{{Message: Message{Role: "assistant", Content: "ok"}}}}, nil
+	}
+	req := &CompletionRequest{Messages: []Message{{Role: "user", Content: "hi"}}, Tools: []Tool{weatherTool}}
+
+	tests := []struct {
+		name     string
+		emulate  bool
+		provider Provider
+	}{
+		{"disabled", false, &toollessProvider{mockProvider{name: "p", completionFunc: capture}}},
+		{"capabilities unknown", true, &mockProvider{name: "p", completionFunc: capture}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client, err := NewClient(WithToolEmulation(tt.emulate))
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer client.Close()
+			client.RegisterProvider(tt.provider)
+
+			r := *req
+			r.Model = "p/m"
+			if _, err := client.Completion(context.Background(), &r); err != nil {
+				t.Fatal(err)
+			}
+			if len(got.Tools) != 1 {
+				t.Error("tools were not sent natively")
+			}
+		})
+	}
+}
+
+func TestParseEmulatedToolCalls(t *testing.T) {
+	known := map[string]bool{"get_weather": true}
+	tests := []struct {
+		name string
+		text string
+		want string // arguments of the single call, or "" for none
+	}{
+		{"block", `{"tool_calls":[{"name":"get_weather","arguments":{"city":"Oslo"}}]}`, `{"city":"Oslo"}`},
+		{"bare call", `Calling: {"name":"get_weather","arguments":{"city":"Oslo"}}`, `{"city":"Oslo"}`},
+		{"string arguments", `{"tool_calls":[{"name":"get_weather","arguments":"{\"city\":\"Oslo\"}"}]}`, `{"city":"Oslo"}`},
+		{"no arguments", `{"tool_calls":[{"name":"get_weather"}]}`, `{}`},
+		{"unknown tool", `{"tool_calls":[{"name":"delete_all","arguments":{}}]}`, ""},
+		{"plain text", `It is sunny {probably}.`, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			calls, ok := parseEmulatedToolCalls(tt.text, known)
+			if tt.want == "" {
+				if ok {
+					t.Errorf("parsed %+v, want no calls", calls)
+				}
+				return
+			}
+			if !ok || len(calls) != 1 || calls[0].Function.Arguments != tt.want {
+				t.Errorf("calls = %+v, want arguments %s", calls, tt.want)
+			}
+		})
+	}
+}
+
+func TestEmulateToolCallingChoice(t *testing.T) {
+	req := &CompletionRequest{
+		Messages:   []Message{{Role: "system", Content: "Be terse."}, {Role: "user", Content: "hi"}},
+		Tools:      []Tool{weatherTool},
+		ToolChoice: &ToolChoice{Type: "required"},
+	}
+	got, err := emulateToolCalling(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	system := got.Messages[0].Content.(string)
+	if len(got.Messages) != 2 || !strings.HasPrefix(system, "Be terse.") || !strings.Contains(system, "must call at least one tool") {
+		t.Errorf("system = %q", system)
+	}
+	if req.Messages[0].Content != "Be terse." {
+		t.Error("caller's messages were modified")
+	}
+
+	req.ToolChoice = &ToolChoice{Type: "none"}
+	got, _ = emulateToolCalling(req)
+	if got.Messages[0].Content != "Be terse." {
+		t.Errorf("system = %q, want unchanged for tool choice none", got.Messages[0].Content)
+	}
+}
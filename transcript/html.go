@@ -0,0 +1,160 @@
+package transcript
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"io"
+	"strings"
+
+	"github.com/blue-context/warp"
+)
+
+// htmlMessage is a message prepared for the HTML template.
+type htmlMessage struct {
+	Role       string
+	Name       string
+	Text       string
+	Images     []string
+	ToolCalls  []htmlToolCall
+	ToolCallID string
+	Turn       *Turn
+}
+
+// htmlToolCall is a tool call with pretty-printed arguments.
+type htmlToolCall struct {
+	ID        string
+	Name      string
+	Arguments string
+}
+
+// htmlPage is the data passed to the HTML template.
+type htmlPage struct {
+	Title     string
+	Model     string
+	Messages  []htmlMessage
+	Tools     []string
+	Usage     warp.Usage
+	Cost      float64
+	ShowTotal bool
+}
+
+// WriteHTML writes a conversation as a self-contained HTML transcript.
+//
+// Tool calls are shown with their arguments, tool results are linked to
+// their calls, and each model response is annotated with its token usage
+// and cost. All content is HTML-escaped.
+func WriteHTML(w io.Writer, conv *Conversation) error {
+	if conv == nil {
+		return fmt.Errorf("conversation cannot be nil")
+	}
+
+	page := htmlPage{
+		Title:     conv.Title,
+		Model:     conv.Model,
+		Usage:     conv.TotalUsage(),
+		Cost:      conv.TotalCost(),
+		ShowTotal: len(conv.Turns) > 0,
+	}
+	if page.Title == "" {
+		page.Title = "Conversation"
+		if conv.ID != "" {
+			page.Title += " " + conv.ID
+		}
+	}
+	for _, t := range conv.Tools {
+		page.Tools = append(page.Tools, t.Function.Name)
+	}
+
+	turns := make(map[int]*Turn, len(conv.Turns))
+	for i := range conv.Turns {
+		turns[conv.Turns[i].Message] = &conv.Turns[i]
+	}
+
+	for i, msg := range conv.Messages {
+		hm := htmlMessage{Role: msg.Role, Name: msg.Name, ToolCallID: msg.ToolCallID, Turn: turns[i]}
+		switch content := msg.Content.(type) {
+		case string:
+			hm.Text = content
+		case []warp.ContentPart:
+			for _, part := range content {
+				if part.Type == "image_url" && part.ImageURL != nil {
+					hm.Images = append(hm.Images, part.ImageURL.URL)
+				} else if part.Text != "" {
+					hm.Text += part.Text
+				}
+			}
+		}
+		for _, tc := range msg.ToolCalls {
+			hm.ToolCalls = append(hm.ToolCalls, htmlToolCall{
+				ID:        tc.ID,
+				Name:      tc.Function.Name,
+				Arguments: prettyJSON(tc.Function.Arguments),
+			})
+		}
+		page.Messages = append(page.Messages, hm)
+	}
+
+	if err := htmlTemplate.Execute(w, page); err != nil {
+		return fmt.Errorf("failed to render transcript: %w", err)
+	}
+	return nil
+}
+
+// prettyJSON indents JSON, returning s unchanged if it is not valid JSON.
+func prettyJSON(s string) string {
+	var buf bytes.Buffer
+	if err := json.Indent(&buf, []byte(s), "", "  "); err != nil {
+		return s
+	}
+	return buf.String()
+}
+
+// imageURL trusts image data URIs, which html/template would otherwise
+// reject. Other URLs are left to the template's own sanitization.
+func imageURL(s string) any {
+	if strings.HasPrefix(s, "data:image/") {
+		return template.URL(s)
+	}
+	return s
+}
+
+// htmlTemplate renders a transcript page.
+var htmlTemplate = template.Must(template.New("transcript").Funcs(template.FuncMap{
+	"usd": func(v float64) string { return fmt.Sprintf("$%.6f", v) },
+	"url": imageURL,
+}).Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>{{.Title}}</title>
+<style>
+body{font-family:system-ui,sans-serif;max-width:860px;margin:2em auto;padding:0 1em;color:#222}
+header{border-bottom:1px solid #ddd;margin-bottom:1em}
+.msg{border-radius:8px;padding:.75em 1em;margin:.75em 0;background:#f6f6f6}
+.msg.system{background:#fff8e1}.msg.user{background:#e8f0fe}.msg.assistant{background:#f1f8e9}.msg.tool{background:#f3e5f5}
+.role{font-weight:600;font-size:.85em;text-transform:uppercase;color:#555}
+.text{white-space:pre-wrap}
+pre{background:#fff;border:1px solid #ddd;border-radius:4px;padding:.5em;overflow-x:auto}
+.meta{font-size:.8em;color:#777}
+img{max-width:100%}
+</style>
+</head>
+<body>
+<header>
+<h1>{{.Title}}</h1>
+{{if .Model}}<p class="meta">Model: {{.Model}}</p>{{end}}
+{{if .Tools}}<p class="meta">Tools: {{range $i, $t := .Tools}}{{if $i}}, {{end}}{{$t}}{{end}}</p>{{end}}
+{{if .ShowTotal}}<p class="meta">Total: {{.Usage.PromptTokens}} prompt + {{.Usage.CompletionTokens}} completion tokens, {{usd .Cost}}</p>{{end}}
+</header>
+{{range .Messages}}<div class="msg {{.Role}}">
+<div class="role">{{.Role}}{{if .Name}} ({{.Name}}){{end}}{{if .ToolCallID}} &rarr; {{.ToolCallID}}{{end}}</div>
+{{if .Text}}<div class="text">{{.Text}}</div>{{end}}
+{{range .Images}}<img src="{{url .}}" alt="image">{{end}}
+{{range .ToolCalls}}<details open><summary>Tool call <code>{{.Name}}</code>{{if .ID}} <span class="meta">{{.ID}}</span>{{end}}</summary><pre>{{.Arguments}}</pre></details>{{end}}
+{{with .Turn}}<div class="meta">{{with .Usage}}{{.PromptTokens}} prompt + {{.CompletionTokens}} completion tokens{{end}}{{if .Cost}} &middot; {{usd .Cost}}{{end}}</div>{{end}}
+</div>
+{{end}}</body>
+</html>
+`))
@@ -0,0 +1,320 @@
+// Package transcript exports and imports conversations.
+//
+// Three formats are supported:
+//
+//   - OpenAI fine-tuning JSONL: one {"messages": [...], "tools": [...]} object
+//     per line, ready for dataset creation.
+//   - JSON: a full-fidelity Conversation including usage and costs,
+//     optionally encrypted at rest with WriteEncrypted.
+//   - HTML: a self-contained, readable transcript for debugging, showing tool
+//     calls, tool results, token usage, and costs.
+//
+// A Tree keeps every branch of a conversation whose responses were
+// regenerated or whose messages were edited and resent, for chat UIs that
+// persist full histories; any branch can be exported as a Conversation.
+//
+// Basic usage:
+//
+//	conv := &transcript.Conversation{Model: req.Model, Messages: req.Messages}
+//	resp, err := client.Completion(ctx, req)
+//	if err != nil {
+//	    return err
+//	}
+//	cost, _ := client.CompletionCost(resp)
+//	conv.AddResponse(resp, cost)
+//
+//	err = transcript.WriteHTML(file, conv)
+package transcript
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"github.com/blue-context/warp"
+	"github.com/blue-context/warp/encrypt"
+)
+
+// encryptionLabel is authenticated with encrypted conversations so other
+// sealed data cannot be read as a conversation.
+var encryptionLabel = []byte("warp/transcript")
+
+// Conversation is a recorded exchange with a model.
+type Conversation struct {
+	// ID identifies the conversation.
+	ID string `json:"id,omitempty"`
+
+	// Title is a human-readable title, used as the HTML page title.
+	Title string `json:"title,omitempty"`
+
+	// Model is the model the conversation was held with.
+	Model string `json:"model,omitempty"`
+
+	// Messages is the conversation, including tool calls and results.
+	Messages []warp.Message `json:"messages"`
+
+	// Tools lists the tools offered to the model.
+	Tools []warp.Tool `json:"tools,omitempty"`
+
+	// Turns records usage and cost for model responses.
+	Turns []Turn `json:"turns,omitempty"`
+
+	// Metadata contains arbitrary key-value pairs.
+	Metadata map[string]any `json:"metadata,omitempty"`
+}
+
+// Turn records the usage and cost of one model response.
+type Turn struct {
+	// Message is the index in Messages of the assistant message produced.
+	Message int `json:"message"`
+
+	// Usage is the token usage reported for the response.
+	Usage *warp.Usage `json:"usage,omitempty"`
+
+	// Cost is the response cost in USD.
+	Cost float64 `json:"cost,omitempty"`
+}
+
+// AddResponse appends the first choice of resp to the conversation and
+// records its usage and cost (in USD, e.g. from Client.CompletionCost).
+//
+// Model is set from resp if empty.
+func (c *Conversation) AddResponse(resp *warp.CompletionResponse, cost float64) {
+	if resp == nil || len(resp.Choices) == 0 {
+		return
+	}
+
+	msg := resp.Choices[0].Message
+	if msg.Role == "" {
+		msg.Role = "assistant"
+	}
+	c.Messages = append(c.Messages, msg)
+	c.Turns = append(c.Turns, Turn{Message: len(c.Messages) - 1, Usage: resp.Usage, Cost: cost})
+
+	if c.Model == "" {
+		c.Model = resp.Model
+	}
+}
+
+// TotalCost returns the sum of all turn costs in USD.
+func (c *Conversation) TotalCost() float64 {
+	total := 0.0
+	for _, t := range c.Turns {
+		total += t.Cost
+	}
+	return total
+}
+
+// TotalUsage returns the sum of all turn token usage.
+func (c *Conversation) TotalUsage() warp.Usage {
+	var total warp.Usage
+	for _, t := range c.Turns {
+		if t.Usage == nil {
+			continue
+		}
+		total.PromptTokens += t.Usage.PromptTokens
+		total.CompletionTokens += t.Usage.CompletionTokens
+		total.TotalTokens += t.Usage.TotalTokens
+	}
+	return total
+}
+
+// fineTuneExample is one line of an OpenAI fine-tuning dataset.
+type fineTuneExample struct {
+	Messages []fineTuneMessage `json:"messages"`
+	Tools    []warp.Tool       `json:"tools,omitempty"`
+}
+
+// fineTuneMessage is a message in an OpenAI fine-tuning dataset. It holds
+// only the fields the format accepts; OpenAI rejects files with others,
+// such as warp.Message's metadata and reasoning_content.
+type fineTuneMessage struct {
+	Role       string          `json:"role"`
+	Content    any             `json:"content"`
+	Name       string          `json:"name,omitempty"`
+	ToolCalls  []warp.ToolCall `json:"tool_calls,omitempty"`
+	ToolCallID string          `json:"tool_call_id,omitempty"`
+}
+
+// toFineTuneMessages converts messages to fine-tuning messages.
+func toFineTuneMessages(messages []warp.Message) []fineTuneMessage {
+	out := make([]fineTuneMessage, len(messages))
+	for i, msg := range messages {
+		out[i] = fineTuneMessage{
+			Role:       msg.Role,
+			Content:    msg.Content,
+			Name:       msg.Name,
+			ToolCalls:  msg.ToolCalls,
+			ToolCallID: msg.ToolCallID,
+		}
+	}
+	return out
+}
+
+// fromFineTuneMessages converts fine-tuning messages to messages.
+func fromFineTuneMessages(messages []fineTuneMessage) []warp.Message {
+	if messages == nil {
+		return nil
+	}
+	out := make([]warp.Message, len(messages))
+	for i, msg := range messages {
+		out[i] = warp.Message{
+			Role:       msg.Role,
+			Content:    msg.Content,
+			Name:       msg.Name,
+			ToolCalls:  msg.ToolCalls,
+			ToolCallID: msg.ToolCallID,
+		}
+	}
+	return out
+}
+
+// WriteJSONL writes conversations in OpenAI fine-tuning JSONL format, one
+// conversation per line.
+//
+// Only messages and tools are written; usage, costs, and metadata are not
+// part of the format. Messages keep their role, content, name, tool calls,
+// and tool call ID; message metadata and reasoning content are dropped.
+//
+// Example:
+//
+//	f, err := os.Create("train.jsonl")
+//	if err != nil {
+//	    return err
+//	}
+//	defer f.Close()
+//	err = transcript.WriteJSONL(f, conversations...)
+func WriteJSONL(w io.Writer, conversations ...*Conversation) error {
+	enc := json.NewEncoder(w)
+	enc.SetEscapeHTML(false)
+	for i, conv := range conversations {
+		if conv == nil {
+			return fmt.Errorf("conversation %d is nil", i)
+		}
+		if err := enc.Encode(fineTuneExample{Messages: toFineTuneMessages(conv.Messages), Tools: conv.Tools}); err != nil {
+			return fmt.Errorf("failed to encode conversation %d: %w", i, err)
+		}
+	}
+	return nil
+}
+
+// ReadJSONL reads conversations from OpenAI fine-tuning JSONL. Blank lines
+// are skipped.
+func ReadJSONL(r io.Reader) ([]*Conversation, error) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 64*1024*1024)
+
+	var conversations []*Conversation
+	line := 0
+	for scanner.Scan() {
+		line++
+		data := bytes.TrimSpace(scanner.Bytes())
+		if len(data) == 0 {
+			continue
+		}
+
+		var example fineTuneExample
+		if err := json.Unmarshal(data, &example); err != nil {
+			return nil, fmt.Errorf("line %d: failed to decode conversation: %w", line, err)
+		}
+		conv := &Conversation{Messages: fromFineTuneMessages(example.Messages), Tools: example.Tools}
+		if err := normalizeContent(conv.Messages); err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+		conversations = append(conversations, conv)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read JSONL: %w", err)
+	}
+
+	return conversations, nil
+}
+
+// WriteJSON writes a conversation as indented JSON.
+func WriteJSON(w io.Writer, conv *Conversation) error {
+	if conv == nil {
+		return fmt.Errorf("conversation cannot be nil")
+	}
+	enc := json.NewEncoder(w)
+	enc.SetEscapeHTML(false)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(conv); err != nil {
+		return fmt.Errorf("failed to encode conversation: %w", err)
+	}
+	return nil
+}
+
+// ReadJSON reads a conversation written by WriteJSON.
+func ReadJSON(r io.Reader) (*Conversation, error) {
+	var conv Conversation
+	if err := json.NewDecoder(r).Decode(&conv); err != nil {
+		return nil, fmt.Errorf("failed to decode conversation: %w", err)
+	}
+	if err := normalizeContent(conv.Messages); err != nil {
+		return nil, err
+	}
+	return &conv, nil
+}
+
+// WriteEncrypted writes a conversation as JSON encrypted with the current
+// key from keys, for persisting conversations without storing prompts in
+// plaintext.
+//
+// Example:
+//
+//	keys, _ := encrypt.NewKeyRing("v1", key)
+//	err := transcript.WriteEncrypted(ctx, file, conv, keys)
+func WriteEncrypted(ctx context.Context, w io.Writer, conv *Conversation, keys encrypt.KeyProvider) error {
+	var buf bytes.Buffer
+	if err := WriteJSON(&buf, conv); err != nil {
+		return err
+	}
+
+	sealed, err := encrypt.Seal(ctx, keys, buf.Bytes(), encryptionLabel)
+	if err != nil {
+		return fmt.Errorf("failed to encrypt conversation: %w", err)
+	}
+	if _, err := w.Write(sealed); err != nil {
+		return fmt.Errorf("failed to write conversation: %w", err)
+	}
+	return nil
+}
+
+// ReadEncrypted reads a conversation written by WriteEncrypted, using the
+// key it was written with.
+func ReadEncrypted(ctx context.Context, r io.Reader, keys encrypt.KeyProvider) (*Conversation, error) {
+	sealed, err := io.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read conversation: %w", err)
+	}
+
+	data, err := encrypt.Open(ctx, keys, sealed, encryptionLabel)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt conversation: %w", err)
+	}
+	return ReadJSON(bytes.NewReader(data))
+}
+
+// normalizeContent converts decoded multimodal content ([]any) back into
+// []warp.ContentPart so it matches messages built in code.
+func normalizeContent(messages []warp.Message) error {
+	for i := range messages {
+		parts, ok := messages[i].Content.([]any)
+		if !ok {
+			continue
+		}
+		data, err := json.Marshal(parts)
+		if err != nil {
+			return fmt.Errorf("message %d: invalid content: %w", i, err)
+		}
+		var content []warp.ContentPart
+		if err := json.Unmarshal(data, &content); err != nil {
+			return fmt.Errorf("message %d: invalid content: %w", i, err)
+		}
+		messages[i].Content = content
+	}
+	return nil
+}
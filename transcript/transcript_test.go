@@ -0,0 +1,215 @@
+package transcript
+
+import (
+	"bytes"
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/blue-context/warp"
+	"github.com/blue-context/warp/encrypt"
+)
+
+// testConversation returns a conversation with a tool call and two turns.
+func testConversation() *Conversation {
+	conv := &Conversation{
+		ID: "conv_1",
+		Messages: []warp.Message{
+			{Role: "system", Content: "You are helpful."},
+			{Role: "user", Content: []warp.ContentPart{
+				{Type: "text", Text: "What's the weather in <Paris>?"},
+				{Type: "image_url", ImageURL: &warp.ImageURL{URL: "data:image/png;base64,AAAA"}},
+			}},
+		},
+		Tools: []warp.Tool{{Type: "function", Function: warp.Function{Name: "get_weather"}}},
+	}
+	conv.AddResponse(&warp.CompletionResponse{
+		Model: "openai/gpt-4o",
+		Choices: []warp.Choice{{Message: warp.Message{
+			ToolCalls: []warp.ToolCall{{
+				ID: "call_1", Type: "function",
+				Function: warp.FunctionCall{Name: "get_weather", Arguments: `{"city":"Paris"}`},
+			}},
+		}}},
+		Usage: &warp.Usage{PromptTokens: 20, CompletionTokens: 5, TotalTokens: 25},
+	}, 0.0001)
+	conv.Messages = append(conv.Messages, warp.Message{Role: "tool", ToolCallID: "call_1", Content: `{"temp_c":21}`})
+	conv.AddResponse(&warp.CompletionResponse{
+		Choices: []warp.Choice{{Message: warp.Message{Role: "assistant", Content: "It's 21°C."}}},
+		Usage:   &warp.Usage{PromptTokens: 40, CompletionTokens: 6, TotalTokens: 46},
+	}, 0.0002)
+	return conv
+}
+
+// TestAddResponse tests turn recording and totals
+func TestAddResponse(t *testing.T) {
+	conv := testConversation()
+
+	if len(conv.Messages) != 5 || conv.Messages[2].Role != "assistant" {
+		t.Fatalf("Messages = %+v", conv.Messages)
+	}
+	if conv.Model != "openai/gpt-4o" {
+		t.Errorf("Model = %q", conv.Model)
+	}
+	if len(conv.Turns) != 2 || conv.Turns[0].Message != 2 || conv.Turns[1].Message != 4 {
+		t.Errorf("Turns = %+v", conv.Turns)
+	}
+	if got := conv.TotalUsage(); got.PromptTokens != 60 || got.CompletionTokens != 11 || got.TotalTokens != 71 {
+		t.Errorf("TotalUsage() = %+v", got)
+	}
+	if got := conv.TotalCost(); got < 0.00029 || got > 0.00031 {
+		t.Errorf("TotalCost() = %v", got)
+	}
+}
+
+// TestJSONLRoundTrip tests fine-tuning JSONL export and import
+func TestJSONLRoundTrip(t *testing.T) {
+	conv := testConversation()
+
+	var buf bytes.Buffer
+	if err := WriteJSONL(&buf, conv, conv); err != nil {
+		t.Fatalf("WriteJSONL() error = %v", err)
+	}
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("got %d lines, want 2", len(lines))
+	}
+	if strings.Contains(lines[0], "turns") || strings.Contains(lines[0], "conv_1") {
+		t.Errorf("JSONL line contains non fine-tuning fields: %s", lines[0])
+	}
+	if !strings.Contains(lines[0], `"What's the weather in <Paris>?"`) {
+		t.Errorf("JSONL line escaped HTML: %s", lines[0])
+	}
+
+	got, err := ReadJSONL(strings.NewReader(buf.String() + "\n\n"))
+	if err != nil {
+		t.Fatalf("ReadJSONL() error = %v", err)
+	}
+	if len(got) != 2 {
+		t.Fatalf("ReadJSONL() returned %d conversations, want 2", len(got))
+	}
+	if !reflect.DeepEqual(got[0].Messages, conv.Messages) {
+		t.Errorf("Messages = %+v, want %+v", got[0].Messages, conv.Messages)
+	}
+	if !reflect.DeepEqual(got[0].Tools, conv.Tools) {
+		t.Errorf("Tools = %+v", got[0].Tools)
+	}
+
+	if _, err := ReadJSONL(strings.NewReader("{\"messages\":[]}\nnot json\n")); err == nil || !strings.Contains(err.Error(), "line 2") {
+		t.Errorf("ReadJSONL() error = %v, want line 2 error", err)
+	}
+}
+
+// TestJSONLDropsNonFineTuningFields tests that message fields OpenAI rejects
+// in fine-tuning files are not written
+func TestJSONLDropsNonFineTuningFields(t *testing.T) {
+	conv := &Conversation{Messages: []warp.Message{
+		{Role: "user", Content: "Hi", Name: "ana", Metadata: map[string]any{"id": "m1"}},
+		{Role: "assistant", Content: "Hello", ReasoningContent: "greet back", Metadata: map[string]any{"id": "m2"}},
+	}}
+
+	var buf bytes.Buffer
+	if err := WriteJSONL(&buf, conv); err != nil {
+		t.Fatalf("WriteJSONL() error = %v", err)
+	}
+	if strings.Contains(buf.String(), "metadata") || strings.Contains(buf.String(), "reasoning_content") {
+		t.Errorf("JSONL line contains non fine-tuning fields: %s", buf.String())
+	}
+
+	got, err := ReadJSONL(&buf)
+	if err != nil {
+		t.Fatalf("ReadJSONL() error = %v", err)
+	}
+	want := []warp.Message{
+		{Role: "user", Content: "Hi", Name: "ana"},
+		{Role: "assistant", Content: "Hello"},
+	}
+	if len(got) != 1 || !reflect.DeepEqual(got[0].Messages, want) {
+		t.Errorf("Messages = %+v, want %+v", got[0].Messages, want)
+	}
+}
+
+// TestJSONRoundTrip tests full-fidelity JSON export and import
+func TestJSONRoundTrip(t *testing.T) {
+	conv := testConversation()
+
+	var buf bytes.Buffer
+	if err := WriteJSON(&buf, conv); err != nil {
+		t.Fatalf("WriteJSON() error = %v", err)
+	}
+	got, err := ReadJSON(&buf)
+	if err != nil {
+		t.Fatalf("ReadJSON() error = %v", err)
+	}
+	if !reflect.DeepEqual(got, conv) {
+		t.Errorf("ReadJSON() = %+v, want %+v", got, conv)
+	}
+}
+
+// TestWriteHTML tests the HTML transcript
+func TestWriteHTML(t *testing.T) {
+	conv := testConversation()
+	conv.Messages = append(conv.Messages, warp.Message{Role: "user", Content: []warp.ContentPart{
+		{Type: "image_url", ImageURL: &warp.ImageURL{URL: "javascript:alert(1)"}},
+	}})
+
+	var buf bytes.Buffer
+	if err := WriteHTML(&buf, conv); err != nil {
+		t.Fatalf("WriteHTML() error = %v", err)
+	}
+	out := buf.String()
+
+	for _, want := range []string{
+		"<title>Conversation conv_1</title>",
+		"Model: openai/gpt-4o",
+		"Tools: get_weather",
+		"&lt;Paris&gt;",
+		`src="data:image/png;base64,AAAA"`,
+		"<code>get_weather</code>",
+		`&#34;city&#34;: &#34;Paris&#34;`,
+		"&rarr; call_1",
+		"20 prompt + 5 completion tokens",
+		"$0.000100",
+		"Total: 60 prompt + 11 completion tokens, $0.000300",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("HTML missing %q", want)
+		}
+	}
+	if strings.Contains(out, "<Paris>") || strings.Contains(out, "javascript:") {
+		t.Error("HTML contains unescaped content")
+	}
+}
+
+func TestEncrypted(t *testing.T) {
+	ctx := context.Background()
+	keys, err := encrypt.NewKeyRing("k1", bytes.Repeat([]byte{1}, 32))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	conv := &Conversation{ID: "c1", Messages: []warp.Message{{Role: "user", Content: "secret prompt"}}}
+
+	var buf bytes.Buffer
+	if err := WriteEncrypted(ctx, &buf, conv, keys); err != nil {
+		t.Fatalf("WriteEncrypted() error = %v", err)
+	}
+	if bytes.Contains(buf.Bytes(), []byte("secret")) {
+		t.Error("encrypted conversation contains plaintext")
+	}
+
+	keys.Rotate("k2", bytes.Repeat([]byte{2}, 32))
+	got, err := ReadEncrypted(ctx, &buf, keys)
+	if err != nil {
+		t.Fatalf("ReadEncrypted() error = %v", err)
+	}
+	if got.ID != "c1" || got.Messages[0].Content != "secret prompt" {
+		t.Errorf("ReadEncrypted() = %+v", got)
+	}
+
+	if _, err := ReadEncrypted(ctx, bytes.NewReader([]byte(`{"id":"c1"}`)), keys); err == nil {
+		t.Error("ReadEncrypted() of plaintext succeeded")
+	}
+}
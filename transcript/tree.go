@@ -0,0 +1,314 @@
+package transcript
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"slices"
+	"strconv"
+
+	"github.com/blue-context/warp"
+)
+
+// Tree is a conversation with branches, as kept by chat UIs that can
+// regenerate a response or edit an earlier message and resend it. Each
+// message is a node whose parent is the message before it; regenerating or
+// editing adds a sibling instead of replacing the message, so every branch
+// is kept. Current is the node the conversation continues from.
+//
+// Thread Safety: Tree is NOT safe for concurrent use.
+//
+// Example:
+//
+//	tree := &transcript.Tree{Model: "openai/gpt-4o"}
+//	tree.Add(warp.Message{Role: "user", Content: "Name a color."})
+//	resp, err := client.Completion(ctx, &warp.CompletionRequest{Model: tree.Model, Messages: tree.Messages()})
+//	if err != nil {
+//	    return err
+//	}
+//	answer := tree.AddResponse(resp, 0)
+//
+//	// Regenerate: ask again from the user message; both answers are kept
+//	if err := tree.Regenerate(answer); err != nil {
+//	    return err
+//	}
+//	resp, err = client.Completion(ctx, &warp.CompletionRequest{Model: tree.Model, Messages: tree.Messages()})
+//	if err != nil {
+//	    return err
+//	}
+//	alternative := tree.AddResponse(resp, 0)
+//	fmt.Println(tree.Siblings(alternative)) // [answer alternative]
+type Tree struct {
+	// ID identifies the conversation.
+	ID string `json:"id,omitempty"`
+
+	// Title is a human-readable title.
+	Title string `json:"title,omitempty"`
+
+	// Model is the model the conversation is held with.
+	Model string `json:"model,omitempty"`
+
+	// Tools lists the tools offered to the model.
+	Tools []warp.Tool `json:"tools,omitempty"`
+
+	// Nodes holds the messages of every branch in the order they were
+	// added, so parents precede their children. Nodes may be appended to
+	// directly but not otherwise changed, since the tree indexes them.
+	Nodes []Node `json:"nodes"`
+
+	// Current is the ID of the node the conversation continues from, or
+	// empty to start a new root.
+	Current string `json:"current,omitempty"`
+
+	// Metadata contains arbitrary key-value pairs.
+	Metadata map[string]any `json:"metadata,omitempty"`
+
+	// The first indexed nodes are indexed by ID (their position in Nodes)
+	// and by parent ID (their children's IDs, in order)
+	byID     map[string]int
+	byParent map[string][]string
+	indexed  int
+}
+
+// Node is one message of a Tree.
+type Node struct {
+	// ID identifies the node within the tree.
+	ID string `json:"id"`
+
+	// Parent is the ID of the preceding message, or empty for the first
+	// message of a branch.
+	Parent string `json:"parent,omitempty"`
+
+	// Message is the message.
+	Message warp.Message `json:"message"`
+
+	// Usage is the token usage of the response that produced an assistant
+	// message.
+	Usage *warp.Usage `json:"usage,omitempty"`
+
+	// Cost is the cost in USD of the response that produced an assistant
+	// message.
+	Cost float64 `json:"cost,omitempty"`
+}
+
+// Add appends msg after the current node and makes it current. It returns
+// the ID of the new node.
+func (t *Tree) Add(msg warp.Message) string {
+	return t.add(Node{Parent: t.Current, Message: msg})
+}
+
+// AddResponse appends the first choice of resp after the current node,
+// with its usage and cost (in USD), and makes it current. It returns the
+// ID of the new node, or an empty string if resp has no choices.
+//
+// Model is set from resp if empty.
+func (t *Tree) AddResponse(resp *warp.CompletionResponse, cost float64) string {
+	if resp == nil || len(resp.Choices) == 0 {
+		return ""
+	}
+	msg := resp.Choices[0].Message
+	if msg.Role == "" {
+		msg.Role = "assistant"
+	}
+	if t.Model == "" {
+		t.Model = resp.Model
+	}
+	return t.add(Node{Parent: t.Current, Message: msg, Usage: resp.Usage, Cost: cost})
+}
+
+// add appends node with a new ID and makes it current. IDs are sequence
+// numbers, skipping any taken by nodes added with other IDs.
+func (t *Tree) add(node Node) string {
+	for n := len(t.Nodes) + 1; node.ID == "" || t.Node(node.ID) != nil; n++ {
+		node.ID = strconv.Itoa(n)
+	}
+	t.Nodes = append(t.Nodes, node)
+	t.index()
+	t.Current = node.ID
+	return node.ID
+}
+
+// Node returns the node with the given ID, or nil if there is none.
+func (t *Tree) Node(id string) *Node {
+	if id == "" {
+		return nil
+	}
+	t.index()
+	if i, ok := t.byID[id]; ok {
+		return &t.Nodes[i]
+	}
+	return nil
+}
+
+// index indexes the nodes appended since the last call, starting over if
+// Nodes has shrunk.
+func (t *Tree) index() {
+	if t.byID == nil || t.indexed > len(t.Nodes) {
+		t.byID = make(map[string]int, len(t.Nodes))
+		t.byParent = make(map[string][]string)
+		t.indexed = 0
+	}
+	for ; t.indexed < len(t.Nodes); t.indexed++ {
+		node := &t.Nodes[t.indexed]
+		// The first node with a repeated ID wins
+		if _, ok := t.byID[node.ID]; !ok {
+			t.byID[node.ID] = t.indexed
+		}
+		t.byParent[node.Parent] = append(t.byParent[node.Parent], node.ID)
+	}
+}
+
+// Checkout makes the node with the given ID current, to continue or view
+// its branch.
+func (t *Tree) Checkout(id string) error {
+	if t.Node(id) == nil {
+		return fmt.Errorf("node %q not found", id)
+	}
+	t.Current = id
+	return nil
+}
+
+// Regenerate makes the parent of the node with the given ID current, so
+// the next message added, typically the new response, becomes a sibling
+// of that node.
+func (t *Tree) Regenerate(id string) error {
+	node := t.Node(id)
+	if node == nil {
+		return fmt.Errorf("node %q not found", id)
+	}
+	t.Current = node.Parent
+	return nil
+}
+
+// Edit adds msg as a sibling of the node with the given ID, branching the
+// conversation at that message, and makes it current. It returns the ID
+// of the new node; send Messages to get the response to the edit.
+func (t *Tree) Edit(id string, msg warp.Message) (string, error) {
+	node := t.Node(id)
+	if node == nil {
+		return "", fmt.Errorf("node %q not found", id)
+	}
+	return t.add(Node{Parent: node.Parent, Message: msg}), nil
+}
+
+// Children returns the IDs of the children of the node with the given ID,
+// or of the roots for an empty ID, in the order they were added.
+func (t *Tree) Children(id string) []string {
+	t.index()
+	return slices.Clone(t.byParent[id])
+}
+
+// Siblings returns the IDs of the node with the given ID and its
+// siblings, the alternatives a UI offers to switch between, in the order
+// they were added.
+func (t *Tree) Siblings(id string) []string {
+	node := t.Node(id)
+	if node == nil {
+		return nil
+	}
+	return t.Children(node.Parent)
+}
+
+// Leaf returns the ID of the most recent leaf under the node with the
+// given ID, following the last child at each step. A UI switching to a
+// sibling branch checks out its leaf.
+func (t *Tree) Leaf(id string) string {
+	t.index()
+	for {
+		children := t.byParent[id]
+		if len(children) == 0 {
+			return id
+		}
+		id = children[len(children)-1]
+	}
+}
+
+// Path returns the nodes from the root to the node with the given ID.
+func (t *Tree) Path(id string) []Node {
+	var path []Node
+	for node := t.Node(id); node != nil; node = t.Node(node.Parent) {
+		path = append(path, *node)
+	}
+	for i, j := 0, len(path)-1; i < j; i, j = i+1, j-1 {
+		path[i], path[j] = path[j], path[i]
+	}
+	return path
+}
+
+// Messages returns the messages from the root to the current node, the
+// conversation to send to the model.
+func (t *Tree) Messages() []warp.Message {
+	path := t.Path(t.Current)
+	messages := make([]warp.Message, len(path))
+	for i, node := range path {
+		messages[i] = node.Message
+	}
+	return messages
+}
+
+// Conversation returns the branch ending at the node with the given ID as
+// a Conversation, with a turn for each node with usage or cost, for export
+// with WriteJSONL or WriteHTML.
+func (t *Tree) Conversation(id string) *Conversation {
+	conv := &Conversation{ID: t.ID, Title: t.Title, Model: t.Model, Tools: t.Tools, Metadata: t.Metadata}
+	for i, node := range t.Path(id) {
+		conv.Messages = append(conv.Messages, node.Message)
+		if node.Usage != nil || node.Cost != 0 {
+			conv.Turns = append(conv.Turns, Turn{Message: i, Usage: node.Usage, Cost: node.Cost})
+		}
+	}
+	if conv.Messages == nil {
+		conv.Messages = []warp.Message{}
+	}
+	return conv
+}
+
+// WriteTreeJSON writes a tree as indented JSON.
+func WriteTreeJSON(w io.Writer, tree *Tree) error {
+	if tree == nil {
+		return fmt.Errorf("tree cannot be nil")
+	}
+	enc := json.NewEncoder(w)
+	enc.SetEscapeHTML(false)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(tree); err != nil {
+		return fmt.Errorf("failed to encode tree: %w", err)
+	}
+	return nil
+}
+
+// ReadTreeJSON reads a tree written by WriteTreeJSON.
+//
+// Returns an error if node IDs are empty or repeated, a node's parent does
+// not precede it, or Current is not a node.
+func ReadTreeJSON(r io.Reader) (*Tree, error) {
+	var tree Tree
+	if err := json.NewDecoder(r).Decode(&tree); err != nil {
+		return nil, fmt.Errorf("failed to decode tree: %w", err)
+	}
+
+	seen := make(map[string]bool, len(tree.Nodes))
+	messages := make([]warp.Message, len(tree.Nodes))
+	for i, node := range tree.Nodes {
+		switch {
+		case node.ID == "" || seen[node.ID]:
+			return nil, fmt.Errorf("node %d: ID %q is empty or repeated", i, node.ID)
+		case node.Parent != "" && !seen[node.Parent]:
+			return nil, fmt.Errorf("node %q: parent %q does not precede it", node.ID, node.Parent)
+		}
+		seen[node.ID] = true
+		messages[i] = node.Message
+	}
+	if tree.Current != "" && !seen[tree.Current] {
+		return nil, fmt.Errorf("current node %q not found", tree.Current)
+	}
+
+	if err := normalizeContent(messages); err != nil {
+		return nil, err
+	}
+	for i := range tree.Nodes {
+		tree.Nodes[i].Message = messages[i]
+	}
+	tree.index()
+	return &tree, nil
+}
@@ -0,0 +1,164 @@
+package transcript
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/blue-context/warp"
+)
+
+// testTree returns a tree with a regenerated answer and an edited question:
+//
+//	1 user "Name a color."
+//	├── 2 assistant "Red."
+//	└── 3 assistant "Blue."
+//	4 user "Name a fruit."
+func testTree(t *testing.T) *Tree {
+	t.Helper()
+	tree := &Tree{ID: "tree_1"}
+	question := tree.Add(warp.Message{Role: "user", Content: "Name a color."})
+	answer := tree.AddResponse(&warp.CompletionResponse{
+		Model:   "openai/gpt-4o",
+		Choices: []warp.Choice{{Message: warp.Message{Content: "Red."}}},
+		Usage:   &warp.Usage{PromptTokens: 10, CompletionTokens: 2, TotalTokens: 12},
+	}, 0.0001)
+	if err := tree.Regenerate(answer); err != nil {
+		t.Fatalf("Regenerate() error = %v", err)
+	}
+	tree.AddResponse(&warp.CompletionResponse{
+		Model:   "openai/gpt-4o-mini",
+		Choices: []warp.Choice{{Message: warp.Message{Role: "assistant", Content: "Blue."}}},
+	}, 0.0002)
+	if _, err := tree.Edit(question, warp.Message{Role: "user", Content: "Name a fruit."}); err != nil {
+		t.Fatalf("Edit() error = %v", err)
+	}
+	return tree
+}
+
+// TestTreeBranching tests regenerate and edit branching
+func TestTreeBranching(t *testing.T) {
+	tree := testTree(t)
+
+	if tree.Model != "openai/gpt-4o" {
+		t.Errorf("Model = %q, want the first response's model", tree.Model)
+	}
+	if tree.Current != "4" {
+		t.Errorf("Current = %q, want 4", tree.Current)
+	}
+	if got := tree.Siblings("3"); !reflect.DeepEqual(got, []string{"2", "3"}) {
+		t.Errorf("Siblings(3) = %v", got)
+	}
+	if got := tree.Children(""); !reflect.DeepEqual(got, []string{"1", "4"}) {
+		t.Errorf("Children(\"\") = %v", got)
+	}
+	if got := tree.Leaf("1"); got != "3" {
+		t.Errorf("Leaf(1) = %q, want 3", got)
+	}
+	if got := tree.Messages(); len(got) != 1 || got[0].Content != "Name a fruit." {
+		t.Errorf("Messages() = %+v", got)
+	}
+
+	if err := tree.Checkout("2"); err != nil {
+		t.Fatalf("Checkout() error = %v", err)
+	}
+	got := tree.Messages()
+	if len(got) != 2 || got[0].Content != "Name a color." || got[1].Content != "Red." || got[1].Role != "assistant" {
+		t.Errorf("Messages() = %+v", got)
+	}
+
+	if err := tree.Checkout("9"); err == nil {
+		t.Error("Checkout() of unknown node should fail")
+	}
+	if err := tree.Regenerate("9"); err == nil {
+		t.Error("Regenerate() of unknown node should fail")
+	}
+	if _, err := tree.Edit("9", warp.Message{}); err == nil {
+		t.Error("Edit() of unknown node should fail")
+	}
+	if id := tree.AddResponse(&warp.CompletionResponse{}, 0); id != "" || tree.Current != "2" {
+		t.Errorf("AddResponse() of empty response = %q, Current = %q", id, tree.Current)
+	}
+}
+
+// TestTreeAppendedNodes tests that nodes appended to Nodes directly are
+// found, and that IDs are not reused after them
+func TestTreeAppendedNodes(t *testing.T) {
+	tree := testTree(t)
+	tree.Nodes = append(tree.Nodes, Node{ID: "5", Parent: "2", Message: warp.Message{Role: "user", Content: "Why?"}})
+
+	if got := tree.Children("2"); !reflect.DeepEqual(got, []string{"5"}) {
+		t.Errorf("Children(2) = %v, want [5]", got)
+	}
+	if got := tree.Leaf("1"); got != "3" {
+		t.Errorf("Leaf(1) = %q, want 3", got)
+	}
+	if path := tree.Path("5"); len(path) != 3 || path[0].ID != "1" || path[2].ID != "5" {
+		t.Errorf("Path(5) = %+v", path)
+	}
+	if err := tree.Checkout("5"); err != nil {
+		t.Fatalf("Checkout(5) error = %v", err)
+	}
+	if id := tree.Add(warp.Message{Role: "assistant", Content: "Because."}); id != "6" {
+		t.Errorf("Add() = %q, want 6", id)
+	}
+
+	// Children returns a copy of the index
+	tree.Children("1")[0] = "x"
+	if got := tree.Children("1"); !reflect.DeepEqual(got, []string{"2", "3"}) {
+		t.Errorf("Children(1) = %v, want [2 3]", got)
+	}
+}
+
+// TestTreeConversation tests exporting a branch as a conversation
+func TestTreeConversation(t *testing.T) {
+	tree := testTree(t)
+
+	conv := tree.Conversation("3")
+	if conv.ID != "tree_1" || len(conv.Messages) != 2 || conv.Messages[1].Content != "Blue." {
+		t.Fatalf("Conversation(3) = %+v", conv)
+	}
+	if len(conv.Turns) != 1 || conv.Turns[0].Message != 1 || conv.Turns[0].Cost != 0.0002 {
+		t.Errorf("Turns = %+v", conv.Turns)
+	}
+	if conv := tree.Conversation("9"); len(conv.Messages) != 0 || conv.Messages == nil {
+		t.Errorf("Conversation(9).Messages = %#v, want empty", conv.Messages)
+	}
+}
+
+// TestTreeJSONRoundTrip tests tree export, import, and validation
+func TestTreeJSONRoundTrip(t *testing.T) {
+	tree := testTree(t)
+	tree.Nodes[0].Message.Content = []warp.ContentPart{{Type: "text", Text: "Name a color."}}
+
+	var buf bytes.Buffer
+	if err := WriteTreeJSON(&buf, tree); err != nil {
+		t.Fatalf("WriteTreeJSON() error = %v", err)
+	}
+	got, err := ReadTreeJSON(&buf)
+	if err != nil {
+		t.Fatalf("ReadTreeJSON() error = %v", err)
+	}
+	if !reflect.DeepEqual(got, tree) {
+		t.Errorf("ReadTreeJSON() = %+v, want %+v", got, tree)
+	}
+
+	tests := []struct {
+		name string
+		json string
+		want string
+	}{
+		{"empty ID", `{"nodes":[{"message":{"role":"user"}}]}`, "empty or repeated"},
+		{"repeated ID", `{"nodes":[{"id":"1"},{"id":"1"}]}`, "empty or repeated"},
+		{"parent after child", `{"nodes":[{"id":"1","parent":"2"},{"id":"2"}]}`, "does not precede"},
+		{"unknown current", `{"nodes":[{"id":"1"}],"current":"2"}`, "current node"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := ReadTreeJSON(strings.NewReader(tt.json)); err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("ReadTreeJSON() error = %v, want %q", err, tt.want)
+			}
+		})
+	}
+}
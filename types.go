@@ -44,6 +44,7 @@
 package warp
 
 import (
+	"encoding/json"
 	"io"
 	"time"
 )
@@ -85,7 +86,8 @@ type CompletionRequest struct {
 	// Positive values increase likelihood of talking about new topics.
 	PresencePenalty *float64 `json:"presence_penalty,omitempty"`
 
-	// Stop contains up to 4 sequences where the API will stop generating.
+	// Stop contains sequences where the API will stop generating. Most
+	// providers accept up to 4; see StopSequenceLimiter.
 	Stop []string `json:"stop,omitempty"`
 
 	// N specifies how many chat completion choices to generate.
@@ -109,6 +111,11 @@ type CompletionRequest struct {
 	// APIVersion overrides the provider API version for this request.
 	APIVersion string `json:"api_version,omitempty"`
 
+	// ExtraBody contains provider-specific fields merged into the request body.
+	// Keys set here override fields generated from the request.
+	// Providers that do not support extra fields ignore them.
+	ExtraBody map[string]any `json:"extra_body,omitempty"`
+
 	// Metadata contains arbitrary key-value pairs for callbacks and tracking.
 	Metadata map[string]any `json:"metadata,omitempty"`
 
@@ -121,6 +128,43 @@ type CompletionRequest struct {
 
 	// Timeout specifies the maximum duration for this request.
 	Timeout time.Duration `json:"timeout,omitempty"`
+
+	// ServiceTier requests a provider processing tier (e.g., ServiceTierFlex,
+	// ServiceTierPriority). Empty uses the provider's default tier.
+	// Providers without service tiers ignore it.
+	ServiceTier ServiceTier `json:"service_tier,omitempty"`
+
+	// SafetySettings sets the harm categories to block and at what
+	// severity. It is translated to Gemini safety settings, checked
+	// against Azure content filter results, and enforced for OpenAI with a
+	// moderation check of the prompt. See SafetySettings.
+	SafetySettings SafetySettings `json:"safety_settings,omitempty"`
+
+	// PromptLogprobs asks for the log probability of each prompt token,
+	// returned in CompletionResponse.PromptLogprobs, for evaluations such
+	// as perplexity scoring. Supported by vLLM and OpenAI-compatible
+	// servers that accept prompt_logprobs; other providers ignore it.
+	PromptLogprobs bool `json:"prompt_logprobs,omitempty"`
+
+	// CachedContent is the name of content cached for the model with
+	// Client.CreateCachedContent, used as the start of the prompt. The
+	// request's messages follow it; system messages and tools must be part
+	// of the cached content instead. Providers that do not implement
+	// CachedContentManager refuse the request.
+	CachedContent string `json:"cached_content,omitempty"`
+
+	// PromptCacheKey groups requests that share a long prompt prefix, such
+	// as a system prompt, so OpenAI routes them to the same prompt cache
+	// and more of them are served from it. The cache hit rate of each key
+	// is reported by the dashboard package. Providers without the hint
+	// ignore it.
+	PromptCacheKey string `json:"prompt_cache_key,omitempty"`
+
+	// SafetyIdentifier is a stable identifier of the end user, such as a
+	// hash of their username, that OpenAI uses to detect users violating
+	// its usage policies without penalizing the whole organization.
+	// Providers without it ignore it.
+	SafetyIdentifier string `json:"safety_identifier,omitempty"`
 }
 
 // Message represents a single message in a conversation.
@@ -131,7 +175,10 @@ type CompletionRequest struct {
 // concurrently without external synchronization.
 type Message struct {
 	// Role identifies the message sender.
-	// Valid values: "system", "user", "assistant", "tool"
+	// Valid values: "system", "developer", "user", "assistant", "tool"
+	//
+	// "developer" carries instructions for OpenAI reasoning models; it is
+	// sent as "system" to providers that do not support it.
 	Role string `json:"role"`
 
 	// Content can be either:
@@ -146,9 +193,23 @@ type Message struct {
 	// ToolCalls contains tool invocations made by the assistant.
 	ToolCalls []ToolCall `json:"tool_calls,omitempty"`
 
+	// ReasoningContent is the reasoning (chain of thought) the assistant
+	// produced before its answer, for providers that return it apart from
+	// Content: Anthropic extended thinking, and the reasoning_content of
+	// DeepSeek and vLLM reasoning parsers. It is not sent back to
+	// providers, except to vLLM chat templates.
+	ReasoningContent string `json:"reasoning_content,omitempty"`
+
 	// ToolCallID identifies which tool call this message is responding to.
 	// Used when Role is "tool".
 	ToolCallID string `json:"tool_call_id,omitempty"`
+
+	// Metadata holds application data about the message (e.g., IDs or
+	// timestamps). It is not sent to providers, except those that
+	// explicitly accept extra message fields (see the openaicompat
+	// WithMessageMetadata option and the vLLM chat API, which passes it to
+	// chat templates).
+	Metadata map[string]any `json:"metadata,omitempty"`
 }
 
 // ContentPart represents a component of multimodal message content.
@@ -206,12 +267,32 @@ type CompletionResponse struct {
 	// Can be used to understand model behavior changes.
 	SystemFingerprint string `json:"system_fingerprint,omitempty"`
 
+	// ServiceTier is the processing tier that served the request, if the
+	// provider reports it. It may differ from the requested tier.
+	ServiceTier ServiceTier `json:"service_tier,omitempty"`
+
+	// Citations are the sources the first choice cites, normalized from
+	// the provider's citation format (OpenAI url_citation annotations,
+	// Perplexity search results, Cohere citations).
+	Citations []Citation `json:"citations,omitempty"`
+
+	// PromptLogprobs are the log probabilities of the prompt tokens, if
+	// the request set PromptLogprobs and the provider supports it.
+	PromptLogprobs PromptLogprobs `json:"prompt_logprobs,omitempty"`
+
 	// ProviderFields contains provider-specific response fields.
 	ProviderFields map[string]any `json:"provider_specific_fields,omitempty"`
 
 	// HiddenParams contains internal metadata (prefixed with _).
 	// Used for debugging and tracking internal state.
 	HiddenParams map[string]any `json:"_hidden_params,omitempty"`
+
+	// RequestID is the warp request ID (internal metadata).
+	RequestID string `json:"-"`
+
+	// ProviderRequestID is the request ID the provider returned, if any,
+	// for support escalations (internal metadata).
+	ProviderRequestID string `json:"-"`
 }
 
 // GetModel returns the model name.
@@ -222,6 +303,14 @@ func (r *CompletionResponse) GetModel() string {
 	return r.Model
 }
 
+// GetServiceTier returns the service tier that served the request.
+func (r *CompletionResponse) GetServiceTier() string {
+	if r == nil {
+		return ""
+	}
+	return string(r.ServiceTier)
+}
+
 // GetUsageInfo returns the usage information as a generic interface.
 // This satisfies the cost.CompletionResponse interface.
 func (r *CompletionResponse) GetUsageInfo() interface{} {
@@ -247,6 +336,41 @@ type Choice struct {
 	Logprobs *Logprobs `json:"logprobs,omitempty"`
 }
 
+// Citation is a source cited by a completion.
+type Citation struct {
+	// URL is the address of the source, if it has one.
+	URL string `json:"url,omitempty"`
+
+	// Title is the title of the source.
+	Title string `json:"title,omitempty"`
+
+	// Text is the cited span of the message content, if the provider
+	// reports one.
+	Text string `json:"text,omitempty"`
+
+	// StartIndex and EndIndex are the character offsets of the cited span
+	// in the message content. EndIndex is zero if the citation applies to
+	// the whole response rather than a span of it.
+	StartIndex int `json:"start_index,omitempty"`
+	EndIndex   int `json:"end_index,omitempty"`
+
+	// DocumentID is the ID of the cited document, for citations of
+	// documents sent with the request.
+	DocumentID string `json:"document_id,omitempty"`
+}
+
+// UnmarshalJSON decodes a citation from an object or, as in Perplexity
+// responses, a bare URL string.
+func (c *Citation) UnmarshalJSON(data []byte) error {
+	var url string
+	if err := json.Unmarshal(data, &url); err == nil {
+		*c = Citation{URL: url}
+		return nil
+	}
+	type citation Citation
+	return json.Unmarshal(data, (*citation)(c))
+}
+
 // Logprobs represents log probability information for generated tokens.
 // This is useful for understanding model confidence and alternative choices.
 type Logprobs struct {
@@ -308,11 +432,39 @@ func (u *Usage) GetTotalTokens() int {
 	return u.TotalTokens
 }
 
+// GetCachedTokens returns the number of prompt tokens served from the provider's prompt cache.
+func (u *Usage) GetCachedTokens() int {
+	if u == nil || u.PromptDetails == nil {
+		return 0
+	}
+	return u.PromptDetails.CachedTokens
+}
+
+// GetCacheWriteTokens returns the number of prompt tokens written to the provider's prompt cache.
+func (u *Usage) GetCacheWriteTokens() int {
+	if u == nil || u.PromptDetails == nil {
+		return 0
+	}
+	return u.PromptDetails.CacheWriteTokens
+}
+
+// GetReasoningTokens returns the number of completion tokens spent on reasoning.
+func (u *Usage) GetReasoningTokens() int {
+	if u == nil || u.CompletionDetails == nil {
+		return 0
+	}
+	return u.CompletionDetails.ReasoningTokens
+}
+
 // PromptTokensDetails provides detailed breakdown of prompt token usage.
 type PromptTokensDetails struct {
 	// CachedTokens is the number of cached tokens that didn't need processing.
 	CachedTokens int `json:"cached_tokens,omitempty"`
 
+	// CacheWriteTokens is the number of tokens written to the prompt cache,
+	// which some providers (e.g., Anthropic) bill above the input rate.
+	CacheWriteTokens int `json:"cache_write_tokens,omitempty"`
+
 	// AudioTokens is the number of tokens from audio input.
 	AudioTokens int `json:"audio_tokens,omitempty"`
 }
@@ -333,6 +485,9 @@ type Tool struct {
 
 	// Function contains the function definition.
 	Function Function `json:"function"`
+
+	// encoded is the JSON encoding cached by PrepareRequest.
+	encoded []byte
 }
 
 // Function represents a function that can be called by the model.
@@ -402,7 +557,31 @@ type ResponseFormat struct {
 	// Valid values:
 	// - "text": plain text response (default)
 	// - "json_object": response will be valid JSON
+	// - "json_schema": response will conform to JSONSchema
 	Type string `json:"type"`
+
+	// JSONSchema constrains the response to a schema when Type is "json_schema".
+	// Providers with constrained decoding (OpenAI strict mode, vLLM guided_json)
+	// guarantee the output matches the schema.
+	JSONSchema *JSONSchema `json:"json_schema,omitempty"`
+
+	// encoded is the JSON encoding cached by PrepareRequest.
+	encoded []byte
+}
+
+// JSONSchema describes a schema for structured output.
+type JSONSchema struct {
+	// Name identifies the schema (e.g., "weather_report").
+	Name string `json:"name"`
+
+	// Description explains what the response represents.
+	Description string `json:"description,omitempty"`
+
+	// Schema is the JSON Schema the response must match.
+	Schema map[string]any `json:"schema,omitempty"`
+
+	// Strict enables strict schema adherence where supported.
+	Strict *bool `json:"strict,omitempty"`
 }
 
 // CompletionChunk represents a single chunk in a streaming response.
@@ -424,6 +603,17 @@ type CompletionChunk struct {
 
 	// Usage contains cumulative token usage (only present in final chunk).
 	Usage *Usage `json:"usage,omitempty"`
+
+	// RequestID is the warp request ID (internal metadata).
+	RequestID string `json:"-"`
+
+	// ProviderRequestID is the request ID the provider returned, if any,
+	// for support escalations (internal metadata).
+	ProviderRequestID string `json:"-"`
+
+	// ProviderFields contains provider-specific chunk fields. The stream
+	// accumulator merges them into CompletionResponse.ProviderFields.
+	ProviderFields map[string]any `json:"provider_specific_fields,omitempty"`
 }
 
 // ChunkChoice represents a single choice in a streaming chunk.
@@ -451,6 +641,11 @@ type MessageDelta struct {
 	// Content contains the incremental text content.
 	Content string `json:"content,omitempty"`
 
+	// ReasoningContent contains incremental reasoning, which providers
+	// stream before the answer (see Message.ReasoningContent). UIs can
+	// render it apart from Content or drop it.
+	ReasoningContent string `json:"reasoning_content,omitempty"`
+
 	// ToolCalls contains incremental tool call information.
 	ToolCalls []ToolCall `json:"tool_calls,omitempty"`
 }
@@ -517,6 +712,16 @@ type EmbeddingResponse struct {
 
 	// Usage contains token usage information for this request.
 	Usage *EmbeddingUsage `json:"usage,omitempty"`
+
+	// Provider is the provider that generated the embeddings (internal metadata).
+	Provider string `json:"-"`
+
+	// RequestID is the warp request ID (internal metadata).
+	RequestID string `json:"-"`
+
+	// ProviderRequestID is the request ID the provider returned, if any,
+	// for support escalations (internal metadata).
+	ProviderRequestID string `json:"-"`
 }
 
 // GetModel returns the model name.
@@ -697,6 +902,19 @@ type ImageGenerationResponse struct {
 
 	// Model is the model used for generation (internal metadata).
 	Model string `json:"-"`
+
+	// Size is the requested image size, used for cost calculation (internal metadata).
+	Size string `json:"-"`
+
+	// Quality is the requested image quality, used for cost calculation (internal metadata).
+	Quality string `json:"-"`
+
+	// RequestID is the warp request ID (internal metadata).
+	RequestID string `json:"-"`
+
+	// ProviderRequestID is the request ID the provider returned, if any,
+	// for support escalations (internal metadata).
+	ProviderRequestID string `json:"-"`
 }
 
 // ImageData represents a single generated image.
@@ -750,6 +968,11 @@ type ImageEditRequest struct {
 	// Must end with ".png".
 	MaskFilename string `json:"-"`
 
+	// OnProgress, if set, is called with the number of request body bytes
+	// sent so far as the upload progresses. Providers that do not stream
+	// uploads may not call it.
+	OnProgress func(sent int64) `json:"-"`
+
 	// N specifies how many edited images to generate (1-10).
 	// Note: DALL-E 2 supports up to 10 images.
 	N *int `json:"n,omitempty"`
@@ -811,6 +1034,11 @@ type ImageVariationRequest struct {
 	// Must end with ".png".
 	ImageFilename string `json:"-"`
 
+	// OnProgress, if set, is called with the number of request body bytes
+	// sent so far as the upload progresses. Providers that do not stream
+	// uploads may not call it.
+	OnProgress func(sent int64) `json:"-"`
+
 	// N specifies how many image variations to generate (1-10).
 	// Note: DALL-E 2 supports up to 10 images.
 	N *int `json:"n,omitempty"`
@@ -864,9 +1092,15 @@ type TranscriptionRequest struct {
 	File io.Reader `json:"-"`
 
 	// Filename is the name of the audio file (including extension).
-	// Required for multipart form data encoding.
+	// Required for multipart form data encoding unless the format can be
+	// detected from the content; a wrong extension is corrected.
 	Filename string `json:"-"`
 
+	// OnProgress, if set, is called with the number of request body bytes
+	// sent so far as the upload progresses. Providers that do not stream
+	// uploads may not call it.
+	OnProgress func(sent int64) `json:"-"`
+
 	// Language is a hint for the audio language (ISO 639-1 code).
 	// Examples: "en", "es", "fr"
 	// Improves accuracy and reduces latency.
@@ -935,6 +1169,36 @@ type TranscriptionResponse struct {
 
 	// Model is the model used for transcription (internal metadata).
 	Model string `json:"-"`
+
+	// RequestID is the warp request ID (internal metadata).
+	RequestID string `json:"-"`
+
+	// ProviderRequestID is the request ID the provider returned, if any,
+	// for support escalations (internal metadata).
+	ProviderRequestID string `json:"-"`
+}
+
+// AudioDuration returns the length of the transcribed audio.
+//
+// Uses the reported Duration when available (verbose_json), otherwise the end
+// of the last segment or word. Returns 0 if the duration cannot be determined,
+// e.g. for "text" or "json" response formats.
+func (r *TranscriptionResponse) AudioDuration() time.Duration {
+	if r == nil {
+		return 0
+	}
+
+	seconds := r.Duration
+	if seconds == 0 {
+		for _, seg := range r.Segments {
+			seconds = max(seconds, seg.End)
+		}
+		for _, w := range r.Words {
+			seconds = max(seconds, w.End)
+		}
+	}
+
+	return time.Duration(seconds * float64(time.Second))
 }
 
 // Word represents a transcribed word with timestamp.
@@ -1075,6 +1339,13 @@ type ModerationResponse struct {
 
 	// Provider is the provider that performed the moderation (internal metadata).
 	Provider string `json:"-"`
+
+	// RequestID is the warp request ID (internal metadata).
+	RequestID string `json:"-"`
+
+	// ProviderRequestID is the request ID the provider returned, if any,
+	// for support escalations (internal metadata).
+	ProviderRequestID string `json:"-"`
 }
 
 // ModerationResult represents moderation results for a single input text.
@@ -1202,6 +1473,20 @@ type RerankRequest struct {
 	// Documents are the documents to rank by relevance to the query.
 	Documents []string `json:"documents"`
 
+	// StructuredDocuments are documents with named fields, such as
+	// {"title": ..., "text": ...}, ranked instead of Documents. Set one or
+	// the other. Results carry the original object in StructuredDocument.
+	//
+	// Cohere ranks the objects natively; other providers rank Documents,
+	// which the client fills with each object's RankFields rendered as
+	// "field: value" lines.
+	StructuredDocuments []map[string]any `json:"structured_documents,omitempty"`
+
+	// RankFields selects the fields of StructuredDocuments that are ranked,
+	// in order. If empty, Cohere ranks the "text" field and other providers
+	// receive every field, sorted by name.
+	RankFields []string `json:"rank_fields,omitempty"`
+
 	// TopN returns only the top N most relevant documents.
 	// If nil or 0, all documents are returned.
 	TopN *int `json:"top_n,omitempty"`
@@ -1241,8 +1526,10 @@ type RerankResponse struct {
 	Meta *RerankMeta `json:"meta,omitempty"`
 
 	// Internal metadata (not serialized)
-	Provider string `json:"-"`
-	Model    string `json:"-"`
+	Provider          string `json:"-"`
+	Model             string `json:"-"`
+	RequestID         string `json:"-"`
+	ProviderRequestID string `json:"-"`
 }
 
 // RerankResult represents a single ranked document.
@@ -1261,6 +1548,10 @@ type RerankResult struct {
 	// Document contains the document text if ReturnDocuments was true in the request.
 	// Otherwise this field is empty.
 	Document string `json:"document,omitempty"`
+
+	// StructuredDocument is the original document object when the request
+	// used StructuredDocuments.
+	StructuredDocument map[string]any `json:"structured_document,omitempty"`
 }
 
 // RerankMeta contains metadata about the reranking operation.
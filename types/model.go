@@ -1,6 +1,8 @@
 // Package types contains shared type definitions used across packages to avoid import cycles.
 package types
 
+import "time"
+
 // ModelInfo contains information about a specific model.
 //
 // This is used for model metadata, cost calculation, and capability discovery.
@@ -16,14 +18,44 @@ type ModelInfo struct {
 	OutputCostPer1M float64      // Cost per 1M output tokens (USD)
 	Capabilities    Capabilities // Supported features for this model
 
+	// Discounted and specialized token pricing (0 means not applicable)
+	CachedInputCostPer1M      float64 // Cost per 1M cached (prompt cache read) input tokens (USD); 0 uses InputCostPer1M
+	CacheWriteCostPer1M       float64 // Cost per 1M input tokens written to the prompt cache (USD); 0 uses InputCostPer1M
+	ReasoningCostPer1M        float64 // Cost per 1M reasoning tokens (USD); 0 uses OutputCostPer1M
+	CacheStorageCostPer1MHour float64 // Cost per 1M tokens of explicitly cached content stored per hour (USD)
+	BatchDiscount             float64 // Fractional discount for batch-tier requests (e.g., 0.5 for 50% off)
+
+	// ImageCostPerImage is the cost per generated image (USD), keyed by
+	// "quality/size" (e.g., "hd/1024x1792"), size alone (e.g., "1024x1024"),
+	// or "" for a flat per-image price.
+	ImageCostPerImage map[string]float64
+
+	// DefaultImageSize is the size an image model generates when a request
+	// sets none (e.g., "1024x1024"), used to price those images
+	DefaultImageSize string
+
+	// ServiceTierMultipliers scales the token cost of requests served by a
+	// service tier, keyed by tier name (e.g., "flex": 0.5, "priority": 1.75).
+	// Tiers without an entry are billed at standard rates.
+	ServiceTierMultipliers map[string]float64
+
+	// Audio pricing (0 means not applicable)
+	SpeechCostPer1MChars       float64 // Cost per 1M input characters for text-to-speech (USD)
+	TranscriptionCostPerMinute float64 // Cost per minute of transcribed audio (USD)
+
 	// Additional metadata
 	SupportsVision    bool // Vision/multimodal support (redundant with Capabilities.Vision)
 	SupportsFunctions bool // Function calling support (redundant with Capabilities.FunctionCalling)
 	SupportsJSON      bool // JSON mode support (redundant with Capabilities.JSON)
 	SupportsStreaming bool // Streaming support (redundant with Capabilities.Streaming)
 
-	Deprecated bool   // Model is deprecated
-	ReplacedBy string // Replacement model if deprecated
+	// SupportsDimensions reports whether an embedding model accepts a
+	// requested output dimension (Matryoshka representation learning)
+	SupportsDimensions bool
+
+	Deprecated   bool      // Model is deprecated
+	ReplacedBy   string    // Replacement model if deprecated
+	ShutdownDate time.Time // Date the provider stops serving the model (zero if not announced)
 }
 
 // Capabilities defines what operations a provider supports.
@@ -0,0 +1,119 @@
+package warp
+
+import (
+	"fmt"
+	"io"
+)
+
+// DefaultUploadBufferSize is the default limit on memory used to buffer an
+// upload that cannot seek, so it can be resent on retry.
+const DefaultUploadBufferSize = 32 << 20
+
+// upload makes a request file readable again for retries. Seekable
+// readers are rewound with Seek; others are buffered as they are read,
+// up to a limit beyond which the upload cannot be resent.
+type upload struct {
+	r      io.Reader
+	seeker io.Seeker
+	start  int64
+
+	buf      []byte
+	pos      int
+	limit    int
+	overflow bool
+}
+
+// newUpload wraps r for replay, buffering at most limit bytes if r cannot
+// seek.
+func newUpload(r io.Reader, limit int) *upload {
+	u := &upload{r: r, limit: limit}
+	if seeker, ok := r.(io.Seeker); ok {
+		// Files such as pipes implement io.Seeker but fail to seek
+		if start, err := seeker.Seek(0, io.SeekCurrent); err == nil {
+			u.seeker, u.start = seeker, start
+		}
+	}
+	return u
+}
+
+// Read reads from the buffered bytes, then from the underlying reader.
+func (u *upload) Read(p []byte) (int, error) {
+	if u.pos < len(u.buf) {
+		n := copy(p, u.buf[u.pos:])
+		u.pos += n
+		return n, nil
+	}
+
+	n, err := u.r.Read(p)
+	if n > 0 && u.seeker == nil && !u.overflow {
+		if len(u.buf)+n > u.limit {
+			u.overflow, u.buf, u.pos = true, nil, 0
+		} else {
+			u.buf = append(u.buf, p[:n]...)
+			u.pos = len(u.buf)
+		}
+	}
+	return n, err
+}
+
+// rewind returns to the start of the upload.
+func (u *upload) rewind() error {
+	switch {
+	case u.seeker != nil:
+		if _, err := u.seeker.Seek(u.start, io.SeekStart); err != nil {
+			return fmt.Errorf("failed to rewind upload: %w", err)
+		}
+	case u.overflow:
+		return fmt.Errorf("upload larger than %d bytes cannot be resent", u.limit)
+	default:
+		u.pos = 0
+	}
+	return nil
+}
+
+// replayable reports whether the upload can be rewound.
+func (u *upload) replayable() bool {
+	return u.seeker != nil || !u.overflow
+}
+
+// uploads are the files of a request.
+type uploads []*upload
+
+// newUploads replaces each non-nil reader with a replayable upload.
+func (c *client) newUploads(readers ...*io.Reader) uploads {
+	limit := c.config.UploadBufferSize
+	if limit <= 0 {
+		limit = DefaultUploadBufferSize
+	}
+
+	var us uploads
+	for _, r := range readers {
+		if *r == nil {
+			continue
+		}
+		u := newUpload(*r, limit)
+		*r = u
+		us = append(us, u)
+	}
+	return us
+}
+
+// rewind rewinds every upload.
+func (us uploads) rewind() error {
+	for _, u := range us {
+		if err := u.rewind(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// replayable reports whether every upload can be rewound.
+func (us uploads) replayable() bool {
+	for _, u := range us {
+		if !u.replayable() {
+			return false
+		}
+	}
+	return true
+}
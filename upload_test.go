@@ -0,0 +1,119 @@
+package warp
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"strings"
+	"testing"
+	"testing/iotest"
+	"time"
+)
+
+func TestUploadRewind(t *testing.T) {
+	tests := []struct {
+		name           string
+		reader         io.Reader
+		limit          int
+		wantReplayable bool
+	}{
+		{"seekable", strings.NewReader("audio data"), 4, true},
+		{"buffered", iotest.HalfReader(bytes.NewBufferString("audio data")), 16, true},
+		{"over the limit", bytes.NewBufferString("audio data"), 4, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			u := newUpload(tt.reader, tt.limit)
+
+			// A partial read, as when an upload fails midway
+			first := make([]byte, 3)
+			if _, err := io.ReadFull(u, first); err != nil {
+				t.Fatalf("Read() error = %v", err)
+			}
+			if err := u.rewind(); err != nil {
+				t.Fatalf("rewind() after a partial read error = %v", err)
+			}
+			if data, _ := io.ReadAll(u); string(data) != "audio data" {
+				t.Fatalf("data = %q, want the whole upload", data)
+			}
+
+			if got := u.replayable(); got != tt.wantReplayable {
+				t.Errorf("replayable() = %v, want %v", got, tt.wantReplayable)
+			}
+			if err := u.rewind(); (err == nil) != tt.wantReplayable {
+				t.Fatalf("rewind() error = %v, want replayable %v", err, tt.wantReplayable)
+			}
+			if !tt.wantReplayable {
+				return
+			}
+			if data, _ := io.ReadAll(u); string(data) != "audio data" {
+				t.Errorf("replayed data = %q, want the whole upload", data)
+			}
+		})
+	}
+}
+
+// flakyTranscriptionProvider fails the first attempts with a retryable
+// error after reading the whole file.
+type flakyTranscriptionProvider struct {
+	mockTranscriptionProvider
+	failures int
+	reads    []string
+}
+
+func (p *flakyTranscriptionProvider) Transcription(ctx context.Context, req *TranscriptionRequest) (*TranscriptionResponse, error) {
+	data, _ := io.ReadAll(req.File)
+	p.reads = append(p.reads, string(data))
+	if len(p.reads) <= p.failures {
+		return nil, NewServiceUnavailableError("overloaded", "openai", nil)
+	}
+	return &TranscriptionResponse{Text: "ok"}, nil
+}
+
+func TestClientTranscriptionRetriesUpload(t *testing.T) {
+	audio := "ID3" + strings.Repeat("x", 100)
+	tests := []struct {
+		name      string
+		file      io.Reader
+		limit     int
+		wantReads int
+		wantErr   bool
+	}{
+		{"seekable file", strings.NewReader(audio), 16, 3, false},
+		{"buffered stream", bytes.NewBufferString(audio), 1 << 10, 3, false},
+		{"stream over the buffer limit", bytes.NewBufferString(audio), 16, 1, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client, err := NewClient(
+				WithRetries(3, time.Millisecond, 1),
+				WithUploadBufferSize(tt.limit),
+			)
+			if err != nil {
+				t.Fatalf("NewClient() error = %v", err)
+			}
+			provider := &flakyTranscriptionProvider{
+				mockTranscriptionProvider: mockTranscriptionProvider{name: "openai", supportsTranscription: true},
+				failures:                  2,
+			}
+			client.RegisterProvider(provider)
+
+			_, err = client.Transcription(context.Background(), &TranscriptionRequest{
+				Model:    "openai/whisper-1",
+				File:     tt.file,
+				Filename: "audio.mp3",
+			})
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Transcription() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if len(provider.reads) != tt.wantReads {
+				t.Fatalf("attempts = %d, want %d", len(provider.reads), tt.wantReads)
+			}
+			for i, read := range provider.reads {
+				if read != audio {
+					t.Errorf("attempt %d read %d bytes, want the whole file", i, len(read))
+				}
+			}
+		})
+	}
+}
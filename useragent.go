@@ -0,0 +1,94 @@
+package warp
+
+import (
+	"context"
+	"net/http"
+)
+
+// Version is the warp version reported to providers.
+const Version = "0.1.0"
+
+// DefaultUserAgent is the User-Agent sent to providers: warp-go/<version>.
+const DefaultUserAgent = "warp-go/" + Version
+
+// contextKeyRequestHeaders carries headers for provider HTTP requests.
+const contextKeyRequestHeaders contextKey = "warp_request_headers"
+
+// WithRequestHeaders adds headers for providers to send with their HTTP
+// requests, such as a User-Agent or app identification headers. They
+// are merged with headers already in the context, replacing values of
+// the same name.
+//
+// The client adds its WithUserAgent and WithRequestHeader configuration
+// this way; call it directly for per-request headers.
+//
+// Example:
+//
+//	ctx = warp.WithRequestHeaders(ctx, http.Header{"X-Title": {"Support Bot"}})
+func WithRequestHeaders(ctx context.Context, headers http.Header) context.Context {
+	merged := RequestHeadersFromContext(ctx).Clone()
+	if merged == nil {
+		merged = make(http.Header, len(headers))
+	}
+	for key, values := range headers {
+		merged[http.CanonicalHeaderKey(key)] = values
+	}
+	return context.WithValue(ctx, contextKeyRequestHeaders, merged)
+}
+
+// RequestHeadersFromContext returns the headers added by
+// WithRequestHeaders, or nil. The result must not be modified.
+func RequestHeadersFromContext(ctx context.Context) http.Header {
+	headers, _ := ctx.Value(contextKeyRequestHeaders).(http.Header)
+	return headers
+}
+
+// SetRequestHeaders adds the User-Agent and the headers from
+// WithRequestHeaders in the request's context to an outgoing provider
+// request. Headers the provider already set, such as authentication, are
+// kept. The User-Agent defaults to DefaultUserAgent. If payload tracing is
+// enabled (see WithPayloadTracing), it also captures the request body.
+//
+// Providers call it on every HTTP request they send, after setting the
+// body and Content-Type; the providercore package does so automatically.
+func SetRequestHeaders(req *http.Request) {
+	if trace := payloadTraceFromContext(req.Context()); trace != nil {
+		trace.recordRequest(req)
+	}
+	for key, values := range RequestHeadersFromContext(req.Context()) {
+		if _, ok := req.Header[key]; !ok {
+			req.Header[key] = values
+		}
+	}
+	if req.Header.Get("User-Agent") == "" {
+		req.Header.Set("User-Agent", DefaultUserAgent)
+	}
+}
+
+// withRequestHeaders adds the configured User-Agent and request headers and
+// the request ID header to ctx, keeping headers the caller already added,
+// and a recorder for the provider request ID.
+func (c *client) withRequestHeaders(ctx context.Context) context.Context {
+	ctx = withProviderRequestID(ctx)
+	if c.config.MaxResponseBytes > 0 {
+		ctx = withResponseLimit(ctx, c.config.MaxResponseBytes)
+	}
+
+	userAgent := DefaultUserAgent
+	if c.config.UserAgent != "" {
+		userAgent = c.config.UserAgent + " " + DefaultUserAgent
+	}
+
+	headers := c.config.RequestHeaders.Clone()
+	if headers == nil {
+		headers = make(http.Header, 1)
+	}
+	headers.Set("User-Agent", userAgent)
+	if id := RequestIDFromContext(ctx); id != "" && headers.Get(RequestIDHeader) == "" {
+		headers.Set(RequestIDHeader, id)
+	}
+	for key, values := range RequestHeadersFromContext(ctx) {
+		headers[key] = values
+	}
+	return context.WithValue(ctx, contextKeyRequestHeaders, headers)
+}
@@ -0,0 +1,78 @@
+package warp
+
+import (
+	"context"
+	"net/http"
+	"testing"
+)
+
+func TestSetRequestHeaders(t *testing.T) {
+	req, _ := http.NewRequest("POST", "https://api.example.com", nil)
+	SetRequestHeaders(req)
+	if got := req.Header.Get("User-Agent"); got != DefaultUserAgent {
+		t.Errorf("User-Agent = %q, want %q", got, DefaultUserAgent)
+	}
+
+	ctx := WithRequestHeaders(context.Background(), http.Header{"x-title": {"Bot"}, "Authorization": {"Bearer stolen"}})
+	ctx = WithRequestHeaders(ctx, http.Header{"X-Title": {"Support Bot"}})
+	req, _ = http.NewRequestWithContext(ctx, "POST", "https://api.example.com", nil)
+	req.Header.Set("Authorization", "Bearer key")
+	SetRequestHeaders(req)
+	if got := req.Header.Get("X-Title"); got != "Support Bot" {
+		t.Errorf("X-Title = %q, want the later value", got)
+	}
+	if got := req.Header.Get("Authorization"); got != "Bearer key" {
+		t.Errorf("Authorization = %q, want the provider's value", got)
+	}
+}
+
+func TestClientRequestHeaders(t *testing.T) {
+	client, err := NewClient(
+		WithUserAgent("support-bot/2.3.1"),
+		WithRequestHeader("X-Title", "Support Bot"),
+		WithMaxRetries(0),
+	)
+	if err != nil {
+		t.Fatalf("NewClient() error = %v", err)
+	}
+	defer client.Close()
+
+	var got http.Header
+	mock := &mockProvider{
+		name: "test",
+		completionFunc: func(ctx context.Context, req *CompletionRequest) (*CompletionResponse, error) {
+			httpReq, _ := http.NewRequestWithContext(ctx, "POST", "https://api.example.com", nil)
+			SetRequestHeaders(httpReq)
+			got = httpReq.Header
+			return &CompletionResponse{ID: "test"}, nil
+		},
+	}
+	if err := client.RegisterProvider(mock); err != nil {
+		t.Fatalf("RegisterProvider() error = %v", err)
+	}
+
+	ctx := WithRequestHeaders(context.Background(), http.Header{"X-Title": {"Per Request"}})
+	_, err = client.Completion(ctx, &CompletionRequest{
+		Model:    "test/model",
+		Messages: []Message{{Role: "user", Content: "Hello"}},
+	})
+	if err != nil {
+		t.Fatalf("Completion() error = %v", err)
+	}
+
+	if ua := got.Get("User-Agent"); ua != "support-bot/2.3.1 "+DefaultUserAgent {
+		t.Errorf("User-Agent = %q", ua)
+	}
+	if title := got.Get("X-Title"); title != "Per Request" {
+		t.Errorf("X-Title = %q, want the per-request value", title)
+	}
+}
+
+func TestUserAgentOptions(t *testing.T) {
+	if _, err := NewClient(WithUserAgent(" ")); err == nil {
+		t.Error("WithUserAgent(\" \") error = nil")
+	}
+	if _, err := NewClient(WithRequestHeader("", "x")); err == nil {
+		t.Error("WithRequestHeader(\"\") error = nil")
+	}
+}
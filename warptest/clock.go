@@ -0,0 +1,159 @@
+// Package warptest provides utilities for testing code that uses warp: a
+// fake clock and fault injection functions for deterministic tests of
+// retries, timeouts and fallbacks.
+//
+// Example: verify that a rate-limited request is retried with backoff
+// without waiting in real time.
+//
+//	clock := warptest.NewClock(time.Now())
+//	client, _ := warp.NewClient(
+//	    warp.WithClock(clock),
+//	    warp.WithRetries(3, time.Second, 2),
+//	    warp.WithFaultInjection(warptest.Faults(
+//	        warptest.FailFirst(2, warp.NewRateLimitError("injected", "openai", 0, nil)),
+//	    )),
+//	)
+//	client.RegisterProvider(provider)
+//
+//	done := make(chan error)
+//	go func() {
+//	    _, err := client.Completion(ctx, req)
+//	    done <- err
+//	}()
+//	clock.BlockUntil(2) // the request timeout and the first backoff
+//	clock.Advance(2 * time.Second) // past 1s plus jitter
+//	clock.BlockUntil(2) // the second backoff
+//	clock.Advance(3 * time.Second) // past 2s plus jitter
+//	err := <-done
+package warptest
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/blue-context/warp"
+)
+
+// Clock is a fake warp.Clock whose time only moves when advanced.
+//
+// Thread Safety: Clock is safe for concurrent use.
+type Clock struct {
+	mu     sync.Mutex
+	cond   *sync.Cond
+	now    time.Time
+	timers []*timer
+}
+
+// timer is a pending fake timer.
+type timer struct {
+	clock *Clock
+	at    time.Time
+	ch    chan time.Time
+}
+
+// C returns the timer's channel.
+func (t *timer) C() <-chan time.Time {
+	return t.ch
+}
+
+// Stop removes the timer if it has not fired.
+func (t *timer) Stop() bool {
+	c := t.clock
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for i, pending := range c.timers {
+		if pending == t {
+			c.timers = append(c.timers[:i], c.timers[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+// NewClock creates a clock set to start.
+func NewClock(start time.Time) *Clock {
+	c := &Clock{now: start}
+	c.cond = sync.NewCond(&c.mu)
+	return c
+}
+
+// Now returns the clock's current time.
+func (c *Clock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+// NewTimer returns a timer that fires once the clock has been advanced by
+// d. A non-positive d fires immediately.
+func (c *Clock) NewTimer(d time.Duration) warp.Timer {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	t := &timer{clock: c, at: c.now.Add(d), ch: make(chan time.Time, 1)}
+	if d <= 0 {
+		t.ch <- c.now
+		return t
+	}
+	c.timers = append(c.timers, t)
+	c.cond.Broadcast()
+	return t
+}
+
+// Advance moves the clock forward by d, firing the timers that expire on
+// the way in order.
+func (c *Clock) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.set(c.now.Add(d))
+}
+
+// Set moves the clock to t, firing expired timers. Moving backwards fires
+// nothing.
+func (c *Clock) Set(t time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.set(t)
+}
+
+// set moves the clock to t. The caller must hold c.mu.
+func (c *Clock) set(t time.Time) {
+	if t.Before(c.now) {
+		c.now = t
+		return
+	}
+	sort.SliceStable(c.timers, func(i, j int) bool {
+		return c.timers[i].at.Before(c.timers[j].at)
+	})
+
+	remaining := c.timers[:0]
+	for _, tm := range c.timers {
+		if tm.at.After(t) {
+			remaining = append(remaining, tm)
+			continue
+		}
+		tm.ch <- tm.at
+	}
+	c.timers = remaining
+	c.now = t
+}
+
+// Timers returns the number of pending timers.
+func (c *Clock) Timers() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return len(c.timers)
+}
+
+// BlockUntil waits until at least n timers are pending, e.g. until the
+// client is sleeping before a retry, so the test can advance the clock
+// past it without racing the code under test.
+func (c *Clock) BlockUntil(n int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for len(c.timers) < n {
+		c.cond.Wait()
+	}
+}
@@ -0,0 +1,117 @@
+package warptest
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/blue-context/warp"
+	"github.com/blue-context/warp/internal/testutil"
+)
+
+func TestClockAdvance(t *testing.T) {
+	start := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	clock := NewClock(start)
+
+	late := clock.NewTimer(2 * time.Second)
+	early := clock.NewTimer(time.Second)
+	stopped := clock.NewTimer(time.Second)
+	if !stopped.Stop() {
+		t.Error("Stop() = false for a pending timer")
+	}
+	if clock.Timers() != 2 {
+		t.Fatalf("Timers() = %d, want 2", clock.Timers())
+	}
+
+	clock.Advance(time.Second)
+	select {
+	case at := <-early.C():
+		if !at.Equal(start.Add(time.Second)) {
+			t.Errorf("early fired at %v, want %v", at, start.Add(time.Second))
+		}
+	default:
+		t.Error("early timer did not fire")
+	}
+	select {
+	case <-late.C():
+		t.Error("late timer fired early")
+	case <-stopped.C():
+		t.Error("stopped timer fired")
+	default:
+	}
+
+	clock.Advance(time.Hour)
+	if at := <-late.C(); !at.Equal(start.Add(2 * time.Second)) {
+		t.Errorf("late fired at %v, want its deadline", at)
+	}
+	if got := clock.Now(); !got.Equal(start.Add(time.Hour + time.Second)) {
+		t.Errorf("Now() = %v", got)
+	}
+	if late.Stop() {
+		t.Error("Stop() = true for a fired timer")
+	}
+}
+
+func TestClockBlockUntil(t *testing.T) {
+	clock := NewClock(time.Now())
+	fired := make(chan struct{})
+	go func() {
+		<-clock.NewTimer(time.Minute).C()
+		close(fired)
+	}()
+
+	clock.BlockUntil(1)
+	clock.Advance(time.Minute)
+	<-fired
+}
+
+func TestClockImmediateTimer(t *testing.T) {
+	clock := NewClock(time.Now())
+	select {
+	case <-clock.NewTimer(0).C():
+	default:
+		t.Error("zero duration timer did not fire")
+	}
+	if clock.Timers() != 0 {
+		t.Errorf("Timers() = %d, want 0", clock.Timers())
+	}
+}
+
+func TestClockDrivesClientRetries(t *testing.T) {
+	clock := NewClock(time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC))
+	client, err := warp.NewClient(
+		warp.WithClock(clock),
+		warp.WithRetries(3, time.Second, 2),
+		warp.WithFaultInjection(Faults(
+			FailFirst(2, warp.NewRateLimitError("injected", "mock", 0, nil)),
+		)),
+	)
+	if err != nil {
+		t.Fatalf("NewClient() error = %v", err)
+	}
+	defer client.Close()
+
+	client.RegisterProvider(&testutil.MockProvider{
+		NameFunc: func() string { return "mock" },
+		CompletionFunc: func(ctx context.Context, req *warp.CompletionRequest) (*warp.CompletionResponse, error) {
+			return &warp.CompletionResponse{ID: "ok"}, nil
+		},
+	})
+
+	done := make(chan error)
+	go func() {
+		_, err := client.Completion(context.Background(), &warp.CompletionRequest{
+			Model:    "mock/model",
+			Messages: []warp.Message{{Role: "user", Content: "Hello"}},
+		})
+		done <- err
+	}()
+
+	clock.BlockUntil(2) // the request timeout and the first backoff
+	clock.Advance(2 * time.Second)
+	clock.BlockUntil(2) // the second backoff
+	clock.Advance(3 * time.Second)
+	if err := <-done; err != nil {
+		t.Fatalf("Completion() error = %v", err)
+	}
+}
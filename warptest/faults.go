@@ -0,0 +1,124 @@
+package warptest
+
+import (
+	"context"
+	"math/rand"
+	"sync"
+	"time"
+
+	"github.com/blue-context/warp"
+)
+
+// Distribution returns a latency sample.
+type Distribution func() time.Duration
+
+// Fixed is a distribution that always returns d.
+func Fixed(d time.Duration) Distribution {
+	return func() time.Duration { return d }
+}
+
+// Uniform is a distribution uniform over [min, max), seeded for
+// reproducible tests.
+func Uniform(min, max time.Duration, seed int64) Distribution {
+	r := lockedRand(seed)
+	return func() time.Duration {
+		if max <= min {
+			return min
+		}
+		return min + time.Duration(r.int63n(int64(max-min)))
+	}
+}
+
+// Exponential is an exponential distribution with the given mean, seeded
+// for reproducible tests. It models the long tail of provider latency.
+func Exponential(mean time.Duration, seed int64) Distribution {
+	r := lockedRand(seed)
+	return func() time.Duration {
+		return time.Duration(r.expFloat64() * float64(mean))
+	}
+}
+
+// Faults combines fault functions: latencies add up and the first error
+// wins.
+func Faults(fns ...warp.FaultFunc) warp.FaultFunc {
+	return func(ctx context.Context, attempt int) (time.Duration, error) {
+		var total time.Duration
+		var firstErr error
+		for _, fn := range fns {
+			latency, err := fn(ctx, attempt)
+			total += latency
+			if firstErr == nil {
+				firstErr = err
+			}
+		}
+		return total, firstErr
+	}
+}
+
+// Latency adds latency sampled from dist to every attempt.
+func Latency(dist Distribution) warp.FaultFunc {
+	return func(ctx context.Context, attempt int) (time.Duration, error) {
+		return dist(), nil
+	}
+}
+
+// FailFirst fails the first n attempts of every request with err, so the
+// request succeeds on attempt n if the client retries that often.
+func FailFirst(n int, err error) warp.FaultFunc {
+	return func(ctx context.Context, attempt int) (time.Duration, error) {
+		if attempt < n {
+			return 0, err
+		}
+		return 0, nil
+	}
+}
+
+// ErrorRate fails each attempt with err with probability rate (0 to 1),
+// seeded for reproducible tests.
+func ErrorRate(rate float64, err error, seed int64) warp.FaultFunc {
+	r := lockedRand(seed)
+	return func(ctx context.Context, attempt int) (time.Duration, error) {
+		if r.float64() < rate {
+			return 0, err
+		}
+		return 0, nil
+	}
+}
+
+// ForProvider applies fn only to requests to the named provider.
+func ForProvider(provider string, fn warp.FaultFunc) warp.FaultFunc {
+	return func(ctx context.Context, attempt int) (time.Duration, error) {
+		if warp.ProviderFromContext(ctx) != provider {
+			return 0, nil
+		}
+		return fn(ctx, attempt)
+	}
+}
+
+// safeRand is a math/rand source safe for concurrent use.
+type safeRand struct {
+	mu sync.Mutex
+	r  *rand.Rand
+}
+
+func lockedRand(seed int64) *safeRand {
+	return &safeRand{r: rand.New(rand.NewSource(seed))}
+}
+
+func (s *safeRand) int63n(n int64) int64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.r.Int63n(n)
+}
+
+func (s *safeRand) float64() float64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.r.Float64()
+}
+
+func (s *safeRand) expFloat64() float64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.r.ExpFloat64()
+}
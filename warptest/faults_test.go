@@ -0,0 +1,79 @@
+package warptest
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/blue-context/warp"
+)
+
+func TestFaults(t *testing.T) {
+	errInjected := errors.New("injected")
+	fault := Faults(
+		Latency(Fixed(time.Second)),
+		FailFirst(2, errInjected),
+		Latency(Fixed(500*time.Millisecond)),
+	)
+
+	for attempt, wantErr := range []error{errInjected, errInjected, nil} {
+		latency, err := fault(context.Background(), attempt)
+		if latency != 1500*time.Millisecond {
+			t.Errorf("attempt %d latency = %v, want 1.5s", attempt, latency)
+		}
+		if err != wantErr {
+			t.Errorf("attempt %d error = %v, want %v", attempt, err, wantErr)
+		}
+	}
+}
+
+func TestDistributions(t *testing.T) {
+	uniform := Uniform(time.Second, 2*time.Second, 1)
+	again := Uniform(time.Second, 2*time.Second, 1)
+	for i := 0; i < 100; i++ {
+		d := uniform()
+		if d < time.Second || d >= 2*time.Second {
+			t.Fatalf("Uniform() = %v, want within [1s, 2s)", d)
+		}
+		if d2 := again(); d2 != d {
+			t.Fatalf("Uniform() with the same seed = %v and %v", d, d2)
+		}
+	}
+
+	var total time.Duration
+	exp := Exponential(100*time.Millisecond, 1)
+	for i := 0; i < 1000; i++ {
+		total += exp()
+	}
+	if mean := total / 1000; mean < 80*time.Millisecond || mean > 120*time.Millisecond {
+		t.Errorf("Exponential() mean = %v, want about 100ms", mean)
+	}
+}
+
+func TestErrorRate(t *testing.T) {
+	errInjected := errors.New("injected")
+	fault := ErrorRate(0.25, errInjected, 1)
+
+	failures := 0
+	for i := 0; i < 1000; i++ {
+		if _, err := fault(context.Background(), 0); err != nil {
+			failures++
+		}
+	}
+	if failures < 200 || failures > 300 {
+		t.Errorf("failures = %d of 1000, want about 250", failures)
+	}
+}
+
+func TestForProvider(t *testing.T) {
+	errInjected := errors.New("injected")
+	fault := ForProvider("openai", FailFirst(1, errInjected))
+
+	if _, err := fault(warp.WithProvider(context.Background(), "openai"), 0); err != errInjected {
+		t.Errorf("openai error = %v, want injected", err)
+	}
+	if _, err := fault(warp.WithProvider(context.Background(), "anthropic"), 0); err != nil {
+		t.Errorf("anthropic error = %v, want nil", err)
+	}
+}